@@ -51,37 +51,57 @@ type Topic struct {
 // New docs do NOT have to be listed here — they will get an auto-generated
 // summary — but listing them yields tighter prose.
 var summaries = map[string]string{
-	"getting-started":    "Quickstart: install ailloy and cast your first mold",
-	"blanks":             "Blanks: commands, skills, and workflow templates",
-	"anneal":             "Configure flux variables interactively (alias: configure)",
-	"flux":               "Template variable system, schemas, and value layering",
-	"foundry":            "Resolve molds from git foundries and manage indexes",
-	"smelt":              "Package molds into distributable tarballs or binaries",
-	"temper":             "Validate molds and ingot packages",
-	"assay":              "Lint AI instruction files against best practices",
-	"plugin":             "Generate plugins from molds (Claude Code)",
-	"ingots":             "Reusable template components",
-	"agents-md":          "Tool-agnostic agent instructions in molds",
-	"cast-claude-plugin": "Cast a mold as a Claude Code plugin",
-	"helm-users":         "Concept map for Helm users coming to Ailloy",
-	"cache":              "Clear ailloy's on-disk cache (mold artifacts and foundry indexes)",
+	"getting-started":     "Quickstart: install ailloy and cast your first mold",
+	"blanks":              "Blanks: commands, skills, and workflow templates",
+	"anneal":              "Configure flux variables interactively (alias: configure)",
+	"flux":                "Template variable system, schemas, and value layering",
+	"foundry":             "Resolve molds from git foundries and manage indexes",
+	"smelt":               "Package molds into distributable tarballs or binaries",
+	"temper":              "Validate molds and ingot packages",
+	"assay":               "Lint AI instruction files against best practices",
+	"plugin":              "Generate plugins from molds (Claude Code or any output adapter)",
+	"ingots":              "Reusable template components",
+	"agents-md":           "Tool-agnostic agent instructions in molds",
+	"cast-claude-plugin":  "Cast a mold as a Claude Code plugin",
+	"cast-claude-skills":  "Compile a mold's blanks into Claude Skills",
+	"cast-cursor-rules":   "Convert a mold's blanks into Cursor .mdc rules",
+	"cast-opencode-codex": "Convert a mold's blanks for OpenCode or the Codex CLI",
+	"output-adapters":     "Convert a mold for Windsurf, JetBrains AI Assistant, and other tools with cast --to",
+	"serve":               "HTTP API for listing, validating, and rendering molds",
+	"mcp":                 "Serve molds to MCP clients such as Claude Desktop",
+	"sdk":                 "Embed ailloy in Go programs with pkg/ailloy",
+	"logging":             "Quiet, verbose, and JSON output for CI (--quiet, --verbose, --log-format)",
+	"helm-users":          "Concept map for Helm users coming to Ailloy",
+	"cache":               "Clear ailloy's on-disk cache (mold artifacts and foundry indexes)",
+	"keys":                "Signing keys and trusted publishers for molds",
+	"history":             "Audit log of casts, upgrades, and uninstalls, and rolling back",
+	"backups":             "Snapshots of files casts overwrote, and restoring them",
 }
 
 // CommandTopic maps a cobra command name to the topic slug rendered when
 // `--docs` is passed to that command. Slugs may include subdirectories.
 var CommandTopic = map[string]string{
-	"ailloy":  "getting-started",
-	"anneal":  "anneal",
-	"cast":    "blanks",
-	"forge":   "blanks",
-	"mold":    "blanks",
-	"foundry": "foundry",
-	"smelt":   "smelt",
-	"temper":  "temper",
-	"assay":   "assay",
-	"plugin":  "plugin",
-	"ingot":   "ingots",
-	"cache":   "cache",
+	"ailloy":   "getting-started",
+	"init":     "getting-started",
+	"anneal":   "anneal",
+	"cast":     "blanks",
+	"forge":    "blanks",
+	"mold":     "blanks",
+	"foundry":  "foundry",
+	"smelt":    "smelt",
+	"embedded": "smelt",
+	"temper":   "temper",
+	"assay":    "assay",
+	"plugin":   "plugin",
+	"ingot":    "ingots",
+	"cache":    "cache",
+	"keys":     "keys",
+	"history":  "history",
+	"rollback": "history",
+	"backups":  "backups",
+	"schema":   "flux",
+	"mcp":      "mcp",
+	"serve":    "serve",
 }
 
 // FS exposes the embedded filesystem for advanced consumers (e.g. tests).
@@ -1,11 +1,14 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
@@ -26,6 +29,10 @@ need configuration, then runs an interactive wizard with type-driven prompts
 and optional discovery commands. The result is written as a YAML file that
 can be passed to cast or forge via the -f flag.
 
+Prompts for scm.host, project.organization, repo.name and
+repo.default_branch start pre-filled from the current directory's git
+origin remote when the mold leaves them empty.
+
 Example:
   ailloy anneal ./nimble-mold -o ore.yaml
   ailloy cast ./nimble-mold -f ore.yaml`,
@@ -34,22 +41,28 @@ Example:
 }
 
 var (
-	annealSetVars []string
-	annealOutput  string
+	annealSetVars     []string
+	annealSetFileVars []string
+	annealSetJSONVars []string
+	annealOutput      string
 )
 
 func init() {
 	rootCmd.AddCommand(annealCmd)
 
 	annealCmd.Flags().StringArrayVarP(&annealSetVars, "set", "s", nil, "set flux variable (format: key=value)")
+	_ = annealCmd.RegisterFlagCompletionFunc("set", completeSetFlag(0))
+	annealCmd.Flags().StringArrayVar(&annealSetFileVars, "set-file", nil, "set flux variable to a file's content (format: key=path)")
+	annealCmd.Flags().StringArrayVar(&annealSetJSONVars, "set-json", nil, "set flux variable to a JSON value (format: key=json)")
 	annealCmd.Flags().StringVarP(&annealOutput, "output", "o", "", "write flux YAML to file (default: mold's flux.yaml)")
 }
 
 func runAnneal(_ *cobra.Command, args []string) error {
-	// Scripted mode: --set flags (backward compatible, no mold required)
-	if len(annealSetVars) > 0 {
+	// Scripted mode: --set, --set-file and --set-json flags (backward
+	// compatible, no mold required)
+	if sets := (fluxSets{Set: annealSetVars, Files: annealSetFileVars, JSON: annealSetJSONVars}); len(sets.all()) > 0 {
 		flux := make(map[string]any)
-		if err := mold.ApplySetOverrides(flux, annealSetVars); err != nil {
+		if err := sets.apply(flux); err != nil {
 			return err
 		}
 		if annealOutput != "" {
@@ -66,17 +79,22 @@ func runAnneal(_ *cobra.Command, args []string) error {
 
 	var reader *blanks.MoldReader
 	if foundry.IsRemoteReference(moldDir) {
-		fsys, err := foundry.Resolve(moldDir)
+		fsys, result, err := foundry.ResolveWithMetadata(moldDir)
 		if err != nil {
-			return fmt.Errorf("resolving remote mold: %w", err)
+			return resolutionError(fmt.Errorf("resolving remote mold: %w", err))
+		}
+		if reader, err = ComposeMoldReader(blanks.NewMoldReader(fsys), result.Ref.OverrideKey()); err != nil {
+			return err
 		}
-		reader = blanks.NewMoldReader(fsys)
 	} else {
 		var err error
 		reader, err = blanks.NewMoldReaderFromPath(moldDir)
 		if err != nil {
 			return fmt.Errorf("reading mold directory: %w", err)
 		}
+		if reader, err = ComposeMoldReader(reader, ""); err != nil {
+			return err
+		}
 	}
 
 	// Auto-install any declared ingot/ore deps before resolving the schema so
@@ -107,36 +125,95 @@ func runAnneal(_ *cobra.Command, args []string) error {
 	if len(schema) == 0 {
 		return fmt.Errorf("no flux variables found in %s (add flux.schema.yaml or flux.yaml)", moldDir)
 	}
+	// Start the wizard pre-filled with the project's host, organization,
+	// repository, and default branch.
+	fluxDefaults = withDetectedRepo(schema, fluxDefaults)
 
 	// Interactive mode: run dynamic wizard
 	wiz := newDynamicWizard(schema, fluxDefaults)
+	if !isInteractive() {
+		// The wizard's default answer is Cancel, which prints the values.
+		slog.Warn("prompts are off; printing the mold's flux values without running the wizard (use --set to script anneal)")
+		return writePublicFluxToStdout(schema, wiz.currentFlux())
+	}
+	source := ""
+	if parsed, perr := foundry.ParseReference(moldDir); perr == nil && foundry.IsRemoteReference(moldDir) {
+		source = parsed.OverrideKey()
+	}
+	manifest, _ := reader.LoadManifest()
+	key, name := execConsentKey(source, manifest), moldDir
+	if manifest != nil {
+		name = manifest.Name
+	}
+	if key == "" {
+		key, _ = filepath.Abs(moldDir)
+	}
+	if err := wiz.guardDiscovery(key, name); err != nil {
+		return err
+	}
 	result, confirmed, err := wiz.run()
 	if err != nil {
 		return err
 	}
+	if result != nil {
+		for _, w := range fillIterationOptions(context.Background(), schema, result) {
+			log.Printf("warning: %s", w)
+		}
+	}
 
 	if !confirmed {
 		// User chose "Cancel" — print to stdout for inspection
 		if result != nil {
-			return writeFluxToStdout(result)
+			return writePublicFluxToStdout(schema, result)
 		}
 		return nil
 	}
 
-	// User chose "Save" — write to file
+	// User chose "Save" — write to file, secrets to a git-ignored companion
 	dest := annealOutput
 	if dest == "" {
 		dest = filepath.Join(moldDir, "flux.yaml")
 	}
-	if err := writeFluxToFile(result, dest); err != nil {
+	public, secrets := mold.SplitSecretFlux(schema, result)
+	if err := writeFluxToFile(public, dest); err != nil {
 		return err
 	}
+	secretDest := ""
+	if len(secrets) > 0 {
+		secretDest = mold.LocalFluxPath(dest)
+		if err := writeFluxToFile(secrets, secretDest); err != nil {
+			return err
+		}
+		if _, err := addGitignoreEntries(filepath.Join(filepath.Dir(secretDest), ".gitignore"), []string{filepath.Base(secretDest)}); err != nil {
+			return err
+		}
+	}
 
 	fmt.Println()
 	fmt.Println(styles.SuccessBanner("Blank annealing saved to " + dest))
+	if secretDest != "" {
+		fmt.Println(styles.InfoStyle.Render("Secret values saved to "+secretDest+" (git-ignored); pass it to cast after the flux file: ") +
+			styles.CodeStyle.Render("-f "+dest+" -f "+secretDest))
+	}
 	return nil
 }
 
+// writePublicFluxToStdout prints flux without the values of schema's
+// secret variables, noting which were left out.
+func writePublicFluxToStdout(schema []mold.FluxVar, flux map[string]any) error {
+	public, secrets := mold.SplitSecretFlux(schema, flux)
+	if len(secrets) > 0 {
+		var keys []string
+		for _, fv := range schema {
+			if _, ok := mold.GetNestedAny(secrets, fv.Name); ok {
+				keys = append(keys, fv.Name)
+			}
+		}
+		slog.Info("secret values left out of the printed flux", "keys", strings.Join(keys, ", "))
+	}
+	return writeFluxToStdout(public)
+}
+
 // resolveAnnealSchema resolves the merged schema and flux defaults a mold's
 // anneal wizard should prompt against. Precedence (highest first):
 //
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/github"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// projectNumberKey holds the number of the GitHub Project the ore's
+// discover commands query, next to mold.RepoOrganizationKey.
+const projectNumberKey = "project.number"
+
+// newProjectClient returns the client anneal reads project fields with.
+// Rate-limit waits are announced on stderr, so a throttled lookup doesn't
+// look like a hang, and bounded by github.retryBudget in config.yaml.
+// Tests replace it.
+var newProjectClient = func(host string) *github.Client {
+	c := github.NewClientForHost(host)
+	c.OnRetry = func(e github.RetryEvent) { slog.Warn(e.String()) }
+	if cfg, err := index.LoadConfig(); err == nil {
+		budget, err := cfg.GitHubRetryBudget()
+		if err != nil {
+			slog.Warn(err.Error())
+		} else {
+			c.RetryBudget = budget
+		}
+	}
+	return c
+}
+
+// fillIterationOptions fills the options map every resolve.current_iteration
+// names from GitHub, so cast picks the running iteration from the project's
+// live iteration configuration. The field is the one whose ID the map's
+// sibling field_id holds (ore.iteration.field_id for ore.iteration.options),
+// on the project project.organization and project.number name. Maps whose
+// field_id is empty are left alone. It returns a warning for each map it
+// could not fill; flux is modified in place.
+func fillIterationOptions(ctx context.Context, schema []mold.FluxVar, flux map[string]any) []string {
+	var (
+		warnings []string
+		seen     = map[string]bool{}
+		client   *github.Client
+		fields   []github.Field
+	)
+	for _, fv := range schema {
+		if fv.Resolve == nil || fv.Resolve.CurrentIteration == "" {
+			continue
+		}
+		path := strings.TrimPrefix(strings.TrimSpace(fv.Resolve.CurrentIteration), ".")
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		fieldKey := "field_id"
+		if i := strings.LastIndex(path, "."); i >= 0 {
+			fieldKey = path[:i+1] + fieldKey
+		}
+		fieldID, _ := mold.GetNestedValue(flux, fieldKey)
+		if fieldID == "" {
+			continue
+		}
+		if client == nil {
+			org, _ := mold.GetNestedValue(flux, mold.RepoOrganizationKey)
+			raw, _ := mold.GetNestedAny(flux, projectNumberKey)
+			number, _ := strconv.Atoi(fmt.Sprint(raw))
+			if org == "" || number <= 0 {
+				return append(warnings, fmt.Sprintf("%s: set %s and %s to read iteration field %s from GitHub", path, mold.RepoOrganizationKey, projectNumberKey, fieldID))
+			}
+			host, _ := mold.GetNestedValue(flux, mold.RepoHostKey)
+			client = newProjectClient(host)
+			res, err := client.GetProjectFields(ctx, org, number)
+			if err != nil {
+				return append(warnings, fmt.Sprintf("%s: reading project %s/%d: %v", path, org, number, err))
+			}
+			fields = res.Fields
+		}
+		var field *github.Field
+		for i := range fields {
+			if fields[i].ID == fieldID {
+				field = &fields[i]
+				break
+			}
+		}
+		if field == nil || field.Type != github.FieldTypeIteration {
+			warnings = append(warnings, fmt.Sprintf("%s: %s %s is not an iteration field of the project", path, fieldKey, fieldID))
+			continue
+		}
+		mold.SetNestedAny(flux, path, github.IterationOptions(field))
+	}
+	return warnings
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/github"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// projectFieldsExecer answers gh's project fields query with one iteration
+// field and one single-select field, after rejecting the first rateLimited
+// calls with a rate-limit response.
+type projectFieldsExecer struct{ calls, rateLimited int }
+
+func (e *projectFieldsExecer) Run(_ context.Context, _ []string) ([]byte, error) {
+	e.calls++
+	if e.calls <= e.rateLimited {
+		return []byte("HTTP/2.0 403 Forbidden\r\nX-Ratelimit-Remaining: 0\r\n\r\n" +
+			`{"message":"API rate limit exceeded"}` + "\ngh: API rate limit exceeded (HTTP 403)\n"), errors.New("exit status 1")
+	}
+	return []byte(`{"data": {"organization": {"projectV2": {"id": "P1", "fields": {"nodes": [
+		{"id": "PVTIF_sprint", "name": "Sprint", "configuration": {"iterations": [
+			{"id": "2c1f9a", "title": "Sprint 12", "startDate": "2026-10-12", "duration": 14},
+			{"id": "8e04b7", "title": "Sprint 13", "startDate": "2026-10-26", "duration": 14}
+		]}},
+		{"id": "PVTSSF_status", "name": "Status", "options": [{"id": "s1", "name": "Todo"}]}
+	]}}}}}`), nil
+}
+
+func stubProjectClient(t *testing.T) *projectFieldsExecer {
+	t.Helper()
+	exec := &projectFieldsExecer{}
+	orig := newProjectClient
+	t.Cleanup(func() { newProjectClient = orig })
+	newProjectClient = func(host string) *github.Client {
+		c := orig(host)
+		c.Exec = exec
+		return c
+	}
+	return exec
+}
+
+var iterationSchema = []mold.FluxVar{
+	{Name: "ore.iteration.current", Type: "string", Resolve: &mold.ResolveSpec{CurrentIteration: "ore.iteration.options"}},
+	{Name: "ore.iteration.current_title", Type: "string", Resolve: &mold.ResolveSpec{CurrentIteration: "ore.iteration.options", Field: "label"}},
+}
+
+func TestFillIterationOptions_FromProjectField(t *testing.T) {
+	exec := stubProjectClient(t)
+	flux := map[string]any{
+		"project": map[string]any{"organization": "acme", "number": "6"},
+		"ore":     map[string]any{"iteration": map[string]any{"field_id": "PVTIF_sprint"}},
+	}
+	if warnings := fillIterationOptions(t.Context(), iterationSchema, flux); len(warnings) > 0 {
+		t.Fatalf("warnings: %v", warnings)
+	}
+	if exec.calls != 1 {
+		t.Errorf("gh ran %d times, want once", exec.calls)
+	}
+	if id, _ := mold.GetNestedValue(flux, "ore.iteration.options.sprint_13.id"); id != "8e04b7" {
+		t.Errorf("sprint_13.id = %q, want 8e04b7", id)
+	}
+
+	// Cast picks the running iteration from what anneal filled in.
+	mold.ResolveFlux(iterationSchema, flux, time.Date(2026, 10, 27, 9, 0, 0, 0, time.UTC))
+	if got, _ := mold.GetNestedValue(flux, "ore.iteration.current"); got != "8e04b7" {
+		t.Errorf("current = %q, want Sprint 13's id", got)
+	}
+	if got, _ := mold.GetNestedValue(flux, "ore.iteration.current_title"); got != "Sprint 13" {
+		t.Errorf("current_title = %q, want Sprint 13", got)
+	}
+}
+
+func TestFillIterationOptions_Skips(t *testing.T) {
+	exec := stubProjectClient(t)
+
+	// No field picked: the options map is left as it is.
+	flux := map[string]any{"ore": map[string]any{"iteration": map[string]any{"options": map[string]any{"mine": map[string]any{"id": "x"}}}}}
+	if warnings := fillIterationOptions(t.Context(), iterationSchema, flux); len(warnings) > 0 || exec.calls > 0 {
+		t.Fatalf("warnings %v, %d gh calls; want neither without a field_id", warnings, exec.calls)
+	}
+	if id, _ := mold.GetNestedValue(flux, "ore.iteration.options.mine.id"); id != "x" {
+		t.Errorf("hand-written options were replaced")
+	}
+
+	// A field but no project to read it from.
+	mold.SetNestedValue(flux, "ore.iteration.field_id", "PVTIF_sprint")
+	warnings := fillIterationOptions(t.Context(), iterationSchema, flux)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "project.number") {
+		t.Fatalf("warnings = %v, want one asking for the project", warnings)
+	}
+
+	// A field that isn't an iteration field.
+	mold.SetNestedValue(flux, "project.organization", "acme")
+	mold.SetNestedValue(flux, "project.number", "6")
+	mold.SetNestedValue(flux, "ore.iteration.field_id", "PVTSSF_status")
+	warnings = fillIterationOptions(t.Context(), iterationSchema, flux)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "not an iteration field") {
+		t.Fatalf("warnings = %v, want one naming the wrong field", warnings)
+	}
+}
+
+func TestFillIterationOptions_AnnouncesRateLimitRetry(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	mustWrite(t, filepath.Join(home, ".ailloy", "config.yaml"), "github:\n  retryBudget: 5s\n")
+	var stderr bytes.Buffer
+	if err := logging.Setup(logging.Options{Stderr: &stderr}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logging.Setup(logging.Options{}) })
+
+	exec := stubProjectClient(t)
+	exec.rateLimited = 1
+	if c := newProjectClient(""); c.RetryBudget != 5*time.Second {
+		t.Errorf("RetryBudget = %v, want github.retryBudget from config.yaml", c.RetryBudget)
+	}
+	flux := map[string]any{
+		"project": map[string]any{"organization": "acme", "number": "6"},
+		"ore":     map[string]any{"iteration": map[string]any{"field_id": "PVTIF_sprint"}},
+	}
+	if warnings := fillIterationOptions(t.Context(), iterationSchema, flux); len(warnings) > 0 {
+		t.Fatalf("warnings: %v", warnings)
+	}
+	if exec.calls != 2 {
+		t.Errorf("gh ran %d times, want a retry after the rate limit", exec.calls)
+	}
+	if want := "warning: GitHub API rate limit exceeded; retrying in 1s…"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+}
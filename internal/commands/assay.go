@@ -3,11 +3,13 @@ package commands
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/nimble-giant/ailloy/internal/tui/ceremony"
 	"github.com/nimble-giant/ailloy/pkg/assay"
 	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/scope"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
 )
@@ -82,10 +84,14 @@ func runAssay(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("finding project root: %w", err)
 	}
 
-	// Load config
-	cfg, err := assay.LoadConfig(rootDir)
+	// Load config, layered across the workspace (monorepo root first)
+	dirs := scope.WorkspaceDirs(startDir)
+	if !slices.Contains(dirs, rootDir) {
+		dirs = append([]string{rootDir}, dirs...)
+	}
+	cfg, err := assay.LoadWorkspaceConfig(dirs)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return configError(fmt.Errorf("loading config: %w", err))
 	}
 
 	// Apply CLI overrides
@@ -155,7 +161,7 @@ func runAssay(_ *cobra.Command, args []string) error {
 		fmt.Println(styles.ErrorStyle.Render(fmt.Sprintf("%d error(s), %d warning(s), %d suggestion(s)",
 			errors, warnings, suggestions)))
 		ceremony.FailStamp(ceremony.Assay, stampSummary)
-		return fmt.Errorf("assay: findings exceed --%s threshold", assayFailOn)
+		return validationError(fmt.Errorf("assay: findings exceed --%s threshold", assayFailOn))
 	}
 
 	fmt.Println(styles.SuccessStyle.Render(fmt.Sprintf("%d error(s), %d warning(s), %d suggestion(s)",
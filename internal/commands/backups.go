@@ -0,0 +1,437 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+// Backups are snapshots of the files a cast or upgrade was about to
+// overwrite: .ailloy/backups/<id>/ (~/.ailloy/backups/ for --global) holds
+// index.yaml and, under files/, each original at its destination path.
+// Files whose content is exactly what the mold last cast there are not
+// backed up — they can be cast again — so snapshots hold hand-edited and
+// foreign files.
+const (
+	backupsDirName  = "backups"
+	backupIndexFile = "index.yaml"
+	backupFilesDir  = "files"
+	backupIDLayout  = "20060102-150405"
+)
+
+var (
+	backupsGlobal bool
+	backupsOutput string
+	backupsDryRun bool
+)
+
+var backupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "List and restore files cast and upgrades overwrote",
+	Long: `Before cast, recast, sync or rollback overwrites a file that isn't exactly
+what the mold last cast there — a hand-edited CLAUDE.md, say — the original
+is copied to .ailloy/backups/<timestamp>/ (~/.ailloy/backups/ with -g).
+
+Available subcommands:
+  list      List backup snapshots, newest first
+  restore   Put a snapshot's files back`,
+}
+
+var backupsListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List backup snapshots, newest first",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runBackupsList,
+}
+
+var backupsRestoreCmd = &cobra.Command{
+	Use:   "restore <id|latest> [file...]",
+	Short: "Put a snapshot's files back",
+	Long: `Restore the files in a backup snapshot, or only the named ones (paths as
+` + "`backups list`" + ` shows them). The files being replaced are backed up
+first, so a restore can itself be undone.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runBackupsRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(backupsCmd)
+	backupsCmd.AddCommand(backupsListCmd, backupsRestoreCmd)
+	backupsCmd.PersistentFlags().BoolVarP(&backupsGlobal, "global", "g", false, "use the global backups under ~/.ailloy/backups")
+	addOutputFlag(backupsListCmd, &backupsOutput)
+	backupsRestoreCmd.Flags().BoolVar(&backupsDryRun, "dry-run", false, "list the files that would be restored without writing them")
+}
+
+// backupScope says where a cast's backups go and what they are labeled.
+type backupScope struct {
+	DestPrefix string // "" for the project, the home directory for --global
+	Op         string // history operation, e.g. "cast"
+	Mold       string
+}
+
+// backupIndex is a snapshot's index.yaml.
+type backupIndex struct {
+	Time   time.Time    `json:"time" yaml:"time"`
+	Op     string       `json:"op" yaml:"op"`
+	Mold   string       `json:"mold,omitempty" yaml:"mold,omitempty"`
+	Global bool         `json:"global,omitempty" yaml:"global,omitempty"`
+	Files  []backupFile `json:"files" yaml:"files"`
+}
+
+// backupFile is one backed-up file: its destination relative to the
+// project (or home directory) in slash form, as history records it. When
+// the destination was a symlink, the content is its target's and Link
+// records the link as written, so restore can put it back.
+type backupFile struct {
+	Path   string      `json:"path" yaml:"path"`
+	SHA256 string      `json:"sha256" yaml:"sha256"`
+	Mode   fs.FileMode `json:"mode" yaml:"mode"`
+	Link   string      `json:"link,omitempty" yaml:"link,omitempty"`
+}
+
+// backupSnapshot is a snapshot on disk.
+type backupSnapshot struct {
+	ID          string `json:"id" yaml:"id"`
+	backupIndex `yaml:",inline"`
+	dir         string
+	dests       map[string]string // backed-up Path → destination, until settle
+}
+
+// backupsRoot returns the backups directory for destPrefix.
+func backupsRoot(destPrefix string) string {
+	if destPrefix == "" {
+		return filepath.Join(".ailloy", backupsDirName)
+	}
+	return filepath.Join(destPrefix, ".ailloy", backupsDirName)
+}
+
+// backup copies the journaled files that existed and aren't the mold's own
+// last cast (previous holds its hashes by destination) into a new snapshot.
+// It returns nil when there is nothing to back up.
+func (j *castJournal) backup(scope backupScope, previous map[string]string) (*backupSnapshot, error) {
+	var files []journaledFile
+	for _, f := range j.files {
+		if f.existed && hashBytes(f.data) != previous[f.path] {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	s, err := newBackupSnapshot(backupsRoot(scope.DestPrefix), now)
+	if err != nil {
+		return nil, err
+	}
+	s.backupIndex = backupIndex{Time: now, Op: scope.Op, Mold: scope.Mold, Global: scope.DestPrefix != ""}
+	s.dests = map[string]string{}
+	for _, f := range files {
+		rel := installedRelPath(scope.DestPrefix, f.path)
+		if err := s.store(rel, f.data, f.mode, f.link); err != nil {
+			s.discard()
+			return nil, err
+		}
+		s.dests[rel] = f.path
+	}
+	if err := s.writeIndex(); err != nil {
+		s.discard()
+		return nil, err
+	}
+	return s, nil
+}
+
+// newBackupSnapshot creates the directory for a snapshot taken at t under
+// root, suffixing the id when another snapshot took the same second.
+func newBackupSnapshot(root string, t time.Time) (*backupSnapshot, error) {
+	if err := os.MkdirAll(root, 0750); err != nil { // #nosec G301
+		return nil, fmt.Errorf("creating %s: %w", root, err)
+	}
+	base := t.Format(backupIDLayout)
+	for n := 1; ; n++ {
+		id := base
+		if n > 1 {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		dir := filepath.Join(root, id)
+		err := os.Mkdir(dir, 0750) // #nosec G301
+		if err == nil {
+			return &backupSnapshot{ID: id, dir: dir}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("creating backup %s: %w", dir, err)
+		}
+	}
+}
+
+func (s *backupSnapshot) store(rel string, data []byte, mode fs.FileMode, link string) error {
+	path := filepath.Join(s.dir, backupFilesDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil { // #nosec G301
+		return fmt.Errorf("backing up %s: %w", rel, err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("backing up %s: %w", rel, err)
+	}
+	s.Files = append(s.Files, backupFile{Path: rel, SHA256: hashBytes(data), Mode: mode, Link: link})
+	return nil
+}
+
+func (s *backupSnapshot) writeIndex() error {
+	sort.Slice(s.Files, func(a, b int) bool { return s.Files[a].Path < s.Files[b].Path })
+	data, err := yaml.Marshal(s.backupIndex)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, backupIndexFile), data, 0600)
+}
+
+// settle drops the files the cast left unchanged (kept local edits, merges
+// that added nothing) and removes the snapshot if none are left.
+func (s *backupSnapshot) settle() error {
+	kept := s.Files[:0]
+	for _, f := range s.Files {
+		if current, err := os.ReadFile(s.dests[f.Path]); err == nil && hashBytes(current) == f.SHA256 { // #nosec G304 -- cast destination
+			_ = os.Remove(filepath.Join(s.dir, backupFilesDir, filepath.FromSlash(f.Path)))
+			continue
+		}
+		kept = append(kept, f)
+	}
+	s.Files = kept
+	if len(s.Files) == 0 {
+		s.discard()
+		return nil
+	}
+	return s.writeIndex()
+}
+
+// discard removes the snapshot, e.g. when the cast it was taken for was
+// rolled back.
+func (s *backupSnapshot) discard() {
+	_ = os.RemoveAll(s.dir)
+}
+
+// listBackupSnapshots returns the snapshots under root, newest first. A snapshot
+// whose index can't be read is skipped.
+func listBackupSnapshots(root string) ([]backupSnapshot, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []backupSnapshot
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := readBackup(root, e.Name())
+		if err != nil {
+			continue
+		}
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if !out[a].Time.Equal(out[b].Time) {
+			return out[a].Time.After(out[b].Time)
+		}
+		return out[a].ID > out[b].ID
+	})
+	return out, nil
+}
+
+// readBackup loads the snapshot id under root.
+func readBackup(root, id string) (*backupSnapshot, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("invalid backup id %q", id)
+	}
+	dir := filepath.Join(root, id)
+	data, err := os.ReadFile(filepath.Join(dir, backupIndexFile)) // #nosec G304 -- id is a single path element under ailloy's backups dir
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no backup %q; run `ailloy backups list`", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &backupSnapshot{ID: id, dir: dir}
+	if err := yaml.Unmarshal(data, &s.backupIndex); err != nil {
+		return nil, fmt.Errorf("reading backup %s: %w", id, err)
+	}
+	return s, nil
+}
+
+// restoreBackupSnapshot writes the snapshot's files (or only those in paths) back
+// to their destinations under destPrefix, after backing up what they
+// replace. It returns the restored paths.
+func restoreBackupSnapshot(s *backupSnapshot, destPrefix string, paths []string, dryRun bool) ([]string, error) {
+	files := s.Files
+	if len(paths) > 0 {
+		byPath := map[string]backupFile{}
+		for _, f := range s.Files {
+			byPath[f.Path] = f
+		}
+		files = nil
+		for _, p := range paths {
+			f, ok := byPath[filepath.ToSlash(filepath.Clean(p))]
+			if !ok {
+				return nil, fmt.Errorf("backup %s has no file %q", s.ID, p)
+			}
+			files = append(files, f)
+		}
+	}
+
+	var restored []string
+	type pending struct {
+		dest string
+		data []byte
+		mode fs.FileMode
+		link string
+	}
+	var writes []pending
+	var dests []string
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(s.dir, backupFilesDir, filepath.FromSlash(f.Path))) // #nosec G304 -- inside the snapshot
+		if err != nil {
+			return nil, fmt.Errorf("reading backup of %s: %w", f.Path, err)
+		}
+		if hashBytes(data) != f.SHA256 {
+			return nil, fmt.Errorf("backup of %s is corrupt: its sha256 doesn't match the index", f.Path)
+		}
+		dest := castDest(destPrefix, f.Path)
+		if current, err := os.ReadFile(dest); err == nil && bytes.Equal(current, data) { // #nosec G304 -- restore destination
+			continue
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		writes = append(writes, pending{dest: dest, data: data, mode: mode, link: f.Link})
+		dests = append(dests, dest)
+		restored = append(restored, f.Path)
+	}
+	if dryRun || len(writes) == 0 {
+		return restored, nil
+	}
+
+	journal, err := journalDests(dests)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := journal.backup(backupScope{DestPrefix: destPrefix, Op: "restore", Mold: s.Mold}, nil); err != nil {
+		return nil, err
+	}
+	for _, w := range writes {
+		if err := os.MkdirAll(filepath.Dir(w.dest), 0750); err != nil { // #nosec G301
+			return nil, errors.Join(err, journal.rollback())
+		}
+		if w.link != "" {
+			// A symlinked destination that has since been removed gets its
+			// link back; the content is then written through it.
+			if _, err := os.Lstat(w.dest); errors.Is(err, fs.ErrNotExist) {
+				if err := os.Symlink(w.link, w.dest); err != nil {
+					return nil, errors.Join(fmt.Errorf("restoring %s: %w", w.dest, err), journal.rollback())
+				}
+				if target, err := resolveLink(w.dest); err == nil {
+					_ = os.MkdirAll(filepath.Dir(target), 0750) // #nosec G301
+				}
+			}
+		}
+		if err := os.WriteFile(w.dest, w.data, w.mode); err != nil {
+			return nil, errors.Join(fmt.Errorf("restoring %s: %w", w.dest, err), journal.rollback())
+		}
+	}
+	return restored, nil
+}
+
+func runBackupsList(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(backupsOutput); err != nil {
+		return err
+	}
+	destPrefix, err := globalDestPrefix(backupsGlobal)
+	if err != nil {
+		return err
+	}
+	snapshots, err := listBackupSnapshots(backupsRoot(destPrefix))
+	if err != nil {
+		return err
+	}
+	return writeBackupsList(cmd.OutOrStdout(), snapshots, backupsOutput)
+}
+
+func writeBackupsList(w io.Writer, snapshots []backupSnapshot, format string) error {
+	if format != "" {
+		if snapshots == nil {
+			snapshots = []backupSnapshot{}
+		}
+		return writeStructured(w, format, snapshots)
+	}
+	if len(snapshots) == 0 {
+		_, _ = fmt.Fprintln(w, "No backups. Files a cast overwrites are backed up here when they differ from what the mold last cast.")
+		return nil
+	}
+	for _, s := range snapshots {
+		label := s.Op
+		if s.Mold != "" {
+			label += " " + s.Mold
+		}
+		_, _ = fmt.Fprintf(w, "%s  %s  %s\n", styles.CodeStyle.Render(s.ID),
+			styles.SubtleStyle.Render(s.Time.Local().Format("2006-01-02 15:04:05")), label)
+		for _, f := range s.Files {
+			_, _ = fmt.Fprintln(w, styles.SubtleStyle.Render("    - "+f.Path))
+		}
+	}
+	return nil
+}
+
+func runBackupsRestore(_ *cobra.Command, args []string) error {
+	destPrefix, err := globalDestPrefix(backupsGlobal)
+	if err != nil {
+		return err
+	}
+	root := backupsRoot(destPrefix)
+	id := args[0]
+	if id == "latest" {
+		snapshots, err := listBackupSnapshots(root)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return fmt.Errorf("no backups in %s", root)
+		}
+		id = snapshots[0].ID
+	}
+	s, err := readBackup(root, id)
+	if err != nil {
+		return err
+	}
+	restored, err := restoreBackupSnapshot(s, destPrefix, args[1:], backupsDryRun)
+	if err != nil {
+		return err
+	}
+	if len(restored) == 0 {
+		fmt.Println(styles.InfoStyle.Render("Nothing to restore: ") + "the files already match backup " + styles.CodeStyle.Render(s.ID))
+		return nil
+	}
+	header := "Restored from "
+	if backupsDryRun {
+		header = "Would restore from "
+	}
+	fmt.Println(styles.SuccessStyle.Render(header) + styles.CodeStyle.Render(s.ID))
+	for _, p := range restored {
+		fmt.Println(styles.SubtleStyle.Render("  - " + p))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCastJournal_BackupSettleRestore(t *testing.T) {
+	t.Chdir(t.TempDir())
+	mustWriteFile(t, "edited.md", []byte("my notes\n"))
+	mustWriteFile(t, "unchanged.md", []byte("same\n"))
+	mustWriteFile(t, "ours.md", []byte("cast last time\n"))
+
+	journal, err := journalDests([]string{"edited.md", "unchanged.md", "ours.md", "new.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := map[string]string{"ours.md": hashBytes([]byte("cast last time\n"))}
+	snap, err := journal.backup(backupScope{Op: "cast", Mold: "demo"}, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap == nil || len(snap.Files) != 2 {
+		t.Fatalf("snapshot = %+v, want edited.md and unchanged.md (ours.md is the mold's own cast, new.md didn't exist)", snap)
+	}
+
+	mustWriteFile(t, "edited.md", []byte("overwritten\n"))
+	mustWriteFile(t, "ours.md", []byte("new render\n"))
+	if err := snap.settle(); err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Files) != 1 || snap.Files[0].Path != "edited.md" {
+		t.Fatalf("settled files = %+v, want only edited.md", snap.Files)
+	}
+
+	snapshots, err := listBackupSnapshots(backupsRoot(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ID != snap.ID || snapshots[0].Mold != "demo" {
+		t.Fatalf("listBackupSnapshots = %+v, want the one snapshot", snapshots)
+	}
+
+	restored, err := restoreBackupSnapshot(&snapshots[0], "", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 1 {
+		t.Fatalf("restored = %v, want [edited.md]", restored)
+	}
+	if got, _ := os.ReadFile("edited.md"); string(got) != "my notes\n" {
+		t.Errorf("edited.md = %q, want the backed-up content", got)
+	}
+
+	// The restore backed up what it replaced.
+	snapshots, err = listBackupSnapshots(backupsRoot(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Op != "restore" {
+		t.Fatalf("after restore snapshots = %+v, want a restore snapshot first", snapshots)
+	}
+	if data, _ := os.ReadFile(filepath.Join(backupsRoot(""), snapshots[0].ID, backupFilesDir, "edited.md")); string(data) != "overwritten\n" {
+		t.Errorf("restore snapshot holds %q, want the replaced content", data)
+	}
+}
+
+func TestRestoreBackupSnapshot_Errors(t *testing.T) {
+	t.Chdir(t.TempDir())
+	mustWriteFile(t, "a.md", []byte("original\n"))
+	journal, err := journalDests([]string{"a.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := journal.backup(backupScope{Op: "cast"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := restoreBackupSnapshot(snap, "", []string{"b.md"}, false); err == nil || !strings.Contains(err.Error(), `no file "b.md"`) {
+		t.Errorf("unknown file: err = %v", err)
+	}
+	if _, err := readBackup(backupsRoot(""), "../x"); err == nil {
+		t.Error("readBackup should reject ids that aren't a single path element")
+	}
+
+	mustWriteFile(t, filepath.Join(snap.dir, backupFilesDir, "a.md"), []byte("tampered\n"))
+	if _, err := restoreBackupSnapshot(snap, "", nil, false); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("tampered backup: err = %v, want corrupt", err)
+	}
+}
+
+func TestCastMold_BacksUpOverwrittenFiles(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	t.Setenv("HOME", t.TempDir())
+
+	moldDir := filepath.Join(projectDir, "mold")
+	if err := os.MkdirAll(moldDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, filepath.Join(moldDir, "mold.yaml"), []byte("apiVersion: v1\nkind: Mold\nname: demo\nversion: 0.1.0\n"))
+	mustWriteFile(t, filepath.Join(moldDir, "flux.yaml"), []byte("output:\n  CLAUDE.md: CLAUDE.md\n"))
+	mustWriteFile(t, filepath.Join(moldDir, "CLAUDE.md"), []byte("from the mold\n"))
+	mustWriteFile(t, "CLAUDE.md", []byte("hand-written instructions\n"))
+
+	res, err := CastMold(t.Context(), moldDir, CastOptions{})
+	if err != nil {
+		t.Fatalf("CastMold: %v", err)
+	}
+	if res.Backup == "" {
+		t.Fatal("CastMold overwrote a hand-written CLAUDE.md without backing it up")
+	}
+	data, err := os.ReadFile(filepath.Join(".ailloy", "backups", res.Backup, backupFilesDir, "CLAUDE.md"))
+	if err != nil || !bytes.Equal(data, []byte("hand-written instructions\n")) {
+		t.Fatalf("backup of CLAUDE.md = %q, %v", data, err)
+	}
+
+	// Casting again only replaces the mold's own output: nothing to back up.
+	res, err = CastMold(t.Context(), moldDir, CastOptions{})
+	if err != nil {
+		t.Fatalf("second CastMold: %v", err)
+	}
+	if res.Backup != "" {
+		t.Errorf("re-cast over the mold's own output took backup %s", res.Backup)
+	}
+}
+
+func TestCastJournal_BacksUpSymlinkedDest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	mustWriteFile(t, "AGENTS.md", []byte("hand-written instructions\n"))
+	if err := os.Symlink("AGENTS.md", "CLAUDE.md"); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	journal, err := journalDests([]string{"CLAUDE.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := journal.backup(backupScope{Op: "cast", Mold: "demo"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap == nil || len(snap.Files) != 1 || snap.Files[0].Path != "CLAUDE.md" || snap.Files[0].Link != "AGENTS.md" {
+		t.Fatalf("snapshot = %+v, want CLAUDE.md with its link recorded", snap)
+	}
+
+	// The cast writes through the link.
+	mustWriteFile(t, "CLAUDE.md", []byte("from the mold\n"))
+	if err := snap.settle(); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err := listBackupSnapshots(backupsRoot(""))
+	if err != nil || len(snapshots) != 1 {
+		t.Fatalf("snapshots = %+v, %v", snapshots, err)
+	}
+	if _, err := restoreBackupSnapshot(&snapshots[0], "", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile("AGENTS.md"); string(got) != "hand-written instructions\n" {
+		t.Errorf("AGENTS.md = %q, want the backed-up content restored through the link", got)
+	}
+
+	// A link removed since the backup is put back.
+	if err := os.Remove("CLAUDE.md"); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, "AGENTS.md", []byte("edited again\n"))
+	if _, err := restoreBackupSnapshot(&snapshots[0], "", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if link, err := os.Readlink("CLAUDE.md"); err != nil || link != "AGENTS.md" {
+		t.Errorf("CLAUDE.md link = %q, %v; want it recreated", link, err)
+	}
+	if got, _ := os.ReadFile("AGENTS.md"); string(got) != "hand-written instructions\n" {
+		t.Errorf("AGENTS.md = %q after restoring the link", got)
+	}
+}
@@ -21,6 +21,7 @@ var (
 	cacheClearIndexes bool
 	cacheClearDryRun  bool
 	cacheClearYes     bool
+	cacheListOutput   string
 )
 
 var cacheCmd = &cobra.Command{
@@ -29,9 +30,19 @@ var cacheCmd = &cobra.Command{
 	Long: `Manage ailloy's on-disk cache.
 
 Available subcommands:
+  list       List cached molds and their versions
   clear      Clear cached mold artifacts and foundry indexes`,
 }
 
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached molds and their versions",
+	Long: `List the molds in ailloy's on-disk cache (~/.ailloy/cache) with the
+versions downloaded for each. Use --output json or yaml for scripts.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheList,
+}
+
 var cacheClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear ailloy's on-disk cache",
@@ -48,8 +59,11 @@ deleting. Use --yes to skip the confirmation prompt.`,
 
 func init() {
 	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd)
 	cacheCmd.AddCommand(cacheClearCmd)
 
+	addOutputFlag(cacheListCmd, &cacheListOutput)
+
 	registerCacheClearFlags(cacheClearCmd)
 }
 
@@ -60,6 +74,64 @@ func registerCacheClearFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&cacheClearYes, "yes", "y", false, "skip the confirmation prompt")
 }
 
+// cachedMold is one entry in cache list's output.
+type cachedMold struct {
+	Ref      string   `json:"ref" yaml:"ref"` // host/owner/repo
+	Path     string   `json:"path" yaml:"path"`
+	Versions []string `json:"versions" yaml:"versions"`
+}
+
+func runCacheList(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(cacheListOutput); err != nil {
+		return err
+	}
+	moldRoot, err := foundry.CacheDir()
+	if err != nil {
+		return err
+	}
+	indexRoot, err := index.IndexCacheDir()
+	if err != nil {
+		return err
+	}
+	return executeCacheList(moldRoot, indexRoot, cacheListOutput, cmd.OutOrStdout())
+}
+
+// executeCacheList lists the molds cached under moldRoot, skipping the
+// foundry index cache when it lives inside it.
+func executeCacheList(moldRoot, indexRoot, format string, w io.Writer) error {
+	entries, err := foundry.ListCachedMolds(moldRoot)
+	if err != nil {
+		return err
+	}
+	molds := []cachedMold{}
+	for _, e := range entries {
+		path := filepath.Join(moldRoot, e.Host, e.Owner, e.Repo)
+		if filepath.Join(moldRoot, e.Host) == filepath.Clean(indexRoot) {
+			continue
+		}
+		versions := e.Versions
+		if versions == nil {
+			versions = []string{}
+		}
+		molds = append(molds, cachedMold{Ref: e.Host + "/" + e.Owner + "/" + e.Repo, Path: path, Versions: versions})
+	}
+	if format != "" {
+		return writeStructured(w, format, molds)
+	}
+
+	if len(molds) == 0 {
+		_, _ = fmt.Fprintln(w, "Cache is empty.")
+		return nil
+	}
+	for _, m := range molds {
+		_, _ = fmt.Fprintln(w, m.Ref)
+		if len(m.Versions) > 0 {
+			_, _ = fmt.Fprintf(w, "  %s\n", strings.Join(m.Versions, ", "))
+		}
+	}
+	return nil
+}
+
 func runCacheClear(cmd *cobra.Command, _ []string) error {
 	moldRoot, err := foundry.CacheDir()
 	if err != nil {
@@ -233,7 +305,7 @@ func displayPath(p string) string {
 }
 
 func stdinIsTTY() bool {
-	return term.IsTerminal(int(os.Stdin.Fd()))
+	return !nonInteractive() && term.IsTerminal(int(os.Stdin.Fd()))
 }
 
 type moldStats struct {
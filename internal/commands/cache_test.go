@@ -466,3 +466,36 @@ func mustWriteFile(t *testing.T, p string, data []byte) {
 		t.Fatalf("WriteFile(%s): %v", p, err)
 	}
 }
+
+func TestExecuteCacheList(t *testing.T) {
+	root := t.TempDir()
+	mustMkdirAll(t, filepath.Join(root, "github.com", "foo", "bar", "git"))
+	mustMkdirAll(t, filepath.Join(root, "github.com", "foo", "bar", "v1.0.0"))
+	mustMkdirAll(t, filepath.Join(root, "github.com", "foo", "bar", "v1.1.0"))
+	indexRoot := filepath.Join(root, "indexes")
+	mustMkdirAll(t, filepath.Join(indexRoot, "github.com", "acme"))
+
+	var out bytes.Buffer
+	if err := executeCacheList(root, indexRoot, "", &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "github.com/foo/bar\n  v1.0.0, v1.1.0\n"; out.String() != want {
+		t.Errorf("text = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := executeCacheList(root, indexRoot, outputJSON, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"ref": "github.com/foo/bar"`) || strings.Contains(out.String(), "indexes") {
+		t.Errorf("json = %s", out.String())
+	}
+
+	out.Reset()
+	if err := executeCacheList(t.TempDir(), indexRoot, outputYAML, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "[]\n" {
+		t.Errorf("empty yaml = %q", out.String())
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -8,8 +9,10 @@ import (
 	"io"
 	"io/fs"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -17,14 +20,19 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/charmbracelet/huh"
 	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/internal/tui/ceremony"
+	"github.com/nimble-giant/ailloy/internal/tui/progress"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
 	"github.com/nimble-giant/ailloy/pkg/merge"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/smelt"
 	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/nimble-giant/ailloy/pkg/yamlcheck"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var castCmd = &cobra.Command{
@@ -34,19 +42,35 @@ var castCmd = &cobra.Command{
 	Long: `Cast Ailloy configuration into a project (alias: install).
 
 Installs rendered blanks from the given mold into the current repository.
+The mold is a directory, a remote reference, or a .tar.gz archive made by
+smelt (ailloy cast ./my-mold-1.2.3.tar.gz).
 If run from a stuffed binary (created by smelt -o binary), the embedded mold
-is used automatically when no mold-dir is provided.
+is used automatically when no mold-dir is provided. A binary smelted with
+several molds casts the one named by --mold, else its default, else asks.
 Use -f to layer additional flux value files (Helm-style).
+Flux keys the mold leaves empty among scm.host, project.organization,
+repo.name and repo.default_branch are pre-filled from the repository's git
+origin remote.
 Use -g/--global to install into the user's home directory (~/) instead.`,
-	RunE: runCast,
+	ValidArgsFunction: completeCachedRefs,
+	RunE:              runCast,
 }
 
 var (
 	withWorkflows                bool
+	castCI                       string
 	castGlobal                   bool
 	castSetFlags                 []string
+	castSetFileFlags             []string
+	castSetJSONFlags             []string
 	castValFiles                 []string
 	castClaudePluginFlag         bool
+	castClaudeSkillsFlag         bool
+	castCursorRulesFlag          bool
+	castOpenCodeFlag             bool
+	castCodexFlag                bool
+	castTo                       string
+	castSkillNames               []string
 	castPluginName               string
 	castPluginVer                string
 	castForceReplaceOnParseError bool
@@ -62,6 +86,40 @@ var (
 	// and bare-clone fetches are served from the local cache; fails with an
 	// actionable error if the cache is cold. Intended for air-gapped builds.
 	castOffline bool
+	// castCacheFirst, set from `foundry.resolution: cache-first` in
+	// config.yaml, resolves from the local cache when it can and fetches
+	// only what the cache lacks.
+	castCacheFirst bool
+	// castHistoryFlags are the flags this cast ran with, for the history
+	// log.
+	castHistoryFlags []string
+	// castAllowYanked lets resolution use versions their author yanked,
+	// with a warning, instead of refusing them.
+	castAllowYanked bool
+	// castIgnoreRequires downgrades an unmet requires.ailloy constraint on
+	// the mold or any of its dependencies from an error to a warning.
+	castIgnoreRequires bool
+	// castEphemeral records the cast as a trial in .ailloy/ephemeral.yaml
+	// (with backups of overwritten files) instead of installed.yaml, so
+	// `ailloy revert --ephemeral` can undo it. castEphemeralDays sets when
+	// the trial is flagged as expired.
+	castEphemeral     bool
+	castEphemeralDays int
+	// castAll casts every mold declared in the project's ailloy.yaml (the
+	// same as `ailloy sync`).
+	castAll bool
+	// castProfile selects one of the mold's output profiles (e.g. "cursor")
+	// in place of its default output mapping.
+	castProfile string
+	// castNoHooks skips the hook scripts declared in mold.yaml.
+	castNoHooks bool
+	// castOnly and castExclude cast part of the mold: path patterns or
+	// names of the mold's components (see mold.Selection).
+	castOnly    []string
+	castExclude []string
+	// castMold picks the mold to cast from a stuffed binary smelted with
+	// several (see chooseEmbeddedMold).
+	castMold string
 )
 
 // copyOpts configures copyResolvedFiles. Centralising these as a struct lets
@@ -78,6 +136,33 @@ type copyOpts struct {
 	// renders). Nil falls back to log.Default(); the TUI path passes a
 	// discarding logger so concurrent casts can't race on log.SetOutput.
 	Logger *log.Logger
+	// RenderHashes, when non-nil, receives the sha256 of each file's
+	// rendered content keyed by DestPath, taken before merge/append
+	// strategies touch the destination. Recorded on the installed manifest
+	// so `status` can tell outdated files from locally modified ones.
+	RenderHashes map[string]string
+	// PreviousHashes holds, by DestPath, the sha256 each file had when this
+	// mold last wrote it (see previousCastHashes). An append destination
+	// that still matches and has no section for the mold was written whole
+	// by an older cast, and is replaced by the section instead of keeping
+	// a second copy of the mold's content.
+	PreviousHashes map[string]string
+	// Provenance stamps replace-strategy files with a provenance header
+	// (see mold.StampProvenance) unless the mold or the file opts out.
+	Provenance bool
+	// Edits, when non-nil, keeps or merges into files whose provenance
+	// header shows local edits instead of overwriting them (see
+	// localEdits). Nil overwrites them like any other file.
+	Edits *localEdits
+	// Backup, when non-nil, snapshots the existing files the cast is about
+	// to change, except those still exactly as the mold last cast them
+	// (PreviousHashes), into .ailloy/backups/ (see castJournal.backup).
+	// BackupID receives the snapshot's id, if one was kept.
+	Backup   *backupScope
+	BackupID *string
+	// FluxSources points flux validation errors at the values file line or
+	// --set flag the offending value came from (see valuesFluxSources).
+	FluxSources mold.FluxSources
 }
 
 // logger returns opts.Logger or log.Default() when unset.
@@ -92,10 +177,20 @@ func init() {
 	rootCmd.AddCommand(castCmd)
 
 	castCmd.Flags().BoolVarP(&castGlobal, "global", "g", false, "install into user home directory (~/) instead of current project")
-	castCmd.Flags().BoolVar(&withWorkflows, "with-workflows", false, "include GitHub Actions workflow blanks")
+	castCmd.Flags().BoolVar(&withWorkflows, "with-workflows", false, "include workflow blanks for the repository's CI system (detected; GitHub Actions if none is)")
+	castCmd.Flags().StringVar(&castCI, "ci", "", "include the workflow blanks for this CI system (one of: "+strings.Join(mold.CINames(), ", ")+"); implies --with-workflows")
 	castCmd.Flags().StringArrayVar(&castSetFlags, "set", nil, "override flux variable (format: key=value, can be repeated)")
+	_ = castCmd.RegisterFlagCompletionFunc("set", completeSetFlag(0))
+	castCmd.Flags().StringArrayVar(&castSetFileFlags, "set-file", nil, "set a flux variable to a file's content (format: key=path, can be repeated)")
+	castCmd.Flags().StringArrayVar(&castSetJSONFlags, "set-json", nil, "set a flux variable to a JSON value (format: key=json, can be repeated)")
 	castCmd.Flags().StringArrayVarP(&castValFiles, "values", "f", nil, "flux value files (can be repeated, later files override earlier)")
 	castCmd.Flags().BoolVar(&castClaudePluginFlag, "claude-plugin", false, "package the rendered mold as a Claude Code plugin instead of installing blanks at their cast destinations")
+	castCmd.Flags().BoolVar(&castClaudeSkillsFlag, "claude-skills", false, "compile the rendered command blanks into Claude Skills (SKILL.md + resources) instead of installing blanks at their cast destinations")
+	castCmd.Flags().BoolVar(&castCursorRulesFlag, "cursor-rules", false, "convert the rendered command and skill blanks into Cursor rules (.cursor/rules/*.mdc) instead of installing blanks at their cast destinations")
+	castCmd.Flags().BoolVar(&castOpenCodeFlag, "opencode", false, "convert the rendered command and skill blanks into OpenCode commands (.opencode/command/*.md, plus opencode.json) instead of installing blanks at their cast destinations")
+	castCmd.Flags().BoolVar(&castCodexFlag, "codex", false, "convert the rendered blanks for the Codex CLI — commands into ~/.codex/prompts/*.md, skills into AGENTS.md sections — instead of installing blanks at their cast destinations")
+	castCmd.Flags().StringVar(&castTo, "to", "", "convert the rendered blanks into another tool's native files instead of installing them at their cast destinations (one of: "+strings.Join(blanks.OutputAdapterNames(), ", ")+")")
+	castCmd.Flags().StringArrayVar(&castSkillNames, "skill", nil, "only compile the named skill (can be repeated; requires --claude-skills)")
 	castCmd.Flags().StringVar(&castPluginName, "plugin-name", "", "override the plugin name (defaults to the mold's name; requires a plugin output flag such as --claude-plugin)")
 	castCmd.Flags().StringVar(&castPluginVer, "plugin-version", "", "override the plugin version (defaults to the mold's version; requires a plugin output flag such as --claude-plugin)")
 	castCmd.Flags().BoolVar(&castForceReplaceOnParseError,
@@ -114,18 +209,79 @@ func init() {
 		"offline",
 		false,
 		"resolve all dependencies from the local cache only; fails if the cache is cold (run without --offline first to warm it)")
+	castCmd.Flags().BoolVar(&castAllowYanked,
+		"allow-yanked",
+		false,
+		"allow casting a mold or dependency version its author has yanked (warns instead of failing)")
+	castCmd.Flags().BoolVar(&castIgnoreRequires,
+		"ignore-requires",
+		false,
+		"warn instead of failing when the mold or a dependency requires a different ailloy version")
+	castCmd.Flags().BoolVar(&castAll,
+		"all",
+		false,
+		"cast every mold declared in ailloy.yaml (same as 'ailloy sync')")
+	castCmd.Flags().StringVar(&castProfile,
+		"profile",
+		"",
+		"cast with the mold's named output profile (e.g. claude, cursor, opencode, codex) instead of its default output mapping")
+	castCmd.Flags().BoolVar(&castEphemeral,
+		"ephemeral",
+		false,
+		"cast as a revertible trial: recorded in .ailloy/ephemeral.yaml instead of installed.yaml; undo with 'ailloy revert --ephemeral'")
+	castCmd.Flags().IntVar(&castEphemeralDays,
+		"ephemeral-days",
+		int(foundry.DefaultEphemeralTTL/(24*time.Hour)),
+		"days before an --ephemeral trial is flagged as expired")
+	castCmd.Flags().BoolVar(&castNoHooks,
+		"no-hooks",
+		false,
+		"do not run the pre-cast and post-cast hook scripts declared in mold.yaml")
+	castCmd.Flags().StringArrayVar(&castOnly,
+		"only",
+		nil,
+		"cast only the blanks matching this path pattern or mold component (can be repeated)")
+	castCmd.Flags().StringArrayVar(&castExclude,
+		"exclude",
+		nil,
+		"skip the blanks matching this path pattern or mold component (can be repeated)")
+	castCmd.Flags().StringVar(&castMold,
+		"mold",
+		"",
+		"cast this mold from a stuffed binary smelted with several")
 }
 
-func runCast(_ *cobra.Command, args []string) error {
+func runCast(cmd *cobra.Command, args []string) error {
+	cacheFirst, err := configuredCacheFirst()
+	if err != nil {
+		return err
+	}
+	castCacheFirst = cacheFirst
+	castHistoryFlags = historyFlags(cmd)
+	if castAll {
+		return runCastAll(cmd, args)
+	}
 	if err := validatePluginFlags(); err != nil {
 		return err
 	}
+	if err := validateEphemeralFlags(); err != nil {
+		return err
+	}
+	if _, err := castCISystem(false, castCI, ""); err != nil {
+		return err
+	}
+	if outputs := castOutputFlags(); len(outputs) > 0 && (len(castOnly) > 0 || len(castExclude) > 0) {
+		return fmt.Errorf("--only and --exclude cannot be combined with %s", outputs[0])
+	}
 	// A smelted binary carries its mold embedded; network resolution of
 	// transitive deps is unnecessary and breaks air-gapped environments.
 	// Auto-enable offline mode so the binary works without --offline.
 	if len(args) == 0 && smelt.HasEmbeddedMold() {
 		castOffline = true
 	}
+	if err := chooseEmbeddedMold(castMold, args); err != nil {
+		return err
+	}
 	reader, source, err := resolveMoldReader(args)
 	if err != nil {
 		return err
@@ -136,12 +292,158 @@ func runCast(_ *cobra.Command, args []string) error {
 	if castClaudePluginFlag {
 		return castClaudePlugin(reader, source)
 	}
+	if castClaudeSkillsFlag {
+		return castClaudeSkills(reader, source)
+	}
+	if name := castAdapterName(); name != "" {
+		adapter, _ := blanks.LookupOutputAdapter(name) // checked by validatePluginFlags
+		return castWithAdapter(reader, source, adapter)
+	}
 	return castProject(reader, source)
 }
 
+// runCastAll handles `cast --all`: the declarative counterpart of casting
+// each ailloy.yaml mold by hand. Flags that only make sense for one mold,
+// or that target a different install location, are rejected.
+func runCastAll(cmd *cobra.Command, args []string) error {
+	switch {
+	case len(args) > 0:
+		return fmt.Errorf("--all casts the molds declared in %s; don't pass a mold reference", foundry.ProjectFileName)
+	case castGlobal:
+		return fmt.Errorf("--all cannot be combined with --global; %s is project-scoped", foundry.ProjectFileName)
+	case castEphemeral:
+		return fmt.Errorf("--all cannot be combined with --ephemeral")
+	case len(castOutputFlags()) > 0:
+		return fmt.Errorf("--all cannot be combined with %s", castOutputFlags()[0])
+	case len(castOnly) > 0 || len(castExclude) > 0:
+		return fmt.Errorf("--all cannot be combined with --only or --exclude")
+	}
+	return syncProjectMolds(cmd.Context(), foundry.ProjectFileName, syncOptions{
+		Frozen:        castFrozen,
+		WithWorkflows: withWorkflows,
+		ValueFiles:    castValFiles,
+		SetOverrides:  castSetFlags,
+		SetFiles:      castSetFileFlags,
+		SetJSON:       castSetJSONFlags,
+		Profile:       castProfile,
+		HistoryOp:     "cast",
+		HistoryFlags:  castHistoryFlags,
+	})
+}
+
+// castFluxSets returns cast's --set, --set-file and --set-json flags.
+func castFluxSets() fluxSets {
+	return fluxSets{Set: castSetFlags, Files: castSetFileFlags, JSON: castSetJSONFlags}
+}
+
+// fluxSets holds the --set, --set-file and --set-json flags a cast layers
+// over its flux. They are kept apart so only --set-file reads files: a
+// --set value is taken literally however its key is spelled. They apply
+// in that order, so --set-json beats --set-file beats --set for a key.
+type fluxSets struct {
+	Set   []string
+	Files []string
+	JSON  []string
+}
+
+// apply layers the flags over flux.
+func (s fluxSets) apply(flux map[string]any) error {
+	if err := mold.ApplySetOverrides(flux, s.Set); err != nil {
+		return err
+	}
+	if err := mold.ApplySetFileOverrides(flux, s.Files); err != nil {
+		return err
+	}
+	return mold.ApplySetJSONOverrides(flux, s.JSON)
+}
+
+// all returns every flag in the order they apply, for crediting the keys
+// they set.
+func (s fluxSets) all() []string {
+	out := make([]string, 0, len(s.Set)+len(s.Files)+len(s.JSON))
+	out = append(out, s.Set...)
+	out = append(out, s.Files...)
+	return append(out, s.JSON...)
+}
+
+// recordedValueFiles returns the -f files installed.yaml and the history
+// log keep for recast and rollback to replay. Values piped in with -f -
+// are gone once the cast ends, so stdin is left out.
+func recordedValueFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if f != mold.StdinValuesPath {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// castBackupScope returns where castProject backs up the files it
+// overwrites, or nil for a trial cast, which keeps its own backups.
+func castBackupScope(trial *foundry.EphemeralTrial, destPrefix, moldName string) *backupScope {
+	if trial != nil {
+		return nil
+	}
+	return &backupScope{DestPrefix: destPrefix, Op: "cast", Mold: moldName}
+}
+
+// castOptionsRecord returns the cast flags recorded for recast and rollback
+// to replay.
+func castOptionsRecord() *foundry.CastOptionsRecord {
+	return &foundry.CastOptionsRecord{
+		WithWorkflows: withWorkflows,
+		ValueFiles:    recordedValueFiles(castValFiles),
+		SetOverrides:  castSetFlags,
+		SetFiles:      castSetFileFlags,
+		SetJSON:       castSetJSONFlags,
+		Profile:       castProfile,
+		Only:          castOnly,
+		Exclude:       castExclude,
+		CI:            castCI,
+	}
+}
+
+// castOutputFlags returns the alternative output flags (those that convert
+// the mold for another tool instead of installing blanks) set on this run.
+func castOutputFlags() []string {
+	var set []string
+	for _, f := range []struct {
+		on   bool
+		name string
+	}{
+		{castClaudePluginFlag, "--claude-plugin"},
+		{castClaudeSkillsFlag, "--claude-skills"},
+		{castCursorRulesFlag, "--cursor-rules"},
+		{castOpenCodeFlag, "--opencode"},
+		{castCodexFlag, "--codex"},
+		{castTo != "", "--to " + castTo},
+	} {
+		if f.on {
+			set = append(set, f.name)
+		}
+	}
+	return set
+}
+
 // validatePluginFlags ensures plugin-specific overrides are only used when a
 // plugin output flag is set.
 func validatePluginFlags() error {
+	if outputs := castOutputFlags(); len(outputs) > 1 {
+		return fmt.Errorf("%s cannot be combined", strings.Join(outputs, " and "))
+	}
+	if name := castAdapterName(); name != "" {
+		adapter, ok := blanks.LookupOutputAdapter(name)
+		if !ok {
+			return fmt.Errorf("unknown --to %q (want one of %s)", name, strings.Join(blanks.OutputAdapterNames(), ", "))
+		}
+		if castGlobal && !blanks.SupportsGlobal(adapter) {
+			return fmt.Errorf("%s cannot be combined with --global; %s are project-scoped", castOutputFlags()[0], blanks.AdapterTitle(adapter))
+		}
+	}
+	if !castClaudeSkillsFlag && len(castSkillNames) > 0 {
+		return fmt.Errorf("--skill requires --claude-skills")
+	}
 	if !castClaudePluginFlag {
 		if castPluginName != "" {
 			return fmt.Errorf("--plugin-name requires a plugin output flag (e.g. --claude-plugin)")
@@ -153,6 +455,23 @@ func validatePluginFlags() error {
 	return nil
 }
 
+// validateEphemeralFlags rejects --ephemeral combinations that would write
+// outside the project or produce output revert can't track.
+func validateEphemeralFlags() error {
+	if !castEphemeral {
+		return nil
+	}
+	switch {
+	case castGlobal:
+		return fmt.Errorf("--ephemeral cannot be combined with --global; trials are project-only")
+	case len(castOutputFlags()) > 0:
+		return fmt.Errorf("--ephemeral cannot be combined with %s", castOutputFlags()[0])
+	case castEphemeralDays <= 0:
+		return fmt.Errorf("--ephemeral-days must be positive, got %d", castEphemeralDays)
+	}
+	return nil
+}
+
 // checkAilloyRequirement enforces a mold's `requires.ailloy` constraint before
 // any casting work begins. Without this gate an old binary silently ignores
 // the constraint and proceeds with a degraded cast (e.g. skipping transitive
@@ -166,7 +485,36 @@ func checkAilloyRequirement(reader *blanks.MoldReader) error {
 	if err != nil || manifest == nil {
 		return nil
 	}
-	return enforceAilloyVersion(manifest.Requires.Ailloy)
+	return requireAilloy("this mold", manifest.Requires.Ailloy)
+}
+
+// warnUnknownManifestFields logs each mold.yaml key the manifest type has no
+// field for. Loading drops such keys, so a typo like `fluxx:` would
+// otherwise cast without a word; temper reports the same keys as warnings.
+func warnUnknownManifestFields(reader *blanks.MoldReader, logger *log.Logger) {
+	data, err := fs.ReadFile(reader.FS(), "mold.yaml")
+	if err != nil {
+		return
+	}
+	unknown, err := yamlcheck.UnknownFields(data, &mold.Mold{})
+	if err != nil {
+		return // reported by the loader
+	}
+	for _, f := range unknown {
+		logger.Printf("warning: mold.yaml:%d: %s is ignored", f.Line, f)
+	}
+}
+
+// requireAilloy enforces subject's requires.ailloy constraint for a cast.
+// With --ignore-requires an unmet constraint is logged as a warning instead.
+func requireAilloy(subject, requires string) error {
+	err := enforceAilloyVersionFor(subject, requires)
+	if err == nil || !castIgnoreRequires {
+		return err
+	}
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	log.Printf("warning: %s; casting anyway (--ignore-requires)", msg)
+	return nil
 }
 
 // enforceAilloyVersion checks the running ailloy build against a mold's
@@ -176,6 +524,12 @@ func checkAilloyRequirement(reader *blanks.MoldReader) error {
 // should not double as a linter), or when the running binary has no release
 // version to compare (dev builds, where evolveCurrentVersion is empty/"dev").
 func enforceAilloyVersion(requires string) error {
+	return enforceAilloyVersionFor("this mold", requires)
+}
+
+// enforceAilloyVersionFor is enforceAilloyVersion for any package; subject
+// names it in the error (e.g. "this mold", "ore github.com/acme/ores//jira").
+func enforceAilloyVersionFor(subject, requires string) error {
 	requires = strings.TrimSpace(requires)
 	if requires == "" {
 		return nil
@@ -196,8 +550,8 @@ func enforceAilloyVersion(requires string) error {
 		return nil
 	}
 	return fmt.Errorf(
-		"this mold requires ailloy %s, but you are running v%s\nRun `brew upgrade ailloy` to update",
-		requires, strings.TrimPrefix(current, "v"))
+		"%s requires ailloy %s, but you are running v%s\nRun `brew upgrade ailloy` to update, or pass --ignore-requires to cast anyway",
+		subject, requires, strings.TrimPrefix(current, "v"))
 }
 
 // resolvedRemote holds metadata about the most recently resolved remote mold.
@@ -210,25 +564,25 @@ var resolvedRemote *foundry.ResolveResult
 func resolveMoldReader(args []string) (*blanks.MoldReader, string, error) {
 	resolvedRemote = nil
 	if len(args) >= 1 {
-		if foundry.IsRemoteReference(args[0]) {
-			var resolveOpts []foundry.ResolveOption
-			if castGlobal {
-				resolveOpts = append(resolveOpts, foundry.WithLockPath(globalLockPath()))
-			}
-			if castOffline {
-				resolveOpts = append(resolveOpts, foundry.WithOffline())
-			}
-			fsys, result, err := foundry.ResolveWithMetadata(args[0], resolveOpts...)
+		if foundry.IsRemoteReference(args[0]) && !blanks.IsTarball(args[0]) {
+			fsys, result, err := foundry.ResolveWithMetadata(args[0], castResolveOpts(castGlobal)...)
 			if err != nil {
 				if errors.Is(err, foundry.ErrNoSemverTags) {
 					return resolveMoldReaderWithDefaultBranch(args[0])
 				}
-				return nil, "", fmt.Errorf("resolving remote mold: %w", err)
+				return nil, "", resolutionError(fmt.Errorf("resolving remote mold: %w", err))
 			}
 			resolvedRemote = result
-			return blanks.NewMoldReaderFromFS(fsys, result.Root), result.Ref.OverrideKey(), nil
+			slog.Debug("resolved mold", "ref", args[0], "tag", result.Resolved.Tag, "commit", result.Resolved.Commit, "root", result.Root)
+			source := result.Ref.OverrideKey()
+			reader, err := ComposeMoldReader(blanks.NewMoldReaderFromFS(fsys, result.Root), source, castResolveOpts(castGlobal)...)
+			return reader, source, err
+		}
+		reader, err := openLocalMold(args[0])
+		if err != nil {
+			return nil, "", err
 		}
-		reader, err := blanks.NewMoldReaderFromPath(args[0])
+		reader, err = ComposeMoldReader(reader, "", castResolveOpts(castGlobal)...)
 		return reader, "", err
 	}
 	if smelt.HasEmbeddedMold() {
@@ -236,15 +590,25 @@ func resolveMoldReader(args []string) (*blanks.MoldReader, string, error) {
 		if err != nil {
 			return nil, "", fmt.Errorf("opening embedded mold: %w", err)
 		}
-		return blanks.NewMoldReader(fsys), "", nil
+		reader, err := ComposeMoldReader(blanks.NewMoldReader(fsys), "", castResolveOpts(castGlobal)...)
+		return reader, "", err
 	}
 	return nil, "", fmt.Errorf("mold directory is required: ailloy cast <mold-dir>")
 }
 
+// openLocalMold reads a mold from a local directory or, for a .tar.gz or
+// .tgz path, from an archive such as `ailloy smelt` writes.
+func openLocalMold(p string) (*blanks.MoldReader, error) {
+	if blanks.IsTarball(p) {
+		return blanks.NewMoldReaderFromTarball(p)
+	}
+	return blanks.NewMoldReaderFromPath(p)
+}
+
 // resolveMoldReaderWithDefaultBranch handles the fallback path when a foundry
-// has no semver tags. It prompts the user interactively (or auto-accepts when
-// --latest-on-no-tags is set) then resolves the default branch HEAD commit and
-// fetches the mold from it.
+// has no semver tags. It prompts the user interactively (auto-accepting when
+// --latest-on-no-tags is set, declining when prompts are off) then resolves
+// the default branch HEAD commit and fetches the mold from it.
 func resolveMoldReaderWithDefaultBranch(rawRef string) (*blanks.MoldReader, string, error) {
 	ref, err := foundry.ParseReference(rawRef)
 	if err != nil {
@@ -253,31 +617,33 @@ func resolveMoldReaderWithDefaultBranch(rawRef string) (*blanks.MoldReader, stri
 
 	if !castLatestOnNoTags {
 		var confirm bool
-		form := huh.NewForm(
-			huh.NewGroup(
-				huh.NewConfirm().
-					Title(fmt.Sprintf("No semver tags found for %s.", ref.CacheKey())).
-					Description("Cast from latest commit on default branch instead?").
-					Affirmative("Yes").
-					Negative("No").
-					Value(&confirm),
-			),
-		).WithTheme(ailloyTheme())
-		if err := form.Run(); err != nil {
-			return nil, "", fmt.Errorf("prompt failed: %w", err)
+		if isInteractive() {
+			form := huh.NewForm(
+				huh.NewGroup(
+					huh.NewConfirm().
+						Title(fmt.Sprintf("No semver tags found for %s.", ref.CacheKey())).
+						Description("Cast from latest commit on default branch instead?").
+						Affirmative("Yes").
+						Negative("No").
+						Value(&confirm),
+				),
+			).WithTheme(ailloyTheme())
+			if err := form.Run(); err != nil {
+				return nil, "", fmt.Errorf("prompt failed: %w", err)
+			}
 		}
 		if !confirm {
-			return nil, "", fmt.Errorf(
+			return nil, "", abortError(fmt.Errorf(
 				"cast aborted: %s has no semver tags\n"+
 					"Add a version tag to the foundry, or use --latest-on-no-tags to cast from HEAD",
-				ref.CacheKey())
+				ref.CacheKey()))
 		}
 	}
 
 	git := foundry.DefaultGitRunner()
 	resolved, err := foundry.ResolveDefaultBranchHead(ref, git)
 	if err != nil {
-		return nil, "", fmt.Errorf("resolving default branch HEAD: %w", err)
+		return nil, "", resolutionError(fmt.Errorf("resolving default branch HEAD: %w", err))
 	}
 
 	fetcher, err := foundry.NewFetcher(git)
@@ -286,16 +652,18 @@ func resolveMoldReaderWithDefaultBranch(rawRef string) (*blanks.MoldReader, stri
 	}
 	fsys, root, err := fetcher.Fetch(ref, resolved)
 	if err != nil {
-		return nil, "", fmt.Errorf("fetching mold: %w", err)
+		return nil, "", resolutionError(fmt.Errorf("fetching mold: %w", err))
 	}
 
 	result := &foundry.ResolveResult{Ref: ref, Resolved: *resolved, Root: root}
 	resolvedRemote = result
-	return blanks.NewMoldReaderFromFS(fsys, root), ref.OverrideKey(), nil
+	reader, err := ComposeMoldReader(blanks.NewMoldReaderFromFS(fsys, root), ref.OverrideKey(), castResolveOpts(castGlobal)...)
+	return reader, ref.OverrideKey(), err
 }
 
 // loadCastFlux loads layered flux values using Helm-style precedence:
-// mold flux.yaml < mold.yaml schema defaults < persisted ~/.ailloy/flux/<slug>.yaml
+// mold flux.yaml < mold.yaml schema defaults < values detected from the
+// project's git remote (empty keys only) < persisted ~/.ailloy/flux/<slug>.yaml
 // < persisted ./.ailloy/flux/<slug>.yaml < -f files (left to right) < --set flags.
 //
 // Schema and defaults are loaded via LoadMoldFluxWithOres so installed ore
@@ -306,81 +674,79 @@ func resolveMoldReaderWithDefaultBranch(rawRef string) (*blanks.MoldReader, stri
 // foundry cache key for remote refs). Empty source skips persisted-file lookup.
 //
 // Returns the resolved flux map plus the merged schema (used downstream by
-// copyResolvedFiles for ValidateFlux).
+// copyResolvedFiles for ValidateFlux). Errors are returned through
+// fluxLoadError; callers must not fall back to empty flux.
 func loadCastFlux(reader *blanks.MoldReader, source string) (map[string]any, []mold.FluxVar, error) {
-	// Layers 1+2: ore-aware merge of mold.yaml flux schema, mold flux.yaml,
-	// and any installed ore overlays (mold-local → project → global).
-	mergedSchema, fluxDefaults, _, err := mold.LoadMoldFluxWithOres(reader.FS(), readerSearchPaths(reader, castGlobal))
+	flux, schema, err := layerFluxForCore(reader, source, castValFiles, castFluxSets(), castGlobal, castResolveOpts(castGlobal))
+	return flux, schema, fluxLoadError(err)
+}
+
+// fluxLoadError keeps the exit class layerFlux tagged err with (a bad --set
+// is a usage error) and tags anything else as a config error.
+func fluxLoadError(err error) error {
+	var tagged *exitError
+	if err == nil || errors.As(err, &tagged) {
+		return err
+	}
+	return configError(err)
+}
+
+// configuredCacheFirst reports whether config.yaml selects the cache-first
+// resolution policy. An unreadable config means the default; an unknown
+// policy is an error.
+func configuredCacheFirst() (bool, error) {
+	cfg, err := index.LoadConfig()
 	if err != nil {
-		// Fall back to the legacy single-mold path so an ore-loading hiccup
-		// doesn't break ore-less casts.
-		fluxDefaults, err = reader.LoadFluxDefaults()
-		if err != nil {
-			fluxDefaults = make(map[string]any)
-		}
+		return false, nil
 	}
-	// Merge mold.yaml's in-line flux: schema in. LoadMoldFluxWithOres only
-	// reads the standalone flux.schema.yaml file; molds that declare their
-	// schema inline (no flux.schema.yaml on disk) still need their defaults.
-	manifest, _ := reader.LoadManifest()
-	if manifest != nil && len(manifest.Flux) > 0 {
-		fluxDefaults = mold.ApplyFluxDefaults(manifest.Flux, fluxDefaults)
-		if len(mergedSchema) == 0 {
-			mergedSchema = manifest.Flux
-		}
+	policy, err := cfg.ResolutionPolicy()
+	if err != nil {
+		return false, configError(err)
 	}
+	return policy == foundry.ResolutionCacheFirst, nil
+}
 
-	flux := make(map[string]any)
-	for k, v := range fluxDefaults {
-		flux[k] = v
+// castResolveOpts returns the foundry resolve options for a cast's remote
+// references: the global lock for -g, plus --offline, --allow-yanked, and the
+// configured resolution policy.
+func castResolveOpts(global bool) []foundry.ResolveOption {
+	var opts []foundry.ResolveOption
+	if global {
+		opts = append(opts, foundry.WithLockPath(globalLockPath()))
 	}
-	mold.ApplyManifestOutputDefault(flux, manifest)
-
-	// Layer 3: persisted flux files written by the foundries TUI (global, then
-	// project — project wins on conflict). Layered before user-supplied -f so
-	// explicit -f still overrides saved values.
-	persisted := mold.PersistedFluxPaths(source)
-	if len(persisted) > 0 {
-		overlay, err := mold.LayerFluxFiles(persisted)
-		if err != nil {
-			return nil, nil, err
-		}
-		for k, v := range overlay {
-			flux[k] = v
-		}
+	if castOffline {
+		opts = append(opts, foundry.WithOffline())
 	}
-
-	// Layer 4: Layer -f files left-to-right (each overrides previous)
-	if len(castValFiles) > 0 {
-		overlay, err := mold.LayerFluxFiles(castValFiles)
-		if err != nil {
-			return nil, nil, err
-		}
-		for k, v := range overlay {
-			flux[k] = v
-		}
+	if castCacheFirst {
+		opts = append(opts, foundry.WithCacheFirst())
 	}
-
-	// Layer 5: Apply --set overrides (highest precedence)
-	if err := mold.ApplySetOverrides(flux, castSetFlags); err != nil {
-		return nil, nil, err
+	if castAllowYanked {
+		opts = append(opts, foundry.WithAllowYanked())
 	}
+	return opts
+}
 
-	return flux, mergedSchema, nil
+// selectOutputProfile applies an output profile to flux. An explicit profile
+// (--profile, or one recorded at cast time) must be declared by the mold.
+// Without one, the default profile from ~/.ailloy/config.yaml applies to
+// molds that declare it; molds without it keep their default output mapping.
+// Returns the profile that was applied, or "" when none was.
+func selectOutputProfile(flux map[string]any, manifest *mold.Mold, explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, mold.ApplyOutputProfile(flux, manifest, explicit)
+	}
+	cfg, err := index.LoadConfig()
+	if err != nil || cfg.Profile == "" || !mold.HasOutputProfile(flux, manifest, cfg.Profile) {
+		return "", nil
+	}
+	return cfg.Profile, mold.ApplyOutputProfile(flux, manifest, cfg.Profile)
 }
 
 // resolveDestPrefix returns the destination directory prefix.
 // When --global is set, files are installed under ~/ instead of the current directory,
 // so mold output paths land in the user's home directory.
 func resolveDestPrefix() (string, error) {
-	if !castGlobal {
-		return "", nil
-	}
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
-	}
-	return homeDir, nil
+	return globalDestPrefix(castGlobal)
 }
 
 func castProject(reader *blanks.MoldReader, source string) error {
@@ -398,11 +764,7 @@ func castProject(reader *blanks.MoldReader, source string) error {
 	// Check if we're in a git repository (skip for global installs)
 	if destPrefix == "" {
 		if _, err := os.Stat(".git"); os.IsNotExist(err) {
-			warning := styles.WarningStyle.Render("⚠️  Warning: ") +
-				"Not in a Git repository. Consider running " +
-				styles.CodeStyle.Render("git init") + " first."
-			fmt.Println(warning)
-			fmt.Println()
+			slog.Warn("not in a git repository; consider running git init first")
 		}
 	}
 
@@ -411,6 +773,11 @@ func castProject(reader *blanks.MoldReader, source string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load mold manifest: %w", err)
 	}
+	warnUnknownManifestFields(reader, log.Default())
+
+	if castEphemeral && hasMoldDeps(manifest) {
+		return fmt.Errorf("--ephemeral does not support molds with mold dependencies; cast %s without --ephemeral", manifest.Name)
+	}
 
 	// Auto-install declared ingot/ore deps before flux merge so the next
 	// LoadMoldFluxWithOres call sees the just-installed overlays.
@@ -432,8 +799,14 @@ func castProject(reader *blanks.MoldReader, source string) error {
 	// Load flux values and merged schema (mold + ore overlays).
 	flux, mergedSchema, err := loadCastFlux(reader, source)
 	if err != nil {
-		flux = make(map[string]any)
-		mergedSchema = manifest.Flux
+		return err
+	}
+	profile, err := selectOutputProfile(flux, manifest, castProfile)
+	if err != nil {
+		return err
+	}
+	if profile != "" {
+		logging.Say(styles.InfoStyle.Render("Output profile: ")+styles.CodeStyle.Render(profile), "output profile", "profile", profile)
 	}
 
 	// Load ignore patterns from .ailloyignore and mold.yaml.
@@ -458,20 +831,47 @@ func castProject(reader *blanks.MoldReader, source string) error {
 	if err != nil {
 		return fmt.Errorf("failed to resolve output files: %w", err)
 	}
+	if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+		return err
+	}
+	if err := castPickComponents(manifest); err != nil {
+		return err
+	}
+	selection := mold.Selection{Only: castOnly, Exclude: castExclude}
+	if resolved, err = selection.Select(resolved, manifest.Components); err != nil {
+		return err
+	}
 
-	// Filter out workflow files unless --with-workflows is set.
+	// Filter out workflow files unless --with-workflows or --ci selects
+	// their CI system.
+	ci, err := castCISystem(withWorkflows, castCI, destPrefix)
+	if err != nil {
+		return err
+	}
 	var filesToCast []mold.ResolvedFile
 	for _, rf := range resolved {
-		if !withWorkflows && strings.HasPrefix(rf.DestPath, ".github/") {
+		if mold.SkipCIFile(rf.DestPath, ci) {
+			slog.Debug("skipping workflow blank; pass --with-workflows or --ci to cast it", "dest", rf.DestPath, "ci", ci)
 			continue
 		}
 		// Prefix dest paths for global installs.
-		if destPrefix != "" {
-			rf.DestPath = filepath.Join(destPrefix, rf.DestPath)
-		}
+		rf.DestPath = castDest(destPrefix, rf.DestPath)
 		filesToCast = append(filesToCast, rf)
 	}
 
+	// Hooks can't be undone by a revert, so trial casts don't run them.
+	runHooks := !castNoHooks && !castEphemeral
+	hookKey := execConsentKey(source, manifest)
+	hookDir := destPrefix
+	if hookDir == "" {
+		hookDir = "."
+	}
+	if runHooks {
+		if err := runMoldHooks(mold.HookPreCast, reader.FS(), manifest, flux, hookKey, hookDir); err != nil {
+			return err
+		}
+	}
+
 	// Collect unique output directories.
 	dirSet := make(map[string]bool)
 	for _, rf := range filesToCast {
@@ -483,31 +883,84 @@ func castProject(reader *blanks.MoldReader, source string) error {
 	}
 	sort.Strings(dirs)
 
-	fmt.Println(styles.InfoStyle.Render("📁 Creating directory structure..."))
-	for i, dir := range dirs {
-		fmt.Print(styles.ProgressStep(i+1, len(dirs), "Creating "+dir))
-		time.Sleep(100 * time.Millisecond) // Small delay for visual effect
-
+	decorative := logging.Decorative()
+	if decorative {
+		fmt.Println(styles.InfoStyle.Render("📁 Creating directory structure..."))
+	}
+	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0750); err != nil { // #nosec G301 -- Project directories need group read access
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
-		fmt.Print("\r" + styles.SuccessStyle.Render("✅ Created directory: ") + styles.CodeStyle.Render(dir) + "\n")
+		if decorative {
+			fmt.Println(styles.SuccessStyle.Render("✅ Created directory: ") + styles.CodeStyle.Render(dir))
+		} else {
+			slog.Debug("created directory", "dir", dir)
+		}
+	}
+	if decorative {
+		fmt.Println()
+	}
+
+	// Trial casts back up every destination they are about to overwrite.
+	var trial *foundry.EphemeralTrial
+	if castEphemeral {
+		trial, err = beginEphemeralTrial(manifest, source, filesToCast)
+		if err != nil {
+			return err
+		}
 	}
-	fmt.Println()
 
 	// Copy resolved files from mold (using the ore-merged schema for validation).
+	renderHashes := map[string]string{}
 	if err := copyResolvedFilesWithSchema(reader, manifest, mergedSchema, flux, filesToCast, copyOpts{
 		ForceReplaceOnParseError: castForceReplaceOnParseError,
+		RenderHashes:             renderHashes,
+		PreviousHashes:           previousCastHashes(manifest.Name),
+		Provenance:               true,
+		Backup:                   castBackupScope(trial, destPrefix, manifest.Name),
+		FluxSources:              valuesFluxSources(append(mold.PersistedFluxPaths(source), castValFiles...), castFluxSets().all()),
 	}); err != nil {
+		cleanupEmptyDirs(dirs, destPrefix)
 		return fmt.Errorf("failed to copy files: %w", err)
 	}
 
 	// Drop directories that ended up empty after skipped renders (#145).
 	dirs = cleanupEmptyDirs(dirs, destPrefix)
 
+	dests := make([]string, len(filesToCast))
+	for i, f := range filesToCast {
+		dests[i] = f.DestPath
+	}
+	record := historyEntry{Op: "cast", Mold: manifest.Name, Source: source, Version: manifest.Version,
+		Files: castedHistoryFiles(destPrefix, dests), Flags: castHistoryFlags, Global: castGlobal, Options: castOptionsRecord()}
+	if resolvedRemote != nil {
+		record.Version, record.Commit = resolvedRemote.Resolved.Tag, resolvedRemote.Resolved.Commit
+	}
+	recordHistory(record, nil)
+
+	if trial != nil {
+		if err := finishEphemeralTrial(trial); err != nil {
+			return err
+		}
+		printEphemeralSummary(trial)
+		return nil
+	}
+
+	if ci == mold.CIGitLab {
+		if changed, err := addGitLabIncludes(destPrefix, filesToCast); err != nil {
+			log.Printf("warning: failed to add includes to .gitlab-ci.yml: %v", err)
+		} else if changed {
+			slog.Info("listed the mold's GitLab CI files in .gitlab-ci.yml include:")
+		}
+	}
+
 	// Record where blanks were installed (non-fatal if this fails).
 	if destPrefix == "" {
-		if err := writeInstallState(dirs); err != nil {
+		version := ""
+		if resolvedRemote != nil {
+			version = resolvedRemote.Resolved.Tag
+		}
+		if err := writeInstallState(dirs, installedBlanksFor(manifest, source, version, filesToCast)...); err != nil {
 			log.Printf("warning: failed to write install state: %v", err)
 		}
 	}
@@ -520,24 +973,37 @@ func castProject(reader *blanks.MoldReader, source string) error {
 		installed := make([]foundry.InstalledFile, 0, len(filesToCast))
 		for _, f := range filesToCast {
 			sum, _ := hashFile(f.DestPath)
-			rel := f.DestPath
-			if destPrefix != "" {
-				if r, rerr := filepath.Rel(destPrefix, f.DestPath); rerr == nil {
-					rel = r
-				}
-			}
-			installed = append(installed, foundry.InstalledFile{RelPath: rel, SHA256: sum})
-		}
-		castOpts := &foundry.CastOptionsRecord{
-			WithWorkflows: withWorkflows,
-			ValueFiles:    castValFiles,
-			SetOverrides:  castSetFlags,
+			installed = append(installed, foundry.InstalledFile{RelPath: installedRelPath(destPrefix, f.DestPath), SHA256: sum, RenderSHA256: renderHashes[f.DestPath]})
 		}
-		if err := recordCastedFiles(resolvedRemote, installed, castGlobal, castOpts, nil); err != nil {
+		if err := recordCastedFiles(resolvedRemote, installed, castGlobal, castOptionsRecord(), castStampOf(flux), nil); err != nil {
 			log.Printf("warning: failed to record installed files: %v", err)
 		}
 	}
 
+	// A real cast of a mold under trial keeps it: forget the trial so a
+	// later revert can't roll back the now-installed files.
+	if destPrefix == "" {
+		key, subpath := source, ""
+		if resolvedRemote != nil {
+			key, subpath = resolvedRemote.Ref.CacheKey(), resolvedRemote.Ref.Subpath
+		}
+		if key == "" {
+			key = manifest.Name
+		}
+		if kept, err := foundry.DiscardEphemeral(foundry.EphemeralStatePath, key, subpath); err != nil {
+			log.Printf("warning: failed to clear ephemeral trial: %v", err)
+		} else if kept {
+			logging.Say(styles.InfoStyle.Render("🧪 Kept ephemeral trial: ")+styles.CodeStyle.Render(manifest.Name)+" is now a regular install",
+				"kept ephemeral trial as a regular install", "mold", manifest.Name)
+		}
+	}
+
+	if runHooks {
+		if err := runMoldHooks(mold.HookPostCast, reader.FS(), manifest, flux, hookKey, hookDir); err != nil {
+			return err
+		}
+	}
+
 	// Cast transitive mold deps (mold-on-mold dependencies). No-op when the
 	// root has no mold-kind deps. Runs after the root is recorded so cycles
 	// or conflicts surface alongside the root cast result.
@@ -547,6 +1013,11 @@ func castProject(reader *blanks.MoldReader, source string) error {
 		return fmt.Errorf("casting transitive dependencies: %w", err)
 	}
 
+	if !logging.Decorative() {
+		slog.Info("cast complete", "mold", manifest.Name, "dirs", dirs)
+		return nil
+	}
+
 	// Success celebration
 	fmt.Println()
 	successMessage := "Project casting complete!"
@@ -613,7 +1084,12 @@ func castProject(reader *blanks.MoldReader, source string) error {
 		if agentsInstalled {
 			if _, err := os.Stat("CLAUDE.md"); err == nil {
 				if !claudeMDHasAgentsImport("CLAUDE.md") {
-					offerAgentsImport("CLAUDE.md")
+					if isInteractive() {
+						offerAgentsImport("CLAUDE.md")
+					} else {
+						fmt.Println(styles.InfoStyle.Render("Tip: ") + "add " + styles.CodeStyle.Render("@AGENTS.md") +
+							" to CLAUDE.md so Claude Code loads your AGENTS.md instructions.")
+					}
 				}
 			}
 		}
@@ -635,7 +1111,8 @@ func cleanupEmptyDirs(dirs []string, destPrefix string) []string {
 
 	candidates := make(map[string]bool)
 	for _, d := range dirs {
-		for cur := d; cur != stop && cur != "." && cur != string(filepath.Separator) && cur != ""; cur = filepath.Dir(cur) {
+		// A filesystem root is its own parent ("/", or `C:\` on Windows).
+		for cur := d; cur != stop && cur != "." && cur != "" && filepath.Dir(cur) != cur; cur = filepath.Dir(cur) {
 			candidates[cur] = true
 		}
 	}
@@ -682,22 +1159,84 @@ func hashFile(path string) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
+// hashBytes returns the hex-encoded sha256 of data.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // installState represents the .ailloy/state.yaml file that records where blanks were installed.
 type installState struct {
-	BlankDirs    []string `yaml:"blankDirs,omitempty"`
-	WorkflowDirs []string `yaml:"workflowDirs,omitempty"`
+	SchemaVersion int              `yaml:"schemaVersion"`
+	BlankDirs     []string         `yaml:"blankDirs,omitempty"`
+	WorkflowDirs  []string         `yaml:"workflowDirs,omitempty"`
+	Files         []installedBlank `yaml:"files,omitempty"`
+}
+
+// installedBlank records the provenance of one installed file: which mold
+// produced it, from which source path, and what it hashed to when written.
+// Unlike installed.yaml (remote molds only), entries are kept for local and
+// embedded casts too, so `mold list --installed` covers every cast.
+type installedBlank struct {
+	Dest    string `yaml:"dest"`
+	Mold    string `yaml:"mold"`             // mold.yaml name
+	Source  string `yaml:"source,omitempty"` // remote reference (host/owner/repo[/subpath]); empty for local and embedded molds
+	Version string `yaml:"version,omitempty"`
+	SrcPath string `yaml:"srcPath"`
+	Origin  string `yaml:"origin,omitempty"` // ore namespace that supplied the blank, empty for the mold itself
+	SHA256  string `yaml:"sha256,omitempty"`
+}
+
+// installedBlanksFor builds provenance entries for files just cast by mold
+// m. Files that were never written (skipped empty renders) are left out.
+func installedBlanksFor(m *mold.Mold, source, version string, files []mold.ResolvedFile) []installedBlank {
+	name := ""
+	if m != nil {
+		name = m.Name
+		if version == "" {
+			version = m.Version
+		}
+	}
+	out := make([]installedBlank, 0, len(files))
+	for _, rf := range files {
+		sum, err := hashFile(rf.DestPath)
+		if err != nil {
+			continue
+		}
+		out = append(out, installedBlank{
+			Dest:    filepath.ToSlash(rf.DestPath),
+			Mold:    name,
+			Source:  source,
+			Version: version,
+			SrcPath: rf.SrcPath,
+			Origin:  rf.Origin,
+			SHA256:  sum,
+		})
+	}
+	return out
 }
 
 const installStatePath = ".ailloy/state.yaml"
 
+// installStateSchemaVersion is the newest state.yaml schema this binary
+// reads and writes. Older files are migrated on read (migrateInstallState);
+// newer ones are refused for writes so an older ailloy can't drop fields.
+const installStateSchemaVersion = 1
+
 // writeInstallState records where blanks were installed so `mold list` can find them.
 //
 // Reads the existing state.yaml first and unions the new dirs into it, so
 // repeated casts (e.g. installing several molds from a foundry) accumulate
-// rather than overwriting each other.
-func writeInstallState(dirs []string) error {
+// rather than overwriting each other. files replace any entry for the same
+// destination; earlier entries of the same mold that this cast no longer
+// produced are dropped.
+func writeInstallState(dirs []string, files ...installedBlank) error {
 	state := installState{}
-	if existing, err := readInstallState(installStatePath); err == nil && existing != nil {
+	existing, err := readInstallState(installStatePath)
+	if foundry.IsSchemaTooNew(err) {
+		return err
+	}
+	if err == nil && existing != nil {
 		state = *existing
 	}
 
@@ -710,7 +1249,8 @@ func writeInstallState(dirs []string) error {
 		workflowSet[d] = struct{}{}
 	}
 	for _, d := range dirs {
-		if strings.HasPrefix(d, ".github/") {
+		d = filepath.ToSlash(d)
+		if _, ok := mold.CIForDest(d); ok {
 			workflowSet[d] = struct{}{}
 		} else {
 			blankSet[d] = struct{}{}
@@ -719,7 +1259,65 @@ func writeInstallState(dirs []string) error {
 
 	state.BlankDirs = sortedKeys(blankSet)
 	state.WorkflowDirs = sortedKeys(workflowSet)
+	if len(files) > 0 {
+		state.Files = mergeInstalledBlanks(state.Files, files)
+	}
+
+	return saveInstallState(state)
+}
+
+// mergeInstalledBlanks layers files over existing, keyed by destination.
+func mergeInstalledBlanks(existing, files []installedBlank) []installedBlank {
+	type moldID struct{ mold, source string }
+	recast := map[moldID]bool{}
+	byDest := make(map[string]installedBlank, len(existing)+len(files))
+	for _, f := range files {
+		recast[moldID{f.Mold, f.Source}] = true
+	}
+	for _, f := range existing {
+		if !recast[moldID{f.Mold, f.Source}] {
+			byDest[f.Dest] = f
+		}
+	}
+	for _, f := range files {
+		byDest[f.Dest] = f
+	}
+	out := make([]installedBlank, 0, len(byDest))
+	for _, f := range byDest {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Dest < out[j].Dest })
+	return out
+}
+
+// forgetInstalledBlanks drops the provenance entries for dests (e.g. files
+// removed by uninstall). A missing state file is not an error.
+func forgetInstalledBlanks(dests []string) error {
+	state, err := readInstallState(installStatePath)
+	if err != nil || state == nil || len(state.Files) == 0 {
+		return err
+	}
+	drop := make(map[string]bool, len(dests))
+	for _, d := range dests {
+		drop[filepath.ToSlash(d)] = true
+	}
+	kept := state.Files[:0]
+	for _, f := range state.Files {
+		if !drop[f.Dest] {
+			kept = append(kept, f)
+		}
+	}
+	state.Files = kept
+	return saveInstallState(*state)
+}
 
+// saveInstallState writes state stamped with the current schema version,
+// refusing to overwrite a file written by a newer ailloy.
+func saveInstallState(state installState) error {
+	if err := foundry.CheckSchemaVersion(installStatePath, foundry.PeekSchemaVersion(installStatePath), installStateSchemaVersion); err != nil {
+		return err
+	}
+	migrateInstallState(&state)
 	data, err := yaml.Marshal(state)
 	if err != nil {
 		return err
@@ -730,6 +1328,10 @@ func writeInstallState(dirs []string) error {
 	return os.WriteFile(installStatePath, data, 0644) // #nosec G306
 }
 
+// readInstallState parses the state file at path, returning (nil, nil) when
+// it does not exist. Older schemas are migrated in memory. A file from a
+// newer ailloy is still parsed, but returned alongside a *foundry.SchemaError
+// so read-only callers can warn and carry on while writers refuse.
 func readInstallState(path string) (*installState, error) {
 	data, err := os.ReadFile(path) // #nosec G304 -- path is a known constant
 	if err != nil {
@@ -742,9 +1344,21 @@ func readInstallState(path string) (*installState, error) {
 	if err := yaml.Unmarshal(data, &s); err != nil {
 		return nil, err
 	}
+	if err := foundry.CheckSchemaVersion(path, s.SchemaVersion, installStateSchemaVersion); err != nil {
+		return &s, err
+	}
+	migrateInstallState(&s)
 	return &s, nil
 }
 
+// migrateInstallState upgrades state read from an older schema.
+//
+//	0 → 1: unversioned files (blank/workflow dirs only) gain schemaVersion;
+//	       per-file provenance starts empty and fills in on the next cast.
+func migrateInstallState(s *installState) {
+	s.SchemaVersion = installStateSchemaVersion
+}
+
 func sortedKeys(m map[string]struct{}) []string {
 	if len(m) == 0 {
 		return nil
@@ -803,14 +1417,14 @@ func offerAgentsImport(claudePath string) {
 
 	data, err := os.ReadFile(claudePath) // #nosec G304 -- path is a known constant
 	if err != nil {
-		fmt.Println(styles.WarningStyle.Render("⚠️  Could not read " + claudePath + ": " + err.Error()))
+		slog.Warn("could not read "+claudePath, "error", err)
 		return
 	}
 
 	newContent := "@AGENTS.md\n\n" + string(data)
 	//#nosec G306,G703 -- claudePath is a hardcoded constant ("CLAUDE.md"), not user input
 	if err := os.WriteFile(claudePath, []byte(newContent), 0644); err != nil {
-		fmt.Println(styles.WarningStyle.Render("⚠️  Could not update " + claudePath + ": " + err.Error()))
+		slog.Warn("could not update "+claudePath, "error", err)
 		return
 	}
 
@@ -829,6 +1443,19 @@ func chooseFS(rf mold.ResolvedFile, primary fs.FS) fs.FS {
 	return primary
 }
 
+// skipLargeBinaries drops binary files over mold.MaxBinaryFileSize that the
+// manifest's binaries: doesn't allow, warning about each.
+func skipLargeBinaries(resolved []mold.ResolvedFile, primary fs.FS, manifest *mold.Mold) ([]mold.ResolvedFile, error) {
+	kept, skipped, err := mold.FilterLargeBinaries(resolved, primary, manifest.AllowedBinaries())
+	if err != nil {
+		return nil, err
+	}
+	for _, rf := range skipped {
+		slog.Warn("skipping large binary file; list it under binaries: in mold.yaml to cast it", "file", rf.SrcPath, "limit_bytes", mold.MaxBinaryFileSize)
+	}
+	return kept, nil
+}
+
 // copyResolvedFiles copies resolved mold files to the project, applying template
 // processing where indicated by the output mapping. Schema for validation is
 // inferred from the reader / mold manifest. Cast-time callers should prefer
@@ -847,9 +1474,242 @@ func copyResolvedFiles(reader *blanks.MoldReader, manifest *mold.Mold, flux map[
 // copyResolvedFilesWithSchema is copyResolvedFiles with an explicit schema
 // parameter. Callers that have already merged ore overlays (cast/recast)
 // pass the merged schema so ValidateFlux sees the full ore.<name>.* surface.
+//
+// Files are rendered and written by a pool of castWorkers goroutines. Files
+// sharing a destination (merge/append fragments) are written in order by one
+// worker; the "✅ Created" lines are reported in resolved order regardless
+// of which worker finishes first.
+//
+// The cast is all or nothing: every file renders before any is written, and
+// when a write fails the destinations already written are restored (see
+// castJournal).
 func copyResolvedFilesWithSchema(reader *blanks.MoldReader, manifest *mold.Mold, schema []mold.FluxVar, flux map[string]any, resolved []mold.ResolvedFile, opts copyOpts) error {
-	logger := opts.logger()
+	var bar *progress.Bar
+	if !opts.Silent {
+		bar = progress.New(len(resolved), "Rendering blanks")
+	}
+	rendered, err := renderCastFilesProgress(reader, manifest, schema, flux, resolved, opts.logger(), opts.FluxSources, bar.Step)
+	bar.Finish()
+	if err != nil {
+		return err
+	}
+
+	if opts.RenderHashes != nil {
+		for _, f := range rendered {
+			opts.RenderHashes[f.DestPath] = hashBytes(f.content)
+		}
+	}
+
+	// Group by destination, keeping resolved order within each group.
+	var groups [][]int
+	groupOf := map[string]int{}
+	for i, f := range rendered {
+		g, ok := groupOf[f.DestPath]
+		if !ok {
+			g = len(groups)
+			groupOf[f.DestPath] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	dests := make([]string, len(rendered))
+	for i, f := range rendered {
+		dests[i] = f.DestPath
+	}
+	journal, err := journalDests(dests)
+	if err != nil {
+		return err
+	}
+	var snapshot *backupSnapshot
+	if opts.Backup != nil {
+		if snapshot, err = journal.backup(*opts.Backup, opts.PreviousHashes); err != nil {
+			return fmt.Errorf("backing up files before overwriting them: %w", err)
+		}
+	}
+
+	if !opts.Silent {
+		bar = progress.New(len(rendered), "Writing blanks")
+		defer bar.Finish()
+	}
+	written := make(chan int, len(rendered))
+	eg, ctx := errgroup.WithContext(context.Background())
+	eg.SetLimit(castWorkers())
+	go func() {
+		for _, group := range groups {
+			eg.Go(func() error {
+				for _, i := range group {
+					if ctx.Err() != nil {
+						return nil
+					}
+					if err := writeCastFile(manifest, rendered[i], opts); err != nil {
+						return err
+					}
+					written <- i
+				}
+				return nil
+			})
+		}
+		err = eg.Wait()
+		close(written)
+	}()
+
+	done := make([]bool, len(rendered))
+	next := 0
+	for i := range written {
+		done[i] = true
+		bar.Step()
+		for ; next < len(rendered) && done[next]; next++ {
+			if !opts.Silent {
+				dest := rendered[next].DestPath
+				bar.Say(styles.SuccessStyle.Render("✅ Created: ")+styles.CodeStyle.Render(dest), "created", "path", dest)
+			}
+		}
+	}
+	if err != nil {
+		if rerr := journal.rollback(); rerr != nil {
+			return fmt.Errorf("%w\nrestoring the files written before the failure also failed: %v", err, rerr)
+		}
+		if snapshot != nil {
+			snapshot.discard()
+		}
+		return fmt.Errorf("%w\nno files were changed: the files written before the failure were restored", err)
+	}
+	if snapshot != nil {
+		if err := snapshot.settle(); err != nil {
+			opts.logger().Printf("warning: failed to update backup %s: %v", snapshot.ID, err)
+		}
+		if len(snapshot.Files) > 0 {
+			if opts.BackupID != nil {
+				*opts.BackupID = snapshot.ID
+			}
+			if !opts.Silent {
+				logging.Say(styles.InfoStyle.Render("💾 Backed up ")+fmt.Sprintf("%d overwritten file(s) as ", len(snapshot.Files))+styles.CodeStyle.Render(snapshot.ID)+
+					styles.SubtleStyle.Render(" (ailloy backups restore "+snapshot.ID+")"),
+					"backed up overwritten files", "backup", snapshot.ID, "files", len(snapshot.Files))
+			}
+		}
+	}
+	return nil
+}
+
+// writeCastFile applies f's strategy to its destination.
+func writeCastFile(manifest *mold.Mold, f castRenderedFile, opts copyOpts) error {
+	rf := f.ResolvedFile
+	switch rf.Strategy {
+	case "merge":
+		err := merge.MergeFile(rf.DestPath, f.content, merge.Options{
+			ForceReplaceOnParseError: opts.ForceReplaceOnParseError,
+		})
+		if err != nil {
+			var pe *merge.ParseError
+			if errors.As(err, &pe) {
+				return fmt.Errorf(
+					"failed to merge into %s: existing %s file could not be parsed: %w. "+
+						"Re-run with --force-replace-on-parse-error to overwrite",
+					pe.Path, pe.Format, pe.Err)
+			}
+			return fmt.Errorf("failed to merge %s: %w", rf.DestPath, err)
+		}
+	case "append":
+		if manifest == nil {
+			return fmt.Errorf("append strategy requires a mold manifest with a name (dest %s)", rf.DestPath)
+		}
+		if err := dropWholeFileCast(rf.DestPath, manifest.Name, opts.PreviousHashes[rf.DestPath]); err != nil {
+			return err
+		}
+		err := merge.AppendFile(rf.DestPath, f.content, merge.AppendOptions{
+			MoldName: manifest.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to append into %s: %w", rf.DestPath, err)
+		}
+	case "", "replace":
+		content, write := replaceContent(manifest, f, opts)
+		if !write {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(rf.DestPath), 0750); err != nil { // #nosec G301
+			return fmt.Errorf("failed to create directory for %s: %w", rf.DestPath, err)
+		}
+		// Executable blanks (scripts) stay executable.
+		perm := os.FileMode(0644)
+		if f.exec {
+			perm = 0755
+		}
+		//#nosec G306 -- Blanks need to be readable
+		if err := os.WriteFile(rf.DestPath, content, perm); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rf.DestPath, err)
+		}
+		if f.exec {
+			if err := os.Chmod(rf.DestPath, perm); err != nil { // #nosec G302 -- the source blank is executable
+				return fmt.Errorf("failed to make %s executable: %w", rf.DestPath, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q on output for %s", rf.Strategy, rf.DestPath)
+	}
+	return nil
+}
+
+// dropWholeFileCast removes dest when moldName wrote it whole last time and
+// it is unchanged since (its sha256 is still previous) — the file predates
+// its append strategy and holds nothing but the mold's old content.
+func dropWholeFileCast(dest, moldName, previous string) error {
+	if previous == "" {
+		return nil
+	}
+	data, err := os.ReadFile(dest) // #nosec G304 -- cast destination
+	if err != nil || hashBytes(data) != previous || merge.HasBlock(data, moldName) {
+		return nil
+	}
+	if err := os.Remove(dest); err != nil {
+		return fmt.Errorf("replacing %s: %w", dest, err)
+	}
+	return nil
+}
+
+// previousCastHashes returns the sha256 of every file .ailloy/state.yaml
+// records mold moldName writing, by destination.
+func previousCastHashes(moldName string) map[string]string {
+	state, err := readInstallState(installStatePath)
+	if err != nil || state == nil {
+		return nil
+	}
+	hashes := map[string]string{}
+	for _, f := range state.Files {
+		if f.Mold == moldName && f.SHA256 != "" {
+			hashes[f.Dest] = f.SHA256
+		}
+	}
+	return hashes
+}
+
+// castWorkers is the size of the render/write worker pool.
+func castWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
 
+// castRenderedFile is a resolved output with its rendered content, before
+// any merge/append strategy is applied.
+type castRenderedFile struct {
+	mold.ResolvedFile
+	content []byte
+	exec    bool // the source blank is executable
+}
+
+// renderCastFiles renders resolved the way cast does, without writing
+// anything. Files that render to whitespace only are dropped (#130).
+func renderCastFiles(reader *blanks.MoldReader, manifest *mold.Mold, schema []mold.FluxVar, flux map[string]any, resolved []mold.ResolvedFile, logger *log.Logger) ([]castRenderedFile, error) {
+	return renderCastFilesProgress(reader, manifest, schema, flux, resolved, logger, nil, nil)
+}
+
+// renderCastFilesProgress is renderCastFiles with step, when non-nil,
+// called as each file finishes rendering, and flux validation warnings
+// pointing at sources. Files render in parallel; the
+// result keeps resolved order, and a failure reports every broken blank
+// (see renderBlanks).
+func renderCastFilesProgress(reader *blanks.MoldReader, manifest *mold.Mold, schema []mold.FluxVar, flux map[string]any, resolved []mold.ResolvedFile, logger *log.Logger, sources mold.FluxSources, step func()) ([]castRenderedFile, error) {
 	// Validate: ore-merged schema preferred; fall back to flux.schema.yaml /
 	// mold.yaml's flux: block when caller didn't supply one.
 	if len(schema) == 0 {
@@ -859,7 +1719,10 @@ func copyResolvedFilesWithSchema(reader *blanks.MoldReader, manifest *mold.Mold,
 			schema = manifest.Flux
 		}
 	}
-	if err := mold.ValidateFlux(schema, flux); err != nil {
+	for _, w := range append(mold.MigrateFlux(schema, flux), mold.ResolveFlux(schema, flux, time.Now())...) {
+		logger.Printf("warning: %s", w)
+	}
+	if err := mold.ValidateFluxWithSources(schema, flux, sources); err != nil {
 		logger.Printf("warning: %v", err)
 	}
 
@@ -867,81 +1730,30 @@ func copyResolvedFilesWithSchema(reader *blanks.MoldReader, manifest *mold.Mold,
 	// filesystem for stuffed-binary casts, where the mold's ingots live off-disk).
 	resolver := buildIngotResolver(flux, reader.Root())
 	resolver.FS = reader.FS()
-	tplOpts := []mold.TemplateOption{
-		mold.WithIngotResolver(resolver),
-		mold.WithLogger(logger),
+	applyIngotConstraints(resolver, manifest)
+	if err := attachRemoteIngots(resolver, reader.FS(), resolved); err != nil {
+		return nil, err
 	}
 
-	for _, rf := range resolved {
-		content, err := fs.ReadFile(chooseFS(rf, reader.FS()), rf.SrcPath)
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", rf.SrcPath, err)
-		}
-
-		var outputContent []byte
-		if rf.Process {
-			fluxForFile := flux
-			if len(rf.Set) > 0 {
-				fluxForFile = mold.MergeSet(flux, rf.Set)
-			}
-			processed, err := mold.ProcessTemplate(string(content), fluxForFile, tplOpts...)
-			if err != nil {
-				return fmt.Errorf("failed to process %s: %w", rf.SrcPath, err)
-			}
-			outputContent = []byte(processed)
-		} else {
-			outputContent = content
-		}
+	contents, err := renderBlanks(reader.FS(), resolved, flux, resolver, logger, step)
+	if err != nil {
+		return nil, err
+	}
 
+	out := make([]castRenderedFile, 0, len(resolved))
+	for i, rf := range resolved {
 		// Skip files that render to empty or whitespace-only content (#130)
-		if rf.Process && strings.TrimSpace(string(outputContent)) == "" {
+		if rf.Process && strings.TrimSpace(string(contents[i])) == "" {
 			logger.Printf("skipping %s: rendered to empty content", rf.SrcPath)
 			continue
 		}
-
-		switch rf.Strategy {
-		case "merge":
-			err := merge.MergeFile(rf.DestPath, outputContent, merge.Options{
-				ForceReplaceOnParseError: opts.ForceReplaceOnParseError,
-			})
-			if err != nil {
-				var pe *merge.ParseError
-				if errors.As(err, &pe) {
-					return fmt.Errorf(
-						"failed to merge into %s: existing %s file could not be parsed: %w. "+
-							"Re-run with --force-replace-on-parse-error to overwrite",
-						pe.Path, pe.Format, pe.Err)
-				}
-				return fmt.Errorf("failed to merge %s: %w", rf.DestPath, err)
-			}
-		case "append":
-			if manifest == nil {
-				return fmt.Errorf("append strategy requires a mold manifest with a name (dest %s)", rf.DestPath)
-			}
-			err := merge.AppendFile(rf.DestPath, outputContent, merge.AppendOptions{
-				MoldName: manifest.Name,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to append into %s: %w", rf.DestPath, err)
-			}
-		case "", "replace":
-			if err := os.MkdirAll(filepath.Dir(rf.DestPath), 0750); err != nil { // #nosec G301
-				return fmt.Errorf("failed to create directory for %s: %w", rf.DestPath, err)
-			}
-			//#nosec G306 -- Blanks need to be readable
-			if err := os.WriteFile(rf.DestPath, outputContent, 0644); err != nil {
-				return fmt.Errorf("failed to write %s: %w", rf.DestPath, err)
-			}
-		default:
-			return fmt.Errorf("unknown strategy %q on output for %s", rf.Strategy, rf.DestPath)
-		}
-
-		if !opts.Silent {
-			fmt.Println(styles.SuccessStyle.Render("✅ Created: ") + styles.CodeStyle.Render(rf.DestPath))
+		f := castRenderedFile{ResolvedFile: rf, content: contents[i]}
+		if info, err := fs.Stat(reader.FS(), rf.SrcPath); err == nil {
+			f.exec = info.Mode().Perm()&0o111 != 0
 		}
+		out = append(out, f)
 	}
-
-	return nil
+	return out, nil
 }
 
 // recordInstalled upserts the just-cast mold into the installed manifest,
@@ -953,8 +1765,9 @@ func copyResolvedFilesWithSchema(reader *blanks.MoldReader, manifest *mold.Mold,
 // installedAs is "direct" for top-level casts (the user typed `ailloy cast
 // <ref>`) and "transitive" for molds pulled in by another mold's
 // dependencies. installedBy is the list of parent source[@subpath] strings
-// for transitives — empty/ignored for direct casts.
-func recordInstalled(result *foundry.ResolveResult, global bool, opts *foundry.CastOptionsRecord, installedAs string, installedBy []string, logger *log.Logger) error {
+// for transitives — empty/ignored for direct casts. stamp is recorded so
+// status can re-render with the cast's own _ailloy values.
+func recordInstalled(result *foundry.ResolveResult, global bool, opts *foundry.CastOptionsRecord, stamp castStamp, installedAs string, installedBy []string, logger *log.Logger) error {
 	if logger == nil {
 		logger = log.Default()
 	}
@@ -990,17 +1803,27 @@ func recordInstalled(result *foundry.ResolveResult, global bool, opts *foundry.C
 		Name:        result.Ref.Repo,
 		Source:      result.Ref.CacheKey(),
 		Subpath:     result.Ref.Subpath,
+		Ref:         result.Ref.String(),
 		Version:     result.Resolved.Tag,
 		Commit:      result.Resolved.Commit,
-		CastAt:      time.Now().UTC(),
+		CastAt:      stamp.At.UTC(),
 		InstalledAs: installedAs,
 		InstalledBy: mergedBy,
+		GitBranch:   stamp.Branch,
+		GitCommit:   stamp.Commit,
+	}
+	if entry.CastAt.IsZero() {
+		entry.CastAt = time.Now().UTC()
 	}
-	if opts != nil && (opts.WithWorkflows || len(opts.ValueFiles) > 0 || len(opts.SetOverrides) > 0) {
+	if opts != nil && (opts.WithWorkflows || opts.CI != "" || len(opts.ValueFiles) > 0 || len(opts.SetOverrides) > 0 || len(opts.SetFiles) > 0 || len(opts.SetJSON) > 0 || opts.Profile != "" || len(opts.Only) > 0 || len(opts.Exclude) > 0) {
 		// Copy to detach from caller's slice ownership.
 		copied := *opts
 		copied.ValueFiles = append([]string(nil), opts.ValueFiles...)
 		copied.SetOverrides = append([]string(nil), opts.SetOverrides...)
+		copied.SetFiles = append([]string(nil), opts.SetFiles...)
+		copied.SetJSON = append([]string(nil), opts.SetJSON...)
+		copied.Only = append([]string(nil), opts.Only...)
+		copied.Exclude = append([]string(nil), opts.Exclude...)
 		entry.CastOptions = &copied
 	}
 	manifest.UpsertEntry(entry)
@@ -1014,15 +1837,15 @@ func recordInstalled(result *foundry.ResolveResult, global bool, opts *foundry.C
 // recordInstalled just wrote for monorepo foundries. opts persists the cast
 // arguments for `recast`; logger is forwarded so TUI callers can keep the
 // alt-screen clean.
-func recordCastedFiles(result *foundry.ResolveResult, files []foundry.InstalledFile, global bool, opts *foundry.CastOptionsRecord, logger *log.Logger) error {
-	return recordCastedFilesWithProvenance(result, files, global, opts, "direct", nil, logger)
+func recordCastedFiles(result *foundry.ResolveResult, files []foundry.InstalledFile, global bool, opts *foundry.CastOptionsRecord, stamp castStamp, logger *log.Logger) error {
+	return recordCastedFilesWithProvenance(result, files, global, opts, stamp, "direct", nil, logger)
 }
 
 // recordCastedFilesWithProvenance is recordCastedFiles with explicit
 // InstalledAs / InstalledBy plumbing for transitive mold deps. The default
 // recordCastedFiles call sets installedAs="direct" and no installedBy.
-func recordCastedFilesWithProvenance(result *foundry.ResolveResult, files []foundry.InstalledFile, global bool, opts *foundry.CastOptionsRecord, installedAs string, installedBy []string, logger *log.Logger) error {
-	if err := recordInstalled(result, global, opts, installedAs, installedBy, logger); err != nil {
+func recordCastedFilesWithProvenance(result *foundry.ResolveResult, files []foundry.InstalledFile, global bool, opts *foundry.CastOptionsRecord, stamp castStamp, installedAs string, installedBy []string, logger *log.Logger) error {
+	if err := recordInstalled(result, global, opts, stamp, installedAs, installedBy, logger); err != nil {
 		return err
 	}
 	path := manifestPathFor(global)
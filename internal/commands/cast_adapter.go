@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+)
+
+// castAdapterName returns the output adapter selected by --to or one of its
+// shorthands (--cursor-rules, --opencode, --codex), or "" for none.
+func castAdapterName() string {
+	switch {
+	case castTo != "":
+		return castTo
+	case castCursorRulesFlag:
+		return plugin.FormatCursor
+	case castOpenCodeFlag:
+		return plugin.FormatOpenCode
+	case castCodexFlag:
+		return plugin.FormatCodex
+	}
+	return ""
+}
+
+// castWithAdapter is the CLI entrypoint for `ailloy cast --to <adapter>`.
+// It renders the mold through the same pipeline as --claude-skills and runs
+// the result through the adapter's hooks (see blanks.OutputAdapter), writing
+// into the project, or the tool's user directory with --global.
+func castWithAdapter(reader *blanks.MoldReader, source string, adapter blanks.OutputAdapter) error {
+	return adaptMold(reader, source, adapter, castValFiles, castFluxSets(), castGlobal, castResolveOpts(castGlobal))
+}
+
+// adaptMold renders the mold with flux layered from valFiles and sets, and
+// writes it through adapter. Shared by cast --to and ailloy.yaml's to:.
+func adaptMold(reader *blanks.MoldReader, source string, adapter blanks.OutputAdapter, valFiles []string, sets fluxSets, global bool, resolveOpts []foundry.ResolveOption) error {
+	logging.Decor(styles.WorkingBanner(fmt.Sprintf("Converting Ailloy mold into %s...", blanks.AdapterTitle(adapter))), "")
+
+	flux, _, err := layerFluxForCore(reader, source, valFiles, sets, global, resolveOpts)
+	if err != nil {
+		return fluxLoadError(err)
+	}
+
+	manifest, err := reader.LoadManifest()
+	if err != nil {
+		return fmt.Errorf("loading mold manifest: %w", err)
+	}
+	rendered, err := renderMoldFiles(reader, manifest, flux, log.Default())
+	if err != nil {
+		return err
+	}
+
+	scope := blanks.ScopeProject
+	if global {
+		scope = blanks.ScopeGlobal
+	}
+	written, err := blanks.Adapt(adapter, rendered, blanks.AdaptOptions{Root: ".", Scope: scope, MoldName: manifest.Name})
+	if err != nil {
+		return err
+	}
+	if len(written) == 0 {
+		return fmt.Errorf("mold has no blanks to convert into %s", blanks.AdapterTitle(adapter))
+	}
+
+	for _, f := range written {
+		var verb string
+		switch f.Action {
+		case blanks.ActionKept:
+			continue
+		case blanks.ActionCreated:
+			verb = "Created"
+		case blanks.ActionUpdated:
+			verb = "Updated"
+		default:
+			verb = "Wrote"
+		}
+		logging.Say(styles.SuccessStyle.Render("✅ "+verb+" ")+styles.CodeStyle.Render(f.Path), strings.ToLower(verb), "path", f.Path)
+	}
+	if h, ok := adapter.(blanks.HintAdapter); ok {
+		logging.Decor("", styles.InfoStyle.Render("💡 "+h.Hint()))
+	}
+	return nil
+}
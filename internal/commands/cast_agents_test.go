@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func agentsMoldReader(body string) *blanks.MoldReader {
+	return blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: agents-mold\nversion: 1.0.0\n")},
+		"AGENTS.md": &fstest.MapFile{Data: []byte(body)},
+	})
+}
+
+func TestCastProject_AgentsMDKeepsProjectContent(t *testing.T) {
+	resetCastFlags()
+	defer resetCastFlags()
+	chdir(t, t.TempDir())
+	mustWrite(t, "AGENTS.md", "# Project rules\n\nUse tabs.\n")
+
+	if err := castProject(agentsMoldReader("# Mold v1\n"), ""); err != nil {
+		t.Fatalf("castProject: %v", err)
+	}
+	if err := castProject(agentsMoldReader("# Mold v2\n"), ""); err != nil {
+		t.Fatalf("castProject: %v", err)
+	}
+	got, _ := os.ReadFile("AGENTS.md")
+	want := "# Project rules\n\nUse tabs.\n\n<!-- ailloy:mold=agents-mold:start -->\n# Mold v2\n<!-- ailloy:mold=agents-mold:end -->\n"
+	if string(got) != want {
+		t.Errorf("AGENTS.md =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCastProject_AgentsMDReplacesWholeFileCast(t *testing.T) {
+	resetCastFlags()
+	defer resetCastFlags()
+	chdir(t, t.TempDir())
+
+	// An older cast wrote AGENTS.md whole and recorded its hash.
+	mustWrite(t, "AGENTS.md", "# Mold v1\n")
+	old := installedBlank{Dest: "AGENTS.md", Mold: "agents-mold", SrcPath: "AGENTS.md", SHA256: hashBytes([]byte("# Mold v1\n"))}
+	if err := writeInstallState(nil, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := castProject(agentsMoldReader("# Mold v2\n"), ""); err != nil {
+		t.Fatalf("castProject: %v", err)
+	}
+	got, _ := os.ReadFile("AGENTS.md")
+	if strings.Contains(string(got), "# Mold v1") || !strings.HasPrefix(string(got), "<!-- ailloy:mold=agents-mold:start -->\n# Mold v2\n") {
+		t.Errorf("AGENTS.md =\n%s\nwant only the mold's v2 section", got)
+	}
+}
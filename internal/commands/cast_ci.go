@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// castCISystem picks the CI system whose workflow blanks a cast includes:
+// ci when named (naming one implies workflows), otherwise — when workflows
+// are on — the one detected in dir, falling back to GitHub Actions. ""
+// means the cast leaves all workflow blanks out.
+func castCISystem(withWorkflows bool, ci, dir string) (string, error) {
+	if ci != "" {
+		if _, ok := mold.LookupCI(ci); !ok {
+			return "", fmt.Errorf("unknown --ci %q (want one of %s)", ci, strings.Join(mold.CINames(), ", "))
+		}
+		return ci, nil
+	}
+	if !withWorkflows {
+		return "", nil
+	}
+	if dir == "" {
+		dir = "."
+	}
+	if s, ok := mold.DetectCI(dir); ok {
+		return s.Name, nil
+	}
+	return mold.CIGitHub, nil
+}
+
+// addGitLabIncludes lists the GitLab CI files a cast wrote under .gitlab/ci/
+// in root's .gitlab-ci.yml, creating it if needed, and returns whether the
+// file changed. A mold that casts .gitlab-ci.yml itself owns it and is left
+// alone.
+func addGitLabIncludes(root string, files []mold.ResolvedFile) (bool, error) {
+	if root == "" {
+		root = "."
+	}
+	var includes []string
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f.DestPath)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".gitlab-ci.yml" {
+			return false, nil
+		}
+		ext := filepath.Ext(rel)
+		if !strings.HasPrefix(rel, mold.GitLabIncludeDir) || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		if _, err := os.Stat(f.DestPath); err != nil {
+			continue // rendered empty, not written
+		}
+		includes = append(includes, rel)
+	}
+	if len(includes) == 0 {
+		return false, nil
+	}
+	sort.Strings(includes)
+
+	configPath := filepath.Join(root, ".gitlab-ci.yml")
+	config, err := os.ReadFile(configPath) // #nosec G304 -- project CI config
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	updated, changed, err := mold.GitLabIncludes(config, includes)
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := os.WriteFile(configPath, updated, 0o644); err != nil { // #nosec G306 -- CI config is committed to the repo
+		return false, fmt.Errorf("writing %s: %w", configPath, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCastMold_CISystem(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       CastOptions
+		existingCI string // .gitlab-ci.yml before the cast, "" for none
+		wantGitHub bool
+		wantGitLab bool
+	}{
+		{name: "no workflows", opts: CastOptions{}},
+		{name: "workflows default to github", opts: CastOptions{WithWorkflows: true}, wantGitHub: true},
+		{name: "workflows detect gitlab", opts: CastOptions{WithWorkflows: true}, existingCI: "stages: [test]\n", wantGitLab: true},
+		{name: "ci names gitlab", opts: CastOptions{CI: "gitlab"}, wantGitLab: true},
+		{name: "ci names circle", opts: CastOptions{CI: "circle"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			t.Chdir(projectDir)
+			t.Setenv("HOME", t.TempDir())
+			// A mold shipping workflow blanks for GitHub Actions and GitLab CI.
+			moldDir := t.TempDir()
+			for name, content := range map[string]string{
+				"mold.yaml":         "apiVersion: v1\nkind: Mold\nname: ci\nversion: 0.1.0\n",
+				"flux.yaml":         "output:\n  github: .github/workflows\n  gitlab: .gitlab/ci\n  commands: .claude/commands\n",
+				"github/lint.yml":   "name: lint\n",
+				"gitlab/lint.yml":   "lint:\n  script: make lint\n",
+				"commands/hello.md": "# hello\n",
+			} {
+				mustWrite(t, filepath.Join(moldDir, name), content)
+			}
+			if tt.existingCI != "" {
+				if err := os.WriteFile(".gitlab-ci.yml", []byte(tt.existingCI), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := CastMold(t.Context(), moldDir, tt.opts); err != nil {
+				t.Fatalf("CastMold: %v", err)
+			}
+
+			if _, err := os.Stat(".claude/commands/hello.md"); err != nil {
+				t.Errorf("command blank not cast: %v", err)
+			}
+			_, err := os.Stat(".github/workflows/lint.yml")
+			if got := err == nil; got != tt.wantGitHub {
+				t.Errorf("GitHub workflow cast = %v, want %v", got, tt.wantGitHub)
+			}
+			_, err = os.Stat(".gitlab/ci/lint.yml")
+			if got := err == nil; got != tt.wantGitLab {
+				t.Errorf("GitLab CI file cast = %v, want %v", got, tt.wantGitLab)
+			}
+			config, _ := os.ReadFile(".gitlab-ci.yml")
+			if got := strings.Contains(string(config), "- local: /.gitlab/ci/lint.yml"); got != tt.wantGitLab {
+				t.Errorf(".gitlab-ci.yml includes lint.yml = %v, want %v:\n%s", got, tt.wantGitLab, config)
+			}
+			if tt.existingCI != "" && !strings.Contains(string(config), tt.existingCI) {
+				t.Errorf(".gitlab-ci.yml lost its existing content:\n%s", config)
+			}
+		})
+	}
+}
+
+func TestCastMold_UnknownCI(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	_, err := CastMold(t.Context(), fluxCastMold(t), CastOptions{CI: "jenkins"})
+	if err == nil || !strings.Contains(err.Error(), `unknown --ci "jenkins"`) {
+		t.Fatalf("CastMold error = %v, want unknown --ci", err)
+	}
+}
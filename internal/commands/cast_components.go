@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// castPickComponents asks which of the mold's components to cast, when it
+// declares any, neither --only nor --exclude was given and there is a
+// terminal to ask on. Components start selected unless the mold's last cast
+// excluded them. The unselected ones become castExclude, which the installed
+// manifest records so recast and the next cast respect them.
+func castPickComponents(manifest *mold.Mold) error {
+	if len(manifest.Components) == 0 || len(castOnly) > 0 || len(castExclude) > 0 || !isInteractive() {
+		return nil
+	}
+	previous := previousCastExclude()
+	names := make([]string, 0, len(manifest.Components))
+	for name := range manifest.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	selected := preselectedComponents(names, previous)
+	options := make([]huh.Option[string], 0, len(names))
+	for _, name := range names {
+		label := fmt.Sprintf("%s (%s)", name, strings.Join(manifest.Components[name], ", "))
+		options = append(options, huh.NewOption(label, name))
+	}
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title(fmt.Sprintf("Which components of %s should be cast?", manifest.Name)).
+				Description("Blanks outside every component are always cast.").
+				Options(options...).
+				Value(&selected),
+		),
+	).WithTheme(ailloyTheme())
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("prompt failed: %w", err)
+	}
+	castExclude = componentExclusions(names, selected, previous)
+	return nil
+}
+
+// previousCastExclude returns the --exclude entries recorded for the last
+// cast of the remote mold being cast, if any.
+func previousCastExclude() []string {
+	if resolvedRemote == nil {
+		return nil
+	}
+	installed, err := foundry.ReadInstalledManifest(manifestPathFor(castGlobal))
+	if err != nil {
+		return nil
+	}
+	entry := installed.FindBySource(resolvedRemote.Ref.CacheKey(), resolvedRemote.Ref.Subpath)
+	if entry == nil || entry.CastOptions == nil {
+		return nil
+	}
+	return entry.CastOptions.Exclude
+}
+
+// preselectedComponents returns the components not excluded last time.
+func preselectedComponents(names, previous []string) []string {
+	var selected []string
+	for _, name := range names {
+		if !slices.Contains(previous, name) {
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
+
+// componentExclusions returns the exclude list for a picker selection: the
+// unselected components, after any recorded entries that aren't component
+// names (patterns from an earlier --exclude), which the picker doesn't show.
+func componentExclusions(names, selected, previous []string) []string {
+	var exclude []string
+	for _, e := range previous {
+		if !slices.Contains(names, e) {
+			exclude = append(exclude, e)
+		}
+	}
+	for _, name := range names {
+		if !slices.Contains(selected, name) {
+			exclude = append(exclude, name)
+		}
+	}
+	return exclude
+}
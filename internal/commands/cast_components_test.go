@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestComponentPickerSelection(t *testing.T) {
+	names := []string{"release", "review", "triage"}
+	previous := []string{"agents/", "review"}
+
+	selected := preselectedComponents(names, previous)
+	if want := []string{"release", "triage"}; !slices.Equal(selected, want) {
+		t.Errorf("preselected = %v, want %v", selected, want)
+	}
+
+	// Deselecting triage keeps the recorded pattern and drops the
+	// re-selected review from the exclusions.
+	got := componentExclusions(names, []string{"release", "review"}, previous)
+	if want := []string{"agents/", "triage"}; !slices.Equal(got, want) {
+		t.Errorf("exclusions = %v, want %v", got, want)
+	}
+	if got := componentExclusions(names, names, nil); len(got) != 0 {
+		t.Errorf("selecting everything excludes %v", got)
+	}
+}
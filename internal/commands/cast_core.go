@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"dario.cat/mergo"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/mold"
@@ -17,20 +22,43 @@ import (
 // CastOptions configures a CastMold call. All fields are optional.
 type CastOptions struct {
 	Global        bool     // install under $HOME instead of cwd
-	WithWorkflows bool     // include .github/ workflow blanks
+	WithWorkflows bool     // include workflow blanks for the detected CI system
+	CI            string   // include workflow blanks for this CI system (see mold.CINames)
 	ValueFiles    []string // -f layered flux value files
 	SetOverrides  []string // --set key=val overrides
+	SetFiles      []string // --set-file key=path overrides
+	SetJSON       []string // --set-json key=json overrides
 	// ForceReplaceOnParseError, when true, allows merge-strategy
 	// destinations whose existing on-disk file is unparseable to be
 	// replaced rather than erroring. Mirrors the
 	// --force-replace-on-parse-error CLI flag.
 	ForceReplaceOnParseError bool
+	// Offline resolves a remote ref from the local cache only, failing
+	// instead of touching the network (see foundry.WithOffline).
+	Offline bool
 	// Frozen, when true, makes cast fail (not auto-install) on any declared
 	// ingot/ore dep that is missing from .ailloy/. Intended for CI: a typo
 	// or unpinned bump in mold.yaml becomes a loud error rather than a
 	// silent network fetch + manifest/lock mutation.
-	Frozen     bool
+	Frozen bool
+	// Profile selects one of the mold's output profiles (see
+	// mold.ApplyOutputProfile). Empty falls back to the config default.
+	Profile string
+	// Only and Exclude cast part of the mold (see mold.Selection).
+	Only       []string
+	Exclude    []string
 	OnProgress func(stage, item string)
+	// Hooks names the mold hook stage to run before rendering
+	// (mold.HookPreCast or mold.HookPreUpgrade); post-cast hooks then run
+	// after it. Empty runs no hooks: they write to the terminal and may
+	// prompt for approval, which the TUI and MCP callers can't host.
+	Hooks string
+	// KeepLocalEdits keeps files whose provenance header shows they were
+	// edited since the last cast, instead of overwriting them, or merges
+	// the new render into them when MergeBases holds the render they were
+	// cast from (by destination). CastResult lists which.
+	KeepLocalEdits bool
+	MergeBases     map[string][]byte
 
 	// ClaudePlugin packages the rendered mold as a Claude Code plugin under
 	// .claude/plugins/<slug>/ (or ~/.claude/plugins/<slug>/ when Global is set)
@@ -39,6 +67,38 @@ type CastOptions struct {
 	ClaudePlugin  bool
 	PluginName    string
 	PluginVersion string
+
+	// HistoryOp names the operation recorded in .ailloy/history.log
+	// ("cast" when empty) and HistoryFlags the CLI flags it ran with.
+	HistoryOp    string
+	HistoryFlags []string
+}
+
+// record returns the options a cast records for recast and rollback to
+// replay.
+func (o CastOptions) record() *foundry.CastOptionsRecord {
+	return &foundry.CastOptionsRecord{
+		WithWorkflows: o.WithWorkflows,
+		ValueFiles:    recordedValueFiles(o.ValueFiles),
+		SetOverrides:  o.SetOverrides,
+		SetFiles:      o.SetFiles,
+		SetJSON:       o.SetJSON,
+		Profile:       o.Profile,
+		Only:          o.Only,
+		Exclude:       o.Exclude,
+		CI:            o.CI,
+	}
+}
+
+// fluxSets returns the --set, --set-file and --set-json overrides.
+func (o CastOptions) fluxSets() fluxSets {
+	return fluxSets{Set: o.SetOverrides, Files: o.SetFiles, JSON: o.SetJSON}
+}
+
+// recordedFluxSets returns the --set, --set-file and --set-json overrides
+// a cast recorded.
+func recordedFluxSets(rec foundry.CastOptionsRecord) fluxSets {
+	return fluxSets{Set: rec.SetOverrides, Files: rec.SetFiles, JSON: rec.SetJSON}
 }
 
 // CastResult summarizes a CastMold call for programmatic consumers.
@@ -48,6 +108,9 @@ type CastResult struct {
 	FilesCast  []foundry.InstalledFile // files installed (with sha256 hashes)
 	Dirs       []string                // unique parent directories created
 	GlobalRoot string                  // populated when Global=true
+	Merged     []string                // locally edited files the new render was merged into
+	Kept       []string                // locally edited files left as they were
+	Backup     string                  // id of the snapshot of overwritten files, if one was taken
 }
 
 // CastMold performs the cast install pipeline as a callable function with
@@ -65,7 +128,7 @@ func CastMold(_ context.Context, ref string, opts CastOptions) (CastResult, erro
 
 	silentLogger := log.New(io.Discard, "", 0)
 
-	reader, remoteResult, err := openMoldReaderForCore(ref, opts.Global, silentLogger)
+	reader, remoteResult, err := openMoldReaderForCore(ref, opts.Global, opts.Offline, silentLogger)
 	if err != nil {
 		return res, err
 	}
@@ -100,10 +163,13 @@ func CastMold(_ context.Context, ref string, opts CastOptions) (CastResult, erro
 		return res, fmt.Errorf("installing declared dependencies: %w", err)
 	}
 
-	flux, mergedSchema, err := layerFluxForCore(reader, source, opts.ValueFiles, opts.SetOverrides, opts.Global)
+	flux, mergedSchema, err := layerFluxForCore(reader, source, opts.ValueFiles, opts.fluxSets(), opts.Global, coreResolveOpts(opts.Global, opts.Offline, silentLogger))
 	if err != nil {
 		return res, err
 	}
+	if _, err := selectOutputProfile(flux, manifest, opts.Profile); err != nil {
+		return res, err
+	}
 
 	if opts.ClaudePlugin {
 		pluginRes, perr := packageMoldAsClaudePlugin(reader, flux, pluginPackageOpts{
@@ -121,18 +187,15 @@ func CastMold(_ context.Context, ref string, opts CastOptions) (CastResult, erro
 			home, _ := os.UserHomeDir()
 			res.GlobalRoot = home
 		}
+		recordCastHistory(opts, manifest, remoteResult, source, []string{installedRelPath(res.GlobalRoot, pluginRes.TargetDir) + "/"}, silentLogger)
 		return res, nil
 	}
 
-	destPrefix := ""
-	if opts.Global {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return res, fmt.Errorf("cannot determine home directory: %w", err)
-		}
-		destPrefix = home
-		res.GlobalRoot = home
+	destPrefix, err := globalDestPrefix(opts.Global)
+	if err != nil {
+		return res, err
 	}
+	res.GlobalRoot = destPrefix
 
 	ignore := mold.LoadIgnorePatterns(reader.FS(), manifest)
 	var resolveOpts []mold.ResolveOption
@@ -144,15 +207,24 @@ func CastMold(_ context.Context, ref string, opts CastOptions) (CastResult, erro
 	if err != nil {
 		return res, fmt.Errorf("resolving output files: %w", err)
 	}
+	if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+		return res, err
+	}
+	selection := mold.Selection{Only: opts.Only, Exclude: opts.Exclude}
+	if resolved, err = selection.Select(resolved, manifest.Components); err != nil {
+		return res, err
+	}
 
+	ci, err := castCISystem(opts.WithWorkflows, opts.CI, destPrefix)
+	if err != nil {
+		return res, err
+	}
 	var filesToCast []mold.ResolvedFile
 	for _, rf := range resolved {
-		if !opts.WithWorkflows && strings.HasPrefix(rf.DestPath, ".github/") {
+		if mold.SkipCIFile(rf.DestPath, ci) {
 			continue
 		}
-		if destPrefix != "" {
-			rf.DestPath = filepath.Join(destPrefix, rf.DestPath)
-		}
+		rf.DestPath = castDest(destPrefix, rf.DestPath)
 		filesToCast = append(filesToCast, rf)
 	}
 
@@ -166,6 +238,17 @@ func CastMold(_ context.Context, ref string, opts CastOptions) (CastResult, erro
 	}
 	res.Dirs = dirs
 
+	hookKey := execConsentKey(source, manifest)
+	hookDir := destPrefix
+	if hookDir == "" {
+		hookDir = "."
+	}
+	if opts.Hooks != "" {
+		if err := runMoldHooks(opts.Hooks, reader.FS(), manifest, flux, hookKey, hookDir); err != nil {
+			return res, err
+		}
+	}
+
 	for i, dir := range dirs {
 		if opts.OnProgress != nil {
 			opts.OnProgress(fmt.Sprintf("mkdir %d/%d", i+1, len(dirs)), dir)
@@ -175,22 +258,49 @@ func CastMold(_ context.Context, ref string, opts CastOptions) (CastResult, erro
 		}
 	}
 
+	renderHashes := map[string]string{}
+	var edits *localEdits
+	if opts.KeepLocalEdits {
+		edits = &localEdits{bases: opts.MergeBases}
+	}
 	if err := copyResolvedFilesWithSchema(reader, manifest, mergedSchema, flux, filesToCast, copyOpts{
 		ForceReplaceOnParseError: opts.ForceReplaceOnParseError,
 		Silent:                   true,
 		Logger:                   silentLogger,
+		RenderHashes:             renderHashes,
+		PreviousHashes:           previousCastHashes(manifest.Name),
+		Provenance:               true,
+		Edits:                    edits,
+		Backup:                   &backupScope{DestPrefix: destPrefix, Op: historyOp(opts), Mold: manifest.Name},
+		BackupID:                 &res.Backup,
+		FluxSources:              valuesFluxSources(append(mold.PersistedFluxPaths(source), opts.ValueFiles...), opts.fluxSets().all()),
 	}); err != nil {
 		return res, fmt.Errorf("copying files: %w", err)
 	}
+	if edits != nil {
+		sort.Strings(edits.merged)
+		sort.Strings(edits.kept)
+		res.Merged, res.Kept = edits.merged, edits.kept
+	}
 
 	// Drop directories that ended up empty after skipped renders (#145, #195).
 	dirs = cleanupEmptyDirs(dirs, destPrefix)
 	res.Dirs = dirs
 
+	if ci == mold.CIGitLab {
+		if _, err := addGitLabIncludes(destPrefix, filesToCast); err != nil {
+			silentLogger.Printf("warning: failed to add includes to .gitlab-ci.yml: %v", err)
+		}
+	}
+
 	// Mirror what cast.go does: record install dirs in .ailloy/state.yaml so
 	// `mold list` can find blanks installed via the foundries TUI.
 	if destPrefix == "" {
-		if err := writeInstallState(dirs); err != nil {
+		version := ""
+		if remoteResult != nil {
+			version = remoteResult.Resolved.Tag
+		}
+		if err := writeInstallState(dirs, installedBlanksFor(manifest, source, version, filesToCast)...); err != nil {
 			silentLogger.Printf("warning: failed to write install state: %v", err)
 		}
 	}
@@ -199,74 +309,152 @@ func CastMold(_ context.Context, ref string, opts CastOptions) (CastResult, erro
 		installed := make([]foundry.InstalledFile, 0, len(filesToCast))
 		for _, f := range filesToCast {
 			sum, _ := hashFile(f.DestPath)
-			rel := f.DestPath
-			if destPrefix != "" {
-				if r, rerr := filepath.Rel(destPrefix, f.DestPath); rerr == nil {
-					rel = r
-				}
-			}
-			installed = append(installed, foundry.InstalledFile{RelPath: rel, SHA256: sum})
+			installed = append(installed, foundry.InstalledFile{RelPath: installedRelPath(destPrefix, f.DestPath), SHA256: sum, RenderSHA256: renderHashes[f.DestPath]})
 		}
 		res.FilesCast = installed
-		castOpts := &foundry.CastOptionsRecord{
-			WithWorkflows: opts.WithWorkflows,
-			ValueFiles:    opts.ValueFiles,
-			SetOverrides:  opts.SetOverrides,
-		}
-		if err := recordCastedFiles(remoteResult, installed, opts.Global, castOpts, silentLogger); err != nil {
+		if err := recordCastedFiles(remoteResult, installed, opts.Global, opts.record(), castStampOf(flux), silentLogger); err != nil {
 			silentLogger.Printf("warning: failed to record installed files: %v", err)
 		}
 	}
 
+	dests := make([]string, len(filesToCast))
+	for i, f := range filesToCast {
+		dests[i] = f.DestPath
+	}
+	recordCastHistory(opts, manifest, remoteResult, source, castedHistoryFiles(destPrefix, dests), silentLogger)
+
+	if opts.Hooks != "" {
+		if err := runMoldHooks(mold.HookPostCast, reader.FS(), manifest, flux, hookKey, hookDir); err != nil {
+			return res, err
+		}
+	}
+
 	return res, nil
 }
 
+// historyOp is the operation a CastMold call is recorded as.
+func historyOp(opts CastOptions) string {
+	if opts.HistoryOp == "" {
+		return "cast"
+	}
+	return opts.HistoryOp
+}
+
+// recordCastHistory appends a CastMold run to the history log.
+func recordCastHistory(opts CastOptions, manifest *mold.Mold, remote *foundry.ResolveResult, source string, files []string, logger *log.Logger) {
+	e := historyEntry{Op: historyOp(opts), Source: source, Files: files, Flags: opts.HistoryFlags, Global: opts.Global, Options: opts.record()}
+	if manifest != nil {
+		e.Mold, e.Version = manifest.Name, manifest.Version
+	}
+	if remote != nil {
+		e.Version, e.Commit = remote.Resolved.Tag, remote.Resolved.Commit
+	}
+	recordHistory(e, logger)
+}
+
 // openMoldReaderForCore is the CastMold-flavored counterpart to
 // resolveMoldReader; it accepts a single ref string instead of args.
 // The returned *foundry.ResolveResult is populated for remote refs (so the
 // caller can record provenance in the installed manifest) and nil for local
 // refs.
-func openMoldReaderForCore(ref string, global bool, logger *log.Logger) (*blanks.MoldReader, *foundry.ResolveResult, error) {
+func openMoldReaderForCore(ref string, global, offline bool, logger *log.Logger) (*blanks.MoldReader, *foundry.ResolveResult, error) {
 	if ref == "" {
 		return nil, nil, fmt.Errorf("ref required")
 	}
 	if foundry.IsRemoteReference(ref) {
-		resolveOpts := []foundry.ResolveOption{}
-		if logger != nil {
-			resolveOpts = append(resolveOpts, foundry.WithLogger(logger))
-		}
-		if global {
-			resolveOpts = append(resolveOpts, foundry.WithLockPath(globalLockPath()))
-		}
+		resolveOpts := coreResolveOpts(global, offline, logger)
 		fsys, result, err := foundry.ResolveWithMetadata(ref, resolveOpts...)
 		if err != nil {
-			return nil, nil, fmt.Errorf("resolving remote mold: %w", err)
+			return nil, nil, resolutionError(fmt.Errorf("resolving remote mold: %w", err))
 		}
-		return blanks.NewMoldReaderFromFS(fsys, result.Root), result, nil
+		reader, err := ComposeMoldReader(blanks.NewMoldReaderFromFS(fsys, result.Root), result.Ref.OverrideKey(), resolveOpts...)
+		return reader, result, err
 	}
 	reader, err := blanks.NewMoldReaderFromPath(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err = ComposeMoldReader(reader, "")
 	return reader, nil, err
 }
 
-// layerFluxForCore mirrors loadCastFlux but is parameterized so CastMold
-// doesn't depend on package-level cast flag vars. `source` is the resolved
-// mold ref used to pick up persisted flux files (~/.ailloy/flux/<slug>.yaml
-// then ./.ailloy/flux/<slug>.yaml). Empty source skips persisted-file lookup.
+// layerFluxForCore is loadCastFlux parameterized so CastMold doesn't depend
+// on package-level cast flag vars. `source` is the resolved mold ref used to
+// pick up persisted flux files (~/.ailloy/flux/<slug>.yaml then
+// ./.ailloy/flux/<slug>.yaml). Empty source skips persisted-file lookup.
 //
 // Returns the layered flux map plus the merged schema (mold + ore overlays);
 // callers thread the schema into copyResolvedFilesWithSchema so ValidateFlux
 // sees ore.<name>.* entries.
-func layerFluxForCore(reader *blanks.MoldReader, source string, valueFiles, setOverrides []string, global bool) (map[string]any, []mold.FluxVar, error) {
+func layerFluxForCore(reader *blanks.MoldReader, source string, valueFiles []string, sets fluxSets, global bool, resolveOpts []foundry.ResolveOption) (map[string]any, []mold.FluxVar, error) {
+	return layerFlux(reader, source, valueFiles, sets, global, resolveOpts, nil)
+}
+
+// coreResolveOpts returns the foundry resolve options for a CastMold-style
+// call: the global lock for global, and offline resolution.
+func coreResolveOpts(global, offline bool, logger *log.Logger) []foundry.ResolveOption {
+	resolveOpts := []foundry.ResolveOption{}
+	if logger != nil {
+		resolveOpts = append(resolveOpts, foundry.WithLogger(logger))
+	}
+	if global {
+		resolveOpts = append(resolveOpts, foundry.WithLockPath(globalLockPath()))
+	}
+	if offline {
+		resolveOpts = append(resolveOpts, foundry.WithOffline())
+	}
+	return resolveOpts
+}
+
+// valuesOptions returns how layering reads remote -f files for a cast
+// resolving its mold with resolveOpts: git:: files resolve the same way
+// (lock, --offline, cache policy), and offline, HTTPS ones are refused.
+func valuesOptions(resolveOpts []foundry.ResolveOption) []mold.ValuesOption {
+	opts := []mold.ValuesOption{mold.WithValuesResolver(func(ref string) (fs.FS, error) {
+		fsys, _, err := foundry.ResolveWithMetadata(ref, resolveOpts...)
+		return fsys, err
+	})}
+	if foundry.IsOffline(resolveOpts...) {
+		opts = append(opts, mold.WithOfflineValues())
+	}
+	return opts
+}
+
+// valuesFluxSources records which of the values files at paths, or which
+// --set flag, each value came from. Layering has already reported any file
+// that doesn't load, so such files just go unrecorded.
+func valuesFluxSources(paths, sets []string) mold.FluxSources {
+	_, sources, err := mold.LayerFluxFilesWithSources(paths)
+	if err != nil {
+		sources = mold.FluxSources{}
+	}
+	sources.AddSets(sets)
+	return sources
+}
+
+// layerFlux runs the flux layering shared by cast, the SDK and explain.
+// Remote -f files resolve with resolveOpts (see valuesOptions). A non-nil
+// trace records which layer set each value (see fluxTrace).
+func layerFlux(reader *blanks.MoldReader, source string, valueFiles []string, sets fluxSets, global bool, resolveOpts []foundry.ResolveOption, trace fluxTrace) (map[string]any, []mold.FluxVar, error) {
+	if trace != nil {
+		base, _ := reader.LoadFluxDefaults()
+		trace.record(base, fluxOrigin{Layer: fluxLayerMold})
+	}
 	mergedSchema, defaults, _, err := mold.LoadMoldFluxWithOres(reader.FS(), readerSearchPaths(reader, global))
 	if err != nil {
-		// Fall back to the legacy single-mold path.
+		// Fall back to the legacy single-mold path so an ore-loading hiccup
+		// doesn't break ore-less casts.
 		defaults, err = reader.LoadFluxDefaults()
 		if err != nil {
 			defaults = make(map[string]any)
 		}
 	}
-	// Merge mold.yaml's in-line flux: schema in for molds that declare their
-	// schema inline rather than via a separate flux.schema.yaml.
+	trace.record(defaults, fluxOrigin{Layer: fluxLayerOre})
+	defaults = withSecretFlux(reader, defaults)
+	trace.record(defaults, fluxOrigin{Layer: fluxLayerSecret})
+	// Merge mold.yaml's in-line flux: schema in. LoadMoldFluxWithOres only
+	// reads the standalone flux.schema.yaml file; molds that declare their
+	// schema inline (no flux.schema.yaml on disk) still need their defaults.
 	manifest, _ := reader.LoadManifest()
 	if manifest != nil && len(manifest.Flux) > 0 {
 		defaults = mold.ApplyFluxDefaults(manifest.Flux, defaults)
@@ -274,31 +462,143 @@ func layerFluxForCore(reader *blanks.MoldReader, source string, valueFiles, setO
 			mergedSchema = manifest.Flux
 		}
 	}
+	trace.record(defaults, fluxOrigin{Layer: fluxLayerSchema})
+
 	flux := make(map[string]any, len(defaults))
 	for k, v := range defaults {
 		flux[k] = v
 	}
 	mold.ApplyManifestOutputDefault(flux, manifest)
+	trace.record(flux, fluxOrigin{Layer: fluxLayerOutput})
+	if !global {
+		flux = withDetectedRepo(mergedSchema, flux)
+		trace.record(flux, fluxOrigin{Layer: fluxLayerGitRemote})
+	}
+
+	// Persisted flux files written by the foundries TUI (system, global,
+	// workspace, then project — more specific wins on conflict). Layered
+	// before user-supplied -f so explicit -f still overrides saved values.
 	if persisted := mold.PersistedFluxPaths(source); len(persisted) > 0 {
 		overlay, perr := mold.LayerFluxFiles(persisted)
 		if perr != nil {
-			return nil, nil, perr
+			return nil, nil, configError(perr)
 		}
 		for k, v := range overlay {
 			flux[k] = v
 		}
+		trace.recordFiles(flux, persisted, persistedFluxLayer)
 	}
+
+	// -f files left-to-right (each overrides previous).
 	if len(valueFiles) > 0 {
-		overlay, lerr := mold.LayerFluxFiles(valueFiles)
+		overlay, lerr := mold.LayerFluxFiles(valueFiles, valuesOptions(resolveOpts)...)
 		if lerr != nil {
-			return nil, nil, lerr
+			return nil, nil, configError(lerr)
 		}
 		for k, v := range overlay {
 			flux[k] = v
 		}
+		trace.recordFiles(flux, valueFiles, func(string) string { return fluxLayerValues })
 	}
-	if err := mold.ApplySetOverrides(flux, setOverrides); err != nil {
-		return nil, nil, err
+
+	// --set, --set-file and --set-json overrides (highest precedence).
+	if err := sets.apply(flux); err != nil {
+		return nil, nil, usageError(err)
 	}
+	trace.recordSets(flux, sets.all())
+
+	flux = withCastContext(flux, manifest, source, global)
+	trace.record(flux, fluxOrigin{Layer: fluxLayerContext})
 	return flux, mergedSchema, nil
 }
+
+// withSecretFlux deep-merges the mold's flux.secret.yaml, decrypted with
+// sops, over its defaults. A file that can't be decrypted is skipped with a
+// warning naming the sealed variables, so the cast goes on without them and
+// schema validation reports any that are required.
+func withSecretFlux(reader *blanks.MoldReader, defaults map[string]any) map[string]any {
+	secret, err := mold.LoadSecretFlux(reader.FS())
+	if err != nil {
+		slog.Warn("skipping "+mold.SecretFluxFile, "error", err)
+		return defaults
+	}
+	if len(secret) > 0 {
+		_ = mergo.Merge(&defaults, secret, mergo.WithOverride)
+	}
+	return defaults
+}
+
+// withDetectedRepo pre-fills the host, organization, repository name, and
+// default branch of the current directory's git origin as flux defaults
+// (see mold.ApplyRepoDefaults). Global casts have no project repository
+// and skip it.
+func withDetectedRepo(schema []mold.FluxVar, flux map[string]any) map[string]any {
+	info, ok := mold.DetectRepo(".")
+	if !ok {
+		return flux
+	}
+	slog.Debug("pre-filling flux from git remote", "host", info.Host, "owner", info.Owner, "repo", info.Name, "branch", info.DefaultBranch)
+	return mold.ApplyRepoDefaults(schema, flux, info)
+}
+
+// castStamp holds the _ailloy values that change from one cast to the
+// next while the mold stays the same: when the cast ran and the project's
+// branch and HEAD commit. Cast records them on the installed entry, and
+// status pins them so a blank that prints them doesn't read as outdated.
+type castStamp struct {
+	At     time.Time
+	Branch string
+	Commit string
+}
+
+// castStampOf reads the stamp from the cast context in flux.
+func castStampOf(flux map[string]any) castStamp {
+	var s castStamp
+	if ts, _ := mold.GetNestedValue(flux, mold.ContextKey+".timestamp"); ts != "" {
+		s.At, _ = time.Parse(time.RFC3339, ts)
+	}
+	s.Branch, _ = mold.GetNestedValue(flux, mold.ContextKey+".git.branch")
+	s.Commit, _ = mold.GetNestedValue(flux, mold.ContextKey+".git.commit")
+	return s
+}
+
+// installedStamp returns the stamp recorded on entry.
+func installedStamp(entry *foundry.InstalledEntry) castStamp {
+	return castStamp{At: entry.CastAt, Branch: entry.GitBranch, Commit: entry.GitCommit}
+}
+
+// pin replaces the cast context's values in flux with s's. Empty values
+// are left alone, so an entry recorded without a branch or commit keeps
+// the current ones.
+func (s castStamp) pin(flux map[string]any) {
+	if !s.At.IsZero() {
+		mold.SetNestedValue(flux, mold.ContextKey+".timestamp", s.At.UTC().Format(time.RFC3339))
+	}
+	if s.Branch != "" {
+		mold.SetNestedValue(flux, mold.ContextKey+".git.branch", s.Branch)
+	}
+	if s.Commit != "" {
+		mold.SetNestedValue(flux, mold.ContextKey+".git.commit", s.Commit)
+	}
+}
+
+// withCastContext stores the cast context under flux's reserved _ailloy key
+// (see mold.CastContext). A global cast has no target repository, so its git
+// fields stay empty.
+func withCastContext(flux map[string]any, manifest *mold.Mold, source string, global bool) map[string]any {
+	c := mold.CastContext{
+		Version:   strings.TrimPrefix(strings.TrimSpace(evolveCurrentVersion), "v"),
+		Timestamp: time.Now(),
+		Source:    source,
+	}
+	if c.Version == "" {
+		c.Version = "dev"
+	}
+	if manifest != nil {
+		c.MoldName, c.MoldVersion = manifest.Name, manifest.Version
+	}
+	if !global {
+		c.Git = mold.DetectGit(".")
+	}
+	return mold.ApplyCastContext(flux, c)
+}
@@ -3,7 +3,9 @@ package commands
 import (
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -272,7 +274,7 @@ func TestRecordCastedFiles_SubpathMold(t *testing.T) {
 		{RelPath: ".claude/agents/shortcut.md", SHA256: "deadbeef"},
 	}
 
-	if err := recordCastedFiles(result, files, false, nil, nil); err != nil {
+	if err := recordCastedFiles(result, files, false, nil, castStamp{}, nil); err != nil {
 		t.Fatalf("recordCastedFiles: %v", err)
 	}
 
@@ -327,7 +329,7 @@ flux:
 	slug := mold.FluxFileSlug(source)
 
 	// Without any persisted file: target == default.
-	flux, _, err := layerFluxForCore(reader, source, nil, nil, false)
+	flux, _, err := layerFluxForCore(reader, source, nil, fluxSets{}, false, nil)
 	if err != nil {
 		t.Fatalf("layerFluxForCore: %v", err)
 	}
@@ -344,7 +346,7 @@ flux:
 		t.Fatal(err)
 	}
 
-	flux, _, err = layerFluxForCore(reader, source, nil, nil, false)
+	flux, _, err = layerFluxForCore(reader, source, nil, fluxSets{}, false, nil)
 	if err != nil {
 		t.Fatalf("layerFluxForCore: %v", err)
 	}
@@ -353,7 +355,7 @@ flux:
 	}
 
 	// Explicit --set still wins over persisted file (Helm-style precedence).
-	flux, _, err = layerFluxForCore(reader, source, nil, []string{"target=zed"}, false)
+	flux, _, err = layerFluxForCore(reader, source, nil, fluxSets{Set: []string{"target=zed"}}, false, nil)
 	if err != nil {
 		t.Fatalf("layerFluxForCore: %v", err)
 	}
@@ -362,7 +364,7 @@ flux:
 	}
 
 	// Empty source skips persisted-file lookup (local mold dirs).
-	flux, _, err = layerFluxForCore(reader, "", nil, nil, false)
+	flux, _, err = layerFluxForCore(reader, "", nil, fluxSets{}, false, nil)
 	if err != nil {
 		t.Fatalf("layerFluxForCore: %v", err)
 	}
@@ -419,7 +421,7 @@ flux:
 	if err != nil {
 		t.Fatalf("ParseReference: %v", err)
 	}
-	flux, _, err := layerFluxForCore(reader, ref.OverrideKey(), nil, nil, false)
+	flux, _, err := layerFluxForCore(reader, ref.OverrideKey(), nil, fluxSets{}, false, nil)
 	if err != nil {
 		t.Fatalf("layerFluxForCore: %v", err)
 	}
@@ -430,7 +432,7 @@ flux:
 
 	// CacheKey() — the old, buggy lookup — must NOT find the override.
 	// Pinning this prevents a future refactor from silently regressing.
-	flux, _, err = layerFluxForCore(reader, ref.CacheKey(), nil, nil, false)
+	flux, _, err = layerFluxForCore(reader, ref.CacheKey(), nil, fluxSets{}, false, nil)
 	if err != nil {
 		t.Fatalf("layerFluxForCore (cache key): %v", err)
 	}
@@ -438,3 +440,122 @@ flux:
 		t.Fatalf("CacheKey() lookup unexpectedly matched; subpath molds in the same repo would collide. got=%v", got)
 	}
 }
+
+// TestCastMold_PrefillsFluxFromGitRemote verifies that empty repository flux
+// keys are filled from the project's origin remote while explicit values and
+// --set overrides win.
+func TestCastMold_PrefillsFluxFromGitRemote(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	t.Setenv("HOME", t.TempDir())
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "https://github.com/acme/widgets.git"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	moldDir := filepath.Join(t.TempDir(), "mold")
+	if err := os.MkdirAll(filepath.Join(moldDir, "commands"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"mold.yaml":        "apiVersion: v1\nkind: Mold\nname: repo\nversion: 0.1.0\n",
+		"flux.yaml":        "project:\n  organization: \"\"\nrepo:\n  name: fixed\noutput:\n  commands: .claude/commands\n",
+		"commands/info.md": "{{ scm.host }} {{ project.organization }} {{ repo.name }}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(moldDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := CastMold(t.Context(), moldDir, CastOptions{SetOverrides: []string{"scm.host=git.acme.dev"}}); err != nil {
+		t.Fatalf("CastMold: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(".claude", "commands", "info.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "git.acme.dev acme fixed\n"; !strings.HasSuffix(string(got), want) {
+		t.Errorf("info.md = %q, want it to end in %q", got, want)
+	}
+}
+
+// TestCastMold_CastContext verifies that blanks see the reserved _ailloy
+// namespace and that flux layers cannot override it.
+func TestCastMold_CastContext(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	t.Setenv("HOME", t.TempDir())
+	orig := evolveCurrentVersion
+	t.Cleanup(func() { evolveCurrentVersion = orig })
+	evolveCurrentVersion = "v1.4.0"
+
+	moldDir := filepath.Join(t.TempDir(), "mold")
+	if err := os.MkdirAll(filepath.Join(moldDir, "commands"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"mold.yaml":        "apiVersion: v1\nkind: Mold\nname: ctx\nversion: 0.2.0\n",
+		"flux.yaml":        "output:\n  commands: .claude/commands\n",
+		"commands/info.md": "{{ _ailloy.mold.name }}@{{ _ailloy.mold.version }} ailloy {{ _ailloy.version }}{{ if ._ailloy.timestamp }} stamped{{ end }}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(moldDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := CastMold(t.Context(), moldDir, CastOptions{SetOverrides: []string{"_ailloy.version=9.9.9"}}); err != nil {
+		t.Fatalf("CastMold: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(".claude", "commands", "info.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ctx@0.2.0 ailloy 1.4.0 stamped\n"; !strings.HasSuffix(string(got), want) {
+		t.Errorf("info.md = %q, want it to end in %q", got, want)
+	}
+}
+
+func TestLayerFluxForCore_SecretFlux(t *testing.T) {
+	t.Chdir(t.TempDir())
+	moldDir := t.TempDir()
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "name: launch\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "linear:\n  team: Platform\n  api_key: \"\"\n")
+	mustWrite(t, filepath.Join(moldDir, mold.SecretFluxFile), "linear:\n  api_key: ENC[AES256_GCM,data:Zm9v,type:str]\nsops:\n  mac: ENC[AES256_GCM,data:bWFj,type:str]\n")
+	reader, err := blanks.NewMoldReaderFromPath(moldDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := mold.DecryptFlux
+	t.Cleanup(func() { mold.DecryptFlux = orig })
+	mold.DecryptFlux = func([]byte) ([]byte, error) { return []byte("linear:\n  api_key: lin_123\n"), nil }
+	flux, _, err := layerFluxForCore(reader, "", nil, fluxSets{}, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := mold.GetNestedAny(flux, "linear.api_key"); v != "lin_123" {
+		t.Errorf("linear.api_key = %v, want the decrypted value", v)
+	}
+	if v, _ := mold.GetNestedAny(flux, "linear.team"); v != "Platform" {
+		t.Errorf("linear.team = %v, want flux.yaml's value kept", v)
+	}
+
+	// Without a key the secret file is skipped and the cast goes on.
+	mold.DecryptFlux = func([]byte) ([]byte, error) { return nil, io.EOF }
+	flux, _, err = layerFluxForCore(reader, "", nil, fluxSets{}, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := mold.GetNestedAny(flux, "linear.api_key"); v != "" {
+		t.Errorf("linear.api_key = %v, want flux.yaml's empty value", v)
+	}
+}
@@ -10,12 +10,14 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/foundry/depgraph"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/smelt"
 	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 )
 
 // hasMoldDeps reports whether the given mold declares any kind=="mold"
@@ -48,8 +50,8 @@ type depFetcher interface {
 // as the root, with Helm-style flux propagation: dep defaults <- the parent's
 // `with:` block <- the root cast's `--set <depAlias>.*` and `-f` overrides.
 //
-// `--with-workflows` cascades to transitives; transitives' .github/ files are
-// emitted only when the user passed --with-workflows for the root.
+// `--with-workflows` and `--ci` cascade to transitives; transitives' workflow
+// blanks are emitted only for the CI system the root cast selected.
 //
 // Failures during dep resolution or per-dep casting bubble up unchanged so
 // the cast as a whole is reported as failed (rather than silently leaving a
@@ -81,6 +83,8 @@ func castTransitiveDeps(rootResult *foundry.ResolveResult, root *mold.Mold, root
 		prodFetcher.LockPath = globalLockPath()
 	}
 	prodFetcher.Offline = castOffline
+	prodFetcher.CacheFirst = castCacheFirst
+	prodFetcher.AllowYanked = castAllowYanked
 
 	// When running as a smelted binary with embedded deps, prefer the
 	// embedded dep store over the network so offline casts work end-to-end.
@@ -99,7 +103,13 @@ func castTransitiveDepsWith(fetcher depFetcher, rootResult *foundry.ResolveResul
 	if rootResult == nil || root == nil || !hasMoldDeps(root) {
 		return nil
 	}
-	graph, err := depgraph.New(fetcher).Build(root, rootResult.Ref)
+	builder := depgraph.New(fetcher)
+	builder.Jobs = foundry.FetchJobs
+	builder.Fetched = func(key depgraph.NodeKey, version string) {
+		logging.Say(styles.SuccessStyle.Render("  Fetched: ")+styles.CodeStyle.Render(key.String())+" "+styles.CodeStyle.Render(version),
+			"fetched dependency", "mold", key.String(), "version", version)
+	}
+	graph, err := builder.Build(root, rootResult.Ref)
 	if err != nil {
 		return fmt.Errorf("resolving dependency graph: %w", err)
 	}
@@ -107,12 +117,26 @@ func castTransitiveDepsWith(fetcher depFetcher, rootResult *foundry.ResolveResul
 	rootKey := depgraph.NodeKey{Source: rootResult.Ref.CacheKey(), Subpath: rootResult.Ref.Subpath}
 	parentLabel := rootKey.String()
 
+	// Check every dependency's requires.ailloy before casting any of them,
+	// so an incompatible dep can't leave the project half-cast.
 	for _, node := range graph.Nodes {
 		if node.Key == rootKey {
 			continue
 		}
+		if entry := fetcher.CacheEntry(node.Key); entry != nil && entry.Mold != nil {
+			if err := requireAilloy("dependency "+node.Key.String(), entry.Mold.Requires.Ailloy); err != nil {
+				return err
+			}
+		}
+	}
 
-		fmt.Println(styles.InfoStyle.Render("📦 Casting dependency: ") + styles.CodeStyle.Render(node.Key.String()) + " " + styles.CodeStyle.Render(node.Version))
+	for _, node := range graph.Nodes {
+		if node.Key == rootKey {
+			continue
+		}
+
+		logging.Say(styles.InfoStyle.Render("📦 Casting dependency: ")+styles.CodeStyle.Render(node.Key.String())+" "+styles.CodeStyle.Render(node.Version),
+			"casting dependency", "mold", node.Key.String(), "version", node.Version)
 
 		entry := fetcher.CacheEntry(node.Key)
 		if entry == nil || entry.FS == nil {
@@ -133,7 +157,14 @@ func castTransitiveDepsWith(fetcher depFetcher, rootResult *foundry.ResolveResul
 				moldRoot = tmpDir
 			}
 		}
-		reader := blanks.NewMoldReaderFromFS(entry.FS, moldRoot)
+		depID := node.Key.Source
+		if sp := strings.Trim(node.Key.Subpath, "/"); sp != "" {
+			depID += "/" + sp
+		}
+		reader, err := ComposeMoldReader(blanks.NewMoldReaderFromFS(entry.FS, moldRoot), depID, castResolveOpts(castGlobal)...)
+		if err != nil {
+			return fmt.Errorf("composing %s: %w", node.Key, err)
+		}
 
 		manifest, err := reader.LoadManifest()
 		if err != nil {
@@ -168,15 +199,20 @@ func castTransitiveDepsWith(fetcher depFetcher, rootResult *foundry.ResolveResul
 		if err != nil {
 			return fmt.Errorf("resolving output files for %s: %w", node.Key, err)
 		}
+		if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+			return err
+		}
 
+		ci, err := castCISystem(withWorkflows, castCI, destPrefix)
+		if err != nil {
+			return err
+		}
 		var filesToCast []mold.ResolvedFile
 		for _, rf := range resolved {
-			if !withWorkflows && strings.HasPrefix(rf.DestPath, ".github/") {
+			if mold.SkipCIFile(rf.DestPath, ci) {
 				continue
 			}
-			if destPrefix != "" {
-				rf.DestPath = filepath.Join(destPrefix, rf.DestPath)
-			}
+			rf.DestPath = castDest(destPrefix, rf.DestPath)
 			filesToCast = append(filesToCast, rf)
 		}
 
@@ -191,8 +227,12 @@ func castTransitiveDepsWith(fetcher depFetcher, rootResult *foundry.ResolveResul
 			}
 		}
 
+		renderHashes := map[string]string{}
 		if err := copyResolvedFilesWithSchema(reader, manifest, schema, flux, filesToCast, copyOpts{
 			ForceReplaceOnParseError: castForceReplaceOnParseError,
+			RenderHashes:             renderHashes,
+			Provenance:               true,
+			Backup:                   &backupScope{DestPrefix: destPrefix, Op: "cast", Mold: manifest.Name},
 		}); err != nil {
 			return fmt.Errorf("copying files for %s: %w", node.Key, err)
 		}
@@ -202,13 +242,7 @@ func castTransitiveDepsWith(fetcher depFetcher, rootResult *foundry.ResolveResul
 		installedFiles := make([]foundry.InstalledFile, 0, len(filesToCast))
 		for _, f := range filesToCast {
 			sum, _ := hashFileForDeps(f.DestPath)
-			rel := f.DestPath
-			if destPrefix != "" {
-				if r, rerr := filepath.Rel(destPrefix, f.DestPath); rerr == nil {
-					rel = r
-				}
-			}
-			installedFiles = append(installedFiles, foundry.InstalledFile{RelPath: rel, SHA256: sum})
+			installedFiles = append(installedFiles, foundry.InstalledFile{RelPath: installedRelPath(destPrefix, f.DestPath), SHA256: sum, RenderSHA256: renderHashes[f.DestPath]})
 		}
 
 		// Synthesize a ResolveResult-shaped record from the cached fetch. The
@@ -240,7 +274,7 @@ func castTransitiveDepsWith(fetcher depFetcher, rootResult *foundry.ResolveResul
 			parents = []string{parentLabel}
 		}
 
-		if err := recordCastedFilesWithProvenance(depResult, installedFiles, castGlobal, nil, "transitive", parents, nil); err != nil {
+		if err := recordCastedFilesWithProvenance(depResult, installedFiles, castGlobal, nil, castStampOf(flux), "transitive", parents, nil); err != nil {
 			log.Printf("warning: failed to record transitive dep %s: %v", node.Key, err)
 		}
 	}
@@ -276,16 +310,14 @@ func loadDepFlux(reader *blanks.MoldReader, manifest *mold.Mold, node *depgraph.
 	// here; users opt-in by listing the keys in `with:` on the parent.
 	alias := depAlias(node, manifest)
 	if alias != "" {
-		prefix := alias + "."
-		for _, raw := range castSetFlags {
-			parts := strings.SplitN(raw, "=", 2)
-			if len(parts) != 2 || !strings.HasPrefix(parts[0], prefix) {
-				continue
-			}
-			scoped := strings.TrimPrefix(parts[0], prefix) + "=" + parts[1]
-			if err := mold.ApplySetOverrides(flux, []string{scoped}); err != nil {
-				return nil, nil, fmt.Errorf("applying scoped --set %q: %w", raw, err)
-			}
+		sets := castFluxSets()
+		scoped := fluxSets{
+			Set:   scopedSets(sets.Set, alias),
+			Files: scopedSets(sets.Files, alias),
+			JSON:  scopedSets(sets.JSON, alias),
+		}
+		if err := scoped.apply(flux); err != nil {
+			return nil, nil, fmt.Errorf("applying --set scoped to %s: %w", alias, err)
 		}
 	}
 
@@ -296,6 +328,21 @@ func loadDepFlux(reader *blanks.MoldReader, manifest *mold.Mold, node *depgraph.
 	return flux, schema, nil
 }
 
+// scopedSets returns the <alias>.<key>=value flags among sets as
+// <key>=value.
+func scopedSets(sets []string, alias string) []string {
+	prefix := alias + "."
+	var out []string
+	for _, raw := range sets {
+		parts := strings.SplitN(raw, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], prefix) {
+			continue
+		}
+		out = append(out, strings.TrimPrefix(parts[0], prefix)+"="+parts[1])
+	}
+	return out
+}
+
 // depAlias returns the alias the parent declared for this node, falling back
 // to the mold's own name when the dep entry didn't set `as:`.
 func depAlias(node *depgraph.Node, manifest *mold.Mold) string {
@@ -319,7 +366,7 @@ func stageIngotsFromFS(fsys fs.FS) (string, error) {
 	if _, err := fs.Stat(fsys, "ingots"); err != nil {
 		return "", nil
 	}
-	tmpDir, err := os.MkdirTemp("", "ailloy-dep-ingots-*")
+	tmpDir, err := tmpdir.MkdirTemp("dep-ingots-*")
 	if err != nil {
 		return "", err
 	}
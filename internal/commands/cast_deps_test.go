@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"testing/fstest"
 
@@ -69,6 +70,8 @@ func TestDepAlias(t *testing.T) {
 type fakeDepFetcher struct {
 	molds map[string]map[string]*moldFixture // sourceKey -> version -> fixture
 	tags  map[string]map[string]string       // sourceKey -> tag -> sha
+
+	mu    sync.Mutex // the cast fetches siblings concurrently
 	cache map[depgraph.NodeKey]*depgraph.ProdFetchCacheEntry
 }
 
@@ -131,7 +134,9 @@ func (f *fakeDepFetcher) Fetch(ref *foundry.Reference) (depgraph.FetchResult, er
 		Reference: ref,
 	}
 	nodeKey := depgraph.NodeKey{Source: ref.CacheKey(), Subpath: ref.Subpath}
+	f.mu.Lock()
 	f.cache[nodeKey] = cached
+	f.mu.Unlock()
 	return depgraph.FetchResult{
 		Mold:    v.mold,
 		Version: resolved.Tag,
@@ -156,6 +161,8 @@ func (f *fakeDepFetcher) Tags(source, subpath string) (map[string]depgraph.TagIn
 }
 
 func (f *fakeDepFetcher) CacheEntry(k depgraph.NodeKey) *depgraph.ProdFetchCacheEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	return f.cache[k]
 }
 
@@ -449,3 +456,49 @@ func TestCastTransitiveDeps_LocalDirSentinel(t *testing.T) {
 		t.Errorf("InstalledAs = %q; want transitive", leafEntry.InstalledAs)
 	}
 }
+
+// TestCastTransitiveDeps_DepRequiresNewerAilloy verifies an incompatible
+// dependency stops the cast before any dependency is written, and that
+// --ignore-requires lets it through.
+func TestCastTransitiveDeps_DepRequiresNewerAilloy(t *testing.T) {
+	tmp := t.TempDir()
+	chdir(t, tmp)
+	orig := evolveCurrentVersion
+	t.Cleanup(func() { evolveCurrentVersion = orig; castIgnoreRequires = false })
+	evolveCurrentVersion = "0.6.32"
+
+	leafFS := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: leaf\nversion: 1.0.0\n")},
+		"flux.yaml": &fstest.MapFile{Data: []byte("output:\n  hello.md: hello-out.md\n")},
+		"hello.md":  &fstest.MapFile{Data: []byte("# hi from leaf\n")},
+	}
+	leafMold := &mold.Mold{APIVersion: "v1", Kind: "mold", Name: "leaf", Version: "1.0.0", Requires: mold.Requires{Ailloy: ">=0.7.0"}}
+	fetcher := newFakeDepFetcher()
+	fetcher.addMold("github.com/x/leaf", "1.0.0", &moldFixture{mold: leafMold, fs: leafFS})
+
+	root := &mold.Mold{
+		APIVersion: "v1", Kind: "mold", Name: "root", Version: "1.0.0",
+		Dependencies: []mold.Dependency{{Mold: "github.com/x/leaf", Version: "^1.0.0"}},
+	}
+	rootRef, err := foundry.ParseReference("github.com/x/root@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootResult := &foundry.ResolveResult{Ref: rootRef, Resolved: foundry.ResolvedVersion{Tag: "v1.0.0"}}
+
+	err = castTransitiveDepsWith(fetcher, rootResult, root, map[string]any{}, "")
+	if err == nil || !strings.Contains(err.Error(), "dependency github.com/x/leaf requires ailloy >=0.7.0") {
+		t.Fatalf("err = %v, want the dependency's requires.ailloy error", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "hello-out.md")); err == nil {
+		t.Error("leaf was cast despite failing requires.ailloy")
+	}
+
+	castIgnoreRequires = true
+	if err := castTransitiveDepsWith(fetcher, rootResult, root, map[string]any{}, ""); err != nil {
+		t.Fatalf("with --ignore-requires: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "hello-out.md")); err != nil {
+		t.Errorf("leaf output missing with --ignore-requires: %v", err)
+	}
+}
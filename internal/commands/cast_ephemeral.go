@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/internal/tui/ceremony"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+)
+
+// beginEphemeralTrial loads (or starts) the trial for this mold and backs up
+// every destination the cast may overwrite. State is saved before any file
+// is written so an interrupted cast can still be reverted.
+func beginEphemeralTrial(manifest *mold.Mold, source string, files []mold.ResolvedFile) (*foundry.EphemeralTrial, error) {
+	state, err := foundry.ReadEphemeralState(foundry.EphemeralStatePath)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &foundry.EphemeralState{APIVersion: "v1"}
+	}
+
+	trial := foundry.EphemeralTrial{Name: manifest.Name, Source: source}
+	if resolvedRemote != nil {
+		trial.Source = resolvedRemote.Ref.CacheKey()
+		trial.Subpath = resolvedRemote.Ref.Subpath
+		trial.Version = resolvedRemote.Resolved.Tag
+		trial.Commit = resolvedRemote.Resolved.Commit
+	}
+	if trial.Source == "" {
+		trial.Source = manifest.Name
+	}
+	if existing := state.Find(trial.Source, trial.Subpath); existing != nil {
+		trial.Files = existing.Files
+	}
+
+	paths := make([]string, len(files))
+	for i, rf := range files {
+		paths[i] = rf.DestPath
+	}
+	if err := foundry.BackupForTrial(foundry.EphemeralStatePath, &trial, paths); err != nil {
+		return nil, err
+	}
+	trial.CastAt = time.Now().UTC()
+	trial.ExpiresAt = trial.CastAt.Add(time.Duration(castEphemeralDays) * 24 * time.Hour)
+	state.Upsert(trial)
+	if err := foundry.WriteEphemeralState(foundry.EphemeralStatePath, state); err != nil {
+		return nil, err
+	}
+	return state.Find(trial.Source, trial.Subpath), nil
+}
+
+// finishEphemeralTrial records post-cast hashes so revert can tell whether
+// a trial file was edited afterwards.
+func finishEphemeralTrial(trial *foundry.EphemeralTrial) error {
+	state, err := foundry.ReadEphemeralState(foundry.EphemeralStatePath)
+	if err != nil {
+		return err
+	}
+	if err := foundry.RecordTrialHashes(foundry.EphemeralStatePath, trial); err != nil {
+		return err
+	}
+	if state == nil {
+		state = &foundry.EphemeralState{APIVersion: "v1"}
+	}
+	state.Upsert(*trial)
+	return foundry.WriteEphemeralState(foundry.EphemeralStatePath, state)
+}
+
+func printEphemeralSummary(trial *foundry.EphemeralTrial) {
+	if !logging.Decorative() {
+		slog.Info("trial cast complete", "trial", trial.Display(), "files", len(trial.Files), "expires", trial.ExpiresAt)
+		return
+	}
+	fmt.Println()
+	fmt.Println(styles.SuccessBanner("Trial cast complete!"))
+	fmt.Println()
+
+	restorable := 0
+	for _, f := range trial.Files {
+		if f.Backup != "" {
+			restorable++
+		}
+	}
+	content := styles.SuccessStyle.Render("🧪 Ephemeral trial: ") + styles.AccentStyle.Render(trial.Display()) + "\n\n" +
+		styles.FoxBullet(fmt.Sprintf("%d file(s) cast, %d with backed-up originals", len(trial.Files), restorable)) + "\n" +
+		styles.FoxBullet("Expires: "+trial.ExpiresAt.Local().Format("2006-01-02")) + "\n" +
+		styles.FoxBullet("Revert: "+styles.CodeStyle.Render("ailloy revert --ephemeral "+trial.Display())) + "\n" +
+		styles.FoxBullet("Keep:   "+styles.CodeStyle.Render("ailloy cast "+trial.Display()))
+	fmt.Println(styles.SuccessBoxStyle.Render(content))
+	ceremony.Stamp(ceremony.Cast, fmt.Sprintf("trial of %d file(s)", len(trial.Files)))
+}
+
+// warnExpiredTrials prints a notice to stderr for each expired trial in the
+// current project. It is quiet when the project has no trials.
+func warnExpiredTrials(now time.Time) {
+	state, err := foundry.ReadEphemeralState(foundry.EphemeralStatePath)
+	if err != nil || state == nil {
+		return
+	}
+	for _, t := range state.Expired(now) {
+		if !logging.Decorative() {
+			slog.Warn("ephemeral trial expired", "trial", t.Display(), "cast_at", t.CastAt)
+			continue
+		}
+		fmt.Fprintln(os.Stderr, styles.WarningStyle.Render("⚠️  Ephemeral trial expired: ")+
+			styles.AccentStyle.Render(t.Display())+
+			styles.SubtleStyle.Render(fmt.Sprintf(" (cast %s) — run 'ailloy revert --ephemeral %s' or re-cast it without --ephemeral to keep it",
+				t.CastAt.Local().Format("2006-01-02"), t.Display())))
+	}
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+)
+
+func trialMoldReader() *blanks.MoldReader {
+	return blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml":         &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: trial-mold\nversion: 1.0.0\n")},
+		"flux.yaml":         &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n")},
+		"commands/hello.md": &fstest.MapFile{Data: []byte("# trial hello\n")},
+		"commands/new.md":   &fstest.MapFile{Data: []byte("# trial new\n")},
+	})
+}
+
+func resetRevertFlags() {
+	revertEphemeral, revertExpired, revertList, revertForce, revertDryRun = false, false, false, false, false
+}
+
+func TestCastEphemeral_RevertRestoresProject(t *testing.T) {
+	resetCastFlags()
+	castEphemeral = true
+	defer resetCastFlags()
+	defer resetRevertFlags()
+
+	tmp := t.TempDir()
+	chdir(t, tmp)
+	if err := os.MkdirAll(".claude/commands", 0o750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, ".claude/commands/hello.md", "# my hello\n")
+
+	if err := castProject(trialMoldReader(), "./trial-mold"); err != nil {
+		t.Fatalf("castProject: %v", err)
+	}
+	if got := castedBody(t, ".claude/commands/hello.md"); got != "# trial hello\n" {
+		t.Errorf("trial should overwrite hello.md, got %q", got)
+	}
+	if _, err := os.Stat(foundry.InstalledManifestPath); !os.IsNotExist(err) {
+		t.Error("trial casts must not write installed.yaml")
+	}
+	state, err := foundry.ReadEphemeralState(foundry.EphemeralStatePath)
+	if err != nil || state == nil {
+		t.Fatalf("reading ephemeral state: %v", err)
+	}
+	trial := state.Find("./trial-mold", "")
+	if trial == nil || len(trial.Files) != 2 || !trial.ExpiresAt.After(trial.CastAt) {
+		t.Fatalf("unexpected trial: %+v", trial)
+	}
+
+	revertEphemeral = true
+	if err := runRevert(nil, []string{"trial-mold"}); err != nil {
+		t.Fatalf("runRevert: %v", err)
+	}
+	if got, _ := os.ReadFile(".claude/commands/hello.md"); string(got) != "# my hello\n" {
+		t.Errorf("revert should restore hello.md, got %q", got)
+	}
+	if _, err := os.Stat(".claude/commands/new.md"); !os.IsNotExist(err) {
+		t.Error("revert should remove files the trial created")
+	}
+	if _, err := os.Stat(foundry.EphemeralStatePath); !os.IsNotExist(err) {
+		t.Error("ephemeral state should be removed after the last revert")
+	}
+}
+
+func TestCastEphemeral_RegularCastKeepsTrial(t *testing.T) {
+	resetCastFlags()
+	castEphemeral = true
+	defer resetCastFlags()
+
+	chdir(t, t.TempDir())
+	if err := castProject(trialMoldReader(), "./trial-mold"); err != nil {
+		t.Fatalf("trial cast: %v", err)
+	}
+
+	castEphemeral = false
+	if err := castProject(trialMoldReader(), "./trial-mold"); err != nil {
+		t.Fatalf("regular cast: %v", err)
+	}
+	if _, err := os.Stat(foundry.EphemeralStatePath); !os.IsNotExist(err) {
+		t.Error("a regular cast should discard the trial")
+	}
+	mustFile(t, ".claude/commands/new.md")
+}
+
+func TestRevert_RequiresEphemeralFlag(t *testing.T) {
+	defer resetRevertFlags()
+	chdir(t, t.TempDir())
+	err := runRevert(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "--ephemeral") {
+		t.Errorf("expected --ephemeral error, got %v", err)
+	}
+}
+
+func TestValidateEphemeralFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr string
+	}{
+		{"off", func() {}, ""},
+		{"on", func() { castEphemeral = true }, ""},
+		{"global", func() { castEphemeral, castGlobal = true, true }, "--global"},
+		{"plugin", func() { castEphemeral, castClaudePluginFlag = true, true }, "--claude-plugin"},
+		{"days", func() { castEphemeral, castEphemeralDays = true, 0 }, "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCastFlags()
+			defer resetCastFlags()
+			tt.setup()
+			err := validateEphemeralFlags()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
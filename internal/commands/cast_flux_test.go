@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// fluxCastMold writes a mold whose one blank renders team, and returns its
+// directory.
+func fluxCastMold(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: flux-mold\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(dir, "flux.yaml"), "team: \"\"\noutput:\n  commands: .claude/commands\n")
+	mustWrite(t, filepath.Join(dir, "commands", "team.md"), "Team: {{ .team }}\n")
+	return dir
+}
+
+// runFluxCast runs cast on moldDir from an empty project and returns its
+// error.
+func runFluxCast(t *testing.T, moldDir string) error {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	chdir(t, t.TempDir())
+	t.Cleanup(resetCastFlags)
+	return runCast(castCmd, []string{moldDir})
+}
+
+func TestRunCast_SealedValuesFileFails(t *testing.T) {
+	resetCastFlags()
+	moldDir := fluxCastMold(t)
+	sealed := filepath.Join(t.TempDir(), "secrets.yaml")
+	mustWrite(t, sealed, "team: ENC[AES256_GCM,data:Zm9v,type:str]\nsops:\n  mac: ENC[AES256_GCM,data:bWFj,type:str]\n")
+	orig := mold.DecryptFlux
+	t.Cleanup(func() { mold.DecryptFlux = orig })
+	mold.DecryptFlux = func([]byte) ([]byte, error) { return nil, io.EOF }
+	castValFiles = []string{sealed}
+
+	err := runFluxCast(t, moldDir)
+	var sealedErr *mold.SealedFluxError
+	if !errors.As(err, &sealedErr) {
+		t.Fatalf("runCast error = %v, want a *mold.SealedFluxError", err)
+	}
+	if _, serr := os.Stat(filepath.Join(".claude", "commands", "team.md")); !os.IsNotExist(serr) {
+		t.Errorf("team.md was cast despite the sealed values file (stat err %v)", serr)
+	}
+}
+
+// trustTestServer makes the values fetch trust srv's certificate. The fetch
+// client has no transport of its own, so it uses http.DefaultTransport.
+func trustTestServer(t *testing.T, srv *httptest.Server) {
+	t.Helper()
+	orig := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = orig })
+	http.DefaultTransport = srv.Client().Transport
+}
+
+func TestRunCast_RemoteValuesFileFails(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "team: downgraded\n")
+	}))
+	defer plain.Close()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing.yaml":
+			http.NotFound(w, r)
+		case "/huge.yaml":
+			_, _ = io.WriteString(w, "team: "+strings.Repeat("x", 10<<20)+"\n")
+		case "/redirect.yaml":
+			http.Redirect(w, r, plain.URL+"/values.yaml", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name, path, want string
+	}{
+		{"not found", "/missing.yaml", "404 Not Found"},
+		{"too large", "/huge.yaml", "larger than"},
+		{"plain HTTP redirect", "/redirect.yaml", "refusing redirect to non-HTTPS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCastFlags()
+			trustTestServer(t, srv)
+			castValFiles = []string{srv.URL + tt.path}
+			err := runFluxCast(t, fluxCastMold(t))
+			if got := exitCodeFor(err); got != ExitConfig || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("runCast = exit %d (%v), want exit %d mentioning %q", got, err, ExitConfig, tt.want)
+			}
+			if _, serr := os.Stat(filepath.Join(".claude", "commands", "team.md")); !os.IsNotExist(serr) {
+				t.Errorf("team.md was cast despite the failed fetch (stat err %v)", serr)
+			}
+		})
+	}
+}
+
+func TestRunCast_OfflineRemoteValues(t *testing.T) {
+	var hits int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		_, _ = io.WriteString(w, "team: remote\n")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name, path, want string
+	}{
+		{"https values", srv.URL + "/values.yaml", "can't be fetched offline"},
+		{"uncached git values", "git::github.com/acme/values@v1.0.0//team.yaml", "offline mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCastFlags()
+			trustTestServer(t, srv)
+			castOffline = true
+			castValFiles = []string{tt.path}
+			err := runFluxCast(t, fluxCastMold(t))
+			if got := exitCodeFor(err); got != ExitConfig || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("runCast = exit %d (%v), want exit %d mentioning %q", got, err, ExitConfig, tt.want)
+			}
+			if hits != 0 {
+				t.Errorf("offline cast fetched the values URL %d time(s)", hits)
+			}
+		})
+	}
+}
+
+func TestRunCast_OfflineGitValuesFromCache(t *testing.T) {
+	resetCastFlags()
+	t.Cleanup(resetCastFlags)
+	moldDir := fluxCastMold(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Warm the cache with a bare clone of a values repository, the way an
+	// earlier online cast would have.
+	src := t.TempDir()
+	mustWrite(t, filepath.Join(src, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: values\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(src, "team.yaml"), "team: cached\n")
+	bare := filepath.Join(home, ".ailloy", "cache", "github.com", "acme", "values", "git")
+	for _, args := range [][]string{
+		{"-C", src, "init", "-q"},
+		{"-C", src, "add", "."},
+		{"-C", src, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "values"},
+		{"-C", src, "tag", "v1.0.0"},
+		{"clone", "-q", "--bare", src, bare},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	chdir(t, t.TempDir())
+	castOffline = true
+	castValFiles = []string{"git::github.com/acme/values@v1.0.0//team.yaml"}
+	if err := runCast(castCmd, []string{moldDir}); err != nil {
+		t.Fatalf("offline cast with cached git values: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(".claude", "commands", "team.md"))
+	if err != nil || !strings.Contains(string(data), "Team: cached") {
+		t.Errorf("team.md = %q (err %v), want the cached team", data, err)
+	}
+}
+
+func TestRunCast_StdinValuesFromTerminalFails(t *testing.T) {
+	resetCastFlags()
+	orig := mold.StdinIsTerminal
+	t.Cleanup(func() { mold.StdinIsTerminal = orig })
+	mold.StdinIsTerminal = func(*os.File) bool { return true }
+	castValFiles = []string{mold.StdinValuesPath}
+
+	err := runFluxCast(t, fluxCastMold(t))
+	if got := exitCodeFor(err); got != ExitConfig || !strings.Contains(err.Error(), "stdin is a terminal") {
+		t.Fatalf("runCast = exit %d (%v), want exit %d for a terminal on stdin", got, err, ExitConfig)
+	}
+}
+
+func TestRunCast_MissingSetFileFails(t *testing.T) {
+	resetCastFlags()
+	castSetFileFlags = []string{"team=" + filepath.Join(t.TempDir(), "missing.txt")}
+
+	err := runFluxCast(t, fluxCastMold(t))
+	if got := exitCodeFor(err); got != ExitUsage || !strings.Contains(err.Error(), "missing.txt") {
+		t.Fatalf("runCast = exit %d (%v), want exit %d naming the missing file", got, err, ExitUsage)
+	}
+	if _, serr := os.Stat(filepath.Join(".claude", "commands", "team.md")); !os.IsNotExist(serr) {
+		t.Errorf("team.md was cast despite the missing --set-file (stat err %v)", serr)
+	}
+}
+
+func TestUnknownManifestFields_WarnOnCastAndForge(t *testing.T) {
+	resetCastFlags()
+	moldDir := fluxCastMold(t)
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: flux-mold\nversion: 1.0.0\nfluxx:\n  - name: team\n")
+	const want = "warning: mold.yaml:5: unknown field `fluxx` (did you mean `flux`?) is ignored"
+
+	var logs bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	if err := runFluxCast(t, moldDir); err != nil {
+		t.Fatalf("cast: %v", err)
+	}
+	if !strings.Contains(logs.String(), want) {
+		t.Errorf("cast logs missing %q:\n%s", want, logs.String())
+	}
+
+	reader, err := blanks.NewMoldReaderFromPath(moldDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forgeLogs bytes.Buffer
+	if _, _, err := forgeRender(reader, false, forgeInput{logger: log.New(&forgeLogs, "", 0)}); err != nil {
+		t.Fatalf("forge: %v", err)
+	}
+	if !strings.Contains(forgeLogs.String(), want) {
+		t.Errorf("forge logs missing %q:\n%s", want, forgeLogs.String())
+	}
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
+)
+
+// runMoldHooks runs the scripts m declares for stage in dir, with the flux
+// values exported as environment variables (see mold.HookEnv). Scripts run
+// only once the user approved the mold's hooks (see confirmMoldExec), and
+// not at all while the exec policy disables execution. A script whose
+// interpreter the policy's allowlist doesn't cover is an error, as is a
+// failing script, which also stops the remaining ones.
+func runMoldHooks(stage string, fsys fs.FS, m *mold.Mold, flux map[string]any, key, dir string) error {
+	scripts := m.Hooks.Scripts(stage)
+	if len(scripts) == 0 {
+		return nil
+	}
+	policy, err := currentExecPolicy()
+	if err != nil {
+		return err
+	}
+	if policy.Disabled {
+		slog.Info("skipping mold hooks: command execution is disabled", "mold", m.Name, "stage", stage)
+		return nil
+	}
+	approved, err := approveMoldHooks(fsys, m, key)
+	if err != nil {
+		return err
+	}
+	if !approved {
+		return nil
+	}
+
+	tmp, err := tmpdir.MkdirTemp("hooks-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	env := append(os.Environ(),
+		"AILLOY_HOOK="+stage,
+		"AILLOY_MOLD="+m.Name,
+		"AILLOY_MOLD_VERSION="+m.Version,
+	)
+	env = append(env, mold.HookEnv(flux)...)
+
+	for i, script := range scripts {
+		data, err := mold.ReadHookScript(fsys, script)
+		if err != nil {
+			return fmt.Errorf("%s hook: %w", stage, err)
+		}
+		if err := policy.CheckScript(data); err != nil {
+			return fmt.Errorf("%s hook %s: %w", stage, script, err)
+		}
+		p := filepath.Join(tmp, fmt.Sprintf("%d-%s", i, path.Base(script)))
+		if err := os.WriteFile(p, data, 0o700); err != nil { // #nosec G306 -- the script must be executable
+			return err
+		}
+		// Scripts without a shebang are run by sh.
+		cmd := exec.Command(p) // #nosec G204 -- running the mold's approved hook script is the point
+		if !bytes.HasPrefix(data, []byte("#!")) {
+			cmd = exec.Command("sh", p) // #nosec G204 -- as above
+		}
+		cmd.Dir = dir
+		cmd.Env = env
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		fmt.Println(styles.InfoStyle.Render("🪝 Running "+stage+" hook: ") + styles.CodeStyle.Render(script))
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %s: %w", stage, script, err)
+		}
+	}
+	return nil
+}
+
+// approveMoldHooks reports whether m's hooks may run, asking the user when
+// the scripts haven't been approved in their current form.
+func approveMoldHooks(fsys fs.FS, m *mold.Mold, key string) (bool, error) {
+	digest, err := hooksDigest(fsys, m.Hooks)
+	if err != nil {
+		return false, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s declares hook scripts that run on this machine:\n", m.Name)
+	for _, stage := range []string{mold.HookPreCast, mold.HookPostCast, mold.HookPreUpgrade} {
+		for _, script := range m.Hooks.Scripts(stage) {
+			fmt.Fprintf(&b, "  %-12s %s\n", stage, script)
+		}
+	}
+	return confirmMoldExec(key, execKindHooks, digest, m.Name, b.String())
+}
+
+// hooksDigest hashes every declared script's stage, path and content.
+func hooksDigest(fsys fs.FS, h mold.Hooks) (string, error) {
+	sum := sha256.New()
+	for _, stage := range []string{mold.HookPreCast, mold.HookPostCast, mold.HookPreUpgrade} {
+		for _, script := range h.Scripts(stage) {
+			data, err := mold.ReadHookScript(fsys, script)
+			if err != nil {
+				return "", fmt.Errorf("%s hook: %w", stage, err)
+			}
+			fmt.Fprintf(sum, "%s\x00%s\x00%d\x00", stage, script, len(data))
+			sum.Write(data)
+		}
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func hooksTestMold() (fstest.MapFS, *mold.Mold) {
+	fsys := fstest.MapFS{
+		"hooks/post.sh": &fstest.MapFile{Data: []byte("echo \"$AILLOY_HOOK $AILLOY_MOLD $AILLOY_FLUX_PROJECT_NAME\" > hook.out\n")},
+	}
+	m := &mold.Mold{Name: "demo", Version: "1.0.0", Hooks: mold.Hooks{PostCast: []string{"hooks/post.sh"}}}
+	return fsys, m
+}
+
+// approveHooksForTest records consent for m's hooks under key in $HOME.
+func approveHooksForTest(t *testing.T, fsys fstest.MapFS, m *mold.Mold, key string) {
+	t.Helper()
+	digest, err := hooksDigest(fsys, m.Hooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := yaml.Marshal(execConsents{Molds: map[string]map[string]string{key: {execKindHooks: digest}}})
+	path, err := execConsentFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunMoldHooks_Approved(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	fsys, m := hooksTestMold()
+	approveHooksForTest(t, fsys, m, "demo")
+
+	flux := map[string]any{"project": map[string]any{"name": "api"}}
+	if err := runMoldHooks(mold.HookPostCast, fsys, m, flux, execConsentKey("", m), dir); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "hook.out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "post-cast demo api" {
+		t.Errorf("hook output = %q", got)
+	}
+
+	// Nothing is declared for pre-cast.
+	if err := runMoldHooks(mold.HookPreCast, fsys, m, flux, "demo", dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunMoldHooks_UnapprovedSkippedWithoutTerminal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	fsys, m := hooksTestMold()
+
+	if err := runMoldHooks(mold.HookPostCast, fsys, m, nil, "unapproved-demo", dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hook.out")); !os.IsNotExist(err) {
+		t.Errorf("unapproved hook ran: stat err = %v", err)
+	}
+}
+
+func TestRunMoldHooks_YesApprovesWithoutTerminal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	fsys, m := hooksTestMold()
+
+	// --yes runs unapproved hooks for this run without recording consent.
+	dir := t.TempDir()
+	rootYes = true
+	err := runMoldHooks(mold.HookPostCast, fsys, m, nil, "yes-demo", dir)
+	rootYes = false
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hook.out")); err != nil {
+		t.Errorf("hook did not run with --yes: %v", err)
+	}
+	path, err := execConsentFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("--yes should not record consent: stat err = %v", err)
+	}
+
+	// AILLOY_YES does the same.
+	dir = t.TempDir()
+	t.Setenv(assumeYesEnv, "1")
+	if err := runMoldHooks(mold.HookPostCast, fsys, m, nil, "yes-env-demo", dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hook.out")); err != nil {
+		t.Errorf("hook did not run with AILLOY_YES: %v", err)
+	}
+}
+
+func TestRunMoldHooks_FailureStops(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	fsys := fstest.MapFS{"fail.sh": &fstest.MapFile{Data: []byte("#!/bin/sh\nexit 3\n")}}
+	m := &mold.Mold{Name: "failing", Hooks: mold.Hooks{PreCast: []string{"fail.sh"}}}
+	approveHooksForTest(t, fsys, m, "failing")
+
+	err := runMoldHooks(mold.HookPreCast, fsys, m, nil, "failing", t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "pre-cast hook fail.sh") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRunMoldHooks_ExecPolicy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+	fsys, m := hooksTestMold()
+	approveHooksForTest(t, fsys, m, "demo")
+
+	// --no-exec skips approved hooks.
+	rootNoExec = true
+	err := runMoldHooks(mold.HookPostCast, fsys, m, nil, "demo", dir)
+	rootNoExec = false
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hook.out")); !os.IsNotExist(err) {
+		t.Errorf("hook ran with --no-exec: stat err = %v", err)
+	}
+
+	// An allowlist without sh refuses the (shebang-less) script.
+	if err := os.WriteFile(filepath.Join(home, ".ailloy", "config.yaml"), []byte("exec:\n  allow: [gh]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err = runMoldHooks(mold.HookPostCast, fsys, m, nil, "demo", dir)
+	if err == nil || !strings.Contains(err.Error(), `"sh" is not in the exec allowlist`) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -8,6 +11,7 @@ import (
 	"testing"
 	"testing/fstest"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/mold"
@@ -250,6 +254,60 @@ func TestCopyResolvedFiles_SkipsEmptyRenderedFiles(t *testing.T) {
 	}
 }
 
+func TestCopyResolvedFiles_ParallelKeepsOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	var stdout bytes.Buffer
+	if err := logging.Setup(logging.Options{Stdout: &stdout}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logging.Setup(logging.Options{}) })
+
+	moldFS := fstest.MapFS{
+		"settings-a.json": &fstest.MapFile{Data: []byte(`{"a": 1, "b": 1}`)},
+		"settings-b.json": &fstest.MapFile{Data: []byte(`{"b": 2}`)},
+	}
+	var resolved []mold.ResolvedFile
+	var want []string
+	for i := range 200 {
+		src := fmt.Sprintf("commands/c%03d.md", i)
+		moldFS[src] = &fstest.MapFile{Data: []byte(fmt.Sprintf("# {{ name }} %d\n", i))}
+		dest := filepath.Join(tmpDir, ".claude", src)
+		resolved = append(resolved, mold.ResolvedFile{SrcPath: src, DestPath: dest, Process: true})
+		want = append(want, dest)
+	}
+	settings := filepath.Join(tmpDir, ".claude", "settings.json")
+	for _, src := range []string{"settings-a.json", "settings-b.json"} {
+		resolved = append(resolved, mold.ResolvedFile{SrcPath: src, DestPath: settings, Strategy: "merge"})
+		want = append(want, settings)
+	}
+
+	reader := blanks.NewMoldReader(moldFS)
+	if err := copyResolvedFiles(reader, nil, map[string]any{"name": "x"}, resolved, copyOpts{}); err != nil {
+		t.Fatalf("copyResolvedFiles failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(tmpDir, ".claude", "commands", "c150.md"))
+	if err != nil || string(got) != "# x 150\n" {
+		t.Errorf("c150.md = %q, %v", got, err)
+	}
+	var merged map[string]any
+	data, _ := os.ReadFile(settings)
+	if err := json.Unmarshal(data, &merged); err != nil {
+		t.Fatalf("settings.json: %v", err)
+	}
+	if merged["a"] != float64(1) || merged["b"] != float64(2) {
+		t.Errorf("merge fragments applied out of order: %v", merged)
+	}
+
+	var lines []string
+	for _, l := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(l, "✅ Created:")))
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("created lines out of resolved order:\n%s", stdout.String())
+	}
+}
+
 func TestCleanupEmptyDirs_RemovesEmptyAndAncestors(t *testing.T) {
 	tmp := t.TempDir()
 
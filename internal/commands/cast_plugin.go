@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/plugin"
@@ -46,12 +48,11 @@ type pluginPackageResult struct {
 // function and calls packageMoldAsClaudePlugin directly with a discarding
 // logger, so this CLI path always prints.
 func castClaudePlugin(reader *blanks.MoldReader, source string) error {
-	fmt.Println(styles.WorkingBanner("Casting Ailloy mold as Claude Code plugin..."))
-	fmt.Println()
+	logging.Decor(styles.WorkingBanner("Casting Ailloy mold as Claude Code plugin..."), "")
 
 	flux, _, err := loadCastFlux(reader, source)
 	if err != nil {
-		flux = make(map[string]any)
+		return err
 	}
 
 	res, err := packageMoldAsClaudePlugin(reader, flux, pluginPackageOpts{
@@ -65,11 +66,11 @@ func castClaudePlugin(reader *blanks.MoldReader, source string) error {
 	}
 
 	if withWorkflows && res.HadWorkflows {
-		fmt.Println(styles.WarningStyle.Render("⚠️  --with-workflows has no effect with --claude-plugin: workflow blanks are not bundled into Claude Code plugins."))
+		slog.Warn("--with-workflows has no effect with --claude-plugin: workflow blanks are not bundled into Claude Code plugins")
 	}
-	fmt.Println()
-	fmt.Println(styles.SuccessStyle.Render("✅ Plugin written to ") + styles.CodeStyle.Render(res.TargetDir))
-	fmt.Println(styles.InfoStyle.Render("💡 Claude Code will discover the plugin at this path on its next start."))
+	logging.Decor("")
+	logging.Say(styles.SuccessStyle.Render("✅ Plugin written to ")+styles.CodeStyle.Render(res.TargetDir), "plugin written", "dir", res.TargetDir)
+	logging.Decor(styles.InfoStyle.Render("💡 Claude Code will discover the plugin at this path on its next start."))
 
 	return nil
 }
@@ -145,6 +146,9 @@ func renderMoldFiles(reader *blanks.MoldReader, manifest *mold.Mold, flux map[st
 	if err != nil {
 		return nil, fmt.Errorf("resolving output files: %w", err)
 	}
+	if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+		return nil, err
+	}
 
 	var schema []mold.FluxVar
 	if s, lerr := reader.LoadFluxSchema(); lerr == nil && s != nil {
@@ -152,12 +156,19 @@ func renderMoldFiles(reader *blanks.MoldReader, manifest *mold.Mold, flux map[st
 	} else if manifest != nil && len(manifest.Flux) > 0 {
 		schema = manifest.Flux
 	}
+	for _, w := range append(mold.MigrateFlux(schema, flux), mold.ResolveFlux(schema, flux, time.Now())...) {
+		logger.Printf("warning: %s", w)
+	}
 	if verr := mold.ValidateFlux(schema, flux); verr != nil {
 		logger.Printf("warning: %v", verr)
 	}
 
 	resolver := buildIngotResolver(flux, reader.Root())
 	resolver.FS = reader.FS()
+	applyIngotConstraints(resolver, manifest)
+	if err := attachRemoteIngots(resolver, reader.FS(), resolved); err != nil {
+		return nil, err
+	}
 	tplOpts := []mold.TemplateOption{
 		mold.WithIngotResolver(resolver),
 		mold.WithLogger(logger),
@@ -173,7 +184,7 @@ func renderMoldFiles(reader *blanks.MoldReader, manifest *mold.Mold, flux map[st
 		if rf.Process {
 			processed, perr := mold.ProcessTemplate(string(content), flux, tplOpts...)
 			if perr != nil {
-				return nil, fmt.Errorf("processing %s: %w", rf.SrcPath, perr)
+				return nil, renderError(fmt.Errorf("processing %s: %w", rf.SrcPath, perr))
 			}
 			if strings.TrimSpace(processed) == "" {
 				continue
@@ -216,7 +227,7 @@ func readMoldReadme(reader *blanks.MoldReader, flux map[string]any) ([]byte, err
 	resolver.FS = reader.FS()
 	processed, perr := mold.ProcessTemplate(string(raw), flux, mold.WithIngotResolver(resolver))
 	if perr != nil {
-		return nil, fmt.Errorf("processing mold README.md: %w", perr)
+		return nil, renderError(fmt.Errorf("processing mold README.md: %w", perr))
 	}
 	return []byte(processed), nil
 }
@@ -267,16 +278,17 @@ func slugifyPluginName(name string) (string, error) {
 	return slug, nil
 }
 
-// resolvePluginTargetDir returns the absolute output directory for the plugin.
-// When global is true, plugins go under ~/.claude/plugins/<slug>/; otherwise
+// resolvePluginTargetDir returns the output directory for the plugin. When
+// global is true, plugins go under the user plugins directory
+// (plugin.UserPluginsDir, ~/.claude/plugins/<slug>/ by default); otherwise
 // .claude/plugins/<slug>/ in the working directory.
 func resolvePluginTargetDir(slug string, global bool) (string, error) {
 	if global {
-		homeDir, err := os.UserHomeDir()
+		dir, err := plugin.UserPluginsDir()
 		if err != nil {
-			return "", fmt.Errorf("cannot determine home directory: %w", err)
+			return "", err
 		}
-		return filepath.Join(homeDir, ".claude", "plugins", slug), nil
+		return filepath.Join(dir, slug), nil
 	}
-	return filepath.Join(".claude", "plugins", slug), nil
+	return filepath.Join(plugin.ProjectPluginsDir("."), slug), nil
 }
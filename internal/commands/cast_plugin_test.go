@@ -68,6 +68,21 @@ func TestValidatePluginFlags(t *testing.T) {
 			t.Errorf("unexpected error: %v", err)
 		}
 	})
+	t.Run("--skill without --claude-skills errors", func(t *testing.T) {
+		resetCastFlags()
+		castSkillNames = []string{"hello"}
+		if err := validatePluginFlags(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+	t.Run("--claude-plugin with --claude-skills errors", func(t *testing.T) {
+		resetCastFlags()
+		castClaudePluginFlag = true
+		castClaudeSkillsFlag = true
+		if err := validatePluginFlags(); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
 	t.Run("no plugin flags OK", func(t *testing.T) {
 		resetCastFlags()
 		if err := validatePluginFlags(); err != nil {
@@ -354,10 +369,25 @@ func resetCastFlags() {
 	withWorkflows = false
 	castGlobal = false
 	castSetFlags = nil
+	castSetFileFlags = nil
+	castSetJSONFlags = nil
 	castValFiles = nil
 	castClaudePluginFlag = false
+	castClaudeSkillsFlag = false
+	castCursorRulesFlag = false
+	castOpenCodeFlag = false
+	castCodexFlag = false
+	castTo = ""
+	castSkillNames = nil
 	castPluginName = ""
 	castPluginVer = ""
+	castEphemeral = false
+	castEphemeralDays = 7
+	castAll = false
+	castProfile = ""
+	castOnly = nil
+	castExclude = nil
+	castOffline = false
 }
 
 // chdir switches into dir for the duration of the test, restoring the original
@@ -421,3 +451,38 @@ output:
 	}
 	return blanks.NewMoldReader(fsys)
 }
+
+func TestPluginGenerate_MatchesCastClaudePlugin(t *testing.T) {
+	resetCastFlags()
+	castClaudePluginFlag = true
+	castSetFlags = []string{"greeting=Howdy"}
+	defer resetCastFlags()
+	pluginOutputDir, pluginFormat, pluginSetValues = "generated", plugin.FormatClaude, []string{"greeting=Howdy"}
+	defer func() { pluginOutputDir, pluginFormat, pluginSetValues = "ailloy", plugin.FormatClaude, nil }()
+
+	tmp := t.TempDir()
+	chdir(t, tmp)
+
+	if err := castClaudePlugin(fixtureMoldReader(), ""); err != nil {
+		t.Fatalf("castClaudePlugin: %v", err)
+	}
+	g, err := newPluginGenerator(fixtureMoldReader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	castDir := filepath.Join(tmp, ".claude", "plugins", "fixture-mold")
+	for _, rel := range []string{"commands/hello.md", "skills/demo.md", "AGENTS.md", "README.md", ".claude-plugin/plugin.json"} {
+		want, err := os.ReadFile(filepath.Join(castDir, rel)) // #nosec G304 -- test temp path
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(tmp, "generated", rel)) // #nosec G304 -- test temp path
+		if err != nil || string(got) != string(want) {
+			t.Errorf("%s = %q, %v; want cast's %q", rel, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/merge"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// previewStamp is the cast stamp renders that stand in for a cast (mold
+// test, mold dev, diff) are pinned to, so a blank that prints
+// _ailloy.timestamp or _ailloy.git.commit renders the same on every run.
+var previewStamp = castStamp{
+	At:     time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	Branch: "main",
+	Commit: strings.Repeat("0", 40),
+}
+
+// withPreviewContext is withCastContext for preview renders: the mold's
+// name and version, ailloy version "dev", no repository, and previewStamp.
+// Nothing in it depends on the machine or project the preview runs in.
+func withPreviewContext(flux map[string]any, manifest *mold.Mold) map[string]any {
+	c := mold.CastContext{Version: "dev"}
+	if manifest != nil {
+		c.MoldName, c.MoldVersion = manifest.Name, manifest.Version
+	}
+	flux = mold.ApplyCastContext(flux, c)
+	previewStamp.pin(flux)
+	return flux
+}
+
+// previewCastOutputs renders reader through the cast pipeline and returns
+// what cast would write into a project holding none of the mold's files,
+// keyed by slash-separated destination path. Flux is layered like forge
+// (valFiles and setValues on the mold's defaults) and carries the preview
+// cast context. Large binaries are left out as cast leaves them out;
+// append-strategy files (AGENTS.md) carry the mold's section markers, and,
+// with provenance set, replace-strategy files their provenance header.
+// Failures and flux warnings
+// come back as diagnostics. allowLocalDeps is passed to
+// ResolveDepsEphemeral: false for molds resolved from a remote source.
+func previewCastOutputs(reader *blanks.MoldReader, allowLocalDeps bool, valFiles, setValues []string, provenance bool) (map[string]string, []mold.Diagnostic) {
+	fail := func(file string, err error) (map[string]string, []mold.Diagnostic) {
+		return nil, []mold.Diagnostic{{Severity: mold.SeverityError, File: file, Message: err.Error()}}
+	}
+
+	manifest, err := reader.LoadManifest()
+	if err != nil {
+		return fail("mold.yaml", err)
+	}
+	oreResolver, err := ResolveDepsEphemeral(manifest, allowLocalDeps)
+	if err != nil {
+		return fail("mold.yaml", fmt.Errorf("resolving ore deps: %w", err))
+	}
+	flux, err := loadForgeFlux(reader, oreResolver, valFiles, setValues)
+	if err != nil {
+		return fail("", err)
+	}
+	flux = withPreviewContext(flux, manifest)
+
+	schema, _ := reader.LoadFluxSchema()
+	if schema == nil && len(manifest.Flux) > 0 {
+		schema = manifest.Flux
+	}
+	if merged, _, _, err := oreResolver.MergeInto(schema, nil); err == nil {
+		schema = merged
+	}
+
+	var resolveOpts []mold.ResolveOption
+	if patterns := mold.LoadIgnorePatterns(reader.FS(), manifest); len(patterns) > 0 {
+		resolveOpts = append(resolveOpts, mold.WithIgnorePatterns(patterns))
+	}
+	resolved, err := mold.ResolveFilesWithOreSources(flux["output"], reader.FS(), oreResolver.OreSources(), resolveOpts...)
+	if err != nil {
+		return fail("", fmt.Errorf("resolving output files: %w", err))
+	}
+	if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+		return fail("mold.yaml", err)
+	}
+
+	var warnings bytes.Buffer
+	sources := valuesFluxSources(valFiles, setValues)
+	rendered, err := renderCastFilesProgress(reader, manifest, schema, flux, resolved, log.New(&warnings, "", 0), sources, nil)
+	diags := warningDiagnostics(warnings.String())
+	var broken renderErrors
+	if errors.As(err, &broken) {
+		// Report each broken blank and render the rest, so one bad
+		// template doesn't hide every other output.
+		failed := make(map[string]bool, len(broken))
+		for _, be := range broken {
+			diags = append(diags, mold.Diagnostic{Severity: mold.SeverityError, File: be.Path, Message: be.Err.Error()})
+			failed[be.Path] = true
+		}
+		rest := slices.DeleteFunc(slices.Clone(resolved), func(rf mold.ResolvedFile) bool { return failed[rf.SrcPath] })
+		rendered, err = renderCastFilesProgress(reader, manifest, schema, flux, rest, log.New(io.Discard, "", 0), sources, nil)
+	}
+	if err != nil {
+		return nil, append(diags, mold.Diagnostic{Severity: mold.SeverityError, Message: err.Error()})
+	}
+
+	outputs := make(map[string]string, len(rendered))
+	for _, f := range rendered {
+		content := f.content
+		switch f.Strategy {
+		case "append":
+			content = merge.Block(content, manifest.Name)
+		case "", "replace":
+			content, _ = replaceContent(manifest, f, copyOpts{Provenance: provenance})
+		}
+		outputs[filepath.ToSlash(f.DestPath)] = string(content)
+	}
+	return outputs, diags
+}
+
+// previewMoldDir is previewCastOutputs for the mold directory moldDir,
+// provenance headers included.
+func previewMoldDir(moldDir string, valFiles, setValues []string) (map[string]string, []mold.Diagnostic) {
+	reader, err := blanks.NewMoldReaderFromPath(moldDir)
+	if err != nil {
+		return nil, []mold.Diagnostic{{Severity: mold.SeverityError, Message: err.Error()}}
+	}
+	if reader, err = ComposeMoldReader(reader, ""); err != nil {
+		return nil, []mold.Diagnostic{{Severity: mold.SeverityError, File: "mold.yaml", Message: err.Error()}}
+	}
+	return previewCastOutputs(reader, true, valFiles, setValues, true)
+}
+
+// warningDiagnostics turns the "warning: " lines a render logged into
+// warning diagnostics. Other lines (skipped empty renders) are dropped.
+func warningDiagnostics(logged string) []mold.Diagnostic {
+	var diags []mold.Diagnostic
+	for _, line := range strings.Split(logged, "\n") {
+		if msg, ok := strings.CutPrefix(line, "warning: "); ok {
+			diags = append(diags, mold.Diagnostic{Severity: mold.SeverityWarning, Message: msg})
+		}
+	}
+	return diags
+}
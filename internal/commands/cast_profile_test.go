@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// profileMoldManifest is a mold whose commands land in .claude/commands by
+// default and in .cursor/rules under the "cursor" profile.
+const profileMoldManifest = `apiVersion: v1
+kind: Mold
+name: multi-tool
+version: 0.1.0
+output:
+  commands: .claude/commands
+profiles:
+  cursor:
+    commands: .cursor/rules
+`
+
+func TestCastMold_Profile(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	t.Setenv("HOME", t.TempDir())
+	moldDir := filepath.Join(projectDir, "mold")
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), profileMoldManifest)
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "hi")
+
+	if _, err := CastMold(t.Context(), moldDir, CastOptions{Profile: "cursor"}); err != nil {
+		t.Fatalf("CastMold: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".cursor", "rules", "hello.md")); err != nil {
+		t.Fatalf("expected cursor profile destination: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".claude", "commands")); err == nil {
+		t.Error("default output mapping should not be cast when a profile is selected")
+	}
+}
+
+func TestCastMold_UnknownProfile(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	t.Setenv("HOME", t.TempDir())
+	moldDir := filepath.Join(projectDir, "mold")
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), profileMoldManifest)
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "hi")
+
+	_, err := CastMold(t.Context(), moldDir, CastOptions{Profile: "zed"})
+	if err == nil || !strings.Contains(err.Error(), "available: cursor") {
+		t.Fatalf("expected unknown profile error listing cursor, got %v", err)
+	}
+}
+
+func TestCastMold_ConfigDefaultProfile(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	moldDir := filepath.Join(projectDir, "mold")
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), profileMoldManifest)
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "hi")
+
+	mustWrite(t, filepath.Join(home, ".ailloy", "config.yaml"), "profile: cursor\n")
+
+	if _, err := CastMold(t.Context(), moldDir, CastOptions{}); err != nil {
+		t.Fatalf("CastMold: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".cursor", "rules", "hello.md")); err != nil {
+		t.Fatalf("expected config default profile to apply: %v", err)
+	}
+
+	// A default the mold doesn't declare is ignored rather than failing.
+	mustWrite(t, filepath.Join(home, ".ailloy", "config.yaml"), "profile: codex\n")
+	if _, err := CastMold(t.Context(), moldDir, CastOptions{}); err != nil {
+		t.Fatalf("CastMold with undeclared default profile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".claude", "commands", "hello.md")); err != nil {
+		t.Fatalf("expected default output mapping: %v", err)
+	}
+}
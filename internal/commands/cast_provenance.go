@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/merge"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// localEdits decides what happens to replace-strategy destinations whose
+// provenance header shows they were edited since the mold wrote them: the
+// new render is merged into the edits when bases holds the render the file
+// was cast from, else the file is kept as it is.
+type localEdits struct {
+	bases map[string][]byte // by DestPath
+
+	mu     sync.Mutex
+	merged []string
+	kept   []string
+}
+
+// replaceContent returns what a replace-strategy write puts at f's
+// destination — the render, stamped with a provenance header unless the
+// mold or the file opts out — and false when a locally edited file is kept.
+func replaceContent(manifest *mold.Mold, f castRenderedFile, opts copyOpts) ([]byte, bool) {
+	if manifest == nil {
+		return f.content, true
+	}
+	prov := mold.Provenance{Mold: manifest.Name, Version: manifest.Version}
+	stamp := opts.Provenance && manifest.StampsProvenance() && !f.NoProvenance
+
+	if opts.Edits != nil {
+		existing, err := os.ReadFile(f.DestPath) // #nosec G304 -- cast destination
+		if err == nil {
+			if p, body, ok := mold.ReadProvenance(f.DestPath, existing); ok && p.Mold == manifest.Name && p.Modified(body) {
+				return opts.Edits.resolve(f.DestPath, p, body, f.content, stamp, prov, opts.logger())
+			}
+		}
+	}
+	if !stamp {
+		return f.content, true
+	}
+	return mold.StampProvenance(f.DestPath, f.content, prov), true
+}
+
+// resolve merges render into local, the edited content of dest (stamped
+// from the render p records), when the base it was cast from is known and
+// the changes don't overlap. The merged file keeps a header for render, so
+// it still reads as edited. Otherwise dest is kept.
+func (e *localEdits) resolve(dest string, p mold.Provenance, local, render []byte, stamp bool, prov mold.Provenance, logger *log.Logger) ([]byte, bool) {
+	if base, ok := e.bases[dest]; ok && hashBytes(base) == p.SHA256 {
+		merged, clean, err := merge.ThreeWay(local, base, render)
+		if err != nil {
+			logger.Printf("warning: merging local edits into %s: %v", dest, err)
+		}
+		if err == nil && clean {
+			e.record(&e.merged, dest)
+			if !stamp {
+				return merged, true
+			}
+			prov.SHA256 = hashBytes(render)
+			return mold.StampProvenance(dest, merged, prov), true
+		}
+	}
+	e.record(&e.kept, dest)
+	return nil, false
+}
+
+func (e *localEdits) record(list *[]string, dest string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	*list = append(*list, dest)
+}
+
+// editedFiles returns the files of entry under root whose provenance
+// header shows local edits.
+func editedFiles(root string, entry *foundry.InstalledEntry) []string {
+	var edited []string
+	for _, rel := range entry.Files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		data, err := os.ReadFile(path) // #nosec G304 -- recorded cast destination
+		if err != nil {
+			continue
+		}
+		if p, body, ok := mold.ReadProvenance(path, data); ok && p.Mold == entry.Name && p.Modified(body) {
+			edited = append(edited, rel)
+		}
+	}
+	return edited
+}
+
+// recastMergeBases renders the installed version of entry with the options
+// it was cast with — what its locally edited files were stamped from — for
+// recast to merge those edits against. It returns nil when nothing was
+// edited or that version can't be rendered (the edited files are then kept).
+func recastMergeBases(ctx context.Context, root string, entry *foundry.InstalledEntry, ref *foundry.Reference, global bool) map[string][]byte {
+	if entry.Version == "" || len(editedFiles(root, entry)) == 0 {
+		return nil
+	}
+	fetcher, err := foundry.NewFetcher(foundry.DefaultGitRunner())
+	if err != nil {
+		return nil
+	}
+	fsys, _, err := fetcher.Fetch(ref, &foundry.ResolvedVersion{Tag: entry.Version, Commit: entry.Commit})
+	if err != nil {
+		return nil
+	}
+	silent := log.New(io.Discard, "", 0)
+	reader, err := ComposeMoldReader(blanks.NewMoldReader(fsys), ref.OverrideKey(), foundry.WithLogger(silent))
+	if err != nil {
+		return nil
+	}
+	opts := CastOptions{Global: global}
+	if rec := entry.CastOptions; rec != nil {
+		opts.WithWorkflows = rec.WithWorkflows
+		opts.CI = rec.CI
+		opts.ValueFiles = rec.ValueFiles
+		opts.SetOverrides = rec.SetOverrides
+		opts.SetFiles = rec.SetFiles
+		opts.SetJSON = rec.SetJSON
+		opts.Profile = rec.Profile
+	}
+	plan, err := PlanCastMold(ctx, reader, ref.OverrideKey(), opts)
+	if err != nil {
+		return nil
+	}
+	bases := make(map[string][]byte, len(plan))
+	for _, pf := range plan {
+		if _, body, ok := mold.ReadProvenance(pf.Path, pf.Content); ok {
+			bases[pf.Path] = body
+		}
+	}
+	return bases
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// castedBody returns the file cast wrote at path without its provenance
+// header.
+func castedBody(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatal(err)
+	}
+	return withoutProvenance(path, data)
+}
+
+func withoutProvenance(path string, data []byte) string {
+	if _, body, ok := mold.ReadProvenance(path, data); ok {
+		return string(body)
+	}
+	return string(data)
+}
+
+func castFile(dest, content string) castRenderedFile {
+	return castRenderedFile{ResolvedFile: mold.ResolvedFile{DestPath: dest}, content: []byte(content)}
+}
+
+func TestReplaceContent_Provenance(t *testing.T) {
+	manifest := &mold.Mold{Name: "tools", Version: "1.2.0"}
+	f := castFile("cmd.md", "# deploy\n")
+
+	got, write := replaceContent(manifest, f, copyOpts{Provenance: true})
+	p, body, ok := mold.ReadProvenance("cmd.md", got)
+	if !write || !ok || p.Mold != "tools" || p.Version != "1.2.0" || string(body) != "# deploy\n" || p.Modified(body) {
+		t.Errorf("stamped = %q", got)
+	}
+
+	off := false
+	for name, tc := range map[string]struct {
+		manifest *mold.Mold
+		file     castRenderedFile
+		opts     copyOpts
+	}{
+		"not asked":        {manifest, f, copyOpts{}},
+		"mold opts out":    {&mold.Mold{Name: "tools", Provenance: &off}, f, copyOpts{Provenance: true}},
+		"file opts out":    {manifest, castRenderedFile{ResolvedFile: mold.ResolvedFile{DestPath: "cmd.md", NoProvenance: true}, content: f.content}, copyOpts{Provenance: true}},
+		"no comment style": {manifest, castFile("settings.json", `{"a": 1}`), copyOpts{Provenance: true}},
+	} {
+		if got, _ := replaceContent(tc.manifest, tc.file, tc.opts); string(got) != string(tc.file.content) {
+			t.Errorf("%s: wrote %q, want the render as is", name, got)
+		}
+	}
+}
+
+func TestReplaceContent_LocalEdits(t *testing.T) {
+	chdir(t, t.TempDir())
+	manifest := &mold.Mold{Name: "tools", Version: "2.0.0"}
+	base := "# deploy\n\nrun make\n\nthen tag\n"
+	edited := "# deploy\n\nrun make\n\nthen tag\nand tell the team\n"
+	render := "# deploy (v2)\n\nrun make\n\nthen tag\n"
+
+	castEdited := func(dest string) {
+		stamped := mold.StampProvenance(dest, []byte(edited), mold.Provenance{Mold: "tools", SHA256: hashBytes([]byte(base))})
+		mustWrite(t, dest, string(stamped))
+	}
+
+	// Without the base the edited file is kept.
+	castEdited("kept.md")
+	edits := &localEdits{}
+	if _, write := replaceContent(manifest, castFile("kept.md", render), copyOpts{Provenance: true, Edits: edits}); write {
+		t.Error("an edited file with no merge base should be kept")
+	}
+
+	// With it the render is merged into the edits.
+	castEdited("merged.md")
+	edits.bases = map[string][]byte{"merged.md": []byte(base)}
+	got, write := replaceContent(manifest, castFile("merged.md", render), copyOpts{Provenance: true, Edits: edits})
+	p, body, ok := mold.ReadProvenance("merged.md", got)
+	if !write || !ok || string(body) != "# deploy (v2)\n\nrun make\n\nthen tag\nand tell the team\n" {
+		t.Fatalf("merged = %q", got)
+	}
+	if p.SHA256 != hashBytes([]byte(render)) || !p.Modified(body) {
+		t.Errorf("merged header = %+v, want the render's hash so the file still reads as edited", p)
+	}
+
+	// Overlapping changes keep the file.
+	castEdited("conflict.md")
+	edits.bases["conflict.md"] = []byte(base)
+	conflicting := strings.Replace(base, "then tag\n", "then push\n", 1)
+	if _, write := replaceContent(manifest, castFile("conflict.md", conflicting+"and tell the team\nbut not yet\n"), copyOpts{Provenance: true, Edits: edits}); write {
+		t.Error("a conflicting merge should keep the file")
+	}
+
+	if strings.Join(edits.kept, ",") != "kept.md,conflict.md" || strings.Join(edits.merged, ",") != "merged.md" {
+		t.Errorf("kept = %v, merged = %v", edits.kept, edits.merged)
+	}
+
+	// Unedited files are overwritten.
+	mustWrite(t, "clean.md", string(mold.StampProvenance("clean.md", []byte(base), mold.Provenance{Mold: "tools"})))
+	if got, write := replaceContent(manifest, castFile("clean.md", render), copyOpts{Provenance: true, Edits: edits}); !write || withoutProvenance("clean.md", got) != render {
+		t.Errorf("clean = %q", got)
+	}
+}
@@ -1,12 +1,16 @@
 package commands
 
 import (
+	"bytes"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
 func TestEnforceAilloyVersion(t *testing.T) {
@@ -63,6 +67,54 @@ func TestEnforceAilloyVersion_ErrorMessage(t *testing.T) {
 	}
 }
 
+func TestRequireAilloy_IgnoreRequires(t *testing.T) {
+	orig := evolveCurrentVersion
+	t.Cleanup(func() { evolveCurrentVersion = orig; castIgnoreRequires = false })
+	evolveCurrentVersion = "0.6.32"
+
+	err := requireAilloy("ore jira", ">=0.7.0")
+	if err == nil || !strings.Contains(err.Error(), "ore jira requires ailloy >=0.7.0") || !strings.Contains(err.Error(), "--ignore-requires") {
+		t.Fatalf("err = %v, want an error naming the ore and --ignore-requires", err)
+	}
+
+	castIgnoreRequires = true
+	var logs bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	if err := requireAilloy("ore jira", ">=0.7.0"); err != nil {
+		t.Fatalf("with --ignore-requires: %v", err)
+	}
+	if !strings.Contains(logs.String(), "warning: ore jira requires ailloy >=0.7.0, but you are running v0.6.32; casting anyway") {
+		t.Errorf("expected a warning, got %q", logs.String())
+	}
+}
+
+func TestTemperPackage_Requires(t *testing.T) {
+	orig := evolveCurrentVersion
+	t.Cleanup(func() { evolveCurrentVersion = orig; temperIgnoreRequires = false })
+	evolveCurrentVersion = "0.6.32"
+	fsys := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: gated\nversion: 0.1.0\nrequires:\n  ailloy: \">=0.7.0\"\n")},
+	}
+
+	find := func(result *mold.TemperResult) *mold.Diagnostic {
+		for i, d := range result.Diagnostics {
+			if strings.Contains(d.Message, "requires ailloy >=0.7.0") {
+				return &result.Diagnostics[i]
+			}
+		}
+		return nil
+	}
+	if d := find(temperPackage(fsys, "", true)); d == nil || d.Severity != mold.SeverityError {
+		t.Fatalf("want an error diagnostic, got %+v", d)
+	}
+	temperIgnoreRequires = true
+	if d := find(temperPackage(fsys, "", true)); d == nil || d.Severity != mold.SeverityWarning {
+		t.Fatalf("want a warning with --ignore-requires, got %+v", d)
+	}
+}
+
 // TestCheckAilloyRequirement reads requires.ailloy straight from a mold.yaml on
 // disk and enforces it against the running version.
 func TestCheckAilloyRequirement(t *testing.T) {
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// castJournal records what a cast's destinations held before it wrote them,
+// so a cast that fails part way through can put the project back as it was.
+type castJournal struct {
+	files []journaledFile
+	dirs  []string // directories the cast may create, deepest first
+}
+
+type journaledFile struct {
+	path    string
+	existed bool
+	data    []byte
+	mode    fs.FileMode
+	// target is where writes to path land: path itself, or the file a
+	// symlink at path resolves to. link is that symlink's own target, as
+	// written, when path is one.
+	target string
+	link   string
+}
+
+// journalDests snapshots every destination in dests and notes which of
+// their parent directories don't exist yet. A symlinked destination is
+// snapshotted through the link, since writing it writes the file the link
+// points at.
+func journalDests(dests []string) (*castJournal, error) {
+	j := &castJournal{}
+	seen := map[string]bool{}
+	missingDirs := map[string]bool{}
+	for _, dest := range dests {
+		if seen[dest] {
+			continue
+		}
+		seen[dest] = true
+		jf := journaledFile{path: dest, target: dest}
+		if info, err := os.Lstat(dest); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			if jf.link, err = os.Readlink(dest); err != nil {
+				return nil, fmt.Errorf("checking %s: %w", dest, err)
+			}
+			if jf.target, err = resolveLink(dest); err != nil {
+				return nil, fmt.Errorf("checking %s: %w", dest, err)
+			}
+		}
+		info, err := os.Stat(jf.target)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			j.files = append(j.files, jf)
+			for dir := filepath.Dir(jf.target); dir != "." && filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+				if _, err := os.Stat(dir); err == nil {
+					break
+				}
+				missingDirs[dir] = true
+			}
+		case err != nil:
+			return nil, fmt.Errorf("checking %s: %w", dest, err)
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(jf.target) // #nosec G304 -- cast destination
+			if err != nil {
+				return nil, fmt.Errorf("backing up %s: %w", dest, err)
+			}
+			jf.existed, jf.data, jf.mode = true, data, info.Mode().Perm()
+			j.files = append(j.files, jf)
+		}
+		// Anything else (a directory, a device) can't be written over, so
+		// the cast fails on it without changing it.
+	}
+	for dir := range missingDirs {
+		j.dirs = append(j.dirs, dir)
+	}
+	sort.Slice(j.dirs, func(a, b int) bool { return len(j.dirs[a]) > len(j.dirs[b]) })
+	return j, nil
+}
+
+// maxLinkHops bounds how many symlinks resolveLink follows.
+const maxLinkHops = 40
+
+// resolveLink follows the symlink at p, and any it leads to, to the path a
+// write to p lands on. Unlike filepath.EvalSymlinks it works when the final
+// target doesn't exist yet.
+func resolveLink(p string) (string, error) {
+	for range maxLinkHops {
+		info, err := os.Lstat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			return p, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return p, nil
+		}
+		link, err := os.Readlink(p)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(p), link)
+		}
+		p = link
+	}
+	return "", fmt.Errorf("too many levels of symbolic links")
+}
+
+// rollback restores every journaled destination: files that existed get
+// their old content back, new files and the directories made for them are
+// removed. Symlinked destinations are restored through the link; the link
+// itself is left alone. It carries on past failures and reports them together.
+func (j *castJournal) rollback() error {
+	var errs []error
+	for _, f := range j.files {
+		if !f.existed {
+			if err := os.Remove(f.target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if current, err := os.ReadFile(f.target); err == nil && bytes.Equal(current, f.data) { // #nosec G304 -- cast destination
+			continue
+		}
+		if err := os.WriteFile(f.target, f.data, f.mode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, dir := range j.dirs {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			_ = os.Remove(dir)
+		}
+	}
+	return errors.Join(errs...)
+}
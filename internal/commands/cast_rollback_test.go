@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestCopyResolvedFiles_RollsBackOnWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "CLAUDE.md")
+	settings := filepath.Join(dir, "settings.json")
+	mustWrite(t, existing, "hand-written\n")
+	mustWrite(t, settings, "{not json")
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"CLAUDE.md":             {Data: []byte("from the mold\n")},
+		"commands/review.md":    {Data: []byte("# Review\n")},
+		"settings.json.partial": {Data: []byte(`{"a": 1}`)},
+	})
+	resolved := []mold.ResolvedFile{
+		{SrcPath: "CLAUDE.md", DestPath: existing},
+		{SrcPath: "commands/review.md", DestPath: filepath.Join(dir, ".claude", "commands", "review.md")},
+		{SrcPath: "settings.json.partial", DestPath: settings, Strategy: "merge"},
+	}
+
+	err := copyResolvedFiles(reader, nil, map[string]any{}, resolved, copyOpts{Silent: true})
+	if err == nil || !strings.Contains(err.Error(), "restored") {
+		t.Fatalf("want a merge failure reporting the rollback, got %v", err)
+	}
+	if got := castedBody(t, existing); got != "hand-written\n" {
+		t.Errorf("CLAUDE.md = %q, want its original content", got)
+	}
+	if got := castedBody(t, settings); got != "{not json" {
+		t.Errorf("settings.json = %q, want it untouched", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".claude")); !os.IsNotExist(err) {
+		t.Errorf(".claude should be removed with the file created in it, stat err = %v", err)
+	}
+}
+
+func TestCastJournal_RollbackKeepsUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.md")
+	mustWrite(t, kept, "same\n")
+	before, err := os.Stat(kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := journalDests([]string{kept, kept, filepath.Join(dir, "a", "b", "new.md")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.files) != 2 || len(j.dirs) != 2 || j.dirs[0] != filepath.Join(dir, "a", "b") {
+		t.Fatalf("journal = %+v, want 2 files and a/b before a", j)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "a", "b"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(dir, "a", "b", "new.md"), "x")
+	if err := j.rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Errorf("a/ should be removed, stat err = %v", err)
+	}
+	after, err := os.Stat(kept)
+	if err != nil || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("an unchanged file should not be rewritten (err %v)", err)
+	}
+}
+
+func TestCopyResolvedFiles_RollsBackThroughSymlinkedDest(t *testing.T) {
+	dir := t.TempDir()
+	agents := filepath.Join(dir, "AGENTS.md")
+	claude := filepath.Join(dir, "CLAUDE.md")
+	settings := filepath.Join(dir, "settings.json")
+	mustWrite(t, agents, "hand-written\n")
+	mustWrite(t, settings, "{not json")
+	if err := os.Symlink("AGENTS.md", claude); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	// A dangling link: the cast creates the file it points at.
+	dangling := filepath.Join(dir, "GEMINI.md")
+	if err := os.Symlink(filepath.Join("docs", "gemini.md"), dangling); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"CLAUDE.md":             {Data: []byte("from the mold\n")},
+		"GEMINI.md":             {Data: []byte("from the mold\n")},
+		"settings.json.partial": {Data: []byte(`{"a": 1}`)},
+	})
+	resolved := []mold.ResolvedFile{
+		{SrcPath: "CLAUDE.md", DestPath: claude},
+		{SrcPath: "GEMINI.md", DestPath: dangling},
+		{SrcPath: "settings.json.partial", DestPath: settings, Strategy: "merge"},
+	}
+
+	err := copyResolvedFiles(reader, nil, map[string]any{}, resolved, copyOpts{Silent: true})
+	if err == nil || !strings.Contains(err.Error(), "restored") {
+		t.Fatalf("want a merge failure reporting the rollback, got %v", err)
+	}
+	if got := castedBody(t, agents); got != "hand-written\n" {
+		t.Errorf("AGENTS.md (behind the CLAUDE.md link) = %q, want its original content", got)
+	}
+	if link, err := os.Readlink(claude); err != nil || link != "AGENTS.md" {
+		t.Errorf("CLAUDE.md link = %q, %v; want it kept", link, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs")); !os.IsNotExist(err) {
+		t.Errorf("docs/ created through the dangling link should be removed, stat err = %v", err)
+	}
+	if _, err := os.Lstat(dangling); err != nil {
+		t.Errorf("dangling link should be kept: %v", err)
+	}
+}
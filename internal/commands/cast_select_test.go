@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestCastProject_OnlyAndExclude(t *testing.T) {
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml":             &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: select-mold\nversion: 1.0.0\ncomponents:\n  review: [commands/pr-review.md]\n")},
+		"flux.yaml":             &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n")},
+		"commands/pr-create.md": &fstest.MapFile{Data: []byte("# create\n")},
+		"commands/pr-review.md": &fstest.MapFile{Data: []byte("# review\n")},
+		"commands/deploy.md":    &fstest.MapFile{Data: []byte("# deploy\n")},
+	})
+
+	resetCastFlags()
+	defer resetCastFlags()
+	chdir(t, t.TempDir())
+	castOnly = []string{"commands/pr-*"}
+	castExclude = []string{"review"}
+	if err := castProject(reader, ""); err != nil {
+		t.Fatalf("castProject: %v", err)
+	}
+	for file, want := range map[string]bool{
+		".claude/commands/pr-create.md": true,
+		".claude/commands/pr-review.md": false,
+		".claude/commands/deploy.md":    false,
+	} {
+		if _, err := os.Stat(file); (err == nil) != want {
+			t.Errorf("%s cast = %v, want %v", file, err == nil, want)
+		}
+	}
+
+	castOnly, castExclude = []string{"commands/typo-*"}, nil
+	if err := castProject(reader, ""); err == nil || !strings.Contains(err.Error(), "selects no blanks") {
+		t.Errorf("err = %v, want an unmatched --only error", err)
+	}
+}
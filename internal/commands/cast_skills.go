@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+)
+
+// castClaudeSkills is the CLI entrypoint for `ailloy cast --claude-skills`.
+// It renders the mold through the same pipeline as --claude-plugin, compiles
+// command blanks into Claude Skills, validates them against the skills spec,
+// and writes them to .claude/skills/ (or ~/.claude/skills/ with --global).
+func castClaudeSkills(reader *blanks.MoldReader, source string) error {
+	logging.Decor(styles.WorkingBanner("Compiling Ailloy mold into Claude Skills..."), "")
+
+	flux, _, err := loadCastFlux(reader, source)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := reader.LoadManifest()
+	if err != nil {
+		return fmt.Errorf("loading mold manifest: %w", err)
+	}
+	rendered, err := renderMoldFiles(reader, manifest, flux, log.Default())
+	if err != nil {
+		return err
+	}
+
+	skills, err := plugin.CompileSkills(rendered, castSkillNames)
+	if err != nil {
+		return err
+	}
+	if len(skills) == 0 {
+		return fmt.Errorf("mold has no command or skill blanks to compile")
+	}
+	if err := validateSkills(skills); err != nil {
+		return err
+	}
+
+	targetDir, err := resolveSkillsTargetDir(castGlobal)
+	if err != nil {
+		return err
+	}
+	w := &plugin.SkillWriter{OutputDir: targetDir}
+	if err := w.Write(skills); err != nil {
+		return err
+	}
+
+	for _, s := range skills {
+		path := filepath.Join(targetDir, s.Name, "SKILL.md")
+		logging.Say(styles.SuccessStyle.Render("✅ Compiled skill ")+styles.CodeStyle.Render(path), "compiled skill", "path", path)
+	}
+	logging.Decor("", styles.InfoStyle.Render("💡 Claude Code will discover these skills on its next start."))
+	return nil
+}
+
+// validateSkills reports every spec violation across skills at once so a
+// mold author can fix them in a single pass.
+func validateSkills(skills []*plugin.Skill) error {
+	var lines []string
+	for _, s := range skills {
+		for _, p := range s.Validate() {
+			lines = append(lines, fmt.Sprintf("  %s (%s): %s", s.Name, s.Source, p))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("skills failed validation:\n%s", strings.Join(lines, "\n"))
+}
+
+// resolveSkillsTargetDir returns .claude/skills in the working directory, or
+// ~/.claude/skills when global is true.
+func resolveSkillsTargetDir(global bool) (string, error) {
+	if global {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		return filepath.Join(homeDir, ".claude", "skills"), nil
+	}
+	return filepath.Join(".claude", "skills"), nil
+}
@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+)
+
+func TestCastClaudeSkills_FullPipeline(t *testing.T) {
+	resetCastFlags()
+	castClaudeSkillsFlag = true
+	defer resetCastFlags()
+
+	tmp := t.TempDir()
+	chdir(t, tmp)
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: skills-mold\nversion: 1.0.0\n")},
+		"flux.yaml": &fstest.MapFile{Data: []byte("greeting: Hello\noutput:\n  commands: .claude/commands\n")},
+		"commands/greeting-users.md": &fstest.MapFile{Data: []byte(
+			"---\ndescription: Greets users. Use when starting a session.\n---\n# Greet\n{{ .greeting }}, world!\n")},
+		"commands/greeting-users/examples.md": &fstest.MapFile{Data: []byte("# Examples\n")},
+	})
+	if err := castClaudeSkills(reader, ""); err != nil {
+		t.Fatalf("castClaudeSkills: %v", err)
+	}
+
+	skillDir := filepath.Join(tmp, ".claude", "skills", "greeting-users")
+	data, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if err != nil {
+		t.Fatalf("reading SKILL.md: %v", err)
+	}
+	got := string(data)
+	if !strings.Contains(got, "name: greeting-users") || !strings.Contains(got, "Hello, world!") {
+		t.Errorf("unexpected SKILL.md:\n%s", got)
+	}
+	mustFile(t, filepath.Join(skillDir, "examples.md"))
+	if _, err := os.Stat(filepath.Join(tmp, ".claude", "commands")); !os.IsNotExist(err) {
+		t.Error("--claude-skills should not install command blanks")
+	}
+}
+
+func TestCastClaudeSkills_ValidationFails(t *testing.T) {
+	resetCastFlags()
+	castClaudeSkillsFlag = true
+	defer resetCastFlags()
+
+	chdir(t, t.TempDir())
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml":            &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: bad\nversion: 1.0.0\n")},
+		"flux.yaml":            &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n")},
+		"commands/Bad_Name.md": &fstest.MapFile{Data: []byte("# Only a heading\n")},
+	})
+	err := castClaudeSkills(reader, "")
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"Bad_Name", "lowercase", "description is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+}
+
+func TestCastCursorRules(t *testing.T) {
+	resetCastFlags()
+	castCursorRulesFlag = true
+	defer resetCastFlags()
+
+	tmp := t.TempDir()
+	chdir(t, tmp)
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: rules-mold\nversion: 1.0.0\n")},
+		"flux.yaml": &fstest.MapFile{Data: []byte("greeting: Hello\noutput:\n  commands: .claude/commands\n")},
+		"commands/greet.md": &fstest.MapFile{Data: []byte(
+			"---\ndescription: Greets users.\nglobs: \"*.md\"\n---\n# Greet\n{{ .greeting }}, world!\n")},
+	})
+	if err := castWithAdapter(reader, "", plugin.CursorAdapter{}); err != nil {
+		t.Fatalf("castWithAdapter: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmp, ".cursor", "rules", "greet.mdc"))
+	if err != nil {
+		t.Fatalf("reading rule: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{"description: Greets users.", "globs: *.md", "alwaysApply: false", "Hello, world!"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rule missing %q:\n%s", want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmp, ".claude", "commands")); !os.IsNotExist(err) {
+		t.Error("--cursor-rules should not install command blanks")
+	}
+}
+
+func TestValidatePluginFlags_CursorRules(t *testing.T) {
+	defer resetCastFlags()
+
+	resetCastFlags()
+	castCursorRulesFlag = true
+	castClaudeSkillsFlag = true
+	if err := validatePluginFlags(); err == nil {
+		t.Error("expected --cursor-rules with --claude-skills to error")
+	}
+
+	resetCastFlags()
+	castCursorRulesFlag = true
+	castGlobal = true
+	if err := validatePluginFlags(); err == nil {
+		t.Error("expected --cursor-rules with --global to error")
+	}
+}
+
+func TestCastOpenCode(t *testing.T) {
+	resetCastFlags()
+	castOpenCodeFlag = true
+	defer resetCastFlags()
+
+	tmp := t.TempDir()
+	chdir(t, tmp)
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: oc-mold\nversion: 1.0.0\n")},
+		"flux.yaml": &fstest.MapFile{Data: []byte("greeting: Hello\noutput:\n  commands: .claude/commands\n")},
+		"commands/greet.md": &fstest.MapFile{Data: []byte(
+			"---\ndescription: Greets users.\nagent: build\n---\n# Greet\n{{ .greeting }}, $ARGUMENTS!\n")},
+	})
+	if err := castWithAdapter(reader, "", plugin.OpenCodeAdapter{}); err != nil {
+		t.Fatalf("castWithAdapter: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmp, ".opencode", "command", "greet.md"))
+	if err != nil {
+		t.Fatalf("reading command: %v", err)
+	}
+	for _, want := range []string{"description: Greets users.", "agent: build", "Hello, $ARGUMENTS!"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("command missing %q:\n%s", want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "opencode.json")); err != nil {
+		t.Errorf("expected opencode.json: %v", err)
+	}
+}
+
+func TestCastOpenCode_Global(t *testing.T) {
+	resetCastFlags()
+	castOpenCodeFlag = true
+	castGlobal = true
+	defer resetCastFlags()
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	chdir(t, t.TempDir())
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml":         &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: oc-mold\nversion: 1.0.0\n")},
+		"flux.yaml":         &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n")},
+		"commands/greet.md": &fstest.MapFile{Data: []byte("# Greet\nHi.\n")},
+	})
+	if err := castWithAdapter(reader, "", plugin.OpenCodeAdapter{}); err != nil {
+		t.Fatalf("castWithAdapter: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configHome, "opencode", "command", "greet.md")); err != nil {
+		t.Errorf("expected global command: %v", err)
+	}
+}
+
+func TestCastCodex(t *testing.T) {
+	resetCastFlags()
+	castCodexFlag = true
+	defer resetCastFlags()
+
+	tmp := t.TempDir()
+	chdir(t, tmp)
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: codex-mold\nversion: 1.0.0\n")},
+		"flux.yaml": &fstest.MapFile{Data: []byte("greeting: Hello\noutput:\n  commands: .claude/commands\n  skills: .claude/skills\n")},
+		"commands/greet.md": &fstest.MapFile{Data: []byte(
+			"---\ndescription: Greets users.\n---\n{{ .greeting }}, $1!\n")},
+		"skills/style/SKILL.md": &fstest.MapFile{Data: []byte(
+			"---\ndescription: House style.\n---\n# Style\nBe terse.\n")},
+	})
+	if err := castWithAdapter(reader, "", plugin.CodexAdapter{}); err != nil {
+		t.Fatalf("castWithAdapter: %v", err)
+	}
+
+	prompt, err := os.ReadFile(filepath.Join(codexHome, "prompts", "greet.md"))
+	if err != nil {
+		t.Fatalf("reading prompt: %v", err)
+	}
+	if !strings.Contains(string(prompt), "Hello, $1!") {
+		t.Errorf("unexpected prompt:\n%s", prompt)
+	}
+	agents, err := os.ReadFile(filepath.Join(tmp, "AGENTS.md"))
+	if err != nil {
+		t.Fatalf("reading AGENTS.md: %v", err)
+	}
+	for _, want := range []string{"ailloy:mold=codex-mold:start", "## style", "House style.", "### Style"} {
+		if !strings.Contains(string(agents), want) {
+			t.Errorf("AGENTS.md missing %q:\n%s", want, agents)
+		}
+	}
+}
+
+func TestValidatePluginFlags_OutputCombos(t *testing.T) {
+	defer resetCastFlags()
+
+	resetCastFlags()
+	castOpenCodeFlag = true
+	castCodexFlag = true
+	err := validatePluginFlags()
+	if err == nil || !strings.Contains(err.Error(), "--opencode and --codex") {
+		t.Errorf("expected --opencode with --codex to error, got %v", err)
+	}
+
+	resetCastFlags()
+	castCodexFlag = true
+	castGlobal = true
+	if err := validatePluginFlags(); err != nil {
+		t.Errorf("--codex with --global should be allowed, got %v", err)
+	}
+}
+
+func TestCastWithAdapter_Windsurf(t *testing.T) {
+	resetCastFlags()
+	castTo = plugin.FormatWindsurf
+	defer resetCastFlags()
+
+	tmp := t.TempDir()
+	chdir(t, tmp)
+
+	reader := blanks.NewMoldReader(fstest.MapFS{
+		"mold.yaml":             &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: wind-mold\nversion: 1.0.0\n")},
+		"flux.yaml":             &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n  skills: .claude/skills\n")},
+		"commands/deploy.md":    &fstest.MapFile{Data: []byte("---\ndescription: Deploys.\n---\nShip it.\n")},
+		"skills/style/SKILL.md": &fstest.MapFile{Data: []byte("Be terse.\n")},
+	})
+	if err := castWithAdapter(reader, "", plugin.WindsurfAdapter{}); err != nil {
+		t.Fatalf("castWithAdapter: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, ".windsurf", "workflows", "deploy.md")); err != nil {
+		t.Errorf("expected workflow: %v", err)
+	}
+	rules, err := os.ReadFile(filepath.Join(tmp, ".windsurfrules"))
+	if err != nil || !strings.Contains(string(rules), "ailloy:mold=wind-mold:start") {
+		t.Errorf(".windsurfrules = %q, err = %v", rules, err)
+	}
+}
+
+func TestValidatePluginFlags_To(t *testing.T) {
+	defer resetCastFlags()
+
+	resetCastFlags()
+	castTo = "nope"
+	if err := validatePluginFlags(); err == nil || !strings.Contains(err.Error(), "unknown --to") {
+		t.Errorf("expected unknown adapter error, got %v", err)
+	}
+
+	resetCastFlags()
+	castTo = plugin.FormatJetBrains
+	castGlobal = true
+	if err := validatePluginFlags(); err == nil || !strings.Contains(err.Error(), "project-scoped") {
+		t.Errorf("expected --to jetbrains with --global to error, got %v", err)
+	}
+
+	resetCastFlags()
+	castTo = plugin.FormatWindsurf
+	castCursorRulesFlag = true
+	if err := validatePluginFlags(); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("expected --to with --cursor-rules to error, got %v", err)
+	}
+}
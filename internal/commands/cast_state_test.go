@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
 // Regression: writeInstallState must merge with the existing state.yaml
@@ -66,6 +68,100 @@ func TestWriteInstallState_FreshDirCreatesFile(t *testing.T) {
 	}
 }
 
+func TestWriteInstallState_RecordsFileProvenance(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(".claude/commands", 0750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, ".claude/commands/a.md", "a")
+	mustWrite(t, ".claude/commands/b.md", "b")
+	mustWrite(t, ".claude/commands/other.md", "o")
+
+	m := &mold.Mold{Name: "alpha", Version: "1.0.0"}
+	first := installedBlanksFor(m, "", "", []mold.ResolvedFile{
+		{SrcPath: "commands/a.md", DestPath: ".claude/commands/a.md"},
+		{SrcPath: "commands/b.md", DestPath: ".claude/commands/b.md"},
+		{SrcPath: "commands/empty.md", DestPath: ".claude/commands/empty.md"}, // never written
+	})
+	other := installedBlanksFor(&mold.Mold{Name: "beta"}, "github.com/x/beta", "v2.0.0", []mold.ResolvedFile{
+		{SrcPath: "commands/other.md", DestPath: ".claude/commands/other.md", Origin: "shared"},
+	})
+	if err := writeInstallState([]string{".claude/commands"}, append(first, other...)...); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-cast alpha producing only a.md: b.md's stale entry goes, beta stays.
+	if err := writeInstallState([]string{".claude/commands"}, first[0]); err != nil {
+		t.Fatal(err)
+	}
+	state, err := loadInstallStateForTest(installStatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dests []string
+	for _, f := range state.Files {
+		dests = append(dests, f.Dest)
+	}
+	if want := []string{".claude/commands/a.md", ".claude/commands/other.md"}; !reflect.DeepEqual(dests, want) {
+		t.Fatalf("dests = %v, want %v", dests, want)
+	}
+	if a := state.Files[0]; a.Mold != "alpha" || a.Version != "1.0.0" || a.SrcPath != "commands/a.md" || a.SHA256 == "" {
+		t.Errorf("alpha entry = %+v", a)
+	}
+	if b := state.Files[1]; b.Source != "github.com/x/beta" || b.Version != "v2.0.0" || b.Origin != "shared" {
+		t.Errorf("beta entry = %+v", b)
+	}
+
+	if err := forgetInstalledBlanks([]string{".claude/commands/other.md"}); err != nil {
+		t.Fatal(err)
+	}
+	state, _ = loadInstallStateForTest(installStatePath)
+	if len(state.Files) != 1 || state.Files[0].Dest != ".claude/commands/a.md" {
+		t.Errorf("after forget: %+v", state.Files)
+	}
+	if len(state.BlankDirs) != 1 {
+		t.Errorf("forget should leave blank dirs alone, got %v", state.BlankDirs)
+	}
+}
+
+func TestWriteInstallState_SchemaVersions(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// Unversioned files from older releases are migrated on write.
+	mustWriteState(t, "blankDirs:\n- .claude/commands\n")
+	if err := writeInstallState([]string{".claude/skills"}); err != nil {
+		t.Fatal(err)
+	}
+	state, err := readInstallState(installStatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.SchemaVersion != installStateSchemaVersion || len(state.BlankDirs) != 2 {
+		t.Errorf("migrated state = %+v", state)
+	}
+
+	// Files from a newer ailloy are readable for listing but never rewritten.
+	mustWriteState(t, "schemaVersion: 99\nblankDirs:\n- .claude/commands\n")
+	state, err = readInstallState(installStatePath)
+	if !foundry.IsSchemaTooNew(err) || state == nil || len(state.BlankDirs) != 1 {
+		t.Fatalf("read newer state = %+v, %v", state, err)
+	}
+	if err := writeInstallState([]string{".claude/skills"}); !foundry.IsSchemaTooNew(err) {
+		t.Fatalf("writeInstallState err = %v, want SchemaError", err)
+	}
+	if err := forgetInstalledBlanks([]string{".claude/commands/x.md"}); !foundry.IsSchemaTooNew(err) {
+		t.Fatalf("forgetInstalledBlanks err = %v, want SchemaError", err)
+	}
+}
+
+func mustWriteState(t *testing.T, content string) {
+	t.Helper()
+	if err := os.MkdirAll(".ailloy", 0750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, installStatePath, content)
+}
+
 func loadInstallStateForTest(path string) (*installState, error) {
 	data, err := os.ReadFile(path) // #nosec G304 -- test path
 	if err != nil {
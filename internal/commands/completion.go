@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for ailloy and print it to stdout.
+
+Besides commands and flags, completion fills in installed blank names
+(mold show), cached mold references (cast), and flux variable names for
+--set from the local mold's flux.schema.yaml and flux.yaml.
+
+  bash:        source <(ailloy completion bash)
+               # or persist: ailloy completion bash > /etc/bash_completion.d/ailloy
+  zsh:         ailloy completion zsh > "${fpath[1]}/_ailloy"
+  fish:        ailloy completion fish > ~/.config/fish/completions/ailloy.fish
+  powershell:  ailloy completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE:                  runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("unsupported shell %q", args[0])
+}
+
+// completeBlankNames completes the category/name of every installed blank.
+func completeBlankNames(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	moldDirs, workflowDirs := loadInstalledDirs()
+	var names []string
+	for _, b := range collectListedBlanks(moldDirs, workflowDirs) {
+		if b.Description != "" && !b.Workflow {
+			names = append(names, b.Name+"\t"+b.Description)
+		} else {
+			names = append(names, b.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCachedRefs completes mold references in the local cache, bare and
+// pinned to each cached version. Directories still complete, since cast
+// also takes a local mold path.
+func completeCachedRefs(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	moldRoot, err := foundry.CacheDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	indexRoot, _ := index.IndexCacheDir()
+	entries, err := foundry.ListCachedMolds(moldRoot)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	var refs []string
+	for _, e := range entries {
+		if filepath.Join(moldRoot, e.Host) == filepath.Clean(indexRoot) {
+			continue
+		}
+		ref := e.Host + "/" + e.Owner + "/" + e.Repo
+		refs = append(refs, ref)
+		for _, v := range e.Versions {
+			refs = append(refs, ref+"@"+v)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	return refs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSetFlag returns a --set completion for commands whose mold
+// directory is argument moldArg (the working directory when omitted). Before
+// "=" it completes variable names from the mold's schema and flux.yaml;
+// after it, the declared options of select and bool variables.
+func completeSetFlag(moldArg int) cobra.CompletionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		moldDir := "."
+		if len(args) > moldArg {
+			moldDir = args[moldArg]
+		}
+		vars := fluxCompletionVars(moldDir)
+
+		if name, _, ok := strings.Cut(toComplete, "="); ok {
+			var values []string
+			for _, v := range vars[name] {
+				values = append(values, name+"="+v)
+			}
+			return values, cobra.ShellCompDirectiveNoFileComp
+		}
+		names := slices.Sorted(maps.Keys(vars))
+		for i := range names {
+			names[i] += "="
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// fluxCompletionVars maps each flux variable of the mold in moldDir to the
+// values worth suggesting for it. Unreadable molds yield nothing.
+func fluxCompletionVars(moldDir string) map[string][]string {
+	vars := map[string][]string{}
+	if info, err := os.Stat(moldDir); err != nil || !info.IsDir() {
+		return vars
+	}
+	reader, err := blanks.NewMoldReaderFromPath(moldDir)
+	if err != nil {
+		return vars
+	}
+
+	schema, _ := reader.LoadFluxSchema()
+	if schema == nil {
+		if manifest, err := reader.LoadManifest(); err == nil {
+			schema = manifest.Flux
+		}
+	}
+	for _, v := range schema {
+		var values []string
+		for _, o := range v.Options {
+			values = append(values, o.Value)
+		}
+		if v.Type == "bool" {
+			values = []string{"true", "false"}
+		}
+		vars[v.Name] = values
+	}
+
+	defaults, _ := reader.LoadFluxDefaults()
+	for _, key := range flattenFluxKeys("", defaults) {
+		if _, ok := vars[key]; !ok {
+			vars[key] = nil
+		}
+	}
+	return vars
+}
+
+// flattenFluxKeys returns the dotted paths of every leaf in a flux map.
+func flattenFluxKeys(prefix string, m map[string]any) []string {
+	var keys []string
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			keys = append(keys, flattenFluxKeys(key, nested)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
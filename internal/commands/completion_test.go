@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteSetFlag(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"flux.schema.yaml": `- name: project.name
+  type: string
+- name: tone
+  type: select
+  options:
+    - label: Formal
+      value: formal
+    - label: Casual
+      value: casual
+- name: strict
+  type: bool
+`,
+		"flux.yaml": "project:\n  name: demo\n  org: acme\nextras: []\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	complete := completeSetFlag(0)
+	names, directive := complete(castCmd, []string{dir}, "")
+	want := []string{"extras=", "project.name=", "project.org=", "strict=", "tone="}
+	if !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Errorf("directive = %v, want NoSpace so the value can follow", directive)
+	}
+
+	if values, _ := complete(castCmd, []string{dir}, "tone="); !slices.Equal(values, []string{"tone=formal", "tone=casual"}) {
+		t.Errorf("tone values = %v", values)
+	}
+	if values, _ := complete(castCmd, []string{dir}, "strict=t"); !slices.Equal(values, []string{"strict=true", "strict=false"}) {
+		t.Errorf("strict values = %v", values)
+	}
+	if names, _ := complete(castCmd, []string{filepath.Join(dir, "missing")}, ""); len(names) != 0 {
+		t.Errorf("missing mold names = %v, want none", names)
+	}
+}
+
+func TestCompletionCommand(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		var out bytes.Buffer
+		completionCmd.SetOut(&out)
+		if err := runCompletion(completionCmd, []string{shell}); err != nil {
+			t.Fatalf("%s: %v", shell, err)
+		}
+		if !strings.Contains(out.String(), "ailloy") {
+			t.Errorf("%s script does not mention ailloy", shell)
+		}
+	}
+	completionCmd.SetOut(nil)
+}
@@ -11,7 +11,12 @@ import (
 
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Manage Ailloy project configuration",
+	Short: "Manage Ailloy configuration",
+	Long: `Inspect and edit Ailloy configuration with dotted keys.
+
+Configuration lives in three files, highest precedence first: the project's
+.ailloyrc.yaml (assay settings), ~/.ailloy/config.yaml (foundries, profile,
+foundry and evolve settings) and the system scope's config.yaml.`,
 }
 
 var allowFieldsCmd = &cobra.Command{
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/scope"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade config.yaml to the current layout",
+	Long: `Rewrite ~/.ailloy/config.yaml (or the system scope's with --system) in
+the current config layout and stamp it with configVersion.
+
+ailloy already reads older layouts, upgrading them in memory and warning on
+every run; migrate makes the upgrade permanent. Keys the current layout does
+not read are reported and kept in the file, never dropped. --dry-run prints
+the result without writing it.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runConfigMigrate,
+}
+
+var configMigrateDryRun bool
+
+func init() {
+	configCmd.AddCommand(configMigrateCmd)
+	configMigrateCmd.Flags().BoolVar(&configSystem, "system", false, "migrate the system scope's config.yaml")
+	configMigrateCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false, "print the migrated file instead of writing it")
+}
+
+func runConfigMigrate(cmd *cobra.Command, _ []string) error {
+	path, err := index.ConfigPath()
+	if err != nil {
+		return err
+	}
+	if configSystem {
+		root, err := scope.RequireWritableSystem()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(root, "config.yaml")
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- ailloy's own config file
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println(styles.SubtleStyle.Render("No config file at " + displayPath(path) + "; nothing to migrate"))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m, err := index.MigrateConfigData(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", displayPath(path), err)
+	}
+
+	// Unknown keys were already reported when the root command loaded the
+	// config; they stay in the migrated document.
+	if m.From == index.CurrentConfigVersion && len(m.Changes) == 0 {
+		fmt.Println(styles.SuccessStyle.Render("✓ ") + displayPath(path) +
+			fmt.Sprintf(" is already at config layout %d", index.CurrentConfigVersion))
+		return nil
+	}
+
+	out, err := yaml.Marshal(m.Document)
+	if err != nil {
+		return err
+	}
+	if configMigrateDryRun {
+		_, err := cmd.OutOrStdout().Write(out)
+		return err
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil { // #nosec G306 -- user config file
+		return err
+	}
+	fmt.Println(styles.SuccessStyle.Render("✓ ") + "Migrated " + displayPath(path) +
+		fmt.Sprintf(" from config layout %d to %d", m.From, index.CurrentConfigVersion))
+	for _, change := range m.Changes {
+		fmt.Println("  - " + change)
+	}
+	if len(m.Changes) == 0 {
+		fmt.Println("  - stamped configVersion")
+	}
+	return nil
+}
@@ -0,0 +1,470 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/assay"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/scope"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Long: `Print the value of a dotted configuration key, e.g. evolve.channel or
+assay.rules.line-count.options.max-lines.
+
+Without a scope flag the highest-precedence file that sets the key wins:
+the project's .ailloyrc.yaml, then ~/.ailloy/config.yaml, then the system
+scope's config.yaml. --show-origin also prints that file.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long: `Set a dotted configuration key, creating the file and any parent keys.
+
+The value is parsed as YAML, so true, 3 and [a, b] are stored as a bool, a
+number and a list; quote it ('"3"') to force a string. The file is checked
+after the edit and left untouched if the result would not load.
+
+Writes the project's .ailloyrc.yaml by default, ~/.ailloy/config.yaml with
+--global, or the system scope's config.yaml with --system.
+
+Examples:
+  ailloy config set evolve.channel beta --global
+  ailloy config set assay.rules.line-count.options.max-lines 200`,
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Long: `Remove a dotted configuration key (and any parents it leaves empty)
+from the project's .ailloyrc.yaml, or from ~/.ailloy/config.yaml with
+--global, or the system scope's config.yaml with --system.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runConfigUnset,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configuration values and the files they come from",
+	Long: `List every configuration value as key=value, grouped by the file that
+sets it, highest precedence first. A scope flag limits the listing to one
+file.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runConfigList,
+}
+
+var (
+	configGlobal     bool
+	configSystem     bool
+	configProject    bool
+	configShowOrigin bool
+)
+
+func init() {
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd)
+	for _, cmd := range []*cobra.Command{configGetCmd, configSetCmd, configUnsetCmd, configListCmd} {
+		cmd.Flags().BoolVarP(&configGlobal, "global", "g", false, "use ~/.ailloy/config.yaml")
+		cmd.Flags().BoolVar(&configSystem, "system", false, "use the system scope's config.yaml")
+		cmd.Flags().BoolVar(&configProject, "project", false, "use the project's .ailloyrc.yaml")
+		cmd.MarkFlagsMutuallyExclusive("global", "system", "project")
+	}
+	configGetCmd.Flags().BoolVar(&configShowOrigin, "show-origin", false, "print the file the value comes from")
+}
+
+// configFile is one configuration file config get/set/unset/list operate on.
+type configFile struct {
+	Scope string
+	Path  string
+	// check reports whether data is a loadable config for this file.
+	check func(data []byte) error
+}
+
+// configFiles returns the configuration files in precedence order, highest
+// first: the project's .ailloyrc.yaml, those of its workspace ancestors,
+// then the global and system config.yaml. Scopes that are unavailable on
+// this machine are omitted.
+func configFiles() ([]configFile, error) {
+	var files []configFile
+	root, err := assay.FindProjectRoot(".")
+	if err != nil {
+		return nil, fmt.Errorf("finding project root: %w", err)
+	}
+	files = append(files, configFile{Scope: scope.Project, Path: filepath.Join(root, ".ailloyrc.yaml"), check: checkAilloyRC})
+	// Workspace ancestors (a monorepo root) sit below the project and above
+	// the global scope; set and unset still edit the project's own file.
+	dirs := scope.WorkspaceDirs(root)
+	for i := len(dirs) - 2; i >= 0; i-- {
+		path := filepath.Join(dirs[i], ".ailloyrc.yaml")
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, configFile{Scope: scope.Project, Path: path, check: checkAilloyRC})
+		}
+	}
+	if path, err := index.ConfigPath(); err == nil {
+		files = append(files, configFile{Scope: scope.Global, Path: path, check: checkIndexConfig})
+	}
+	if path := index.SystemConfigPath(); path != "" {
+		files = append(files, configFile{Scope: scope.System, Path: path, check: checkIndexConfig})
+	}
+	return files, nil
+}
+
+// selectedConfigFiles narrows configFiles to the scope flag, if any.
+func selectedConfigFiles() ([]configFile, error) {
+	files, err := configFiles()
+	if err != nil {
+		return nil, err
+	}
+	want := ""
+	switch {
+	case configGlobal:
+		want = scope.Global
+	case configSystem:
+		want = scope.System
+	case configProject:
+		want = scope.Project
+	default:
+		return files, nil
+	}
+	var selected []configFile
+	for _, f := range files {
+		if f.Scope == want {
+			selected = append(selected, f)
+		}
+	}
+	if len(selected) > 0 {
+		return selected, nil
+	}
+	if want == scope.System {
+		return nil, fmt.Errorf("no system scope on this machine; set %s to use one", scope.SystemRootEnv)
+	}
+	return nil, fmt.Errorf("cannot locate the %s configuration file", want)
+}
+
+// writableConfigFile returns the file set/unset edit: the project's by
+// default, else the one chosen with --global or --system.
+func writableConfigFile() (configFile, error) {
+	if configSystem {
+		root, err := scope.RequireWritableSystem()
+		if err != nil {
+			return configFile{}, err
+		}
+		return configFile{Scope: scope.System, Path: filepath.Join(root, "config.yaml"), check: checkIndexConfig}, nil
+	}
+	files, err := configFiles()
+	if err != nil {
+		return configFile{}, err
+	}
+	want := scope.Project
+	if configGlobal {
+		want = scope.Global
+	}
+	for _, f := range files {
+		if f.Scope == want {
+			return f, nil
+		}
+	}
+	return configFile{}, fmt.Errorf("cannot locate the %s configuration file", want)
+}
+
+func checkAilloyRC(data []byte) error {
+	var rc struct {
+		Assay assay.Config `yaml:"assay"`
+	}
+	return yaml.Unmarshal(data, &rc)
+}
+
+func checkIndexConfig(data []byte) error {
+	_, err := index.ParseConfig(data)
+	return err
+}
+
+// readConfigValues loads a configuration file as an ordered map. A missing
+// file is empty.
+func readConfigValues(path string) (yaml.MapSlice, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- ailloy's own config files
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var values yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(data, &values, yaml.UseOrderedMap()); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// writeConfigValues writes values to f after checking they load.
+func writeConfigValues(f configFile, values yaml.MapSlice) error {
+	data := []byte{}
+	if len(values) > 0 {
+		var err error
+		if data, err = yaml.Marshal(values); err != nil {
+			return err
+		}
+	}
+	if err := f.check(data); err != nil {
+		return fmt.Errorf("%s would no longer load, so it was not changed: %w", displayPath(f.Path), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, data, 0o644) // #nosec G306 -- user config file
+}
+
+// splitConfigKey splits a dotted key, rejecting empty segments.
+func splitConfigKey(key string) ([]string, error) {
+	parts := strings.Split(key, ".")
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("invalid key %q: use dotted names such as evolve.channel", key)
+		}
+	}
+	return parts, nil
+}
+
+// lookupConfigValue returns the value at path in values.
+func lookupConfigValue(values yaml.MapSlice, path []string) (any, bool) {
+	for _, item := range values {
+		if fmt.Sprint(item.Key) != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return item.Value, true
+		}
+		nested, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			return nil, false
+		}
+		return lookupConfigValue(nested, path[1:])
+	}
+	return nil, false
+}
+
+// setConfigValue returns values with path set to value, creating parent
+// maps (and replacing scalars in the way) as needed.
+func setConfigValue(values yaml.MapSlice, path []string, value any) yaml.MapSlice {
+	for i, item := range values {
+		if fmt.Sprint(item.Key) != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			values[i].Value = value
+		} else {
+			nested, _ := item.Value.(yaml.MapSlice)
+			values[i].Value = setConfigValue(nested, path[1:], value)
+		}
+		return values
+	}
+	if len(path) == 1 {
+		return append(values, yaml.MapItem{Key: path[0], Value: value})
+	}
+	return append(values, yaml.MapItem{Key: path[0], Value: setConfigValue(nil, path[1:], value)})
+}
+
+// unsetConfigValue returns values without path, dropping parents left
+// empty, and whether anything was removed.
+func unsetConfigValue(values yaml.MapSlice, path []string) (yaml.MapSlice, bool) {
+	for i, item := range values {
+		if fmt.Sprint(item.Key) != path[0] {
+			continue
+		}
+		if len(path) > 1 {
+			nested, ok := item.Value.(yaml.MapSlice)
+			if !ok {
+				return values, false
+			}
+			nested, removed := unsetConfigValue(nested, path[1:])
+			if !removed {
+				return values, false
+			}
+			if len(nested) > 0 {
+				values[i].Value = nested
+				return values, true
+			}
+		}
+		return append(values[:i], values[i+1:]...), true
+	}
+	return values, false
+}
+
+// flattenConfigValues returns "key=value" for every leaf under prefix.
+func flattenConfigValues(prefix string, values yaml.MapSlice) []string {
+	var lines []string
+	for _, item := range values {
+		key := fmt.Sprint(item.Key)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := item.Value.(yaml.MapSlice); ok && len(nested) > 0 {
+			lines = append(lines, flattenConfigValues(key, nested)...)
+			continue
+		}
+		lines = append(lines, key+"="+formatConfigValue(item.Value))
+	}
+	return lines
+}
+
+// formatConfigValue renders a value the way it would be passed to set:
+// scalars bare, lists and maps as flow-style YAML.
+func formatConfigValue(v any) string {
+	switch v.(type) {
+	case yaml.MapSlice, []any:
+		data, err := yaml.MarshalWithOptions(v, yaml.Flow(true))
+		if err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	case nil:
+		return "null"
+	}
+	return fmt.Sprint(v)
+}
+
+// parseConfigValue reads a set value as YAML, falling back to the literal
+// string when it does not parse.
+func parseConfigValue(raw string) any {
+	var v any
+	if err := yaml.UnmarshalWithOptions([]byte(raw), &v, yaml.UseOrderedMap()); err != nil || v == nil {
+		return raw
+	}
+	return v
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	path, err := splitConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	files, err := selectedConfigFiles()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		values, err := readConfigValues(f.Path)
+		if err != nil {
+			return err
+		}
+		v, ok := lookupConfigValue(values, path)
+		if !ok {
+			continue
+		}
+		out := formatConfigValue(v)
+		if nested, ok := v.(yaml.MapSlice); ok {
+			data, err := yaml.Marshal(nested)
+			if err != nil {
+				return err
+			}
+			out = strings.TrimSuffix(string(data), "\n")
+		}
+		if configShowOrigin {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t", displayPath(f.Path))
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), out)
+		return nil
+	}
+	return fmt.Errorf("%s is not set", args[0])
+}
+
+func runConfigSet(_ *cobra.Command, args []string) error {
+	path, err := splitConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	f, err := writableConfigFile()
+	if err != nil {
+		return err
+	}
+	values, err := readConfigValues(f.Path)
+	if err != nil {
+		return err
+	}
+	if err := writeConfigValues(f, setConfigValue(values, path, parseConfigValue(args[1]))); err != nil {
+		return err
+	}
+	fmt.Println(styles.SuccessStyle.Render("✓ ") + "Set " + styles.CodeStyle.Render(args[0]) +
+		styles.SubtleStyle.Render(" in "+displayPath(f.Path)))
+	return nil
+}
+
+func runConfigUnset(_ *cobra.Command, args []string) error {
+	path, err := splitConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	f, err := writableConfigFile()
+	if err != nil {
+		return err
+	}
+	values, err := readConfigValues(f.Path)
+	if err != nil {
+		return err
+	}
+	values, removed := unsetConfigValue(values, path)
+	if !removed {
+		return fmt.Errorf("%s is not set in %s", args[0], displayPath(f.Path))
+	}
+	if err := writeConfigValues(f, values); err != nil {
+		return err
+	}
+	fmt.Println(styles.SuccessStyle.Render("✓ ") + "Unset " + styles.CodeStyle.Render(args[0]) +
+		styles.SubtleStyle.Render(" in "+displayPath(f.Path)))
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, _ []string) error {
+	files, err := selectedConfigFiles()
+	if err != nil {
+		return err
+	}
+	return listConfigValues(cmd.OutOrStdout(), files)
+}
+
+func listConfigValues(w io.Writer, files []configFile) error {
+	printed := false
+	for _, f := range files {
+		values, err := readConfigValues(f.Path)
+		if err != nil {
+			return err
+		}
+		lines := flattenConfigValues("", values)
+		if len(lines) == 0 {
+			continue
+		}
+		if printed {
+			_, _ = fmt.Fprintln(w)
+		}
+		printed = true
+		_, _ = fmt.Fprintln(w, styles.SubtleStyle.Render("# "+f.Scope+": "+displayPath(f.Path)))
+		for _, line := range lines {
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}
+	if !printed {
+		_, _ = fmt.Fprintln(w, "No configuration values set.")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestConfigValueEditing(t *testing.T) {
+	var values yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte("profile: cursor\nevolve:\n  channel: beta\n"), &values, yaml.UseOrderedMap()); err != nil {
+		t.Fatal(err)
+	}
+
+	values = setConfigValue(values, []string{"evolve", "notify"}, parseConfigValue("true"))
+	values = setConfigValue(values, []string{"foundry", "mirrors", "github.com"}, parseConfigValue("git.corp/mirror"))
+	values = setConfigValue(values, []string{"profile"}, parseConfigValue("claude"))
+	got := strings.Join(flattenConfigValues("", values), "\n")
+	want := "profile=claude\nevolve.channel=beta\nevolve.notify=true\nfoundry.mirrors.github.com=git.corp/mirror"
+	if got != want {
+		t.Errorf("after set:\n%s\nwant:\n%s", got, want)
+	}
+	if v, ok := lookupConfigValue(values, []string{"evolve", "notify"}); !ok || v != true {
+		t.Errorf("evolve.notify = %v (%v), want bool true", v, ok)
+	}
+
+	values, removed := unsetConfigValue(values, []string{"foundry", "mirrors", "github.com"})
+	if !removed {
+		t.Fatal("unset reported nothing removed")
+	}
+	if _, ok := lookupConfigValue(values, []string{"foundry"}); ok {
+		t.Error("unset left the emptied foundry block behind")
+	}
+	if _, removed := unsetConfigValue(values, []string{"evolve", "missing"}); removed {
+		t.Error("unset of a missing key reported a removal")
+	}
+
+	if _, err := splitConfigKey("evolve..channel"); err == nil {
+		t.Error("splitConfigKey accepted an empty segment")
+	}
+	if v := parseConfigValue("[a, b]"); formatConfigValue(v) != "[a, b]" {
+		t.Errorf("list value = %q", formatConfigValue(v))
+	}
+}
+
+func TestConfigCommands(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AILLOY_SYSTEM_ROOT", "")
+	if err := os.Mkdir(filepath.Join(project, ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+	t.Cleanup(func() { configGlobal, configShowOrigin = false, false })
+
+	configGlobal = true
+	if err := runConfigSet(configSetCmd, []string{"evolve.channel", "beta"}); err != nil {
+		t.Fatalf("set --global: %v", err)
+	}
+	if err := runConfigSet(configSetCmd, []string{"evolve.notify", "sometimes"}); err == nil || !strings.Contains(err.Error(), "not changed") {
+		t.Errorf("invalid value: err = %v, want the edit refused", err)
+	}
+	configGlobal = false
+	if err := runConfigSet(configSetCmd, []string{"assay.rules.line-count.options.max-lines", "200"}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(home, ".ailloy", "config.yaml")) // #nosec G304 -- test temp path
+	if string(data) != "evolve:\n  channel: beta\n" {
+		t.Errorf("config.yaml = %q", data)
+	}
+
+	var out bytes.Buffer
+	configGetCmd.SetOut(&out)
+	t.Cleanup(func() { configGetCmd.SetOut(nil) })
+	configShowOrigin = true
+	if err := runConfigGet(configGetCmd, []string{"evolve.channel"}); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got := out.String(); got != "~/.ailloy/config.yaml\tbeta\n" {
+		t.Errorf("get --show-origin = %q", got)
+	}
+	if err := runConfigGet(configGetCmd, []string{"evolve.notify"}); err == nil {
+		t.Error("get of an unset key succeeded")
+	}
+
+	out.Reset()
+	files, err := configFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listConfigValues(&out, files); err != nil {
+		t.Fatal(err)
+	}
+	list := out.String()
+	if !strings.Contains(list, "assay.rules.line-count.options.max-lines=200") || !strings.Contains(list, "evolve.channel=beta") ||
+		strings.Index(list, ".ailloyrc.yaml") > strings.Index(list, "config.yaml\n") {
+		t.Errorf("list = %q, want project values before global ones", list)
+	}
+
+	if err := runConfigUnset(configUnsetCmd, []string{"assay.rules.line-count.options.max-lines"}); err != nil {
+		t.Fatalf("unset: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(project, ".ailloyrc.yaml")); len(data) != 0 { // #nosec G304 -- test temp path
+		t.Errorf(".ailloyrc.yaml = %q, want emptied", data)
+	}
+}
@@ -2,10 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"log/slog"
 	"os/exec"
 	"runtime"
 	"strings"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 )
 
@@ -42,6 +44,17 @@ var runtimeDeps = []dependency{
 // checkDependencies checks for runtime dependencies and prints styled results.
 // All checks are warnings only - this never blocks execution.
 func checkDependencies() {
+	if !logging.Decorative() {
+		for _, dep := range runtimeDeps {
+			if found, version := checkBinary(dep.binary); found {
+				slog.Debug("dependency found", "name", dep.name, "version", version)
+			} else {
+				slog.Info("optional dependency not found", "name", dep.name)
+			}
+		}
+		return
+	}
+
 	fmt.Println(styles.InfoStyle.Render("🔍 Checking dependencies..."))
 	fmt.Println()
 
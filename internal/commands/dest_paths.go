@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Mold destinations are slash-separated and project-relative (see
+// mold.NormalizeDest). They only take the OS form when joined onto the
+// --global prefix, and anything recorded — installed.yaml, state.yaml,
+// SDK results — goes back to slash form so the same project reads the same
+// on every platform.
+
+// globalDestPrefix returns the directory destinations are installed under:
+// the user's home directory for --global, otherwise "" (the project).
+func globalDestPrefix(global bool) (string, error) {
+	if !global {
+		return "", nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return home, nil
+}
+
+// castDest returns where dest is written: dest itself for a project cast,
+// or dest joined onto destPrefix in OS form for a global one.
+func castDest(destPrefix, dest string) string {
+	if destPrefix == "" {
+		return dest
+	}
+	return filepath.Join(destPrefix, filepath.FromSlash(dest))
+}
+
+// installedRelPath is the inverse of castDest for recording: path relative
+// to destPrefix, in slash form.
+func installedRelPath(destPrefix, path string) string {
+	if destPrefix != "" {
+		if rel, err := filepath.Rel(destPrefix, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
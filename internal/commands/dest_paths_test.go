@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobalDestPrefix(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if got, err := globalDestPrefix(false); err != nil || got != "" {
+		t.Fatalf("project cast prefix = %q, %v; want empty", got, err)
+	}
+	want, _ := os.UserHomeDir()
+	if got, err := globalDestPrefix(true); err != nil || got != want {
+		t.Fatalf("global prefix = %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestCastDestRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	for _, dest := range []string{"AGENTS.md", ".claude/commands/review.md", ".github/workflows/ci.yml"} {
+		if got := castDest("", dest); got != dest {
+			t.Errorf("project castDest(%q) = %q, want it unchanged", dest, got)
+		}
+		onDisk := castDest(home, dest)
+		if want := filepath.Join(home, filepath.FromSlash(dest)); onDisk != want {
+			t.Errorf("castDest(%q) = %q, want %q", dest, onDisk, want)
+		}
+		if got := installedRelPath(home, onDisk); got != dest {
+			t.Errorf("installedRelPath(castDest(%q)) = %q", dest, got)
+		}
+		if got := installedRelPath("", dest); got != dest {
+			t.Errorf("project installedRelPath(%q) = %q", dest, got)
+		}
+	}
+}
+
+func TestWriteInstallState_RecordsSlashDirs(t *testing.T) {
+	chdir(t, t.TempDir())
+	dirs := []string{filepath.Join(".claude", "commands"), filepath.Join(".github", "workflows")}
+	if err := writeInstallState(dirs); err != nil {
+		t.Fatal(err)
+	}
+	state, err := readInstallState(installStatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.BlankDirs) != 1 || state.BlankDirs[0] != ".claude/commands" {
+		t.Errorf("blank dirs = %v", state.BlankDirs)
+	}
+	if len(state.WorkflowDirs) != 1 || state.WorkflowDirs[0] != ".github/workflows" {
+		t.Errorf("workflow dirs = %v", state.WorkflowDirs)
+	}
+}
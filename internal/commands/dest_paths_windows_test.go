@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCastDest_WindowsSeparators(t *testing.T) {
+	home := `C:\Users\dev`
+	got := castDest(home, ".claude/commands/review.md")
+	if want := `C:\Users\dev\.claude\commands\review.md`; got != want {
+		t.Fatalf("castDest = %q, want %q", got, want)
+	}
+	if rel := installedRelPath(home, got); rel != ".claude/commands/review.md" {
+		t.Errorf("installedRelPath = %q, want slash form", rel)
+	}
+}
+
+func TestCleanupEmptyDirs_StopsAtVolumeRoot(t *testing.T) {
+	dir := t.TempDir()
+	vol := filepath.VolumeName(dir) + `\`
+	// A prefix that isn't an ancestor must not walk past the volume root.
+	if kept := cleanupEmptyDirs([]string{filepath.Join(dir, "missing")}, vol+"elsewhere"); len(kept) != 0 {
+		t.Errorf("kept = %v", kept)
+	}
+}
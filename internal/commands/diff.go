@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <ref1> <ref2>",
+	Short: "Compare what two versions of a mold render",
+	Long: `Render two molds (or two versions of one mold) with the same flux and
+report what changes between them, file by file:
+
+  added    rendered by ref2 only
+  removed  rendered by ref1 only
+  changed  rendered by both, with different content
+
+Each ref is a local mold directory or a remote reference, as forge takes.
+A ref2 that starts with "@" is a version of ref1's source, so
+
+  ailloy diff github.com/acme/tools@v1.2.0 @v1.3.0
+
+compares two releases of the same mold. Both renders go through cast's
+pipeline and layer forge's flux — the mold's own defaults, then -f and
+--set — so the summary shows what an upgrade would change for your values.
+_ailloy is pinned as in 'ailloy mold test', and provenance headers are
+left out. Nothing is written.
+
+Use --patch to print a line diff under each changed file.
+
+Example:
+  ailloy diff github.com/acme/tools@v1.2.0 @v1.3.0 -f values.yaml
+  ailloy diff ./tools-main ./tools-branch --patch`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= 2 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeCachedRefs(cmd, nil, toComplete)
+	},
+	RunE: runDiff,
+}
+
+var (
+	diffSetValues []string
+	diffValFiles  []string
+	diffPatch     bool
+	diffOutput    string
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringArrayVar(&diffSetValues, "set", nil, "set flux values for both renders (key=value)")
+	diffCmd.Flags().StringArrayVarP(&diffValFiles, "values", "f", nil, "flux value files for both renders (can be repeated, later files override earlier)")
+	diffCmd.Flags().BoolVarP(&diffPatch, "patch", "p", false, "print a line diff for each changed file")
+	addOutputFlag(diffCmd, &diffOutput)
+}
+
+// Kinds of change reported by diff.
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// diffFile is one destination that differs between the two renders.
+type diffFile struct {
+	Path      string `json:"path" yaml:"path"`
+	Change    string `json:"change" yaml:"change"`
+	Additions int    `json:"additions" yaml:"additions"` // lines added
+	Deletions int    `json:"deletions" yaml:"deletions"` // lines removed
+	Patch     string `json:"patch,omitempty" yaml:"patch,omitempty"`
+}
+
+// diffReport is diff's structured output.
+type diffReport struct {
+	From      string     `json:"from" yaml:"from"`
+	To        string     `json:"to" yaml:"to"`
+	Unchanged int        `json:"unchanged" yaml:"unchanged"`
+	Files     []diffFile `json:"files" yaml:"files"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(diffOutput); err != nil {
+		return err
+	}
+	from := args[0]
+	to, err := diffTargetRef(from, args[1])
+	if err != nil {
+		return err
+	}
+
+	old, err := renderDiffSide(from)
+	if err != nil {
+		return err
+	}
+	renders, err := renderDiffSide(to)
+	if err != nil {
+		return err
+	}
+
+	files, unchanged := diffRenders(old, renders, diffPatch || diffOutput != "")
+	if diffOutput != "" {
+		return writeStructured(cmd.OutOrStdout(), diffOutput, diffReport{From: from, To: to, Unchanged: unchanged, Files: files})
+	}
+	printDiff(from, to, files, unchanged)
+	return nil
+}
+
+// diffTargetRef expands a ref2 of "@<version>" to that version of from's
+// source; any other ref2 is returned as is.
+func diffTargetRef(from, to string) (string, error) {
+	version, ok := strings.CutPrefix(to, "@")
+	if !ok {
+		return to, nil
+	}
+	if !foundry.IsRemoteReference(from) {
+		return "", fmt.Errorf("%s is a local mold; @%s only names a version of a remote reference", from, version)
+	}
+	ref, err := foundry.ParseReference(from)
+	if err != nil {
+		return "", err
+	}
+	return buildVersionedRefString(ref, version), nil
+}
+
+// renderDiffSide resolves ref and renders it the way cast would, with
+// diff's flux and the preview cast context, failing on the first render
+// error. Provenance headers are left out: they name the mold version, so
+// they would mark every file of two versions as changed.
+func renderDiffSide(ref string) (map[string]string, error) {
+	reader, remote, err := resolveForgeReader([]string{ref})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+	outputs, diags := previewCastOutputs(reader, !remote, diffValFiles, diffSetValues, false)
+	for _, d := range diags {
+		if d.Severity != mold.SeverityError {
+			continue
+		}
+		if d.File != "" {
+			return nil, fmt.Errorf("%s: %s: %s", ref, d.File, d.Message)
+		}
+		return nil, fmt.Errorf("%s: %s", ref, d.Message)
+	}
+	return outputs, nil
+}
+
+// diffRenders compares two renders keyed by destination path and returns
+// the files that differ, sorted by path, and the number that don't. With
+// patch set each changed file carries its mold.LineDiff.
+func diffRenders(from, to map[string]string, patch bool) ([]diffFile, int) {
+	files := []diffFile{}
+	unchanged := 0
+	for path, before := range from {
+		after, ok := to[path]
+		switch {
+		case !ok:
+			files = append(files, diffFile{Path: path, Change: diffRemoved, Deletions: countLines(before)})
+		case after == before:
+			unchanged++
+		default:
+			d := mold.LineDiff(before, after)
+			f := diffFile{Path: path, Change: diffChanged}
+			for _, line := range strings.Split(d, "\n") {
+				switch {
+				case strings.HasPrefix(line, "+ "):
+					f.Additions++
+				case strings.HasPrefix(line, "- "):
+					f.Deletions++
+				}
+			}
+			if patch {
+				f.Patch = d
+			}
+			files = append(files, f)
+		}
+	}
+	for path, after := range to {
+		if _, ok := from[path]; !ok {
+			files = append(files, diffFile{Path: path, Change: diffAdded, Additions: countLines(after)})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, unchanged
+}
+
+// countLines counts the lines of s, the last one with or without a newline.
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(s, "\n"), "\n") + 1
+}
+
+func printDiff(from, to string, files []diffFile, unchanged int) {
+	fmt.Println(styles.HeaderStyle.Render("diff") + " " + styles.CodeStyle.Render(from) +
+		styles.SubtleStyle.Render(" → ") + styles.CodeStyle.Render(to))
+	for _, f := range files {
+		var marker string
+		switch f.Change {
+		case diffAdded:
+			marker = styles.SuccessStyle.Render("  A added    ")
+		case diffRemoved:
+			marker = styles.ErrorStyle.Render("  D removed  ")
+		default:
+			marker = styles.WarningStyle.Render("  M changed  ")
+		}
+		stat := styles.SuccessStyle.Render(fmt.Sprintf("+%d", f.Additions)) + " " +
+			styles.ErrorStyle.Render(fmt.Sprintf("-%d", f.Deletions))
+		fmt.Println(marker + styles.CodeStyle.Render(f.Path) + " " + stat)
+		if f.Patch != "" {
+			for _, line := range strings.Split(strings.TrimSuffix(f.Patch, "\n"), "\n") {
+				fmt.Println("      " + styleDiffLine(line))
+			}
+		}
+	}
+	summary := fmt.Sprintf("  %d changed, %d unchanged", len(files), unchanged)
+	if len(files) == 0 {
+		summary += ", the renders are identical"
+	}
+	fmt.Println(styles.SuccessStyle.Render(summary))
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDiffRenders(t *testing.T) {
+	from := map[string]string{
+		"same.md":    "same\n",
+		"edited.md":  "# title\nold line\nkept\n",
+		"dropped.md": "one\ntwo\n",
+	}
+	to := map[string]string{
+		"same.md":   "same\n",
+		"edited.md": "# title\nnew line\nkept\nmore\n",
+		"added.md":  "added",
+	}
+
+	files, unchanged := diffRenders(from, to, false)
+	if unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1", unchanged)
+	}
+	want := []diffFile{
+		{Path: "added.md", Change: diffAdded, Additions: 1},
+		{Path: "dropped.md", Change: diffRemoved, Deletions: 2},
+		{Path: "edited.md", Change: diffChanged, Additions: 2, Deletions: 1},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("files = %+v, want %+v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("files[%d] = %+v, want %+v", i, files[i], want[i])
+		}
+	}
+
+	if files, _ := diffRenders(from, to, true); files[2].Patch == "" {
+		t.Error("patch requested but not set on the changed file")
+	}
+}
+
+func TestDiffTargetRef(t *testing.T) {
+	got, err := diffTargetRef("github.com/acme/tools@v1.2.0//molds/base", "@v1.3.0")
+	if err != nil || got != "github.com/acme/tools@v1.3.0//molds/base" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if got, _ := diffTargetRef("./a", "./b"); got != "./b" {
+		t.Errorf("plain ref2 rewritten to %q", got)
+	}
+	if _, err := diffTargetRef("./a", "@v2"); err == nil {
+		t.Error("expected an error for a version of a local mold")
+	}
+}
+
+func TestRunDiff_LocalMolds(t *testing.T) {
+	write := func(dir, body string) string {
+		moldDir := filepath.Join(t.TempDir(), dir)
+		if err := os.MkdirAll(filepath.Join(moldDir, "commands"), 0750); err != nil {
+			t.Fatal(err)
+		}
+		mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: tools\nversion: 1.0.0\n")
+		mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\norg: default\n")
+		mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), body)
+		return moldDir
+	}
+	oldDir := write("old", "# Hello {{org}}\n")
+	newDir := write("new", "# Hi {{org}}\n")
+	mustWrite(t, filepath.Join(newDir, "flux.yaml"), "output:\n  commands: .claude/commands\n  AGENTS.md: AGENTS.md\norg: default\n")
+	mustWrite(t, filepath.Join(newDir, "AGENTS.md"), "Cast by {{ ._ailloy.mold.name }}\n")
+
+	diffSetValues, diffOutput = []string{"org=acme"}, outputJSON
+	t.Cleanup(func() { diffSetValues, diffOutput = nil, "" })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runDiff(cmd, []string{oldDir, newDir}); err != nil {
+		t.Fatal(err)
+	}
+	var report diffReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decoding %q: %v", out.String(), err)
+	}
+	if len(report.Files) != 2 || report.Files[0].Path != ".claude/commands/hello.md" || report.Files[0].Change != diffChanged {
+		t.Fatalf("files = %+v", report.Files)
+	}
+	// No provenance headers: only the body differs.
+	if want := "- # Hello acme\n+ # Hi acme\n  \n"; report.Files[0].Patch != want {
+		t.Errorf("patch = %q, want %q (both renders use --set)", report.Files[0].Patch, want)
+	}
+	// AGENTS.md renders as cast writes it: the mold's section, markers included.
+	if f := report.Files[1]; f.Path != "AGENTS.md" || f.Change != diffAdded || f.Additions != 3 {
+		t.Errorf("AGENTS.md = %+v, want added with its section markers", f)
+	}
+}
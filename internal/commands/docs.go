@@ -68,13 +68,15 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&rootDocs, "docs", false,
 		"render the command's associated documentation and exit")
 
-	prev := rootCmd.PersistentPreRun
-	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+	// Cobra runs PersistentPreRunE instead of PersistentPreRun when both are
+	// set, so chain onto the root's hook rather than replacing it.
+	prev := rootCmd.PersistentPreRunE
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		if prev != nil {
-			prev(cmd, args)
+			if err := prev(cmd, args); err != nil {
+				return err
+			}
 		}
-	}
-	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		if !rootDocs {
 			return nil
 		}
@@ -176,9 +178,10 @@ func tryExecDocsExtension(args []string) (int, bool, error) {
 	return code, true, err
 }
 
-// isInteractive reports whether stdout AND stdin are attached to a TTY.
+// isInteractive reports whether ailloy may prompt: stdout AND stdin are
+// attached to a TTY and --non-interactive isn't in effect.
 func isInteractive() bool {
-	return term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stdin.Fd()))
+	return !nonInteractive() && term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stdin.Fd()))
 }
 
 // topicForCommand walks up parents so that `ailloy foundry add --docs`
@@ -197,7 +200,7 @@ func printTopicList(w io.Writer) error {
 
 	header := lipgloss.NewStyle().Bold(true).Foreground(styles.Primary1)
 	t := table.New().
-		Border(lipgloss.NormalBorder()).
+		Border(styles.TableBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(styles.Primary1)).
 		StyleFunc(func(row, _ int) lipgloss.Style {
 			if row == table.HeaderRow {
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/scope"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Report ailloy's effective configuration and diagnose problems",
+	Long: `Report ailloy's effective configuration and diagnose problems.
+
+Shows the install scopes ailloy reads from, lowest precedence first:
+system (organization-provisioned, shared by every user on the machine),
+global (~/.ailloy), and project (./.ailloy). For each scope it prints the
+root, whether it exists and is writable by the current user, and what it
+contributes (foundries, ores, ingots, persisted flux files).
+
+The system root is $AILLOY_SYSTEM_ROOT when set, otherwise the first of
+/usr/local/share/ailloy and /etc/ailloy that exists.
+
+It then checks the environment and prints a fix for anything wrong:
+  - git is installed, and gh is installed and authenticated
+  - every configured foundry host (or its mirror) is reachable
+  - each config file that exists parses: config.yaml in every scope,
+    ailloy.yaml, .ailloyrc.yaml, installed.yaml and ailloy.lock
+  - the mold cache has no broken clones or empty snapshots
+  - installed molds' requires.ailloy constraints accept this version
+
+--offline skips the network check. Doctor exits non-zero when a check
+fails; warnings (such as a missing gh) do not.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+var (
+	doctorOffline bool
+	doctorOutput  string
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "skip checks that need the network")
+	addOutputFlag(doctorCmd, &doctorOutput)
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(doctorOutput); err != nil {
+		return err
+	}
+	checks := runDoctorChecks(doctorOffline)
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed++
+		}
+	}
+
+	if doctorOutput != "" {
+		if err := writeStructured(cmd.OutOrStdout(), doctorOutput, struct {
+			Checks []doctorCheck `json:"checks" yaml:"checks"`
+		}{checks}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(styles.HeaderStyle.Render("Install scopes (lowest → highest precedence)"))
+		fmt.Println()
+		for _, layer := range scope.Stack() {
+			printScopeLayer(layer)
+		}
+		fmt.Println(styles.HeaderStyle.Render("Checks"))
+		fmt.Println()
+		for _, c := range checks {
+			printDoctorCheck(c)
+		}
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		return validationError(fmt.Errorf("%d check(s) failed", failed))
+	}
+	return nil
+}
+
+func printDoctorCheck(c doctorCheck) {
+	line := "  "
+	switch c.Status {
+	case doctorOK:
+		line += styles.SuccessStyle.Render("✓ " + c.Name)
+	case doctorWarn:
+		line += styles.WarningStyle.Render("⚠ " + c.Name)
+	default:
+		line += styles.ErrorStyle.Render("✗ " + c.Name)
+	}
+	if c.Detail != "" {
+		line += styles.SubtleStyle.Render(" — " + c.Detail)
+	}
+	fmt.Println(line)
+	if c.Fix != "" {
+		fmt.Println("      Fix: " + styles.CodeStyle.Render(c.Fix))
+	}
+}
+
+func printScopeLayer(layer scope.Layer) {
+	fmt.Println("  " + styles.AccentStyle.Render(layer.Name))
+	if layer.Root == "" {
+		msg := "not available"
+		if layer.Name == scope.System {
+			msg = fmt.Sprintf("not configured (set %s or create one of: %s)",
+				scope.SystemRootEnv, strings.Join(scope.SystemRootCandidates(), ", "))
+		}
+		fmt.Println(styles.SubtleStyle.Render("    " + msg))
+		fmt.Println()
+		return
+	}
+
+	fmt.Println(styles.SubtleStyle.Render("    Root:     ") + styles.CodeStyle.Render(layer.Root))
+	switch {
+	case !layer.Exists:
+		fmt.Println(styles.SubtleStyle.Render("    Status:   absent"))
+	case layer.Writable:
+		fmt.Println(styles.SubtleStyle.Render("    Status:   present, writable"))
+	default:
+		fmt.Println(styles.SubtleStyle.Render("    Status:   present, ") + styles.WarningStyle.Render("read-only"))
+	}
+	if layer.Exists {
+		foundries := 0
+		if cfg, err := index.LoadConfigFrom(filepath.Join(layer.Root, "config.yaml")); err == nil {
+			foundries = len(cfg.Foundries)
+		}
+		fmt.Println(styles.SubtleStyle.Render(fmt.Sprintf("    Contents: %d foundr%s, %d ore(s), %d ingot(s), %d flux file(s)",
+			foundries, pluralY(foundries),
+			countEntries(filepath.Join(layer.Root, "ores"), true),
+			countEntries(filepath.Join(layer.Root, "ingots"), false),
+			countEntries(filepath.Join(layer.Root, "flux"), false))))
+	}
+	fmt.Println()
+}
+
+// countEntries counts non-hidden entries in dir, only directories when
+// dirsOnly is set. A missing dir counts as zero.
+func countEntries(dir string, dirsOnly bool) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || (dirsOnly && !e.IsDir()) {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
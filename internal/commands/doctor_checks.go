@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/assay"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/scope"
+)
+
+// Doctor check outcomes. A warning degrades some feature; a failure breaks
+// a common workflow and makes doctor exit non-zero.
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorCheck is the outcome of one environment check.
+type doctorCheck struct {
+	Name   string `json:"name" yaml:"name"`
+	Status string `json:"status" yaml:"status"`
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+	// Fix is the action that resolves a warning or failure.
+	Fix string `json:"fix,omitempty" yaml:"fix,omitempty"`
+}
+
+var (
+	// doctorRun runs a command and reports whether it exited zero; a
+	// variable so tests can stand in for gh and git.
+	doctorRun = func(name string, args ...string) error {
+		return exec.Command(name, args...).Run() // #nosec G204 -- fixed tool names and arguments
+	}
+	// doctorDial checks that addr (host:port) accepts TCP connections.
+	doctorDial = func(addr string) error {
+		conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+)
+
+// runDoctorChecks runs every environment check. offline skips the ones that
+// need the network.
+func runDoctorChecks(offline bool) []doctorCheck {
+	var checks []doctorCheck
+	checks = append(checks, checkGit(), checkGitHubCLI())
+	if !offline {
+		checks = append(checks, checkFoundryHosts()...)
+	}
+	checks = append(checks, checkConfigFiles()...)
+	checks = append(checks, checkCache())
+	checks = append(checks, checkInstalledRequires()...)
+	return checks
+}
+
+// installHint returns the install command for dep on this platform.
+func installHint(dep dependency) string {
+	if help := dep.installHelp[runtime.GOOS]; help != "" {
+		return help
+	}
+	return dep.installHelp["linux"]
+}
+
+func runtimeDep(binary string) dependency {
+	for _, dep := range runtimeDeps {
+		if dep.binary == binary {
+			return dep
+		}
+	}
+	return dependency{name: binary, binary: binary}
+}
+
+func checkGit() doctorCheck {
+	found, version := checkBinary("git")
+	if !found {
+		return doctorCheck{Name: "git", Status: doctorFail,
+			Detail: "not found on PATH; foundries, cast and recast fetch molds with git",
+			Fix:    installHint(runtimeDep("git"))}
+	}
+	return doctorCheck{Name: "git", Status: doctorOK, Detail: version}
+}
+
+func checkGitHubCLI() doctorCheck {
+	found, version := checkBinary("gh")
+	if !found {
+		return doctorCheck{Name: "gh", Status: doctorWarn,
+			Detail: "not found on PATH; GitHub blanks and smelt push releases need it",
+			Fix:    installHint(runtimeDep("gh"))}
+	}
+	if err := doctorRun("gh", "auth", "status"); err != nil {
+		return doctorCheck{Name: "gh", Status: doctorWarn,
+			Detail: version + ", not authenticated", Fix: "gh auth login"}
+	}
+	return doctorCheck{Name: "gh", Status: doctorOK, Detail: version + ", authenticated"}
+}
+
+// checkFoundryHosts dials every host serving a configured foundry (its
+// mirror, when one is set), in parallel.
+func checkFoundryHosts() []doctorCheck {
+	cfg, err := index.LoadConfig()
+	if err != nil {
+		return nil // reported by checkConfigFiles
+	}
+	mirrors := map[string]string{}
+	for host, mirror := range cfg.SystemMirrors {
+		mirrors[host] = mirror
+	}
+	for host, mirror := range cfg.Foundry.Mirrors {
+		mirrors[host] = mirror
+	}
+
+	var addrs []string
+	for _, f := range slices.Concat(cfg.System, cfg.Foundries) {
+		addr := remoteAddr(f.URL)
+		if addr == "" {
+			continue
+		}
+		if host, _, _ := net.SplitHostPort(addr); mirrors[host] != "" {
+			addr = remoteAddr(mirrors[host])
+		}
+		if !slices.Contains(addrs, addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return []doctorCheck{{Name: "foundry hosts", Status: doctorOK, Detail: "no foundries configured"}}
+	}
+
+	checks := make([]doctorCheck, len(addrs))
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[i] = doctorCheck{Name: "reach " + addr, Status: doctorOK}
+			if err := doctorDial(addr); err != nil {
+				checks[i].Status = doctorFail
+				checks[i].Detail = err.Error()
+				checks[i].Fix = "check your network, proxy or foundry.mirrors in config.yaml; cached molds still cast with --offline"
+			}
+		}()
+	}
+	wg.Wait()
+	return checks
+}
+
+// remoteAddr returns the host:port a git or HTTPS URL connects to, e.g.
+// "github.com:443" for github.com/acme/molds and "github.com:22" for
+// git@github.com:acme/molds.git. Empty when raw names no host.
+func remoteAddr(raw string) string {
+	s, port := strings.TrimSpace(raw), "443"
+	if scheme, rest, ok := strings.Cut(s, "://"); ok {
+		s = rest
+		switch scheme {
+		case "ssh":
+			port = "22"
+		case "http":
+			port = "80"
+		}
+	} else if user, rest, ok := strings.Cut(s, "@"); ok && !strings.Contains(user, "/") {
+		s, port = strings.Replace(rest, ":", "/", 1), "22" // scp-like git@host:owner/repo
+	}
+	hostport := strings.Split(s, "/")[0]
+	if _, after, ok := strings.Cut(hostport, "@"); ok {
+		hostport = after
+	}
+	if hostport == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(hostport); err == nil {
+		return hostport
+	}
+	return net.JoinHostPort(hostport, port)
+}
+
+// checkConfigFiles parses every configuration file ailloy reads that
+// exists: each scope's config.yaml, the project's ailloy.yaml, the
+// .ailloyrc.yaml of the project and its workspace ancestors, and the project and global installed.yaml and ailloy.lock.
+func checkConfigFiles() []doctorCheck {
+	type candidate struct {
+		path  string
+		parse func(string) error
+	}
+	var candidates []candidate
+	for _, layer := range scope.Stack() {
+		if layer.Root != "" {
+			candidates = append(candidates, candidate{filepath.Join(layer.Root, "config.yaml"), func(p string) error {
+				_, err := index.LoadConfigFrom(p)
+				return err
+			}})
+		}
+	}
+	candidates = append(candidates,
+		candidate{foundry.ProjectFileName, func(p string) error {
+			_, err := foundry.ReadProjectFile(p)
+			return err
+		}},
+	)
+	for _, dir := range slices.Backward(scope.WorkspaceDirs(".")) {
+		candidates = append(candidates, candidate{filepath.Join(dir, ".ailloyrc.yaml"), func(string) error {
+			_, err := assay.LoadConfig(dir)
+			return err
+		}})
+	}
+	for _, p := range []string{projectManifestPath(), globalManifestPath()} {
+		candidates = append(candidates, candidate{p, func(p string) error {
+			_, err := foundry.ReadInstalledManifest(p)
+			return err
+		}})
+	}
+	for _, p := range []string{projectLockPath(), globalLockPath()} {
+		candidates = append(candidates, candidate{p, func(p string) error {
+			_, err := foundry.ReadLockFile(p)
+			return err
+		}})
+	}
+
+	var checks []doctorCheck
+	for _, c := range candidates {
+		if c.path == "" {
+			continue
+		}
+		if _, err := os.Stat(c.path); err != nil {
+			continue
+		}
+		check := doctorCheck{Name: "config " + displayPath(c.path), Status: doctorOK, Detail: "valid"}
+		if err := c.parse(c.path); err != nil {
+			check.Status, check.Detail = doctorFail, err.Error()
+			check.Fix = "fix the YAML in " + displayPath(c.path)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkCache verifies the mold cache: every bare clone must be a usable git
+// repository and every version snapshot must contain a manifest.
+func checkCache() doctorCheck {
+	check := doctorCheck{Name: "cache", Status: doctorOK}
+	moldRoot, err := foundry.CacheDir()
+	if err != nil {
+		check.Status, check.Detail = doctorWarn, err.Error()
+		return check
+	}
+	indexRoot, _ := index.IndexCacheDir()
+	entries, err := foundry.ListCachedMolds(moldRoot)
+	if err != nil {
+		check.Status, check.Detail = doctorFail, err.Error()
+		check.Fix = "ailloy cache clear"
+		return check
+	}
+
+	var broken []string
+	snapshots := 0
+	for _, e := range entries {
+		if filepath.Join(moldRoot, e.Host) == filepath.Clean(indexRoot) {
+			continue
+		}
+		repoDir := filepath.Join(moldRoot, e.Host, e.Owner, e.Repo)
+		if gitDir := filepath.Join(repoDir, "git"); dirExists(gitDir) {
+			if err := doctorRun("git", "--git-dir", gitDir, "rev-parse", "--git-dir"); err != nil {
+				broken = append(broken, displayPath(gitDir))
+			}
+		}
+		for _, v := range e.Versions {
+			snapshots++
+			if !containsManifest(filepath.Join(repoDir, v)) {
+				broken = append(broken, displayPath(filepath.Join(repoDir, v)))
+			}
+		}
+	}
+	if len(broken) > 0 {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%d damaged entr%s: %s", len(broken), pluralY(len(broken)), strings.Join(broken, ", "))
+		check.Fix = "remove the listed directories (they are fetched again on demand) or run `ailloy cache clear`"
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d snapshot(s) in %s", snapshots, displayPath(moldRoot))
+	return check
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// containsManifest reports whether a cache snapshot holds a mold, ingot or
+// ore manifest at any depth (subpath packages live below the repo root).
+func containsManifest(dir string) bool {
+	found := false
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		switch d.Name() {
+		case "mold.yaml", "ingot.yaml", "ore.yaml":
+			found = !d.IsDir()
+		}
+		if found {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// checkInstalledRequires checks the running ailloy against the
+// requires.ailloy constraint of every installed mold whose snapshot is
+// still cached.
+func checkInstalledRequires() []doctorCheck {
+	cacheDir, err := foundry.CacheDir()
+	if err != nil {
+		return nil
+	}
+	var checks []doctorCheck
+	for _, manifestPath := range []string{projectManifestPath(), globalManifestPath()} {
+		if manifestPath == "" {
+			continue
+		}
+		manifest, err := foundry.ReadInstalledManifest(manifestPath)
+		if err != nil || manifest == nil {
+			continue // reported by checkConfigFiles
+		}
+		for _, e := range manifest.Molds {
+			ref, err := foundry.ParseReference(e.Source)
+			if err != nil {
+				continue
+			}
+			dir := filepath.Join(foundry.VersionDir(cacheDir, ref, e.Version), e.Subpath)
+			m, err := mold.LoadMold(filepath.Join(dir, "mold.yaml"))
+			if err != nil || m == nil || strings.TrimSpace(m.Requires.Ailloy) == "" {
+				continue
+			}
+			name := e.Name + "@" + e.Version
+			if err := enforceAilloyVersionFor(e.Name, m.Requires.Ailloy); err != nil {
+				msg, _, _ := strings.Cut(err.Error(), "\n")
+				checks = append(checks, doctorCheck{Name: "requires " + name, Status: doctorFail,
+					Detail: msg, Fix: "ailloy evolve"})
+				continue
+			}
+			checks = append(checks, doctorCheck{Name: "requires " + name, Status: doctorOK,
+				Detail: "ailloy " + m.Requires.Ailloy})
+		}
+	}
+	return checks
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRemoteAddr(t *testing.T) {
+	cases := map[string]string{
+		"github.com/acme/molds":                 "github.com:443",
+		"https://gitlab.example.com/acme/molds": "gitlab.example.com:443",
+		"http://localhost:8080/foundry.yaml":    "localhost:8080",
+		"git@github.com:acme/molds.git":         "github.com:22",
+		"ssh://git@mirror.corp:2222/github":     "mirror.corp:2222",
+		"git.internal.corp/mirror":              "git.internal.corp:443",
+		"":                                      "",
+	}
+	for in, want := range cases {
+		if got := remoteAddr(in); got != want {
+			t.Errorf("remoteAddr(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRunDoctorChecks(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AILLOY_SYSTEM_ROOT", "")
+	t.Chdir(project)
+
+	origDial, origVersion := doctorDial, evolveCurrentVersion
+	t.Cleanup(func() { doctorDial, evolveCurrentVersion = origDial, origVersion })
+	var mu sync.Mutex
+	var dialed []string
+	doctorDial = func(addr string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		dialed = append(dialed, addr)
+		if strings.HasPrefix(addr, "mirror.corp") {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	evolveCurrentVersion = "1.0.0"
+
+	mustWrite(t, filepath.Join(home, ".ailloy", "config.yaml"), `foundries:
+  - name: official
+    url: github.com/nimble-giant/foundry
+    type: git
+  - name: corp
+    url: https://git.corp/acme/foundry
+    type: git
+foundry:
+  mirrors:
+    git.corp: mirror.corp
+`)
+	mustWrite(t, filepath.Join(project, "ailloy.yaml"), "molds: [\n")
+	cache := filepath.Join(home, ".ailloy", "cache", "github.com", "acme", "molds")
+	mustWrite(t, filepath.Join(cache, "v1.0.0", "mold.yaml"),
+		"apiVersion: v1\nkind: mold\nname: review\nversion: 1.0.0\nrequires:\n  ailloy: \">=2.0.0\"\n")
+	if err := os.MkdirAll(filepath.Join(cache, "v0.9.0"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(project, ".ailloy", "installed.yaml"),
+		"apiVersion: v1\nmolds:\n  - name: review\n    source: github.com/acme/molds\n    version: v1.0.0\n")
+
+	byName := map[string]doctorCheck{}
+	for _, c := range runDoctorChecks(false) {
+		byName[c.Name] = c
+	}
+
+	if c := byName["reach github.com:443"]; c.Status != doctorOK {
+		t.Errorf("github check = %+v", c)
+	}
+	if c := byName["reach mirror.corp:443"]; c.Status != doctorFail || c.Fix == "" {
+		t.Errorf("mirror check = %+v, want a failure with a fix (dialed %v)", c, dialed)
+	}
+	if c := byName["config ailloy.yaml"]; c.Status != doctorFail {
+		t.Errorf("ailloy.yaml check = %+v, want a parse failure", c)
+	}
+	if c := byName["config ~/.ailloy/config.yaml"]; c.Status != doctorOK {
+		t.Errorf("config.yaml check = %+v", c)
+	}
+	if c := byName["cache"]; c.Status != doctorFail || !strings.Contains(c.Detail, "v0.9.0") {
+		t.Errorf("cache check = %+v, want the empty v0.9.0 snapshot flagged", c)
+	}
+	if c := byName["requires review@v1.0.0"]; c.Status != doctorFail || c.Fix != "ailloy evolve" {
+		t.Errorf("requires check = %+v, want an unmet constraint", c)
+	}
+
+	// --offline dials nothing.
+	dialed = nil
+	for _, c := range runDoctorChecks(true) {
+		if strings.HasPrefix(c.Name, "reach ") {
+			t.Errorf("offline ran %s", c.Name)
+		}
+	}
+	if len(dialed) != 0 {
+		t.Errorf("offline dialed %v", dialed)
+	}
+}
@@ -0,0 +1,264 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/smelt"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var embeddedCmd = &cobra.Command{
+	Use:   "embedded",
+	Short: "Inspect the mold stuffed into this binary",
+	Long: `Inspect the mold stuffed into a binary built with smelt -o binary.
+
+A stuffed binary checks its embedded files against the digests smelt
+recorded in provenance.yaml whenever it opens the mold, and refuses to cast
+from a corrupted payload.`,
+}
+
+var embeddedInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Print the embedded mold's name, version and digest, and verify it",
+	Long: `Print the embedded mold's name, version and content digest, and verify
+every embedded file against provenance.yaml. Exits non-zero when a file is
+missing, altered or unrecorded. A binary smelted with several molds lists
+and verifies each of them; --mold limits it to one.
+
+The digest is the SHA-256 of the mold's "<sha256>  <path>" lines, so it
+identifies the packaged files regardless of when or where they were smelted.
+Binaries smelted before provenance was recorded can't be verified.
+
+Example:
+  ./my-mold-1.2.3 embedded info
+  ./my-mold-1.2.3 embedded info -o json`,
+	Args: cobra.NoArgs,
+	RunE: runEmbeddedInfo,
+}
+
+var (
+	embeddedInfoOutput string
+	embeddedInfoMold   string
+)
+
+func init() {
+	rootCmd.AddCommand(embeddedCmd)
+	embeddedCmd.AddCommand(embeddedInfoCmd)
+	addOutputFlag(embeddedInfoCmd, &embeddedInfoOutput)
+	embeddedInfoCmd.Flags().StringVar(&embeddedInfoMold, "mold", "", "only describe this mold of a binary smelted with several")
+}
+
+// embeddedInfo describes a stuffed binary's mold for embedded info.
+type embeddedInfo struct {
+	Name     string   `json:"name" yaml:"name"`
+	Version  string   `json:"version" yaml:"version"`
+	Digest   string   `json:"digest,omitempty" yaml:"digest,omitempty"`
+	Builder  string   `json:"builder,omitempty" yaml:"builder,omitempty"`
+	BuiltAt  string   `json:"builtAt,omitempty" yaml:"builtAt,omitempty"`
+	Files    int      `json:"files" yaml:"files"`
+	Default  bool     `json:"default,omitempty" yaml:"default,omitempty"`
+	Verified bool     `json:"verified" yaml:"verified"`
+	Problems []string `json:"problems,omitempty" yaml:"problems,omitempty"`
+
+	noProvenance bool // smelted before provenance.yaml was recorded
+}
+
+func runEmbeddedInfo(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(embeddedInfoOutput); err != nil {
+		return err
+	}
+	if !smelt.HasEmbeddedMold() {
+		return errNotStuffed
+	}
+	index, err := smelt.EmbeddedMolds()
+	if err != nil {
+		return fmt.Errorf("embedded mold is corrupted: %w", err)
+	}
+	if index == nil || embeddedInfoMold != "" {
+		info, err := verifyEmbedded(embeddedInfoMold)
+		if err != nil {
+			return err
+		}
+		info.Default = index != nil && index.Default == info.Name
+		if embeddedInfoOutput != "" {
+			if err := writeStructured(cmd.OutOrStdout(), embeddedInfoOutput, info); err != nil {
+				return err
+			}
+		} else {
+			printEmbeddedInfo(cmd, info)
+		}
+		return embeddedIntegrityError(info)
+	}
+
+	// A multi-mold binary: describe and verify every mold.
+	infos := make([]embeddedInfo, 0, len(index.Molds))
+	corrupted := 0
+	for i, m := range index.Molds {
+		info, err := verifyEmbedded(m.Name)
+		if err != nil {
+			return err
+		}
+		info.Default = index.Default == m.Name
+		infos = append(infos, info)
+		if embeddedIntegrityError(info) != nil {
+			corrupted++
+		}
+		if embeddedInfoOutput == "" {
+			if i > 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout())
+			}
+			printEmbeddedInfo(cmd, info)
+		}
+	}
+	if embeddedInfoOutput != "" {
+		if err := writeStructured(cmd.OutOrStdout(), embeddedInfoOutput, infos); err != nil {
+			return err
+		}
+	}
+	if corrupted > 0 {
+		return validationError(fmt.Errorf("%d of %d embedded molds are corrupted; rebuild the binary with ailloy smelt -o binary", corrupted, len(infos)))
+	}
+	return nil
+}
+
+var errNotStuffed = errors.New("this ailloy binary has no embedded mold; build one with ailloy smelt -o binary")
+
+// verifyEmbedded verifies the embedded mold called name (empty for a
+// single-mold binary) and describes it. A mold that fails verification is
+// described with its problems; the error is set only when its files can't
+// be read at all.
+func verifyEmbedded(name string) (embeddedInfo, error) {
+	fsys, prov, verr := smelt.VerifyEmbeddedMold(name)
+	if errors.Is(verr, smelt.ErrNoEmbeddedMold) {
+		return embeddedInfo{}, errNotStuffed
+	}
+	if fsys == nil {
+		if name != "" {
+			return embeddedInfo{}, verr
+		}
+		return embeddedInfo{}, fmt.Errorf("embedded mold is corrupted: %w", verr)
+	}
+	return describeEmbedded(fsys, prov, verr), nil
+}
+
+// printEmbeddedInfo writes info as embedded info's text rows.
+func printEmbeddedInfo(cmd *cobra.Command, info embeddedInfo) {
+	out := cmd.OutOrStdout()
+	row := func(label, value string) {
+		_, _ = fmt.Fprintf(out, "%s %s\n", styles.SubtleStyle.Render(fmt.Sprintf("%-9s", label+":")), value)
+	}
+	mold := info.Name + "@" + info.Version
+	if info.Default {
+		mold += styles.SubtleStyle.Render(" (default)")
+	}
+	row("Mold", mold)
+	if info.Digest != "" {
+		row("Digest", info.Digest)
+	}
+	if info.Builder != "" {
+		row("Builder", info.Builder)
+	}
+	if info.BuiltAt != "" {
+		row("Built", info.BuiltAt)
+	}
+	row("Files", fmt.Sprintf("%d", info.Files))
+	switch {
+	case info.Verified:
+		row("Verified", styles.SuccessStyle.Render("all files match provenance.yaml"))
+	case info.noProvenance:
+		row("Verified", styles.WarningStyle.Render("no provenance.yaml to check against (smelted by an older ailloy)"))
+	default:
+		row("Verified", styles.ErrorStyle.Render("corrupted"))
+		for _, p := range info.Problems {
+			_, _ = fmt.Fprintf(out, "  %s\n", p)
+		}
+	}
+}
+
+// embeddedIntegrityError returns the error embedded info exits with when
+// info's files don't match its provenance.
+func embeddedIntegrityError(info embeddedInfo) error {
+	if len(info.Problems) == 0 {
+		return nil
+	}
+	return validationError(fmt.Errorf("embedded mold is corrupted: %d file(s) do not match provenance.yaml; rebuild the binary with ailloy smelt -o binary", len(info.Problems)))
+}
+
+// describeEmbedded builds embedded info from the stuffed files, their
+// provenance (nil for older binaries) and smelt.VerifyFS's result.
+func describeEmbedded(fsys fs.FS, prov *smelt.Provenance, verr error) embeddedInfo {
+	var info embeddedInfo
+	if prov != nil {
+		info.Name, info.Version = prov.Mold.Name, prov.Mold.Version
+		info.Digest = prov.Digest()
+		info.Builder = prov.Builder.Name + " " + prov.Builder.Version
+		info.BuiltAt = prov.BuiltAt
+		info.Files = len(prov.Files)
+	} else {
+		if m, err := mold.LoadMoldFromFS(fsys, "mold.yaml"); err == nil {
+			info.Name, info.Version = m.Name, m.Version
+		}
+		_ = fs.WalkDir(fsys, ".", func(_ string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				info.Files++
+			}
+			return nil
+		})
+	}
+	info.Verified = verr == nil
+	info.noProvenance = errors.Is(verr, smelt.ErrNoProvenance)
+	var integrity *smelt.IntegrityError
+	if errors.As(verr, &integrity) {
+		info.Problems = integrity.Problems
+	}
+	return info
+}
+
+// chooseEmbeddedMold picks the mold cast or forge uses from a binary
+// smelted with several: name (their --mold flag) when set, else the
+// binary's default, else one the user picks from a prompt. args are the
+// command's mold arguments; --mold only applies when there are none.
+func chooseEmbeddedMold(name string, args []string) error {
+	if len(args) > 0 || !smelt.HasEmbeddedMold() {
+		if name != "" {
+			return errors.New("--mold picks a mold embedded in a binary built with ailloy smelt -o binary; it can't be combined with a mold argument")
+		}
+		return nil
+	}
+	if name != "" {
+		return smelt.SelectEmbeddedMold(name)
+	}
+	index, err := smelt.EmbeddedMolds()
+	if err != nil || index == nil || index.Default != "" {
+		return err
+	}
+	if !isInteractive() {
+		return fmt.Errorf("this binary embeds several molds (%s); choose one with --mold", strings.Join(index.Names(), ", "))
+	}
+	options := make([]huh.Option[string], 0, len(index.Molds))
+	for _, m := range index.Molds {
+		label := m.Name + "@" + m.Version
+		if m.Description != "" {
+			label += " — " + m.Description
+		}
+		options = append(options, huh.NewOption(label, m.Name))
+	}
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Which mold should be used?").
+				Options(options...).
+				Value(&name),
+		),
+	).WithTheme(ailloyTheme())
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("prompt failed: %w", err)
+	}
+	return smelt.SelectEmbeddedMold(name)
+}
@@ -12,11 +12,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/nimble-giant/ailloy/internal/tui/evolution"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +26,15 @@ import (
 const (
 	evolveRepoOwner = "nimble-giant"
 	evolveRepoName  = "ailloy"
+
+	// Release channels. stable follows the latest full release; beta also
+	// takes prereleases, whichever is newest.
+	evolveChannelStable = "stable"
+	evolveChannelBeta   = "beta"
+
+	// evolveKeepBackups is how many replaced binaries evolve keeps under
+	// ~/.ailloy/bin-backups/ for --rollback.
+	evolveKeepBackups = 3
 )
 
 var (
@@ -32,6 +43,8 @@ var (
 	evolvePin      string
 	evolveSkipAnim bool
 	evolveDemo     bool
+	evolveChannel  string
+	evolveRollback bool
 )
 
 var (
@@ -51,10 +64,21 @@ Aliased as 'reinstall' for users who reach for that name first.
 
 Fetches the latest release from GitHub, verifies SHA256 against the
 release's checksums.txt, and atomically swaps the running binary in
-place. Skips Homebrew installs by default — those should run
-'brew upgrade nimble-giant/tap/ailloy' instead. Use --force to override.
+place. On Windows, where a running .exe can't be overwritten, the old
+binary is moved aside and deleted once it is no longer in use.
+
+Skips installs owned by a package manager (Homebrew, apt, rpm, Scoop,
+Chocolatey, winget, Snap, Nix) and prints its upgrade command instead,
+e.g. 'brew upgrade nimble-giant/tap/ailloy'. Use --force to override.
+
+Use --version to install or downgrade to a specific release tag.
 
-Use --version to install or downgrade to a specific release tag.`,
+--channel picks the release channel: stable (default) or beta, which also
+takes prereleases. Set a default with evolve.channel in
+~/.ailloy/config.yaml.
+
+Each upgrade keeps the replaced binary under ~/.ailloy/bin-backups/ (the
+last 3). --rollback restores the most recent one.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE:          runEvolve,
@@ -63,9 +87,14 @@ Use --version to install or downgrade to a specific release tag.`,
 func init() {
 	rootCmd.AddCommand(evolveCmd)
 	evolveCmd.Flags().BoolVar(&evolveCheck, "check", false, "print available version without installing")
-	evolveCmd.Flags().BoolVar(&evolveForce, "force", false, "evolve even if installed via Homebrew")
+	evolveCmd.Flags().BoolVar(&evolveForce, "force", false, "evolve even if installed via a package manager")
 	evolveCmd.Flags().StringVar(&evolvePin, "version", "", "install a specific release tag (e.g. v0.6.19)")
 	evolveCmd.Flags().BoolVar(&evolveSkipAnim, "no-animate", false, "skip the evolution animation")
+	evolveCmd.Flags().StringVar(&evolveChannel, "channel", "", "release channel: stable or beta (default: evolve.channel in config.yaml, else stable)")
+	evolveCmd.Flags().BoolVar(&evolveRollback, "rollback", false, "restore the binary replaced by the last evolve")
+	evolveCmd.MarkFlagsMutuallyExclusive("rollback", "version")
+	evolveCmd.MarkFlagsMutuallyExclusive("rollback", "channel")
+	evolveCmd.MarkFlagsMutuallyExclusive("rollback", "check")
 	evolveCmd.Flags().BoolVar(&evolveDemo, "demo", false, "play the evolution cinematic without performing an upgrade (for QA / showing off)")
 	_ = evolveCmd.Flags().MarkHidden("demo")
 }
@@ -99,11 +128,20 @@ func runEvolve(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
+	if evolveRollback {
+		return runEvolveRollback()
+	}
+
+	channel, err := resolveEvolveChannel()
+	if err != nil {
+		return err
+	}
+
 	target := strings.TrimSpace(evolvePin)
 	if target == "" {
-		latest, err := fetchLatestTag()
+		latest, err := fetchChannelTag(channel)
 		if err != nil {
-			return fmt.Errorf("look up latest release: %w", err)
+			return fmt.Errorf("look up latest %s release: %w", channel, err)
 		}
 		target = latest
 	}
@@ -115,51 +153,273 @@ func runEvolve(_ *cobra.Command, _ []string) error {
 		if cmp, err := compareSemver(current, strings.TrimPrefix(target, "v")); err == nil && cmp == 0 {
 			fmt.Println(styles.SuccessStyle.Render("✓ ") + fmt.Sprintf("ailloy is already at %s", target))
 			return nil
+		} else if err == nil && cmp > 0 {
+			fmt.Println(styles.SuccessStyle.Render("✓ ") + fmt.Sprintf("ailloy %s is newer than the latest %s release (%s)", current, channel, target))
+			fmt.Println(styles.SubtleStyle.Render("    Pass --version " + target + " to downgrade"))
+			return nil
 		}
 	}
 
 	if evolveCheck {
 		fmt.Printf("current: %s\n", current)
-		fmt.Printf("latest:  %s\n", target)
+		fmt.Printf("latest:  %s (%s)\n", target, channel)
 		return nil
 	}
 
-	exePath, err := resolveExecutable()
+	exePath, err := swappableExecutable()
 	if err != nil {
-		return fmt.Errorf("locate current executable: %w", err)
+		return err
 	}
 
-	if runtime.GOOS == "windows" {
-		fmt.Println(styles.WarningStyle.Render("⚠️  ") +
-			"Self-upgrade is not supported on Windows.")
-		fmt.Println(styles.SubtleStyle.Render(
-			"    Download " + target + " from https://github.com/" +
-				evolveRepoOwner + "/" + evolveRepoName + "/releases"))
-		return errors.New("windows self-upgrade unsupported")
+	backup, err := backupExecutable(exePath, current)
+	if err != nil {
+		return fmt.Errorf("back up current binary: %w", err)
+	}
+	if err := installRelease(target, exePath); err != nil {
+		_ = os.Remove(backup)
+		return err
+	}
+
+	playEvolutionAnimation(target, evolveSkipAnim)
+
+	if out, err := exec.Command(exePath, "--version").CombinedOutput(); err == nil { // #nosec G204 -- exePath is the resolved path of our own executable
+		fmt.Println(strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// swappableExecutable returns the running binary's path when evolve may
+// replace it in place. Binaries owned by a package manager are refused
+// (unless --force) with the manager's own upgrade command.
+func swappableExecutable() (string, error) {
+	exePath, err := resolveExecutable()
+	if err != nil {
+		return "", fmt.Errorf("locate current executable: %w", err)
 	}
+	cleanReplacedExecutables(exePath)
 
-	if isHomebrewPath(exePath) && !evolveForce {
+	if m, ok := detectManagedInstall(exePath); ok && !evolveForce {
 		fmt.Println(styles.WarningStyle.Render("⚠️  ") +
-			"ailloy was installed via Homebrew.")
+			"ailloy was installed via " + m.Manager + ".")
 		fmt.Println(styles.SubtleStyle.Render(
-			"    Run: brew upgrade nimble-giant/tap/ailloy"))
+			"    Run: " + m.Upgrade))
 		fmt.Println(styles.SubtleStyle.Render(
 			"    (or pass --force to swap the binary anyway)"))
-		return errors.New("managed by Homebrew")
+		return "", fmt.Errorf("managed by %s", m.Manager)
 	}
+	return exePath, nil
+}
 
-	if err := installRelease(target, exePath); err != nil {
+// resolveEvolveChannel returns the release channel from --channel, else
+// evolve.channel in config.yaml, else stable.
+func resolveEvolveChannel() (string, error) {
+	channel := strings.TrimSpace(evolveChannel)
+	source := "--channel"
+	if channel == "" {
+		if cfg, err := index.LoadConfig(); err == nil {
+			channel, source = cfg.Evolve.Channel, "evolve.channel in config.yaml"
+		}
+	}
+	switch channel {
+	case "":
+		return evolveChannelStable, nil
+	case evolveChannelStable, evolveChannelBeta:
+		return channel, nil
+	}
+	return "", fmt.Errorf("invalid %s %q: use %s or %s", source, channel, evolveChannelStable, evolveChannelBeta)
+}
+
+// fetchChannelTag returns the newest release tag on channel.
+func fetchChannelTag(channel string) (string, error) {
+	if channel == evolveChannelBeta {
+		return fetchNewestTag()
+	}
+	return fetchLatestTag()
+}
+
+// fetchNewestTag returns the highest-versioned published release,
+// prereleases included. Drafts and non-semver tags are skipped.
+func fetchNewestTag() (string, error) {
+	url := evolveReleaseAPIBase + "/repos/" + evolveRepoOwner + "/" + evolveRepoName + "/releases?per_page=50"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := evolveHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github api returned %s", resp.Status)
+	}
+	var releases []struct {
+		TagName string `json:"tag_name"`
+		Draft   bool   `json:"draft"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", err
+	}
+	var best string
+	var bestVer *semver.Version
+	for _, r := range releases {
+		v, err := semver.NewVersion(strings.TrimPrefix(r.TagName, "v"))
+		if r.Draft || err != nil {
+			continue
+		}
+		if bestVer == nil || v.GreaterThan(bestVer) {
+			best, bestVer = r.TagName, v
+		}
+	}
+	if best == "" {
+		return "", errors.New("github api returned no releases")
+	}
+	return best, nil
+}
+
+// evolveBackupDir returns ~/.ailloy/bin-backups.
+func evolveBackupDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".ailloy", "bin-backups"), nil
+}
+
+// backupExecutable copies the binary at exePath into the backup directory
+// as ailloy-<version>, pruning all but the newest evolveKeepBackups copies.
+// It returns the backup's path.
+func backupExecutable(exePath, version string) (string, error) {
+	dir, err := evolveBackupDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	name := "ailloy-" + strings.TrimPrefix(version, "v")
+	if version == "dev" {
+		name = fmt.Sprintf("ailloy-dev-%d", time.Now().Unix())
+	}
+	dest := filepath.Join(dir, name)
+	if err := copyExecutable(exePath, dest); err != nil {
+		return "", err
+	}
+	// Mark it newest even if an older run left a file by the same name.
+	now := time.Now()
+	_ = os.Chtimes(dest, now, now)
+
+	backups, err := listBackups(dir)
+	if err != nil {
+		return dest, nil
+	}
+	for _, old := range backups[min(len(backups), evolveKeepBackups):] {
+		_ = os.Remove(old)
+	}
+	return dest, nil
+}
+
+// listBackups returns the backups in dir, newest first.
+func listBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type backup struct {
+		path string
+		mod  time.Time
+	}
+	var found []backup
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "ailloy-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, backup{filepath.Join(dir, e.Name()), info.ModTime()})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].mod.After(found[j].mod) })
+	paths := make([]string, len(found))
+	for i, b := range found {
+		paths[i] = b.path
+	}
+	return paths, nil
+}
+
+// copyExecutable copies src to dst with executable permissions.
+func copyExecutable(src, dst string) error {
+	in, err := os.Open(src) // #nosec G304 -- src is our own executable or one of its backups
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755) // #nosec G302 G304 -- backups must stay executable
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
 		return err
 	}
+	return os.Chmod(dst, 0o755) // #nosec G302 -- binary must be executable
+}
 
-	playEvolutionAnimation(target, evolveSkipAnim)
+// runEvolveRollback swaps the most recent backup back into place and
+// removes it from the backup directory.
+func runEvolveRollback() error {
+	dir, err := evolveBackupDir()
+	if err != nil {
+		return err
+	}
+	backups, err := listBackups(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read %s: %w", dir, err)
+	}
+	if len(backups) == 0 {
+		return fmt.Errorf("no backups in %s: nothing to roll back to", dir)
+	}
 
+	exePath, err := swappableExecutable()
+	if err != nil {
+		return err
+	}
+	if err := restoreBackup(backups[0], exePath); err != nil {
+		return err
+	}
+
+	fmt.Println(styles.SuccessStyle.Render("✓ ") + "Rolled back to " + strings.TrimPrefix(filepath.Base(backups[0]), "ailloy-"))
 	if out, err := exec.Command(exePath, "--version").CombinedOutput(); err == nil { // #nosec G204 -- exePath is the resolved path of our own executable
 		fmt.Println(strings.TrimSpace(string(out)))
 	}
 	return nil
 }
 
+// restoreBackup atomically replaces destPath with the backup and deletes
+// the backup.
+func restoreBackup(backup, destPath string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), ".ailloy-rollback-*")
+	if err != nil {
+		return fmt.Errorf("create temp file in %s: %w", filepath.Dir(destPath), err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	if err := copyExecutable(backup, tmpPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("copy %s: %w", backup, err)
+	}
+	if err := replaceExecutable(tmpPath, destPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("replace binary at %s: %w", destPath, err)
+	}
+	return os.Remove(backup)
+}
+
 func resolveExecutable() (string, error) {
 	exe, err := os.Executable()
 	if err != nil {
@@ -245,7 +505,13 @@ func assetName(goos, goarch string) string {
 }
 
 func installRelease(tag, destPath string) error {
-	asset := assetName(runtime.GOOS, runtime.GOARCH)
+	return downloadReleaseAsset(tag, assetName(runtime.GOOS, runtime.GOARCH), destPath)
+}
+
+// downloadReleaseAsset downloads one asset of release tag to destPath,
+// verifying it against the release's checksums.txt. The file is written next
+// to destPath and renamed into place only once the checksum matches.
+func downloadReleaseAsset(tag, asset, destPath string) error {
 	releaseBase := fmt.Sprintf("%s/%s/%s/releases/download/%s",
 		evolveReleaseDLBase, evolveRepoOwner, evolveRepoName, tag)
 
@@ -299,7 +565,7 @@ func installRelease(tag, destPath string) error {
 	if err := os.Chmod(tmpPath, 0o755); err != nil { // #nosec G302 -- binary must be executable
 		return fmt.Errorf("chmod new binary: %w", err)
 	}
-	if err := os.Rename(tmpPath, destPath); err != nil {
+	if err := replaceExecutable(tmpPath, destPath); err != nil {
 		return fmt.Errorf("replace binary at %s: %w", destPath, err)
 	}
 	keepTmp = true
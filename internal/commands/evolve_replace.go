@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// evolveGOOS is the platform evolve swaps binaries for. A variable so tests
+// can exercise the Windows strategy anywhere.
+var evolveGOOS = runtime.GOOS
+
+// evolveRemove deletes a moved-aside binary; a variable so tests can make
+// it fail the way it does on Windows while the old binary still runs.
+var evolveRemove = os.Remove
+
+// replacedExecutablePattern matches binaries moved aside by a Windows swap,
+// left next to the new one until they are no longer locked.
+const replacedExecutablePattern = ".ailloy-replaced-*"
+
+// replaceExecutable moves the verified binary at src over dst.
+//
+// On Unix a rename replaces the running binary atomically. Windows refuses
+// to overwrite an executable that is running, but does allow renaming it,
+// so there the current binary is first moved aside, src renamed into its
+// place, and the old file deleted if it is no longer locked. It usually is
+// (it is the running evolve), so sweepReplacedExecutables removes it when
+// the next ailloy command starts.
+func replaceExecutable(src, dst string) error {
+	if evolveGOOS != "windows" {
+		return os.Rename(src, dst)
+	}
+
+	aside := filepath.Join(filepath.Dir(dst), fmt.Sprintf(".ailloy-replaced-%d", time.Now().UnixNano()))
+	if err := os.Rename(dst, aside); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("move current binary aside: %w", err)
+		}
+		aside = ""
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if aside != "" {
+			_ = os.Rename(aside, dst)
+		}
+		return err
+	}
+	if aside != "" {
+		_ = evolveRemove(aside)
+	}
+	return nil
+}
+
+// cleanReplacedExecutables deletes binaries a previous Windows swap moved
+// aside next to exePath. Files still locked by a running ailloy are skipped.
+func cleanReplacedExecutables(exePath string) {
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(exePath), replacedExecutablePattern))
+	for _, m := range matches {
+		_ = os.Remove(m)
+	}
+}
+
+// sweepReplacedExecutables runs cleanReplacedExecutables for the running
+// binary on Windows, at the start of every command, so a binary evolve
+// moved aside doesn't linger until the next evolve.
+func sweepReplacedExecutables() {
+	if evolveGOOS != "windows" {
+		return
+	}
+	if exe, err := resolveExecutable(); err == nil {
+		cleanReplacedExecutables(exe)
+	}
+}
+
+// managedInstall describes a package manager that owns the ailloy binary.
+type managedInstall struct {
+	Manager string // human-readable name, e.g. "Homebrew"
+	Upgrade string // the command that upgrades ailloy through it
+}
+
+// evolvePackageQuery runs a package database lookup and reports whether it
+// succeeded. A variable so tests can stand in for dpkg and rpm.
+var evolvePackageQuery = func(name string, args ...string) bool {
+	if _, err := exec.LookPath(name); err != nil {
+		return false
+	}
+	return exec.Command(name, args...).Run() == nil // #nosec G204 -- fixed package tools queried about our own path
+}
+
+// detectManagedInstall reports which package manager, if any, installed the
+// binary at path. Self-upgrading such a binary would fight the manager, so
+// evolve points at its upgrade command instead.
+func detectManagedInstall(path string) (managedInstall, bool) {
+	if isHomebrewPath(path) {
+		return managedInstall{"Homebrew", "brew upgrade nimble-giant/tap/ailloy"}, true
+	}
+
+	// Windows installs are matched case-insensitively on forward slashes so
+	// the checks hold whichever platform evaluates them.
+	p := strings.ToLower(strings.ReplaceAll(path, `\`, "/"))
+	switch {
+	case strings.Contains(p, "/scoop/apps/"), strings.Contains(p, "/scoop/shims/"):
+		return managedInstall{"Scoop", "scoop update ailloy"}, true
+	case strings.Contains(p, "/chocolatey/"):
+		return managedInstall{"Chocolatey", "choco upgrade ailloy"}, true
+	case strings.Contains(p, "/winget/"):
+		return managedInstall{"winget", "winget upgrade ailloy"}, true
+	case strings.HasPrefix(p, "/snap/"):
+		return managedInstall{"Snap", "sudo snap refresh ailloy"}, true
+	case strings.HasPrefix(p, "/nix/store/"):
+		return managedInstall{"Nix", "nix profile upgrade ailloy"}, true
+	}
+
+	// System directories are only package-owned when the package database
+	// says so; a binary copied into /usr/bin by hand can still evolve.
+	if evolveGOOS == "linux" && (strings.HasPrefix(p, "/usr/bin/") || strings.HasPrefix(p, "/usr/sbin/")) {
+		if evolvePackageQuery("dpkg-query", "-S", path) {
+			return managedInstall{"apt", "sudo apt-get update && sudo apt-get install --only-upgrade ailloy"}, true
+		}
+		if evolvePackageQuery("rpm", "-qf", path) {
+			return managedInstall{"rpm", "sudo dnf upgrade ailloy"}, true
+		}
+	}
+	return managedInstall{}, false
+}
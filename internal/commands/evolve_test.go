@@ -1,8 +1,15 @@
 package commands
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestEvolveAnimationArt(t *testing.T) {
@@ -107,3 +114,196 @@ e99c75c95f88d37730d509ac48395d1420440cdf9a470a10167c71e92f7746d6 *ailloy-darwin-
 		}
 	}
 }
+
+func TestResolveEvolveChannel(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { evolveChannel = "" })
+
+	evolveChannel = ""
+	if got, err := resolveEvolveChannel(); err != nil || got != evolveChannelStable {
+		t.Errorf("default = (%q, %v), want stable", got, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".ailloy"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ailloy", "config.yaml"), []byte("evolve:\n  channel: beta\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := resolveEvolveChannel(); err != nil || got != evolveChannelBeta {
+		t.Errorf("from config = (%q, %v), want beta", got, err)
+	}
+
+	evolveChannel = "stable"
+	if got, err := resolveEvolveChannel(); err != nil || got != evolveChannelStable {
+		t.Errorf("flag over config = (%q, %v), want stable", got, err)
+	}
+
+	evolveChannel = "nightly"
+	if _, err := resolveEvolveChannel(); err == nil || !strings.Contains(err.Error(), "--channel") {
+		t.Errorf("err = %v, want invalid --channel", err)
+	}
+}
+
+func TestFetchNewestTag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"tag_name": "v0.9.0", "draft": true},
+			{"tag_name": "v0.8.0-beta.2", "prerelease": true},
+			{"tag_name": "v0.7.3"},
+			{"tag_name": "nightly"}
+		]`))
+	}))
+	defer srv.Close()
+	orig := evolveReleaseAPIBase
+	evolveReleaseAPIBase = srv.URL
+	t.Cleanup(func() { evolveReleaseAPIBase = orig })
+
+	got, err := fetchNewestTag()
+	if err != nil || got != "v0.8.0-beta.2" {
+		t.Errorf("fetchNewestTag() = (%q, %v), want the newest non-draft v0.8.0-beta.2", got, err)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	exe := filepath.Join(t.TempDir(), "ailloy")
+
+	for i, v := range []string{"0.6.0", "v0.7.0", "0.8.0", "0.9.0"} {
+		if err := os.WriteFile(exe, []byte("binary "+v), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := backupExecutable(exe, v); err != nil {
+			t.Fatalf("backup %s: %v", v, err)
+		}
+		// Keep mtimes strictly ordered regardless of clock resolution.
+		stamp := time.Now().Add(time.Duration(i-10) * time.Minute)
+		dir, _ := evolveBackupDir()
+		_ = os.Chtimes(filepath.Join(dir, "ailloy-"+strings.TrimPrefix(v, "v")), stamp, stamp)
+	}
+
+	dir, _ := evolveBackupDir()
+	backups, err := listBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, b := range backups {
+		names = append(names, filepath.Base(b))
+	}
+	if want := []string{"ailloy-0.9.0", "ailloy-0.8.0", "ailloy-0.7.0"}; !slices.Equal(names, want) {
+		t.Errorf("backups = %v, want newest %d %v", names, evolveKeepBackups, want)
+	}
+
+	if err := os.WriteFile(exe, []byte("binary 1.0.0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreBackup(backups[0], exe); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	data, err := os.ReadFile(exe) // #nosec G304 -- test temp path
+	if err != nil || string(data) != "binary 0.9.0" {
+		t.Errorf("restored %q (%v), want binary 0.9.0", data, err)
+	}
+	if info, err := os.Stat(exe); err != nil || info.Mode()&0o111 == 0 {
+		t.Errorf("restored binary not executable: %v", err)
+	}
+	if _, err := os.Stat(backups[0]); !os.IsNotExist(err) {
+		t.Errorf("used backup should be removed, stat err = %v", err)
+	}
+}
+
+func TestDetectManagedInstall(t *testing.T) {
+	origGOOS, origQuery := evolveGOOS, evolvePackageQuery
+	t.Cleanup(func() { evolveGOOS, evolvePackageQuery = origGOOS, origQuery })
+	evolveGOOS = "linux"
+	evolvePackageQuery = func(name string, args ...string) bool {
+		return name == "dpkg-query" && args[len(args)-1] == "/usr/bin/ailloy"
+	}
+
+	cases := []struct {
+		path, manager string
+	}{
+		{"/opt/homebrew/bin/ailloy", "Homebrew"},
+		{`C:\Users\alice\scoop\apps\ailloy\current\ailloy.exe`, "Scoop"},
+		{`C:\ProgramData\chocolatey\lib\ailloy\tools\ailloy.exe`, "Chocolatey"},
+		{`C:\Users\alice\AppData\Local\Microsoft\WinGet\Packages\ailloy\ailloy.exe`, "winget"},
+		{"/snap/ailloy/12/bin/ailloy", "Snap"},
+		{"/usr/bin/ailloy", "apt"},
+		{"/usr/sbin/ailloy", ""}, // not in the package database
+		{"/usr/local/bin/ailloy", ""},
+		{`C:\tools\ailloy.exe`, ""},
+	}
+	for _, tc := range cases {
+		m, ok := detectManagedInstall(tc.path)
+		if m.Manager != tc.manager || ok != (tc.manager != "") {
+			t.Errorf("detectManagedInstall(%q) = (%+v, %v), want %q", tc.path, m, ok, tc.manager)
+		}
+	}
+}
+
+func TestReplaceExecutable_Windows(t *testing.T) {
+	orig := evolveGOOS
+	evolveGOOS = "windows"
+	t.Cleanup(func() { evolveGOOS = orig })
+
+	dir := t.TempDir()
+	dst, src := filepath.Join(dir, "ailloy.exe"), filepath.Join(dir, "new")
+	if err := os.WriteFile(dst, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("new"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceExecutable(src, dst); err != nil {
+		t.Fatalf("replaceExecutable: %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new" { // #nosec G304 -- test temp path
+		t.Errorf("dst = %q, want the new binary", data)
+	}
+	// An unlocked old binary is deleted straight away.
+	if matches, _ := filepath.Glob(filepath.Join(dir, replacedExecutablePattern)); len(matches) != 0 {
+		t.Errorf("left behind %v", matches)
+	}
+
+	// The running binary can't be deleted; it is left aside and cleaned
+	// once it is no longer locked.
+	evolveRemove = func(string) error { return errors.New("access is denied") }
+	t.Cleanup(func() { evolveRemove = os.Remove })
+	if err := os.WriteFile(src, []byte("newer"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceExecutable(src, dst); err != nil {
+		t.Fatalf("replaceExecutable with a locked binary: %v", err)
+	}
+	stale, _ := filepath.Glob(filepath.Join(dir, replacedExecutablePattern))
+	if len(stale) != 1 {
+		t.Fatalf("moved-aside binaries = %v, want the locked one", stale)
+	}
+	evolveRemove = os.Remove
+	cleanReplacedExecutables(dst)
+	if _, err := os.Stat(stale[0]); !os.IsNotExist(err) {
+		t.Errorf("stale binary not cleaned: %v", err)
+	}
+}
+
+func TestSweepReplacedExecutables(t *testing.T) {
+	orig := evolveGOOS
+	evolveGOOS = "windows"
+	t.Cleanup(func() { evolveGOOS = orig })
+
+	exe, err := resolveExecutable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(filepath.Dir(exe), ".ailloy-replaced-1")
+	if err := os.WriteFile(stale, []byte("old"), 0o755); err != nil {
+		t.Skipf("test binary directory not writable: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(stale) })
+	sweepReplacedExecutables()
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("startup sweep left %s: %v", stale, err)
+	}
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+)
+
+// Kinds of mold-supplied commands that need consent.
+const (
+	execKindHooks    = "hooks"
+	execKindDiscover = "discover"
+)
+
+// rootNoExec is the global --no-exec flag: no mold-supplied command runs.
+var rootNoExec bool
+
+// execConsents is ~/.ailloy/exec-consent.yaml: for each mold, and each kind
+// of command it runs, the fingerprint of the commands the user agreed to.
+// A mold whose commands change is asked about again.
+type execConsents struct {
+	Molds map[string]map[string]string `yaml:"molds"`
+}
+
+// declinedExec holds the consents declined this run, so a mold with both
+// pre- and post-cast hooks is only asked about once.
+var declinedExec sync.Map
+
+// execConsentFile returns ~/.ailloy/exec-consent.yaml.
+func execConsentFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ailloy", "exec-consent.yaml"), nil
+}
+
+// execConsentKey identifies a mold in exec-consent.yaml: its source when it
+// has one, its name otherwise.
+func execConsentKey(source string, m *mold.Mold) string {
+	if source != "" || m == nil {
+		return source
+	}
+	return m.Name
+}
+
+// currentExecPolicy returns the policy for mold-supplied commands: the
+// effective `exec:` settings of config.yaml, disabled by --no-exec.
+func currentExecPolicy() (mold.ExecPolicy, error) {
+	cfg, err := index.LoadConfig()
+	if err != nil {
+		return mold.ExecPolicy{}, err
+	}
+	eff := cfg.EffectiveExec()
+	return mold.ExecPolicy{Disabled: rootNoExec || eff.Disabled, Allow: eff.Allow}, nil
+}
+
+// confirmMoldExec reports whether the mold at key may run its commands of
+// the given kind, identified by fingerprint. Consent already recorded for
+// that fingerprint is reused; otherwise summary is shown and the user
+// asked, and a yes is remembered. --yes (or AILLOY_YES) approves them for
+// this run without asking, logging summary. Without a terminal to ask on,
+// consent is refused with a warning.
+func confirmMoldExec(key, kind, fingerprint, name, summary string) (bool, error) {
+	id := key + "\x00" + kind + "\x00" + fingerprint
+	if _, declined := declinedExec.Load(id); declined {
+		return false, nil
+	}
+	path, err := execConsentFile()
+	if err != nil {
+		return false, err
+	}
+	var consents execConsents
+	if data, err := os.ReadFile(path); err == nil { // #nosec G304 -- ailloy's own state file
+		if err := yaml.Unmarshal(data, &consents); err != nil {
+			log.Printf("warning: ignoring unreadable %s: %v", displayPath(path), err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if consents.Molds[key][kind] == fingerprint {
+		return true, nil
+	}
+
+	if assumeYes() {
+		log.Printf("running the %s commands of %s without review (--yes):\n%s", kind, name, strings.TrimRight(summary, "\n"))
+		return true, nil
+	}
+	if !isInteractive() {
+		log.Printf("warning: not running the %s commands of %s: they have not been approved; run from a terminal to review them, or pass --yes", kind, name)
+		declinedExec.Store(id, true)
+		return false, nil
+	}
+	ok, err := confirmInteractive(os.Stdin, os.Stdout, "\n"+summary+"Run them? [y/N] ")
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		fmt.Println(styles.SubtleStyle.Render("Not running them; you'll be asked again next time."))
+		declinedExec.Store(id, true)
+		return false, nil
+	}
+
+	if consents.Molds == nil {
+		consents.Molds = map[string]map[string]string{}
+	}
+	if consents.Molds[key] == nil {
+		consents.Molds[key] = map[string]string{}
+	}
+	consents.Molds[key][kind] = fingerprint
+	data, err := yaml.Marshal(consents)
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		log.Printf("warning: failed to record consent: %v", err)
+	}
+	return true, nil
+}
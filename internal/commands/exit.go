@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/huh"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/smelt"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, one per failure class, so scripts can branch on why a command
+// failed without parsing its output. They are part of the CLI's contract.
+const (
+	ExitFailure    = 1   // any failure without a more specific class
+	ExitUsage      = 2   // unknown flag, bad flag value, or wrong arguments
+	ExitConfig     = 3   // config.yaml, ailloy.yaml, value files or state files are unreadable or invalid
+	ExitValidation = 4   // a check failed: temper, assay, quench --verify, status --check, doctor
+	ExitResolution = 5   // a mold or dependency couldn't be resolved or fetched
+	ExitRender     = 6   // a blank failed to render
+	ExitAborted    = 130 // the user cancelled a prompt or declined to continue
+)
+
+// exitError tags err with the exit code of its failure class.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+func usageError(err error) error      { return withExitCode(ExitUsage, err) }
+func configError(err error) error     { return withExitCode(ExitConfig, err) }
+func validationError(err error) error { return withExitCode(ExitValidation, err) }
+func resolutionError(err error) error { return withExitCode(ExitResolution, err) }
+func renderError(err error) error     { return withExitCode(ExitRender, err) }
+func abortError(err error) error      { return withExitCode(ExitAborted, err) }
+
+// exitCodeFor returns the exit code for a command's error. A tag added by
+// one of the wrappers above wins (the outermost one); otherwise the error
+// types the packages return are classified.
+func exitCodeFor(err error) int {
+	var tagged *exitError
+	var (
+		notCached   *foundry.NotCachedError
+		missingRefs *foundry.MissingRefsError
+		yanked      *foundry.YankedError
+		schema      *foundry.SchemaError
+		sealed      *mold.SealedFluxError
+		cycle       *mold.ExtendsCycleError
+		integrity   *smelt.IntegrityError
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &tagged):
+		return tagged.code
+	case errors.Is(err, huh.ErrUserAborted):
+		return ExitAborted
+	case errors.As(err, &schema), errors.As(err, &sealed):
+		return ExitConfig
+	case errors.As(err, &cycle), errors.As(err, &integrity):
+		return ExitValidation
+	case errors.As(err, &notCached), errors.As(err, &missingRefs), errors.As(err, &yanked),
+		errors.Is(err, foundry.ErrNoSemverTags), errors.Is(err, index.ErrNotFound), errors.Is(err, index.ErrForbidden):
+		return ExitResolution
+	}
+	return ExitFailure
+}
+
+// tagUsageErrors makes flag parsing and argument validation errors exit
+// with ExitUsage, for cmd and every command below it.
+func tagUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return usageError(err)
+	})
+	var tag func(*cobra.Command)
+	tag = func(c *cobra.Command) {
+		if args := c.Args; args != nil {
+			c.Args = func(c *cobra.Command, a []string) error {
+				return usageError(args(c, a))
+			}
+		}
+		for _, sub := range c.Commands() {
+			tag(sub)
+		}
+	}
+	tag(cmd)
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/huh"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/spf13/cobra"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"untagged", errors.New("boom"), ExitFailure},
+		{"config", configError(errors.New("bad config")), ExitConfig},
+		{"validation wrapped again", fmt.Errorf("temper: %w", validationError(errors.New("2 errors"))), ExitValidation},
+		{"outermost tag wins", renderError(resolutionError(errors.New("x"))), ExitRender},
+		{"prompt cancelled", fmt.Errorf("prompt failed: %w", huh.ErrUserAborted), ExitAborted},
+		{"not cached", fmt.Errorf("resolving: %w", &foundry.NotCachedError{Ref: "github.com/acme/m"}), ExitResolution},
+		{"forbidden", fmt.Errorf("cloning: %w", index.ErrForbidden), ExitResolution},
+		{"newer schema", &foundry.SchemaError{Path: "ailloy.lock", Version: 9, Supported: 1}, ExitConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeFor(tt.err); got != tt.want {
+				t.Errorf("exitCodeFor(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+	if withExitCode(ExitConfig, nil) != nil {
+		t.Error("tagging a nil error should stay nil")
+	}
+}
+
+func TestTagUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	child := &cobra.Command{Use: "child", Args: cobra.NoArgs, RunE: func(*cobra.Command, []string) error { return nil }}
+	child.Flags().Int("n", 0, "")
+	root.AddCommand(child)
+	tagUsageErrors(root)
+
+	for _, args := range [][]string{{"child", "extra"}, {"child", "--bogus"}, {"child", "--n", "x"}} {
+		root.SetArgs(args)
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+		if got := exitCodeFor(root.Execute()); got != ExitUsage {
+			t.Errorf("%v: exit code %d, want %d", args, got, ExitUsage)
+		}
+	}
+}
+
+func TestSyncProjectMolds_MissingProjectFileIsConfigError(t *testing.T) {
+	dir := t.TempDir()
+	err := syncProjectMolds(t.Context(), filepath.Join(dir, "ailloy.yaml"), syncOptions{})
+	if got := exitCodeFor(err); got != ExitConfig {
+		t.Fatalf("exit code %d (%v), want %d", got, err, ExitConfig)
+	}
+}
+
+func TestLayerFlux_BadValueFileIsConfigError(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: m\nversion: 1.0.0\n")
+	bad := filepath.Join(dir, "bad.yaml")
+	mustWrite(t, bad, "a: [unclosed\n")
+	reader, _, err := openMoldReaderForCore(dir, false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = layerFluxForCore(reader, "", []string{bad}, fluxSets{}, false, nil)
+	if got := exitCodeFor(err); got != ExitConfig {
+		t.Fatalf("exit code %d (%v), want %d", got, err, ExitConfig)
+	}
+	_, _, err = layerFluxForCore(reader, "", nil, fluxSets{Set: []string{"novalue"}}, false, nil)
+	if got := exitCodeFor(err); got != ExitUsage {
+		t.Fatalf("exit code %d (%v), want %d", got, err, ExitUsage)
+	}
+}
+
+func TestRunCast_MissingValueFileIsConfigError(t *testing.T) {
+	resetCastFlags()
+	moldDir := fluxCastMold(t)
+	castValFiles = []string{filepath.Join(t.TempDir(), "missing.yaml")}
+	err := runFluxCast(t, moldDir)
+	if got := exitCodeFor(err); got != ExitConfig {
+		t.Fatalf("exit code %d (%v), want %d", got, err, ExitConfig)
+	}
+}
@@ -0,0 +1,304 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/scope"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <mold> [flux.var...]",
+	Short: "Show where each flux value of a cast comes from",
+	Long: `Layer a mold's flux exactly as cast does and show, for each value, the
+layer that set it and the values it overrode. Nothing is installed.
+
+Layers, lowest precedence first:
+
+  mold flux.yaml         the mold's flux.yaml
+  ore defaults           defaults of installed ores
+  mold flux.secret.yaml  the mold's encrypted values, when sops decrypts them
+  schema default         default: of a flux variable declared in mold.yaml
+  mold output            mold.yaml's output: mapping
+  git remote             host, organization, repository and default branch
+                         of the current repository's origin (not with -g)
+  system flux file       persisted flux under the system root
+  global flux file       ~/.ailloy/flux/<mold>.yaml
+  workspace flux file    .ailloy/flux/<mold>.yaml of an enclosing workspace
+  project flux file      ./.ailloy/flux/<mold>.yaml
+  -f                     each values file, left to right
+  --set                  --set flags
+  cast context           the _ailloy.* values cast adds for templates
+
+The mold is a local directory or a remote reference, as cast takes. Name
+flux variables after it to explain only those; a name also matches the
+values nested under it (scm matches scm.host). _ailloy.* values are shown
+only when asked for by name.
+
+Example:
+  ailloy explain . scm.host
+  ailloy explain github.com/acme/tools -f team.yaml --set board=Ops
+  ailloy explain github.com/acme/tools -o json`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeCachedRefs,
+	RunE:              runExplain,
+}
+
+var (
+	explainValFiles  []string
+	explainSetFlags  []string
+	explainGlobal    bool
+	explainOutputFmt string
+)
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringArrayVarP(&explainValFiles, "values", "f", nil, "flux value files (can be repeated, later files override earlier)")
+	explainCmd.Flags().StringArrayVar(&explainSetFlags, "set", nil, "override flux variable (format: key=value, can be repeated)")
+	_ = explainCmd.RegisterFlagCompletionFunc("set", completeSetFlag(1))
+	explainCmd.Flags().BoolVarP(&explainGlobal, "global", "g", false, "explain a global cast (no git remote values, global ores)")
+	addOutputFlag(explainCmd, &explainOutputFmt)
+}
+
+// Flux layers recorded by fluxTrace, in layering order.
+const (
+	fluxLayerMold      = "mold flux.yaml"
+	fluxLayerOre       = "ore defaults"
+	fluxLayerSecret    = "mold flux.secret.yaml"
+	fluxLayerSchema    = "schema default"
+	fluxLayerOutput    = "mold output"
+	fluxLayerGitRemote = "git remote"
+	fluxLayerSystem    = "system flux file"
+	fluxLayerGlobal    = "global flux file"
+	fluxLayerWorkspace = "workspace flux file"
+	fluxLayerProject   = "project flux file"
+	fluxLayerValues    = "-f"
+	fluxLayerSet       = "--set"
+	fluxLayerContext   = "cast context"
+)
+
+// fluxOrigin is one layer setting a flux value.
+type fluxOrigin struct {
+	Layer string `json:"layer" yaml:"layer"`
+	File  string `json:"file,omitempty" yaml:"file,omitempty"`
+	Value any    `json:"value" yaml:"value"`
+}
+
+// String names the layer and, for file layers, the file.
+func (o fluxOrigin) String() string {
+	if o.File == "" {
+		return o.Layer
+	}
+	return o.Layer + " " + displayPath(o.File)
+}
+
+// fluxTrace maps each leaf of a flux map, by dotted key, to the layers that
+// set it in order; the last one holds the final value. A nil fluxTrace
+// records nothing, so layerFlux only pays for tracing when explain asks.
+type fluxTrace map[string][]fluxOrigin
+
+// record credits origin with every leaf of flux that is new or changed
+// since the last layer, and forgets leaves flux no longer has.
+func (t fluxTrace) record(flux map[string]any, origin fluxOrigin) {
+	if t == nil {
+		return
+	}
+	walkFluxLeaves(flux, "", func(key string, v any) {
+		if hist := t[key]; len(hist) == 0 || !reflect.DeepEqual(hist[len(hist)-1].Value, v) {
+			t.add(key, origin, v)
+		}
+	})
+	t.prune(flux)
+}
+
+// recordFiles credits each file in paths with the leaves it set: those
+// whose value the files layered up to and including it still hold.
+// layerFor names the layer a file belongs to.
+func (t fluxTrace) recordFiles(flux map[string]any, paths []string, layerFor func(path string) string) {
+	if t == nil {
+		return
+	}
+	for i, p := range paths {
+		upTo, err := mold.LayerFluxFiles(paths[:i+1])
+		if err != nil {
+			continue
+		}
+		own, err := mold.LayerFluxFiles([]string{p})
+		if err != nil {
+			continue
+		}
+		walkFluxLeaves(own, "", func(key string, v any) {
+			if got, ok := mold.GetNestedAny(upTo, key); ok && reflect.DeepEqual(got, v) {
+				t.add(key, fluxOrigin{Layer: layerFor(p), File: p}, v)
+			}
+		})
+	}
+	t.prune(flux)
+}
+
+// recordSets credits --set with the leaves under each key it set.
+func (t fluxTrace) recordSets(flux map[string]any, sets []string) {
+	if t == nil {
+		return
+	}
+	for _, s := range sets {
+		key := mold.SetOverrideKey(s)
+		v, ok := mold.GetNestedAny(flux, key)
+		if !ok {
+			continue
+		}
+		if m, isMap := v.(map[string]any); isMap && len(m) > 0 {
+			walkFluxLeaves(m, key+".", func(leaf string, lv any) {
+				t.add(leaf, fluxOrigin{Layer: fluxLayerSet}, lv)
+			})
+			continue
+		}
+		t.add(key, fluxOrigin{Layer: fluxLayerSet}, v)
+	}
+	t.prune(flux)
+}
+
+func (t fluxTrace) add(key string, origin fluxOrigin, v any) {
+	origin.Value = v
+	t[key] = append(t[key], origin)
+}
+
+// prune drops keys that are no longer leaves of flux, e.g. nested values a
+// later layer replaced along with their top-level key.
+func (t fluxTrace) prune(flux map[string]any) {
+	for key := range t {
+		v, ok := mold.GetNestedAny(flux, key)
+		if m, isMap := v.(map[string]any); !ok || (isMap && len(m) > 0) {
+			delete(t, key)
+		}
+	}
+}
+
+// walkFluxLeaves calls fn with the dotted key and value of every leaf of m:
+// values other than non-empty maps.
+func walkFluxLeaves(m map[string]any, prefix string, fn func(key string, v any)) {
+	for k, v := range m {
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			walkFluxLeaves(nested, prefix+k+".", fn)
+			continue
+		}
+		fn(prefix+k, v)
+	}
+}
+
+// persistedFluxLayer names the layer of a path from mold.PersistedFluxPaths.
+func persistedFluxLayer(path string) string {
+	if root := scope.SystemRoot(); root != "" && strings.HasPrefix(path, root+string(os.PathSeparator)) {
+		return fluxLayerSystem
+	}
+	if !filepath.IsAbs(path) {
+		return fluxLayerProject
+	}
+	if home, err := os.UserHomeDir(); err == nil && filepath.Dir(path) == filepath.Join(home, ".ailloy", "flux") {
+		return fluxLayerGlobal
+	}
+	return fluxLayerWorkspace
+}
+
+// explainEntry is one flux value in explain's structured output.
+type explainEntry struct {
+	Key    string       `json:"key" yaml:"key"`
+	Value  any          `json:"value" yaml:"value"`
+	Set    bool         `json:"set" yaml:"set"`
+	Layers []fluxOrigin `json:"layers,omitempty" yaml:"layers,omitempty"` // lowest precedence first
+}
+
+func runExplain(_ *cobra.Command, args []string) error {
+	if err := validateOutputFormat(explainOutputFmt); err != nil {
+		return err
+	}
+	// resolveMoldReader follows cast's --global for remote resolution and
+	// ore lookup.
+	castGlobal = explainGlobal
+	reader, source, err := resolveMoldReader(args[:1])
+	if err != nil {
+		return err
+	}
+	trace := fluxTrace{}
+	if _, _, err := layerFlux(reader, source, explainValFiles, fluxSets{Set: explainSetFlags}, explainGlobal, nil, trace); err != nil {
+		return err
+	}
+
+	entries := explainEntries(trace, args[1:])
+	if explainOutputFmt != "" {
+		return writeStructured(os.Stdout, explainOutputFmt, entries)
+	}
+	if len(entries) == 0 {
+		fmt.Println("The mold has no flux values.")
+		return nil
+	}
+	for _, e := range entries {
+		if !e.Set {
+			fmt.Println(styles.AccentStyle.Render(e.Key) + styles.SubtleStyle.Render(" is not set"))
+			continue
+		}
+		fmt.Println(styles.AccentStyle.Render(e.Key) + " = " + explainValue(e.Value))
+		last := len(e.Layers) - 1
+		fmt.Println("  from " + e.Layers[last].String())
+		for i := last - 1; i >= 0; i-- {
+			fmt.Println(styles.SubtleStyle.Render("  overrides " + e.Layers[i].String() + " = " + explainValue(e.Layers[i].Value)))
+		}
+	}
+	return nil
+}
+
+// explainEntries returns the traced values matching keys, sorted by key.
+// A key matches itself and the values nested under it; with no keys, all
+// values but _ailloy.* match. Keys nothing matches are reported unset.
+func explainEntries(trace fluxTrace, keys []string) []explainEntry {
+	matches := func(leaf string) bool {
+		if len(keys) == 0 {
+			return leaf != mold.ContextKey && !strings.HasPrefix(leaf, mold.ContextKey+".")
+		}
+		for _, k := range keys {
+			if leaf == k || strings.HasPrefix(leaf, k+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	entries := []explainEntry{}
+	matched := map[string]bool{}
+	for leaf, layers := range trace {
+		if !matches(leaf) || len(layers) == 0 {
+			continue
+		}
+		entries = append(entries, explainEntry{Key: leaf, Value: layers[len(layers)-1].Value, Set: true, Layers: layers})
+		for _, k := range keys {
+			if leaf == k || strings.HasPrefix(leaf, k+".") {
+				matched[k] = true
+			}
+		}
+	}
+	for _, k := range keys {
+		if !matched[k] {
+			entries = append(entries, explainEntry{Key: k})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// explainValue formats a flux value on one line.
+func explainValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
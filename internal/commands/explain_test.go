@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestLayerFlux_Trace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	source := "github.com/acme/tools/molds/launch"
+	persisted := filepath.Join(".ailloy", "flux", mold.FluxFileSlug(source)+".yaml")
+	for _, dir := range []string{"mold", filepath.Dir(persisted)} {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustWrite(t, filepath.Join("mold", "mold.yaml"), `name: launch
+flux:
+  - name: board
+    type: string
+    default: Engineering
+`)
+	mustWrite(t, filepath.Join("mold", "flux.yaml"), "target: claude\nproject:\n  name: launch\n  lead: ada\n")
+	mustWrite(t, persisted, "target: cursor\n")
+	mustWrite(t, "team.yaml", "target: opencode\nproject:\n  name: atlas\n")
+	mustWrite(t, "ci.yaml", "target: codex\n")
+
+	reader, err := blanks.NewMoldReaderFromPath("mold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace := fluxTrace{}
+	flux, _, err := layerFlux(reader, source, []string{"team.yaml", "ci.yaml"}, fluxSets{Set: []string{"board=Ops"}}, true, nil, trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layers := func(key string) string {
+		var out []string
+		for _, o := range trace[key] {
+			out = append(out, o.String())
+		}
+		return strings.Join(out, ", ")
+	}
+	want := map[string]string{
+		"target":       "mold flux.yaml, project flux file " + persisted + ", -f team.yaml, -f ci.yaml",
+		"board":        "schema default, --set",
+		"project.name": "mold flux.yaml, -f team.yaml",
+	}
+	for key, w := range want {
+		if got := layers(key); got != w {
+			t.Errorf("%s layers = %q, want %q", key, got, w)
+		}
+		got, _ := mold.GetNestedAny(flux, key)
+		if last := trace[key][len(trace[key])-1].Value; last != got {
+			t.Errorf("%s traced value %v, flux has %v", key, last, got)
+		}
+	}
+	// team.yaml replaced the whole project map, so project.lead is gone.
+	if _, ok := trace["project.lead"]; ok {
+		t.Error("trace kept project.lead after -f replaced project")
+	}
+
+	entries := explainEntries(trace, []string{"project", "missing"})
+	if len(entries) != 2 || entries[0].Key != "missing" || entries[0].Set || entries[1].Key != "project.name" || entries[1].Value != "atlas" {
+		t.Errorf("explainEntries = %+v", entries)
+	}
+	for _, e := range explainEntries(trace, nil) {
+		if strings.HasPrefix(e.Key, mold.ContextKey) {
+			t.Errorf("%s listed without being asked for", e.Key)
+		}
+	}
+}
+
+func TestLayerFlux_NilTrace(t *testing.T) {
+	var trace fluxTrace
+	trace.record(map[string]any{"a": "b"}, fluxOrigin{Layer: fluxLayerMold})
+	trace.recordSets(map[string]any{"a": "c"}, []string{"a=c"})
+	if trace != nil {
+		t.Error("nil trace recorded values")
+	}
+}
@@ -120,7 +120,7 @@ func runExtensionsList(cmd *cobra.Command, _ []string) error {
 
 	header := lipgloss.NewStyle().Bold(true).Foreground(styles.Primary1)
 	t := table.New().
-		Border(lipgloss.NormalBorder()).
+		Border(styles.TableBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(styles.Primary1)).
 		StyleFunc(func(row, _ int) lipgloss.Style {
 			if row == table.HeaderRow {
@@ -4,18 +4,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"dario.cat/mergo"
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/internal/tui/ceremony"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/merge"
 	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/scope"
 	"github.com/nimble-giant/ailloy/pkg/smelt"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
@@ -29,7 +31,8 @@ var forgeCmd = &cobra.Command{
 
 This is the "what would cast produce?" preview, analogous to helm template.
 If run from a stuffed binary (created by smelt -o binary), the embedded mold
-is used automatically when no mold-dir is provided.
+is used automatically when no mold-dir is provided; --mold picks one from a
+binary smelted with several.
 By default, rendered output is printed to stdout. Use --output to write files to a directory.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runForge,
@@ -41,6 +44,8 @@ var (
 	forgeValFiles                 []string
 	forgeForceReplaceOnParseError bool
 	forgeDebug                    bool
+	forgeProfile                  string
+	forgeMold                     string
 )
 
 func init() {
@@ -48,19 +53,22 @@ func init() {
 
 	forgeCmd.Flags().StringVarP(&forgeOutputDir, "output", "o", "", "write rendered files to this directory instead of stdout")
 	forgeCmd.Flags().StringArrayVar(&forgeSetValues, "set", nil, "set flux values (key=value)")
+	_ = forgeCmd.RegisterFlagCompletionFunc("set", completeSetFlag(0))
 	forgeCmd.Flags().StringArrayVarP(&forgeValFiles, "values", "f", nil, "flux value files (can be repeated, later files override earlier)")
+	forgeCmd.Flags().StringVar(&forgeProfile, "profile", "", "render with the mold's named output profile (e.g. cursor)")
 	forgeCmd.Flags().BoolVar(&forgeForceReplaceOnParseError,
 		"force-replace-on-parse-error",
 		false,
 		"if a destination uses strategy: merge but is unparseable, replace it instead of erroring (only used with --output)")
 	forgeCmd.Flags().BoolVar(&forgeDebug, "debug", false, "print resolved output mapping with source provenance (ore vs mold) before rendering")
+	forgeCmd.Flags().StringVar(&forgeMold, "mold", "", "render this mold from a stuffed binary smelted with several")
 }
 
 // loadForgeFlux loads layered flux values using Helm-style precedence:
-// ore defaults < mold flux.yaml < mold.yaml schema defaults < -f files
-// (left to right) < --set flags. The resolver may be nil — callers that
+// ore defaults < mold flux.yaml < mold.yaml schema defaults < valFiles
+// (left to right) < setValues. The resolver may be nil — callers that
 // don't resolve ore deps will get pre-Phase-9 behavior.
-func loadForgeFlux(reader *blanks.MoldReader, resolver *EphemeralOreResolver) (map[string]any, error) {
+func loadForgeFlux(reader *blanks.MoldReader, resolver *EphemeralOreResolver, valFiles, setValues []string) (map[string]any, error) {
 	// Layer 0: Ore-namespace defaults (resolved ephemerally). Lowest priority;
 	// the mold's own flux.yaml deep-merges on top via mergo.WithOverride.
 	flux := make(map[string]any)
@@ -75,6 +83,7 @@ func loadForgeFlux(reader *blanks.MoldReader, resolver *EphemeralOreResolver) (m
 	if err != nil {
 		fluxDefaults = make(map[string]any)
 	}
+	fluxDefaults = withSecretFlux(reader, fluxDefaults)
 
 	// Layer 2: Apply mold.yaml schema defaults
 	manifest, _ := reader.LoadManifest()
@@ -88,10 +97,10 @@ func loadForgeFlux(reader *blanks.MoldReader, resolver *EphemeralOreResolver) (m
 	mold.ApplyManifestOutputDefault(flux, manifest)
 
 	// Layer 3: Layer -f files left-to-right (each overrides previous)
-	if len(forgeValFiles) > 0 {
-		overlay, err := mold.LayerFluxFiles(forgeValFiles)
+	if len(valFiles) > 0 {
+		overlay, err := mold.LayerFluxFiles(valFiles)
 		if err != nil {
-			return nil, err
+			return nil, configError(err)
 		}
 		for k, v := range overlay {
 			flux[k] = v
@@ -99,8 +108,8 @@ func loadForgeFlux(reader *blanks.MoldReader, resolver *EphemeralOreResolver) (m
 	}
 
 	// Layer 4: Apply --set overrides (highest precedence)
-	if err := mold.ApplySetOverrides(flux, forgeSetValues); err != nil {
-		return nil, err
+	if err := mold.ApplySetOverrides(flux, setValues); err != nil {
+		return nil, usageError(err)
 	}
 
 	return flux, nil
@@ -110,39 +119,98 @@ func loadForgeFlux(reader *blanks.MoldReader, resolver *EphemeralOreResolver) (m
 func renderFile(name string, content []byte, flux map[string]any, opts ...mold.TemplateOption) (string, error) {
 	rendered, err := mold.ProcessTemplate(string(content), flux, opts...)
 	if err != nil {
-		return "", fmt.Errorf("template %s: %w", name, err)
+		return "", renderError(fmt.Errorf("template %s: %w", name, err))
 	}
 	return rendered, nil
 }
 
 type renderedFile struct {
 	destPath string // relative output path (e.g. ".claude/commands/brainstorm.md")
+	srcPath  string // source path within the mold (e.g. "commands/brainstorm.md")
 	content  string
 	strategy string
 }
 
 func runForge(_ *cobra.Command, args []string) error {
+	if err := chooseEmbeddedMold(forgeMold, args); err != nil {
+		return err
+	}
 	reader, remote, err := resolveForgeReader(args)
 	if err != nil {
 		return err
 	}
 
+	in := forgeInput{valFiles: forgeValFiles, setValues: forgeSetValues, profile: forgeProfile}
+	if forgeDebug {
+		in.debug = os.Stderr
+	}
+	manifest, files, err := forgeRender(reader, remote, in)
+	if err != nil {
+		return err
+	}
+
+	ceremony.Open(ceremony.Forge)
+
+	if forgeOutputDir != "" {
+		if err := writeForgeFiles(files, forgeOutputDir, forgeForceReplaceOnParseError, manifest.Name); err != nil {
+			return err
+		}
+		ceremony.Stamp(ceremony.Forge, fmt.Sprintf("%d file(s) → %s", len(files), forgeOutputDir))
+		return nil
+	}
+	// Stdout-rendered preview: skip the trailing stamp so pipe consumers
+	// (e.g. `ailloy forge | code -`) don't get an extra trailing line.
+	return printForgeFiles(files)
+}
+
+// forgeInput is the flux forgeRender layers over a mold's defaults, in
+// precedence order: value files, then values (like one more -f file), then
+// --set overrides, then the output profile.
+type forgeInput struct {
+	valFiles  []string
+	values    map[string]any
+	setValues []string
+	profile   string
+	// debug, when set, receives the resolved output provenance (forge --debug).
+	debug io.Writer
+	// logger receives validation and skipped-render warnings; nil means
+	// log.Default().
+	logger *log.Logger
+}
+
+// forgeRender renders every output of reader's mold the way forge does, with
+// ore deps resolved ephemerally — nothing under .ailloy/ is touched.
+// Local-path deps are refused when the mold itself was loaded from a remote
+// source (mirrors installDeclaredDeps' rule).
+func forgeRender(reader *blanks.MoldReader, remote bool, in forgeInput) (*mold.Mold, []renderedFile, error) {
+	logger := in.logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
 	manifest, err := reader.LoadManifest()
 	if err != nil {
-		return fmt.Errorf("failed to load mold manifest: %w", err)
+		return nil, nil, fmt.Errorf("failed to load mold manifest: %w", err)
 	}
+	warnUnknownManifestFields(reader, logger)
 
-	// Resolve ore deps ephemerally for the preview render — never touches
-	// .ailloy/ores/. Local-path deps are refused when the parent mold itself
-	// was loaded from a remote source (mirrors installDeclaredDeps' rule).
 	oreResolver, err := ResolveDepsEphemeral(manifest, !remote)
 	if err != nil {
-		return fmt.Errorf("resolving ore deps for forge: %w", err)
+		return nil, nil, fmt.Errorf("resolving ore deps for forge: %w", err)
 	}
 
-	flux, err := loadForgeFlux(reader, oreResolver)
+	flux, err := loadForgeFlux(reader, oreResolver, in.valFiles, nil)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	for k, v := range in.values {
+		flux[k] = v
+	}
+	if err := mold.ApplySetOverrides(flux, in.setValues); err != nil {
+		return nil, nil, err
+	}
+	if _, err := selectOutputProfile(flux, manifest, in.profile); err != nil {
+		return nil, nil, err
 	}
 
 	// Validate: prefer flux.schema.yaml, fall back to mold.yaml flux: section.
@@ -152,16 +220,19 @@ func runForge(_ *cobra.Command, args []string) error {
 	}
 	mergedSchema, _, _, mergeErr := oreResolver.MergeInto(schema, nil)
 	if mergeErr != nil {
-		return fmt.Errorf("merging ore schema overlays: %w", mergeErr)
+		return nil, nil, fmt.Errorf("merging ore schema overlays: %w", mergeErr)
+	}
+	for _, w := range append(mold.MigrateFlux(mergedSchema, flux), mold.ResolveFlux(mergedSchema, flux, time.Now())...) {
+		logger.Printf("warning: %s", w)
 	}
-	if err := mold.ValidateFlux(mergedSchema, flux); err != nil {
-		log.Printf("warning: %v", err)
+	if err := mold.ValidateFluxWithSources(mergedSchema, flux, valuesFluxSources(in.valFiles, nil)); err != nil {
+		logger.Printf("warning: %v", err)
 	}
 
 	// Build ingot resolver
 	ingotResolver := buildIngotResolver(flux, reader.Root())
 	ingotResolver.FS = reader.FS()
-	opts := []mold.TemplateOption{mold.WithIngotResolver(ingotResolver)}
+	applyIngotConstraints(ingotResolver, manifest)
 
 	// Load ignore patterns from .ailloyignore and mold.yaml.
 	ignorePatterns := mold.LoadIgnorePatterns(reader.FS(), manifest)
@@ -174,64 +245,50 @@ func runForge(_ *cobra.Command, args []string) error {
 	// Resolve all output files from the flux.
 	resolved, err := mold.ResolveFilesWithOreSources(flux["output"], reader.FS(), oreResolver.OreSources(), resolveOpts...)
 	if err != nil {
-		return fmt.Errorf("resolving output files: %w", err)
+		return nil, nil, fmt.Errorf("resolving output files: %w", err)
+	}
+	if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+		return nil, nil, err
 	}
 
-	if forgeDebug {
-		printForgeDebugProvenance(os.Stderr, resolved)
+	if in.debug != nil {
+		printForgeDebugProvenance(in.debug, resolved)
 	}
 
-	var files []renderedFile
-	for _, rf := range resolved {
-		content, err := fs.ReadFile(chooseFS(rf, reader.FS()), rf.SrcPath)
-		if err != nil {
-			return fmt.Errorf("reading %s: %w", rf.SrcPath, err)
-		}
+	if err := attachRemoteIngots(ingotResolver, reader.FS(), resolved); err != nil {
+		return nil, nil, err
+	}
 
-		var rendered string
-		if rf.Process {
-			fluxForFile := flux
-			if len(rf.Set) > 0 {
-				fluxForFile = mold.MergeSet(flux, rf.Set)
-			}
-			rendered, err = renderFile(rf.SrcPath, content, fluxForFile, opts...)
-			if err != nil {
-				return err
-			}
-		} else {
-			rendered = string(content)
-		}
+	contents, err := renderBlanks(reader.FS(), resolved, flux, ingotResolver, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []renderedFile
+	for i, rf := range resolved {
+		rendered := string(contents[i])
 
 		// Skip files that render to empty or whitespace-only content (#130)
 		if rf.Process && strings.TrimSpace(rendered) == "" {
-			log.Printf("skipping %s: rendered to empty content", rf.SrcPath)
+			logger.Printf("skipping %s: rendered to empty content", rf.SrcPath)
 			continue
 		}
 
 		files = append(files, renderedFile{
 			destPath: rf.DestPath,
+			srcPath:  rf.SrcPath,
 			content:  rendered,
 			strategy: rf.Strategy,
 		})
 	}
-
-	ceremony.Open(ceremony.Forge)
-
-	if forgeOutputDir != "" {
-		if err := writeForgeFiles(files, forgeOutputDir, forgeForceReplaceOnParseError, manifest.Name); err != nil {
-			return err
-		}
-		ceremony.Stamp(ceremony.Forge, fmt.Sprintf("%d file(s) → %s", len(files), forgeOutputDir))
-		return nil
-	}
-	// Stdout-rendered preview: skip the trailing stamp so pipe consumers
-	// (e.g. `ailloy forge | code -`) don't get an extra trailing line.
-	return printForgeFiles(files)
+	return manifest, files, nil
 }
 
 // buildIngotResolver creates an IngotResolver with the standard search path order:
 // mold source root (so bundled ingots are found when casting from a remote or
-// path mold), current directory (mold-local), project .ailloy/, global ~/.ailloy/.
+// path mold), current directory (mold-local), project .ailloy/, the .ailloy/
+// of each workspace ancestor (nearest first), global ~/.ailloy/, then the
+// system scope root (organization-provisioned ingots).
 // moldRoot may be empty when the mold has no on-disk root (e.g., embedded molds).
 func buildIngotResolver(flux map[string]any, moldRoot string) *mold.IngotResolver {
 	var searchPaths []string
@@ -244,6 +301,7 @@ func buildIngotResolver(flux map[string]any, moldRoot string) *mold.IngotResolve
 	if _, err := os.Stat(".ailloy"); err == nil {
 		searchPaths = append(searchPaths, ".ailloy")
 	}
+	searchPaths = append(searchPaths, scope.WorkspaceProjectRoots()...)
 
 	if homeDir, err := os.UserHomeDir(); err == nil {
 		globalDir := filepath.Join(homeDir, ".ailloy")
@@ -252,9 +310,79 @@ func buildIngotResolver(flux map[string]any, moldRoot string) *mold.IngotResolve
 		}
 	}
 
+	if systemDir := scope.SystemRoot(); systemDir != "" {
+		if _, err := os.Stat(systemDir); err == nil {
+			searchPaths = append(searchPaths, systemDir)
+		}
+	}
+
 	return mold.NewIngotResolver(searchPaths, flux)
 }
 
+// applyIngotConstraints records the version constraints of manifest's ingot
+// dependencies on r, keyed by the ingot names those deps provide. Names come
+// from the installed manifests (project, then global) when the dep has been
+// installed — which covers multi-ingot repos whose package names differ
+// from the ref — falling back to the last segment of the ref's subpath or
+// repo path.
+func applyIngotConstraints(r *mold.IngotResolver, manifest *mold.Mold) {
+	if manifest == nil {
+		return
+	}
+	var installed []*foundry.InstalledManifest
+	for _, p := range []string{projectManifestPath(), globalManifestPath()} {
+		if im, err := foundry.ReadInstalledManifest(p); err == nil && im != nil {
+			installed = append(installed, im)
+		}
+	}
+
+	for _, d := range manifest.Dependencies {
+		if d.Ingot == "" || d.Version == "" {
+			continue
+		}
+		for _, name := range ingotDepNames(d.Ingot, installed) {
+			if r.Constraints == nil {
+				r.Constraints = make(map[string]string)
+			}
+			r.Constraints[name] = d.Version
+		}
+	}
+}
+
+// ingotDepNames returns the ingot names an ingot dependency ref provides.
+func ingotDepNames(ref string, installed []*foundry.InstalledManifest) []string {
+	source, subpath := depIdentity(ref)
+	var names []string
+	for _, im := range installed {
+		for _, e := range im.Ingots {
+			if e.Source != source {
+				continue
+			}
+			if subpath != "" && e.Subpath != subpath {
+				continue
+			}
+			if e.Name != "" && !containsString(names, e.Name) {
+				names = append(names, e.Name)
+			}
+		}
+	}
+	if len(names) > 0 {
+		return names
+	}
+	base := subpath
+	if base == "" {
+		base = source
+	}
+	base = strings.TrimSuffix(filepath.ToSlash(base), "/")
+	if i := strings.LastIndex(base, "/"); i != -1 {
+		base = base[i+1:]
+	}
+	if base == "" {
+		return nil
+	}
+	return []string{base}
+}
+
 // printForgeDebugProvenance prints the resolved file list to w, annotating each
 // row with its origin (mold or ore:<namespace>) so authors can see where each
 // rendered file came from. Triggered by `forge --debug`.
@@ -303,7 +431,7 @@ func writeForgeFiles(files []renderedFile, outputDir string, forceReplaceOnParse
 				}
 				return fmt.Errorf("failed to merge %s: %w", dest, err)
 			}
-			fmt.Println(styles.SuccessStyle.Render("Merged ") + styles.CodeStyle.Render(dest))
+			logging.Say(styles.SuccessStyle.Render("Merged ")+styles.CodeStyle.Render(dest), "merged", "path", dest)
 		case "append":
 			if moldName == "" {
 				return fmt.Errorf("append strategy requires a mold name (dest %s)", dest)
@@ -314,7 +442,7 @@ func writeForgeFiles(files []renderedFile, outputDir string, forceReplaceOnParse
 			if err != nil {
 				return fmt.Errorf("failed to append into %s: %w", dest, err)
 			}
-			fmt.Println(styles.SuccessStyle.Render("Appended ") + styles.CodeStyle.Render(dest))
+			logging.Say(styles.SuccessStyle.Render("Appended ")+styles.CodeStyle.Render(dest), "appended", "path", dest)
 		case "", "replace":
 			if err := os.MkdirAll(filepath.Dir(dest), 0750); err != nil { // #nosec G301 -- Output directories need group read access
 				return fmt.Errorf("creating directory for %s: %w", f.destPath, err)
@@ -323,7 +451,7 @@ func writeForgeFiles(files []renderedFile, outputDir string, forceReplaceOnParse
 			if err := os.WriteFile(dest, []byte(f.content), 0644); err != nil {
 				return fmt.Errorf("writing %s: %w", f.destPath, err)
 			}
-			fmt.Println(styles.SuccessStyle.Render("Wrote ") + styles.CodeStyle.Render(dest))
+			logging.Say(styles.SuccessStyle.Render("Wrote ")+styles.CodeStyle.Render(dest), "wrote", "path", dest)
 		default:
 			return fmt.Errorf("unknown strategy %q on output for %s", f.strategy, dest)
 		}
@@ -337,14 +465,19 @@ func writeForgeFiles(files []renderedFile, outputDir string, forceReplaceOnParse
 // remotely-resolved mold.
 func resolveForgeReader(args []string) (*blanks.MoldReader, bool, error) {
 	if len(args) >= 1 {
-		if foundry.IsRemoteReference(args[0]) {
-			fsys, root, err := foundry.ResolveWithRoot(args[0])
+		if foundry.IsRemoteReference(args[0]) && !blanks.IsTarball(args[0]) {
+			fsys, result, err := foundry.ResolveWithMetadata(args[0])
 			if err != nil {
-				return nil, true, fmt.Errorf("resolving remote mold: %w", err)
+				return nil, true, resolutionError(fmt.Errorf("resolving remote mold: %w", err))
 			}
-			return blanks.NewMoldReaderFromFS(fsys, root), true, nil
+			reader, err := ComposeMoldReader(blanks.NewMoldReaderFromFS(fsys, result.Root), result.Ref.OverrideKey())
+			return reader, true, err
 		}
-		reader, err := blanks.NewMoldReaderFromPath(args[0])
+		reader, err := openLocalMold(args[0])
+		if err != nil {
+			return nil, false, err
+		}
+		reader, err = ComposeMoldReader(reader, "")
 		return reader, false, err
 	}
 	if smelt.HasEmbeddedMold() {
@@ -352,7 +485,8 @@ func resolveForgeReader(args []string) (*blanks.MoldReader, bool, error) {
 		if err != nil {
 			return nil, false, fmt.Errorf("opening embedded mold: %w", err)
 		}
-		return blanks.NewMoldReader(fsys), false, nil
+		reader, err := ComposeMoldReader(blanks.NewMoldReader(fsys), "")
+		return reader, false, err
 	}
 	return nil, false, fmt.Errorf("mold directory is required: ailloy forge <mold-dir>")
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
@@ -79,6 +80,31 @@ func TestBuildIngotResolver_NoMoldRootFallsBackToCWD(t *testing.T) {
 	}
 }
 
+func TestIngotDepNames(t *testing.T) {
+	installed := []*foundry.InstalledManifest{{
+		Ingots: []foundry.ArtifactEntry{
+			{Name: "pr-helpers", Source: "github.com/org/ingots", Subpath: "ingots/pr-helpers"},
+			{Name: "issue-helpers", Source: "github.com/org/ingots", Subpath: "ingots/issue-helpers"},
+		},
+	}}
+
+	tests := []struct {
+		ref  string
+		want []string
+	}{
+		{"github.com/org/ingots", []string{"pr-helpers", "issue-helpers"}},
+		{"github.com/org/ingots//ingots/pr-helpers", []string{"pr-helpers"}},
+		{"github.com/org/other//ingots/footer", []string{"footer"}},
+		{"github.com/org/standalone", []string{"standalone"}},
+	}
+	for _, tt := range tests {
+		got := ingotDepNames(tt.ref, installed)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("ingotDepNames(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
 // TestCastFromPath_ResolvesBundledIngot is a higher-level regression test for
 // issue #140: casting a path-based mold from a different CWD must find the
 // mold's bundled ingots, not silently fail with "ingot not found".
@@ -154,8 +180,12 @@ version: 0.1.0
 	}
 }
 
+// mustWrite writes content to path, creating its parent directories.
 func mustWrite(t *testing.T, path, content string) {
 	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("creating %s: %v", filepath.Dir(path), err)
+	}
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		t.Fatalf("writing %s: %v", path, err)
 	}
@@ -31,7 +31,7 @@ func init() {
 }
 
 func runFoundries(_ *cobra.Command, _ []string) error {
-	if !term.IsTerminal(int(os.Stdout.Fd())) {
+	if nonInteractive() || !term.IsTerminal(int(os.Stdout.Fd())) {
 		return fmt.Errorf("ailloy foundries requires a TTY; use ailloy foundry list/search/etc. for scripts")
 	}
 	deps := foundries.Deps{
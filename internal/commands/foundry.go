@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/scope"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
 )
@@ -50,7 +52,9 @@ same way ailloy cast resolves shorthand sources. Explicit https://,
 http://, and git@ schemes are kept as-is.
 
 The index is fetched, validated, and cached locally. The registration
-is saved to ~/.ailloy/config.yaml.`,
+is saved to ~/.ailloy/config.yaml, or with --system to the system scope's
+config.yaml so every user on the machine sees the foundry (requires write
+access to the system root; see 'ailloy doctor').`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFoundryAdd,
 }
@@ -67,7 +71,8 @@ var foundryRemoveCmd = &cobra.Command{
 	Short: "Remove a registered foundry index",
 	Long: `Remove a registered foundry index by name or URL.
 
-This removes the registration from ~/.ailloy/config.yaml and cleans up cached data.`,
+This removes the registration from ~/.ailloy/config.yaml (or the system
+scope's config.yaml with --system) and cleans up cached data.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFoundryRemove,
 }
@@ -88,6 +93,7 @@ var (
 	foundryCastShallow       bool
 	foundryCastValueFiles    []string
 	foundryCastSetOverrides  []string
+	foundrySystem            bool
 )
 
 var foundryCastCmd = &cobra.Command{
@@ -113,11 +119,14 @@ func init() {
 	foundryCmd.AddCommand(foundryUpdateCmd)
 	foundryCmd.AddCommand(foundryCastCmd)
 
+	foundryAddCmd.Flags().BoolVar(&foundrySystem, "system", false, "register in the system scope for every user on this machine")
+	foundryRemoveCmd.Flags().BoolVar(&foundrySystem, "system", false, "remove from the system scope")
+
 	foundrySearchCmd.Flags().BoolVar(&searchIndexOnly, "index-only", false, "only search registered foundry indexes")
 	foundrySearchCmd.Flags().BoolVar(&searchGitHubOnly, "github-only", false, "only search GitHub Topics")
 
 	foundryCastCmd.Flags().BoolVarP(&foundryCastGlobal, "global", "g", false, "install each mold under ~/ instead of the current project")
-	foundryCastCmd.Flags().BoolVar(&foundryCastWithWorkflows, "with-workflows", false, "include GitHub Actions workflow blanks")
+	foundryCastCmd.Flags().BoolVar(&foundryCastWithWorkflows, "with-workflows", false, "include workflow blanks for the repository's CI system")
 	foundryCastCmd.Flags().BoolVar(&foundryCastDryRun, "dry-run", false, "list what would be installed without casting")
 	foundryCastCmd.Flags().BoolVar(&foundryCastForce, "force", false, "re-cast molds that are already installed")
 	foundryCastCmd.Flags().BoolVar(&foundryCastClaudePlugin, "claude-plugin", false, "package each mold as a Claude Code plugin under .claude/plugins/<slug>/")
@@ -136,7 +145,7 @@ func runFoundrySearch(_ *cobra.Command, args []string) error {
 
 	cfg, err := index.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return configError(fmt.Errorf("loading config: %w", err))
 	}
 
 	opts := index.SearchOptions{
@@ -188,15 +197,19 @@ func runFoundrySearch(_ *cobra.Command, args []string) error {
 func runFoundryAdd(_ *cobra.Command, args []string) error {
 	url := index.NormalizeFoundryURL(args[0])
 
-	cfg, err := index.LoadConfig()
+	cfg, configPath, err := loadFoundryConfigForWrite(foundrySystem)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return err
 	}
 
 	if existing := cfg.FindFoundry(url); existing != nil {
 		fmt.Println(styles.InfoStyle.Render("Foundry already registered: ") + styles.CodeStyle.Render(url))
 		return nil
 	}
+	if cfg.IsSystemFoundry(url) {
+		fmt.Println(styles.InfoStyle.Render("Foundry already provisioned system-wide: ") + styles.CodeStyle.Render(url))
+		return nil
+	}
 
 	fmt.Println(styles.WorkingBanner("Fetching foundry index..."))
 
@@ -209,12 +222,10 @@ func runFoundryAdd(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if err := index.SaveConfig(cfg); err != nil {
+	if err := index.SaveConfigTo(cfg, configPath); err != nil {
 		return err
 	}
 
-	configPath, _ := index.ConfigPath()
-
 	fmt.Println()
 	fmt.Println(styles.SuccessStyle.Render("Foundry registered: ") + styles.AccentStyle.Render(res.Entry.Name))
 	fmt.Println(styles.SubtleStyle.Render(fmt.Sprintf("  URL:   %s", url)))
@@ -225,10 +236,39 @@ func runFoundryAdd(_ *cobra.Command, args []string) error {
 	return nil
 }
 
+// loadFoundryConfigForWrite returns the config that foundry add/remove
+// should edit and the path to save it to: the user's config, or the system
+// scope's when system is set. System writes fail early with an actionable
+// error when the current user can't write to the system root.
+func loadFoundryConfigForWrite(system bool) (*index.Config, string, error) {
+	if !system {
+		cfg, err := index.LoadConfig()
+		if err != nil {
+			return nil, "", configError(fmt.Errorf("loading config: %w", err))
+		}
+		configPath, err := index.ConfigPath()
+		if err != nil {
+			return nil, "", err
+		}
+		return cfg, configPath, nil
+	}
+
+	root, err := scope.RequireWritableSystem()
+	if err != nil {
+		return nil, "", err
+	}
+	configPath := filepath.Join(root, "config.yaml")
+	cfg, err := index.LoadConfigFrom(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("loading system config: %w", err)
+	}
+	return cfg, configPath, nil
+}
+
 func runFoundryList(_ *cobra.Command, _ []string) error {
 	cfg, err := index.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return configError(fmt.Errorf("loading config: %w", err))
 	}
 
 	foundries := cfg.EffectiveFoundries()
@@ -248,6 +288,9 @@ func runFoundryList(_ *cobra.Command, _ []string) error {
 		if index.IsOfficialFoundry(entry.URL) {
 			name += " " + styles.SuccessStyle.Render("✓ verified")
 		}
+		if cfg.IsSystemFoundry(entry.URL) {
+			name += " " + styles.SubtleStyle.Render("(system)")
+		}
 		status := formatStatus(entry.Status)
 		lastUpdated := "never"
 		if !entry.LastUpdated.IsZero() {
@@ -290,9 +333,9 @@ func printFoundryTree(node *index.ResolvedFoundry, indent string) {
 func runFoundryRemove(_ *cobra.Command, args []string) error {
 	nameOrURL := args[0]
 
-	cfg, err := index.LoadConfig()
+	cfg, configPath, err := loadFoundryConfigForWrite(foundrySystem)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return err
 	}
 
 	removed, err := RemoveFoundryCore(cfg, nameOrURL)
@@ -304,7 +347,7 @@ func runFoundryRemove(_ *cobra.Command, args []string) error {
 		_ = index.CleanIndexCache(cacheDir, &removed)
 	}
 
-	if err := index.SaveConfig(cfg); err != nil {
+	if err := index.SaveConfigTo(cfg, configPath); err != nil {
 		return err
 	}
 
@@ -316,7 +359,7 @@ func runFoundryRemove(_ *cobra.Command, args []string) error {
 func runFoundryUpdate(_ *cobra.Command, _ []string) error {
 	cfg, err := index.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return configError(fmt.Errorf("loading config: %w", err))
 	}
 
 	fmt.Println(styles.WorkingBanner("Updating foundry indexes..."))
@@ -375,12 +418,10 @@ func formatStatus(status string) string {
 	}
 }
 
-// defaultGitRunner returns a GitRunner that shells out to git.
+// defaultGitRunner returns a GitRunner that shells out to git, honoring
+// configured foundry mirrors.
 func defaultGitRunner() index.GitRunner {
-	return func(args ...string) ([]byte, error) {
-		cmd := exec.Command("git", args...) // #nosec G204 -- args are constructed internally
-		return cmd.CombinedOutput()
-	}
+	return index.GitRunner(foundry.DefaultGitRunner())
 }
 
 // nameFromFoundryURL derives a short name from a foundry URL.
@@ -405,7 +446,7 @@ func runFoundryCast(_ *cobra.Command, args []string) error {
 
 	cfg, err := index.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return configError(fmt.Errorf("loading config: %w", err))
 	}
 
 	fmt.Println(styles.WorkingBanner("Casting every mold from " + nameOrURL + "..."))
@@ -159,6 +159,9 @@ var ErrCannotRemoveDefault = errors.New("cannot remove the default verified foun
 func RemoveFoundryCore(cfg *index.Config, nameOrURL string) (index.FoundryEntry, error) {
 	entry := cfg.FindFoundry(nameOrURL)
 	if entry == nil {
+		if cfg.IsSystemFoundry(nameOrURL) {
+			return index.FoundryEntry{}, fmt.Errorf("foundry %q is provisioned in the system scope (%s); remove it there with 'ailloy foundry remove --system'", nameOrURL, index.SystemConfigPath())
+		}
 		official := index.OfficialFoundryEntry()
 		if strings.EqualFold(nameOrURL, official.Name) || index.IsOfficialFoundry(nameOrURL) {
 			return index.FoundryEntry{}, ErrCannotRemoveDefault
@@ -197,10 +200,17 @@ func UpdateFoundriesCore(cfg *index.Config) ([]UpdateFoundryReport, error) {
 	for i := range cfg.Foundries {
 		targets = append(targets, target{entry: &cfg.Foundries[i], persisted: true})
 	}
-	if !cfg.HasOfficialFoundry() {
+	if !cfg.HasOfficialFoundry() && !cfg.IsSystemFoundry(index.OfficialFoundryURL) {
 		official := index.OfficialFoundryEntry()
 		targets = append([]target{{entry: &official, persisted: false}}, targets...)
 	}
+	// System-scope foundries are refreshed into the user's cache but their
+	// metadata is never written back to the (usually read-only) system config.
+	for i := range cfg.System {
+		if cfg.FindFoundry(cfg.System[i].URL) == nil {
+			targets = append(targets, target{entry: &cfg.System[i], persisted: false})
+		}
+	}
 
 	// Network-only lookup. Fetcher writes each fetched index to disk on
 	// success, so resolving the tree also populates the on-disk cache that
@@ -237,7 +247,7 @@ func UpdateFoundriesCore(cfg *index.Config) ([]UpdateFoundryReport, error) {
 // InstallFoundryOptions controls a bulk install across every mold in a foundry.
 type InstallFoundryOptions struct {
 	Global        bool // pass --global to each cast
-	WithWorkflows bool // include .github/ workflow blanks
+	WithWorkflows bool // include workflow blanks for the repository's CI system
 	DryRun        bool // report what would be installed; don't touch disk
 	Force         bool // re-cast even if already installed in the target lockfile
 	ClaudePlugin  bool // package each mold as a Claude Code plugin
@@ -318,3 +318,15 @@ func TestFoundryCastCommandShape(t *testing.T) {
 		t.Fatalf("foundryCastCmd.Aliases = %v, want %v", gotAliases, wantAliases)
 	}
 }
+
+// TestRemoveFoundryCoreRejectsSystemFoundry verifies a system-provisioned
+// foundry can't be removed from the user's config and points at --system.
+func TestRemoveFoundryCoreRejectsSystemFoundry(t *testing.T) {
+	cfg := &index.Config{
+		System: []index.FoundryEntry{{Name: "org", URL: "https://github.com/org/foundry"}},
+	}
+	_, err := RemoveFoundryCore(cfg, "org")
+	if err == nil || !strings.Contains(err.Error(), "--system") {
+		t.Fatalf("expected a --system hint, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var githooksCmd = &cobra.Command{
+	Use:   "githooks",
+	Short: "Manage ailloy's git pre-commit hook",
+	Long: `Manage ailloy's git pre-commit hook.
+
+Available subcommands:
+  install    Run ailloy's checks before every commit
+  uninstall  Remove the hook`,
+}
+
+var githooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Run ailloy's checks before every commit",
+	Long: `Install a git pre-commit hook that runs ailloy's checks.
+
+In a mold repository (mold.yaml at the repository root) the hook runs
+'ailloy temper --assay' and, when the mold has a tests/ directory,
+'ailloy mold test'. In an ingot or ore repository it runs 'ailloy temper'.
+--drift adds 'ailloy status --offline --check', which fails the commit when
+blanks cast into the repository were edited or deleted, or no longer match
+their cached mold source; on its own it sets up a consumer repository that
+casts molds rather than authoring one.
+
+The checks live in a managed script, ailloy-pre-commit, in the hooks
+directory (core.hooksPath is honored). Reinstalling rewrites that script
+only. The pre-commit hook calls it from a marked block: a pre-commit hook
+you already have keeps its contents, and the block is added at its end.
+Skip the checks for one commit with 'git commit --no-verify'.`,
+	Args: cobra.NoArgs,
+	RunE: runGithooksInstall,
+}
+
+var githooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove ailloy's pre-commit hook",
+	Long: `Remove the managed ailloy-pre-commit script and the block that calls it
+from the pre-commit hook. The rest of the hook is left alone; a hook that
+only held ailloy's block is deleted.`,
+	Args: cobra.NoArgs,
+	RunE: runGithooksUninstall,
+}
+
+var githooksDrift bool
+
+func init() {
+	rootCmd.AddCommand(githooksCmd)
+	githooksCmd.AddCommand(githooksInstallCmd)
+	githooksCmd.AddCommand(githooksUninstallCmd)
+
+	githooksInstallCmd.Flags().BoolVar(&githooksDrift, "drift", false, "also fail commits when cast blanks drift from their mold source")
+}
+
+// Names of the hook files githooks manages, and the markers around the
+// block it adds to pre-commit.
+const (
+	preCommitHook     = "pre-commit"
+	managedHookScript = "ailloy-pre-commit"
+	hookBlockBegin    = "# >>> ailloy >>>"
+	hookBlockEnd      = "# <<< ailloy <<<"
+)
+
+// hookBlock is what githooks adds to pre-commit: a call to the managed
+// script, which sits next to the hook.
+const hookBlock = hookBlockBegin + `
+# Added by 'ailloy githooks install'; remove with 'ailloy githooks uninstall'.
+"$(dirname "$0")/` + managedHookScript + `" || exit $?
+` + hookBlockEnd + "\n"
+
+func runGithooksInstall(_ *cobra.Command, _ []string) error {
+	hooksDir, err := gitHooksDir(".")
+	if err != nil {
+		return err
+	}
+	script, err := preCommitScript(".", githooksDrift)
+	if err != nil {
+		return err
+	}
+	if err := installPreCommitHook(hooksDir, script); err != nil {
+		return err
+	}
+	fmt.Println(styles.SuccessStyle.Render("✓ ") + "Installed pre-commit hook " +
+		styles.CodeStyle.Render(filepath.Join(hooksDir, managedHookScript)))
+	return nil
+}
+
+func runGithooksUninstall(_ *cobra.Command, _ []string) error {
+	hooksDir, err := gitHooksDir(".")
+	if err != nil {
+		return err
+	}
+	removed, err := uninstallPreCommitHook(hooksDir)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		fmt.Println("No ailloy pre-commit hook installed.")
+		return nil
+	}
+	fmt.Println(styles.SuccessStyle.Render("✓ ") + "Removed the ailloy pre-commit hook")
+	return nil
+}
+
+// gitHooksDir returns the hooks directory of the repository containing
+// dir, following core.hooksPath and worktrees.
+func gitHooksDir(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks").Output() // #nosec G204 -- fixed git subcommand
+	if err != nil {
+		return "", fmt.Errorf("%s is not in a git repository", dir)
+	}
+	hooks := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
+
+// preCommitScript returns the managed script for the repository at root:
+// package checks for a mold, ingot or ore repository, plus the drift check
+// when drift is set.
+func preCommitScript(root string, drift bool) (string, error) {
+	kind := ""
+	for _, k := range []string{"mold", "ingot", "ore"} {
+		if _, err := os.Stat(filepath.Join(root, k+".yaml")); err == nil {
+			kind = k
+			break
+		}
+	}
+	if kind == "" && !drift {
+		return "", errors.New("no mold.yaml, ingot.yaml or ore.yaml at the repository root; use --drift in a repository that casts molds")
+	}
+
+	var b strings.Builder
+	b.WriteString(`#!/bin/sh
+# Managed by 'ailloy githooks install': reinstalling overwrites this file.
+# Add your own checks to the pre-commit hook instead.
+set -e
+
+if ! command -v ailloy >/dev/null 2>&1; then
+  echo "ailloy pre-commit: ailloy not found on PATH; skipping checks" >&2
+  exit 0
+fi
+`)
+	switch kind {
+	case "mold":
+		b.WriteString(`
+ailloy temper --assay .
+if [ -d tests ]; then
+  ailloy mold test .
+fi
+`)
+	case "ingot", "ore":
+		b.WriteString("\nailloy temper .\n")
+	}
+	if drift {
+		b.WriteString(`
+if [ -f .ailloy/installed.yaml ]; then
+  ailloy status --offline --check
+fi
+`)
+	}
+	return b.String(), nil
+}
+
+// installPreCommitHook writes the managed script to hooksDir and makes
+// sure pre-commit calls it, creating pre-commit when missing.
+func installPreCommitHook(hooksDir, script string) error {
+	if err := os.MkdirAll(hooksDir, 0o750); err != nil {
+		return fmt.Errorf("creating hooks directory: %w", err)
+	}
+	hookPath := filepath.Join(hooksDir, preCommitHook)
+	existing, err := os.ReadFile(hookPath) // #nosec G304 -- hook in the repository's hooks dir
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", hookPath, err)
+	}
+	hook := string(existing)
+	if !strings.Contains(hook, hookBlockBegin) {
+		switch {
+		case hook == "":
+			hook = "#!/bin/sh\n\n" + hookBlock
+		case !isShellHook(hook):
+			return fmt.Errorf("%s is not a shell script; call %s from it yourself",
+				hookPath, filepath.Join(hooksDir, managedHookScript))
+		default:
+			if !strings.HasSuffix(hook, "\n") {
+				hook += "\n"
+			}
+			hook += "\n" + hookBlock
+		}
+	}
+
+	// #nosec G306 -- hooks must be executable
+	if err := os.WriteFile(filepath.Join(hooksDir, managedHookScript), []byte(script), 0o755); err != nil {
+		return fmt.Errorf("writing %s: %w", managedHookScript, err)
+	}
+	// #nosec G306 -- hooks must be executable
+	if err := os.WriteFile(hookPath, []byte(hook), 0o755); err != nil {
+		return fmt.Errorf("writing %s: %w", hookPath, err)
+	}
+	return nil
+}
+
+// uninstallPreCommitHook removes the managed script and ailloy's block
+// from pre-commit, deleting pre-commit when nothing but its shebang is
+// left. The bool reports whether anything was installed.
+func uninstallPreCommitHook(hooksDir string) (bool, error) {
+	removed := false
+	scriptPath := filepath.Join(hooksDir, managedHookScript)
+	if err := os.Remove(scriptPath); err == nil {
+		removed = true
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	hookPath := filepath.Join(hooksDir, preCommitHook)
+	data, err := os.ReadFile(hookPath) // #nosec G304 -- hook in the repository's hooks dir
+	if os.IsNotExist(err) {
+		return removed, nil
+	} else if err != nil {
+		return false, err
+	}
+	hook := string(data)
+	begin := strings.Index(hook, hookBlockBegin)
+	end := strings.Index(hook, hookBlockEnd)
+	if begin < 0 || end < begin {
+		return removed, nil
+	}
+	end += len(hookBlockEnd)
+	if end < len(hook) && hook[end] == '\n' {
+		end++
+	}
+	hook = strings.TrimRight(hook[:begin], "\n") + "\n" + hook[end:]
+
+	if rest := strings.TrimSpace(hook); rest == "" || (strings.HasPrefix(rest, "#!") && !strings.Contains(rest, "\n")) {
+		return true, os.Remove(hookPath)
+	}
+	// #nosec G306 -- hooks must be executable
+	return true, os.WriteFile(hookPath, []byte(hook), 0o755)
+}
+
+// isShellHook reports whether hook is a POSIX-style shell script that
+// ailloy's block can be appended to: no shebang, or one naming sh, bash,
+// dash, ksh or zsh.
+func isShellHook(hook string) bool {
+	first, _, _ := strings.Cut(hook, "\n")
+	if !strings.HasPrefix(first, "#!") {
+		return true
+	}
+	fields := strings.Fields(strings.TrimPrefix(first, "#!"))
+	if len(fields) == 0 {
+		return true
+	}
+	interp := filepath.Base(fields[0])
+	if interp == "env" && len(fields) > 1 {
+		interp = fields[1]
+	}
+	switch interp {
+	case "sh", "bash", "dash", "ksh", "zsh":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreCommitScript(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := preCommitScript(dir, false); err == nil {
+		t.Error("expected an error without a package manifest or --drift")
+	}
+	drift, err := preCommitScript(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(drift, "ailloy status --offline --check") || strings.Contains(drift, "temper") {
+		t.Errorf("consumer script:\n%s", drift)
+	}
+
+	mustWrite(t, filepath.Join(dir, "mold.yaml"), "name: tools\n")
+	script, err := preCommitScript(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"#!/bin/sh", "ailloy temper --assay .", "ailloy mold test ."} {
+		if !strings.Contains(script, want) {
+			t.Errorf("mold script lacks %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "status") {
+		t.Errorf("mold script runs the drift check without --drift:\n%s", script)
+	}
+}
+
+func TestInstallPreCommitHook(t *testing.T) {
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	hooksDir, err := gitHooksDir(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hookPath := filepath.Join(hooksDir, preCommitHook)
+	userHook := "#!/usr/bin/env bash\nmake lint\n"
+	mustWrite(t, hookPath, userHook)
+
+	// Installing twice keeps one block and the user's own checks.
+	for range 2 {
+		if err := installPreCommitHook(hooksDir, "#!/bin/sh\necho checks\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hook, _ := os.ReadFile(hookPath)
+	if !strings.HasPrefix(string(hook), userHook) || strings.Count(string(hook), hookBlockBegin) != 1 {
+		t.Errorf("pre-commit after install:\n%s", hook)
+	}
+	if info, err := os.Stat(filepath.Join(hooksDir, managedHookScript)); err != nil || info.Mode()&0o111 == 0 {
+		t.Errorf("managed script missing or not executable: %v", err)
+	}
+
+	removed, err := uninstallPreCommitHook(hooksDir)
+	if err != nil || !removed {
+		t.Fatalf("uninstall = %v, %v", removed, err)
+	}
+	if hook, _ := os.ReadFile(hookPath); string(hook) != userHook {
+		t.Errorf("pre-commit after uninstall = %q, want the user's hook back", hook)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, managedHookScript)); !os.IsNotExist(err) {
+		t.Error("managed script left behind")
+	}
+
+	// A hook ailloy created is deleted again.
+	if err := os.Remove(hookPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := installPreCommitHook(hooksDir, "#!/bin/sh\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uninstallPreCommitHook(hooksDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Error("pre-commit created by install survived uninstall")
+	}
+
+	// A hook in another language is left for the user to wire up.
+	mustWrite(t, hookPath, "#!/usr/bin/env python3\nprint('hi')\n")
+	if err := installPreCommitHook(hooksDir, "#!/bin/sh\n"); err == nil || !strings.Contains(err.Error(), "not a shell script") {
+		t.Errorf("install over a python hook: err = %v", err)
+	}
+}
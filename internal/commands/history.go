@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// historyFile is the append-only audit log of operations that wrote or
+// removed blanks, one JSON entry per line, under .ailloy/ (or ~/.ailloy/
+// for --global).
+const historyFile = "history.log"
+
+var (
+	historyLimit  int
+	historyGlobal bool
+	historyFiles  bool
+	historyOutput string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [mold]",
+	Short: "Show the log of casts, upgrades and uninstalls",
+	Long: `Show .ailloy/history.log, the append-only record of every operation that
+wrote or removed blanks: cast, recast, sync, plugin install, uninstall,
+revert and rollback. Each entry has the time, the mold's source and version,
+the files touched and the flags it ran with. Newest entries are listed first.
+
+Pass a mold name or source to show only its entries.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "show at most this many entries (0 for all)")
+	historyCmd.Flags().BoolVarP(&historyGlobal, "global", "g", false, "show the global log (~/.ailloy/history.log)")
+	historyCmd.Flags().BoolVar(&historyFiles, "files", false, "list the files each operation touched")
+	addOutputFlag(historyCmd, &historyOutput)
+}
+
+// historyEntry is one line of the history log.
+type historyEntry struct {
+	Time    time.Time `json:"time" yaml:"time"`
+	Op      string    `json:"op" yaml:"op"`
+	Mold    string    `json:"mold,omitempty" yaml:"mold,omitempty"`
+	Source  string    `json:"source,omitempty" yaml:"source,omitempty"`
+	Version string    `json:"version,omitempty" yaml:"version,omitempty"`
+	Commit  string    `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Files   []string  `json:"files,omitempty" yaml:"files,omitempty"`
+	Flags   []string  `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Global  bool      `json:"global,omitempty" yaml:"global,omitempty"`
+	// Options are the flux values and selection a cast ran with, which
+	// rollback replays.
+	Options *foundry.CastOptionsRecord `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// historyPathFor returns the project or global history log path. Returns
+// "" if the home directory cannot be resolved.
+func historyPathFor(global bool) string {
+	if !global {
+		return filepath.Join(".ailloy", historyFile)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ailloy", historyFile)
+}
+
+// appendHistory adds e to the log at path. Entries are only ever appended,
+// so concurrent writers interleave whole lines.
+func appendHistory(path string, e historyEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil { // #nosec G301
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 -- ailloy's own state dir
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readHistory returns the entries of the log at path, oldest first. A
+// missing log has no entries; a line that isn't an entry is an error
+// naming it.
+func readHistory(path string) ([]historyEntry, error) {
+	f, err := os.Open(path) // #nosec G304 -- ailloy's own state dir
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []historyEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var e historyEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// recordHistory stamps e and appends it to the project or global log. The
+// log is an audit aid: failing to write it is a warning, never a failed
+// operation.
+func recordHistory(e historyEntry, logger *log.Logger) {
+	e.Time = time.Now().UTC().Truncate(time.Second)
+	path := historyPathFor(e.Global)
+	if path == "" {
+		return
+	}
+	if err := appendHistory(path, e); err != nil {
+		if logger == nil {
+			logger = log.Default()
+		}
+		logger.Printf("warning: failed to record history: %v", err)
+	}
+}
+
+// historyFlags renders the flags set on cmd's command line as --name=value,
+// in the order cobra defines them. Repeated flags keep every value.
+func historyFlags(cmd *cobra.Command) []string {
+	if cmd == nil {
+		return nil
+	}
+	var out []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				out = append(out, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		if f.Value.Type() == "bool" && f.Value.String() == "true" {
+			out = append(out, "--"+f.Name)
+			return
+		}
+		out = append(out, "--"+f.Name+"="+f.Value.String())
+	})
+	return out
+}
+
+// castedHistoryFiles lists the cast destinations that exist on disk, in
+// the slash form installed.yaml records: renders that came out empty were
+// never written.
+func castedHistoryFiles(destPrefix string, paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			out = append(out, installedRelPath(destPrefix, p))
+		}
+	}
+	return out
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(historyOutput); err != nil {
+		return err
+	}
+	if historyLimit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	path := historyPathFor(historyGlobal)
+	if path == "" {
+		return fmt.Errorf("cannot determine home directory")
+	}
+	entries, err := readHistory(path)
+	if err != nil {
+		return err
+	}
+	mold := ""
+	if len(args) == 1 {
+		mold = args[0]
+	}
+	return writeHistory(cmd.OutOrStdout(), selectHistory(entries, mold, historyLimit), historyOutput, historyFiles)
+}
+
+// selectHistory returns the entries for mold (matched by name or source;
+// "" for all), newest first, at most limit of them (0 for no limit).
+func selectHistory(entries []historyEntry, mold string, limit int) []historyEntry {
+	out := []historyEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if mold != "" && !strings.EqualFold(e.Mold, mold) && !strings.EqualFold(e.Source, mold) {
+			continue
+		}
+		out = append(out, e)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+func writeHistory(w io.Writer, entries []historyEntry, format string, files bool) error {
+	if format != "" {
+		return writeStructured(w, format, entries)
+	}
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(w, "No history yet. Casts, recasts, syncs, uninstalls and reverts are recorded here.")
+		return nil
+	}
+	for _, e := range entries {
+		subject := e.Mold
+		if subject == "" {
+			subject = e.Source
+		}
+		if e.Version != "" {
+			subject += "@" + e.Version
+		}
+		line := fmt.Sprintf("%s  %-10s %s", styles.SubtleStyle.Render(e.Time.Local().Format("2006-01-02 15:04:05")),
+			e.Op, styles.CodeStyle.Render(subject))
+		line += styles.SubtleStyle.Render(fmt.Sprintf("  %d file(s)", len(e.Files)))
+		if len(e.Flags) > 0 {
+			line += "  " + strings.Join(e.Flags, " ")
+		}
+		_, _ = fmt.Fprintln(w, line)
+		if e.Source != "" && e.Source != e.Mold {
+			_, _ = fmt.Fprintln(w, styles.SubtleStyle.Render("    from "+e.Source))
+		}
+		if files {
+			for _, f := range e.Files {
+				_, _ = fmt.Fprintln(w, styles.SubtleStyle.Render("    - "+f))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestHistory_AppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ailloy", historyFile)
+
+	entries, err := readHistory(path)
+	if err != nil || entries != nil {
+		t.Fatalf("missing log: got %v, %v; want no entries", entries, err)
+	}
+
+	first := historyEntry{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Op: "cast", Mold: "demo", Version: "1.0.0", Files: []string{"a.md"}}
+	second := historyEntry{Time: first.Time.Add(time.Hour), Op: "uninstall", Mold: "demo", Flags: []string{"--force"}}
+	for _, e := range []historyEntry{first, second} {
+		if err := appendHistory(path, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err = readHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Op != "cast" || entries[1].Op != "uninstall" {
+		t.Fatalf("entries = %+v, want cast then uninstall", entries)
+	}
+	if !entries[0].Time.Equal(first.Time) || !slices.Equal(entries[0].Files, first.Files) {
+		t.Errorf("first entry = %+v, want %+v", entries[0], first)
+	}
+}
+
+func TestHistory_ReadRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFile)
+	if err := os.WriteFile(path, []byte("{\"op\":\"cast\"}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readHistory(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("err = %v, want one naming line 2", err)
+	}
+}
+
+func TestSelectHistory(t *testing.T) {
+	entries := []historyEntry{
+		{Op: "cast", Mold: "demo"},
+		{Op: "cast", Mold: "other", Source: "github.com/acme/other"},
+		{Op: "recast", Mold: "demo"},
+		{Op: "uninstall", Mold: "demo"},
+	}
+
+	got := selectHistory(entries, "", 0)
+	if len(got) != 4 || got[0].Op != "uninstall" || got[3].Op != "cast" {
+		t.Errorf("all entries = %+v, want newest first", got)
+	}
+	got = selectHistory(entries, "demo", 2)
+	if len(got) != 2 || got[0].Op != "uninstall" || got[1].Op != "recast" {
+		t.Errorf("demo, limit 2 = %+v, want uninstall, recast", got)
+	}
+	got = selectHistory(entries, "github.com/acme/other", 0)
+	if len(got) != 1 || got[0].Mold != "other" {
+		t.Errorf("by source = %+v, want the other mold's cast", got)
+	}
+}
+
+func TestHistoryFlags(t *testing.T) {
+	var (
+		global bool
+		set    []string
+		values []string
+		ci     string
+	)
+	cmd := &cobra.Command{Use: "cast"}
+	cmd.Flags().BoolVarP(&global, "global", "g", false, "")
+	cmd.Flags().StringArrayVar(&set, "set", nil, "")
+	cmd.Flags().StringSliceVarP(&values, "values", "f", nil, "")
+	cmd.Flags().StringVar(&ci, "ci", "", "")
+	if err := cmd.ParseFlags([]string{"-g", "--set", "a=1", "--set", "b=x,y", "-f", "v.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"--global", "--set=a=1", "--set=b=x,y", "--values=v.yaml"}
+	if got := historyFlags(cmd); !slices.Equal(got, want) {
+		t.Errorf("historyFlags = %q, want %q", got, want)
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	entries := []historyEntry{{
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Op:      "cast",
+		Mold:    "demo",
+		Source:  "github.com/acme/demo",
+		Version: "v1.2.3",
+		Files:   []string{".claude/commands/a.md"},
+		Flags:   []string{"--global"},
+	}}
+
+	var out bytes.Buffer
+	if err := writeHistory(&out, entries, "", true); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"cast", "demo@v1.2.3", "1 file(s)", "--global", "from github.com/acme/demo", "- .claude/commands/a.md"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := writeHistory(&out, []historyEntry{}, "json", false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("json for no entries = %q, want []", out.String())
+	}
+}
+
+func TestCastMold_RecordsHistory(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	t.Setenv("HOME", t.TempDir())
+
+	moldDir := filepath.Join(projectDir, "mold")
+	if err := os.MkdirAll(filepath.Join(moldDir, "commands"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(moldDir, "mold.yaml"),
+		[]byte("apiVersion: v1\nkind: Mold\nname: demo\nversion: 0.2.0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(moldDir, "flux.yaml"),
+		[]byte("output:\n  commands: .claude/commands\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(moldDir, "commands", "hello.md"), []byte("hello\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CastMold(t.Context(), moldDir, CastOptions{HistoryOp: "sync", HistoryFlags: []string{"--frozen"}}); err != nil {
+		t.Fatalf("CastMold: %v", err)
+	}
+
+	entries, err := readHistory(historyPathFor(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("history has %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Op != "sync" || e.Mold != "demo" || e.Version != "0.2.0" || e.Time.IsZero() {
+		t.Errorf("entry = %+v, want a timestamped sync of demo 0.2.0", e)
+	}
+	if !slices.Equal(e.Files, []string{".claude/commands/hello.md"}) || !slices.Equal(e.Flags, []string{"--frozen"}) {
+		t.Errorf("entry files/flags = %q %q", e.Files, e.Flags)
+	}
+}
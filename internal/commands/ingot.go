@@ -249,11 +249,29 @@ func installSingleIngot(fsys fs.FS, pkg mold.IngotPackage, result *foundry.Resol
 		return foundry.ArtifactEntry{}, fmt.Errorf("creating ingot directory: %w", err)
 	}
 
+	if err := copyIngotPackage(fsys, pkg, destDir); err != nil {
+		return foundry.ArtifactEntry{}, err
+	}
+
+	return foundry.ArtifactEntry{
+		Name:        pkg.Name,
+		Source:      result.Ref.CacheKey(),
+		Subpath:     effectiveSubpath,
+		Version:     result.Resolved.Tag,
+		Commit:      result.Resolved.Commit,
+		InstalledAt: time.Now().UTC(),
+		Dependents:  []string{"user"},
+	}, nil
+}
+
+// copyIngotPackage writes pkg's files from fsys into destDir, printing each
+// written path.
+func copyIngotPackage(fsys fs.FS, pkg mold.IngotPackage, destDir string) error {
 	pkgFS := fsys
 	if pkg.Root != "." {
 		sub, serr := fs.Sub(fsys, pkg.Root)
 		if serr != nil {
-			return foundry.ArtifactEntry{}, fmt.Errorf("scoping fs to %s: %w", pkg.Root, serr)
+			return fmt.Errorf("scoping fs to %s: %w", pkg.Root, serr)
 		}
 		pkgFS = sub
 	}
@@ -266,6 +284,9 @@ func installSingleIngot(fsys fs.FS, pkg mold.IngotPackage, result *foundry.Resol
 		if d.IsDir() {
 			return os.MkdirAll(destPath, 0o750)
 		}
+		if err := mold.CheckMoldPath(pkgFS, p); err != nil {
+			return err
+		}
 		content, rerr := fs.ReadFile(pkgFS, p)
 		if rerr != nil {
 			return fmt.Errorf("reading %s: %w", p, rerr)
@@ -276,16 +297,7 @@ func installSingleIngot(fsys fs.FS, pkg mold.IngotPackage, result *foundry.Resol
 		fmt.Println(styles.SuccessStyle.Render("  + ") + styles.CodeStyle.Render(destPath))
 		return nil
 	}); err != nil {
-		return foundry.ArtifactEntry{}, fmt.Errorf("copying ingot files: %w", err)
+		return fmt.Errorf("copying ingot files: %w", err)
 	}
-
-	return foundry.ArtifactEntry{
-		Name:        pkg.Name,
-		Source:      result.Ref.CacheKey(),
-		Subpath:     effectiveSubpath,
-		Version:     result.Resolved.Tag,
-		Commit:      result.Resolved.Commit,
-		InstalledAt: time.Now().UTC(),
-		Dependents:  []string{"user"},
-	}, nil
+	return nil
 }
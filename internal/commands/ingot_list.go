@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/safepath"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var ingotListMold string
+
+var ingotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available ingots",
+	Long: `List the ingots {{ingot "name"}} can resolve, in search order.
+
+Search paths are the mold directory (--mold), the current directory,
+./.ailloy, and ~/.ailloy — each searched under ingots/. When the same name
+appears on more than one path, later copies are marked as shadowed.
+
+Ingots downloaded to the foundry cache (ailloy ingot get) are listed
+separately; install them with 'ailloy ingot add' or vendor them with
+'ailloy ingot vendor' to make them resolvable.`,
+	Args: cobra.NoArgs,
+	RunE: runIngotList,
+}
+
+var ingotShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Display an ingot's manifest and content",
+	Long: `Display the ingot {{ingot "name"}} resolves to: where it was found, its
+manifest fields, and its unrendered source.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIngotShow,
+}
+
+var showIngotSubCmd = &cobra.Command{
+	Use:   "ingot <name>",
+	Short: "Display an ingot's manifest and content",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runIngotShow,
+}
+
+func init() {
+	ingotCmd.AddCommand(ingotListCmd)
+	ingotCmd.AddCommand(ingotShowCmd)
+	// Bidirectional: "show ingot <name>" also works
+	showCmd.AddCommand(showIngotSubCmd)
+
+	for _, c := range []*cobra.Command{ingotListCmd, ingotShowCmd, showIngotSubCmd} {
+		c.Flags().StringVar(&ingotListMold, "mold", "", "mold directory to search first")
+	}
+}
+
+func runIngotList(_ *cobra.Command, _ []string) error {
+	resolver := buildIngotResolver(nil, ingotListMold)
+	ingots, err := resolver.List()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(styles.HeaderStyle.Render("Ingots"))
+	fmt.Println()
+
+	if len(ingots) == 0 {
+		fmt.Println(styles.SubtleStyle.Render("  No ingots found on the search paths."))
+	}
+	var lastPath string
+	for _, in := range ingots {
+		if in.SearchPath != lastPath {
+			if lastPath != "" {
+				fmt.Println()
+			}
+			fmt.Println(styles.InfoStyle.Render(filepath.Join(in.SearchPath, "ingots")))
+			lastPath = in.SearchPath
+		}
+		fmt.Println("  " + formatIngotLine(in))
+	}
+
+	cached, err := cachedIngots()
+	if err != nil {
+		return err
+	}
+	if len(cached) > 0 {
+		fmt.Println()
+		fmt.Println(styles.InfoStyle.Render("Foundry cache") + styles.SubtleStyle.Render(" (not resolvable until added or vendored)"))
+		for _, c := range cached {
+			fmt.Println("  " + styles.AccentStyle.Render(c.Name) + " " + c.Version +
+				styles.SubtleStyle.Render("  "+c.Ref))
+		}
+	}
+	return nil
+}
+
+func formatIngotLine(in mold.IngotInfo) string {
+	line := styles.AccentStyle.Render(in.Name)
+	switch {
+	case in.Bare:
+		line += styles.SubtleStyle.Render(" (bare file)")
+	case in.Version != "":
+		line += " " + in.Version
+	}
+	if in.Description != "" {
+		line += styles.SubtleStyle.Render(" - " + in.Description)
+	}
+	if in.Shadowed {
+		line += styles.WarningStyle.Render(" [shadowed]")
+	}
+	return line
+}
+
+// cachedIngot is an ingot package found in a foundry cache snapshot.
+type cachedIngot struct {
+	Name    string
+	Version string
+	// Ref is the reference that installs exactly this package.
+	Ref string
+}
+
+// cachedIngots scans every cached foundry snapshot for ingot packages. Mold
+// snapshots without ingots are skipped; unreadable snapshots are ignored.
+func cachedIngots() ([]cachedIngot, error) {
+	cacheDir, err := foundry.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := foundry.ListCachedMolds(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	var out []cachedIngot
+	for _, e := range entries {
+		key := e.Host + "/" + e.Owner + "/" + e.Repo
+		for _, v := range e.Versions {
+			pkgs, err := mold.DiscoverIngotPackages(os.DirFS(filepath.Join(cacheDir, e.Host, e.Owner, e.Repo, v)))
+			if err != nil {
+				continue
+			}
+			for _, p := range pkgs {
+				ref := key + "@" + v
+				if p.Subpath != "" {
+					ref += "//" + p.Subpath
+				}
+				out = append(out, cachedIngot{Name: p.Name, Version: p.Version, Ref: ref})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Ref < out[j].Ref
+	})
+	return out, nil
+}
+
+func runIngotShow(_ *cobra.Command, args []string) error {
+	resolver := buildIngotResolver(nil, ingotListMold)
+	in, err := resolver.Locate(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(styles.HeaderStyle.Render(in.Name))
+	fmt.Println()
+	if in.Version != "" {
+		fmt.Println(styles.InfoStyle.Render("Version:     ") + in.Version)
+	}
+	if in.Description != "" {
+		fmt.Println(styles.InfoStyle.Render("Description: ") + in.Description)
+	}
+	fmt.Println(styles.InfoStyle.Render("Path:        ") + styles.CodeStyle.Render(in.Path))
+	if in.Bare {
+		fmt.Println(styles.SubtleStyle.Render("Bare-file ingot (no ingot.yaml); cannot satisfy version constraints."))
+	} else {
+		fmt.Println(styles.InfoStyle.Render("Files:       ") + strings.Join(in.Files, ", "))
+	}
+
+	dir := in.Path
+	if in.Bare {
+		dir = filepath.Dir(in.Path)
+	}
+	for _, f := range in.Files {
+		fp, err := safepath.Join(dir, f)
+		if err != nil {
+			return fmt.Errorf("ingot %q file %q: %w", in.Name, f, err)
+		}
+		content, err := os.ReadFile(fp) // #nosec G304 -- path sanitized by safepath.Join
+		if err != nil {
+			return fmt.Errorf("reading ingot %q file %q: %w", in.Name, f, err)
+		}
+		fmt.Println()
+		fmt.Println(styles.SubtleStyle.Render("── " + f + " ──"))
+		fmt.Print(string(content))
+		if !strings.HasSuffix(string(content), "\n") {
+			fmt.Println()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var ingotNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Scaffold a new ingot",
+	Long: `Scaffold a new manifest-based ingot: an ingot.yaml and a content file.
+
+By default the ingot is created under ./ingots/<name>/, which is where a
+mold's own ingots live — run it from the mold root. Use -o to pick another
+parent directory (e.g. -o . for a standalone ingot repository).
+
+Example:
+  ailloy ingot new pr-checklist
+  ailloy ingot new pr-checklist -o .`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIngotNew,
+}
+
+var ingotNewOutput string
+
+func init() {
+	ingotCmd.AddCommand(ingotNewCmd)
+	ingotNewCmd.Flags().StringVarP(&ingotNewOutput, "output", "o", "ingots", "parent directory to create the ingot in")
+}
+
+func runIngotNew(_ *cobra.Command, args []string) error {
+	name := args[0]
+	if strings.ContainsAny(name, "/\\:*?\"<>|") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid ingot name %q: contains special characters", name)
+	}
+
+	ingotDir := filepath.Join(ingotNewOutput, name)
+	if _, err := os.Stat(ingotDir); err == nil {
+		return fmt.Errorf("directory %s already exists", ingotDir)
+	}
+
+	logging.Decor(styles.WorkingBanner("Scaffolding new ingot..."), "")
+
+	if err := os.MkdirAll(ingotDir, 0750); err != nil { // #nosec G301 -- Ingot directories need group read access
+		return fmt.Errorf("failed to create directory %s: %w", ingotDir, err)
+	}
+
+	contentFile := name + ".md"
+	files := []struct{ path, content string }{
+		{"ingot.yaml", scaffoldIngotYaml(name, contentFile)},
+		{contentFile, scaffoldIngotContent(name)},
+	}
+	for _, f := range files {
+		dest := filepath.Join(ingotDir, f.path)
+		//#nosec G306 -- Ingot files need to be readable
+		if err := os.WriteFile(dest, []byte(f.content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+		logging.Say(styles.SuccessStyle.Render("  created ")+styles.CodeStyle.Render(filepath.Join(ingotDir, f.path)), "created", "file", filepath.Join(ingotDir, f.path))
+	}
+
+	logging.Decor("", styles.SuccessBanner("Ingot scaffolded at "+ingotDir), "")
+
+	nextSteps := styles.InfoStyle.Render("Next steps:\n\n") +
+		"  1. Edit " + styles.CodeStyle.Render("ingot.yaml") + " to set the description\n" +
+		"  2. Write the reusable content in " + styles.CodeStyle.Render(contentFile) + "\n" +
+		"  3. Include it from a blank with " + styles.CodeStyle.Render(fmt.Sprintf(`{{ingot "%s"}}`, name)) + "\n" +
+		"  4. Validate with " + styles.CodeStyle.Render("ailloy temper "+ingotDir)
+	logging.Decor(styles.InfoBoxStyle.Render(nextSteps))
+
+	return nil
+}
+
+func scaffoldIngotYaml(name, contentFile string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ingot
+name: %s
+version: 0.1.0
+description: ""
+files:
+  - %s
+`, name, contentFile)
+}
+
+func scaffoldIngotContent(name string) string {
+	return fmt.Sprintf(`## %s
+
+Reusable content shared across blanks. Flux variables and ingot arguments
+are available here just like in a blank.
+`, name)
+}
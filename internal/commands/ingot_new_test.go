@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestRunIngotNew_CreatesManifestAndContent(t *testing.T) {
+	dir := t.TempDir()
+	ingotNewOutput = filepath.Join(dir, "ingots")
+	defer func() { ingotNewOutput = "ingots" }()
+
+	if err := runIngotNew(nil, []string{"pr-checklist"}); err != nil {
+		t.Fatalf("runIngotNew: %v", err)
+	}
+
+	ingot, err := mold.LoadIngot(filepath.Join(dir, "ingots", "pr-checklist", "ingot.yaml"))
+	if err != nil {
+		t.Fatalf("LoadIngot: %v", err)
+	}
+	if ingot.Kind != "ingot" || ingot.Name != "pr-checklist" || len(ingot.Files) != 1 || ingot.Files[0] != "pr-checklist.md" {
+		t.Errorf("unexpected manifest: %+v", ingot)
+	}
+
+	r := mold.NewIngotResolver([]string{dir}, nil)
+	if _, err := r.Resolve("pr-checklist"); err != nil {
+		t.Errorf("scaffolded ingot should resolve: %v", err)
+	}
+	if res := mold.Temper(os.DirFS(filepath.Join(dir, "ingots", "pr-checklist"))); res.HasErrors() {
+		t.Errorf("scaffolded ingot should temper cleanly: %+v", res.Diagnostics)
+	}
+
+	if err := runIngotNew(nil, []string{"pr-checklist"}); err == nil {
+		t.Error("expected error when the ingot already exists")
+	}
+	if err := runIngotNew(nil, []string{"bad/name"}); err == nil {
+		t.Error("expected error for invalid name")
+	}
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"golang.org/x/sync/errgroup"
+)
+
+// attachRemoteIngots pre-fetches every remote {{ingot "<ref>"}} the cast can
+// reach and hands the results to r, so rendering never touches the network.
+// See collectRemoteIngots for what is scanned.
+func attachRemoteIngots(r *mold.IngotResolver, moldFS fs.FS, resolved []mold.ResolvedFile) error {
+	remote, err := collectRemoteIngots(moldFS, resolved, fetchRemoteIngot)
+	if err != nil {
+		return err
+	}
+	if len(remote) > 0 {
+		r.Remote = remote
+	}
+	return nil
+}
+
+// collectRemoteIngots scans the processed blanks in resolved, the mold's own
+// ingots/ tree, and (transitively) every fetched remote ingot for remote
+// {{ingot}} references, fetching each distinct reference once. References
+// found at the same depth are fetched concurrently, up to foundry.FetchJobs
+// at a time; fetch must be safe for concurrent use. If a depth's first
+// failure is an offline cache miss, every miss at that depth is reported.
+func collectRemoteIngots(moldFS fs.FS, resolved []mold.ResolvedFile, fetch func(ref string) (fs.FS, error)) (map[string]fs.FS, error) {
+	var queue []string
+	for _, rf := range resolved {
+		if !rf.Process {
+			continue
+		}
+		data, err := fs.ReadFile(chooseFS(rf, moldFS), rf.SrcPath)
+		if err != nil {
+			continue // surfaced by the render pass
+		}
+		queue = append(queue, mold.RemoteIngotRefs(string(data))...)
+	}
+	if moldFS != nil {
+		queue = append(queue, scanRemoteIngotRefs(moldFS, "ingots")...)
+	}
+
+	fetched := make(map[string]fs.FS)
+	for len(queue) > 0 {
+		var wave []string
+		for _, ref := range queue {
+			if _, done := fetched[ref]; !done {
+				fetched[ref] = nil
+				wave = append(wave, ref)
+			}
+		}
+		queue = nil
+
+		results := make([]fs.FS, len(wave))
+		errs := make([]error, len(wave))
+		var g errgroup.Group
+		g.SetLimit(foundry.FetchJobs)
+		for i, ref := range wave {
+			g.Go(func() error {
+				results[i], errs[i] = fetch(ref)
+				return nil
+			})
+		}
+		_ = g.Wait()
+
+		for i, ref := range wave {
+			if errs[i] != nil {
+				if foundry.IsNotCached(errs[i]) {
+					return nil, foundry.MissingRefs(errs...)
+				}
+				return nil, fmt.Errorf("fetching remote ingot %q: %w", ref, errs[i])
+			}
+			fetched[ref] = results[i]
+			queue = append(queue, scanRemoteIngotRefs(results[i], ".")...)
+		}
+	}
+	return fetched, nil
+}
+
+// scanRemoteIngotRefs returns remote ingot references found in the .md files
+// under root in fsys. A missing root yields nil.
+func scanRemoteIngotRefs(fsys fs.FS, root string) []string {
+	var refs []string
+	_ = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".md" {
+			return nil
+		}
+		if data, rerr := fs.ReadFile(fsys, p); rerr == nil {
+			refs = append(refs, mold.RemoteIngotRefs(string(data))...)
+		}
+		return nil
+	})
+	return refs
+}
+
+// fetchRemoteIngot resolves a remote ingot reference through the same path as
+// declared ingot dependencies: the smelted binary's embedded store first,
+// then the foundry cache (honoring --offline and ailloy.lock).
+func fetchRemoteIngot(ref string) (fs.FS, error) {
+	base, version := splitIngotRefVersion(ref)
+	fsys, _, _, _, _, err := resolveDepFS(base, version, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(fsys, "ingot.yaml"); err != nil {
+		return nil, fmt.Errorf("no ingot.yaml at %s", ref)
+	}
+	return fsys, nil
+}
+
+// splitIngotRefVersion separates a trailing @version written after the
+// //subpath (`host/owner/repo//footer@^1.0.0`, the natural form inside a
+// template) from the reference. References that carry the version in the
+// standard position (`host/owner/repo@^1.0.0//footer`) or none at all are
+// returned unchanged with an empty version.
+func splitIngotRefVersion(ref string) (string, string) {
+	i := strings.Index(ref, "//")
+	if i == -1 {
+		return ref, ""
+	}
+	sub := ref[i+2:]
+	if j := strings.LastIndex(sub, "@"); j != -1 {
+		return ref[:i+2] + sub[:j], sub[j+1:]
+	}
+	return ref, ""
+}
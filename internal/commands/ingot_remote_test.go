@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestSplitIngotRefVersion(t *testing.T) {
+	tests := []struct {
+		ref, base, version string
+	}{
+		{"github.com/org/ingots//footer@^1.0.0", "github.com/org/ingots//footer", "^1.0.0"},
+		{"github.com/org/ingots@v1.2.0//footer", "github.com/org/ingots@v1.2.0//footer", ""},
+		{"github.com/org/footer", "github.com/org/footer", ""},
+	}
+	for _, tt := range tests {
+		base, version := splitIngotRefVersion(tt.ref)
+		if base != tt.base || version != tt.version {
+			t.Errorf("splitIngotRefVersion(%q) = (%q, %q), want (%q, %q)", tt.ref, base, version, tt.base, tt.version)
+		}
+	}
+}
+
+func TestCollectRemoteIngots_Transitive(t *testing.T) {
+	moldFS := fstest.MapFS{
+		"commands/a.md":   {Data: []byte(`{{ingot "github.com/org/one"}} {{ingot "local"}}`)},
+		"commands/raw.md": {Data: []byte(`{{ingot "github.com/org/skipped"}}`)},
+		"ingots/local.md": {Data: []byte(`{{ingot "github.com/org/two"}}`)},
+	}
+	remotes := map[string]fs.FS{
+		"github.com/org/one":   fstest.MapFS{"ingot.yaml": {Data: []byte("name: one")}, "x.md": {Data: []byte(`{{ingot "github.com/org/three"}}`)}},
+		"github.com/org/two":   fstest.MapFS{"ingot.yaml": {Data: []byte("name: two")}},
+		"github.com/org/three": fstest.MapFS{"ingot.yaml": {Data: []byte("name: three")}},
+	}
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	fetch := func(ref string) (fs.FS, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, ref)
+		if f, ok := remotes[ref]; ok {
+			return f, nil
+		}
+		return nil, errors.New("not found")
+	}
+	resolved := []mold.ResolvedFile{
+		{SrcPath: "commands/a.md", Process: true},
+		{SrcPath: "commands/raw.md", Process: false},
+	}
+
+	got, err := collectRemoteIngots(moldFS, resolved, fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("expected one, two, three fetched, got %v", calls)
+	}
+	if len(calls) != 3 {
+		t.Errorf("each ref must be fetched once, got calls %v", calls)
+	}
+}
+
+func TestCollectRemoteIngots_FetchError(t *testing.T) {
+	moldFS := fstest.MapFS{"commands/a.md": {Data: []byte(`{{ingot "github.com/org/missing"}}`)}}
+	fetch := func(string) (fs.FS, error) { return nil, errors.New("boom") }
+	_, err := collectRemoteIngots(moldFS, []mold.ResolvedFile{{SrcPath: "commands/a.md", Process: true}}, fetch)
+	if err == nil {
+		t.Fatal("expected fetch error")
+	}
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ingotVendorMold  string
+	ingotVendorForce bool
+)
+
+var ingotVendorCmd = &cobra.Command{
+	Use:   "vendor <reference>",
+	Short: "Copy a remote ingot into a mold's ingots/ directory",
+	Long: `Copy a remote ingot into the mold's own ingots/<name>/ directory.
+
+Vendored ingots ship inside the mold, so casts resolve them without
+fetching, installing, or declaring a dependency. Unlike 'ingot add', nothing
+is recorded in .ailloy/installed.yaml or ailloy.lock — the copy is part of
+the mold's source from now on.
+
+The reference follows the standard format: <host>/<owner>/<repo>[@<version>][//<subpath>]
+A multi-ingot repository without a //subpath vendors every ingot in it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIngotVendor,
+}
+
+func init() {
+	ingotCmd.AddCommand(ingotVendorCmd)
+	ingotVendorCmd.Flags().StringVar(&ingotVendorMold, "mold", ".", "mold directory to vendor into")
+	ingotVendorCmd.Flags().BoolVar(&ingotVendorForce, "force", false, "replace an existing ingot of the same name")
+}
+
+func runIngotVendor(_ *cobra.Command, args []string) error {
+	ref := args[0]
+	if !foundry.IsRemoteReference(ref) {
+		return fmt.Errorf("expected a remote reference (e.g. github.com/owner/repo), got %q", ref)
+	}
+
+	fmt.Println(styles.WorkingBanner("Vendoring ingot..."))
+	fmt.Println()
+
+	fsys, err := foundry.Resolve(ref)
+	if err != nil {
+		return fmt.Errorf("resolving ingot: %w", err)
+	}
+	names, err := vendorIngots(fsys, ingotVendorMold, ingotVendorForce)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for _, name := range names {
+		fmt.Println(styles.SuccessStyle.Render("Vendored: ") + styles.AccentStyle.Render(name) +
+			styles.SubtleStyle.Render(fmt.Sprintf(`  use {{ingot "%s"}}`, name)))
+	}
+	return nil
+}
+
+// vendorIngots copies every ingot package in fsys into <moldDir>/ingots/<name>/
+// and returns the vendored names. Existing ingot directories are an error
+// unless force is set, in which case they are replaced.
+func vendorIngots(fsys fs.FS, moldDir string, force bool) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(moldDir, "mold.yaml")); err != nil {
+		return nil, fmt.Errorf("%s is not a mold directory (no mold.yaml); pass --mold <dir>", moldDir)
+	}
+
+	pkgs, err := mold.DiscoverIngotPackages(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("discovering ingots: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no ingot.yaml found at root or under ingots/<name>/")
+	}
+
+	// Check every destination before writing so a partial vendor can't
+	// leave the mold half-updated.
+	for _, p := range pkgs {
+		dest := filepath.Join(moldDir, "ingots", p.Name)
+		if _, err := os.Stat(dest); err == nil && !force {
+			return nil, fmt.Errorf("ingot %s already exists; pass --force to replace it", dest)
+		}
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for _, p := range pkgs {
+		dest := filepath.Join(moldDir, "ingots", p.Name)
+		if err := os.RemoveAll(dest); err != nil {
+			return nil, fmt.Errorf("removing existing ingot %s: %w", dest, err)
+		}
+		if err := os.MkdirAll(dest, 0o750); err != nil {
+			return nil, fmt.Errorf("creating ingot directory: %w", err)
+		}
+		if err := copyIngotPackage(fsys, p, dest); err != nil {
+			return nil, err
+		}
+		names = append(names, p.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestVendorIngots_SingleIngot(t *testing.T) {
+	src := t.TempDir()
+	writeIngotFixture(t, src, "footer", "1.0.0")
+	moldDir := t.TempDir()
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: m\nversion: 1.0.0\n")
+
+	names, err := vendorIngots(os.DirFS(src), moldDir, false)
+	if err != nil {
+		t.Fatalf("vendorIngots: %v", err)
+	}
+	if len(names) != 1 || names[0] != "footer" {
+		t.Fatalf("names = %v", names)
+	}
+	mustFile(t, filepath.Join(moldDir, "ingots", "footer", "ingot.yaml"))
+	mustFile(t, filepath.Join(moldDir, "ingots", "footer", "content.md"))
+
+	// The vendored copy resolves from the mold root.
+	r := mold.NewIngotResolver([]string{moldDir}, nil)
+	out, err := r.Resolve("footer")
+	if err != nil || !strings.Contains(out, "# footer") {
+		t.Errorf("Resolve = %q, %v", out, err)
+	}
+}
+
+func TestVendorIngots_MultiIngotAndForce(t *testing.T) {
+	src := t.TempDir()
+	writeMultiIngotFixture(t, src, "alpha", "beta")
+	moldDir := t.TempDir()
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: m\nversion: 1.0.0\n")
+
+	if _, err := vendorIngots(os.DirFS(src), moldDir, false); err != nil {
+		t.Fatalf("vendorIngots: %v", err)
+	}
+	mustFile(t, filepath.Join(moldDir, "ingots", "alpha", "ingot.yaml"))
+	mustFile(t, filepath.Join(moldDir, "ingots", "beta", "ingot.yaml"))
+
+	stale := filepath.Join(moldDir, "ingots", "alpha", "stale.md")
+	if err := os.WriteFile(stale, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vendorIngots(os.DirFS(src), moldDir, false); err == nil {
+		t.Fatal("expected error when ingot already exists")
+	}
+	if _, err := vendorIngots(os.DirFS(src), moldDir, true); err != nil {
+		t.Fatalf("vendorIngots --force: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("--force should replace the existing ingot directory")
+	}
+}
+
+func TestVendorIngots_RequiresMold(t *testing.T) {
+	src := t.TempDir()
+	writeIngotFixture(t, src, "footer", "1.0.0")
+	if _, err := vendorIngots(os.DirFS(src), t.TempDir(), false); err == nil {
+		t.Error("expected error for a directory without mold.yaml")
+	}
+}
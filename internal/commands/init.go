@@ -0,0 +1,346 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/assay"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up ailloy in the current project",
+	Long: `Set up ailloy in the current project.
+
+Init looks at the repository (its origin remote, CI system, and the AI
+tools whose files it already has), asks which AI tools the team uses and
+which mold to start from, then:
+
+  - adds the mold to ailloy.yaml, creating it if needed; tools other than
+    Claude Code go in the mold's to: list, so their files are converted
+    too (see ailloy sync)
+  - adds ailloy's local state (trial casts under .ailloy/ephemeral) to
+    .gitignore
+  - casts the project's molds, as ailloy sync does
+
+The official nimble-mold is recommended as the starter mold. Without a
+terminal, or with --yes, the detected tools and the recommended mold are
+used; --tools, --mold, --no-mold and --no-cast set the answers instead.
+
+Example:
+  ailloy init
+  ailloy init --yes --tools claude,cursor
+  ailloy init --mold github.com/acme/platform-mold --no-cast`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+var (
+	initTools         []string
+	initMold          string
+	initNoMold        bool
+	initNoCast        bool
+	initWithWorkflows bool
+	initYes           bool
+)
+
+// starterMold is the mold init recommends.
+const starterMold = "github.com/nimble-giant/nimble-mold"
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringSliceVar(&initTools, "tools", nil, "AI tools the team uses (comma-separated: "+strings.Join(initToolNames(), ", ")+"; default: detected)")
+	initCmd.Flags().StringVar(&initMold, "mold", starterMold, "starter mold to add to ailloy.yaml")
+	initCmd.Flags().BoolVar(&initNoMold, "no-mold", false, "create ailloy.yaml without a starter mold")
+	initCmd.Flags().BoolVar(&initNoCast, "no-cast", false, "write ailloy.yaml and .gitignore without casting")
+	initCmd.Flags().BoolVar(&initWithWorkflows, "with-workflows", false, "include the mold's workflow blanks for the repository's CI system")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "accept the detected and recommended answers without prompting")
+}
+
+// initTool is an AI tool init can set a project up for. Every tool but
+// Claude Code, which molds target natively, is an output adapter name.
+type initTool struct {
+	name    string
+	title   string
+	markers []string // project paths whose presence means the tool is in use
+}
+
+var initToolList = []initTool{
+	{plugin.FormatClaude, "Claude Code", []string{".claude", "CLAUDE.md"}},
+	{plugin.FormatCursor, "Cursor", []string{".cursor", ".cursorrules"}},
+	{plugin.FormatOpenCode, "OpenCode", []string{".opencode", "opencode.json"}},
+	{plugin.FormatCodex, "Codex CLI", []string{".codex"}},
+	{plugin.FormatWindsurf, "Windsurf", []string{".windsurf", ".windsurfrules"}},
+	{plugin.FormatJetBrains, "JetBrains AI Assistant", []string{".aiassistant"}},
+}
+
+func initToolNames() []string {
+	names := make([]string, len(initToolList))
+	for i, t := range initToolList {
+		names[i] = t.name
+	}
+	return names
+}
+
+func initToolTitle(name string) string {
+	for _, t := range initToolList {
+		if t.name == name {
+			return t.title
+		}
+	}
+	return name
+}
+
+// detectInitTools returns the tools with files in dir, in initToolList order.
+func detectInitTools(dir string) []string {
+	var found []string
+	for _, t := range initToolList {
+		for _, marker := range t.markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				found = append(found, t.name)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// initGitignoreEntries is the local state init keeps out of version control:
+// the files trial casts back up and their record.
+var initGitignoreEntries = []string{".ailloy/ephemeral/", foundry.EphemeralStatePath}
+
+// initAnswers are init's choices, from flags, detection, or the prompt.
+type initAnswers struct {
+	Tools         []string
+	Mold          string
+	WithWorkflows bool
+	Cast          bool
+}
+
+func runInit(cmd *cobra.Command, _ []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := assay.FindProjectRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("finding project root: %w", err)
+	}
+	if root != cwd {
+		return fmt.Errorf("run ailloy init from the project root (%s)", root)
+	}
+
+	detected := detectInitTools(".")
+	ci, hasCI := mold.DetectCI(".")
+	printInitDetection(detected, ci, hasCI)
+
+	answers := initAnswers{
+		Tools:         initTools,
+		Mold:          initMold,
+		WithWorkflows: initWithWorkflows,
+		Cast:          !initNoCast,
+	}
+	if initNoMold {
+		answers.Mold = ""
+	}
+	if len(answers.Tools) == 0 {
+		answers.Tools = detected
+		if len(answers.Tools) == 0 {
+			answers.Tools = []string{plugin.FormatClaude}
+		}
+	}
+	if !initYes && isInteractive() {
+		if err := promptInit(&answers, hasCI && !cmd.Flags().Changed("with-workflows")); err != nil {
+			return err
+		}
+	}
+	for _, name := range answers.Tools {
+		if !slices.Contains(initToolNames(), name) {
+			return fmt.Errorf("unknown tool %q (want one of %s)", name, strings.Join(initToolNames(), ", "))
+		}
+	}
+
+	created, err := writeInitProjectFile(answers)
+	if err != nil {
+		return err
+	}
+	verb := "updated "
+	if created {
+		verb = "created "
+	}
+	logging.Say(styles.SuccessStyle.Render("  "+verb)+styles.CodeStyle.Render(foundry.ProjectFileName), strings.TrimSpace(verb), "file", foundry.ProjectFileName)
+
+	added, err := addGitignoreEntries(".gitignore", initGitignoreEntries)
+	if err != nil {
+		return err
+	}
+	if len(added) > 0 {
+		logging.Say(styles.SuccessStyle.Render("  updated ")+styles.CodeStyle.Render(".gitignore")+
+			styles.SubtleStyle.Render(" ("+strings.Join(added, ", ")+")"), "updated", "file", ".gitignore", "added", added)
+	}
+	logging.Decor("")
+
+	if answers.Mold != "" && !slices.Contains(answers.Tools, plugin.FormatClaude) {
+		logging.Decor(styles.SubtleStyle.Render("The mold's own blanks are cast as well, at the destinations it declares."), "")
+	}
+	if answers.Mold == "" || !answers.Cast {
+		next := "Add molds under " + styles.CodeStyle.Render("molds:") + " in " + styles.CodeStyle.Render(foundry.ProjectFileName) +
+			", then run " + styles.CodeStyle.Render("ailloy sync") + " to cast them."
+		if answers.Mold != "" {
+			next = "Run " + styles.CodeStyle.Render("ailloy sync") + " to cast " + styles.CodeStyle.Render(answers.Mold) + "."
+		}
+		logging.Decor(styles.InfoStyle.Render("Next: ") + next)
+		return nil
+	}
+	return syncProjectMolds(cmd.Context(), foundry.ProjectFileName, syncOptions{})
+}
+
+// printInitDetection reports what init found in the project.
+func printInitDetection(tools []string, ci mold.CISystem, hasCI bool) {
+	// row prints label: value, or label: none (subtle) when value is empty;
+	// the log record carries the plain value.
+	row := func(label, value, none string) {
+		pretty := value
+		if value == "" {
+			pretty = styles.SubtleStyle.Render(none)
+		}
+		logging.Say(styles.SubtleStyle.Render(fmt.Sprintf("%-11s", label+":"))+" "+pretty, "detected", "kind", label, "value", value)
+	}
+	repoValue := ""
+	if repo, ok := mold.DetectRepo("."); ok {
+		repoValue = repo.Host + "/" + repo.Owner + "/" + repo.Name
+		if repo.DefaultBranch != "" {
+			repoValue += " (" + repo.DefaultBranch + ")"
+		}
+	}
+	row("Repository", repoValue, "no git origin remote")
+	ciValue := ""
+	if hasCI {
+		ciValue = ci.Title
+	}
+	row("CI", ciValue, "none detected")
+	titles := make([]string, len(tools))
+	for i, name := range tools {
+		titles[i] = initToolTitle(name)
+	}
+	row("AI tools", strings.Join(titles, ", "), "none detected")
+	logging.Decor("")
+}
+
+// promptInit asks for init's choices, pre-filled from flags and detection.
+// askWorkflows adds the workflow question, for repositories with CI.
+func promptInit(a *initAnswers, askWorkflows bool) error {
+	options := make([]huh.Option[string], 0, len(initToolList))
+	for _, t := range initToolList {
+		options = append(options, huh.NewOption(t.title, t.name).Selected(slices.Contains(a.Tools, t.name)))
+	}
+	fields := []huh.Field{
+		huh.NewMultiSelect[string]().
+			Title("Which AI tools does the team use?").
+			Options(options...).
+			Validate(func(s []string) error {
+				if len(s) == 0 {
+					return fmt.Errorf("pick at least one tool")
+				}
+				return nil
+			}).
+			Value(&a.Tools),
+		huh.NewInput().
+			Title("Starter mold").
+			Description("Recommended: " + starterMold + ". Leave empty to add molds later.").
+			Value(&a.Mold),
+	}
+	if askWorkflows {
+		fields = append(fields, huh.NewConfirm().
+			Title("Include the mold's CI workflows?").
+			Affirmative("Yes").
+			Negative("No").
+			Value(&a.WithWorkflows))
+	}
+	fields = append(fields, huh.NewConfirm().
+		Title("Cast the mold now?").
+		Affirmative("Yes").
+		Negative("No").
+		Value(&a.Cast))
+	form := huh.NewForm(huh.NewGroup(fields...).Title("Set up ailloy")).WithTheme(ailloyTheme())
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("prompt failed: %w", err)
+	}
+	a.Mold = strings.TrimSpace(a.Mold)
+	return nil
+}
+
+// writeInitProjectFile adds the chosen mold to ailloy.yaml, creating the
+// file if needed, and reports whether it was created. A mold already
+// declared keeps its entry.
+func writeInitProjectFile(a initAnswers) (bool, error) {
+	pf, err := foundry.ReadProjectFile(foundry.ProjectFileName)
+	if err != nil {
+		return false, err
+	}
+	created := pf == nil
+	if created {
+		pf = &foundry.ProjectFile{APIVersion: "v1", Molds: []foundry.ProjectMold{}}
+	}
+	if a.Mold != "" && pf.FindMold(a.Mold) == nil {
+		m := foundry.ProjectMold{Ref: a.Mold, WithWorkflows: a.WithWorkflows}
+		for _, name := range a.Tools {
+			if name != plugin.FormatClaude {
+				m.To = append(m.To, name)
+			}
+		}
+		pf.Molds = append(pf.Molds, m)
+	}
+	return created, foundry.WriteProjectFile(foundry.ProjectFileName, pf)
+}
+
+// addGitignoreEntries appends the entries path doesn't list yet under an
+// ailloy comment, creating the file if needed, and returns the ones added.
+func addGitignoreEntries(path string, entries []string) ([]string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- project .gitignore
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	present := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+	var added []string
+	for _, e := range entries {
+		if !present[e] && !present["/"+e] {
+			added = append(added, e)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	b.Write(data)
+	if len(data) > 0 {
+		if data[len(data)-1] != '\n' {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("# ailloy local state\n")
+	for _, e := range added {
+		b.WriteString(e + "\n")
+	}
+	//#nosec G306 -- .gitignore is committed
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return added, nil
+}
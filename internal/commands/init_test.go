@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+)
+
+func TestDetectInitTools(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, ".cursorrules"), "")
+	if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := detectInitTools(dir), []string{"claude", "cursor"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("detectInitTools = %v, want %v", got, want)
+	}
+}
+
+func TestWriteInitProjectFile(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	created, err := writeInitProjectFile(initAnswers{Tools: []string{"claude", "cursor"}, Mold: starterMold})
+	if err != nil || !created {
+		t.Fatalf("first write: created=%v err=%v", created, err)
+	}
+	created, err = writeInitProjectFile(initAnswers{Tools: []string{"codex"}, Mold: "github.com/acme/team-mold", WithWorkflows: true})
+	if err != nil || created {
+		t.Fatalf("second write: created=%v err=%v", created, err)
+	}
+	// Re-running with a declared mold leaves its entry alone.
+	if _, err := writeInitProjectFile(initAnswers{Tools: []string{"opencode"}, Mold: starterMold}); err != nil {
+		t.Fatal(err)
+	}
+
+	pf, err := foundry.ReadProjectFile(foundry.ProjectFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []foundry.ProjectMold{
+		{Ref: starterMold, To: []string{"cursor"}},
+		{Ref: "github.com/acme/team-mold", WithWorkflows: true, To: []string{"codex"}},
+	}
+	if pf.APIVersion != "v1" || !reflect.DeepEqual(pf.Molds, want) {
+		t.Errorf("ailloy.yaml = %+v", pf)
+	}
+}
+
+func TestAddGitignoreEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gitignore")
+	mustWrite(t, path, "node_modules/\n/.ailloy/ephemeral.yaml")
+
+	added, err := addGitignoreEntries(path, initGitignoreEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(added, []string{".ailloy/ephemeral/"}) {
+		t.Errorf("added = %v", added)
+	}
+	data, _ := os.ReadFile(path)
+	want := "node_modules/\n/.ailloy/ephemeral.yaml\n\n# ailloy local state\n.ailloy/ephemeral/\n"
+	if string(data) != want {
+		t.Errorf(".gitignore = %q, want %q", data, want)
+	}
+
+	if added, err := addGitignoreEntries(path, initGitignoreEntries); err != nil || added != nil {
+		t.Errorf("second run: added=%v err=%v, want nothing", added, err)
+	}
+}
+
+func TestSyncProjectMolds_ConvertsForTools(t *testing.T) {
+	project := t.TempDir()
+	chdir(t, project)
+	t.Setenv("HOME", t.TempDir())
+
+	moldDir := filepath.Join(project, "molds", "base")
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: base\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\nteam: default\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "base.md"), "# {{team}}\n")
+	mustWrite(t, filepath.Join(project, "ailloy.yaml"), `apiVersion: v1
+molds:
+  - ref: molds/base
+    to: [cursor]
+`)
+	if err := syncProjectMolds(t.Context(), "ailloy.yaml", syncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := castedBody(t, ".claude/commands/base.md"); got != "# default\n" {
+		t.Errorf("native cast = %q", got)
+	}
+	rules, _ := filepath.Glob(filepath.Join(project, ".cursor", "rules", "*.mdc"))
+	if len(rules) == 0 {
+		t.Fatal("expected Cursor rules from to: [cursor]")
+	}
+
+	mustWrite(t, filepath.Join(project, "ailloy.yaml"), "molds:\n  - ref: molds/base\n    to: [vim]\n")
+	if err := syncProjectMolds(t.Context(), "ailloy.yaml", syncOptions{}); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("unknown tool: err = %v", err)
+	}
+}
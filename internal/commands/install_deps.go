@@ -7,13 +7,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/internal/tui/progress"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/smelt"
 	"github.com/nimble-giant/ailloy/pkg/styles"
+	"golang.org/x/sync/errgroup"
 )
 
 // installDeclaredDeps walks manifest.Dependencies, installs any missing
@@ -75,7 +79,12 @@ func installDeclaredDeps(manifest *mold.Mold, moldKey string, global, allowLocal
 		im = &foundry.InstalledManifest{APIVersion: "v1"}
 	}
 
-	for _, d := range manifest.Dependencies {
+	fetched, err := prefetchDeclaredDeps(manifest, im, global, allowLocalDeps, frozen, silent)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range manifest.Dependencies {
 		kind, _ := d.Kind() // already validated above
 		// Mold-on-mold dependencies are resolved transitively by the cast
 		// pipeline (see castTransitiveDeps), not here. This function is for
@@ -110,17 +119,21 @@ func installDeclaredDeps(manifest *mold.Mold, moldKey string, global, allowLocal
 		}
 
 		if !silent {
-			fmt.Println(styles.WorkingBanner(fmt.Sprintf("Installing %s %s...", kind, ref)))
+			logging.Decor(styles.WorkingBanner(fmt.Sprintf("Installing %s %s...", kind, ref)))
 		}
-		fsys, resolvedSource, resolvedSubpath, version, commit, err := resolveDepFS(ref, d.Version, global)
-		if err != nil {
-			return fmt.Errorf("resolving %s %s: %w", kind, ref, err)
+		dep, ok := fetched[i]
+		if !ok {
+			dep = fetchDeclaredDep(ref, d.Version, global)
+		}
+		if dep.err != nil {
+			return fmt.Errorf("resolving %s %s: %w", kind, ref, dep.err)
 		}
+		fsys, version, commit := dep.fsys, dep.version, dep.commit
 		// Trust the resolver's view of (source, subpath) once we have it;
 		// pre-parse can't see e.g. lock-file rewrites, but practically these
 		// match for both the remote and local branches.
-		sourceID = resolvedSource
-		subpath = resolvedSubpath
+		sourceID = dep.source
+		subpath = dep.subpath
 
 		// Validate manifest matches kind. Ingots may be multi-package; ore is
 		// always single-package today (PR #192 requires explicit subpath for
@@ -152,6 +165,12 @@ func installDeclaredDeps(manifest *mold.Mold, moldKey string, global, allowLocal
 					continue
 				}
 
+				if pkg.Ingot != nil {
+					if err := requireAilloy("ingot "+pkg.Name, pkg.Ingot.Requires.Ailloy); err != nil {
+						return err
+					}
+				}
+
 				pkgFS := fsys
 				if pkg.Root != "." {
 					sub, serr := fs.Sub(fsys, pkg.Root)
@@ -180,7 +199,8 @@ func installDeclaredDeps(manifest *mold.Mold, moldKey string, global, allowLocal
 				}
 				im.UpsertArtifact("ingot", entry)
 				if !silent {
-					fmt.Println(styles.SuccessStyle.Render("  Installed: ") + styles.AccentStyle.Render(pkg.Name+" "+version))
+					logging.Say(styles.SuccessStyle.Render("  Installed: ")+styles.AccentStyle.Render(pkg.Name+" "+version),
+						"installed dependency", "kind", "ingot", "name", pkg.Name, "version", version)
 				}
 			}
 			continue
@@ -196,6 +216,9 @@ func installDeclaredDeps(manifest *mold.Mold, moldKey string, global, allowLocal
 			return fmt.Errorf("manifest at %s has kind=%q, expected 'ore'", ref, ore.Kind)
 		}
 		manifestName = ore.Name
+		if err := requireAilloy("ore "+ore.Name, ore.Requires.Ailloy); err != nil {
+			return err
+		}
 
 		// Install-dir name resolution (alias if set, else manifest name).
 		installName := manifestName
@@ -237,7 +260,8 @@ func installDeclaredDeps(manifest *mold.Mold, moldKey string, global, allowLocal
 		im.UpsertArtifact(kind, entry)
 
 		if !silent {
-			fmt.Println(styles.SuccessStyle.Render("  Installed: ") + styles.AccentStyle.Render(manifestName+" "+version))
+			logging.Say(styles.SuccessStyle.Render("  Installed: ")+styles.AccentStyle.Render(manifestName+" "+version),
+				"installed dependency", "kind", kind, "name", manifestName, "version", version)
 		}
 	}
 
@@ -261,6 +285,93 @@ func installDeclaredDeps(manifest *mold.Mold, moldKey string, global, allowLocal
 	return nil
 }
 
+// fetchedDep is the outcome of resolveDepFS for one declared dependency.
+type fetchedDep struct {
+	fsys                             fs.FS
+	source, subpath, version, commit string
+	err                              error
+}
+
+// fetchDeclaredDep wraps resolveDepFS in a fetchedDep.
+func fetchDeclaredDep(ref, declaredVersion string, global bool) fetchedDep {
+	var d fetchedDep
+	d.fsys, d.source, d.subpath, d.version, d.commit, d.err = resolveDepFS(ref, declaredVersion, global)
+	return d
+}
+
+// prefetchDeclaredDeps resolves the ingot/ore deps installDeclaredDeps is
+// about to install, up to foundry.FetchJobs at a time, keyed by their index
+// in manifest.Dependencies. Deps the install loop would skip or reject
+// (mold-kind, already installed, local paths when not allowed, anything
+// under --frozen) are left out, as is a lone dep, which the loop fetches
+// itself. Failures are recorded per dep and surface when the loop reaches
+// it, so errors keep their declaration order. The exception is an offline
+// cache miss: if the first failure is one, every uncached dep is returned
+// together as a *foundry.MissingRefsError.
+func prefetchDeclaredDeps(manifest *mold.Mold, im *foundry.InstalledManifest, global, allowLocalDeps, frozen, silent bool) (map[int]fetchedDep, error) {
+	if frozen {
+		return nil, nil
+	}
+	var todo []int
+	for i, d := range manifest.Dependencies {
+		kind, _ := d.Kind()
+		ref := d.Source()
+		if kind == "mold" || (!allowLocalDeps && !foundry.IsRemoteReference(ref)) {
+			continue
+		}
+		sourceID, subpath := depIdentity(ref)
+		if findArtifactBySource(im, kind, sourceID, subpath, d.As) != nil {
+			continue
+		}
+		todo = append(todo, i)
+	}
+	if len(todo) < 2 {
+		return nil, nil
+	}
+
+	var bar *progress.Bar
+	if !silent {
+		bar = progress.New(len(todo), "Fetching dependencies")
+		defer bar.Finish()
+	}
+	var (
+		mu  sync.Mutex
+		out = make(map[int]fetchedDep, len(todo))
+		g   errgroup.Group
+	)
+	g.SetLimit(foundry.FetchJobs)
+	for _, i := range todo {
+		d := manifest.Dependencies[i]
+		g.Go(func() error {
+			dep := fetchDeclaredDep(d.Source(), d.Version, global)
+			mu.Lock()
+			out[i] = dep
+			mu.Unlock()
+			if dep.err == nil && !silent {
+				bar.Say(styles.SuccessStyle.Render("  Fetched: ")+styles.CodeStyle.Render(d.Source())+" "+styles.CodeStyle.Render(dep.version),
+					"fetched dependency", "ref", d.Source(), "version", dep.version)
+			}
+			bar.Step()
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	errs := make([]error, 0, len(todo))
+	for _, i := range todo {
+		errs = append(errs, out[i].err)
+	}
+	for _, err := range errs {
+		if err != nil {
+			if foundry.IsNotCached(err) {
+				return nil, foundry.MissingRefs(errs...)
+			}
+			break
+		}
+	}
+	return out, nil
+}
+
 // resolveDepFS returns an fs.FS for an ore/ingot dep along with provenance
 // fields suitable for InstalledManifest: source (cache key for remote, path
 // for local), subpath (only set for remote refs that include //subpath),
@@ -288,14 +399,7 @@ func resolveDepFS(ref, declaredVersion string, global bool) (fs.FS, string, stri
 			}
 		}
 
-		var resolveOpts []foundry.ResolveOption
-		if global {
-			resolveOpts = append(resolveOpts, foundry.WithLockPath(globalLockPath()))
-		}
-		if castOffline {
-			resolveOpts = append(resolveOpts, foundry.WithOffline())
-		}
-		fsys, result, err := foundry.ResolveWithMetadata(ref, resolveOpts...)
+		fsys, result, err := foundry.ResolveWithMetadata(ref, castResolveOpts(global)...)
 		if err != nil {
 			return nil, "", "", "", "", err
 		}
@@ -396,6 +500,9 @@ func copyFromFS(fsys fs.FS, dest string) error {
 		if d.IsDir() {
 			return os.MkdirAll(out, 0750) // #nosec G301
 		}
+		if err := mold.CheckMoldPath(fsys, path); err != nil {
+			return err
+		}
 		body, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return err
@@ -610,8 +717,9 @@ func cascadeUninstallTransitiveMolds(manifestPath, parentKey string, global, dry
 				log.Printf("warning: cascade-uninstall of transitive mold %s: %v", o.label, uerr)
 				continue
 			}
-			fmt.Println(styles.SuccessStyle.Render("  Cascade-removed: ") + styles.AccentStyle.Render("mold "+o.label) +
-				styles.SubtleStyle.Render(fmt.Sprintf(" (%d file(s))", len(ures.Deleted))))
+			logging.Say(styles.SuccessStyle.Render("  Cascade-removed: ")+styles.AccentStyle.Render("mold "+o.label)+
+				styles.SubtleStyle.Render(fmt.Sprintf(" (%d file(s))", len(ures.Deleted))),
+				"cascade-removed", "kind", "mold", "name", o.label, "files", len(ures.Deleted))
 			// Recurse: this orphan might have its own transitives.
 			if err := cascadeUninstallTransitiveMolds(manifestPath, o.label, global, dryRun); err != nil {
 				return err
@@ -673,7 +781,8 @@ func cascadeUninstallArtifacts(manifestPath, moldKey string, global bool) error
 		if err := os.RemoveAll(baseDir); err != nil {
 			log.Printf("warning: removing %s: %v", baseDir, err)
 		}
-		fmt.Println(styles.SuccessStyle.Render("  Cascade-removed: ") + styles.AccentStyle.Render(kind+" "+installName))
+		logging.Say(styles.SuccessStyle.Render("  Cascade-removed: ")+styles.AccentStyle.Render(kind+" "+installName),
+			"cascade-removed", "kind", kind, "name", installName)
 	}
 
 	if err := foundry.WriteInstalledManifest(manifestPath, im); err != nil {
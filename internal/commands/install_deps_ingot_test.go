@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -146,3 +148,69 @@ func TestInstallDeclaredDeps_BareIngotRef_DoubleInvoke_NoReInstall(t *testing.T)
 		}
 	}
 }
+
+func TestInstallDeclaredDeps_SeveralDeps_FetchedConcurrently(t *testing.T) {
+	tmp := t.TempDir()
+	srcDir := filepath.Join(tmp, "src")
+	writeMultiIngotFixture(t, srcDir, "header", "footer", "aside", "extra")
+
+	projectDir := filepath.Join(tmp, "proj")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, projectDir)
+
+	var deps []mold.Dependency
+	for _, name := range []string{"header", "footer", "aside"} {
+		deps = append(deps, mold.Dependency{Ingot: filepath.Join(srcDir, "ingots", name), Version: "local"})
+	}
+	manifest := &mold.Mold{APIVersion: "v1", Kind: "mold", Name: "consumer", Version: "0.1.0", Dependencies: deps}
+
+	if err := installDeclaredDeps(manifest, "test/consumer", false, true, false, true, nil); err != nil {
+		t.Fatalf("installDeclaredDeps: %v", err)
+	}
+	im, err := foundry.ReadInstalledManifest(filepath.Join(projectDir, ".ailloy", "installed.yaml"))
+	if err != nil || im == nil {
+		t.Fatalf("read installed manifest: im=%v err=%v", im, err)
+	}
+	if len(im.Ingots) != 3 {
+		t.Fatalf("expected 3 ingot entries, got %d (%+v)", len(im.Ingots), im.Ingots)
+	}
+
+	// A failed fetch is reported for its own dep.
+	missing := filepath.Join(srcDir, "ingots", "missing")
+	manifest.Dependencies = []mold.Dependency{
+		{Ingot: filepath.Join(srcDir, "ingots", "extra"), Version: "local"},
+		{Ingot: missing, Version: "local"},
+	}
+	err = installDeclaredDeps(manifest, "test/other", false, true, false, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "resolving ingot "+missing) {
+		t.Fatalf("expected error naming %s, got %v", missing, err)
+	}
+}
+
+func TestInstallDeclaredDeps_OfflineListsEveryMissingRef(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AILLOY_TMPDIR", "")
+	chdir(t, t.TempDir())
+	castOffline = true
+	t.Cleanup(func() { castOffline = false })
+
+	manifest := &mold.Mold{
+		APIVersion: "v1", Kind: "mold", Name: "consumer", Version: "0.1.0",
+		Dependencies: []mold.Dependency{
+			{Ingot: "github.com/acme/header", Version: "^1.0.0"},
+			{Ore: "github.com/acme/status", Version: "^1.0.0"},
+			{Ingot: "github.com/acme/footer", Version: "^1.0.0"},
+		},
+	}
+	err := installDeclaredDeps(manifest, "test/consumer", false, false, false, true, nil)
+	var missing *foundry.MissingRefsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingRefsError, got %v", err)
+	}
+	want := []string{"github.com/acme/header", "github.com/acme/status", "github.com/acme/footer"}
+	if !slices.Equal(missing.Refs, want) {
+		t.Errorf("missing refs = %v, want %v", missing.Refs, want)
+	}
+}
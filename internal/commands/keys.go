@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/keys"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keysGenerateForce bool
+	keysExportOut     string
+	keysTrustName     string
+	keysTrustHosts    []string
+	keysListOutput    string
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage mold signing keys and trusted publishers",
+	Long: `Manage the ed25519 keys meant for signing and verifying smelted artifacts
+and remote molds. Keys live under ~/.ailloy/keys.
+
+Signatures are not enforced yet: no command signs a mold, and cast and
+install don't check signatures.
+
+Available subcommands:
+  generate   Create a signing key
+  export     Print a signing key's public key to share with users
+  trust      Trust a publisher's public key, optionally pinned to foundry hosts
+  revoke     Stop trusting a publisher key
+  list       List signing keys and trusted publishers`,
+}
+
+var keysGenerateCmd = &cobra.Command{
+	Use:   "generate <name>",
+	Short: "Create a signing key",
+	Long: `Create an ed25519 signing key: ~/.ailloy/keys/<name>.key, readable only
+by you, and <name>.pub. Share the public key with ` + "`ailloy keys export`" + `.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runKeysGenerate,
+}
+
+var keysExportCmd = &cobra.Command{
+	Use:          "export <name>",
+	Short:        "Print a signing key's public key",
+	Long:         `Print the PEM public key of a signing key, or write it to --out, for users to pass to ` + "`ailloy keys trust`" + `.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runKeysExport,
+}
+
+var keysTrustCmd = &cobra.Command{
+	Use:   "trust <public-key-file>",
+	Short: "Trust a publisher's public key",
+	Long: `Trust a publisher's PEM public key ("-" reads it from stdin) under --name.
+
+--host pins the key to a foundry host (github.com) or a path under one
+(github.com/acme), and may be repeated. Signatures on molds from a pinned
+source only verify when made by a key pinned to it; keys without --host are
+trusted for every other source. Trusting a key again adds its new hosts.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runKeysTrust,
+}
+
+var keysRevokeCmd = &cobra.Command{
+	Use:   "revoke <name|fingerprint>",
+	Short: "Stop trusting a publisher key",
+	Long: `Revoke a trusted publisher key. Signatures it made no longer verify,
+and the key can't be trusted again; the name can be reused for a new key.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runKeysRevoke,
+}
+
+var keysListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List signing keys and trusted publishers",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runKeysList,
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysGenerateCmd, keysExportCmd, keysTrustCmd, keysRevokeCmd, keysListCmd)
+
+	keysGenerateCmd.Flags().BoolVar(&keysGenerateForce, "force", false, "replace an existing key of the same name")
+	keysExportCmd.Flags().StringVar(&keysExportOut, "out", "", "write the public key to this file instead of stdout")
+	keysTrustCmd.Flags().StringVar(&keysTrustName, "name", "", "name for the publisher (required)")
+	keysTrustCmd.Flags().StringArrayVar(&keysTrustHosts, "host", nil, "pin the key to this foundry host or host/owner path (repeatable)")
+	_ = keysTrustCmd.MarkFlagRequired("name")
+	addOutputFlag(keysListCmd, &keysListOutput)
+}
+
+func runKeysGenerate(_ *cobra.Command, args []string) error {
+	store, err := keys.DefaultStore()
+	if err != nil {
+		return err
+	}
+	key, err := store.Generate(args[0], keysGenerateForce)
+	if err != nil {
+		return err
+	}
+	fmt.Println(styles.SuccessStyle.Render("Generated: ") + styles.CodeStyle.Render(key.Name) + " " + styles.SubtleStyle.Render(key.Fingerprint))
+	fmt.Println(styles.SubtleStyle.Render("  Share the public key with: ailloy keys export " + key.Name))
+	return nil
+}
+
+func runKeysExport(cmd *cobra.Command, args []string) error {
+	store, err := keys.DefaultStore()
+	if err != nil {
+		return err
+	}
+	pub, err := store.PublicKey(args[0])
+	if err != nil {
+		return err
+	}
+	data, err := keys.EncodePublicKey(pub)
+	if err != nil {
+		return err
+	}
+	if keysExportOut == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	//#nosec G306 -- public keys are meant to be shared
+	if err := os.WriteFile(keysExportOut, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Println(styles.SuccessStyle.Render("Exported: ") + styles.CodeStyle.Render(keysExportOut) + " " + styles.SubtleStyle.Render(keys.Fingerprint(pub)))
+	return nil
+}
+
+func runKeysTrust(cmd *cobra.Command, args []string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if args[0] == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(args[0]) // #nosec G304 -- user-supplied key file
+	}
+	if err != nil {
+		return fmt.Errorf("reading public key: %w", err)
+	}
+	pub, err := keys.ParsePublicKey(data)
+	if err != nil {
+		return err
+	}
+	store, err := keys.DefaultStore()
+	if err != nil {
+		return err
+	}
+	key, err := store.Trust(keysTrustName, pub, keysTrustHosts)
+	if err != nil {
+		return err
+	}
+	scope := "every source without a pinned key"
+	if len(key.Hosts) > 0 {
+		scope = strings.Join(key.Hosts, ", ")
+	}
+	fmt.Println(styles.SuccessStyle.Render("Trusted: ") + styles.CodeStyle.Render(key.Name) + " " + styles.SubtleStyle.Render(key.Fingerprint))
+	fmt.Println(styles.SubtleStyle.Render("  for " + scope))
+	return nil
+}
+
+func runKeysRevoke(_ *cobra.Command, args []string) error {
+	store, err := keys.DefaultStore()
+	if err != nil {
+		return err
+	}
+	key, err := store.Revoke(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(styles.WarningStyle.Render("Revoked: ") + styles.CodeStyle.Render(key.Name) + " " + styles.SubtleStyle.Render(key.Fingerprint))
+	return nil
+}
+
+// keysListing is keys list's structured output.
+type keysListing struct {
+	Signing []keys.Key        `json:"signing" yaml:"signing"`
+	Trusted []keys.TrustedKey `json:"trusted" yaml:"trusted"`
+}
+
+func runKeysList(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(keysListOutput); err != nil {
+		return err
+	}
+	store, err := keys.DefaultStore()
+	if err != nil {
+		return err
+	}
+	return executeKeysList(store, keysListOutput, cmd.OutOrStdout())
+}
+
+func executeKeysList(store *keys.Store, format string, w io.Writer) error {
+	signing, err := store.Keys()
+	if err != nil {
+		return err
+	}
+	trusted, err := store.Trusted()
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		out := keysListing{Signing: signing, Trusted: trusted}
+		if out.Signing == nil {
+			out.Signing = []keys.Key{}
+		}
+		if out.Trusted == nil {
+			out.Trusted = []keys.TrustedKey{}
+		}
+		return writeStructured(w, format, out)
+	}
+
+	if len(signing) == 0 && len(trusted) == 0 {
+		_, _ = fmt.Fprintln(w, "No keys. Create one with `ailloy keys generate <name>` or trust a publisher with `ailloy keys trust`.")
+		return nil
+	}
+	if len(signing) > 0 {
+		_, _ = fmt.Fprintln(w, styles.HeaderStyle.Render("Signing keys"))
+		for _, k := range signing {
+			_, _ = fmt.Fprintf(w, "  %s  %s\n", k.Name, styles.SubtleStyle.Render(k.Fingerprint))
+		}
+	}
+	if len(trusted) > 0 {
+		_, _ = fmt.Fprintln(w, styles.HeaderStyle.Render("Trusted publishers"))
+		for _, k := range trusted {
+			scope := "any host"
+			if len(k.Hosts) > 0 {
+				scope = strings.Join(k.Hosts, ", ")
+			}
+			line := fmt.Sprintf("  %s  %s  %s", k.Name, styles.SubtleStyle.Render(k.Fingerprint), scope)
+			if k.Revoked != nil {
+				line += "  " + styles.WarningStyle.Render("revoked "+k.Revoked.Format("2006-01-02"))
+			}
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
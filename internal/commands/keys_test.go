@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/keys"
+)
+
+func TestExecuteKeysList(t *testing.T) {
+	store := &keys.Store{Dir: t.TempDir()}
+	var empty bytes.Buffer
+	if err := executeKeysList(store, "json", &empty); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(empty.String()), ""); got != `{"signing":[],"trusted":[]}` {
+		t.Errorf("empty listing = %s", got)
+	}
+
+	key, err := store.Generate("release", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := store.PublicKey("release")
+	if _, err := store.Trust("self", pub, []string{"github.com/acme"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Revoke("self"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := executeKeysList(store, "json", &buf); err != nil {
+		t.Fatal(err)
+	}
+	var got keysListing
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Signing) != 1 || got.Signing[0].Fingerprint != key.Fingerprint ||
+		len(got.Trusted) != 1 || got.Trusted[0].Revoked == nil || got.Trusted[0].Hosts[0] != "github.com/acme" {
+		t.Errorf("listing = %+v", got)
+	}
+
+	buf.Reset()
+	if err := executeKeysList(store, "", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "release") || !strings.Contains(out, "revoked") {
+		t.Errorf("text listing:\n%s", out)
+	}
+}
@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/nimble-giant/ailloy/pkg/mcp"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Model Context Protocol integration",
+	Long: `Model Context Protocol integration.
+
+Commands for driving ailloy from MCP clients such as Claude Desktop.`,
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve molds to MCP clients over stdio",
+	Long: `Run a Model Context Protocol server on stdin/stdout.
+
+The server exposes the project's installed command and skill blanks (as
+recorded in .ailloy/state.yaml) as prompts, and these tools:
+
+  list_molds    the molds cast into the project and the files they installed
+  render_mold   render a mold with flux overrides and return its files (like forge)
+  cast_mold     cast a mold into the project, or the home directory with global
+
+Start it from the project directory; MCP clients launch it as a subprocess.
+Claude Desktop, for example, is configured with:
+
+  {
+    "mcpServers": {
+      "ailloy": {
+        "command": "ailloy",
+        "args": ["mcp", "serve"],
+        "cwd": "/path/to/project"
+      }
+    }
+  }`,
+	Args: cobra.NoArgs,
+	RunE: runMCPServe,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpServeCmd)
+}
+
+func runMCPServe(cmd *cobra.Command, _ []string) error {
+	// Stdout carries the protocol; keep stray log output off it.
+	log.SetOutput(os.Stderr)
+	return newMCPServer().Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
+
+// newMCPServer builds the server `mcp serve` runs.
+func newMCPServer() *mcp.Server {
+	version := evolveCurrentVersion
+	if version == "" {
+		version = "dev"
+	}
+	refProp := map[string]any{"type": "string", "description": "Mold reference (host/owner/repo[@version][//subpath]) or local mold directory"}
+	setProp := map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Flux overrides as key=value"}
+	profileProp := map[string]any{"type": "string", "description": "Output profile declared by the mold"}
+	return &mcp.Server{
+		Name:    "ailloy",
+		Version: version,
+		Tools: []mcp.Tool{
+			{
+				Name:        "list_molds",
+				Description: "List the molds cast into the project and the files each installed.",
+				InputSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+				Handler:     mcpListMolds,
+			},
+			{
+				Name:        "render_mold",
+				Description: "Render a mold with layered flux and return its output files without installing anything.",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"mold":    refProp,
+						"set":     setProp,
+						"profile": profileProp,
+					},
+				},
+				Handler: mcpRenderMold,
+			},
+			{
+				Name:        "cast_mold",
+				Description: "Cast a mold: install its rendered blanks into the project (or the home directory with global).",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"mold":           refProp,
+						"set":            setProp,
+						"values":         map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Flux value files, later files override earlier"},
+						"profile":        profileProp,
+						"global":         map[string]any{"type": "boolean", "description": "Install under the home directory"},
+						"with_workflows": map[string]any{"type": "boolean", "description": "Include workflow blanks for the repository's CI system"},
+					},
+					"required": []string{"mold"},
+				},
+				Handler: mcpCastMold,
+			},
+		},
+		Prompts: mcpInstalledPrompts,
+	}
+}
+
+// mcpInstalledMold is one list_molds entry.
+type mcpInstalledMold struct {
+	Mold    string             `json:"mold"`
+	Source  string             `json:"source,omitempty"`
+	Version string             `json:"version,omitempty"`
+	Files   []mcpInstalledFile `json:"files"`
+}
+
+type mcpInstalledFile struct {
+	Dest string `json:"dest"`
+	Src  string `json:"src"`
+}
+
+func mcpListMolds(context.Context, map[string]any) (string, error) {
+	state, err := readInstallState(installStatePath)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", installStatePath, err)
+	}
+	molds := []*mcpInstalledMold{}
+	if state != nil {
+		byKey := map[string]*mcpInstalledMold{}
+		for _, f := range state.Files {
+			key := f.Mold + "\x00" + f.Source
+			m, ok := byKey[key]
+			if !ok {
+				m = &mcpInstalledMold{Mold: f.Mold, Source: f.Source, Version: f.Version}
+				byKey[key] = m
+				molds = append(molds, m)
+			}
+			m.Files = append(m.Files, mcpInstalledFile{Dest: f.Dest, Src: f.SrcPath})
+		}
+		sort.Slice(molds, func(i, j int) bool {
+			if molds[i].Mold != molds[j].Mold {
+				return molds[i].Mold < molds[j].Mold
+			}
+			return molds[i].Source < molds[j].Source
+		})
+	}
+	return mcpJSON(molds)
+}
+
+func mcpRenderMold(_ context.Context, args map[string]any) (string, error) {
+	ref, err := mcpStringArg(args, "mold")
+	if err != nil {
+		return "", err
+	}
+	set, err := mcpStringsArg(args, "set")
+	if err != nil {
+		return "", err
+	}
+	profile, err := mcpStringArg(args, "profile")
+	if err != nil {
+		return "", err
+	}
+
+	_, files, err := renderMoldRef(ref, nil, set, profile)
+	if err != nil {
+		return "", err
+	}
+	out := make([]renderedFileJSON, 0, len(files))
+	for _, f := range files {
+		out = append(out, renderedFileJSON{Path: f.CastDest, Content: string(f.Content)})
+	}
+	return mcpJSON(out)
+}
+
+// mcpCastSummary is the cast_mold result.
+type mcpCastSummary struct {
+	Mold   string   `json:"mold,omitempty"`
+	Source string   `json:"source,omitempty"`
+	Files  []string `json:"files,omitempty"`
+	Dirs   []string `json:"dirs"`
+}
+
+func mcpCastMold(ctx context.Context, args map[string]any) (string, error) {
+	ref, err := mcpStringArg(args, "mold")
+	if err != nil {
+		return "", err
+	}
+	if ref == "" {
+		return "", fmt.Errorf("mold is required")
+	}
+	var opts CastOptions
+	if opts.SetOverrides, err = mcpStringsArg(args, "set"); err != nil {
+		return "", err
+	}
+	if opts.ValueFiles, err = mcpStringsArg(args, "values"); err != nil {
+		return "", err
+	}
+	if opts.Profile, err = mcpStringArg(args, "profile"); err != nil {
+		return "", err
+	}
+	if opts.Global, err = mcpBoolArg(args, "global"); err != nil {
+		return "", err
+	}
+	if opts.WithWorkflows, err = mcpBoolArg(args, "with_workflows"); err != nil {
+		return "", err
+	}
+
+	res, err := CastMold(ctx, ref, opts)
+	if err != nil {
+		return "", err
+	}
+	summary := mcpCastSummary{Mold: res.MoldName, Source: res.Source, Dirs: res.Dirs}
+	for _, f := range res.FilesCast {
+		summary.Files = append(summary.Files, f.RelPath)
+	}
+	sort.Strings(summary.Dirs)
+	return mcpJSON(summary)
+}
+
+// mcpInstalledPrompts offers the installed command and skill blanks as
+// prompts, read from disk so local edits show up.
+func mcpInstalledPrompts() ([]mcp.Prompt, error) {
+	state, err := readInstallState(installStatePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", installStatePath, err)
+	}
+	if state == nil {
+		return nil, nil
+	}
+	var files []plugin.RenderedFile
+	for _, f := range state.Files {
+		content, err := os.ReadFile(f.Dest) // #nosec G304 -- paths recorded by cast
+		if err != nil {
+			continue // removed since it was cast
+		}
+		if _, body, ok := mold.ReadProvenance(f.Dest, content); ok {
+			content = body
+		}
+		files = append(files, plugin.RenderedFile{CastDest: f.Dest, Content: content})
+	}
+	compiled, err := plugin.CompilePrompts(files)
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]mcp.Prompt, 0, len(compiled))
+	for _, p := range compiled {
+		prompts = append(prompts, mcp.Prompt{
+			Name:        p.Name,
+			Description: p.Description,
+			Arguments: []mcp.PromptArgument{
+				{Name: "arguments", Description: "Text substituted for $ARGUMENTS"},
+			},
+			Render: func(args map[string]string) (string, error) {
+				return p.Expand(args["arguments"]), nil
+			},
+		})
+	}
+	return prompts, nil
+}
+
+func mcpJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func mcpStringArg(args map[string]any, key string) (string, error) {
+	v, ok := args[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return s, nil
+}
+
+func mcpBoolArg(args map[string]any, key string) (bool, error) {
+	v, ok := args[key]
+	if !ok || v == nil {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return b, nil
+}
+
+// mcpStringsArg reads a string-array argument; a lone string is accepted
+// as a one-element list.
+func mcpStringsArg(args map[string]any, key string) ([]string, error) {
+	switch v := args[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", key)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s must be a list of strings", key)
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMCPRenderMold(t *testing.T) {
+	moldDir := t.TempDir()
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: mcp-test\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\nproject_name: default\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "---\ndescription: Say hello\n---\nHello from {{project_name}}: $ARGUMENTS\n")
+	chdir(t, t.TempDir())
+
+	out, err := mcpRenderMold(context.Background(), map[string]any{
+		"mold": moldDir,
+		"set":  []any{"project_name=Atlas"},
+	})
+	if err != nil {
+		t.Fatalf("render_mold: %v", err)
+	}
+	var files []renderedFileJSON
+	if err := json.Unmarshal([]byte(out), &files); err != nil {
+		t.Fatalf("decoding %s: %v", out, err)
+	}
+	if len(files) != 1 || files[0].Path != ".claude/commands/hello.md" || !strings.Contains(files[0].Content, "Hello from Atlas") {
+		t.Fatalf("render_mold = %+v", files)
+	}
+	if _, err := os.Stat(".claude"); !os.IsNotExist(err) {
+		t.Error("render_mold must not install anything")
+	}
+
+	if _, err := mcpRenderMold(context.Background(), map[string]any{"mold": moldDir, "set": 3}); err == nil {
+		t.Error("expected an error for a non-list set argument")
+	}
+}
+
+func TestMCPCastListAndPrompts(t *testing.T) {
+	moldDir := t.TempDir()
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: mcp-test\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\nproject_name: default\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "---\ndescription: Say hello\n---\nHello from {{project_name}}: $ARGUMENTS\n")
+	chdir(t, t.TempDir())
+
+	if _, err := mcpCastMold(context.Background(), map[string]any{}); err == nil {
+		t.Fatal("expected cast_mold to require mold")
+	}
+	if _, err := mcpCastMold(context.Background(), map[string]any{"mold": moldDir}); err != nil {
+		t.Fatalf("cast_mold: %v", err)
+	}
+	if _, err := os.Stat(".claude/commands/hello.md"); err != nil {
+		t.Fatalf("cast_mold did not install the blank: %v", err)
+	}
+
+	out, err := mcpListMolds(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list_molds: %v", err)
+	}
+	var molds []mcpInstalledMold
+	if err := json.Unmarshal([]byte(out), &molds); err != nil {
+		t.Fatalf("decoding %s: %v", out, err)
+	}
+	if len(molds) != 1 || molds[0].Mold != "mcp-test" || len(molds[0].Files) != 1 || molds[0].Files[0].Dest != ".claude/commands/hello.md" {
+		t.Fatalf("list_molds = %+v", molds)
+	}
+
+	prompts, err := mcpInstalledPrompts()
+	if err != nil {
+		t.Fatalf("prompts: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "hello" || prompts[0].Description != "Say hello" {
+		t.Fatalf("prompts = %+v", prompts)
+	}
+	text, err := prompts[0].Render(map[string]string{"arguments": "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello from default: world" {
+		t.Errorf("prompt text = %q", text)
+	}
+}
+
+func TestMCPServerListsTools(t *testing.T) {
+	s := newMCPServer()
+	var names []string
+	for _, tool := range s.Tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "list_molds,render_mold,cast_mold" {
+		t.Errorf("tools = %s", got)
+	}
+}
@@ -2,13 +2,14 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
-	"github.com/goccy/go-yaml"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/styles"
@@ -26,14 +27,27 @@ Molds are Markdown files that define AI commands and workflows.`,
 var listMoldsCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available molds",
-	RunE:  runListMolds,
+	Long: `List the blanks installed in this project.
+
+With --installed, lists every file recorded in .ailloy/state.yaml grouped by
+the mold that produced it, with the source path inside the mold and a marker
+for files edited or deleted since they were cast.`,
+	RunE: runListMolds,
 }
 
+var (
+	// moldListInstalled switches mold list to per-file provenance output.
+	moldListInstalled bool
+	moldListOutput    string
+	moldShowOutput    string
+)
+
 var showMoldCmd = &cobra.Command{
-	Use:   "show <mold-name>",
-	Short: "Display a mold's content",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runShowMold,
+	Use:               "show <mold-name>",
+	Short:             "Display a mold's content",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeBlankNames,
+	RunE:              runShowMold,
 }
 
 // showCmd is a top-level command that enables bidirectional syntax: "mold show" and "show mold"
@@ -43,10 +57,11 @@ var showCmd = &cobra.Command{
 }
 
 var showMoldSubCmd = &cobra.Command{
-	Use:   "mold <mold-name>",
-	Short: "Display a mold's content",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runShowMold,
+	Use:               "mold <mold-name>",
+	Short:             "Display a mold's content",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeBlankNames,
+	RunE:              runShowMold,
 }
 
 var getMoldCmd = &cobra.Command{
@@ -67,13 +82,28 @@ func init() {
 	moldCmd.AddCommand(getMoldCmd)
 	moldCmd.AddCommand(newMoldCmd)
 
+	listMoldsCmd.Flags().BoolVar(&moldListInstalled, "installed", false, "show which mold each installed file came from")
+	addOutputFlag(listMoldsCmd, &moldListOutput)
+	addOutputFlag(showMoldCmd, &moldShowOutput)
+	addOutputFlag(showMoldSubCmd, &moldShowOutput)
+
 	// Bidirectional: "show mold <name>" also works
 	rootCmd.AddCommand(showCmd)
 	showCmd.AddCommand(showMoldSubCmd)
 }
 
 func runListMolds(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(moldListOutput); err != nil {
+		return err
+	}
+	if moldListInstalled {
+		return runListInstalledBlanks(cmd.OutOrStdout())
+	}
 	moldDirs, workflowDirs := loadInstalledDirs()
+	found := collectListedBlanks(moldDirs, workflowDirs)
+	if moldListOutput != "" {
+		return writeStructured(cmd.OutOrStdout(), moldListOutput, found)
+	}
 
 	// Header with inquisitive fox for exploring molds
 	header := lipgloss.JoinVertical(
@@ -84,7 +114,47 @@ func runListMolds(cmd *cobra.Command, args []string) error {
 	fmt.Println(header)
 	fmt.Println()
 
-	foundMolds := false
+	for _, b := range found {
+		if b.Unreadable {
+			errorMsg := styles.ErrorStyle.Render("❌ ") +
+				styles.AccentStyle.Render(b.Name) +
+				styles.SubtleStyle.Render(" (unreadable)")
+			fmt.Println("  " + errorMsg)
+			continue
+		}
+		// Style the blank listing
+		icon := getMoldIcon(b.Name[strings.LastIndex(b.Name, "/")+1:])
+		blankDisplay := styles.SuccessStyle.Render(icon+" ") +
+			styles.AccentStyle.Render(b.Name) +
+			styles.SubtleStyle.Render(" - "+b.Description)
+		fmt.Println("  " + blankDisplay)
+	}
+
+	if len(found) == 0 {
+		noMoldsMsg := styles.InfoBoxStyle.Render(
+			styles.InfoStyle.Render("ℹ️  No molds found.\n\n") +
+				"Run " + styles.CodeStyle.Render("ailloy cast") + " to set up molds.",
+		)
+		fmt.Println(noMoldsMsg)
+	}
+
+	return nil
+}
+
+// listedBlank is one blank reported by mold list.
+type listedBlank struct {
+	Name        string `json:"name" yaml:"name"` // category/name, e.g. "commands/brainstorm" or "workflows/claude"
+	Path        string `json:"path" yaml:"path"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Workflow    bool   `json:"workflow,omitempty" yaml:"workflow,omitempty"`
+	Unreadable  bool   `json:"unreadable,omitempty" yaml:"unreadable,omitempty"`
+}
+
+// collectListedBlanks walks the installed blank and workflow directories.
+// A blank's description is its leading "# " heading; a workflow's is its
+// name: field.
+func collectListedBlanks(moldDirs, workflowDirs []string) []listedBlank {
+	found := []listedBlank{}
 
 	for _, dir := range moldDirs {
 		if _, err := os.Stat(dir); os.IsNotExist(err) { // #nosec G703 -- CLI tool intentionally accesses user-specified blank directories
@@ -92,60 +162,47 @@ func runListMolds(cmd *cobra.Command, args []string) error {
 		}
 
 		// Walk through subdirectories to find blanks
-		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error { // #nosec G703 -- Intentional directory traversal for blank discovery
+		_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error { // #nosec G703 -- Intentional directory traversal for blank discovery
 			if err != nil {
 				return nil // Skip errors, continue walking
 			}
 
 			// Only process .md files
-			if !d.IsDir() && strings.HasSuffix(path, ".md") {
-				// Get relative path from base dir for category
-				relPath, _ := filepath.Rel(dir, path)
-				pathParts := strings.Split(filepath.Dir(relPath), string(filepath.Separator))
-
-				var category string
-				if len(pathParts) > 0 && pathParts[0] != "." {
-					category = pathParts[0]
-				} else {
-					category = "general"
-				}
-
-				fileName := filepath.Base(path)
-				blankName := strings.TrimSuffix(fileName, ".md")
-
-				// Try to extract the first line as description
-				content, err := os.ReadFile(path) // #nosec G304,G122 -- CLI tool reads user blank files
-				if err != nil {
-					errorMsg := styles.ErrorStyle.Render("❌ ") +
-						styles.AccentStyle.Render(category+"/"+blankName) +
-						styles.SubtleStyle.Render(" (unreadable)")
-					fmt.Println("  " + errorMsg)
-					return nil
-				}
-
-				lines := strings.Split(string(content), "\n")
-				var description string
-				if len(lines) > 0 && strings.HasPrefix(lines[0], "# ") {
-					description = strings.TrimPrefix(lines[0], "# ")
-				} else {
-					description = "Blank"
-				}
+			if d.IsDir() || !strings.HasSuffix(path, ".md") {
+				return nil
+			}
+			// Get relative path from base dir for category
+			relPath, _ := filepath.Rel(dir, path)
+			pathParts := strings.Split(filepath.Dir(relPath), string(filepath.Separator))
+
+			var category string
+			if len(pathParts) > 0 && pathParts[0] != "." {
+				category = pathParts[0]
+			} else {
+				category = "general"
+			}
+			b := listedBlank{
+				Name: category + "/" + strings.TrimSuffix(filepath.Base(path), ".md"),
+				Path: path,
+			}
 
-				// Style the blank listing
-				icon := getMoldIcon(blankName)
-				blankDisplay := styles.SuccessStyle.Render(icon+" ") +
-					styles.AccentStyle.Render(category+"/"+blankName) +
-					styles.SubtleStyle.Render(" - "+description)
-				fmt.Println("  " + blankDisplay)
-				foundMolds = true
+			// Try to extract the first line as description
+			content, err := os.ReadFile(path) // #nosec G304,G122 -- CLI tool reads user blank files
+			if err != nil {
+				b.Unreadable = true
+				found = append(found, b)
+				return nil
 			}
 
+			lines := strings.Split(string(content), "\n")
+			if len(lines) > 0 && strings.HasPrefix(lines[0], "# ") {
+				b.Description = strings.TrimPrefix(lines[0], "# ")
+			} else {
+				b.Description = "Blank"
+			}
+			found = append(found, b)
 			return nil
 		})
-
-		if err != nil {
-			continue
-		}
 	}
 
 	// List workflow blanks
@@ -154,65 +211,61 @@ func runListMolds(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
 			if err != nil {
 				return nil
 			}
+			if d.IsDir() || !strings.HasSuffix(path, ".yml") {
+				return nil
+			}
 
-			if !d.IsDir() && strings.HasSuffix(path, ".yml") {
-				fileName := filepath.Base(path)
-				blankName := strings.TrimSuffix(fileName, ".yml")
-
-				// Extract the workflow name from the YAML
-				content, err := os.ReadFile(path) // #nosec G304,G122 -- CLI tool reads user workflow files
-				if err != nil {
-					return nil
-				}
+			// Extract the workflow name from the YAML
+			content, err := os.ReadFile(path) // #nosec G304,G122 -- CLI tool reads user workflow files
+			if err != nil {
+				return nil
+			}
 
-				var description string
-				for _, line := range strings.Split(string(content), "\n") {
-					if strings.HasPrefix(line, "name:") {
-						description = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
-						break
-					}
+			var description string
+			for _, line := range strings.Split(string(content), "\n") {
+				if strings.HasPrefix(line, "name:") {
+					description = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
+					break
 				}
-				if description == "" {
-					description = "GitHub Actions workflow"
-				}
-
-				icon := getMoldIcon(blankName)
-				blankDisplay := styles.SuccessStyle.Render(icon+" ") +
-					styles.AccentStyle.Render("workflows/"+blankName) +
-					styles.SubtleStyle.Render(" - "+description)
-				fmt.Println("  " + blankDisplay)
-				foundMolds = true
 			}
-
+			if description == "" {
+				description = "GitHub Actions workflow"
+			}
+			found = append(found, listedBlank{
+				Name:        "workflows/" + strings.TrimSuffix(filepath.Base(path), ".yml"),
+				Path:        path,
+				Description: description,
+				Workflow:    true,
+			})
 			return nil
 		})
-
-		if err != nil {
-			continue
-		}
-	}
-
-	if !foundMolds {
-		noMoldsMsg := styles.InfoBoxStyle.Render(
-			styles.InfoStyle.Render("ℹ️  No molds found.\n\n") +
-				"Run " + styles.CodeStyle.Render("ailloy cast") + " to set up molds.",
-		)
-		fmt.Println(noMoldsMsg)
 	}
+	return found
+}
 
-	return nil
+// shownMold is mold show's structured output.
+type shownMold struct {
+	Name    string `json:"name" yaml:"name"`
+	Path    string `json:"path" yaml:"path"`
+	Content string `json:"content" yaml:"content"`
 }
 
 func runShowMold(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(moldShowOutput); err != nil {
+		return err
+	}
 	moldName := args[0]
 
 	// Find mold file
 	moldPath, err := findMold(moldName)
 	if err != nil {
+		if moldShowOutput != "" {
+			return err
+		}
 		errorMsg := styles.ErrorBoxStyle.Render(
 			styles.ErrorStyle.Render("❌ Mold not found: ") +
 				styles.CodeStyle.Render(moldName) + "\n\n" +
@@ -227,6 +280,9 @@ func runShowMold(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to read mold: %w", err)
 	}
+	if moldShowOutput != "" {
+		return writeStructured(cmd.OutOrStdout(), moldShowOutput, shownMold{Name: moldName, Path: moldPath, Content: string(content)})
+	}
 
 	// Header with small fox emoji
 	icon := getMoldIcon(moldName)
@@ -364,15 +420,116 @@ func runGetMold(_ *cobra.Command, args []string) error {
 	return nil
 }
 
+// listedInstalledBlank is one file in mold list --installed's structured
+// output.
+type listedInstalledBlank struct {
+	Dest    string `json:"dest" yaml:"dest"`
+	Mold    string `json:"mold" yaml:"mold"`
+	Source  string `json:"source,omitempty" yaml:"source,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	SrcPath string `json:"srcPath" yaml:"srcPath"`
+	Origin  string `json:"origin,omitempty" yaml:"origin,omitempty"`
+	State   string `json:"state" yaml:"state"` // "cast", "modified", or "missing"
+}
+
+// installedBlankState reports whether f is still on disk as cast.
+func installedBlankState(f installedBlank) string {
+	switch sum, err := hashFile(filepath.FromSlash(f.Dest)); {
+	case err != nil:
+		return fileMissing
+	case f.SHA256 != "" && sum != f.SHA256:
+		return fileModified
+	}
+	return "cast"
+}
+
+// runListInstalledBlanks prints the per-file provenance recorded in
+// .ailloy/state.yaml, grouped by mold.
+func runListInstalledBlanks(w io.Writer) error {
+	state, err := readInstallState(installStatePath)
+	switch {
+	case foundry.IsSchemaTooNew(err):
+		fmt.Fprintln(os.Stderr, styles.WarningStyle.Render("⚠️  ")+err.Error())
+	case err != nil:
+		return fmt.Errorf("reading %s: %w", installStatePath, err)
+	}
+	if moldListOutput != "" {
+		out := []listedInstalledBlank{}
+		if state != nil {
+			for _, f := range state.Files {
+				out = append(out, listedInstalledBlank{
+					Dest:    f.Dest,
+					Mold:    f.Mold,
+					Source:  f.Source,
+					Version: f.Version,
+					SrcPath: f.SrcPath,
+					Origin:  f.Origin,
+					State:   installedBlankState(f),
+				})
+			}
+		}
+		return writeStructured(w, moldListOutput, out)
+	}
+	if state == nil || len(state.Files) == 0 {
+		fmt.Println(styles.InfoStyle.Render("No installed files recorded in ") + styles.CodeStyle.Render(installStatePath))
+		fmt.Println(styles.SubtleStyle.Render("Files cast before provenance was recorded don't appear; re-cast the mold to backfill."))
+		return nil
+	}
+
+	fmt.Println(styles.HeaderStyle.Render("Installed Blanks"))
+	fmt.Println()
+
+	var order []string
+	groups := map[string][]installedBlank{}
+	for _, f := range state.Files {
+		key := f.Mold + "\x00" + f.Source
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], f)
+	}
+	sort.Strings(order)
+
+	for _, key := range order {
+		files := groups[key]
+		head := styles.AccentStyle.Render(files[0].Mold)
+		if files[0].Version != "" {
+			head += " " + styles.CodeStyle.Render(files[0].Version)
+		}
+		source := files[0].Source
+		if source == "" {
+			source = "local"
+		}
+		fmt.Println("  " + head + styles.SubtleStyle.Render("  "+source))
+		for _, f := range files {
+			from := f.SrcPath
+			if f.Origin != "" {
+				from = "ore/" + f.Origin + ": " + from
+			}
+			line := "    " + styles.CodeStyle.Render(f.Dest) + styles.SubtleStyle.Render(" ← "+from)
+			switch installedBlankState(f) {
+			case fileMissing:
+				line += " " + styles.ErrorStyle.Render("(missing)")
+			case fileModified:
+				line += " " + styles.WarningStyle.Render("(modified)")
+			}
+			fmt.Println(line)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
 // loadInstalledDirs reads .ailloy/state.yaml to find where blanks are installed.
 // Falls back to empty lists when no state file exists.
 func loadInstalledDirs() (blankDirs, workflowDirs []string) {
-	data, err := os.ReadFile(".ailloy/state.yaml") // #nosec G304 -- fixed path in project directory
-	if err == nil {
-		var state installState
-		if yaml.Unmarshal(data, &state) == nil && len(state.BlankDirs) > 0 {
-			return state.BlankDirs, state.WorkflowDirs
-		}
+	state, err := readInstallState(installStatePath)
+	if foundry.IsSchemaTooNew(err) {
+		// Listing is read-only, so a newer state file is still usable.
+		fmt.Fprintln(os.Stderr, styles.WarningStyle.Render("⚠️  ")+err.Error())
+	}
+	if state != nil && len(state.BlankDirs) > 0 {
+		return state.BlankDirs, state.WorkflowDirs
 	}
 	// Fallback: default workflow dir only
 	return nil, []string{".github/workflows"}
@@ -0,0 +1,324 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var moldDevCmd = &cobra.Command{
+	Use:   "dev [mold-dir]",
+	Short: "Temper and preview-render a mold, optionally on every save",
+	Long: `Run temper on a mold and render its blanks into a preview directory.
+
+With --watch the mold directory is polled for changes; every save re-runs
+temper and re-renders, writing only the outputs whose content changed and
+removing outputs that are no longer produced. Diagnostics are reported
+incrementally: new ones in full, with counts for those resolved or still
+outstanding since the previous pass. Press Ctrl+C to stop.
+
+Flux layering matches forge (flux.yaml, ore overlays, -f, --set), and
+the preview holds what cast would write: provenance headers, AGENTS.md
+sections, and _ailloy pinned as in 'ailloy mold test'. The preview
+directory defaults to .ailloy/preview inside the mold, which the
+scaffolded .gitignore already excludes.
+
+Example:
+  ailloy mold dev --watch
+  ailloy mold dev ./my-mold --watch --set project.organization=acme
+  ailloy mold dev -o /tmp/preview`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMoldDev,
+}
+
+var (
+	moldDevWatch     bool
+	moldDevOutput    string
+	moldDevInterval  time.Duration
+	moldDevSetValues []string
+	moldDevValFiles  []string
+)
+
+// moldDevPreviewDir is the default preview location, relative to the mold.
+const moldDevPreviewDir = ".ailloy/preview"
+
+func init() {
+	moldCmd.AddCommand(moldDevCmd)
+
+	moldDevCmd.Flags().BoolVarP(&moldDevWatch, "watch", "w", false, "re-run on every change until interrupted")
+	moldDevCmd.Flags().StringVarP(&moldDevOutput, "output", "o", "", "preview directory (default <mold-dir>/"+moldDevPreviewDir+")")
+	moldDevCmd.Flags().DurationVar(&moldDevInterval, "interval", 500*time.Millisecond, "how often --watch polls for changes")
+	moldDevCmd.Flags().StringArrayVar(&moldDevSetValues, "set", nil, "set flux values (key=value)")
+	_ = moldDevCmd.RegisterFlagCompletionFunc("set", completeSetFlag(0))
+	moldDevCmd.Flags().StringArrayVarP(&moldDevValFiles, "values", "f", nil, "flux value files (can be repeated, later files override earlier)")
+}
+
+func runMoldDev(_ *cobra.Command, args []string) error {
+	moldDir := "."
+	if len(args) > 0 {
+		moldDir = args[0]
+	}
+	if _, err := os.Stat(filepath.Join(moldDir, "mold.yaml")); err != nil {
+		return fmt.Errorf("%s is not a mold directory (no mold.yaml)", moldDir)
+	}
+	outDir := moldDevOutput
+	if outDir == "" {
+		outDir = filepath.Join(moldDir, moldDevPreviewDir)
+	}
+	if moldDevWatch && moldDevInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	dev := &moldDevSession{moldDir: moldDir, outDir: outDir}
+	fmt.Println(styles.WorkingBanner("Previewing " + moldDir + " → " + outDir))
+	fmt.Println()
+	hasErrors := dev.pass()
+	if !moldDevWatch {
+		if hasErrors {
+			return fmt.Errorf("mold dev: temper or render errors found")
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Println(styles.SubtleStyle.Render("Watching for changes (Ctrl+C to stop)..."))
+	return dev.watch(ctx, moldDevInterval)
+}
+
+// moldDevSession carries state between passes so each pass can report
+// only what changed: rendered content by destination and the diagnostics
+// seen last time.
+type moldDevSession struct {
+	moldDir  string
+	outDir   string
+	rendered map[string]string
+	diags    map[string]bool
+	snapshot map[string]devFileStamp
+}
+
+// devFileStamp is what the watcher compares to detect a change.
+type devFileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watch polls the mold tree and runs a pass whenever it changes. A change
+// is acted on once the tree has been stable for one interval so an editor's
+// multi-step save triggers a single pass.
+func (d *moldDevSession) watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			fmt.Println(styles.SubtleStyle.Render("Stopped watching."))
+			return nil
+		case <-ticker.C:
+			changed, err := d.scan()
+			if err != nil {
+				return err
+			}
+			switch {
+			case changed:
+				pending = true
+			case pending:
+				pending = false
+				fmt.Println()
+				fmt.Println(styles.InfoStyle.Render(time.Now().Format("15:04:05") + " change detected"))
+				d.pass()
+			}
+		}
+	}
+}
+
+// scan refreshes the snapshot of the mold tree and reports whether any file
+// was added, removed, or modified. The preview directory, .git, and .ailloy
+// are skipped so the session's own output never retriggers it.
+func (d *moldDevSession) scan() (bool, error) {
+	skip, _ := filepath.Abs(d.outDir)
+	next := map[string]devFileStamp{}
+	err := filepath.WalkDir(d.moldDir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if p != d.moldDir && (entry.Name() == ".git" || entry.Name() == ".ailloy") {
+				return filepath.SkipDir
+			}
+			if abs, _ := filepath.Abs(p); abs == skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		next[p] = devFileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("watching %s: %w", d.moldDir, err)
+	}
+
+	changed := d.snapshot != nil && len(next) != len(d.snapshot)
+	if d.snapshot != nil && !changed {
+		for p, s := range next {
+			if prev, ok := d.snapshot[p]; !ok || prev != s {
+				changed = true
+				break
+			}
+		}
+	}
+	d.snapshot = next
+	return changed, nil
+}
+
+// pass runs temper, renders every output, syncs the preview directory, and
+// prints what changed. It returns true when temper or rendering reported
+// errors; those never stop a watch session.
+func (d *moldDevSession) pass() bool {
+	if d.snapshot == nil {
+		_, _ = d.scan()
+	}
+
+	result := temperPackage(os.DirFS(d.moldDir), d.moldDir, true)
+
+	var outputs map[string]string
+	if !result.HasErrors() {
+		var renderDiags []mold.Diagnostic
+		outputs, renderDiags = previewMoldDir(d.moldDir, moldDevValFiles, moldDevSetValues)
+		result.Diagnostics = append(result.Diagnostics, renderDiags...)
+	}
+
+	d.reportDiagnostics(result.Diagnostics)
+	if outputs != nil {
+		written, removed, err := d.sync(outputs)
+		if err != nil {
+			fmt.Println(styles.ErrorStyle.Render("ERROR: ") + err.Error())
+			return true
+		}
+		d.reportOutputs(written, removed)
+	}
+	return result.HasErrors()
+}
+
+// sync writes outputs whose content differs from the previous pass and
+// removes destinations that are no longer produced.
+func (d *moldDevSession) sync(outputs map[string]string) (written, removed []string, err error) {
+	for dest, content := range outputs {
+		if prev, ok := d.rendered[dest]; ok && prev == content {
+			continue
+		}
+		target := filepath.Join(d.outDir, filepath.FromSlash(dest))
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil { // #nosec G301 -- Preview directories need group read access
+			return nil, nil, fmt.Errorf("creating directory for %s: %w", dest, err)
+		}
+		//#nosec G306 -- Rendered blanks need to be readable
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return nil, nil, fmt.Errorf("writing %s: %w", dest, err)
+		}
+		written = append(written, dest)
+	}
+	for dest := range d.rendered {
+		if _, ok := outputs[dest]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.outDir, filepath.FromSlash(dest))); err != nil && !os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("removing %s: %w", dest, err)
+		}
+		removed = append(removed, dest)
+	}
+	d.rendered = outputs
+	sort.Strings(written)
+	sort.Strings(removed)
+	return written, removed, nil
+}
+
+// reportDiagnostics prints diagnostics not seen in the previous pass and
+// summarises the rest, so a long watch session stays readable.
+func (d *moldDevSession) reportDiagnostics(diags []mold.Diagnostic) {
+	seen := make(map[string]bool, len(diags))
+	unchanged, errCount, warnCount := 0, 0, 0
+	for _, diag := range diags {
+		switch diag.Severity {
+		case mold.SeverityError:
+			errCount++
+		case mold.SeverityWarning:
+			warnCount++
+		default:
+			continue
+		}
+		key := fmt.Sprintf("%d|%s|%s", diag.Severity, diag.File, diag.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if d.diags[key] {
+			unchanged++
+			continue
+		}
+		loc := ""
+		if diag.File != "" {
+			loc = styles.SubtleStyle.Render(diag.File + ": ")
+		}
+		if diag.Severity == mold.SeverityError {
+			fmt.Println(styles.ErrorStyle.Render("ERROR: ") + loc + diag.Message)
+		} else {
+			fmt.Println(styles.WarningStyle.Render("WARNING: ") + loc + diag.Message)
+		}
+	}
+	resolved := 0
+	for key := range d.diags {
+		if !seen[key] {
+			resolved++
+		}
+	}
+	d.diags = seen
+
+	var notes []string
+	if resolved > 0 {
+		notes = append(notes, fmt.Sprintf("%d resolved", resolved))
+	}
+	if unchanged > 0 {
+		notes = append(notes, fmt.Sprintf("%d unchanged", unchanged))
+	}
+	summary := fmt.Sprintf("temper: %d error(s), %d warning(s)", errCount, warnCount)
+	if len(notes) > 0 {
+		summary += styles.SubtleStyle.Render(" (" + strings.Join(notes, ", ") + ")")
+	}
+	if errCount > 0 {
+		fmt.Println(styles.ErrorStyle.Render(summary))
+	} else {
+		fmt.Println(styles.SuccessStyle.Render(summary))
+	}
+}
+
+func (d *moldDevSession) reportOutputs(written, removed []string) {
+	if len(written) == 0 && len(removed) == 0 {
+		fmt.Println(styles.SubtleStyle.Render("render: no output changes"))
+		return
+	}
+	for _, dest := range written {
+		fmt.Println(styles.SuccessStyle.Render("  rendered ") + styles.CodeStyle.Render(dest))
+	}
+	for _, dest := range removed {
+		fmt.Println(styles.WarningStyle.Render("  removed  ") + styles.CodeStyle.Render(dest))
+	}
+}
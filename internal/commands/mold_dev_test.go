@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestMoldDev_PassSyncsOnlyChangedOutputs(t *testing.T) {
+	moldDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(moldDir, "commands"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: dev-test\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\nproject_name: Atlas\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "# {{project_name}}\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "other.md"), "# other\n")
+
+	outDir := filepath.Join(moldDir, moldDevPreviewDir)
+	dev := &moldDevSession{moldDir: moldDir, outDir: outDir}
+	if dev.pass() {
+		t.Fatal("first pass reported errors")
+	}
+	hello := filepath.Join(outDir, ".claude", "commands", "hello.md")
+	other := filepath.Join(outDir, ".claude", "commands", "other.md")
+	// The preview is what cast writes, provenance header included.
+	stamped := func(body string) string {
+		return string(mold.StampProvenance(".claude/commands/hello.md", []byte(body), mold.Provenance{Mold: "dev-test", Version: "1.0.0"}))
+	}
+	if got, want := readPreview(t, hello), stamped("# Atlas\n"); got != want {
+		t.Errorf("hello = %q, want %q", got, want)
+	}
+
+	// The preview directory lives inside the mold but must not count as a change.
+	if changed, err := dev.scan(); err != nil || changed {
+		t.Fatalf("scan after pass: changed=%v err=%v", changed, err)
+	}
+
+	// Edit one blank and delete the other: only the edit is rewritten and the
+	// deleted blank's output is removed.
+	otherInfo, err := os.Stat(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "# {{project_name}} v2\n")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(moldDir, "commands", "hello.md"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := dev.scan(); err != nil || !changed {
+		t.Fatalf("scan after edit: changed=%v err=%v", changed, err)
+	}
+	if dev.pass() {
+		t.Fatal("second pass reported errors")
+	}
+	if got, want := readPreview(t, hello), stamped("# Atlas v2\n"); got != want {
+		t.Errorf("hello after edit = %q, want %q", got, want)
+	}
+	if info, err := os.Stat(other); err != nil || !info.ModTime().Equal(otherInfo.ModTime()) {
+		t.Errorf("unchanged output was rewritten or lost: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(moldDir, "commands", "other.md")); err != nil {
+		t.Fatal(err)
+	}
+	dev.pass()
+	if _, err := os.Stat(other); !os.IsNotExist(err) {
+		t.Errorf("output of deleted blank should be removed, stat err = %v", err)
+	}
+}
+
+func TestMoldDev_RenderErrorIsReportedNotFatal(t *testing.T) {
+	moldDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(moldDir, "commands"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: dev-test\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "good.md"), "# good\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "bad.md"), "{{ include \"missing-ingot\" }}\n")
+
+	outputs, diags := previewMoldDir(moldDir, nil, nil)
+	if len(diags) == 0 {
+		t.Fatal("expected a diagnostic for the broken blank")
+	}
+	if diags[0].File != "commands/bad.md" {
+		t.Errorf("diagnostic file = %q, want commands/bad.md", diags[0].File)
+	}
+	if _, ok := outputs[".claude/commands/good.md"]; !ok {
+		t.Errorf("good blank should still render, got %v", outputs)
+	}
+}
+
+func readPreview(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
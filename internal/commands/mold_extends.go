@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// ComposeMoldReader applies the `extends:` chain of the mold behind reader
+// (see mold.ComposeExtends). source is the mold's remote reference, or ""
+// for local and embedded molds; opts resolve remote parents.
+func ComposeMoldReader(reader *blanks.MoldReader, source string, opts ...foundry.ResolveOption) (*blanks.MoldReader, error) {
+	if reader == nil {
+		return nil, nil
+	}
+	id := source
+	if id == "" && reader.Root() != "" {
+		abs, err := filepath.Abs(reader.Root())
+		if err != nil {
+			return nil, err
+		}
+		id = abs
+	}
+	return reader.Compose(id, moldExtendsResolver(opts...))
+}
+
+// moldExtendsResolver resolves `extends:` references: remote references
+// through the foundry cache, local paths relative to the extending mold's
+// directory. Like local-path dependencies, local parents are refused for
+// remote and embedded molds, whose identity isn't a directory.
+func moldExtendsResolver(opts ...foundry.ResolveOption) mold.ExtendsResolver {
+	return func(ref, from string) (fs.FS, string, error) {
+		if foundry.IsRemoteReference(ref) {
+			fsys, result, err := foundry.ResolveWithMetadata(ref, opts...)
+			if err != nil {
+				return nil, "", err
+			}
+			return fsys, result.Ref.OverrideKey(), nil
+		}
+		if !filepath.IsAbs(from) {
+			return nil, "", fmt.Errorf("%s is not a local mold and cannot extend the local path %s", from, ref)
+		}
+		dir := ref
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(from, ref)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, "", err
+		}
+		if !info.IsDir() {
+			return nil, "", fmt.Errorf("%s is not a directory", dir)
+		}
+		return os.DirFS(dir), filepath.Clean(dir), nil
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestComposeMoldReader_LocalParent(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(rel, content string) {
+		t.Helper()
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("base/mold.yaml", "apiVersion: v1\nkind: mold\nname: base\nversion: 1.0.0\n")
+	writeFile("base/commands/review.md", "review")
+	writeFile("child/mold.yaml", "apiVersion: v1\nkind: mold\nname: child\nversion: 1.0.0\nextends: ../base\n")
+
+	reader, err := blanks.NewMoldReaderFromPath(filepath.Join(dir, "child"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	composed, err := ComposeMoldReader(reader, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if composed.Root() != reader.Root() {
+		t.Errorf("Root() = %q, want %q", composed.Root(), reader.Root())
+	}
+	if _, err := fs.ReadFile(composed.FS(), "commands/review.md"); err != nil {
+		t.Errorf("inherited blank not readable: %v", err)
+	}
+
+	// A remote mold may not extend a local path.
+	if _, err := ComposeMoldReader(reader, "github.com/acme/child"); err == nil || !strings.Contains(err.Error(), "not a local mold") {
+		t.Errorf("err = %v, want a refusal of the local parent", err)
+	}
+}
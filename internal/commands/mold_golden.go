@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var moldTestCmd = &cobra.Command{
+	Use:   "test [mold-dir]",
+	Short: "Render golden-file test cases and diff them against expected output",
+	Long: `Run a mold's golden-file tests.
+
+Each case is a directory under tests/ with an optional flux.yaml and an
+expected/ tree of rendered outputs keyed by destination path:
+
+  tests/
+    default/
+      expected/.claude/commands/hello.md
+    acme/
+      flux.yaml
+      expected/.claude/commands/hello.md
+
+A case renders the mold the way cast writes it, with forge's flux
+layering plus its flux.yaml (layered like a -f values file), and compares
+every output against expected/. Outputs carry their provenance headers and
+AGENTS.md sections, and _ailloy is pinned (version dev, timestamp
+2000-01-01T00:00:00Z, branch main, an all-zero commit). Missing, unexpected, and changed files fail the case; changed
+files are shown as a line diff. --update regenerates expected/ from the
+current render instead.
+
+The tests/ directory is reserved and never cast as mold content.
+
+Example:
+  ailloy mold test
+  ailloy mold test ./my-mold --case acme
+  ailloy mold test --update`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMoldTest,
+}
+
+var (
+	moldTestUpdate bool
+	moldTestCases  []string
+)
+
+func init() {
+	moldCmd.AddCommand(moldTestCmd)
+
+	moldTestCmd.Flags().BoolVar(&moldTestUpdate, "update", false, "regenerate expected/ outputs from the current render")
+	moldTestCmd.Flags().StringArrayVar(&moldTestCases, "case", nil, "only run the named case (can be repeated)")
+}
+
+func runMoldTest(_ *cobra.Command, args []string) error {
+	moldDir := "."
+	if len(args) > 0 {
+		moldDir = args[0]
+	}
+	if _, err := os.Stat(filepath.Join(moldDir, "mold.yaml")); err != nil {
+		return fmt.Errorf("%s is not a mold directory (no mold.yaml)", moldDir)
+	}
+
+	cases, err := mold.DiscoverGoldenCases(moldDir)
+	if err != nil {
+		return err
+	}
+	cases, err = filterGoldenCases(cases, moldTestCases)
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		fmt.Println(styles.SubtleStyle.Render("No test cases found under " + filepath.Join(moldDir, mold.GoldenTestsDir) + "/."))
+		return nil
+	}
+
+	failed := 0
+	for _, c := range cases {
+		if !runGoldenCase(moldDir, c) {
+			failed++
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case failed > 0:
+		fmt.Println(styles.ErrorStyle.Render(fmt.Sprintf("%d of %d case(s) failed", failed, len(cases))))
+		if !moldTestUpdate {
+			fmt.Println(styles.SubtleStyle.Render("If the new output is intended, run 'ailloy mold test --update'."))
+		}
+		return validationError(fmt.Errorf("mold test: %d case(s) failed", failed))
+	case moldTestUpdate:
+		fmt.Println(styles.SuccessStyle.Render(fmt.Sprintf("Updated %d case(s)", len(cases))))
+	default:
+		fmt.Println(styles.SuccessStyle.Render(fmt.Sprintf("All %d case(s) passed", len(cases))))
+	}
+	return nil
+}
+
+// runGoldenCase renders one case and either compares it against or
+// rewrites its expected/ tree. It reports the outcome and returns false
+// when the case failed.
+func runGoldenCase(moldDir string, c mold.GoldenCase) bool {
+	var valFiles []string
+	if c.FluxFile != "" {
+		valFiles = []string{c.FluxFile}
+	}
+	outputs, diags := previewMoldDir(moldDir, valFiles, nil)
+
+	var errs []mold.Diagnostic
+	for _, d := range diags {
+		if d.Severity == mold.SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	if len(errs) > 0 {
+		fmt.Println(styles.ErrorStyle.Render("FAIL ") + c.Name)
+		for _, d := range errs {
+			loc := ""
+			if d.File != "" {
+				loc = d.File + ": "
+			}
+			fmt.Println(styles.SubtleStyle.Render("    " + loc + d.Message))
+		}
+		return false
+	}
+
+	if moldTestUpdate {
+		if err := mold.WriteGolden(c.ExpectedDir, outputs); err != nil {
+			fmt.Println(styles.ErrorStyle.Render("FAIL ") + c.Name)
+			fmt.Println(styles.SubtleStyle.Render("    " + err.Error()))
+			return false
+		}
+		fmt.Println(styles.SuccessStyle.Render("UPDATED ") + c.Name + styles.SubtleStyle.Render(fmt.Sprintf(" (%d file(s))", len(outputs))))
+		return true
+	}
+
+	mismatches, err := mold.CompareGolden(c.ExpectedDir, outputs)
+	if err != nil {
+		fmt.Println(styles.ErrorStyle.Render("FAIL ") + c.Name)
+		fmt.Println(styles.SubtleStyle.Render("    " + err.Error()))
+		return false
+	}
+	if len(mismatches) == 0 {
+		fmt.Println(styles.SuccessStyle.Render("PASS ") + c.Name + styles.SubtleStyle.Render(fmt.Sprintf(" (%d file(s))", len(outputs))))
+		return true
+	}
+
+	fmt.Println(styles.ErrorStyle.Render("FAIL ") + c.Name)
+	for _, m := range mismatches {
+		switch m.Kind {
+		case "missing":
+			fmt.Println(styles.WarningStyle.Render("    missing    ") + styles.CodeStyle.Render(m.Path) + styles.SubtleStyle.Render(" (expected but not rendered)"))
+		case "unexpected":
+			fmt.Println(styles.WarningStyle.Render("    unexpected ") + styles.CodeStyle.Render(m.Path) + styles.SubtleStyle.Render(" (rendered but has no golden)"))
+		default:
+			fmt.Println(styles.WarningStyle.Render("    changed    ") + styles.CodeStyle.Render(m.Path))
+			for _, line := range strings.Split(strings.TrimSuffix(m.Diff, "\n"), "\n") {
+				fmt.Println("      " + styleDiffLine(line))
+			}
+		}
+	}
+	return false
+}
+
+func styleDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return styles.SuccessStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return styles.ErrorStyle.Render(line)
+	default:
+		return styles.SubtleStyle.Render(line)
+	}
+}
+
+// filterGoldenCases keeps only the named cases, erroring on unknown names.
+func filterGoldenCases(cases []mold.GoldenCase, names []string) ([]mold.GoldenCase, error) {
+	if len(names) == 0 {
+		return cases, nil
+	}
+	var out []mold.GoldenCase
+	for _, name := range names {
+		found := false
+		for _, c := range cases {
+			if c.Name == name {
+				out = append(out, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("test case %q not found under %s/", name, mold.GoldenTestsDir)
+		}
+	}
+	return out, nil
+}
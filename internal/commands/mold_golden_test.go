@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestMoldTest_UpdateThenPassThenFail(t *testing.T) {
+	moldDir := t.TempDir()
+	for _, dir := range []string{"commands", "tests/default", "tests/acme"} {
+		if err := os.MkdirAll(filepath.Join(moldDir, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: golden-test\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\n  AGENTS.md: AGENTS.md\norg: default\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "# Hello {{org}}\n")
+	mustWrite(t, filepath.Join(moldDir, "AGENTS.md"), "Cast by {{ ._ailloy.mold.name }} at {{ ._ailloy.timestamp }}\n")
+	mustWrite(t, filepath.Join(moldDir, "tests", "acme", "flux.yaml"), "org: acme\n")
+	t.Cleanup(func() { moldTestUpdate, moldTestCases = false, nil })
+
+	// No goldens yet: every rendered file is unexpected.
+	if err := runMoldTest(nil, []string{moldDir}); err == nil {
+		t.Fatal("expected failure before goldens exist")
+	}
+
+	moldTestUpdate = true
+	if err := runMoldTest(nil, []string{moldDir}); err != nil {
+		t.Fatalf("--update: %v", err)
+	}
+	golden := filepath.Join(moldDir, "tests", "acme", "expected", ".claude", "commands", "hello.md")
+	data, err := os.ReadFile(golden) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatalf("reading golden: %v", err)
+	}
+	// Goldens hold what cast writes: the provenance header, and AGENTS.md
+	// as the mold's section with the pinned cast context.
+	want := mold.StampProvenance(".claude/commands/hello.md", []byte("# Hello acme\n"), mold.Provenance{Mold: "golden-test", Version: "1.0.0"})
+	if string(data) != string(want) {
+		t.Errorf("acme golden = %q, want %q", data, want)
+	}
+	agents, err := os.ReadFile(filepath.Join(moldDir, "tests", "acme", "expected", "AGENTS.md")) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatalf("reading AGENTS.md golden: %v", err)
+	}
+	if want := "<!-- ailloy:mold=golden-test:start -->\nCast by golden-test at 2000-01-01T00:00:00Z\n<!-- ailloy:mold=golden-test:end -->\n"; string(agents) != want {
+		t.Errorf("AGENTS.md golden = %q, want %q", agents, want)
+	}
+
+	moldTestUpdate = false
+	if err := runMoldTest(nil, []string{moldDir}); err != nil {
+		t.Fatalf("goldens should pass: %v", err)
+	}
+
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "# Hi {{org}}\n")
+	moldTestCases = []string{"acme"}
+	err = runMoldTest(nil, []string{moldDir})
+	if err == nil || !strings.Contains(err.Error(), "1 case(s) failed") {
+		t.Fatalf("expected one failed case, got %v", err)
+	}
+
+	moldTestCases = []string{"nope"}
+	if err := runMoldTest(nil, []string{moldDir}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected unknown case error, got %v", err)
+	}
+}
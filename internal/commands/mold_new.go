@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/huh"
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
 )
@@ -16,25 +18,50 @@ var newMoldCmd = &cobra.Command{
 	Short:   "Scaffold a new mold directory",
 	Long: `Scaffold a new mold directory with boilerplate files (alias: create).
 
-Creates a ready-to-use mold with mold.yaml, flux.yaml, sample blanks,
-and an optional AGENTS.md for tool-agnostic agent instructions.
+Creates a ready-to-use mold with mold.yaml, flux.yaml, flux.schema.yaml,
+sample command and skill blanks, a .gitignore, and an optional AGENTS.md for
+tool-agnostic agent instructions. --with-workflow adds a Claude Code GitHub
+Actions workflow whose action version, model, triggers, and permissions are
+flux variables.
+
+Use -i to answer the same choices interactively; without a terminal (or
+with --non-interactive) -i keeps the flags' answers.
 
 Example:
   ailloy mold new my-mold
   ailloy mold new my-mold -o /tmp
-  ailloy mold new my-mold --no-agents`,
+  ailloy mold new my-mold --description "Team review commands" --author "Jane Doe"
+  ailloy mold new my-mold --with-workflow --no-agents
+  ailloy mold new my-mold -i`,
 	Args: cobra.ExactArgs(1),
 	RunE: runNewMold,
 }
 
 var (
-	newMoldOutput   string
-	newMoldNoAgents bool
+	newMoldOutput       string
+	newMoldNoAgents     bool
+	newMoldDescription  string
+	newMoldAuthor       string
+	newMoldWithWorkflow bool
+	newMoldInteractive  bool
 )
 
 func init() {
 	newMoldCmd.Flags().StringVarP(&newMoldOutput, "output", "o", ".", "parent directory to create the mold in")
 	newMoldCmd.Flags().BoolVar(&newMoldNoAgents, "no-agents", false, "skip generating AGENTS.md")
+	newMoldCmd.Flags().StringVar(&newMoldDescription, "description", "", "mold description for mold.yaml")
+	newMoldCmd.Flags().StringVar(&newMoldAuthor, "author", "", "author name for mold.yaml")
+	newMoldCmd.Flags().BoolVar(&newMoldWithWorkflow, "with-workflow", false, "include a parameterized Claude Code GitHub Actions workflow")
+	newMoldCmd.Flags().BoolVarP(&newMoldInteractive, "interactive", "i", false, "choose scaffold options interactively")
+}
+
+// moldScaffold holds the choices that shape a scaffolded mold.
+type moldScaffold struct {
+	Name        string
+	Description string
+	Author      string
+	Agents      bool
+	Workflow    bool
 }
 
 func runNewMold(_ *cobra.Command, args []string) error {
@@ -52,73 +79,210 @@ func runNewMold(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("directory %s already exists", moldDir)
 	}
 
-	fmt.Println(styles.WorkingBanner("Scaffolding new mold..."))
-	fmt.Println()
-
-	// Create directory structure
-	dirs := []string{
-		moldDir,
-		filepath.Join(moldDir, "commands"),
-		filepath.Join(moldDir, "skills"),
+	opts := moldScaffold{
+		Name:        name,
+		Description: newMoldDescription,
+		Author:      newMoldAuthor,
+		Agents:      !newMoldNoAgents,
+		Workflow:    newMoldWithWorkflow,
 	}
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0750); err != nil { // #nosec G301 -- Mold directories need group read access
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	if newMoldInteractive && isInteractive() {
+		if err := promptMoldScaffold(&opts); err != nil {
+			return err
 		}
 	}
 
-	// Write scaffold files
-	files := map[string]string{
-		"mold.yaml":         scaffoldMoldYaml(name),
-		"flux.yaml":         scaffoldFluxYaml,
-		"commands/hello.md": scaffoldCommandBlank,
-		"skills/helper.md":  scaffoldSkillBlank,
-	}
-
-	if !newMoldNoAgents {
-		files["AGENTS.md"] = scaffoldAgentsMd
-	}
+	logging.Decor(styles.WorkingBanner("Scaffolding new mold..."), "")
 
-	for relPath, content := range files {
-		dest := filepath.Join(moldDir, relPath)
+	files := opts.files()
+	for _, f := range files {
+		dest := filepath.Join(moldDir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0750); err != nil { // #nosec G301 -- Mold directories need group read access
+			return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dest), err)
+		}
 		//#nosec G306 -- Mold files need to be readable
-		if err := os.WriteFile(dest, []byte(content), 0644); err != nil {
+		if err := os.WriteFile(dest, []byte(f.content), 0644); err != nil {
 			return fmt.Errorf("failed to write %s: %w", dest, err)
 		}
-		fmt.Println(styles.SuccessStyle.Render("  created ") + styles.CodeStyle.Render(relPath))
+		logging.Say(styles.SuccessStyle.Render("  created ")+styles.CodeStyle.Render(f.path), "created", "file", filepath.ToSlash(filepath.Join(moldDir, f.path)))
 	}
 
-	fmt.Println()
-	fmt.Println(styles.SuccessBanner("Mold scaffolded at " + moldDir))
-	fmt.Println()
+	logging.Decor("", styles.SuccessBanner("Mold scaffolded at "+moldDir), "")
 
+	castCmd := "ailloy cast " + moldDir
+	if opts.Workflow {
+		castCmd += " --with-workflows"
+	}
 	nextSteps := styles.InfoStyle.Render("Next steps:\n\n") +
 		"  1. Edit " + styles.CodeStyle.Render("mold.yaml") + " to set description and author\n" +
 		"  2. Add your blanks to " + styles.CodeStyle.Render("commands/") + " and " + styles.CodeStyle.Render("skills/") + "\n" +
-		"  3. Validate with " + styles.CodeStyle.Render("ailloy temper "+moldDir) + "\n" +
-		"  4. Preview with " + styles.CodeStyle.Render("ailloy forge "+moldDir) + "\n" +
-		"  5. Install with " + styles.CodeStyle.Render("ailloy cast "+moldDir)
-	fmt.Println(styles.InfoBoxStyle.Render(nextSteps))
+		"  3. Declare flux in " + styles.CodeStyle.Render("flux.schema.yaml") + " and set defaults in " + styles.CodeStyle.Render("flux.yaml") + "\n" +
+		"  4. Validate with " + styles.CodeStyle.Render("ailloy temper "+moldDir) + "\n" +
+		"  5. Preview with " + styles.CodeStyle.Render("ailloy forge "+moldDir) + "\n" +
+		"  6. Install with " + styles.CodeStyle.Render(castCmd)
+	logging.Decor(styles.InfoBoxStyle.Render(nextSteps))
+
+	return nil
+}
 
+// promptMoldScaffold asks for the scaffold choices, pre-filled from flags.
+func promptMoldScaffold(opts *moldScaffold) error {
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Description").
+				Description("One line describing what the mold provides").
+				Value(&opts.Description),
+			huh.NewInput().
+				Title("Author").
+				Value(&opts.Author),
+			huh.NewConfirm().
+				Title("Include AGENTS.md?").
+				Description("Tool-agnostic agent instructions installed at the project root.").
+				Affirmative("Yes").
+				Negative("No").
+				Value(&opts.Agents),
+			huh.NewConfirm().
+				Title("Include a Claude Code GitHub workflow?").
+				Description("Installed to .github/workflows with cast --with-workflows.").
+				Affirmative("Yes").
+				Negative("No").
+				Value(&opts.Workflow),
+		).Title("New mold: " + opts.Name),
+	).WithTheme(ailloyTheme())
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("prompt failed: %w", err)
+	}
 	return nil
 }
 
-func scaffoldMoldYaml(name string) string {
+type scaffoldFile struct {
+	path    string
+	content string
+}
+
+// files returns the scaffold's files in the order they are written.
+func (o moldScaffold) files() []scaffoldFile {
+	files := []scaffoldFile{
+		{"mold.yaml", scaffoldMoldYaml(o.Name, o.Description, o.Author)},
+		{"flux.yaml", scaffoldFluxYaml(o.Workflow)},
+		{"flux.schema.yaml", scaffoldFluxSchema(o.Workflow)},
+		{".gitignore", scaffoldGitignore},
+		{"commands/hello.md", scaffoldCommandBlank},
+		{"skills/helper.md", scaffoldSkillBlank},
+	}
+	if o.Agents {
+		files = append(files, scaffoldFile{"AGENTS.md", scaffoldAgentsMd})
+	}
+	if o.Workflow {
+		files = append(files, scaffoldFile{"workflows/claude-code.yml", scaffoldClaudeWorkflow})
+	}
+	return files
+}
+
+func scaffoldMoldYaml(name, description, author string) string {
 	return fmt.Sprintf(`apiVersion: v1
 kind: mold
 name: %s
 version: 0.1.0
-description: ""
+description: %q
 author:
-  name: ""
-`, name)
+  name: %q
+`, name, description, author)
+}
+
+func scaffoldFluxYaml(workflow bool) string {
+	var b strings.Builder
+	b.WriteString("output:\n  commands: .claude/commands\n  skills: .claude/skills\n")
+	if workflow {
+		b.WriteString("  workflows:\n    dest: .github/workflows\n    process: true\n")
+	}
+	b.WriteString("\nproject_name: my-project\n")
+	if workflow {
+		b.WriteString(`
+claude:
+  action_version: v1
+  model: claude-sonnet-4-5
+  triggers:
+    - issue_comment
+    - pull_request_review_comment
+  permissions:
+    contents: read
+    pull_requests: write
+`)
+	}
+	return b.String()
 }
 
-const scaffoldFluxYaml = `output:
-  commands: .claude/commands
-  skills: .claude/skills
+func scaffoldFluxSchema(workflow bool) string {
+	schema := `- name: project_name
+  type: string
+  description: Project name used in blank headings
+  required: true
+`
+	if workflow {
+		schema += `- name: claude.action_version
+  type: string
+  description: anthropics/claude-code-action ref to pin (tag or commit SHA)
+  required: true
+- name: claude.model
+  type: string
+  description: Model the workflow runs Claude with
+- name: claude.triggers
+  type: list
+  description: GitHub events that start the workflow
+- name: claude.permissions.contents
+  type: select
+  description: Repository contents permission
+  options:
+    - label: read
+      value: read
+    - label: write
+      value: write
+- name: claude.permissions.pull_requests
+  type: select
+  description: Pull request permission
+  options:
+    - label: read
+      value: read
+    - label: write
+      value: write
+`
+	}
+	return schema
+}
+
+const scaffoldGitignore = `# Local ailloy state from test casts of this mold
+.ailloy/
+ailloy.lock
+
+# Output written by ailloy forge --output
+out/
+`
 
-project_name: my-project
+// scaffoldClaudeWorkflow renders with flux (process: true); ${{ }} Actions
+// expressions pass through untouched.
+const scaffoldClaudeWorkflow = `name: Claude Code
+on:
+{{- range .claude.triggers}}
+  {{.}}:
+    types: [created]
+{{- end}}
+permissions:
+  contents: {{claude.permissions.contents}}
+  pull-requests: {{claude.permissions.pull_requests}}
+  issues: write
+  id-token: write
+jobs:
+  claude:
+    if: contains(github.event.comment.body, '@claude')
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: anthropics/claude-code-action@{{claude.action_version}}
+        with:
+          anthropic_api_key: ${{ secrets.ANTHROPIC_API_KEY }}
+          claude_args: --model {{claude.model}}
 `
 
 const scaffoldAgentsMd = `# {{project_name}} Agent Instructions
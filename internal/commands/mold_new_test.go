@@ -1,11 +1,13 @@
 package commands
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
@@ -26,6 +28,8 @@ func TestNewMold_CreatesStructure(t *testing.T) {
 	expected := []string{
 		"mold.yaml",
 		"flux.yaml",
+		"flux.schema.yaml",
+		".gitignore",
 		"AGENTS.md",
 		"commands/hello.md",
 		"skills/helper.md",
@@ -192,3 +196,116 @@ func TestNewMold_InvalidName(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMold_DescriptionAndAuthorFlags(t *testing.T) {
+	dir := t.TempDir()
+	newMoldOutput = dir
+	newMoldNoAgents = false
+	newMoldDescription = "Team review commands"
+	newMoldAuthor = "Jane Doe"
+	t.Cleanup(func() { newMoldDescription, newMoldAuthor = "", "" })
+
+	if err := runNewMold(nil, []string{"described"}); err != nil {
+		t.Fatalf("runNewMold returned error: %v", err)
+	}
+
+	manifest, err := mold.LoadMold(filepath.Join(dir, "described", "mold.yaml"))
+	if err != nil {
+		t.Fatalf("loading scaffolded mold.yaml: %v", err)
+	}
+	if manifest.Description != "Team review commands" {
+		t.Errorf("description = %q, want %q", manifest.Description, "Team review commands")
+	}
+	if manifest.Author.Name != "Jane Doe" {
+		t.Errorf("author = %q, want %q", manifest.Author.Name, "Jane Doe")
+	}
+}
+
+func TestNewMold_WithWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	name := "workflow-test"
+
+	newMoldOutput = dir
+	newMoldNoAgents = true
+	newMoldWithWorkflow = true
+	t.Cleanup(func() { newMoldWithWorkflow = false })
+
+	if err := runNewMold(nil, []string{name}); err != nil {
+		t.Fatalf("runNewMold returned error: %v", err)
+	}
+
+	moldDir := filepath.Join(dir, name)
+	content, err := os.ReadFile(filepath.Join(moldDir, "workflows", "claude-code.yml"))
+	if err != nil {
+		t.Fatalf("workflow should exist: %v", err)
+	}
+	if !strings.Contains(string(content), "anthropics/claude-code-action@{{claude.action_version}}") {
+		t.Errorf("workflow should pin the action version via flux, got:\n%s", content)
+	}
+
+	result := mold.Temper(os.DirFS(moldDir))
+	for _, d := range append(result.Errors(), result.Warnings()...) {
+		t.Errorf("temper %s: %s: %s", d.Severity, d.File, d.Message)
+	}
+}
+
+func TestNewMold_WithoutWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	newMoldOutput = dir
+	newMoldNoAgents = false
+
+	if err := runNewMold(nil, []string{"plain"}); err != nil {
+		t.Fatalf("runNewMold returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plain", "workflows")); !os.IsNotExist(err) {
+		t.Error("workflows/ should not exist without --with-workflow")
+	}
+	flux, err := os.ReadFile(filepath.Join(dir, "plain", "flux.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(flux), "workflows") {
+		t.Errorf("flux.yaml should not map workflows, got:\n%s", flux)
+	}
+}
+
+func TestNewMold_QuietAndJSONLogs(t *testing.T) {
+	run := func(t *testing.T, opts logging.Options) (stdout, stderr string) {
+		t.Helper()
+		var out, errOut bytes.Buffer
+		opts.Stdout, opts.Stderr = &out, &errOut
+		if err := logging.Setup(opts); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = logging.Setup(logging.Options{}) })
+		dir := t.TempDir()
+		newMoldOutput = dir
+		newMoldNoAgents = false
+		if err := runNewMold(nil, []string{"quiet-mold"}); err != nil {
+			t.Fatalf("runNewMold returned error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "quiet-mold", "mold.yaml")); err != nil {
+			t.Errorf("mold.yaml not written: %v", err)
+		}
+		return out.String(), errOut.String()
+	}
+
+	t.Run("quiet", func(t *testing.T) {
+		stdout, stderr := run(t, logging.Options{Quiet: true})
+		if stdout != "" || stderr != "" {
+			t.Errorf("--quiet printed output:\nstdout: %s\nstderr: %s", stdout, stderr)
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		stdout, stderr := run(t, logging.Options{Format: logging.FormatJSON})
+		if stdout != "" {
+			t.Errorf("--log-format json printed to stdout: %s", stdout)
+		}
+		if !strings.Contains(stderr, `"msg":"created"`) || !strings.Contains(stderr, "quiet-mold/mold.yaml") {
+			t.Errorf("want a created record per file on stderr, got:\n%s", stderr)
+		}
+		if strings.Contains(stderr, "Scaffolding") || strings.Contains(stderr, "Next steps") {
+			t.Errorf("banner or next-steps box leaked into the JSON log:\n%s", stderr)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/smelt"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var moldRenderCmd = &cobra.Command{
+	Use:   "render <blank> [mold-dir]",
+	Short: "Render a single blank to stdout",
+	Long: `Render one blank from a mold with layered flux and print it to stdout.
+
+This is forge for a single file: flux layering, ore overlays, ingots, and
+per-destination set: overrides all behave as they do in forge, and nothing
+is installed. The mold directory defaults to the current directory.
+
+The blank can be named by its source path (commands/hello.md), its
+destination path (.claude/commands/hello.md), or its file name with or
+without the extension (hello). When a name matches more than one output —
+e.g. one source mapped to several destinations — pass the destination path.
+
+Example:
+  ailloy mold render hello
+  ailloy mold render commands/hello.md ./my-mold --set project_name=Atlas
+  ailloy mold render CLAUDE.md -f values.yaml -o /tmp/CLAUDE.md`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMoldRender,
+}
+
+var (
+	moldRenderOutput    string
+	moldRenderSetValues []string
+	moldRenderValFiles  []string
+)
+
+func init() {
+	moldCmd.AddCommand(moldRenderCmd)
+
+	moldRenderCmd.Flags().StringVarP(&moldRenderOutput, "output", "o", "", "write the rendered blank to this file instead of stdout")
+	moldRenderCmd.Flags().StringArrayVar(&moldRenderSetValues, "set", nil, "set flux values (key=value)")
+	_ = moldRenderCmd.RegisterFlagCompletionFunc("set", completeSetFlag(1))
+	moldRenderCmd.Flags().StringArrayVarP(&moldRenderValFiles, "values", "f", nil, "flux value files (can be repeated, later files override earlier)")
+}
+
+func runMoldRender(_ *cobra.Command, args []string) error {
+	name := args[0]
+	moldArgs := args[1:]
+	if len(moldArgs) == 0 && !smelt.HasEmbeddedMold() {
+		moldArgs = []string{"."}
+	}
+
+	reader, remote, err := resolveForgeReader(moldArgs)
+	if err != nil {
+		return err
+	}
+	manifest, err := reader.LoadManifest()
+	if err != nil {
+		return fmt.Errorf("failed to load mold manifest: %w", err)
+	}
+
+	oreResolver, err := ResolveDepsEphemeral(manifest, !remote)
+	if err != nil {
+		return fmt.Errorf("resolving ore deps for render: %w", err)
+	}
+	flux, err := loadForgeFlux(reader, oreResolver, moldRenderValFiles, moldRenderSetValues)
+	if err != nil {
+		return err
+	}
+
+	schema, _ := reader.LoadFluxSchema()
+	if schema == nil && len(manifest.Flux) > 0 {
+		schema = manifest.Flux
+	}
+	mergedSchema, _, _, err := oreResolver.MergeInto(schema, nil)
+	if err != nil {
+		return fmt.Errorf("merging ore schema overlays: %w", err)
+	}
+	for _, w := range append(mold.MigrateFlux(mergedSchema, flux), mold.ResolveFlux(mergedSchema, flux, time.Now())...) {
+		log.Printf("warning: %s", w)
+	}
+	if err := mold.ValidateFluxWithSources(mergedSchema, flux, valuesFluxSources(moldRenderValFiles, moldRenderSetValues)); err != nil {
+		log.Printf("warning: %v", err)
+	}
+
+	var resolveOpts []mold.ResolveOption
+	if patterns := mold.LoadIgnorePatterns(reader.FS(), manifest); len(patterns) > 0 {
+		resolveOpts = append(resolveOpts, mold.WithIgnorePatterns(patterns))
+	}
+	resolved, err := mold.ResolveFilesWithOreSources(flux["output"], reader.FS(), oreResolver.OreSources(), resolveOpts...)
+	if err != nil {
+		return fmt.Errorf("resolving output files: %w", err)
+	}
+	rf, err := matchBlank(resolved, name)
+	if err != nil {
+		return err
+	}
+
+	content, err := fs.ReadFile(chooseFS(rf, reader.FS()), rf.SrcPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", rf.SrcPath, err)
+	}
+	rendered := string(content)
+	if rf.Process {
+		ingotResolver := buildIngotResolver(flux, reader.Root())
+		ingotResolver.FS = reader.FS()
+		applyIngotConstraints(ingotResolver, manifest)
+		if err := attachRemoteIngots(ingotResolver, reader.FS(), []mold.ResolvedFile{rf}); err != nil {
+			return err
+		}
+		rendered, err = renderFile(rf.SrcPath, content, mold.MergeSet(flux, rf.Set), mold.WithIngotResolver(ingotResolver))
+		if err != nil {
+			return err
+		}
+	}
+
+	if moldRenderOutput == "" {
+		fmt.Print(rendered)
+		return nil
+	}
+	if dir := filepath.Dir(moldRenderOutput); dir != "." {
+		if err := os.MkdirAll(dir, 0750); err != nil { // #nosec G301 -- Output directory needs group read access
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+	}
+	//#nosec G306 -- Rendered blanks need to be readable
+	if err := os.WriteFile(moldRenderOutput, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", moldRenderOutput, err)
+	}
+	fmt.Println(styles.SuccessStyle.Render("Rendered ") + styles.CodeStyle.Render(rf.SrcPath) +
+		styles.SubtleStyle.Render(" → ") + styles.CodeStyle.Render(moldRenderOutput))
+	return nil
+}
+
+// matchBlank picks the resolved output named by name. Exact source or
+// destination path matches win over file-name matches; a name that still
+// matches several outputs is an error listing them.
+func matchBlank(resolved []mold.ResolvedFile, name string) (mold.ResolvedFile, error) {
+	name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+
+	var exact, loose []mold.ResolvedFile
+	for _, rf := range resolved {
+		switch {
+		case rf.SrcPath == name || rf.DestPath == name:
+			exact = append(exact, rf)
+		case strings.TrimSuffix(rf.SrcPath, path.Ext(rf.SrcPath)) == name,
+			path.Base(rf.SrcPath) == name,
+			strings.TrimSuffix(path.Base(rf.SrcPath), path.Ext(rf.SrcPath)) == name:
+			loose = append(loose, rf)
+		}
+	}
+	matches := exact
+	if len(matches) == 0 {
+		matches = loose
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		srcs := make([]string, 0, len(resolved))
+		for _, rf := range resolved {
+			if !containsString(srcs, rf.SrcPath) {
+				srcs = append(srcs, rf.SrcPath)
+			}
+		}
+		sort.Strings(srcs)
+		return mold.ResolvedFile{}, fmt.Errorf("blank %q not found in the mold's output mapping; available: %s", name, strings.Join(srcs, ", "))
+	default:
+		lines := make([]string, len(matches))
+		for i, rf := range matches {
+			lines[i] = fmt.Sprintf("  %s → %s", rf.SrcPath, rf.DestPath)
+		}
+		return mold.ResolvedFile{}, fmt.Errorf("blank %q matches %d outputs; pass the destination path to pick one:\n%s", name, len(matches), strings.Join(lines, "\n"))
+	}
+}
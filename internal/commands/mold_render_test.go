@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestMatchBlank(t *testing.T) {
+	resolved := []mold.ResolvedFile{
+		{SrcPath: "commands/hello.md", DestPath: ".claude/commands/hello.md"},
+		{SrcPath: "skills/hello.md", DestPath: ".claude/skills/hello.md"},
+		{SrcPath: "commands/review.md", DestPath: ".claude/commands/review.md"},
+		{SrcPath: "AGENTS.md", DestPath: "AGENTS.md"},
+		{SrcPath: "AGENTS.md", DestPath: "CLAUDE.md"},
+	}
+
+	tests := []struct {
+		name     string
+		wantDest string
+		wantErr  string
+	}{
+		{name: "review", wantDest: ".claude/commands/review.md"},
+		{name: "review.md", wantDest: ".claude/commands/review.md"},
+		{name: "commands/hello", wantDest: ".claude/commands/hello.md"},
+		{name: "./skills/hello.md", wantDest: ".claude/skills/hello.md"},
+		{name: ".claude/commands/hello.md", wantDest: ".claude/commands/hello.md"},
+		{name: "CLAUDE.md", wantDest: "CLAUDE.md"},
+		{name: "hello", wantErr: "matches 2 outputs"},
+		{name: "AGENTS.md", wantErr: "matches 2 outputs"},
+		{name: "missing", wantErr: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rf, err := matchBlank(resolved, tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rf.DestPath != tt.wantDest {
+				t.Errorf("DestPath = %q, want %q", rf.DestPath, tt.wantDest)
+			}
+		})
+	}
+}
+
+func TestMoldRender_WritesSingleBlankWithLayeredFlux(t *testing.T) {
+	moldDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(moldDir, "commands"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: render-test\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  commands: .claude/commands\nproject_name: default\nteam: core\n")
+	mustWrite(t, filepath.Join(moldDir, "values.yaml"), "team: platform\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "# {{project_name}} / {{team}}\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "other.md"), "# other\n")
+
+	out := filepath.Join(t.TempDir(), "nested", "hello.md")
+	moldRenderOutput = out
+	moldRenderSetValues = []string{"project_name=Atlas"}
+	moldRenderValFiles = []string{filepath.Join(moldDir, "values.yaml")}
+	t.Cleanup(func() {
+		moldRenderOutput, moldRenderSetValues, moldRenderValFiles = "", nil, nil
+	})
+
+	chdir(t, t.TempDir())
+	if err := runMoldRender(nil, []string{"hello", moldDir}); err != nil {
+		t.Fatalf("runMoldRender: %v", err)
+	}
+
+	got, err := os.ReadFile(out) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(got) != "# Atlas / platform\n" {
+		t.Errorf("rendered = %q, want %q", got, "# Atlas / platform\n")
+	}
+	if _, err := os.Stat(".claude"); !os.IsNotExist(err) {
+		t.Error("render must not install anything into the working directory")
+	}
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var moldSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Work with a mold's flux schema",
+}
+
+var moldSchemaInferCmd = &cobra.Command{
+	Use:   "infer [mold-dir]",
+	Short: "Generate a starter flux.schema.yaml from flux.yaml and the blanks",
+	Long: `Draft a flux.schema.yaml for a mold that doesn't have one yet.
+
+Every value in flux.yaml becomes a variable typed from its value (bool, int,
+list or string) with the value as its default. Every variable the blanks
+read that flux.yaml doesn't set is added too, typed from how the blanks use
+it: a range target is a list, a bare {{if}} condition a bool, anything else
+a string. Those are required, except the bools. Each variable gets a
+description stub to fill in.
+
+The result is a starting point: review the types, descriptions and required
+flags, then run ailloy temper. The mold directory defaults to the current
+directory. An existing flux.schema.yaml is kept unless --force is passed.
+
+Example:
+  ailloy mold schema infer
+  ailloy mold schema infer ./my-mold --stdout`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runMoldSchemaInfer,
+}
+
+var (
+	moldSchemaInferStdout bool
+	moldSchemaInferForce  bool
+)
+
+func init() {
+	moldCmd.AddCommand(moldSchemaCmd)
+	moldSchemaCmd.AddCommand(moldSchemaInferCmd)
+
+	moldSchemaInferCmd.Flags().BoolVar(&moldSchemaInferStdout, "stdout", false, "print the schema instead of writing flux.schema.yaml")
+	moldSchemaInferCmd.Flags().BoolVar(&moldSchemaInferForce, "force", false, "overwrite an existing flux.schema.yaml")
+}
+
+// inferredSchemaHeader opens a generated flux.schema.yaml.
+const inferredSchemaHeader = `# Generated by ailloy mold schema infer. Review the types, descriptions and
+# required flags before publishing the mold.
+`
+
+func runMoldSchemaInfer(cmd *cobra.Command, args []string) error {
+	moldDir := "."
+	if len(args) == 1 {
+		moldDir = args[0]
+	}
+	info, err := os.Stat(moldDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a mold directory", moldDir)
+	}
+
+	dest := filepath.Join(moldDir, "flux.schema.yaml")
+	if !moldSchemaInferStdout && !moldSchemaInferForce {
+		if _, err := os.Stat(dest); err == nil {
+			return fmt.Errorf("%s already exists; pass --force to replace it or --stdout to print the draft", dest)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	schema, err := mold.InferFluxSchema(os.DirFS(moldDir))
+	if err != nil {
+		return fmt.Errorf("inferring schema: %w", err)
+	}
+	if len(schema) == 0 {
+		return fmt.Errorf("no flux values or template variables found in %s", moldDir)
+	}
+	body, err := yaml.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	data := append([]byte(inferredSchemaHeader), body...)
+
+	if moldSchemaInferStdout {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	//#nosec G306 -- Mold files need to be readable
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+
+	required := 0
+	for _, fv := range schema {
+		if fv.Required {
+			required++
+		}
+	}
+	fmt.Println(styles.SuccessStyle.Render("✅ Wrote ") + styles.CodeStyle.Render(dest) +
+		fmt.Sprintf(" with %d variable(s), %d required", len(schema), required))
+	fmt.Println(styles.SubtleStyle.Render("  review the types and descriptions, then run ailloy temper " + moldDir))
+	return nil
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestMoldSchemaInfer_WritesOnceUnlessForced(t *testing.T) {
+	moldDir := t.TempDir()
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: infer-test\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "team: core\n")
+	mustWrite(t, filepath.Join(moldDir, "CLAUDE.md"), "{{ .team }} / {{ .org }}\n")
+	t.Cleanup(func() { moldSchemaInferForce = false })
+
+	if err := runMoldSchemaInfer(moldSchemaInferCmd, []string{moldDir}); err != nil {
+		t.Fatalf("runMoldSchemaInfer: %v", err)
+	}
+	schema, err := mold.LoadFluxSchema(os.DirFS(moldDir), "flux.schema.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema) != 2 || schema[0].Name != "org" || !schema[0].Required || schema[1].Default != "core" {
+		t.Errorf("written schema = %+v, want required org and team defaulting to core", schema)
+	}
+
+	if err := runMoldSchemaInfer(moldSchemaInferCmd, []string{moldDir}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("second run: err = %v, want a refusal suggesting --force", err)
+	}
+	moldSchemaInferForce = true
+	if err := runMoldSchemaInfer(moldSchemaInferCmd, []string{moldDir}); err != nil {
+		t.Errorf("--force: %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -42,3 +45,32 @@ func TestGetMoldIcon(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectListedBlanks(t *testing.T) {
+	dir := t.TempDir()
+	blankDir := filepath.Join(dir, ".claude")
+	wfDir := filepath.Join(dir, "workflows")
+	if err := os.MkdirAll(filepath.Join(blankDir, "commands"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(wfDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(blankDir, "commands", "brainstorm.md"), "# Brainstorm ideas\n")
+	mustWrite(t, filepath.Join(blankDir, "notes.md"), "no heading\n")
+	mustWrite(t, filepath.Join(wfDir, "claude.yml"), "name: Claude\non: push\n")
+
+	got := collectListedBlanks([]string{blankDir}, []string{wfDir})
+	want := []listedBlank{
+		{Name: "commands/brainstorm", Path: filepath.Join(blankDir, "commands", "brainstorm.md"), Description: "Brainstorm ideas"},
+		{Name: "general/notes", Path: filepath.Join(blankDir, "notes.md"), Description: "Blank"},
+		{Name: "workflows/claude", Path: filepath.Join(wfDir, "claude.yml"), Description: "Claude", Workflow: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectListedBlanks =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := collectListedBlanks(nil, []string{filepath.Join(dir, "missing")}); got == nil || len(got) != 0 {
+		t.Errorf("empty listing = %#v, want empty non-nil slice", got)
+	}
+}
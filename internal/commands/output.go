@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+)
+
+// Structured formats accepted by --output on inventory commands (mold
+// list/show, cache list, status). The empty default keeps the styled
+// human output.
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// addOutputFlag registers --output/-o on cmd, bound to target.
+func addOutputFlag(cmd *cobra.Command, target *string) {
+	cmd.Flags().StringVarP(target, "output", "o", "", "print structured output instead of styled text: json or yaml")
+}
+
+// validateOutputFormat rejects --output values other than json and yaml.
+// Call it before doing any work so a typo fails fast.
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid --output %q: use %s or %s", format, outputJSON, outputYAML)
+}
+
+// writeStructured encodes v to w as format (json or yaml).
+func writeStructured(w io.Writer, format string, v any) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return validateOutputFormat(format)
+}
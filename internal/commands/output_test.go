@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteStructured(t *testing.T) {
+	v := []statusFile{{Path: "a.md", State: fileModified}}
+
+	var buf bytes.Buffer
+	if err := writeStructured(&buf, outputJSON, v); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[\n  {\n    \"path\": \"a.md\",\n    \"state\": \"modified\"\n  }\n]\n"; buf.String() != want {
+		t.Errorf("json = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeStructured(&buf, outputYAML, v); err != nil {
+		t.Fatal(err)
+	}
+	if want := "- path: a.md\n  state: modified\n"; buf.String() != want {
+		t.Errorf("yaml = %q, want %q", buf.String(), want)
+	}
+
+	if err := writeStructured(&buf, "xml", v); err == nil {
+		t.Error("xml: want error")
+	}
+	if err := validateOutputFormat(""); err != nil {
+		t.Errorf("empty format: %v", err)
+	}
+}
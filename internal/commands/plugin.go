@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/plugin"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
@@ -18,6 +21,16 @@ var (
 	pluginMoldDir   string
 	pluginWatch     bool
 	pluginForce     bool
+	pluginRuntime   bool
+	pluginFormat    string
+	pluginSetValues []string
+	pluginValFiles  []string
+	pluginName      string
+	pluginVersion   string
+
+	pluginDiffInstalled string
+	pluginDiffGlobal    bool
+	pluginDiffExitCode  bool
 )
 
 var pluginCmd = &cobra.Command{
@@ -35,12 +48,29 @@ var generatePluginCmd = &cobra.Command{
 	Short: "Generate Claude Code plugin from blanks",
 	Long: `Generate a complete Claude Code plugin from a mold directory.
 
-The generated plugin will include:
-- All commands from the mold
-- Plugin manifest (plugin.json)
-- README documentation
+The mold is rendered the way cast renders it — flux.yaml and schema
+defaults, then --values files, then --set — and packaged in the layout
+cast --claude-plugin writes:
+- Commands, skills, agents and hooks from the mold
+- AGENTS.md, when the mold renders one
+- Plugin manifest (plugin.json) from mold.yaml's name, version,
+  description and author
+- README documentation (the mold's README.md, or a generated command list)
 - Installation scripts
-- Hooks and agents configurations`,
+
+Workflow blanks are left out: plugins can't bundle them.
+
+--format selects another tool's layout instead of a Claude Code plugin,
+written under <output> at the paths the tool reads in a project:
+- cursor:    Cursor rules in .cursor/rules/*.mdc
+- opencode:  OpenCode commands in .opencode/command/*.md plus opencode.json
+- codex:     Codex CLI prompts in .codex/prompts/*.md, with skills folded
+             into AGENTS.md
+- windsurf:  Windsurf workflows in .windsurf/workflows/*.md, with skills
+             folded into .windsurfrules
+- jetbrains: a JetBrains AI Assistant prompt library in
+             .aiassistant/prompts/<mold>.json plus rules in
+             .aiassistant/rules/*.md`,
 	RunE: runGeneratePlugin,
 }
 
@@ -49,18 +79,45 @@ var updatePluginCmd = &cobra.Command{
 	Short: "Update existing Claude Code plugin",
 	Long: `Update an existing Claude Code plugin with the latest blanks.
 
-This preserves any custom additions while updating the core commands
-from the latest Ailloy blanks.`,
+The mold is rendered exactly as plugin generate renders it (ingots, --values,
+--set) and written over the plugin at the same paths, so an updated plugin
+matches a freshly generated one. Files the mold no longer produces, such as
+custom commands, are left in place.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runUpdatePlugin,
 }
 
 var validatePluginCmd = &cobra.Command{
-	Use:   "validate [path]",
-	Short: "Validate Claude Code plugin structure",
-	Long:  `Validate that a Claude Code plugin has the correct structure and all required files.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runValidatePlugin,
+	Use:     "validate [path]",
+	Aliases: []string{"verify"},
+	Short:   "Validate Claude Code plugin structure",
+	Long: `Validate that a Claude Code plugin has the correct structure and all required files.
+
+plugin.json, hooks.json and command frontmatter are checked against the
+Claude Code plugin spec (field types, hook events, frontmatter fields), and
+every file the manifest or a hook references must exist. Findings are
+reported like temper's, with the file they concern.
+
+With --runtime, additionally load the plugin into the locally installed
+claude CLI inside a throwaway sandbox project and confirm every command
+registers without errors. This catches spec drift that static validation
+misses. Requires Claude Code on PATH.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runValidatePlugin,
+}
+
+var diffPluginCmd = &cobra.Command{
+	Use:   "diff [generated-path]",
+	Short: "Show how a generated plugin differs from the installed copy",
+	Long: `Compare a freshly generated plugin against the copy Claude Code loads and
+report which commands were added, removed, or changed before you ship it.
+
+The installed copy defaults to .claude/plugins/<slug> (or ~/.claude/plugins/<slug>
+with --global), where <slug> comes from the generated plugin.json name. A
+warning is printed when content changed but the plugin version did not, since
+users may keep running the stale copy.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDiffPlugin,
 }
 
 func init() {
@@ -68,25 +125,55 @@ func init() {
 	pluginCmd.AddCommand(generatePluginCmd)
 	pluginCmd.AddCommand(updatePluginCmd)
 	pluginCmd.AddCommand(validatePluginCmd)
+	pluginCmd.AddCommand(diffPluginCmd)
 
 	// Generate command flags
 	generatePluginCmd.Flags().StringVarP(&pluginOutputDir, "output", "o", "ailloy", "Output directory for generated plugin")
 	generatePluginCmd.Flags().BoolVarP(&pluginWatch, "watch", "w", false, "Watch blanks and regenerate on changes")
 	generatePluginCmd.Flags().BoolVarP(&pluginForce, "force", "f", false, "Overwrite existing plugin without prompting")
 	generatePluginCmd.Flags().StringVar(&pluginMoldDir, "mold", "", "mold directory to generate plugin from (required)")
+	generatePluginCmd.Flags().StringArrayVar(&pluginSetValues, "set", nil, "set flux values (key=value)")
+	generatePluginCmd.Flags().StringArrayVar(&pluginValFiles, "values", nil, "flux value files (can be repeated, later files override earlier)")
+	generatePluginCmd.Flags().StringVar(&pluginName, "plugin-name", "", "override the plugin name (default: mold name)")
+	generatePluginCmd.Flags().StringVar(&pluginVersion, "plugin-version", "", "override the plugin version (default: mold version)")
+	generatePluginCmd.Flags().StringVar(&pluginFormat, "format", plugin.FormatClaude, "output format: claude (Claude Code plugin) or an output adapter ("+strings.Join(blanks.OutputAdapterNames(), ", ")+")")
 
 	// Update command flags
 	updatePluginCmd.Flags().BoolVarP(&pluginForce, "force", "f", false, "Force update without backup")
 	updatePluginCmd.Flags().StringVar(&pluginMoldDir, "mold", "", "mold directory to update plugin from (required)")
+	updatePluginCmd.Flags().StringArrayVar(&pluginSetValues, "set", nil, "set flux values (key=value)")
+	updatePluginCmd.Flags().StringArrayVar(&pluginValFiles, "values", nil, "flux value files (can be repeated, later files override earlier)")
+	updatePluginCmd.Flags().StringVar(&pluginName, "plugin-name", "", "override the plugin name (default: mold name)")
+	updatePluginCmd.Flags().StringVar(&pluginVersion, "plugin-version", "", "override the plugin version (default: mold version)")
+
+	// Validate command flags
+	validatePluginCmd.Flags().BoolVar(&pluginRuntime, "runtime", false, "also load the plugin in the local claude CLI and confirm commands register")
+
+	// Diff command flags
+	diffPluginCmd.Flags().StringVar(&pluginDiffInstalled, "installed", "", "installed plugin directory to compare against (default: resolved from the plugin name)")
+	diffPluginCmd.Flags().BoolVarP(&pluginDiffGlobal, "global", "g", false, "compare against the plugin installed under ~/.claude/plugins")
+	diffPluginCmd.Flags().BoolVar(&pluginDiffExitCode, "exit-code", false, "exit with an error when the plugins differ")
 }
 
 func runGeneratePlugin(cmd *cobra.Command, args []string) error {
 	// Display generation header
-	fmt.Println(styles.WorkingBanner("Generating Claude Code Plugin from Ailloy Blanks..."))
+	var adapter blanks.OutputAdapter
+	if pluginFormat == plugin.FormatClaude {
+		fmt.Println(styles.WorkingBanner("Generating Claude Code Plugin from Ailloy Blanks..."))
+	} else {
+		var ok bool
+		if adapter, ok = blanks.LookupOutputAdapter(pluginFormat); !ok {
+			return fmt.Errorf("unknown --format %q (want one of %s)", pluginFormat, strings.Join(plugin.Formats(), ", "))
+		}
+		fmt.Println(styles.WorkingBanner(fmt.Sprintf("Generating %s from Ailloy Blanks...", blanks.AdapterTitle(adapter))))
+	}
 	fmt.Println()
 
 	// Check if output directory exists
 	if _, err := os.Stat(pluginOutputDir); err == nil && !pluginForce {
+		if !isInteractive() {
+			return fmt.Errorf("plugin directory %s already exists; use --force to overwrite it", pluginOutputDir)
+		}
 		// Directory exists, ask for confirmation
 		warning := styles.WarningStyle.Render("⚠️  Warning: ") +
 			fmt.Sprintf("Plugin directory '%s' already exists.", pluginOutputDir)
@@ -109,20 +196,13 @@ func runGeneratePlugin(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if reader, err = ComposeMoldReader(reader, ""); err != nil {
+		return err
+	}
 
-	// Create generator
-	generator := plugin.NewGenerator(pluginOutputDir, reader)
-
-	// Configure generator
-	generator.Config = &plugin.Config{
-		Name:        "ailloy",
-		Version:     "1.0.0",
-		Description: "AI-assisted development workflows and structured blanks for Claude Code",
-		Author: plugin.Author{
-			Name:  "Ailloy Team",
-			Email: "support@ailloy.dev",
-			URL:   "https://github.com/nimble-giant/ailloy",
-		},
+	generator, err := newPluginGenerator(reader)
+	if err != nil {
+		return err
 	}
 
 	// Progress display
@@ -132,6 +212,22 @@ func runGeneratePlugin(cmd *cobra.Command, args []string) error {
 	if err := generator.Generate(); err != nil {
 		return fmt.Errorf("failed to generate plugin: %w", err)
 	}
+	if generator.HadWorkflows {
+		fmt.Println(styles.WarningStyle.Render("⚠️  ") + "workflow blanks are not bundled into Claude Code plugins and were left out")
+	}
+
+	if adapter != nil {
+		steps := "Copy the generated files into place from the project root:\n" +
+			styles.CodeStyle.Render(fmt.Sprintf("   cp -r %s/. .", pluginOutputDir))
+		if h, ok := adapter.(blanks.HintAdapter); ok {
+			steps += "\n\n" + h.Hint()
+		}
+		fmt.Println()
+		fmt.Println(styles.SuccessBanner("Output generated successfully!"))
+		fmt.Println()
+		fmt.Println(styles.InfoBoxStyle.Render(styles.AccentStyle.Render("Next Steps:\n\n") + steps))
+		return nil
+	}
 
 	// Success message
 	fmt.Println()
@@ -159,6 +255,52 @@ func runGeneratePlugin(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newPluginGenerator renders the mold with plugin generate's flux — flux
+// defaults, then --values, then --set — through cast's pipeline and returns
+// a generator for the output directory holding the result.
+func newPluginGenerator(reader *blanks.MoldReader) (*plugin.Generator, error) {
+	manifest, err := reader.LoadManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mold manifest: %w", err)
+	}
+	oreResolver, err := ResolveDepsEphemeral(manifest, true)
+	if err != nil {
+		return nil, fmt.Errorf("resolving ore deps: %w", err)
+	}
+	flux, err := loadForgeFlux(reader, oreResolver, pluginValFiles, pluginSetValues)
+	if err != nil {
+		return nil, err
+	}
+	files, err := renderMoldFiles(reader, manifest, flux, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	generator := plugin.NewGenerator(pluginOutputDir, reader)
+	generator.Format = pluginFormat
+	generator.Files = files
+	if pluginFormat != plugin.FormatClaude {
+		return generator, nil
+	}
+
+	readme, err := readMoldReadme(reader, flux)
+	if err != nil {
+		return nil, err
+	}
+	generator.Readme = readme
+	input, err := buildManifestInput(manifest, pluginName, pluginVersion)
+	if err != nil {
+		return nil, err
+	}
+	generator.Config = &plugin.Config{
+		Name:        input.Name,
+		Version:     input.Version,
+		Description: input.Description,
+		Author:      plugin.Author{Name: input.Author.Name, URL: input.Author.URL},
+	}
+	return generator, nil
+}
+
 func runUpdatePlugin(cmd *cobra.Command, args []string) error {
 	pluginPath := "ailloy"
 	if len(args) > 0 {
@@ -166,7 +308,7 @@ func runUpdatePlugin(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check if plugin exists
-	if _, err := os.Stat(filepath.Join(pluginPath, ".ailloy", "plugin.json")); err != nil {
+	if _, err := os.Stat(filepath.Join(pluginPath, ".claude-plugin", "plugin.json")); err != nil {
 		return fmt.Errorf("no valid plugin found at %s", pluginPath)
 	}
 
@@ -178,12 +320,21 @@ func runUpdatePlugin(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if reader, err = ComposeMoldReader(reader, ""); err != nil {
+		return err
+	}
 
 	fmt.Println(styles.WorkingBanner("Updating Claude Code Plugin..."))
 	fmt.Println()
 
-	// Create updater
+	// Render the new content as generate does: ingots, --set and --values
+	pluginFormat = plugin.FormatClaude
+	generator, err := newPluginGenerator(reader)
+	if err != nil {
+		return err
+	}
 	updater := plugin.NewUpdater(pluginPath, reader)
+	updater.Generator = generator
 
 	// Backup existing plugin
 	if !pluginForce {
@@ -231,7 +382,7 @@ func runValidatePlugin(cmd *cobra.Command, args []string) error {
 	// Run validation
 	results, err := validator.Validate()
 	if err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+		return validationError(fmt.Errorf("validation failed: %w", err))
 	}
 
 	// Display results
@@ -266,28 +417,34 @@ func runValidatePlugin(cmd *cobra.Command, args []string) error {
 	}
 
 	detailsBox := lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder()).
+		Border(styles.TableBorder()).
 		BorderForeground(styles.Primary1).
 		Padding(1, 2).
 		Render(strings.Join(details, "\n"))
 
 	fmt.Println(detailsBox)
 
-	// Show warnings
-	if len(results.Warnings) > 0 {
+	// Show diagnostics, warnings first, as temper does
+	if len(results.Diagnostics) > 0 {
 		fmt.Println()
-		fmt.Println(styles.WarningStyle.Render("Warnings:"))
-		for _, warning := range results.Warnings {
-			fmt.Println("  • " + warning)
-		}
 	}
-
-	// Show errors
-	if len(results.Errors) > 0 {
-		fmt.Println()
-		fmt.Println(styles.ErrorStyle.Render("Errors:"))
-		for _, err := range results.Errors {
-			fmt.Println("  • " + err)
+	for _, severity := range []mold.DiagSeverity{mold.SeverityWarning, mold.SeverityError} {
+		for _, d := range results.Diagnostics {
+			if d.Severity != severity {
+				continue
+			}
+			label := styles.WarningStyle.Render("WARNING: ")
+			if severity == mold.SeverityError {
+				label = styles.ErrorStyle.Render("ERROR: ")
+			}
+			loc := ""
+			if d.File != "" {
+				loc = styles.SubtleStyle.Render(d.File + ": ")
+			}
+			fmt.Println(label + loc + d.Message)
+			if d.Tip != "" {
+				fmt.Println("  " + styles.SubtleStyle.Render(d.Tip))
+			}
 		}
 	}
 
@@ -295,5 +452,156 @@ func runValidatePlugin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("plugin validation failed with %d errors", len(results.Errors))
 	}
 
+	if pluginRuntime {
+		return runRuntimePluginVerify(cmd, pluginPath)
+	}
+
 	return nil
 }
+
+// runRuntimePluginVerify loads the plugin in the local claude CLI and
+// reports which commands registered.
+func runRuntimePluginVerify(cmd *cobra.Command, pluginPath string) error {
+	fmt.Println()
+	fmt.Println(styles.InfoStyle.Render("🧪 Verifying plugin against the local claude CLI..."))
+
+	verifier := plugin.NewRuntimeVerifier(pluginPath)
+	res, err := verifier.Verify(cmd.Context())
+	if err != nil {
+		if errors.Is(err, plugin.ErrClaudeNotInstalled) {
+			return err
+		}
+		return fmt.Errorf("runtime verification failed: %w", err)
+	}
+
+	fmt.Println(styles.SubtleStyle.Render("  claude " + res.ClaudeVersion))
+	for _, name := range res.Registered {
+		fmt.Println("  " + styles.SuccessStyle.Render("✓") + " /" + name + " registered")
+	}
+	for _, name := range res.Missing {
+		fmt.Println("  " + styles.ErrorStyle.Render("✗") + " /" + name + " not registered")
+	}
+	for _, e := range res.Errors {
+		fmt.Println("  " + styles.ErrorStyle.Render("✗") + " " + e)
+	}
+
+	if !res.IsValid() {
+		return validationError(fmt.Errorf("runtime verification failed: %d error(s), %d command(s) not registered", len(res.Errors), len(res.Missing)))
+	}
+	fmt.Println(styles.SuccessStyle.Render("✅ Claude Code loaded the plugin and registered all commands"))
+	return nil
+}
+
+func runDiffPlugin(cmd *cobra.Command, args []string) error {
+	generatedPath := "ailloy"
+	if len(args) > 0 {
+		generatedPath = args[0]
+	}
+
+	installedPath := pluginDiffInstalled
+	if installedPath == "" {
+		name, err := pluginManifestName(generatedPath)
+		if err != nil {
+			return err
+		}
+		slug, err := slugifyPluginName(name)
+		if err != nil {
+			return err
+		}
+		installedPath, err = resolvePluginTargetDir(slug, pluginDiffGlobal)
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := plugin.Diff(generatedPath, installedPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(styles.InfoStyle.Render("🔍 Comparing ") + styles.CodeStyle.Render(generatedPath) +
+		styles.InfoStyle.Render(" with installed ") + styles.CodeStyle.Render(installedPath))
+	fmt.Println()
+
+	if !res.HasChanges() {
+		fmt.Println(styles.SuccessStyle.Render("✅ Installed plugin is up to date"))
+		return nil
+	}
+
+	var commands, others []plugin.FileChange
+	for _, c := range res.Changes {
+		if c.IsCommand() {
+			commands = append(commands, c)
+		} else {
+			others = append(others, c)
+		}
+	}
+	if len(commands) > 0 {
+		fmt.Println(styles.AccentStyle.Render("Commands:"))
+		for _, c := range commands {
+			fmt.Println("  " + formatPluginChange(c, "/"+c.CommandName()))
+		}
+	}
+	if len(others) > 0 {
+		if len(commands) > 0 {
+			fmt.Println()
+		}
+		fmt.Println(styles.AccentStyle.Render("Other files:"))
+		for _, c := range others {
+			fmt.Println("  " + formatPluginChange(c, c.Path))
+		}
+	}
+
+	if res.GeneratedVersion != res.InstalledVersion {
+		fmt.Println()
+		fmt.Println(styles.SubtleStyle.Render(fmt.Sprintf("  version %s → %s", orNone(res.InstalledVersion), orNone(res.GeneratedVersion))))
+	}
+	if res.VersionUnchanged() {
+		fmt.Println()
+		fmt.Println(styles.WarningStyle.Render("⚠️  Warning: ") +
+			fmt.Sprintf("content changed but version is still %s; bump it so users pick up the update.", res.GeneratedVersion))
+	}
+
+	if pluginDiffExitCode {
+		return validationError(fmt.Errorf("plugin differs from installed copy: %d file(s) changed", len(res.Changes)))
+	}
+	return nil
+}
+
+// pluginManifestName reads the plugin name from .claude-plugin/plugin.json.
+func pluginManifestName(pluginPath string) (string, error) {
+	manifestPath := filepath.Join(pluginPath, ".claude-plugin", "plugin.json")
+	data, err := os.ReadFile(manifestPath) // #nosec G304 -- user-specified plugin directory
+	if err != nil {
+		return "", fmt.Errorf("reading plugin manifest: %w", err)
+	}
+	var m struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", manifestPath, err)
+	}
+	if m.Name == "" {
+		return "", fmt.Errorf("%s has no name; pass --installed <dir>", manifestPath)
+	}
+	return m.Name, nil
+}
+
+func formatPluginChange(c plugin.FileChange, label string) string {
+	switch c.Kind {
+	case plugin.ChangeAdded:
+		return styles.SuccessStyle.Render("+") + " " + label + styles.SubtleStyle.Render(" (new)")
+	case plugin.ChangeRemoved:
+		return styles.ErrorStyle.Render("-") + " " + label + styles.SubtleStyle.Render(" (removed)")
+	default:
+		return styles.WarningStyle.Render("~") + " " + label +
+			styles.SubtleStyle.Render(fmt.Sprintf(" (+%d/-%d lines)", c.LinesAdded, c.LinesRemoved))
+	}
+}
+
+func orNone(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
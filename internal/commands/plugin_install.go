@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var installPluginCmd = &cobra.Command{
+	Use:   "install <mold-ref>",
+	Short: "Install a mold as a Claude Code plugin where Claude Code discovers it",
+	Long: `Render a mold as a Claude Code plugin and install it into a plugin
+discovery directory, replacing any earlier install of the same plugin.
+
+The plugin goes to the project's .claude/plugins/<slug>, or with --global to
+the user plugins directory:
+
+  $CLAUDE_CONFIG_DIR/plugins   when CLAUDE_CONFIG_DIR is set
+  ~/.claude/plugins            on macOS and Linux
+  %USERPROFILE%\.claude\plugins on Windows
+
+<slug> comes from the plugin name (the mold name, or --plugin-name). The
+mold ref is a local directory or a remote reference, as cast takes, and is
+rendered with the same flux layers as cast --claude-plugin.
+
+Example:
+  ailloy plugin install github.com/nimble-giant/nimble-mold
+  ailloy plugin install ./my-mold --global --set org=acme`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeCachedRefs(cmd, nil, toComplete)
+	},
+	RunE: runInstallPlugin,
+}
+
+var listPluginCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List Claude Code plugins installed for this project and user",
+	Long: `List the plugins Claude Code discovers from the current directory: those
+in the project's .claude/plugins, then those in the user plugins directory
+(see 'ailloy plugin install --help'), with the version from each plugin's
+.claude-plugin/plugin.json.`,
+	Args: cobra.NoArgs,
+	RunE: runListPlugins,
+}
+
+var (
+	pluginInstallGlobal    bool
+	pluginInstallSetValues []string
+	pluginInstallValFiles  []string
+	pluginInstallName      string
+	pluginInstallVersion   string
+	pluginListOutput       string
+)
+
+func init() {
+	pluginCmd.AddCommand(installPluginCmd)
+	pluginCmd.AddCommand(listPluginCmd)
+
+	installPluginCmd.Flags().BoolVarP(&pluginInstallGlobal, "global", "g", false, "install into the user plugins directory instead of the project's")
+	installPluginCmd.Flags().StringArrayVar(&pluginInstallSetValues, "set", nil, "set flux values (key=value)")
+	installPluginCmd.Flags().StringArrayVarP(&pluginInstallValFiles, "values", "f", nil, "flux value files (can be repeated, later files override earlier)")
+	installPluginCmd.Flags().StringVar(&pluginInstallName, "plugin-name", "", "override the plugin name (default: mold name)")
+	installPluginCmd.Flags().StringVar(&pluginInstallVersion, "plugin-version", "", "override the plugin version (default: mold version)")
+
+	addOutputFlag(listPluginCmd, &pluginListOutput)
+}
+
+func runInstallPlugin(cmd *cobra.Command, args []string) error {
+	scope := plugin.ScopeProject
+	if pluginInstallGlobal {
+		scope = plugin.ScopeUser
+	}
+	previous, err := installedPluginsIn(scope)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(styles.WorkingBanner("Installing Claude Code plugin..."))
+	fmt.Println()
+
+	res, err := CastMold(cmd.Context(), args[0], CastOptions{
+		Global:        pluginInstallGlobal,
+		ValueFiles:    pluginInstallValFiles,
+		SetOverrides:  pluginInstallSetValues,
+		ClaudePlugin:  true,
+		PluginName:    pluginInstallName,
+		PluginVersion: pluginInstallVersion,
+		HistoryOp:     "plugin install",
+		HistoryFlags:  historyFlags(cmd),
+	})
+	if err != nil {
+		return err
+	}
+	dir := res.Dirs[0]
+	installed, _ := plugin.ReadInstalled(dir)
+
+	verb := "Installed "
+	version := installed.Version
+	if prev, ok := previous[dir]; ok {
+		verb = "Updated "
+		if prev.Version != installed.Version {
+			version = prev.Version + " → " + installed.Version
+		}
+	}
+	fmt.Println(styles.SuccessStyle.Render("✅ "+verb) + styles.AccentStyle.Render(installed.Name) + " " + version +
+		styles.SuccessStyle.Render(" in ") + styles.CodeStyle.Render(dir))
+	fmt.Println(styles.InfoStyle.Render("💡 Claude Code will discover the plugin at this path on its next start."))
+	return nil
+}
+
+// installedPluginsIn returns the plugins installed in the discovery directory
+// of scope, keyed by plugin directory.
+func installedPluginsIn(scope string) (map[string]plugin.InstalledPlugin, error) {
+	locations, err := plugin.DiscoveryDirs(".")
+	if err != nil {
+		return nil, err
+	}
+	var in []plugin.Location
+	for _, loc := range locations {
+		if loc.Scope == scope {
+			in = append(in, loc)
+		}
+	}
+	plugins, err := plugin.ListInstalled(in)
+	if err != nil {
+		return nil, err
+	}
+	byDir := make(map[string]plugin.InstalledPlugin, len(plugins))
+	for _, p := range plugins {
+		byDir[p.Dir] = p
+	}
+	return byDir, nil
+}
+
+// pluginListReport is plugin list's structured output.
+type pluginListReport struct {
+	Locations []plugin.Location        `json:"locations" yaml:"locations"`
+	Plugins   []plugin.InstalledPlugin `json:"plugins" yaml:"plugins"`
+}
+
+func runListPlugins(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(pluginListOutput); err != nil {
+		return err
+	}
+	locations, err := plugin.DiscoveryDirs(".")
+	if err != nil {
+		return err
+	}
+	plugins, err := plugin.ListInstalled(locations)
+	if err != nil {
+		return err
+	}
+	if pluginListOutput != "" {
+		if plugins == nil {
+			plugins = []plugin.InstalledPlugin{}
+		}
+		return writeStructured(cmd.OutOrStdout(), pluginListOutput, pluginListReport{Locations: locations, Plugins: plugins})
+	}
+
+	fmt.Println(styles.HeaderStyle.Render("Claude Code plugins"))
+	for _, loc := range locations {
+		fmt.Println()
+		fmt.Println(styles.InfoStyle.Render(loc.Dir) + styles.SubtleStyle.Render(" ("+loc.Scope+")"))
+		found := false
+		for _, p := range plugins {
+			if p.Scope != loc.Scope {
+				continue
+			}
+			found = true
+			line := "  " + styles.AccentStyle.Render(p.Name)
+			if p.Version != "" {
+				line += " " + p.Version
+			}
+			if p.Description != "" {
+				line += styles.SubtleStyle.Render(" - " + p.Description)
+			}
+			fmt.Println(line)
+		}
+		if !found {
+			fmt.Println(styles.SubtleStyle.Render("  No plugins installed."))
+		}
+	}
+	return nil
+}
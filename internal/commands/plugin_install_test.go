@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+func TestPluginInstallAndList(t *testing.T) {
+	resetCastFlags()
+	defer resetCastFlags()
+
+	tmp := t.TempDir()
+	t.Setenv("HOME", filepath.Join(tmp, "home"))
+	t.Setenv(plugin.ClaudeConfigDirEnv, filepath.Join(tmp, "claude-config"))
+	moldDir := filepath.Join(tmp, "mold")
+	writeFixtureMoldToDisk(t, moldDir)
+	work := filepath.Join(tmp, "work")
+	if err := os.MkdirAll(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, work)
+
+	pluginInstallGlobal = true
+	pluginInstallVersion = "2.0.0"
+	t.Cleanup(func() { pluginInstallGlobal, pluginInstallVersion, pluginListOutput = false, "", "" })
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(t.Context())
+	if err := runInstallPlugin(cmd, []string{moldDir}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	mustFile(t, filepath.Join(tmp, "claude-config", "plugins", "fixture-mold", ".claude-plugin", "plugin.json"))
+
+	pluginListOutput = outputJSON
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	if err := runListPlugins(cmd, nil); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var report pluginListReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decoding %q: %v", out.String(), err)
+	}
+	if len(report.Locations) != 2 || report.Locations[1].Dir != filepath.Join(tmp, "claude-config", "plugins") {
+		t.Errorf("locations = %+v", report.Locations)
+	}
+	if len(report.Plugins) != 1 {
+		t.Fatalf("plugins = %+v, want the installed fixture", report.Plugins)
+	}
+	if p := report.Plugins[0]; p.Name != "fixture-mold" || p.Version != "2.0.0" || p.Scope != plugin.ScopeUser {
+		t.Errorf("plugin = %+v", p)
+	}
+}
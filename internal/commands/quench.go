@@ -54,7 +54,10 @@ func runQuench(_ *cobra.Command, args []string) error {
 
 	// Read the existing lock up front so we can both verify against it and
 	// reject scoped quench when there's nothing to scope into.
-	existingLock, _ := foundry.ReadLockFile(lockPath)
+	existingLock, err := foundry.ReadLockFile(lockPath)
+	if foundry.IsSchemaTooNew(err) {
+		return err
+	}
 
 	// Filter to a single ref if provided. Scoped quench requires an existing
 	// lock — otherwise we'd silently drop every other manifest entry by writing
@@ -94,7 +97,7 @@ func runQuench(_ *cobra.Command, args []string) error {
 	}
 	if len(failures) > 0 && quenchVerify {
 		fmt.Println()
-		return fmt.Errorf("verification failed (%d issue(s))", len(failures))
+		return validationError(fmt.Errorf("verification failed (%d issue(s))", len(failures)))
 	}
 
 	if quenchVerify {
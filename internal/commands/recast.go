@@ -3,12 +3,14 @@ package commands
 import (
 	"fmt"
 	"log"
+	"os"
 	"slices"
-	"strings"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/internal/tui/ceremony"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +29,11 @@ Flags supplied here layer on top of the options the original cast recorded
 in installed.yaml. --set and --values overrides are persisted back; the
 recovery flag --force-replace-on-parse-error is not.
 
+Files edited since they were cast (their provenance header no longer matches
+their content) are not overwritten: the new version is three-way merged into
+the edits when the changes don't overlap, and the file is kept as it is
+otherwise. Use --overwrite-modified to replace them anyway.
+
 Use --global/-g to operate on the manifest under ~/ instead of the current
 project. Use --dry-run to preview which molds will move, without re-rendering.`,
 	Args: cobra.MaximumNArgs(1),
@@ -39,10 +46,17 @@ var (
 	recastSetFlags      []string
 	recastValFiles      []string
 	recastWithWorkflows bool
+	recastCI            string
+	recastProfile       string
 	recastForceReplace  bool
 	// recastFrozen mirrors --frozen on cast: fail (do not auto-install) on
 	// any declared ingot/ore dep that's missing from .ailloy/.
 	recastFrozen bool
+	// recastNoHooks skips the mold's pre-upgrade and post-cast hooks.
+	recastNoHooks bool
+	// recastOverwriteModified replaces locally edited files instead of
+	// merging into or keeping them.
+	recastOverwriteModified bool
 )
 
 // recastCLIOptions holds the option-shaped flags supplied for THIS recast run.
@@ -50,8 +64,10 @@ var (
 // merge algorithm has explicit "recorded" and "this-run" inputs.
 type recastCLIOptions struct {
 	WithWorkflows            bool
+	CI                       string
 	ValueFiles               []string
 	SetOverrides             []string
+	Profile                  string
 	ForceReplaceOnParseError bool // run-time only, never persisted
 }
 
@@ -60,17 +76,20 @@ type recastCLIOptions struct {
 // ForceReplaceOnParseError is intentionally excluded — a recovery flag alone
 // should not force a re-render of an already-up-to-date mold.
 func (o recastCLIOptions) hasOverrides() bool {
-	return o.WithWorkflows || len(o.ValueFiles) > 0 || len(o.SetOverrides) > 0
+	return o.WithWorkflows || o.CI != "" || len(o.ValueFiles) > 0 || len(o.SetOverrides) > 0 || o.Profile != ""
 }
 
 // mergeRecastOptions composes the persisted (recorded) options with this run's
 // CLI flags. CLI flags layer on top of recorded options:
 //
 //   - WithWorkflows is OR'd (CLI cannot turn off a recorded true).
+//   - CI: a CLI CI system replaces the recorded one.
 //   - ValueFiles: recorded first, CLI appended; dedupe on exact path.
 //   - SetOverrides: recorded first, CLI appended; if a CLI override has the
 //     same dotted key as a recorded entry, the recorded entry is replaced
-//     in place rather than duplicated.
+//     in place rather than duplicated. A recorded --set-file or --set-json
+//     entry for that key is dropped.
+//   - Profile: a CLI profile replaces the recorded one.
 //
 // The returned record is what we persist back to the manifest after a
 // successful recast. ForceReplaceOnParseError is not part of the result.
@@ -80,9 +99,17 @@ func mergeRecastOptions(recorded *foundry.CastOptionsRecord, cli recastCLIOption
 		rec = *recorded
 		rec.ValueFiles = append([]string(nil), recorded.ValueFiles...)
 		rec.SetOverrides = append([]string(nil), recorded.SetOverrides...)
+		rec.SetFiles = append([]string(nil), recorded.SetFiles...)
+		rec.SetJSON = append([]string(nil), recorded.SetJSON...)
 	}
 
 	rec.WithWorkflows = rec.WithWorkflows || cli.WithWorkflows
+	if cli.CI != "" {
+		rec.CI = cli.CI
+	}
+	if cli.Profile != "" {
+		rec.Profile = cli.Profile
+	}
 
 	for _, f := range cli.ValueFiles {
 		if !slices.Contains(rec.ValueFiles, f) {
@@ -92,6 +119,9 @@ func mergeRecastOptions(recorded *foundry.CastOptionsRecord, cli recastCLIOption
 
 	for _, kv := range cli.SetOverrides {
 		key := setOverrideKey(kv)
+		sameKey := func(existing string) bool { return setOverrideKey(existing) == key }
+		rec.SetFiles = slices.DeleteFunc(rec.SetFiles, sameKey)
+		rec.SetJSON = slices.DeleteFunc(rec.SetJSON, sameKey)
 		replaced := false
 		for i, existing := range rec.SetOverrides {
 			if setOverrideKey(existing) == key {
@@ -110,24 +140,25 @@ func mergeRecastOptions(recorded *foundry.CastOptionsRecord, cli recastCLIOption
 	return rec
 }
 
-// setOverrideKey returns the LHS of a `key=value` --set string, trimmed of
-// whitespace. Mirrors the trim behavior in mold.ApplySetOverrides
-// (pkg/mold/flux.go) so dedupe matches what the renderer ultimately sees.
-// Inputs without "=" return the entire string trimmed (treated as a single-
-// key entry).
+// setOverrideKey returns the flux key a recorded --set, --set-file or
+// --set-json entry sets (see mold.SetOverrideKey), so a new --set replaces
+// a recorded --set-file for the same key.
 func setOverrideKey(kv string) string {
-	parts := strings.SplitN(kv, "=", 2)
-	return strings.TrimSpace(parts[0])
+	return mold.SetOverrideKey(kv)
 }
 
 func init() {
 	rootCmd.AddCommand(recastCmd)
 	recastCmd.Flags().BoolVar(&recastDryRun, "dry-run", false, "preview changes without applying")
 	recastCmd.Flags().BoolVarP(&recastGlobal, "global", "g", false, "operate on the global manifest/lock under ~/")
-	recastCmd.Flags().BoolVar(&recastWithWorkflows, "with-workflows", false, "include GitHub Actions workflow blanks (OR'd with the recorded value)")
+	recastCmd.Flags().BoolVar(&recastWithWorkflows, "with-workflows", false, "include workflow blanks for the repository's CI system (OR'd with the recorded value)")
+	recastCmd.Flags().StringVar(&recastCI, "ci", "", "CI system to include workflow blanks for: github, gitlab, circle or azure (replaces the recorded one)")
 	recastCmd.Flags().StringArrayVar(&recastSetFlags, "set", nil, "override flux variable (key=value, repeatable; supports dotted keys)")
 	recastCmd.Flags().StringArrayVarP(&recastValFiles, "values", "f", nil, "flux value file (repeatable; later files override earlier)")
+	recastCmd.Flags().StringVar(&recastProfile, "profile", "", "output profile to recast with (replaces the recorded profile)")
 	recastCmd.Flags().BoolVar(&recastForceReplace, "force-replace-on-parse-error", false, "replace unparseable merge-strategy destinations instead of erroring")
+	recastCmd.Flags().BoolVar(&recastNoHooks, "no-hooks", false, "do not run the pre-upgrade and post-cast hook scripts declared in mold.yaml")
+	recastCmd.Flags().BoolVar(&recastOverwriteModified, "overwrite-modified", false, "replace files edited since they were cast instead of merging the new version into them")
 	recastCmd.Flags().BoolVar(&recastFrozen, "frozen", false, "fail (do not auto-install) when a declared ingot/ore dep is missing from .ailloy/; intended for CI")
 }
 
@@ -165,18 +196,28 @@ func runRecast(cmd *cobra.Command, args []string) error {
 
 	cli := recastCLIOptions{
 		WithWorkflows:            recastWithWorkflows,
+		CI:                       recastCI,
 		ValueFiles:               recastValFiles,
 		SetOverrides:             recastSetFlags,
+		Profile:                  recastProfile,
 		ForceReplaceOnParseError: recastForceReplace,
 	}
 
 	if recastDryRun {
-		fmt.Println(styles.WorkingBanner("Previewing dependency updates (dry run)..."))
-		fmt.Println()
+		logging.Decor(styles.WorkingBanner("Previewing dependency updates (dry run)..."), "")
 	} else {
 		ceremony.Open(ceremony.Recast)
 	}
 
+	root := "."
+	if recastGlobal {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		root = home
+	}
+
 	git := foundry.DefaultGitRunner()
 	var changes []recastChange
 	failures := 0
@@ -184,21 +225,21 @@ func runRecast(cmd *cobra.Command, args []string) error {
 	for _, entry := range entries {
 		ref, refErr := referenceFromInstalledEntry(&entry)
 		if refErr != nil {
-			fmt.Printf("%s skipping %s: %v\n", styles.WarningStyle.Render("!"), entry.Name, refErr)
+			log.Printf("warning: skipping %s: %v", entry.Name, refErr)
 			failures++
 			continue
 		}
 		resolved, resolveErr := foundry.ResolveVersion(ref, git)
 		if resolveErr != nil {
-			fmt.Printf("%s skipping %s: %v\n", styles.WarningStyle.Render("!"), entry.Name, resolveErr)
+			log.Printf("warning: skipping %s: %v", entry.Name, resolveErr)
 			failures++
 			continue
 		}
 
 		versionUnchanged := resolved.Tag == entry.Version && resolved.Commit == entry.Commit
 		if versionUnchanged && !cli.hasOverrides() {
-			fmt.Println(styles.InfoStyle.Render("  ") + entry.Name + " is already up to date (" +
-				styles.CodeStyle.Render(entry.Version) + ")")
+			logging.Say(styles.InfoStyle.Render("  ")+entry.Name+" is already up to date ("+styles.CodeStyle.Render(entry.Version)+")",
+				"already up to date", "mold", entry.Name, "version", entry.Version)
 			continue
 		}
 
@@ -226,15 +267,44 @@ func runRecast(cmd *cobra.Command, args []string) error {
 		castOpts := CastOptions{
 			Global:                   recastGlobal,
 			WithWorkflows:            effective.WithWorkflows,
+			CI:                       effective.CI,
 			ValueFiles:               effective.ValueFiles,
 			SetOverrides:             effective.SetOverrides,
+			SetFiles:                 effective.SetFiles,
+			SetJSON:                  effective.SetJSON,
+			Profile:                  effective.Profile,
+			Only:                     effective.Only,
+			Exclude:                  effective.Exclude,
 			ForceReplaceOnParseError: cli.ForceReplaceOnParseError,
+			HistoryOp:                "recast",
+			HistoryFlags:             historyFlags(cmd),
+		}
+		if !recastNoHooks {
+			castOpts.Hooks = mold.HookPreUpgrade
+		}
+		if !recastOverwriteModified {
+			castOpts.KeepLocalEdits = true
+			castOpts.MergeBases = recastMergeBases(cmd.Context(), root, &entry, ref, recastGlobal)
 		}
-		if _, castErr := CastMold(cmd.Context(), versionedRef, castOpts); castErr != nil {
-			fmt.Printf("%s skipping %s: %v\n", styles.WarningStyle.Render("!"), entry.Name, castErr)
+		res, castErr := CastMold(cmd.Context(), versionedRef, castOpts)
+		if castErr != nil {
+			log.Printf("warning: skipping %s: %v", entry.Name, castErr)
 			failures++
 			continue
 		}
+		for _, path := range res.Merged {
+			logging.Say(styles.InfoStyle.Render("  ")+entry.Name+": merged the new version into your edits to "+styles.CodeStyle.Render(displayPath(path)),
+				"merged the new version into local edits", "mold", entry.Name, "path", displayPath(path))
+		}
+		for _, path := range res.Kept {
+			log.Printf("warning: %s: kept your edits to %s; the new version was not applied (re-run with --overwrite-modified to replace it)",
+				entry.Name, displayPath(path))
+		}
+		if res.Backup != "" {
+			logging.Say(styles.InfoStyle.Render("  ")+entry.Name+": backed up the files it overwrote as "+
+				styles.CodeStyle.Render(res.Backup)+styles.SubtleStyle.Render(" (ailloy backups restore "+res.Backup+")"),
+				"backed up overwritten files", "mold", entry.Name, "backup", res.Backup)
+		}
 
 		// Reconcile the freshly resolved mold's dependency graph: install
 		// any newly declared deps and prune any that the mold no longer
@@ -248,8 +318,10 @@ func runRecast(cmd *cobra.Command, args []string) error {
 		}
 		if fetcher, ferr := foundry.NewFetcher(git); ferr == nil {
 			if fetchedFS, _, fetchErr := fetcher.Fetch(ref, resolved); fetchErr == nil {
-				reader := blanks.NewMoldReader(fetchedFS)
-				if freshMold, mErr := reader.LoadManifest(); mErr != nil {
+				reader, cErr := ComposeMoldReader(blanks.NewMoldReader(fetchedFS), ref.OverrideKey())
+				if cErr != nil {
+					log.Printf("warning: composing fresh mold for %s: %v", entry.Name, cErr)
+				} else if freshMold, mErr := reader.LoadManifest(); mErr != nil {
 					log.Printf("warning: loading fresh mold manifest for %s: %v", entry.Name, mErr)
 				} else if freshMold != nil {
 					// Auto-install newly declared deps. Recast operates on the
@@ -268,13 +340,12 @@ func runRecast(cmd *cobra.Command, args []string) error {
 
 		// CastMold has already upserted the manifest entry; now overlay the
 		// merged effective options so subsequent recasts replay them.
-		if persistErr := persistEffectiveOptions(manifestPath, entry.Source, entry.Subpath, effective); persistErr != nil {
+		if persistErr := persistEffectiveOptions(manifestPath, entry.Source, entry.Subpath, entry.Ref, effective); persistErr != nil {
 			// The on-disk files were updated by CastMold, but we couldn't record
 			// the effective options on the manifest entry. Surface as a failure
 			// (non-zero exit) so it doesn't get silently lost — this state means
 			// the next recast won't replay these options.
-			fmt.Printf("%s %s: re-rendered, but failed to persist options: %v\n",
-				styles.WarningStyle.Render("!"), entry.Name, persistErr)
+			log.Printf("warning: %s: re-rendered, but failed to persist options: %v", entry.Name, persistErr)
 			failures++
 		}
 
@@ -282,18 +353,18 @@ func runRecast(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(changes) == 0 && failures == 0 {
-		fmt.Println()
-		fmt.Println(styles.SuccessStyle.Render("All dependencies are up to date."))
+		logging.Decor("")
+		logging.Say(styles.SuccessStyle.Render("All dependencies are up to date."), "all dependencies are up to date")
 		return nil
 	}
 
-	fmt.Println()
+	logging.Decor("")
 	if recastDryRun {
-		fmt.Println(styles.InfoStyle.Render("Changes that would be applied:"))
+		logging.Decor(styles.InfoStyle.Render("Changes that would be applied:"))
 	} else if len(changes) > 0 {
-		fmt.Println(styles.SuccessStyle.Render("Updated dependencies:"))
+		logging.Decor(styles.SuccessStyle.Render("Updated dependencies:"))
 	}
-	fmt.Println()
+	logging.Decor("")
 	for _, c := range changes {
 		line := fmt.Sprintf("  %s  %s %s %s",
 			styles.FoxBullet(c.Name),
@@ -308,17 +379,19 @@ func runRecast(cmd *cobra.Command, args []string) error {
 				line += "  " + styles.InfoStyle.Render("(options overridden)")
 			}
 		}
-		fmt.Println(line)
+		msg := "updated"
+		if recastDryRun {
+			msg = "would update"
+		}
+		logging.Say(line, msg, "mold", c.Name, "from", c.OldVersion, "to", c.NewVersion, "options_changed", c.OptionsChanged)
 	}
 
 	if !recastDryRun && len(changes) > 0 {
-		fmt.Println()
-		fmt.Println(styles.SuccessBanner("Recast complete!"))
+		logging.Decor("", styles.SuccessBanner("Recast complete!"))
 		ceremony.Stamp(ceremony.Recast, fmt.Sprintf("%d mold(s) updated", len(changes)))
 	}
 	if recastDryRun {
-		fmt.Println()
-		fmt.Println(styles.InfoStyle.Render("Run without --dry-run to apply these changes."))
+		logging.Decor("", styles.InfoStyle.Render("Run without --dry-run to apply these changes."))
 	}
 
 	if failures > 0 {
@@ -353,7 +426,11 @@ func buildVersionedRefString(ref *foundry.Reference, tag string) string {
 // manifest from earlier in the recast loop, since CastMold's writes would be
 // silently overwritten on save. The TOCTOU window is acceptable here because
 // no other process writes to installed.yaml during a recast.
-func persistEffectiveOptions(manifestPath, source, subpath string, eff foundry.CastOptionsRecord) error {
+//
+// ref restores the reference the mold was originally cast with: CastMold
+// records the exact tag recast pinned it to, which would otherwise replace
+// the user's constraint. An empty ref leaves the recorded one alone.
+func persistEffectiveOptions(manifestPath, source, subpath, ref string, eff foundry.CastOptionsRecord) error {
 	manifest, err := foundry.ReadInstalledManifest(manifestPath)
 	if err != nil {
 		return err
@@ -365,10 +442,17 @@ func persistEffectiveOptions(manifestPath, source, subpath string, eff foundry.C
 	if target == nil {
 		return fmt.Errorf("entry %s/%s not found after recast", source, subpath)
 	}
-	if eff.WithWorkflows || len(eff.ValueFiles) > 0 || len(eff.SetOverrides) > 0 {
+	if ref != "" {
+		target.Ref = ref
+	}
+	if eff.WithWorkflows || eff.CI != "" || len(eff.ValueFiles) > 0 || len(eff.SetOverrides) > 0 || len(eff.SetFiles) > 0 || len(eff.SetJSON) > 0 || eff.Profile != "" || len(eff.Only) > 0 || len(eff.Exclude) > 0 {
 		copied := eff
-		copied.ValueFiles = append([]string(nil), eff.ValueFiles...)
+		copied.ValueFiles = recordedValueFiles(eff.ValueFiles)
 		copied.SetOverrides = append([]string(nil), eff.SetOverrides...)
+		copied.SetFiles = append([]string(nil), eff.SetFiles...)
+		copied.SetJSON = append([]string(nil), eff.SetJSON...)
+		copied.Only = append([]string(nil), eff.Only...)
+		copied.Exclude = append([]string(nil), eff.Exclude...)
 		target.CastOptions = &copied
 	} else {
 		target.CastOptions = nil
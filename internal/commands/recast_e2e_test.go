@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 )
@@ -343,3 +344,74 @@ func TestE2E_Recast_PartialFailureExitsNonZero(t *testing.T) {
 	}
 	t.Skip("TODO: requires multi-remote sandbox; tracked as follow-up to the recast e2e harness")
 }
+
+// TestE2E_Status_RendersRenamedFlux casts a value set under a variable's
+// renamed_from name and checks that status re-renders it the way cast did,
+// so the file isn't reported outdated.
+func TestE2E_Status_RendersRenamedFlux(t *testing.T) {
+	if testing.Short() {
+		t.Skip("e2e binary build is slow; skipping in -short mode")
+	}
+
+	env := setupRecastE2EEnv(t)
+	schema := "- name: foo\n  type: string\n  renamed_from: old_foo\n"
+	if err := os.WriteFile(filepath.Join(env.repoDir, "flux.schema.yaml"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env.commitAndTag(t, "rename foo", "v1.1.0")
+	project := t.TempDir()
+
+	if out, err := env.run(t, project, "cast", env.refString(), "--set", "old_foo=legacy"); err != nil {
+		t.Fatalf("cast failed: %v\n%s", err, out)
+	}
+	got, _ := os.ReadFile(filepath.Join(project, "README.md"))
+	if !strings.Contains(string(got), "foo=legacy") {
+		t.Fatalf("cast did not migrate old_foo into foo:\n%s", got)
+	}
+
+	if out, err := env.run(t, project, "status", "--check"); err != nil {
+		t.Fatalf("status --check reported drift after a clean cast: %v\n%s", err, out)
+	}
+}
+
+// TestE2E_Status_PinsCastStamp casts a blank that prints the cast time and
+// the project's HEAD commit, commits in the project, and checks that status
+// re-renders with the values the cast recorded instead of reporting drift.
+func TestE2E_Status_PinsCastStamp(t *testing.T) {
+	if testing.Short() {
+		t.Skip("e2e binary build is slow; skipping in -short mode")
+	}
+
+	env := setupRecastE2EEnv(t)
+	env.writeMoldFiles(t, "1.1.0", " at {{ ._ailloy.timestamp }} on {{ ._ailloy.git.commit }}")
+	env.commitAndTag(t, "stamp", "v1.1.0")
+
+	project := t.TempDir()
+	gitInProject := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = project
+		cmd.Env = env.sandboxEnv()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	gitInProject("init", "-q")
+	gitInProject("-c", "user.email=test@example.com", "-c", "user.name=ailloy-test", "commit", "-q", "--allow-empty", "-m", "initial")
+
+	if out, err := env.run(t, project, "cast", env.refString(), "--set", "foo=bar"); err != nil {
+		t.Fatalf("cast failed: %v\n%s", err, out)
+	}
+	got, _ := os.ReadFile(filepath.Join(project, "README.md"))
+	if !strings.Contains(string(got), " at 20") {
+		t.Fatalf("README.md doesn't carry the cast timestamp:\n%s", got)
+	}
+
+	// Move HEAD and let the clock pass a second.
+	gitInProject("-c", "user.email=test@example.com", "-c", "user.name=ailloy-test", "commit", "-q", "--allow-empty", "-m", "later")
+	time.Sleep(1100 * time.Millisecond)
+
+	if out, err := env.run(t, project, "status", "--check"); err != nil {
+		t.Fatalf("status --check reported drift from the cast stamp: %v\n%s", err, out)
+	}
+}
@@ -48,12 +48,24 @@ func TestMergeRecastOptions(t *testing.T) {
 				SetOverrides:  []string{"k=v"},
 			},
 		},
+		{
+			name:     "recorded selection is replayed",
+			recorded: &foundry.CastOptionsRecord{Only: []string{"commands/pr-*"}, Exclude: []string{"review"}},
+			cli:      recastCLIOptions{Profile: "cursor"},
+			want:     foundry.CastOptionsRecord{Only: []string{"commands/pr-*"}, Exclude: []string{"review"}, Profile: "cursor"},
+		},
 		{
 			name:     "with-workflows is OR'd",
 			recorded: &foundry.CastOptionsRecord{WithWorkflows: false},
 			cli:      recastCLIOptions{WithWorkflows: true},
 			want:     foundry.CastOptionsRecord{WithWorkflows: true},
 		},
+		{
+			name:     "ci: CLI replaces recorded",
+			recorded: &foundry.CastOptionsRecord{WithWorkflows: true, CI: "github"},
+			cli:      recastCLIOptions{CI: "gitlab"},
+			want:     foundry.CastOptionsRecord{WithWorkflows: true, CI: "gitlab"},
+		},
 		{
 			name:     "value files: recorded first, CLI appended",
 			recorded: &foundry.CastOptionsRecord{ValueFiles: []string{"./a.yaml"}},
@@ -90,6 +102,23 @@ func TestMergeRecastOptions(t *testing.T) {
 			cli:      recastCLIOptions{SetOverrides: []string{"a=99", "c=3"}},
 			want:     foundry.CastOptionsRecord{SetOverrides: []string{"a=99", "b=2", "c=3"}},
 		},
+		{
+			name:     "set: drops a recorded set-file or set-json for the key",
+			recorded: &foundry.CastOptionsRecord{SetFiles: []string{"a=./a.md", "b=./b.md"}, SetJSON: []string{"a=1"}},
+			cli:      recastCLIOptions{SetOverrides: []string{"a=2"}},
+			want:     foundry.CastOptionsRecord{SetOverrides: []string{"a=2"}, SetFiles: []string{"b=./b.md"}, SetJSON: []string{}},
+		},
+		{
+			name:     "profile: recorded kept when CLI is empty",
+			recorded: &foundry.CastOptionsRecord{Profile: "cursor"},
+			want:     foundry.CastOptionsRecord{Profile: "cursor"},
+		},
+		{
+			name:     "profile: CLI replaces recorded",
+			recorded: &foundry.CastOptionsRecord{Profile: "cursor"},
+			cli:      recastCLIOptions{Profile: "codex"},
+			want:     foundry.CastOptionsRecord{Profile: "codex"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"golang.org/x/sync/errgroup"
+)
+
+// blankError is a blank that failed to read or render.
+type blankError struct {
+	Path string // source path within the mold
+	Err  error
+}
+
+// renderErrors lists every blank of a render pass that failed, in resolved
+// order, so a mold author sees all broken templates in one run.
+type renderErrors []blankError
+
+func (e renderErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("rendering %s: %v", e[0].Path, e[0].Err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d blanks failed to render:", len(e))
+	for _, be := range e {
+		fmt.Fprintf(&b, "\n  %s: %v", be.Path, be.Err)
+	}
+	return b.String()
+}
+
+// renderBlanks reads and renders resolved on castWorkers goroutines and
+// returns each file's content in resolved order. Files without Process are
+// returned as read. Every file is attempted; when any fail the error is a
+// renderErrors listing all of them. resolver is cloned per file; logger
+// (nil for the default) receives template warnings, and step, when
+// non-nil, is called from the workers as each file finishes.
+func renderBlanks(primary fs.FS, resolved []mold.ResolvedFile, flux map[string]any, resolver *mold.IngotResolver, logger *log.Logger, step func()) ([][]byte, error) {
+	contents := make([][]byte, len(resolved))
+	failures := make([]error, len(resolved))
+	var eg errgroup.Group
+	eg.SetLimit(castWorkers())
+	for i, rf := range resolved {
+		eg.Go(func() error {
+			defer func() {
+				if step != nil {
+					step()
+				}
+			}()
+			content, err := fs.ReadFile(chooseFS(rf, primary), rf.SrcPath)
+			if err != nil {
+				failures[i] = fmt.Errorf("reading: %w", err)
+				return nil
+			}
+			if rf.Process {
+				fluxForFile := flux
+				if len(rf.Set) > 0 {
+					fluxForFile = mold.MergeSet(flux, rf.Set)
+				}
+				opts := []mold.TemplateOption{mold.WithIngotResolver(resolver.Clone())}
+				if logger != nil {
+					opts = append(opts, mold.WithLogger(logger))
+				}
+				processed, err := mold.ProcessTemplate(string(content), fluxForFile, opts...)
+				if err != nil {
+					failures[i] = err
+					return nil
+				}
+				content = []byte(processed)
+			}
+			contents[i] = content
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	var errs renderErrors
+	for i, err := range failures {
+		if err != nil {
+			errs = append(errs, blankError{Path: resolved[i].SrcPath, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, renderError(errs)
+	}
+	return contents, nil
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestRenderBlanks_KeepsOrder(t *testing.T) {
+	fsys := fstest.MapFS{}
+	var resolved []mold.ResolvedFile
+	for i := range 40 {
+		name := fmt.Sprintf("commands/c%02d.md", i)
+		fsys[name] = &fstest.MapFile{Data: []byte(fmt.Sprintf("%d {{team}}", i))}
+		resolved = append(resolved, mold.ResolvedFile{SrcPath: name, Process: true})
+	}
+	var steps atomic.Int32
+	contents, err := renderBlanks(fsys, resolved, map[string]any{"team": "core"}, mold.NewIngotResolver(nil, nil), nil, func() { steps.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range contents {
+		if want := fmt.Sprintf("%d core", i); string(c) != want {
+			t.Fatalf("contents[%d] = %q, want %q", i, c, want)
+		}
+	}
+	if int(steps.Load()) != len(resolved) {
+		t.Errorf("step called %d times, want %d", steps.Load(), len(resolved))
+	}
+}
+
+func TestRenderBlanks_ReportsEveryBrokenBlank(t *testing.T) {
+	fsys := fstest.MapFS{
+		"commands/a.md":  {Data: []byte("{{ if }}")},
+		"commands/ok.md": {Data: []byte("fine")},
+		"commands/b.md":  {Data: []byte("{{ end }}")},
+	}
+	resolved := []mold.ResolvedFile{
+		{SrcPath: "commands/a.md", Process: true},
+		{SrcPath: "commands/ok.md", Process: true},
+		{SrcPath: "commands/b.md", Process: true},
+		{SrcPath: "commands/missing.md"},
+	}
+	_, err := renderBlanks(fsys, resolved, map[string]any{}, mold.NewIngotResolver(nil, nil), nil, nil)
+	if err == nil {
+		t.Fatal("expected render errors")
+	}
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "3 blanks failed to render:") {
+		t.Errorf("error should count the broken blanks, got:\n%s", msg)
+	}
+	a, b, missing := strings.Index(msg, "commands/a.md"), strings.Index(msg, "commands/b.md"), strings.Index(msg, "commands/missing.md")
+	if a < 0 || b < a || missing < b || strings.Contains(msg, "commands/ok.md") {
+		t.Errorf("error should list the broken blanks in order, got:\n%s", msg)
+	}
+	if got := exitCodeFor(err); got != ExitRender {
+		t.Errorf("exit code %d, want %d", got, ExitRender)
+	}
+}
+
+func TestRenderErrors_SingleBlank(t *testing.T) {
+	err := renderErrors{{Path: "commands/a.md", Err: fmt.Errorf("boom")}}
+	if got := err.Error(); got != "rendering commands/a.md: boom" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,409 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/scope"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Print an anonymized environment report to attach to bug reports",
+	Long: `Print an anonymized environment report to attach to bug reports.
+
+Collects, from this machine only, what a maintainer usually asks for when
+triaging an issue:
+  - the ailloy version, OS and architecture, and git/gh versions
+  - a summary of config.yaml: scopes present, foundry and mirror counts,
+    resolution policy, exec policy
+  - the molds, ingots and ores in the project and global installed.yaml,
+    with their versions, commits and cast options
+  - the molds and versions in the mold cache
+  - doctor's checks, without the network one
+  - temper diagnostics for each installed mold, read from the cache
+
+Nothing is sent anywhere: the report is printed for you to review and
+attach. It is anonymized: your home directory becomes ~, the working
+directory becomes ., --set values are dropped (their keys are kept),
+and foundry names and URLs are counted, not listed. --redact-sources
+also replaces mold, ingot and ore sources with a short hash, for
+private repositories.`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+var (
+	reportOutput        string
+	reportRedactSources bool
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", outputYAML, "report format: yaml or json")
+	reportCmd.Flags().BoolVar(&reportRedactSources, "redact-sources", false, "replace mold, ingot and ore sources with a short hash")
+}
+
+// usageReport is what ailloy report prints.
+type usageReport struct {
+	Generated string           `json:"generated" yaml:"generated"`
+	Ailloy    string           `json:"ailloy" yaml:"ailloy"`
+	System    reportSystem     `json:"system" yaml:"system"`
+	Config    reportConfig     `json:"config" yaml:"config"`
+	Installed []reportManifest `json:"installed" yaml:"installed"`
+	Cache     reportCache      `json:"cache" yaml:"cache"`
+	Checks    []doctorCheck    `json:"checks" yaml:"checks"`
+}
+
+type reportSystem struct {
+	OS   string `json:"os" yaml:"os"`
+	Arch string `json:"arch" yaml:"arch"`
+	Go   string `json:"go" yaml:"go"`
+	Git  string `json:"git,omitempty" yaml:"git,omitempty"`
+	GH   string `json:"gh,omitempty" yaml:"gh,omitempty"`
+}
+
+type reportScope struct {
+	Name     string `json:"name" yaml:"name"`
+	Exists   bool   `json:"exists" yaml:"exists"`
+	Writable bool   `json:"writable" yaml:"writable"`
+}
+
+// reportConfig summarizes the effective config.yaml. Foundries and mirrors
+// are counted rather than listed: their URLs can name private hosts.
+type reportConfig struct {
+	Scopes          []reportScope `json:"scopes" yaml:"scopes"`
+	Foundries       int           `json:"foundries" yaml:"foundries"`
+	SystemFoundries int           `json:"systemFoundries" yaml:"systemFoundries"`
+	Mirrors         int           `json:"mirrors" yaml:"mirrors"`
+	Resolution      string        `json:"resolution,omitempty" yaml:"resolution,omitempty"`
+	Profile         string        `json:"profile,omitempty" yaml:"profile,omitempty"`
+	EvolveChannel   string        `json:"evolveChannel,omitempty" yaml:"evolveChannel,omitempty"`
+	ExecDisabled    bool          `json:"execDisabled" yaml:"execDisabled"`
+	ExecAllow       []string      `json:"execAllow,omitempty" yaml:"execAllow,omitempty"`
+	Error           string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// reportManifest is one installed.yaml.
+type reportManifest struct {
+	Scope  string           `json:"scope" yaml:"scope"` // project or global
+	Path   string           `json:"path" yaml:"path"`
+	Molds  []reportMold     `json:"molds" yaml:"molds"`
+	Ingots []reportArtifact `json:"ingots,omitempty" yaml:"ingots,omitempty"`
+	Ores   []reportArtifact `json:"ores,omitempty" yaml:"ores,omitempty"`
+	Error  string           `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type reportMold struct {
+	Name        string             `json:"name" yaml:"name"`
+	Source      string             `json:"source" yaml:"source"`
+	Version     string             `json:"version" yaml:"version"`
+	Commit      string             `json:"commit,omitempty" yaml:"commit,omitempty"`
+	CastAt      string             `json:"castAt,omitempty" yaml:"castAt,omitempty"`
+	Files       int                `json:"files" yaml:"files"`
+	InstalledAs string             `json:"installedAs,omitempty" yaml:"installedAs,omitempty"`
+	Options     *reportCastOptions `json:"castOptions,omitempty" yaml:"castOptions,omitempty"`
+	Temper      reportTemper       `json:"temper" yaml:"temper"`
+}
+
+// reportCastOptions mirrors foundry.CastOptionsRecord with --set values
+// dropped.
+type reportCastOptions struct {
+	WithWorkflows bool     `json:"withWorkflows,omitempty" yaml:"withWorkflows,omitempty"`
+	CI            string   `json:"ci,omitempty" yaml:"ci,omitempty"`
+	Profile       string   `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Only          []string `json:"only,omitempty" yaml:"only,omitempty"`
+	Exclude       []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	ValueFiles    []string `json:"valueFiles,omitempty" yaml:"valueFiles,omitempty"`
+	SetKeys       []string `json:"setKeys,omitempty" yaml:"setKeys,omitempty"`
+}
+
+type reportArtifact struct {
+	Name    string `json:"name" yaml:"name"`
+	Source  string `json:"source" yaml:"source"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// reportTemper is temper's verdict on the cached source of an installed
+// mold. Error is set when the source isn't in the cache.
+type reportTemper struct {
+	Errors      int                `json:"errors" yaml:"errors"`
+	Warnings    int                `json:"warnings" yaml:"warnings"`
+	Diagnostics []reportDiagnostic `json:"diagnostics,omitempty" yaml:"diagnostics,omitempty"`
+	Error       string             `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type reportDiagnostic struct {
+	Severity string `json:"severity" yaml:"severity"`
+	File     string `json:"file,omitempty" yaml:"file,omitempty"`
+	Rule     string `json:"rule,omitempty" yaml:"rule,omitempty"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+type reportCache struct {
+	Molds   []cachedMold `json:"molds" yaml:"molds"`
+	Indexes int          `json:"indexes" yaml:"indexes"`
+}
+
+// reportToolVersion returns the first line of `<name> --version`, or ""
+// when the tool isn't installed; a variable so tests needn't depend on
+// what the machine has.
+var reportToolVersion = func(name string) string {
+	out, err := exec.Command(name, "--version").Output() // #nosec G204 -- fixed tool names
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return line
+}
+
+func runReport(cmd *cobra.Command, _ []string) error {
+	if reportOutput == "" {
+		reportOutput = outputYAML
+	}
+	if err := validateOutputFormat(reportOutput); err != nil {
+		return err
+	}
+	return writeStructured(cmd.OutOrStdout(), reportOutput, buildUsageReport(reportRedactSources))
+}
+
+// buildUsageReport gathers the report. It reads local state only: mold
+// sources are resolved offline and doctor's network check is skipped.
+func buildUsageReport(redactSources bool) usageReport {
+	anon := newReportAnonymizer()
+	source := func(s string) string {
+		if redactSources && s != "" {
+			sum := sha256.Sum256([]byte(s))
+			return "sha256:" + hex.EncodeToString(sum[:6])
+		}
+		return anon.String(s)
+	}
+
+	version := strings.TrimSpace(evolveCurrentVersion)
+	if version == "" {
+		version = "dev"
+	}
+	r := usageReport{
+		Generated: time.Now().UTC().Format(time.RFC3339),
+		Ailloy:    version,
+		System: reportSystem{
+			OS:   runtime.GOOS,
+			Arch: runtime.GOARCH,
+			Go:   runtime.Version(),
+			Git:  reportToolVersion("git"),
+			GH:   reportToolVersion("gh"),
+		},
+		Config:    reportConfigSummary(),
+		Installed: []reportManifest{},
+		Cache:     reportCacheSummary(source, anon),
+	}
+	if redactSources {
+		// The cache paths spell out the refs that source hashed.
+		for i := range r.Cache.Molds {
+			r.Cache.Molds[i].Path = ""
+		}
+	}
+	for _, m := range []struct {
+		scope  string
+		path   string
+		global bool
+	}{
+		{scope.Project, projectManifestPath(), false},
+		{scope.Global, globalManifestPath(), true},
+	} {
+		if m.path == "" {
+			continue
+		}
+		if rm, ok := reportInstalled(m.scope, m.path, m.global, source, anon); ok {
+			r.Installed = append(r.Installed, rm)
+		}
+	}
+	for _, c := range runDoctorChecks(true) {
+		c.Detail, c.Fix = anon.String(c.Detail), anon.String(c.Fix)
+		r.Checks = append(r.Checks, c)
+	}
+	return r
+}
+
+func reportConfigSummary() reportConfig {
+	rc := reportConfig{Scopes: []reportScope{}}
+	for _, layer := range scope.Stack() {
+		rc.Scopes = append(rc.Scopes, reportScope{Name: layer.Name, Exists: layer.Exists, Writable: layer.Writable})
+	}
+	cfg, err := index.LoadConfig()
+	if err != nil {
+		rc.Error = err.Error()
+		return rc
+	}
+	rc.Foundries = len(cfg.Foundries)
+	rc.SystemFoundries = len(cfg.System)
+	rc.Mirrors = len(cfg.Foundry.Mirrors) + len(cfg.SystemMirrors)
+	rc.Resolution = cfg.Foundry.Resolution
+	rc.Profile = cfg.Profile
+	rc.EvolveChannel = cfg.Evolve.Channel
+	policy := cfg.EffectiveExec()
+	rc.ExecDisabled, rc.ExecAllow = policy.Disabled, policy.Allow
+	return rc
+}
+
+// reportInstalled summarizes the installed manifest at path, temper
+// included. The bool is false when there is no manifest.
+func reportInstalled(scopeName, path string, global bool, source func(string) string, anon reportAnonymizer) (reportManifest, bool) {
+	rm := reportManifest{Scope: scopeName, Path: anon.String(path), Molds: []reportMold{}}
+	manifest, err := foundry.ReadInstalledManifest(path)
+	if err != nil {
+		rm.Error = anon.String(err.Error())
+		return rm, true
+	}
+	if manifest == nil {
+		return rm, false
+	}
+	for i := range manifest.Molds {
+		e := &manifest.Molds[i]
+		m := reportMold{
+			Name:        e.Name,
+			Source:      source(e.Source),
+			Version:     e.Version,
+			Commit:      e.Commit[:min(len(e.Commit), 12)],
+			Files:       len(e.Files),
+			InstalledAs: e.InstalledAs,
+			Temper:      temperInstalledMold(e, global, anon),
+		}
+		if !e.CastAt.IsZero() {
+			m.CastAt = e.CastAt.UTC().Format(time.RFC3339)
+		}
+		if o := e.CastOptions; o != nil {
+			m.Options = &reportCastOptions{
+				WithWorkflows: o.WithWorkflows,
+				CI:            o.CI,
+				Profile:       o.Profile,
+				Only:          o.Only,
+				Exclude:       o.Exclude,
+			}
+			for _, f := range o.ValueFiles {
+				m.Options.ValueFiles = append(m.Options.ValueFiles, anon.String(f))
+			}
+			for _, s := range recordedFluxSets(*o).all() {
+				key, _, _ := strings.Cut(s, "=")
+				m.Options.SetKeys = append(m.Options.SetKeys, key)
+			}
+		}
+		rm.Molds = append(rm.Molds, m)
+	}
+	artifacts := func(entries []foundry.ArtifactEntry) []reportArtifact {
+		var out []reportArtifact
+		for _, a := range entries {
+			out = append(out, reportArtifact{Name: a.Name, Source: source(a.Source), Version: a.Version})
+		}
+		return out
+	}
+	rm.Ingots, rm.Ores = artifacts(manifest.Ingots), artifacts(manifest.Ores)
+	return rm, true
+}
+
+// temperInstalledMold resolves entry from the cache, without network
+// access, and tempers the result.
+func temperInstalledMold(entry *foundry.InstalledEntry, global bool, anon reportAnonymizer) reportTemper {
+	var rt reportTemper
+	refStr := entry.Ref
+	if refStr == "" {
+		ref, err := referenceFromInstalledEntry(entry)
+		if err != nil {
+			rt.Error = anon.String(err.Error())
+			return rt
+		}
+		refStr = buildVersionedRefString(ref, "")
+	}
+	opts := []foundry.ResolveOption{foundry.WithLogger(log.New(io.Discard, "", 0)), foundry.WithOffline()}
+	if global {
+		opts = append(opts, foundry.WithLockPath(globalLockPath()))
+	}
+	fsys, result, err := foundry.ResolveWithMetadata(refStr, opts...)
+	if err != nil {
+		rt.Error = anon.String(err.Error())
+		return rt
+	}
+	for _, d := range temperPackage(fsys, result.Root, false).Diagnostics {
+		switch d.Severity {
+		case mold.SeverityError:
+			rt.Errors++
+		case mold.SeverityWarning:
+			rt.Warnings++
+		}
+		rt.Diagnostics = append(rt.Diagnostics, reportDiagnostic{
+			Severity: d.Severity.String(),
+			File:     d.File,
+			Rule:     d.Rule,
+			Message:  anon.String(d.Message),
+		})
+	}
+	return rt
+}
+
+func reportCacheSummary(source func(string) string, anon reportAnonymizer) reportCache {
+	rc := reportCache{Molds: []cachedMold{}}
+	moldRoot, err := foundry.CacheDir()
+	if err != nil {
+		return rc
+	}
+	indexRoot, err := index.IndexCacheDir()
+	if err == nil {
+		rc.Indexes = countEntries(indexRoot, false)
+	}
+	entries, err := foundry.ListCachedMolds(moldRoot)
+	if err != nil {
+		return rc
+	}
+	for _, e := range entries {
+		if filepath.Join(moldRoot, e.Host) == filepath.Clean(indexRoot) {
+			continue
+		}
+		ref := e.Host + "/" + e.Owner + "/" + e.Repo
+		versions := e.Versions
+		if versions == nil {
+			versions = []string{}
+		}
+		rc.Molds = append(rc.Molds, cachedMold{
+			Ref:      source(ref),
+			Path:     anon.String(filepath.Join(moldRoot, e.Host, e.Owner, e.Repo)),
+			Versions: versions,
+		})
+	}
+	return rc
+}
+
+// reportAnonymizer removes user-identifying paths from report text: the
+// working directory becomes . and the home directory ~.
+type reportAnonymizer struct {
+	replacer *strings.Replacer
+}
+
+func newReportAnonymizer() reportAnonymizer {
+	var pairs []string
+	// The working directory first: it is usually inside home, and the
+	// replacer tries its pairs in order.
+	if wd, err := os.Getwd(); err == nil && wd != string(filepath.Separator) {
+		pairs = append(pairs, wd, ".")
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != string(filepath.Separator) {
+		pairs = append(pairs, home, "~")
+	}
+	return reportAnonymizer{replacer: strings.NewReplacer(pairs...)}
+}
+
+// String returns s with the working and home directories replaced.
+func (a reportAnonymizer) String(s string) string {
+	return a.replacer.Replace(s)
+}
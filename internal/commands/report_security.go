@@ -0,0 +1,344 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/keys"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/scope"
+	"github.com/spf13/cobra"
+)
+
+var reportSecurityCmd = &cobra.Command{
+	Use:   "security",
+	Short: "Print the security posture of this machine's ailloy setup",
+	Long: `Print the security posture of this machine's ailloy setup, as markdown
+for a security review.
+
+Reads the loaded config.yaml (system and user scopes) and ailloy's local
+state, and reports:
+  - the foundries registered for discovery, with their scope, the
+    mirrors and the resolution policy
+  - the effective exec policy for mold commands (discover and hooks),
+    the system scope's part in it, and how many molds have consent
+    recorded in ~/.ailloy/exec-consent.yaml
+  - your signing keys and the trusted publisher keys, with their host
+    pins and revocations, and whether signatures are enforced
+  - the persisted flux files in every scope, whether each is encrypted
+    with sops or a git-ignored secret companion, and its file mode
+
+Unlike ailloy report, nothing is anonymized: the report names your
+foundries and paths. -o json or -o yaml prints the same data structured.`,
+	Args: cobra.NoArgs,
+	RunE: runReportSecurity,
+}
+
+var reportSecurityOutput string
+
+func init() {
+	reportCmd.AddCommand(reportSecurityCmd)
+	addOutputFlag(reportSecurityCmd, &reportSecurityOutput)
+}
+
+// securityReport is what ailloy report security prints.
+type securityReport struct {
+	Generated  string             `json:"generated" yaml:"generated"`
+	Ailloy     string             `json:"ailloy" yaml:"ailloy"`
+	Foundries  securityFoundries  `json:"foundries" yaml:"foundries"`
+	Exec       securityExec       `json:"exec" yaml:"exec"`
+	Signatures securitySignatures `json:"signatures" yaml:"signatures"`
+	Flux       securityFlux       `json:"flux" yaml:"flux"`
+	Errors     []string           `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+type securityFoundries struct {
+	// Restricted is always false: any git source can be cast, registered
+	// or not.
+	Restricted bool              `json:"restricted" yaml:"restricted"`
+	Registered []securityFoundry `json:"registered" yaml:"registered"`
+	Mirrors    map[string]string `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+	Resolution string            `json:"resolution" yaml:"resolution"`
+}
+
+type securityFoundry struct {
+	Name  string `json:"name" yaml:"name"`
+	URL   string `json:"url" yaml:"url"`
+	Scope string `json:"scope" yaml:"scope"` // official, system or user
+}
+
+type securityExec struct {
+	Disabled       bool     `json:"disabled" yaml:"disabled"`
+	Allow          []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	NoExecFlag     bool     `json:"noExecFlag,omitempty" yaml:"noExecFlag,omitempty"`
+	SystemDisabled bool     `json:"systemDisabled,omitempty" yaml:"systemDisabled,omitempty"`
+	SystemAllow    []string `json:"systemAllow,omitempty" yaml:"systemAllow,omitempty"`
+	ConsentedMolds int      `json:"consentedMolds" yaml:"consentedMolds"`
+}
+
+type securitySignatures struct {
+	// Enforced is always false: install and cast don't verify signatures.
+	Enforced    bool                 `json:"enforced" yaml:"enforced"`
+	SigningKeys []keys.Key           `json:"signingKeys" yaml:"signingKeys"`
+	Trusted     []securityTrustedKey `json:"trusted" yaml:"trusted"`
+}
+
+type securityTrustedKey struct {
+	Name        string   `json:"name" yaml:"name"`
+	Fingerprint string   `json:"fingerprint" yaml:"fingerprint"`
+	Hosts       []string `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+	Revoked     bool     `json:"revoked" yaml:"revoked"`
+}
+
+type securityFlux struct {
+	Sops  bool               `json:"sops" yaml:"sops"` // sops is on PATH
+	Files []securityFluxFile `json:"files" yaml:"files"`
+}
+
+type securityFluxFile struct {
+	Scope string `json:"scope" yaml:"scope"`
+	Path  string `json:"path" yaml:"path"`
+	// Storage is "encrypted" (sops), "secret" (a .local.yaml companion
+	// holding type: secret values in plain text) or "plain".
+	Storage string `json:"storage" yaml:"storage"`
+	Mode    string `json:"mode" yaml:"mode"`
+}
+
+func runReportSecurity(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(reportSecurityOutput); err != nil {
+		return err
+	}
+	r := buildSecurityReport()
+	if reportSecurityOutput != "" {
+		return writeStructured(cmd.OutOrStdout(), reportSecurityOutput, r)
+	}
+	writeSecurityMarkdown(cmd.OutOrStdout(), r)
+	return nil
+}
+
+// buildSecurityReport gathers the report from local config and state.
+// Problems reading any part are collected in Errors; the rest is still
+// reported.
+func buildSecurityReport() securityReport {
+	version := strings.TrimSpace(evolveCurrentVersion)
+	if version == "" {
+		version = "dev"
+	}
+	r := securityReport{
+		Generated: time.Now().UTC().Format(time.RFC3339),
+		Ailloy:    version,
+		Foundries: securityFoundries{Registered: []securityFoundry{}},
+		Signatures: securitySignatures{
+			SigningKeys: []keys.Key{},
+			Trusted:     []securityTrustedKey{},
+		},
+		Flux: securityFlux{Files: []securityFluxFile{}},
+	}
+	fail := func(what string, err error) {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	if cfg, err := index.LoadConfig(); err != nil {
+		fail("config.yaml", err)
+	} else {
+		for _, f := range cfg.EffectiveFoundries() {
+			sf := securityFoundry{Name: f.Name, URL: f.URL, Scope: "user"}
+			switch {
+			case cfg.IsSystemFoundry(f.URL):
+				sf.Scope = scope.System
+			case cfg.FindFoundry(f.URL) == nil:
+				sf.Scope = "official"
+			}
+			r.Foundries.Registered = append(r.Foundries.Registered, sf)
+		}
+		r.Foundries.Mirrors = cfg.MirrorRules()
+		if r.Foundries.Resolution, err = cfg.ResolutionPolicy(); err != nil {
+			fail("config.yaml", err)
+		}
+		eff := cfg.EffectiveExec()
+		r.Exec = securityExec{
+			Disabled:       eff.Disabled || rootNoExec,
+			Allow:          eff.Allow,
+			NoExecFlag:     rootNoExec,
+			SystemDisabled: cfg.SystemExec.Disabled,
+			SystemAllow:    cfg.SystemExec.Allow,
+		}
+	}
+	if path, err := execConsentFile(); err == nil {
+		if data, err := os.ReadFile(path); err == nil { // #nosec G304 -- ailloy's own state file
+			var consents execConsents
+			if err := yaml.Unmarshal(data, &consents); err != nil {
+				fail(displayPath(path), err)
+			}
+			r.Exec.ConsentedMolds = len(consents.Molds)
+		}
+	}
+
+	if store, err := keys.DefaultStore(); err != nil {
+		fail("keys", err)
+	} else {
+		if own, err := store.Keys(); err != nil {
+			fail("signing keys", err)
+		} else if own != nil {
+			r.Signatures.SigningKeys = own
+		}
+		trusted, err := store.Trusted()
+		if err != nil {
+			fail("trusted keys", err)
+		}
+		for _, k := range trusted {
+			r.Signatures.Trusted = append(r.Signatures.Trusted, securityTrustedKey{
+				Name:        k.Name,
+				Fingerprint: k.Fingerprint,
+				Hosts:       k.Hosts,
+				Revoked:     k.Revoked != nil,
+			})
+		}
+	}
+
+	_, err := exec.LookPath("sops")
+	r.Flux.Sops = err == nil
+	r.Flux.Files = securityFluxFiles()
+	return r
+}
+
+// securityFluxFiles lists the persisted flux files in every scope, in
+// load order.
+func securityFluxFiles() []securityFluxFile {
+	dirs := []struct{ scope, dir string }{}
+	for _, layer := range scope.Stack() {
+		if layer.Root == "" || !layer.Exists {
+			continue
+		}
+		if layer.Name == scope.Project {
+			roots := scope.WorkspaceProjectRoots()
+			for i := len(roots) - 1; i >= 0; i-- {
+				dirs = append(dirs, struct{ scope, dir string }{"workspace", filepath.Join(roots[i], "flux")})
+			}
+		}
+		dirs = append(dirs, struct{ scope, dir string }{layer.Name, filepath.Join(layer.Root, "flux")})
+	}
+
+	files := []securityFluxFile{}
+	for _, d := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(d.dir, "*.yaml"))
+		slices.Sort(matches)
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			f := securityFluxFile{Scope: d.scope, Path: path, Storage: "plain", Mode: fmt.Sprintf("%04o", info.Mode().Perm())}
+			var vals map[string]any
+			if data, err := os.ReadFile(path); err == nil && yaml.Unmarshal(data, &vals) == nil && mold.IsEncryptedFlux(vals) { // #nosec G304 -- flux file under a scope root
+				f.Storage = "encrypted"
+			} else if strings.HasSuffix(path, ".local.yaml") {
+				f.Storage = "secret"
+			}
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// writeSecurityMarkdown renders r as a markdown document.
+func writeSecurityMarkdown(w io.Writer, r securityReport) {
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	list := func(items []string) string {
+		if len(items) == 0 {
+			return "—"
+		}
+		return "`" + strings.Join(items, "`, `") + "`"
+	}
+
+	fmt.Fprintf(w, "# Ailloy security posture\n\nGenerated %s by ailloy %s.\n\n", r.Generated, r.Ailloy)
+
+	fmt.Fprintln(w, "## Foundries")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Registered foundries are for discovery only: any git source can be cast or installed, registered or not.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Name | URL | Scope |")
+	fmt.Fprintln(w, "|------|-----|-------|")
+	for _, f := range r.Foundries.Registered {
+		fmt.Fprintf(w, "| %s | %s | %s |\n", f.Name, f.URL, f.Scope)
+	}
+	fmt.Fprintf(w, "\nResolution policy: `%s`.\n", r.Foundries.Resolution)
+	if len(r.Foundries.Mirrors) > 0 {
+		hosts := make([]string, 0, len(r.Foundries.Mirrors))
+		for host := range r.Foundries.Mirrors {
+			hosts = append(hosts, host)
+		}
+		slices.Sort(hosts)
+		fmt.Fprintln(w, "\nMirrors:")
+		fmt.Fprintln(w)
+		for _, host := range hosts {
+			fmt.Fprintf(w, "- `%s` → `%s`\n", host, r.Foundries.Mirrors[host])
+		}
+	}
+
+	fmt.Fprintln(w, "\n## Mold commands")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Applies to flux schema `discover:` commands and mold `hooks:` scripts.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Setting | Value |")
+	fmt.Fprintln(w, "|---------|-------|")
+	fmt.Fprintf(w, "| Disabled | %s |\n", yesNo(r.Exec.Disabled))
+	allow := list(r.Exec.Allow)
+	if len(r.Exec.Allow) == 0 {
+		allow = "any binary"
+	}
+	fmt.Fprintf(w, "| Allowed binaries | %s |\n", allow)
+	fmt.Fprintf(w, "| Disabled by system scope | %s |\n", yesNo(r.Exec.SystemDisabled))
+	fmt.Fprintf(w, "| System allowlist | %s |\n", list(r.Exec.SystemAllow))
+	fmt.Fprintf(w, "| Molds with recorded consent | %d |\n", r.Exec.ConsentedMolds)
+
+	fmt.Fprintln(w, "\n## Signatures")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Enforced: %s. Install and cast do not verify signatures.\n\n", yesNo(r.Signatures.Enforced))
+	fmt.Fprintf(w, "Signing keys: %d.\n\n", len(r.Signatures.SigningKeys))
+	for _, k := range r.Signatures.SigningKeys {
+		fmt.Fprintf(w, "- %s `%s`\n", k.Name, k.Fingerprint)
+	}
+	if len(r.Signatures.SigningKeys) > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "| Trusted publisher | Fingerprint | Hosts | Revoked |")
+	fmt.Fprintln(w, "|-------------------|-------------|-------|---------|")
+	for _, k := range r.Signatures.Trusted {
+		hosts := list(k.Hosts)
+		if len(k.Hosts) == 0 {
+			hosts = "any"
+		}
+		fmt.Fprintf(w, "| %s | `%s` | %s | %s |\n", k.Name, k.Fingerprint, hosts, yesNo(k.Revoked))
+	}
+
+	fmt.Fprintln(w, "\n## Flux storage")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "sops on PATH: %s. Secret companions (`.local.yaml`) hold `type: secret` values in plain text.\n\n", yesNo(r.Flux.Sops))
+	fmt.Fprintln(w, "| Scope | File | Storage | Mode |")
+	fmt.Fprintln(w, "|-------|------|---------|------|")
+	for _, f := range r.Flux.Files {
+		fmt.Fprintf(w, "| %s | %s | %s | %s |\n", f.Scope, f.Path, f.Storage, f.Mode)
+	}
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(w, "\n## Errors")
+		fmt.Fprintln(w)
+		for _, e := range r.Errors {
+			fmt.Fprintf(w, "- %s\n", e)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSecurityReport(t *testing.T) {
+	home, project, system := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AILLOY_SYSTEM_ROOT", system)
+	t.Chdir(project)
+
+	mustWrite(t, filepath.Join(system, "config.yaml"), `foundries:
+  - name: corp
+    url: https://git.corp.example/acme/foundry
+    type: git
+exec:
+  allow: [gh, jq]
+`)
+	mustWrite(t, filepath.Join(home, ".ailloy", "config.yaml"), `foundries:
+  - name: team
+    url: https://github.com/acme/foundry
+    type: git
+exec:
+  allow: [gh]
+`)
+	mustWrite(t, filepath.Join(home, ".ailloy", "exec-consent.yaml"), "molds:\n  github.com/acme/molds:\n    hooks: abc\n")
+	mustWrite(t, filepath.Join(project, ".ailloy", "flux", "acme.yaml"), "team: platform\n")
+	mustWrite(t, filepath.Join(project, ".ailloy", "flux", "acme.local.yaml"), "api:\n  token: hunter2\n")
+	mustWrite(t, filepath.Join(home, ".ailloy", "flux", "acme.yaml"), "token: ENC[AES256_GCM,data:x]\nsops:\n  mac: ENC[AES256_GCM,data:y]\n  version: 3.9.0\n")
+
+	r := buildSecurityReport()
+	if len(r.Errors) != 0 {
+		t.Fatalf("errors = %v", r.Errors)
+	}
+	scopes := map[string]string{}
+	for _, f := range r.Foundries.Registered {
+		scopes[f.Name] = f.Scope
+	}
+	if scopes["team"] != "user" || scopes["corp"] != "system" {
+		t.Errorf("foundry scopes = %v, want team user and corp system", scopes)
+	}
+	if got := strings.Join(r.Exec.Allow, ","); got != "gh" || r.Exec.Disabled {
+		t.Errorf("exec = %+v, want allow [gh] capped by the system list", r.Exec)
+	}
+	if got := strings.Join(r.Exec.SystemAllow, ","); got != "gh,jq" || r.Exec.ConsentedMolds != 1 {
+		t.Errorf("exec = %+v, want system allow [gh jq] and one consented mold", r.Exec)
+	}
+	if r.Signatures.Enforced || len(r.Signatures.Trusted) != 0 {
+		t.Errorf("signatures = %+v, want none trusted and not enforced", r.Signatures)
+	}
+	storage := map[string]string{}
+	for _, f := range r.Flux.Files {
+		storage[f.Scope+" "+filepath.Base(f.Path)] = f.Storage
+	}
+	want := map[string]string{
+		"global acme.yaml":        "encrypted",
+		"project acme.yaml":       "plain",
+		"project acme.local.yaml": "secret",
+	}
+	for k, v := range want {
+		if storage[k] != v {
+			t.Errorf("flux storage = %v, want %s %s", storage, k, v)
+		}
+	}
+
+	var out strings.Builder
+	writeSecurityMarkdown(&out, r)
+	for _, s := range []string{"## Foundries", "| corp | https://git.corp.example/acme/foundry | system |", "| Allowed binaries | `gh` |", "Enforced: no", "| secret |"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("markdown missing %q:\n%s", s, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Error("markdown leaks a flux value")
+	}
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildUsageReport(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AILLOY_SYSTEM_ROOT", "")
+	t.Chdir(project)
+
+	origTool, origVersion := reportToolVersion, evolveCurrentVersion
+	t.Cleanup(func() { reportToolVersion, evolveCurrentVersion = origTool, origVersion })
+	reportToolVersion = func(name string) string { return name + " version 1.0" }
+	evolveCurrentVersion = "1.2.3"
+
+	mustWrite(t, filepath.Join(home, ".ailloy", "config.yaml"), `foundries:
+  - name: internal
+    url: https://git.secret.corp/acme/foundry
+    type: git
+exec:
+  allow: [gh]
+`)
+	mustWrite(t, filepath.Join(project, ".ailloy", "installed.yaml"), `apiVersion: v1
+molds:
+  - name: acme
+    source: github.com/acme/private-molds
+    version: v1.0.0
+    commit: 0123456789abcdef0123456789abcdef01234567
+    files: [AGENTS.md, .claude/commands/review.md]
+    castOptions:
+      valueFiles: [`+filepath.Join(project, "values.yaml")+`, `+filepath.Join(home, "shared.yaml")+`]
+      setOverrides: ["api.token=hunter2", "team=platform"]
+`)
+
+	r := buildUsageReport(false)
+	if r.Ailloy != "1.2.3" || r.System.Git != "git version 1.0" {
+		t.Errorf("ailloy = %q, git = %q", r.Ailloy, r.System.Git)
+	}
+	if r.Config.Foundries != 1 || len(r.Config.ExecAllow) != 1 {
+		t.Errorf("config = %+v, want 1 foundry and exec allow [gh]", r.Config)
+	}
+	if len(r.Installed) != 1 || len(r.Installed[0].Molds) != 1 {
+		t.Fatalf("installed = %+v, want the project manifest with one mold", r.Installed)
+	}
+	m := r.Installed[0].Molds[0]
+	if m.Source != "github.com/acme/private-molds" || m.Commit != "0123456789ab" || m.Files != 2 {
+		t.Errorf("mold = %+v", m)
+	}
+	if got := strings.Join(m.Options.SetKeys, ","); got != "api.token,team" {
+		t.Errorf("setKeys = %q, want api.token,team", got)
+	}
+	if got := strings.Join(m.Options.ValueFiles, ","); got != "./values.yaml,~/shared.yaml" {
+		t.Errorf("valueFiles = %q, want anonymized paths", got)
+	}
+	if m.Temper.Error == "" {
+		t.Error("temper of an uncached mold should report an error")
+	}
+
+	var out strings.Builder
+	if err := writeStructured(&out, outputYAML, r); err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"hunter2", "git.secret.corp", home, project} {
+		if strings.Contains(out.String(), leak) {
+			t.Errorf("report leaks %q:\n%s", leak, out.String())
+		}
+	}
+
+	redacted := buildUsageReport(true)
+	if src := redacted.Installed[0].Molds[0].Source; !strings.HasPrefix(src, "sha256:") || strings.Contains(src, "acme") {
+		t.Errorf("redacted source = %q, want a hash", src)
+	}
+}
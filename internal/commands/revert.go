@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	revertEphemeral bool
+	revertExpired   bool
+	revertList      bool
+	revertForce     bool
+	revertDryRun    bool
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert --ephemeral [source[//subpath]]",
+	Short: "Undo ephemeral trial casts",
+	Long: `Undo molds cast with 'ailloy cast --ephemeral'.
+
+Files the trial created are deleted and files it overwrote are restored to
+their pre-trial content, then the trial is dropped from
+.ailloy/ephemeral.yaml. With no argument every trial in the project is
+reverted, newest first; --expired limits that to trials past their expiry.
+Use --list to see the current trials.
+
+Files modified since the trial cast are left in place unless --force is
+given; their original content stays under .ailloy/ephemeral/.
+
+To keep a trial instead, cast the mold again without --ephemeral.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRevert,
+}
+
+func init() {
+	rootCmd.AddCommand(revertCmd)
+	revertCmd.Flags().BoolVar(&revertEphemeral, "ephemeral", false, "revert trial casts made with 'cast --ephemeral'")
+	revertCmd.Flags().BoolVar(&revertExpired, "expired", false, "only revert trials past their expiry")
+	revertCmd.Flags().BoolVar(&revertList, "list", false, "list trial casts without reverting")
+	revertCmd.Flags().BoolVar(&revertForce, "force", false, "revert files even if modified since the trial cast")
+	revertCmd.Flags().BoolVar(&revertDryRun, "dry-run", false, "print what would be reverted without touching disk")
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	if !revertEphemeral {
+		return fmt.Errorf("revert only undoes trial casts; pass --ephemeral (use 'ailloy uninstall' for regular installs)")
+	}
+	state, err := foundry.ReadEphemeralState(foundry.EphemeralStatePath)
+	if err != nil {
+		return err
+	}
+	if state == nil || len(state.Trials) == 0 {
+		logging.Say(styles.SubtleStyle.Render("No ephemeral trials in this project."), "no ephemeral trials")
+		return nil
+	}
+
+	now := time.Now()
+	if revertList {
+		printTrials(state.Trials, now)
+		return nil
+	}
+
+	targets, err := selectTrials(state, args, revertExpired, now)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		logging.Say(styles.SubtleStyle.Render("No expired ephemeral trials."), "no expired ephemeral trials")
+		return nil
+	}
+
+	for _, t := range targets {
+		res, err := foundry.RevertEphemeral(foundry.EphemeralStatePath, t.Source, t.Subpath, foundry.UninstallOptions{
+			Force:  revertForce,
+			DryRun: revertDryRun,
+		})
+		if err != nil {
+			return err
+		}
+		if !revertDryRun {
+			recordHistory(historyEntry{
+				Op:      "revert",
+				Mold:    t.Name,
+				Source:  t.Source,
+				Version: t.Version,
+				Commit:  t.Commit,
+				Files:   append(append([]string(nil), res.Restored...), res.Deleted...),
+				Flags:   historyFlags(cmd),
+			}, nil)
+		}
+		printRevertResult(t, res)
+	}
+	return nil
+}
+
+// selectTrials picks the trials to revert: the one named by args, or all
+// (optionally only expired) trials, newest first so overlapping trials
+// unwind in reverse cast order.
+func selectTrials(state *foundry.EphemeralState, args []string, expiredOnly bool, now time.Time) ([]foundry.EphemeralTrial, error) {
+	if len(args) == 1 {
+		source, subpath, _ := strings.Cut(args[0], "//")
+		if t := state.Find(source, subpath); t != nil {
+			return []foundry.EphemeralTrial{*t}, nil
+		}
+		var matches []foundry.EphemeralTrial
+		for _, t := range state.Trials {
+			if t.Name == args[0] || (subpath == "" && t.Source == source) {
+				matches = append(matches, t)
+			}
+		}
+		switch len(matches) {
+		case 1:
+			return matches, nil
+		case 0:
+			return nil, fmt.Errorf("no ephemeral trial matches %q; run 'ailloy revert --ephemeral --list'", args[0])
+		default:
+			names := make([]string, len(matches))
+			for i, t := range matches {
+				names[i] = t.Display()
+			}
+			return nil, fmt.Errorf("%q matches %d trials; pass one of: %s", args[0], len(matches), strings.Join(names, ", "))
+		}
+	}
+
+	var out []foundry.EphemeralTrial
+	for _, t := range state.Trials {
+		if !expiredOnly || t.Expired(now) {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CastAt.After(out[j].CastAt) })
+	return out, nil
+}
+
+func printTrials(trials []foundry.EphemeralTrial, now time.Time) {
+	logging.Decor(styles.HeaderStyle.Render("Ephemeral trials"), "")
+	for _, t := range trials {
+		line := "  " + styles.AccentStyle.Render(t.Display())
+		if t.Version != "" {
+			line += " " + t.Version
+		}
+		line += styles.SubtleStyle.Render(fmt.Sprintf("  %d file(s), cast %s", len(t.Files), t.CastAt.Local().Format("2006-01-02")))
+		if t.Expired(now) {
+			line += styles.WarningStyle.Render("  expired " + t.ExpiresAt.Local().Format("2006-01-02"))
+		} else {
+			line += styles.SubtleStyle.Render(", expires " + t.ExpiresAt.Local().Format("2006-01-02"))
+		}
+		logging.Say(line, "ephemeral trial", "mold", t.Display(), "version", t.Version, "files", len(t.Files),
+			"cast_at", t.CastAt, "expires_at", t.ExpiresAt, "expired", t.Expired(now))
+	}
+}
+
+func printRevertResult(t foundry.EphemeralTrial, res foundry.RevertResult) {
+	header := "Reverted"
+	if revertDryRun {
+		header = "Would revert (dry-run)"
+	}
+	logging.Say(styles.SuccessStyle.Render(header+" ")+styles.AccentStyle.Render(t.Display()),
+		strings.ToLower(header), "mold", t.Display(), "restored", res.Restored, "removed", res.Deleted,
+		"skipped_modified", res.SkippedModified, "kept_backups", res.KeptBackups)
+	if len(res.Restored) > 0 {
+		logging.Decor(styles.SubtleStyle.Render(fmt.Sprintf("  Restored: %d file(s)", len(res.Restored))))
+		decorFileList(res.Restored)
+	}
+	if len(res.Deleted) > 0 {
+		logging.Decor(styles.SubtleStyle.Render(fmt.Sprintf("  Removed:  %d file(s)", len(res.Deleted))))
+		decorFileList(res.Deleted)
+	}
+	if len(res.SkippedModified) > 0 {
+		logging.Decor(styles.WarningStyle.Render(fmt.Sprintf("  Skipped (modified): %d file(s)", len(res.SkippedModified))))
+		decorFileList(res.SkippedModified)
+		for _, b := range res.KeptBackups {
+			logging.Decor(styles.SubtleStyle.Render("    original kept at " + b))
+		}
+		logging.Decor(styles.SubtleStyle.Render("  Re-run with --force to override."))
+	}
+}
@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackTo                string
+	rollbackGlobal            bool
+	rollbackDryRun            bool
+	rollbackOverwriteModified bool
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <mold>",
+	Short: "Restore the previously installed version of a mold",
+	Long: `Restore the version of a mold that was installed before the current one,
+using the history log (.ailloy/history.log) to find it and the flux values,
+value files and selection it was cast with.
+
+The earlier version is cast from the local mold cache; rollback never goes
+to the network, so it fails if that version is no longer cached. Files the
+current version added that the earlier one doesn't produce are removed,
+unless they were edited since they were cast.
+
+Files edited since they were cast are kept as they are, like recast; pass
+--overwrite-modified to replace them.
+
+--to picks a specific earlier version from the history instead of the most
+recent one. Running rollback twice swaps back to the version you rolled
+back from; ` + "`ailloy recast`" + ` upgrades to the latest version again.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "roll back to this version from the history instead of the previous one")
+	rollbackCmd.Flags().BoolVarP(&rollbackGlobal, "global", "g", false, "operate on the global manifest and history under ~/")
+	rollbackCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", false, "show the version and options that would be restored without casting")
+	rollbackCmd.Flags().BoolVar(&rollbackOverwriteModified, "overwrite-modified", false, "replace files edited since they were cast instead of keeping them")
+}
+
+// installOps are the history operations that installed a mold at a
+// version rollback can return to.
+var installOps = []string{"cast", "recast", "sync", "rollback"}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	manifestPath := manifestPathFor(rollbackGlobal)
+	if manifestPath == "" {
+		return fmt.Errorf("cannot determine installed manifest path")
+	}
+	manifest, err := foundry.ReadInstalledManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("reading installed manifest: %w", err)
+	}
+	history, err := readHistory(historyPathFor(rollbackGlobal))
+	if err != nil {
+		return err
+	}
+	entry := findRollbackEntry(manifest, history, args[0])
+	if entry == nil {
+		return fmt.Errorf("mold %q not found in %s; only molds cast from a foundry can be rolled back", args[0], manifestPath)
+	}
+	ref, err := referenceFromInstalledEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	target := rollbackTarget(history, ref.OverrideKey(), entry, rollbackTo)
+	if target == nil {
+		if rollbackTo != "" {
+			return fmt.Errorf("%s was never installed at %s according to %s", entry.Name, rollbackTo, historyPathFor(rollbackGlobal))
+		}
+		return fmt.Errorf("no earlier version of %s in %s", entry.Name, historyPathFor(rollbackGlobal))
+	}
+
+	opts := target.Options
+	if opts == nil {
+		// Entries written before history recorded options: the current
+		// install's options are the best guess.
+		opts = entry.CastOptions
+	}
+	if opts == nil {
+		opts = &foundry.CastOptionsRecord{}
+	}
+
+	logging.Say(styles.WorkingBanner(fmt.Sprintf("Rolling back %s from %s to %s...", entry.Name, entry.Version, target.Version)),
+		"rolling back", "mold", entry.Name, "from", entry.Version, "to", target.Version)
+	logging.Decor(styles.SubtleStyle.Render("  installed " + target.Time.Local().Format("2006-01-02 15:04:05") + " by " + target.Op))
+	printRollbackOptions(opts)
+	if rollbackDryRun {
+		return nil
+	}
+
+	res, err := CastMold(cmd.Context(), buildVersionedRefString(ref, rollbackVersion(target)), CastOptions{
+		Global:         rollbackGlobal,
+		WithWorkflows:  opts.WithWorkflows,
+		CI:             opts.CI,
+		ValueFiles:     opts.ValueFiles,
+		SetOverrides:   opts.SetOverrides,
+		SetFiles:       opts.SetFiles,
+		SetJSON:        opts.SetJSON,
+		Profile:        opts.Profile,
+		Only:           opts.Only,
+		Exclude:        opts.Exclude,
+		Offline:        true,
+		KeepLocalEdits: !rollbackOverwriteModified,
+		HistoryOp:      "rollback",
+		HistoryFlags:   historyFlags(cmd),
+	})
+	if err != nil {
+		return fmt.Errorf("restoring %s %s from the cache: %w", entry.Name, target.Version, err)
+	}
+
+	destPrefix, err := globalDestPrefix(rollbackGlobal)
+	if err != nil {
+		return err
+	}
+	removed, kept := removeRolledBackFiles(destPrefix, entry, res.FilesCast)
+
+	logging.Say(styles.SuccessStyle.Render("Rolled back ")+styles.AccentStyle.Render(entry.Name)+" to "+styles.CodeStyle.Render(target.Version),
+		"rolled back", "mold", entry.Name, "to", target.Version)
+	for _, path := range res.Kept {
+		log.Printf("warning: kept your edits to %s (re-run with --overwrite-modified to replace it)", displayPath(path))
+	}
+	if res.Backup != "" {
+		logging.Say(styles.SubtleStyle.Render("  backed up the files it overwrote as "+res.Backup+" (ailloy backups restore "+res.Backup+")"),
+			"backed up overwritten files", "backup", res.Backup)
+	}
+	for _, f := range removed {
+		logging.Say(styles.SubtleStyle.Render("  removed "+f+" (not in "+target.Version+")"), "removed", "file", f)
+	}
+	for _, f := range kept {
+		log.Printf("warning: kept %s: it isn't in %s but was edited since it was cast", f, target.Version)
+	}
+	return nil
+}
+
+// findRollbackEntry finds the installed entry for name: its manifest name
+// (the repository), its source, or the mold name history recorded for it.
+func findRollbackEntry(manifest *foundry.InstalledManifest, history []historyEntry, name string) *foundry.InstalledEntry {
+	if manifest == nil {
+		return nil
+	}
+	if e := manifest.FindByName(name); e != nil {
+		return e
+	}
+	for i := range manifest.Molds {
+		e := &manifest.Molds[i]
+		ref, err := referenceFromInstalledEntry(e)
+		if err != nil {
+			continue
+		}
+		key := ref.OverrideKey()
+		if strings.EqualFold(key, name) {
+			return e
+		}
+		for _, h := range history {
+			if h.Source == key && strings.EqualFold(h.Mold, name) {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// rollbackTarget returns the most recent history entry that installed the
+// mold at source at another commit than the current install, or at version
+// to when it is set. Ephemeral trials don't count as installs.
+func rollbackTarget(history []historyEntry, source string, current *foundry.InstalledEntry, to string) *historyEntry {
+	for i := len(history) - 1; i >= 0; i-- {
+		e := &history[i]
+		if e.Source != source || e.Version == "" || !slices.Contains(installOps, e.Op) || slices.Contains(e.Flags, "--ephemeral") {
+			continue
+		}
+		if to != "" {
+			if e.Version == to {
+				return e
+			}
+			continue
+		}
+		if e.Commit != current.Commit || (e.Commit == "" && e.Version != current.Version) {
+			return e
+		}
+	}
+	return nil
+}
+
+// rollbackVersion is the version to pin the rollback cast to: the tag the
+// entry recorded, or its commit when the tag is a branch that has since
+// moved on.
+func rollbackVersion(e *historyEntry) string {
+	if _, err := semver.NewVersion(e.Version); err == nil || e.Commit == "" {
+		return e.Version
+	}
+	return e.Commit
+}
+
+func printRollbackOptions(opts *foundry.CastOptionsRecord) {
+	if len(opts.ValueFiles) > 0 {
+		logging.Decor(styles.SubtleStyle.Render("  values: " + strings.Join(opts.ValueFiles, ", ")))
+	}
+	if len(opts.SetOverrides) > 0 {
+		logging.Decor(styles.SubtleStyle.Render("  set:    " + strings.Join(opts.SetOverrides, ", ")))
+	}
+	if len(opts.SetFiles) > 0 {
+		logging.Decor(styles.SubtleStyle.Render("  set-file: " + strings.Join(opts.SetFiles, ", ")))
+	}
+	if len(opts.SetJSON) > 0 {
+		logging.Decor(styles.SubtleStyle.Render("  set-json: " + strings.Join(opts.SetJSON, ", ")))
+	}
+	if opts.Profile != "" {
+		logging.Decor(styles.SubtleStyle.Render("  profile: " + opts.Profile))
+	}
+	if len(opts.Only) > 0 {
+		logging.Decor(styles.SubtleStyle.Render("  only:   " + strings.Join(opts.Only, ", ")))
+	}
+	if len(opts.Exclude) > 0 {
+		logging.Decor(styles.SubtleStyle.Render("  exclude: " + strings.Join(opts.Exclude, ", ")))
+	}
+}
+
+// removeRolledBackFiles deletes the files the replaced install recorded
+// that the rolled-back version didn't cast, unless they changed since
+// they were cast (those are returned as kept).
+func removeRolledBackFiles(destPrefix string, replaced *foundry.InstalledEntry, cast []foundry.InstalledFile) (removed, kept []string) {
+	now := make(map[string]bool, len(cast))
+	for _, f := range cast {
+		now[f.RelPath] = true
+	}
+	var dirs []string
+	for _, f := range replaced.Files {
+		if now[f] {
+			continue
+		}
+		path := castDest(destPrefix, f)
+		sum, err := hashFile(path)
+		if err != nil {
+			continue // already gone
+		}
+		if want := replaced.FileHashes[f]; want == "" || sum != want {
+			kept = append(kept, f)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			kept = append(kept, f)
+			continue
+		}
+		removed = append(removed, f)
+		dirs = append(dirs, filepath.Dir(path))
+	}
+	cleanupEmptyDirs(dirs, destPrefix)
+	return removed, kept
+}
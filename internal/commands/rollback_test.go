@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	const src = "github.com/acme/demo"
+	history := []historyEntry{
+		{Op: "cast", Source: src, Version: "v1.0.0", Commit: "c1"},
+		{Op: "cast", Source: "github.com/acme/other", Version: "v9.0.0", Commit: "c9"},
+		{Op: "recast", Source: src, Version: "v2.0.0", Commit: "c2"},
+		{Op: "cast", Source: src, Version: "v3.0.0", Commit: "c3", Flags: []string{"--ephemeral"}},
+		{Op: "uninstall", Source: src, Version: "v2.0.0", Commit: "c2"},
+		{Op: "recast", Source: src, Version: "v2.0.0", Commit: "c2"},
+	}
+	current := &foundry.InstalledEntry{Version: "v2.0.0", Commit: "c2"}
+
+	if got := rollbackTarget(history, src, current, ""); got == nil || got.Version != "v1.0.0" {
+		t.Errorf("previous = %+v, want v1.0.0 (trials and uninstalls don't count)", got)
+	}
+	if got := rollbackTarget(history, src, current, "v2.0.0"); got == nil || got.Op != "recast" {
+		t.Errorf("--to v2.0.0 = %+v, want the latest recast", got)
+	}
+	if got := rollbackTarget(history, src, current, "v3.0.0"); got != nil {
+		t.Errorf("--to v3.0.0 = %+v, want nil: it was only a trial", got)
+	}
+	if got := rollbackTarget(history[2:3], src, current, ""); got != nil {
+		t.Errorf("only the current version = %+v, want nil", got)
+	}
+}
+
+func TestRollbackVersion(t *testing.T) {
+	for _, tc := range []struct {
+		entry historyEntry
+		want  string
+	}{
+		{historyEntry{Version: "v1.2.0", Commit: "abc"}, "v1.2.0"},
+		{historyEntry{Version: "main", Commit: "abc"}, "abc"},
+		{historyEntry{Version: "main"}, "main"},
+	} {
+		if got := rollbackVersion(&tc.entry); got != tc.want {
+			t.Errorf("rollbackVersion(%+v) = %q, want %q", tc.entry, got, tc.want)
+		}
+	}
+}
+
+// TestE2E_Rollback_RestoresPreviousVersion casts v1 with --set, recasts to
+// v2 (which adds a file) with another value, then removes the remote and
+// rolls back: v1's render and flux value come back from the cache and the
+// file only v2 produced is removed.
+func TestE2E_Rollback_RestoresPreviousVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("e2e binary build is slow; skipping in -short mode")
+	}
+
+	env := setupRecastE2EEnv(t)
+	project := t.TempDir()
+
+	if out, err := env.run(t, project, "cast", env.refString(), "--set", "foo=one"); err != nil {
+		t.Fatalf("cast failed: %v\n%s", err, out)
+	}
+
+	env.writeMoldFiles(t, "2.0.0", " (v2)")
+	if err := os.WriteFile(filepath.Join(env.repoDir, "flux.yaml"), []byte("output:\n  README.md: README.md\n  NEW.md: NEW.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(env.repoDir, "NEW.md"), []byte("only in v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env.commitAndTag(t, "v2", "v2.0.0")
+	if out, err := env.run(t, project, "recast", "--set", "foo=two"); err != nil {
+		t.Fatalf("recast failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(project, "NEW.md")); err != nil {
+		t.Fatalf("recast to v2 should write NEW.md: %v", err)
+	}
+
+	// Rollback must not need the remote.
+	if err := os.RemoveAll(env.bareDir); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := env.run(t, project, "rollback", "e2e-recast", "--dry-run"); err != nil || !strings.Contains(string(out), "v1.0.0") {
+		t.Fatalf("rollback --dry-run: %v\n%s", err, out)
+	}
+	if out, err := env.run(t, project, "rollback", env.repo); err != nil {
+		t.Fatalf("rollback failed: %v\n%s", err, out)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(project, "README.md"))
+	if !strings.Contains(string(got), "foo=one") || strings.Contains(string(got), "(v2)") {
+		t.Errorf("README.md after rollback = %q, want v1 rendered with foo=one", got)
+	}
+	if _, err := os.Stat(filepath.Join(project, "NEW.md")); !os.IsNotExist(err) {
+		t.Errorf("NEW.md should be removed by rollback, stat err = %v", err)
+	}
+	manifest, err := foundry.ReadInstalledManifest(filepath.Join(project, ".ailloy", "installed.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := manifest.Molds[0]; e.Version != "v1.0.0" || e.CastOptions == nil || e.CastOptions.SetOverrides[0] != "foo=one" {
+		t.Errorf("installed entry after rollback = %+v, want v1.0.0 with foo=one", e)
+	}
+
+	history, err := readHistory(filepath.Join(project, ".ailloy", historyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.Op != "rollback" || last.Version != "v1.0.0" {
+		t.Errorf("last history entry = %+v, want rollback to v1.0.0", last)
+	}
+}
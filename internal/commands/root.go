@@ -2,30 +2,118 @@ package commands
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/internal/tui/splash"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/smelt"
 	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 	"github.com/spf13/cobra"
 )
 
-var rootNoAnimate bool
+var (
+	rootNoAnimate bool
+	rootVerbose   bool
+	rootQuiet     bool
+	rootLogFormat string
+	rootNoColor   bool
+	rootPlain     bool
+
+	rootNonInteractive bool
+	rootYes            bool
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "ailloy",
 	Short: "The package manager for AI instructions",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		styles.Init()
-		styles.SetNoAnimate(rootNoAnimate)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		initStyles()
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+		if rootNonInteractive {
+			// Extensions and mold hook scripts inherit the environment, so
+			// they stay non-interactive too.
+			_ = os.Setenv(nonInteractiveEnv, "1")
+		}
+		if err := applyMirrors(); err != nil {
+			return err
+		}
+		if cmd != revertCmd {
+			warnExpiredTrials(time.Now())
+		}
+		// Sweep scratch space left behind by interrupted runs. Best effort:
+		// a failure here must never block the command.
+		_, _ = tmpdir.CleanOrphans(tmpdir.OrphanAge)
+		sweepReplacedExecutables()
+		pendingUpdateCheck = startUpdateCheck(cmd)
+		return nil
 	},
 }
 
+// initStyles applies the global --no-color, --plain, and --no-animate flags.
+func initStyles() {
+	styles.SetNoColor(rootNoColor)
+	styles.SetPlain(rootPlain)
+	styles.Init()
+	styles.SetNoAnimate(rootNoAnimate)
+}
+
+// setupLogging applies the global --verbose, --quiet, and --log-format
+// flags. Subcommands that define their own --verbose (assay) keep it.
+func setupLogging(cmd *cobra.Command) error {
+	verbose := rootVerbose
+	if f := cmd.Flags().Lookup("verbose"); f != nil && f != cmd.Root().PersistentFlags().Lookup("verbose") {
+		verbose = false
+	}
+	return logging.Setup(logging.Options{Format: rootLogFormat, Verbose: verbose, Quiet: rootQuiet})
+}
+
+// nonInteractiveEnv disables every prompt when set, like --non-interactive.
+const nonInteractiveEnv = "AILLOY_NONINTERACTIVE"
+
+// nonInteractive reports whether prompts are turned off by the global
+// --non-interactive flag or AILLOY_NONINTERACTIVE. Each prompt then takes
+// its default answer, or fails naming the flag that answers it.
+func nonInteractive() bool {
+	return rootNonInteractive || os.Getenv(nonInteractiveEnv) != ""
+}
+
+// assumeYesEnv pre-approves like --yes when set.
+const assumeYesEnv = "AILLOY_YES"
+
+// assumeYes reports whether the global --yes flag or AILLOY_YES
+// pre-approves mold-supplied commands that haven't been approved yet.
+func assumeYes() bool {
+	return rootYes || os.Getenv(assumeYesEnv) != ""
+}
+
+// applyMirrors installs the foundry mirrors from config.yaml so every git
+// command this invocation runs fetches through them. An unreadable config is
+// left for the commands that need it to report.
+func applyMirrors() error {
+	cfg, err := index.LoadConfig()
+	if err != nil {
+		return nil
+	}
+	if err := foundry.SetMirrors(cfg.MirrorRules()); err != nil {
+		return configError(fmt.Errorf("foundry.mirrors in config.yaml: %w", err))
+	}
+	return nil
+}
+
 // SetVersionInfo sets the version information injected via ldflags at build time.
 func SetVersionInfo(version, commit, date string) {
 	evolveCurrentVersion = version
+	smelt.SetBuilderVersion(version)
 	if commit != "unknown" && date != "unknown" {
 		rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date)
 	} else {
@@ -34,10 +122,22 @@ func SetVersionInfo(version, commit, date string) {
 	rootCmd.Long = buildLongDescription(rootCmd.Version)
 }
 
+// Execute runs the command line and exits with the failure class's exit
+// code (see exit.go) when the command fails.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, styles.ErrorStyle.Render("Error: ")+err.Error())
-		os.Exit(1)
+	tagUsageErrors(rootCmd)
+	err := rootCmd.Execute()
+	code := exitCodeFor(err)
+	if err != nil {
+		if logging.JSON() {
+			slog.Error(err.Error(), "exitCode", code)
+		} else {
+			fmt.Fprintln(os.Stderr, styles.ErrorStyle.Render("Error: ")+err.Error())
+		}
+	}
+	printUpdateNotice()
+	if err != nil {
+		os.Exit(code)
 	}
 }
 
@@ -77,13 +177,16 @@ func buildLongDescription(version string) string {
 // (when allowed by the environment) and then prints the static help into
 // normal scrollback so it persists for the user to read, scroll, and copy.
 // PersistentPreRun isn't called for help-only invocations, so we redo the
-// styles.Init / SetNoAnimate setup here.
+// style setup here.
 func animatedHelpFunc(cmd *cobra.Command, args []string) {
-	styles.Init()
-	styles.SetNoAnimate(rootNoAnimate)
+	initStyles()
 
 	if cmd == rootCmd {
 		splash.Run() // no-op when not animatable; never writes to stdout permanently
+		if styles.Plain() {
+			// Long was built before flags were parsed, with the fox.
+			cmd.Long = buildLongDescription(cmd.Version)
+		}
 	}
 
 	fmt.Println(cmd.Long)
@@ -91,8 +194,15 @@ func animatedHelpFunc(cmd *cobra.Command, args []string) {
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&rootVerbose, "verbose", "v", false, "verbose output: include debug logs")
+	rootCmd.PersistentFlags().BoolVarP(&rootQuiet, "quiet", "q", false, "only print warnings, errors, and command data")
+	rootCmd.PersistentFlags().StringVar(&rootLogFormat, "log-format", logging.FormatText, "log format: text (styled, for people) or json (one record per line on stderr)")
 	rootCmd.PersistentFlags().BoolVar(&rootNoAnimate, "no-animate", false, "disable terminal animations")
+	rootCmd.PersistentFlags().BoolVar(&rootNoColor, "no-color", false, "disable colored output (also set by NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&rootPlain, "plain", false, "plain ASCII output: no color, emoji, box art, or animation (also set by TERM=dumb)")
+	rootCmd.PersistentFlags().BoolVar(&rootNoExec, "no-exec", false, "never run commands supplied by molds (flux discover commands, hook scripts)")
+	rootCmd.PersistentFlags().BoolVar(&rootYes, "yes", false, "run mold-supplied commands (hook scripts, flux discover commands) without asking for consent; the exec policy still applies (also set by AILLOY_YES)")
+	rootCmd.PersistentFlags().BoolVar(&rootNonInteractive, "non-interactive", false, "never prompt: every question takes its default answer (also set by AILLOY_NONINTERACTIVE, and whenever stdin or stdout isn't a terminal)")
 	rootCmd.SetHelpFunc(animatedHelpFunc)
 
 	// Register custom template function to render commands as a styled table
@@ -107,7 +217,7 @@ func init() {
 		}
 
 		t := table.New().
-			Border(lipgloss.NormalBorder()).
+			Border(styles.TableBorder()).
 			BorderStyle(lipgloss.NewStyle().Foreground(styles.Primary1)).
 			StyleFunc(func(row, col int) lipgloss.Style {
 				if row == table.HeaderRow {
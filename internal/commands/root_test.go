@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNonInteractive_FlagAndEnv(t *testing.T) {
+	t.Setenv(nonInteractiveEnv, "")
+	defer func() { rootNonInteractive = false }()
+
+	if nonInteractive() {
+		t.Fatal("prompts should be on without the flag or env")
+	}
+	t.Setenv(nonInteractiveEnv, "1")
+	if !nonInteractive() || isInteractive() {
+		t.Error("AILLOY_NONINTERACTIVE should turn prompts off")
+	}
+	t.Setenv(nonInteractiveEnv, "")
+	rootNonInteractive = true
+	if !nonInteractive() || isInteractive() || stdinIsTTY() {
+		t.Error("--non-interactive should turn prompts off")
+	}
+}
+
+func TestResolveMoldReaderWithDefaultBranch_DeclinesWithoutPrompt(t *testing.T) {
+	castLatestOnNoTags = false
+	_, _, err := resolveMoldReaderWithDefaultBranch("github.com/acme/untagged")
+	if err == nil || !strings.Contains(err.Error(), "--latest-on-no-tags") {
+		t.Fatalf("want abort suggesting --latest-on-no-tags, got %v", err)
+	}
+}
+
+func TestRunGeneratePlugin_ExistingDirNeedsForceWithoutPrompt(t *testing.T) {
+	oldDir, oldForce := pluginOutputDir, pluginForce
+	defer func() { pluginOutputDir, pluginForce = oldDir, oldForce }()
+	pluginOutputDir, pluginForce = t.TempDir(), false
+
+	err := runGeneratePlugin(generatePluginCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("want an error suggesting --force, got %v", err)
+	}
+}
@@ -16,4 +16,8 @@ func init() {
 		}
 		return fsys, nil, nil
 	}
+	mold.ResolveValuesFunc = func(ref string) (fs.FS, error) {
+		fsys, _, err := foundry.ResolveWithMetadata(ref)
+		return fsys, err
+	}
 }
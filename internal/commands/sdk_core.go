@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// The functions in this file back the pkg/ailloy SDK. Like CastMold they
+// take explicit options instead of reading cast/forge flag vars, and write
+// nothing to the terminal.
+
+// RenderOptions configures RenderMold. All fields are optional.
+type RenderOptions struct {
+	ValueFiles   []string       // -f layered flux value files
+	Values       map[string]any // top-level flux values, layered after ValueFiles
+	SetOverrides []string       // --set key=val overrides
+	Profile      string         // output profile (see mold.ApplyOutputProfile)
+	// Logger receives flux validation and skipped-render warnings; nil
+	// discards them.
+	Logger *log.Logger
+}
+
+// RenderedBlank is one mold output rendered in memory.
+type RenderedBlank struct {
+	Dest     string // destination relative to the cast root
+	Src      string // source path within the mold
+	Strategy string // "", "replace", "merge", or "append"
+	Content  []byte
+}
+
+// RenderMold renders every output of reader's mold the way forge does.
+// Nothing is written; ore deps are resolved ephemerally. remote reports
+// whether reader came from a foundry, which disallows local-path deps.
+func RenderMold(reader *blanks.MoldReader, remote bool, opts RenderOptions) (*mold.Mold, []RenderedBlank, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	manifest, files, err := forgeRender(reader, remote, forgeInput{
+		valFiles:  opts.ValueFiles,
+		values:    opts.Values,
+		setValues: opts.SetOverrides,
+		profile:   opts.Profile,
+		logger:    logger,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make([]RenderedBlank, 0, len(files))
+	for _, f := range files {
+		out = append(out, RenderedBlank{Dest: f.destPath, Src: f.srcPath, Strategy: f.strategy, Content: []byte(f.content)})
+	}
+	return manifest, out, nil
+}
+
+// TemperMold validates reader's mold or ingot the way `ailloy temper` does.
+func TemperMold(reader *blanks.MoldReader, remote bool) *mold.TemperResult {
+	return temperPackage(reader.FS(), reader.Root(), !remote)
+}
+
+// PlannedFile is one file a cast would install.
+type PlannedFile struct {
+	Path     string // destination; absolute under $HOME for global casts
+	Src      string // source path within the mold
+	Strategy string // "", "replace", "merge", or "append"
+	// Content is the rendered blank as cast writes it, with its provenance
+	// header. For merge and append strategies it is the fragment cast
+	// combines with the existing file, not the result.
+	Content []byte
+	// Exists reports whether Path is already on disk; Unchanged, whether
+	// its bytes equal Content (always false for merge and append).
+	Exists    bool
+	Unchanged bool
+}
+
+// PlanCastMold renders what CastMold would install from reader without
+// touching disk. source is the mold's foundry override key (empty for local
+// molds) and selects persisted flux files. Declared deps are not installed:
+// ore overlays already present under .ailloy/ are layered in, others are
+// not. Claude plugin packaging is not supported.
+func PlanCastMold(_ context.Context, reader *blanks.MoldReader, source string, opts CastOptions) ([]PlannedFile, error) {
+	if opts.ClaudePlugin {
+		return nil, fmt.Errorf("planning is not supported for claude plugin casts")
+	}
+	silentLogger := log.New(io.Discard, "", 0)
+
+	manifest, err := reader.LoadManifest()
+	if err != nil {
+		return nil, fmt.Errorf("loading mold manifest: %w", err)
+	}
+	flux, mergedSchema, err := layerFluxForCore(reader, source, opts.ValueFiles, opts.fluxSets(), opts.Global, coreResolveOpts(opts.Global, opts.Offline, silentLogger))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := selectOutputProfile(flux, manifest, opts.Profile); err != nil {
+		return nil, err
+	}
+
+	destPrefix, err := globalDestPrefix(opts.Global)
+	if err != nil {
+		return nil, err
+	}
+
+	ignore := mold.LoadIgnorePatterns(reader.FS(), manifest)
+	var resolveOpts []mold.ResolveOption
+	if len(ignore) > 0 {
+		resolveOpts = append(resolveOpts, mold.WithIgnorePatterns(ignore))
+	}
+	resolved, err := mold.ResolveFiles(flux["output"], reader.FS(), resolveOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving output files: %w", err)
+	}
+	if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+		return nil, err
+	}
+	ci, err := castCISystem(opts.WithWorkflows, opts.CI, destPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var filesToCast []mold.ResolvedFile
+	for _, rf := range resolved {
+		if mold.SkipCIFile(rf.DestPath, ci) {
+			continue
+		}
+		rf.DestPath = castDest(destPrefix, rf.DestPath)
+		filesToCast = append(filesToCast, rf)
+	}
+
+	rendered, err := renderCastFiles(reader, manifest, mergedSchema, flux, filesToCast, silentLogger)
+	if err != nil {
+		return nil, err
+	}
+	plan := make([]PlannedFile, 0, len(rendered))
+	for _, f := range rendered {
+		pf := PlannedFile{Path: f.DestPath, Src: f.SrcPath, Strategy: f.Strategy, Content: f.content}
+		replace := f.Strategy == "" || f.Strategy == "replace"
+		if replace {
+			pf.Content, _ = replaceContent(manifest, f, copyOpts{Provenance: true})
+		}
+		if existing, err := os.ReadFile(f.DestPath); err == nil { // #nosec G304 -- mold-declared destination
+			pf.Exists = true
+			pf.Unchanged = replace && bytes.Equal(existing, pf.Content)
+		}
+		plan = append(plan, pf)
+	}
+	return plan, nil
+}
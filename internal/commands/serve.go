@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/plugin"
+	"github.com/nimble-giant/ailloy/pkg/smelt"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local HTTP API for rendering molds",
+	Long: `Serve a JSON HTTP API for listing, validating, and rendering molds.
+
+For platforms that want ailloy rendering behind a service instead of
+shelling out. Endpoints:
+
+  GET  /healthz      liveness check
+  GET  /v1/molds     molds in the foundry cache, with their cached versions
+  POST /v1/temper    validate a mold or ingot: {"mold": "<ref or path>"}
+  POST /v1/render    render a mold: {"mold": "<ref or path>", "values": {...},
+                     "set": ["key=value"], "profile": "<name>"}
+
+Nothing is installed: render returns the rendered files like forge does.
+Molds are named by remote reference or by a directory on the server's
+filesystem, so bind to a non-loopback address only on trusted networks.
+POSTs must be application/json, and requests must name the listen address
+or localhost as their Host, so web pages can't reach the API from a
+browser.
+
+Example:
+  ailloy serve --addr 127.0.0.1:8484
+  curl -s localhost:8484/v1/render -H 'Content-Type: application/json' \
+    -d '{"mold":"github.com/acme/mold","values":{"team":"core"}}'`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var serveAddr string
+
+// serveMaxBody caps request bodies; flux values are small.
+const serveMaxBody = 1 << 20
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8484", "address to listen on")
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	ln, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", serveAddr, err)
+	}
+	srv := &http.Server{
+		Handler:           newServeHandler(ln.Addr().String()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Println(styles.SuccessStyle.Render("Serving the ailloy API on ") + styles.CodeStyle.Render("http://"+ln.Addr().String()))
+	fmt.Println(styles.SubtleStyle.Render("Press Ctrl+C to stop."))
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// newServeHandler routes the `serve` API listening on addr.
+func newServeHandler(addr string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeServeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /v1/molds", serveListMolds)
+	mux.HandleFunc("POST /v1/temper", serveTemper)
+	mux.HandleFunc("POST /v1/render", serveRender)
+	return serveGuard(addr, mux)
+}
+
+// serveGuard keeps browsers away from the API: a web page can POST to a
+// loopback address cross-site, and with DNS rebinding read the response.
+// It rejects requests whose Host is neither addr nor localhost, whose
+// Origin names another host, and POSTs that aren't application/json (which
+// a page can't send without a CORS preflight the API never answers).
+func serveGuard(addr string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !serveHostAllowed(addr, r.Host) {
+			writeServeError(w, http.StatusForbidden, fmt.Errorf("host %q is not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				writeServeError(w, http.StatusForbidden, fmt.Errorf("origin %q is not allowed", origin))
+				return
+			}
+		}
+		if r.Method == http.MethodPost {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				writeServeError(w, http.StatusUnsupportedMediaType, errors.New("request Content-Type must be application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveHostAllowed reports whether a request Host names the server
+// listening on addr: localhost, a loopback address, or addr's own host.
+// Listening on all interfaces, any IP address is allowed; DNS rebinding
+// needs a host name.
+func serveHostAllowed(addr, host string) bool {
+	name := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		name = h
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "["), "]")
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+	listen, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		listen = addr
+	}
+	if strings.EqualFold(name, listen) {
+		return true
+	}
+	ip := net.ParseIP(name)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	listenIP := net.ParseIP(listen)
+	return listen == "" || (listenIP != nil && listenIP.IsUnspecified())
+}
+
+// serveCachedMold is one GET /v1/molds entry.
+type serveCachedMold struct {
+	Source   string   `json:"source"`
+	Versions []string `json:"versions"`
+}
+
+func serveListMolds(w http.ResponseWriter, _ *http.Request) {
+	cacheDir, err := foundry.CacheDir()
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	entries, err := foundry.ListCachedMolds(cacheDir)
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	molds := make([]serveCachedMold, 0, len(entries))
+	for _, e := range entries {
+		versions := append([]string{}, e.Versions...)
+		sort.Strings(versions)
+		molds = append(molds, serveCachedMold{Source: e.Host + "/" + e.Owner + "/" + e.Repo, Versions: versions})
+	}
+	sort.Slice(molds, func(i, j int) bool { return molds[i].Source < molds[j].Source })
+	writeServeJSON(w, http.StatusOK, map[string]any{"molds": molds})
+}
+
+// serveDiagnostic is a temper diagnostic in a /v1/temper response.
+type serveDiagnostic struct {
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+	Tip     string `json:"tip,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+}
+
+// serveTemperResult is the /v1/temper response.
+type serveTemperResult struct {
+	Name     string            `json:"name,omitempty"`
+	Kind     string            `json:"kind,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Valid    bool              `json:"valid"`
+	Errors   []serveDiagnostic `json:"errors"`
+	Warnings []serveDiagnostic `json:"warnings"`
+}
+
+func serveTemper(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Mold string `json:"mold"`
+	}
+	if !decodeServeRequest(w, r, &req) {
+		return
+	}
+	if req.Mold == "" {
+		writeServeError(w, http.StatusBadRequest, errors.New("mold is required"))
+		return
+	}
+	reader, remote, err := resolveForgeReader([]string{req.Mold})
+	if err != nil {
+		writeServeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	result := temperPackage(reader.FS(), reader.Root(), !remote)
+	writeServeJSON(w, http.StatusOK, serveTemperResult{
+		Name:     result.Name,
+		Kind:     result.ManifestKind,
+		Version:  result.Version,
+		Valid:    !result.HasErrors(),
+		Errors:   serveDiagnostics(result.Errors()),
+		Warnings: serveDiagnostics(result.Warnings()),
+	})
+}
+
+func serveDiagnostics(diags []mold.Diagnostic) []serveDiagnostic {
+	out := make([]serveDiagnostic, 0, len(diags))
+	for _, d := range diags {
+		out = append(out, serveDiagnostic{File: d.File, Message: d.Message, Tip: d.Tip, Rule: d.Rule})
+	}
+	return out
+}
+
+// renderedFileJSON is one rendered output file in a render response.
+type renderedFileJSON struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// serveRenderResult is the /v1/render response.
+type serveRenderResult struct {
+	Mold    string             `json:"mold,omitempty"`
+	Version string             `json:"version,omitempty"`
+	Files   []renderedFileJSON `json:"files"`
+}
+
+func serveRender(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Mold    string         `json:"mold"`
+		Values  map[string]any `json:"values"`
+		Set     []string       `json:"set"`
+		Profile string         `json:"profile"`
+	}
+	if !decodeServeRequest(w, r, &req) {
+		return
+	}
+	if req.Mold == "" {
+		writeServeError(w, http.StatusBadRequest, errors.New("mold is required"))
+		return
+	}
+	manifest, files, err := renderMoldRef(req.Mold, req.Values, req.Set, req.Profile)
+	if err != nil {
+		writeServeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	res := serveRenderResult{Files: make([]renderedFileJSON, 0, len(files))}
+	if manifest != nil {
+		res.Mold, res.Version = manifest.Name, manifest.Version
+	}
+	for _, f := range files {
+		res.Files = append(res.Files, renderedFileJSON{Path: f.CastDest, Content: string(f.Content)})
+	}
+	writeServeJSON(w, http.StatusOK, res)
+}
+
+// renderMoldRef renders the mold at ref (a remote reference or local
+// directory) the way forge does, without installing anything. values is
+// layered like a -f file, then set like --set. An empty ref means the
+// embedded mold in a smelted binary, else the current directory. Shared by
+// `serve` and `mcp serve`.
+func renderMoldRef(ref string, values map[string]any, set []string, profile string) (*mold.Mold, []plugin.RenderedFile, error) {
+	moldArgs := []string{ref}
+	if ref == "" {
+		moldArgs = []string{"."}
+		if smelt.HasEmbeddedMold() {
+			moldArgs = nil
+		}
+	}
+	reader, remote, err := resolveForgeReader(moldArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, rendered, err := RenderMold(reader, remote, RenderOptions{Values: values, SetOverrides: set, Profile: profile})
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make([]plugin.RenderedFile, 0, len(rendered))
+	for _, b := range rendered {
+		files = append(files, plugin.RenderedFile{CastDest: b.Dest, Content: b.Content})
+	}
+	return manifest, files, nil
+}
+
+// decodeServeRequest reads a JSON request body into req, writing a 400 and
+// returning false when it can't.
+func decodeServeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, serveMaxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(req); err != nil {
+		writeServeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeServeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	writeServeJSON(w, status, map[string]string{"error": err.Error()})
+}
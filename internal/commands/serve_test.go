@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func serveRequest(t *testing.T, method, path, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = "127.0.0.1:8484"
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	newServeHandler("127.0.0.1:8484").ServeHTTP(rec, req)
+	var out map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, out
+}
+
+func TestServe_Render(t *testing.T) {
+	moldDir := fluxCastMold(t)
+	chdir(t, t.TempDir())
+
+	body, _ := json.Marshal(map[string]any{
+		"mold":   moldDir,
+		"values": map[string]any{"team": "Atlas"},
+	})
+	code, out := serveRequest(t, http.MethodPost, "/v1/render", string(body))
+	if code != http.StatusOK {
+		t.Fatalf("status %d: %v", code, out)
+	}
+	if out["mold"] != "flux-mold" || out["version"] != "1.0.0" {
+		t.Errorf("mold/version = %v/%v", out["mold"], out["version"])
+	}
+	files := out["files"].([]any)
+	if len(files) != 1 {
+		t.Fatalf("files = %v", files)
+	}
+	f := files[0].(map[string]any)
+	if f["path"] != ".claude/commands/team.md" || !strings.Contains(f["content"].(string), "Team: Atlas") {
+		t.Errorf("file = %v", f)
+	}
+	if _, err := os.Stat(".claude"); !os.IsNotExist(err) {
+		t.Error("render must not install anything")
+	}
+
+	// --set-style overrides win over posted values.
+	body, _ = json.Marshal(map[string]any{
+		"mold":   moldDir,
+		"values": map[string]any{"team": "Atlas"},
+		"set":    []string{"team=Borealis"},
+	})
+	_, out = serveRequest(t, http.MethodPost, "/v1/render", string(body))
+	if content := out["files"].([]any)[0].(map[string]any)["content"].(string); !strings.Contains(content, "Team: Borealis") {
+		t.Errorf("set should override values, got %q", content)
+	}
+}
+
+func TestServe_RequestErrors(t *testing.T) {
+	chdir(t, t.TempDir())
+	tests := []struct {
+		path, body string
+		want       int
+		msg        string
+	}{
+		{"/v1/render", `{}`, http.StatusBadRequest, "mold is required"},
+		{"/v1/render", `{"mold":"x","bogus":1}`, http.StatusBadRequest, "invalid request body"},
+		{"/v1/temper", `not json`, http.StatusBadRequest, "invalid request body"},
+		{"/v1/render", `{"mold":"./missing"}`, http.StatusUnprocessableEntity, "missing"},
+	}
+	for _, tt := range tests {
+		code, out := serveRequest(t, http.MethodPost, tt.path, tt.body)
+		if code != tt.want || !strings.Contains(out["error"].(string), tt.msg) {
+			t.Errorf("POST %s %s = %d %v, want %d containing %q", tt.path, tt.body, code, out, tt.want, tt.msg)
+		}
+	}
+}
+
+func TestServe_Temper(t *testing.T) {
+	moldDir := fluxCastMold(t)
+	chdir(t, t.TempDir())
+
+	code, out := serveRequest(t, http.MethodPost, "/v1/temper", `{"mold":"`+filepath.ToSlash(moldDir)+`"}`)
+	if code != http.StatusOK || out["valid"] != true || out["kind"] != "mold" || out["name"] != "flux-mold" {
+		t.Fatalf("temper = %d %v", code, out)
+	}
+
+	broken := t.TempDir()
+	mustWrite(t, filepath.Join(broken, "mold.yaml"), "apiVersion: v1\nkind: mold\n")
+	_, out = serveRequest(t, http.MethodPost, "/v1/temper", `{"mold":"`+filepath.ToSlash(broken)+`"}`)
+	if out["valid"] != false || len(out["errors"].([]any)) == 0 {
+		t.Errorf("broken mold should fail temper, got %v", out)
+	}
+}
+
+func TestServe_ListMolds(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, v := range []string{"v1.1.0", "v1.0.0", "git"} {
+		if err := os.MkdirAll(filepath.Join(home, ".ailloy", "cache", "github.com", "acme", "mold", v), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code, out := serveRequest(t, http.MethodGet, "/v1/molds", "")
+	if code != http.StatusOK {
+		t.Fatalf("status %d: %v", code, out)
+	}
+	molds := out["molds"].([]any)
+	if len(molds) != 1 {
+		t.Fatalf("molds = %v", molds)
+	}
+	m := molds[0].(map[string]any)
+	if m["source"] != "github.com/acme/mold" || len(m["versions"].([]any)) != 2 || m["versions"].([]any)[0] != "v1.0.0" {
+		t.Errorf("mold = %v", m)
+	}
+
+	if code, out := serveRequest(t, http.MethodGet, "/healthz", ""); code != http.StatusOK || out["status"] != "ok" {
+		t.Errorf("healthz = %d %v", code, out)
+	}
+}
+
+func TestServe_RejectsBrowserRequests(t *testing.T) {
+	chdir(t, t.TempDir())
+	tests := []struct {
+		name, host, origin, contentType string
+		want                            int
+	}{
+		{"json from curl", "127.0.0.1:8484", "", "application/json", http.StatusUnprocessableEntity},
+		{"localhost host", "localhost:8484", "", "application/json; charset=utf-8", http.StatusUnprocessableEntity},
+		{"same origin", "localhost:8484", "http://localhost:8484", "application/json", http.StatusUnprocessableEntity},
+		{"rebound host", "evil.example:8484", "", "application/json", http.StatusForbidden},
+		{"cross-site origin", "127.0.0.1:8484", "https://evil.example", "application/json", http.StatusForbidden},
+		{"simple request", "127.0.0.1:8484", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"no content type", "127.0.0.1:8484", "", "", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/v1/render", strings.NewReader(`{"mold":"./missing"}`))
+		req.Host = tt.host
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		rec := httptest.NewRecorder()
+		newServeHandler("127.0.0.1:8484").ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, rec.Code, tt.want, rec.Body.String())
+		}
+	}
+}
+
+func TestServeHostAllowed(t *testing.T) {
+	tests := []struct {
+		addr, host string
+		want       bool
+	}{
+		{"127.0.0.1:8484", "127.0.0.1:8484", true},
+		{"127.0.0.1:8484", "localhost", true},
+		{"127.0.0.1:8484", "[::1]:8484", true},
+		{"127.0.0.1:8484", "192.168.1.5:8484", false},
+		{"192.168.1.5:8484", "192.168.1.5:8484", true},
+		{"[::]:8484", "192.168.1.5:8484", true},
+		{"[::]:8484", "rebind.example:8484", false},
+	}
+	for _, tt := range tests {
+		if got := serveHostAllowed(tt.addr, tt.host); got != tt.want {
+			t.Errorf("serveHostAllowed(%q, %q) = %v, want %v", tt.addr, tt.host, got, tt.want)
+		}
+	}
+}
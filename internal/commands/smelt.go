@@ -2,42 +2,159 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/internal/tui/ceremony"
 	"github.com/nimble-giant/ailloy/pkg/smelt"
 	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 	"github.com/spf13/cobra"
 )
 
 var smeltCmd = &cobra.Command{
-	Use:     "smelt [mold-dir]",
+	Use:     "smelt [mold-dir...]",
 	Aliases: []string{"package"},
 	Short:   "Package a mold into a distributable format",
 	Long: `Package a mold into a distributable archive (alias: package).
 
 By default, creates a .tar.gz tarball from the current mold directory.
-Use -o binary for self-contained binary output (embeds the mold in the ailloy binary).`,
+Tarballs are reproducible: the same mold always yields a byte-identical
+archive. --check-reproducible builds it a second time and fails if the two
+differ.
+Use -o binary for self-contained binary output (embeds the mold in the ailloy binary).
+
+With -o binary, --platforms builds one binary per os/arch target plus a
+checksums file, e.g. --platforms linux/amd64,darwin/arm64,windows/amd64.
+Each target is stuffed into the ailloy release binary for that platform:
+taken from --base-dir when it holds ailloy-<os>-<arch>[.exe], the running
+binary for the current platform, and otherwise downloaded from the GitHub
+release matching this ailloy's version and verified against its checksums.
+--package-meta also writes a Homebrew formula, a Scoop manifest and nfpm
+configs (for .deb/.rpm) for those binaries; --download-url is where they
+will be published.
+
+With -o binary, several mold directories build one binary that embeds them
+all, e.g. ailloy smelt -o binary ./base ./team-web ./team-data. The binary
+is named after the first mold; cast and forge pick a mold with --mold, else
+the --default-mold given here, else prompt for one.`,
 	RunE: runSmelt,
 }
 
+var smeltInspectCmd = &cobra.Command{
+	Use:   "inspect <artifact>",
+	Short: "Print the provenance recorded in a smelted tarball or binary",
+	Long: `Print the provenance.yaml smelt embeds in every tarball and binary: the
+mold, the source git commit and whether the checkout was dirty, the ailloy
+version that built it, the build time, and the SHA-256 of every file inside.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSmeltInspect,
+}
+
+var smeltPushCmd = &cobra.Command{
+	Use:   "push [mold-dir]",
+	Short: "Tag, release, and index a new version of a mold",
+	Long: `Publish the version in mold.yaml.
+
+Push tags the repository (v<version>, or <dir>-v<version> for a mold in a
+subdirectory), pushes the tag, and creates a GitHub or GitLab release with
+the smelted tarball and its checksums attached, using the gh or glab CLI.
+The provider is detected from the remote's host; use --provider for
+self-hosted instances, or --no-release to only push the tag.
+
+With --index, the mold's entry in that foundry.yaml is added or updated;
+commit and push the index yourself.
+
+Nothing is changed unless the mold is committed and the tag is new.
+--dry-run runs those checks and prints the plan.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runSmeltPush,
+}
+
 var (
 	smeltOutputFormat string
 	smeltOutputPath   string
+	smeltPlatforms    string
+	smeltBaseDir      string
+	smeltCheckRepro   bool
+	smeltInspectYAML  bool
+	smeltDefaultMold  string
+	smeltPackageMeta  bool
+	smeltDownloadURL  string
+
+	smeltPushRemote     string
+	smeltPushProvider   string
+	smeltPushIndex      string
+	smeltPushNoRelease  bool
+	smeltPushAllowDirty bool
+	smeltPushDryRun     bool
 )
 
 func init() {
 	rootCmd.AddCommand(smeltCmd)
+	smeltCmd.AddCommand(smeltInspectCmd)
+	smeltCmd.AddCommand(smeltPushCmd)
+
+	smeltPushCmd.Flags().StringVar(&smeltPushRemote, "remote", "origin", "git remote to tag and release against")
+	smeltPushCmd.Flags().StringVar(&smeltOutputPath, "output", "", "keep the tarball and checksums in this directory")
+	smeltPushCmd.Flags().StringVar(&smeltPushProvider, "provider", "", "release provider: github or gitlab (default: detect from the remote host)")
+	smeltPushCmd.Flags().StringVar(&smeltPushIndex, "index", "", "foundry.yaml to add or update this mold's entry in")
+	smeltPushCmd.Flags().BoolVar(&smeltPushNoRelease, "no-release", false, "push the tag without creating a release")
+	smeltPushCmd.Flags().BoolVar(&smeltPushAllowDirty, "allow-dirty", false, "publish even with uncommitted changes in the mold")
+	smeltPushCmd.Flags().BoolVar(&smeltPushDryRun, "dry-run", false, "run the checks and print the plan without changing anything")
 
 	smeltCmd.Flags().StringVarP(&smeltOutputFormat, "output-format", "o", "tar", "output format: tar, binary")
 	smeltCmd.Flags().StringVar(&smeltOutputPath, "output", "", "output directory (default: current directory)")
+	smeltCmd.Flags().BoolVar(&smeltCheckRepro, "check-reproducible", false, "with -o tar, build the archive twice and fail unless both are byte-identical")
+	smeltCmd.Flags().StringVar(&smeltPlatforms, "platforms", "", "with -o binary, comma-separated os/arch targets to build (e.g. linux/amd64,darwin/arm64,windows/amd64)")
+	smeltCmd.Flags().StringVar(&smeltDefaultMold, "default-mold", "", "with -o binary and several molds, the one cast uses when --mold isn't given")
+	smeltCmd.Flags().BoolVar(&smeltPackageMeta, "package-meta", false, "with --platforms, also write a Homebrew formula, Scoop manifest and nfpm configs for the binaries")
+	smeltCmd.Flags().StringVar(&smeltDownloadURL, "download-url", "", "with --package-meta, the URL the binaries will be published under (e.g. a release's download URL)")
+	smeltInspectCmd.Flags().BoolVar(&smeltInspectYAML, "yaml", false, "print the provenance as YAML")
+	smeltCmd.Flags().StringVar(&smeltBaseDir, "base-dir", "", "directory of ailloy-<os>-<arch> release binaries to stuff for --platforms (default: download the matching release)")
 }
 
 func runSmelt(_ *cobra.Command, args []string) error {
 	ceremony.Open(ceremony.Smelt)
 
-	moldDir := "."
-	if len(args) > 0 {
-		moldDir = args[0]
+	moldDirs := args
+	if len(moldDirs) == 0 {
+		moldDirs = []string{"."}
+	}
+	moldDir := moldDirs[0]
+	if len(moldDirs) > 1 && smeltOutputFormat != "binary" {
+		return fmt.Errorf("smelting several molds requires -o binary")
+	}
+	if smeltDefaultMold != "" && len(moldDirs) < 2 {
+		return fmt.Errorf("--default-mold requires -o binary and several mold directories")
+	}
+
+	if smeltPackageMeta && smeltPlatforms == "" {
+		return fmt.Errorf("--package-meta requires -o binary and --platforms")
+	}
+	if smeltPackageMeta && smeltDownloadURL == "" {
+		return fmt.Errorf("--package-meta requires --download-url, the URL the binaries will be published under")
+	}
+	if smeltDownloadURL != "" && !smeltPackageMeta {
+		return fmt.Errorf("--download-url requires --package-meta")
+	}
+
+	if smeltPlatforms != "" {
+		if smeltOutputFormat != "binary" {
+			return fmt.Errorf("--platforms requires -o binary")
+		}
+		return runSmeltPlatforms(moldDirs)
+	}
+	if smeltBaseDir != "" {
+		return fmt.Errorf("--base-dir requires --platforms")
+	}
+	if smeltCheckRepro && smeltOutputFormat != "tar" {
+		return fmt.Errorf("--check-reproducible requires -o tar")
 	}
 
 	var (
@@ -49,8 +166,11 @@ func runSmelt(_ *cobra.Command, args []string) error {
 	switch smeltOutputFormat {
 	case "tar":
 		outputFile, size, err = smelt.PackageTarball(moldDir, smeltOutputPath)
+		if err == nil && smeltCheckRepro {
+			err = smelt.CheckTarballReproducible(moldDir, outputFile)
+		}
 	case "binary":
-		outputFile, size, err = smelt.PackageBinary(moldDir, smeltOutputPath)
+		outputFile, size, err = smelt.PackageMultiMoldBinary(moldDirs, smeltDefaultMold, smeltOutputPath)
 	default:
 		return fmt.Errorf("unknown output format %q (supported: tar, binary)", smeltOutputFormat)
 	}
@@ -61,10 +181,176 @@ func runSmelt(_ *cobra.Command, args []string) error {
 
 	fmt.Println(styles.SuccessStyle.Render("Smelted: ") + styles.CodeStyle.Render(outputFile) +
 		styles.SubtleStyle.Render(fmt.Sprintf(" (%s)", humanSize(size))))
+	if smeltCheckRepro {
+		fmt.Println(styles.SuccessStyle.Render("Reproducible: ") + styles.SubtleStyle.Render("a second build is byte-identical"))
+	}
 	ceremony.Stamp(ceremony.Smelt, fmt.Sprintf("%s · %s", outputFile, humanSize(size)))
 	return nil
 }
 
+// runSmeltPlatforms smelts one binary per --platforms target plus a
+// checksums file.
+func runSmeltPlatforms(moldDirs []string) error {
+	platforms, err := smelt.ParsePlatforms(smeltPlatforms)
+	if err != nil {
+		return err
+	}
+
+	downloadDir, err := tmpdir.MkdirTemp("smelt-base-*")
+	if err != nil {
+		return fmt.Errorf("creating download directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(downloadDir) }()
+
+	bins, checksums, err := smelt.PackageMultiMoldBinaries(moldDirs, smeltDefaultMold, smeltOutputPath, platforms, smeltBaseBinary(smeltBaseDir, downloadDir))
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, b := range bins {
+		total += b.Size
+		fmt.Println(styles.SuccessStyle.Render("Smelted: ") + styles.CodeStyle.Render(b.Path) +
+			styles.SubtleStyle.Render(fmt.Sprintf(" (%s, %s)", b.Platform, humanSize(b.Size))))
+	}
+	fmt.Println(styles.SuccessStyle.Render("Checksums: ") + styles.CodeStyle.Render(checksums))
+
+	if smeltPackageMeta {
+		written, err := smelt.WritePackageMeta(moldDirs[0], smeltOutputPath, smeltDownloadURL, bins)
+		if err != nil {
+			return err
+		}
+		if len(written) == 0 {
+			fmt.Println(styles.WarningStyle.Render("Packaging: ") + "no target is packaged by Homebrew, Scoop or nfpm")
+		}
+		for _, p := range written {
+			fmt.Println(styles.SuccessStyle.Render("Packaging: ") + styles.CodeStyle.Render(p))
+		}
+	}
+	ceremony.Stamp(ceremony.Smelt, fmt.Sprintf("%d binaries · %s", len(bins), humanSize(total)))
+	return nil
+}
+
+// smeltBaseBinary returns the ailloy binary to stuff for each platform: the
+// release asset in baseDir when present, the running binary for the current
+// platform, and otherwise the asset of the release matching this build,
+// downloaded into downloadDir.
+func smeltBaseBinary(baseDir, downloadDir string) smelt.BaseBinaryFunc {
+	return func(p smelt.Platform) (string, error) {
+		asset := assetName(p.OS, p.Arch)
+		if baseDir != "" {
+			path := filepath.Join(baseDir, asset)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+		if p.OS == runtime.GOOS && p.Arch == runtime.GOARCH {
+			return resolveExecutable()
+		}
+
+		version := strings.TrimPrefix(strings.TrimSpace(evolveCurrentVersion), "v")
+		if version == "" || version == "dev" {
+			return "", fmt.Errorf("no %s to stuff: development builds can't download release binaries; pass --base-dir", asset)
+		}
+		dest := filepath.Join(downloadDir, asset)
+		if err := downloadReleaseAsset("v"+version, asset, dest); err != nil {
+			return "", fmt.Errorf("fetching ailloy v%s for %s: %w", version, p, err)
+		}
+		return dest, nil
+	}
+}
+
+func runSmeltInspect(cmd *cobra.Command, args []string) error {
+	p, err := smelt.ReadProvenance(args[0])
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if smeltInspectYAML {
+		data, err := yaml.Marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	row := func(label, value string) {
+		_, _ = fmt.Fprintf(out, "%s %s\n", styles.SubtleStyle.Render(fmt.Sprintf("%-9s", label+":")), value)
+	}
+	row("Mold", p.Mold.Name+"@"+p.Mold.Version)
+	if src := p.Source; src != nil {
+		commit := src.Commit
+		if src.Repository != "" {
+			commit = src.Repository + " @ " + commit
+		}
+		if src.Dirty {
+			commit += styles.WarningStyle.Render(" (dirty)")
+		}
+		row("Source", commit)
+	} else {
+		row("Source", styles.SubtleStyle.Render("not a git checkout"))
+	}
+	row("Builder", p.Builder.Name+" "+p.Builder.Version)
+	if p.BuiltAt != "" {
+		row("Built", p.BuiltAt)
+	}
+	row("Files", fmt.Sprintf("%d", len(p.Files)))
+	for _, f := range p.Files {
+		_, _ = fmt.Fprintf(out, "  %s  %8s  %s\n", styles.SubtleStyle.Render(f.SHA256), humanSize(int64(f.Size)), f.Path)
+	}
+	return nil
+}
+
+func runSmeltPush(_ *cobra.Command, args []string) error {
+	moldDir := "."
+	if len(args) > 0 {
+		moldDir = args[0]
+	}
+
+	res, err := smelt.Push(moldDir, smelt.PushOptions{
+		Remote:     smeltPushRemote,
+		OutputDir:  smeltOutputPath,
+		Provider:   smeltPushProvider,
+		IndexPath:  smeltPushIndex,
+		NoRelease:  smeltPushNoRelease,
+		AllowDirty: smeltPushAllowDirty,
+		DryRun:     smeltPushDryRun,
+		OnStep: func(msg string) {
+			logging.Say(styles.InfoStyle.Render("• ")+msg, msg)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if smeltPushDryRun {
+		release := "none (--no-release)"
+		if res.Provider != "" {
+			release = res.Provider + " release " + res.Tag + " with the tarball and checksums"
+		}
+		logging.Say(styles.InfoStyle.Render("Dry run: ")+"would publish "+res.Mold.Name+"@"+res.Mold.Version,
+			"dry run", "mold", res.Mold.Name, "version", res.Mold.Version, "tag", res.Tag, "source", res.Source)
+		logging.Say("  tag:     "+res.Tag+" → "+res.Remote, "tag", "tag", res.Tag, "remote", res.Remote)
+		logging.Say("  release: "+release, "release", "provider", res.Provider)
+		if res.Index != "" {
+			logging.Say("  index:   "+res.Index+" ("+res.Source+")", "index", "path", res.Index, "source", res.Source)
+		}
+		return nil
+	}
+
+	logging.Say(styles.SuccessStyle.Render("Published: ")+styles.CodeStyle.Render(res.Source+"@"+res.Tag),
+		"published", "source", res.Source, "tag", res.Tag)
+	if smeltOutputPath != "" {
+		logging.Say("  "+res.Tarball+"\n  "+res.Checksum, "artifacts", "tarball", res.Tarball, "checksums", res.Checksum)
+	}
+	if res.Index != "" {
+		logging.Say(styles.SubtleStyle.Render("  Commit and push "+res.Index+" to list the mold in your foundry."),
+			"index updated", "path", res.Index)
+	}
+	return nil
+}
+
 // humanSize formats a byte count as a human-readable string.
 func humanSize(b int64) string {
 	const unit = 1024
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/smelt"
+)
+
+func TestSmeltBaseBinary(t *testing.T) {
+	origVersion, origBase := evolveCurrentVersion, evolveReleaseDLBase
+	t.Cleanup(func() { evolveCurrentVersion, evolveReleaseDLBase = origVersion, origBase })
+
+	other := smelt.Platform{OS: "plan9", Arch: "amd64"}
+	if runtime.GOOS == "plan9" {
+		other.OS = "linux"
+	}
+	asset := assetName(other.OS, other.Arch)
+
+	t.Run("base dir wins", func(t *testing.T) {
+		baseDir := t.TempDir()
+		want := filepath.Join(baseDir, asset)
+		if err := os.WriteFile(want, []byte("bin"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		got, err := smeltBaseBinary(baseDir, t.TempDir())(other)
+		if err != nil || got != want {
+			t.Errorf("got (%s, %v), want %s", got, err, want)
+		}
+	})
+
+	t.Run("host uses running binary", func(t *testing.T) {
+		want, err := resolveExecutable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := smeltBaseBinary(t.TempDir(), t.TempDir())(smelt.Platform{OS: runtime.GOOS, Arch: runtime.GOARCH})
+		if err != nil || got != want {
+			t.Errorf("got (%s, %v), want %s", got, err, want)
+		}
+	})
+
+	t.Run("dev build can't download", func(t *testing.T) {
+		evolveCurrentVersion = ""
+		_, err := smeltBaseBinary("", t.TempDir())(other)
+		if err == nil || !strings.Contains(err.Error(), "--base-dir") {
+			t.Errorf("err = %v, want a --base-dir hint", err)
+		}
+	})
+
+	t.Run("downloads matching release", func(t *testing.T) {
+		body := []byte("release binary")
+		sum := sha256.Sum256(body)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			prefix := "/" + evolveRepoOwner + "/" + evolveRepoName + "/releases/download/v1.4.0/"
+			switch r.URL.Path {
+			case prefix + "checksums.txt":
+				_, _ = w.Write([]byte(hex.EncodeToString(sum[:]) + "  " + asset + "\n"))
+			case prefix + asset:
+				_, _ = w.Write(body)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer srv.Close()
+		evolveReleaseDLBase, evolveCurrentVersion = srv.URL, "1.4.0"
+
+		got, err := smeltBaseBinary("", t.TempDir())(other)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(got) // #nosec G304 -- test temp path
+		if err != nil || string(data) != string(body) {
+			t.Errorf("downloaded %q (%v), want %q", data, err, body)
+		}
+	})
+}
+
+func TestSmeltTarball_KeepsExecutableBlanks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no executable bit on Windows")
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	moldDir := t.TempDir()
+	mustWrite(t, filepath.Join(moldDir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: scripts\nversion: 1.0.0\n")
+	mustWrite(t, filepath.Join(moldDir, "flux.yaml"), "output:\n  bin: bin\n  commands: .claude/commands\n")
+	mustWrite(t, filepath.Join(moldDir, "bin", "setup.sh"), "#!/bin/sh\necho setup\n")
+	mustWrite(t, filepath.Join(moldDir, "commands", "hello.md"), "# hello\n")
+	if err := os.Chmod(filepath.Join(moldDir, "bin", "setup.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, _, err := smelt.PackageTarball(moldDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("PackageTarball: %v", err)
+	}
+	resetCastFlags()
+	t.Cleanup(resetCastFlags)
+	if err := runCast(castCmd, []string{archive}); err != nil {
+		t.Fatalf("cast %s: %v", archive, err)
+	}
+	for file, want := range map[string]os.FileMode{"bin/setup.sh": 0o755, ".claude/commands/hello.md": 0o644} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %o, want %o", file, got, want)
+		}
+	}
+}
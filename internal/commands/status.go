@@ -0,0 +1,342 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [name]",
+	Short: "Compare installed blanks with their mold source",
+	Long: `Compare installed blanks with their mold source.
+
+For every mold in .ailloy/installed.yaml (or one mold by name), re-renders
+the originating mold in memory — resolving the reference it was cast with
+and replaying its recorded --set/--values/--profile options — and checks each file
+recorded at cast time:
+
+  unchanged  on disk as cast, and the source still renders the same
+  modified   edited locally since cast
+  missing    recorded at cast time but no longer on disk
+  outdated   on disk as cast, but the source now renders something
+             different (run 'ailloy recast' to update)
+
+Files the source renders that were not part of the cast are reported as
+outdated too. Nothing on disk is written.
+
+Use --global/-g to inspect the manifest under ~/ instead of the current
+project, and --offline to render from the local cache only. --check exits
+non-zero when any file is modified, missing, or outdated, for hooks and CI.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatus,
+}
+
+var (
+	statusGlobal  bool
+	statusOffline bool
+	statusCheck   bool
+	statusOutput  string
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().BoolVarP(&statusGlobal, "global", "g", false, "inspect the global manifest under ~/")
+	statusCmd.Flags().BoolVar(&statusOffline, "offline", false, "render from the local cache without network access")
+	statusCmd.Flags().BoolVar(&statusCheck, "check", false, "exit non-zero when any installed file has drifted from its source")
+	addOutputFlag(statusCmd, &statusOutput)
+}
+
+// File states reported by status.
+const (
+	fileUnchanged = "unchanged"
+	fileModified  = "modified"
+	fileMissing   = "missing"
+	fileOutdated  = "outdated"
+)
+
+// statusFile is the state of one installed (or newly rendered) file.
+type statusFile struct {
+	Path  string `json:"path" yaml:"path"`
+	State string `json:"state" yaml:"state"`
+	Note  string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// statusMold is the status of one installed mold.
+type statusMold struct {
+	Name          string       `json:"name" yaml:"name"`
+	Source        string       `json:"source" yaml:"source"`
+	Version       string       `json:"version" yaml:"version"`                                 // installed version
+	SourceVersion string       `json:"sourceVersion,omitempty" yaml:"sourceVersion,omitempty"` // version the source rendered at
+	RenderError   string       `json:"renderError,omitempty" yaml:"renderError,omitempty"`     // set when outdated files could not be detected
+	Files         []statusFile `json:"files" yaml:"files"`
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(statusOutput); err != nil {
+		return err
+	}
+	manifestPath := manifestPathFor(statusGlobal)
+	manifest, err := foundry.ReadInstalledManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("reading installed manifest: %w", err)
+	}
+	if manifest == nil || len(manifest.Molds) == 0 {
+		return fmt.Errorf("no installed manifest at %s — run %s first",
+			styles.CodeStyle.Render(manifestPath),
+			styles.CodeStyle.Render("ailloy cast"))
+	}
+
+	entries := manifest.Molds
+	if len(args) == 1 {
+		match := manifest.FindByName(args[0])
+		if match == nil {
+			return fmt.Errorf("mold %q not found in installed manifest", args[0])
+		}
+		entries = []foundry.InstalledEntry{*match}
+	}
+
+	root := "."
+	if statusGlobal {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		root = home
+	}
+
+	molds := make([]statusMold, 0, len(entries))
+	drifted := 0
+	for i := range entries {
+		entry := &entries[i]
+		fresh, version, renderErr := renderInstalledMold(entry, statusGlobal, statusOffline)
+		m := statusMold{
+			Name:          entry.Name,
+			Source:        entry.Source,
+			Version:       entry.Version,
+			SourceVersion: version,
+			Files:         classifyInstalledFiles(root, entry, fresh),
+		}
+		if renderErr != nil {
+			m.RenderError = renderErr.Error()
+		}
+		for _, f := range m.Files {
+			if f.State != fileUnchanged {
+				drifted++
+			}
+		}
+		if statusOutput != "" {
+			molds = append(molds, m)
+			continue
+		}
+		printStatusHeader(m)
+		if m.RenderError != "" {
+			log.Printf("warning: %s: could not render source, outdated files not detected: %s", m.Name, m.RenderError)
+		}
+		printStatusFiles(m)
+		logging.Decor("")
+	}
+	if statusOutput != "" {
+		if err := writeStructured(cmd.OutOrStdout(), statusOutput, molds); err != nil {
+			return err
+		}
+	}
+	if statusCheck && drifted > 0 {
+		return validationError(fmt.Errorf("%d installed file(s) drifted from their mold source", drifted))
+	}
+	return nil
+}
+
+// renderInstalledMold re-renders entry's mold the way cast would and returns
+// the sha256 of each rendered file keyed by slash-separated destination path,
+// plus the version that was rendered. The reference the mold was cast with is
+// re-resolved, so a constraint picks up newer matching versions.
+func renderInstalledMold(entry *foundry.InstalledEntry, global, offline bool) (map[string]string, string, error) {
+	refStr := entry.Ref
+	if refStr == "" {
+		ref, err := referenceFromInstalledEntry(entry)
+		if err != nil {
+			return nil, "", err
+		}
+		refStr = buildVersionedRefString(ref, "")
+	}
+
+	silent := log.New(io.Discard, "", 0)
+	resolveOpts := coreResolveOpts(global, offline, silent)
+	fsys, result, err := foundry.ResolveWithMetadata(refStr, resolveOpts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving %s: %w", refStr, err)
+	}
+	reader, err := ComposeMoldReader(blanks.NewMoldReaderFromFS(fsys, result.Root), result.Ref.OverrideKey(), resolveOpts...)
+	if err != nil {
+		return nil, result.Resolved.Tag, err
+	}
+	manifest, err := reader.LoadManifest()
+	if err != nil {
+		return nil, result.Resolved.Tag, fmt.Errorf("loading mold manifest: %w", err)
+	}
+
+	var castOpts foundry.CastOptionsRecord
+	if entry.CastOptions != nil {
+		castOpts = *entry.CastOptions
+	}
+	flux, schema, err := layerFluxForCore(reader, result.Ref.OverrideKey(), castOpts.ValueFiles, recordedFluxSets(castOpts), global, resolveOpts)
+	if err != nil {
+		return nil, result.Resolved.Tag, err
+	}
+	installedStamp(entry).pin(flux)
+	if _, err := selectOutputProfile(flux, manifest, castOpts.Profile); err != nil {
+		return nil, result.Resolved.Tag, err
+	}
+	depResolver, err := ResolveDepsEphemeral(manifest, false)
+	if err != nil {
+		return nil, result.Resolved.Tag, fmt.Errorf("resolving ore deps: %w", err)
+	}
+
+	var fileOpts []mold.ResolveOption
+	if patterns := mold.LoadIgnorePatterns(reader.FS(), manifest); len(patterns) > 0 {
+		fileOpts = append(fileOpts, mold.WithIgnorePatterns(patterns))
+	}
+	resolved, err := mold.ResolveFilesWithOreSources(flux["output"], reader.FS(), depResolver.OreSources(), fileOpts...)
+	if err != nil {
+		return nil, result.Resolved.Tag, fmt.Errorf("resolving output files: %w", err)
+	}
+	if resolved, err = skipLargeBinaries(resolved, reader.FS(), manifest); err != nil {
+		return nil, result.Resolved.Tag, err
+	}
+	selection := mold.Selection{Only: castOpts.Only, Exclude: castOpts.Exclude}
+	if resolved, err = selection.Select(resolved, manifest.Components); err != nil {
+		return nil, result.Resolved.Tag, err
+	}
+
+	ci, err := castCISystem(castOpts.WithWorkflows, castOpts.CI, "")
+	if err != nil {
+		return nil, result.Resolved.Tag, err
+	}
+	kept := resolved[:0]
+	for _, rf := range resolved {
+		if !mold.SkipCIFile(rf.DestPath, ci) {
+			kept = append(kept, rf)
+		}
+	}
+	rendered, err := renderCastFiles(reader, manifest, schema, flux, kept, silent)
+	if err != nil {
+		return nil, result.Resolved.Tag, err
+	}
+	hashes := make(map[string]string, len(rendered))
+	for _, rf := range rendered {
+		hashes[filepath.ToSlash(rf.DestPath)] = hashBytes(rf.content)
+	}
+	return hashes, result.Resolved.Tag, nil
+}
+
+// classifyInstalledFiles compares the files recorded on entry with what is
+// on disk under root and with fresh, the hashes of a fresh render. A nil
+// fresh map (the source could not be rendered) skips outdated detection.
+// Results are sorted by path.
+func classifyInstalledFiles(root string, entry *foundry.InstalledEntry, fresh map[string]string) []statusFile {
+	out := []statusFile{}
+	edited := editedFiles(root, entry)
+	recorded := make(map[string]bool, len(entry.Files))
+	for _, rel := range entry.Files {
+		recorded[rel] = true
+		installedHash := entry.FileHashes[rel]
+
+		onDisk, err := hashFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			// No hash means the blank rendered empty at cast time and was
+			// never written; only a fresh render producing it matters.
+			if installedHash == "" {
+				if _, ok := fresh[rel]; ok {
+					out = append(out, statusFile{Path: rel, State: fileOutdated, Note: "now rendered by source"})
+				}
+				continue
+			}
+			out = append(out, statusFile{Path: rel, State: fileMissing})
+			continue
+		}
+		if installedHash == "" || onDisk != installedHash {
+			out = append(out, statusFile{Path: rel, State: fileModified})
+			continue
+		}
+		if slices.Contains(edited, rel) {
+			// Recast recorded the file as is after keeping or merging into
+			// local edits; its provenance header still tells them apart.
+			out = append(out, statusFile{Path: rel, State: fileModified, Note: "edited since cast"})
+			continue
+		}
+		if fresh == nil {
+			out = append(out, statusFile{Path: rel, State: fileUnchanged})
+			continue
+		}
+
+		renderedHash := installedHash
+		if h, ok := entry.RenderHashes[rel]; ok {
+			renderedHash = h
+		}
+		switch h, ok := fresh[rel]; {
+		case !ok:
+			out = append(out, statusFile{Path: rel, State: fileOutdated, Note: "no longer rendered by source"})
+		case h != renderedHash:
+			out = append(out, statusFile{Path: rel, State: fileOutdated})
+		default:
+			out = append(out, statusFile{Path: rel, State: fileUnchanged})
+		}
+	}
+	for rel := range fresh {
+		if !recorded[rel] {
+			out = append(out, statusFile{Path: rel, State: fileOutdated, Note: "new in source"})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+func printStatusHeader(m statusMold) {
+	line := styles.HeaderStyle.Render(m.Name) + " " + styles.CodeStyle.Render(m.Version)
+	if m.SourceVersion != "" && m.SourceVersion != m.Version {
+		line += " " + styles.InfoStyle.Render("(source at "+m.SourceVersion+")")
+	}
+	logging.Say(line, "installed", "mold", m.Name, "version", m.Version, "source_version", m.SourceVersion, "source", m.Source)
+	logging.Decor(styles.SubtleStyle.Render("  " + m.Source))
+}
+
+func printStatusFiles(m statusMold) {
+	unchanged := 0
+	for _, f := range m.Files {
+		var marker string
+		switch f.State {
+		case fileUnchanged:
+			unchanged++
+			continue
+		case fileModified:
+			marker = styles.WarningStyle.Render("  M modified  ")
+		case fileMissing:
+			marker = styles.ErrorStyle.Render("  ! missing   ")
+		case fileOutdated:
+			marker = styles.InfoStyle.Render("  ↑ outdated  ")
+		}
+		line := marker + styles.CodeStyle.Render(f.Path)
+		if f.Note != "" {
+			line += styles.SubtleStyle.Render(" (" + f.Note + ")")
+		}
+		logging.Say(line, "drift", "mold", m.Name, "file", f.Path, "state", f.State, "note", f.Note)
+	}
+	summary := fmt.Sprintf("  %d unchanged", unchanged)
+	if unchanged == len(m.Files) {
+		summary += ", everything up to date"
+	}
+	logging.Say(styles.SuccessStyle.Render(summary), "status", "mold", m.Name, "unchanged", unchanged, "files", len(m.Files))
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestClassifyInstalledFiles(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "same.md"), "same\n")
+	mustWrite(t, filepath.Join(root, "edited.md"), "edited locally\n")
+	mustWrite(t, filepath.Join(root, "stale.md"), "old render\n")
+	mustWrite(t, filepath.Join(root, "dropped.md"), "dropped\n")
+	mustWrite(t, filepath.Join(root, "settings.json"), `{"merged": true}`)
+	// Recast merged a new render into local edits and recorded the result.
+	merged := string(mold.StampProvenance("merged.md", []byte("render\nlocal edit\n"),
+		mold.Provenance{Mold: "tools", SHA256: hashBytes([]byte("render\n"))}))
+	mustWrite(t, filepath.Join(root, "merged.md"), merged)
+
+	entry := &foundry.InstalledEntry{
+		Name:  "tools",
+		Files: []string{"dropped.md", "edited.md", "gone.md", "merged.md", "same.md", "settings.json", "stale.md"},
+		FileHashes: map[string]string{
+			"same.md":       hashBytes([]byte("same\n")),
+			"edited.md":     hashBytes([]byte("as cast\n")),
+			"gone.md":       hashBytes([]byte("gone\n")),
+			"stale.md":      hashBytes([]byte("old render\n")),
+			"dropped.md":    hashBytes([]byte("dropped\n")),
+			"settings.json": hashBytes([]byte(`{"merged": true}`)),
+			"merged.md":     hashBytes([]byte(merged)),
+		},
+		RenderHashes: map[string]string{"settings.json": hashBytes([]byte(`{"a": 1}`))},
+	}
+	fresh := map[string]string{
+		"same.md":       hashBytes([]byte("same\n")),
+		"edited.md":     hashBytes([]byte("as cast\n")),
+		"gone.md":       hashBytes([]byte("gone\n")),
+		"stale.md":      hashBytes([]byte("new render\n")),
+		"settings.json": hashBytes([]byte(`{"a": 1}`)),
+		"added.md":      hashBytes([]byte("added\n")),
+		"merged.md":     hashBytes([]byte("render\n")),
+	}
+
+	got := map[string]string{}
+	for _, f := range classifyInstalledFiles(root, entry, fresh) {
+		got[f.Path] = f.State
+	}
+	want := map[string]string{
+		"added.md":      fileOutdated,
+		"dropped.md":    fileOutdated,
+		"edited.md":     fileModified,
+		"gone.md":       fileMissing,
+		"merged.md":     fileModified,
+		"same.md":       fileUnchanged,
+		"settings.json": fileUnchanged,
+		"stale.md":      fileOutdated,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("states = %v, want %v", got, want)
+	}
+
+	// Without a fresh render only local drift is reported.
+	for _, f := range classifyInstalledFiles(root, entry, nil) {
+		if f.State == fileOutdated {
+			t.Errorf("%s reported outdated without a fresh render", f.Path)
+		}
+	}
+}
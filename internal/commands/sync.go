@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Cast every mold declared in ailloy.yaml",
+	Long: `Cast every mold declared in the project's ailloy.yaml (also: cast --all).
+
+ailloy.yaml lists the molds a project is built from, each with its own
+values files and overrides:
+
+  apiVersion: v1
+  molds:
+    - ref: github.com/acme/base-mold@^1.0
+    - ref: github.com/acme/platform-mold
+      values: [.ailloy/platform-values.yaml]
+      set: [team=platform]
+      withWorkflows: true
+      profile: cursor
+      to: [cursor, codex]
+
+Molds are cast in order, so a later mold can override files from an earlier
+one. Each cast installs the mold or updates it to the newest version its ref
+allows, exactly as 'ailloy cast <ref>' would. Relative values paths and
+local mold paths are resolved against the directory holding ailloy.yaml.
+A mold's to: list converts its blanks for other tools as well, as
+'ailloy cast --to' would, after the regular cast.
+
+--set and --values given here apply to every mold, after its own;
+--profile replaces every mold's profile.`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+var (
+	syncFile          string
+	syncDryRun        bool
+	syncFrozen        bool
+	syncWithWorkflows bool
+	syncSetFlags      []string
+	syncValFiles      []string
+	syncProfile       string
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVar(&syncFile, "file", foundry.ProjectFileName, "project file declaring the molds to cast")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "list what would be cast without casting")
+	syncCmd.Flags().BoolVar(&syncFrozen, "frozen", false, "fail (do not auto-install) when a declared ingot/ore dep is missing from .ailloy/; intended for CI")
+	syncCmd.Flags().BoolVar(&syncWithWorkflows, "with-workflows", false, "include workflow blanks for the repository's CI system for every mold")
+	syncCmd.Flags().StringArrayVar(&syncSetFlags, "set", nil, "override flux variable for every mold (format: key=value, can be repeated)")
+	syncCmd.Flags().StringVar(&syncProfile, "profile", "", "output profile to cast every mold with (e.g. cursor)")
+	syncCmd.Flags().StringArrayVarP(&syncValFiles, "values", "f", nil, "flux value files applied to every mold after its own (can be repeated)")
+}
+
+// syncOptions carries the flags shared by `sync` and `cast --all`.
+type syncOptions struct {
+	DryRun        bool
+	Frozen        bool
+	WithWorkflows bool
+	ValueFiles    []string
+	SetOverrides  []string
+	// SetFiles and SetJSON are cast's --set-file and --set-json flags;
+	// project files and sync itself only take --set.
+	SetFiles []string
+	SetJSON  []string
+	Profile  string
+	// HistoryOp and HistoryFlags are recorded in the history log for each
+	// mold cast (HistoryOp defaults to "sync").
+	HistoryOp    string
+	HistoryFlags []string
+}
+
+func runSync(cmd *cobra.Command, _ []string) error {
+	return syncProjectMolds(cmd.Context(), syncFile, syncOptions{
+		DryRun:        syncDryRun,
+		Frozen:        syncFrozen,
+		WithWorkflows: syncWithWorkflows,
+		ValueFiles:    syncValFiles,
+		SetOverrides:  syncSetFlags,
+		Profile:       syncProfile,
+		HistoryFlags:  historyFlags(cmd),
+	})
+}
+
+// syncProjectMolds casts every mold declared in the project file at path.
+// Failures don't stop the run; they are reported and counted.
+func syncProjectMolds(ctx context.Context, path string, opts syncOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pf, err := foundry.ReadProjectFile(path)
+	if err != nil {
+		return configError(err)
+	}
+	if pf == nil {
+		return configError(fmt.Errorf("no %s found — declare the project's molds under %s first",
+			styles.CodeStyle.Render(path), styles.CodeStyle.Render("molds:")))
+	}
+	if len(pf.Molds) == 0 {
+		logging.Say(styles.InfoStyle.Render("No molds declared in ")+styles.CodeStyle.Render(path), "no molds declared", "file", path)
+		return nil
+	}
+
+	banner := fmt.Sprintf("Casting %d mold(s) from %s...", len(pf.Molds), path)
+	if opts.DryRun {
+		banner = fmt.Sprintf("Previewing %d mold(s) from %s (dry run)...", len(pf.Molds), path)
+	}
+	logging.Decor(styles.WorkingBanner(banner), "")
+
+	historyOp := opts.HistoryOp
+	if historyOp == "" {
+		historyOp = "sync"
+	}
+	base := filepath.Dir(path)
+	cast, failed := 0, 0
+	for _, m := range pf.Molds {
+		ref := m.Ref
+		if !foundry.IsRemoteReference(ref) {
+			ref = resolveProjectPath(base, ref)
+		}
+		valueFiles := make([]string, 0, len(m.Values)+len(opts.ValueFiles))
+		for _, v := range m.Values {
+			valueFiles = append(valueFiles, resolveProjectPath(base, v))
+		}
+		valueFiles = append(valueFiles, opts.ValueFiles...)
+		setOverrides := append(append([]string(nil), m.Set...), opts.SetOverrides...)
+		profile := m.Profile
+		if opts.Profile != "" {
+			profile = opts.Profile
+		}
+
+		if opts.DryRun {
+			logging.Say("  "+styles.AccentStyle.Render(m.Ref)+" "+styles.InfoStyle.Render("would cast"),
+				"would cast", "ref", m.Ref, "values", valueFiles, "set", setOverrides, "profile", profile, "to", m.To)
+			if len(valueFiles) > 0 {
+				logging.Decor(styles.SubtleStyle.Render("    values: " + strings.Join(valueFiles, ", ")))
+			}
+			if len(setOverrides) > 0 {
+				logging.Decor(styles.SubtleStyle.Render("    set:    " + strings.Join(setOverrides, ", ")))
+			}
+			if profile != "" {
+				logging.Decor(styles.SubtleStyle.Render("    profile: " + profile))
+			}
+			if len(m.To) > 0 {
+				logging.Decor(styles.SubtleStyle.Render("    to:     " + strings.Join(m.To, ", ")))
+			}
+			continue
+		}
+
+		res, err := CastMold(ctx, ref, CastOptions{
+			WithWorkflows: m.WithWorkflows || opts.WithWorkflows,
+			ValueFiles:    valueFiles,
+			SetOverrides:  setOverrides,
+			SetFiles:      opts.SetFiles,
+			SetJSON:       opts.SetJSON,
+			Frozen:        opts.Frozen,
+			Profile:       profile,
+			HistoryOp:     historyOp,
+			HistoryFlags:  opts.HistoryFlags,
+		})
+		if err != nil {
+			failed++
+			log.Printf("error: %s: %v", m.Ref, err)
+			continue
+		}
+		cast++
+		logging.Say("  "+styles.AccentStyle.Render(m.Ref)+" "+styles.SuccessStyle.Render("ok")+styles.SubtleStyle.Render(" ("+res.MoldName+")"),
+			"cast", "ref", m.Ref, "mold", res.MoldName)
+		sets := fluxSets{Set: setOverrides, Files: opts.SetFiles, JSON: opts.SetJSON}
+		if err := adaptProjectMold(ref, m.To, valueFiles, sets); err != nil {
+			failed++
+			log.Printf("error: %s: to: %v", m.Ref, err)
+		}
+	}
+
+	logging.Decor("")
+	if opts.DryRun {
+		logging.Say(styles.SuccessStyle.Render(fmt.Sprintf("would cast %d", len(pf.Molds))), "sync dry run complete", "molds", len(pf.Molds))
+		return nil
+	}
+	logging.Say(styles.SuccessStyle.Render(fmt.Sprintf("cast %d · failed %d", cast, failed)), "sync complete", "cast", cast, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("%d mold(s) failed to cast", failed)
+	}
+	return nil
+}
+
+// adaptProjectMold converts the mold at ref for each output adapter named in
+// to, after it was cast.
+func adaptProjectMold(ref string, to, valueFiles []string, sets fluxSets) error {
+	if len(to) == 0 {
+		return nil
+	}
+	adapters := make([]blanks.OutputAdapter, 0, len(to))
+	for _, name := range to {
+		adapter, ok := blanks.LookupOutputAdapter(name)
+		if !ok {
+			return fmt.Errorf("unknown tool %q (want one of %s)", name, strings.Join(blanks.OutputAdapterNames(), ", "))
+		}
+		adapters = append(adapters, adapter)
+	}
+	reader, source, err := resolveMoldReader([]string{ref})
+	if err != nil {
+		return err
+	}
+	for _, adapter := range adapters {
+		if err := adaptMold(reader, source, adapter, valueFiles, sets, false, nil); err != nil {
+			return fmt.Errorf("%s: %w", adapter.Name(), err)
+		}
+	}
+	return nil
+}
+
+// resolveProjectPath anchors a relative path from ailloy.yaml at base.
+func resolveProjectPath(base, p string) string {
+	if filepath.IsAbs(p) || base == "." {
+		return p
+	}
+	return filepath.Join(base, p)
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncProjectMolds_CastsEveryDeclaredMold(t *testing.T) {
+	project := t.TempDir()
+	chdir(t, project)
+	t.Setenv("HOME", t.TempDir())
+
+	for _, name := range []string{"base", "team"} {
+		dir := filepath.Join(project, "molds", name)
+		mustWrite(t, filepath.Join(dir, "mold.yaml"), "apiVersion: v1\nkind: mold\nname: "+name+"\nversion: 1.0.0\n")
+		mustWrite(t, filepath.Join(dir, "flux.yaml"), "output:\n  commands: .claude/commands\nteam: default\n")
+		mustWrite(t, filepath.Join(dir, "commands", name+".md"), "# {{team}}\n")
+	}
+	mustWrite(t, filepath.Join(project, "team-values.yaml"), "team: platform\n")
+	mustWrite(t, filepath.Join(project, "ailloy.yaml"), `apiVersion: v1
+molds:
+  - ref: molds/base
+  - ref: molds/team
+    values: [team-values.yaml]
+  - ref: molds/missing
+`)
+
+	err := syncProjectMolds(context.Background(), "ailloy.yaml", syncOptions{})
+	if err == nil || !strings.Contains(err.Error(), "1 mold(s) failed") {
+		t.Fatalf("expected the missing mold to fail alone, got %v", err)
+	}
+	for file, want := range map[string]string{
+		".claude/commands/base.md": "# default\n",
+		".claude/commands/team.md": "# platform\n",
+	} {
+		if got := castedBody(t, file); got != want {
+			t.Errorf("%s = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestSyncProjectMolds_MissingFile(t *testing.T) {
+	chdir(t, t.TempDir())
+	if err := syncProjectMolds(context.Background(), "ailloy.yaml", syncOptions{}); err == nil || !strings.Contains(err.Error(), "no ") {
+		t.Fatalf("expected missing-file error, got %v", err)
+	}
+}
+
+func TestCastAll_RejectsSingleMoldFlags(t *testing.T) {
+	resetCastFlags()
+	t.Cleanup(resetCastFlags)
+	castAll = true
+	if err := runCast(castCmd, []string{"github.com/acme/mold"}); err == nil {
+		t.Error("expected --all with a ref to fail")
+	}
+	castGlobal = true
+	if err := runCast(castCmd, nil); err == nil || !strings.Contains(err.Error(), "--global") {
+		t.Errorf("expected --all --global to fail, got %v", err)
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/nimble-giant/ailloy/internal/tui/ceremony"
 	"github.com/nimble-giant/ailloy/pkg/assay"
 	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +24,9 @@ var temperCmd = &cobra.Command{
 	Short:   "Validate a mold or ingot package",
 	Long: `Validate a mold or ingot package (alias: validate).
 
+path is a package directory (default: the current one) or a .tar.gz
+archive made by smelt.
+
 Checks structural integrity, manifest fields, file references,
 template syntax, and flux schema consistency. Reports errors
 (blocking) and warnings (informational).
@@ -40,6 +45,9 @@ var (
 	temperFormat    string
 	temperFailOn    string
 	temperMaxLines  int
+	// temperIgnoreRequires reports an unmet requires.ailloy as a warning
+	// rather than an error.
+	temperIgnoreRequires bool
 )
 
 func init() {
@@ -49,10 +57,12 @@ func init() {
 	temperCmd.Flags().BoolVar(&temperLint, "lint", false, "alias for --assay")
 	_ = temperCmd.Flags().MarkHidden("lint")
 	temperCmd.Flags().StringArrayVar(&temperSetValues, "set", nil, "set flux values for rendering (key=value)")
+	_ = temperCmd.RegisterFlagCompletionFunc("set", completeSetFlag(0))
 	temperCmd.Flags().StringArrayVarP(&temperValFiles, "values", "f", nil, "flux value files for rendering (can be repeated)")
 	temperCmd.Flags().StringVar(&temperFormat, "format", "console", "assay output format: console, json, markdown")
 	temperCmd.Flags().StringVar(&temperFailOn, "fail-on", "error", "assay exit threshold: error, warning, suggestion")
 	temperCmd.Flags().IntVar(&temperMaxLines, "max-lines", 0, "override assay line-count threshold (default: 150)")
+	temperCmd.Flags().BoolVar(&temperIgnoreRequires, "ignore-requires", false, "report an unmet requires.ailloy constraint as a warning instead of an error")
 }
 
 func runTemper(_ *cobra.Command, args []string) error {
@@ -63,15 +73,17 @@ func runTemper(_ *cobra.Command, args []string) error {
 		moldDir = args[0]
 	}
 
-	fsys := os.DirFS(moldDir)
-	result := mold.Temper(fsys)
-
-	// For molds, layer in ephemeral ore-resolution diagnostics so the
-	// merged-schema view is validated end-to-end. Local-path deps are
-	// allowed because temper only operates on local trees.
-	if result.ManifestKind == "mold" {
-		appendOreDiagnostics(fsys, result)
-		appendMoldAssayDiagnostics(moldDir, result)
+	var result *mold.TemperResult
+	if blanks.IsTarball(moldDir) {
+		// An archive has no directory for local-path deps to be relative to.
+		reader, err := blanks.NewMoldReaderFromTarball(moldDir)
+		if err != nil {
+			return err
+		}
+		result = temperPackage(reader.FS(), "", false)
+	} else {
+		// Local-path deps are allowed because temper only operates on local trees.
+		result = temperPackage(os.DirFS(moldDir), moldDir, true)
 	}
 
 	if result.Name != "" {
@@ -108,7 +120,7 @@ func runTemper(_ *cobra.Command, args []string) error {
 		fmt.Println(styles.ErrorStyle.Render(fmt.Sprintf("Validation failed: %d error(s), %d warning(s)",
 			len(errors), len(warnings))))
 		ceremony.FailStamp(ceremony.Temper, fmt.Sprintf("%d error(s), %d warning(s)", len(errors), len(warnings)))
-		return fmt.Errorf("temper: %d error(s) found", len(errors))
+		return validationError(fmt.Errorf("temper: %d error(s) found", len(errors)))
 	}
 
 	msg := fmt.Sprintf("Validation passed: 0 errors, %d warning(s)", len(warnings))
@@ -132,15 +144,81 @@ func runTemper(_ *cobra.Command, args []string) error {
 	return nil
 }
 
+// appendRequiresDiagnostic reports a requires.ailloy constraint the running
+// ailloy doesn't satisfy: an error, or a warning with --ignore-requires.
+func appendRequiresDiagnostic(fsys fs.FS, result *mold.TemperResult) {
+	file := result.ManifestKind + ".yaml"
+	var requires string
+	switch result.ManifestKind {
+	case "mold":
+		if m, err := mold.LoadMoldFromFS(fsys, file); err == nil {
+			requires = m.Requires.Ailloy
+		}
+	case "ingot":
+		if i, err := mold.LoadIngotFromFS(fsys, file); err == nil {
+			requires = i.Requires.Ailloy
+		}
+	case "ore":
+		if o, err := mold.LoadOreFromFS(fsys, file); err == nil {
+			requires = o.Requires.Ailloy
+		}
+	}
+	err := enforceAilloyVersionFor("this "+result.ManifestKind, requires)
+	if err == nil {
+		return
+	}
+	severity := mold.SeverityError
+	if temperIgnoreRequires {
+		severity = mold.SeverityWarning
+	}
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	result.Diagnostics = append(result.Diagnostics, mold.Diagnostic{
+		Severity: severity,
+		Message:  msg,
+		File:     file,
+	})
+}
+
+// temperPackage validates the package in fsys. A mold that extends another
+// is validated as composed (see ComposeMoldReader). For molds it layers in
+// ephemeral ore-resolution diagnostics, so the merged-schema view is
+// validated end-to-end, and — when the package is on disk at dir — the
+// mold-tree assay rules.
+func temperPackage(fsys fs.FS, dir string, allowLocalDeps bool) *mold.TemperResult {
+	composed, err := ComposeMoldReader(blanks.NewMoldReaderFromFS(fsys, dir), "")
+	if err != nil {
+		result := mold.Temper(fsys)
+		result.Diagnostics = append(result.Diagnostics, mold.Diagnostic{
+			Severity: mold.SeverityError,
+			Message:  err.Error(),
+			File:     "mold.yaml",
+		})
+		return result
+	}
+	fsys = composed.FS()
+	result := mold.Temper(fsys)
+	appendRequiresDiagnostic(fsys, result)
+	if result.ManifestKind == "mold" {
+		appendOreDiagnostics(fsys, result, allowLocalDeps)
+		if dir != "" {
+			appendMoldAssayDiagnostics(dir, result)
+		}
+	}
+	return result
+}
+
 // runTemperLint renders the mold blanks into a temp directory and runs assay on them.
 func runTemperLint(moldDir string) error {
 	fmt.Println(styles.WorkingBanner("Linting rendered blanks..."))
 	fmt.Println()
 
-	reader, err := blanks.NewMoldReaderFromPath(moldDir)
+	reader, err := openLocalMold(moldDir)
 	if err != nil {
 		return fmt.Errorf("reading mold: %w", err)
 	}
+	if reader, err = ComposeMoldReader(reader, ""); err != nil {
+		return fmt.Errorf("reading mold: %w", err)
+	}
 
 	// Load flux with layering (same precedence as forge/cast)
 	flux, err := loadTemperFlux(reader)
@@ -158,22 +236,29 @@ func runTemperLint(moldDir string) error {
 	if schema == nil && len(manifest.Flux) > 0 {
 		schema = manifest.Flux
 	}
-	if err := mold.ValidateFlux(schema, flux); err != nil {
+	for _, w := range append(mold.MigrateFlux(schema, flux), mold.ResolveFlux(schema, flux, time.Now())...) {
+		log.Printf("warning: %s", w)
+	}
+	if err := mold.ValidateFluxWithSources(schema, flux, valuesFluxSources(temperValFiles, temperSetValues)); err != nil {
 		log.Printf("warning: %v", err)
 	}
 
 	// Build ingot resolver and render files
 	resolver := buildIngotResolver(flux, reader.Root())
 	resolver.FS = reader.FS()
+	applyIngotConstraints(resolver, manifest)
 	opts := []mold.TemplateOption{mold.WithIngotResolver(resolver)}
 
 	resolved, err := mold.ResolveFiles(flux["output"], reader.FS())
 	if err != nil {
 		return fmt.Errorf("resolving output files: %w", err)
 	}
+	if err := attachRemoteIngots(resolver, reader.FS(), resolved); err != nil {
+		return err
+	}
 
 	// Render to temp directory
-	tmpDir, err := os.MkdirTemp("", "ailloy-temper-lint-*")
+	tmpDir, err := tmpdir.MkdirTemp("temper-lint-*")
 	if err != nil {
 		return fmt.Errorf("creating temp directory: %w", err)
 	}
@@ -282,7 +367,7 @@ func runAssayOnDir(dir string) error {
 	if result.HasFailures(failOnSeverity) {
 		fmt.Println(styles.ErrorStyle.Render(fmt.Sprintf("%d error(s), %d warning(s), %d suggestion(s)",
 			errs, warns, suggestions)))
-		return fmt.Errorf("temper --assay: assay findings exceed --%s threshold", temperFailOn)
+		return validationError(fmt.Errorf("temper --assay: assay findings exceed --%s threshold", temperFailOn))
 	}
 
 	fmt.Println(styles.SuccessStyle.Render(fmt.Sprintf("%d error(s), %d warning(s), %d suggestion(s)",
@@ -296,14 +381,15 @@ func runAssayOnDir(dir string) error {
 // entries (SeverityWarning, informational), and orphan defaults
 // (SeverityWarning). Resolution is ephemeral — never writes to .ailloy/ores/.
 //
-// Local-path deps are allowed: temper always runs against a local tree.
-func appendOreDiagnostics(fsys fs.FS, result *mold.TemperResult) {
+// allowLocalDeps follows installDeclaredDeps' rule: only molds that are
+// themselves local may declare local-path deps.
+func appendOreDiagnostics(fsys fs.FS, result *mold.TemperResult, allowLocalDeps bool) {
 	manifest, err := mold.LoadMoldFromFS(fsys, "mold.yaml")
 	if err != nil || manifest == nil || len(manifest.Dependencies) == 0 {
 		return
 	}
 
-	resolver, rerr := ResolveDepsEphemeral(manifest, true)
+	resolver, rerr := ResolveDepsEphemeral(manifest, allowLocalDeps)
 	if rerr != nil {
 		result.Diagnostics = append(result.Diagnostics, mold.Diagnostic{
 			Severity: mold.SeverityError,
@@ -388,7 +474,7 @@ func appendMoldAssayDiagnostics(moldDir string, result *mold.TemperResult) {
 		if !cfg.IsRuleEnabled(rule.Name()) {
 			continue
 		}
-		result.Diagnostics = append(result.Diagnostics, rule.Check(ctx)...)
+		result.Diagnostics = append(result.Diagnostics, cfg.ApplyRuleSeverity(rule.Name(), rule.Check(ctx))...)
 	}
 }
 
@@ -3,8 +3,10 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 	"github.com/spf13/cobra"
@@ -43,7 +45,7 @@ func init() {
 	uninstallCmd.Flags().BoolVar(&uninstallDryRun, "dry-run", false, "print what would be removed without touching disk")
 }
 
-func runUninstall(_ *cobra.Command, args []string) error {
+func runUninstall(cmd *cobra.Command, args []string) error {
 	manifestPath := manifestPathFor(uninstallGlobal)
 	if manifestPath == "" {
 		return fmt.Errorf("cannot determine installed manifest path")
@@ -54,6 +56,13 @@ func runUninstall(_ *cobra.Command, args []string) error {
 		return err
 	}
 
+	record := historyEntry{Op: "uninstall", Source: source, Flags: historyFlags(cmd), Global: uninstallGlobal}
+	if m, merr := foundry.ReadInstalledManifest(manifestPath); merr == nil && m != nil {
+		if entry := m.FindBySource(source, subpath); entry != nil {
+			record.Mold, record.Version, record.Commit = entry.Name, entry.Version, entry.Commit
+		}
+	}
+
 	res, err := foundry.UninstallMold(manifestPath, source, subpath, foundry.UninstallOptions{
 		Force:  uninstallForce,
 		DryRun: uninstallDryRun,
@@ -71,16 +80,22 @@ func runUninstall(_ *cobra.Command, args []string) error {
 			moldKey += "@" + subpath
 		}
 		if cerr := cascadeUninstallArtifacts(manifestPath, moldKey, uninstallGlobal); cerr != nil {
-			fmt.Println(styles.WarningStyle.Render("⚠️  ") + "cascade cleanup: " + cerr.Error())
+			log.Printf("warning: cascade cleanup: %v", cerr)
 		}
 		if cerr := cascadeUninstallTransitiveMolds(manifestPath, moldKey, uninstallGlobal, uninstallDryRun); cerr != nil {
-			fmt.Println(styles.WarningStyle.Render("⚠️  ") + "transitive-mold cascade: " + cerr.Error())
+			log.Printf("warning: transitive-mold cascade: %v", cerr)
 		}
+		if !uninstallGlobal {
+			if serr := forgetInstalledBlanks(res.Deleted); serr != nil {
+				log.Printf("warning: install state: %v", serr)
+			}
+		}
+		record.Files = res.Deleted
+		recordHistory(record, nil)
 	}
 	if err != nil {
 		if errors.Is(err, foundry.ErrLegacyEntry) {
-			fmt.Println(styles.WarningStyle.Render("⚠️  ") + err.Error())
-			fmt.Println(styles.SubtleStyle.Render("    Run `ailloy cast " + display + "` to backfill the manifest, then retry."))
+			log.Printf("warning: %v; run `ailloy cast %s` to backfill the manifest, then retry", err, display)
 			return nil
 		}
 		return err
@@ -90,33 +105,37 @@ func runUninstall(_ *cobra.Command, args []string) error {
 	if uninstallDryRun {
 		header = "Would uninstall (dry-run)"
 	}
-	fmt.Println(styles.SuccessStyle.Render(header+" ") + styles.AccentStyle.Render(display))
+	logging.Say(styles.SuccessStyle.Render(header+" ")+styles.AccentStyle.Render(display),
+		strings.ToLower(header), "mold", display, "removed", res.Deleted, "skipped_modified", res.SkippedModified,
+		"retained", res.Retained, "absent", len(res.NotFound))
 
 	if len(res.Deleted) > 0 {
-		fmt.Println(styles.SubtleStyle.Render(fmt.Sprintf("  Removed:  %d file(s)", len(res.Deleted))))
-		for _, f := range res.Deleted {
-			fmt.Println(styles.SubtleStyle.Render("    - " + f))
-		}
+		logging.Decor(styles.SubtleStyle.Render(fmt.Sprintf("  Removed:  %d file(s)", len(res.Deleted))))
+		decorFileList(res.Deleted)
 	}
 	if len(res.SkippedModified) > 0 {
-		fmt.Println(styles.WarningStyle.Render(fmt.Sprintf("  Skipped (modified): %d file(s)", len(res.SkippedModified))))
-		for _, f := range res.SkippedModified {
-			fmt.Println(styles.SubtleStyle.Render("    - " + f))
-		}
-		fmt.Println(styles.SubtleStyle.Render("  Re-run with --force to override."))
+		logging.Decor(styles.WarningStyle.Render(fmt.Sprintf("  Skipped (modified): %d file(s)", len(res.SkippedModified))))
+		decorFileList(res.SkippedModified)
+		logging.Decor(styles.SubtleStyle.Render("  Re-run with --force to override."))
 	}
 	if len(res.Retained) > 0 {
-		fmt.Println(styles.InfoStyle.Render(fmt.Sprintf("  Retained (claimed by another mold): %d file(s)", len(res.Retained))))
-		for _, f := range res.Retained {
-			fmt.Println(styles.SubtleStyle.Render("    - " + f))
-		}
+		logging.Decor(styles.InfoStyle.Render(fmt.Sprintf("  Retained (claimed by another mold): %d file(s)", len(res.Retained))))
+		decorFileList(res.Retained)
 	}
 	if len(res.NotFound) > 0 {
-		fmt.Println(styles.SubtleStyle.Render(fmt.Sprintf("  Already absent: %d file(s)", len(res.NotFound))))
+		logging.Decor(styles.SubtleStyle.Render(fmt.Sprintf("  Already absent: %d file(s)", len(res.NotFound))))
 	}
 	return nil
 }
 
+// decorFileList prints files as the indented bullet list under a result
+// count; the result's log record carries them for non-decorative output.
+func decorFileList(files []string) {
+	for _, f := range files {
+		logging.Decor(styles.SubtleStyle.Render("    - " + f))
+	}
+}
+
 // resolveUninstallTarget interprets the user's positional argument as a mold
 // reference. If the argument carries a //subpath, that wins. Otherwise the
 // installed manifest is consulted: a single matching entry is auto-resolved,
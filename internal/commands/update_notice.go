@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// updateCheckInterval is how often the update notice asks GitHub for the
+// latest release. In between, the answer cached in updateCheckFile is used.
+const updateCheckInterval = 24 * time.Hour
+
+// updateCheckState is the cached result of the last update check.
+type updateCheckState struct {
+	CheckedAt time.Time `yaml:"checkedAt"`
+	Channel   string    `yaml:"channel"`
+	Latest    string    `yaml:"latest"`
+}
+
+// updateCheck is an update check running alongside a command.
+type updateCheck struct {
+	current string
+	cached  string      // latest release known before the command started
+	fresh   chan string // the network answer, when a refresh was started
+}
+
+// pendingUpdateCheck is started by the root PersistentPreRunE and reported
+// once the command returns.
+var pendingUpdateCheck *updateCheck
+
+// updateCheckFile returns ~/.ailloy/update-check.yaml.
+func updateCheckFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ailloy", "update-check.yaml"), nil
+}
+
+// startUpdateCheck begins the opt-in update check (evolve.notify in
+// config.yaml) for cmd. It never blocks: a cached answer younger than
+// updateCheckInterval is used as is, and a stale one is refreshed in the
+// background. Nothing runs for evolve itself, development builds, CI, or
+// when stderr is not a terminal or output isn't decorative.
+func startUpdateCheck(cmd *cobra.Command) *updateCheck {
+	current := strings.TrimSpace(evolveCurrentVersion)
+	if cmd == evolveCmd || current == "" || current == "dev" || os.Getenv("CI") != "" ||
+		!logging.Decorative() || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	cfg, err := index.LoadConfig()
+	if err != nil || !cfg.Evolve.Notify {
+		return nil
+	}
+	return checkForUpdate(current, time.Now())
+}
+
+// checkForUpdate returns the update check for the running version,
+// starting a background refresh when the cached answer is missing, stale,
+// or for another channel. The check time is saved before the refresh
+// starts, so a command that exits first still throttles the next check.
+func checkForUpdate(current string, now time.Time) *updateCheck {
+	channel, err := resolveEvolveChannel()
+	if err != nil {
+		return nil
+	}
+	path, err := updateCheckFile()
+	if err != nil {
+		return nil
+	}
+
+	var state updateCheckState
+	if data, err := os.ReadFile(path); err == nil { // #nosec G304 -- our own state file
+		_ = yaml.Unmarshal(data, &state)
+	}
+	u := &updateCheck{current: current}
+	if state.Channel == channel {
+		u.cached = state.Latest
+		if now.Sub(state.CheckedAt) < updateCheckInterval {
+			return u
+		}
+	}
+
+	// Record the check before it runs: the command may exit before the
+	// refresh answers, and the next one mustn't ask GitHub again.
+	saveUpdateCheck(path, updateCheckState{CheckedAt: now, Channel: channel, Latest: u.cached})
+	u.fresh = make(chan string, 1)
+	go func() {
+		tag, err := fetchChannelTag(channel)
+		if err != nil {
+			close(u.fresh)
+			return
+		}
+		saveUpdateCheck(path, updateCheckState{CheckedAt: now, Channel: channel, Latest: tag})
+		u.fresh <- tag
+	}()
+	return u
+}
+
+// saveUpdateCheck writes state to path, ignoring errors: a missing cache
+// only means the next command checks again.
+func saveUpdateCheck(path string, state updateCheckState) {
+	data, err := yaml.Marshal(state)
+	if err == nil && os.MkdirAll(filepath.Dir(path), 0o750) == nil {
+		_ = os.WriteFile(path, data, 0o600)
+	}
+}
+
+// latest returns the newest known release without waiting: the background
+// refresh's answer if it has arrived, else the cached one.
+func (u *updateCheck) latest() string {
+	select {
+	case tag, ok := <-u.fresh:
+		if ok {
+			return tag
+		}
+	default:
+	}
+	return u.cached
+}
+
+// notice returns the one-line update notice, or "" when the running version
+// is current.
+func (u *updateCheck) notice() string {
+	latest := u.latest()
+	if latest == "" {
+		return ""
+	}
+	if cmp, err := compareSemver(u.current, latest); err != nil || cmp >= 0 {
+		return ""
+	}
+	current := u.current
+	if !strings.HasPrefix(current, "v") {
+		current = "v" + current
+	}
+	return styles.InfoStyle.Render("A new ailloy release is available: ") +
+		current + " → " + latest + styles.SubtleStyle.Render(" · run `ailloy evolve` to upgrade")
+}
+
+// printUpdateNotice reports the pending update check, if any, on stderr.
+func printUpdateNotice() {
+	if pendingUpdateCheck == nil {
+		return
+	}
+	if msg := pendingUpdateCheck.notice(); msg != "" {
+		_, _ = fmt.Fprintln(logging.Stderr(), msg)
+	}
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckForUpdate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"tag_name": "v0.8.0"}`))
+	}))
+	defer srv.Close()
+	orig := evolveReleaseAPIBase
+	evolveReleaseAPIBase = srv.URL
+	t.Cleanup(func() { evolveReleaseAPIBase = orig })
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	u := checkForUpdate("0.7.3", now)
+	if u == nil || u.fresh == nil {
+		t.Fatalf("first check = %+v, want a background refresh", u)
+	}
+	if tag := <-u.fresh; tag != "v0.8.0" {
+		t.Errorf("refreshed tag = %q, want v0.8.0", tag)
+	}
+	path, _ := updateCheckFile()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("state file not written: %v", err)
+	}
+
+	// Within the interval the cached answer is used without a request.
+	u = checkForUpdate("0.7.3", now.Add(time.Hour))
+	if u.fresh != nil || calls.Load() != 1 {
+		t.Errorf("fresh cache refreshed anyway (calls = %d)", calls.Load())
+	}
+	if msg := u.notice(); !strings.Contains(msg, "v0.7.3 → v0.8.0") {
+		t.Errorf("notice = %q, want v0.7.3 → v0.8.0", msg)
+	}
+	if msg := (&updateCheck{current: "0.8.0", cached: "v0.8.0"}).notice(); msg != "" {
+		t.Errorf("notice when current = %q, want none", msg)
+	}
+
+	// Past the interval the cache still answers while a refresh runs.
+	u = checkForUpdate("0.7.3", now.Add(updateCheckInterval+time.Minute))
+	if u.fresh == nil || u.cached != "v0.8.0" {
+		t.Errorf("stale check = %+v, want cached answer plus a refresh", u)
+	}
+	<-u.fresh
+}
+
+func TestCheckForUpdate_RecordsCheckBeforeRefreshAnswers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	release := make(chan struct{})
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`{"tag_name": "v0.8.0"}`))
+	}))
+	defer srv.Close()
+	orig := evolveReleaseAPIBase
+	evolveReleaseAPIBase = srv.URL
+	t.Cleanup(func() { evolveReleaseAPIBase = orig })
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := checkForUpdate("0.7.3", now)
+	if first == nil || first.fresh == nil {
+		t.Fatalf("first check = %+v, want a background refresh", first)
+	}
+	// The first refresh hasn't answered (its command could have exited);
+	// a second command within the interval still doesn't refresh.
+	if u := checkForUpdate("0.7.3", now.Add(time.Minute)); u.fresh != nil {
+		t.Error("check before the refresh answered started another refresh")
+	}
+	close(release)
+	<-first.fresh
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestStartUpdateCheck_Disabled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	orig := evolveCurrentVersion
+	evolveCurrentVersion = "0.7.3"
+	t.Cleanup(func() { evolveCurrentVersion = orig })
+
+	// Not opted in, and `go test` output is never a terminal.
+	if u := startUpdateCheck(rootCmd); u != nil {
+		t.Errorf("startUpdateCheck = %+v, want nil", u)
+	}
+}
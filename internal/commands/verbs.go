@@ -223,6 +223,8 @@ func init() {
 	newFoundrySubCmd.Flags().StringVarP(&newFoundryOutput, "output", "o", ".", "parent directory to create the foundry in")
 
 	// Flags for bidirectional "add ore" must mirror "ore add" flags.
+	addFoundrySubCmd.Flags().BoolVar(&foundrySystem, "system", false, "register in the system scope for every user on this machine")
+	removeFoundrySubCmd.Flags().BoolVar(&foundrySystem, "system", false, "remove from the system scope")
 	addOreSubCmd.Flags().StringVar(&oreAddAlias, "as", "", "namespace alias (install at ore.<alias>.* instead of ore.<name>.*)")
 	addOreSubCmd.Flags().BoolVar(&oreAddGlobal, "global", false, "install under ~/.ailloy/ores/ instead of ./.ailloy/ores/")
 
@@ -1,12 +1,15 @@
 package commands
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/huh"
+	"github.com/nimble-giant/ailloy/pkg/github"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 )
@@ -25,6 +28,7 @@ type dynamicWizard struct {
 	values          map[string]*string               // bound string/int/select values
 	boolVals        map[string]*bool                 // bound bool values
 	textVals        map[string]*string               // bound list (multi-line text) values
+	multiVals       map[string]*[]string             // bound multiselect values
 	discoverResults map[string][]mold.DiscoverResult // last discovery results per field name
 }
 
@@ -33,10 +37,11 @@ func newDynamicWizard(schema []mold.FluxVar, flux map[string]any) *dynamicWizard
 	w := &dynamicWizard{
 		schema:          schema,
 		flux:            flux,
-		discovery:       mold.NewDiscoverExecutor(),
+		discovery:       newDiscoveryExecutor(),
 		values:          make(map[string]*string),
 		boolVals:        make(map[string]*bool),
 		textVals:        make(map[string]*string),
+		multiVals:       make(map[string]*[]string),
 		discoverResults: make(map[string][]mold.DiscoverResult),
 	}
 
@@ -59,7 +64,15 @@ func newDynamicWizard(schema []mold.FluxVar, flux map[string]any) *dynamicWizard
 				s = fv.Default
 			}
 			w.textVals[fv.Name] = &s
-		default: // string, int, select
+		case "multiselect":
+			var sel []string
+			if v, ok := mold.GetNestedAny(flux, fv.Name); ok {
+				sel = mold.FluxListValues(v)
+			} else {
+				sel = mold.FluxListValues(fv.Default)
+			}
+			w.multiVals[fv.Name] = &sel
+		default: // string, int, select, secret
 			s := ""
 			if v, ok := getFluxString(flux, fv.Name); ok {
 				s = v
@@ -167,6 +180,10 @@ func (w *dynamicWizard) buildField(fv mold.FluxVar) huh.Field {
 		return w.buildListField(fv)
 	case "select":
 		return w.buildSelectField(fv)
+	case "multiselect":
+		return w.buildMultiSelectField(fv)
+	case "secret":
+		return w.buildStringField(fv)
 	default: // string
 		if fv.Discover != nil {
 			return w.buildDiscoverField(fv)
@@ -175,20 +192,23 @@ func (w *dynamicWizard) buildField(fv mold.FluxVar) huh.Field {
 	}
 }
 
-// buildStringField creates a huh.Input for string variables.
+// buildStringField creates a huh.Input for string variables. Secret
+// variables are masked as they are typed.
 func (w *dynamicWizard) buildStringField(fv mold.FluxVar) huh.Field {
 	input := huh.NewInput().
 		Title(fieldTitle(fv)).
 		Description(fv.Description).
 		Value(w.values[fv.Name])
 
-	if fv.Default != "" {
+	if fv.Type == "secret" {
+		input.EchoMode(huh.EchoModePassword)
+	} else if fv.Default != "" {
 		input.Placeholder(fv.Default)
 	}
 
-	if fv.Required {
+	if fv.Required || fv.RequiredIf != "" {
 		input.Validate(func(s string) error {
-			if s == "" {
+			if s == "" && w.required(fv) {
 				return fmt.Errorf("%s is required", fv.Name)
 			}
 			return nil
@@ -198,6 +218,13 @@ func (w *dynamicWizard) buildStringField(fv mold.FluxVar) huh.Field {
 	return input
 }
 
+// required reports whether fv must be filled in given the answers so far,
+// so a required_if field is only demanded once its condition holds.
+func (w *dynamicWizard) required(fv mold.FluxVar) bool {
+	req, err := fv.RequiredFor(w.currentFlux())
+	return err == nil && req
+}
+
 // buildBoolField creates a huh.Confirm for bool variables.
 func (w *dynamicWizard) buildBoolField(fv mold.FluxVar) huh.Field {
 	return huh.NewConfirm().
@@ -216,7 +243,7 @@ func (w *dynamicWizard) buildIntField(fv mold.FluxVar) huh.Field {
 		Value(w.values[fv.Name]).
 		Validate(func(s string) error {
 			if s == "" {
-				if fv.Required {
+				if w.required(fv) {
 					return fmt.Errorf("%s is required", fv.Name)
 				}
 				return nil
@@ -281,6 +308,44 @@ func (w *dynamicWizard) buildDiscoverField(fv mold.FluxVar) huh.Field {
 		Height(10)
 }
 
+// buildMultiSelectField creates a huh.MultiSelect for multiselect variables,
+// with static options or lazy discovery like select.
+func (w *dynamicWizard) buildMultiSelectField(fv mold.FluxVar) huh.Field {
+	ms := huh.NewMultiSelect[string]().
+		Title(fieldTitle(fv)).
+		Description(fv.Description).
+		Value(w.multiVals[fv.Name]).
+		Height(10)
+	if fv.Discover != nil {
+		ms.OptionsFunc(func() []huh.Option[string] {
+			// Placeholders and the "(skip)" entry carry no value; nothing
+			// selected already skips a multiselect.
+			var opts []huh.Option[string]
+			for _, o := range w.runDiscovery(fv) {
+				if o.Value != "" {
+					opts = append(opts, o)
+				}
+			}
+			return opts
+		}, w.fluxDeps(fv))
+	} else {
+		opts := make([]huh.Option[string], 0, len(fv.Options))
+		for _, o := range fv.Options {
+			opts = append(opts, huh.NewOption(o.Label, o.Value))
+		}
+		ms.Options(opts...)
+	}
+	if fv.Required || fv.RequiredIf != "" {
+		ms.Validate(func(sel []string) error {
+			if len(sel) == 0 && w.required(fv) {
+				return fmt.Errorf("pick at least one %s", fv.Name)
+			}
+			return nil
+		})
+	}
+	return ms
+}
+
 // siblingEnabledHideFunc returns a function that hides a field when a sibling
 // "enabled" bool in the same group prefix is false. Returns nil if no such
 // sibling exists or if the field IS the enabled bool itself.
@@ -301,6 +366,40 @@ func (w *dynamicWizard) siblingEnabledHideFunc(name string) func() bool {
 	}
 }
 
+// guardDiscovery puts the wizard's discover commands under the exec policy
+// and asks for consent to run them (see confirmMoldExec); key and name
+// identify the mold. Without consent the commands are refused like under
+// --no-exec, and the affected prompts fall back to manual entry.
+func (w *dynamicWizard) guardDiscovery(key, name string) error {
+	var b strings.Builder
+	sum := sha256.New()
+	for _, fv := range w.schema {
+		if fv.Discover == nil || fv.Discover.Command == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", fv.Name, fv.Discover.Command)
+		fmt.Fprintf(sum, "%s\x00%s\x00", fv.Name, fv.Discover.Command)
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	policy, err := currentExecPolicy()
+	if err != nil {
+		return err
+	}
+	w.discovery.Policy = policy
+	if policy.Disabled {
+		return nil
+	}
+	summary := name + " runs these commands to discover flux options:\n" + b.String()
+	ok, err := confirmMoldExec(key, execKindDiscover, hex.EncodeToString(sum.Sum(nil)), name, summary)
+	if err != nil {
+		return err
+	}
+	w.discovery.Policy.Disabled = !ok
+	return nil
+}
+
 // runDiscovery executes a discover spec and returns huh options.
 // Falls back to a manual entry option on failure.
 // If template dependencies (e.g. {{.project.organization}}) are not yet
@@ -464,6 +563,9 @@ func (w *dynamicWizard) fluxDeps(fv mold.FluxVar) any {
 		if ptr, ok := w.textVals[ref]; ok {
 			deps = append(deps, ptr)
 		}
+		if ptr, ok := w.multiVals[ref]; ok {
+			deps = append(deps, ptr)
+		}
 	}
 	if len(deps) == 0 {
 		return "static"
@@ -519,6 +621,19 @@ func (w *dynamicWizard) currentFlux() map[string]any {
 			mold.SetNestedValue(flux, name, *ptr)
 		}
 	}
+	for name, ptr := range w.multiVals {
+		if ptr == nil {
+			continue
+		}
+		// Stored as a YAML list; an emptied selection clears a saved one.
+		if _, had := mold.GetNestedAny(flux, name); len(*ptr) > 0 || had {
+			sel := make([]any, len(*ptr))
+			for i, s := range *ptr {
+				sel[i] = s
+			}
+			mold.SetNestedAny(flux, name, sel)
+		}
+	}
 	// Apply also_sets: propagate extra segments from discover results
 	w.applyAlsoSets(flux)
 	return flux
@@ -555,6 +670,9 @@ func (w *dynamicWizard) applyAlsoSets(flux map[string]any) {
 	}
 }
 
+// secretMask stands in for a secret value in the review summary.
+const secretMask = "********"
+
 // buildSummary creates a dynamic review preview from all bound values.
 func (w *dynamicWizard) buildSummary() string {
 	var b strings.Builder
@@ -565,6 +683,9 @@ func (w *dynamicWizard) buildSummary() string {
 
 	for _, fv := range w.schema {
 		val := w.getBoundValue(fv)
+		if val != "" && fv.Type == "secret" {
+			val = secretMask
+		}
 		if val != "" {
 			fmt.Fprintf(&b, "  %s: %s\n", fv.Name, val)
 		}
@@ -595,6 +716,10 @@ func (w *dynamicWizard) getBoundValue(fv mold.FluxVar) string {
 		if ptr, ok := w.textVals[fv.Name]; ok && ptr != nil {
 			return *ptr
 		}
+	case "multiselect":
+		if ptr, ok := w.multiVals[fv.Name]; ok && ptr != nil {
+			return strings.Join(*ptr, ", ")
+		}
 	default:
 		if ptr, ok := w.values[fv.Name]; ok && ptr != nil {
 			return *ptr
@@ -668,6 +793,9 @@ func (w *dynamicWizard) allDeps() any {
 	for _, ptr := range w.textVals {
 		deps = append(deps, ptr)
 	}
+	for _, ptr := range w.multiVals {
+		deps = append(deps, ptr)
+	}
 	return deps
 }
 
@@ -685,3 +813,15 @@ func fieldTitle(fv mold.FluxVar) string {
 	}
 	return name
 }
+
+// newDiscoveryExecutor returns the executor for schema discovery commands.
+// When the flux names a GitHub Enterprise scm.host, gh in those commands
+// targets it through GH_HOST.
+func newDiscoveryExecutor() *mold.DiscoverExecutor {
+	d := mold.NewDiscoverExecutor()
+	d.Env = func(flux map[string]any) []string {
+		host, _ := mold.GetNestedValue(flux, mold.RepoHostKey)
+		return github.HostEnv(host)
+	}
+	return d
+}
@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -265,6 +267,45 @@ func TestDynamicWizard_CurrentFlux(t *testing.T) {
 	}
 }
 
+func TestDynamicWizard_Multiselect(t *testing.T) {
+	schema := []mold.FluxVar{
+		{Name: "labels", Type: "multiselect", Default: "bug, docs", Options: []mold.SelectOption{{Value: "bug"}, {Value: "docs"}, {Value: "ops"}}},
+		{Name: "teams", Type: "multiselect", Options: []mold.SelectOption{{Value: "core"}}},
+	}
+	w := newDynamicWizard(schema, map[string]any{"teams": []any{"core"}})
+	if got := *w.multiVals["labels"]; !reflect.DeepEqual(got, []string{"bug", "docs"}) {
+		t.Errorf("labels pre-populated from default = %v", got)
+	}
+	*w.multiVals["labels"] = []string{"ops"}
+	*w.multiVals["teams"] = nil
+
+	flux := w.currentFlux()
+	if got := flux["labels"]; !reflect.DeepEqual(got, []any{"ops"}) {
+		t.Errorf("labels = %#v, want a YAML list", got)
+	}
+	if got := flux["teams"]; !reflect.DeepEqual(got, []any{}) {
+		t.Errorf("teams = %#v, want the saved selection cleared", got)
+	}
+	if !strings.Contains(w.buildSummary(), "labels: ops") {
+		t.Errorf("summary = %q", w.buildSummary())
+	}
+}
+
+func TestDynamicWizard_SecretMaskedInSummary(t *testing.T) {
+	schema := []mold.FluxVar{
+		{Name: "linear.api_key", Type: "secret"},
+		{Name: "linear.team", Type: "string"},
+	}
+	w := newDynamicWizard(schema, map[string]any{"linear": map[string]any{"api_key": "lin_123", "team": "core"}})
+	summary := w.buildSummary()
+	if strings.Contains(summary, "lin_123") || !strings.Contains(summary, "linear.api_key: "+secretMask) {
+		t.Errorf("summary = %q, want the secret masked", summary)
+	}
+	if got, _ := mold.GetNestedAny(w.currentFlux(), "linear.api_key"); got != "lin_123" {
+		t.Errorf("current flux api_key = %v, want the real value", got)
+	}
+}
+
 func TestDynamicWizard_BuildGroups_GeneratesGroups(t *testing.T) {
 	schema := []mold.FluxVar{
 		{Name: "project.organization", Type: "string", Required: true, Description: "Org name"},
@@ -364,7 +405,7 @@ func TestDynamicWizard_RunDiscovery_Success(t *testing.T) {
 
 	w := newDynamicWizard(schema, map[string]any{})
 	w.discovery = &mold.DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte("Board A|id_a\nBoard B|id_b\n"), nil
 		},
 	}
@@ -387,7 +428,7 @@ func TestDynamicWizard_RunDiscovery_Failure(t *testing.T) {
 
 	w := newDynamicWizard(schema, map[string]any{})
 	w.discovery = &mold.DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return nil, fmt.Errorf("command failed")
 		},
 	}
@@ -413,7 +454,7 @@ func TestDynamicWizard_RunDiscovery_WaitingForDeps(t *testing.T) {
 	w := newDynamicWizard(schema, map[string]any{})
 	cmdRan := false
 	w.discovery = &mold.DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			cmdRan = true
 			return []byte("Board A|id_a\n"), nil
 		},
@@ -443,7 +484,7 @@ func TestDynamicWizard_RunDiscovery_DepsPopulated(t *testing.T) {
 		"project": map[string]any{"organization": "acme"},
 	})
 	w.discovery = &mold.DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte("Board A|id_a\n"), nil
 		},
 	}
@@ -470,7 +511,7 @@ func TestDynamicWizard_AlsoSets(t *testing.T) {
 
 	w := newDynamicWizard(schema, map[string]any{})
 	w.discovery = &mold.DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte("engineering (#6)|PVT_abc|engineering|6\n"), nil
 		},
 	}
@@ -580,3 +621,28 @@ func TestLookupNestedString(t *testing.T) {
 		t.Errorf("expected '42' for int value, got %q", v)
 	}
 }
+
+func TestDynamicWizard_GuardDiscovery(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	schema := []mold.FluxVar{
+		{Name: "project.id", Type: "string", Discover: &mold.DiscoverSpec{Command: "gh api x"}},
+	}
+
+	// Without a terminal, unapproved commands are refused.
+	w := newDynamicWizard(schema, map[string]any{})
+	if err := w.guardDiscovery("example/mold", "mold"); err != nil {
+		t.Fatal(err)
+	}
+	if !w.discovery.Policy.Disabled {
+		t.Error("unapproved discover commands were left enabled")
+	}
+	if _, err := w.discovery.Run(*schema[0].Discover, nil); !errors.Is(err, mold.ErrExecDisabled) {
+		t.Errorf("Run err = %v", err)
+	}
+
+	// A schema without discover commands needs no consent.
+	w = newDynamicWizard([]mold.FluxVar{{Name: "org", Type: "string"}}, map[string]any{})
+	if err := w.guardDiscovery("example/mold", "mold"); err != nil || w.discovery.Policy.Disabled {
+		t.Errorf("err = %v, disabled = %v", err, w.discovery.Policy.Disabled)
+	}
+}
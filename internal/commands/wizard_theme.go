@@ -64,7 +64,7 @@ func ailloyTheme() *huh.Theme {
 		MarginBottom(1)
 
 	t.Focused.Card = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(styles.BoxBorder()).
 		BorderForeground(styles.Primary1).
 		Padding(1, 2)
 
@@ -78,5 +78,14 @@ func ailloyTheme() *huh.Theme {
 	t.Blurred.SelectSelector = lipgloss.NewStyle().
 		SetString("  ")
 
+	// Plain output: swap the remaining box-drawing and arrow glyphs.
+	if styles.Plain() {
+		t.Focused.Base = t.Focused.Base.BorderStyle(lipgloss.ASCIIBorder())
+		for _, fs := range []*huh.FieldStyles{&t.Focused, &t.Blurred} {
+			fs.NextIndicator = fs.NextIndicator.SetString(">")
+			fs.PrevIndicator = fs.PrevIndicator.SetString("<")
+		}
+	}
+
 	return t
 }
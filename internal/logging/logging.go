@@ -0,0 +1,183 @@
+// Package logging configures ailloy's leveled logger. Commands report through
+// log/slog (and the standard log package, which is bridged into it), and
+// through Say for the progress lines the human format decorates.
+//
+// Two formats are supported. "text" is for people: progress goes to stdout
+// with styling and emoji, and warnings and errors go to stderr. "json" is for
+// machines: every record is one JSON object on stderr, decoration is dropped,
+// and stdout carries only command data (rendered files, --format json
+// reports).
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+)
+
+// Formats accepted by --log-format.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures Setup.
+type Options struct {
+	Format  string // FormatText (default) or FormatJSON
+	Verbose bool   // enable debug records
+	Quiet   bool   // only warnings and errors; progress lines are dropped
+	// Stdout and Stderr default to os.Stdout and os.Stderr, looked up on
+	// every write so tests that swap them are honored.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+var (
+	mu      sync.RWMutex
+	current = Options{Format: FormatText}
+)
+
+// Setup installs the slog default logger for opts and routes the standard
+// log package through it. Lines logged there with a "warning:", "error:", or
+// "debug:" prefix keep that level; the rest are info.
+func Setup(opts Options) error {
+	if opts.Format == "" {
+		opts.Format = FormatText
+	}
+	if opts.Format != FormatText && opts.Format != FormatJSON {
+		return fmt.Errorf("invalid --log-format %q: use %s or %s", opts.Format, FormatText, FormatJSON)
+	}
+	if opts.Verbose && opts.Quiet {
+		return fmt.Errorf("--verbose and --quiet are mutually exclusive")
+	}
+
+	mu.Lock()
+	current = opts
+	mu.Unlock()
+
+	level := slog.LevelInfo
+	switch {
+	case opts.Verbose:
+		level = slog.LevelDebug
+	case opts.Quiet:
+		level = slog.LevelWarn
+	}
+
+	var h slog.Handler
+	if opts.Format == FormatJSON {
+		h = slog.NewJSONHandler(stderrWriter{}, &slog.HandlerOptions{Level: level})
+		// Unconverted output paths still print; keep ANSI codes out of it.
+		lipgloss.SetColorProfile(termenv.Ascii)
+		styles.SetNoAnimate(true)
+	} else {
+		h = &textHandler{level: level}
+		if opts.Quiet {
+			styles.SetNoAnimate(true)
+		}
+	}
+	slog.SetDefault(slog.New(h))
+
+	// slog.SetDefault already points the log package at h, but at info
+	// level only; the bridge recovers levels from message prefixes.
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(bridge{})
+	return nil
+}
+
+// Decorative reports whether styled, human-only output (banners, emoji,
+// blank spacer lines, animations) should be printed: text format without
+// --quiet.
+func Decorative() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current.Format != FormatJSON && !current.Quiet
+}
+
+// JSON reports whether --log-format json is in effect.
+func JSON() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current.Format == FormatJSON
+}
+
+// Say reports progress. In the decorative text format pretty is printed to
+// stdout as-is; otherwise msg and args are logged at info level, so JSON
+// consumers get a stable message and fields instead of styled text.
+func Say(pretty, msg string, args ...any) {
+	if Decorative() {
+		_, _ = fmt.Fprintln(Stdout(), pretty)
+		return
+	}
+	slog.Info(msg, args...)
+}
+
+// Decor prints lines to stdout only when output is decorative: banners,
+// tips, and spacer lines ("" prints an empty line) that carry nothing a
+// machine needs.
+func Decor(lines ...string) {
+	if !Decorative() {
+		return
+	}
+	for _, l := range lines {
+		_, _ = fmt.Fprintln(Stdout(), l)
+	}
+}
+
+// Stdout returns the configured stdout.
+func Stdout() io.Writer {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current.Stdout != nil {
+		return current.Stdout
+	}
+	return os.Stdout
+}
+
+// Stderr returns the configured stderr.
+func Stderr() io.Writer {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current.Stderr != nil {
+		return current.Stderr
+	}
+	return os.Stderr
+}
+
+type stderrWriter struct{}
+
+func (stderrWriter) Write(p []byte) (int, error) { return Stderr().Write(p) }
+
+// bridge receives standard log package output and re-logs it through slog
+// at the level its prefix names.
+type bridge struct{}
+
+func (bridge) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	level := slog.LevelInfo
+	for _, lp := range []struct {
+		prefix string
+		level  slog.Level
+	}{
+		{"warning: ", slog.LevelWarn},
+		{"warn: ", slog.LevelWarn},
+		{"error: ", slog.LevelError},
+		{"debug: ", slog.LevelDebug},
+	} {
+		if len(msg) >= len(lp.prefix) && strings.EqualFold(msg[:len(lp.prefix)], lp.prefix) {
+			msg, level = msg[len(lp.prefix):], lp.level
+			break
+		}
+	}
+	slog.Log(context.Background(), level, msg)
+	return len(p), nil
+}
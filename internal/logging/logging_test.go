@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func setup(t *testing.T, opts Options) (stdout, stderr *bytes.Buffer) {
+	t.Helper()
+	stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+	opts.Stdout, opts.Stderr = stdout, stderr
+	if err := Setup(opts); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	t.Cleanup(func() { _ = Setup(Options{}) })
+	return stdout, stderr
+}
+
+func TestSetup_RejectsBadOptions(t *testing.T) {
+	if err := Setup(Options{Format: "yaml"}); err == nil {
+		t.Error("unknown format: want error")
+	}
+	if err := Setup(Options{Verbose: true, Quiet: true}); err == nil {
+		t.Error("verbose and quiet: want error")
+	}
+}
+
+func TestText_SayAndLevels(t *testing.T) {
+	stdout, stderr := setup(t, Options{})
+
+	Say("✅ Created: a.md", "created", "path", "a.md")
+	Decor("banner")
+	slog.Debug("hidden")
+	log.Printf("warning: disk %s", "full")
+	log.Printf("skipping x: rendered to empty content")
+
+	if got := stdout.String(); got != "✅ Created: a.md\nbanner\n" {
+		t.Errorf("stdout = %q", got)
+	}
+	errOut := stderr.String()
+	if strings.Contains(errOut, "hidden") {
+		t.Error("debug record printed without --verbose")
+	}
+	if !strings.Contains(errOut, "warning:") || !strings.Contains(errOut, "disk full") {
+		t.Errorf("bridged warning missing: %q", errOut)
+	}
+	if !strings.Contains(errOut, "skipping x: rendered to empty content\n") {
+		t.Errorf("bridged info missing: %q", errOut)
+	}
+}
+
+func TestText_QuietAndVerbose(t *testing.T) {
+	stdout, stderr := setup(t, Options{Quiet: true})
+	Say("✅ Created: a.md", "created", "path", "a.md")
+	Decor("banner")
+	log.Printf("info line")
+	slog.Warn("careful", "n", 2)
+	if stdout.Len() != 0 {
+		t.Errorf("quiet stdout = %q", stdout.String())
+	}
+	if got := stderr.String(); !strings.Contains(got, "careful n=2") || strings.Contains(got, "info line") {
+		t.Errorf("quiet stderr = %q", got)
+	}
+
+	_, stderr = setup(t, Options{Verbose: true})
+	slog.Debug("resolved", "ref", "github.com/acme/mold")
+	if got := stderr.String(); !strings.Contains(got, "resolved ref=github.com/acme/mold") {
+		t.Errorf("verbose stderr = %q", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	stdout, stderr := setup(t, Options{Format: FormatJSON})
+	if !JSON() || Decorative() {
+		t.Fatal("json format should be non-decorative")
+	}
+
+	Say("✅ Created: a.md", "created", "path", "a.md")
+	Decor("banner")
+	log.Printf("warning: disk full")
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing", stdout.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["level"] != "INFO" || rec["msg"] != "created" || rec["path"] != "a.md" {
+		t.Errorf("record = %v", rec)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["level"] != "WARN" || rec["msg"] != "disk full" {
+		t.Errorf("bridged record = %v", rec)
+	}
+}
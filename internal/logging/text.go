@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/styles"
+)
+
+// textHandler writes records for people: one line per record on stderr,
+// "warning: "/"error: "/"debug: " prefixed, attributes as key=value. No
+// timestamps; the records sit among the command's own output.
+type textHandler struct {
+	level  slog.Leveler
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *textHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString(styles.ErrorStyle.Render("error:") + " ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString(styles.WarningStyle.Render("warning:") + " ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString(styles.SubtleStyle.Render("debug:") + " ")
+	}
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writeAttr(&b, h.groups, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.groups, a)
+		return true
+	})
+	b.WriteByte('\n')
+	_, err := fmt.Fprint(Stderr(), b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &c
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.groups = append(append([]string{}, h.groups...), name)
+	return &c
+}
+
+func writeAttr(b *strings.Builder, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		sub := groups
+		if a.Key != "" {
+			sub = append(append([]string{}, groups...), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, sub, ga)
+		}
+		return
+	}
+	key := strings.Join(append(append([]string{}, groups...), a.Key), ".")
+	val := a.Value.String()
+	if val == "" || strings.ContainsAny(val, " \t\"=") {
+		val = fmt.Sprintf("%q", val)
+	}
+	fmt.Fprintf(b, " %s=%s", key, val)
+}
@@ -13,10 +13,12 @@ package ceremony
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/nimble-giant/ailloy/internal/logging"
 	"github.com/nimble-giant/ailloy/pkg/styles"
 )
 
@@ -64,8 +66,12 @@ type Theme struct {
 
 // Open plays the entrance flourish and prints the working banner. Returns
 // immediately on non-animatable terminals after writing the plain-text
-// banner. Synchronous; the animation is brief by design.
+// banner. Synchronous; the animation is brief by design. Prints nothing
+// under --quiet or --log-format json.
 func Open(t Theme) {
+	if !logging.Decorative() {
+		return
+	}
 	if !styles.ShouldAnimate() {
 		fmt.Println(styles.WorkingBanner(t.Verb + "..."))
 		fmt.Println()
@@ -102,7 +108,8 @@ func Open(t Theme) {
 // squash-and-stretch entrance, then settles. The summary text is the
 // command's existing plain-text conclusion (e.g. "0 errors, 2 warnings");
 // it appears verbatim within the stamp. Falls back to plain text when not
-// animatable.
+// animatable. Under --quiet or --log-format json the summary is logged at
+// info level instead.
 //
 // Format on a TTY:
 //
@@ -110,6 +117,10 @@ func Open(t Theme) {
 //
 // (One frame of oversized padding/bold, then settles to the regular form.)
 func Stamp(t Theme, summary string) {
+	if !logging.Decorative() {
+		slog.Info(t.Name+" complete", "summary", summary)
+		return
+	}
 	stamp := composeStamp(t.Glyph, t.StampWord, summary, t.Primary, t.Accent, false)
 	if !styles.ShouldAnimate() {
 		fmt.Println(stamp)
@@ -125,8 +136,13 @@ func Stamp(t Theme, summary string) {
 
 // FailStamp is the error counterpart of Stamp. Uses the warning/error
 // palette. The animated frame is dimmer rather than brighter so failure
-// reads as deflation, not celebration.
+// reads as deflation, not celebration. Logged as an error when output
+// isn't decorative.
 func FailStamp(t Theme, summary string) {
+	if !logging.Decorative() {
+		slog.Error(t.Name+" failed", "summary", summary)
+		return
+	}
 	stamp := composeFailStamp(t.Glyph, t.StampWord, summary)
 	if !styles.ShouldAnimate() {
 		fmt.Println(stamp)
@@ -155,7 +171,7 @@ func composeStamp(glyph, word, summary string, fg, bgAccent lipgloss.Color, squa
 	if strings.TrimSpace(summary) != "" {
 		tail = lipgloss.NewStyle().Foreground(styles.Gray).Render(" — ") + summary
 	}
-	return glyph + " " + wordStyle.Render(word) + tail
+	return styles.Text(glyph + " " + wordStyle.Render(word) + tail)
 }
 
 func composeFailStamp(glyph, word, summary string) string {
@@ -164,5 +180,5 @@ func composeFailStamp(glyph, word, summary string) string {
 	if strings.TrimSpace(summary) != "" {
 		tail = lipgloss.NewStyle().Foreground(styles.Gray).Render(" — ") + summary
 	}
-	return glyph + " " + wordStyle.Render(word+" FAILED") + tail
+	return styles.Text(glyph + " " + wordStyle.Render(word+" FAILED") + tail)
 }
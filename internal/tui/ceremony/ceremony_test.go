@@ -21,6 +21,9 @@ func removeEscapes(s string) string {
 }
 
 func TestComposeStamp_PlainContent(t *testing.T) {
+	// TERM=dumb would switch to plain output and strip the glyph.
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("AILLOY_PLAIN", "")
 	got := removeEscapes(composeStamp("🔥", "TEMPERED", "0 errors, 2 warnings", Temper.Primary, Temper.Accent, false))
 	// Must contain glyph, word, separator, and the summary verbatim.
 	for _, want := range []string{"🔥", "TEMPERED", "—", "0 errors, 2 warnings"} {
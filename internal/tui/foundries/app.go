@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +15,7 @@ import (
 	"github.com/nimble-giant/ailloy/internal/tui/foundries/health"
 	"github.com/nimble-giant/ailloy/internal/tui/foundries/installed"
 	"github.com/nimble-giant/ailloy/internal/tui/foundries/registered"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/foundry/index"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 )
@@ -406,10 +406,7 @@ func dedupeFluxVarsByName(in []mold.FluxVar) []mold.FluxVar {
 }
 
 func defaultGitRunner() index.GitRunner {
-	return func(args ...string) ([]byte, error) {
-		cmd := exec.Command("git", args...) // #nosec G204 -- args constructed internally
-		return cmd.CombinedOutput()
-	}
+	return index.GitRunner(foundry.DefaultGitRunner())
 }
 
 // Interface compliance — these will fail to compile if any tab drifts.
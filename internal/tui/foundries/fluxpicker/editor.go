@@ -46,7 +46,7 @@ func buildEditorForm(fv mold.FluxVar, value *string, boolVal *bool) *huh.Form {
 					}),
 			),
 		)
-	case "list":
+	case "list", "multiselect":
 		return huh.NewForm(
 			huh.NewGroup(
 				huh.NewText().
@@ -71,6 +71,16 @@ func buildEditorForm(fv mold.FluxVar, value *string, boolVal *bool) *huh.Form {
 					Value(value),
 			),
 		)
+	case "secret":
+		return huh.NewForm(
+			huh.NewGroup(
+				huh.NewInput().
+					Title(fv.Name).
+					Description(fv.Description).
+					EchoMode(huh.EchoModePassword).
+					Value(value),
+			),
+		)
 	default: // string
 		return huh.NewForm(
 			huh.NewGroup(
@@ -116,27 +126,24 @@ func commitEditorValue(m Model, fv mold.FluxVar, raw string) Model {
 			m.err = fmt.Errorf("%s: must be true/false", fv.Name)
 			return m
 		}
-	case "list":
+	case "list", "multiselect":
 		var parts []string
 		for _, p := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' }) {
 			t := strings.TrimSpace(p)
 			if t == "" {
 				continue
 			}
+			if fv.Type == "multiselect" && len(fv.Options) > 0 && !hasOption(fv, t) {
+				m.err = fmt.Errorf("%s: %q is not a valid option", fv.Name, t)
+				return m
+			}
 			parts = append(parts, t)
 		}
 		m.overrides[fv.Name] = parts
 	case "select":
 		v := strings.TrimSpace(raw)
 		if len(fv.Options) > 0 {
-			ok := false
-			for _, o := range fv.Options {
-				if o.Value == v {
-					ok = true
-					break
-				}
-			}
-			if !ok {
+			if !hasOption(fv, v) {
 				m.err = fmt.Errorf("%s: %q is not a valid option", fv.Name, v)
 				return m
 			}
@@ -149,6 +156,16 @@ func commitEditorValue(m Model, fv mold.FluxVar, raw string) Model {
 	return m
 }
 
+// hasOption reports whether v is one of fv's static option values.
+func hasOption(fv mold.FluxVar, v string) bool {
+	for _, o := range fv.Options {
+		if o.Value == v {
+			return true
+		}
+	}
+	return false
+}
+
 // ErrUnknownVar is returned when the editor is asked to commit a variable
 // not present in the schema.
 var ErrUnknownVar = errors.New("unknown flux variable")
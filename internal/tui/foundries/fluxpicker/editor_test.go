@@ -1,6 +1,7 @@
 package fluxpicker
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/nimble-giant/ailloy/pkg/mold"
@@ -84,3 +85,28 @@ func TestCommitEditorValue_SelectValidates(t *testing.T) {
 		t.Fatal("expected error on invalid select option")
 	}
 }
+
+func TestCommitEditorValue_MultiselectValidates(t *testing.T) {
+	fv := mold.FluxVar{
+		Name:    "k",
+		Type:    "multiselect",
+		Options: []mold.SelectOption{{Value: "a"}, {Value: "b"}},
+	}
+	m := commitEditorValue(Model{overrides: map[string]any{}}, fv, "a, b")
+	if got, ok := m.Overrides()["k"].([]string); !ok || len(got) != 2 {
+		t.Fatalf("override = %v want [a b]", m.Overrides()["k"])
+	}
+	m2 := commitEditorValue(Model{overrides: map[string]any{}}, fv, "a, z")
+	if _, ok := m2.Overrides()["k"]; ok || m2.err == nil {
+		t.Fatal("expected an unknown option to be refused")
+	}
+}
+
+func TestBuildEditorForm_SecretIsMasked(t *testing.T) {
+	value := "s3cret"
+	form := buildEditorForm(mold.FluxVar{Name: "token", Type: "secret"}, &value, new(bool))
+	form.Init()
+	if out := form.View(); strings.Contains(out, "s3cret") {
+		t.Fatalf("secret value should be masked in the editor, got:\n%s", out)
+	}
+}
@@ -101,24 +101,81 @@ func resolveGlobalPath(moldName string) (string, error) {
 var fluxFileSlug = mold.FluxFileSlug
 
 // persistOverrides routes overrides to the chosen save target. Returns the
-// path written (empty string for SaveTargetSession).
-func persistOverrides(moldName string, target SaveTarget, overrides map[string]any) (string, error) {
+// path written (empty string for SaveTargetSession). Values of schema's
+// secret variables go to the path's git-ignored LocalFluxPath companion.
+func persistOverrides(moldName string, target SaveTarget, schema []mold.FluxVar, overrides map[string]any) (string, error) {
 	switch target {
 	case SaveTargetSession:
 		return "", nil
 	case SaveTargetProject:
 		path := resolveProjectPath(moldName)
-		return path, writeFluxFile(path, overrides)
+		return path, writeOverrides(path, schema, overrides)
 	case SaveTargetGlobal:
 		path, err := resolveGlobalPath(moldName)
 		if err != nil {
 			return "", err
 		}
-		return path, writeFluxFile(path, overrides)
+		return path, writeOverrides(path, schema, overrides)
 	}
 	return "", fmt.Errorf("unknown save target %v", target)
 }
 
+// writeOverrides writes overrides to the flux file at path, except the
+// values of schema's secret variables: those go to mold.LocalFluxPath(path),
+// which is written 0600 and added to its directory's .gitignore. Cast
+// layers the companion over path (see mold.PersistedFluxPaths).
+func writeOverrides(path string, schema []mold.FluxVar, overrides map[string]any) error {
+	public, secret := splitSecretOverrides(schema, overrides)
+	if err := writeFluxFile(path, public); err != nil {
+		return err
+	}
+	if len(secret) == 0 {
+		return nil
+	}
+	secretPath := mold.LocalFluxPath(path)
+	if err := writeFluxFile(secretPath, secret); err != nil {
+		return err
+	}
+	return ignoreFluxFile(secretPath)
+}
+
+// splitSecretOverrides separates the dotted-key overrides of schema's
+// secret variables from the rest, via mold.SplitSecretFlux.
+func splitSecretOverrides(schema []mold.FluxVar, overrides map[string]any) (public, secret map[string]any) {
+	_, secrets := mold.SplitSecretFlux(schema, mergeOverrides(nil, overrides))
+	public, secret = map[string]any{}, map[string]any{}
+	for k, v := range overrides {
+		if _, ok := mold.GetNestedAny(secrets, k); ok {
+			secret[k] = v
+		} else {
+			public[k] = v
+		}
+	}
+	return public, secret
+}
+
+// ignoreFluxFile adds path's base name to the .gitignore in its directory
+// unless it is already listed.
+func ignoreFluxFile(path string) error {
+	ignorePath := filepath.Join(filepath.Dir(path), ".gitignore")
+	name := filepath.Base(path)
+	data, err := os.ReadFile(ignorePath) // #nosec G304 -- .gitignore beside a persisted flux file
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if l := strings.TrimSpace(line); l == name || l == "/"+name {
+			return nil
+		}
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	data = append(data, name+"\n"...)
+	//#nosec G306 -- .gitignore may be committed
+	return os.WriteFile(ignorePath, data, 0644)
+}
+
 // persistFoundryOverrides writes foundry-scope picker output to per-mold flux
 // files. Returns the list of paths written.
 //
@@ -129,6 +186,9 @@ func persistOverrides(moldName string, target SaveTarget, overrides map[string]a
 // used for each file is mold.FluxFileSlug(perMoldSourceRefs[moldName]) so the
 // cast pipeline's PersistedFluxPaths picks them up on subsequent casts.
 //
+// Secret values go to each file's LocalFluxPath companion, as in
+// persistOverrides.
+//
 // SaveTargetSession is a no-op (returns nil paths) because session overrides
 // live in the picker model and are forwarded by the App via FluxOverridesMsg.
 func persistFoundryOverrides(
@@ -189,7 +249,7 @@ func persistFoundryOverrides(
 		default:
 			return written, fmt.Errorf("unknown save target %v", target)
 		}
-		if err := writeFluxFile(path, overrides); err != nil {
+		if err := writeOverrides(path, perMoldSchemas[moldName], overrides); err != nil {
 			return written, err
 		}
 		written = append(written, path)
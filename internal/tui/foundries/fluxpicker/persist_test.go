@@ -59,7 +59,7 @@ func TestWriteFluxFile_MergesExisting(t *testing.T) {
 }
 
 func TestPersistOverrides_Session(t *testing.T) {
-	path, err := persistOverrides("agents", SaveTargetSession, map[string]any{"k": "v"})
+	path, err := persistOverrides("agents", SaveTargetSession, nil, map[string]any{"k": "v"})
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -70,7 +70,7 @@ func TestPersistOverrides_Session(t *testing.T) {
 
 func TestPersistOverrides_ProjectWritesFile(t *testing.T) {
 	t.Chdir(t.TempDir())
-	path, err := persistOverrides("agents", SaveTargetProject, map[string]any{"agents.targets": []string{"opencode"}})
+	path, err := persistOverrides("agents", SaveTargetProject, nil, map[string]any{"agents.targets": []string{"opencode"}})
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -85,7 +85,7 @@ func TestPersistOverrides_ProjectWritesFile(t *testing.T) {
 func TestPersistOverrides_GlobalWritesFile(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
-	path, err := persistOverrides("agents", SaveTargetGlobal, map[string]any{"k": "v"})
+	path, err := persistOverrides("agents", SaveTargetGlobal, nil, map[string]any{"k": "v"})
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -98,6 +98,51 @@ func TestPersistOverrides_GlobalWritesFile(t *testing.T) {
 	}
 }
 
+func TestPersistOverrides_SecretsGoToIgnoredLocalFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	schema := []mold.FluxVar{
+		{Name: "org", Type: "string"},
+		{Name: "api.token", Type: "secret"},
+	}
+	path, err := persistOverrides("agents", SaveTargetProject, schema, map[string]any{"org": "acme", "api.token": "s3cret"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	public, _ := os.ReadFile(path)
+	if strings.Contains(string(public), "s3cret") || !strings.Contains(string(public), "acme") {
+		t.Errorf("%s should hold org and not the secret:\n%s", path, public)
+	}
+
+	secretPath := filepath.Join(".ailloy", "flux", "agents.local.yaml")
+	b, err := os.ReadFile(secretPath)
+	if err != nil {
+		t.Fatalf("read secret file: %v", err)
+	}
+	var got map[string]any
+	if err := yaml.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := mold.GetNestedAny(got, "api.token"); v != "s3cret" || len(got) != 1 {
+		t.Errorf("secret file = %v, want only api.token", got)
+	}
+	if info, err := os.Stat(secretPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("secret file mode = %v, %v; want 0600", info, err)
+	}
+	ignore, _ := os.ReadFile(filepath.Join(".ailloy", "flux", ".gitignore"))
+	if !strings.Contains(string(ignore), "agents.local.yaml\n") {
+		t.Errorf(".gitignore = %q, want agents.local.yaml listed", ignore)
+	}
+
+	// Saving again doesn't list the file twice.
+	if _, err := persistOverrides("agents", SaveTargetProject, schema, map[string]any{"api.token": "other"}); err != nil {
+		t.Fatal(err)
+	}
+	ignore, _ = os.ReadFile(filepath.Join(".ailloy", "flux", ".gitignore"))
+	if strings.Count(string(ignore), "agents.local.yaml") != 1 {
+		t.Errorf(".gitignore = %q, want one entry", ignore)
+	}
+}
+
 func TestFluxFileSlug(t *testing.T) {
 	cases := map[string]string{
 		"":                                   "mold",
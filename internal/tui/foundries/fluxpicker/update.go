@@ -195,7 +195,7 @@ func (m Model) handleSaveKey(k tea.KeyMsg) (Model, tea.Cmd) {
 			return m, nil
 		}
 		moldName := fluxFileSlug(m.moldRef)
-		if _, err := persistOverrides(moldName, target, m.overrides); err != nil {
+		if _, err := persistOverrides(moldName, target, m.schema, m.overrides); err != nil {
 			m.err = err
 			return m, nil
 		}
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
 const footerHint = "tab: commit filter   enter: edit key   ctrl+s: save & close   esc: discard / close"
@@ -57,7 +59,7 @@ func (m Model) View() string {
 			badge = "○"
 			row = rowDefault
 		}
-		display := m.displayValueFor(fv.Name)
+		display := m.displayValueFor(fv)
 		line := fmt.Sprintf("%s %-22s %-8s %s", badge, fv.Name, fv.Type, display)
 		// cursor starts at 0, so the top row is highlighted by default until
 		// the user moves it.
@@ -101,12 +103,21 @@ func (m Model) View() string {
 	return pickerBox.Render(b.String())
 }
 
-func (m Model) displayValueFor(name string) string {
-	if v, ok := m.overrides[name]; ok {
+// secretMask stands in for the value of a secret variable.
+const secretMask = "********"
+
+func (m Model) displayValueFor(fv mold.FluxVar) string {
+	if v, ok := m.overrides[fv.Name]; ok {
+		if fv.Type == "secret" {
+			return secretMask
+		}
 		return fmt.Sprintf("%v", v)
 	}
-	if hasDottedKey(m.defaults, name) {
-		return fmt.Sprintf("%v (default)", lookupDottedKey(m.defaults, name))
+	if hasDottedKey(m.defaults, fv.Name) {
+		if fv.Type == "secret" {
+			return secretMask + " (default)"
+		}
+		return fmt.Sprintf("%v (default)", lookupDottedKey(m.defaults, fv.Name))
 	}
 	return "—"
 }
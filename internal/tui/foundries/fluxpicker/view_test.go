@@ -39,6 +39,20 @@ func TestView_BadgesReflectState(t *testing.T) {
 	}
 }
 
+func TestView_MasksSecretValues(t *testing.T) {
+	schema := []mold.FluxVar{{Name: "token", Type: "secret"}, {Name: "key", Type: "secret"}}
+	defaults := map[string]any{"key": "default-key"}
+	m := New().OpenFor("ref", data.ScopeProject, schema, defaults).SetOverride("token", "s3cret")
+	m.width, m.height = 80, 24
+	out := m.View()
+	if strings.Contains(out, "s3cret") || strings.Contains(out, "default-key") {
+		t.Fatalf("secret values should be masked, got:\n%s", out)
+	}
+	if !strings.Contains(out, secretMask) {
+		t.Fatalf("expected %q in place of the secrets, got:\n%s", secretMask, out)
+	}
+}
+
 func TestView_HiddenWhenClosed(t *testing.T) {
 	m := New()
 	if m.View() != "" {
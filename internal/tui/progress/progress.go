@@ -0,0 +1,95 @@
+// Package progress draws an inline progress bar for work with a known number
+// of steps, such as rendering and writing a mold's blanks. The bar advances
+// only when a step actually finishes; there are no timers.
+//
+// Like ceremony, it never takes over the screen: the bar lives on the last
+// line and is redrawn in place, and lines printed through Say scroll above
+// it. On non-TTY, NO_COLOR, CI, TERM=dumb, --plain, --no-animate, --quiet,
+// or --log-format json no bar is drawn and Say falls through to
+// logging.Say.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+	"github.com/nimble-giant/ailloy/pkg/styles"
+)
+
+// Bar is an inline progress bar. The zero value and a nil *Bar are valid
+// and draw nothing. Methods are safe for concurrent use.
+type Bar struct {
+	mu    sync.Mutex
+	w     io.Writer
+	label string
+	total int
+	done  int
+	live  bool
+}
+
+// New returns a bar for total steps labelled label. The bar is drawn only
+// when output is decorative and animations are allowed.
+func New(total int, label string) *Bar {
+	b := &Bar{
+		w:     logging.Stdout(),
+		label: label,
+		total: total,
+		live:  total > 0 && logging.Decorative() && styles.ShouldAnimate(),
+	}
+	b.mu.Lock()
+	b.draw()
+	b.mu.Unlock()
+	return b
+}
+
+// Step marks one step done and redraws the bar.
+func (b *Bar) Step() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done < b.total {
+		b.done++
+	}
+	b.draw()
+}
+
+// Say prints a progress line above the bar, with the same arguments and
+// fallback as logging.Say.
+func (b *Bar) Say(pretty, msg string, args ...any) {
+	if b == nil || !b.live {
+		logging.Say(pretty, msg, args...)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, _ = fmt.Fprint(b.w, "\r\033[K")
+	_, _ = fmt.Fprintln(b.w, pretty)
+	b.draw()
+}
+
+// Finish erases the bar. Call it once the work is done or has failed;
+// later calls are no-ops.
+func (b *Bar) Finish() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.live {
+		_, _ = fmt.Fprint(b.w, "\r\033[K")
+		b.live = false
+	}
+}
+
+// draw repaints the bar line. Callers hold b.mu.
+func (b *Bar) draw() {
+	if !b.live {
+		return
+	}
+	desc := fmt.Sprintf("%s %d/%d", b.label, b.done, b.total)
+	_, _ = fmt.Fprint(b.w, "\r\033[K"+styles.ProgressStep(b.done, b.total, desc))
+}
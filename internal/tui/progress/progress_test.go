@@ -0,0 +1,37 @@
+package progress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/internal/logging"
+)
+
+func TestBar_NotLiveFallsThrough(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := logging.Setup(logging.Options{Stdout: &stdout}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logging.Setup(logging.Options{}) })
+
+	// go test's stdout is not a terminal, so no bar is drawn.
+	b := New(2, "Writing")
+	b.Step()
+	b.Say("created a.md", "created", "path", "a.md")
+	b.Step()
+	b.Step() // past total: ignored
+	b.Finish()
+
+	if got := stdout.String(); got != "created a.md\n" {
+		t.Errorf("stdout = %q, want only the Say line", got)
+	}
+	if b.done != 2 {
+		t.Errorf("done = %d, want 2", b.done)
+	}
+}
+
+func TestBar_Nil(t *testing.T) {
+	var b *Bar
+	b.Step()
+	b.Finish()
+}
@@ -0,0 +1,111 @@
+// Package ailloy is the Go API for embedding ailloy: resolve a mold, render
+// its blanks, validate it, and plan or apply a cast, without shelling out to
+// the CLI. It runs the same pipelines as `ailloy forge`, `temper`, and
+// `cast`, and writes nothing to the terminal.
+//
+//	m, err := ailloy.Resolve(ctx, "github.com/acme/mold@v1", ailloy.ResolveOptions{})
+//	files, err := ailloy.RenderBlanks(m, ailloy.RenderOptions{Set: []string{"team=core"}})
+//
+// Filesystem paths (value files, local-path deps, cast destinations) are
+// relative to the process working directory, as they are for the CLI.
+package ailloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+
+	"github.com/nimble-giant/ailloy/internal/commands"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// Mold is a resolved mold or ingot, ready to render, temper, or cast.
+type Mold struct {
+	// Ref is the reference or directory the mold was loaded from.
+	Ref string
+	// Source is the foundry key persisted flux and overrides are filed
+	// under (host/owner/repo[//subpath]); empty for local molds.
+	Source string
+	// Tag and Commit identify the resolved revision of a remote mold.
+	Tag    string
+	Commit string
+
+	reader *blanks.MoldReader
+	remote bool
+}
+
+// FS returns the mold's files, rooted at the directory holding mold.yaml.
+func (m *Mold) FS() fs.FS { return m.reader.FS() }
+
+// Remote reports whether the mold came from a foundry.
+func (m *Mold) Remote() bool { return m.remote }
+
+// Manifest loads mold.yaml (or ingot.yaml).
+func (m *Mold) Manifest() (*mold.Mold, error) { return m.reader.LoadManifest() }
+
+// ResolveOptions configures Resolve. All fields are optional.
+type ResolveOptions struct {
+	// Offline serves the resolve from the foundry cache and fails if the
+	// cache is cold (see foundry.WithOffline).
+	Offline bool
+	// LockPath pins resolution to a lock file other than ./ailloy.lock.
+	LockPath string
+	// Logger receives non-fatal resolve warnings; nil discards them.
+	Logger *log.Logger
+}
+
+// Resolve loads the mold at ref: a remote reference
+// (host/owner/repo[@version][//subpath]) is fetched into the foundry cache,
+// anything else is treated as a local directory.
+func Resolve(_ context.Context, ref string, opts ResolveOptions) (*Mold, error) {
+	if ref == "" {
+		return nil, errors.New("mold reference is required")
+	}
+	if !foundry.IsRemoteReference(ref) {
+		return LoadMold(ref)
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	resolveOpts := []foundry.ResolveOption{foundry.WithLogger(logger)}
+	if opts.Offline {
+		resolveOpts = append(resolveOpts, foundry.WithOffline())
+	}
+	if opts.LockPath != "" {
+		resolveOpts = append(resolveOpts, foundry.WithLockPath(opts.LockPath))
+	}
+	fsys, result, err := foundry.ResolveWithMetadata(ref, resolveOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving remote mold: %w", err)
+	}
+	reader, err := commands.ComposeMoldReader(blanks.NewMoldReaderFromFS(fsys, result.Root), result.Ref.OverrideKey(), resolveOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Mold{
+		Ref:    ref,
+		Source: result.Ref.OverrideKey(),
+		Tag:    result.Resolved.Tag,
+		Commit: result.Resolved.Commit,
+		reader: reader,
+		remote: true,
+	}, nil
+}
+
+// LoadMold loads the mold in a local directory.
+func LoadMold(dir string) (*Mold, error) {
+	reader, err := blanks.NewMoldReaderFromPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if reader, err = commands.ComposeMoldReader(reader, ""); err != nil {
+		return nil, err
+	}
+	return &Mold{Ref: dir, reader: reader}, nil
+}
@@ -0,0 +1,155 @@
+package ailloy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testMold is a mold with one command blank, copied into a temp dir with
+// os.CopyFS by each test.
+var testMold = fstest.MapFS{
+	"mold.yaml":         {Data: []byte("apiVersion: v1\nkind: mold\nname: sdk-test\nversion: 1.0.0\n")},
+	"flux.yaml":         {Data: []byte("output:\n  commands: .claude/commands\nproject_name: default\n")},
+	"commands/hello.md": {Data: []byte("---\ndescription: Say hello\n---\nHello from {{project_name}}\n")},
+}
+
+func TestResolveLocal(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, testMold); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Resolve(context.Background(), dir, ResolveOptions{})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if m.Remote() || m.Source != "" {
+		t.Errorf("local mold reported remote: %+v", m)
+	}
+	manifest, err := m.Manifest()
+	if err != nil {
+		t.Fatalf("Manifest: %v", err)
+	}
+	if manifest.Name != "sdk-test" {
+		t.Errorf("Name = %q, want sdk-test", manifest.Name)
+	}
+
+	if _, err := Resolve(context.Background(), "", ResolveOptions{}); err == nil {
+		t.Error("Resolve with empty ref: want error")
+	}
+	if _, err := LoadMold(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadMold of missing dir: want error")
+	}
+}
+
+func TestRenderBlanks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, testMold); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadMold(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+
+	files, err := RenderBlanks(m, RenderOptions{Values: map[string]any{"project_name": "Atlas"}})
+	if err != nil {
+		t.Fatalf("RenderBlanks: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != ".claude/commands/hello.md" || files[0].Src != "commands/hello.md" {
+		t.Fatalf("files = %+v", files)
+	}
+	if !strings.Contains(string(files[0].Content), "Hello from Atlas") {
+		t.Errorf("content = %q", files[0].Content)
+	}
+
+	files, err = RenderBlanks(m, RenderOptions{
+		Values: map[string]any{"project_name": "Atlas"},
+		Set:    []string{"project_name=Borealis"},
+	})
+	if err != nil {
+		t.Fatalf("RenderBlanks: %v", err)
+	}
+	if !strings.Contains(string(files[0].Content), "Hello from Borealis") {
+		t.Errorf("Set should override Values, got %q", files[0].Content)
+	}
+	if _, err := os.Stat(".claude"); !os.IsNotExist(err) {
+		t.Error("RenderBlanks wrote to disk")
+	}
+}
+
+func TestTemper(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, testMold); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadMold(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := Temper(m); res.HasErrors() {
+		t.Fatalf("valid mold has errors: %+v", res.Errors())
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "mold.yaml"), []byte("apiVersion: v1\nkind: mold\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if res := Temper(m); !res.HasErrors() {
+		t.Error("mold without a name should fail temper")
+	}
+}
+
+func TestPlanAndApplyCast(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, testMold); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadMold(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	opts := CastOptions{Set: []string{"project_name=Atlas"}}
+
+	plan, err := PlanCast(context.Background(), m, opts)
+	if err != nil {
+		t.Fatalf("PlanCast: %v", err)
+	}
+	if len(plan.Files) != 1 {
+		t.Fatalf("plan = %+v", plan.Files)
+	}
+	pf := plan.Files[0]
+	if pf.Path != ".claude/commands/hello.md" || pf.Exists || pf.Unchanged {
+		t.Errorf("planned file = %+v", pf)
+	}
+	if _, err := os.Stat(".claude"); !os.IsNotExist(err) {
+		t.Fatal("PlanCast wrote to disk")
+	}
+
+	res, err := ApplyCast(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyCast: %v", err)
+	}
+	if res.MoldName != "sdk-test" {
+		t.Errorf("result = %+v", res)
+	}
+	got, err := os.ReadFile(pf.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(pf.Content) {
+		t.Errorf("cast wrote %q, plan had %q", got, pf.Content)
+	}
+
+	plan, err = PlanCast(context.Background(), m, opts)
+	if err != nil {
+		t.Fatalf("PlanCast after apply: %v", err)
+	}
+	if pf := plan.Files[0]; !pf.Exists || !pf.Unchanged {
+		t.Errorf("re-plan after apply = %+v, want exists and unchanged", pf)
+	}
+}
@@ -0,0 +1,94 @@
+package ailloy
+
+import (
+	"context"
+
+	"github.com/nimble-giant/ailloy/internal/commands"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+)
+
+// CastOptions configures PlanCast and ApplyCast. All fields are optional.
+type CastOptions struct {
+	Global        bool     // install under $HOME instead of the working directory
+	WithWorkflows bool     // include .github/ workflow blanks
+	ValueFiles    []string // flux value files, like -f
+	Set           []string // key=value overrides, like --set
+	Profile       string   // output profile declared by the mold
+	// Frozen fails instead of installing declared deps missing from
+	// .ailloy/, like --frozen.
+	Frozen bool
+	// ForceReplaceOnParseError replaces merge destinations that can't be
+	// parsed instead of failing.
+	ForceReplaceOnParseError bool
+}
+
+func (o CastOptions) core() commands.CastOptions {
+	return commands.CastOptions{
+		Global:                   o.Global,
+		WithWorkflows:            o.WithWorkflows,
+		ValueFiles:               o.ValueFiles,
+		SetOverrides:             o.Set,
+		Profile:                  o.Profile,
+		Frozen:                   o.Frozen,
+		ForceReplaceOnParseError: o.ForceReplaceOnParseError,
+	}
+}
+
+// PlannedFile is one file a cast would install.
+type PlannedFile struct {
+	Path     string // destination; absolute under $HOME for global casts
+	Src      string // source path within the mold
+	Strategy string // "", "replace", "merge", or "append"
+	// Content is the rendered blank. For merge and append it is the
+	// fragment combined with the existing file, not the final file.
+	Content []byte
+	// Exists reports whether Path is already on disk; Unchanged, whether a
+	// replace would leave it byte-for-byte the same.
+	Exists    bool
+	Unchanged bool
+}
+
+// Plan is the outcome of PlanCast. Pass it to ApplyCast to install it.
+type Plan struct {
+	Mold  *Mold
+	Files []PlannedFile
+
+	opts CastOptions
+}
+
+// PlanCast renders what casting m would install, without writing anything.
+// Declared ingot and ore deps are not installed while planning; ore
+// overlays already under .ailloy/ are layered in.
+func PlanCast(ctx context.Context, m *Mold, opts CastOptions) (*Plan, error) {
+	planned, err := commands.PlanCastMold(ctx, m.reader, m.Source, opts.core())
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Mold: m, opts: opts, Files: make([]PlannedFile, 0, len(planned))}
+	for _, f := range planned {
+		plan.Files = append(plan.Files, PlannedFile(f))
+	}
+	return plan, nil
+}
+
+// CastResult summarizes an applied cast.
+type CastResult struct {
+	MoldName string
+	Source   string
+	// Files lists the installed files with their hashes. It is filled for
+	// remote molds only, whose casts are recorded in .ailloy/installed.yaml.
+	Files []foundry.InstalledFile
+}
+
+// ApplyCast performs the cast plan was made for: deps are installed, blanks
+// written, and .ailloy/state.yaml and installed.yaml updated, as `ailloy
+// cast` does. The mold is re-resolved from plan.Mold.Ref, so a floating
+// remote ref may pick up a newer revision than the one planned; pin refs
+// (or use a lock file) when that matters.
+func ApplyCast(ctx context.Context, plan *Plan) (*CastResult, error) {
+	res, err := commands.CastMold(ctx, plan.Mold.Ref, plan.opts.core())
+	if err != nil {
+		return nil, err
+	}
+	return &CastResult{MoldName: res.MoldName, Source: res.Source, Files: res.FilesCast}, nil
+}
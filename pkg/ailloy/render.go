@@ -0,0 +1,54 @@
+package ailloy
+
+import (
+	"log"
+
+	"github.com/nimble-giant/ailloy/internal/commands"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// RenderOptions layers flux over the mold's defaults, in this order. All
+// fields are optional.
+type RenderOptions struct {
+	ValueFiles []string       // flux value files, like -f
+	Values     map[string]any // top-level flux values, applied after ValueFiles
+	Set        []string       // key=value overrides, like --set
+	Profile    string         // output profile declared by the mold
+	// Logger receives flux validation and skipped-render warnings; nil
+	// discards them.
+	Logger *log.Logger
+}
+
+// File is a rendered blank.
+type File struct {
+	Path     string // destination relative to the cast root
+	Src      string // source path within the mold
+	Strategy string // "", "replace", "merge", or "append"
+	Content  []byte
+}
+
+// RenderBlanks renders every output of m the way `ailloy forge` does. Ore
+// deps are resolved without installing them and nothing is written.
+func RenderBlanks(m *Mold, opts RenderOptions) ([]File, error) {
+	_, rendered, err := commands.RenderMold(m.reader, m.remote, commands.RenderOptions{
+		ValueFiles:   opts.ValueFiles,
+		Values:       opts.Values,
+		SetOverrides: opts.Set,
+		Profile:      opts.Profile,
+		Logger:       opts.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := make([]File, 0, len(rendered))
+	for _, r := range rendered {
+		files = append(files, File{Path: r.Dest, Src: r.Src, Strategy: r.Strategy, Content: r.Content})
+	}
+	return files, nil
+}
+
+// Temper validates m the way `ailloy temper` does. Check HasErrors on the
+// result; warnings do not make a mold invalid.
+func Temper(m *Mold) *mold.TemperResult {
+	return commands.TemperMold(m.reader, m.remote)
+}
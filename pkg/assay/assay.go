@@ -179,7 +179,7 @@ func Assay(rootDir string, cfg *Config) (*AssayResult, error) {
 			}
 		}
 
-		diags := rule.Check(ctx)
+		diags := cfg.ApplyRuleSeverity(rule.Name(), rule.Check(ctx))
 		result.Diagnostics = append(result.Diagnostics, diags...)
 	}
 
@@ -1,11 +1,16 @@
 package assay
 
 import (
+	"fmt"
+	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"sort"
 
 	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/yamlcheck"
 )
 
 // Config holds assay configuration loaded from .ailloyrc.yaml.
@@ -17,13 +22,19 @@ type Config struct {
 
 // RuleConfig holds per-rule configuration.
 type RuleConfig struct {
-	Enabled *bool          `yaml:"enabled"`
-	Options map[string]any `yaml:"options,omitempty"`
+	Enabled *bool `yaml:"enabled"`
+	// Severity overrides the severity of every finding the rule reports:
+	// "error", "warning", or "suggestion". Empty keeps the rule's own.
+	Severity string         `yaml:"severity,omitempty"`
+	Options  map[string]any `yaml:"options,omitempty"`
 }
 
 // ailloyRC represents the top-level .ailloyrc.yaml structure.
 type ailloyRC struct {
 	Assay Config `yaml:"assay"`
+	// Workspace marks the directory as a workspace root; see
+	// scope.WorkspaceDirs.
+	Workspace bool `yaml:"workspace,omitempty"`
 }
 
 // IsRuleEnabled returns whether a rule is enabled in the config.
@@ -42,6 +53,45 @@ func (c *Config) IsRuleEnabled(ruleName string) bool {
 	return *rc.Enabled
 }
 
+// RuleSeverity returns the severity configured for a rule's findings. The
+// bool is false when the config leaves the rule's own severity in place.
+func (c *Config) RuleSeverity(ruleName string) (mold.DiagSeverity, bool) {
+	if c == nil || c.Rules == nil {
+		return 0, false
+	}
+	rc, ok := c.Rules[ruleName]
+	if !ok || rc.Severity == "" {
+		return 0, false
+	}
+	sev, err := parseSeverity(rc.Severity)
+	return sev, err == nil
+}
+
+// ApplyRuleSeverity rewrites the severity of diags, reported by ruleName,
+// to the configured one, if any, and returns diags.
+func (c *Config) ApplyRuleSeverity(ruleName string, diags []mold.Diagnostic) []mold.Diagnostic {
+	if sev, ok := c.RuleSeverity(ruleName); ok {
+		for i := range diags {
+			diags[i].Severity = sev
+		}
+	}
+	return diags
+}
+
+// parseSeverity parses a configured severity name; "" parses as an error
+// severity but callers treat it as unset.
+func parseSeverity(s string) (mold.DiagSeverity, error) {
+	switch s {
+	case "", "error":
+		return mold.SeverityError, nil
+	case "warning":
+		return mold.SeverityWarning, nil
+	case "suggestion":
+		return mold.SeveritySuggestion, nil
+	}
+	return 0, fmt.Errorf("invalid severity %q: use error, warning, or suggestion", s)
+}
+
 // RuleOption returns a rule-specific option value, or the fallback if not set.
 func (c *Config) RuleOption(ruleName, optionName string, fallback any) any {
 	if c == nil || c.Rules == nil {
@@ -86,10 +136,64 @@ func LoadConfig(rootDir string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &rc); err != nil {
 		return nil, err
 	}
+	if unknown, err := yamlcheck.UnknownFields(data, &rc); err == nil {
+		for _, f := range unknown {
+			log.Printf("warning: %s:%d: %s is ignored", path, f.Line, f)
+		}
+	}
+	for name, rule := range rc.Assay.Rules {
+		if _, err := parseSeverity(rule.Severity); err != nil {
+			return nil, fmt.Errorf("assay.rules.%s.severity: %w", name, err)
+		}
+	}
 
 	return &rc.Assay, nil
 }
 
+// LoadWorkspaceConfig layers the .ailloyrc.yaml of each directory in dirs,
+// root first (see scope.WorkspaceDirs), so a monorepo package inherits the
+// root's settings and overrides them locally. A rule's enabled flag and
+// each of its options are taken from the nearest file that sets them,
+// ignore patterns accumulate, and the nearest platforms list wins.
+func LoadWorkspaceConfig(dirs []string) (*Config, error) {
+	merged := DefaultConfig()
+	for _, dir := range dirs {
+		cfg, err := LoadConfig(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, ".ailloyrc.yaml"), err)
+		}
+		merged.merge(cfg)
+	}
+	return merged, nil
+}
+
+// merge layers o over c.
+func (c *Config) merge(o *Config) {
+	for name, rule := range o.Rules {
+		if c.Rules == nil {
+			c.Rules = make(map[string]RuleConfig)
+		}
+		base := c.Rules[name]
+		if rule.Enabled != nil {
+			base.Enabled = rule.Enabled
+		}
+		if rule.Severity != "" {
+			base.Severity = rule.Severity
+		}
+		if len(rule.Options) > 0 {
+			options := make(map[string]any, len(base.Options)+len(rule.Options))
+			maps.Copy(options, base.Options)
+			maps.Copy(options, rule.Options)
+			base.Options = options
+		}
+		c.Rules[name] = base
+	}
+	c.Ignore = append(c.Ignore, o.Ignore...)
+	if len(o.Platforms) > 0 {
+		c.Platforms = o.Platforms
+	}
+}
+
 // DefaultConfig returns a config with all rules enabled at default thresholds.
 func DefaultConfig() *Config {
 	return &Config{}
@@ -248,6 +352,23 @@ assay:
       enabled: true              # warn on backslash-style paths (e.g. scripts\helper.py) in markdown content
     name-gerund-form:
       enabled: true              # suggest gerund-form skill names (verb + -ing) over pure-noun names
+    heading-structure:
+      enabled: true              # warn when headings skip a level or have no text
+    broken-links:
+      enabled: true              # warn on relative links to files that don't exist
+    long-lines:
+      enabled: true
+      options:
+        max-length: 500          # warn on prose lines longer than this many characters
+    todo-markers:
+      enabled: true
+      # options:
+      #   markers: [TODO, FIXME, XXX]  # words reported as leftover markers
+    template-artifacts:
+      enabled: true
+      # severity: error          # any rule's findings can be raised or lowered: error, warning, suggestion
+    command-description:
+      enabled: true              # warn when a slash command has no description: front matter
   ignore: []
     # - "vendor/**"
     # - ".claude/rules/generated-*.md"
@@ -3,7 +3,10 @@ package assay
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
 func TestLoadConfig_NoFile(t *testing.T) {
@@ -207,3 +210,99 @@ func containsSubstr(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadWorkspaceConfig_Layers(t *testing.T) {
+	root := t.TempDir()
+	pkg := filepath.Join(root, "packages", "web")
+	if err := os.MkdirAll(pkg, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	rootRC := `
+assay:
+  rules:
+    line-count:
+      options:
+        max-lines: 200
+        warn-lines: 100
+    structure:
+      enabled: false
+  ignore:
+    - "vendor/**"
+  platforms:
+    - claude
+`
+	pkgRC := `
+assay:
+  rules:
+    line-count:
+      options:
+        max-lines: 400
+    structure:
+      enabled: true
+  ignore:
+    - "dist/**"
+`
+	if err := os.WriteFile(filepath.Join(root, ".ailloyrc.yaml"), []byte(rootRC), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pkg, ".ailloyrc.yaml"), []byte(pkgRC), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadWorkspaceConfig([]string{root, filepath.Join(root, "packages"), pkg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsRuleEnabled("structure") {
+		t.Error("package should re-enable structure")
+	}
+	if got := cfg.RuleOption("line-count", "max-lines", 0); got != uint64(400) {
+		t.Errorf("max-lines = %v (%T), want 400", got, got)
+	}
+	if got := cfg.RuleOption("line-count", "warn-lines", 0); got != uint64(100) {
+		t.Errorf("warn-lines = %v, want inherited 100", got)
+	}
+	if len(cfg.Ignore) != 2 {
+		t.Errorf("ignore = %v, want root and package patterns", cfg.Ignore)
+	}
+	if len(cfg.Platforms) != 1 || cfg.Platforms[0] != "claude" {
+		t.Errorf("platforms = %v, want inherited [claude]", cfg.Platforms)
+	}
+}
+
+func TestLoadConfig_RuleSeverity(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+assay:
+  rules:
+    todo-markers:
+      severity: error
+    structure:
+      enabled: true
+`
+	if err := os.WriteFile(filepath.Join(dir, ".ailloyrc.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sev, ok := cfg.RuleSeverity("todo-markers"); !ok || sev != mold.SeverityError {
+		t.Errorf("RuleSeverity(todo-markers) = %v, %v; want error, true", sev, ok)
+	}
+	if _, ok := cfg.RuleSeverity("structure"); ok {
+		t.Error("structure has no severity override")
+	}
+	diags := cfg.ApplyRuleSeverity("todo-markers", []mold.Diagnostic{{Severity: mold.SeverityWarning}})
+	if diags[0].Severity != mold.SeverityError {
+		t.Errorf("severity = %v, want error", diags[0].Severity)
+	}
+
+	bad := "assay:\n  rules:\n    todo-markers:\n      severity: fatal\n"
+	if err := os.WriteFile(filepath.Join(dir, ".ailloyrc.yaml"), []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(dir); err == nil || !strings.Contains(err.Error(), "assay.rules.todo-markers.severity") {
+		t.Errorf("LoadConfig with invalid severity: err = %v", err)
+	}
+}
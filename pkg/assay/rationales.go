@@ -35,6 +35,12 @@ var ruleRationales = map[string]string{
 	"reference-depth":             "When SKILL.md links to a file that links to another file, Claude may only partial-read the nested file. Keep all reference files one level deep from SKILL.md so they are read in full. See: https://platform.claude.com/docs/en/agents-and-tools/agent-skills/best-practices#avoid-deeply-nested-references",
 	"windows-paths":               "Windows-style backslash paths (e.g., `scripts\\helper.py`) cause errors on Unix systems where skills typically run. Use forward slashes for cross-platform compatibility. See: https://platform.claude.com/docs/en/agents-and-tools/agent-skills/best-practices#avoid-windows-style-paths",
 	"name-gerund-form":            "Gerund-form names (verb + -ing, e.g., `processing-pdfs`) trigger more reliably than pure noun names. Action-verb names (e.g., `process-pdfs`) and noun-phrase names with `-ing` (e.g., `pdf-processing`) are also accepted. See: https://platform.claude.com/docs/en/agents-and-tools/agent-skills/best-practices#naming-conventions",
+	"heading-structure":           "Models use the heading hierarchy to understand how instructions relate. A skipped level (## straight to ####) or an empty heading breaks that outline and makes sections harder to locate.",
+	"broken-links":                "A relative link to a missing file sends the model looking for context that isn't there. Links usually break when files are renamed or a blank's output path changes.",
+	"long-lines":                  "Very long lines are usually pasted blobs or unwrapped generated text. They cost context without structure and are hard to review in diffs.",
+	"todo-markers":                "Leftover TODO and FIXME markers are read as part of the instructions. The model may act on them, or treat the surrounding guidance as unfinished.",
+	"template-artifacts":          "Stray {{ or }} in rendered output means a blank didn't render as intended, e.g. unbalanced delimiters or a file cast without template processing. The model sees raw template syntax instead of your values.",
+	"command-description":         "Agents list slash commands by their description. Without one the command appears blank in pickers and is never chosen on its own.",
 }
 
 // RuleRationale returns the educational rationale for a rule, or empty string if none is defined.
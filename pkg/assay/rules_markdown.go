@@ -0,0 +1,412 @@
+package assay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func init() {
+	Register(&headingStructureRule{})
+	Register(&brokenLinksRule{})
+	Register(&longLinesRule{})
+	Register(&todoMarkersRule{})
+	Register(&templateArtifactsRule{})
+	Register(&commandDescriptionRule{})
+}
+
+// mdLine is one line of a markdown file, numbered from 1. Prose is false
+// inside front matter and fenced code blocks, where markdown rules don't
+// apply.
+type mdLine struct {
+	Num   int
+	Text  string
+	Prose bool
+}
+
+// markdownLines splits content into lines, marking which are prose.
+func markdownLines(content []byte) []mdLine {
+	var lines []mdLine
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	inFrontmatter, fence := false, ""
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		prose := false
+		switch {
+		case n == 1 && trimmed == "---":
+			inFrontmatter = true
+		case inFrontmatter:
+			if trimmed == "---" {
+				inFrontmatter = false
+			}
+		case fence != "":
+			// Only a bare run of at least as many fence characters closes
+			// the block; ```bash inside it is content.
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+		default:
+			prose = true
+		}
+		lines = append(lines, mdLine{Num: n, Text: text, Prose: prose})
+	}
+	return lines
+}
+
+// isMarkdown reports whether path is a markdown file (Cursor's .mdc rules
+// included).
+func isMarkdown(path string) bool {
+	switch filepath.Ext(path) {
+	case ".md", ".mdc":
+		return true
+	}
+	return false
+}
+
+// inlineCodeRegex matches `code spans`, which hold literal text.
+var inlineCodeRegex = regexp.MustCompile("`+[^`]*`+")
+
+// stripInlineCode blanks out code spans in a prose line.
+func stripInlineCode(line string) string {
+	return inlineCodeRegex.ReplaceAllStringFunc(line, func(s string) string {
+		return strings.Repeat(" ", len(s))
+	})
+}
+
+// lineList formats line numbers for a message: "line 3", "lines 3, 17
+// and 40", with anything past the first five counted rather than listed.
+func lineList(nums []int) string {
+	const shown = 5
+	if len(nums) == 1 {
+		return fmt.Sprintf("line %d", nums[0])
+	}
+	parts := make([]string, 0, min(len(nums), shown))
+	for _, n := range nums[:min(len(nums), shown)] {
+		parts = append(parts, fmt.Sprint(n))
+	}
+	if extra := len(nums) - shown; extra > 0 {
+		return "lines " + strings.Join(parts, ", ") + fmt.Sprintf(" and %d more", extra)
+	}
+	return "lines " + strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// atxHeadingRegex matches an ATX heading, capturing its marker and text.
+var atxHeadingRegex = regexp.MustCompile(`^(#{1,6})(?:\s+(.*?))?\s*#*\s*$`)
+
+// headingStructureRule warns when headings skip a level (## followed by
+// ####) or have no text.
+type headingStructureRule struct{}
+
+func (r *headingStructureRule) Name() string                       { return "heading-structure" }
+func (r *headingStructureRule) DefaultSeverity() mold.DiagSeverity { return mold.SeverityWarning }
+func (r *headingStructureRule) Platforms() []Platform              { return nil }
+
+func (r *headingStructureRule) Check(ctx *RuleContext) []mold.Diagnostic {
+	var diags []mold.Diagnostic
+	for _, f := range ctx.Files {
+		if !isMarkdown(f.Path) {
+			continue
+		}
+		prev := 0
+		for _, l := range markdownLines(f.Content) {
+			if !l.Prose {
+				continue
+			}
+			m := atxHeadingRegex.FindStringSubmatch(l.Text)
+			if m == nil {
+				continue
+			}
+			level := len(m[1])
+			switch {
+			case strings.TrimSpace(m[2]) == "":
+				diags = append(diags, mold.Diagnostic{
+					Severity: r.DefaultSeverity(),
+					Message:  fmt.Sprintf("line %d: empty heading", l.Num),
+					File:     f.Path,
+					Rule:     r.Name(),
+				})
+			case prev > 0 && level > prev+1:
+				diags = append(diags, mold.Diagnostic{
+					Severity: r.DefaultSeverity(),
+					Message:  fmt.Sprintf("line %d: heading level %d follows level %d; headings should nest one level at a time", l.Num, level, prev),
+					Tip:      fmt.Sprintf("use %s here, or add the missing level above it", strings.Repeat("#", prev+1)),
+					File:     f.Path,
+					Rule:     r.Name(),
+				})
+			}
+			prev = level
+		}
+	}
+	return diags
+}
+
+// inlineLinkRegex matches [text](target) and ![alt](target) links,
+// capturing the target without an optional "title".
+var inlineLinkRegex = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+["'(][^)]*)?\)`)
+
+// brokenLinksRule reports relative links whose target doesn't exist.
+// Links with a scheme (https:, mailto:), in-page anchors, and root-relative
+// paths are not checked.
+type brokenLinksRule struct{}
+
+func (r *brokenLinksRule) Name() string                       { return "broken-links" }
+func (r *brokenLinksRule) DefaultSeverity() mold.DiagSeverity { return mold.SeverityWarning }
+func (r *brokenLinksRule) Platforms() []Platform              { return nil }
+
+func (r *brokenLinksRule) Check(ctx *RuleContext) []mold.Diagnostic {
+	var diags []mold.Diagnostic
+	for _, f := range ctx.Files {
+		if !isMarkdown(f.Path) {
+			continue
+		}
+		for _, l := range markdownLines(f.Content) {
+			if !l.Prose {
+				continue
+			}
+			for _, m := range inlineLinkRegex.FindAllStringSubmatch(stripInlineCode(l.Text), -1) {
+				target, ok := localLinkTarget(m[1])
+				if !ok {
+					continue
+				}
+				if _, err := os.Stat(filepath.Join(ctx.RootDir, filepath.Dir(f.Path), filepath.FromSlash(target))); err == nil {
+					continue
+				}
+				diags = append(diags, mold.Diagnostic{
+					Severity: r.DefaultSeverity(),
+					Message:  fmt.Sprintf("line %d: link target %q does not exist", l.Num, m[1]),
+					File:     f.Path,
+					Rule:     r.Name(),
+				})
+			}
+		}
+	}
+	return diags
+}
+
+// localLinkTarget returns the file path a link points at, without its
+// #fragment or ?query. The bool is false for links the rule doesn't check.
+func localLinkTarget(target string) (string, bool) {
+	if strings.Contains(target, ":") || strings.HasPrefix(target, "#") ||
+		strings.HasPrefix(target, "/") || strings.Contains(target, "{{") {
+		return "", false
+	}
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	return target, target != ""
+}
+
+// defaultMaxLineLength is the long-lines threshold, in characters.
+const defaultMaxLineLength = 500
+
+// longLinesRule warns about prose lines past a length threshold: they're
+// usually pasted blobs or unwrapped generated text that cost context
+// without structure.
+type longLinesRule struct{}
+
+func (r *longLinesRule) Name() string                       { return "long-lines" }
+func (r *longLinesRule) DefaultSeverity() mold.DiagSeverity { return mold.SeverityWarning }
+func (r *longLinesRule) Platforms() []Platform              { return nil }
+
+func (r *longLinesRule) Check(ctx *RuleContext) []mold.Diagnostic {
+	maxLength := defaultMaxLineLength
+	switch n := ctx.Config.RuleOption(r.Name(), "max-length", nil).(type) {
+	case int:
+		maxLength = n
+	case uint64:
+		if n <= uint64(maxInt) {
+			maxLength = int(n)
+		}
+	case float64:
+		maxLength = int(n)
+	}
+
+	var diags []mold.Diagnostic
+	for _, f := range ctx.Files {
+		if !isMarkdown(f.Path) {
+			continue
+		}
+		var long []int
+		longest := 0
+		for _, l := range markdownLines(f.Content) {
+			if n := utf8.RuneCountInString(l.Text); l.Prose && n > maxLength {
+				long = append(long, l.Num)
+				longest = max(longest, n)
+			}
+		}
+		if len(long) == 0 {
+			continue
+		}
+		diags = append(diags, mold.Diagnostic{
+			Severity: r.DefaultSeverity(),
+			Message:  fmt.Sprintf("%s longer than %d characters (longest: %d)", lineList(long), maxLength, longest),
+			Tip:      "break long lines into paragraphs or lists; move bulk content into a referenced file",
+			File:     f.Path,
+			Rule:     r.Name(),
+		})
+	}
+	return diags
+}
+
+// defaultTodoMarkers are the markers todo-markers looks for.
+var defaultTodoMarkers = []string{"TODO", "FIXME"}
+
+// todoMarkersRule warns about leftover TODO/FIXME markers in prose: the
+// model reads them as instructions, or as a sign the file is unfinished.
+type todoMarkersRule struct{}
+
+func (r *todoMarkersRule) Name() string                       { return "todo-markers" }
+func (r *todoMarkersRule) DefaultSeverity() mold.DiagSeverity { return mold.SeverityWarning }
+func (r *todoMarkersRule) Platforms() []Platform              { return nil }
+
+func (r *todoMarkersRule) Check(ctx *RuleContext) []mold.Diagnostic {
+	markers := defaultTodoMarkers
+	if configured, ok := ctx.Config.RuleOption(r.Name(), "markers", nil).([]any); ok {
+		markers = nil
+		for _, m := range configured {
+			if s, ok := m.(string); ok && s != "" {
+				markers = append(markers, regexp.QuoteMeta(s))
+			}
+		}
+	}
+	if len(markers) == 0 {
+		return nil
+	}
+	markerRegex := regexp.MustCompile(`\b(` + strings.Join(markers, "|") + `)\b`)
+
+	var diags []mold.Diagnostic
+	for _, f := range ctx.Files {
+		if !isMarkdown(f.Path) {
+			continue
+		}
+		var found []int
+		for _, l := range markdownLines(f.Content) {
+			if l.Prose && markerRegex.MatchString(stripInlineCode(l.Text)) {
+				found = append(found, l.Num)
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+		diags = append(diags, mold.Diagnostic{
+			Severity: r.DefaultSeverity(),
+			Message:  fmt.Sprintf("leftover %s marker on %s", strings.Join(markers, "/"), lineList(found)),
+			File:     f.Path,
+			Rule:     r.Name(),
+		})
+	}
+	return diags
+}
+
+// templateArtifactRegex finds template delimiters; a match preceded by $
+// is a GitHub Actions ${{ expression }} and is skipped by the rule.
+var templateArtifactRegex = regexp.MustCompile(`\{\{|\}\}`)
+
+// templateArtifactsRule reports {{ or }} left in rendered prose — a sign a
+// blank didn't render (unbalanced braces, a file cast without processing).
+// Code spans and fenced blocks, where template syntax is often quoted, and
+// GitHub Actions ${{ }} expressions are skipped.
+type templateArtifactsRule struct{}
+
+func (r *templateArtifactsRule) Name() string                       { return "template-artifacts" }
+func (r *templateArtifactsRule) DefaultSeverity() mold.DiagSeverity { return mold.SeverityWarning }
+func (r *templateArtifactsRule) Platforms() []Platform              { return nil }
+
+func (r *templateArtifactsRule) Check(ctx *RuleContext) []mold.Diagnostic {
+	var diags []mold.Diagnostic
+	for _, f := range ctx.Files {
+		if !isMarkdown(f.Path) {
+			continue
+		}
+		var found []int
+		for _, l := range markdownLines(f.Content) {
+			if l.Prose && hasTemplateArtifact(stripInlineCode(l.Text)) {
+				found = append(found, l.Num)
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+		diags = append(diags, mold.Diagnostic{
+			Severity: r.DefaultSeverity(),
+			Message:  fmt.Sprintf("unrendered template delimiters ({{ or }}) on %s", lineList(found)),
+			Tip:      "check the blank renders (ailloy forge), or quote literal braces in a code span",
+			File:     f.Path,
+			Rule:     r.Name(),
+		})
+	}
+	return diags
+}
+
+// hasTemplateArtifact reports whether line holds {{ or }} outside a
+// ${{ }} expression.
+func hasTemplateArtifact(line string) bool {
+	depth := 0 // open ${{ expressions
+	for _, loc := range templateArtifactRegex.FindAllStringIndex(line, -1) {
+		switch {
+		case line[loc[0]] == '{' && loc[0] > 0 && line[loc[0]-1] == '$':
+			depth++
+		case line[loc[0]] == '}' && depth > 0:
+			depth--
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// commandDescriptionRule warns when a slash command has no description in
+// its front matter: agents list commands by description, and without one
+// the command shows up blank in pickers and is never chosen on its own.
+type commandDescriptionRule struct{}
+
+func (r *commandDescriptionRule) Name() string                       { return "command-description" }
+func (r *commandDescriptionRule) DefaultSeverity() mold.DiagSeverity { return mold.SeverityWarning }
+func (r *commandDescriptionRule) Platforms() []Platform              { return []Platform{PlatformClaude} }
+
+func (r *commandDescriptionRule) Check(ctx *RuleContext) []mold.Diagnostic {
+	var diags []mold.Diagnostic
+	for _, f := range ctx.Files {
+		if f.Platform != PlatformClaude || filepath.Ext(f.Path) != ".md" {
+			continue
+		}
+		isStandardCmd := filepath.Dir(f.Path) == filepath.Join(".claude", "commands")
+		isPluginCmd := f.PluginDir != "" && isUnderPluginSubdir(f.Path, f.PluginDir, "commands")
+		if !isStandardCmd && !isPluginCmd {
+			continue
+		}
+		var fm map[string]any
+		if raw := extractFrontmatter(f.Content); raw != nil {
+			if err := yaml.Unmarshal(raw, &fm); err != nil {
+				continue // reported by command-frontmatter
+			}
+		}
+		if s, ok := fm["description"].(string); ok && strings.TrimSpace(s) != "" {
+			continue
+		}
+		diags = append(diags, mold.Diagnostic{
+			Severity: r.DefaultSeverity(),
+			Message:  "command has no description: front matter",
+			Tip:      "add front matter at the top of the file, e.g. `description: Review the open pull request`",
+			File:     f.Path,
+			Rule:     r.Name(),
+		})
+	}
+	return diags
+}
@@ -0,0 +1,176 @@
+package assay
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestHeadingStructureRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantDiag string
+	}{
+		{"nested one level at a time", "# Title\n## A\n### B\n## C\n", ""},
+		{"skipped level", "# Title\n### Deep\n", "heading level 3 follows level 1"},
+		{"empty heading", "# Title\n##\n", "empty heading"},
+		{"code fence ignored", "# Title\n```sh\n#### comment\n```\n", ""},
+		{"front matter ignored", "---\n# yaml comment\n---\n## Section\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &RuleContext{
+				Files:  []DetectedFile{{Path: "AGENTS.md", Content: []byte(tt.content)}},
+				Config: DefaultConfig(),
+			}
+			diags := (&headingStructureRule{}).Check(ctx)
+			checkSingleDiag(t, diags, tt.wantDiag)
+		})
+	}
+}
+
+func TestBrokenLinksRule(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docs", "style guide.md"), []byte("# Style\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		wantDiag string
+	}{
+		{"existing file", "See [style](docs/style%20guide.md#naming).\n", ""},
+		{"missing file", "See [arch](docs/architecture.md).\n", `"docs/architecture.md" does not exist`},
+		{"missing image", "![diagram](img/flow.png)\n", `"img/flow.png" does not exist`},
+		{"urls and anchors skipped", "[a](https://example.com) [b](#usage) [c](mailto:x@y.z) [d](/abs.md)\n", ""},
+		{"code skipped", "`[x](missing.md)`\n```\n[y](missing.md)\n```\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &RuleContext{
+				RootDir: dir,
+				Files:   []DetectedFile{{Path: "AGENTS.md", Content: []byte(tt.content)}},
+				Config:  DefaultConfig(),
+			}
+			diags := (&brokenLinksRule{}).Check(ctx)
+			checkSingleDiag(t, diags, tt.wantDiag)
+		})
+	}
+}
+
+func TestLongLinesRule(t *testing.T) {
+	long := strings.Repeat("word ", 120) // 600 characters
+	content := "# Title\n" + long + "\n```\n" + long + "\n```\n" + long + "\n"
+
+	ctx := &RuleContext{
+		Files:  []DetectedFile{{Path: "CLAUDE.md", Content: []byte(content)}},
+		Config: DefaultConfig(),
+	}
+	diags := (&longLinesRule{}).Check(ctx)
+	checkSingleDiag(t, diags, "lines 2 and 6 longer than 500 characters (longest: 600)")
+
+	ctx.Config = &Config{Rules: map[string]RuleConfig{
+		"long-lines": {Options: map[string]any{"max-length": uint64(1000)}},
+	}}
+	checkSingleDiag(t, (&longLinesRule{}).Check(ctx), "")
+}
+
+func TestTodoMarkersRule(t *testing.T) {
+	content := "# Title\nTODO: write this\nRun `make todo` and fix FIXMEs.\n```\n// FIXME in code\n```\nXXX later\n"
+	ctx := &RuleContext{
+		Files:  []DetectedFile{{Path: "AGENTS.md", Content: []byte(content)}},
+		Config: DefaultConfig(),
+	}
+	checkSingleDiag(t, (&todoMarkersRule{}).Check(ctx), "leftover TODO/FIXME marker on line 2")
+
+	ctx.Config = &Config{Rules: map[string]RuleConfig{
+		"todo-markers": {Options: map[string]any{"markers": []any{"XXX"}}},
+	}}
+	checkSingleDiag(t, (&todoMarkersRule{}).Check(ctx), "leftover XXX marker on line 7")
+}
+
+func TestTemplateArtifactsRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantDiag string
+	}{
+		{"rendered", "# Title\nUse acme/widgets.\n", ""},
+		{"stray delimiter", "# Title\nUse {{ .repo.name }.\n", "on line 2"},
+		{"closing brace only", "# Title\nDone }}\n", "on line 2"},
+		{"github expression", "Set `${{ secrets.TOKEN }}` or ${{ github.ref }}.\n", ""},
+		{"quoted in code", "Write `{{ .name }}`.\n```\n{{ range . }}\n```\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &RuleContext{
+				Files:  []DetectedFile{{Path: "AGENTS.md", Content: []byte(tt.content)}},
+				Config: DefaultConfig(),
+			}
+			checkSingleDiag(t, (&templateArtifactsRule{}).Check(ctx), tt.wantDiag)
+		})
+	}
+}
+
+func TestCommandDescriptionRule(t *testing.T) {
+	cmdPath := filepath.Join(".claude", "commands", "review.md")
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		wantDiag bool
+	}{
+		{"has description", cmdPath, "---\ndescription: Review the open pull request\n---\n# Review\n", false},
+		{"no front matter", cmdPath, "# Review\n", true},
+		{"empty description", cmdPath, "---\ndescription: \"\"\n---\n", true},
+		{"not a command", "CLAUDE.md", "# Project\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &RuleContext{
+				Files:  []DetectedFile{{Path: tt.path, Platform: PlatformClaude, Content: []byte(tt.content)}},
+				Config: DefaultConfig(),
+			}
+			diags := (&commandDescriptionRule{}).Check(ctx)
+			if got := len(diags) > 0; got != tt.wantDiag {
+				t.Errorf("got diagnostics %v, want any: %v", diags, tt.wantDiag)
+			}
+		})
+	}
+}
+
+// checkSingleDiag fails unless diags is empty (want == "") or holds one
+// diagnostic whose message contains want.
+func checkSingleDiag(t *testing.T, diags []mold.Diagnostic, want string) {
+	t.Helper()
+	if want == "" {
+		if len(diags) > 0 {
+			t.Errorf("expected no diagnostic, got: %v", diags)
+		}
+		return
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, want) {
+		t.Errorf("expected one diagnostic containing %q, got: %v", want, diags)
+	}
+}
+
+func TestMarkdownLines_Fences(t *testing.T) {
+	content := "intro\n````markdown\n```bash\nnested\n```\n````\nafter\n~~~\ncode\n~~~\n"
+	var prose []string
+	for _, l := range markdownLines([]byte(content)) {
+		if l.Prose {
+			prose = append(prose, l.Text)
+		}
+	}
+	if got := strings.Join(prose, ","); got != "intro,after" {
+		t.Errorf("prose lines = %q, want intro,after", got)
+	}
+}
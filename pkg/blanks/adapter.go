@@ -0,0 +1,289 @@
+package blanks
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/nimble-giant/ailloy/pkg/merge"
+)
+
+// RenderedFile is a single flux-rendered blank, identified by its intended
+// cast destination (e.g. ".claude/commands/foo.md").
+type RenderedFile struct {
+	CastDest string
+	Content  []byte
+}
+
+// OutputAdapter writes rendered blanks in one AI tool's native layout.
+// Adapters register by name with RegisterOutputAdapter; `cast --to <name>`
+// and `plugin generate --format <name>` look them up, so supporting a new
+// tool only takes a new adapter.
+//
+// Every rendered blank goes through MapDestination and, when mapped,
+// TransformContent; the results are written, then PostInstall runs once.
+type OutputAdapter interface {
+	// Name is the format name users select the adapter by (e.g. "cursor").
+	Name() string
+	// MapDestination maps a blank's slash-separated cast destination to the
+	// project-relative path the tool reads it from. ok is false for blanks
+	// the tool has no place for, or that PostInstall handles.
+	MapDestination(dest string) (mapped string, ok bool)
+	// TransformContent converts a mapped blank's rendered content into the
+	// tool's format. dest is the original cast destination.
+	TransformContent(dest string, content []byte) ([]byte, error)
+	// PostInstall runs after the mapped blanks are written, for output that
+	// spans blanks (config files, sections of a shared instructions file).
+	PostInstall(ctx *InstallContext) error
+}
+
+// TitledAdapter is implemented by adapters with a human-readable name for
+// their output (e.g. "Cursor rules").
+type TitledAdapter interface {
+	Title() string
+}
+
+// HintAdapter is implemented by adapters with a tip to show after a cast,
+// such as how to invoke the converted commands.
+type HintAdapter interface {
+	Hint() string
+}
+
+// UserDirAdapter is implemented by adapters whose tool also reads files from
+// a per-user directory (e.g. ~/.codex). Only these support global casts.
+type UserDirAdapter interface {
+	UserDir() (string, error)
+	// UserPath maps a project-relative path the adapter produces to its
+	// location under UserDir. ok is false when the tool only reads the file
+	// from a project; always is true when it only reads it from UserDir
+	// (e.g. Codex prompts), so project casts put it there too.
+	UserPath(mapped string) (rel string, always, ok bool)
+}
+
+var outputAdapters = map[string]OutputAdapter{}
+
+// RegisterOutputAdapter makes an adapter selectable by its name. It panics
+// if the name is taken, as that is a programming error.
+func RegisterOutputAdapter(a OutputAdapter) {
+	if _, dup := outputAdapters[a.Name()]; dup {
+		panic(fmt.Sprintf("blanks: output adapter %q registered twice", a.Name()))
+	}
+	outputAdapters[a.Name()] = a
+}
+
+// LookupOutputAdapter returns the adapter registered under name.
+func LookupOutputAdapter(name string) (OutputAdapter, bool) {
+	a, ok := outputAdapters[name]
+	return a, ok
+}
+
+// OutputAdapterNames returns the registered adapter names, sorted.
+func OutputAdapterNames() []string {
+	names := make([]string, 0, len(outputAdapters))
+	for name := range outputAdapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AdapterTitle returns a's Title, or its Name when it has none.
+func AdapterTitle(a OutputAdapter) string {
+	if t, ok := a.(TitledAdapter); ok {
+		return t.Title()
+	}
+	return a.Name()
+}
+
+// SupportsGlobal reports whether a can write to a user directory.
+func SupportsGlobal(a OutputAdapter) bool {
+	_, ok := a.(UserDirAdapter)
+	return ok
+}
+
+// OutputScope selects where adapter output is written.
+type OutputScope int
+
+const (
+	// ScopeProject writes into the project at Root; files the tool only
+	// reads from its user directory go there.
+	ScopeProject OutputScope = iota
+	// ScopeGlobal writes every file to the tool's user directory.
+	ScopeGlobal
+	// ScopeBundle writes every file under Root at its project path, for
+	// `plugin generate` output meant to be copied into place.
+	ScopeBundle
+)
+
+// WriteMode says how a file is written when the destination exists.
+type WriteMode int
+
+const (
+	// WriteReplace overwrites the destination.
+	WriteReplace WriteMode = iota
+	// WriteIfMissing creates the destination only when it does not exist;
+	// used for config files the user is expected to edit.
+	WriteIfMissing
+	// WriteBlock places the content in a sentinel block keyed by the mold
+	// name, updating that block in place and leaving the rest of the file
+	// alone. The destination must tolerate HTML comments.
+	WriteBlock
+)
+
+// Actions reported in WrittenFile.
+const (
+	ActionWrote   = "wrote"
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionKept    = "kept"
+)
+
+// WrittenFile is the outcome for one file an adapter produced.
+type WrittenFile struct {
+	Path   string
+	Action string
+}
+
+// InstallContext is what PostInstall sees: the mold's rendered blanks and a
+// writer for the same scope the mapped blanks were written to.
+type InstallContext struct {
+	MoldName string
+	// Rendered is every rendered blank, mapped or not.
+	Rendered []RenderedFile
+	// Written lists the files written so far, in order.
+	Written []WrittenFile
+
+	adapter OutputAdapter
+	root    string
+	scope   OutputScope
+}
+
+// Write writes content at the project-relative path mapped, honoring the
+// install scope, and records it in Written.
+func (c *InstallContext) Write(mapped string, content []byte, mode WriteMode) error {
+	dest, err := c.destination(mapped)
+	if err != nil {
+		return err
+	}
+	action, err := writeAdapted(dest, content, mode, c.MoldName)
+	if err != nil {
+		return err
+	}
+	c.Written = append(c.Written, WrittenFile{Path: dest, Action: action})
+	return nil
+}
+
+func (c *InstallContext) destination(mapped string) (string, error) {
+	project := filepath.Join(c.root, filepath.FromSlash(mapped))
+	if c.scope == ScopeBundle {
+		return project, nil
+	}
+	ua, ok := c.adapter.(UserDirAdapter)
+	if !ok {
+		if c.scope == ScopeGlobal {
+			return "", fmt.Errorf("%s has no user-level location for %s", AdapterTitle(c.adapter), mapped)
+		}
+		return project, nil
+	}
+	rel, always, ok := ua.UserPath(mapped)
+	switch {
+	case c.scope == ScopeProject && !always:
+		return project, nil
+	case !ok:
+		return "", fmt.Errorf("%s has no user-level location for %s", AdapterTitle(c.adapter), mapped)
+	}
+	dir, err := ua.UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+// AdaptOptions configures Adapt.
+type AdaptOptions struct {
+	// Root is the project root (ScopeProject) or output dir (ScopeBundle).
+	Root  string
+	Scope OutputScope
+	// MoldName keys WriteBlock sentinel blocks; defaults to "ailloy".
+	MoldName string
+}
+
+// Adapt runs files through a and writes the result: each mapped blank, in
+// order of its mapped path, then whatever PostInstall writes. Two blanks
+// mapping to the same path are an error.
+func Adapt(a OutputAdapter, files []RenderedFile, opts AdaptOptions) ([]WrittenFile, error) {
+	type mappedFile struct {
+		path string
+		src  RenderedFile
+	}
+	var mapped []mappedFile
+	bySrc := make(map[string]string)
+	for _, rf := range files {
+		dest := filepath.ToSlash(rf.CastDest)
+		p, ok := a.MapDestination(dest)
+		if !ok {
+			continue
+		}
+		p = path.Clean(p)
+		if prev, dup := bySrc[p]; dup {
+			return nil, fmt.Errorf("%s is produced by both %s and %s", p, prev, rf.CastDest)
+		}
+		bySrc[p] = rf.CastDest
+		mapped = append(mapped, mappedFile{path: p, src: rf})
+	}
+	sort.Slice(mapped, func(i, j int) bool { return mapped[i].path < mapped[j].path })
+
+	moldName := opts.MoldName
+	if moldName == "" {
+		moldName = "ailloy"
+	}
+	ctx := &InstallContext{
+		MoldName: moldName,
+		Rendered: files,
+		adapter:  a,
+		root:     opts.Root,
+		scope:    opts.Scope,
+	}
+	for _, m := range mapped {
+		content, err := a.TransformContent(filepath.ToSlash(m.src.CastDest), m.src.Content)
+		if err != nil {
+			return ctx.Written, err
+		}
+		if err := ctx.Write(m.path, content, WriteReplace); err != nil {
+			return ctx.Written, err
+		}
+	}
+	if err := a.PostInstall(ctx); err != nil {
+		return ctx.Written, err
+	}
+	return ctx.Written, nil
+}
+
+func writeAdapted(dest string, content []byte, mode WriteMode, moldName string) (string, error) {
+	_, statErr := os.Stat(dest)
+	exists := statErr == nil
+	if mode == WriteIfMissing && exists {
+		return ActionKept, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil { // #nosec G301 -- tool config dirs need group read access
+		return "", fmt.Errorf("creating dir for %s: %w", dest, err)
+	}
+	if mode == WriteBlock {
+		if err := merge.AppendBlock(dest, content, merge.AppendOptions{MoldName: moldName}); err != nil {
+			return "", err
+		}
+		if exists {
+			return ActionUpdated, nil
+		}
+		return ActionCreated, nil
+	}
+	if err := os.WriteFile(dest, content, 0o644); err != nil { // #nosec G306 -- tool files need to be readable
+		return "", fmt.Errorf("writing %s: %w", dest, err)
+	}
+	if mode == WriteIfMissing {
+		return ActionCreated, nil
+	}
+	return ActionWrote, nil
+}
@@ -0,0 +1,125 @@
+package blanks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// upperAdapter maps notes/<name>.txt to out/<name>.md in upper case and
+// writes an index of what it converted from PostInstall.
+type upperAdapter struct{ userDir string }
+
+func (upperAdapter) Name() string { return "upper" }
+
+func (upperAdapter) MapDestination(dest string) (string, bool) {
+	if !strings.HasPrefix(dest, "notes/") {
+		return "", false
+	}
+	return "out/" + strings.TrimSuffix(strings.TrimPrefix(dest, "notes/"), ".txt") + ".md", true
+}
+
+func (upperAdapter) TransformContent(_ string, content []byte) ([]byte, error) {
+	return []byte(strings.ToUpper(string(content))), nil
+}
+
+func (upperAdapter) PostInstall(ctx *InstallContext) error {
+	return ctx.Write("INDEX.md", []byte("converted\n"), WriteBlock)
+}
+
+// globalUpperAdapter also writes to a user directory; out/ files only live
+// there.
+type globalUpperAdapter struct{ upperAdapter }
+
+func (a globalUpperAdapter) UserDir() (string, error) { return a.userDir, nil }
+
+func (globalUpperAdapter) UserPath(mapped string) (string, bool, bool) {
+	if rest, ok := strings.CutPrefix(mapped, "out/"); ok {
+		return "notes/" + rest, true, true
+	}
+	return "", false, false
+}
+
+func TestAdapt_MapsTransformsAndPostInstalls(t *testing.T) {
+	dir := t.TempDir()
+	files := []RenderedFile{
+		{CastDest: "notes/b.txt", Content: []byte("bee")},
+		{CastDest: "notes/a.txt", Content: []byte("ay")},
+		{CastDest: "other/skip.txt", Content: []byte("skip")},
+	}
+	written, err := Adapt(upperAdapter{}, files, AdaptOptions{Root: dir, MoldName: "m"})
+	if err != nil {
+		t.Fatalf("Adapt: %v", err)
+	}
+	var paths []string
+	for _, w := range written {
+		rel, _ := filepath.Rel(dir, w.Path)
+		paths = append(paths, filepath.ToSlash(rel)+":"+w.Action)
+	}
+	if got, want := strings.Join(paths, ","), "out/a.md:wrote,out/b.md:wrote,INDEX.md:created"; got != want {
+		t.Errorf("written = %s, want %s", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out", "b.md")); string(data) != "BEE" {
+		t.Errorf("out/b.md = %q", data)
+	}
+	index, _ := os.ReadFile(filepath.Join(dir, "INDEX.md"))
+	if !strings.Contains(string(index), "ailloy:mold=m:start") {
+		t.Errorf("INDEX.md = %q", index)
+	}
+}
+
+func TestAdapt_DuplicateDestination(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: "notes/a.txt", Content: []byte("1")},
+		{CastDest: "notes/a", Content: []byte("2")},
+	}
+	_, err := Adapt(upperAdapter{}, files, AdaptOptions{Root: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "produced by both") {
+		t.Errorf("expected duplicate error, got %v", err)
+	}
+}
+
+func TestAdapt_Scopes(t *testing.T) {
+	files := []RenderedFile{{CastDest: "notes/a.txt", Content: []byte("ay")}}
+
+	if _, err := Adapt(upperAdapter{}, files, AdaptOptions{Root: t.TempDir(), Scope: ScopeGlobal}); err == nil ||
+		!strings.Contains(err.Error(), "no user-level location") {
+		t.Errorf("expected global error without a user dir, got %v", err)
+	}
+
+	root, userDir := t.TempDir(), t.TempDir()
+	a := globalUpperAdapter{upperAdapter{userDir: userDir}}
+	if _, err := Adapt(a, files, AdaptOptions{Root: root}); err != nil {
+		t.Fatalf("project Adapt: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(userDir, "notes", "a.md")); err != nil {
+		t.Errorf("always-user file should go to the user dir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "INDEX.md")); err != nil {
+		t.Errorf("project file should stay in the project: %v", err)
+	}
+
+	bundle := t.TempDir()
+	if _, err := Adapt(a, files, AdaptOptions{Root: bundle, Scope: ScopeBundle}); err != nil {
+		t.Fatalf("bundle Adapt: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(bundle, "out", "a.md")); err != nil {
+		t.Errorf("bundle should keep project paths: %v", err)
+	}
+}
+
+func TestRegisterOutputAdapter(t *testing.T) {
+	RegisterOutputAdapter(upperAdapter{})
+	t.Cleanup(func() { delete(outputAdapters, "upper") })
+
+	if a, ok := LookupOutputAdapter("upper"); !ok || AdapterTitle(a) != "upper" {
+		t.Fatalf("LookupOutputAdapter = %v, %v", a, ok)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected duplicate registration to panic")
+		}
+	}()
+	RegisterOutputAdapter(upperAdapter{})
+}
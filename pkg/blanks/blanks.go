@@ -50,6 +50,17 @@ func (r *MoldReader) Root() string {
 	return r.root
 }
 
+// Compose returns a reader for the mold with its `extends:` chain applied
+// (see mold.ComposeExtends), keeping the on-disk root. id identifies the
+// mold to resolve.
+func (r *MoldReader) Compose(id string, resolve mold.ExtendsResolver) (*MoldReader, error) {
+	fsys, err := mold.ComposeExtends(r.fsys, id, resolve)
+	if err != nil {
+		return nil, err
+	}
+	return &MoldReader{fsys: fsys, root: r.root}, nil
+}
+
 // LoadManifest loads and parses the mold.yaml manifest.
 func (r *MoldReader) LoadManifest() (*mold.Mold, error) {
 	return mold.LoadMoldFromFS(r.fsys, "mold.yaml")
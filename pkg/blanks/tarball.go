@@ -0,0 +1,245 @@
+package blanks
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxTarballSize caps how many bytes a mold tarball may expand to, so a
+// gzip bomb fails instead of exhausting memory.
+const MaxTarballSize = 512 << 20
+
+// IsTarball reports whether p names a gzipped tarball (.tar.gz or .tgz),
+// the format `ailloy smelt` writes.
+func IsTarball(p string) bool {
+	lower := strings.ToLower(p)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// NewMoldReaderFromTarball reads a mold from a .tar.gz archive, such as one
+// `ailloy smelt` packaged, into memory. The archive's single top-level
+// directory ({name}-{version}/ for smelted molds) becomes the mold root.
+//
+// Archives are untrusted: entries with absolute paths, ".." escapes or
+// backslashes are an error, links and device files are left out, and the
+// contents may expand to at most MaxTarballSize bytes. The reader has no
+// on-disk root.
+func NewMoldReaderFromTarball(archive string) (*MoldReader, error) {
+	f, err := os.Open(archive) // #nosec G304 -- archive path supplied by the user
+	if err != nil {
+		return nil, fmt.Errorf("mold archive %q: %w", archive, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	fsys, err := readTarball(f)
+	if err != nil {
+		return nil, fmt.Errorf("mold archive %q: %w", archive, err)
+	}
+	return NewMoldReader(fsys), nil
+}
+
+func readTarball(r io.Reader) (*memFS, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a gzipped tarball: %w", err)
+	}
+	tr := tar.NewReader(gr)
+
+	files := map[string][]byte{}
+	exec := map[string]bool{}
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			// Directories are implied by the files under them; links and
+			// device files are never read, since a link could point
+			// anywhere on the host.
+			continue
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if strings.HasPrefix(name, "/") || strings.Contains(name, `\`) || !fs.ValidPath(path.Clean(name)) {
+			return nil, fmt.Errorf("tar entry %q escapes the archive", hdr.Name)
+		}
+		total += hdr.Size
+		if hdr.Size < 0 || total > MaxTarballSize {
+			return nil, fmt.Errorf("archive expands past %d bytes", MaxTarballSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		files[path.Clean(name)] = data
+		if hdr.Mode&0o111 != 0 {
+			exec[path.Clean(name)] = true
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("archive has no files")
+	}
+	top := topDir(files)
+	return newMemFS(stripTopDir(files, top), stripTopDir(exec, top)), nil
+}
+
+// topDir returns the top-level directory every file sits under, or ""
+// when they don't all share one.
+func topDir(files map[string][]byte) string {
+	var top string
+	for name := range files {
+		dir, _, ok := strings.Cut(name, "/")
+		if !ok || (top != "" && dir != top) {
+			return ""
+		}
+		top = dir
+	}
+	return top
+}
+
+// stripTopDir re-roots the entries of m at top, when top isn't "".
+func stripTopDir[V any](m map[string]V, top string) map[string]V {
+	if top == "" {
+		return m
+	}
+	stripped := make(map[string]V, len(m))
+	for name, v := range m {
+		stripped[strings.TrimPrefix(name, top+"/")] = v
+	}
+	return stripped
+}
+
+// memFS is a read-only in-memory fs.FS of regular files; directories are
+// derived from the file paths. Files in exec report an executable mode.
+type memFS struct {
+	files map[string][]byte
+	exec  map[string]bool
+	dirs  map[string][]fs.DirEntry // sorted by name
+}
+
+func newMemFS(files map[string][]byte, exec map[string]bool) *memFS {
+	m := &memFS{files: files, exec: exec, dirs: map[string][]fs.DirEntry{".": nil}}
+	seen := map[string]bool{}
+	for name, data := range files {
+		child := memInfo{name: path.Base(name), size: int64(len(data)), exec: exec[name]}
+		for dir := path.Dir(name); ; dir = path.Dir(dir) {
+			key := dir + "/" + child.name
+			if !seen[key] {
+				seen[key] = true
+				m.dirs[dir] = append(m.dirs[dir], fs.FileInfoToDirEntry(child))
+			}
+			if dir == "." {
+				break
+			}
+			child = memInfo{name: path.Base(dir), dir: true}
+		}
+	}
+	for _, entries := range m.dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	}
+	return m
+}
+
+// Open implements fs.FS.
+func (m *memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := m.files[name]; ok {
+		return &memFile{info: memInfo{name: path.Base(name), size: int64(len(data)), exec: m.exec[name]}, r: strings.NewReader(string(data))}, nil
+	}
+	if entries, ok := m.dirs[name]; ok {
+		return &memDir{info: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (m *memFS) ReadFile(name string) ([]byte, error) {
+	data, ok := m.files[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, ok := m.dirs[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+	exec bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	if i.exec {
+		return 0o555
+	}
+	return 0o444
+}
+
+type memFile struct {
+	info memInfo
+	r    *strings.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Read(b []byte) (int, error) { return f.r.Read(b) }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+func (d *memDir) Close() error { return nil }
+
+// ReadDir implements fs.ReadDirFile.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return append([]fs.DirEntry(nil), rest...), nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return append([]fs.DirEntry(nil), rest[:n]...), nil
+}
@@ -0,0 +1,89 @@
+package blanks
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeTarball(t *testing.T, entries ...*tar.Header) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, h := range entries {
+		body := []byte("content of " + h.Name)
+		if h.Typeflag == tar.TypeReg {
+			h.Size = int64(len(body))
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(body); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "demo-1.2.3.tar.gz")
+	if err := os.WriteFile(p, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestNewMoldReaderFromTarball(t *testing.T) {
+	archive := writeTarball(t,
+		&tar.Header{Name: "demo-1.2.3/", Typeflag: tar.TypeDir},
+		&tar.Header{Name: "demo-1.2.3/mold.yaml", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "demo-1.2.3/commands/pr.md", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "demo-1.2.3/skills/review/SKILL.md", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "demo-1.2.3/commands/passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+	)
+	reader, err := NewMoldReaderFromTarball(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.Root() != "" {
+		t.Errorf("Root() = %q, want none", reader.Root())
+	}
+	if err := fstest.TestFS(reader.FS(), "mold.yaml", "commands/pr.md", "skills/review/SKILL.md"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.FS().Open("commands/passwd"); err == nil {
+		t.Error("symlink entry was read")
+	}
+}
+
+func TestNewMoldReaderFromTarball_UnsafeEntries(t *testing.T) {
+	for _, name := range []string{"/etc/cron.d/job", "demo/../../escape.md", `demo\..\escape.md`} {
+		archive := writeTarball(t, &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644})
+		if _, err := NewMoldReaderFromTarball(archive); err == nil || !strings.Contains(err.Error(), "escapes the archive") {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
+
+func TestIsTarball(t *testing.T) {
+	for p, want := range map[string]bool{
+		"demo-1.2.3.tar.gz":   true,
+		"./out/demo.TGZ":      true,
+		"github.com/acme/x":   false,
+		"molds/demo.tar.gz/x": false,
+	} {
+		if got := IsTarball(p); got != want {
+			t.Errorf("IsTarball(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
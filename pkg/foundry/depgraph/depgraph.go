@@ -22,6 +22,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/mold"
+	"golang.org/x/sync/errgroup"
 )
 
 // NodeKey is the canonical identity of a mold in the graph: its source URL
@@ -109,6 +110,13 @@ type Fetcher interface {
 // Builder builds dep graphs.
 type Builder struct {
 	Fetcher Fetcher
+	// Jobs bounds how many of a mold's dependencies are fetched at once
+	// during discovery. 0 or 1 fetches one at a time; above that the
+	// Fetcher must be safe for concurrent use.
+	Jobs int
+	// Fetched, when set, is called after each discovery fetch completes,
+	// in declaration order. Callers use it to report per-ref progress.
+	Fetched func(key NodeKey, version string)
 }
 
 // New constructs a Builder.
@@ -143,7 +151,10 @@ func (b *Builder) Build(root *mold.Mold, rootRef *foundry.Reference) (*Graph, er
 
 	state := &buildState{
 		fetcher:       b.Fetcher,
+		jobs:          b.Jobs,
+		fetched:       b.Fetched,
 		nodes:         map[NodeKey]*Node{},
+		walked:        map[NodeKey]bool{},
 		constraints:   map[NodeKey][]constraintRef{},
 		order:         nil,
 		visiting:      map[NodeKey]bool{},
@@ -161,6 +172,7 @@ func (b *Builder) Build(root *mold.Mold, rootRef *foundry.Reference) (*Graph, er
 	}
 	state.nodes[rootKey] = rootNode
 	state.order = append(state.order, rootKey)
+	state.walked[rootKey] = true
 
 	if err := state.walkChildren(rootKey, root); err != nil {
 		return nil, err
@@ -229,7 +241,10 @@ type nonSemverRef struct {
 
 type buildState struct {
 	fetcher       Fetcher
+	jobs          int
+	fetched       func(key NodeKey, version string)
 	nodes         map[NodeKey]*Node
+	walked        map[NodeKey]bool // nodes whose children have been walked
 	constraints   map[NodeKey][]constraintRef
 	order         []NodeKey // pre-order discovery (root first)
 	visiting      map[NodeKey]bool
@@ -247,6 +262,7 @@ func (s *buildState) walkChildren(parentKey NodeKey, parent *mold.Mold) error {
 		}
 	}()
 
+	var children []pendingChild
 	for _, dep := range parent.Dependencies {
 		kind, kerr := dep.Kind()
 		if kerr != nil {
@@ -290,39 +306,98 @@ func (s *buildState) walkChildren(parentKey NodeKey, parent *mold.Mold) error {
 
 		// Record / merge the parent edge on the child (whether or not it's new).
 		s.recordParentEdge(childKey, parentKey, dep)
+		children = append(children, pendingChild{key: childKey, ref: ref})
+	}
+
+	// Discover: fetch each new child with the current constraint just to
+	// learn its transitive deps. The chosen version may be replaced during
+	// resolveAll when more constraints are intersected.
+	if err := s.discover(children); err != nil {
+		return err
+	}
 
-		// Already discovered? Skip the recursive fetch — its subtree was
-		// already walked. Constraints/pins still accumulate via the records
-		// above. (Re-walk is unnecessary because the same mold yields the same
-		// dep declarations regardless of which path led to it.)
-		// Note: recordParentEdge may have pre-allocated a Node with nil Mold
-		// just to attach the edge, so check Mold != nil rather than presence.
-		if existing, seen := s.nodes[childKey]; seen && existing.Mold != nil {
+	for _, c := range children {
+		// Already walked? Its subtree has been visited. Constraints/pins still
+		// accumulated via the records above. (Re-walk is unnecessary because
+		// the same mold yields the same dep declarations regardless of which
+		// path led to it.) A child discovered alongside a sibling but not yet
+		// walked is walked here, under this parent, so cycles through it are
+		// still caught by the visiting stack.
+		if s.walked[c.key] {
 			continue
 		}
+		s.walked[c.key] = true
+		if err := s.walkChildren(c.key, s.nodes[c.key].Mold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		// Discover: fetch with the current constraint just to learn the child's
-		// transitive deps. The chosen version may be replaced during resolveAll
-		// when more constraints are intersected.
-		fr, err := s.fetcher.Fetch(ref)
-		if err != nil {
-			return fmt.Errorf("fetching %s: %w", childKey, err)
+// pendingChild is a dependency edge recorded by walkChildren, waiting to be
+// discovered and walked.
+type pendingChild struct {
+	key NodeKey
+	ref *foundry.Reference
+}
+
+// discover fetches every child not yet fetched, up to s.jobs at a time, and
+// fills in its node. Nodes are filled and appended to s.order in
+// declaration order regardless of which fetch finishes first, and the first
+// failure in declaration order is reported; if it is a cache miss, every
+// cache miss in the batch is reported together.
+func (s *buildState) discover(children []pendingChild) error {
+	// Note: recordParentEdge may have pre-allocated a Node with nil Mold
+	// just to attach the edge, so check Mold != nil rather than presence.
+	var todo []pendingChild
+	queued := map[NodeKey]bool{}
+	for _, c := range children {
+		if s.nodes[c.key].Mold != nil || queued[c.key] {
+			continue
 		}
-		// If recordParentEdge pre-created the Node, fill it in; otherwise add a
-		// fresh one. Either way append to the order list once.
-		child := s.nodes[childKey]
-		if child == nil {
-			child = &Node{Key: childKey}
-			s.nodes[childKey] = child
+		queued[c.key] = true
+		todo = append(todo, c)
+	}
+
+	results := make([]FetchResult, len(todo))
+	errs := make([]error, len(todo))
+	fetch := func(i int) { results[i], errs[i] = s.fetcher.Fetch(todo[i].ref) }
+	if s.jobs > 1 && len(todo) > 1 {
+		var g errgroup.Group
+		g.SetLimit(s.jobs)
+		for i := range todo {
+			g.Go(func() error {
+				fetch(i)
+				return nil
+			})
+		}
+		_ = g.Wait()
+	} else {
+		for i := range todo {
+			// Keep going past cache misses so an offline cast lists every
+			// missing ref at once.
+			if fetch(i); errs[i] != nil && !foundry.IsNotCached(errs[i]) {
+				break
+			}
 		}
+	}
+
+	for i, c := range todo {
+		if errs[i] != nil {
+			if foundry.IsNotCached(errs[i]) {
+				return foundry.MissingRefs(errs...)
+			}
+			return fmt.Errorf("fetching %s: %w", c.key, errs[i])
+		}
+		fr := results[i]
+		child := s.nodes[c.key]
 		child.Mold = fr.Mold
-		child.Ref = ref
+		child.Ref = c.ref
 		child.Version = fr.Version
 		child.Commit = fr.Commit
-		s.order = append(s.order, childKey)
-
-		if err := s.walkChildren(childKey, fr.Mold); err != nil {
-			return err
+		s.order = append(s.order, c.key)
+		if s.fetched != nil {
+			s.fetched(c.key, fr.Version)
 		}
 	}
 	return nil
@@ -1,6 +1,8 @@
 package depgraph
 
 import (
+	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
@@ -295,6 +297,97 @@ func TestBuild_Cycle(t *testing.T) {
 	}
 }
 
+// TestBuild_ConcurrentDiscovery: with Jobs > 1 siblings are fetched in
+// parallel, but the graph and the Fetched callbacks match a serial build.
+func TestBuild_ConcurrentDiscovery(t *testing.T) {
+	f := newFakeFetcher()
+	var deps []mold.Dependency
+	for _, name := range []string{"b", "c", "d", "e", "g", "h"} {
+		src := "github.com/x/" + name
+		f.addMold(src, "1.0.0", makeMold(name, mold.Dependency{Mold: "github.com/x/leaf", Version: "^1.0.0"}))
+		deps = append(deps, mold.Dependency{Mold: src, Version: "^1.0.0"})
+	}
+	f.addMold("github.com/x/leaf", "1.0.0", makeMold("leaf"))
+	a := makeMold("a", deps...)
+
+	build := func(jobs int) (*Graph, []string) {
+		var fetched []string
+		b := New(f)
+		b.Jobs = jobs
+		b.Fetched = func(key NodeKey, version string) {
+			fetched = append(fetched, key.String()+"@"+version)
+		}
+		graph, err := b.Build(a, mustRef(t, "github.com/x/a@1.0.0"))
+		if err != nil {
+			t.Fatalf("Build(jobs=%d): %v", jobs, err)
+		}
+		return graph, fetched
+	}
+	serial, serialFetched := build(1)
+	parallel, parallelFetched := build(4)
+
+	if len(parallel.Nodes) != len(serial.Nodes) {
+		t.Fatalf("got %d nodes, want %d", len(parallel.Nodes), len(serial.Nodes))
+	}
+	for i := range serial.Nodes {
+		if parallel.Nodes[i].Key != serial.Nodes[i].Key || parallel.Nodes[i].Version != serial.Nodes[i].Version {
+			t.Errorf("node %d = %s@%s, want %s@%s", i, parallel.Nodes[i].Key, parallel.Nodes[i].Version, serial.Nodes[i].Key, serial.Nodes[i].Version)
+		}
+	}
+	if strings.Join(parallelFetched, " ") != strings.Join(serialFetched, " ") {
+		t.Errorf("Fetched order = %v, want %v", parallelFetched, serialFetched)
+	}
+	if len(serialFetched) != 7 {
+		t.Errorf("Fetched called %d times, want 7 (six siblings and the shared leaf)", len(serialFetched))
+	}
+}
+
+// TestBuild_CycleAcrossSiblings: A→B,C; B→C; C→B. B and C are discovered
+// together, so the cycle must still be caught when walking B.
+func TestBuild_CycleAcrossSiblings(t *testing.T) {
+	f := newFakeFetcher()
+	b := makeMold("b", mold.Dependency{Mold: "github.com/x/c", Version: "^1.0.0"})
+	c := makeMold("c", mold.Dependency{Mold: "github.com/x/b", Version: "^1.0.0"})
+	f.addMold("github.com/x/b", "1.0.0", b)
+	f.addMold("github.com/x/c", "1.0.0", c)
+	a := makeMold("a",
+		mold.Dependency{Mold: "github.com/x/b", Version: "^1.0.0"},
+		mold.Dependency{Mold: "github.com/x/c", Version: "^1.0.0"},
+	)
+
+	builder := New(f)
+	builder.Jobs = 4
+	_, err := builder.Build(a, mustRef(t, "github.com/x/a@1.0.0"))
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+// notCachedFetcher fails every fetch with an offline cache miss.
+type notCachedFetcher struct{ *fakeFetcher }
+
+func (notCachedFetcher) Fetch(ref *foundry.Reference) (FetchResult, error) {
+	return FetchResult{}, fmt.Errorf("resolve: %w", &foundry.NotCachedError{Ref: ref.CacheKey()})
+}
+
+// TestBuild_ListsEveryMissingRef: offline cache misses among siblings are
+// reported together, serially and in parallel.
+func TestBuild_ListsEveryMissingRef(t *testing.T) {
+	a := makeMold("a",
+		mold.Dependency{Mold: "github.com/x/b", Version: "^1.0.0"},
+		mold.Dependency{Mold: "github.com/x/c", Version: "^1.0.0"},
+	)
+	for _, jobs := range []int{1, 4} {
+		builder := New(notCachedFetcher{newFakeFetcher()})
+		builder.Jobs = jobs
+		_, err := builder.Build(a, mustRef(t, "github.com/x/a@1.0.0"))
+		var missing *foundry.MissingRefsError
+		if !errors.As(err, &missing) || strings.Join(missing.Refs, " ") != "github.com/x/b github.com/x/c" {
+			t.Errorf("jobs=%d: err = %v, want both refs listed", jobs, err)
+		}
+	}
+}
+
 // TestBuild_DedupesByCanonicalKey: alias differences don't change identity.
 func TestBuild_DedupesByCanonicalKey(t *testing.T) {
 	f := newFakeFetcher()
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/fs"
 	"strings"
+	"sync"
 
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/mold"
@@ -12,7 +13,7 @@ import (
 // ProdFetcher implements Fetcher against the production foundry stack
 // (foundry.ResolveWithMetadata + git ls-remote tag listing). It also caches
 // fetched filesystems so callers can later read the rendered mold contents
-// without re-fetching.
+// without re-fetching. It is safe for concurrent use.
 type ProdFetcher struct {
 	GitRunner foundry.GitRunner
 	// LockPath is forwarded to ResolveWithMetadata so transitive fetches
@@ -21,7 +22,14 @@ type ProdFetcher struct {
 	// Offline disables all network operations; tag listing and bare-clone
 	// fetches are served from the local cache. Set by --offline on cast.
 	Offline bool
-
+	// CacheFirst resolves from the local cache when it can, fetching only
+	// what the cache lacks. Set by `foundry.resolution: cache-first`.
+	CacheFirst bool
+	// AllowYanked lets resolution use yanked versions with a warning. Set by
+	// --allow-yanked on cast.
+	AllowYanked bool
+
+	mu    sync.Mutex
 	cache map[NodeKey]*ProdFetchCacheEntry
 }
 
@@ -48,9 +56,11 @@ func NewProdFetcher() *ProdFetcher {
 // Callers use this after Build to obtain the fs.FS for each transitive node
 // without re-fetching from the remote.
 func (p *ProdFetcher) CacheEntry(key NodeKey) *ProdFetchCacheEntry {
-	if p == nil || p.cache == nil {
+	if p == nil {
 		return nil
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return p.cache[key]
 }
 
@@ -58,9 +68,6 @@ func (p *ProdFetcher) CacheEntry(key NodeKey) *ProdFetchCacheEntry {
 // resolved version, and parsed Mold are cached for later retrieval via
 // CacheEntry().
 func (p *ProdFetcher) Fetch(ref *foundry.Reference) (FetchResult, error) {
-	if p.cache == nil {
-		p.cache = map[NodeKey]*ProdFetchCacheEntry{}
-	}
 	var opts []foundry.ResolveOption
 	if p.LockPath != "" {
 		opts = append(opts, foundry.WithLockPath(p.LockPath))
@@ -68,6 +75,12 @@ func (p *ProdFetcher) Fetch(ref *foundry.Reference) (FetchResult, error) {
 	if p.Offline {
 		opts = append(opts, foundry.WithOffline())
 	}
+	if p.CacheFirst {
+		opts = append(opts, foundry.WithCacheFirst())
+	}
+	if p.AllowYanked {
+		opts = append(opts, foundry.WithAllowYanked())
+	}
 	// Resolve from the *Reference directly so an explicitly-set Type (e.g. an
 	// exact pin to a monorepo-prefixed tag during constraint re-fetch) is not
 	// lost to a raw-string round-trip.
@@ -85,6 +98,10 @@ func (p *ProdFetcher) Fetch(ref *foundry.Reference) (FetchResult, error) {
 	}
 
 	key := NodeKey{Source: ref.CacheKey(), Subpath: ref.Subpath}
+	p.mu.Lock()
+	if p.cache == nil {
+		p.cache = map[NodeKey]*ProdFetchCacheEntry{}
+	}
 	p.cache[key] = &ProdFetchCacheEntry{
 		FS:        fsys,
 		Root:      result.Root,
@@ -92,6 +109,7 @@ func (p *ProdFetcher) Fetch(ref *foundry.Reference) (FetchResult, error) {
 		Resolved:  result.Resolved,
 		Reference: result.Ref,
 	}
+	p.mu.Unlock()
 	return FetchResult{
 		Mold:    m,
 		Version: result.Resolved.Tag,
@@ -160,16 +178,19 @@ func (p *ProdFetcher) moldVersionReader(source, subpath string) (foundry.MoldVer
 // effectiveGitRunner returns the GitRunner to use for this fetch. When
 // p.Offline is true it wraps the real runner with the offline interceptor so
 // that network-requiring commands are served from (or blocked by) the local
-// cache.
+// cache; when p.CacheFirst is true, with the cache-first interceptor.
 func (p *ProdFetcher) effectiveGitRunner() (foundry.GitRunner, error) {
-	if !p.Offline {
+	if !p.Offline && !p.CacheFirst {
 		return p.GitRunner, nil
 	}
 	cacheDir, err := foundry.CacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("offline mode: %w", err)
+		return nil, fmt.Errorf("locating cache: %w", err)
+	}
+	if p.Offline {
+		return foundry.NewOfflineGitRunner(p.GitRunner, cacheDir), nil
 	}
-	return foundry.NewOfflineGitRunner(p.GitRunner, cacheDir), nil
+	return foundry.NewCacheFirstGitRunner(p.GitRunner, cacheDir), nil
 }
 
 // refToRaw renders a Reference back to a raw string suitable for
@@ -0,0 +1,178 @@
+package foundry
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/goccy/go-yaml"
+)
+
+// deprecationsFile lists a package's deprecated and yanked versions. It sits
+// next to the package manifest and has the same shape as the manifest's
+// `deprecations:` block:
+//
+//	deprecated:
+//	  "<1.3.0": the review command misses renamed files; use 1.3 or later
+//	yanked:
+//	  "1.2.1": renders a broken workflow
+//
+// Keys are exact versions or semver constraints; values say why.
+const deprecationsFile = "DEPRECATIONS.yaml"
+
+// deprecations is the merged set of rules published for a package.
+type deprecations struct {
+	Deprecated map[string]string `yaml:"deprecated"`
+	Yanked     map[string]string `yaml:"yanked"`
+}
+
+// merge adds o's rules to d. Rules already in d win.
+func (d *deprecations) merge(o deprecations) {
+	d.Deprecated = mergeRules(d.Deprecated, o.Deprecated)
+	d.Yanked = mergeRules(d.Yanked, o.Yanked)
+}
+
+func mergeRules(dst, src map[string]string) map[string]string {
+	for k, v := range src {
+		if dst == nil {
+			dst = map[string]string{}
+		}
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
+// lookup reports the first rule in rules whose version or constraint matches
+// v. Keys are tried in sorted order so the message is stable; unparseable
+// keys never match.
+func lookup(rules map[string]string, v *semver.Version) (string, bool) {
+	for _, key := range slices.Sorted(maps.Keys(rules)) {
+		c, err := semver.NewConstraint(key)
+		if err == nil && c.Check(v) {
+			return rules[key], true
+		}
+	}
+	return "", false
+}
+
+// yanked reports whether version v is yanked, and why.
+func (d *deprecations) yanked(v *semver.Version) (string, bool) {
+	return lookup(d.Yanked, v)
+}
+
+// deprecated reports whether version v is deprecated, and why.
+func (d *deprecations) deprecated(v *semver.Version) (string, bool) {
+	return lookup(d.Deprecated, v)
+}
+
+// skipYanked wraps reader so that tags carrying a yanked version are reported
+// as absent, which drops them from latest and constraint resolution.
+func (d *deprecations) skipYanked(reader MoldVersionReader) MoldVersionReader {
+	if len(d.Yanked) == 0 {
+		return reader
+	}
+	return func(tag string) (string, bool) {
+		version, found := reader(tag)
+		if !found {
+			return version, found
+		}
+		if v, ok := RankVersion(tag, version); ok {
+			if _, yanked := d.yanked(v); yanked {
+				return "", false
+			}
+		}
+		return version, found
+	}
+}
+
+// YankedError reports that resolution landed on a version its author yanked.
+type YankedError struct {
+	Ref     string
+	Version string
+	Message string
+}
+
+func (e *YankedError) Error() string {
+	return fmt.Sprintf("%s@%s has been yanked: %s\npin another version, or pass --allow-yanked to use it anyway", e.Ref, e.Version, e.Message)
+}
+
+// deprecationsFor reads the deprecation rules the package's newest cached
+// release publishes: its deprecationsFile and the manifest's `deprecations:`
+// block. Authors yank a version by listing it in a later release, so the
+// newest tag speaks for every version. Reads only the local bare clone; any
+// failure means no rules.
+func (f *Fetcher) deprecationsFor(ref *Reference) *deprecations {
+	d := &deprecations{}
+	bareDir := BareCloneDir(f.cacheDir, ref)
+	out, err := localTagsOutput(bareDir, f.git)
+	if err != nil {
+		return d
+	}
+	all, err := parseLsRemoteTags(string(out))
+	if err != nil {
+		return d
+	}
+	newest, newestVer := "", (*semver.Version)(nil)
+	for tag := range selectTagsForPrefix(all, ref.ReleasePrefix()) {
+		if v, ok := RankVersion(tag, ""); ok && (newestVer == nil || v.GreaterThan(newestVer)) {
+			newest, newestVer = tag, v
+		}
+	}
+	if newest == "" {
+		return d
+	}
+
+	dir := strings.Trim(ref.Subpath, "/")
+	if dir != "" {
+		dir += "/"
+	}
+	if out, err := f.git("-C", bareDir, "show", newest+":"+dir+deprecationsFile); err == nil {
+		var file deprecations
+		if yaml.Unmarshal(out, &file) == nil {
+			d.merge(file)
+		}
+	}
+	for _, name := range []string{"mold.yaml", "ingot.yaml", "ore.yaml"} {
+		out, err := f.git("-C", bareDir, "show", newest+":"+dir+name)
+		if err != nil {
+			continue
+		}
+		var m struct {
+			Deprecations deprecations `yaml:"deprecations"`
+		}
+		if yaml.Unmarshal(out, &m) == nil {
+			d.merge(m.Deprecations)
+		}
+		break
+	}
+	return d
+}
+
+// check applies the deprecation rules to the resolved version:
+// a deprecated version logs a warning; a yanked one fails with *YankedError
+// unless allowYanked is set, in which case it warns. Branch and SHA pins
+// carry no version and are not checked.
+func (d *deprecations) check(ref *Reference, resolved *ResolvedVersion, cfg *resolveConfig) error {
+	if ref.Type == Branch || ref.Type == SHA {
+		return nil
+	}
+	v, ok := RankVersion(resolved.Tag, resolved.MoldVersion)
+	if !ok {
+		return nil
+	}
+	if msg, yanked := d.yanked(v); yanked {
+		if !cfg.allowYanked {
+			return &YankedError{Ref: ref.CacheKey(), Version: resolved.Tag, Message: msg}
+		}
+		cfg.logger.Printf("warning: %s@%s has been yanked: %s", ref.CacheKey(), resolved.Tag, msg)
+		return nil
+	}
+	if msg, deprecated := d.deprecated(v); deprecated {
+		cfg.logger.Printf("warning: %s@%s is deprecated: %s", ref.CacheKey(), resolved.Tag, msg)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package foundry
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func resolveWithRules(t *testing.T, f *fakeRemote, opts ...ResolveOption) (*ResolveResult, string, error) {
+	t.Helper()
+	var logs bytes.Buffer
+	opts = append(opts, WithLockPath(filepath.Join(t.TempDir(), "none.lock")), WithLogger(log.New(&logs, "", 0)))
+	_, result, err := resolveWithMeta(f.ref, f.run, opts...)
+	return result, logs.String(), err
+}
+
+func TestResolve_SkipsYankedWhenPicking(t *testing.T) {
+	f := newFakeRemote(t, "v1.0.0", "v1.1.0")
+	f.seedClone("v1.0.0", "v1.1.0")
+	f.files = map[string]string{"DEPRECATIONS.yaml": "yanked:\n  \"1.1.0\": renders a broken workflow\n"}
+
+	result, _, err := resolveWithRules(t, f)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if result.Resolved.Tag != "v1.0.0" {
+		t.Errorf("tag = %s, want v1.0.0 (v1.1.0 is yanked)", result.Resolved.Tag)
+	}
+}
+
+func TestResolve_RefusesPinnedYanked(t *testing.T) {
+	f := newFakeRemote(t, "v1.0.0", "v1.1.0")
+	f.seedClone("v1.0.0", "v1.1.0")
+	f.files = map[string]string{"mold.yaml": "name: m\nversion: 1.1.0\ndeprecations:\n  yanked:\n    \"1.0.0\": leaks tokens\n"}
+	f.ref.Version, f.ref.Type = "v1.0.0", Exact
+
+	_, _, err := resolveWithRules(t, f)
+	var yanked *YankedError
+	if !errors.As(err, &yanked) || yanked.Message != "leaks tokens" {
+		t.Fatalf("err = %v, want YankedError", err)
+	}
+	if !strings.Contains(err.Error(), "--allow-yanked") {
+		t.Errorf("error should mention --allow-yanked: %v", err)
+	}
+
+	result, logs, err := resolveWithRules(t, f, WithAllowYanked())
+	if err != nil || result.Resolved.Tag != "v1.0.0" {
+		t.Fatalf("with --allow-yanked: (%v, %v), want v1.0.0", result, err)
+	}
+	if !strings.Contains(logs, "warning: github.com/acme/mold@v1.0.0 has been yanked: leaks tokens") {
+		t.Errorf("expected a yanked warning, got %q", logs)
+	}
+}
+
+func TestResolve_WarnsOnDeprecated(t *testing.T) {
+	f := newFakeRemote(t, "v1.0.0", "v2.0.0")
+	f.seedClone("v1.0.0", "v2.0.0")
+	f.files = map[string]string{"DEPRECATIONS.yaml": "deprecated:\n  \"<2.0.0\": move to 2.x\n"}
+	f.ref.Version, f.ref.Type = "^1.0.0", Constraint
+
+	result, logs, err := resolveWithRules(t, f)
+	if err != nil || result.Resolved.Tag != "v1.0.0" {
+		t.Fatalf("resolve: (%v, %v), want v1.0.0", result, err)
+	}
+	if !strings.Contains(logs, "warning: github.com/acme/mold@v1.0.0 is deprecated: move to 2.x") {
+		t.Errorf("expected a deprecation warning, got %q", logs)
+	}
+}
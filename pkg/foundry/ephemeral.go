@@ -0,0 +1,362 @@
+package foundry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// EphemeralStatePath is the project state file recording trial casts.
+const EphemeralStatePath = ".ailloy/ephemeral.yaml"
+
+// DefaultEphemeralTTL is how long a trial cast lives before it is flagged.
+const DefaultEphemeralTTL = 7 * 24 * time.Hour
+
+// EphemeralFile is one file written by a trial cast.
+type EphemeralFile struct {
+	Path string `yaml:"path"` // project-relative, forward-slash separated
+	// SHA256 is the file's hash right after the trial cast; revert skips
+	// files whose content has changed since.
+	SHA256 string `yaml:"sha256,omitempty"`
+	// Backup is the project-relative path of the file's pre-trial content.
+	// Empty when the trial created the file, so revert deletes it.
+	Backup string `yaml:"backup,omitempty"`
+}
+
+// EphemeralTrial records a mold cast with --ephemeral. Trials live in
+// EphemeralStatePath rather than installed.yaml so they never reach
+// ailloy.lock, recast, or uninstall, and revert restores the project to its
+// pre-trial state.
+type EphemeralTrial struct {
+	Name      string          `yaml:"name"`
+	Source    string          `yaml:"source"`
+	Subpath   string          `yaml:"subpath,omitempty"`
+	Version   string          `yaml:"version,omitempty"`
+	Commit    string          `yaml:"commit,omitempty"`
+	CastAt    time.Time       `yaml:"castAt"`
+	ExpiresAt time.Time       `yaml:"expiresAt"`
+	Files     []EphemeralFile `yaml:"files,omitempty"`
+}
+
+// Expired reports whether the trial has outlived its expiry at now.
+func (t EphemeralTrial) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt)
+}
+
+// Display returns source or source//subpath.
+func (t EphemeralTrial) Display() string {
+	if t.Subpath != "" {
+		return t.Source + "//" + t.Subpath
+	}
+	return t.Source
+}
+
+// EphemeralState is the on-disk list of trial casts.
+type EphemeralState struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Trials     []EphemeralTrial `yaml:"trials"`
+}
+
+// ReadEphemeralState reads the trial state at path. Returns (nil, nil) if
+// the file does not exist.
+func ReadEphemeralState(path string) (*EphemeralState, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- path constructed by callers
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading ephemeral state: %w", err)
+	}
+	var s EphemeralState
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing ephemeral state: %w", err)
+	}
+	return &s, nil
+}
+
+// WriteEphemeralState writes s to path, or removes the file when no trials
+// remain.
+func WriteEphemeralState(path string, s *EphemeralState) error {
+	if s == nil || len(s.Trials) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing ephemeral state: %w", err)
+		}
+		return nil
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshaling ephemeral state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil { // #nosec G301 -- .ailloy needs group read access
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	//#nosec G306 -- state file needs to be readable
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing ephemeral state: %w", err)
+	}
+	return nil
+}
+
+// Find returns the trial for (source, subpath), or nil.
+func (s *EphemeralState) Find(source, subpath string) *EphemeralTrial {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Trials {
+		if s.Trials[i].Source == source && s.Trials[i].Subpath == subpath {
+			return &s.Trials[i]
+		}
+	}
+	return nil
+}
+
+// Upsert adds or replaces the trial with the same (source, subpath).
+func (s *EphemeralState) Upsert(t EphemeralTrial) {
+	if existing := s.Find(t.Source, t.Subpath); existing != nil {
+		*existing = t
+		return
+	}
+	s.Trials = append(s.Trials, t)
+}
+
+// Expired returns the trials expired at now, oldest first.
+func (s *EphemeralState) Expired(now time.Time) []EphemeralTrial {
+	if s == nil {
+		return nil
+	}
+	var out []EphemeralTrial
+	for _, t := range s.Trials {
+		if t.Expired(now) {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CastAt.Before(out[j].CastAt) })
+	return out
+}
+
+// ephemeralBackupDir returns the project-relative directory holding a
+// trial's pre-cast file copies. The name is a hash of the trial identity so
+// arbitrary sources (including local paths) map to a safe directory name.
+func ephemeralBackupDir(source, subpath string) string {
+	sum := sha256.Sum256([]byte(source + "//" + subpath))
+	return ".ailloy/ephemeral/" + hex.EncodeToString(sum[:])[:16]
+}
+
+// BackupForTrial snapshots the current content of every existing file in
+// paths before a trial cast overwrites it, adding entries to t.Files. Paths
+// already tracked by t keep their original backup, so re-casting a trial
+// still reverts to the pre-trial state. statePath locates the project root.
+func BackupForTrial(statePath string, t *EphemeralTrial, paths []string) error {
+	root := projectRootForManifest(statePath)
+	tracked := make(map[string]bool, len(t.Files))
+	for _, f := range t.Files {
+		tracked[f.Path] = true
+	}
+	backupDir := ephemeralBackupDir(t.Source, t.Subpath)
+
+	for _, rel := range paths {
+		rel = filepath.ToSlash(filepath.Clean(rel))
+		if tracked[rel] {
+			continue
+		}
+		tracked[rel] = true
+		entry := EphemeralFile{Path: rel}
+
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		st, err := os.Stat(abs)
+		switch {
+		case err == nil && st.Mode().IsRegular():
+			entry.Backup = backupDir + "/" + rel
+			if err := copyFile(abs, filepath.Join(root, filepath.FromSlash(entry.Backup))); err != nil {
+				return fmt.Errorf("backing up %s: %w", rel, err)
+			}
+		case err != nil && !os.IsNotExist(err):
+			return fmt.Errorf("stat %s: %w", rel, err)
+		}
+		t.Files = append(t.Files, entry)
+	}
+	return nil
+}
+
+// RecordTrialHashes records the post-cast hash of each trial file. Files
+// the cast never wrote (e.g. blanks that rendered empty) and that had no
+// prior content are dropped.
+func RecordTrialHashes(statePath string, t *EphemeralTrial) error {
+	root := projectRootForManifest(statePath)
+	kept := t.Files[:0]
+	for _, f := range t.Files {
+		sum, err := hashPath(filepath.Join(root, filepath.FromSlash(f.Path)))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("hashing %s: %w", f.Path, err)
+			}
+			if f.Backup == "" {
+				continue
+			}
+		}
+		f.SHA256 = sum
+		kept = append(kept, f)
+	}
+	t.Files = kept
+	return nil
+}
+
+// RevertResult summarizes the outcome of reverting a trial.
+type RevertResult struct {
+	Restored        []string // files put back to their pre-trial content
+	Deleted         []string // files the trial created, now removed
+	SkippedModified []string // files changed since the trial cast; left as-is
+	// KeptBackups lists pre-trial copies left on disk because their file
+	// was skipped as modified.
+	KeptBackups []string
+}
+
+// RevertEphemeral undoes the trial (source, subpath) recorded in statePath:
+// files the trial created are deleted, files it overwrote are restored from
+// their backups, and the trial is dropped from the state. Files changed
+// since the trial cast are skipped unless opts.Force is set; their backups
+// are kept so nothing is lost.
+func RevertEphemeral(statePath, source, subpath string, opts UninstallOptions) (RevertResult, error) {
+	var res RevertResult
+
+	state, err := ReadEphemeralState(statePath)
+	if err != nil {
+		return res, err
+	}
+	trial := state.Find(source, subpath)
+	if trial == nil {
+		return res, fmt.Errorf("no ephemeral trial for %q (subpath %q)", source, subpath)
+	}
+
+	root := projectRootForManifest(statePath)
+	dirsTouched := make(map[string]struct{})
+
+	files := append([]EphemeralFile(nil), trial.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path > files[j].Path })
+	for _, f := range files {
+		abs := filepath.Join(root, filepath.FromSlash(f.Path))
+
+		if !opts.Force {
+			if _, err := os.Stat(abs); err == nil {
+				modified, err := fileModifiedSinceCast(abs, f.SHA256)
+				if err != nil {
+					return res, fmt.Errorf("checking %s: %w", f.Path, err)
+				}
+				if modified {
+					res.SkippedModified = append(res.SkippedModified, f.Path)
+					if f.Backup != "" {
+						res.KeptBackups = append(res.KeptBackups, f.Backup)
+					}
+					continue
+				}
+			}
+		}
+
+		if f.Backup != "" {
+			res.Restored = append(res.Restored, f.Path)
+			if opts.DryRun {
+				continue
+			}
+			if err := copyFile(filepath.Join(root, filepath.FromSlash(f.Backup)), abs); err != nil {
+				return res, fmt.Errorf("restoring %s: %w", f.Path, err)
+			}
+			continue
+		}
+
+		res.Deleted = append(res.Deleted, f.Path)
+		if opts.DryRun {
+			continue
+		}
+		if err := os.Remove(abs); err != nil && !os.IsNotExist(err) {
+			return res, fmt.Errorf("removing %s: %w", f.Path, err)
+		}
+		dirsTouched[filepath.Dir(abs)] = struct{}{}
+	}
+
+	sort.Strings(res.Restored)
+	sort.Strings(res.Deleted)
+	sort.Strings(res.SkippedModified)
+	sort.Strings(res.KeptBackups)
+	if opts.DryRun {
+		return res, nil
+	}
+
+	dirs := make([]string, 0, len(dirsTouched))
+	for d := range dirsTouched {
+		dirs = append(dirs, d)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, d := range dirs {
+		pruneEmptyDirs(d, root)
+	}
+
+	if err := dropTrial(statePath, state, source, subpath, len(res.KeptBackups) == 0); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// DiscardEphemeral forgets the trial (source, subpath) without touching the
+// files it cast, e.g. when the mold is re-cast for real. Reports whether a
+// trial existed.
+func DiscardEphemeral(statePath, source, subpath string) (bool, error) {
+	state, err := ReadEphemeralState(statePath)
+	if err != nil {
+		return false, err
+	}
+	if state.Find(source, subpath) == nil {
+		return false, nil
+	}
+	return true, dropTrial(statePath, state, source, subpath, true)
+}
+
+// dropTrial removes the trial from state, optionally deleting its backups,
+// and writes the state back.
+func dropTrial(statePath string, state *EphemeralState, source, subpath string, removeBackups bool) error {
+	if removeBackups {
+		root := projectRootForManifest(statePath)
+		backupDir := filepath.Join(root, filepath.FromSlash(ephemeralBackupDir(source, subpath)))
+		if err := os.RemoveAll(backupDir); err != nil {
+			return fmt.Errorf("removing trial backups: %w", err)
+		}
+		pruneEmptyDirs(filepath.Dir(backupDir), root)
+	}
+	for i := range state.Trials {
+		if state.Trials[i].Source == source && state.Trials[i].Subpath == subpath {
+			state.Trials = append(state.Trials[:i], state.Trials[i+1:]...)
+			break
+		}
+	}
+	return WriteEphemeralState(statePath, state)
+}
+
+func hashPath(path string) (string, error) {
+	f, err := os.Open(path) // #nosec G304 -- trial files are recorded by cast
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src) // #nosec G304 -- trial files are recorded by cast
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil { // #nosec G301 -- project directories need group read access
+		return err
+	}
+	return os.WriteFile(dst, data, 0644) // #nosec G306 -- restored blanks need to be readable
+}
@@ -0,0 +1,184 @@
+package foundry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// castTrial simulates a trial cast in root: back up paths, write contents,
+// record hashes, and persist the trial.
+func castTrial(t *testing.T, root string, trial EphemeralTrial, contents map[string]string) string {
+	t.Helper()
+	statePath := filepath.Join(root, EphemeralStatePath)
+	state, err := ReadEphemeralState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil {
+		state = &EphemeralState{APIVersion: "v1"}
+	}
+	if existing := state.Find(trial.Source, trial.Subpath); existing != nil {
+		trial.Files = existing.Files
+	}
+	paths := make([]string, 0, len(contents))
+	for p := range contents {
+		paths = append(paths, p)
+	}
+	if err := BackupForTrial(statePath, &trial, paths); err != nil {
+		t.Fatalf("BackupForTrial: %v", err)
+	}
+	for p, c := range contents {
+		writeFileT(t, filepath.Join(root, p), c)
+	}
+	if err := RecordTrialHashes(statePath, &trial); err != nil {
+		t.Fatalf("RecordTrialHashes: %v", err)
+	}
+	state.Upsert(trial)
+	if err := WriteEphemeralState(statePath, state); err != nil {
+		t.Fatal(err)
+	}
+	return statePath
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRevertEphemeral_RestoresOverwrittenAndDeletesCreated(t *testing.T) {
+	root := t.TempDir()
+	writeFileT(t, filepath.Join(root, "AGENTS.md"), "mine\n")
+
+	statePath := castTrial(t, root, EphemeralTrial{Name: "demo", Source: "github.com/o/demo"}, map[string]string{
+		"AGENTS.md":                "from trial\n",
+		".claude/commands/demo.md": "# demo\n",
+	})
+
+	res, err := RevertEphemeral(statePath, "github.com/o/demo", "", UninstallOptions{})
+	if err != nil {
+		t.Fatalf("RevertEphemeral: %v", err)
+	}
+	if len(res.Restored) != 1 || len(res.Deleted) != 1 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if got := readTestFile(t, filepath.Join(root, "AGENTS.md")); got != "mine\n" {
+		t.Errorf("AGENTS.md = %q, want original content", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".claude")); !os.IsNotExist(err) {
+		t.Error("empty directories created by the trial should be pruned")
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Error("state file should be removed once no trials remain")
+	}
+	if _, err := os.Stat(filepath.Join(root, ".ailloy", "ephemeral")); !os.IsNotExist(err) {
+		t.Error("backups should be removed after revert")
+	}
+}
+
+func TestRevertEphemeral_RecastKeepsOriginalBackup(t *testing.T) {
+	root := t.TempDir()
+	writeFileT(t, filepath.Join(root, "AGENTS.md"), "mine\n")
+	trial := EphemeralTrial{Name: "demo", Source: "./demo"}
+
+	castTrial(t, root, trial, map[string]string{"AGENTS.md": "v1\n"})
+	statePath := castTrial(t, root, trial, map[string]string{"AGENTS.md": "v2\n"})
+
+	if _, err := RevertEphemeral(statePath, "./demo", "", UninstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, filepath.Join(root, "AGENTS.md")); got != "mine\n" {
+		t.Errorf("AGENTS.md = %q, want pre-trial content", got)
+	}
+}
+
+func TestRevertEphemeral_SkipsModifiedUnlessForced(t *testing.T) {
+	root := t.TempDir()
+	writeFileT(t, filepath.Join(root, "AGENTS.md"), "mine\n")
+	statePath := castTrial(t, root, EphemeralTrial{Name: "demo", Source: "./demo"}, map[string]string{
+		"AGENTS.md": "from trial\n",
+		"notes.md":  "trial notes\n",
+	})
+	writeFileT(t, filepath.Join(root, "AGENTS.md"), "edited after trial\n")
+
+	res, err := RevertEphemeral(statePath, "./demo", "", UninstallOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.SkippedModified) != 1 || res.SkippedModified[0] != "AGENTS.md" || len(res.KeptBackups) != 1 {
+		t.Errorf("dry run: unexpected result %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes.md")); err != nil {
+		t.Error("dry run must not touch disk")
+	}
+
+	if _, err := RevertEphemeral(statePath, "./demo", "", UninstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, filepath.Join(root, "AGENTS.md")); got != "edited after trial\n" {
+		t.Errorf("modified file should be left alone, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes.md")); !os.IsNotExist(err) {
+		t.Error("unmodified trial file should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(root, res.KeptBackups[0])); err != nil {
+		t.Errorf("backup of a skipped file should be kept: %v", err)
+	}
+}
+
+func TestRevertEphemeral_ForceRestoresModified(t *testing.T) {
+	root := t.TempDir()
+	writeFileT(t, filepath.Join(root, "AGENTS.md"), "mine\n")
+	statePath := castTrial(t, root, EphemeralTrial{Name: "demo", Source: "./demo"}, map[string]string{"AGENTS.md": "from trial\n"})
+	writeFileT(t, filepath.Join(root, "AGENTS.md"), "edited\n")
+
+	if _, err := RevertEphemeral(statePath, "./demo", "", UninstallOptions{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, filepath.Join(root, "AGENTS.md")); got != "mine\n" {
+		t.Errorf("AGENTS.md = %q, want pre-trial content", got)
+	}
+}
+
+func TestDiscardEphemeral_KeepsFiles(t *testing.T) {
+	root := t.TempDir()
+	statePath := castTrial(t, root, EphemeralTrial{Name: "a", Source: "./a"}, map[string]string{"a.md": "a\n"})
+	castTrial(t, root, EphemeralTrial{Name: "b", Source: "./b"}, map[string]string{"b.md": "b\n"})
+
+	kept, err := DiscardEphemeral(statePath, "./a", "")
+	if err != nil || !kept {
+		t.Fatalf("DiscardEphemeral = %v, %v", kept, err)
+	}
+	if got := readTestFile(t, filepath.Join(root, "a.md")); got != "a\n" {
+		t.Errorf("discard must keep trial files, got %q", got)
+	}
+	state, err := ReadEphemeralState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Find("./a", "") != nil || state.Find("./b", "") == nil {
+		t.Errorf("unexpected trials after discard: %+v", state.Trials)
+	}
+
+	if kept, err := DiscardEphemeral(statePath, "./missing", ""); err != nil || kept {
+		t.Errorf("discarding an unknown trial = %v, %v; want false, nil", kept, err)
+	}
+}
+
+func TestEphemeralState_Expired(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	s := &EphemeralState{Trials: []EphemeralTrial{
+		{Source: "fresh", CastAt: now.Add(-24 * time.Hour), ExpiresAt: now.Add(6 * 24 * time.Hour)},
+		{Source: "newer", CastAt: now.Add(-8 * 24 * time.Hour), ExpiresAt: now.Add(-24 * time.Hour)},
+		{Source: "older", CastAt: now.Add(-30 * 24 * time.Hour), ExpiresAt: now.Add(-23 * 24 * time.Hour)},
+	}}
+	expired := s.Expired(now)
+	if len(expired) != 2 || expired[0].Source != "older" || expired[1].Source != "newer" {
+		t.Errorf("Expired = %+v", expired)
+	}
+}
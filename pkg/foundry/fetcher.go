@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 )
 
 // Fetcher clones and checks out mold versions from git repositories.
@@ -115,9 +116,17 @@ func (f *Fetcher) MoldVersionReaderFor(ref *Reference) (MoldVersionReader, error
 	}, nil
 }
 
+// bareCloneLocks serializes clone and fetch per bare-clone directory, so
+// concurrent resolves of refs in the same repository (e.g. two ingots from
+// one monorepo) don't race on git's ref locks.
+var bareCloneLocks sync.Map // bare dir -> *sync.Mutex
+
 // ensureBareClone creates or updates the bare clone for the reference.
 func (f *Fetcher) ensureBareClone(ref *Reference) error {
 	bareDir := BareCloneDir(f.cacheDir, ref)
+	mu, _ := bareCloneLocks.LoadOrStore(bareDir, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
 
 	if _, err := os.Stat(filepath.Join(bareDir, "HEAD")); err == nil {
 		// Bare clone exists — fetch updates including new tags.
@@ -131,15 +140,41 @@ func (f *Fetcher) ensureBareClone(ref *Reference) error {
 		return nil
 	}
 
-	// Create parent directories.
-	if err := os.MkdirAll(filepath.Dir(bareDir), 0750); err != nil {
-		return fmt.Errorf("creating cache directory: %w", err)
+	// Clone into scratch space and move into place, so an interrupted clone
+	// never leaves a HEAD-bearing partial repo at bareDir.
+	staging, err := tmpdir.MkdirTemp("clone-*")
+	if err != nil {
+		return fmt.Errorf("creating staging directory: %w", err)
 	}
+	defer func() { _ = os.RemoveAll(staging) }()
+	staged := filepath.Join(staging, "git")
 
-	out, err := f.git("clone", "--bare", ref.CloneURL(), bareDir)
+	out, err := f.git("clone", "--bare", ref.CloneURL(), staged)
 	if err != nil {
 		return fmt.Errorf("git clone --bare %s: %w\n%s", ref.CloneURL(), err, out)
 	}
+	return commitCacheEntry(staged, bareDir, func() bool {
+		_, err := os.Stat(filepath.Join(bareDir, "HEAD"))
+		return err == nil
+	})
+}
+
+// commitCacheEntry moves a staged cache entry to dest. Leftovers at dest
+// from a run that predates staging are replaced; if a concurrent run
+// committed a valid entry first (complete reports true), that one is kept.
+func commitCacheEntry(staged, dest string, complete func() bool) error {
+	if complete() {
+		return nil
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("removing incomplete cache entry %s: %w", dest, err)
+	}
+	if err := tmpdir.Commit(staged, dest); err != nil {
+		if complete() {
+			return nil
+		}
+		return err
+	}
 	return nil
 }
 
@@ -149,22 +184,30 @@ func (f *Fetcher) checkoutVersion(ref *Reference, resolved *ResolvedVersion) err
 	bareDir := BareCloneDir(f.cacheDir, ref)
 	vDir := VersionDir(f.cacheDir, ref, resolved.Tag)
 
-	if err := os.MkdirAll(vDir, 0750); err != nil {
-		return fmt.Errorf("creating version directory: %w", err)
-	}
-
 	// Use git archive to extract files without a working tree.
 	out, err := f.git("-C", bareDir, "archive", "--format=tar", resolved.Tag)
 	if err != nil {
 		return fmt.Errorf("git archive %s: %w\n%s", resolved.Tag, err, out)
 	}
 
-	// Extract tar into version directory.
-	if err := extractTar(out, vDir); err != nil {
+	// Extract into scratch space and move into place, so an interrupted
+	// extraction never leaves a version directory that looks complete.
+	staging, err := tmpdir.MkdirTemp("extract-*")
+	if err != nil {
+		return fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+	staged := filepath.Join(staging, "version")
+	if err := os.MkdirAll(staged, 0750); err != nil {
+		return fmt.Errorf("creating version directory: %w", err)
+	}
+	if err := extractTar(out, staged); err != nil {
 		return fmt.Errorf("extracting archive: %w", err)
 	}
 
-	return nil
+	return commitCacheEntry(staged, vDir, func() bool {
+		return hasMoldManifestInDir(vDir, ref.Subpath)
+	})
 }
 
 // navigateSubpath applies the //subpath and validates the mold manifest exists.
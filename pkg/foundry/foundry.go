@@ -1,6 +1,7 @@
 package foundry
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -17,6 +19,10 @@ import (
 type InstalledFile struct {
 	RelPath string // path relative to the manifest dir, forward-slash separated
 	SHA256  string // hex-encoded sha256 of file content at install time
+	// RenderSHA256 is the sha256 of the mold's rendered output before any
+	// merge/append strategy folded it into an existing file. Empty when
+	// unknown; only persisted when it differs from SHA256.
+	RenderSHA256 string
 }
 
 // RecordInstalledFiles backfills the Files list and FileHashes map on the
@@ -42,6 +48,7 @@ func RecordInstalledFiles(manifestPath, source, subpath string, files []Installe
 	seen := make(map[string]struct{}, len(files))
 	paths := make([]string, 0, len(files))
 	hashes := make(map[string]string, len(files))
+	renders := make(map[string]string)
 	for _, f := range files {
 		s := filepath.ToSlash(f.RelPath)
 		if _, dup := seen[s]; dup {
@@ -52,6 +59,9 @@ func RecordInstalledFiles(manifestPath, source, subpath string, files []Installe
 		if f.SHA256 != "" {
 			hashes[s] = f.SHA256
 		}
+		if f.RenderSHA256 != "" && f.RenderSHA256 != f.SHA256 {
+			renders[s] = f.RenderSHA256
+		}
 	}
 	sort.Strings(paths)
 	entry.Files = paths
@@ -60,6 +70,11 @@ func RecordInstalledFiles(manifestPath, source, subpath string, files []Installe
 	} else {
 		entry.FileHashes = nil
 	}
+	if len(renders) > 0 {
+		entry.RenderHashes = renders
+	} else {
+		entry.RenderHashes = nil
+	}
 
 	return WriteInstalledManifest(manifestPath, m)
 }
@@ -80,8 +95,25 @@ type resolveConfig struct {
 	// fetches are served from the local cache; the cast fails if the cache is
 	// cold. Enabled by --offline on the cast command.
 	offline bool
+	// cacheFirst serves resolution from the local cache when it can and
+	// goes to the network only for what the cache can't satisfy. Enabled
+	// by `foundry.resolution: cache-first` in config.yaml.
+	cacheFirst bool
+	// allowYanked lets resolution use versions their author yanked, with a
+	// warning. Enabled by --allow-yanked on the cast command.
+	allowYanked bool
 }
 
+// Resolution policies accepted by `foundry.resolution` in config.yaml.
+const (
+	// ResolutionAlwaysFetch refreshes each repository from its remote before
+	// resolving a version. The default.
+	ResolutionAlwaysFetch = "always-fetch"
+	// ResolutionCacheFirst resolves against the local cache and fetches only
+	// repositories, or versions, the cache doesn't have.
+	ResolutionCacheFirst = "cache-first"
+)
+
 // applyResolveDefaults sets the default lockPath. Exposed for tests.
 func applyResolveDefaults(c *resolveConfig) {
 	if c.lockPath == "" {
@@ -119,6 +151,36 @@ func WithOffline() ResolveOption {
 	}
 }
 
+// IsOffline reports whether opts include WithOffline.
+func IsOffline(opts ...ResolveOption) bool {
+	var c resolveConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c.offline
+}
+
+// WithCacheFirst resolves from the local cache when it can: a cached
+// repository is neither re-fetched nor asked for its remote tags, so a
+// cached mold casts without network access. If the cache can't satisfy the
+// reference (an uncached repository, or a version newer than the cached
+// tags) resolution falls back to the network. WithOffline takes precedence.
+func WithCacheFirst() ResolveOption {
+	return func(c *resolveConfig) {
+		c.cacheFirst = true
+	}
+}
+
+// WithAllowYanked lets resolution settle on a yanked version (one listed
+// under `yanked:` in the package's DEPRECATIONS.yaml or manifest) instead of
+// failing with *YankedError. Latest and constraint refs still prefer
+// versions that aren't yanked.
+func WithAllowYanked() ResolveOption {
+	return func(c *resolveConfig) {
+		c.allowYanked = true
+	}
+}
+
 // shouldUseLock returns true when a lock file exists at the configured path.
 // Lock reads/writes are gated on file presence — opt-in via `ailloy quench`.
 func shouldUseLock(path string) bool {
@@ -208,33 +270,69 @@ func resolveWithMeta(ref *Reference, git GitRunner, opts ...ResolveOption) (fs.F
 
 	useLock := shouldUseLock(cfg.lockPath)
 
-	var resolved *ResolvedVersion
+	var locked *ResolvedVersion
 	if useLock {
 		lock, err := ReadLockFile(cfg.lockPath)
+		if IsSchemaTooNew(err) {
+			return nil, nil, err
+		}
 		if err != nil {
 			cfg.logger.Printf("warning: reading lock file: %v", err)
 		}
 		if entry := lock.FindEntry(ref.CacheKey(), ref.Subpath); entry != nil && ref.Type != Branch && ref.Type != SHA {
 			if lockedSatisfies(ref, entry) {
-				resolved = &ResolvedVersion{Tag: entry.Version, Commit: entry.Commit}
+				locked = &ResolvedVersion{Tag: entry.Version, Commit: entry.Commit}
 				cfg.logger.Printf("using locked version %s@%s", ref.CacheKey(), entry.Version)
 			}
 		}
 	}
 
-	if cfg.offline {
+	git = NewRetryGitRunner(git, cfg.logger)
+	network := git
+	if cfg.offline || cfg.cacheFirst {
 		cacheDir, cErr := CacheDir()
 		if cErr != nil {
-			return nil, nil, fmt.Errorf("offline mode: %w", cErr)
+			return nil, nil, fmt.Errorf("locating cache: %w", cErr)
+		}
+		if cfg.offline {
+			git = NewOfflineGitRunner(git, cacheDir)
+		} else {
+			git = NewCacheFirstGitRunner(git, cacheDir)
+		}
+	}
+
+	resolved, fsys, root, err := fetchResolved(ref, git, locked, &cfg)
+	var yanked *YankedError
+	if err != nil && cfg.cacheFirst && !cfg.offline && !errors.As(err, &yanked) {
+		// The cache couldn't satisfy the reference (e.g. a constraint only a
+		// newer, uncached tag meets); resolve against the remote instead.
+		resolved, fsys, root, err = fetchResolved(ref, network, locked, &cfg)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if useLock {
+		if err := updateLockAt(cfg.lockPath, ref, resolved); err != nil {
+			cfg.logger.Printf("warning: updating lock file: %v", err)
 		}
-		git = NewOfflineGitRunner(git, cacheDir)
 	}
 
+	return fsys, &ResolveResult{Ref: ref, Resolved: *resolved, Root: root}, nil
+}
+
+// fetchResolved resolves ref to a version (unless locked already pins one)
+// and fetches it through git, returning the version, the mold's fs.FS, and
+// its on-disk root. Yanked versions are passed over when picking a version
+// and refused (see deprecations.check) when pinned.
+func fetchResolved(ref *Reference, git GitRunner, locked *ResolvedVersion, cfg *resolveConfig) (*ResolvedVersion, fs.FS, string, error) {
 	fetcher, err := NewFetcher(git)
 	if err != nil {
-		return nil, nil, fmt.Errorf("creating fetcher: %w", err)
+		return nil, nil, "", fmt.Errorf("creating fetcher: %w", err)
 	}
 
+	resolved := locked
+	var deps *deprecations
 	if resolved == nil {
 		// Rank candidate tags by the dependency mold's declared mold.yaml
 		// version (release-train monorepos tag every mold with a shared
@@ -242,27 +340,30 @@ func resolveWithMeta(ref *Reference, git GitRunner, opts ...ResolveOption) (fs.F
 		// fetch below uses, so this adds no extra network round-trip.
 		reader, rerr := fetcher.MoldVersionReaderFor(ref)
 		if rerr != nil {
-			return nil, nil, fmt.Errorf("resolving version: %w", rerr)
+			return nil, nil, "", fmt.Errorf("resolving version: %w", rerr)
+		}
+		deps = fetcher.deprecationsFor(ref)
+		if !cfg.allowYanked && (ref.Type == Latest || ref.Type == Constraint) {
+			reader = deps.skipYanked(reader)
 		}
 		v, resolveErr := ResolveVersionWithMoldReader(ref, git, reader)
 		if resolveErr != nil {
-			return nil, nil, fmt.Errorf("resolving version: %w", resolveErr)
+			return nil, nil, "", fmt.Errorf("resolving version: %w", resolveErr)
 		}
 		resolved = v
 	}
 
 	fsys, root, err := fetcher.Fetch(ref, resolved)
 	if err != nil {
-		return nil, nil, fmt.Errorf("fetching mold: %w", err)
+		return nil, nil, "", fmt.Errorf("fetching mold: %w", err)
 	}
-
-	if useLock {
-		if err := updateLockAt(cfg.lockPath, ref, resolved); err != nil {
-			cfg.logger.Printf("warning: updating lock file: %v", err)
-		}
+	if deps == nil {
+		deps = fetcher.deprecationsFor(ref)
 	}
-
-	return fsys, &ResolveResult{Ref: ref, Resolved: *resolved, Root: root}, nil
+	if err := deps.check(ref, resolved, cfg); err != nil {
+		return nil, nil, "", err
+	}
+	return resolved, fsys, root, nil
 }
 
 func lockedSatisfies(ref *Reference, entry *LockEntry) bool {
@@ -313,9 +414,18 @@ func lockEntryVersion(entry *LockEntry) *semver.Version {
 	return nil
 }
 
+// lockUpdateMu serializes updateLockAt so concurrent resolves don't drop
+// each other's entries.
+var lockUpdateMu sync.Mutex
+
 // updateLockAt reads, upserts, and writes the lock at the given path.
 func updateLockAt(path string, ref *Reference, resolved *ResolvedVersion) error {
+	lockUpdateMu.Lock()
+	defer lockUpdateMu.Unlock()
 	lock, err := ReadLockFile(path)
+	if IsSchemaTooNew(err) {
+		return err
+	}
 	if err != nil {
 		lock = nil
 	}
@@ -207,6 +207,35 @@ func TestRecordInstalledFiles(t *testing.T) {
 	}
 }
 
+func TestRecordInstalledFiles_RenderHashes(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, ".ailloy", "installed.yaml")
+	seed := &InstalledManifest{
+		APIVersion: "v1",
+		Molds:      []InstalledEntry{{Name: "test", Source: "github.com/x/y", Version: "v1", Commit: "c1"}},
+	}
+	if err := WriteInstalledManifest(manifestPath, seed); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []InstalledFile{
+		{RelPath: "replaced.md", SHA256: "same", RenderSHA256: "same"},
+		{RelPath: "merged.json", SHA256: "merged", RenderSHA256: "rendered"},
+	}
+	if err := RecordInstalledFiles(manifestPath, "github.com/x/y", "", files); err != nil {
+		t.Fatalf("RecordInstalledFiles: %v", err)
+	}
+
+	loaded, err := ReadInstalledManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"merged.json": "rendered"}
+	if got := loaded.Molds[0].RenderHashes; !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderHashes = %v, want only the merged file", got)
+	}
+}
+
 func TestRecordInstalledFiles_NoManifest(t *testing.T) {
 	err := RecordInstalledFiles("/nonexistent/.ailloy/installed.yaml", "github.com/x/y", "", []InstalledFile{{RelPath: "a"}})
 	if err == nil {
@@ -2,17 +2,160 @@ package index
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/github"
+	"github.com/nimble-giant/ailloy/pkg/scope"
 )
 
 // Config represents the ~/.ailloy/config.yaml structure.
 type Config struct {
+	// ConfigVersion is the layout version the file was written in; see
+	// CurrentConfigVersion. SaveConfigTo stamps the current one.
+	ConfigVersion int `yaml:"configVersion,omitempty"`
+
 	Foundries []FoundryEntry `yaml:"foundries,omitempty"`
+
+	// Profile is the default output profile (e.g. "cursor") applied to
+	// casts of molds that declare it, when --profile is not given.
+	Profile string `yaml:"profile,omitempty"`
+
+	// Foundry holds settings for resolving foundry references.
+	Foundry FoundrySettings `yaml:"foundry,omitempty"`
+
+	// Evolve holds settings for `ailloy evolve` self-upgrades.
+	Evolve EvolveSettings `yaml:"evolve,omitempty"`
+
+	// Exec limits the commands molds may run: flux discover commands and
+	// hook scripts.
+	Exec ExecSettings `yaml:"exec,omitempty"`
+
+	// GitHub holds settings for the GitHub API queries anneal makes.
+	GitHub GitHubSettings `yaml:"github,omitempty"`
+
+	// System holds foundries provisioned in the system scope's config.yaml.
+	// LoadConfig fills it; it is never written back to the user's config.
+	System []FoundryEntry `yaml:"-"`
+
+	// SystemMirrors holds the mirrors set in the system scope's config.yaml.
+	// Like System, it is never written back to the user's config.
+	SystemMirrors map[string]string `yaml:"-"`
+
+	// SystemExec holds the exec settings of the system scope's config.yaml,
+	// which bound the user's. Never written back to the user's config.
+	SystemExec ExecSettings `yaml:"-"`
+}
+
+// FoundrySettings is the `foundry:` block of config.yaml.
+type FoundrySettings struct {
+	// Resolution is the resolution policy: "always-fetch" (default) or
+	// "cache-first". See foundry.ResolutionCacheFirst.
+	Resolution string `yaml:"resolution,omitempty"`
+
+	// Mirrors maps a foundry host to the mirror that serves its
+	// repositories, e.g. github.com: git.internal.corp/mirror. See
+	// foundry.SetMirrors.
+	Mirrors map[string]string `yaml:"mirrors,omitempty"`
+}
+
+// EvolveSettings is the `evolve:` block of config.yaml.
+type EvolveSettings struct {
+	// Channel is the default release channel: "stable" or "beta".
+	Channel string `yaml:"channel,omitempty"`
+
+	// Notify opts in to a one-line notice, checked at most once a day,
+	// when a newer release is available on Channel.
+	Notify bool `yaml:"notify,omitempty"`
+}
+
+// ExecSettings is the `exec:` block of config.yaml.
+type ExecSettings struct {
+	// Disabled refuses every mold-supplied command, like --no-exec.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Allow lists the binaries mold commands may invoke, by base name.
+	// Empty allows any binary once the mold's commands are approved.
+	Allow []string `yaml:"allow,omitempty"`
+}
+
+// GitHubSettings is the `github:` block of config.yaml.
+type GitHubSettings struct {
+	// RetryBudget is how long in total a query waits out rate limits
+	// before giving up, as a Go duration ("90s", "5m"); "0s" disables
+	// retries. Empty means github.DefaultRetryBudget.
+	RetryBudget string `yaml:"retryBudget,omitempty"`
+}
+
+// EffectiveExec combines the user's exec settings with the system
+// scope's: either can disable execution, and a system allowlist caps the
+// user's (only binaries on both are allowed; the system list alone when
+// the user has none).
+func (c *Config) EffectiveExec() ExecSettings {
+	eff := ExecSettings{
+		Disabled: c.Exec.Disabled || c.SystemExec.Disabled,
+		Allow:    c.Exec.Allow,
+	}
+	if sys := c.SystemExec.Allow; len(sys) > 0 {
+		if len(eff.Allow) == 0 {
+			eff.Allow = sys
+		} else {
+			eff.Allow = slices.DeleteFunc(slices.Clone(eff.Allow), func(bin string) bool { return !slices.Contains(sys, bin) })
+			if len(eff.Allow) == 0 {
+				// Nothing is on both lists; an empty list would allow anything.
+				eff.Disabled = true
+			}
+		}
+	}
+	return eff
+}
+
+// ResolutionPolicy returns the configured foundry resolution policy,
+// defaulting to foundry.ResolutionAlwaysFetch, or an error naming the
+// accepted values when config.yaml holds anything else.
+func (c *Config) ResolutionPolicy() (string, error) {
+	switch c.Foundry.Resolution {
+	case "", foundry.ResolutionAlwaysFetch:
+		return foundry.ResolutionAlwaysFetch, nil
+	case foundry.ResolutionCacheFirst:
+		return foundry.ResolutionCacheFirst, nil
+	}
+	return "", fmt.Errorf("invalid foundry.resolution %q in config.yaml: use %s or %s",
+		c.Foundry.Resolution, foundry.ResolutionCacheFirst, foundry.ResolutionAlwaysFetch)
+}
+
+// GitHubRetryBudget returns the configured github.retryBudget, defaulting to
+// github.DefaultRetryBudget, or an error when it is not a non-negative
+// duration.
+func (c *Config) GitHubRetryBudget() (time.Duration, error) {
+	if c.GitHub.RetryBudget == "" {
+		return github.DefaultRetryBudget, nil
+	}
+	d, err := time.ParseDuration(c.GitHub.RetryBudget)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid github.retryBudget %q in config.yaml: use a duration such as 90s or 5m", c.GitHub.RetryBudget)
+	}
+	return d, nil
+}
+
+// MirrorRules returns the host rewrite rules in effect: the system scope's
+// mirrors, overridden host by host by the user's.
+func (c *Config) MirrorRules() map[string]string {
+	if len(c.SystemMirrors) == 0 && len(c.Foundry.Mirrors) == 0 {
+		return nil
+	}
+	rules := maps.Clone(c.SystemMirrors)
+	if rules == nil {
+		rules = map[string]string{}
+	}
+	maps.Copy(rules, c.Foundry.Mirrors)
+	return rules
 }
 
 // FoundryEntry tracks a registered foundry with metadata.
@@ -24,11 +167,6 @@ type FoundryEntry struct {
 	Status      string    `yaml:"status,omitempty"` // "ok", "error", "pending"
 }
 
-// legacyConfig represents the old config format with plain string URLs.
-type legacyConfig struct {
-	Foundries []string `yaml:"foundries,omitempty"`
-}
-
 // ConfigPath returns the path to ~/.ailloy/config.yaml.
 func ConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
@@ -38,14 +176,38 @@ func ConfigPath() (string, error) {
 	return filepath.Join(home, ".ailloy", "config.yaml"), nil
 }
 
-// LoadConfig reads and parses ~/.ailloy/config.yaml.
-// It auto-migrates the old string-list format to the new FoundryEntry format.
+// SystemConfigPath returns the system scope's config.yaml, or "" when the
+// machine has no system scope.
+func SystemConfigPath() string {
+	root := scope.SystemRoot()
+	if root == "" {
+		return ""
+	}
+	return filepath.Join(root, "config.yaml")
+}
+
+// LoadConfig reads and parses ~/.ailloy/config.yaml, plus any foundries and
+// mirrors provisioned in the system scope (see Config.System).
+// Files in an older layout are upgraded in memory; see MigrateConfigData.
 func LoadConfig() (*Config, error) {
 	configPath, err := ConfigPath()
 	if err != nil {
 		return nil, err
 	}
-	return LoadConfigFrom(configPath)
+	cfg, err := LoadConfigFrom(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if sysPath := SystemConfigPath(); sysPath != "" {
+		sys, err := LoadConfigFrom(sysPath)
+		if err != nil {
+			return nil, fmt.Errorf("system config %s: %w", sysPath, err)
+		}
+		cfg.System = sys.Foundries
+		cfg.SystemMirrors = sys.Foundry.Mirrors
+		cfg.SystemExec = sys.Exec
+	}
+	return cfg, nil
 }
 
 // LoadConfigFrom reads and parses config from a specific path.
@@ -58,31 +220,12 @@ func LoadConfigFrom(path string) (*Config, error) {
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
 
-	// Try the new format first.
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err == nil && len(cfg.Foundries) > 0 {
-		// Check if we actually got FoundryEntry structs (URL field populated).
-		if cfg.Foundries[0].URL != "" {
-			return &cfg, nil
-		}
-	}
-
-	// Fall back to the legacy string-list format.
-	var legacy legacyConfig
-	if err := yaml.Unmarshal(data, &legacy); err != nil {
+	cfg, migration, err := parseConfig(data)
+	if err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
-
-	migrated := &Config{}
-	for _, url := range legacy.Foundries {
-		migrated.Foundries = append(migrated.Foundries, FoundryEntry{
-			Name:   nameFromURL(url),
-			URL:    url,
-			Type:   DetectType(url),
-			Status: "pending",
-		})
-	}
-	return migrated, nil
+	warnConfigMigration(path, migration)
+	return cfg, nil
 }
 
 // SaveConfig writes the config to ~/.ailloy/config.yaml.
@@ -100,7 +243,9 @@ func SaveConfigTo(cfg *Config, path string) error {
 		return fmt.Errorf("creating config directory: %w", err)
 	}
 
-	data, err := yaml.Marshal(cfg)
+	stamped := *cfg
+	stamped.ConfigVersion = CurrentConfigVersion
+	data, err := yaml.Marshal(&stamped)
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
@@ -123,21 +268,39 @@ func OfficialFoundryEntry() FoundryEntry {
 }
 
 // EffectiveFoundries returns the configured foundries with the official
-// foundry prepended if it isn't already registered. The returned slice is
-// safe to iterate but should not be saved back to disk — only entries
-// originating from the user's config should be persisted.
+// foundry prepended if it isn't already registered, followed by any
+// system-provisioned foundries the user hasn't registered themselves. The
+// returned slice is safe to iterate but should not be saved back to disk —
+// only entries originating from the user's config should be persisted.
 func (c *Config) EffectiveFoundries() []FoundryEntry {
-	for _, entry := range c.Foundries {
-		if IsOfficialFoundry(entry.URL) {
-			return c.Foundries
-		}
+	out := make([]FoundryEntry, 0, len(c.Foundries)+len(c.System)+1)
+	if !c.HasOfficialFoundry() && !c.IsSystemFoundry(OfficialFoundryURL) {
+		out = append(out, OfficialFoundryEntry())
 	}
-	out := make([]FoundryEntry, 0, len(c.Foundries)+1)
-	out = append(out, OfficialFoundryEntry())
 	out = append(out, c.Foundries...)
+	for _, entry := range c.System {
+		if c.FindFoundry(entry.URL) == nil {
+			out = append(out, entry)
+		}
+	}
 	return out
 }
 
+// IsSystemFoundry reports whether nameOrURL names a foundry provisioned in
+// the system scope and not also registered in the user's config.
+func (c *Config) IsSystemFoundry(nameOrURL string) bool {
+	if c.FindFoundry(nameOrURL) != nil {
+		return false
+	}
+	for _, entry := range c.System {
+		if strings.EqualFold(entry.Name, nameOrURL) || strings.EqualFold(entry.URL, nameOrURL) ||
+			(IsOfficialFoundry(nameOrURL) && IsOfficialFoundry(entry.URL)) {
+			return true
+		}
+	}
+	return false
+}
+
 // FoundryForSource returns the foundry entry whose cached index lists a
 // mold matching the given source, or nil if none does. Iterates effective
 // foundries (verified default + user-registered); first match wins.
@@ -0,0 +1,256 @@
+package index
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/yamlcheck"
+)
+
+// CurrentConfigVersion is the config.yaml layout this build reads and
+// writes. Files without a configVersion are version 0.
+const CurrentConfigVersion = 1
+
+// configMigration upgrades a config.yaml document from version From to
+// From+1. Apply returns a note for each change it made; a migration with
+// nothing to do returns none.
+type configMigration struct {
+	From  int
+	Apply func(doc yaml.MapSlice) (yaml.MapSlice, []string)
+}
+
+// configMigrations is the ordered upgrade chain. A breaking layout change
+// bumps CurrentConfigVersion and appends a migration here, so older files
+// keep loading instead of silently losing the values they hold.
+var configMigrations = []configMigration{
+	{From: 0, Apply: migrateConfigV0},
+}
+
+// retiredConfigKeys explains top-level keys earlier layouts used that have
+// no equivalent in the current one.
+var retiredConfigKeys = map[string]string{
+	"templates": "template variables are no longer read from config.yaml; move them to a flux file passed with -f, or to ~/.ailloy/flux/<mold>.yaml to apply them to every cast of a mold",
+}
+
+// ConfigMigration is the outcome of upgrading a config.yaml document.
+type ConfigMigration struct {
+	// From is the version the document declared.
+	From int
+	// Changes describes each rewrite applied, empty when the layout was
+	// already current.
+	Changes []string
+	// Unknown lists the keys the current layout does not read, located in
+	// the upgraded document. Migration keeps them.
+	Unknown []yamlcheck.UnknownField
+	// Document is the upgraded document, stamped with CurrentConfigVersion.
+	Document yaml.MapSlice
+}
+
+// MigrateConfigData upgrades raw config.yaml content to
+// CurrentConfigVersion. Documents from a newer ailloy are rejected rather
+// than read with their new settings dropped.
+func MigrateConfigData(data []byte) (*ConfigMigration, error) {
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(data, &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	m := &ConfigMigration{}
+	if v, ok := lookupMapSlice(doc, "configVersion"); ok {
+		n, ok := configVersionNumber(v)
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("invalid configVersion %v", v)
+		}
+		m.From = n
+	}
+	if m.From > CurrentConfigVersion {
+		return nil, fmt.Errorf("configVersion %d is newer than this ailloy supports (%d); upgrade with `ailloy evolve`",
+			m.From, CurrentConfigVersion)
+	}
+	for _, mig := range configMigrations {
+		if mig.From < m.From {
+			continue
+		}
+		var changes []string
+		doc, changes = mig.Apply(doc)
+		m.Changes = append(m.Changes, changes...)
+	}
+	if len(doc) > 0 {
+		doc = setMapSlice(doc, "configVersion", CurrentConfigVersion)
+		// Keep the version first so it is the first thing a reader sees.
+		i := slices.IndexFunc(doc, func(item yaml.MapItem) bool { return fmt.Sprint(item.Key) == "configVersion" })
+		version := doc[i]
+		doc = append(yaml.MapSlice{version}, slices.Delete(doc, i, i+1)...)
+	}
+	m.Document = doc
+
+	// Locate unknown keys in the file as written when no migration touched
+	// it, so their line numbers match what the user sees.
+	src := data
+	if len(m.Changes) > 0 {
+		var err error
+		if src, err = yaml.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	unknown, err := yamlcheck.UnknownFields(src, &Config{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range unknown {
+		if len(m.Changes) > 0 {
+			unknown[i].Line = 0 // a line in the upgraded document, not the file
+		}
+	}
+	m.Unknown = unknown
+	return m, nil
+}
+
+// ParseConfig decodes config.yaml content in any supported layout.
+func ParseConfig(data []byte) (*Config, error) {
+	cfg, _, err := parseConfig(data)
+	return cfg, err
+}
+
+func parseConfig(data []byte) (*Config, *ConfigMigration, error) {
+	m, err := MigrateConfigData(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cfg Config
+	if len(m.Document) == 0 {
+		return &cfg, m, nil
+	}
+	upgraded, err := yaml.Marshal(m.Document)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := yaml.Unmarshal(upgraded, &cfg); err != nil {
+		return nil, nil, err
+	}
+	return &cfg, m, nil
+}
+
+// warnedConfigs records the files whose migration warnings were printed, so
+// repeated loads in one run stay quiet.
+var warnedConfigs sync.Map
+
+// warnConfigMigration prints, once per path, that the file at path uses an
+// old layout or holds keys the current layout ignores.
+func warnConfigMigration(path string, m *ConfigMigration) {
+	if len(m.Changes) == 0 && len(m.Unknown) == 0 {
+		return
+	}
+	if _, seen := warnedConfigs.LoadOrStore(path, true); seen {
+		return
+	}
+	if len(m.Changes) > 0 {
+		log.Printf("warning: %s uses config layout version %d; it was upgraded in memory, run `ailloy config migrate` to update the file", path, m.From)
+	}
+	for _, f := range m.Unknown {
+		at := path
+		if f.Line > 0 {
+			at = fmt.Sprintf("%s:%d", path, f.Line)
+		}
+		top, _, _ := strings.Cut(f.Path, ".")
+		switch {
+		case retiredConfigKeys[top] != "":
+			log.Printf("warning: %s: %s is ignored: %s", at, f.Path, retiredConfigKeys[top])
+		case f.Suggestion != "":
+			log.Printf("warning: %s: unknown key %s is ignored (did you mean %s?)", at, f.Path, f.Suggestion)
+		default:
+			log.Printf("warning: %s: unknown key %s is ignored", at, f.Path)
+		}
+	}
+}
+
+// migrateConfigV0 upgrades unversioned files: foundries listed as plain
+// URLs become entries, and dotted top-level keys (evolve.channel: beta)
+// are nested the way the loader reads them.
+func migrateConfigV0(doc yaml.MapSlice) (yaml.MapSlice, []string) {
+	var changes []string
+
+	var flat yaml.MapSlice
+	doc = slices.DeleteFunc(doc, func(item yaml.MapItem) bool {
+		key := fmt.Sprint(item.Key)
+		if strings.Contains(key, ".") && !strings.HasPrefix(key, ".") && !strings.HasSuffix(key, ".") {
+			flat = append(flat, item)
+			return true
+		}
+		return false
+	})
+	for _, item := range flat {
+		key := fmt.Sprint(item.Key)
+		doc = setMapSlice(doc, key, item.Value)
+		changes = append(changes, fmt.Sprintf("nested flat key %q", key))
+	}
+
+	if v, ok := lookupMapSlice(doc, "foundries"); ok {
+		if list, ok := v.([]any); ok {
+			converted := 0
+			for i, f := range list {
+				url, ok := f.(string)
+				if !ok {
+					continue
+				}
+				list[i] = yaml.MapSlice{
+					{Key: "name", Value: nameFromURL(url)},
+					{Key: "url", Value: url},
+					{Key: "type", Value: DetectType(url)},
+					{Key: "status", Value: "pending"},
+				}
+				converted++
+			}
+			if converted > 0 {
+				changes = append(changes, fmt.Sprintf("converted %d foundry URL(s) to foundry entries", converted))
+			}
+		}
+	}
+	return doc, changes
+}
+
+func configVersionNumber(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func lookupMapSlice(doc yaml.MapSlice, key string) (any, bool) {
+	for _, item := range doc {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// setMapSlice sets the dotted key in doc, creating or merging into nested
+// maps along the way.
+func setMapSlice(doc yaml.MapSlice, key string, value any) yaml.MapSlice {
+	head, rest, nested := strings.Cut(key, ".")
+	for i, item := range doc {
+		if fmt.Sprint(item.Key) != head {
+			continue
+		}
+		if !nested {
+			doc[i].Value = value
+		} else {
+			child, _ := item.Value.(yaml.MapSlice)
+			doc[i].Value = setMapSlice(child, rest, value)
+		}
+		return doc
+	}
+	if !nested {
+		return append(doc, yaml.MapItem{Key: head, Value: value})
+	}
+	return append(doc, yaml.MapItem{Key: head, Value: setMapSlice(nil, rest, value)})
+}
@@ -0,0 +1,107 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestMigrateConfigData_V0(t *testing.T) {
+	data := []byte(`foundries:
+  - https://github.com/acme/idx
+evolve.channel: beta
+templates:
+  variables:
+    org: acme
+`)
+	m, err := MigrateConfigData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.From != 0 {
+		t.Errorf("From = %d, want 0", m.From)
+	}
+	if len(m.Changes) != 2 {
+		t.Errorf("Changes = %v, want nested key and foundry conversion", m.Changes)
+	}
+	if got := unknownPaths(m); !slices.Equal(got, []string{"templates"}) {
+		t.Errorf("Unknown = %v, want [templates]", m.Unknown)
+	}
+	if got := m.Document[0]; got.Key != "configVersion" || got.Value != CurrentConfigVersion {
+		t.Errorf("first item = %v, want configVersion: %d", got, CurrentConfigVersion)
+	}
+
+	out, err := yaml.Marshal(m.Document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "org: acme") {
+		t.Errorf("unknown keys must be kept, got:\n%s", out)
+	}
+	cfg, err := ParseConfig(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ConfigVersion != CurrentConfigVersion || cfg.Evolve.Channel != "beta" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if len(cfg.Foundries) != 1 || cfg.Foundries[0].Name != "idx" || cfg.Foundries[0].Status != "pending" {
+		t.Errorf("Foundries = %+v", cfg.Foundries)
+	}
+}
+
+func TestMigrateConfigData_Current(t *testing.T) {
+	m, err := MigrateConfigData([]byte("configVersion: 1\nprofile: cursor\nfoundry:\n  resolution: cache-first\n  proxy: x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.From != CurrentConfigVersion || len(m.Changes) != 0 {
+		t.Errorf("From = %d, Changes = %v; want current and unchanged", m.From, m.Changes)
+	}
+	if len(m.Unknown) != 1 || m.Unknown[0].Path != "foundry.proxy" || m.Unknown[0].Line != 5 {
+		t.Errorf("Unknown = %+v, want foundry.proxy on line 5", m.Unknown)
+	}
+}
+
+func TestMigrateConfigData_SuggestsTypos(t *testing.T) {
+	m, err := MigrateConfigData([]byte("configVersion: 1\nevolve:\n  chanel: beta\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Unknown) != 1 || m.Unknown[0].Suggestion != "channel" {
+		t.Errorf("Unknown = %+v, want evolve.chanel with suggestion channel", m.Unknown)
+	}
+}
+
+func TestMigrateConfigData_RejectsNewerVersion(t *testing.T) {
+	_, err := MigrateConfigData([]byte("configVersion: 99\n"))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("err = %v, want newer-version error", err)
+	}
+}
+
+func TestSaveConfigTo_StampsVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := SaveConfigTo(&Config{Profile: "cursor"}, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "configVersion: 1\n") {
+		t.Errorf("saved config should start with configVersion, got:\n%s", data)
+	}
+}
+
+func unknownPaths(m *ConfigMigration) []string {
+	var paths []string
+	for _, f := range m.Unknown {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
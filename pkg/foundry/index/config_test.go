@@ -1,10 +1,14 @@
 package index
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/github"
 )
 
 func TestLoadConfigFrom_NewFormat(t *testing.T) {
@@ -81,6 +85,96 @@ func TestLoadConfigFrom_LegacyFormat(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFrom_Profile(t *testing.T) {
+	for name, content := range map[string]string{
+		"with foundries": "profile: cursor\nfoundries:\n  - name: f\n    url: https://github.com/test/f\n    type: git\n",
+		"profile only":   "profile: cursor\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfigFrom(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Profile != "cursor" {
+				t.Errorf("Profile = %q, want %q", cfg.Profile, "cursor")
+			}
+		})
+	}
+}
+
+func TestLoadConfigFrom_ResolutionPolicy(t *testing.T) {
+	for name, tt := range map[string]struct {
+		content, want string
+		wantErr       bool
+	}{
+		"with foundries": {content: "foundry:\n  resolution: cache-first\nfoundries:\n  - name: f\n    url: https://github.com/test/f\n    type: git\n", want: "cache-first"},
+		"settings only":  {content: "foundry:\n  resolution: cache-first\n", want: "cache-first"},
+		"unset":          {content: "profile: cursor\n", want: "always-fetch"},
+		"invalid":        {content: "foundry:\n  resolution: sometimes\n", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfigFrom(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := cfg.ResolutionPolicy()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "cache-first") {
+					t.Errorf("expected an error naming the accepted values, got %v", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ResolutionPolicy() = (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_GitHubRetryBudget(t *testing.T) {
+	for value, want := range map[string]time.Duration{"": github.DefaultRetryBudget, "90s": 90 * time.Second, "0s": 0} {
+		cfg := &Config{GitHub: GitHubSettings{RetryBudget: value}}
+		if got, err := cfg.GitHubRetryBudget(); err != nil || got != want {
+			t.Errorf("GitHubRetryBudget(%q) = (%v, %v), want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"soon", "-1m"} {
+		cfg := &Config{GitHub: GitHubSettings{RetryBudget: value}}
+		if _, err := cfg.GitHubRetryBudget(); err == nil || !strings.Contains(err.Error(), "github.retryBudget") {
+			t.Errorf("GitHubRetryBudget(%q) error = %v, want one naming the key", value, err)
+		}
+	}
+}
+
+func TestConfig_EffectiveExec(t *testing.T) {
+	for name, tt := range map[string]struct {
+		user, system ExecSettings
+		want         ExecSettings
+	}{
+		"unset":             {want: ExecSettings{}},
+		"user only":         {user: ExecSettings{Allow: []string{"gh"}}, want: ExecSettings{Allow: []string{"gh"}}},
+		"system only":       {system: ExecSettings{Allow: []string{"gh", "jq"}}, want: ExecSettings{Allow: []string{"gh", "jq"}}},
+		"system caps user":  {user: ExecSettings{Allow: []string{"gh", "curl"}}, system: ExecSettings{Allow: []string{"gh", "jq"}}, want: ExecSettings{Allow: []string{"gh"}}},
+		"disjoint disables": {user: ExecSettings{Allow: []string{"curl"}}, system: ExecSettings{Allow: []string{"gh"}}, want: ExecSettings{Disabled: true, Allow: []string{}}},
+		"system disables":   {user: ExecSettings{Allow: []string{"gh"}}, system: ExecSettings{Disabled: true}, want: ExecSettings{Disabled: true, Allow: []string{"gh"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := (&Config{Exec: tt.user, SystemExec: tt.system}).EffectiveExec()
+			if got.Disabled != tt.want.Disabled || strings.Join(got.Allow, ",") != strings.Join(tt.want.Allow, ",") {
+				t.Errorf("EffectiveExec() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestLoadConfigFrom_NotFound(t *testing.T) {
 	cfg, err := LoadConfigFrom("/nonexistent/config.yaml")
 	if err != nil {
@@ -256,6 +350,59 @@ func TestEffectiveFoundries_EmptyConfig(t *testing.T) {
 	}
 }
 
+func TestEffectiveFoundries_AppendsSystemFoundries(t *testing.T) {
+	cfg := &Config{
+		Foundries: []FoundryEntry{{Name: "mine", URL: "https://github.com/me/foundry"}},
+		System: []FoundryEntry{
+			{Name: "org", URL: "https://github.com/org/foundry"},
+			{Name: "mine-too", URL: "https://github.com/me/foundry"},
+		},
+	}
+
+	effective := cfg.EffectiveFoundries()
+	if len(effective) != 3 {
+		t.Fatalf("len = %d, want 3 (official, mine, org): %+v", len(effective), effective)
+	}
+	if effective[2].Name != "org" {
+		t.Errorf("effective[2].Name = %q, want org", effective[2].Name)
+	}
+	if !cfg.IsSystemFoundry("org") || cfg.IsSystemFoundry("https://github.com/me/foundry") {
+		t.Error("IsSystemFoundry should be true only for entries the user hasn't registered")
+	}
+}
+
+func TestLoadConfig_ReadsSystemScope(t *testing.T) {
+	home := t.TempDir()
+	system := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AILLOY_SYSTEM_ROOT", system)
+
+	sys := &Config{Foundries: []FoundryEntry{{Name: "org", URL: "https://github.com/org/foundry", Type: "git"}}}
+	if err := SaveConfigTo(sys, filepath.Join(system, "config.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.Foundries) != 0 || len(cfg.System) != 1 {
+		t.Fatalf("Foundries=%v System=%v", cfg.Foundries, cfg.System)
+	}
+
+	// Saving the user's config must not copy system entries into it.
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadConfigFrom(filepath.Join(home, ".ailloy", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Foundries) != 0 {
+		t.Errorf("system foundries leaked into the user config: %v", reloaded.Foundries)
+	}
+}
+
 func TestFoundryForSource(t *testing.T) {
 	cacheDir := t.TempDir()
 	t.Setenv("AILLOY_INDEX_CACHE_DIR", cacheDir)
@@ -378,3 +525,25 @@ func TestNameFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_MirrorRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "foundry:\n  mirrors:\n    github.com: git.internal.corp/mirror\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfigFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SystemMirrors = map[string]string{"github.com": "system.corp/gh", "gitlab.com": "system.corp/gl"}
+
+	got := cfg.MirrorRules()
+	want := map[string]string{"github.com": "git.internal.corp/mirror", "gitlab.com": "system.corp/gl"}
+	if !maps.Equal(got, want) {
+		t.Errorf("MirrorRules() = %v, want %v", got, want)
+	}
+	if (&Config{}).MirrorRules() != nil {
+		t.Error("no mirrors should yield nil")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 )
 
 // GitRunner executes a git command and returns its combined output.
@@ -68,14 +70,26 @@ func (f *Fetcher) fetchGitIndex(entry *FoundryEntry) (*Index, error) {
 			return nil, fmt.Errorf("git fetch %s: %w", entry.URL, classifyGitError(err, out))
 		}
 	} else {
-		// Create bare clone.
-		if err := os.MkdirAll(filepath.Dir(bareDir), 0750); err != nil {
-			return nil, fmt.Errorf("creating cache directory: %w", err)
+		// Create bare clone in scratch space and move it into place, so an
+		// interrupted clone never leaves a HEAD-bearing partial repo behind.
+		staging, err := tmpdir.MkdirTemp("index-clone-*")
+		if err != nil {
+			return nil, fmt.Errorf("creating staging directory: %w", err)
 		}
-		out, err := f.git("clone", "--bare", entry.URL, bareDir)
+		defer func() { _ = os.RemoveAll(staging) }()
+		staged := filepath.Join(staging, "git")
+		out, err := f.git("clone", "--bare", entry.URL, staged)
 		if err != nil {
 			return nil, fmt.Errorf("git clone %s: %w", entry.URL, classifyGitError(err, out))
 		}
+		if err := os.RemoveAll(bareDir); err != nil {
+			return nil, fmt.Errorf("removing incomplete clone %s: %w", bareDir, err)
+		}
+		if err := tmpdir.Commit(staged, bareDir); err != nil {
+			if _, serr := os.Stat(filepath.Join(bareDir, "HEAD")); serr != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Read foundry.yaml from HEAD using git show.
@@ -94,10 +108,7 @@ func (f *Fetcher) fetchGitIndex(entry *FoundryEntry) (*Index, error) {
 
 	// Cache the raw YAML for offline use.
 	cachePath := CachedIndexPath(f.cacheDir, entry)
-	if err := os.MkdirAll(filepath.Dir(cachePath), 0750); err != nil {
-		return nil, fmt.Errorf("creating cache directory: %w", err)
-	}
-	if err := os.WriteFile(cachePath, out, 0644); err != nil { // #nosec G306 -- cache file
+	if err := tmpdir.WriteFileAtomic(cachePath, out, 0644); err != nil {
 		return nil, fmt.Errorf("writing cache: %w", err)
 	}
 
@@ -146,10 +157,7 @@ func (f *Fetcher) fetchURLIndex(entry *FoundryEntry) (*Index, error) {
 
 	// Cache the raw YAML.
 	cachePath := CachedIndexPath(f.cacheDir, entry)
-	if err := os.MkdirAll(filepath.Dir(cachePath), 0750); err != nil {
-		return nil, fmt.Errorf("creating cache directory: %w", err)
-	}
-	if err := os.WriteFile(cachePath, data, 0644); err != nil { // #nosec G306 -- cache file
+	if err := tmpdir.WriteFileAtomic(cachePath, data, 0644); err != nil {
 		return nil, fmt.Errorf("writing cache: %w", err)
 	}
 
@@ -23,16 +23,13 @@ molds:
 	git := func(args ...string) ([]byte, error) {
 		key := strings.Join(args, " ")
 		if strings.Contains(key, "clone --bare") {
-			// Create a fake bare clone directory with HEAD.
-			for _, arg := range args {
-				if strings.HasPrefix(arg, cacheDir) {
-					if err := os.MkdirAll(arg, 0750); err != nil {
-						return nil, err
-					}
-					if err := os.WriteFile(filepath.Join(arg, "HEAD"), []byte("ref: refs/heads/main"), 0644); err != nil {
-						return nil, err
-					}
-				}
+			// Create a fake bare clone directory with HEAD at the clone target.
+			target := args[len(args)-1]
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(filepath.Join(target, "HEAD"), []byte("ref: refs/heads/main"), 0644); err != nil {
+				return nil, err
 			}
 			return []byte("Cloning..."), nil
 		}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry"
 )
 
 // OfficialFoundryURL is the URL of the official nimble-giant foundry.
@@ -158,12 +160,10 @@ func CacheFirstLookup(cacheDir string, fetcher *Fetcher) IndexLookup {
 
 // defaultGitRunnerForSearch returns a GitRunner used by the cache-fallback
 // fetcher inside searchIndexes. Defined locally to avoid importing
-// internal/commands from the index package.
+// internal/commands from the index package; it shares foundry's runner so
+// configured mirrors apply.
 func defaultGitRunnerForSearch() GitRunner {
-	return func(args ...string) ([]byte, error) {
-		cmd := exec.Command("git", args...) // #nosec G204 -- args are constructed internally
-		return cmd.CombinedOutput()
-	}
+	return GitRunner(foundry.DefaultGitRunner())
 }
 
 // matchesMold checks if a mold entry matches the search query.
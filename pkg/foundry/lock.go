@@ -12,6 +12,11 @@ import (
 // LockFileName is the default lock file name.
 const LockFileName = "ailloy.lock"
 
+// LockSchemaVersion is the newest ailloy.lock schema this binary reads and
+// writes. Bump it (and extend migrateLock) whenever the format changes in a
+// way older releases would mishandle.
+const LockSchemaVersion = 1
+
 // LockEntry records the resolved version of a single mold dependency.
 // File-level provenance (which files were rendered and their hashes) lives
 // on InstalledEntry, not here — that keeps uninstall working when the lock
@@ -33,14 +38,17 @@ type LockEntry struct {
 
 // LockFile is the on-disk lock file format.
 type LockFile struct {
-	APIVersion string      `yaml:"apiVersion"`
-	Molds      []LockEntry `yaml:"molds"`
-	Ingots     []LockEntry `yaml:"ingots,omitempty"`
-	Ores       []LockEntry `yaml:"ores,omitempty"`
+	APIVersion    string      `yaml:"apiVersion"`
+	SchemaVersion int         `yaml:"schemaVersion"`
+	Molds         []LockEntry `yaml:"molds"`
+	Ingots        []LockEntry `yaml:"ingots,omitempty"`
+	Ores          []LockEntry `yaml:"ores,omitempty"`
 }
 
 // ReadLockFile reads and parses the lock file at the given path.
-// Returns nil, nil if the file does not exist.
+// Returns nil, nil if the file does not exist. A lock written with a newer
+// schema than LockSchemaVersion is refused with a *SchemaError; older ones
+// are migrated in memory and persisted on the next write.
 func ReadLockFile(path string) (*LockFile, error) {
 	data, err := os.ReadFile(path) //#nosec G304 -- path is constructed from known working directory
 	if err != nil {
@@ -54,11 +62,32 @@ func ReadLockFile(path string) (*LockFile, error) {
 	if err := yaml.Unmarshal(data, &lf); err != nil {
 		return nil, fmt.Errorf("parsing lock file: %w", err)
 	}
+	if err := CheckSchemaVersion(path, lf.SchemaVersion, LockSchemaVersion); err != nil {
+		return nil, err
+	}
+	migrateLock(&lf)
 	return &lf, nil
 }
 
-// WriteLockFile marshals and writes the lock file to the given path.
+// migrateLock upgrades a lock read from an older schema to the current one.
+//
+//	0 → 1: unversioned locks gain schemaVersion; a missing apiVersion
+//	       defaults to v1.
+func migrateLock(lf *LockFile) {
+	if lf.SchemaVersion < 1 && lf.APIVersion == "" {
+		lf.APIVersion = "v1"
+	}
+	lf.SchemaVersion = LockSchemaVersion
+}
+
+// WriteLockFile marshals and writes the lock file to the given path, stamped
+// with LockSchemaVersion. It refuses to overwrite a lock written by a newer
+// ailloy, since re-marshaling would drop fields this binary doesn't know.
 func WriteLockFile(path string, lock *LockFile) error {
+	if err := CheckSchemaVersion(path, PeekSchemaVersion(path), LockSchemaVersion); err != nil {
+		return err
+	}
+	migrateLock(lock)
 	data, err := yaml.Marshal(lock)
 	if err != nil {
 		return fmt.Errorf("marshaling lock file: %w", err)
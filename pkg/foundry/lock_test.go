@@ -1,8 +1,10 @@
 package foundry
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -224,6 +226,46 @@ molds:
 	}
 }
 
+func TestReadLockFile_MigratesUnversioned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ailloy.lock")
+	if err := os.WriteFile(path, []byte("molds:\n  - name: m\n    source: g/m\n    version: 1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadLockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SchemaVersion != LockSchemaVersion || got.APIVersion != "v1" {
+		t.Errorf("migrated lock = schema %d, apiVersion %q", got.SchemaVersion, got.APIVersion)
+	}
+	if err := WriteLockFile(path, got); err != nil {
+		t.Fatal(err)
+	}
+	if v := PeekSchemaVersion(path); v != LockSchemaVersion {
+		t.Errorf("written schemaVersion = %d, want %d", v, LockSchemaVersion)
+	}
+}
+
+func TestLockFile_RefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ailloy.lock")
+	newer := fmt.Sprintf("apiVersion: v1\nschemaVersion: %d\nmolds: []\nfutureField: keep-me\n", LockSchemaVersion+1)
+	if err := os.WriteFile(path, []byte(newer), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadLockFile(path); !IsSchemaTooNew(err) {
+		t.Fatalf("ReadLockFile err = %v, want SchemaError", err)
+	}
+	err := WriteLockFile(path, &LockFile{APIVersion: "v1"})
+	if !IsSchemaTooNew(err) || !strings.Contains(err.Error(), "ailloy evolve") {
+		t.Fatalf("WriteLockFile err = %v, want SchemaError with upgrade guidance", err)
+	}
+	data, _ := os.ReadFile(path) // #nosec G304 -- test temp path
+	if string(data) != newer {
+		t.Error("newer lock must be left untouched")
+	}
+}
+
 func TestLockFile_UpsertArtifactLockIdempotent(t *testing.T) {
 	lf := &LockFile{APIVersion: "v1"}
 	e := LockEntry{Name: "status", Source: "g/status-ore", Version: "1.0.0"}
@@ -21,12 +21,26 @@ const InstalledManifestPath = ".ailloy/installed.yaml"
 // intentionally NOT recorded — they are recovery / context-selection flags,
 // not stable preferences.
 type CastOptionsRecord struct {
-	WithWorkflows bool     `yaml:"withWorkflows,omitempty"`
-	ValueFiles    []string `yaml:"valueFiles,omitempty"`
+	WithWorkflows bool     `json:"withWorkflows,omitempty" yaml:"withWorkflows,omitempty"`
+	ValueFiles    []string `json:"valueFiles,omitempty" yaml:"valueFiles,omitempty"`
 	// SetOverrides stores raw `key=value` strings so consumers can re-parse
 	// them through the same --set parser. Do not convert to a map: that
 	// would silently collapse duplicate keys.
-	SetOverrides []string `yaml:"setOverrides,omitempty"`
+	SetOverrides []string `json:"setOverrides,omitempty" yaml:"setOverrides,omitempty"`
+	// SetFiles and SetJSON store the --set-file key=path and --set-json
+	// key=json flags apart from SetOverrides, so a replayed --set never
+	// reads a file.
+	SetFiles []string `json:"setFiles,omitempty" yaml:"setFiles,omitempty"`
+	SetJSON  []string `json:"setJSON,omitempty" yaml:"setJSON,omitempty"`
+	// Profile is the output profile selected with --profile, if any.
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// Only and Exclude are the --only/--exclude selection the mold was
+	// cast with (see mold.Selection).
+	Only    []string `json:"only,omitempty" yaml:"only,omitempty"`
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// CI is the CI system named with --ci, whose workflow blanks were cast.
+	// Empty with WithWorkflows means the system is detected on each cast.
+	CI string `json:"ci,omitempty" yaml:"ci,omitempty"`
 }
 
 // InstalledEntry records a mold that was cast into the project.
@@ -35,6 +49,13 @@ type CastOptionsRecord struct {
 // post-cast modifications. They are intentionally on the manifest (not the
 // lock) so uninstall keeps working when ailloy.lock has not been opted into.
 //
+// RenderHashes holds the sha256 of what the mold rendered for a file when
+// that differs from the on-disk hash (merge/append strategies fold the
+// render into an existing file); `status` compares fresh renders against it
+// to spot outdated files. Ref is the reference as requested at cast time
+// (e.g. host/owner/repo@^1.2//sub) so the originating constraint can be
+// re-resolved later.
+//
 // InstalledAs distinguishes molds the user cast directly ("direct") from
 // molds installed because some other mold depends on them ("transitive").
 // InstalledBy mirrors ArtifactEntry.Dependents: it lists the parent molds
@@ -42,17 +63,24 @@ type CastOptionsRecord struct {
 // cascade-uninstall — when a transitive's last parent goes away, it can
 // be GC'd. Direct molds are never garbage-collected by the cascade.
 type InstalledEntry struct {
-	Name        string             `yaml:"name"`
-	Source      string             `yaml:"source"`
-	Subpath     string             `yaml:"subpath,omitempty"`
-	Version     string             `yaml:"version"`
-	Commit      string             `yaml:"commit"`
-	CastAt      time.Time          `yaml:"castAt"`
-	Files       []string           `yaml:"files,omitempty"`
-	FileHashes  map[string]string  `yaml:"fileHashes,omitempty"`
-	CastOptions *CastOptionsRecord `yaml:"castOptions,omitempty"`
-	InstalledAs string             `yaml:"installedAs,omitempty"` // "direct" | "transitive"
-	InstalledBy []string           `yaml:"installedBy,omitempty"` // parent mold source[@subpath] strings
+	Name         string             `yaml:"name"`
+	Source       string             `yaml:"source"`
+	Subpath      string             `yaml:"subpath,omitempty"`
+	Ref          string             `yaml:"ref,omitempty"`
+	Version      string             `yaml:"version"`
+	Commit       string             `yaml:"commit"`
+	CastAt       time.Time          `yaml:"castAt"`
+	Files        []string           `yaml:"files,omitempty"`
+	FileHashes   map[string]string  `yaml:"fileHashes,omitempty"`
+	RenderHashes map[string]string  `yaml:"renderHashes,omitempty"`
+	CastOptions  *CastOptionsRecord `yaml:"castOptions,omitempty"`
+	InstalledAs  string             `yaml:"installedAs,omitempty"` // "direct" | "transitive"
+	InstalledBy  []string           `yaml:"installedBy,omitempty"` // parent mold source[@subpath] strings
+	// GitBranch and GitCommit are the project's checked-out branch and HEAD
+	// commit as the cast's blanks saw them (_ailloy.git), recorded with
+	// CastAt so a re-render can reproduce what the cast wrote.
+	GitBranch string `yaml:"gitBranch,omitempty"`
+	GitCommit string `yaml:"gitCommit,omitempty"`
 }
 
 // ArtifactEntry records an installed ingot or ore. Mirrors InstalledEntry
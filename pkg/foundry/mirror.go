@@ -0,0 +1,69 @@
+package foundry
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// mirrorsArgs holds the host rewrite rules installed by SetMirrors, as the
+// `-c url.<mirror>.insteadOf=<origin>` arguments DefaultGitRunner prepends to
+// every git command.
+var (
+	mirrorsMu   sync.RWMutex
+	mirrorsArgs []string
+)
+
+// SetMirrors installs host rewrite rules for every git command run through
+// DefaultGitRunner. Each rule maps a foundry host to the mirror that serves
+// its repositories, e.g. "github.com" -> "git.internal.corp/mirror" fetches
+// github.com/owner/repo from https://git.internal.corp/mirror/owner/repo.git.
+// A mirror without a scheme is reached over HTTPS; a full URL
+// ("ssh://git@mirror/github") is used as given.
+//
+// The rewrite happens inside git (url.<base>.insteadOf), so references,
+// cache paths, ailloy.lock, and installed.yaml keep the original host and
+// stay portable between mirrored and unmirrored machines. Passing nil clears
+// the rules.
+func SetMirrors(rules map[string]string) error {
+	var args []string
+	for _, host := range slices.Sorted(maps.Keys(rules)) {
+		origin, mirror, err := mirrorRule(host, rules[host])
+		if err != nil {
+			return err
+		}
+		args = append(args, "-c", "url."+mirror+".insteadOf="+origin)
+	}
+	mirrorsMu.Lock()
+	mirrorsArgs = args
+	mirrorsMu.Unlock()
+	return nil
+}
+
+// mirrorRule validates one host -> mirror rule and returns the origin URL
+// prefix and the mirror URL prefix that replaces it.
+func mirrorRule(host, mirror string) (origin, target string, err error) {
+	if host == "" || strings.ContainsAny(host, "/:@ ") {
+		return "", "", fmt.Errorf("invalid mirror host %q: use a bare host such as github.com", host)
+	}
+	mirror = strings.TrimSuffix(strings.TrimSpace(mirror), "/")
+	if mirror == "" {
+		return "", "", fmt.Errorf("mirror for %s is empty", host)
+	}
+	if !strings.Contains(mirror, "://") {
+		mirror = "https://" + mirror
+	}
+	return "https://" + host + "/", mirror + "/", nil
+}
+
+// mirroredArgs returns args with the installed rewrite rules prepended.
+func mirroredArgs(args []string) []string {
+	mirrorsMu.RLock()
+	defer mirrorsMu.RUnlock()
+	if len(mirrorsArgs) == 0 {
+		return args
+	}
+	return append(slices.Clone(mirrorsArgs), args...)
+}
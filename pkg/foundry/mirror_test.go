@@ -0,0 +1,67 @@
+package foundry
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func setMirrors(t *testing.T, rules map[string]string) {
+	t.Helper()
+	if err := SetMirrors(rules); err != nil {
+		t.Fatalf("SetMirrors: %v", err)
+	}
+	t.Cleanup(func() { _ = SetMirrors(nil) })
+}
+
+func TestSetMirrors_RewriteArgs(t *testing.T) {
+	setMirrors(t, map[string]string{
+		"github.com": "git.internal.corp/mirror/",
+		"gitlab.com": "ssh://git@mirror.corp/gitlab",
+	})
+	got := mirroredArgs([]string{"ls-remote", "--tags", "https://github.com/a/b.git"})
+	want := []string{
+		"-c", "url.https://git.internal.corp/mirror/.insteadOf=https://github.com/",
+		"-c", "url.ssh://git@mirror.corp/gitlab/.insteadOf=https://gitlab.com/",
+		"ls-remote", "--tags", "https://github.com/a/b.git",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("mirroredArgs =\n  %q\nwant\n  %q", got, want)
+	}
+
+	_ = SetMirrors(nil)
+	if got := mirroredArgs([]string{"fetch"}); !slices.Equal(got, []string{"fetch"}) {
+		t.Errorf("cleared rules still rewrite: %q", got)
+	}
+}
+
+func TestSetMirrors_Invalid(t *testing.T) {
+	for name, rules := range map[string]map[string]string{
+		"host with scheme": {"https://github.com": "mirror.corp"},
+		"host with path":   {"github.com/acme": "mirror.corp"},
+		"empty mirror":     {"github.com": " "},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := SetMirrors(rules); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestDefaultGitRunner_UsesMirror(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	mirror := t.TempDir()
+	bare := filepath.Join(mirror, "acme", "mold.git")
+	if out, err := exec.Command("git", "init", "--bare", bare).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	setMirrors(t, map[string]string{"github.com": "file://" + mirror})
+
+	if out, err := DefaultGitRunner()("ls-remote", "https://github.com/acme/mold.git"); err != nil {
+		t.Fatalf("ls-remote through the mirror: %v\n%s", err, out)
+	}
+}
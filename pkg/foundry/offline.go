@@ -1,6 +1,7 @@
 package foundry
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,14 +25,17 @@ func NewOfflineGitRunner(git GitRunner, cacheDir string) GitRunner {
 		if len(args) >= 3 && args[0] == "ls-remote" && args[1] == "--tags" {
 			url := args[2]
 			bareDir := bareDirForURL(url, cacheDir)
+			if !hasBareClone(bareDir) {
+				return nil, &NotCachedError{Ref: refForURL(url)}
+			}
 			return localTagsOutput(bareDir, git)
 		}
 
 		// git -C <dir> fetch ...  →  no-op (skip network fetch of bare clone)
 		if len(args) >= 3 && args[0] == "-C" && args[2] == "fetch" {
 			bareDir := args[1]
-			if _, err := os.Stat(filepath.Join(bareDir, "HEAD")); err != nil {
-				return nil, fmt.Errorf("offline mode: no cached clone at %s; run without --offline to fetch it", bareDir)
+			if !hasBareClone(bareDir) {
+				return nil, &NotCachedError{Ref: refForBareDir(bareDir, cacheDir)}
 			}
 			return nil, nil
 		}
@@ -46,7 +50,7 @@ func NewOfflineGitRunner(git GitRunner, cacheDir string) GitRunner {
 							url = args[i+1]
 						}
 					}
-					return nil, fmt.Errorf("offline mode: no cached clone for %q; run without --offline to fetch it", url)
+					return nil, &NotCachedError{Ref: refForURL(url)}
 				}
 			}
 		}
@@ -61,11 +65,110 @@ func NewOfflineGitRunner(git GitRunner, cacheDir string) GitRunner {
 	}
 }
 
+// NewCacheFirstGitRunner wraps a GitRunner so that resolution prefers the
+// local cache: tag listings for a repository with a bare clone are read from
+// that clone, and the clone is not refreshed. Repositories that aren't
+// cached yet go to the network as usual. Selected by
+// `foundry.resolution: cache-first` in config.yaml.
+func NewCacheFirstGitRunner(git GitRunner, cacheDir string) GitRunner {
+	return func(args ...string) ([]byte, error) {
+		if len(args) >= 3 && args[0] == "ls-remote" && args[1] == "--tags" {
+			if bareDir := bareDirForURL(args[2], cacheDir); hasBareClone(bareDir) {
+				return localTagsOutput(bareDir, git)
+			}
+		}
+		if len(args) >= 3 && args[0] == "-C" && args[2] == "fetch" && hasBareClone(args[1]) {
+			return nil, nil
+		}
+		return git(args...)
+	}
+}
+
+// NotCachedError reports a repository that offline resolution needed but
+// the local cache doesn't hold.
+type NotCachedError struct {
+	Ref string // host/owner/repo
+}
+
+func (e *NotCachedError) Error() string {
+	return fmt.Sprintf("offline mode: %s is not cached; run without --offline to fetch it", e.Ref)
+}
+
+// MissingRefsError lists every repository an offline cast needed but the
+// local cache doesn't hold, so they can all be warmed in one go.
+type MissingRefsError struct {
+	Refs []string
+}
+
+func (e *MissingRefsError) Error() string {
+	if len(e.Refs) == 1 {
+		return (&NotCachedError{Ref: e.Refs[0]}).Error()
+	}
+	return fmt.Sprintf("offline mode: %d refs are not cached:\n  - %s\nrun without --offline to fetch them",
+		len(e.Refs), strings.Join(e.Refs, "\n  - "))
+}
+
+// MissingRefs gathers the refs of every NotCachedError and MissingRefsError
+// wrapped in errs into one *MissingRefsError, in first-seen order without
+// duplicates. It returns nil when none of errs is a cache miss.
+func MissingRefs(errs ...error) error {
+	var refs []string
+	seen := map[string]bool{}
+	add := func(ref string) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	for _, err := range errs {
+		var nc *NotCachedError
+		var mr *MissingRefsError
+		switch {
+		case errors.As(err, &mr):
+			for _, r := range mr.Refs {
+				add(r)
+			}
+		case errors.As(err, &nc):
+			add(nc.Ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return &MissingRefsError{Refs: refs}
+}
+
+// IsNotCached reports whether err is, or wraps, a cache miss from offline
+// resolution.
+func IsNotCached(err error) bool {
+	return MissingRefs(err) != nil
+}
+
+// hasBareClone reports whether bareDir holds a bare clone.
+func hasBareClone(bareDir string) bool {
+	_, err := os.Stat(filepath.Join(bareDir, "HEAD"))
+	return err == nil
+}
+
 // bareDirForURL derives the local bare-clone path for a remote clone URL.
 // "https://github.com/owner/repo.git" → "<cacheDir>/github.com/owner/repo/git"
 func bareDirForURL(url, cacheDir string) string {
-	key := strings.TrimSuffix(strings.TrimPrefix(url, "https://"), ".git")
-	return filepath.Join(cacheDir, filepath.FromSlash(key), "git")
+	return filepath.Join(cacheDir, filepath.FromSlash(refForURL(url)), "git")
+}
+
+// refForURL turns a clone URL back into its host/owner/repo reference.
+func refForURL(url string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(url, "https://"), ".git")
+}
+
+// refForBareDir turns a bare-clone path back into its host/owner/repo
+// reference, falling back to the path itself.
+func refForBareDir(bareDir, cacheDir string) string {
+	rel, err := filepath.Rel(cacheDir, filepath.Dir(bareDir))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return bareDir
+	}
+	return filepath.ToSlash(rel)
 }
 
 // localTagsOutput reads semver tags from a local bare clone and returns bytes
@@ -0,0 +1,157 @@
+package foundry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeRemote is a GitRunner standing in for one repository with the given
+// remote tags, recording each call. Its bare clone lives in cacheDir once
+// cloned (or when pre-seeded with seedClone).
+type fakeRemote struct {
+	t        *testing.T
+	cacheDir string
+	ref      *Reference
+	remote   []string          // tags on the remote
+	local    []string          // tags in the bare clone
+	files    map[string]string // extra files at every tag, by path
+	calls    []string
+}
+
+func (f *fakeRemote) seedClone(tags ...string) {
+	f.t.Helper()
+	bare := BareCloneDir(f.cacheDir, f.ref)
+	if err := os.MkdirAll(bare, 0750); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bare, "HEAD"), []byte("ref: refs/heads/main"), 0644); err != nil {
+		f.t.Fatal(err)
+	}
+	f.local = tags
+}
+
+func (f *fakeRemote) run(args ...string) ([]byte, error) {
+	sub := gitSubcommand(args)
+	f.calls = append(f.calls, sub)
+	switch sub {
+	case "ls-remote":
+		var out strings.Builder
+		for _, tag := range f.remote {
+			fmt.Fprintf(&out, "%040d\trefs/tags/%s\n", len(tag), tag)
+		}
+		return []byte(out.String()), nil
+	case "fetch":
+		f.local = f.remote
+		return nil, nil
+	case "for-each-ref":
+		if strings.Contains(args[len(args)-1], "*objectname") {
+			return nil, nil
+		}
+		var out strings.Builder
+		for _, tag := range f.local {
+			fmt.Fprintf(&out, "%040d\trefs/tags/%s\n", len(tag), tag)
+		}
+		return []byte(out.String()), nil
+	case "show":
+		tag, path, _ := strings.Cut(args[3], ":")
+		if content, ok := f.files[path]; ok {
+			return []byte(content), nil
+		}
+		if path != "mold.yaml" {
+			return nil, fmt.Errorf("fatal: path '%s' does not exist in '%s'", path, tag)
+		}
+		return []byte("version: " + strings.TrimPrefix(tag, "v")), nil
+	case "archive":
+		return makeTarball(f.t, map[string]string{"mold.yaml": "name: m\nversion: " + strings.TrimPrefix(args[4], "v")}), nil
+	}
+	return nil, fmt.Errorf("unexpected git call: %v", args)
+}
+
+func newFakeRemote(t *testing.T, remote ...string) *fakeRemote {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AILLOY_TMPDIR", "")
+	cacheDir, err := CacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := ParseReference("github.com/acme/mold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeRemote{t: t, cacheDir: cacheDir, ref: ref, remote: remote}
+}
+
+func TestResolve_CacheFirst_ServesFromCache(t *testing.T) {
+	f := newFakeRemote(t, "v1.0.0", "v1.1.0")
+	f.seedClone("v1.0.0")
+
+	_, result, err := resolveWithMeta(f.ref, f.run, WithCacheFirst(), WithLockPath(filepath.Join(t.TempDir(), "none.lock")))
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if result.Resolved.Tag != "v1.0.0" {
+		t.Errorf("tag = %s, want the cached v1.0.0", result.Resolved.Tag)
+	}
+	if slices.Contains(f.calls, "ls-remote") || slices.Contains(f.calls, "fetch") {
+		t.Errorf("cache-first must not touch the network, calls: %v", f.calls)
+	}
+}
+
+func TestResolve_CacheFirst_FallsBackToNetwork(t *testing.T) {
+	f := newFakeRemote(t, "v1.0.0", "v2.0.0")
+	f.seedClone("v1.0.0")
+	f.ref.Version, f.ref.Type = "^2.0.0", Constraint
+
+	_, result, err := resolveWithMeta(f.ref, f.run, WithCacheFirst(), WithLockPath(filepath.Join(t.TempDir(), "none.lock")))
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if result.Resolved.Tag != "v2.0.0" {
+		t.Errorf("tag = %s, want v2.0.0 from the remote", result.Resolved.Tag)
+	}
+	if !slices.Contains(f.calls, "fetch") {
+		t.Errorf("expected a fetch when the cache can't satisfy ^2.0.0, calls: %v", f.calls)
+	}
+}
+
+func TestResolve_Offline_NotCached(t *testing.T) {
+	f := newFakeRemote(t, "v1.0.0")
+
+	_, _, err := resolveWithMeta(f.ref, f.run, WithOffline(), WithLockPath(filepath.Join(t.TempDir(), "none.lock")))
+	var nc *NotCachedError
+	if !errors.As(err, &nc) || nc.Ref != "github.com/acme/mold" {
+		t.Fatalf("err = %v, want NotCachedError for github.com/acme/mold", err)
+	}
+	if len(f.calls) != 0 {
+		t.Errorf("offline resolve ran git: %v", f.calls)
+	}
+}
+
+func TestMissingRefs(t *testing.T) {
+	a := fmt.Errorf("resolving: %w", &NotCachedError{Ref: "github.com/a/one"})
+	b := &MissingRefsError{Refs: []string{"github.com/a/two", "github.com/a/one"}}
+	other := errors.New("boom")
+
+	if MissingRefs(other, nil) != nil {
+		t.Error("no cache misses should yield nil")
+	}
+	err := MissingRefs(a, other, b, nil)
+	var mr *MissingRefsError
+	if !errors.As(err, &mr) || !slices.Equal(mr.Refs, []string{"github.com/a/one", "github.com/a/two"}) {
+		t.Fatalf("MissingRefs = %v", err)
+	}
+	want := "offline mode: 2 refs are not cached:\n  - github.com/a/one\n  - github.com/a/two\nrun without --offline to fetch them"
+	if err.Error() != want {
+		t.Errorf("message = %q, want %q", err.Error(), want)
+	}
+	if !IsNotCached(a) || IsNotCached(other) {
+		t.Error("IsNotCached misclassified an error")
+	}
+}
@@ -0,0 +1,85 @@
+package foundry
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ProjectFileName is the project-level file that declares the molds a
+// project is built from, cast together by `ailloy sync` / `cast --all`.
+const ProjectFileName = "ailloy.yaml"
+
+// ProjectMold declares one mold the project wants cast. Values, Set, and
+// Profile mirror the cast -f/--set/--profile flags; relative Values paths (and a local Ref)
+// are resolved against the directory holding ailloy.yaml. To names output
+// adapters (cast --to) whose tools also get the mold's blanks, converted
+// after the regular cast.
+type ProjectMold struct {
+	Ref           string   `yaml:"ref"`
+	Values        []string `yaml:"values,omitempty"`
+	Set           []string `yaml:"set,omitempty"`
+	WithWorkflows bool     `yaml:"withWorkflows,omitempty"`
+	Profile       string   `yaml:"profile,omitempty"`
+	To            []string `yaml:"to,omitempty"`
+}
+
+// ProjectFile is the on-disk ailloy.yaml format.
+type ProjectFile struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Molds      []ProjectMold `yaml:"molds"`
+}
+
+// ReadProjectFile reads and validates the project file at path.
+// Returns (nil, nil) if the file does not exist.
+func ReadProjectFile(path string) (*ProjectFile, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- path constructed by callers
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var pf ProjectFile
+	if err := yaml.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	seen := make(map[string]bool, len(pf.Molds))
+	for i, m := range pf.Molds {
+		if m.Ref == "" {
+			return nil, fmt.Errorf("%s: molds[%d]: ref is required", path, i)
+		}
+		if seen[m.Ref] {
+			return nil, fmt.Errorf("%s: molds[%d]: %s is listed more than once", path, i, m.Ref)
+		}
+		seen[m.Ref] = true
+	}
+	return &pf, nil
+}
+
+// WriteProjectFile writes pf to path, defaulting its apiVersion to v1.
+func WriteProjectFile(path string, pf *ProjectFile) error {
+	if pf.APIVersion == "" {
+		pf.APIVersion = "v1"
+	}
+	data, err := yaml.Marshal(pf)
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", path, err)
+	}
+	//#nosec G306 -- project file is meant to be committed
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// FindMold returns the declared mold with the given ref, or nil.
+func (pf *ProjectFile) FindMold(ref string) *ProjectMold {
+	for i := range pf.Molds {
+		if pf.Molds[i].Ref == ref {
+			return &pf.Molds[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package foundry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadProjectFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ProjectFileName)
+
+	if pf, err := ReadProjectFile(path); pf != nil || err != nil {
+		t.Fatalf("missing file: %v, %v", pf, err)
+	}
+
+	content := `apiVersion: v1
+molds:
+  - ref: github.com/acme/base-mold@^1.0
+  - ref: github.com/acme/team-mold
+    values: [team.yaml]
+    set: [team=platform]
+    withWorkflows: true
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pf, err := ReadProjectFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pf.Molds) != 2 || pf.Molds[1].Values[0] != "team.yaml" || pf.Molds[1].Set[0] != "team=platform" || !pf.Molds[1].WithWorkflows {
+		t.Errorf("parsed = %+v", pf.Molds)
+	}
+}
+
+func TestReadProjectFile_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing ref": "molds:\n  - values: [a.yaml]\n",
+		"duplicate":   "molds:\n  - ref: github.com/a/b\n  - ref: github.com/a/b\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ProjectFileName)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ReadProjectFile(path); err == nil || !strings.Contains(err.Error(), "molds[") {
+				t.Errorf("err = %v, want molds[i] validation error", err)
+			}
+		})
+	}
+}
@@ -21,10 +21,11 @@ var ErrNoSemverTags = errors.New("no semver tags found")
 // It is injectable for testing.
 type GitRunner func(args ...string) ([]byte, error)
 
-// DefaultGitRunner returns a GitRunner that shells out to git.
+// DefaultGitRunner returns a GitRunner that shells out to git, applying any
+// host rewrite rules installed with SetMirrors.
 func DefaultGitRunner() GitRunner {
 	return func(args ...string) ([]byte, error) {
-		cmd := exec.Command("git", args...) //#nosec G204 -- args are constructed internally, not user-supplied
+		cmd := exec.Command("git", mirroredArgs(args)...) //#nosec G204 -- args are constructed internally, not user-supplied
 		return cmd.CombinedOutput()
 	}
 }
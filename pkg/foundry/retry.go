@@ -0,0 +1,116 @@
+package foundry
+
+import (
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// FetchJobs bounds how many foundry fetches (molds, ingots, ores) run at once
+// when a cast resolves several dependencies.
+const FetchJobs = 4
+
+// Retry policy for network git commands. Variables so tests can shrink the
+// delay.
+var (
+	gitRetryAttempts = 3
+	gitRetryDelay    = 500 * time.Millisecond
+)
+
+// transientGitErrors are fragments of git output that mark a failure as a
+// network hiccup worth retrying rather than a permanent error such as a
+// missing repository or bad credentials.
+var transientGitErrors = []string{
+	"could not resolve host",
+	"connection timed out",
+	"connection reset",
+	"connection refused",
+	"operation timed out",
+	"failed to connect",
+	"early eof",
+	"rpc failed",
+	"remote end hung up unexpectedly",
+	"gnutls_handshake",
+	"ssl_read",
+	"ssl_connect",
+	"unexpected disconnect",
+	"temporary failure in name resolution",
+	"the requested url returned error: 429",
+	"the requested url returned error: 500",
+	"the requested url returned error: 502",
+	"the requested url returned error: 503",
+	"the requested url returned error: 504",
+}
+
+// NewRetryGitRunner wraps a GitRunner so that network git commands (clone,
+// fetch, ls-remote) failing with a transient error are retried with
+// exponential backoff and jitter: up to three attempts, starting at 500ms.
+// Local commands and permanent failures return immediately. Each retry is
+// noted on logger as a warning; nil falls back to log.Default().
+func NewRetryGitRunner(git GitRunner, logger *log.Logger) GitRunner {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(args ...string) ([]byte, error) {
+		out, err := git(args...)
+		if !isNetworkGitCommand(args) {
+			return out, err
+		}
+		delay := gitRetryDelay
+		for attempt := 2; attempt <= gitRetryAttempts && err != nil && isTransientGitError(out, err); attempt++ {
+			wait := delay + time.Duration(rand.Int64N(int64(delay)/2+1))
+			logger.Printf("warning: git %s failed (%s); retrying in %s (attempt %d of %d)",
+				gitSubcommand(args), firstLine(out, err), wait.Round(time.Millisecond), attempt, gitRetryAttempts)
+			time.Sleep(wait)
+			delay *= 2
+			out, err = git(args...)
+		}
+		return out, err
+	}
+}
+
+// gitSubcommand returns the git subcommand in args, skipping a leading
+// `-C <dir>`.
+func gitSubcommand(args []string) string {
+	if len(args) >= 3 && args[0] == "-C" {
+		return args[2]
+	}
+	if len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
+
+// isNetworkGitCommand reports whether args run a git command that talks to
+// the remote.
+func isNetworkGitCommand(args []string) bool {
+	switch gitSubcommand(args) {
+	case "clone", "fetch", "ls-remote":
+		return true
+	}
+	return false
+}
+
+// isTransientGitError reports whether a failed git command looks like a
+// network hiccup.
+func isTransientGitError(out []byte, err error) bool {
+	msg := strings.ToLower(string(out) + "\n" + err.Error())
+	for _, frag := range transientGitErrors {
+		if strings.Contains(msg, frag) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first non-empty line of git's output, or the error
+// when git printed nothing.
+func firstLine(out []byte, err error) string {
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return err.Error()
+}
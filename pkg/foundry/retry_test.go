@@ -0,0 +1,81 @@
+package foundry
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func fastRetries(t *testing.T) {
+	t.Helper()
+	prev := gitRetryDelay
+	gitRetryDelay = 0
+	t.Cleanup(func() { gitRetryDelay = prev })
+}
+
+func TestRetryGitRunner_RetriesTransientErrors(t *testing.T) {
+	fastRetries(t)
+	calls := 0
+	git := func(args ...string) ([]byte, error) {
+		calls++
+		if calls < 3 {
+			return []byte("fatal: unable to access 'https://github.com/a/b.git/': Could not resolve host: github.com\n"), errors.New("exit status 128")
+		}
+		return []byte("ok"), nil
+	}
+	var logs bytes.Buffer
+	out, err := NewRetryGitRunner(git, log.New(&logs, "", 0))("ls-remote", "--tags", "https://github.com/a/b.git")
+	if err != nil || string(out) != "ok" {
+		t.Fatalf("got (%q, %v), want success on the third attempt", out, err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if n := strings.Count(logs.String(), "retrying"); n != 2 {
+		t.Errorf("logged %d retries, want 2:\n%s", n, logs.String())
+	}
+}
+
+func TestRetryGitRunner_GivesUpAfterLastAttempt(t *testing.T) {
+	fastRetries(t)
+	calls := 0
+	git := func(args ...string) ([]byte, error) {
+		calls++
+		return []byte("error: RPC failed; curl 56 GnuTLS recv error\nfatal: early EOF\n"), errors.New("exit status 128")
+	}
+	_, err := NewRetryGitRunner(git, log.New(&bytes.Buffer{}, "", 0))("-C", "/cache/git", "fetch", "--all", "--tags", "--force")
+	if err == nil {
+		t.Fatal("expected the last error")
+	}
+	if calls != gitRetryAttempts {
+		t.Errorf("calls = %d, want %d", calls, gitRetryAttempts)
+	}
+}
+
+func TestRetryGitRunner_NoRetry(t *testing.T) {
+	fastRetries(t)
+	cases := map[string][]string{
+		"permanent error": {"clone", "--bare", "https://github.com/a/missing.git", "/tmp/x"},
+		"local command":   {"-C", "/cache/git", "archive", "--format=tar", "v1.0.0"},
+	}
+	for name, args := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			git := func(a ...string) ([]byte, error) {
+				calls++
+				if a[0] == "clone" {
+					return []byte("remote: Repository not found.\n"), errors.New("exit status 128")
+				}
+				return []byte("connection reset by peer"), errors.New("exit status 1")
+			}
+			if _, err := NewRetryGitRunner(git, log.New(&bytes.Buffer{}, "", 0))(args...); err == nil {
+				t.Fatal("expected error")
+			}
+			if calls != 1 {
+				t.Errorf("calls = %d, want 1", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package foundry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SchemaError reports a project state file (ailloy.lock, .ailloy/state.yaml)
+// written by a newer ailloy than this binary understands. Operating on it
+// anyway risks silently dropping fields the newer release depends on, which
+// bites teams whose members run different ailloy releases on one repo.
+type SchemaError struct {
+	Path      string
+	Version   int
+	Supported int
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s uses schema version %d, but this ailloy only understands up to %d; "+
+		"upgrade ailloy (run `ailloy evolve`) before working in this project",
+		e.Path, e.Version, e.Supported)
+}
+
+// IsSchemaTooNew reports whether err is (or wraps) a *SchemaError.
+func IsSchemaTooNew(err error) bool {
+	var se *SchemaError
+	return errors.As(err, &se)
+}
+
+// CheckSchemaVersion returns a *SchemaError when version is newer than
+// supported. Older versions (including 0, unversioned) are the caller's to
+// migrate.
+func CheckSchemaVersion(path string, version, supported int) error {
+	if version > supported {
+		return &SchemaError{Path: path, Version: version, Supported: supported}
+	}
+	return nil
+}
+
+// PeekSchemaVersion reads only the schemaVersion field of the YAML file at
+// path. A missing file, unparseable file, or unversioned file reports 0.
+func PeekSchemaVersion(path string) int {
+	data, err := os.ReadFile(path) //#nosec G304 -- path constructed by callers
+	if err != nil {
+		return 0
+	}
+	var head struct {
+		SchemaVersion int `yaml:"schemaVersion"`
+	}
+	if yaml.Unmarshal(data, &head) != nil {
+		return 0
+	}
+	return head.SchemaVersion
+}
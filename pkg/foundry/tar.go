@@ -28,7 +28,13 @@ func extractTar(data []byte, destDir string) error {
 			return fmt.Errorf("reading tar: %w", err)
 		}
 
-		target := filepath.Join(absDir, filepath.Clean(hdr.Name))
+		// Remote archives are untrusted: refuse absolute names and ".."
+		// escapes outright rather than re-rooting them.
+		name := filepath.FromSlash(hdr.Name)
+		if filepath.IsAbs(name) || strings.HasPrefix(hdr.Name, "/") || filepath.VolumeName(name) != "" {
+			return fmt.Errorf("tar entry %q has an absolute path", hdr.Name)
+		}
+		target := filepath.Join(absDir, filepath.Clean(name))
 		if !strings.HasPrefix(target, absDir+string(filepath.Separator)) && target != absDir {
 			return fmt.Errorf("tar entry %q would escape destination", hdr.Name)
 		}
@@ -51,6 +57,9 @@ func extractTar(data []byte, destDir string) error {
 				return fmt.Errorf("writing file %s: %w", target, err)
 			}
 			_ = f.Close()
+		default:
+			// Symlinks, hard links and device files are never extracted:
+			// a link could point anywhere on the host.
 		}
 	}
 	return nil
@@ -0,0 +1,74 @@
+package foundry
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func buildTar(t *testing.T, entries ...*tar.Header) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, h := range entries {
+		if h.Typeflag == tar.TypeReg {
+			h.Size = int64(len(h.Name))
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(h.Name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractTar_RejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		wantErr string
+	}{
+		{"absolute", "/etc/cron.d/job", "absolute path"},
+		{"traversal", "blanks/../../escape.md", "escape destination"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildTar(t, &tar.Header{Name: tt.entry, Typeflag: tar.TypeReg, Mode: 0o644})
+			err := extractTar(data, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractTar_SkipsLinks(t *testing.T) {
+	dir := t.TempDir()
+	data := buildTar(t,
+		&tar.Header{Name: "blanks/", Typeflag: tar.TypeDir, Mode: 0o755},
+		&tar.Header{Name: "blanks/passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		&tar.Header{Name: "blanks/hard", Typeflag: tar.TypeLink, Linkname: "/etc/shadow"},
+		&tar.Header{Name: "blanks/a.md", Typeflag: tar.TypeReg, Mode: 0o644},
+	)
+	if err := extractTar(data, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"passwd", "hard"} {
+		if _, err := os.Lstat(filepath.Join(dir, "blanks", name)); !os.IsNotExist(err) {
+			t.Errorf("%s was extracted (err = %v)", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "blanks", "a.md")); err != nil {
+		t.Error(err)
+	}
+}
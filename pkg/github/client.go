@@ -1,10 +1,19 @@
 package github
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // GraphQL query constants
@@ -50,6 +59,8 @@ const projectFieldsQuery = `query($org: String!, $number: Int!) {
               iterations {
                 id
                 title
+                startDate
+                duration
               }
             }
           }
@@ -59,41 +70,96 @@ const projectFieldsQuery = `query($org: String!, $number: Int!) {
   }
 }`
 
-// Execer abstracts command execution for testing
+// DefaultTimeout bounds each gh invocation of a client made by NewClient.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultRetryBudget is how long in total a client made by NewClient waits
+// out rate limits before giving up.
+const DefaultRetryBudget = 2 * time.Minute
+
+// maxBackoff caps the wait between retries when GitHub gives no reset time.
+const maxBackoff = time.Minute
+
+// Execer abstracts command execution for testing. Run must stop the command
+// when ctx is done.
 type Execer interface {
-	Run(args []string) ([]byte, error)
+	Run(ctx context.Context, args []string) ([]byte, error)
 }
 
 // GHExecer calls the real gh CLI
 type GHExecer struct{}
 
-func (g *GHExecer) Run(args []string) ([]byte, error) {
-	cmd := exec.Command("gh", args...) // #nosec G204 -- CLI tool invokes gh with controlled args
+func (g *GHExecer) Run(ctx context.Context, args []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...) // #nosec G204 -- CLI tool invokes gh with controlled args
 	return cmd.CombinedOutput()
 }
 
-// Client provides GitHub ProjectV2 discovery via gh api graphql
+// Client provides GitHub ProjectV2 discovery via gh api graphql.
+//
+// A Client is safe for concurrent use: the result cache is guarded by a
+// mutex, and identical in-flight queries (same cache key) share a single gh
+// invocation instead of each shelling out. Every method takes a context;
+// cancelling it stops the caller's wait and any gh invocation it started
+// (callers sharing that invocation start their own).
 type Client struct {
-	Exec  Execer
-	cache map[string]any
+	Exec Execer
+	// Host is the GitHub hostname to query, passed to gh as --hostname for
+	// GitHub Enterprise Server. Empty means github.com.
+	Host string
+	// Timeout bounds each gh invocation; zero means no limit beyond the
+	// caller's context.
+	Timeout time.Duration
+	// RetryBudget is the total time a query may spend waiting out rate
+	// limits. Each wait is what GitHub's Retry-After or X-RateLimit-Reset
+	// header asks for, else an exponential backoff from one second; a wait
+	// that would exceed the budget returns the *RateLimitError instead.
+	// Zero disables retries.
+	RetryBudget time.Duration
+	// OnRetry, when set, is called before each rate-limit wait, from the
+	// goroutine running the query, so callers can tell the user (see
+	// RetryEvent.String).
+	OnRetry func(RetryEvent)
+
+	sleep func(context.Context, time.Duration) error // nil: sleepContext
+
+	mu       sync.Mutex
+	cache    map[string]any
+	inflight singleflight.Group
 }
 
 // NewClient creates a new discovery client
 func NewClient() *Client {
 	return &Client{
-		Exec:  &GHExecer{},
-		cache: make(map[string]any),
+		Exec:        &GHExecer{},
+		Timeout:     DefaultTimeout,
+		RetryBudget: DefaultRetryBudget,
+		cache:       make(map[string]any),
 	}
 }
 
+// NewClientForHost creates a discovery client for a GitHub host, such as a
+// GitHub Enterprise Server hostname (see NormalizeHost).
+func NewClientForHost(host string) *Client {
+	c := NewClient()
+	c.Host = NormalizeHost(host)
+	return c
+}
+
 // CheckAuth validates that gh is installed and authenticated
-func (c *Client) CheckAuth() error {
+func (c *Client) CheckAuth(ctx context.Context) error {
 	if _, err := exec.LookPath("gh"); err != nil {
 		return ErrGHNotInstalled
 	}
+	return c.authStatus(ctx)
+}
 
-	out, err := c.Exec.Run([]string{"auth", "status"})
+// authStatus runs `gh auth status` for c.Host.
+func (c *Client) authStatus(ctx context.Context) error {
+	out, err := c.run(ctx, append([]string{"auth", "status"}, c.hostArgs()...))
 	if err != nil {
+		if isContextError(err) {
+			return err
+		}
 		if strings.Contains(string(out), "not logged") || strings.Contains(string(out), "not authenticated") {
 			return ErrGHNotAuth
 		}
@@ -103,32 +169,27 @@ func (c *Client) CheckAuth() error {
 }
 
 // ListProjects returns all ProjectV2 boards for an organization
-func (c *Client) ListProjects(org string) ([]Project, error) {
-	cacheKey := "projects:" + org
-	if cached, ok := c.cache[cacheKey]; ok {
-		return cached.([]Project), nil
+func (c *Client) ListProjects(ctx context.Context, org string) ([]Project, error) {
+	v, err := c.cached(ctx, "projects:"+org, func(ctx context.Context) (any, error) {
+		return c.listProjects(ctx, org)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return v.([]Project), nil
+}
 
-	out, err := c.Exec.Run([]string{
-		"api", "graphql",
+func (c *Client) listProjects(ctx context.Context, org string) ([]Project, error) {
+	raw, err := c.graphql(ctx, []string{
 		"-f", "query=" + listProjectsQuery,
 		"-f", "org=" + org,
 	})
 	if err != nil {
-		return nil, c.parseError(out, err)
-	}
-
-	var resp graphQLResponse
-	if err := json.Unmarshal(out, &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if gqlErr := resp.toError(); gqlErr != nil {
-		return nil, gqlErr
+		return nil, err
 	}
 
 	var data projectsData
-	if err := json.Unmarshal(resp.Data, &data); err != nil {
+	if err := json.Unmarshal(raw, &data); err != nil {
 		return nil, fmt.Errorf("failed to parse projects data: %w", err)
 	}
 
@@ -148,38 +209,32 @@ func (c *Client) ListProjects(org string) ([]Project, error) {
 		}
 	}
 
-	c.cache[cacheKey] = projects
 	return projects, nil
 }
 
 // GetProjectFields returns all fields for a specific project
-func (c *Client) GetProjectFields(org string, projectNumber int) (*DiscoveryResult, error) {
-	cacheKey := fmt.Sprintf("fields:%s:%d", org, projectNumber)
-	if cached, ok := c.cache[cacheKey]; ok {
-		return cached.(*DiscoveryResult), nil
+func (c *Client) GetProjectFields(ctx context.Context, org string, projectNumber int) (*DiscoveryResult, error) {
+	v, err := c.cached(ctx, fmt.Sprintf("fields:%s:%d", org, projectNumber), func(ctx context.Context) (any, error) {
+		return c.getProjectFields(ctx, org, projectNumber)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return v.(*DiscoveryResult), nil
+}
 
-	out, err := c.Exec.Run([]string{
-		"api", "graphql",
+func (c *Client) getProjectFields(ctx context.Context, org string, projectNumber int) (*DiscoveryResult, error) {
+	raw, err := c.graphql(ctx, []string{
 		"-f", "query=" + projectFieldsQuery,
 		"-f", "org=" + org,
 		"-F", fmt.Sprintf("number=%d", projectNumber),
 	})
 	if err != nil {
-		return nil, c.parseError(out, err)
-	}
-
-	var resp graphQLResponse
-	if err := json.Unmarshal(out, &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if gqlErr := resp.toError(); gqlErr != nil {
-		return nil, gqlErr
+		return nil, err
 	}
 
 	var data projectFieldsData
-	if err := json.Unmarshal(resp.Data, &data); err != nil {
+	if err := json.Unmarshal(raw, &data); err != nil {
 		return nil, fmt.Errorf("failed to parse fields data: %w", err)
 	}
 
@@ -202,10 +257,225 @@ func (c *Client) GetProjectFields(org string, projectNumber int) (*DiscoveryResu
 		}
 	}
 
-	c.cache[cacheKey] = result
 	return result, nil
 }
 
+// hostArgs returns the gh flags selecting c.Host.
+func (c *Client) hostArgs() []string {
+	if !IsEnterpriseHost(c.Host) {
+		return nil
+	}
+	return []string{"--hostname", NormalizeHost(c.Host)}
+}
+
+// graphql runs `gh api graphql` with the query arguments args and returns
+// the response data. Rate-limited calls are retried within c.RetryBudget.
+func (c *Client) graphql(ctx context.Context, args []string) (json.RawMessage, error) {
+	args = append(append([]string{"api", "graphql", "--include"}, c.hostArgs()...), args...)
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		data, err := c.graphqlOnce(ctx, args)
+		var limited *RateLimitError
+		if !errors.As(err, &limited) {
+			return data, err
+		}
+		wait := limited.RetryAfter
+		if wait <= 0 {
+			wait = min(time.Second<<(attempt-1), maxBackoff)
+		}
+		if waited+wait > c.RetryBudget {
+			return nil, err
+		}
+		if c.OnRetry != nil {
+			c.OnRetry(RetryEvent{Attempt: attempt, Wait: wait, Err: err})
+		}
+		sleep := c.sleep
+		if sleep == nil {
+			sleep = sleepContext
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		waited += wait
+	}
+}
+
+// graphqlOnce is a single graphql attempt.
+func (c *Client) graphqlOnce(ctx context.Context, args []string) (json.RawMessage, error) {
+	out, err := c.run(ctx, args)
+	if err != nil && isContextError(err) {
+		return nil, err
+	}
+	header, body := splitResponse(out)
+	if err != nil {
+		return nil, withRetryAfter(c.parseError(body, err), header, time.Now())
+	}
+
+	var resp graphQLResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if gqlErr := resp.toError(); gqlErr != nil {
+		return nil, withRetryAfter(gqlErr, header, time.Now())
+	}
+	return resp.Data, nil
+}
+
+// RetryEvent describes a rate-limited query about to be retried.
+type RetryEvent struct {
+	Attempt int           // the attempt that was rate limited, from 1
+	Wait    time.Duration // how long the client waits before retrying
+	Err     error         // the *RateLimitError
+}
+
+// String is a one-line message for the user, e.g. "GitHub API rate limit
+// exceeded; retrying in 30s…".
+func (e RetryEvent) String() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded; retrying in %s…", e.Wait.Round(time.Second))
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// splitResponse separates the HTTP status line and headers `gh api --include`
+// prints from the body. Output without them is all body.
+func splitResponse(out []byte) (http.Header, []byte) {
+	header := http.Header{}
+	if !bytes.HasPrefix(out, []byte("HTTP/")) {
+		return header, out
+	}
+	head, body, ok := bytes.Cut(out, []byte("\r\n\r\n"))
+	if !ok {
+		head, body, _ = bytes.Cut(out, []byte("\n\n"))
+	}
+	lines := strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n")
+	for _, line := range lines[1:] {
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			header.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	}
+	return header, body
+}
+
+// withRetryAfter turns a rate-limit err into a *RateLimitError carrying
+// the wait header asks for; other errors pass through.
+func withRetryAfter(err error, header http.Header, now time.Time) error {
+	if !errors.Is(err, ErrRateLimited) {
+		return err
+	}
+	return &RateLimitError{RetryAfter: retryAfter(header, now)}
+}
+
+// retryAfter reads how long to wait from a Retry-After header (seconds or
+// an HTTP date), else from X-RateLimit-Reset when X-RateLimit-Remaining is
+// 0. It returns zero when header says neither.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	if v := header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if at, err := http.ParseTime(v); err == nil && at.After(now) {
+			return at.Sub(now)
+		}
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if at := time.Unix(reset, 0); at.After(now) {
+				return at.Sub(now)
+			}
+		}
+	}
+	return 0
+}
+
+// run invokes gh with args, bounded by ctx and c.Timeout. When the context
+// ends the error is the context's (context.Canceled or
+// context.DeadlineExceeded), whatever gh printed.
+func (c *Client) run(ctx context.Context, args []string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	out, err := c.Exec.Run(ctx, args)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("gh %s: %w", strings.Join(args[:min(2, len(args))], " "), ctxErr)
+	}
+	return out, err
+}
+
+// abandonedError marks a shared fetch that failed because the context of the
+// caller running it ended; callers still waiting retry with their own.
+type abandonedError struct{ err error }
+
+func (e abandonedError) Error() string { return e.err.Error() }
+func (e abandonedError) Unwrap() error { return e.err }
+
+// cached returns the cached value for key, or runs fetch and caches a
+// successful result. Concurrent callers with the same key while a fetch is
+// in flight wait for that fetch rather than starting their own; the fetch
+// runs with the context of the caller that started it. A caller whose
+// context ends stops waiting. Errors are not cached so a later call can
+// retry.
+func (c *Client) cached(ctx context.Context, key string, fetch func(context.Context) (any, error)) (any, error) {
+	for {
+		c.mu.Lock()
+		if v, ok := c.cache[key]; ok {
+			c.mu.Unlock()
+			return v, nil
+		}
+		c.mu.Unlock()
+
+		ch := c.inflight.DoChan(key, func() (any, error) {
+			v, err := fetch(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, abandonedError{err}
+				}
+				return nil, err
+			}
+			c.mu.Lock()
+			if c.cache == nil {
+				c.cache = make(map[string]any)
+			}
+			c.cache[key] = v
+			c.mu.Unlock()
+			return v, nil
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case res := <-ch:
+			var abandoned abandonedError
+			if errors.As(res.Err, &abandoned) {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				continue // another caller gave up on the shared fetch
+			}
+			return res.Val, res.Err
+		}
+	}
+}
+
+// isContextError reports whether err comes from a cancelled or expired
+// context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
 // parseError inspects gh output and exit error to return a specific sentinel error
 func (c *Client) parseError(out []byte, execErr error) error {
 	s := string(out)
@@ -244,8 +514,10 @@ func parseFieldNode(raw json.RawMessage) (*Field, error) {
 		} `json:"options"`
 		Configuration *struct {
 			Iterations []struct {
-				ID    string `json:"id"`
-				Title string `json:"title"`
+				ID        string `json:"id"`
+				Title     string `json:"title"`
+				StartDate string `json:"startDate"`
+				Duration  int    `json:"duration"`
 			} `json:"iterations"`
 		} `json:"configuration"`
 	}
@@ -275,7 +547,7 @@ func parseFieldNode(raw json.RawMessage) (*Field, error) {
 		field.Type = FieldTypeIteration
 		field.Options = make([]Option, len(probe.Configuration.Iterations))
 		for i, iter := range probe.Configuration.Iterations {
-			field.Options[i] = Option{ID: iter.ID, Name: iter.Title}
+			field.Options[i] = Option{ID: iter.ID, Name: iter.Title, StartDate: iter.StartDate, Duration: iter.Duration}
 		}
 	default:
 		field.Type = mapDataType(probe.DataType)
@@ -1,15 +1,22 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // fakeExecer returns canned responses based on argument patterns
 type fakeExecer struct {
+	mu        sync.Mutex
 	calls     [][]string
 	responses map[string]fakeResponse
 }
@@ -19,7 +26,9 @@ type fakeResponse struct {
 	err    error
 }
 
-func (f *fakeExecer) Run(args []string) ([]byte, error) {
+func (f *fakeExecer) Run(_ context.Context, args []string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.calls = append(f.calls, args)
 	key := strings.Join(args, " ")
 	for pattern, resp := range f.responses {
@@ -45,7 +54,7 @@ func TestCheckAuth_Success(t *testing.T) {
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 	// Note: CheckAuth also calls exec.LookPath which checks real PATH.
 	// We test the exec path only; LookPath is tested by the existence of gh.
-	err := client.checkAuthExec()
+	err := client.checkAuthExec(t.Context())
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -59,7 +68,7 @@ func TestCheckAuth_NotAuthenticated(t *testing.T) {
 		},
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
-	err := client.checkAuthExec()
+	err := client.checkAuthExec(t.Context())
 	if !errors.Is(err, ErrGHNotAuth) {
 		t.Errorf("expected ErrGHNotAuth, got %v", err)
 	}
@@ -86,7 +95,7 @@ func TestListProjects_Success(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	projects, err := client.ListProjects("acme")
+	projects, err := client.ListProjects(t.Context(), "acme")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -120,13 +129,13 @@ func TestListProjects_CacheHit(t *testing.T) {
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
 	// First call
-	_, err := client.ListProjects("acme")
+	_, err := client.ListProjects(t.Context(), "acme")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
 	// Second call should use cache
-	_, err = client.ListProjects("acme")
+	_, err = client.ListProjects(t.Context(), "acme")
 	if err != nil {
 		t.Fatalf("unexpected error on cached call: %v", err)
 	}
@@ -136,6 +145,268 @@ func TestListProjects_CacheHit(t *testing.T) {
 	}
 }
 
+func TestListProjects_ConcurrentCallsShareOneQuery(t *testing.T) {
+	respJSON := `{"data": {"organization": {"projectsV2": {"nodes": [
+		{"id": "PVT_1", "number": 1, "title": "Engineering", "url": "", "closed": false}
+	]}}}}`
+
+	fake := newFakeExecer(map[string]fakeResponse{
+		"api graphql": {output: []byte(respJSON)},
+	})
+	client := &Client{Exec: fake}
+
+	const workers = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			projects, err := client.ListProjects(t.Context(), "acme")
+			if err == nil && (len(projects) != 1 || projects[0].ID != "PVT_1") {
+				err = fmt.Errorf("unexpected projects: %+v", projects)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(fake.calls) != 1 {
+		t.Errorf("expected 1 exec call for %d concurrent callers, got %d", workers, len(fake.calls))
+	}
+}
+
+// blockingExecer blocks each call until its context ends, or until release
+// is closed, then answers with output.
+type blockingExecer struct {
+	started chan struct{}
+	release chan struct{}
+	output  []byte
+	calls   atomic.Int32
+}
+
+func (b *blockingExecer) Run(ctx context.Context, _ []string) ([]byte, error) {
+	b.calls.Add(1)
+	b.started <- struct{}{}
+	select {
+	case <-ctx.Done():
+		return nil, errors.New("signal: killed")
+	case <-b.release:
+		return b.output, nil
+	}
+}
+
+func TestListProjects_Cancelled(t *testing.T) {
+	exec := &blockingExecer{started: make(chan struct{}, 1), release: make(chan struct{})}
+	client := &Client{Exec: exec}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	go func() {
+		<-exec.started
+		cancel()
+	}()
+	if _, err := client.ListProjects(ctx, "acme"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := client.ListProjects(ctx, "acme"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a done context to fail fast, got %v", err)
+	}
+	if n := exec.calls.Load(); n != 1 {
+		t.Errorf("expected 1 exec call, got %d", n)
+	}
+}
+
+func TestListProjects_Timeout(t *testing.T) {
+	exec := &blockingExecer{started: make(chan struct{}, 1), release: make(chan struct{})}
+	client := &Client{Exec: exec, Timeout: 10 * time.Millisecond}
+
+	_, err := client.ListProjects(t.Context(), "acme")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestListProjects_WaiterOutlivesCancelledCaller(t *testing.T) {
+	respJSON := `{"data": {"organization": {"projectsV2": {"nodes": [
+		{"id": "PVT_1", "number": 1, "title": "Engineering", "url": "", "closed": false}
+	]}}}}`
+	exec := &blockingExecer{started: make(chan struct{}, 2), release: make(chan struct{}), output: []byte(respJSON)}
+	client := &Client{Exec: exec}
+
+	// The first caller starts the shared query, then gives up on it.
+	ctx, cancel := context.WithCancel(t.Context())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.ListProjects(ctx, "acme")
+		firstErr <- err
+	}()
+	<-exec.started
+
+	type result struct {
+		projects []Project
+		err      error
+	}
+	second := make(chan result, 1)
+	go func() {
+		projects, err := client.ListProjects(t.Context(), "acme")
+		second <- result{projects, err}
+	}()
+	// Give the second caller time to join the in-flight query.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first caller: expected context.Canceled, got %v", err)
+	}
+
+	// The second caller retries with its own context.
+	<-exec.started
+	close(exec.release)
+	res := <-second
+	if res.err != nil || len(res.projects) != 1 {
+		t.Fatalf("second caller: got %+v, %v", res.projects, res.err)
+	}
+}
+
+// sequenceExecer answers successive calls with responses in order, repeating
+// the last one.
+type sequenceExecer struct {
+	mu        sync.Mutex
+	calls     [][]string
+	responses []fakeResponse
+}
+
+func (f *sequenceExecer) Run(_ context.Context, args []string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, args)
+	resp := f.responses[min(len(f.calls), len(f.responses))-1]
+	return resp.output, resp.err
+}
+
+const rateLimitedResponse = "HTTP/2.0 403 Forbidden\r\nRetry-After: 30\r\nX-Ratelimit-Remaining: 0\r\n\r\n" +
+	`{"message":"You have exceeded a secondary rate limit."}` + "\ngh: You have exceeded a secondary rate limit. (HTTP 403)\n"
+
+func TestListProjects_RetriesRateLimit(t *testing.T) {
+	ok := "HTTP/2.0 200 OK\r\nContent-Type: application/json\r\n\r\n" +
+		`{"data": {"organization": {"projectsV2": {"nodes": [{"id": "PVT_1", "number": 1, "title": "Engineering"}]}}}}`
+	fake := &sequenceExecer{responses: []fakeResponse{
+		{output: []byte(rateLimitedResponse), err: errors.New("exit status 1")},
+		{output: []byte(`{"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}]}`)},
+		{output: []byte(ok)},
+	}}
+	var waits []time.Duration
+	var messages []string
+	client := &Client{
+		Exec:        fake,
+		RetryBudget: time.Minute,
+		OnRetry:     func(e RetryEvent) { messages = append(messages, e.String()) },
+		sleep: func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+
+	projects, err := client.ListProjects(t.Context(), "acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != "PVT_1" {
+		t.Errorf("unexpected projects: %+v", projects)
+	}
+	// Retry-After first, then exponential backoff for the attempt without one.
+	if want := []time.Duration{30 * time.Second, 2 * time.Second}; !slices.Equal(waits, want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+	if len(messages) != 2 || messages[0] != "GitHub API rate limit exceeded; retrying in 30s…" {
+		t.Errorf("OnRetry messages = %q", messages)
+	}
+	if !slices.Contains(fake.calls[0], "--include") {
+		t.Errorf("expected gh api --include, got %v", fake.calls[0])
+	}
+}
+
+func TestListProjects_RetryBudgetExceeded(t *testing.T) {
+	fake := &sequenceExecer{responses: []fakeResponse{
+		{output: []byte(rateLimitedResponse), err: errors.New("exit status 1")},
+	}}
+	client := &Client{
+		Exec:        fake,
+		RetryBudget: 10 * time.Second,
+		sleep:       func(context.Context, time.Duration) error { t.Fatal("unexpected wait"); return nil },
+	}
+
+	_, err := client.ListProjects(t.Context(), "acme")
+	var limited *RateLimitError
+	if !errors.As(err, &limited) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected *RateLimitError, got %v", err)
+	}
+	if limited.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", limited.RetryAfter)
+	}
+	if len(fake.calls) != 1 {
+		t.Errorf("expected 1 exec call, got %d", len(fake.calls))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"none", http.Header{}, 0},
+		{"seconds", http.Header{"Retry-After": {"45"}}, 45 * time.Second},
+		{"http date", http.Header{"Retry-After": {now.Add(90 * time.Second).Format(http.TimeFormat)}}, 90 * time.Second},
+		{"reset when exhausted", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {fmt.Sprint(now.Add(5 * time.Minute).Unix())}}, 5 * time.Minute},
+		{"reset with quota left", http.Header{"X-Ratelimit-Remaining": {"12"}, "X-Ratelimit-Reset": {fmt.Sprint(now.Add(5 * time.Minute).Unix())}}, 0},
+		{"reset in the past", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {fmt.Sprint(now.Add(-time.Minute).Unix())}}, 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, now); got != tt.want {
+			t.Errorf("%s: retryAfter = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSplitResponse(t *testing.T) {
+	header, body := splitResponse([]byte(rateLimitedResponse))
+	if header.Get("Retry-After") != "30" || header.Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected header: %v", header)
+	}
+	if !strings.HasPrefix(string(body), `{"message"`) {
+		t.Errorf("unexpected body: %q", body)
+	}
+
+	header, body = splitResponse([]byte(`{"data": {}}`))
+	if len(header) != 0 || string(body) != `{"data": {}}` {
+		t.Errorf("output without headers: got %v, %q", header, body)
+	}
+}
+
+func TestListProjects_ErrorNotCached(t *testing.T) {
+	fake := newFakeExecer(map[string]fakeResponse{
+		"api graphql": {output: []byte("boom"), err: errors.New("exit status 1")},
+	})
+	client := &Client{Exec: fake}
+
+	if _, err := client.ListProjects(t.Context(), "acme"); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := client.ListProjects(t.Context(), "acme"); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(fake.calls) != 2 {
+		t.Errorf("expected failed query to be retried, got %d exec calls", len(fake.calls))
+	}
+}
+
 func TestListProjects_NoProjects(t *testing.T) {
 	respJSON := `{
 		"data": {
@@ -152,7 +423,7 @@ func TestListProjects_NoProjects(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	_, err := client.ListProjects("acme")
+	_, err := client.ListProjects(t.Context(), "acme")
 	if !errors.Is(err, ErrNoProjects) {
 		t.Errorf("expected ErrNoProjects, got %v", err)
 	}
@@ -169,7 +440,7 @@ func TestListProjects_OrgNotFound(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	_, err := client.ListProjects("nonexistent")
+	_, err := client.ListProjects(t.Context(), "nonexistent")
 	if !errors.Is(err, ErrOrgNotFound) {
 		t.Errorf("expected ErrOrgNotFound, got %v", err)
 	}
@@ -186,7 +457,7 @@ func TestListProjects_RateLimited(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	_, err := client.ListProjects("acme")
+	_, err := client.ListProjects(t.Context(), "acme")
 	if !errors.Is(err, ErrRateLimited) {
 		t.Errorf("expected ErrRateLimited, got %v", err)
 	}
@@ -201,7 +472,7 @@ func TestListProjects_ExecError(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	_, err := client.ListProjects("bad")
+	_, err := client.ListProjects(t.Context(), "bad")
 	if !errors.Is(err, ErrOrgNotFound) {
 		t.Errorf("expected ErrOrgNotFound from exec error, got %v", err)
 	}
@@ -259,7 +530,7 @@ func TestGetProjectFields_Success(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	result, err := client.GetProjectFields("acme", 1)
+	result, err := client.GetProjectFields(t.Context(), "acme", 1)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -317,12 +588,12 @@ func TestGetProjectFields_CacheHit(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	_, err := client.GetProjectFields("acme", 1)
+	_, err := client.GetProjectFields(t.Context(), "acme", 1)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	_, err = client.GetProjectFields("acme", 1)
+	_, err = client.GetProjectFields(t.Context(), "acme", 1)
 	if err != nil {
 		t.Fatalf("unexpected error on cached call: %v", err)
 	}
@@ -343,7 +614,7 @@ func TestGetProjectFields_GraphQLError(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	_, err := client.GetProjectFields("acme", 1)
+	_, err := client.GetProjectFields(t.Context(), "acme", 1)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -376,7 +647,7 @@ func TestGetProjectFields_SkipsEmptyNodes(t *testing.T) {
 	})
 	client := &Client{Exec: fake, cache: make(map[string]any)}
 
-	result, err := client.GetProjectFields("acme", 1)
+	result, err := client.GetProjectFields(t.Context(), "acme", 1)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -420,7 +691,7 @@ func TestParseFieldNode_IterationField(t *testing.T) {
 	raw := json.RawMessage(`{
 		"id": "f1", "name": "Sprint",
 		"configuration": {
-			"iterations": [{"id": "i1", "title": "Sprint 1"}]
+			"iterations": [{"id": "i1", "title": "Sprint 1", "startDate": "2026-10-12", "duration": 14}]
 		}
 	}`)
 	field, err := parseFieldNode(raw)
@@ -431,7 +702,10 @@ func TestParseFieldNode_IterationField(t *testing.T) {
 		t.Errorf("expected ITERATION, got %s", field.Type)
 	}
 	if len(field.Options) != 1 {
-		t.Errorf("expected 1 iteration, got %d", len(field.Options))
+		t.Fatalf("expected 1 iteration, got %d", len(field.Options))
+	}
+	if opt := field.Options[0]; opt.StartDate != "2026-10-12" || opt.Duration != 14 {
+		t.Errorf("expected start 2026-10-12 for 14 days, got %s for %d", opt.StartDate, opt.Duration)
 	}
 }
 
@@ -447,14 +721,6 @@ func TestParseFieldNode_EmptyNode(t *testing.T) {
 }
 
 // checkAuthExec is a helper that tests only the exec path of CheckAuth (not LookPath)
-func (c *Client) checkAuthExec() error {
-	out, err := c.Exec.Run([]string{"auth", "status"})
-	if err != nil {
-		s := string(out)
-		if strings.Contains(s, "not logged") || strings.Contains(s, "not authenticated") {
-			return ErrGHNotAuth
-		}
-		return fmt.Errorf("gh auth check failed: %s", s)
-	}
-	return nil
+func (c *Client) checkAuthExec(ctx context.Context) error {
+	return c.authStatus(ctx)
 }
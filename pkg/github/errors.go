@@ -3,6 +3,7 @@ package github
 import (
 	"errors"
 	"strings"
+	"time"
 )
 
 var (
@@ -22,3 +23,20 @@ type GraphQLError struct {
 func (e *GraphQLError) Error() string {
 	return "graphql error: " + strings.Join(e.Errors, "; ")
 }
+
+// RateLimitError is a rate-limited response. It matches ErrRateLimited with
+// errors.Is.
+type RateLimitError struct {
+	// RetryAfter is how long GitHub asked to wait, from the Retry-After or
+	// X-RateLimit-Reset header; zero when it didn't say.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return ErrRateLimited.Error() + " (resets in " + e.RetryAfter.Round(time.Second).String() + ")"
+	}
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
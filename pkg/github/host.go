@@ -0,0 +1,65 @@
+package github
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultHost is the public GitHub host.
+const DefaultHost = "github.com"
+
+// HostEnvVar is the environment variable gh reads for the host of commands
+// that take no --hostname flag.
+const HostEnvVar = "GH_HOST"
+
+// NormalizeHost reduces a configured host — "ghe.acme.com",
+// "https://ghe.acme.com/", "ghe.acme.com/api/graphql" — to the hostname gh
+// expects. The public API host api.github.com becomes github.com.
+func NormalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return ""
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return ""
+	}
+	h := strings.ToLower(u.Host)
+	if h == "api."+DefaultHost {
+		return DefaultHost
+	}
+	return h
+}
+
+// nonGitHubHosts are well-known hosts of other forges, which a project's
+// scm.host may name but gh can't query.
+var nonGitHubHosts = map[string]bool{
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+	"dev.azure.com": true,
+	"codeberg.org":  true,
+}
+
+// IsEnterpriseHost reports whether host names a GitHub other than
+// github.com, i.e. a GitHub Enterprise Server install. Hosts of other forges
+// (gitlab.com, any host with "gitlab" in its name, bitbucket.org, ...) are
+// not.
+func IsEnterpriseHost(host string) bool {
+	h := NormalizeHost(host)
+	if h == "" || h == DefaultHost || nonGitHubHosts[h] || strings.Contains(h, "gitlab") {
+		return false
+	}
+	return true
+}
+
+// HostEnv returns the environment that points gh commands at host: GH_HOST
+// for an enterprise host, nothing for github.com or an empty host.
+func HostEnv(host string) []string {
+	if !IsEnterpriseHost(host) {
+		return nil
+	}
+	return []string{HostEnvVar + "=" + NormalizeHost(host)}
+}
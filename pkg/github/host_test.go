@@ -0,0 +1,69 @@
+package github
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	tests := map[string]string{
+		"":                              "",
+		"github.com":                    "github.com",
+		"api.github.com":                "github.com",
+		"GHE.Acme.com":                  "ghe.acme.com",
+		"https://ghe.acme.com/":         "ghe.acme.com",
+		"ghe.acme.com/api/graphql":      "ghe.acme.com",
+		"https://ghe.acme.com:8443/api": "ghe.acme.com:8443",
+	}
+	for in, want := range tests {
+		if got := NormalizeHost(in); got != want {
+			t.Errorf("NormalizeHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHostEnv(t *testing.T) {
+	tests := map[string][]string{
+		"":                      nil,
+		"github.com":            nil,
+		"gitlab.com":            nil,
+		"gitlab.acme.com":       nil,
+		"https://ghe.acme.com/": {"GH_HOST=ghe.acme.com"},
+	}
+	for host, want := range tests {
+		if got := HostEnv(host); !slices.Equal(got, want) {
+			t.Errorf("HostEnv(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestClient_EnterpriseHost(t *testing.T) {
+	respJSON := `{"data": {"organization": {"projectsV2": {"nodes": [{"id": "PVT_1", "number": 1, "title": "Engineering"}]}}}}`
+	fake := newFakeExecer(map[string]fakeResponse{
+		"auth status": {output: []byte("Logged in to ghe.acme.com")},
+		"api graphql": {output: []byte(respJSON)},
+	})
+	client := &Client{Exec: fake, Host: "https://ghe.acme.com/"}
+
+	if _, err := client.ListProjects(t.Context(), "acme"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.checkAuthExec(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range fake.calls {
+		i := slices.Index(call, "--hostname")
+		if i < 0 || i+1 >= len(call) || call[i+1] != "ghe.acme.com" {
+			t.Errorf("expected --hostname ghe.acme.com in %v", call)
+		}
+	}
+
+	fake.calls = nil
+	client = &Client{Exec: fake}
+	if _, err := client.ListProjects(t.Context(), "acme"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(fake.calls[0], "--hostname") {
+		t.Errorf("github.com query passed --hostname: %v", fake.calls[0])
+	}
+}
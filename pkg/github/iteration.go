@@ -0,0 +1,51 @@
+package github
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IterationOptions maps an iteration field's iterations to the options
+// block of an iteration ore: one entry per iteration, keyed by its title in
+// snake_case ("Sprint 12" -> sprint_12), holding id, label, start_date and
+// duration. mold.ResolveFlux picks the running one from this map.
+func IterationOptions(field *Field) map[string]any {
+	options := make(map[string]any)
+	if field == nil || field.Type != FieldTypeIteration {
+		return options
+	}
+	for _, it := range field.Options {
+		key := optionKey(it.Name)
+		if key == "" {
+			key = "iteration"
+		}
+		for base, n := key, 2; options[key] != nil; n++ {
+			key = base + "_" + strconv.Itoa(n)
+		}
+		options[key] = map[string]any{
+			"id":         it.ID,
+			"label":      it.Name,
+			"start_date": it.StartDate,
+			"duration":   it.Duration,
+		}
+	}
+	return options
+}
+
+// optionKey turns a label into a snake_case flux key.
+func optionKey(label string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(label) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if sep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			sep = false
+			continue
+		}
+		sep = true
+	}
+	return b.String()
+}
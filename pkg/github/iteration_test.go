@@ -0,0 +1,27 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIterationOptions(t *testing.T) {
+	field := &Field{ID: "f1", Name: "Sprint", Type: FieldTypeIteration, Options: []Option{
+		{ID: "i1", Name: "Sprint 12", StartDate: "2026-10-12", Duration: 14},
+		{ID: "i2", Name: "Sprint 12", StartDate: "2026-10-26", Duration: 14},
+		{ID: "i3", Name: "Q4 — Hardening!", StartDate: "2026-11-09", Duration: 7},
+	}}
+	got := IterationOptions(field)
+	want := map[string]any{
+		"sprint_12":    map[string]any{"id": "i1", "label": "Sprint 12", "start_date": "2026-10-12", "duration": 14},
+		"sprint_12_2":  map[string]any{"id": "i2", "label": "Sprint 12", "start_date": "2026-10-26", "duration": 14},
+		"q4_hardening": map[string]any{"id": "i3", "label": "Q4 — Hardening!", "start_date": "2026-11-09", "duration": 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("IterationOptions = %v, want %v", got, want)
+	}
+
+	if got := IterationOptions(&Field{Type: FieldTypeSingleSelect, Options: []Option{{ID: "o1", Name: "Todo"}}}); len(got) != 0 {
+		t.Errorf("single-select field mapped to %v, want nothing", got)
+	}
+}
@@ -33,6 +33,9 @@ type Field struct {
 type Option struct {
 	ID   string
 	Name string
+	// StartDate (YYYY-MM-DD) and Duration (days) are set for iterations
+	StartDate string
+	Duration  int
 }
 
 // DiscoveryResult holds the full result of discovering a project's fields
@@ -0,0 +1,214 @@
+// Package keys manages the ed25519 keys used to sign and verify smelted
+// artifacts and remote molds: the user's own signing keys, and the
+// publisher keys they trust, optionally pinned to foundry hosts.
+//
+// Everything lives in one directory (~/.ailloy/keys): <name>.key and
+// <name>.pub PEM files for each signing key, and trusted.yaml for trusted
+// publishers.
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	privateSuffix = ".key"
+	publicSuffix  = ".pub"
+)
+
+// nameRegex is what key and publisher names must look like: they become
+// file names.
+var nameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Dir returns the keys directory (~/.ailloy/keys).
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".ailloy", "keys"), nil
+}
+
+// Store is a keys directory.
+type Store struct {
+	Dir string
+}
+
+// DefaultStore returns the Store for ~/.ailloy/keys.
+func DefaultStore() (*Store, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Dir: dir}, nil
+}
+
+// Key is one of the user's signing keys.
+type Key struct {
+	Name        string `json:"name" yaml:"name"`
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// ErrKeyExists is returned by Generate when a key of that name exists.
+var ErrKeyExists = errors.New("key already exists")
+
+// checkName rejects names that can't be used as a key file name.
+func checkName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid key name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// Generate creates the signing key name: <name>.key, readable only by the
+// user, and <name>.pub. An existing key is only replaced with force.
+func (s *Store) Generate(name string, force bool) (*Key, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	privPath := filepath.Join(s.Dir, name+privateSuffix)
+	if _, err := os.Stat(privPath); err == nil && !force {
+		return nil, fmt.Errorf("%w: %s (use --force to replace it)", ErrKeyExists, name)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("encoding private key: %w", err)
+	}
+	pubPEM, err := EncodePublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", s.Dir, err)
+	}
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600); err != nil {
+		return nil, fmt.Errorf("writing private key: %w", err)
+	}
+	//#nosec G306 -- public keys are meant to be shared
+	if err := os.WriteFile(filepath.Join(s.Dir, name+publicSuffix), pubPEM, 0o644); err != nil {
+		return nil, fmt.Errorf("writing public key: %w", err)
+	}
+	return &Key{Name: name, Fingerprint: Fingerprint(pub)}, nil
+}
+
+// Keys lists the user's signing keys by name.
+func (s *Store) Keys() ([]Key, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Key
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), privateSuffix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		pub, err := s.PublicKey(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Key{Name: name, Fingerprint: Fingerprint(pub)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// PrivateKey loads the signing key name.
+func (s *Store) PrivateKey(name string) (ed25519.PrivateKey, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir, name+privateSuffix)) // #nosec G304 -- name is checked and joined to the keys dir
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no signing key %q; create one with `ailloy keys generate %s`", name, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s%s is not a PEM private key", name, privateSuffix)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s: %w", name, privateSuffix, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s%s is not an ed25519 key", name, privateSuffix)
+	}
+	return priv, nil
+}
+
+// PublicKey returns the public half of the signing key name, derived from
+// the private key so a lost or edited .pub file doesn't matter.
+func (s *Store) PublicKey(name string) (ed25519.PublicKey, error) {
+	priv, err := s.PrivateKey(name)
+	if err != nil {
+		return nil, err
+	}
+	return priv.Public().(ed25519.PublicKey), nil
+}
+
+// Sign signs data with the signing key name.
+func (s *Store) Sign(name string, data []byte) ([]byte, error) {
+	priv, err := s.PrivateKey(name)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(priv, data), nil
+}
+
+// EncodePublicKey returns pub as a PEM "PUBLIC KEY" block, the form keys
+// are exported and trusted in.
+func EncodePublicKey(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("encoding public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePublicKey reads an ed25519 public key from a PEM "PUBLIC KEY" block.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("not a PEM public key (export one with `ailloy keys export`)")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not ed25519")
+	}
+	return pub, nil
+}
+
+// Fingerprint identifies a public key the way ssh-keygen does:
+// "SHA256:" and the unpadded base64 of its SHA-256.
+func Fingerprint(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+package keys
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestGenerateSignAndExport(t *testing.T) {
+	s := &Store{Dir: filepath.Join(t.TempDir(), "keys")}
+	key, err := s.Generate("release", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(s.Dir, "release.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm&0o077 != 0 {
+		t.Errorf("private key mode = %v, want owner-only", perm)
+	}
+	if _, err := s.Generate("release", false); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("second generate: err = %v, want ErrKeyExists", err)
+	}
+
+	pub, err := s.PublicKey("release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemData, err := EncodePublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePublicKey(pemData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Fingerprint(parsed) != key.Fingerprint {
+		t.Errorf("exported key fingerprint %s, want %s", Fingerprint(parsed), key.Fingerprint)
+	}
+
+	list, err := s.Keys()
+	if err != nil || len(list) != 1 || list[0] != *key {
+		t.Errorf("Keys() = %v, %v", list, err)
+	}
+	if _, err := s.Generate("../escape", false); err == nil {
+		t.Error("expected an invalid-name error")
+	}
+}
+
+func TestTrustPinningAndRevoke(t *testing.T) {
+	publisher := &Store{Dir: t.TempDir()}
+	user := &Store{Dir: t.TempDir()}
+	for _, name := range []string{"acme", "anyone"} {
+		if _, err := publisher.Generate(name, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	acmePub, _ := publisher.PublicKey("acme")
+	anyonePub, _ := publisher.PublicKey("anyone")
+	if _, err := user.Trust("acme", acmePub, []string{"https://GitHub.com/acme/"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := user.Trust("anyone", anyonePub, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("mold digest")
+	acmeSig, _ := publisher.Sign("acme", data)
+	anyoneSig, _ := publisher.Sign("anyone", data)
+
+	// A pinned source only accepts its pinned keys.
+	if k, err := user.Verify("github.com/acme/tools@v1.2.0", data, acmeSig); err != nil || k.Name != "acme" {
+		t.Fatalf("pinned verify = %v, %v", k, err)
+	}
+	if _, err := user.Verify("git@github.com:acme/tools.git", data, anyoneSig); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("unpinned key on a pinned source: err = %v, want ErrBadSignature", err)
+	}
+	// Other sources fall back to unpinned keys.
+	if k, err := user.Verify("gitlab.com/other/mold", data, anyoneSig); err != nil || k.Name != "anyone" {
+		t.Errorf("unpinned verify = %v, %v", k, err)
+	}
+
+	if _, err := user.Revoke("acme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := user.Verify("github.com/acme/tools", data, acmeSig); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("revoked key still verifies: %v", err)
+	}
+	if _, err := user.Trust("acme", acmePub, nil); err == nil {
+		t.Error("a revoked key should not be trusted again")
+	}
+	if _, err := user.Revoke("acme"); err == nil {
+		t.Error("revoking twice should fail")
+	}
+	if _, err := user.Revoke("ghost"); err == nil {
+		t.Error("revoking an unknown key should fail")
+	}
+	if _, err := user.Trust("anyone", acmePub, nil); err == nil {
+		t.Error("a name in use should not take a different key")
+	}
+}
+
+func TestNormalizeSource(t *testing.T) {
+	for in, want := range map[string]string{
+		"github.com":                             "github.com",
+		"GitHub.com/Acme/":                       "github.com/Acme",
+		"https://token@github.com/acme/m.git":    "github.com/acme/m",
+		"git@github.com:acme/m.git":              "github.com/acme/m",
+		"github.com/acme/mono//molds/web@v1.0.0": "github.com/acme/mono",
+		"github.com/acme/m@^1.2":                 "github.com/acme/m",
+	} {
+		if got := NormalizeSource(in); got != want {
+			t.Errorf("NormalizeSource(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
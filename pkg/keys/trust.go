@@ -0,0 +1,244 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+const trustFile = "trusted.yaml"
+
+// TrustedKey is a publisher key the user trusts to sign molds.
+//
+// Hosts pins the key to sources: each entry is a foundry host
+// ("github.com") or a path under one ("github.com/acme"). A source matched
+// by any pinned key is verified against its pinned keys only; keys with no
+// hosts are trusted for every other source. Revoked keys are kept, so
+// re-trusting one by mistake fails instead of silently succeeding.
+type TrustedKey struct {
+	Name        string     `json:"name" yaml:"name"`
+	Fingerprint string     `json:"fingerprint" yaml:"fingerprint"`
+	PublicKey   string     `json:"publicKey" yaml:"publicKey"` // PEM
+	Hosts       []string   `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+	Added       time.Time  `json:"added" yaml:"added"`
+	Revoked     *time.Time `json:"revoked,omitempty" yaml:"revoked,omitempty"`
+}
+
+type trustedFile struct {
+	Keys []TrustedKey `yaml:"keys"`
+}
+
+// Errors returned by Verify.
+var (
+	// ErrNoTrustedKeys means no trusted key applies to the source.
+	ErrNoTrustedKeys = errors.New("no trusted key for this source")
+	// ErrBadSignature means no applicable trusted key made the signature.
+	ErrBadSignature = errors.New("signature does not match any trusted key")
+)
+
+// Trusted returns every trusted key, revoked ones included, in the order
+// they were added.
+func (s *Store) Trusted() ([]TrustedKey, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, trustFile)) // #nosec G304 -- ailloy's own keys dir
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f trustedFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", trustFile, err)
+	}
+	return f.Keys, nil
+}
+
+func (s *Store) saveTrusted(keys []TrustedKey) error {
+	data, err := yaml.Marshal(trustedFile{Keys: keys})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("creating %s: %w", s.Dir, err)
+	}
+	return os.WriteFile(filepath.Join(s.Dir, trustFile), data, 0o600)
+}
+
+// Trust records pub as the publisher key name, pinned to hosts (none for
+// every source). Trusting a key already trusted under the same name adds
+// the hosts to its pins. A revoked key can't be trusted again, and a name
+// can't be reused for a different key.
+func (s *Store) Trust(name string, pub ed25519.PublicKey, hosts []string) (*TrustedKey, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	pins := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		h = NormalizeSource(h)
+		if h == "" {
+			return nil, fmt.Errorf("empty host")
+		}
+		pins = append(pins, h)
+	}
+	keys, err := s.Trusted()
+	if err != nil {
+		return nil, err
+	}
+	fp := Fingerprint(pub)
+	for i := range keys {
+		k := &keys[i]
+		switch {
+		case k.Fingerprint == fp && k.Revoked != nil:
+			return nil, fmt.Errorf("key %s (%s) was revoked on %s and can't be trusted again", k.Name, fp, k.Revoked.Format(time.DateOnly))
+		case k.Fingerprint == fp && k.Name != name:
+			return nil, fmt.Errorf("key %s is already trusted as %q", fp, k.Name)
+		case k.Fingerprint == fp:
+			for _, h := range pins {
+				if !slices.Contains(k.Hosts, h) {
+					k.Hosts = append(k.Hosts, h)
+				}
+			}
+			if err := s.saveTrusted(keys); err != nil {
+				return nil, err
+			}
+			return k, nil
+		case k.Name == name && k.Revoked == nil:
+			return nil, fmt.Errorf("publisher %q is already trusted with key %s; revoke it first", name, k.Fingerprint)
+		}
+	}
+
+	pubPEM, err := EncodePublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	keys = append(keys, TrustedKey{
+		Name:        name,
+		Fingerprint: fp,
+		PublicKey:   string(pubPEM),
+		Hosts:       pins,
+		Added:       time.Now().UTC().Truncate(time.Second),
+	})
+	if err := s.saveTrusted(keys); err != nil {
+		return nil, err
+	}
+	return &keys[len(keys)-1], nil
+}
+
+// Revoke marks the trusted key named ref, or with fingerprint ref, as
+// revoked. Signatures it made are no longer accepted; the name can be
+// trusted again with a new key.
+func (s *Store) Revoke(ref string) (*TrustedKey, error) {
+	keys, err := s.Trusted()
+	if err != nil {
+		return nil, err
+	}
+	revoked := false
+	for i := range keys {
+		k := &keys[i]
+		if k.Name != ref && k.Fingerprint != ref {
+			continue
+		}
+		if k.Revoked != nil {
+			revoked = true
+			continue
+		}
+		now := time.Now().UTC().Truncate(time.Second)
+		k.Revoked = &now
+		if err := s.saveTrusted(keys); err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
+	if revoked {
+		return nil, fmt.Errorf("key %s is already revoked", ref)
+	}
+	return nil, fmt.Errorf("no trusted key %q", ref)
+}
+
+// KeysFor returns the unrevoked trusted keys that may sign source, a
+// foundry reference or git URL: the keys pinned to it if there are any,
+// otherwise the unpinned ones.
+func (s *Store) KeysFor(source string) ([]TrustedKey, error) {
+	keys, err := s.Trusted()
+	if err != nil {
+		return nil, err
+	}
+	source = NormalizeSource(source)
+	var pinned, open []TrustedKey
+	for _, k := range keys {
+		if k.Revoked != nil {
+			continue
+		}
+		if len(k.Hosts) == 0 {
+			open = append(open, k)
+			continue
+		}
+		if slices.ContainsFunc(k.Hosts, func(h string) bool { return sourceUnder(source, h) }) {
+			pinned = append(pinned, k)
+		}
+	}
+	if len(pinned) > 0 {
+		return pinned, nil
+	}
+	return open, nil
+}
+
+// Verify checks that sig is a signature of data by a trusted key that may
+// sign source, and returns that key.
+func (s *Store) Verify(source string, data, sig []byte) (*TrustedKey, error) {
+	keys, err := s.KeysFor(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: %w", source, ErrNoTrustedKeys)
+	}
+	for _, k := range keys {
+		pub, err := ParsePublicKey([]byte(k.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("trusted key %s: %w", k.Name, err)
+		}
+		if ed25519.Verify(pub, data, sig) {
+			return &k, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", source, ErrBadSignature)
+}
+
+// NormalizeSource reduces a foundry reference, git URL or host pin to the
+// host/owner/repo path pins are matched against: no scheme, user, ".git",
+// version or subpath, and a lower-case host.
+func NormalizeSource(source string) string {
+	s := strings.TrimSpace(source)
+	if _, rest, ok := strings.Cut(s, "://"); ok {
+		s = rest
+		if user, host, ok := strings.Cut(s, "@"); ok && !strings.Contains(user, "/") {
+			s = host
+		}
+	} else if user, rest, ok := strings.Cut(s, "@"); ok && !strings.Contains(user, "/") && strings.Contains(rest, ":") {
+		s = strings.Replace(rest, ":", "/", 1) // scp-style git@host:owner/repo
+	}
+	s, _, _ = strings.Cut(s, "//")
+	if i := strings.LastIndex(s, "@"); i > strings.LastIndex(s, "/") {
+		s = s[:i]
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+	host, rest, _ := strings.Cut(s, "/")
+	if rest == "" {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(host) + "/" + rest
+}
+
+// sourceUnder reports whether source is pin or a path below it.
+func sourceUnder(source, pin string) bool {
+	return source == pin || strings.HasPrefix(source, pin+"/")
+}
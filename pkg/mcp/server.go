@@ -0,0 +1,293 @@
+// Package mcp implements the subset of the Model Context Protocol that
+// `ailloy mcp serve` needs: a JSON-RPC 2.0 server over newline-delimited
+// stdio that offers tools and prompts. The server knows nothing about molds;
+// callers register the tools and supply the prompts.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ProtocolVersion is the MCP revision the server speaks. Clients asking for
+// another revision are answered with this one, as the spec requires.
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// maxMessageSize bounds a single request line. Rendered molds are posted
+// back as tool results, not requests, so requests stay small.
+const maxMessageSize = 4 << 20
+
+// Tool is an operation clients can call. Handler receives the call's
+// arguments and returns the text result; an error is reported to the
+// client as a failed tool call rather than a protocol error, so the model
+// can see it.
+type Tool struct {
+	Name        string
+	Description string
+	// InputSchema is the JSON Schema of the arguments object.
+	InputSchema map[string]any
+	Handler     func(ctx context.Context, args map[string]any) (string, error)
+}
+
+// PromptArgument describes one argument a prompt accepts.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Prompt is a message template clients can offer the user. Render returns
+// the text of the single user message the prompt expands to.
+type Prompt struct {
+	Name        string
+	Description string
+	Arguments   []PromptArgument
+	Render      func(args map[string]string) (string, error)
+}
+
+// Server answers MCP requests. Prompts is called on every prompts/list and
+// prompts/get so the list tracks what is installed; nil means no prompts.
+type Server struct {
+	Name    string
+	Version string
+	Tools   []Tool
+	Prompts func() ([]Prompt, error)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+func errorf(code int, format string, a ...any) *rpcError {
+	return &rpcError{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// Serve reads requests from r and writes responses to w, one JSON message
+// per line, until r reaches EOF or ctx is canceled. Requests are handled in
+// order; notifications get no response.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	enc := json.NewEncoder(w)
+	send := func(resp response) error {
+		resp.JSONRPC = "2.0"
+		if resp.ID == nil {
+			resp.ID = json.RawMessage("null")
+		}
+		return enc.Encode(resp)
+	}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := send(response{Error: errorf(CodeParseError, "parse error: %v", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+		result, rerr := s.handle(ctx, req)
+		if len(req.ID) == 0 {
+			continue // notification
+		}
+		resp := response{ID: req.ID, Result: result}
+		if rerr != nil {
+			resp.Result = nil
+			var re *rpcError
+			if !errors.As(rerr, &re) {
+				re = errorf(CodeInternalError, "%v", rerr)
+			}
+			resp.Error = re
+		}
+		if err := send(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handle(ctx context.Context, req request) (any, error) {
+	if req.JSONRPC != "2.0" {
+		return nil, errorf(CodeInvalidRequest, "jsonrpc must be \"2.0\"")
+	}
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	case "prompts/list":
+		return s.listPrompts()
+	case "prompts/get":
+		return s.getPrompt(req.Params)
+	}
+	return nil, errorf(CodeMethodNotFound, "method not found: %s", req.Method)
+}
+
+func (s *Server) initialize() map[string]any {
+	caps := map[string]any{}
+	if len(s.Tools) > 0 {
+		caps["tools"] = map[string]any{}
+	}
+	if s.Prompts != nil {
+		caps["prompts"] = map[string]any{}
+	}
+	return map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    caps,
+		"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+	}
+}
+
+func (s *Server) listTools() map[string]any {
+	tools := make([]map[string]any, 0, len(s.Tools))
+	for _, t := range s.Tools {
+		schema := t.InputSchema
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		tools = append(tools, map[string]any{
+			"name":        t.Name,
+			"description": t.Description,
+			"inputSchema": schema,
+		})
+	}
+	return map[string]any{"tools": tools}
+}
+
+func (s *Server) callTool(ctx context.Context, raw json.RawMessage) (any, error) {
+	var params struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, errorf(CodeInvalidParams, "invalid tools/call params: %v", err)
+	}
+	for _, t := range s.Tools {
+		if t.Name != params.Name {
+			continue
+		}
+		if params.Arguments == nil {
+			params.Arguments = map[string]any{}
+		}
+		text, err := t.Handler(ctx, params.Arguments)
+		if err != nil {
+			return toolResult(err.Error(), true), nil
+		}
+		return toolResult(text, false), nil
+	}
+	return nil, errorf(CodeInvalidParams, "unknown tool: %s", params.Name)
+}
+
+func toolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func (s *Server) prompts() ([]Prompt, error) {
+	if s.Prompts == nil {
+		return nil, nil
+	}
+	prompts, err := s.Prompts()
+	if err != nil {
+		return nil, errorf(CodeInternalError, "listing prompts: %v", err)
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts, nil
+}
+
+func (s *Server) listPrompts() (any, error) {
+	prompts, err := s.prompts()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]map[string]any, 0, len(prompts))
+	for _, p := range prompts {
+		entry := map[string]any{"name": p.Name, "description": p.Description}
+		if len(p.Arguments) > 0 {
+			entry["arguments"] = p.Arguments
+		}
+		out = append(out, entry)
+	}
+	return map[string]any{"prompts": out}, nil
+}
+
+func (s *Server) getPrompt(raw json.RawMessage) (any, error) {
+	var params struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, errorf(CodeInvalidParams, "invalid prompts/get params: %v", err)
+	}
+	prompts, err := s.prompts()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prompts {
+		if p.Name != params.Name {
+			continue
+		}
+		for _, a := range p.Arguments {
+			if a.Required && params.Arguments[a.Name] == "" {
+				return nil, errorf(CodeInvalidParams, "prompt %s requires argument %q", p.Name, a.Name)
+			}
+		}
+		text, err := p.Render(params.Arguments)
+		if err != nil {
+			return nil, errorf(CodeInternalError, "rendering prompt %s: %v", p.Name, err)
+		}
+		return map[string]any{
+			"description": p.Description,
+			"messages": []map[string]any{{
+				"role":    "user",
+				"content": map[string]string{"type": "text", "text": text},
+			}},
+		}, nil
+	}
+	return nil, errorf(CodeInvalidParams, "unknown prompt: %s", params.Name)
+}
@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testServer() *Server {
+	return &Server{
+		Name:    "test",
+		Version: "1.0.0",
+		Tools: []Tool{
+			{
+				Name:        "echo",
+				Description: "Echo the text argument",
+				Handler: func(_ context.Context, args map[string]any) (string, error) {
+					text, _ := args["text"].(string)
+					if text == "" {
+						return "", errors.New("text is required")
+					}
+					return text, nil
+				},
+			},
+		},
+		Prompts: func() ([]Prompt, error) {
+			return []Prompt{
+				{
+					Name:      "zeta",
+					Arguments: []PromptArgument{{Name: "topic", Required: true}},
+					Render: func(args map[string]string) (string, error) {
+						return "talk about " + args["topic"], nil
+					},
+				},
+				{Name: "alpha", Description: "first", Render: func(map[string]string) (string, error) { return "a", nil }},
+			}, nil
+		},
+	}
+}
+
+// exchange sends each request line and decodes one response per line.
+func exchange(t *testing.T, s *Server, lines ...string) []map[string]any {
+	t.Helper()
+	var out strings.Builder
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var resps []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("bad response %q: %v", scanner.Text(), err)
+		}
+		resps = append(resps, m)
+	}
+	return resps
+}
+
+func TestServe_InitializeAndNotifications(t *testing.T) {
+	resps := exchange(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-01-01"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":"p","method":"ping"}`,
+	)
+	if len(resps) != 2 {
+		t.Fatalf("expected 2 responses (notification unanswered), got %d: %v", len(resps), resps)
+	}
+	result := resps[0]["result"].(map[string]any)
+	if result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("protocolVersion = %v", result["protocolVersion"])
+	}
+	caps := result["capabilities"].(map[string]any)
+	if _, ok := caps["tools"]; !ok {
+		t.Error("tools capability missing")
+	}
+	if _, ok := caps["prompts"]; !ok {
+		t.Error("prompts capability missing")
+	}
+	if resps[1]["id"] != "p" {
+		t.Errorf("ping id = %v", resps[1]["id"])
+	}
+}
+
+func TestServe_Tools(t *testing.T) {
+	resps := exchange(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"nope"}}`,
+	)
+	tools := resps[0]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "echo" {
+		t.Fatalf("tools/list = %v", tools)
+	}
+	if schema := tools[0].(map[string]any)["inputSchema"].(map[string]any); schema["type"] != "object" {
+		t.Errorf("default inputSchema = %v", schema)
+	}
+
+	ok := resps[1]["result"].(map[string]any)
+	if ok["isError"] != false || ok["content"].([]any)[0].(map[string]any)["text"] != "hi" {
+		t.Errorf("echo result = %v", ok)
+	}
+	failed := resps[2]["result"].(map[string]any)
+	if failed["isError"] != true || !strings.Contains(failed["content"].([]any)[0].(map[string]any)["text"].(string), "text is required") {
+		t.Errorf("handler error should be a failed tool result, got %v", failed)
+	}
+	if code := resps[3]["error"].(map[string]any)["code"]; code != float64(CodeInvalidParams) {
+		t.Errorf("unknown tool code = %v", code)
+	}
+}
+
+func TestServe_Prompts(t *testing.T) {
+	resps := exchange(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"zeta","arguments":{"topic":"molds"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"zeta"}}`,
+	)
+	prompts := resps[0]["result"].(map[string]any)["prompts"].([]any)
+	if len(prompts) != 2 || prompts[0].(map[string]any)["name"] != "alpha" {
+		t.Fatalf("prompts/list should be sorted by name, got %v", prompts)
+	}
+	msgs := resps[1]["result"].(map[string]any)["messages"].([]any)
+	content := msgs[0].(map[string]any)["content"].(map[string]any)
+	if content["text"] != "talk about molds" {
+		t.Errorf("prompt text = %v", content["text"])
+	}
+	if msg := resps[2]["error"].(map[string]any)["message"].(string); !strings.Contains(msg, `requires argument "topic"`) {
+		t.Errorf("missing argument error = %q", msg)
+	}
+}
+
+func TestServe_Errors(t *testing.T) {
+	resps := exchange(t, testServer(),
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"1.0","id":2,"method":"ping"}`,
+	)
+	want := []float64{CodeParseError, CodeMethodNotFound, CodeInvalidRequest}
+	if len(resps) != len(want) {
+		t.Fatalf("expected %d responses, got %v", len(want), resps)
+	}
+	for i, code := range want {
+		if got := resps[i]["error"].(map[string]any)["code"]; got != code {
+			t.Errorf("response %d code = %v, want %v", i, got, code)
+		}
+	}
+	if resps[0]["id"] != nil {
+		t.Errorf("parse error id = %v, want null", resps[0]["id"])
+	}
+}
@@ -43,12 +43,19 @@ func AppendFile(destPath string, newContent []byte, opts AppendOptions) error {
 	default:
 		return fmt.Errorf("%w: %s", ErrUnsupportedAppendExt, destPath)
 	}
+	return AppendBlock(destPath, newContent, opts)
+}
 
-	// Build the sentinel block.
-	startMark := fmt.Sprintf("<!-- ailloy:mold=%s:start -->", opts.MoldName)
-	endMark := fmt.Sprintf("<!-- ailloy:mold=%s:end -->", opts.MoldName)
-	body := bytes.TrimRight(newContent, "\n")
-	block := fmt.Sprintf("%s\n%s\n%s\n", startMark, body, endMark)
+// AppendBlock is AppendFile without the extension check, for markdown-like
+// files with no .md extension (e.g. .windsurfrules). The sentinels are HTML
+// comments, so the destination must tolerate them.
+func AppendBlock(destPath string, newContent []byte, opts AppendOptions) error {
+	if opts.MoldName == "" {
+		return fmt.Errorf("AppendBlock: MoldName is required")
+	}
+
+	startMark, endMark := blockMarks(opts.MoldName)
+	block := Block(newContent, opts.MoldName)
 
 	existing, err := os.ReadFile(destPath) // #nosec G304 -- caller-controlled cast destination
 	if err != nil {
@@ -56,7 +63,7 @@ func AppendFile(destPath string, newContent []byte, opts AppendOptions) error {
 			return fmt.Errorf("read existing %s: %w", destPath, err)
 		}
 		// Doesn't exist — create with just our block.
-		return writeAll(destPath, []byte(block))
+		return writeAll(destPath, block)
 	}
 
 	// Look for an existing block keyed by MoldName.
@@ -65,7 +72,7 @@ func AppendFile(destPath string, newContent []byte, opts AppendOptions) error {
 	)
 	if pattern.Match(existing) {
 		// Replace in place.
-		updated := pattern.ReplaceAll(existing, []byte(block))
+		updated := pattern.ReplaceAll(existing, block)
 		return writeAll(destPath, updated)
 	}
 
@@ -76,6 +83,25 @@ func AppendFile(destPath string, newContent []byte, opts AppendOptions) error {
 	if len(out) > 0 {
 		buf.WriteString("\n\n")
 	}
-	buf.WriteString(block)
+	buf.Write(block)
 	return writeAll(destPath, buf.Bytes())
 }
+
+// Block returns newContent wrapped in moldName's sentinel block, as
+// AppendBlock writes it into a file that does not exist yet.
+func Block(newContent []byte, moldName string) []byte {
+	startMark, endMark := blockMarks(moldName)
+	body := bytes.TrimRight(newContent, "\n")
+	return fmt.Appendf(nil, "%s\n%s\n%s\n", startMark, body, endMark)
+}
+
+// HasBlock reports whether data contains the sentinel block of moldName.
+func HasBlock(data []byte, moldName string) bool {
+	startMark, endMark := blockMarks(moldName)
+	start := bytes.Index(data, []byte(startMark))
+	return start >= 0 && bytes.Contains(data[start:], []byte(endMark))
+}
+
+func blockMarks(moldName string) (start, end string) {
+	return fmt.Sprintf("<!-- ailloy:mold=%s:start -->", moldName), fmt.Sprintf("<!-- ailloy:mold=%s:end -->", moldName)
+}
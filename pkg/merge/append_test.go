@@ -151,6 +151,21 @@ func TestAppendFile_UnsupportedExtension(t *testing.T) {
 	}
 }
 
+func TestAppendBlock_AllowsExtensionlessDest(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, ".windsurfrules")
+	if err := AppendBlock(dest, []byte("rules"), AppendOptions{MoldName: "wiki"}); err != nil {
+		t.Fatalf("AppendBlock: %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "<!-- ailloy:mold=wiki:start -->\nrules\n") {
+		t.Errorf("unexpected content:\n%s", got)
+	}
+}
+
 func TestAppendFile_RequiresMoldName(t *testing.T) {
 	dir := t.TempDir()
 	dest := filepath.Join(dir, "AGENTS.md")
@@ -172,3 +187,16 @@ func TestAppendFile_MarkdownExtVariants(t *testing.T) {
 		}
 	}
 }
+
+func TestHasBlock(t *testing.T) {
+	data := []byte("# Project\n\n<!-- ailloy:mold=wiki:start -->\nwiki\n<!-- ailloy:mold=wiki:end -->\n")
+	if !HasBlock(data, "wiki") {
+		t.Error("wiki block not found")
+	}
+	if HasBlock(data, "other") {
+		t.Error("found a block for a mold that has none")
+	}
+	if HasBlock([]byte("<!-- ailloy:mold=wiki:start -->\nunterminated\n"), "wiki") {
+		t.Error("an unterminated block should not count")
+	}
+}
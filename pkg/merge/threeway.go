@@ -0,0 +1,43 @@
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
+)
+
+// ThreeWay merges the changes between base and theirs into ours, line by
+// line, with `git merge-file`. ours is the local copy, base the version both
+// started from and theirs the new version. clean is false when the changes
+// overlap; merged then holds git's conflict markers.
+func ThreeWay(ours, base, theirs []byte) (merged []byte, clean bool, err error) {
+	dir, err := tmpdir.MkdirTemp("merge-*")
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	names := []string{"ours", "base", "theirs"}
+	for i, data := range [][]byte{ours, base, theirs} {
+		if err := os.WriteFile(filepath.Join(dir, names[i]), data, 0600); err != nil {
+			return nil, false, fmt.Errorf("staging three-way merge: %w", err)
+		}
+	}
+
+	cmd := exec.Command("git", "merge-file", "-p", "-L", "local", "-L", "base", "-L", "mold", names[0], names[1], names[2]) // #nosec G204 -- fixed arguments
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		return out, true, nil
+	case errors.As(err, &exit) && exit.ExitCode() > 0 && exit.ExitCode() < 128:
+		return out, false, nil // exit code is the number of conflicts
+	default:
+		return nil, false, fmt.Errorf("git merge-file: %w", err)
+	}
+}
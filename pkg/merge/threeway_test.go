@@ -0,0 +1,28 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThreeWay(t *testing.T) {
+	base := []byte("one\ntwo\nthree\nfour\nfive\n")
+	ours := []byte("one\ntwo\nthree\nfour\nfive\nsix\n")
+	theirs := []byte("ONE\ntwo\nthree\nfour\nfive\n")
+
+	merged, clean, err := ThreeWay(ours, base, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clean || string(merged) != "ONE\ntwo\nthree\nfour\nfive\nsix\n" {
+		t.Errorf("merged = %q, clean = %v", merged, clean)
+	}
+
+	merged, clean, err = ThreeWay([]byte("uno\ntwo\n"), []byte("one\ntwo\n"), []byte("ONE\ntwo\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clean || !strings.Contains(string(merged), "<<<<<<< local") {
+		t.Errorf("conflict: merged = %q, clean = %v", merged, clean)
+	}
+}
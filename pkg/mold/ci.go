@@ -0,0 +1,212 @@
+package mold
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// CI systems a mold can ship workflow blanks for.
+const (
+	CIGitHub = "github"
+	CIGitLab = "gitlab"
+	CICircle = "circle"
+	CIAzure  = "azure"
+)
+
+// CISystem describes where a CI system keeps its configuration. A cast
+// destination under one of Roots is a workflow blank for that system.
+type CISystem struct {
+	Name  string
+	Title string
+	// Roots are destination directories (ending in "/") and files owned by
+	// the system.
+	Roots []string
+	// Markers are repository paths whose presence means the repository
+	// uses the system.
+	Markers []string
+}
+
+// ciSystems lists the supported CI systems in detection order.
+var ciSystems = []CISystem{
+	{Name: CIGitHub, Title: "GitHub Actions", Roots: []string{".github/"}, Markers: []string{".github/workflows"}},
+	{Name: CIGitLab, Title: "GitLab CI", Roots: []string{".gitlab-ci.yml", ".gitlab/ci/"}, Markers: []string{".gitlab-ci.yml"}},
+	{Name: CICircle, Title: "CircleCI", Roots: []string{".circleci/"}, Markers: []string{".circleci"}},
+	{Name: CIAzure, Title: "Azure Pipelines", Roots: []string{"azure-pipelines.yml", ".azure-pipelines/"}, Markers: []string{"azure-pipelines.yml", ".azure-pipelines"}},
+}
+
+// CISystems returns the supported CI systems.
+func CISystems() []CISystem {
+	return append([]CISystem(nil), ciSystems...)
+}
+
+// CINames returns the names of the supported CI systems.
+func CINames() []string {
+	names := make([]string, len(ciSystems))
+	for i, s := range ciSystems {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// LookupCI returns the CI system with the given name.
+func LookupCI(name string) (CISystem, bool) {
+	for _, s := range ciSystems {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return CISystem{}, false
+}
+
+// CIForDest returns the CI system a cast destination (file or directory)
+// belongs to, if any.
+func CIForDest(dest string) (CISystem, bool) {
+	dest = strings.TrimPrefix(path.Clean(filepath.ToSlash(dest)), "./")
+	for _, s := range ciSystems {
+		for _, root := range s.Roots {
+			if dir, ok := strings.CutSuffix(root, "/"); ok {
+				if dest == dir || strings.HasPrefix(dest, dir+"/") {
+					return s, true
+				}
+			} else if dest == root {
+				return s, true
+			}
+		}
+	}
+	return CISystem{}, false
+}
+
+// SkipCIFile reports whether a cast leaves out dest when it includes the
+// workflow blanks of ci: dest is a workflow blank of another CI system, or
+// of any system when ci is empty.
+func SkipCIFile(dest, ci string) bool {
+	s, ok := CIForDest(dest)
+	return ok && s.Name != ci
+}
+
+// DetectCI returns the first CI system whose markers exist under dir.
+func DetectCI(dir string) (CISystem, bool) {
+	for _, s := range ciSystems {
+		for _, marker := range s.Markers {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(marker))); err == nil {
+				return s, true
+			}
+		}
+	}
+	return CISystem{}, false
+}
+
+// GitLabIncludeDir holds the GitLab CI files a mold ships; cast lists each in
+// the include: of the repository's .gitlab-ci.yml.
+const GitLabIncludeDir = ".gitlab/ci/"
+
+var includeKeyLine = regexp.MustCompile(`^include:\s*(#.*)?$`)
+
+// GitLabIncludes adds a `- local: /<path>` entry to the include: list of a
+// .gitlab-ci.yml for each path it doesn't include yet, and reports whether
+// anything was added. A block-list include: gets the new entries appended
+// in place; any other form is rewritten as a list. Without include: the
+// list is put first.
+func GitLabIncludes(config []byte, paths []string) ([]byte, bool, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(config, &doc); err != nil {
+		return nil, false, fmt.Errorf("parsing .gitlab-ci.yml: %w", err)
+	}
+
+	current, hasInclude := doc["include"]
+	var entries []any
+	switch v := current.(type) {
+	case nil:
+	case []any:
+		entries = v
+	default:
+		entries = []any{v}
+	}
+	included := map[string]bool{}
+	for _, e := range entries {
+		switch v := e.(type) {
+		case string:
+			included[strings.TrimPrefix(v, "/")] = true
+		case map[string]any:
+			if local, ok := v["local"].(string); ok {
+				included[strings.TrimPrefix(local, "/")] = true
+			}
+		}
+	}
+	var missing []string
+	for _, p := range paths {
+		p = strings.TrimPrefix(filepath.ToSlash(p), "/")
+		if !included[p] {
+			included[p] = true
+			missing = append(missing, "/"+p)
+		}
+	}
+	if len(missing) == 0 {
+		return config, false, nil
+	}
+
+	lines := strings.Split(string(config), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "include:") {
+			start = i
+			break
+		}
+	}
+	if !hasInclude || start < 0 {
+		var b strings.Builder
+		b.WriteString("include:\n")
+		for _, p := range missing {
+			b.WriteString("  - local: " + p + "\n")
+		}
+		if len(strings.TrimSpace(string(config))) > 0 {
+			b.WriteString("\n")
+			b.Write(config)
+		}
+		return []byte(b.String()), true, nil
+	}
+
+	// The include: block runs until the next line at column zero that
+	// isn't a list item.
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+			break
+		}
+		end = i
+	}
+
+	var block []string
+	if _, isList := current.([]any); isList && includeKeyLine.MatchString(lines[start]) && end > start {
+		indent := "  "
+		if first := lines[start+1]; strings.HasPrefix(strings.TrimLeft(first, " "), "-") {
+			indent = first[:len(first)-len(strings.TrimLeft(first, " "))]
+		}
+		block = append(block, lines[start:end+1]...)
+		for _, p := range missing {
+			block = append(block, indent+"- local: "+p)
+		}
+	} else {
+		for _, p := range missing {
+			entries = append(entries, map[string]any{"local": p})
+		}
+		out, err := yaml.Marshal(map[string]any{"include": entries})
+		if err != nil {
+			return nil, false, err
+		}
+		block = strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	}
+
+	result := append(append(append([]string{}, lines[:start]...), block...), lines[end+1:]...)
+	return []byte(strings.Join(result, "\n")), true, nil
+}
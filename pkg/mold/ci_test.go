@@ -0,0 +1,114 @@
+package mold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCIForDest(t *testing.T) {
+	tests := map[string]string{
+		".github/workflows/ci.yml":       CIGitHub,
+		".github/CODEOWNERS":             CIGitHub,
+		".gitlab-ci.yml":                 CIGitLab,
+		".gitlab/ci/lint.yml":            CIGitLab,
+		".circleci/config.yml":           CICircle,
+		"azure-pipelines.yml":            CIAzure,
+		".azure-pipelines/release.yml":   CIAzure,
+		".claude/commands/hello.md":      "",
+		".gitlab/issue_templates/bug.md": "",
+		"docs/.github/ci.yml":            "",
+	}
+	for dest, want := range tests {
+		s, ok := CIForDest(dest)
+		if ok != (want != "") || s.Name != want {
+			t.Errorf("CIForDest(%q) = %q, %v; want %q", dest, s.Name, ok, want)
+		}
+	}
+}
+
+func TestSkipCIFile(t *testing.T) {
+	if !SkipCIFile(".github/workflows/ci.yml", "") {
+		t.Error("workflow blanks are skipped when no CI is selected")
+	}
+	if SkipCIFile(".github/workflows/ci.yml", CIGitHub) {
+		t.Error("GitHub workflows are cast for --ci github")
+	}
+	if !SkipCIFile(".github/workflows/ci.yml", CIGitLab) {
+		t.Error("GitHub workflows are skipped for --ci gitlab")
+	}
+	if SkipCIFile(".claude/commands/hello.md", "") {
+		t.Error("non-CI files are never skipped")
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := DetectCI(dir); ok {
+		t.Error("detected a CI system in an empty directory")
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitlab-ci.yml"), []byte("stages: [test]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := DetectCI(dir); !ok || s.Name != CIGitLab {
+		t.Errorf("DetectCI = %q, %v; want gitlab", s.Name, ok)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := DetectCI(dir); s.Name != CIGitHub {
+		t.Errorf("DetectCI = %q; GitHub Actions is checked first", s.Name)
+	}
+}
+
+func TestGitLabIncludes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "empty",
+			config: "",
+			want:   "include:\n  - local: /.gitlab/ci/lint.yml\n",
+		},
+		{
+			name:   "no include",
+			config: "stages:\n  - test\n",
+			want:   "include:\n  - local: /.gitlab/ci/lint.yml\n\nstages:\n  - test\n",
+		},
+		{
+			name:   "block list",
+			config: "# pipeline\ninclude:\n  - local: /ci/base.yml  # shared\n  - template: Security/SAST.gitlab-ci.yml\n\nstages:\n  - test\n",
+			want:   "# pipeline\ninclude:\n  - local: /ci/base.yml  # shared\n  - template: Security/SAST.gitlab-ci.yml\n  - local: /.gitlab/ci/lint.yml\n\nstages:\n  - test\n",
+		},
+		{
+			name:   "already included",
+			config: "include:\n  - local: .gitlab/ci/lint.yml\n",
+			want:   "include:\n  - local: .gitlab/ci/lint.yml\n",
+		},
+		{
+			name:   "single string",
+			config: "include: /ci/base.yml\nstages: [test]\n",
+			want:   "include:\n- /ci/base.yml\n- local: /.gitlab/ci/lint.yml\nstages: [test]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := GitLabIncludes([]byte(tt.config), []string{".gitlab/ci/lint.yml"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if changed != (tt.config != tt.want) {
+				t.Errorf("changed = %v", changed)
+			}
+		})
+	}
+
+	if _, _, err := GitLabIncludes([]byte("include: [unclosed\n"), []string{"x.yml"}); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
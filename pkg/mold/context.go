@@ -0,0 +1,85 @@
+package mold
+
+import (
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ContextKey is the reserved template data key holding the cast context:
+// blanks read it as {{._ailloy.version}}, {{._ailloy.mold.name}}, and so on.
+// A flux value under the same key is replaced.
+const ContextKey = "_ailloy"
+
+// CastContext is what ailloy knows about a cast beyond its flux values.
+type CastContext struct {
+	Version     string    // running ailloy version, without a leading "v"
+	Timestamp   time.Time // when the cast started
+	MoldName    string
+	MoldVersion string
+	Source      string // remote mold source (host/owner/repo[//subpath]); "" for a local mold
+	Git         GitInfo
+}
+
+// GitInfo describes the git repository a mold is cast into.
+type GitInfo struct {
+	RepoInfo
+	Branch string // checked-out branch; "" on a detached HEAD
+	Commit string // HEAD commit SHA
+}
+
+// DetectGit returns what can be learned about the git repository containing
+// dir; fields it can't determine (no origin, no commits yet) stay empty, and
+// outside a repository the result is empty.
+func DetectGit(dir string) GitInfo {
+	var info GitInfo
+	if repo, ok := DetectRepo(dir); ok {
+		info.RepoInfo = repo
+	}
+	git := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output() // #nosec G204 -- fixed git subcommands
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	info.Branch = git("symbolic-ref", "--short", "-q", "HEAD")
+	info.Commit = git("rev-parse", "-q", "--verify", "HEAD")
+	return info
+}
+
+// Data returns c as template data. Every key is present, empty when unknown,
+// so blanks can test it without unresolved-variable warnings.
+func (c CastContext) Data() map[string]any {
+	timestamp := ""
+	if !c.Timestamp.IsZero() {
+		timestamp = c.Timestamp.UTC().Format(time.RFC3339)
+	}
+	return map[string]any{
+		"version":   c.Version,
+		"timestamp": timestamp,
+		"mold": map[string]any{
+			"name":    c.MoldName,
+			"version": c.MoldVersion,
+		},
+		"source": c.Source,
+		"git": map[string]any{
+			"host":           c.Git.Host,
+			"owner":          c.Git.Owner,
+			"repo":           c.Git.Name,
+			"default_branch": c.Git.DefaultBranch,
+			"branch":         c.Git.Branch,
+			"commit":         c.Git.Commit,
+		},
+	}
+}
+
+// ApplyCastContext stores c in flux under ContextKey, replacing any value a
+// flux layer set there, and returns flux.
+func ApplyCastContext(flux map[string]any, c CastContext) map[string]any {
+	if flux == nil {
+		flux = make(map[string]any)
+	}
+	flux[ContextKey] = c.Data()
+	return flux
+}
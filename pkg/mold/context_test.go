@@ -0,0 +1,69 @@
+package mold
+
+import (
+	"bytes"
+	"log"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestProcessTemplate_CastContext(t *testing.T) {
+	c := CastContext{
+		Version:     "1.4.0",
+		Timestamp:   time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600)),
+		MoldName:    "nimble",
+		MoldVersion: "0.3.0",
+		Source:      "github.com/acme/molds//nimble",
+		Git:         GitInfo{RepoInfo: RepoInfo{Host: "github.com", Owner: "acme", Name: "app"}, Branch: "main", Commit: "abc123"},
+	}
+	flux := ApplyCastContext(map[string]any{ContextKey: "user value"}, c)
+
+	tmpl := `{{_ailloy.mold.name}}@{{_ailloy.mold.version}} by ailloy {{._ailloy.version}} at {{_ailloy.timestamp}} from {{_ailloy.source}} into {{_ailloy.git.owner}}/{{_ailloy.git.repo}}@{{_ailloy.git.branch}}{{if ge ._ailloy.version "1.2"}} (new){{end}}`
+	got, err := ProcessTemplate(tmpl, flux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "nimble@0.3.0 by ailloy 1.4.0 at 2026-03-01T11:00:00Z from github.com/acme/molds//nimble into acme/app@main (new)"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestProcessTemplate_EmptyCastContext(t *testing.T) {
+	var warnings bytes.Buffer
+	logger := log.New(&warnings, "", 0)
+
+	got, err := ProcessTemplate(`[{{_ailloy.version}}]{{if ._ailloy.git.commit}}stamped{{end}}`, nil, WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "[]" {
+		t.Errorf("got %q, want %q", got, "[]")
+	}
+	if warnings.Len() > 0 {
+		t.Errorf("unexpected warnings: %s", warnings.String())
+	}
+}
+
+func TestDetectGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if got := DetectGit(dir); got != (GitInfo{}) {
+		t.Errorf("DetectGit outside a repository = %+v, want empty", got)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "feature"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	got := DetectGit(dir)
+	if got.Branch != "feature" || len(got.Commit) < 40 || got.Host != "" {
+		t.Errorf("DetectGit = %+v, want branch feature, a commit, and no remote", got)
+	}
+}
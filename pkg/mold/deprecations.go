@@ -0,0 +1,65 @@
+package mold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/goccy/go-yaml"
+)
+
+// DeprecationsFile is the optional file next to mold.yaml that marks
+// published versions as deprecated or yanked. It has the same shape as the
+// manifest's `deprecations:` block.
+const DeprecationsFile = "DEPRECATIONS.yaml"
+
+// Deprecations marks published versions as deprecated (cast warns) or yanked
+// (cast refuses unless --allow-yanked). Keys are exact versions or semver
+// constraints; values explain why. Consumers read the rules from the newest
+// release, so a version is yanked by listing it in a later one.
+type Deprecations struct {
+	Deprecated map[string]string `yaml:"deprecated,omitempty"`
+	Yanked     map[string]string `yaml:"yanked,omitempty"`
+}
+
+// LoadDeprecations reads and parses a DEPRECATIONS.yaml from fsys. A missing
+// file yields nil and no error.
+func LoadDeprecations(fsys fs.FS, path string) (*Deprecations, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var d Deprecations
+	if err := yaml.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &d, nil
+}
+
+// problems returns one message per rule whose key isn't a version or semver
+// constraint, or whose reason is empty. field prefixes each message.
+func (d *Deprecations) problems(field string) []string {
+	if d == nil {
+		return nil
+	}
+	var out []string
+	for _, group := range []struct {
+		name  string
+		rules map[string]string
+	}{{"deprecated", d.Deprecated}, {"yanked", d.Yanked}} {
+		for _, key := range slices.Sorted(maps.Keys(group.rules)) {
+			if _, err := semver.NewConstraint(key); err != nil {
+				out = append(out, fmt.Sprintf("%s%s[%q] is not a valid version or constraint", field, group.name, key))
+			} else if group.rules[key] == "" {
+				out = append(out, fmt.Sprintf("%s%s[%q] needs a reason", field, group.name, key))
+			}
+		}
+	}
+	return out
+}
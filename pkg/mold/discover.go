@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"text/template"
@@ -19,16 +20,26 @@ type DiscoverResult struct {
 
 // DiscoverExecutor runs discovery commands and parses their output.
 type DiscoverExecutor struct {
-	// RunCmd executes a shell command and returns its stdout.
-	// Injectable for testing; defaults to real shell execution.
-	RunCmd func(command string) ([]byte, error)
+	// RunCmd executes a shell command with env added to the environment and
+	// returns its stdout. Injectable for testing; defaults to real shell
+	// execution.
+	RunCmd func(command string, env []string) ([]byte, error)
+	// Policy is checked against each expanded command before it runs.
+	Policy ExecPolicy
+	// Env, when set, returns extra KEY=value environment entries for a
+	// command from the flux it is expanded against (e.g. GH_HOST for an
+	// enterprise scm.host).
+	Env func(flux map[string]any) []string
 }
 
 // NewDiscoverExecutor creates a DiscoverExecutor that uses the real shell.
 func NewDiscoverExecutor() *DiscoverExecutor {
 	return &DiscoverExecutor{
-		RunCmd: func(command string) ([]byte, error) {
-			cmd := exec.Command("sh", "-c", command) // #nosec G204 -- discovery commands are authored by mold creators (trusted)
+		RunCmd: func(command string, env []string) ([]byte, error) {
+			cmd := exec.Command("sh", "-c", command) // #nosec G204 -- discovery commands pass the caller's ExecPolicy first
+			if len(env) > 0 {
+				cmd.Env = append(os.Environ(), env...)
+			}
 			var stderr bytes.Buffer
 			cmd.Stderr = &stderr
 			out, err := cmd.Output()
@@ -50,8 +61,16 @@ func (d *DiscoverExecutor) Run(spec DiscoverSpec, flux map[string]any) ([]Discov
 		return nil, fmt.Errorf("expanding discover command template: %w", err)
 	}
 
+	if err := d.Policy.CheckCommand(expandedCmd); err != nil {
+		return nil, err
+	}
+
 	// Execute the command
-	output, err := d.RunCmd(expandedCmd)
+	var env []string
+	if d.Env != nil {
+		env = d.Env(flux)
+	}
+	output, err := d.RunCmd(expandedCmd, env)
 	if err != nil {
 		return nil, fmt.Errorf("running discover command: %w", err)
 	}
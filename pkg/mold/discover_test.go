@@ -7,7 +7,7 @@ import (
 
 func TestDiscoverExecutor_SimpleLineOutput(t *testing.T) {
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte("option1\noption2\noption3\n"), nil
 		},
 	}
@@ -29,7 +29,7 @@ func TestDiscoverExecutor_SimpleLineOutput(t *testing.T) {
 
 func TestDiscoverExecutor_PipeDelimitedOutput(t *testing.T) {
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte("Engineering (#5)|PVT_abc123\nDesign (#8)|PVT_def456\n"), nil
 		},
 	}
@@ -53,7 +53,7 @@ func TestDiscoverExecutor_WithParseTemplate(t *testing.T) {
 	jsonOutput := `{"items":[{"name":"Alpha","id":"1"},{"name":"Beta","id":"2"}]}`
 
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte(jsonOutput), nil
 		},
 	}
@@ -80,7 +80,7 @@ func TestDiscoverExecutor_WithParseTemplate(t *testing.T) {
 
 func TestDiscoverExecutor_ExtraSegments(t *testing.T) {
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte("engineering (#6)|PVT_abc|engineering|6\ndesign (#8)|PVT_def|design|8\n"), nil
 		},
 	}
@@ -105,7 +105,7 @@ func TestDiscoverExecutor_ExtraSegments(t *testing.T) {
 
 func TestDiscoverExecutor_CommandFailure(t *testing.T) {
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return nil, fmt.Errorf("command not found")
 		},
 	}
@@ -121,7 +121,7 @@ func TestDiscoverExecutor_CommandFailure(t *testing.T) {
 
 func TestDiscoverExecutor_EmptyOutput(t *testing.T) {
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte(""), nil
 		},
 	}
@@ -138,7 +138,7 @@ func TestDiscoverExecutor_EmptyOutput(t *testing.T) {
 func TestDiscoverExecutor_TemplateExpansion(t *testing.T) {
 	var capturedCmd string
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			capturedCmd = cmd
 			return []byte("result\n"), nil
 		},
@@ -165,7 +165,7 @@ func TestDiscoverExecutor_TemplateExpansion(t *testing.T) {
 
 func TestDiscoverExecutor_ParseTemplateError(t *testing.T) {
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte(`{"key":"val"}`), nil
 		},
 	}
@@ -183,7 +183,7 @@ func TestDiscoverExecutor_ParseTemplateError(t *testing.T) {
 
 func TestDiscoverExecutor_SkipsBlankLines(t *testing.T) {
 	d := &DiscoverExecutor{
-		RunCmd: func(cmd string) ([]byte, error) {
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
 			return []byte("opt1\n\n  \nopt2\n"), nil
 		},
 	}
@@ -236,3 +236,43 @@ func TestExpandTemplate_MissingKey(t *testing.T) {
 		t.Errorf("expected missing key to render as zero value, got %q", result)
 	}
 }
+
+func TestDiscoverExecutor_PolicyRefusesBeforeRunning(t *testing.T) {
+	ran := false
+	d := &DiscoverExecutor{
+		RunCmd: func(cmd string, _ []string) ([]byte, error) {
+			ran = true
+			return nil, nil
+		},
+		Policy: ExecPolicy{Allow: []string{"gh"}},
+	}
+
+	if _, err := d.Run(DiscoverSpec{Command: "curl {{.url}}"}, map[string]any{"url": "x"}); err == nil {
+		t.Fatal("expected the allowlist to refuse curl")
+	}
+	if ran {
+		t.Error("refused command was run")
+	}
+}
+
+func TestDiscoverExecutor_Env(t *testing.T) {
+	var gotEnv []string
+	d := &DiscoverExecutor{
+		RunCmd: func(cmd string, env []string) ([]byte, error) {
+			gotEnv = env
+			return []byte("a\n"), nil
+		},
+		Env: func(flux map[string]any) []string {
+			host, _ := GetNestedValue(flux, "scm.host")
+			return []string{"GH_HOST=" + host}
+		},
+	}
+
+	flux := map[string]any{"scm": map[string]any{"host": "ghe.acme.com"}}
+	if _, err := d.Run(DiscoverSpec{Command: "gh api graphql"}, flux); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gotEnv) != 1 || gotEnv[0] != "GH_HOST=ghe.acme.com" {
+		t.Errorf("env = %v, want [GH_HOST=ghe.acme.com]", gotEnv)
+	}
+}
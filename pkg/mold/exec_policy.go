@@ -0,0 +1,182 @@
+package mold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ErrExecDisabled is returned for every mold-supplied command while
+// execution is disabled (`--no-exec`, or `exec.disabled` in config.yaml).
+var ErrExecDisabled = errors.New("mold command execution is disabled")
+
+// ExecPolicy limits the commands a mold may have ailloy run on its behalf:
+// flux discover commands and hook scripts. The zero value allows anything.
+type ExecPolicy struct {
+	// Disabled refuses every command.
+	Disabled bool
+	// Allow lists the binaries commands may invoke, by base name (gh, jq).
+	// Empty allows any binary.
+	Allow []string
+}
+
+// CheckCommand reports whether the shell command may run: execution must be
+// enabled and, with an allowlist, every binary CommandBinaries finds in it
+// must be on the list.
+func (p ExecPolicy) CheckCommand(command string) error {
+	if p.Disabled {
+		return ErrExecDisabled
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	for _, bin := range CommandBinaries(command) {
+		if !p.allows(bin) {
+			return fmt.Errorf("%q is not in the exec allowlist (%s)", bin, strings.Join(p.Allow, ", "))
+		}
+	}
+	return nil
+}
+
+// CheckScript reports whether a hook script may run. With an allowlist the
+// script's interpreter — from its #! line, else sh — must be on it; what
+// the script itself runs can't be checked, which is what consent is for.
+func (p ExecPolicy) CheckScript(script []byte) error {
+	if p.Disabled {
+		return ErrExecDisabled
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	if bin := ScriptInterpreter(script); !p.allows(bin) {
+		return fmt.Errorf("interpreter %q is not in the exec allowlist (%s)", bin, strings.Join(p.Allow, ", "))
+	}
+	return nil
+}
+
+func (p ExecPolicy) allows(bin string) bool {
+	return slices.Contains(p.Allow, path.Base(bin))
+}
+
+// ScriptInterpreter returns the base name of the program that runs script:
+// the #! interpreter (looking through `/usr/bin/env`), or sh without one.
+func ScriptInterpreter(script []byte) string {
+	if !bytes.HasPrefix(script, []byte("#!")) {
+		return "sh"
+	}
+	line, _, _ := bytes.Cut(script[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return "sh"
+	}
+	bin := path.Base(fields[0])
+	if bin == "env" {
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				return path.Base(f)
+			}
+		}
+	}
+	return bin
+}
+
+// CommandBinaries returns the programs a shell command line invokes: the
+// first word of each pipeline stage, list element (;, &&, ||, &), subshell
+// and command substitution, skipping leading VAR=value assignments. Quoted
+// text is not split, except for substitutions inside double quotes. It is a
+// lexical approximation, not a shell parser, so an allowlist built on it
+// errs towards refusing: anything it can't attribute to a plain word (such
+// as `$cmd` or `eval`) is reported as is.
+func CommandBinaries(command string) []string {
+	type stage struct {
+		text string
+		args bool // continues the arguments of a command after a substitution
+	}
+	var stages []stage
+	var cur strings.Builder
+	var single, double, backtick, args bool
+	depth := 0 // $( substitutions opened inside double quotes
+	cut := func(nextArgs bool) {
+		stages = append(stages, stage{cur.String(), args})
+		cur.Reset()
+		args = nextArgs
+	}
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case single:
+			single = c != '\''
+		case c == '\\' && i+1 < len(command):
+			cur.WriteByte(c)
+			i++
+			c = command[i]
+		case c == '`':
+			backtick = !backtick
+			cut(!backtick)
+			continue
+		case c == '$' && i+1 < len(command) && command[i+1] == '(':
+			if double {
+				depth++
+			}
+			i++
+			cut(false)
+			continue
+		case double && c == ')' && depth > 0:
+			depth--
+			cut(true)
+			continue
+		case double:
+			double = c != '"'
+		case c == '\'':
+			single = true
+		case c == '"':
+			double = true
+		case c == '&' && i > 0 && (command[i-1] == '>' || command[i-1] == '<'):
+			// 2>&1
+		case c == '&' && i+1 < len(command) && command[i+1] == '>':
+			// &>file
+		case c == ')':
+			cut(true)
+			continue
+		case strings.IndexByte("|;&\n({}", c) >= 0:
+			cut(false)
+			continue
+		}
+		cur.WriteByte(c)
+	}
+	cut(false)
+
+	var bins []string
+	for _, st := range stages {
+		if st.args {
+			continue
+		}
+		for _, word := range strings.Fields(st.text) {
+			if isAssignment(word) {
+				continue
+			}
+			word = strings.Trim(word, `"'`)
+			if word != "" && !slices.Contains(bins, word) {
+				bins = append(bins, word)
+			}
+			break
+		}
+	}
+	return bins
+}
+
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
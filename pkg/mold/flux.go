@@ -1,14 +1,23 @@
 package mold
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 
 	"dario.cat/mergo"
 	"github.com/goccy/go-yaml"
+	"golang.org/x/term"
 )
 
 // SetNestedValue sets a value in a nested map using a dotted path.
@@ -60,6 +69,13 @@ func ApplyFluxDefaults(schema []FluxVar, flux map[string]any) map[string]any {
 		if fv.Default == "" {
 			continue
 		}
+		if fv.Type == "multiselect" {
+			// Selections are lists; the default is comma-separated.
+			if _, found := GetNestedAny(result, fv.Name); !found {
+				SetNestedAny(result, fv.Name, toAnySlice(FluxListValues(fv.Default)))
+			}
+			continue
+		}
 		if _, found := GetNestedValue(result, fv.Name); !found {
 			SetNestedValue(result, fv.Name, fv.Default)
 		}
@@ -68,18 +84,101 @@ func ApplyFluxDefaults(schema []FluxVar, flux map[string]any) map[string]any {
 	return result
 }
 
+// MigrateFlux moves values set under a schema variable's renamed_from name
+// to its current name, and returns a warning for each value it moved and
+// for each deprecated variable set to something other than its default.
+// When both names are set the old one wins: values files written before
+// the rename only know the old name, while the new one usually holds the
+// mold's own default. flux is modified in place.
+func MigrateFlux(schema []FluxVar, flux map[string]any) []string {
+	var warnings []string
+	for _, fv := range schema {
+		if fv.RenamedFrom == "" || fv.RenamedFrom == fv.Name {
+			continue
+		}
+		val, ok := GetNestedAny(flux, fv.RenamedFrom)
+		if !ok {
+			continue
+		}
+		SetNestedAny(flux, fv.Name, val)
+		deleteNestedValue(flux, fv.RenamedFrom)
+		warnings = append(warnings, fmt.Sprintf("flux %q was renamed to %q; set %q instead", fv.RenamedFrom, fv.Name, fv.Name))
+	}
+	for _, fv := range schema {
+		if !fv.Deprecated {
+			continue
+		}
+		val, ok := GetNestedAny(flux, fv.Name)
+		if !ok || val == nil || val == "" {
+			continue
+		}
+		if s, isString := val.(string); isString && s == fv.Default {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("flux %q is deprecated", fv.Name))
+	}
+	return warnings
+}
+
+// deleteNestedValue removes the value at a dotted path, and any maps the
+// removal leaves empty.
+func deleteNestedValue(m map[string]any, dottedKey string) {
+	seg, rest, nested := strings.Cut(dottedKey, ".")
+	if !nested {
+		delete(m, seg)
+		return
+	}
+	child, ok := m[seg].(map[string]any)
+	if !ok {
+		return
+	}
+	deleteNestedValue(child, rest)
+	if len(child) == 0 {
+		delete(m, seg)
+	}
+}
+
 // ValidateFlux validates provided flux values against the schema declarations.
 // It checks that all required variables are present and that values match their
-// declared types. All errors are collected and returned at once.
+// declared types. All errors are collected and returned at once. Values set
+// under renamed variables' old names are moved first; call MigrateFlux
+// beforehand to report those moves.
 func ValidateFlux(schema []FluxVar, flux map[string]any) error {
+	return ValidateFluxWithSources(schema, flux, nil)
+}
+
+// ValidateFluxWithSources is ValidateFlux with each error pointing at the
+// values file and line (or --set flag) the offending value came from, when
+// sources knows it.
+func ValidateFluxWithSources(schema []FluxVar, flux map[string]any, sources FluxSources) error {
+	_ = MigrateFlux(schema, flux)
+
 	var errs []string
 
 	for _, fv := range schema {
 		val, exists := GetNestedValue(flux, fv.Name)
 
+		required, err := fv.RequiredFor(flux)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("flux %q: required_if: %v", fv.Name, err))
+			continue
+		}
+
+		if fv.Type == "multiselect" {
+			if msg := validateMultiselect(fv, flux, required); msg != "" {
+				errs = append(errs, msg+sources.locate(fv))
+			}
+			continue
+		}
+
 		// Check required
-		if fv.Required && (!exists || val == "") {
-			errs = append(errs, fmt.Sprintf("flux %q is required but not provided", fv.Name))
+		if required && (!exists || val == "") {
+			msg := fmt.Sprintf("flux %q is required but not provided", fv.Name)
+			if exists {
+				// Set, but empty: point at where it was emptied.
+				msg += sources.locate(fv)
+			}
+			errs = append(errs, msg)
 			continue
 		}
 
@@ -90,7 +189,7 @@ func ValidateFlux(schema []FluxVar, flux map[string]any) error {
 
 		// Type validation
 		if err := validateFluxType(fv.Type, fv.Name, val); err != "" {
-			errs = append(errs, err)
+			errs = append(errs, err+sources.locate(fv))
 		}
 	}
 
@@ -100,6 +199,95 @@ func ValidateFlux(schema []FluxVar, flux map[string]any) error {
 	return nil
 }
 
+// fluxPathPattern matches a bare dotted flux path, with or without the
+// leading dot of a template reference.
+var fluxPathPattern = regexp.MustCompile(`^\.?[A-Za-z_]\w*(\.[A-Za-z_]\w*)*$`)
+
+func isFluxPath(expr string) bool {
+	return fluxPathPattern.MatchString(strings.TrimSpace(expr))
+}
+
+// RequiredFor reports whether fv must be set given the values in flux:
+// always when it is required, otherwise while its required_if condition
+// holds.
+func (fv FluxVar) RequiredFor(flux map[string]any) (bool, error) {
+	if fv.Required {
+		return true, nil
+	}
+	if fv.RequiredIf == "" {
+		return false, nil
+	}
+	return EvalFluxCondition(fv.RequiredIf, flux)
+}
+
+// EvalFluxCondition evaluates a schema condition against flux. A bare
+// dotted path ("ore.status.enabled") holds when the value there is truthy;
+// anything else is a template expression — `eq .scm.provider "github"`,
+// with or without the surrounding {{ }} — that holds when it renders to
+// something truthy. Empty values, "false", "0" and missing keys are false.
+func EvalFluxCondition(expr string, flux map[string]any) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if isFluxPath(expr) {
+		v, ok := GetNestedAny(flux, strings.TrimPrefix(expr, "."))
+		return ok && fluxTruthy(v), nil
+	}
+	tmpl, err := parseFluxCondition(expr)
+	if err != nil {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, BuildTemplateData(flux)); err != nil {
+		return false, err
+	}
+	return fluxTruthy(buf.String()), nil
+}
+
+// parseFluxCondition parses a template-expression condition; temper uses
+// it to report conditions that can't parse before any cast does.
+func parseFluxCondition(expr string) (*template.Template, error) {
+	src := expr
+	if !strings.Contains(src, "{{") {
+		src = "{{ " + src + " }}"
+	}
+	return template.New("required_if").Funcs(baseFuncMap()).Option("missingkey=zero").Parse(src)
+}
+
+// validateFluxCondition reports a required_if that won't parse.
+func validateFluxCondition(expr string) error {
+	if expr == "" || isFluxPath(expr) {
+		return nil
+	}
+	_, err := parseFluxCondition(strings.TrimSpace(expr))
+	return err
+}
+
+// fluxTruthy reports whether a flux value (or a rendered condition) counts
+// as set: strings are parsed as bools where they can be, so "false" from
+// --set is false like a YAML false.
+func fluxTruthy(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		s := strings.TrimSpace(t)
+		if s == "" || s == "<no value>" {
+			return false
+		}
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+		return true
+	case []any:
+		return len(t) > 0
+	case map[string]any:
+		return len(t) > 0
+	default:
+		return fluxTruthy(fmt.Sprint(t))
+	}
+}
+
 // LoadFluxFile loads a nested map from a YAML file in the given filesystem.
 // Returns an empty map (not an error) if the file does not exist.
 func LoadFluxFile(fsys fs.FS, path string) (map[string]any, error) {
@@ -108,9 +296,9 @@ func LoadFluxFile(fsys fs.FS, path string) (map[string]any, error) {
 		return make(map[string]any), nil //nolint:nilerr // missing file is not an error
 	}
 
-	var vals map[string]any
-	if err := yaml.Unmarshal(data, &vals); err != nil {
-		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	vals, err := decodeFluxFile(path, data)
+	if err != nil {
+		return nil, wrapFluxDecodeError("parsing "+path, err)
 	}
 	if vals == nil {
 		return make(map[string]any), nil
@@ -143,27 +331,88 @@ func ApplyFluxFileDefaults(defaults, flux map[string]any) map[string]any {
 	return result
 }
 
+// StdinValuesPath is the -f path that reads a values document from stdin.
+const StdinValuesPath = "-"
+
+// stdinValues holds stdin once read: a cast layers its -f files more than
+// once (explain, dependencies), and a pipe can only be drained once.
+type stdinCache struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+var (
+	stdinValues = &stdinCache{}
+	valuesStdin = os.Stdin
+)
+
+// StdinIsTerminal reports whether f, the stdin -f - reads, is a terminal.
+// Tests replace it.
+var StdinIsTerminal = func(f *os.File) bool { return term.IsTerminal(int(f.Fd())) }
+
+// readValuesFile reads an -f file, a remote one (see IsRemoteValuesPath),
+// or stdin for StdinValuesPath. A
+// terminal on stdin is an error rather than a prompt nobody asked for.
+func readValuesFile(p string, cfg valuesConfig) ([]byte, error) {
+	if IsRemoteValuesPath(p) {
+		return fetchRemoteValues(p, cfg)
+	}
+	if p != StdinValuesPath {
+		return os.ReadFile(p) // #nosec G304 -- CLI tool reads user-specified flux files
+	}
+	if StdinIsTerminal(valuesStdin) {
+		return nil, errors.New("stdin is a terminal; pipe a values document in")
+	}
+	stdinValues.once.Do(func() {
+		stdinValues.data, stdinValues.err = io.ReadAll(valuesStdin)
+	})
+	return stdinValues.data, stdinValues.err
+}
+
 // LayerFluxFiles loads YAML files from OS paths and deep-merges them left-to-right.
-// Each successive file overrides values from the previous ones.
-func LayerFluxFiles(paths []string) (map[string]any, error) {
+// Each successive file overrides values from the previous ones. A path of
+// "-" (StdinValuesPath) reads the document from stdin; HTTPS URLs and
+// git::<repo>[@version]//<file> references are fetched, as opts direct.
+func LayerFluxFiles(paths []string, opts ...ValuesOption) (map[string]any, error) {
+	result, _, err := LayerFluxFilesWithSources(paths, opts...)
+	return result, err
+}
+
+// LayerFluxFilesWithSources is LayerFluxFiles that also records, for every
+// key the files set, the file and line the layered value came from.
+func LayerFluxFilesWithSources(paths []string, opts ...ValuesOption) (map[string]any, FluxSources, error) {
+	var cfg valuesConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	result := make(map[string]any)
+	sources := FluxSources{}
 
 	for _, p := range paths {
-		data, err := os.ReadFile(p) // #nosec G304 -- CLI tool reads user-specified flux files
+		data, err := readValuesFile(p, cfg)
 		if err != nil {
-			return nil, fmt.Errorf("reading flux file %s: %w", p, err)
+			return nil, nil, fmt.Errorf("reading flux file %s: %w", p, err)
 		}
 
-		var vals map[string]any
-		if err := yaml.Unmarshal(data, &vals); err != nil {
-			return nil, fmt.Errorf("parsing flux file %s: %w", p, err)
+		vals, err := decodeFluxFile(p, data)
+		if err != nil {
+			return nil, nil, wrapFluxDecodeError("parsing flux file "+p, err)
 		}
 		if vals != nil {
 			_ = mergo.Merge(&result, vals, mergo.WithOverride)
+			sources.addFile(p, data, vals)
 		}
 	}
 
-	return result, nil
+	return result, sources, nil
+}
+
+// SetOverrideKey returns the flux key a --set, --set-file or --set-json
+// flag sets: its left-hand side, trimmed.
+func SetOverrideKey(kv string) string {
+	key, _, _ := strings.Cut(kv, "=")
+	return strings.TrimSpace(key)
 }
 
 // ApplySetOverrides applies --set key=value flags to a flux map using dotted paths.
@@ -199,6 +448,84 @@ func ApplySetOverrides(flux map[string]any, setFlags []string) error {
 	return nil
 }
 
+// ApplySetFileOverrides applies --set-file key=path flags, setting each key
+// to the named file's content, as is. It reads whatever path it is given,
+// so only the --set-file flag itself should reach it.
+func ApplySetFileOverrides(flux map[string]any, setFileFlags []string) error {
+	for _, flag := range setFileFlags {
+		if err := applySetFile(flux, strings.SplitN(flag, "=", 2)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplySetJSONOverrides applies --set-json key=value flags, parsing each
+// value as JSON so numbers and booleans stay typed.
+func ApplySetJSONOverrides(flux map[string]any, setJSONFlags []string) error {
+	for _, flag := range setJSONFlags {
+		if err := applySetJSON(flux, strings.SplitN(flag, "=", 2)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applySetFile(flux map[string]any, parts []string) error {
+	key := strings.TrimSpace(parts[0])
+	if len(parts) != 2 || key == "" || strings.TrimSpace(parts[1]) == "" {
+		return fmt.Errorf("invalid --set-file format: %q (expected key=path)", strings.Join(parts, "="))
+	}
+	path := strings.TrimSpace(parts[1])
+	data, err := os.ReadFile(path) // #nosec G304 -- CLI tool reads user-specified files
+	if err != nil {
+		return fmt.Errorf("--set-file %s: %w", key, err)
+	}
+	SetNestedAny(flux, key, string(data))
+	return nil
+}
+
+func applySetJSON(flux map[string]any, parts []string) error {
+	key := strings.TrimSpace(parts[0])
+	if len(parts) != 2 || key == "" {
+		return fmt.Errorf("invalid --set-json format: %q (expected key=json)", strings.Join(parts, "="))
+	}
+	dec := json.NewDecoder(strings.NewReader(parts[1]))
+	dec.UseNumber()
+	var parsed any
+	if err := dec.Decode(&parsed); err != nil {
+		return fmt.Errorf("--set-json %s: invalid JSON: %w", key, err)
+	}
+	if dec.More() {
+		return fmt.Errorf("--set-json %s: invalid JSON: unexpected data after the value", key)
+	}
+	SetNestedAny(flux, key, fromJSONNumbers(parsed))
+	return nil
+}
+
+// fromJSONNumbers turns json.Numbers into int64 where they are integers and
+// float64 otherwise, so {"retries": 3} compares equal to 3 in a template
+// (`eq .retries 3` fails on a float64).
+func fromJSONNumbers(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case []any:
+		for i := range t {
+			t[i] = fromJSONNumbers(t[i])
+		}
+	case map[string]any:
+		for k := range t {
+			t[k] = fromJSONNumbers(t[k])
+		}
+	}
+	return v
+}
+
 // GetNestedAny retrieves any value (not just string) from a nested map by dotted path.
 func GetNestedAny(m map[string]any, dottedPath string) (any, bool) {
 	segments := strings.Split(dottedPath, ".")
@@ -288,11 +615,85 @@ func deepCopyMap(m map[string]any) map[string]any {
 	return out
 }
 
+// validateMultiselect checks a multiselect value: a list (a comma string
+// would render as one item), every item one of the static options when
+// the variable declares them, and at least one item when required.
+func validateMultiselect(fv FluxVar, flux map[string]any, required bool) string {
+	v, _ := GetNestedAny(flux, fv.Name)
+	if s, ok := v.(string); ok && s != "" {
+		return fmt.Sprintf("flux %q must be a list, got %q (use a YAML list, or --set '%s=[a,b]')", fv.Name, s, fv.Name)
+	}
+	items := FluxListValues(v)
+	if len(items) == 0 {
+		if required {
+			return fmt.Sprintf("flux %q is required but not provided", fv.Name)
+		}
+		return ""
+	}
+	if len(fv.Options) == 0 {
+		return ""
+	}
+	var unknown []string
+	for _, item := range items {
+		if !slices.ContainsFunc(fv.Options, func(o SelectOption) bool { return o.Value == item }) {
+			unknown = append(unknown, item)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Sprintf("flux %q: %s not among the options", fv.Name, strings.Join(quoteAll(unknown), ", "))
+	}
+	return ""
+}
+
+// FluxListValues returns the items of a list-valued flux value: the
+// elements of a YAML list, or the comma-separated parts of a string (how
+// defaults and older values files write lists). Empty items are dropped.
+func FluxListValues(v any) []string {
+	var items []string
+	switch t := v.(type) {
+	case []any:
+		for _, e := range t {
+			if s := strings.TrimSpace(fmt.Sprint(e)); s != "" && e != nil {
+				items = append(items, s)
+			}
+		}
+	case []string:
+		for _, e := range t {
+			if s := strings.TrimSpace(e); s != "" {
+				items = append(items, s)
+			}
+		}
+	case string:
+		for _, e := range strings.Split(t, ",") {
+			if s := strings.TrimSpace(e); s != "" {
+				items = append(items, s)
+			}
+		}
+	}
+	return items
+}
+
+func toAnySlice(ss []string) []any {
+	out := make([]any, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
+
+func quoteAll(ss []string) []string {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = strconv.Quote(s)
+	}
+	return out
+}
+
 // validateFluxType checks that a value conforms to the declared type.
 // Returns an error message string, or empty string if valid.
 func validateFluxType(typ, name, val string) string {
 	switch typ {
-	case "string":
+	case "string", "secret":
 		// Any value is valid
 		return ""
 	case "bool":
@@ -312,6 +713,9 @@ func validateFluxType(typ, name, val string) string {
 	case "select":
 		// Any value is valid (must match one of the declared options at runtime)
 		return ""
+	case "multiselect":
+		// Checked by validateMultiselect: selections are lists, not strings.
+		return ""
 	default:
 		return fmt.Sprintf("flux %q has unknown type %q", name, typ)
 	}
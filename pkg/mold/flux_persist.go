@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/scope"
 )
 
 // FluxFileSlug derives a deterministic, filesystem-safe filename stem from a
@@ -46,28 +48,49 @@ func FluxFileSlug(ref string) string {
 }
 
 // PersistedFluxPaths returns the existing persisted flux files for the given
-// mold ref, in load order (global, then project). Files that don't exist are
-// omitted. Empty ref returns nil.
+// mold ref, in load order (system, global, workspace ancestors from the
+// root down, then project), each followed by its LocalFluxPath companion.
+// Files that don't exist are omitted. Empty ref returns nil.
 //
 // Layering order matches Helm conventions: more specific (project) wins over
-// less specific (global). Both sit between the mold's built-in defaults and
-// any user-supplied -f files.
+// less specific (a monorepo root's .ailloy/flux, then global), which wins
+// over organization-provisioned system defaults. All sit between the mold's
+// built-in defaults and any user-supplied -f files.
 func PersistedFluxPaths(ref string) []string {
 	if strings.TrimSpace(ref) == "" {
 		return nil
 	}
 	slug := FluxFileSlug(ref)
 	var paths []string
+	if root := scope.SystemRoot(); root != "" {
+		paths = appendPersistedFlux(paths, filepath.Join(root, "flux", slug+".yaml"))
+	}
 	if home, err := os.UserHomeDir(); err == nil {
-		p := filepath.Join(home, ".ailloy", "flux", slug+".yaml")
+		paths = appendPersistedFlux(paths, filepath.Join(home, ".ailloy", "flux", slug+".yaml"))
+	}
+	roots := scope.WorkspaceProjectRoots()
+	for i := len(roots) - 1; i >= 0; i-- {
+		paths = appendPersistedFlux(paths, filepath.Join(roots[i], "flux", slug+".yaml"))
+	}
+	return appendPersistedFlux(paths, filepath.Join(".ailloy", "flux", slug+".yaml"))
+}
+
+// LocalFluxPath names the git-ignored companion of the flux file at path
+// that holds its secret values: flux.yaml becomes flux.local.yaml.
+func LocalFluxPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".local" + ext
+}
+
+// appendPersistedFlux appends path and then its LocalFluxPath companion,
+// each only if it exists, so secret values layer over the file they
+// were split from.
+func appendPersistedFlux(paths []string, path string) []string {
+	for _, p := range []string{path, LocalFluxPath(path)} {
 		if persistedFluxFileExists(p) {
 			paths = append(paths, p)
 		}
 	}
-	p := filepath.Join(".ailloy", "flux", slug+".yaml")
-	if persistedFluxFileExists(p) {
-		paths = append(paths, p)
-	}
 	return paths
 }
 
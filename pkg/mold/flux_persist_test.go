@@ -38,8 +38,10 @@ func TestPersistedFluxPaths_OrderingAndExistence(t *testing.T) {
 	// accidentally resolve onto the global file.
 	homeDir := t.TempDir()
 	projectDir := t.TempDir()
+	systemDir := t.TempDir()
 	t.Chdir(projectDir)
 	t.Setenv("HOME", homeDir)
+	t.Setenv("AILLOY_SYSTEM_ROOT", systemDir)
 
 	ref := "github.com/x/y"
 	slug := FluxFileSlug(ref)
@@ -74,4 +76,33 @@ func TestPersistedFluxPaths_OrderingAndExistence(t *testing.T) {
 	if len(got) != 2 || got[0] != globalPath || got[1] != projectPath {
 		t.Fatalf("expected [global project]; got %v", got)
 	}
+
+	// Organization-provisioned system defaults load first (lowest precedence).
+	systemPath := filepath.Join(systemDir, "flux", slug+".yaml")
+	if err := os.MkdirAll(filepath.Dir(systemPath), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(systemPath, []byte("k: s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got = PersistedFluxPaths(ref)
+	if len(got) != 3 || got[0] != systemPath || got[1] != globalPath || got[2] != projectPath {
+		t.Fatalf("expected [system global project]; got %v", got)
+	}
+
+	// A file's secret companion layers right after it.
+	globalLocal := LocalFluxPath(globalPath)
+	if err := os.WriteFile(globalLocal, []byte("token: g\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got = PersistedFluxPaths(ref)
+	if len(got) != 4 || got[1] != globalPath || got[2] != globalLocal || got[3] != projectPath {
+		t.Fatalf("expected [system global global.local project]; got %v", got)
+	}
+}
+
+func TestLocalFluxPath(t *testing.T) {
+	if got := LocalFluxPath(filepath.Join(".ailloy", "flux", "agents.yaml")); got != filepath.Join(".ailloy", "flux", "agents.local.yaml") {
+		t.Errorf("LocalFluxPath = %q", got)
+	}
 }
@@ -0,0 +1,154 @@
+package mold
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitValuesPrefix marks an -f path as a file inside a foundry-style git
+// reference: git::<host>/<owner>/<repo>[@version]//<path>.
+const gitValuesPrefix = "git::"
+
+// maxRemoteValuesSize caps the size of a remote values file; a larger one
+// is an error rather than a truncated read.
+const maxRemoteValuesSize = 10 << 20
+
+// maxValuesRedirects caps the redirects followed fetching a values URL.
+const maxValuesRedirects = 10
+
+// ResolveValuesFunc is set by callers that can resolve a foundry reference
+// to the repository's fs.FS, without creating an import cycle into
+// pkg/mold. It backs git:: values files unless WithValuesResolver names
+// another resolver.
+var ResolveValuesFunc func(ref string) (fs.FS, error)
+
+// ValuesOption configures how LayerFluxFiles reads remote values files.
+type ValuesOption func(*valuesConfig)
+
+type valuesConfig struct {
+	offline bool
+	resolve func(ref string) (fs.FS, error)
+}
+
+// WithValuesResolver resolves git:: values files with resolve instead of
+// ResolveValuesFunc, so they can follow the same lock and cache policy as
+// the mold being cast.
+func WithValuesResolver(resolve func(ref string) (fs.FS, error)) ValuesOption {
+	return func(c *valuesConfig) {
+		c.resolve = resolve
+	}
+}
+
+// WithOfflineValues keeps remote values files off the network: an HTTPS
+// file is an error, and a git:: file is read only through the resolver
+// WithValuesResolver supplies, which is expected to serve it from the
+// cache. Without one, git:: files are an error too.
+func WithOfflineValues() ValuesOption {
+	return func(c *valuesConfig) {
+		c.offline = true
+	}
+}
+
+var (
+	valuesHTTPClient = &http.Client{Timeout: 30 * time.Second, CheckRedirect: checkValuesRedirect}
+
+	// remoteValues caches fetched remote values files for the process, so
+	// commands that layer the same files more than once fetch them once.
+	remoteValuesMu sync.Mutex
+	remoteValues   = map[string][]byte{}
+)
+
+// IsRemoteValuesPath reports whether an -f path names an HTTPS URL or a
+// git:: reference rather than a local file.
+func IsRemoteValuesPath(p string) bool {
+	return strings.HasPrefix(p, "https://") || strings.HasPrefix(p, gitValuesPrefix)
+}
+
+// fetchRemoteValues returns the contents of a remote values file.
+func fetchRemoteValues(p string, cfg valuesConfig) ([]byte, error) {
+	if cfg.offline && !strings.HasPrefix(p, gitValuesPrefix) {
+		return nil, errors.New("remote values URLs can't be fetched offline; download the file and pass its path with -f")
+	}
+	remoteValuesMu.Lock()
+	defer remoteValuesMu.Unlock()
+	if data, ok := remoteValues[p]; ok {
+		return data, nil
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if strings.HasPrefix(p, gitValuesPrefix) {
+		data, err = fetchGitValues(strings.TrimPrefix(p, gitValuesPrefix), cfg)
+	} else {
+		data, err = fetchHTTPValues(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	remoteValues[p] = data
+	return data, nil
+}
+
+func fetchHTTPValues(url string) ([]byte, error) {
+	resp, err := valuesHTTPClient.Get(url) // #nosec G107 -- CLI tool fetches user-specified values URLs
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteValuesSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	if len(data) > maxRemoteValuesSize {
+		return nil, fmt.Errorf("GET %s: values file is larger than %d MiB", url, maxRemoteValuesSize>>20)
+	}
+	return data, nil
+}
+
+// checkValuesRedirect follows a values URL's redirects only while they
+// stay on HTTPS, so a redirect can't downgrade the fetch to plain HTTP.
+func checkValuesRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing redirect to non-HTTPS %s", req.URL.Redacted())
+	}
+	if len(via) >= maxValuesRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxValuesRedirects)
+	}
+	return nil
+}
+
+// fetchGitValues reads the file after the last "//" of spec from the
+// repository the part before it references.
+func fetchGitValues(spec string, cfg valuesConfig) ([]byte, error) {
+	start := 0
+	if i := strings.Index(spec, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	i := strings.LastIndex(spec[start:], "//")
+	if i < 0 || strings.TrimSpace(spec[start+i+2:]) == "" {
+		return nil, errors.New("git values files need a path: git::<repo>[@version]//<file>")
+	}
+	ref, file := spec[:start+i], spec[start+i+2:]
+	resolve := cfg.resolve
+	if resolve == nil && !cfg.offline {
+		resolve = ResolveValuesFunc
+	}
+	if resolve == nil {
+		return nil, fmt.Errorf("no resolver registered for %s", ref)
+	}
+	fsys, err := resolve(ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	return fs.ReadFile(fsys, strings.Trim(file, "/"))
+}
@@ -0,0 +1,144 @@
+package mold
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLayerFluxFiles_Remote(t *testing.T) {
+	var hits int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path != "/org.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("board: Org\nproject:\n  id: 7\n"))
+	}))
+	defer srv.Close()
+
+	var resolved string
+	oldClient, oldResolve := valuesHTTPClient, ResolveValuesFunc
+	valuesHTTPClient = srv.Client()
+	ResolveValuesFunc = func(ref string) (fs.FS, error) {
+		resolved = ref
+		return fstest.MapFS{"shared/team.yaml": {Data: []byte("team: core\n")}}, nil
+	}
+	t.Cleanup(func() {
+		valuesHTTPClient, ResolveValuesFunc = oldClient, oldResolve
+		remoteValues = map[string][]byte{}
+	})
+
+	local := filepath.Join(t.TempDir(), "local.yaml")
+	if err := os.WriteFile(local, []byte("board: Local\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	url := srv.URL + "/org.yaml"
+	got, err := LayerFluxFiles([]string{url, "git::github.com/acme/values@v1//shared/team.yaml", local})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["board"] != "Local" || got["team"] != "core" {
+		t.Errorf("layered = %v, want the local board over the URL's and team from git", got)
+	}
+	if project, _ := got["project"].(map[string]any); project == nil {
+		t.Errorf("layered = %v, want project from the URL", got)
+	}
+	if resolved != "github.com/acme/values@v1" {
+		t.Errorf("resolved ref = %q", resolved)
+	}
+
+	if _, err := LayerFluxFiles([]string{url}); err != nil || hits != 1 {
+		t.Errorf("second layer: err = %v, hits = %d, want the cached fetch", err, hits)
+	}
+	if _, err := LayerFluxFiles([]string{srv.URL + "/missing.yaml"}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing URL: err = %v, want the status", err)
+	}
+	if _, err := LayerFluxFiles([]string{"git::github.com/acme/values@v1"}); err == nil || !strings.Contains(err.Error(), "need a path") {
+		t.Errorf("git ref without a path: err = %v", err)
+	}
+}
+
+func TestFetchHTTPValues_LimitsAndRedirects(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("board: Plain\n"))
+	}))
+	defer plain.Close()
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big.yaml":
+			_, _ = w.Write([]byte(strings.Repeat("#", maxRemoteValuesSize+1)))
+		case "/max.yaml":
+			_, _ = w.Write([]byte(strings.Repeat("#", maxRemoteValuesSize)))
+		case "/to-plain.yaml":
+			http.Redirect(w, r, plain.URL+"/org.yaml", http.StatusFound)
+		case "/to-https.yaml":
+			http.Redirect(w, r, srv.URL+"/org.yaml", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("board: Org\n"))
+		}
+	}))
+	defer srv.Close()
+
+	oldClient := valuesHTTPClient
+	client := srv.Client()
+	client.CheckRedirect = oldClient.CheckRedirect
+	valuesHTTPClient = client
+	t.Cleanup(func() { valuesHTTPClient = oldClient })
+
+	if _, err := fetchHTTPValues(srv.URL + "/big.yaml"); err == nil || !strings.Contains(err.Error(), "larger than 10 MiB") {
+		t.Errorf("oversized file: err = %v, want a size error", err)
+	}
+	if data, err := fetchHTTPValues(srv.URL + "/max.yaml"); err != nil || len(data) != maxRemoteValuesSize {
+		t.Errorf("file at the limit: %d bytes, err = %v", len(data), err)
+	}
+	if _, err := fetchHTTPValues(srv.URL + "/to-plain.yaml"); err == nil || !strings.Contains(err.Error(), "non-HTTPS") {
+		t.Errorf("redirect to http: err = %v, want it refused", err)
+	}
+	if data, err := fetchHTTPValues(srv.URL + "/to-https.yaml"); err != nil || string(data) != "board: Org\n" {
+		t.Errorf("redirect within https: %q, err = %v", data, err)
+	}
+}
+
+func TestLayerFluxFiles_RemoteOffline(t *testing.T) {
+	var hits int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		_, _ = w.Write([]byte("board: Org\n"))
+	}))
+	defer srv.Close()
+
+	oldClient, oldResolve := valuesHTTPClient, ResolveValuesFunc
+	valuesHTTPClient = srv.Client()
+	ResolveValuesFunc = func(string) (fs.FS, error) {
+		t.Error("offline layering used the online ResolveValuesFunc")
+		return nil, fs.ErrNotExist
+	}
+	t.Cleanup(func() {
+		valuesHTTPClient, ResolveValuesFunc = oldClient, oldResolve
+		remoteValues = map[string][]byte{}
+	})
+
+	if _, err := LayerFluxFiles([]string{srv.URL + "/org.yaml"}, WithOfflineValues()); err == nil || !strings.Contains(err.Error(), "offline") || hits != 0 {
+		t.Errorf("HTTPS values offline: err = %v, hits = %d, want an offline error and no fetch", err, hits)
+	}
+
+	gitRef := "git::github.com/acme/values@v1//team.yaml"
+	if _, err := LayerFluxFiles([]string{gitRef}, WithOfflineValues()); err == nil || !strings.Contains(err.Error(), "no resolver") {
+		t.Errorf("git values offline without a resolver: err = %v", err)
+	}
+	cached := WithValuesResolver(func(string) (fs.FS, error) {
+		return fstest.MapFS{"team.yaml": {Data: []byte("team: core\n")}}, nil
+	})
+	got, err := LayerFluxFiles([]string{gitRef}, WithOfflineValues(), cached)
+	if err != nil || got["team"] != "core" {
+		t.Errorf("git values offline from the cache = %v, err = %v", got, err)
+	}
+}
@@ -0,0 +1,137 @@
+package mold
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iterationDateLayout is the layout of an iteration's start_date, as
+// GitHub reports it.
+const iterationDateLayout = "2006-01-02"
+
+// ResolveFlux fills the variables that declare a resolve block and have no
+// value in flux, as of now, and returns a warning for each it could not
+// fill. Values already set are kept, so a values file or --set pins them.
+// flux is modified in place.
+func ResolveFlux(schema []FluxVar, flux map[string]any, now time.Time) []string {
+	var warnings []string
+	for _, fv := range schema {
+		if fv.Resolve == nil || fv.Resolve.CurrentIteration == "" {
+			continue
+		}
+		if val, ok := GetNestedAny(flux, fv.Name); ok && val != nil && val != "" {
+			continue
+		}
+		from := strings.TrimPrefix(strings.TrimSpace(fv.Resolve.CurrentIteration), ".")
+		raw, _ := GetNestedAny(flux, from)
+		options, _ := raw.(map[string]any)
+		if len(options) == 0 {
+			// The ore hasn't been set up; nothing to resolve from.
+			continue
+		}
+		opt, err := CurrentIteration(options, now)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("flux %q: %s: %v", fv.Name, from, err))
+			continue
+		}
+		if opt == nil {
+			warnings = append(warnings, fmt.Sprintf("flux %q: no iteration in %s is running on %s", fv.Name, from, now.Format(iterationDateLayout)))
+			continue
+		}
+		field := fv.Resolve.Field
+		if field == "" {
+			field = "id"
+		}
+		if v, ok := opt[field].(string); ok && v != "" {
+			SetNestedValue(flux, fv.Name, v)
+		}
+	}
+	return warnings
+}
+
+// CurrentIteration returns the entry of an iteration options map whose
+// start_date and duration (in days) span now's date, or nil when none
+// does. Entries without a start_date are skipped; when iterations overlap
+// the latest start wins.
+func CurrentIteration(options map[string]any, now time.Time) (map[string]any, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var (
+		current      map[string]any
+		currentStart time.Time
+	)
+	for _, k := range keys {
+		opt, ok := options[k].(map[string]any)
+		if !ok {
+			continue
+		}
+		date, _ := opt["start_date"].(string)
+		if date == "" {
+			continue
+		}
+		start, err := time.Parse(iterationDateLayout, date)
+		if err != nil {
+			return nil, fmt.Errorf("%s.start_date %q is not a date (YYYY-MM-DD)", k, date)
+		}
+		days, err := iterationDays(opt["duration"])
+		if err != nil {
+			return nil, fmt.Errorf("%s.duration: %w", k, err)
+		}
+		if today.Before(start) || !today.Before(start.AddDate(0, 0, days)) {
+			continue
+		}
+		if current == nil || start.After(currentStart) {
+			current, currentStart = opt, start
+		}
+	}
+	return current, nil
+}
+
+// iterationDays reads an iteration's duration, a whole number of days.
+func iterationDays(v any) (int, error) {
+	switch n := v.(type) {
+	case int, int64, uint64:
+		if d := toInt64(n); d > 0 {
+			return int(min(d, math.MaxInt32)), nil
+		}
+	case float64:
+		if n > 0 && n == float64(int(n)) {
+			return int(n), nil
+		}
+	case string:
+		if d, err := strconv.Atoi(n); err == nil && d > 0 {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("want a positive number of days, got %v", v)
+}
+
+// validateResolveSpec checks a variable's resolve block.
+func validateResolveSpec(f FluxVar) error {
+	r := f.Resolve
+	if r == nil {
+		return nil
+	}
+	if r.CurrentIteration == "" {
+		return fmt.Errorf("resolve.current_iteration is required")
+	}
+	if !isFluxPath(r.CurrentIteration) {
+		return fmt.Errorf("resolve.current_iteration %q is not a flux path", r.CurrentIteration)
+	}
+	if r.Field != "" && r.Field != "id" && r.Field != "label" {
+		return fmt.Errorf("resolve.field must be \"id\" or \"label\"")
+	}
+	if f.Type != "string" {
+		return fmt.Errorf("resolve needs type string, got %q", f.Type)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package mold
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveFlux_CurrentIteration(t *testing.T) {
+	schema := []FluxVar{
+		{Name: "ore.iteration.current", Type: "string", Resolve: &ResolveSpec{CurrentIteration: "ore.iteration.options"}},
+		{Name: "ore.iteration.current_title", Type: "string", Resolve: &ResolveSpec{CurrentIteration: ".ore.iteration.options", Field: "label"}},
+		{Name: "ore.iteration.pinned", Type: "string", Resolve: &ResolveSpec{CurrentIteration: "ore.iteration.options"}},
+	}
+	newFlux := func() map[string]any {
+		return map[string]any{"ore": map[string]any{"iteration": map[string]any{
+			"pinned": "PVTI_pinned",
+			"options": map[string]any{
+				"sprint_11": map[string]any{"id": "PVTI_11", "label": "Sprint 11", "start_date": "2026-09-28", "duration": uint64(14)},
+				"sprint_12": map[string]any{"id": "PVTI_12", "label": "Sprint 12", "start_date": "2026-10-12", "duration": uint64(14)},
+				"backlog":   map[string]any{"id": "", "label": "Backlog"},
+			},
+		}}}
+	}
+
+	flux := newFlux()
+	if w := ResolveFlux(schema, flux, time.Date(2026, 10, 25, 23, 0, 0, 0, time.UTC)); len(w) != 0 {
+		t.Fatalf("warnings = %v", w)
+	}
+	for key, want := range map[string]string{
+		"ore.iteration.current":       "PVTI_12",
+		"ore.iteration.current_title": "Sprint 12",
+		"ore.iteration.pinned":        "PVTI_pinned",
+	} {
+		if got, _ := GetNestedValue(flux, key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	// The last day of sprint 12 is 2026-10-25; the 26th is in no iteration.
+	flux = newFlux()
+	w := ResolveFlux(schema, flux, time.Date(2026, 10, 26, 9, 0, 0, 0, time.UTC))
+	if len(w) != 2 || !strings.Contains(w[0], "no iteration in ore.iteration.options is running on 2026-10-26") {
+		t.Errorf("warnings = %v, want one per unpinned variable", w)
+	}
+	if _, ok := GetNestedValue(flux, "ore.iteration.current"); ok {
+		t.Error("current set with no running iteration")
+	}
+
+	// An ore that was never set up has nothing to resolve from.
+	if w := ResolveFlux(schema, map[string]any{}, time.Now()); len(w) != 0 {
+		t.Errorf("warnings without options = %v", w)
+	}
+}
+
+func TestCurrentIteration_BadDates(t *testing.T) {
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	if _, err := CurrentIteration(map[string]any{"s": map[string]any{"start_date": "10/12/2026", "duration": 14}}, now); err == nil || !strings.Contains(err.Error(), "s.start_date") {
+		t.Errorf("bad start_date: err = %v", err)
+	}
+	if _, err := CurrentIteration(map[string]any{"s": map[string]any{"start_date": "2026-10-12"}}, now); err == nil || !strings.Contains(err.Error(), "s.duration") {
+		t.Errorf("missing duration: err = %v", err)
+	}
+}
+
+func TestValidateMold_Resolve(t *testing.T) {
+	for _, tt := range []struct {
+		fv   FluxVar
+		want string
+	}{
+		{FluxVar{Name: "sprint", Type: "string", Resolve: &ResolveSpec{CurrentIteration: "iteration.options"}}, ""},
+		{FluxVar{Name: "sprint", Type: "string", Resolve: &ResolveSpec{}}, "current_iteration is required"},
+		{FluxVar{Name: "sprint", Type: "string", Resolve: &ResolveSpec{CurrentIteration: "{{.x}}"}}, "not a flux path"},
+		{FluxVar{Name: "sprint", Type: "string", Resolve: &ResolveSpec{CurrentIteration: "iteration.options", Field: "title"}}, "resolve.field"},
+		{FluxVar{Name: "sprint", Type: "int", Resolve: &ResolveSpec{CurrentIteration: "iteration.options"}}, "type string"},
+	} {
+		err := ValidateMold(&Mold{APIVersion: "v1", Kind: "mold", Name: "test", Version: "1.0.0", Flux: []FluxVar{tt.fv}})
+		if tt.want == "" && err != nil {
+			t.Errorf("%+v: unexpected error %v", tt.fv.Resolve, err)
+		} else if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%+v: err = %v, want %q", tt.fv.Resolve, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package mold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SecretFluxFile is the optional sops-encrypted companion of a mold's
+// flux.yaml. Cast layers it over flux.yaml when it can be decrypted.
+const SecretFluxFile = "flux.secret.yaml"
+
+// sopsMetadataKey is the top-level key sops adds to the files it encrypts.
+const sopsMetadataKey = "sops"
+
+// DecryptFlux decrypts the contents of a sops-encrypted YAML flux file.
+// The default runs `sops --decrypt`, which finds keys the way sops always
+// does (SOPS_AGE_KEY_FILE, ~/.config/sops/age/keys.txt, cloud KMS
+// credentials, gpg). Tests replace it.
+var DecryptFlux = decryptWithSops
+
+// SealedFluxError reports a sops-encrypted flux file that could not be
+// decrypted, naming the variables it holds.
+type SealedFluxError struct {
+	File string
+	Keys []string // dotted keys of the encrypted values, sorted
+	Err  error
+}
+
+func (e *SealedFluxError) Error() string {
+	msg := fmt.Sprintf("%s is encrypted and could not be decrypted: %v", e.File, e.Err)
+	if len(e.Keys) > 0 {
+		msg += "; sealed flux: " + strings.Join(e.Keys, ", ")
+	}
+	return msg
+}
+
+func (e *SealedFluxError) Unwrap() error { return e.Err }
+
+// wrapFluxDecodeError prefixes a YAML error from decodeFluxFile with
+// prefix; sealed-file errors already name their file and pass through.
+func wrapFluxDecodeError(prefix string, err error) error {
+	var sealed *SealedFluxError
+	if errors.As(err, &sealed) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", prefix, err)
+}
+
+// IsEncryptedFlux reports whether parsed flux values came from a file
+// encrypted with sops.
+func IsEncryptedFlux(vals map[string]any) bool {
+	meta, ok := vals[sopsMetadataKey].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, hasMAC := meta["mac"]
+	return hasMAC
+}
+
+// SealedFluxKeys returns the dotted keys of the values sops encrypted in
+// vals (ENC[...] strings), sorted. The sops metadata is skipped.
+func SealedFluxKeys(vals map[string]any) []string {
+	var keys []string
+	var walk func(m map[string]any, prefix string)
+	walk = func(m map[string]any, prefix string) {
+		for k, v := range m {
+			switch val := v.(type) {
+			case map[string]any:
+				walk(val, prefix+k+".")
+			case string:
+				if strings.HasPrefix(val, "ENC[") {
+					keys = append(keys, prefix+k)
+				}
+			case []any:
+				for _, item := range val {
+					if s, ok := item.(string); ok && strings.HasPrefix(s, "ENC[") {
+						keys = append(keys, prefix+k)
+						break
+					}
+				}
+			}
+		}
+	}
+	for k, v := range vals {
+		if k == sopsMetadataKey {
+			continue
+		}
+		walk(map[string]any{k: v}, "")
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeFluxFile parses a flux file's contents, decrypting them first when
+// sops encrypted them. A file that can't be decrypted yields a
+// *SealedFluxError for name; YAML errors are returned for the caller to wrap.
+func decodeFluxFile(name string, data []byte) (map[string]any, error) {
+	var vals map[string]any
+	if err := yaml.Unmarshal(data, &vals); err != nil {
+		return nil, err
+	}
+	if !IsEncryptedFlux(vals) {
+		return vals, nil
+	}
+	plain, err := DecryptFlux(data)
+	if err != nil {
+		return nil, &SealedFluxError{File: name, Keys: SealedFluxKeys(vals), Err: err}
+	}
+	vals = nil
+	if err := yaml.Unmarshal(plain, &vals); err != nil {
+		return nil, err
+	}
+	delete(vals, sopsMetadataKey)
+	return vals, nil
+}
+
+// LoadSecretFlux loads the mold's flux.secret.yaml, decrypted. A missing
+// file yields an empty map; one that can't be decrypted a *SealedFluxError.
+func LoadSecretFlux(fsys fs.FS) (map[string]any, error) {
+	return LoadFluxFile(fsys, SecretFluxFile)
+}
+
+func decryptWithSops(data []byte) ([]byte, error) {
+	if _, err := exec.LookPath("sops"); err != nil {
+		return nil, errors.New("sops not found on PATH")
+	}
+	// sops reads the encryption metadata from the file itself; a temp
+	// file keeps this portable where /dev/stdin isn't.
+	tmp, err := os.CreateTemp("", "ailloy-flux-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("sops", "--decrypt", "--input-type", "yaml", "--output-type", "yaml", tmp.Name()) // #nosec G204 -- fixed sops arguments
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			msg, _, _ = strings.Cut(msg, "\n")
+			return nil, fmt.Errorf("sops: %s", msg)
+		}
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	return out, nil
+}
+
+// SplitSecretFlux separates the values of schema's secret variables from
+// flux. It returns a copy of flux without them and a map holding only
+// them; flux itself is left untouched.
+func SplitSecretFlux(schema []FluxVar, flux map[string]any) (public, secret map[string]any) {
+	public, secret = deepCopyMap(flux), map[string]any{}
+	for _, fv := range schema {
+		if fv.Type != "secret" {
+			continue
+		}
+		if v, ok := GetNestedAny(public, fv.Name); ok {
+			SetNestedAny(secret, fv.Name, v)
+			deleteNestedValue(public, fv.Name)
+		}
+	}
+	return public, secret
+}
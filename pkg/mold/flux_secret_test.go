@@ -0,0 +1,126 @@
+package mold
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const sealedFlux = `linear:
+  api_key: ENC[AES256_GCM,data:Zm9v,iv:aXY=,tag:dGFn,type:str]
+  team: Platform
+project_ids:
+  - ENC[AES256_GCM,data:YmFy,iv:aXY=,tag:dGFn,type:str]
+sops:
+  age:
+    - recipient: age1example
+  mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+  version: 3.9.0
+`
+
+func stubDecryptFlux(t *testing.T, fn func([]byte) ([]byte, error)) {
+	t.Helper()
+	orig := DecryptFlux
+	DecryptFlux = fn
+	t.Cleanup(func() { DecryptFlux = orig })
+}
+
+func TestLayerFluxFiles_DecryptsSopsFiles(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "values.yaml")
+	secret := filepath.Join(dir, "flux.secret.yaml")
+	if err := os.WriteFile(plain, []byte("linear:\n  team: Infra\n  api_key: placeholder\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secret, []byte(sealedFlux), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stubDecryptFlux(t, func([]byte) ([]byte, error) {
+		return []byte("linear:\n  api_key: lin_123\n  team: Platform\nproject_ids: [PVT_1]\nsops:\n  mac: x\n"), nil
+	})
+	got, err := LayerFluxFiles([]string{plain, secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := GetNestedAny(got, "linear.api_key"); v != "lin_123" {
+		t.Errorf("linear.api_key = %v, want the decrypted value", v)
+	}
+	if _, ok := got["sops"]; ok {
+		t.Error("sops metadata leaked into flux")
+	}
+
+	stubDecryptFlux(t, func([]byte) ([]byte, error) { return nil, errors.New("no identity matched any of the recipients") })
+	_, err = LayerFluxFiles([]string{plain, secret})
+	var sealed *SealedFluxError
+	if !errors.As(err, &sealed) {
+		t.Fatalf("err = %v, want *SealedFluxError", err)
+	}
+	if got := strings.Join(sealed.Keys, ","); got != "linear.api_key,project_ids" {
+		t.Errorf("sealed keys = %q", got)
+	}
+	if !strings.Contains(err.Error(), "no identity matched") || !strings.Contains(err.Error(), secret) {
+		t.Errorf("error = %q", err)
+	}
+}
+
+func TestLoadFluxFile_PlainFileNotDecrypted(t *testing.T) {
+	stubDecryptFlux(t, func([]byte) ([]byte, error) {
+		t.Error("DecryptFlux called for a plain file")
+		return nil, nil
+	})
+	fsys := fstest.MapFS{"flux.yaml": {Data: []byte("sops: {enabled: true}\n")}}
+	vals, err := LoadFluxFile(fsys, "flux.yaml")
+	if err != nil || vals["sops"] == nil {
+		t.Errorf("LoadFluxFile = %v, %v; want the plain sops key kept", vals, err)
+	}
+}
+
+func TestTemperSecretFlux(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"encrypted", sealedFlux, false},
+		{"plain text", "linear:\n  api_key: lin_123\n", true},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &TemperResult{}
+			temperSecretFlux(fstest.MapFS{SecretFluxFile: {Data: []byte(tt.content)}}, result)
+			if got := result.HasErrors(); got != tt.wantErr {
+				t.Errorf("HasErrors = %v, want %v: %v", got, tt.wantErr, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestSplitSecretFlux(t *testing.T) {
+	schema := []FluxVar{
+		{Name: "linear.api_key", Type: "secret"},
+		{Name: "linear.team", Type: "string"},
+		{Name: "token", Type: "secret"},
+		{Name: "unset", Type: "secret"},
+	}
+	flux := map[string]any{
+		"linear": map[string]any{"api_key": "lin_123", "team": "core"},
+		"token":  "t0k",
+	}
+	public, secret := SplitSecretFlux(schema, flux)
+	if !reflect.DeepEqual(public, map[string]any{"linear": map[string]any{"team": "core"}}) {
+		t.Errorf("public = %v", public)
+	}
+	want := map[string]any{"linear": map[string]any{"api_key": "lin_123"}, "token": "t0k"}
+	if !reflect.DeepEqual(secret, want) {
+		t.Errorf("secret = %v, want %v", secret, want)
+	}
+	if _, ok := GetNestedAny(flux, "linear.api_key"); !ok {
+		t.Error("SplitSecretFlux modified its input")
+	}
+}
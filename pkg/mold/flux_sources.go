@@ -0,0 +1,86 @@
+package mold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/yamlcheck"
+)
+
+// FluxSource is where a layered flux value came from: a values file and
+// the line its key is on, or a --set flag (Line 0).
+type FluxSource struct {
+	File string
+	Line int
+}
+
+func (s FluxSource) String() string {
+	if s.Line > 0 {
+		return fmt.Sprintf("%s:%d", s.File, s.Line)
+	}
+	return s.File
+}
+
+// FluxSources maps dotted flux keys to the source of their value, so
+// validation errors can point at the line to fix.
+type FluxSources map[string]FluxSource
+
+// addFile credits p with every key of vals, nested ones included. Lines
+// come from the raw document; sops-encrypted files keep their keys in
+// the clear, so their lines hold too.
+func (s FluxSources) addFile(p string, data []byte, vals map[string]any) {
+	lines, _ := yamlcheck.KeyLines(data)
+	var walk func(m map[string]any, prefix string)
+	walk = func(m map[string]any, prefix string) {
+		for k, v := range m {
+			key := prefix + k
+			s[key] = FluxSource{File: p, Line: lines[key]}
+			if nested, ok := v.(map[string]any); ok {
+				walk(nested, key+".")
+			}
+		}
+	}
+	walk(vals, "")
+}
+
+// AddSets credits each --set (and --set-file, --set-json) flag with the key
+// it sets and everything under it.
+func (s FluxSources) AddSets(sets []string) {
+	for _, kv := range sets {
+		key := SetOverrideKey(kv)
+		for k := range s {
+			if strings.HasPrefix(k, key+".") {
+				delete(s, k)
+			}
+		}
+		s[key] = FluxSource{File: "--set " + key}
+	}
+}
+
+// Lookup returns the source of the value at the dotted key: its own, or
+// that of the nearest parent key set as a whole.
+func (s FluxSources) Lookup(key string) (FluxSource, bool) {
+	for {
+		if src, ok := s[key]; ok {
+			return src, true
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return FluxSource{}, false
+		}
+		key = key[:i]
+	}
+}
+
+// locate returns " (source)" for the value of fv, looked up under its old
+// name too when it was renamed, or "" when sources don't know it.
+func (s FluxSources) locate(fv FluxVar) string {
+	src, ok := s.Lookup(fv.Name)
+	if !ok && fv.RenamedFrom != "" {
+		src, ok = s.Lookup(fv.RenamedFrom)
+	}
+	if !ok {
+		return ""
+	}
+	return " (" + src.String() + ")"
+}
@@ -0,0 +1,52 @@
+package mold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateFluxWithSources(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	team := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(base, []byte("org: acme\nlimits:\n  max: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(team, []byte("# team overrides\norg: \"\"\nlimits:\n  max: lots\nold_board: Ops\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	schema := []FluxVar{
+		{Name: "org", Type: "string", Required: true},
+		{Name: "limits.max", Type: "int"},
+		{Name: "board", Type: "int", RenamedFrom: "old_board"},
+		{Name: "count", Type: "int"},
+		{Name: "project", Type: "string", Required: true},
+	}
+
+	flux, sources, err := LayerFluxFilesWithSources([]string{base, team})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources.AddSets([]string{"count=x"})
+	_ = ApplySetOverrides(flux, []string{"count=x"})
+
+	err = ValidateFluxWithSources(schema, flux, sources)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		`flux "org" is required but not provided (` + team + `:2)`,
+		`(` + team + `:4)`,
+		`(` + team + `:5)`,
+		`(--set count)`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), `"project" is required but not provided (`) {
+		t.Errorf("a value no layer set has no source to point at:\n%v", err)
+	}
+}
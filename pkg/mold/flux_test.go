@@ -3,6 +3,7 @@ package mold
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"testing/fstest"
@@ -655,6 +656,38 @@ func TestLayerFluxFiles_MissingFileError(t *testing.T) {
 	}
 }
 
+func TestLayerFluxFiles_Stdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdin, origCache := valuesStdin, stdinValues
+	valuesStdin, stdinValues = r, &stdinCache{}
+	t.Cleanup(func() { valuesStdin, stdinValues = origStdin, origCache })
+	if _, err := w.WriteString(`{"org": "piped", "board": "Ops"}`); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	f := filepath.Join(t.TempDir(), "after.yaml")
+	if err := os.WriteFile(f, []byte("board: Product\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := LayerFluxFiles([]string{StdinValuesPath, f})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result["org"] != "piped" || result["board"] != "Product" {
+		t.Errorf("result = %v, want org from stdin and board from the later file", result)
+	}
+
+	// Layering again (explain, dependencies) sees the same document.
+	again, err := LayerFluxFiles([]string{StdinValuesPath})
+	if err != nil || again["org"] != "piped" {
+		t.Errorf("second read = %v, %v; want stdin's document again", again, err)
+	}
+}
+
 // --- ApplySetOverrides tests ---
 
 func TestApplySetOverrides_Simple(t *testing.T) {
@@ -777,6 +810,79 @@ func TestApplySetOverrides_SingleElementArray(t *testing.T) {
 	}
 }
 
+func TestApplySetFileOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.md")
+	content := "Review every PR for:\n- tests\n- docs\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	flux := map[string]any{}
+	if err := ApplySetFileOverrides(flux, []string{"agent.prompt=" + path}); err != nil {
+		t.Fatal(err)
+	}
+	// Kept verbatim: not YAML-parsed into a list, not trimmed.
+	if got, _ := GetNestedAny(flux, "agent.prompt"); got != content {
+		t.Errorf("agent.prompt = %#v, want the file content", got)
+	}
+
+	err := ApplySetFileOverrides(flux, []string{"x=" + filepath.Join(t.TempDir(), "missing")})
+	if err == nil || !strings.Contains(err.Error(), "--set-file x") {
+		t.Errorf("missing file: err = %v", err)
+	}
+}
+
+func TestApplySetOverrides_FileSuffixIsLiteralKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(path, []byte("s3cret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	flux := map[string]any{}
+	if err := ApplySetOverrides(flux, []string{"x:file=" + path, `y:json={"a":1}`}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"x:file": path, "y:json": map[string]any{"a": uint64(1)}}
+	if !reflect.DeepEqual(flux, want) {
+		t.Errorf("flux = %#v\nwant %#v", flux, want)
+	}
+}
+
+func TestApplySetJSONOverrides(t *testing.T) {
+	flux := map[string]any{"name": "plain"}
+	err := ApplySetJSONOverrides(flux, []string{
+		`limits={"retries": 3, "ratio": 0.5, "on": true, "tags": ["a", "b"]}`,
+		`name="quoted=value"`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":   "quoted=value",
+		"limits": map[string]any{"retries": int64(3), "ratio": 0.5, "on": true, "tags": []any{"a", "b"}},
+	}
+	if !reflect.DeepEqual(flux, want) {
+		t.Errorf("flux = %#v\nwant %#v", flux, want)
+	}
+
+	for _, bad := range []string{"a={", "a=1 2", "a"} {
+		if err := ApplySetJSONOverrides(map[string]any{}, []string{bad}); err == nil || !strings.Contains(err.Error(), "--set-json") {
+			t.Errorf("ApplySetJSONOverrides(%q) err = %v, want a --set-json error", bad, err)
+		}
+	}
+}
+
+func TestSetOverrideKey(t *testing.T) {
+	for kv, want := range map[string]string{
+		" a.b =c":   "a.b",
+		"a.b=x.md":  "a.b",
+		"novalue":   "novalue",
+		"x:file=/p": "x:file",
+	} {
+		if got := SetOverrideKey(kv); got != want {
+			t.Errorf("SetOverrideKey(%q) = %q, want %q", kv, got, want)
+		}
+	}
+}
+
 func TestApplySetOverrides_PlainStringUnchanged(t *testing.T) {
 	flux := map[string]any{}
 	err := ApplySetOverrides(flux, []string{"board=Product"})
@@ -982,3 +1088,141 @@ func TestMergeSet_TopLevelScalar(t *testing.T) {
 		t.Error("expected base unchanged")
 	}
 }
+
+func TestMigrateFlux_RenamedAndDeprecated(t *testing.T) {
+	schema := []FluxVar{
+		{Name: "git.provider", Type: "string", RenamedFrom: "scm.provider"},
+		{Name: "team", Type: "string", RenamedFrom: "org", Default: "platform"},
+		{Name: "legacy_mode", Type: "bool", Deprecated: true, Default: "false"},
+		{Name: "old_list", Type: "list", Deprecated: true},
+	}
+	flux := map[string]any{
+		"scm":         map[string]any{"provider": "GitLab"},
+		"org":         "acme",
+		"team":        "platform", // schema default already applied
+		"legacy_mode": "false",
+	}
+
+	warnings := MigrateFlux(schema, flux)
+
+	want := []string{
+		`flux "scm.provider" was renamed to "git.provider"; set "git.provider" instead`,
+		`flux "org" was renamed to "team"; set "team" instead`,
+	}
+	if strings.Join(warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(warnings, "\n"), strings.Join(want, "\n"))
+	}
+	if v, _ := GetNestedValue(flux, "git.provider"); v != "GitLab" {
+		t.Errorf("git.provider = %q, want GitLab", v)
+	}
+	if flux["team"] != "acme" {
+		t.Errorf("team = %v, want the old name's value to win over the default", flux["team"])
+	}
+	if _, ok := flux["scm"]; ok {
+		t.Errorf("scm should be removed once empty, got %v", flux["scm"])
+	}
+	if _, ok := flux["org"]; ok {
+		t.Error("org should be removed after the move")
+	}
+
+	flux["old_list"] = []any{"a"}
+	if got := MigrateFlux(schema, flux); len(got) != 1 || got[0] != `flux "old_list" is deprecated` {
+		t.Errorf("second pass warnings = %v, want only the deprecated old_list", got)
+	}
+}
+
+func TestValidateFlux_RenamedSatisfiesRequired(t *testing.T) {
+	schema := []FluxVar{{Name: "team", Type: "string", Required: true, RenamedFrom: "org"}}
+	flux := map[string]any{"org": "acme"}
+	if err := ValidateFlux(schema, flux); err != nil {
+		t.Fatalf("ValidateFlux: %v", err)
+	}
+	if flux["team"] != "acme" {
+		t.Errorf("team = %v, want acme mapped from org", flux["team"])
+	}
+}
+
+func TestEvalFluxCondition(t *testing.T) {
+	flux := map[string]any{
+		"ore":   map[string]any{"status": map[string]any{"enabled": true}, "iteration": map[string]any{"enabled": "false"}},
+		"scm":   map[string]any{"provider": "github"},
+		"teams": []any{},
+	}
+	for _, tc := range []struct {
+		expr string
+		want bool
+	}{
+		{"ore.status.enabled", true},
+		{".ore.status.enabled", true},
+		{"ore.iteration.enabled", false}, // --set leaves "false" as a string
+		{"ore.missing.enabled", false},
+		{"teams", false},
+		{`eq .scm.provider "github"`, true},
+		{`{{ eq .scm.provider "gitlab" }}`, false},
+		{`and .ore.status.enabled .scm.provider`, true},
+		{`.ore.nope`, false},
+	} {
+		got, err := EvalFluxCondition(tc.expr, flux)
+		if err != nil {
+			t.Errorf("EvalFluxCondition(%q): %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("EvalFluxCondition(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+	if _, err := EvalFluxCondition(`eq .a`, flux); err == nil {
+		t.Error("expected an error for a condition that fails to execute")
+	}
+}
+
+func TestValidateFlux_RequiredIf(t *testing.T) {
+	schema := []FluxVar{
+		{Name: "ore.status.enabled", Type: "bool"},
+		{Name: "ore.status.field_id", Type: "string", RequiredIf: "ore.status.enabled"},
+	}
+	if err := ValidateFlux(schema, map[string]any{"ore": map[string]any{"status": map[string]any{"enabled": "false"}}}); err != nil {
+		t.Errorf("disabled feature: unexpected error %v", err)
+	}
+	err := ValidateFlux(schema, map[string]any{"ore": map[string]any{"status": map[string]any{"enabled": true}}})
+	if err == nil || !strings.Contains(err.Error(), `flux "ore.status.field_id" is required`) {
+		t.Errorf("enabled feature: err = %v, want field_id required", err)
+	}
+
+	bad := []FluxVar{{Name: "x", Type: "string", RequiredIf: "eq .a"}}
+	if err := ValidateFlux(bad, map[string]any{}); err == nil || !strings.Contains(err.Error(), "required_if") {
+		t.Errorf("broken condition: err = %v, want a required_if error", err)
+	}
+}
+
+func TestValidateFlux_Multiselect(t *testing.T) {
+	schema := []FluxVar{
+		{Name: "labels", Type: "multiselect", Required: true, Default: "bug,docs", Options: []SelectOption{{Value: "bug"}, {Value: "docs"}}},
+		{Name: "teams", Type: "multiselect", Discover: &DiscoverSpec{Command: "gh api teams"}},
+	}
+
+	flux := ApplyFluxDefaults(schema, map[string]any{})
+	if got := flux["labels"]; !reflect.DeepEqual(got, []any{"bug", "docs"}) {
+		t.Fatalf("default = %#v, want a list", got)
+	}
+	if err := ValidateFlux(schema, flux); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+
+	for _, tc := range []struct {
+		flux map[string]any
+		want string
+	}{
+		{map[string]any{"labels": []any{}}, `"labels" is required`},
+		{map[string]any{"labels": "bug,docs"}, `"labels" must be a list`},
+		{map[string]any{"labels": []any{"bug", "wontfix"}}, `"wontfix" not among the options`},
+	} {
+		err := ValidateFlux(schema, tc.flux)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ValidateFlux(%v) = %v, want %q", tc.flux, err, tc.want)
+		}
+	}
+	if err := ValidateFlux(schema, map[string]any{"labels": []any{"bug"}, "teams": []any{"anything"}}); err != nil {
+		t.Errorf("discovered options aren't checked: %v", err)
+	}
+}
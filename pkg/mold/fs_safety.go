@@ -0,0 +1,135 @@
+package mold
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// MaxBinaryFileSize is the largest binary file cast writes from a mold
+// without mold.yaml listing it under binaries:. Remote molds are untrusted;
+// a stray build artifact or disk image shouldn't land in a project unasked.
+const MaxBinaryFileSize = 1 << 20
+
+// binarySniffLen is how much of a file IsBinary looks at, as git does.
+const binarySniffLen = 8000
+
+// maxSymlinkHops bounds the symlinks CheckMoldPath follows, so a loop
+// fails instead of recursing forever.
+const maxSymlinkHops = 40
+
+// CheckMoldPath returns an error when reading name from fsys could reach
+// outside it: name must be a clean relative path, and every symlink on the
+// way (a symlinked directory as well as the file itself) must point at a
+// relative target that stays inside fsys.
+func CheckMoldPath(fsys fs.FS, name string) error {
+	return checkMoldPath(fsys, name, name, 0)
+}
+
+func checkMoldPath(fsys fs.FS, orig, name string, hops int) error {
+	if !fs.ValidPath(name) || strings.Contains(name, `\`) {
+		return fmt.Errorf("%s: path must be relative to the mold root and stay inside it", orig)
+	}
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		p := strings.Join(parts[:i+1], "/")
+		info, err := fs.Lstat(fsys, p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", orig, err)
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+		if hops >= maxSymlinkHops {
+			return fmt.Errorf("%s: too many levels of symlinks", orig)
+		}
+		target, err := fs.ReadLink(fsys, p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", orig, err)
+		}
+		resolved := path.Join(path.Dir(p), target)
+		if path.IsAbs(target) || strings.Contains(target, `\`) || !fs.ValidPath(resolved) {
+			return fmt.Errorf("%s: symlink %s points to %q, outside the mold", orig, p, target)
+		}
+		return checkMoldPath(fsys, orig, path.Join(append([]string{resolved}, parts[i+1:]...)...), hops+1)
+	}
+	return nil
+}
+
+// NormalizeDest returns dest in the form every destination is compared in:
+// slash-separated and cleaned, so "commands\review.md" and
+// "./commands/review.md" both become "commands/review.md". Output mappings
+// written on Windows therefore filter, record and match the same way as
+// anywhere else.
+func NormalizeDest(dest string) string {
+	if dest == "" {
+		return ""
+	}
+	return path.Clean(strings.ReplaceAll(dest, `\`, "/"))
+}
+
+// checkDestPath returns an error for an output destination that would be
+// written outside the project: absolute paths and ".." escapes.
+func checkDestPath(src, dest string) error {
+	clean := NormalizeDest(dest)
+	if path.IsAbs(clean) || (len(clean) > 1 && clean[1] == ':') || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("output %s maps to %q, outside the project", src, dest)
+	}
+	return nil
+}
+
+// IsBinary reports whether data looks like the contents of a binary file:
+// a NUL byte in its first 8000 bytes.
+func IsBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// FilterLargeBinaries splits resolved into the files to cast and the binary
+// files over MaxBinaryFileSize that allow does not list. allow holds
+// mold.yaml binaries: patterns, matched against source paths the way
+// .ailloyignore patterns are. Files are read from their SrcFS, or moldFS.
+func FilterLargeBinaries(resolved []ResolvedFile, moldFS fs.FS, allow []string) (kept, skipped []ResolvedFile, err error) {
+	for _, rf := range resolved {
+		fsys := rf.SrcFS
+		if fsys == nil {
+			fsys = moldFS
+		}
+		large, err := isLargeBinary(fsys, rf.SrcPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if large && !shouldIgnore(rf.SrcPath, allow) {
+			skipped = append(skipped, rf)
+			continue
+		}
+		kept = append(kept, rf)
+	}
+	return kept, skipped, nil
+}
+
+func isLargeBinary(fsys fs.FS, name string) (bool, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	if info.Size() <= MaxBinaryFileSize {
+		return false, nil
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	defer func() { _ = f.Close() }()
+	head := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	return IsBinary(head[:n]), nil
+}
@@ -0,0 +1,148 @@
+package mold
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolveFiles_RejectsSymlinkEscapes(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, d := range []string{"commands", "shared"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "shared", "pr.md"), []byte("# PR"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A link that stays inside the mold is fine.
+	if err := os.Symlink("../shared/pr.md", filepath.Join(dir, "commands", "pr.md")); err != nil {
+		t.Fatal(err)
+	}
+	output := map[string]any{"commands": ".claude/commands"}
+	if _, err := ResolveFiles(output, os.DirFS(dir)); err != nil {
+		t.Fatalf("in-mold symlink: %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(dir, "commands", "abs.md")); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ResolveFiles(output, os.DirFS(dir))
+	if err == nil || !strings.Contains(err.Error(), "outside the mold") {
+		t.Fatalf("absolute symlink: err = %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "commands", "abs.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../../"+filepath.Base(outside)+"/secret", filepath.Join(dir, "commands", "up.md")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveFiles(output, os.DirFS(dir)); err == nil || !strings.Contains(err.Error(), "outside the mold") {
+		t.Fatalf("relative escape: err = %v", err)
+	}
+}
+
+func TestCheckMoldPath_SymlinkedDirectory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"blanks":     {Data: []byte("/etc"), Mode: fs.ModeSymlink},
+		"loop":       {Data: []byte("loop"), Mode: fs.ModeSymlink},
+		"docs/a.md":  {Data: []byte("a")},
+		"alias":      {Data: []byte("docs"), Mode: fs.ModeSymlink},
+		"../outside": {Data: []byte("x")},
+	}
+	if err := CheckMoldPath(fsys, "blanks/passwd"); err == nil {
+		t.Error("symlinked directory leaving the mold was accepted")
+	}
+	if err := CheckMoldPath(fsys, "alias/a.md"); err != nil {
+		t.Errorf("symlinked directory inside the mold: %v", err)
+	}
+	if err := CheckMoldPath(fsys, "loop"); err == nil || !strings.Contains(err.Error(), "too many levels") {
+		t.Errorf("symlink loop: err = %v", err)
+	}
+	if err := CheckMoldPath(fsys, "../outside"); err == nil {
+		t.Error(".. path was accepted")
+	}
+}
+
+func TestResolveFiles_RejectsEscapingDest(t *testing.T) {
+	fsys := fstest.MapFS{"commands/a.md": {Data: []byte("a")}}
+	for _, dest := range []string{"/etc", "../elsewhere", "C:/Windows"} {
+		if _, err := ResolveFiles(map[string]any{"commands": dest}, fsys); err == nil || !strings.Contains(err.Error(), "outside the project") {
+			t.Errorf("dest %q: err = %v", dest, err)
+		}
+	}
+}
+
+func TestResolveFiles_NormalizesWindowsDests(t *testing.T) {
+	fsys := fstest.MapFS{
+		"commands/a.md": {Data: []byte("a")},
+		"ci.yml":        {Data: []byte("on: push")},
+	}
+	output := map[string]any{
+		"commands": `.claude\commands`,
+		"ci.yml":   `./.github\workflows\ci.yml`,
+	}
+	resolved, err := ResolveFiles(output, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, rf := range resolved {
+		got[rf.SrcPath] = rf.DestPath
+	}
+	if got["commands/a.md"] != ".claude/commands/a.md" || got["ci.yml"] != ".github/workflows/ci.yml" {
+		t.Fatalf("dests = %v, want slash form", got)
+	}
+	if _, ok := CIForDest(got["ci.yml"]); !ok {
+		t.Error("a workflow mapped with backslashes should still be recognised as CI")
+	}
+	if _, err := ResolveFiles(map[string]any{"commands": `..\elsewhere`}, fsys); err == nil {
+		t.Error("a dest escaping the project with backslashes should be rejected")
+	}
+}
+
+func TestNormalizeDest(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                      "",
+		"AGENTS.md":             "AGENTS.md",
+		"./AGENTS.md":           "AGENTS.md",
+		`.claude\commands\a.md`: ".claude/commands/a.md",
+		`.\.github/workflows\`:  ".github/workflows",
+	} {
+		if got := NormalizeDest(in); got != want {
+			t.Errorf("NormalizeDest(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFilterLargeBinaries(t *testing.T) {
+	big := bytes.Repeat([]byte{0x7f, 0}, MaxBinaryFileSize)
+	text := bytes.Repeat([]byte("a"), MaxBinaryFileSize+1)
+	fsys := fstest.MapFS{
+		"assets/logo.png":   {Data: big},
+		"assets/model.bin":  {Data: big},
+		"assets/small.png":  {Data: []byte{0x89, 'P', 'N', 'G', 0}},
+		"assets/corpus.txt": {Data: text},
+	}
+	resolved, err := ResolveFiles(map[string]any{"assets": "assets"}, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, skipped, err := FilterLargeBinaries(resolved, fsys, []string{"*.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 3 || len(skipped) != 1 || skipped[0].SrcPath != "assets/model.bin" {
+		t.Errorf("kept %v, skipped %v", kept, skipped)
+	}
+}
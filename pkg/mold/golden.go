@@ -0,0 +1,204 @@
+package mold
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoldenTestsDir is the reserved top-level directory holding a mold's
+// golden-file test cases. Each case is a subdirectory with an optional
+// flux.yaml (layered over the mold's defaults like a -f values file) and an
+// expected/ tree mirroring the rendered destination paths.
+const GoldenTestsDir = "tests"
+
+// GoldenCase is one test case under tests/<name>/.
+type GoldenCase struct {
+	Name        string
+	Dir         string // case directory on disk
+	FluxFile    string // tests/<name>/flux.yaml, empty when the case has none
+	ExpectedDir string // tests/<name>/expected
+}
+
+// DiscoverGoldenCases lists the cases under moldDir/tests, sorted by name.
+// A mold without a tests/ directory has no cases.
+func DiscoverGoldenCases(moldDir string) ([]GoldenCase, error) {
+	root := filepath.Join(moldDir, GoldenTestsDir)
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", root, err)
+	}
+
+	var cases []GoldenCase
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		c := GoldenCase{Name: e.Name(), Dir: dir, ExpectedDir: filepath.Join(dir, "expected")}
+		if _, err := os.Stat(filepath.Join(dir, "flux.yaml")); err == nil {
+			c.FluxFile = filepath.Join(dir, "flux.yaml")
+		}
+		cases = append(cases, c)
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// GoldenMismatch describes one destination that differs from its golden.
+type GoldenMismatch struct {
+	Path string
+	Kind string // "missing" (expected, not rendered), "unexpected" (rendered, no golden), or "changed"
+	Diff string // line diff for "changed"
+}
+
+// CompareGolden diffs rendered outputs (keyed by slash-separated destination
+// path) against the expected/ tree. Mismatches are sorted by path.
+func CompareGolden(expectedDir string, outputs map[string]string) ([]GoldenMismatch, error) {
+	expected, err := readGoldenTree(expectedDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []GoldenMismatch
+	for p, want := range expected {
+		got, ok := outputs[p]
+		switch {
+		case !ok:
+			out = append(out, GoldenMismatch{Path: p, Kind: "missing"})
+		case got != want:
+			out = append(out, GoldenMismatch{Path: p, Kind: "changed", Diff: LineDiff(want, got)})
+		}
+	}
+	for p := range outputs {
+		if _, ok := expected[p]; !ok {
+			out = append(out, GoldenMismatch{Path: p, Kind: "unexpected"})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// WriteGolden replaces expectedDir with the given outputs.
+func WriteGolden(expectedDir string, outputs map[string]string) error {
+	if err := os.RemoveAll(expectedDir); err != nil {
+		return fmt.Errorf("clearing %s: %w", expectedDir, err)
+	}
+	for p, content := range outputs {
+		dest := filepath.Join(expectedDir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(dest), 0750); err != nil { // #nosec G301 -- Golden directories need group read access
+			return fmt.Errorf("creating directory for %s: %w", p, err)
+		}
+		//#nosec G306 -- Golden files are committed alongside the mold
+		if err := os.WriteFile(dest, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", dest, err)
+		}
+	}
+	return nil
+}
+
+func readGoldenTree(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	fsys := os.DirFS(dir)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		files[path.Clean(p)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// goldenDiffContext is the number of unchanged lines shown around each change.
+const goldenDiffContext = 2
+
+// LineDiff returns a compact line diff from want to got: removed lines are
+// prefixed "-", added lines "+", and up to two unchanged lines of context
+// surround each change. Distant hunks are separated by "...".
+func LineDiff(want, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+
+	// Longest-common-subsequence table, filled from the end.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var ops []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, line{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, line{'+', b[j]})
+			j++
+		}
+	}
+
+	// Keep changed lines plus their context.
+	keep := make([]bool, len(ops))
+	for k, op := range ops {
+		if op.op == ' ' {
+			continue
+		}
+		for c := max(0, k-goldenDiffContext); c <= min(len(ops)-1, k+goldenDiffContext); c++ {
+			keep[c] = true
+		}
+	}
+
+	var sb strings.Builder
+	gap := false
+	for k, op := range ops {
+		if !keep[k] {
+			gap = true
+			continue
+		}
+		if gap && sb.Len() > 0 {
+			sb.WriteString("...\n")
+		}
+		gap = false
+		sb.WriteByte(op.op)
+		sb.WriteString(" ")
+		sb.WriteString(op.text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
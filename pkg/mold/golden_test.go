@@ -0,0 +1,105 @@
+package mold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		want, got string
+		diff      string
+	}{
+		{name: "equal", want: "a\nb\n", got: "a\nb\n", diff: ""},
+		{name: "changed line", want: "a\nb\nc\n", got: "a\nB\nc\n", diff: "  a\n- b\n+ B\n  c\n  \n"},
+		{name: "appended", want: "a\n", got: "a\nb\n", diff: "  a\n+ b\n  \n"},
+		{
+			name: "distant hunks",
+			want: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			got:  "x\n2\n3\n4\n5\n6\n7\n8\ny\n",
+			diff: "- 1\n+ x\n  2\n  3\n...\n  7\n  8\n- 9\n+ y\n  \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LineDiff(tt.want, tt.got); got != tt.diff {
+				t.Errorf("LineDiff =\n%q\nwant\n%q", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestGolden_DiscoverCompareWrite(t *testing.T) {
+	moldDir := t.TempDir()
+	for _, dir := range []string{"tests/b-case", "tests/a-case", "tests/.hidden"} {
+		if err := os.MkdirAll(filepath.Join(moldDir, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(moldDir, "tests", "b-case", "flux.yaml"), []byte("x: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases, err := DiscoverGoldenCases(moldDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 || cases[0].Name != "a-case" || cases[1].Name != "b-case" {
+		t.Fatalf("cases = %+v", cases)
+	}
+	if cases[0].FluxFile != "" || cases[1].FluxFile == "" {
+		t.Errorf("flux files = %q, %q", cases[0].FluxFile, cases[1].FluxFile)
+	}
+
+	expected := cases[0].ExpectedDir
+	if err := WriteGolden(expected, map[string]string{".claude/a.md": "a\n", "gone.md": "g\n"}); err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err := CompareGolden(expected, map[string]string{".claude/a.md": "A\n", "new.md": "n\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]string{}
+	for _, m := range mismatches {
+		kinds[m.Path] = m.Kind
+	}
+	want := map[string]string{".claude/a.md": "changed", "gone.md": "missing", "new.md": "unexpected"}
+	for p, k := range want {
+		if kinds[p] != k {
+			t.Errorf("%s: kind = %q, want %q (all: %+v)", p, kinds[p], k, mismatches)
+		}
+	}
+
+	// WriteGolden replaces the tree, so stale goldens disappear.
+	if err := WriteGolden(expected, map[string]string{".claude/a.md": "A\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(expected, "gone.md")); !os.IsNotExist(err) {
+		t.Errorf("stale golden should be removed, stat err = %v", err)
+	}
+	if mismatches, _ := CompareGolden(expected, map[string]string{".claude/a.md": "A\n"}); len(mismatches) != 0 {
+		t.Errorf("expected no mismatches after update, got %+v", mismatches)
+	}
+}
+
+func TestResolveFiles_SkipsTestsDir(t *testing.T) {
+	moldDir := t.TempDir()
+	for _, f := range []string{"commands/hello.md", "tests/default/expected/commands/hello.md"} {
+		p := filepath.Join(moldDir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolved, err := ResolveFiles(".claude", os.DirFS(moldDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0].SrcPath != "commands/hello.md" {
+		t.Errorf("resolved = %+v, want only commands/hello.md", resolved)
+	}
+}
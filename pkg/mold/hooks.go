@@ -0,0 +1,141 @@
+package mold
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Hook stages a mold can attach scripts to.
+const (
+	HookPreCast    = "pre-cast"
+	HookPostCast   = "post-cast"
+	HookPreUpgrade = "pre-upgrade"
+)
+
+// HookEnvPrefix prefixes the environment variables a hook script receives
+// for each flux value: flux key `project.name` becomes
+// AILLOY_FLUX_PROJECT_NAME.
+const HookEnvPrefix = "AILLOY_FLUX_"
+
+// Hooks lists the scripts, bundled with the mold and named relative to its
+// root, that run around a cast. Pre-cast scripts run before any blank is
+// written, post-cast scripts after every blank is, and pre-upgrade scripts
+// before a recast re-renders an installed mold.
+type Hooks struct {
+	PreCast    []string `yaml:"pre-cast,omitempty"`
+	PostCast   []string `yaml:"post-cast,omitempty"`
+	PreUpgrade []string `yaml:"pre-upgrade,omitempty"`
+}
+
+// Scripts returns the scripts declared for stage, in order.
+func (h Hooks) Scripts(stage string) []string {
+	switch stage {
+	case HookPreCast:
+		return h.PreCast
+	case HookPostCast:
+		return h.PostCast
+	case HookPreUpgrade:
+		return h.PreUpgrade
+	}
+	return nil
+}
+
+// Empty reports whether no stage declares a script.
+func (h Hooks) Empty() bool {
+	return len(h.PreCast) == 0 && len(h.PostCast) == 0 && len(h.PreUpgrade) == 0
+}
+
+// problems returns a message for every script path that isn't relative to
+// the mold root or leaves it.
+func (h Hooks) problems() []string {
+	var errs []string
+	for _, stage := range []string{HookPreCast, HookPostCast, HookPreUpgrade} {
+		for i, script := range h.Scripts(stage) {
+			if _, err := cleanHookPath(script); err != nil {
+				errs = append(errs, fmt.Sprintf("hooks.%s[%d]: %v", stage, i, err))
+			}
+		}
+	}
+	return errs
+}
+
+func cleanHookPath(script string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(script, "./"))
+	if script == "" || strings.Contains(script, `\`) || !fs.ValidPath(clean) || clean == "." {
+		return "", fmt.Errorf("script %q must be a path relative to the mold root that stays inside it", script)
+	}
+	return clean, nil
+}
+
+// ReadHookScript reads a hook script from the mold filesystem under the
+// same rules as {{file}}: the path must stay inside the mold and symlinks
+// are not followed.
+func ReadHookScript(fsys fs.FS, script string) ([]byte, error) {
+	clean, err := cleanHookPath(script)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readTemplateFile(fsys, clean)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// HookEnv returns the flux values as sorted KEY=value environment entries
+// for hook scripts. Nested maps are flattened (HookEnvPrefix + the dotted
+// key, upper-cased, with every other character turned into _); lists and
+// other non-scalar values are passed as JSON. The output mapping is not
+// exported: it describes the mold's layout, not the project.
+func HookEnv(flux map[string]any) []string {
+	var env []string
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if prefix == "" && k == "output" {
+				continue
+			}
+			key := prefix + hookEnvKey(k)
+			if nested, ok := v.(map[string]any); ok {
+				walk(key+"_", nested)
+				continue
+			}
+			env = append(env, HookEnvPrefix+key+"="+hookEnvValue(v))
+		}
+	}
+	walk("", flux)
+	sort.Strings(env)
+	return env
+}
+
+func hookEnvKey(k string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, k)
+}
+
+func hookEnvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,70 @@
+package mold
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHookEnv(t *testing.T) {
+	flux := map[string]any{
+		"org":     "acme",
+		"project": map[string]any{"name": "api", "go-version": 1.25},
+		"enabled": true,
+		"tags":    []any{"a", "b"},
+		"output":  map[string]any{"commands": ".claude/commands"},
+	}
+	got := HookEnv(flux)
+	want := []string{
+		"AILLOY_FLUX_ENABLED=true",
+		"AILLOY_FLUX_ORG=acme",
+		"AILLOY_FLUX_PROJECT_GO_VERSION=1.25",
+		"AILLOY_FLUX_PROJECT_NAME=api",
+		`AILLOY_FLUX_TAGS=["a","b"]`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("HookEnv =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateMold_HookPaths(t *testing.T) {
+	m := &Mold{APIVersion: "v1", Kind: "mold", Name: "m", Version: "1.0.0",
+		Hooks: Hooks{PreCast: []string{"hooks/ok.sh", "../escape.sh"}, PostCast: []string{"/abs.sh"}}}
+	err := ValidateMold(m)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"hooks.pre-cast[1]", "hooks.post-cast[0]"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "hooks.pre-cast[0]") {
+		t.Errorf("valid path reported: %v", err)
+	}
+}
+
+func TestTemper_MissingHookScript(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte(`apiVersion: v1
+kind: mold
+name: test-mold
+version: 1.0.0
+hooks:
+  pre-cast: [hooks/setup.sh]
+  post-cast: [hooks/missing.sh]
+`)},
+		"hooks/setup.sh": &fstest.MapFile{Data: []byte("#!/bin/sh\n")},
+	}
+
+	var got []string
+	for _, d := range Temper(fsys).Errors() {
+		if strings.HasPrefix(d.Message, "hooks.") {
+			got = append(got, d.Message)
+		}
+	}
+	if len(got) != 1 || !strings.Contains(got[0], "hooks.post-cast[0]") {
+		t.Errorf("hook diagnostics = %q, want one for hooks.post-cast[0]", got)
+	}
+}
@@ -0,0 +1,126 @@
+package mold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IngotInfo describes one ingot found under a resolver search path.
+type IngotInfo struct {
+	Name        string
+	Version     string
+	Description string
+	// SearchPath is the search path the ingot was found under.
+	SearchPath string
+	// Path is the ingot's directory (manifest ingots) or .md file (bare).
+	Path string
+	// Bare is true for single-file ingots without an ingot.yaml.
+	Bare bool
+	// Files lists the manifest's files; for bare ingots, the file itself.
+	Files []string
+	// Shadowed is true when an ingot of the same name appears earlier in the
+	// search order, so {{ingot}} never resolves to this copy.
+	Shadowed bool
+}
+
+// List returns every ingot under r.SearchPaths in resolution order. Within a
+// search path, ingots are sorted by name; a name seen on an earlier path
+// marks later copies Shadowed. Unparseable manifests are reported as errors
+// rather than skipped so a broken ingot doesn't silently disappear.
+func (r *IngotResolver) List() ([]IngotInfo, error) {
+	var out []IngotInfo
+	seen := make(map[string]bool)
+	for _, base := range r.SearchPaths {
+		found, err := listIngotsIn(base)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range found {
+			info.Shadowed = seen[info.Name]
+			seen[info.Name] = true
+			out = append(out, info)
+		}
+	}
+	return out, nil
+}
+
+// Locate returns the ingot {{ingot name}} would resolve to from
+// r.SearchPaths, without rendering it.
+func (r *IngotResolver) Locate(name string) (*IngotInfo, error) {
+	all, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		if all[i].Name == name && !all[i].Shadowed {
+			return &all[i], nil
+		}
+	}
+	searched := make([]string, len(r.SearchPaths))
+	for i, p := range r.SearchPaths {
+		searched[i] = filepath.Join(p, "ingots")
+	}
+	return nil, fmt.Errorf("ingot %q not found (searched: %s)", name, strings.Join(searched, ", "))
+}
+
+// listIngotsIn scans <base>/ingots for manifest directories and bare .md
+// files. A manifest directory wins over a bare file of the same name,
+// matching Resolve. A missing ingots/ directory yields nil.
+func listIngotsIn(base string) ([]IngotInfo, error) {
+	dir := filepath.Join(base, "ingots")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+
+	byName := make(map[string]IngotInfo)
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			manifestPath := filepath.Join(p, "ingot.yaml")
+			if _, err := os.Stat(manifestPath); err != nil {
+				continue
+			}
+			ingot, err := LoadIngot(manifestPath)
+			if err != nil {
+				return nil, fmt.Errorf("ingot %s: %w", p, err)
+			}
+			byName[e.Name()] = IngotInfo{
+				Name:        e.Name(),
+				Version:     ingot.Version,
+				Description: ingot.Description,
+				SearchPath:  base,
+				Path:        p,
+				Files:       ingot.Files,
+			}
+			continue
+		}
+		name, ok := strings.CutSuffix(e.Name(), ".md")
+		if !ok {
+			continue
+		}
+		if _, dup := byName[name]; dup {
+			continue
+		}
+		byName[name] = IngotInfo{
+			Name:       name,
+			SearchPath: base,
+			Path:       p,
+			Bare:       true,
+			Files:      []string{e.Name()},
+		}
+	}
+
+	out := make([]IngotInfo, 0, len(byName))
+	for _, info := range byName {
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
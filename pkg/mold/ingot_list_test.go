@@ -0,0 +1,88 @@
+package mold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIngotResolver_List(t *testing.T) {
+	moldDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	writeFile(t, filepath.Join(moldDir, "ingots", "footer", "ingot.yaml"),
+		"apiVersion: v1\nkind: ingot\nname: footer\nversion: 1.2.0\ndescription: Shared footer\nfiles: [footer.md]\n")
+	writeFile(t, filepath.Join(moldDir, "ingots", "footer", "footer.md"), "footer\n")
+	writeFile(t, filepath.Join(moldDir, "ingots", "header.md"), "header\n")
+	writeFile(t, filepath.Join(moldDir, "ingots", "notes.txt"), "ignored\n")
+	writeFile(t, filepath.Join(projectDir, "ingots", "footer.md"), "shadowed footer\n")
+	writeFile(t, filepath.Join(projectDir, "ingots", "extra.md"), "extra\n")
+
+	r := NewIngotResolver([]string{moldDir, projectDir, filepath.Join(t.TempDir(), "missing")}, nil)
+	got, err := r.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	want := []struct {
+		name     string
+		bare     bool
+		shadowed bool
+	}{
+		{"footer", false, false},
+		{"header", true, false},
+		{"extra", true, false},
+		{"footer", true, true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d ingots, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Name != w.name || got[i].Bare != w.bare || got[i].Shadowed != w.shadowed {
+			t.Errorf("ingot %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+	if got[0].Version != "1.2.0" || got[0].Description != "Shared footer" {
+		t.Errorf("manifest fields not loaded: %+v", got[0])
+	}
+}
+
+func TestIngotResolver_Locate(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	writeFile(t, filepath.Join(first, "ingots", "shared.md"), "first\n")
+	writeFile(t, filepath.Join(second, "ingots", "shared.md"), "second\n")
+
+	r := NewIngotResolver([]string{first, second}, nil)
+	in, err := r.Locate("shared")
+	if err != nil {
+		t.Fatalf("Locate: %v", err)
+	}
+	if in.SearchPath != first {
+		t.Errorf("Locate returned %s, want the first search path", in.SearchPath)
+	}
+
+	if _, err := r.Locate("missing"); err == nil {
+		t.Error("expected error for missing ingot")
+	}
+}
+
+func TestIngotResolver_ListBrokenManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ingots", "broken", "ingot.yaml"), "name: [unterminated\n")
+
+	r := NewIngotResolver([]string{dir}, nil)
+	if _, err := r.List(); err == nil {
+		t.Error("expected error for unparseable manifest")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/nimble-giant/ailloy/pkg/safepath"
 )
 
@@ -19,13 +21,51 @@ import (
 // This is how a stuffed-binary cast resolves the mold's own ingots: the mold
 // lives in an embedded fs.FS, not on disk, so a purely path-based search would
 // miss them.
+//
+// Constraints, when non-nil, maps ingot names to semver constraints (the
+// `version:` of the mold's ingot dependencies). The first ingot found for a
+// constrained name must carry an ingot.yaml whose version satisfies it;
+// otherwise Resolve fails naming both the found version and the constraint.
+//
+// Remote holds pre-fetched remote ingots keyed by the reference string used
+// in the template ({{ingot "github.com/org/ingots//footer@^1.0.0"}}). Each
+// fs.FS is rooted at the ingot package (its ingot.yaml). Remote references
+// are never fetched at render time; callers populate Remote while planning
+// the cast (see RemoteIngotRefs).
 type IngotResolver struct {
 	FS          fs.FS
 	SearchPaths []string
 	Flux        map[string]any
+	Constraints map[string]string
+	Remote      map[string]fs.FS
 	resolving   map[string]bool
 }
 
+// ingotNamePattern captures the literal first argument of {{ingot "..."}}.
+var ingotNamePattern = regexp.MustCompile(`\{\{-?\s*ingot\s+"([^"]+)"`)
+
+// IsRemoteIngotRef reports whether an {{ingot}} name is a foundry reference
+// (host/owner/repo...) rather than a local ingot name. The first path
+// segment of a remote reference is a host, so it contains a dot.
+func IsRemoteIngotRef(name string) bool {
+	host, rest, ok := strings.Cut(name, "/")
+	return ok && rest != "" && strings.Contains(host, ".")
+}
+
+// RemoteIngotRefs returns the distinct remote references passed as literal
+// names to {{ingot}} in content, in order of first appearance.
+func RemoteIngotRefs(content string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, m := range ingotNamePattern.FindAllStringSubmatch(content, -1) {
+		if IsRemoteIngotRef(m[1]) && !seen[m[1]] {
+			seen[m[1]] = true
+			refs = append(refs, m[1])
+		}
+	}
+	return refs
+}
+
 // NewIngotResolver creates a resolver that searches the given paths in order.
 func NewIngotResolver(searchPaths []string, flux map[string]any) *IngotResolver {
 	return &IngotResolver{
@@ -43,39 +83,86 @@ func NewIngotResolverWithFS(fsys fs.FS, searchPaths []string, flux map[string]an
 	return r
 }
 
+// Clone returns a resolver with the same configuration and its own
+// circular-reference tracking. Resolve is not safe for concurrent use, so
+// callers rendering in parallel give each goroutine a clone.
+func (r *IngotResolver) Clone() *IngotResolver {
+	c := *r
+	c.resolving = nil
+	return &c
+}
+
 // Resolve finds and renders an ingot by name. It searches each path for a
 // directory with an ingot.yaml manifest first, then falls back to a bare .md file.
 // The ingot content is rendered through the same template engine with the same
 // flux context. Circular references are detected and reported as errors.
-func (r *IngotResolver) Resolve(name string) (string, error) {
+//
+// args are optional key/value pairs ({{ingot "name" level="strict"}} reaches
+// here as "level", "strict") merged over the flux for this ingot's render
+// only. Dotted keys set nested values. Nested ingot calls inherit them.
+func (r *IngotResolver) Resolve(name string, args ...any) (string, error) {
 	if r.resolving[name] {
 		return "", fmt.Errorf("circular ingot reference detected: %s", name)
 	}
+
+	flux, err := r.fluxWithArgs(name, args)
+	if err != nil {
+		return "", err
+	}
+	if r.resolving == nil {
+		r.resolving = make(map[string]bool)
+	}
 	r.resolving[name] = true
 	defer delete(r.resolving, name)
 
+	if IsRemoteIngotRef(name) {
+		fsys, ok := r.Remote[name]
+		if !ok {
+			return "", fmt.Errorf("remote ingot %q was not fetched before rendering", name)
+		}
+		_, content, err := r.resolveManifestFS(fsys, "ingot.yaml", name)
+		if err != nil {
+			return "", fmt.Errorf("remote ingot %q: %w", name, err)
+		}
+		return r.render(content, flux)
+	}
+
 	// Search the embedded/mold fs.FS first (stuffed-binary casts: the mold and
 	// its ingots live in this FS, not on disk).
 	if r.FS != nil {
-		if content, err := r.resolveManifestFS(path.Join("ingots", name, "ingot.yaml"), name); err == nil {
-			return r.render(content)
+		fsPath := path.Join("ingots", name, "ingot.yaml")
+		if ingot, content, err := r.resolveManifestFS(r.FS, fsPath, name); err == nil {
+			if err := r.checkVersion(name, ingot.Version, fsPath); err != nil {
+				return "", err
+			}
+			return r.render(content, flux)
 		}
-		if content, err := fs.ReadFile(r.FS, path.Join("ingots", name+".md")); err == nil {
-			return r.render(string(content))
+		barePath := path.Join("ingots", name+".md")
+		if content, err := fs.ReadFile(r.FS, barePath); err == nil {
+			if err := r.checkVersion(name, "", barePath); err != nil {
+				return "", err
+			}
+			return r.render(string(content), flux)
 		}
 	}
 
 	for _, base := range r.SearchPaths {
 		// Try directory with manifest first
 		manifestPath := filepath.Join(base, "ingots", name, "ingot.yaml")
-		if content, err := r.resolveManifest(manifestPath, name); err == nil {
-			return r.render(content)
+		if ingot, content, err := r.resolveManifest(manifestPath, name); err == nil {
+			if err := r.checkVersion(name, ingot.Version, manifestPath); err != nil {
+				return "", err
+			}
+			return r.render(content, flux)
 		}
 
 		// Fall back to bare file
 		barePath := filepath.Join(base, "ingots", name+".md")
 		if content, err := r.readFile(barePath); err == nil {
-			return r.render(string(content))
+			if err := r.checkVersion(name, "", barePath); err != nil {
+				return "", err
+			}
+			return r.render(string(content), flux)
 		}
 	}
 
@@ -87,20 +174,20 @@ func (r *IngotResolver) Resolve(name string) (string, error) {
 }
 
 // resolveManifest loads an ingot.yaml manifest and concatenates all listed files.
-func (r *IngotResolver) resolveManifest(manifestPath, name string) (string, error) {
+func (r *IngotResolver) resolveManifest(manifestPath, name string) (*Ingot, string, error) {
 	cleanPath, err := safepath.Clean(manifestPath)
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
 
 	data, err := os.ReadFile(cleanPath) // #nosec G304 -- path sanitized by safepath.Clean
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
 
 	ingot, err := ParseIngot(data)
 	if err != nil {
-		return "", fmt.Errorf("parsing ingot %q manifest: %w", name, err)
+		return nil, "", fmt.Errorf("parsing ingot %q manifest: %w", name, err)
 	}
 
 	ingotDir := filepath.Dir(cleanPath)
@@ -108,43 +195,69 @@ func (r *IngotResolver) resolveManifest(manifestPath, name string) (string, erro
 	for _, f := range ingot.Files {
 		filePath, err := safepath.Join(ingotDir, f)
 		if err != nil {
-			return "", fmt.Errorf("ingot %q file %q: %w", name, f, err)
+			return nil, "", fmt.Errorf("ingot %q file %q: %w", name, f, err)
 		}
 		content, err := os.ReadFile(filePath) // #nosec G304 -- path sanitized by safepath.Join
 		if err != nil {
-			return "", fmt.Errorf("reading ingot %q file %q: %w", name, f, err)
+			return nil, "", fmt.Errorf("reading ingot %q file %q: %w", name, f, err)
 		}
 		combined.Write(content)
 	}
 
-	return combined.String(), nil
+	return ingot, combined.String(), nil
 }
 
-// resolveManifestFS loads an ingot.yaml manifest from r.FS and concatenates all
-// listed files. The fs.FS analogue of resolveManifest for stuffed-binary casts.
-func (r *IngotResolver) resolveManifestFS(manifestPath, name string) (string, error) {
-	data, err := fs.ReadFile(r.FS, manifestPath)
+// resolveManifestFS loads an ingot.yaml manifest from fsys and concatenates all
+// listed files. The fs.FS analogue of resolveManifest for stuffed-binary casts
+// and pre-fetched remote ingots.
+func (r *IngotResolver) resolveManifestFS(fsys fs.FS, manifestPath, name string) (*Ingot, string, error) {
+	data, err := fs.ReadFile(fsys, manifestPath)
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
 	ingot, err := ParseIngot(data)
 	if err != nil {
-		return "", fmt.Errorf("parsing ingot %q manifest: %w", name, err)
+		return nil, "", fmt.Errorf("parsing ingot %q manifest: %w", name, err)
 	}
 	ingotDir := path.Dir(manifestPath)
 	var combined strings.Builder
 	for _, f := range ingot.Files {
 		fp := path.Join(ingotDir, f)
 		if !fs.ValidPath(fp) {
-			return "", fmt.Errorf("ingot %q file %q: invalid path", name, f)
+			return nil, "", fmt.Errorf("ingot %q file %q: invalid path", name, f)
 		}
-		content, err := fs.ReadFile(r.FS, fp)
+		content, err := fs.ReadFile(fsys, fp)
 		if err != nil {
-			return "", fmt.Errorf("reading ingot %q file %q: %w", name, f, err)
+			return nil, "", fmt.Errorf("reading ingot %q file %q: %w", name, f, err)
 		}
 		combined.Write(content)
 	}
-	return combined.String(), nil
+	return ingot, combined.String(), nil
+}
+
+// checkVersion enforces r.Constraints for the ingot found at location.
+// version is the ingot.yaml version, or "" for a bare-file ingot (which can
+// never satisfy a declared constraint). Unconstrained names always pass.
+func (r *IngotResolver) checkVersion(name, version, location string) error {
+	want, ok := r.Constraints[name]
+	if !ok || want == "" || want == "latest" {
+		return nil
+	}
+	c, err := semver.NewConstraint(want)
+	if err != nil {
+		return fmt.Errorf("ingot %q: invalid version constraint %q: %w", name, want, err)
+	}
+	if version == "" {
+		return fmt.Errorf("ingot %q at %s has no version (bare file or missing ingot.yaml version) but the mold requires %s", name, location, want)
+	}
+	v, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return fmt.Errorf("ingot %q at %s has invalid version %q: %w", name, location, version, err)
+	}
+	if !c.Check(v) {
+		return fmt.Errorf("ingot %q version %s at %s does not satisfy the mold's constraint %s", name, version, location, want)
+	}
+	return nil
 }
 
 // readFile reads a file with path sanitization.
@@ -156,7 +269,32 @@ func (r *IngotResolver) readFile(path string) ([]byte, error) {
 	return os.ReadFile(cleanPath) // #nosec G304 -- path sanitized by safepath.Clean
 }
 
-// render processes ingot content through the template engine with the same context.
-func (r *IngotResolver) render(content string) (string, error) {
-	return ProcessTemplate(content, r.Flux, WithIngotResolver(r))
+// fluxWithArgs returns the flux an ingot renders with: r.Flux when no
+// arguments were passed, otherwise a copy with the key/value pairs merged in.
+func (r *IngotResolver) fluxWithArgs(name string, args []any) (map[string]any, error) {
+	if len(args) == 0 {
+		return r.Flux, nil
+	}
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("ingot %q: arguments must be key=value pairs", name)
+	}
+	set := make(map[string]any, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("ingot %q: argument name must be a non-empty string, got %v", name, args[i])
+		}
+		set[key] = args[i+1]
+	}
+	return MergeSet(r.Flux, set), nil
+}
+
+// render processes ingot content through the template engine. Nested ingot
+// calls resolve through a copy of r carrying flux, so arguments passed to
+// this ingot stay visible to the ingots it includes. The copy shares the
+// resolving set, keeping circular-reference detection intact.
+func (r *IngotResolver) render(content string, flux map[string]any) (string, error) {
+	child := *r
+	child.Flux = flux
+	return ProcessTemplate(content, flux, WithIngotResolver(&child))
 }
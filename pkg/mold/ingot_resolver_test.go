@@ -1,6 +1,8 @@
 package mold
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -257,6 +259,52 @@ func TestIngotResolver_NestedIngots(t *testing.T) {
 	}
 }
 
+func TestIngotResolver_ArgsMergeOverFlux(t *testing.T) {
+	dir := t.TempDir()
+	ingotDir := filepath.Join(dir, "ingots")
+	if err := os.MkdirAll(ingotDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ingotDir, "inner.md"), []byte("{{review.level}}/{{review.owner}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ingotDir, "outer.md"), []byte(`[{{ingot "inner"}}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	flux := map[string]any{"review": map[string]any{"level": "relaxed", "owner": "team"}}
+	r := NewIngotResolver([]string{dir}, flux)
+	result, err := r.Resolve("outer", "review.level", "strict")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "[strict/team]" {
+		t.Errorf("expected nested ingot to inherit args, got %q", result)
+	}
+	if got, _ := GetNestedValue(flux, "review.level"); got != "relaxed" {
+		t.Errorf("args must not mutate the resolver's flux, got review.level=%q", got)
+	}
+}
+
+func TestIngotResolver_ArgsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	ingotDir := filepath.Join(dir, "ingots")
+	if err := os.MkdirAll(ingotDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ingotDir, "x.md"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewIngotResolver([]string{dir}, nil)
+	if _, err := r.Resolve("x", "dangling"); err == nil || !strings.Contains(err.Error(), "key=value") {
+		t.Errorf("expected key=value pairing error, got %v", err)
+	}
+	if _, err := r.Resolve("x", 1, "v"); err == nil || !strings.Contains(err.Error(), "argument name") {
+		t.Errorf("expected argument name error, got %v", err)
+	}
+}
+
 func TestIngotResolver_EmptySearchPaths(t *testing.T) {
 	r := NewIngotResolver(nil, nil)
 	_, err := r.Resolve("anything")
@@ -267,3 +315,136 @@ func TestIngotResolver_EmptySearchPaths(t *testing.T) {
 		t.Errorf("expected 'not found' error, got: %v", err)
 	}
 }
+
+func writeVersionedIngot(t *testing.T, base, name, version string) {
+	t.Helper()
+	dir := filepath.Join(base, "ingots", name)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "apiVersion: v1\nkind: ingot\nname: " + name + "\nversion: " + version + "\nfiles:\n  - body.md\n"
+	if err := os.WriteFile(filepath.Join(dir, "ingot.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "body.md"), []byte(name+"@"+version), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIngotResolver_ConstraintSatisfied(t *testing.T) {
+	dir := t.TempDir()
+	writeVersionedIngot(t, dir, "checklist", "1.4.0")
+
+	r := NewIngotResolver([]string{dir}, nil)
+	r.Constraints = map[string]string{"checklist": "^1.2.0"}
+	got, err := r.Resolve("checklist")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "checklist@1.4.0" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestIngotResolver_ConstraintMismatch(t *testing.T) {
+	dir := t.TempDir()
+	writeVersionedIngot(t, dir, "checklist", "1.4.0")
+
+	r := NewIngotResolver([]string{dir}, nil)
+	r.Constraints = map[string]string{"checklist": "^2.0.0"}
+	_, err := r.Resolve("checklist")
+	if err == nil {
+		t.Fatal("expected constraint mismatch error")
+	}
+	for _, want := range []string{"1.4.0", "^2.0.0"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+}
+
+func TestIngotResolver_ConstraintBareFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "ingots"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ingots", "checklist.md"), []byte("bare"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewIngotResolver([]string{dir}, nil)
+	r.Constraints = map[string]string{"checklist": "^1.0.0"}
+	if _, err := r.Resolve("checklist"); err == nil || !strings.Contains(err.Error(), "no version") {
+		t.Errorf("expected bare-file constraint error, got %v", err)
+	}
+}
+
+func TestIngotResolver_ConstraintFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ingots/head/ingot.yaml": {Data: []byte("name: head\nversion: 0.9.0\nfiles:\n  - head.md\n")},
+		"ingots/head/head.md":    {Data: []byte("== head ==")},
+	}
+	r := NewIngotResolverWithFS(fsys, nil, nil)
+	r.Constraints = map[string]string{"head": ">=1.0.0"}
+	if _, err := r.Resolve("head"); err == nil || !strings.Contains(err.Error(), "0.9.0") {
+		t.Errorf("expected embedded ingot constraint error, got %v", err)
+	}
+}
+
+func TestRemoteIngotRefs(t *testing.T) {
+	content := `{{ingot "local"}} {{ingot "github.com/org/ingots//footer@^1.0.0"}}
+{{- ingot "github.com/org/ingots//footer@^1.0.0" level="x" -}} {{ingot "gitlab.example.com/a/b"}}`
+	got := RemoteIngotRefs(content)
+	want := []string{"github.com/org/ingots//footer@^1.0.0", "gitlab.example.com/a/b"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("RemoteIngotRefs = %v, want %v", got, want)
+	}
+	if IsRemoteIngotRef("team/preamble") {
+		t.Error("a slash-separated local name without a host must not be remote")
+	}
+}
+
+func TestIngotResolver_Remote(t *testing.T) {
+	const ref = "github.com/org/ingots//footer@^1.0.0"
+	remote := fstest.MapFS{
+		"ingot.yaml": {Data: []byte("name: footer\nversion: 1.2.0\nfiles:\n  - footer.md\n")},
+		"footer.md":  {Data: []byte("-- {{org}} --")},
+	}
+	r := NewIngotResolver(nil, map[string]any{"org": "Acme"})
+	r.Remote = map[string]fs.FS{ref: remote}
+
+	got, err := ProcessTemplate(`{{ingot "`+ref+`"}}`, r.Flux, WithIngotResolver(r))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "-- Acme --" {
+		t.Errorf("got %q, want %q", got, "-- Acme --")
+	}
+
+	if _, err := r.Resolve("github.com/org/other//x"); err == nil || !strings.Contains(err.Error(), "not fetched") {
+		t.Errorf("expected not-fetched error, got %v", err)
+	}
+}
+
+func TestIngotResolver_CloneConcurrent(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ingots/footer.md": {Data: []byte("-- {{ team }} --")},
+	}
+	r := NewIngotResolverWithFS(fsys, nil, map[string]any{"team": "core"})
+
+	errs := make(chan error, 16)
+	for range 16 {
+		go func() {
+			got, err := r.Clone().Resolve("footer")
+			if err == nil && got != "-- core --" {
+				err = fmt.Errorf("got %q", got)
+			}
+			errs <- err
+		}()
+	}
+	for range 16 {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/nimble-giant/ailloy/pkg/safepath"
@@ -36,6 +38,18 @@ type SelectOption struct {
 	Value string `yaml:"value"`
 }
 
+// ResolveSpec computes a flux variable's value at cast time when the values
+// files leave it empty.
+type ResolveSpec struct {
+	// CurrentIteration is the dotted flux path of an iteration options map
+	// ({key: {id, label, start_date, duration}}); the variable resolves to
+	// the iteration running on the day of the cast.
+	CurrentIteration string `yaml:"current_iteration,omitempty"`
+	// Field picks the option field the variable takes: "id" (the default)
+	// or "label".
+	Field string `yaml:"field,omitempty"`
+}
+
 // FluxVar declares a template variable with type information.
 type FluxVar struct {
 	Name        string         `yaml:"name"`
@@ -45,6 +59,19 @@ type FluxVar struct {
 	Default     string         `yaml:"default,omitempty"`
 	Options     []SelectOption `yaml:"options,omitempty"`  // Static options for select type
 	Discover    *DiscoverSpec  `yaml:"discover,omitempty"` // Dynamic discovery specification
+	// Deprecated marks a variable the mold is phasing out: casts warn when
+	// values still set it, and temper flags blanks that still read it.
+	Deprecated bool `yaml:"deprecated,omitempty"`
+	// RenamedFrom is the variable's previous name. Values set under it are
+	// moved to Name with a warning (see MigrateFlux).
+	RenamedFrom string `yaml:"renamed_from,omitempty"`
+	// RequiredIf makes the variable required only while a condition holds:
+	// a dotted flux path ("ore.status.enabled") or a template expression
+	// (`eq .scm.provider "github"`). See EvalFluxCondition.
+	RequiredIf string `yaml:"required_if,omitempty"`
+	// Resolve computes the value at cast time when none is set. See
+	// ResolveFlux.
+	Resolve *ResolveSpec `yaml:"resolve,omitempty"`
 }
 
 // Dependency declares a dependency on a mold, ingot, or ore. Exactly one of
@@ -108,7 +135,7 @@ func (d Dependency) Source() string {
 // OutputTarget represents a single output directory or file mapping.
 // It supports three YAML forms:
 //   - Simple string: "dest/path" (process defaults to true)
-//   - Expanded map: {dest: "dest/path", process: false, set: {...}, strategy: "merge"}
+//   - Expanded map: {dest: "dest/path", process: false, set: {...}, strategy: "merge", exclude: [...]}
 //   - List of either form, expanded into multiple targets (multi-destination)
 type OutputTarget struct {
 	Dest     string         `yaml:"dest"`
@@ -121,6 +148,13 @@ type OutputTarget struct {
 	// an ore-shipped template without the ore needing to declare the
 	// destination itself.
 	From string `yaml:"from,omitempty"`
+	// Provenance false leaves the provenance header out of these files;
+	// see StampProvenance. nil = true (default).
+	Provenance *bool `yaml:"provenance,omitempty"`
+	// Exclude lists .ailloyignore-style patterns for files of a source
+	// directory or glob key to leave out, matched against their path
+	// relative to the directory (or the glob's base directory).
+	Exclude []string `yaml:"exclude,omitempty"`
 }
 
 // ShouldProcess returns whether files under this target should be template-processed.
@@ -143,22 +177,52 @@ type ResolvedFile struct {
 	// Origin identifies the ore namespace this entry came from, for
 	// diagnostics. Empty means the consumer mold itself.
 	Origin string `yaml:"-"`
+	// NoProvenance leaves the provenance header out of this file.
+	NoProvenance bool `yaml:"-"`
 }
 
 // Mold represents a mold.yaml manifest.
 type Mold struct {
-	APIVersion   string       `yaml:"apiVersion"`
-	Kind         string       `yaml:"kind"`
-	Name         string       `yaml:"name"`
-	Version      string       `yaml:"version"`
-	Description  string       `yaml:"description,omitempty"`
-	License      string       `yaml:"license,omitempty"`
-	Author       Author       `yaml:"author,omitempty"`
-	Requires     Requires     `yaml:"requires,omitempty"`
-	Flux         []FluxVar    `yaml:"flux,omitempty"`
-	Output       any          `yaml:"output,omitempty"`
-	Dependencies []Dependency `yaml:"dependencies,omitempty"`
-	Ignore       []string     `yaml:"ignore,omitempty"`
+	APIVersion   string         `yaml:"apiVersion"`
+	Kind         string         `yaml:"kind"`
+	Name         string         `yaml:"name"`
+	Version      string         `yaml:"version"`
+	Extends      string         `yaml:"extends,omitempty"` // parent mold reference; see ComposeExtends
+	Description  string         `yaml:"description,omitempty"`
+	License      string         `yaml:"license,omitempty"`
+	Author       Author         `yaml:"author,omitempty"`
+	Requires     Requires       `yaml:"requires,omitempty"`
+	Flux         []FluxVar      `yaml:"flux,omitempty"`
+	Output       any            `yaml:"output,omitempty"`
+	Profiles     map[string]any `yaml:"profiles,omitempty"`
+	Dependencies []Dependency   `yaml:"dependencies,omitempty"`
+	Ignore       []string       `yaml:"ignore,omitempty"`
+	// Components names groups of path patterns that `cast --only` and
+	// `--exclude` accept in place of the patterns; see Selection.
+	Components   map[string][]string `yaml:"components,omitempty"`
+	Deprecations *Deprecations       `yaml:"deprecations,omitempty"`
+	Hooks        Hooks               `yaml:"hooks,omitempty"`
+	// Provenance false casts blanks without provenance headers; see
+	// StampsProvenance.
+	Provenance *bool `yaml:"provenance,omitempty"`
+	// Binaries lists .ailloyignore-style patterns of binary files cast may
+	// write even when larger than MaxBinaryFileSize.
+	Binaries []string `yaml:"binaries,omitempty"`
+}
+
+// AllowedBinaries returns the manifest's binaries: patterns; nil for a
+// nil manifest.
+func (m *Mold) AllowedBinaries() []string {
+	if m == nil {
+		return nil
+	}
+	return m.Binaries
+}
+
+// StampsProvenance reports whether cast writes provenance headers into the
+// mold's blanks (the default; `provenance: false` turns it off).
+func (m *Mold) StampsProvenance() bool {
+	return m != nil && (m.Provenance == nil || *m.Provenance)
 }
 
 // LoadMold reads and parses a mold.yaml file from the given path.
@@ -197,6 +261,63 @@ func ApplyManifestOutputDefault(flux map[string]any, manifest *Mold) {
 	flux["output"] = manifest.Output
 }
 
+// outputProfiles collects the output profiles available to a cast: mold.yaml's
+// profiles: overlaid by flux["profiles"] (flux.yaml, -f, --set), so a values
+// file can add a tool or redirect one of the mold's.
+func outputProfiles(flux map[string]any, manifest *Mold) map[string]any {
+	profiles := make(map[string]any)
+	if manifest != nil {
+		for name, mapping := range manifest.Profiles {
+			profiles[name] = mapping
+		}
+	}
+	if fromFlux, ok := flux["profiles"].(map[string]any); ok {
+		for name, mapping := range fromFlux {
+			profiles[name] = mapping
+		}
+	}
+	return profiles
+}
+
+// OutputProfileNames returns the sorted names of the output profiles declared
+// by the mold and its flux.
+func OutputProfileNames(flux map[string]any, manifest *Mold) []string {
+	profiles := outputProfiles(flux, manifest)
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasOutputProfile reports whether the named output profile is declared.
+func HasOutputProfile(flux map[string]any, manifest *Mold, profile string) bool {
+	_, ok := outputProfiles(flux, manifest)[profile]
+	return ok
+}
+
+// ApplyOutputProfile replaces flux["output"] with the output mapping of the
+// named profile (e.g. "cursor"), letting one mold target several AI tools.
+// The profile mapping has the same shape as output: and replaces it whole.
+// An empty profile is a no-op; an unknown one is an error naming the
+// profiles that are declared.
+func ApplyOutputProfile(flux map[string]any, manifest *Mold, profile string) error {
+	if profile == "" || flux == nil {
+		return nil
+	}
+	mapping, ok := outputProfiles(flux, manifest)[profile]
+	if !ok {
+		names := OutputProfileNames(flux, manifest)
+		if len(names) == 0 {
+			return fmt.Errorf("output profile %q not found: mold declares no profiles", profile)
+		}
+		return fmt.Errorf("output profile %q not found (available: %s)", profile, strings.Join(names, ", "))
+	}
+	flux["output"] = mapping
+	return nil
+}
+
 // ParseMold parses raw YAML bytes into a Mold struct.
 func ParseMold(data []byte) (*Mold, error) {
 	var m Mold
@@ -194,6 +194,99 @@ func TestApplyManifestOutputDefault_NilManifestOutputIsNoop(t *testing.T) {
 	}
 }
 
+func TestParseMold_Profiles(t *testing.T) {
+	m, err := ParseMold([]byte(`
+apiVersion: v1
+kind: mold
+name: multi-tool
+version: 1.0.0
+output:
+  commands: .claude/commands
+profiles:
+  cursor:
+    commands: .cursor/rules
+  opencode:
+    commands: .opencode/command
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.Profiles) != 2 {
+		t.Fatalf("expected 2 profiles, got %d", len(m.Profiles))
+	}
+	if got := OutputProfileNames(nil, m); strings.Join(got, ",") != "cursor,opencode" {
+		t.Errorf("unexpected profile names: %v", got)
+	}
+}
+
+func TestApplyOutputProfile_ReplacesOutput(t *testing.T) {
+	manifest := &Mold{
+		Output:   map[string]any{"commands": ".claude/commands"},
+		Profiles: map[string]any{"cursor": map[string]any{"commands": ".cursor/rules"}},
+	}
+	flux := map[string]any{}
+	ApplyManifestOutputDefault(flux, manifest)
+
+	if err := ApplyOutputProfile(flux, manifest, "cursor"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	outMap := flux["output"].(map[string]any)
+	if outMap["commands"] != ".cursor/rules" {
+		t.Errorf("expected cursor destination, got %v", outMap["commands"])
+	}
+}
+
+func TestApplyOutputProfile_FluxProfilesOverrideManifest(t *testing.T) {
+	manifest := &Mold{
+		Profiles: map[string]any{"cursor": map[string]any{"commands": ".cursor/rules"}},
+	}
+	flux := map[string]any{
+		"profiles": map[string]any{
+			"cursor": map[string]any{"commands": "custom/rules"},
+			"codex":  map[string]any{"commands": ".codex/prompts"},
+		},
+	}
+
+	if err := ApplyOutputProfile(flux, manifest, "cursor"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := flux["output"].(map[string]any)["commands"]; got != "custom/rules" {
+		t.Errorf("expected flux profile to win, got %v", got)
+	}
+	if !HasOutputProfile(flux, manifest, "codex") {
+		t.Error("expected flux-declared codex profile to be available")
+	}
+}
+
+func TestApplyOutputProfile_EmptyIsNoop(t *testing.T) {
+	flux := map[string]any{"output": "keep"}
+	if err := ApplyOutputProfile(flux, &Mold{}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flux["output"] != "keep" {
+		t.Errorf("expected output untouched, got %v", flux["output"])
+	}
+}
+
+func TestApplyOutputProfile_UnknownListsAvailable(t *testing.T) {
+	manifest := &Mold{Profiles: map[string]any{
+		"cursor": map[string]any{},
+		"codex":  map[string]any{},
+	}}
+	err := ApplyOutputProfile(map[string]any{}, manifest, "zed")
+	if err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+	if !strings.Contains(err.Error(), "available: codex, cursor") {
+		t.Errorf("expected available profiles in error, got %v", err)
+	}
+
+	err = ApplyOutputProfile(map[string]any{}, &Mold{}, "zed")
+	if err == nil || !strings.Contains(err.Error(), "declares no profiles") {
+		t.Errorf("expected no-profiles error, got %v", err)
+	}
+}
+
 func TestValidateMold_Valid(t *testing.T) {
 	m := &Mold{
 		APIVersion: "v1",
@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"path"
 	"sort"
+	"strings"
 )
 
 // LoadOreOverlaysFromFS scans <root>/*/ for ore packages and returns one
@@ -69,6 +70,17 @@ func LoadOreOverlaysFromFS(fsys fs.FS, root string, seen map[string]struct{}) ([
 		for _, e := range schema {
 			pe := e
 			pe.Name = prefix + e.Name
+			if e.RenamedFrom != "" {
+				pe.RenamedFrom = prefix + e.RenamedFrom
+			}
+			if isFluxPath(e.RequiredIf) {
+				pe.RequiredIf = prefix + strings.TrimPrefix(e.RequiredIf, ".")
+			}
+			if e.Resolve != nil && isFluxPath(e.Resolve.CurrentIteration) {
+				r := *e.Resolve
+				r.CurrentIteration = prefix + strings.TrimPrefix(r.CurrentIteration, ".")
+				pe.Resolve = &r
+			}
 			prefixed = append(prefixed, pe)
 		}
 		overlays = append(overlays, OverlaySchema{
@@ -97,3 +97,25 @@ func TestLoadOreOverlays_HigherPriorityWins(t *testing.T) {
 		t.Errorf("project should be skipped because mold-local already loaded 'status': %+v", projectOverlays)
 	}
 }
+
+func TestOreResolver_PrefixesResolvePaths(t *testing.T) {
+	oreFS := fstest.MapFS{
+		"ores/iteration/ore.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: ore\nname: iteration\nversion: 1.0.0\n")},
+		"ores/iteration/flux.schema.yaml": &fstest.MapFile{Data: []byte(`- name: enabled
+  type: bool
+- name: current
+  type: string
+  resolve:
+    current_iteration: options
+`)},
+		"ores/iteration/flux.yaml": &fstest.MapFile{Data: []byte("enabled: false\n")},
+	}
+	overlays, _, err := LoadOreOverlaysFromFS(oreFS, "ores", nil)
+	if err != nil {
+		t.Fatalf("LoadOreOverlaysFromFS: %v", err)
+	}
+	current := overlays[0].Entries[1]
+	if current.Resolve == nil || current.Resolve.CurrentIteration != "ore.iteration.options" {
+		t.Errorf("resolve = %+v, want the options path prefixed", current.Resolve)
+	}
+}
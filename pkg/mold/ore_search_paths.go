@@ -3,6 +3,8 @@ package mold
 import (
 	"io/fs"
 	"os"
+
+	"github.com/nimble-giant/ailloy/pkg/scope"
 )
 
 // BuildDefaultOreSearchPaths returns the canonical ore-search-path order used
@@ -14,11 +16,15 @@ import (
 //  2. project — ores installed into the current working directory under
 //     ".ailloy/ores". Honored even when the caller is doing a global cast,
 //     because users may have project-installed ores they want to layer in.
-//     Skipped if the cwd cannot be determined.
+//     Skipped if the cwd cannot be determined. Then the ".ailloy/ores" of
+//     each workspace ancestor (see scope.WorkspaceDirs), nearest first, so
+//     a monorepo package sees ores installed at the repository root.
 //  3. global — ores installed into the user's home directory under
-//     ".ailloy/ores". Lowest priority; only contributes namespaces not
-//     already provided by mold-local or project. Skipped if the home dir
-//     cannot be determined.
+//     ".ailloy/ores". Only contributes namespaces not already provided by
+//     mold-local or project. Skipped if the home dir cannot be determined.
+//  4. system — organization-provisioned ores under the system root's
+//     "ores" (see pkg/scope). Lowest priority. Skipped when the machine
+//     has no system scope.
 //
 // Lower-priority entries only contribute ore namespaces not already seen,
 // mirroring how flux defaults are layered.
@@ -38,6 +44,13 @@ func BuildDefaultOreSearchPaths(moldFS fs.FS, global bool) []OreSearchPath {
 			Root: ".ailloy/ores",
 		})
 	}
+	for _, root := range scope.WorkspaceProjectRoots() {
+		paths = append(paths, OreSearchPath{
+			Name: "workspace",
+			FS:   os.DirFS(root),
+			Root: "ores",
+		})
+	}
 	if home, err := os.UserHomeDir(); err == nil {
 		paths = append(paths, OreSearchPath{
 			Name: "global",
@@ -45,6 +58,13 @@ func BuildDefaultOreSearchPaths(moldFS fs.FS, global bool) []OreSearchPath {
 			Root: ".ailloy/ores",
 		})
 	}
+	if root := scope.SystemRoot(); root != "" {
+		paths = append(paths, OreSearchPath{
+			Name: "system",
+			FS:   os.DirFS(root),
+			Root: "ores",
+		})
+	}
 	_ = global // currently only affects install-dir, not search-path order
 	return paths
 }
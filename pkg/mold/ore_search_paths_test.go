@@ -76,3 +76,16 @@ func TestBuildDefaultOreSearchPaths_GlobalFlagDoesNotChangeOrder(t *testing.T) {
 		t.Errorf("path Name order differs: false=%v true=%v", aNames, bNames)
 	}
 }
+
+// TestBuildDefaultOreSearchPaths_SystemScopeIsLast verifies the system root
+// contributes an "ores" search path after every per-user scope.
+func TestBuildDefaultOreSearchPaths_SystemScopeIsLast(t *testing.T) {
+	systemDir := t.TempDir()
+	t.Setenv("AILLOY_SYSTEM_ROOT", systemDir)
+
+	paths := mold.BuildDefaultOreSearchPaths(fstest.MapFS{}, false)
+	last := paths[len(paths)-1]
+	if last.Name != "system" || last.Root != "ores" {
+		t.Fatalf("last path = %s (%s), want system (ores)", last.Name, last.Root)
+	}
+}
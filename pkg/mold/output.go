@@ -13,6 +13,7 @@ import (
 var reservedDirs = map[string]bool{
 	"ingots": true,
 	"deps":   true, // smelt-embedded dep tree; internal to the binary, not mold content
+	"tests":  true, // golden-file test cases run by `ailloy mold test`
 }
 
 // reservedRootFiles are root-level files excluded from auto-discovery
@@ -36,6 +37,8 @@ var reservedRootFiles = map[string]bool{
 	"PLUGIN_SUMMARY.md": true, // plugin summary metadata
 	"LICENSE":           true, // mold license file
 	".ailloyignore":     true, // ignore patterns for casting
+	"DEPRECATIONS.yaml": true, // deprecated and yanked versions
+	"provenance.yaml":   true, // build provenance written by smelt
 }
 
 // dirMapping represents a normalized directory-to-directory output mapping.
@@ -51,6 +54,8 @@ type fileMapping struct {
 	process  bool
 	set      map[string]any
 	strategy string
+	// noProvenance leaves the provenance header out of the file.
+	noProvenance bool
 }
 
 // resolveConfig holds configuration for ResolveFiles.
@@ -90,6 +95,12 @@ func WithIgnorePatterns(patterns []string) ResolveOption {
 //
 // Use WithIgnorePatterns to exclude specific files or directories from
 // the resolved output. This is typically loaded via LoadIgnorePatterns.
+//
+// Files whose mapping names no strategy get DefaultStrategy's.
+//
+// Remote molds are untrusted, so every resolved file is checked with
+// CheckMoldPath (no symlink may lead outside moldFS), and a destination
+// that is absolute or escapes the project with ".." is an error.
 func ResolveFiles(output any, moldFS fs.FS, opts ...ResolveOption) ([]ResolvedFile, error) {
 	cfg := resolveConfig{}
 	for _, opt := range opts {
@@ -118,10 +129,36 @@ func ResolveFiles(output any, moldFS fs.FS, opts ...ResolveOption) ([]ResolvedFi
 	if len(cfg.ignorePatterns) > 0 {
 		resolved = filterIgnored(resolved, cfg.ignorePatterns)
 	}
+	for i, rf := range resolved {
+		if err := CheckMoldPath(moldFS, rf.SrcPath); err != nil {
+			return nil, err
+		}
+		if err := checkDestPath(rf.SrcPath, rf.DestPath); err != nil {
+			return nil, err
+		}
+		resolved[i].DestPath = NormalizeDest(rf.DestPath)
+	}
+	for i := range resolved {
+		if resolved[i].Strategy == "" {
+			resolved[i].Strategy = DefaultStrategy(resolved[i].DestPath)
+		}
+	}
 
 	return resolved, nil
 }
 
+// DefaultStrategy returns the strategy a destination is written with when
+// its output mapping names none. AGENTS.md is shared by every tool and mold
+// that writes agent instructions, and usually carries the project's own, so
+// a mold's content goes into its own section ("append") rather than
+// replacing the file. Anything else is replaced ("").
+func DefaultStrategy(dest string) string {
+	if path.Base(dest) == "AGENTS.md" {
+		return "append"
+	}
+	return ""
+}
+
 // resolveIdentity walks all top-level directories and root-level files
 // (excluding reserved ones) and returns files with identity mapping
 // (src = dest, process = true).
@@ -243,31 +280,48 @@ func parseStringOutput(parent string, moldFS fs.FS) ([]dirMapping, []fileMapping
 	return dirs, files, nil
 }
 
-// parseMapOutput handles the map form of output.
+// parseMapOutput handles the map form of output. Keys are source
+// directories, files, or glob patterns (see expandGlobMapping).
 func parseMapOutput(m map[string]any, moldFS fs.FS) ([]dirMapping, []fileMapping, error) {
 	var dirs []dirMapping
 	var files []fileMapping
+	mapped := make(map[string]bool, len(m))
 
 	for src, val := range m {
-		isDir := isDirectory(moldFS, src)
-
+		mapped[src] = true
 		targets, err := parseOutputValue(val)
 		if err != nil {
 			return nil, nil, fmt.Errorf("output key %q: %w", src, err)
 		}
 
+		if isOutputGlob(src) && !pathExists(moldFS, src) {
+			globFiles, err := expandGlobMapping(moldFS, src, targets)
+			if err != nil {
+				return nil, nil, fmt.Errorf("output key %q: %w", src, err)
+			}
+			for _, f := range globFiles {
+				mapped[f.src] = true
+			}
+			files = append(files, globFiles...)
+			continue
+		}
+
+		isDir := isDirectory(moldFS, src)
 		for _, target := range targets {
 			if isDir {
 				dirs = append(dirs, dirMapping{src: src, target: target})
-			} else {
-				files = append(files, fileMapping{
-					src:      src,
-					dest:     target.Dest,
-					process:  target.ShouldProcess(),
-					set:      target.Set,
-					strategy: target.Strategy,
-				})
+				continue
 			}
+			if len(target.Exclude) > 0 {
+				return nil, nil, fmt.Errorf("output key %q: exclude applies to directories and patterns, not a single file", src)
+			}
+			dest := target.Dest
+			if isDestTemplate(dest) {
+				if dest, err = renderDest(dest, src, path.Base(src)); err != nil {
+					return nil, nil, fmt.Errorf("output key %q: %w", src, err)
+				}
+			}
+			files = append(files, newFileMapping(src, dest, target))
 		}
 	}
 
@@ -276,10 +330,6 @@ func parseMapOutput(m map[string]any, moldFS fs.FS) ([]dirMapping, []fileMapping
 	if err != nil {
 		return nil, nil, err
 	}
-	mapped := make(map[string]bool, len(m))
-	for k := range m {
-		mapped[k] = true
-	}
 	for _, f := range rootFiles {
 		if !mapped[f] {
 			files = append(files, fileMapping{
@@ -297,6 +347,53 @@ func parseMapOutput(m map[string]any, moldFS fs.FS) ([]dirMapping, []fileMapping
 	return dirs, files, nil
 }
 
+// expandGlobMapping maps each file a glob key matches to every target.
+// Paths are taken relative to the pattern's base directory, so
+// `commands/**/*.md: .claude/commands` keeps subdirectories under
+// .claude/commands; exclude patterns match those relative paths.
+func expandGlobMapping(moldFS fs.FS, pattern string, targets []OutputTarget) ([]fileMapping, error) {
+	matches, err := expandOutputGlob(moldFS, pattern)
+	if err != nil {
+		return nil, err
+	}
+	base := globBase(pattern)
+	var files []fileMapping
+	for _, target := range targets {
+		bySrc := make(map[string]string) // dest → src, to catch renames that collide
+		for _, p := range matches {
+			rel := p
+			if base != "." {
+				rel = strings.TrimPrefix(p, base+"/")
+			}
+			if shouldIgnore(rel, target.Exclude) {
+				continue
+			}
+			dest, err := renderDest(target.Dest, p, rel)
+			if err != nil {
+				return nil, err
+			}
+			if other, dup := bySrc[dest]; dup {
+				return nil, fmt.Errorf("%s and %s both map to %s", other, p, dest)
+			}
+			bySrc[dest] = p
+			files = append(files, newFileMapping(p, dest, target))
+		}
+	}
+	return files, nil
+}
+
+// newFileMapping maps the mold file src to dest with target's options.
+func newFileMapping(src, dest string, target OutputTarget) fileMapping {
+	return fileMapping{
+		src:          src,
+		dest:         dest,
+		process:      target.ShouldProcess(),
+		set:          target.Set,
+		strategy:     target.Strategy,
+		noProvenance: target.Provenance != nil && !*target.Provenance,
+	}
+}
+
 // parseOutputValue normalizes a single output value into one or more targets.
 //
 // Accepted forms:
@@ -382,6 +479,23 @@ func parseTargetMap(v map[string]any) (OutputTarget, error) {
 		}
 		t.From = f
 	}
+	if prov, ok := v["provenance"]; ok {
+		b, ok := prov.(bool)
+		if !ok {
+			return t, fmt.Errorf("provenance must be a boolean")
+		}
+		t.Provenance = &b
+	}
+	if exclude, ok := v["exclude"]; ok {
+		patterns, err := stringList(exclude)
+		if err != nil {
+			return t, fmt.Errorf("exclude %w", err)
+		}
+		if err := validateExclude(patterns); err != nil {
+			return t, err
+		}
+		t.Exclude = patterns
+	}
 	return t, nil
 }
 
@@ -397,6 +511,7 @@ func resolveFromMappings(dirs []dirMapping, files []fileMapping, moldFS fs.FS) (
 
 	// Walk each mapped directory.
 	for _, dm := range dirs {
+		renamed := make(map[string]string) // dest → src, to catch renames that collide
 		err := fs.WalkDir(moldFS, dm.src, func(p string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
@@ -409,28 +524,43 @@ func resolveFromMappings(dirs []dirMapping, files []fileMapping, moldFS fs.FS) (
 			if overrides, ok := fileOverrides[p]; ok {
 				for _, fo := range overrides {
 					resolved = append(resolved, ResolvedFile{
-						SrcPath:  p,
-						DestPath: fo.dest,
-						Process:  fo.process,
-						Set:      fo.set,
-						Strategy: fo.strategy,
+						SrcPath:      p,
+						DestPath:     fo.dest,
+						Process:      fo.process,
+						Set:          fo.set,
+						Strategy:     fo.strategy,
+						NoProvenance: fo.noProvenance,
 					})
 				}
 				delete(fileOverrides, p) // consumed
 				return nil
 			}
 
-			// Apply directory mapping: replace source prefix with dest prefix.
+			// Apply directory mapping: replace source prefix with dest prefix,
+			// or render the dest template.
 			rel := strings.TrimPrefix(p, dm.src)
 			rel = strings.TrimPrefix(rel, "/")
-			destPath := path.Join(dm.target.Dest, rel)
+			if shouldIgnore(rel, dm.target.Exclude) {
+				return nil
+			}
+			destPath, err := renderDest(dm.target.Dest, p, rel)
+			if err != nil {
+				return err
+			}
+			if isDestTemplate(dm.target.Dest) {
+				if other, dup := renamed[destPath]; dup {
+					return fmt.Errorf("%s and %s both map to %s", other, p, destPath)
+				}
+				renamed[destPath] = p
+			}
 
 			resolved = append(resolved, ResolvedFile{
-				SrcPath:  p,
-				DestPath: destPath,
-				Process:  dm.target.ShouldProcess(),
-				Set:      dm.target.Set,
-				Strategy: dm.target.Strategy,
+				SrcPath:      p,
+				DestPath:     destPath,
+				Process:      dm.target.ShouldProcess(),
+				Set:          dm.target.Set,
+				Strategy:     dm.target.Strategy,
+				NoProvenance: dm.target.Provenance != nil && !*dm.target.Provenance,
 			})
 			return nil
 		})
@@ -447,11 +577,12 @@ func resolveFromMappings(dirs []dirMapping, files []fileMapping, moldFS fs.FS) (
 		}
 		for _, f := range overrides {
 			resolved = append(resolved, ResolvedFile{
-				SrcPath:  f.src,
-				DestPath: f.dest,
-				Process:  f.process,
-				Set:      f.set,
-				Strategy: f.strategy,
+				SrcPath:      f.src,
+				DestPath:     f.dest,
+				Process:      f.process,
+				Set:          f.set,
+				Strategy:     f.strategy,
+				NoProvenance: f.noProvenance,
 			})
 		}
 	}
@@ -464,6 +595,31 @@ func resolveFromMappings(dirs []dirMapping, files []fileMapping, moldFS fs.FS) (
 	return resolved, nil
 }
 
+// pathExists reports whether name exists in the given filesystem.
+func pathExists(moldFS fs.FS, name string) bool {
+	_, err := fs.Stat(moldFS, name)
+	return err == nil
+}
+
+// stringList converts a YAML string or list of strings.
+func stringList(v any) ([]string, error) {
+	switch val := v.(type) {
+	case string:
+		return []string{val}, nil
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of strings")
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("must be a string or list of strings")
+}
+
 // isDirectory checks if a path is a directory in the given filesystem.
 func isDirectory(moldFS fs.FS, name string) bool {
 	info, err := fs.Stat(moldFS, name)
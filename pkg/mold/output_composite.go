@@ -168,14 +168,26 @@ func ResolveFilesWithOreSources(output any, moldFS fs.FS, oreSources []OreSource
 		if info.IsDir() {
 			return nil, fmt.Errorf("output entry for %q references %q in ore %q, but that is a directory — `from:` must point to a single file", fe.dest, oreRelPath, ns)
 		}
+		if err := CheckMoldPath(src.FS, oreRelPath); err != nil {
+			return nil, fmt.Errorf("output entry for %q in ore %q: %w", fe.dest, ns, err)
+		}
+		if err := checkDestPath(fe.from, fe.dest); err != nil {
+			return nil, err
+		}
+		dest := NormalizeDest(fe.dest)
+		strategy := fe.strategy
+		if strategy == "" {
+			strategy = DefaultStrategy(dest)
+		}
 		resolved = append(resolved, ResolvedFile{
-			SrcPath:  oreRelPath,
-			DestPath: fe.dest,
-			Process:  fe.process,
-			Set:      fe.set,
-			Strategy: fe.strategy,
-			SrcFS:    src.FS,
-			Origin:   ns,
+			SrcPath:      oreRelPath,
+			DestPath:     dest,
+			Process:      fe.process,
+			Set:          fe.set,
+			Strategy:     strategy,
+			SrcFS:        src.FS,
+			Origin:       ns,
+			NoProvenance: fe.noProvenance,
 		})
 	}
 
@@ -280,6 +292,8 @@ type fromEntry struct {
 	process  bool
 	set      map[string]any
 	strategy string
+	// noProvenance leaves the provenance header out of the file.
+	noProvenance bool
 }
 
 // parseFromEntryFields extracts a `from: ore/<ns>/<path>` entry from a map.
@@ -301,12 +315,14 @@ func parseFromEntryFields(m map[string]any, defaultDest string) (fromEntry, bool
 	}
 	set, _ := m["set"].(map[string]any)
 	strategy, _ := m["strategy"].(string)
+	provenance, ok := m["provenance"].(bool)
 	return fromEntry{
-		from:     from,
-		dest:     dest,
-		process:  process,
-		set:      set,
-		strategy: strategy,
+		from:         from,
+		dest:         dest,
+		process:      process,
+		set:          set,
+		strategy:     strategy,
+		noProvenance: ok && !provenance,
 	}, true
 }
 
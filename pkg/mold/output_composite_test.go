@@ -1,8 +1,11 @@
 package mold
 
 import (
+	"bytes"
 	"fmt"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -83,6 +86,28 @@ func TestResolveFilesWithOreSources_ConsumerFromSelector(t *testing.T) {
 	}
 }
 
+func TestResolveFilesWithOreSources_FromSelectorDestIsChecked(t *testing.T) {
+	moldFS := fstest.MapFS{"mold.yaml": &fstest.MapFile{Data: []byte("name: c\n")}}
+	oreFS := fstest.MapFS{"blanks/AGENTS.md": &fstest.MapFile{Data: []byte("# from ore\n")}}
+	sources := []OreSource{{Namespace: "agent_targets", FS: oreFS}}
+
+	output := map[string]any{
+		"AGENTS.md": map[string]any{"from": "ore/agent_targets/blanks/AGENTS.md", "dest": `docs\AGENTS.md`},
+	}
+	resolved, err := ResolveFilesWithOreSources(output, moldFS, sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0].DestPath != "docs/AGENTS.md" {
+		t.Fatalf("resolved = %+v, want dest docs/AGENTS.md", resolved)
+	}
+
+	output["AGENTS.md"] = map[string]any{"from": "ore/agent_targets/blanks/AGENTS.md", "dest": "../AGENTS.md"}
+	if _, err := ResolveFilesWithOreSources(output, moldFS, sources); err == nil || !strings.Contains(err.Error(), "outside the project") {
+		t.Errorf("a from: dest outside the project should be rejected, got %v", err)
+	}
+}
+
 func TestResolveFilesWithOreSources_BackwardCompat_MoldOnly(t *testing.T) {
 	moldFS := fstest.MapFS{
 		"mold.yaml":         &fstest.MapFile{Data: []byte("name: c\n")},
@@ -332,3 +357,60 @@ func TestResolveFilesWithOreSources_FromSelectorRejectsDirectoryTarget(t *testin
 		t.Errorf("error should mention directory, got: %v", err)
 	}
 }
+
+func TestResolveFilesWithOreSources_FromSelectorRejectsSymlinkEscape(t *testing.T) {
+	moldFS := fstest.MapFS{"mold.yaml": &fstest.MapFile{Data: []byte("name: c\n")}}
+	oreDir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, []byte("token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(oreDir, "blanks"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(oreDir, "blanks", "AGENTS.md"), []byte("# ore\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(oreDir, "blanks", "leak.md")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if err := os.Symlink("AGENTS.md", filepath.Join(oreDir, "blanks", "alias.md")); err != nil {
+		t.Fatal(err)
+	}
+	sources := []OreSource{{Namespace: "at", FS: os.DirFS(oreDir)}}
+
+	output := map[string]any{"leak": map[string]any{"from": "ore/at/blanks/leak.md", "dest": "LEAK.md"}}
+	_, err := ResolveFilesWithOreSources(output, moldFS, sources)
+	if err == nil || !strings.Contains(err.Error(), "outside the mold") {
+		t.Fatalf("symlink leaving the ore: err = %v", err)
+	}
+
+	// A symlink that stays inside the ore is fine.
+	output = map[string]any{"alias": map[string]any{"from": "ore/at/blanks/alias.md", "dest": "AGENTS.md"}}
+	if _, err := ResolveFilesWithOreSources(output, moldFS, sources); err != nil {
+		t.Fatalf("symlink inside the ore: %v", err)
+	}
+}
+
+func TestResolveFilesWithOreSources_FromSelectorLargeBinaryFiltered(t *testing.T) {
+	moldFS := fstest.MapFS{"mold.yaml": &fstest.MapFile{Data: []byte("name: c\n")}}
+	oreFS := fstest.MapFS{"assets/model.bin": &fstest.MapFile{Data: bytes.Repeat([]byte{0x7f, 0}, MaxBinaryFileSize)}}
+	output := map[string]any{"model": map[string]any{"from": "ore/at/assets/model.bin", "dest": "model.bin"}}
+	resolved, err := ResolveFilesWithOreSources(output, moldFS, []OreSource{{Namespace: "at", FS: oreFS}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is read from the ore, not the mold, and skipped unless listed.
+	kept, skipped, err := FilterLargeBinaries(resolved, moldFS, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 0 || len(skipped) != 1 {
+		t.Errorf("kept %v, skipped %v", kept, skipped)
+	}
+	kept, _, err = FilterLargeBinaries(resolved, moldFS, []string{"*.bin"})
+	if err != nil || len(kept) != 1 {
+		t.Errorf("listed binary: kept %v, err %v", kept, err)
+	}
+}
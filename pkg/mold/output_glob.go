@@ -0,0 +1,162 @@
+package mold
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// isOutputGlob reports whether an output key is a glob pattern
+// (commands/pr-*.md, skills/**/SKILL.md) rather than a literal path.
+func isOutputGlob(key string) bool {
+	return strings.ContainsAny(key, "*?[")
+}
+
+// matchOutputGlob matches a slash-separated path against a glob pattern.
+// Segments follow path.Match; a "**" segment matches any number of
+// directories, including none.
+func matchOutputGlob(pattern, name string) (bool, error) {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if ok, err := matchGlobSegments(pattern[1:], name[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if !ok || err != nil {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// globBase returns the directory part of a glob pattern before its first
+// wildcard segment: "commands/pr-*.md" → "commands", "*.md" → ".".
+func globBase(pattern string) string {
+	segs := strings.Split(pattern, "/")
+	for i, s := range segs {
+		if isOutputGlob(s) {
+			if i == 0 {
+				return "."
+			}
+			return path.Join(segs[:i]...)
+		}
+	}
+	return path.Dir(pattern)
+}
+
+// expandOutputGlob returns the mold files a glob output key matches, sorted.
+// Reserved root files, reserved directories and dot-prefixed paths are never
+// matched. A pattern matching nothing is an error, so a typo fails temper
+// instead of silently casting less.
+func expandOutputGlob(moldFS fs.FS, pattern string) ([]string, error) {
+	for _, seg := range strings.Split(pattern, "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	var matches []string
+	root := globBase(pattern)
+	err := fs.WalkDir(moldFS, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || (path.Dir(p) == "." && reservedDirs[name])) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || (path.Dir(p) == "." && reservedRootFiles[p]) {
+			return nil
+		}
+		if ok, _ := matchOutputGlob(pattern, p); ok {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("pattern matches no files")
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// isDestTemplate reports whether an output dest renames each file with a
+// template such as ".claude/commands/{{ .name }}.md".
+func isDestTemplate(dest string) bool {
+	return strings.Contains(dest, "{{")
+}
+
+// renderDest returns the destination of the mold file src, whose path
+// relative to the mapped source (a directory or a glob's base) is rel.
+// A dest template is rendered with:
+//
+//	.name  file name without its extension ("pr-review")
+//	.ext   extension, with the dot (".md")
+//	.file  file name ("pr-review.md")
+//	.dir   directory of rel ("" at the top)
+//	.path  rel itself ("review/pr-review.md")
+//
+// Any other dest is a directory the file keeps its relative path under.
+func renderDest(dest, src, rel string) (string, error) {
+	if !isDestTemplate(dest) {
+		return path.Join(dest, rel), nil
+	}
+	tmpl, err := template.New("dest").Option("missingkey=error").Parse(dest)
+	if err != nil {
+		return "", fmt.Errorf("dest template %q: %w", dest, err)
+	}
+	file := path.Base(src)
+	ext := path.Ext(file)
+	dir := path.Dir(rel)
+	if dir == "." {
+		dir = ""
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, map[string]string{
+		"name": strings.TrimSuffix(file, ext),
+		"ext":  ext,
+		"file": file,
+		"dir":  dir,
+		"path": rel,
+	}); err != nil {
+		return "", fmt.Errorf("dest template %q: %w", dest, err)
+	}
+	out := path.Clean(b.String())
+	if out == "." || path.IsAbs(out) || out == ".." || strings.HasPrefix(out, "../") {
+		return "", fmt.Errorf("dest template %q renders %q for %s, outside the project", dest, b.String(), src)
+	}
+	return out, nil
+}
+
+// validateExclude checks an exclude: list's patterns.
+func validateExclude(patterns []string) error {
+	for _, p := range patterns {
+		if p == "" {
+			return fmt.Errorf("exclude patterns must not be empty")
+		}
+		if _, err := path.Match(strings.TrimSuffix(strings.TrimSuffix(p, "/**"), "/"), ""); err != nil {
+			return fmt.Errorf("exclude pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
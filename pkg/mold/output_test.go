@@ -1,6 +1,7 @@
 package mold
 
 import (
+	"strings"
 	"testing"
 	"testing/fstest"
 )
@@ -264,6 +265,67 @@ func TestResolveFiles_RootFiles_Identity(t *testing.T) {
 	}
 }
 
+func TestResolveFiles_AgentsMDDefaultsToAppend(t *testing.T) {
+	moldFS := fstest.MapFS{
+		"AGENTS.md":          &fstest.MapFile{Data: []byte("# Agent instructions")},
+		"docs/AGENTS.md":     &fstest.MapFile{Data: []byte("# Docs agents")},
+		"commands/AGENTS.md": &fstest.MapFile{Data: []byte("# Not a default")},
+		"commands/review.md": &fstest.MapFile{Data: []byte("review")},
+	}
+	output := map[string]any{
+		"AGENTS.md":          "AGENTS.md",
+		"docs":               "docs",
+		"commands/AGENTS.md": map[string]any{"dest": ".claude/commands/AGENTS.md", "strategy": "replace"},
+		"commands/review.md": ".claude/commands/review.md",
+	}
+	resolved, err := ResolveFiles(output, moldFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"AGENTS.md":                  "append",
+		"docs/AGENTS.md":             "append",
+		".claude/commands/AGENTS.md": "replace",
+		".claude/commands/review.md": "",
+	}
+	for _, rf := range resolved {
+		if rf.Strategy != want[rf.DestPath] {
+			t.Errorf("%s: strategy %q, want %q", rf.DestPath, rf.Strategy, want[rf.DestPath])
+		}
+	}
+}
+
+func TestResolveFiles_ProvenanceOptOut(t *testing.T) {
+	moldFS := fstest.MapFS{
+		"commands/deploy.md": &fstest.MapFile{Data: []byte("deploy")},
+		"commands/review.md": &fstest.MapFile{Data: []byte("review")},
+		"rules/style.md":     &fstest.MapFile{Data: []byte("style")},
+	}
+	output := map[string]any{
+		"commands":           ".claude/commands",
+		"commands/review.md": map[string]any{"dest": ".claude/commands/review.md", "provenance": false},
+		"rules":              map[string]any{"dest": ".claude/rules", "provenance": false},
+	}
+	resolved, err := ResolveFiles(output, moldFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		".claude/commands/deploy.md": false,
+		".claude/commands/review.md": true,
+		".claude/rules/style.md":     true,
+	}
+	for _, rf := range resolved {
+		if rf.NoProvenance != want[rf.DestPath] {
+			t.Errorf("%s: NoProvenance = %v, want %v", rf.DestPath, rf.NoProvenance, want[rf.DestPath])
+		}
+	}
+
+	if _, err := ResolveFiles(map[string]any{"rules": map[string]any{"dest": "x", "provenance": "no"}}, moldFS); err == nil {
+		t.Error("expected an error for a non-boolean provenance")
+	}
+}
+
 func TestResolveFiles_RootFiles_StringOutput(t *testing.T) {
 	moldFS := fstest.MapFS{
 		"commands/hello.md": &fstest.MapFile{Data: []byte("hello")},
@@ -760,3 +822,77 @@ func TestResolveFiles_StrategyPropagation_DirOutput(t *testing.T) {
 		t.Errorf("want strategy=merge, got %q", resolved[0].Strategy)
 	}
 }
+
+func TestResolveFiles_GlobExcludeAndRename(t *testing.T) {
+	moldFS := fstest.MapFS{
+		"commands/pr-review.md":        &fstest.MapFile{Data: []byte("r")},
+		"commands/pr-open.md":          &fstest.MapFile{Data: []byte("o")},
+		"commands/pr-draft.md":         &fstest.MapFile{Data: []byte("d")},
+		"commands/deploy.md":           &fstest.MapFile{Data: []byte("x")},
+		"skills/lint/SKILL.md":         &fstest.MapFile{Data: []byte("l")},
+		"skills/lint/ref/rules.md":     &fstest.MapFile{Data: []byte("r")},
+		"skills/format/SKILL.md":       &fstest.MapFile{Data: []byte("f")},
+		"rules/go.md":                  &fstest.MapFile{Data: []byte("g")},
+		"rules/README.md":              &fstest.MapFile{Data: []byte("n")},
+		"rules/drafts/experimental.md": &fstest.MapFile{Data: []byte("e")},
+	}
+	output := map[string]any{
+		"commands/pr-*.md":   map[string]any{"dest": ".claude/commands", "exclude": []any{"*-draft.md"}},
+		"skills/**/SKILL.md": ".claude/skills",
+		"rules":              map[string]any{"dest": ".cursor/rules/{{ .name }}.mdc", "exclude": []any{"README.md", "drafts/"}},
+		"commands/deploy.md": map[string]any{"dest": ".claude/commands/ship{{ .ext }}"},
+	}
+
+	resolved, err := ResolveFiles(output, moldFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, rf := range resolved {
+		got[rf.SrcPath] = rf.DestPath
+	}
+	want := map[string]string{
+		"commands/pr-review.md":  ".claude/commands/pr-review.md",
+		"commands/pr-open.md":    ".claude/commands/pr-open.md",
+		"commands/deploy.md":     ".claude/commands/ship.md",
+		"skills/lint/SKILL.md":   ".claude/skills/lint/SKILL.md",
+		"skills/format/SKILL.md": ".claude/skills/format/SKILL.md",
+		"rules/go.md":            ".cursor/rules/go.mdc",
+	}
+	if len(got) != len(want) {
+		t.Errorf("resolved %v, want %v", got, want)
+	}
+	for src, dest := range want {
+		if got[src] != dest {
+			t.Errorf("%s → %q, want %q", src, got[src], dest)
+		}
+	}
+}
+
+func TestResolveFiles_GlobErrors(t *testing.T) {
+	moldFS := fstest.MapFS{
+		"commands/a/run.md": &fstest.MapFile{Data: []byte("a")},
+		"commands/b/run.md": &fstest.MapFile{Data: []byte("b")},
+	}
+	tests := []struct {
+		name   string
+		output map[string]any
+		want   string
+	}{
+		{"no matches", map[string]any{"commands/pr-*.md": ".claude/commands"}, "pattern matches no files"},
+		{"bad pattern", map[string]any{"commands/[.md": ".claude/commands"}, "invalid pattern"},
+		{"rename collision", map[string]any{"commands/**/*.md": ".claude/{{ .file }}"}, "both map to .claude/run.md"},
+		{"unknown template var", map[string]any{"commands": ".claude/{{ .title }}"}, "dest template"},
+		{"escapes project", map[string]any{"commands": "../{{ .path }}"}, "outside the project"},
+		{"exclude on a file", map[string]any{"commands/a/run.md": map[string]any{"dest": "x.md", "exclude": "*.md"}}, "not a single file"},
+		{"exclude type", map[string]any{"commands": map[string]any{"dest": "x", "exclude": 3}}, "exclude must be a string or list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOutputSources(tt.output, moldFS)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package mold
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Provenance is the header comment cast writes into a rendered blank: the
+// mold that wrote it, the mold's version and the sha256 of the content as
+// rendered (without the header). A file whose content no longer hashes to
+// SHA256 has been edited since it was cast.
+//
+//	<!-- ailloy: mold=team-tools version=1.2.0 sha256=9f86d0… -->
+//	# ailloy: mold=team-tools version=1.2.0 sha256=9f86d0…
+type Provenance struct {
+	Mold    string
+	Version string
+	SHA256  string
+}
+
+const provenanceTag = "ailloy:"
+
+// provenanceStyle returns the comment delimiters for dest's file type, or
+// ok=false when the type has no comment syntax a header could safely use
+// (JSON, unknown extensions).
+func provenanceStyle(dest string) (open, closing string, ok bool) {
+	switch strings.ToLower(path.Ext(dest)) {
+	case ".md", ".mdc", ".mdx", ".markdown":
+		return "<!-- ", " -->", true
+	case ".yaml", ".yml", ".toml", ".sh", ".bash", ".zsh", ".py", ".rb":
+		return "# ", "", true
+	}
+	return "", "", false
+}
+
+// provenanceOffset returns where the header line goes in content: after
+// YAML frontmatter in markdown (agents and tools read the frontmatter from
+// the first line) and after a #! line in scripts, else at the top.
+func provenanceOffset(open string, content []byte) int {
+	if open == "<!-- " {
+		if !bytes.HasPrefix(content, []byte("---\n")) {
+			return 0
+		}
+		rest := content[4:]
+		for off := 4; len(rest) > 0; {
+			line, after, found := bytes.Cut(rest, []byte("\n"))
+			if string(bytes.TrimRight(line, "\r")) == "---" {
+				if !found {
+					return -1 // frontmatter closes at EOF; nowhere to put the header
+				}
+				return off + len(line) + 1
+			}
+			off += len(line) + 1
+			rest = after
+		}
+		return 0
+	}
+	if bytes.HasPrefix(content, []byte("#!")) {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			return -1
+		}
+		return i + 1
+	}
+	return 0
+}
+
+// StampProvenance returns content with a provenance header for p in the
+// comment syntax of dest's file type. An empty p.SHA256 is computed from
+// content; a caller stamping content that already carries local edits
+// passes the hash of the mold's render instead. Files without a usable
+// comment syntax are returned unchanged.
+func StampProvenance(dest string, content []byte, p Provenance) []byte {
+	open, closing, ok := provenanceStyle(dest)
+	if !ok || p.Mold == "" {
+		return content
+	}
+	off := provenanceOffset(open, content)
+	if off < 0 {
+		return content
+	}
+	if p.SHA256 == "" {
+		sum := sha256.Sum256(content)
+		p.SHA256 = hex.EncodeToString(sum[:])
+	}
+	fields := "mold=" + p.Mold
+	if p.Version != "" {
+		fields += " version=" + p.Version
+	}
+	fields += " sha256=" + p.SHA256
+	header := fmt.Sprintf("%s%s %s%s\n", open, provenanceTag, fields, closing)
+
+	out := make([]byte, 0, len(content)+len(header))
+	out = append(out, content[:off]...)
+	out = append(out, header...)
+	return append(out, content[off:]...)
+}
+
+// ReadProvenance finds the provenance header StampProvenance writes into a
+// file at dest and returns it with the content it was stamped onto (data
+// without the header line). ok is false when data has no header.
+func ReadProvenance(dest string, data []byte) (p Provenance, body []byte, ok bool) {
+	open, closing, styled := provenanceStyle(dest)
+	if !styled {
+		return Provenance{}, nil, false
+	}
+	off := provenanceOffset(open, data)
+	if off < 0 {
+		return Provenance{}, nil, false
+	}
+	line, _, found := bytes.Cut(data[off:], []byte("\n"))
+	if !found {
+		return Provenance{}, nil, false
+	}
+	text, matched := strings.CutPrefix(string(line), open+provenanceTag+" ")
+	if !matched {
+		return Provenance{}, nil, false
+	}
+	if text, matched = strings.CutSuffix(text, closing); !matched {
+		return Provenance{}, nil, false
+	}
+	for _, field := range strings.Fields(text) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "mold":
+			p.Mold = value
+		case "version":
+			p.Version = value
+		case "sha256":
+			p.SHA256 = value
+		}
+	}
+	if p.Mold == "" || p.SHA256 == "" {
+		return Provenance{}, nil, false
+	}
+	body = make([]byte, 0, len(data)-len(line)-1)
+	body = append(body, data[:off]...)
+	body = append(body, data[off+len(line)+1:]...)
+	return p, body, true
+}
+
+// Modified reports whether body — as returned by ReadProvenance — differs
+// from the content p was stamped onto.
+func (p Provenance) Modified(body []byte) bool {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]) != p.SHA256
+}
@@ -0,0 +1,55 @@
+package mold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStampProvenance_RoundTrip(t *testing.T) {
+	prov := Provenance{Mold: "tools", Version: "1.2.0"}
+	for _, tc := range []struct {
+		dest, content, header string
+	}{
+		{"cmd.md", "# deploy\n", "<!-- ailloy: mold=tools version=1.2.0 sha256="},
+		{"cmd.md", "---\ndescription: Deploy\n---\n# deploy\n", "---\ndescription: Deploy\n---\n<!-- ailloy: mold=tools"},
+		{"ci.yml", "on: push\n", "# ailloy: mold=tools version=1.2.0 sha256="},
+		{"hook.sh", "#!/bin/sh\necho hi\n", "#!/bin/sh\n# ailloy: mold=tools"},
+	} {
+		stamped := StampProvenance(tc.dest, []byte(tc.content), prov)
+		if !strings.HasPrefix(string(stamped), tc.header) {
+			t.Errorf("%s: stamped = %q, want it to start with %q", tc.dest, stamped, tc.header)
+		}
+		p, body, ok := ReadProvenance(tc.dest, stamped)
+		if !ok || p.Mold != "tools" || p.Version != "1.2.0" || string(body) != tc.content || p.Modified(body) {
+			t.Errorf("%s: read back %+v %q %v", tc.dest, p, body, ok)
+		}
+	}
+}
+
+func TestStampProvenance_Unstamped(t *testing.T) {
+	prov := Provenance{Mold: "tools"}
+	for dest, content := range map[string]string{
+		"settings.json": `{"a": 1}`,
+		"Makefile":      "all:\n",
+		"cmd.md":        "---\nunterminated: true\n---",
+	} {
+		if got := StampProvenance(dest, []byte(content), prov); string(got) != content {
+			t.Errorf("%s: stamped %q, want it unchanged", dest, got)
+		}
+	}
+	if _, _, ok := ReadProvenance("cmd.md", []byte("# deploy\n")); ok {
+		t.Error("read a header from a file without one")
+	}
+	if _, _, ok := ReadProvenance("cmd.md", []byte("<!-- ailloy:mold=tools:start -->\nx\n<!-- ailloy:mold=tools:end -->\n")); ok {
+		t.Error("mistook an append section for a header")
+	}
+}
+
+func TestProvenance_Modified(t *testing.T) {
+	stamped := StampProvenance("cmd.md", []byte("# deploy\n"), Provenance{Mold: "tools"})
+	edited := strings.Replace(string(stamped), "# deploy", "# deploy to prod", 1)
+	p, body, ok := ReadProvenance("cmd.md", []byte(edited))
+	if !ok || !p.Modified(body) {
+		t.Errorf("edit not detected: %+v %q %v", p, body, ok)
+	}
+}
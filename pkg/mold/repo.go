@@ -0,0 +1,124 @@
+package mold
+
+import (
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// Flux keys a cast and anneal pre-fill from the project's git remote.
+const (
+	RepoHostKey          = "scm.host"
+	RepoOrganizationKey  = "project.organization"
+	RepoNameKey          = "repo.name"
+	RepoDefaultBranchKey = "repo.default_branch"
+)
+
+// RepoInfo describes the repository a project is cloned from.
+type RepoInfo struct {
+	Host  string // e.g. github.com
+	Owner string // user, organization, or GitLab group path
+	Name  string
+	// DefaultBranch is the branch origin/HEAD points at; empty when the
+	// clone doesn't record it.
+	DefaultBranch string
+}
+
+// DetectRepo inspects the origin remote of the git repository containing
+// dir. The bool is false when dir isn't in a repository, has no origin, or
+// origin's URL doesn't name a host/owner/repo.
+func DetectRepo(dir string) (RepoInfo, bool) {
+	git := func(args ...string) (string, error) {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output() // #nosec G204 -- fixed git subcommands
+		return strings.TrimSpace(string(out)), err
+	}
+	// The configured URL, not `remote get-url`, so insteadOf rewrites
+	// don't leak into flux.
+	remote, err := git("config", "--get", "remote.origin.url")
+	if err != nil || remote == "" {
+		return RepoInfo{}, false
+	}
+	info, ok := ParseRemoteURL(remote)
+	if !ok {
+		return RepoInfo{}, false
+	}
+	if head, err := git("symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		info.DefaultBranch = strings.TrimPrefix(head, "origin/")
+	}
+	return info, true
+}
+
+// ParseRemoteURL splits a git remote URL — https://host/owner/repo.git,
+// git@host:owner/repo.git, or ssh://git@host:22/owner/repo — into host,
+// owner, and repository name. Credentials and ports are dropped; a GitLab
+// subgroup path is kept whole as the owner.
+func ParseRemoteURL(remote string) (RepoInfo, bool) {
+	remote = strings.TrimSpace(remote)
+	var host, repoPath string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return RepoInfo{}, false
+		}
+		host, repoPath = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		h, p, ok := strings.Cut(remote, ":")
+		if !ok {
+			return RepoInfo{}, false
+		}
+		if _, after, found := strings.Cut(h, "@"); found {
+			h = after
+		}
+		host, repoPath = h, p
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	i := strings.LastIndex(repoPath, "/")
+	if host == "" || i <= 0 || i == len(repoPath)-1 {
+		return RepoInfo{}, false
+	}
+	return RepoInfo{Host: strings.ToLower(host), Owner: repoPath[:i], Name: repoPath[i+1:]}, true
+}
+
+// Flux returns info as flux values keyed by the Repo*Key constants; empty
+// fields are left out.
+func (info RepoInfo) Flux() map[string]string {
+	values := make(map[string]string, 4)
+	for key, v := range map[string]string{
+		RepoHostKey:          info.Host,
+		RepoOrganizationKey:  info.Owner,
+		RepoNameKey:          info.Name,
+		RepoDefaultBranchKey: info.DefaultBranch,
+	} {
+		if v != "" {
+			values[key] = v
+		}
+	}
+	return values
+}
+
+// ApplyRepoDefaults fills info's flux values into flux as defaults: a key is
+// set only when flux leaves it unset or empty and schema declares no default
+// for it. The input map is not mutated.
+func ApplyRepoDefaults(schema []FluxVar, flux map[string]any, info RepoInfo) map[string]any {
+	result := deepCopyMap(flux)
+	declared := make(map[string]string, len(schema))
+	for _, fv := range schema {
+		declared[fv.Name] = fv.Default
+	}
+	for key, v := range info.Flux() {
+		if declared[key] != "" {
+			continue
+		}
+		if current, ok := GetNestedAny(result, key); ok && current != nil && current != "" {
+			continue
+		}
+		if parent, _, _ := strings.Cut(key, "."); result[parent] != nil {
+			if _, isMap := result[parent].(map[string]any); !isMap {
+				continue // the mold uses the parent key as a scalar
+			}
+		}
+		SetNestedValue(result, key, v)
+	}
+	return result
+}
@@ -0,0 +1,261 @@
+package mold
+
+import (
+	"bytes"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// inferSkipFiles are the mold files that configure it rather than render.
+var inferSkipFiles = map[string]bool{
+	"mold.yaml":        true,
+	"ore.yaml":         true,
+	"flux.yaml":        true,
+	"flux.schema.yaml": true,
+	SecretFluxFile:     true,
+	"ailloy.lock":      true,
+}
+
+// inferSkipRoots are top-level flux keys that aren't the mold's own
+// variables: the output mapping, ore namespaces and the cast context.
+var inferSkipRoots = map[string]bool{
+	"output":   true,
+	"ore":      true,
+	ContextKey: true,
+}
+
+// InferSchemaDescription is the description stub InferFluxSchema gives
+// every variable.
+const InferSchemaDescription = "TODO: describe this variable"
+
+// InferFluxSchema drafts a flux schema for a mold that has none: one entry
+// per flux.yaml value, typed from the value and defaulting to it, plus one
+// entry per variable the templates read that flux.yaml doesn't set. Those
+// are typed from how they are used — a range target is a list, a bare if
+// condition a bool — and are otherwise strings; all but the bools are
+// required. Entries are
+// sorted by name. The result is a starting point to review, not a final
+// schema.
+func InferFluxSchema(fsys fs.FS) ([]FluxVar, error) {
+	flux, err := LoadFluxFile(fsys, "flux.yaml")
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]FluxVar{}
+	inferFluxLeaves(flux, "", byName)
+
+	refs, err := templateFluxRefs(fsys)
+	if err != nil {
+		return nil, err
+	}
+	for name, typ := range refs {
+		if _, ok := byName[name]; ok || inferSkipRoots[strings.SplitN(name, ".", 2)[0]] || coveredByFlux(flux, name) {
+			continue
+		}
+		if typ == "" {
+			typ = "string"
+		}
+		// A flag that is only tested reads as false when unset.
+		byName[name] = FluxVar{Name: name, Description: InferSchemaDescription, Type: typ, Required: typ != "bool"}
+	}
+
+	schema := make([]FluxVar, 0, len(byName))
+	for _, fv := range byName {
+		schema = append(schema, fv)
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Name < schema[j].Name })
+	return schema, nil
+}
+
+// inferFluxLeaves adds an entry for every leaf of m.
+func inferFluxLeaves(m map[string]any, prefix string, out map[string]FluxVar) {
+	for key, val := range m {
+		name := prefix + key
+		if prefix == "" && inferSkipRoots[key] {
+			continue
+		}
+		fv := FluxVar{Name: name, Description: InferSchemaDescription, Type: "string"}
+		switch v := val.(type) {
+		case map[string]any:
+			if len(v) > 0 {
+				inferFluxLeaves(v, name+".", out)
+				continue
+			}
+		case bool:
+			fv.Type, fv.Default = "bool", strconv.FormatBool(v)
+		case int, int64, uint64:
+			fv.Type, fv.Default = "int", strconv.FormatInt(toInt64(v), 10)
+		case float64:
+			if v == math.Trunc(v) {
+				fv.Type, fv.Default = "int", strconv.FormatInt(int64(v), 10)
+			} else {
+				fv.Default = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		case []any:
+			// flux.yaml keeps the list itself; a string default would
+			// only shadow it.
+			fv.Type = "list"
+		case string:
+			fv.Default = v
+		}
+		out[name] = fv
+	}
+}
+
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case uint64:
+		return int64(min(n, math.MaxInt64)) // #nosec G115 -- clamped
+	}
+	return 0
+}
+
+// coveredByFlux reports whether name reads part of a flux value: a map
+// above flux.yaml's leaves, or a field of a leaf value.
+func coveredByFlux(flux map[string]any, name string) bool {
+	var cur any = flux
+	for _, seg := range strings.Split(name, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return true
+		}
+		if cur, ok = m[seg]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// templateFluxRefs collects the flux paths the mold's templates read, each
+// with the type its use implies ("" when it implies none). Hidden files,
+// mold configuration, binaries and files that don't parse as templates
+// are skipped; temper reports the last.
+func templateFluxRefs(fsys fs.FS) (map[string]string, error) {
+	refs := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || (path.Dir(p) == "." && inferSkipFiles[p]) {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 || !bytes.Contains(data, []byte("{{")) {
+			return nil
+		}
+		tree := parse.New(p)
+		tree.Mode = parse.SkipFuncCheck
+		trees := map[string]*parse.Tree{}
+		if _, err := tree.Parse(preProcessTemplate(string(data)), "", "", trees); err != nil {
+			return nil //nolint:nilerr // unparsable blanks are temper's to report
+		}
+		if root := trees[p]; root != nil && root.Root != nil {
+			collectFluxRefs(root.Root, true, refs)
+		}
+		return nil
+	})
+	return refs, err
+}
+
+// collectFluxRefs walks node recording the flux paths it reads. rooted is
+// false inside range and with, where dot no longer is the flux root; only
+// $-rooted paths count there.
+func collectFluxRefs(node parse.Node, rooted bool, refs map[string]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			collectFluxRefs(c, rooted, refs)
+		}
+	case *parse.ActionNode:
+		collectPipeRefs(n.Pipe, rooted, "", refs)
+	case *parse.TemplateNode:
+		collectPipeRefs(n.Pipe, rooted, "", refs)
+	case *parse.IfNode:
+		collectPipeRefs(n.Pipe, rooted, "bool", refs)
+		collectFluxRefs(n.List, rooted, refs)
+		collectFluxRefs(n.ElseList, rooted, refs)
+	case *parse.RangeNode:
+		collectPipeRefs(n.Pipe, rooted, "list", refs)
+		collectFluxRefs(n.List, false, refs)
+		collectFluxRefs(n.ElseList, rooted, refs)
+	case *parse.WithNode:
+		collectPipeRefs(n.Pipe, rooted, "", refs)
+		collectFluxRefs(n.List, false, refs)
+		collectFluxRefs(n.ElseList, rooted, refs)
+	}
+}
+
+// collectPipeRefs records the paths pipe reads. hint types a path that is
+// the whole pipeline (`if .enabled`, `if not .enabled`, `range .items`).
+func collectPipeRefs(pipe *parse.PipeNode, rooted bool, hint string, refs map[string]string) {
+	if pipe == nil {
+		return
+	}
+	sole := len(pipe.Cmds) == 1
+	for _, cmd := range pipe.Cmds {
+		args := cmd.Args
+		if sole && hint == "bool" && len(args) == 2 {
+			if id, ok := args[0].(*parse.IdentifierNode); ok && id.Ident == "not" {
+				args = args[1:]
+			}
+		}
+		for _, arg := range args {
+			typ := ""
+			if sole && len(args) == 1 {
+				typ = hint
+			}
+			collectArgRefs(arg, rooted, typ, refs)
+		}
+	}
+}
+
+func collectArgRefs(arg parse.Node, rooted bool, typ string, refs map[string]string) {
+	var ident []string
+	switch a := arg.(type) {
+	case *parse.FieldNode:
+		if rooted {
+			ident = a.Ident
+		}
+	case *parse.VariableNode:
+		if len(a.Ident) > 1 && a.Ident[0] == "$" {
+			ident = a.Ident[1:]
+		}
+	case *parse.PipeNode:
+		collectPipeRefs(a, rooted, "", refs)
+	case *parse.ChainNode:
+		collectArgRefs(a.Node, rooted, "", refs)
+	}
+	if len(ident) == 0 {
+		return
+	}
+	name := strings.Join(ident, ".")
+	prev, seen := refs[name]
+	switch {
+	case !seen, typ == "list":
+		refs[name] = typ
+	case typ == "" && prev == "bool":
+		// Tested and also printed: an optional string, not a flag.
+		refs[name] = ""
+	}
+}
@@ -0,0 +1,41 @@
+package mold
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInferFluxSchema(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mold.yaml": {Data: []byte("name: demo\n")},
+		"flux.yaml": {Data: []byte("team: core\nenabled: true\nlimits:\n  max: 3\nitems: [a, b]\noutput:\n  commands: .claude/commands\n")},
+		"commands/hi.md": {Data: []byte(`{{ if .org }}Org: {{ .org }}{{ end }}
+{{team}} {{ .limits.max }} {{ .limits }}
+{{ if not .beta }}x{{ end }}
+{{ range .repos }}{{ .name }} {{ $.owner }}{{ end }}
+{{ range .items }}{{ .label }}{{ end }}
+{{ ._ailloy.version }} {{ .ore.status.enabled }} ${{ github.ref }}
+`)},
+		"commands/broken.md": {Data: []byte("{{ if .never }}")},
+		".github/x.md":       {Data: []byte("{{ .hidden }}")},
+	}
+	got, err := InferFluxSchema(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := InferSchemaDescription
+	want := []FluxVar{
+		{Name: "beta", Description: d, Type: "bool"},
+		{Name: "enabled", Description: d, Type: "bool", Default: "true"},
+		{Name: "items", Description: d, Type: "list"},
+		{Name: "limits.max", Description: d, Type: "int", Default: "3"},
+		{Name: "org", Description: d, Type: "string", Required: true},
+		{Name: "owner", Description: d, Type: "string", Required: true},
+		{Name: "repos", Description: d, Type: "list", Required: true},
+		{Name: "team", Description: d, Type: "string", Default: "core"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InferFluxSchema =\n%+v\nwant\n%+v", got, want)
+	}
+}
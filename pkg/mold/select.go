@@ -0,0 +1,130 @@
+package mold
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Selection narrows a cast to part of a mold. Entries are either the name of
+// one of the mold's components or a path pattern in .ailloyignore syntax
+// ("commands/", "commands/pr-*", "*.md"), matched against a file's path in
+// the mold and its destination.
+type Selection struct {
+	Only    []string // keep only files matching one of these; empty keeps all
+	Exclude []string // drop files matching any of these
+}
+
+// Empty reports whether s keeps every file.
+func (s Selection) Empty() bool {
+	return len(s.Only) == 0 && len(s.Exclude) == 0
+}
+
+// Select returns the files s keeps, in their original order. components are
+// the mold's named groups of patterns (Mold.Components). An --only entry that
+// matches no file is an error, so a typo doesn't quietly cast nothing.
+func (s Selection) Select(files []ResolvedFile, components map[string][]string) ([]ResolvedFile, error) {
+	if s.Empty() {
+		return files, nil
+	}
+	only := expandSelection(s.Only, components)
+	exclude := expandSelection(s.Exclude, components)
+
+	matched := map[string]bool{} // --only entries that matched a file
+	var kept []ResolvedFile
+	for _, f := range files {
+		if selectionMatches(f, exclude) {
+			continue
+		}
+		if len(only) > 0 {
+			hit := false
+			for _, p := range only {
+				if p.matches(f) {
+					matched[p.entry], hit = true, true
+				}
+			}
+			if !hit {
+				continue
+			}
+		}
+		kept = append(kept, f)
+	}
+
+	var unmatched []string
+	for _, e := range s.Only {
+		if !matched[e] && !slices.Contains(unmatched, e) {
+			unmatched = append(unmatched, e)
+		}
+	}
+	if len(unmatched) > 0 {
+		msg := fmt.Sprintf("--only %s selects no blanks", strings.Join(unmatched, ", "))
+		if names := componentNames(components); len(names) > 0 {
+			msg += fmt.Sprintf(" (components: %s)", strings.Join(names, ", "))
+		}
+		return nil, errors.New(msg)
+	}
+	return kept, nil
+}
+
+// selectionPattern is one path pattern of a selection, with the entry it
+// came from for error messages.
+type selectionPattern struct {
+	pattern, entry string
+}
+
+func expandSelection(entries []string, components map[string][]string) []selectionPattern {
+	var out []selectionPattern
+	for _, e := range entries {
+		patterns, ok := components[e]
+		if !ok {
+			patterns = []string{e}
+		}
+		for _, p := range patterns {
+			out = append(out, selectionPattern{pattern: strings.TrimPrefix(p, "./"), entry: e})
+		}
+	}
+	return out
+}
+
+func (p selectionPattern) matches(f ResolvedFile) bool {
+	return matchIgnorePattern(f.SrcPath, p.pattern) || matchIgnorePattern(f.DestPath, p.pattern)
+}
+
+func selectionMatches(f ResolvedFile, patterns []selectionPattern) bool {
+	for _, p := range patterns {
+		if p.matches(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func componentNames(components map[string][]string) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// componentProblems returns a message for every component without patterns
+// and every malformed pattern.
+func componentProblems(components map[string][]string) []string {
+	var errs []string
+	for _, name := range componentNames(components) {
+		patterns := components[name]
+		if len(patterns) == 0 {
+			errs = append(errs, fmt.Sprintf("components.%s: at least one path pattern is required", name))
+		}
+		for i, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil || p == "" {
+				errs = append(errs, fmt.Sprintf("components.%s[%d]: %q is not a valid path pattern", name, i, p))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,74 @@
+package mold
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSelection_Select(t *testing.T) {
+	files := []ResolvedFile{
+		{SrcPath: "commands/pr-create.md", DestPath: ".claude/commands/pr-create.md"},
+		{SrcPath: "commands/pr-review.md", DestPath: ".claude/commands/pr-review.md"},
+		{SrcPath: "commands/deploy.md", DestPath: ".claude/commands/deploy.md"},
+		{SrcPath: "skills/triage/SKILL.md", DestPath: ".claude/skills/triage/SKILL.md"},
+		{SrcPath: "agents/reviewer.md", DestPath: ".claude/agents/reviewer.md"},
+	}
+	components := map[string][]string{
+		"review": {"commands/pr-review.md", "agents/"},
+	}
+	dests := func(fs []ResolvedFile) []string {
+		var out []string
+		for _, f := range fs {
+			out = append(out, f.SrcPath)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		sel  Selection
+		want []string
+	}{
+		{"empty keeps all", Selection{}, dests(files)},
+		{"only glob", Selection{Only: []string{"commands/pr-*"}}, []string{"commands/pr-create.md", "commands/pr-review.md"}},
+		{"only destination dir", Selection{Only: []string{".claude/skills/"}}, []string{"skills/triage/SKILL.md"}},
+		{"only component", Selection{Only: []string{"review"}}, []string{"commands/pr-review.md", "agents/reviewer.md"}},
+		{"exclude", Selection{Exclude: []string{"commands/"}}, []string{"skills/triage/SKILL.md", "agents/reviewer.md"}},
+		{"only and exclude", Selection{Only: []string{"commands/"}, Exclude: []string{"review"}}, []string{"commands/pr-create.md", "commands/deploy.md"}},
+		{"overlapping only entries", Selection{Only: []string{"commands/", "commands/pr-*"}}, []string{"commands/pr-create.md", "commands/pr-review.md", "commands/deploy.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.sel.Select(files, components)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(dests(got), tt.want) {
+				t.Errorf("Select = %v, want %v", dests(got), tt.want)
+			}
+		})
+	}
+
+	_, err := Selection{Only: []string{"commands/", "comands/pr-*"}}.Select(files, components)
+	if err == nil || !strings.Contains(err.Error(), "comands/pr-*") || !strings.Contains(err.Error(), "components: review") {
+		t.Errorf("err = %v, want the unmatched pattern and the components listed", err)
+	}
+}
+
+func TestValidateMold_Components(t *testing.T) {
+	m := &Mold{APIVersion: "v1", Kind: "mold", Name: "m", Version: "1.0.0",
+		Components: map[string][]string{"ok": {"commands/"}, "empty": nil, "bad": {"commands/[pr"}}}
+	err := ValidateMold(m)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"components.empty", "components.bad[0]"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "components.ok") {
+		t.Errorf("error %q flags a valid component", err)
+	}
+}
@@ -241,7 +241,8 @@ kind: mold
 name: test-mold
 version: 1.0.0
 `)},
-		"commands/inc.md": &fstest.MapFile{Data: []byte(`{{ingot "header"}}`)},
+		"commands/inc.md":  &fstest.MapFile{Data: []byte(`{{ingot "header"}}`)},
+		"commands/args.md": &fstest.MapFile{Data: []byte(`{{ingot "header" level="strict" owner=.project.owner}}`)},
 	}
 
 	result := Temper(fsys)
@@ -752,3 +753,146 @@ license: bogus
 		t.Errorf("expected File=ore.yaml, got %q", d.File)
 	}
 }
+
+func TestTemper_Deprecations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte(`
+apiVersion: v1
+kind: mold
+name: test-mold
+version: 1.3.0
+deprecations:
+  deprecated:
+    "not a version": old
+`)},
+		"DEPRECATIONS.yaml": &fstest.MapFile{Data: []byte(`
+yanked:
+  "1.2.1": renders a broken workflow
+  "<1.0.0": ""
+`)},
+	}
+
+	result := Temper(fsys)
+
+	var got []string
+	for _, d := range result.Errors() {
+		got = append(got, d.File+": "+d.Message)
+	}
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{
+		`mold.yaml: deprecations.deprecated["not a version"] is not a valid version or constraint`,
+		`DEPRECATIONS.yaml: yanked["<1.0.0"] needs a reason`,
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, `"1.2.1"`) {
+		t.Errorf("valid rule reported:\n%s", joined)
+	}
+}
+
+func TestTemper_UnknownManifestFields(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte(`apiVersion: v1
+kind: mold
+name: test-mold
+version: 1.0.0
+fluxx:
+  - name: org
+x-team: platform
+`)},
+		"flux.schema.yaml": &fstest.MapFile{Data: []byte(`- name: org
+  type: string
+  requird: true
+`)},
+	}
+
+	result := Temper(fsys)
+	if result.HasErrors() {
+		t.Fatalf("unknown fields should only warn, got errors: %v", result.Errors())
+	}
+
+	var got []string
+	for _, d := range result.Warnings() {
+		if d.Rule == "unknown-field" {
+			got = append(got, d.File+": "+d.Message)
+		}
+	}
+	want := []string{
+		"mold.yaml: unknown field `fluxx` on line 5 (did you mean `flux`?)",
+		"flux.schema.yaml: unknown field `[0].requird` on line 3 (did you mean `required`?)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unknown-field diagnostics =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTemper_DeprecatedFlux(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte(`apiVersion: v1
+kind: mold
+name: test-mold
+version: 1.0.0
+`)},
+		"flux.schema.yaml": &fstest.MapFile{Data: []byte(`- name: team
+  type: string
+  renamed_from: org
+- name: legacy
+  type: bool
+  deprecated: true
+- name: region
+  type: string
+  renamed_from: team
+`)},
+		"flux.yaml": &fstest.MapFile{Data: []byte("output:\n  a.md: a.md\n  b.md: b.md\n")},
+		"a.md":      &fstest.MapFile{Data: []byte("{{ .org }} {{ if .legacy }}x{{ end }}\n")},
+		"b.md":      &fstest.MapFile{Data: []byte("{{ .team }}\n")},
+	}
+
+	result := Temper(fsys)
+
+	var got []string
+	for _, d := range result.Diagnostics {
+		if strings.Contains(d.Message, "deprecated") || strings.Contains(d.Message, "renamed") {
+			got = append(got, d.Severity.String()+" "+d.File+": "+d.Message)
+		}
+	}
+	want := []string{
+		`error flux.schema.yaml: flux[2] "region": renamed_from "team" is still declared as a flux variable`,
+		`warning a.md: reads deprecated flux "legacy"`,
+		`warning a.md: reads flux "org", which was renamed to "team"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagnostics =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTemper_RequiredIfParse(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte(`apiVersion: v1
+kind: mold
+name: test-mold
+version: 1.0.0
+`)},
+		"flux.schema.yaml": &fstest.MapFile{Data: []byte(`- name: board
+  type: string
+  required_if: ore.status.enabled
+- name: token
+  type: string
+  required_if: "{{ eq .scm.provider "
+`)},
+	}
+
+	result := Temper(fsys)
+
+	var got []string
+	for _, d := range result.Errors() {
+		if strings.Contains(d.Message, "required_if") {
+			got = append(got, d.Message)
+		}
+	}
+	if len(got) != 1 || !strings.HasPrefix(got[0], `flux[1] "token": required_if:`) {
+		t.Errorf("required_if errors = %v, want one for token", got)
+	}
+}
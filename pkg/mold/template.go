@@ -3,9 +3,11 @@ package mold
 import (
 	"bytes"
 	"fmt"
+	"io/fs"
 	"log"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -24,7 +26,45 @@ var (
 // bareVarPattern matches simple {{variable}} or {{dotted.path}} references
 // that lack the Go template dot prefix. The preprocessor adds the dot automatically
 // so template authors can use the simpler {{variable}} syntax.
-var bareVarPattern = regexp.MustCompile(`\{\{(-?\s*)([a-zA-Z]\w*(?:\.\w+)*)(\s*-?)\}\}`)
+var bareVarPattern = regexp.MustCompile(`\{\{(-?\s*)([a-zA-Z_]\w*(?:\.\w+)*)(\s*-?)\}\}`)
+
+// ingotActionPattern matches a whole {{ingot ...}} action, skipping over
+// quoted string literals so a "}}" inside an argument value doesn't end it.
+var ingotActionPattern = regexp.MustCompile("\\{\\{-?\\s*ingot\\s(?:\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`|[^}\"`])*\\}\\}")
+
+// actionsExprPattern matches a GitHub Actions expression (${{ github.ref }},
+// ${{ format('{0}', x) }}), including any template actions nested inside it
+// (${{ secrets.{{secret_name}} }}).
+var actionsExprPattern = regexp.MustCompile(`\$\{\{((?:[^{}]|\{[^{}]*\}|\{\{[^{}]*\}\})*)\}\}`)
+
+// nestedActionPattern matches a template action inside an Actions expression.
+var nestedActionPattern = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+var braceEscaper = strings.NewReplacer("{", `\x7b`, "}", `\x7d`)
+
+// quoteActionsExpr rewrites an Actions expression into template actions
+// that print it verbatim. Literal text becomes string-literal actions with
+// braces hex-escaped, so the rewrites in preProcessTemplate can't match
+// inside them; nested template actions are kept and still render.
+func quoteActionsExpr(expr string) string {
+	literal := func(text string) string {
+		if text == "" {
+			return ""
+		}
+		return "{{" + braceEscaper.Replace(strconv.Quote(text)) + "}}"
+	}
+	inner := expr[len("${{") : len(expr)-len("}}")]
+	var b strings.Builder
+	b.WriteString(literal("${{"))
+	last := 0
+	for _, loc := range nestedActionPattern.FindAllStringIndex(inner, -1) {
+		b.WriteString(literal(inner[last:loc[0]]))
+		b.WriteString(inner[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(literal(inner[last:] + "}}"))
+	return b.String()
+}
 
 // goTemplateKeywords are tokens that must not be dot-prefixed by the preprocessor.
 var goTemplateKeywords = map[string]bool{
@@ -47,7 +87,9 @@ type templateConfig struct {
 	logger        *log.Logger
 }
 
-// WithIngotResolver enables the {{ingot "name"}} template function.
+// WithIngotResolver enables the {{ingot "name"}} template function, including
+// its named-argument form {{ingot "name" key=value}}. The resolver's FS (the
+// mold filesystem) is also where {{file "path"}} reads from.
 func WithIngotResolver(r *IngotResolver) TemplateOption {
 	return func(c *templateConfig) {
 		c.ingotResolver = r
@@ -67,7 +109,12 @@ func WithLogger(logger *log.Logger) TemplateOption {
 // preProcessTemplate normalises simple {{variable}} references into
 // Go template {{.variable}} syntax. This lets template authors use the
 // shorter form while keeping full Go template compatibility.
+//
+// GitHub Actions expressions (${{ ... }}) are wrapped in string-literal
+// actions first so processed workflow blanks keep them verbatim.
 func preProcessTemplate(content string) string {
+	content = actionsExprPattern.ReplaceAllStringFunc(content, quoteActionsExpr)
+	content = ingotActionPattern.ReplaceAllStringFunc(content, rewriteIngotArgs)
 	return bareVarPattern.ReplaceAllStringFunc(content, func(match string) string {
 		sub := bareVarPattern.FindStringSubmatch(match)
 		if len(sub) < 4 {
@@ -84,6 +131,60 @@ func preProcessTemplate(content string) string {
 	})
 }
 
+// rewriteIngotArgs turns the named arguments of an {{ingot}} action into the
+// key/value pairs Go templates can parse: {{ingot "x" level="strict"}}
+// becomes {{ingot "x" "level" "strict"}}. Text inside string literals is
+// left untouched.
+func rewriteIngotArgs(action string) string {
+	var b strings.Builder
+	for i := 0; i < len(action); i++ {
+		c := action[i]
+		if c == '"' || c == '`' {
+			end := i + 1
+			for end < len(action) && action[end] != c {
+				if c == '"' && action[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(action) {
+				end = len(action) - 1
+			}
+			b.WriteString(action[i : end+1])
+			i = end
+			continue
+		}
+		if isIdentStart(c) && i > 0 && isSpace(action[i-1]) {
+			end := i
+			for end < len(action) && isIdentChar(action[end]) {
+				end++
+			}
+			if end < len(action) && action[end] == '=' && (end+1 >= len(action) || action[end+1] != '=') {
+				b.WriteString(`"` + action[i:end] + `" `)
+				i = end
+				continue
+			}
+			b.WriteString(action[i:end])
+			i = end - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c == '.' || (c >= '0' && c <= '9')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
 // baseFuncMap returns the template function map shared by ProcessTemplate and
 // template validation (Temper). Keeping it in one place ensures that the
 // validator accepts every function the renderer does.
@@ -111,6 +212,8 @@ func baseFuncMap() template.FuncMap {
 //   - Go template conditionals: {{if .ore.status.enabled}}...{{end}}
 //   - Go template ranges: {{range $k, $v := .ore.status.options}}...{{end}}
 //   - Nested data access: {{.ore.status.options.ready.id}}
+//   - File inclusion: {{file "snippets/example.json"}} (see readTemplateFile)
+//   - Cast context: {{._ailloy.version}}, {{._ailloy.mold.name}} (see CastContext)
 //
 // Simple {{variable}} references are automatically normalised to {{.variable}}
 // before parsing. Unresolved variables produce logged warnings and resolve to
@@ -128,11 +231,19 @@ func ProcessTemplate(content string, flux map[string]any, opts ...TemplateOption
 	content = preProcessTemplate(content)
 
 	data := BuildTemplateData(flux)
+	// Renders outside a cast (forge, temper) carry no cast context; an
+	// empty one still resolves {{._ailloy.*}}.
+	if _, ok := data[ContextKey].(map[string]any); !ok {
+		data[ContextKey] = CastContext{}.Data()
+	}
 
 	funcMap := baseFuncMap()
+	var moldFS fs.FS
 	if cfg.ingotResolver != nil {
 		funcMap["ingot"] = cfg.ingotResolver.Resolve
+		moldFS = cfg.ingotResolver.FS
 	}
+	funcMap["file"] = templateFileFunc(moldFS)
 
 	tmpl, err := template.New("").Funcs(funcMap).Option("missingkey=zero").Parse(content)
 	if err != nil {
@@ -0,0 +1,75 @@
+package mold
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MaxTemplateFileSize is the largest file the {{file}} template function
+// inlines. Snippets, samples and JSON payloads fit comfortably; anything
+// bigger most likely belongs in the output mapping instead.
+const MaxTemplateFileSize = 1 << 20
+
+// fileActionPattern captures the literal path of a {{file "path"}} action.
+var fileActionPattern = regexp.MustCompile(`\{\{-?\s*file\s+"([^"]+)"`)
+
+// templateFileFunc returns the {{file "path"}} template function, which
+// inlines a file from the mold filesystem verbatim (it is not rendered).
+// A nil fsys yields a function that reports there is nothing to read from.
+func templateFileFunc(fsys fs.FS) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if fsys == nil {
+			return "", fmt.Errorf("file %q: no mold filesystem to read from", name)
+		}
+		return readTemplateFile(fsys, name)
+	}
+}
+
+// readTemplateFile reads name from fsys for {{file}}. The path must be
+// relative to the mold root and stay inside it: absolute paths, backslashes,
+// ".." escapes and symlinks (which could point anywhere on the host) are
+// rejected, as are files over MaxTemplateFileSize.
+func readTemplateFile(fsys fs.FS, name string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(name, "./"))
+	if name == "" || strings.Contains(name, `\`) || !fs.ValidPath(clean) || clean == "." {
+		return "", fmt.Errorf("file %q: path must be relative to the mold root and stay inside it", name)
+	}
+
+	// Check every component so a symlinked directory can't lead outside.
+	parts := strings.Split(clean, "/")
+	for i := range parts {
+		info, err := fs.Lstat(fsys, strings.Join(parts[:i+1], "/"))
+		if err != nil {
+			return "", fmt.Errorf("file %q: %w", name, err)
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("file %q: symlinks are not followed", name)
+		}
+		if i == len(parts)-1 {
+			if !info.Mode().IsRegular() {
+				return "", fmt.Errorf("file %q: not a regular file", name)
+			}
+			if info.Size() > MaxTemplateFileSize {
+				return "", fmt.Errorf("file %q is %d bytes; {{file}} inlines at most %d", name, info.Size(), MaxTemplateFileSize)
+			}
+		}
+	}
+
+	f, err := fsys.Open(clean)
+	if err != nil {
+		return "", fmt.Errorf("file %q: %w", name, err)
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(io.LimitReader(f, MaxTemplateFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("file %q: %w", name, err)
+	}
+	if len(data) > MaxTemplateFileSize {
+		return "", fmt.Errorf("file %q is larger than %d bytes, the most {{file}} inlines", name, MaxTemplateFileSize)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,81 @@
+package mold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProcessTemplate_File(t *testing.T) {
+	fsys := fstest.MapFS{
+		"snippets/example.json": &fstest.MapFile{Data: []byte(`{"org": "{{org}}"}`)},
+	}
+	resolver := NewIngotResolverWithFS(fsys, nil, nil)
+
+	got, err := ProcessTemplate("payload: {{file \"snippets/example.json\"}}", map[string]any{"org": "acme"},
+		WithIngotResolver(resolver))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file is inlined verbatim, not rendered.
+	if want := `payload: {"org": "{{org}}"}`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestProcessTemplate_FileRejectsEscapes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"big.txt": &fstest.MapFile{Data: make([]byte, MaxTemplateFileSize+1)},
+		"dir/a":   &fstest.MapFile{Data: []byte("a")},
+	}
+	resolver := NewIngotResolverWithFS(fsys, nil, nil)
+	for name, want := range map[string]string{
+		"../secret":   "stay inside",
+		"/etc/passwd": "stay inside",
+		`dir\a`:       "stay inside",
+		"missing.txt": "not exist",
+		"dir":         "not a regular file",
+		"big.txt":     "at most",
+	} {
+		_, err := ProcessTemplate(`{{file "`+strings.ReplaceAll(name, `\`, `\\`)+`"}}`, nil, WithIngotResolver(resolver))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("file %q: err = %v, want %q", name, err, want)
+		}
+	}
+
+	if _, err := ProcessTemplate(`{{file "dir/a"}}`, nil); err == nil || !strings.Contains(err.Error(), "no mold filesystem") {
+		t.Errorf("without a resolver: err = %v", err)
+	}
+}
+
+func TestProcessTemplate_FileRejectsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skip("symlinks unavailable:", err)
+	}
+	resolver := NewIngotResolverWithFS(os.DirFS(dir), nil, nil)
+	_, err := ProcessTemplate(`{{file "link"}}`, nil, WithIngotResolver(resolver))
+	if err == nil || !strings.Contains(err.Error(), "symlinks") {
+		t.Errorf("err = %v, want symlink rejection", err)
+	}
+}
+
+func TestTemper_FileReferences(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: m\nversion: 1.0.0\n")},
+		"flux.yaml": &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n")},
+		"commands/hello.md": &fstest.MapFile{Data: []byte(
+			"{{file \"snippets/ok.md\"}}\n{{file \"snippets/missing.md\"}}\n")},
+		"snippets/ok.md": &fstest.MapFile{Data: []byte("ok")},
+	}
+	errs := Temper(fsys).Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "snippets/missing.md") {
+		t.Errorf("errors = %v, want only the missing snippet", errs)
+	}
+}
@@ -530,6 +530,51 @@ func TestProcessTemplate_WithoutIngotResolver(t *testing.T) {
 	}
 }
 
+func TestPreProcessTemplate_IngotNamedArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single arg", `{{ingot "checklist" level="strict"}}`, `{{ingot "checklist" "level" "strict"}}`},
+		{"multiple args", `{{- ingot "x" a="1" b=.project.name -}}`, `{{- ingot "x" "a" "1" "b" .project.name -}}`},
+		{"dotted key", `{{ingot "x" review.level="strict"}}`, `{{ingot "x" "review.level" "strict"}}`},
+		{"equals inside literal untouched", `{{ingot "x" title="a=b c=d"}}`, `{{ingot "x" "title" "a=b c=d"}}`},
+		{"no args untouched", `{{ingot "x"}}`, `{{ingot "x"}}`},
+		{"non-ingot action untouched", `{{if eq .a "b=c"}}y{{end}}`, `{{if eq .a "b=c"}}y{{end}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := preProcessTemplate(tt.input); got != tt.want {
+				t.Errorf("preProcessTemplate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessTemplate_IngotNamedArgs(t *testing.T) {
+	dir := t.TempDir()
+	ingotDir := filepath.Join(dir, "ingots")
+	if err := os.MkdirAll(ingotDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ingotDir, "checklist.md"), []byte("{{level}} review for {{org}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	flux := map[string]any{"org": "Acme", "level": "relaxed"}
+	r := NewIngotResolver([]string{dir}, flux)
+	content := `{{ingot "checklist" level="strict"}} / {{ingot "checklist"}} / {{level}}`
+	result, err := ProcessTemplate(content, flux, WithIngotResolver(r))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "strict review for Acme / relaxed review for Acme / relaxed"
+	if result != want {
+		t.Errorf("expected %q, got %q", want, result)
+	}
+}
+
 // --- has function tests ---
 
 func TestProcessTemplate_HasFunctionStringSlice(t *testing.T) {
@@ -625,3 +670,25 @@ func TestProcessTemplate_NilEverything(t *testing.T) {
 		t.Errorf("expected 'plain text', got %q", result)
 	}
 }
+
+func TestProcessTemplate_PreservesActionsExpressions(t *testing.T) {
+	input := "model: {{model}}\n" +
+		"key: ${{ secrets.ANTHROPIC_API_KEY }}\n" +
+		"if: ${{ github.event_name == 'push' }}\n" +
+		"title: ${{ format('{0}-{1}', github.ref, github.sha) }}\n" +
+		"secret: ${{ secrets.{{secret_name}} }}\n"
+	flux := map[string]any{"model": "claude-sonnet-4-5", "secret_name": "MY_KEY"}
+
+	got, err := ProcessTemplate(input, flux)
+	if err != nil {
+		t.Fatalf("ProcessTemplate: %v", err)
+	}
+	want := "model: claude-sonnet-4-5\n" +
+		"key: ${{ secrets.ANTHROPIC_API_KEY }}\n" +
+		"if: ${{ github.event_name == 'push' }}\n" +
+		"title: ${{ format('{0}-{1}', github.ref, github.sha) }}\n" +
+		"secret: ${{ secrets.MY_KEY }}\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -2,12 +2,19 @@ package mold
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
+	"log"
+	"maps"
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"text/template"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/yamlcheck"
 )
 
 // semverRegex matches semver strings like "1.0.0", "0.2.0-beta.1", etc.
@@ -24,11 +31,13 @@ var snakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
 
 // validFluxTypes is the set of allowed types for flux variable declarations.
 var validFluxTypes = map[string]bool{
-	"string": true,
-	"bool":   true,
-	"int":    true,
-	"list":   true,
-	"select": true,
+	"string":      true,
+	"bool":        true,
+	"int":         true,
+	"list":        true,
+	"select":      true,
+	"multiselect": true,
+	"secret":      true,
 }
 
 // ValidateMold validates a Mold manifest for required fields and correct formats.
@@ -63,10 +72,10 @@ func ValidateMold(m *Mold) error {
 		if f.Type == "" {
 			errs = append(errs, fmt.Sprintf("flux[%d].type is required", i))
 		} else if !validFluxTypes[f.Type] {
-			errs = append(errs, fmt.Sprintf("flux[%d].type %q is not valid (allowed: string, bool, int, list, select)", i, f.Type))
+			errs = append(errs, fmt.Sprintf("flux[%d].type %q is not valid (allowed: string, bool, int, list, select, multiselect, secret)", i, f.Type))
 		}
-		if f.Type == "select" && len(f.Options) == 0 && f.Discover == nil {
-			errs = append(errs, fmt.Sprintf("flux[%d] %q: select type requires options or discover", i, f.Name))
+		if (f.Type == "select" || f.Type == "multiselect") && len(f.Options) == 0 && f.Discover == nil {
+			errs = append(errs, fmt.Sprintf("flux[%d] %q: %s type requires options or discover", i, f.Name, f.Type))
 		}
 		if f.Discover != nil && f.Discover.Command == "" {
 			errs = append(errs, fmt.Sprintf("flux[%d] %q: discover.command is required", i, f.Name))
@@ -74,6 +83,12 @@ func ValidateMold(m *Mold) error {
 		if f.Discover != nil && f.Discover.Prompt != "" && f.Discover.Prompt != "select" && f.Discover.Prompt != "input" {
 			errs = append(errs, fmt.Sprintf("flux[%d] %q: discover.prompt must be \"select\" or \"input\"", i, f.Name))
 		}
+		if err := validateFluxCondition(f.RequiredIf); err != nil {
+			errs = append(errs, fmt.Sprintf("flux[%d] %q: required_if: %v", i, f.Name, err))
+		}
+		if err := validateResolveSpec(f); err != nil {
+			errs = append(errs, fmt.Sprintf("flux[%d] %q: %v", i, f.Name, err))
+		}
 	}
 
 	for i, d := range m.Dependencies {
@@ -86,6 +101,9 @@ func ValidateMold(m *Mold) error {
 			errs = append(errs, fmt.Sprintf("dependencies[%d].version %q is not a valid version constraint", i, d.Version))
 		}
 	}
+	errs = append(errs, m.Deprecations.problems("deprecations.")...)
+	errs = append(errs, m.Hooks.problems()...)
+	errs = append(errs, componentProblems(m.Components)...)
 
 	if len(errs) > 0 {
 		return fmt.Errorf("mold validation failed:\n  - %s", strings.Join(errs, "\n  - "))
@@ -220,6 +238,37 @@ func temperLicense(fsys fs.FS, manifestFile, license string, result *TemperResul
 	}
 }
 
+// temperUnknownFields warns about every key in file that the manifest type v
+// does not define. Loading ignores such keys, so a typo (`fluxx:`) would
+// otherwise be dropped without a word; cast prints the same warning, so
+// temper does not fail a package that casts. Keys prefixed with x- are
+// custom metadata and allowed.
+func temperUnknownFields(fsys fs.FS, file string, v any, result *TemperResult) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return
+	}
+	unknown, err := yamlcheck.UnknownFields(data, v)
+	if err != nil {
+		return // reported by the loader
+	}
+	for _, f := range unknown {
+		msg := fmt.Sprintf("unknown field `%s` on line %d", f.Path, f.Line)
+		tip := "remove it, or prefix custom metadata keys with `" + yamlcheck.ExtensionPrefix + "`"
+		if f.Suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean `%s`?)", f.Suggestion)
+			tip = "rename it to `" + f.Suggestion + "`; unknown fields are ignored when the package is cast"
+		}
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			Message:  msg,
+			Tip:      tip,
+			File:     file,
+			Rule:     "unknown-field",
+		})
+	}
+}
+
 // temperOre validates an ore-directory package. Rules:
 //   - manifest fields valid (apiVersion, kind=ore, snake_case name, semver version)
 //   - flux.schema.yaml present and parseable
@@ -240,6 +289,7 @@ func temperOre(fsys fs.FS, result *TemperResult) {
 
 	result.Name = o.Name
 	result.Version = o.Version
+	temperUnknownFields(fsys, "ore.yaml", &Ore{}, result)
 
 	if verr := ValidateOre(o); verr != nil {
 		for _, line := range extractValidationErrors(verr) {
@@ -314,7 +364,21 @@ func temperOre(fsys fs.FS, result *TemperResult) {
 		} else if !validFluxTypes[f.Type] {
 			result.Diagnostics = append(result.Diagnostics, Diagnostic{
 				Severity: SeverityError,
-				Message:  fmt.Sprintf("flux[%d].type %q is not valid (allowed: string, bool, int, list, select)", i, f.Type),
+				Message:  fmt.Sprintf("flux[%d].type %q is not valid (allowed: string, bool, int, list, select, multiselect, secret)", i, f.Type),
+				File:     "flux.schema.yaml",
+			})
+		}
+		if err := validateFluxCondition(f.RequiredIf); err != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("flux[%d] %q: required_if: %v", i, f.Name, err),
+				File:     "flux.schema.yaml",
+			})
+		}
+		if err := validateResolveSpec(f); err != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("flux[%d] %q: %v", i, f.Name, err),
 				File:     "flux.schema.yaml",
 			})
 		}
@@ -399,7 +463,17 @@ func validateOreOutputBlock(fsys fs.FS, raw any, result *TemperResult) {
 				})
 			}
 		}
-		// Source key must exist in the ore filesystem.
+		// Source key must exist in the ore filesystem; a glob must match.
+		if isOutputGlob(src) && !pathExists(fsys, src) {
+			if _, err := expandOutputGlob(fsys, src); err != nil {
+				result.Diagnostics = append(result.Diagnostics, Diagnostic{
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("flux.yaml output[%q]: %v", src, err),
+					File:     "flux.yaml",
+				})
+			}
+			continue
+		}
 		if _, err := fs.Stat(fsys, src); err != nil {
 			result.Diagnostics = append(result.Diagnostics, Diagnostic{
 				Severity: SeverityError,
@@ -600,6 +674,7 @@ func temperMold(fsys fs.FS, result *TemperResult) {
 
 	result.Name = m.Name
 	result.Version = m.Version
+	temperUnknownFields(fsys, "mold.yaml", &Mold{}, result)
 
 	// Validate manifest fields
 	if err := ValidateMold(m); err != nil {
@@ -613,6 +688,9 @@ func temperMold(fsys fs.FS, result *TemperResult) {
 	}
 
 	temperLicense(fsys, "mold.yaml", m.License, result)
+	temperDeprecations(fsys, result)
+	temperHooks(fsys, m.Hooks, result)
+	temperSecretFlux(fsys, result)
 
 	// Validate output source references. Output can come from flux.yaml or
 	// from a top-level output: in mold.yaml; flux.yaml wins when both exist.
@@ -628,6 +706,17 @@ func temperMold(fsys fs.FS, result *TemperResult) {
 			File:     "flux.yaml",
 		})
 	}
+	for _, name := range OutputProfileNames(flux, m) {
+		if err := ValidateOutputSources(outputProfiles(flux, m)[name], fsys); err != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("profile %q: %v", name, err),
+				File:     "mold.yaml",
+			})
+		}
+	}
+
+	temperBinaries(fsys, flux["output"], m.AllowedBinaries(), result)
 
 	// Validate flux schema consistency
 	temperFluxSchema(fsys, m.Flux, result)
@@ -635,6 +724,146 @@ func temperMold(fsys fs.FS, result *TemperResult) {
 	// Validate template syntax only for output-manifest files
 	outputFiles := resolveOutputPaths(flux["output"], fsys)
 	validateTemplates(fsys, outputFiles, result)
+	temperDeprecatedFlux(fsys, m, outputFiles, result)
+
+	temperWorkflows(fsys, m, flux, result)
+}
+
+// temperDeprecations validates the mold's DEPRECATIONS.yaml, if it has one.
+func temperDeprecations(fsys fs.FS, result *TemperResult) {
+	d, err := LoadDeprecations(fsys, DeprecationsFile)
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  err.Error(),
+			File:     DeprecationsFile,
+		})
+		return
+	}
+	if d != nil {
+		temperUnknownFields(fsys, DeprecationsFile, &Deprecations{}, result)
+	}
+	for _, msg := range d.problems("") {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  msg,
+			File:     DeprecationsFile,
+		})
+	}
+}
+
+// temperHooks checks that every hook script mold.yaml declares is bundled
+// with the mold. Malformed paths are already reported by ValidateMold.
+func temperHooks(fsys fs.FS, h Hooks, result *TemperResult) {
+	for _, stage := range []string{HookPreCast, HookPostCast, HookPreUpgrade} {
+		for i, script := range h.Scripts(stage) {
+			if _, err := cleanHookPath(script); err != nil {
+				continue
+			}
+			if _, err := ReadHookScript(fsys, script); err != nil {
+				result.Diagnostics = append(result.Diagnostics, Diagnostic{
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("hooks.%s[%d]: %v", stage, i, err),
+					File:     "mold.yaml",
+				})
+			}
+		}
+	}
+}
+
+// temperSecretFlux checks that a mold's flux.secret.yaml is encrypted with
+// sops: a plain-text one would ship its values to everyone who casts.
+func temperSecretFlux(fsys fs.FS, result *TemperResult) {
+	data, err := fs.ReadFile(fsys, SecretFluxFile)
+	if err != nil {
+		return
+	}
+	var vals map[string]any
+	if err := yaml.Unmarshal(data, &vals); err != nil {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("failed to parse %s: %v", SecretFluxFile, err),
+			File:     SecretFluxFile,
+		})
+		return
+	}
+	if len(vals) > 0 && !IsEncryptedFlux(vals) {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s is not encrypted; encrypt it with `sops --encrypt --in-place %s`", SecretFluxFile, SecretFluxFile),
+			File:     SecretFluxFile,
+		})
+	}
+}
+
+// temperBinaries warns about binary files over MaxBinaryFileSize that cast
+// would skip because mold.yaml's binaries: doesn't list them.
+func temperBinaries(fsys fs.FS, output any, allow []string, result *TemperResult) {
+	resolved, err := ResolveFiles(output, fsys)
+	if err != nil {
+		return // reported by ValidateOutputSources
+	}
+	_, skipped, err := FilterLargeBinaries(resolved, fsys, allow)
+	if err != nil {
+		return
+	}
+	for _, rf := range skipped {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("binary file over %d bytes; cast skips it unless mold.yaml lists it under binaries:", MaxBinaryFileSize),
+			File:     rf.SrcPath,
+		})
+	}
+}
+
+// temperWorkflows renders each workflow blank (outputs landing in
+// .github/workflows/) with the mold's default flux — flux.yaml plus schema
+// defaults — and lints the result with LintWorkflow. process: false
+// workflows are linted as-is.
+func temperWorkflows(fsys fs.FS, m *Mold, flux map[string]any, result *TemperResult) {
+	resolved, err := ResolveFiles(flux["output"], fsys)
+	if err != nil {
+		return // reported by ValidateOutputSources
+	}
+	schema := m.Flux
+	if s, serr := LoadFluxSchema(fsys, "flux.schema.yaml"); serr == nil && s != nil {
+		schema = s
+	}
+	values := ApplyFluxDefaults(schema, flux)
+
+	for _, rf := range resolved {
+		if !IsWorkflowPath(rf.DestPath) {
+			continue
+		}
+		src := fsys
+		if rf.SrcFS != nil {
+			src = rf.SrcFS
+		}
+		data, rerr := fs.ReadFile(src, rf.SrcPath)
+		if rerr != nil {
+			continue // reported by ValidateOutputSources
+		}
+		content := string(data)
+		if rf.Process {
+			set := values
+			if len(rf.Set) > 0 {
+				set = MergeSet(values, rf.Set)
+			}
+			rendered, perr := ProcessTemplate(content, set,
+				WithIngotResolver(NewIngotResolverWithFS(fsys, nil, set)),
+				WithLogger(log.New(io.Discard, "", 0)))
+			if perr != nil {
+				result.Diagnostics = append(result.Diagnostics, Diagnostic{
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("rendering workflow with default flux: %v", perr),
+					File:     rf.SrcPath,
+				})
+				continue
+			}
+			content = rendered
+		}
+		result.Diagnostics = append(result.Diagnostics, LintWorkflow(rf.SrcPath, []byte(content))...)
+	}
 }
 
 // temperIngotAt validates an ingot package whose manifest is at manifestPath,
@@ -659,6 +888,7 @@ func temperIngotAt(fsys fs.FS, manifestPath string, result *TemperResult) {
 		result.Name = i.Name
 		result.Version = i.Version
 	}
+	temperUnknownFields(fsys, manifestPath, &Ingot{}, result)
 
 	if err := ValidateIngot(i); err != nil {
 		for _, line := range extractValidationErrors(err) {
@@ -708,6 +938,7 @@ func temperFluxSchema(fsys fs.FS, manifestFlux []FluxVar, result *TemperResult)
 	if schemaFlux == nil {
 		return
 	}
+	temperUnknownFields(fsys, "flux.schema.yaml", &[]FluxVar{}, result)
 
 	// Validate schema file flux declarations
 	for i, f := range schemaFlux {
@@ -727,14 +958,14 @@ func temperFluxSchema(fsys fs.FS, manifestFlux []FluxVar, result *TemperResult)
 		} else if !validFluxTypes[f.Type] {
 			result.Diagnostics = append(result.Diagnostics, Diagnostic{
 				Severity: SeverityError,
-				Message:  fmt.Sprintf("flux[%d].type %q is not valid (allowed: string, bool, int, list, select)", i, f.Type),
+				Message:  fmt.Sprintf("flux[%d].type %q is not valid (allowed: string, bool, int, list, select, multiselect, secret)", i, f.Type),
 				File:     "flux.schema.yaml",
 			})
 		}
-		if f.Type == "select" && len(f.Options) == 0 && f.Discover == nil {
+		if (f.Type == "select" || f.Type == "multiselect") && len(f.Options) == 0 && f.Discover == nil {
 			result.Diagnostics = append(result.Diagnostics, Diagnostic{
 				Severity: SeverityError,
-				Message:  fmt.Sprintf("flux[%d] %q: select type requires options or discover", i, f.Name),
+				Message:  fmt.Sprintf("flux[%d] %q: %s type requires options or discover", i, f.Name, f.Type),
 				File:     "flux.schema.yaml",
 			})
 		}
@@ -752,6 +983,20 @@ func temperFluxSchema(fsys fs.FS, manifestFlux []FluxVar, result *TemperResult)
 				File:     "flux.schema.yaml",
 			})
 		}
+		if err := validateFluxCondition(f.RequiredIf); err != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("flux[%d] %q: required_if: %v", i, f.Name, err),
+				File:     "flux.schema.yaml",
+			})
+		}
+		if err := validateResolveSpec(f); err != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("flux[%d] %q: %v", i, f.Name, err),
+				File:     "flux.schema.yaml",
+			})
+		}
 	}
 
 	// Warn if both manifest and schema file define flux vars
@@ -763,6 +1008,74 @@ func temperFluxSchema(fsys fs.FS, manifestFlux []FluxVar, result *TemperResult)
 	}
 }
 
+// temperDeprecatedFlux flags blanks that still read a deprecated flux
+// variable, or a renamed one by its old name, and renamed_from names that
+// are still declared as variables. allowedPaths scopes the blanks as in
+// validateTemplates.
+func temperDeprecatedFlux(fsys fs.FS, m *Mold, allowedPaths map[string]bool, result *TemperResult) {
+	schema := m.Flux
+	file := "mold.yaml"
+	if s, err := LoadFluxSchema(fsys, "flux.schema.yaml"); err == nil && s != nil {
+		schema, file = s, "flux.schema.yaml"
+	}
+
+	declared := make(map[string]bool, len(schema))
+	for _, f := range schema {
+		declared[f.Name] = true
+	}
+	// old names → what to tell a blank that reads them
+	stale := make(map[string]string)
+	for i, f := range schema {
+		if f.Deprecated {
+			stale[f.Name] = fmt.Sprintf("reads deprecated flux %q", f.Name)
+		}
+		if f.RenamedFrom == "" {
+			continue
+		}
+		if declared[f.RenamedFrom] {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("flux[%d] %q: renamed_from %q is still declared as a flux variable", i, f.Name, f.RenamedFrom),
+				File:     file,
+			})
+			continue
+		}
+		stale[f.RenamedFrom] = fmt.Sprintf("reads flux %q, which was renamed to %q", f.RenamedFrom, f.Name)
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	_ = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".md" {
+			return nil
+		}
+		if allowedPaths != nil && !allowedPaths[p] {
+			return nil
+		}
+		data, rerr := fs.ReadFile(fsys, p)
+		if rerr != nil {
+			return nil // reported by validateTemplates
+		}
+		var refs []string
+		for _, re := range []*regexp.Regexp{directVarRefPattern, actionVarRefPattern} {
+			for _, match := range re.FindAllStringSubmatch(string(data), -1) {
+				refs = append(refs, match[1])
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(stale)) {
+			if slices.ContainsFunc(refs, func(ref string) bool { return ref == name || strings.HasPrefix(ref, name+".") }) {
+				result.Diagnostics = append(result.Diagnostics, Diagnostic{
+					Severity: SeverityWarning,
+					Message:  stale[name],
+					File:     p,
+				})
+			}
+		}
+		return nil
+	})
+}
+
 // validateTemplates parses .md files through Go text/template to catch syntax errors.
 // When allowedPaths is non-nil, only files in that set are validated.
 // resolveOutputPaths returns the set of source paths from the output manifest
@@ -818,8 +1131,10 @@ func validateTemplates(fsys fs.FS, allowedPaths map[string]bool, result *TemperR
 		content := preProcessTemplate(string(data))
 		funcMap := baseFuncMap()
 		// Register a no-op ingot stub so validation accepts {{ingot "name"}}
-		// even without a resolver. The real resolver is only available at render time.
-		funcMap["ingot"] = func(name string) string { return "" }
+		// (and its key=value arguments) even without a resolver. The real
+		// resolver is only available at render time.
+		funcMap["ingot"] = func(name string, args ...any) string { return "" }
+		funcMap["file"] = func(name string) string { return "" }
 		if _, parseErr := template.New(path).Funcs(funcMap).Option("missingkey=zero").Parse(content); parseErr != nil {
 			result.Diagnostics = append(result.Diagnostics, Diagnostic{
 				Severity: SeverityError,
@@ -827,6 +1142,21 @@ func validateTemplates(fsys fs.FS, allowedPaths map[string]bool, result *TemperR
 				File:     path,
 			})
 		}
+		// Literal {{file}} paths can be checked now; computed ones only at
+		// render time. Ingots read from whichever mold renders them, so
+		// only a mold's own paths are known here.
+		if result.ManifestKind != "mold" {
+			return nil
+		}
+		for _, m := range fileActionPattern.FindAllStringSubmatch(content, -1) {
+			if _, err := readTemplateFile(fsys, m[1]); err != nil {
+				result.Diagnostics = append(result.Diagnostics, Diagnostic{
+					Severity: SeverityError,
+					Message:  err.Error(),
+					File:     path,
+				})
+			}
+		}
 
 		return nil
 	})
@@ -0,0 +1,255 @@
+package mold
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// workflowEvents are the GitHub Actions trigger events accepted under `on:`.
+var workflowEvents = map[string]bool{
+	"branch_protection_rule": true, "check_run": true, "check_suite": true,
+	"create": true, "delete": true, "deployment": true, "deployment_status": true,
+	"discussion": true, "discussion_comment": true, "fork": true, "gollum": true,
+	"issue_comment": true, "issues": true, "label": true, "merge_group": true,
+	"milestone": true, "page_build": true, "public": true, "pull_request": true,
+	"pull_request_review": true, "pull_request_review_comment": true,
+	"pull_request_target": true, "push": true, "registry_package": true,
+	"release": true, "repository_dispatch": true, "schedule": true, "status": true,
+	"watch": true, "workflow_call": true, "workflow_dispatch": true, "workflow_run": true,
+}
+
+// workflowPermissionScopes are the scopes accepted in a `permissions:` map.
+var workflowPermissionScopes = map[string]bool{
+	"actions": true, "attestations": true, "checks": true, "contents": true,
+	"deployments": true, "discussions": true, "id-token": true, "issues": true,
+	"models": true, "packages": true, "pages": true, "pull-requests": true,
+	"repository-projects": true, "security-events": true, "statuses": true,
+}
+
+// IsWorkflowPath reports whether a cast destination is a GitHub Actions
+// workflow file.
+func IsWorkflowPath(dest string) bool {
+	dest = strings.TrimPrefix(path.Clean(strings.ReplaceAll(dest, "\\", "/")), "./")
+	ext := path.Ext(dest)
+	return path.Dir(dest) == ".github/workflows" && (ext == ".yml" || ext == ".yaml")
+}
+
+// LintWorkflow runs actionlint-style structural checks on a rendered GitHub
+// Actions workflow and returns diagnostics attributed to file. It covers the
+// mistakes flux parameterization tends to introduce — empty or malformed
+// triggers, invalid permission blocks, unpinned or blank action versions,
+// leftover template syntax — not the full workflow schema.
+func LintWorkflow(file string, content []byte) []Diagnostic {
+	var diags []Diagnostic
+	add := func(sev DiagSeverity, format string, args ...any) {
+		diags = append(diags, Diagnostic{Severity: sev, Message: fmt.Sprintf(format, args...), File: file, Rule: "workflow"})
+	}
+
+	if leftover := unrenderedTemplateAction(string(content)); leftover != "" {
+		add(SeverityWarning, "template action %q is left unrendered; set process: true on this output to render flux in the workflow", leftover)
+	}
+
+	var wf map[string]any
+	if err := yaml.Unmarshal(content, &wf); err != nil {
+		add(SeverityError, "workflow is not valid YAML: %v", err)
+		return diags
+	}
+	if wf == nil {
+		add(SeverityError, "workflow is empty")
+		return diags
+	}
+
+	lintWorkflowTriggers(wf["on"], add)
+	if p, ok := wf["permissions"]; ok {
+		lintWorkflowPermissions("workflow", p, add)
+	}
+
+	jobs, ok := wf["jobs"].(map[string]any)
+	if !ok || len(jobs) == 0 {
+		add(SeverityError, "workflow must define at least one job under jobs:")
+		return diags
+	}
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		job, ok := jobs[name].(map[string]any)
+		if !ok {
+			add(SeverityError, "job %q must be a mapping", name)
+			continue
+		}
+		lintWorkflowJob(name, job, jobs, add)
+	}
+	return diags
+}
+
+func lintWorkflowTriggers(on any, add func(DiagSeverity, string, ...any)) {
+	var events []string
+	switch v := on.(type) {
+	case nil:
+		add(SeverityError, "workflow has no trigger events (on:)")
+		return
+	case string:
+		events = []string{v}
+	case []any:
+		for _, e := range v {
+			events = append(events, fmt.Sprint(e))
+		}
+	case map[string]any:
+		for e := range v {
+			events = append(events, e)
+		}
+		if sched, ok := v["schedule"]; ok {
+			lintWorkflowSchedule(sched, add)
+		}
+	default:
+		add(SeverityError, "on: must be an event name, a list of events, or a mapping")
+		return
+	}
+	if len(events) == 0 {
+		add(SeverityError, "workflow has no trigger events (on:)")
+	}
+	sort.Strings(events)
+	for _, e := range events {
+		if !workflowEvents[e] {
+			add(SeverityError, "unknown trigger event %q", e)
+		}
+	}
+}
+
+func lintWorkflowSchedule(sched any, add func(DiagSeverity, string, ...any)) {
+	entries, ok := sched.([]any)
+	if !ok || len(entries) == 0 {
+		add(SeverityError, "on.schedule must be a non-empty list of {cron: ...} entries")
+		return
+	}
+	for _, e := range entries {
+		m, _ := e.(map[string]any)
+		cron, _ := m["cron"].(string)
+		if len(strings.Fields(cron)) != 5 {
+			add(SeverityError, "on.schedule cron %q must have 5 fields", cron)
+		}
+	}
+}
+
+func lintWorkflowPermissions(scope string, perms any, add func(DiagSeverity, string, ...any)) {
+	switch v := perms.(type) {
+	case string:
+		if v != "read-all" && v != "write-all" {
+			add(SeverityError, "%s permissions %q must be read-all, write-all, or a scope mapping", scope, v)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !workflowPermissionScopes[k] {
+				add(SeverityError, "%s permissions: unknown scope %q", scope, k)
+				continue
+			}
+			level := fmt.Sprint(v[k])
+			if level != "read" && level != "write" && level != "none" {
+				add(SeverityError, "%s permissions: %s must be read, write, or none (got %q)", scope, k, level)
+			}
+		}
+	case nil:
+		// `permissions: {}` parses as an empty map; a bare `permissions:` is
+		// nil and GitHub rejects it.
+		add(SeverityError, "%s permissions is empty; use {} to drop all permissions", scope)
+	default:
+		add(SeverityError, "%s permissions must be read-all, write-all, or a scope mapping", scope)
+	}
+}
+
+func lintWorkflowJob(name string, job map[string]any, jobs map[string]any, add func(DiagSeverity, string, ...any)) {
+	if p, ok := job["permissions"]; ok {
+		lintWorkflowPermissions(fmt.Sprintf("job %q", name), p, add)
+	}
+
+	var needs []string
+	switch v := job["needs"].(type) {
+	case string:
+		needs = []string{v}
+	case []any:
+		for _, n := range v {
+			needs = append(needs, fmt.Sprint(n))
+		}
+	}
+	for _, n := range needs {
+		if _, ok := jobs[n]; !ok {
+			add(SeverityError, "job %q needs unknown job %q", name, n)
+		}
+	}
+
+	// A reusable-workflow call has neither runs-on nor steps.
+	if uses, ok := job["uses"]; ok {
+		lintActionRef(fmt.Sprintf("job %q", name), fmt.Sprint(uses), add)
+		return
+	}
+	if ro, ok := job["runs-on"]; !ok || ro == nil || ro == "" {
+		add(SeverityError, "job %q is missing runs-on", name)
+	}
+	steps, ok := job["steps"].([]any)
+	if !ok || len(steps) == 0 {
+		add(SeverityError, "job %q has no steps", name)
+		return
+	}
+	for i, s := range steps {
+		step, ok := s.(map[string]any)
+		if !ok {
+			add(SeverityError, "job %q step %d must be a mapping", name, i+1)
+			continue
+		}
+		uses, hasUses := step["uses"]
+		_, hasRun := step["run"]
+		switch {
+		case hasUses && hasRun:
+			add(SeverityError, "job %q step %d has both uses and run", name, i+1)
+		case !hasUses && !hasRun:
+			add(SeverityError, "job %q step %d needs uses or run", name, i+1)
+		case hasUses:
+			lintActionRef(fmt.Sprintf("job %q step %d", name, i+1), fmt.Sprint(uses), add)
+		}
+	}
+}
+
+// lintActionRef checks that a `uses:` reference names a version. Local
+// (./path) and docker:// references are exempt.
+func lintActionRef(where, ref string, add func(DiagSeverity, string, ...any)) {
+	if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "docker://") {
+		return
+	}
+	action, version, ok := strings.Cut(ref, "@")
+	switch {
+	case strings.TrimSpace(action) == "" || ref == "<nil>":
+		add(SeverityError, "%s: uses is empty", where)
+	case !ok:
+		add(SeverityError, "%s: %q must pin a version (owner/repo@ref)", where, ref)
+	case strings.TrimSpace(version) == "":
+		add(SeverityError, "%s: %q has an empty version after @", where, ref)
+	}
+}
+
+// unrenderedTemplateAction returns the first {{ ... }} in content that is not
+// part of a ${{ ... }} Actions expression — template syntax that survived
+// rendering (e.g. in a process: false workflow). Empty when none remains.
+func unrenderedTemplateAction(content string) string {
+	stripped := actionsExprPattern.ReplaceAllString(content, "")
+	i := strings.Index(stripped, "{{")
+	if i < 0 {
+		return ""
+	}
+	end := strings.Index(stripped[i:], "}}")
+	if end < 0 {
+		return stripped[i:min(len(stripped), i+40)]
+	}
+	return stripped[i : i+end+2]
+}
@@ -0,0 +1,155 @@
+package mold
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const validWorkflow = `name: Claude Code
+on:
+  issue_comment:
+    types: [created]
+  pull_request:
+permissions:
+  contents: read
+  pull-requests: write
+jobs:
+  claude:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: anthropics/claude-code-action@v1
+        with:
+          anthropic_api_key: ${{ secrets.ANTHROPIC_API_KEY }}
+      - run: echo done
+`
+
+func TestLintWorkflow_Valid(t *testing.T) {
+	if diags := LintWorkflow("wf.yml", []byte(validWorkflow)); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got: %v", diags)
+	}
+}
+
+func TestLintWorkflow_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown event", "on: pull-request\njobs:\n  a:\n    runs-on: x\n    steps:\n      - run: x\n", `unknown trigger event "pull-request"`},
+		{"no trigger", "jobs:\n  a:\n    runs-on: x\n    steps:\n      - run: x\n", "no trigger events"},
+		{"bad cron", "on:\n  schedule:\n    - cron: '0 0 *'\njobs:\n  a:\n    runs-on: x\n    steps:\n      - run: x\n", "must have 5 fields"},
+		{"bad permission level", "on: push\npermissions:\n  contents: admin\njobs:\n  a:\n    runs-on: x\n    steps:\n      - run: x\n", "contents must be read, write, or none"},
+		{"unknown permission scope", "on: push\npermissions:\n  code: read\njobs:\n  a:\n    runs-on: x\n    steps:\n      - run: x\n", `unknown scope "code"`},
+		{"empty permissions", "on: push\npermissions:\njobs:\n  a:\n    runs-on: x\n    steps:\n      - run: x\n", "permissions is empty"},
+		{"no jobs", "on: push\n", "at least one job"},
+		{"missing runs-on", "on: push\njobs:\n  a:\n    steps:\n      - run: x\n", `job "a" is missing runs-on`},
+		{"unknown needs", "on: push\njobs:\n  a:\n    runs-on: x\n    needs: build\n    steps:\n      - run: x\n", `needs unknown job "build"`},
+		{"uses and run", "on: push\njobs:\n  a:\n    runs-on: x\n    steps:\n      - uses: actions/checkout@v4\n        run: x\n", "has both uses and run"},
+		{"unpinned action", "on: push\njobs:\n  a:\n    runs-on: x\n    steps:\n      - uses: anthropics/claude-code-action\n", "must pin a version"},
+		{"empty version", "on: push\njobs:\n  a:\n    runs-on: x\n    steps:\n      - uses: anthropics/claude-code-action@\n", "empty version after @"},
+		{"invalid yaml", "on: [push\n", "not valid YAML"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := LintWorkflow("wf.yml", []byte(tt.content))
+			var found bool
+			for _, d := range diags {
+				if d.Severity == SeverityError && strings.Contains(d.Message, tt.want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected error containing %q, got: %v", tt.want, diags)
+			}
+		})
+	}
+}
+
+func TestLintWorkflow_LocalAndDockerActionsExempt(t *testing.T) {
+	content := "on: push\njobs:\n  a:\n    runs-on: x\n    steps:\n      - uses: ./.github/actions/setup\n      - uses: docker://alpine:3\n"
+	if diags := LintWorkflow("wf.yml", []byte(content)); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got: %v", diags)
+	}
+}
+
+func TestLintWorkflow_WarnsOnUnrenderedTemplate(t *testing.T) {
+	content := strings.Replace(validWorkflow, "claude-code-action@v1", "claude-code-action@{{claude.action_version}}", 1)
+	diags := LintWorkflow("wf.yml", []byte(content))
+	if len(diags) == 0 || diags[0].Severity != SeverityWarning || !strings.Contains(diags[0].Message, "process: true") {
+		t.Errorf("expected unrendered-template warning, got: %v", diags)
+	}
+}
+
+func TestIsWorkflowPath(t *testing.T) {
+	tests := map[string]bool{
+		".github/workflows/claude.yml":   true,
+		"./.github/workflows/ci.yaml":    true,
+		".github/workflows/README.md":    false,
+		".github/ISSUE_TEMPLATE/bug.yml": false,
+		"workflows/ci.yml":               false,
+	}
+	for dest, want := range tests {
+		if got := IsWorkflowPath(dest); got != want {
+			t.Errorf("IsWorkflowPath(%q) = %v, want %v", dest, got, want)
+		}
+	}
+}
+
+func TestTemper_RendersAndLintsWorkflowBlanks(t *testing.T) {
+	workflow := `on:
+{{- range .claude.triggers}}
+  {{.}}:
+{{- end}}
+permissions:
+  contents: {{claude.contents_permission}}
+jobs:
+  claude:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: anthropics/claude-code-action@{{claude.action_version}}
+        with:
+          model: {{claude.model}}
+          anthropic_api_key: ${{ secrets.ANTHROPIC_API_KEY }}
+`
+	schema := `- name: claude.action_version
+  type: string
+  default: v1
+- name: claude.model
+  type: string
+  default: claude-sonnet-4-5
+- name: claude.contents_permission
+  type: string
+  default: read
+`
+	newFS := func(fluxClaude string) fstest.MapFS {
+		return fstest.MapFS{
+			"mold.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: wf\nversion: 1.0.0\n")},
+			"flux.yaml": &fstest.MapFile{Data: []byte(`
+output:
+  workflows:
+    dest: .github/workflows
+    process: true
+claude:
+  triggers: [issue_comment, pull_request_review_comment]
+` + fluxClaude)},
+			"flux.schema.yaml":          &fstest.MapFile{Data: []byte(schema)},
+			"workflows/claude-code.yml": &fstest.MapFile{Data: []byte(workflow)},
+		}
+	}
+
+	result := Temper(newFS(""))
+	if result.HasErrors() {
+		t.Fatalf("expected no errors, got: %v", result.Errors())
+	}
+
+	result = Temper(newFS("  action_version: \"\"\n"))
+	d := diagWithRule(result.Errors(), "workflow")
+	if d == nil {
+		t.Fatalf("expected workflow error for empty action version, got: %v", result.Diagnostics)
+	}
+	if !strings.Contains(d.Message, "empty version") || d.File != "workflows/claude-code.yml" {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+}
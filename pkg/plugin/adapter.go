@@ -0,0 +1,17 @@
+package plugin
+
+import "github.com/nimble-giant/ailloy/pkg/blanks"
+
+// The built-in output adapters, selectable with `cast --to` and
+// `plugin generate --format`.
+func init() {
+	for _, a := range []blanks.OutputAdapter{
+		CursorAdapter{},
+		OpenCodeAdapter{},
+		CodexAdapter{},
+		WindsurfAdapter{},
+		JetBrainsAdapter{},
+	} {
+		blanks.RegisterOutputAdapter(a)
+	}
+}
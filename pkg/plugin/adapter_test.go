@@ -0,0 +1,24 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestBuiltinAdapters(t *testing.T) {
+	want := []string{FormatCodex, FormatCursor, FormatJetBrains, FormatOpenCode, FormatWindsurf}
+	if got := blanks.OutputAdapterNames(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("OutputAdapterNames() = %v, want %v", got, want)
+	}
+	if got := Formats(); got[0] != FormatClaude || len(got) != len(want)+1 {
+		t.Errorf("Formats() = %v", got)
+	}
+	for _, name := range []string{FormatCursor, FormatWindsurf, FormatJetBrains} {
+		a, _ := blanks.LookupOutputAdapter(name)
+		if blanks.SupportsGlobal(a) {
+			t.Errorf("%s should be project-only", name)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+// CodexHomeEnv overrides the Codex CLI home directory (default ~/.codex).
+const CodexHomeEnv = "CODEX_HOME"
+
+const codexPromptsPrefix = ".codex/prompts/"
+
+// codexPromptFields are the frontmatter fields Codex understands on a custom
+// prompt besides description; they carry over from the blank.
+var codexPromptFields = []string{"argument-hint"}
+
+// CodexPrompt is a single Codex CLI custom prompt (~/.codex/prompts/<name>.md),
+// invoked as /prompts:<name>. Arguments use $ARGUMENTS and $1..$9 like Claude
+// Code commands, so bodies carry over unchanged.
+type CodexPrompt struct {
+	Name        string
+	Description string
+	// Body is the markdown following the frontmatter.
+	Body string
+	// Source is the cast destination the prompt was compiled from.
+	Source string
+
+	extra []frontmatterField
+}
+
+// Markdown renders the prompt file: description and any carried-over
+// argument-hint frontmatter followed by the body.
+func (p *CodexPrompt) Markdown() ([]byte, error) {
+	fm := yaml.MapSlice{{Key: "description", Value: p.Description}}
+	for _, f := range p.extra {
+		fm = append(fm, yaml.MapItem{Key: f.Key, Value: f.Value})
+	}
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling frontmatter for prompt %s: %w", p.Name, err)
+	}
+	return frontmatterDocument(data, p.Body), nil
+}
+
+// CodexOutput is a mold converted for the Codex CLI: custom prompts from
+// command blanks, plus AGENTS.md content from the mold's own AGENTS.md and
+// its skills.
+type CodexOutput struct {
+	Prompts []*CodexPrompt
+	// Instructions is the mold's rendered root AGENTS.md, if any.
+	Instructions []byte
+	Sections     []*InstructionSection
+}
+
+// AgentsMarkdown renders the block added to AGENTS.md: the mold's own
+// instructions followed by one section per skill. Empty when there is
+// nothing to add.
+func (o *CodexOutput) AgentsMarkdown() []byte {
+	return instructionsMarkdown(o.Instructions, o.Sections)
+}
+
+// CodexTransformer converts Ailloy blanks to Codex CLI prompt format.
+type CodexTransformer struct{}
+
+// NewCodexTransformer creates a new Codex prompt transformer.
+func NewCodexTransformer() *CodexTransformer {
+	return &CodexTransformer{}
+}
+
+// Transform converts an Ailloy blank to a Codex custom prompt file.
+func (t *CodexTransformer) Transform(tmpl BlankInfo) ([]byte, error) {
+	p, err := t.Prompt(tmpl.Name, tmpl.Content, tmpl.Name)
+	if err != nil {
+		return nil, err
+	}
+	return p.Markdown()
+}
+
+// Prompt builds a CodexPrompt from blank content.
+func (t *CodexTransformer) Prompt(name string, content []byte, source string) (*CodexPrompt, error) {
+	desc, extra, body, err := carriedFrontmatter(content, source, codexPromptFields...)
+	if err != nil {
+		return nil, err
+	}
+	return &CodexPrompt{Name: name, Description: desc, Body: body, Source: source, extra: extra}, nil
+}
+
+// CompileCodex converts rendered blanks for the Codex CLI. Command blanks
+// and blanks already cast under .codex/prompts/ become custom prompts;
+// skill entrypoints become AGENTS.md sections, after the mold's own rendered
+// AGENTS.md. Resources and other files are ignored.
+func CompileCodex(files []RenderedFile) (*CodexOutput, error) {
+	entries, err := collectEntrypoints(files, "prompt", codexPromptsPrefix, ".md")
+	if err != nil {
+		return nil, err
+	}
+	out := &CodexOutput{Instructions: rootAgentsMarkdown(files)}
+
+	t := NewCodexTransformer()
+	for _, e := range entries {
+		if e.Kind == entrySkill {
+			s, err := skillSection(e)
+			if err != nil {
+				return nil, err
+			}
+			out.Sections = append(out.Sections, s)
+			continue
+		}
+		p, err := t.Prompt(e.Name, e.File.Content, e.File.CastDest)
+		if err != nil {
+			return nil, err
+		}
+		out.Prompts = append(out.Prompts, p)
+	}
+	return out, nil
+}
+
+// CodexAdapter is the "codex" output adapter. Codex only reads custom
+// prompts from $CODEX_HOME/prompts/, so those always go there; the AGENTS.md
+// block goes into the project, or $CODEX_HOME/AGENTS.md for global casts.
+type CodexAdapter struct{}
+
+// Name implements blanks.OutputAdapter.
+func (CodexAdapter) Name() string { return FormatCodex }
+
+// Title implements blanks.TitledAdapter.
+func (CodexAdapter) Title() string { return "Codex CLI prompts" }
+
+// Hint implements blanks.HintAdapter.
+func (CodexAdapter) Hint() string {
+	return "Run prompts in Codex as /prompts:<name>; Codex reads them from $CODEX_HOME/prompts (default ~/.codex/prompts)."
+}
+
+// UserDir implements blanks.UserDirAdapter.
+func (CodexAdapter) UserDir() (string, error) { return CodexHome() }
+
+// UserPath implements blanks.UserDirAdapter.
+func (CodexAdapter) UserPath(mapped string) (string, bool, bool) {
+	switch {
+	case strings.HasPrefix(mapped, codexPromptsPrefix):
+		return "prompts/" + strings.TrimPrefix(mapped, codexPromptsPrefix), true, true
+	case mapped == "AGENTS.md":
+		return "AGENTS.md", false, true
+	}
+	return "", false, false
+}
+
+// MapDestination implements blanks.OutputAdapter. Skills are left to
+// PostInstall, which folds them into AGENTS.md.
+func (CodexAdapter) MapDestination(dest string) (string, bool) {
+	name, kind, ok := classifyEntrypoint(dest, codexPromptsPrefix, ".md")
+	if !ok || kind == entrySkill {
+		return "", false
+	}
+	return codexPromptsPrefix + name + ".md", true
+}
+
+// TransformContent implements blanks.OutputAdapter.
+func (CodexAdapter) TransformContent(dest string, content []byte) ([]byte, error) {
+	name, _, _ := classifyEntrypoint(dest, codexPromptsPrefix, ".md")
+	p, err := NewCodexTransformer().Prompt(name, content, dest)
+	if err != nil {
+		return nil, err
+	}
+	return p.Markdown()
+}
+
+// PostInstall implements blanks.OutputAdapter: it writes the mold's
+// AGENTS.md and skill sections into an AGENTS.md block.
+func (CodexAdapter) PostInstall(ctx *blanks.InstallContext) error {
+	out, err := CompileCodex(ctx.Rendered)
+	if err != nil {
+		return err
+	}
+	agents := out.AgentsMarkdown()
+	if len(agents) == 0 {
+		return nil
+	}
+	return ctx.Write("AGENTS.md", agents, blanks.WriteBlock)
+}
+
+// CodexHome returns $CODEX_HOME, or ~/.codex when unset.
+func CodexHome() (string, error) {
+	if home := os.Getenv(CodexHomeEnv); home != "" {
+		return home, nil
+	}
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(userHome, ".codex"), nil
+}
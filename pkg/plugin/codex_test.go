@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestCompileCodex(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/review.md", Content: []byte("---\ndescription: Reviews a PR.\nargument-hint: <pr>\nmodel: x\n---\nReview $1.\n")},
+		{CastDest: ".claude/skills/go-style/SKILL.md", Content: []byte("---\ndescription: Go conventions.\n---\n# Go style\n\n```sh\n# not a heading\n```\n")},
+		{CastDest: ".claude/skills/go-style/ref.md", Content: []byte("ref")},
+		{CastDest: "AGENTS.md", Content: []byte("# Project\n\nBe nice.\n")},
+	}
+
+	out, err := CompileCodex(files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Prompts) != 1 || len(out.Sections) != 1 {
+		t.Fatalf("prompts=%d sections=%d", len(out.Prompts), len(out.Sections))
+	}
+	md, err := out.Prompts[0].Markdown()
+	if err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	want := "---\ndescription: Reviews a PR.\nargument-hint: <pr>\n---\n\nReview $1.\n"
+	if string(md) != want {
+		t.Errorf("prompt:\n%s\nwant:\n%s", md, want)
+	}
+
+	agents := string(out.AgentsMarkdown())
+	wantAgents := "# Project\n\nBe nice.\n\n## go-style\n\nGo conventions.\n\n### Go style\n\n```sh\n# not a heading\n```\n"
+	if agents != wantAgents {
+		t.Errorf("AGENTS.md block:\n%s\nwant:\n%s", agents, wantAgents)
+	}
+}
+
+func TestCodexAdapter_PromptsGoToCodexHome(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv(CodexHomeEnv, codexHome)
+	dir := t.TempDir()
+	agentsPath := filepath.Join(dir, "AGENTS.md")
+	if err := os.WriteFile(agentsPath, []byte("# Mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, desc := range []string{"First.", "Second."} {
+		files := []RenderedFile{
+			{CastDest: ".claude/commands/hello.md", Content: []byte("Hello")},
+			{CastDest: ".claude/skills/s/SKILL.md", Content: []byte("---\ndescription: " + desc + "\n---\nBody\n")},
+		}
+		if _, err := blanks.Adapt(CodexAdapter{}, files, blanks.AdaptOptions{Root: dir, MoldName: "m"}); err != nil {
+			t.Fatalf("Adapt: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(agentsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if !strings.HasPrefix(got, "# Mine\n") || strings.Contains(got, "First.") || strings.Count(got, "ailloy:mold=m:start") != 1 {
+		t.Errorf("unexpected AGENTS.md:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(codexHome, "prompts", "hello.md")); err != nil {
+		t.Errorf("expected prompt in CODEX_HOME: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".codex")); !os.IsNotExist(err) {
+		t.Error("project cast should not write prompts into the project")
+	}
+}
+
+func TestCodexHome(t *testing.T) {
+	t.Setenv(CodexHomeEnv, "/opt/codex")
+	if got, err := CodexHome(); err != nil || got != "/opt/codex" {
+		t.Errorf("CodexHome() = %q, %v", got, err)
+	}
+	t.Setenv(CodexHomeEnv, "")
+	t.Setenv("HOME", "/home/u")
+	if got, err := CodexHome(); err != nil || got != filepath.Join("/home/u", ".codex") {
+		t.Errorf("CodexHome() = %q, %v", got, err)
+	}
+}
+
+func TestGenerator_Generate_CodexFormat(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "out")
+	g := NewGenerator(outputDir, testMoldReader())
+	g.Format = FormatCodex
+	if err := g.Generate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, ".codex", "prompts", "test.md")); err != nil {
+		t.Errorf("expected prompt: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+// CursorRuleExt is the file extension Cursor reads project rules from.
+const CursorRuleExt = ".mdc"
+
+const cursorRulesPrefix = ".cursor/rules/"
+
+// CursorRule is a single Cursor project rule (.cursor/rules/<name>.mdc).
+// Cursor decides when to attach a rule from its frontmatter: AlwaysApply
+// rules are always in context, rules with Globs attach when a matching file
+// is referenced, and the rest are offered to the agent by Description.
+type CursorRule struct {
+	Name        string
+	Description string
+	// Globs is the comma-separated list of file patterns, as Cursor stores it.
+	Globs       string
+	AlwaysApply bool
+	// Body is the markdown following the frontmatter.
+	Body string
+	// Source is the cast destination the rule was compiled from.
+	Source string
+}
+
+// Markdown renders the .mdc file: description, globs, and alwaysApply
+// frontmatter followed by the body. Globs are written unquoted, matching the
+// files Cursor itself generates.
+func (r *CursorRule) Markdown() ([]byte, error) {
+	desc, err := yaml.Marshal(yaml.MapSlice{{Key: "description", Value: r.Description}})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling frontmatter for rule %s: %w", r.Name, err)
+	}
+	fm := bytes.NewBuffer(desc)
+	fm.WriteString(strings.TrimRight("globs: "+r.Globs, " ") + "\n")
+	fmt.Fprintf(fm, "alwaysApply: %t\n", r.AlwaysApply)
+	return frontmatterDocument(fm.Bytes(), r.Body), nil
+}
+
+// CursorTransformer converts Ailloy blanks to Cursor rule format.
+type CursorTransformer struct{}
+
+// NewCursorTransformer creates a new Cursor rule transformer.
+func NewCursorTransformer() *CursorTransformer {
+	return &CursorTransformer{}
+}
+
+// Transform converts an Ailloy blank to a Cursor .mdc rule file.
+func (t *CursorTransformer) Transform(tmpl BlankInfo) ([]byte, error) {
+	rule, err := t.Rule(tmpl.Name, tmpl.Content, tmpl.Name)
+	if err != nil {
+		return nil, err
+	}
+	return rule.Markdown()
+}
+
+// Rule builds a CursorRule from blank content. The blank's description,
+// globs (a string or a list), and alwaysApply frontmatter carry over; other
+// fields are dropped because Cursor ignores them. A missing description
+// falls back to the blank's Purpose section, then its first paragraph.
+func (t *CursorTransformer) Rule(name string, content []byte, source string) (*CursorRule, error) {
+	fm, body, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing frontmatter in %s: %w", source, err)
+	}
+	r := &CursorRule{Name: name, Body: body, Source: source}
+	for _, item := range fm {
+		switch fmt.Sprint(item.Key) {
+		case "description":
+			r.Description = strings.TrimSpace(fmt.Sprint(item.Value))
+		case "globs":
+			r.Globs = cursorGlobs(item.Value)
+		case "alwaysApply":
+			r.AlwaysApply = fmt.Sprint(item.Value) == "true"
+		}
+	}
+	if r.Description == "" {
+		r.Description = fallbackDescription(body)
+	}
+	return r, nil
+}
+
+// cursorGlobs normalizes a globs frontmatter value to Cursor's
+// comma-separated form.
+func cursorGlobs(v any) string {
+	var globs []string
+	switch g := v.(type) {
+	case nil:
+	case []any:
+		for _, item := range g {
+			globs = append(globs, strings.TrimSpace(fmt.Sprint(item)))
+		}
+	default:
+		for _, item := range strings.Split(fmt.Sprint(g), ",") {
+			globs = append(globs, strings.TrimSpace(item))
+		}
+	}
+	out := globs[:0]
+	for _, g := range globs {
+		if g != "" {
+			out = append(out, g)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// purposeLine returns the first line of a "## Purpose" section, or "".
+func purposeLine(body string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "## Purpose" {
+			continue
+		}
+		for _, next := range lines[i+1:] {
+			next = strings.TrimSpace(next)
+			if strings.HasPrefix(next, "#") {
+				return ""
+			}
+			if next != "" {
+				return next
+			}
+		}
+	}
+	return ""
+}
+
+// CompileCursorRules converts rendered blanks into Cursor rules. Command
+// blanks (.claude/commands/<name>.md) and skill entrypoints
+// (.claude/skills/<name>/SKILL.md) become rules named <name>, as do blanks
+// already cast under .cursor/rules/. Other files — including command and
+// skill resources, which Cursor has no place for — are ignored. Rules are
+// returned sorted by name.
+func CompileCursorRules(files []RenderedFile) ([]*CursorRule, error) {
+	entries, err := collectEntrypoints(files, "rule", cursorRulesPrefix, ".md", CursorRuleExt)
+	if err != nil {
+		return nil, err
+	}
+	t := NewCursorTransformer()
+	rules := make([]*CursorRule, 0, len(entries))
+	for _, e := range entries {
+		r, err := t.Rule(e.Name, e.File.Content, e.File.CastDest)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// CursorAdapter is the "cursor" output adapter: one .cursor/rules/<name>.mdc
+// per command or skill blank. Cursor has no user-level rule files, so it is
+// project-only.
+type CursorAdapter struct{}
+
+// Name implements blanks.OutputAdapter.
+func (CursorAdapter) Name() string { return FormatCursor }
+
+// Title implements blanks.TitledAdapter.
+func (CursorAdapter) Title() string { return "Cursor rules" }
+
+// Hint implements blanks.HintAdapter.
+func (CursorAdapter) Hint() string { return "Cursor picks up project rules automatically." }
+
+// MapDestination implements blanks.OutputAdapter.
+func (CursorAdapter) MapDestination(dest string) (string, bool) {
+	name, _, ok := classifyEntrypoint(dest, cursorRulesPrefix, ".md", CursorRuleExt)
+	if !ok {
+		return "", false
+	}
+	return cursorRulesPrefix + name + CursorRuleExt, true
+}
+
+// TransformContent implements blanks.OutputAdapter.
+func (CursorAdapter) TransformContent(dest string, content []byte) ([]byte, error) {
+	name, _, _ := classifyEntrypoint(dest, cursorRulesPrefix, ".md", CursorRuleExt)
+	r, err := NewCursorTransformer().Rule(name, content, dest)
+	if err != nil {
+		return nil, err
+	}
+	return r.Markdown()
+}
+
+// PostInstall implements blanks.OutputAdapter; rules need nothing more.
+func (CursorAdapter) PostInstall(*blanks.InstallContext) error { return nil }
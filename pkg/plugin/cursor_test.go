@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestCompileCursorRules(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/create-issue.md", Content: []byte("---\ndescription: Creates GitHub issues.\nallowed-tools: Bash(gh:*)\n---\n# Create Issue\n\nSteps here.\n")},
+		{CastDest: ".claude/commands/create-issue/reference.md", Content: []byte("# Reference\n")},
+		{CastDest: ".claude/skills/go-style/SKILL.md", Content: []byte("---\ndescription: Go conventions.\nglobs:\n  - \"**/*.go\"\n  - go.mod\n---\nUse gofmt.\n")},
+		{CastDest: ".claude/skills/go-style/examples.md", Content: []byte("examples")},
+		{CastDest: ".cursor/rules/always.mdc", Content: []byte("---\nalwaysApply: true\n---\n# Always\n\n## Purpose\n\nHouse rules.\n")},
+		{CastDest: "AGENTS.md", Content: []byte("agents")},
+	}
+
+	rules, err := CompileCursorRules(files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, ","); got != "always,create-issue,go-style" {
+		t.Fatalf("rule names = %s", got)
+	}
+
+	always, issue, goStyle := rules[0], rules[1], rules[2]
+	if !always.AlwaysApply || always.Description != "House rules." {
+		t.Errorf("always rule = %+v", always)
+	}
+	if goStyle.Globs != "**/*.go,go.mod" {
+		t.Errorf("globs = %q", goStyle.Globs)
+	}
+
+	md, err := issue.Markdown()
+	if err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	want := "---\ndescription: Creates GitHub issues.\nglobs:\nalwaysApply: false\n---\n\n# Create Issue\n\nSteps here.\n"
+	if string(md) != want {
+		t.Errorf("Markdown:\n%s\nwant:\n%s", md, want)
+	}
+}
+
+func TestCompileCursorRules_Duplicate(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/review.md", Content: []byte("# Review\n")},
+		{CastDest: ".claude/skills/review/SKILL.md", Content: []byte("# Review\n")},
+	}
+	if _, err := CompileCursorRules(files); err == nil || !strings.Contains(err.Error(), "produced by both") {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+}
+
+func TestCursorAdapter(t *testing.T) {
+	dir := t.TempDir()
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/lint.md", Content: []byte("---\nglobs: [\"*.ts\"]\n---\nBody")},
+		{CastDest: ".claude/commands/lint/ref.md", Content: []byte("resource")},
+	}
+	written, err := blanks.Adapt(CursorAdapter{}, files, blanks.AdaptOptions{Root: dir})
+	if err != nil {
+		t.Fatalf("Adapt: %v", err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("written = %+v", written)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".cursor", "rules", "lint.mdc"))
+	if err != nil {
+		t.Fatalf("reading rule: %v", err)
+	}
+	if got := string(data); !strings.Contains(got, "globs: *.ts\n") || !strings.HasSuffix(got, "Body\n") {
+		t.Errorf("unexpected rule:\n%s", got)
+	}
+}
+
+func TestGenerator_Generate_CursorFormat(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "out")
+	g := NewGenerator(outputDir, testMoldReader())
+	g.Format = FormatCursor
+	if err := g.Generate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rulesDir := filepath.Join(outputDir, ".cursor", "rules")
+	if _, err := os.Stat(filepath.Join(rulesDir, "test.mdc")); err != nil {
+		t.Errorf("expected test.mdc: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rulesDir, "ci.mdc")); err == nil {
+		t.Error("non-markdown blanks should not become rules")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, ".claude-plugin")); err == nil {
+		t.Error("cursor format should not write a Claude plugin")
+	}
+}
+
+func TestGenerator_Generate_UnknownFormat(t *testing.T) {
+	g := NewGenerator(t.TempDir(), testMoldReader())
+	g.Format = "zed"
+	if err := g.Generate(); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
@@ -0,0 +1,170 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies how a file differs between two plugin directories.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"    // only in the generated plugin
+	ChangeRemoved  ChangeKind = "removed"  // only in the installed plugin
+	ChangeModified ChangeKind = "modified" // in both, content differs
+)
+
+// FileChange is a single differing file, keyed by its plugin-relative path.
+type FileChange struct {
+	Path string
+	Kind ChangeKind
+	// LinesAdded / LinesRemoved approximate the size of a modification by
+	// counting lines present in only one side. Zero for added/removed files.
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// IsCommand reports whether the change is to a command file.
+func (c FileChange) IsCommand() bool {
+	return strings.HasPrefix(c.Path, "commands/") && strings.HasSuffix(c.Path, ".md")
+}
+
+// CommandName returns the slash-command name for a command change.
+func (c FileChange) CommandName() string {
+	return strings.TrimSuffix(strings.TrimPrefix(c.Path, "commands/"), ".md")
+}
+
+// DiffResult compares a freshly generated plugin with an installed copy.
+type DiffResult struct {
+	GeneratedVersion string
+	InstalledVersion string
+	Changes          []FileChange
+}
+
+// HasChanges reports whether the installed plugin differs at all.
+func (d *DiffResult) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// CommandChanges returns only the changes to commands/*.md.
+func (d *DiffResult) CommandChanges() []FileChange {
+	var out []FileChange
+	for _, c := range d.Changes {
+		if c.IsCommand() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// VersionUnchanged reports whether content differs while plugin.json's
+// version stayed the same — users' Claude Code may then keep the stale copy.
+func (d *DiffResult) VersionUnchanged() bool {
+	return d.HasChanges() && d.GeneratedVersion != "" && d.GeneratedVersion == d.InstalledVersion
+}
+
+// Diff compares the generated plugin at generatedDir against the installed
+// plugin at installedDir. Both directories must exist.
+func Diff(generatedDir, installedDir string) (*DiffResult, error) {
+	gen, err := readPluginTree(generatedDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading generated plugin: %w", err)
+	}
+	inst, err := readPluginTree(installedDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading installed plugin: %w", err)
+	}
+
+	res := &DiffResult{
+		GeneratedVersion: manifestVersion(gen),
+		InstalledVersion: manifestVersion(inst),
+	}
+	for p, g := range gen {
+		i, ok := inst[p]
+		switch {
+		case !ok:
+			res.Changes = append(res.Changes, FileChange{Path: p, Kind: ChangeAdded})
+		case !bytes.Equal(g, i):
+			added, removed := lineDelta(i, g)
+			res.Changes = append(res.Changes, FileChange{Path: p, Kind: ChangeModified, LinesAdded: added, LinesRemoved: removed})
+		}
+	}
+	for p := range inst {
+		if _, ok := gen[p]; !ok {
+			res.Changes = append(res.Changes, FileChange{Path: p, Kind: ChangeRemoved})
+		}
+	}
+	sort.Slice(res.Changes, func(a, b int) bool { return res.Changes[a].Path < res.Changes[b].Path })
+	return res, nil
+}
+
+// readPluginTree loads every regular file under dir keyed by slash path.
+func readPluginTree(dir string) (map[string][]byte, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	files := make(map[string][]byte)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p) // #nosec G304 -- walking the user's plugin directory
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+func manifestVersion(files map[string][]byte) string {
+	data, ok := files[".claude-plugin/plugin.json"]
+	if !ok {
+		return ""
+	}
+	var m struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(data, &m) != nil {
+		return ""
+	}
+	return m.Version
+}
+
+// lineDelta counts lines only in after (added) and only in before (removed),
+// treating each side as a multiset of lines.
+func lineDelta(before, after []byte) (added, removed int) {
+	counts := make(map[string]int)
+	for _, l := range strings.Split(string(before), "\n") {
+		counts[l]++
+	}
+	for _, l := range strings.Split(string(after), "\n") {
+		if counts[l] > 0 {
+			counts[l]--
+			continue
+		}
+		added++
+	}
+	for _, n := range counts {
+		removed += n
+	}
+	return added, removed
+}
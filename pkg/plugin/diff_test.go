@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for p, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiff_ReportsCommandChanges(t *testing.T) {
+	generated := writeTree(t, map[string]string{
+		".claude-plugin/plugin.json": `{"name":"p","version":"1.1.0"}`,
+		"commands/same.md":           "same\n",
+		"commands/changed.md":        "line one\nline two new\n",
+		"commands/new.md":            "brand new\n",
+		"README.md":                  "readme\n",
+	})
+	installed := writeTree(t, map[string]string{
+		".claude-plugin/plugin.json": `{"name":"p","version":"1.0.0"}`,
+		"commands/same.md":           "same\n",
+		"commands/changed.md":        "line one\nline two\n",
+		"commands/gone.md":           "old\n",
+		"README.md":                  "readme\n",
+	})
+
+	res, err := Diff(generated, installed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GeneratedVersion != "1.1.0" || res.InstalledVersion != "1.0.0" {
+		t.Errorf("versions = %s/%s", res.GeneratedVersion, res.InstalledVersion)
+	}
+	if res.VersionUnchanged() {
+		t.Error("version was bumped; VersionUnchanged should be false")
+	}
+
+	want := map[string]ChangeKind{
+		"commands/changed.md": ChangeModified,
+		"commands/gone.md":    ChangeRemoved,
+		"commands/new.md":     ChangeAdded,
+	}
+	cmds := res.CommandChanges()
+	if len(cmds) != len(want) {
+		t.Fatalf("command changes = %+v, want %v", cmds, want)
+	}
+	for _, c := range cmds {
+		if want[c.Path] != c.Kind {
+			t.Errorf("%s: kind %s, want %s", c.Path, c.Kind, want[c.Path])
+		}
+		if c.Path == "commands/changed.md" && (c.LinesAdded != 1 || c.LinesRemoved != 1) {
+			t.Errorf("changed.md delta = +%d/-%d, want +1/-1", c.LinesAdded, c.LinesRemoved)
+		}
+	}
+	// plugin.json differs too (version bump) — it's a non-command change.
+	if len(res.Changes) != 4 {
+		t.Errorf("expected 4 total changes, got %+v", res.Changes)
+	}
+}
+
+func TestDiff_IdenticalAndVersionUnchanged(t *testing.T) {
+	files := map[string]string{
+		".claude-plugin/plugin.json": `{"name":"p","version":"1.0.0"}`,
+		"commands/a.md":              "a\n",
+	}
+	res, err := Diff(writeTree(t, files), writeTree(t, files))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.HasChanges() {
+		t.Errorf("expected no changes, got %+v", res.Changes)
+	}
+
+	files["commands/a.md"] = "a changed\n"
+	changed := writeTree(t, files)
+	files["commands/a.md"] = "a\n"
+	res, err = Diff(changed, writeTree(t, files))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.VersionUnchanged() {
+		t.Error("content changed without a version bump; expected VersionUnchanged")
+	}
+}
+
+func TestDiff_MissingInstalled(t *testing.T) {
+	if _, err := Diff(t.TempDir(), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing installed plugin")
+	}
+}
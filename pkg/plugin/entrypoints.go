@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Entrypoint kinds recognized by collectEntrypoints.
+const (
+	entryCommand = "command" // .claude/commands/<name>.md
+	entrySkill   = "skill"   // .claude/skills/<name>/SKILL.md
+	entryNative  = "native"  // already in the target tool's layout
+)
+
+// entrypoint is a rendered blank that converts into one file of another
+// tool's format.
+type entrypoint struct {
+	Name string
+	Kind string
+	File RenderedFile
+}
+
+// classifyEntrypoint reports whether the cast destination dest is a
+// command blank, a skill entrypoint, or a file directly under nativePrefix
+// (with one of nativeExts), and the name it converts under. Resources and
+// other files are not entrypoints.
+func classifyEntrypoint(dest, nativePrefix string, nativeExts ...string) (name, kind string, ok bool) {
+	dest = filepath.ToSlash(dest)
+	switch {
+	case strings.HasPrefix(dest, skillCommandsPrefix):
+		rest := strings.TrimPrefix(dest, skillCommandsPrefix)
+		if strings.Contains(rest, "/") || path.Ext(rest) != ".md" {
+			return "", "", false
+		}
+		return strings.TrimSuffix(rest, ".md"), entryCommand, true
+	case strings.HasPrefix(dest, skillSkillsPrefix):
+		skill, rel, found := strings.Cut(strings.TrimPrefix(dest, skillSkillsPrefix), "/")
+		if !found || rel != skillEntrypointName {
+			return "", "", false
+		}
+		return skill, entrySkill, true
+	case nativePrefix != "" && strings.HasPrefix(dest, nativePrefix):
+		rest := strings.TrimPrefix(dest, nativePrefix)
+		ext := path.Ext(rest)
+		if strings.Contains(rest, "/") || !containsName(nativeExts, ext) {
+			return "", "", false
+		}
+		return strings.TrimSuffix(rest, ext), entryNative, true
+	}
+	return "", "", false
+}
+
+// collectEntrypoints picks the entrypoints (see classifyEntrypoint) out of a
+// rendered mold. Two entrypoints with the same name are an error; noun names
+// the output in that message. Entrypoints are returned sorted by name.
+func collectEntrypoints(files []RenderedFile, noun, nativePrefix string, nativeExts ...string) ([]entrypoint, error) {
+	byName := make(map[string]entrypoint)
+	for _, rf := range files {
+		name, kind, ok := classifyEntrypoint(rf.CastDest, nativePrefix, nativeExts...)
+		if !ok {
+			continue
+		}
+		e := entrypoint{Name: name, Kind: kind, File: rf}
+		if prev, dup := byName[e.Name]; dup {
+			return nil, fmt.Errorf("%s %s is produced by both %s and %s", noun, e.Name, prev.File.CastDest, rf.CastDest)
+		}
+		byName[e.Name] = e
+	}
+
+	out := make([]entrypoint, 0, len(byName))
+	for _, e := range byName {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// carriedFrontmatter parses a blank's frontmatter and returns its
+// description, the values of the keep fields (in source order), and the
+// body. A missing description falls back to the body's Purpose section,
+// then its first paragraph.
+func carriedFrontmatter(content []byte, source string, keep ...string) (string, []frontmatterField, string, error) {
+	fm, body, err := splitFrontmatter(content)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("parsing frontmatter in %s: %w", source, err)
+	}
+	var desc string
+	var fields []frontmatterField
+	for _, item := range fm {
+		key := fmt.Sprint(item.Key)
+		switch {
+		case key == "description":
+			desc = strings.TrimSpace(fmt.Sprint(item.Value))
+		case containsName(keep, key):
+			fields = append(fields, frontmatterField{Key: key, Value: item.Value})
+		}
+	}
+	if desc == "" {
+		desc = fallbackDescription(body)
+	}
+	return desc, fields, body, nil
+}
+
+// fallbackDescription describes a blank without a frontmatter description:
+// the first line of its Purpose section, else its first paragraph.
+func fallbackDescription(body string) string {
+	if purpose := purposeLine(body); purpose != "" {
+		return purpose
+	}
+	return firstParagraph(body)
+}
+
+// frontmatterField is one key/value pair carried from a blank's frontmatter.
+type frontmatterField struct {
+	Key   string
+	Value any
+}
+
+// frontmatterDocument joins marshaled frontmatter and a markdown body.
+func frontmatterDocument(frontmatter []byte, body string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(skillFrontmatterSep + "\n")
+	buf.Write(frontmatter)
+	buf.WriteString(skillFrontmatterSep + "\n\n")
+	buf.WriteString(strings.TrimLeft(body, "\n"))
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// InstructionSection is a skill folded into a tool's standing-instructions
+// file (AGENTS.md, .windsurfrules) for tools without skills of their own.
+type InstructionSection struct {
+	Name        string
+	Description string
+	Body        string
+	Source      string
+}
+
+// skillSection builds an InstructionSection from a skill entrypoint.
+func skillSection(e entrypoint) (*InstructionSection, error) {
+	desc, _, body, err := carriedFrontmatter(e.File.Content, e.File.CastDest)
+	if err != nil {
+		return nil, err
+	}
+	return &InstructionSection{Name: e.Name, Description: desc, Body: body, Source: e.File.CastDest}, nil
+}
+
+// rootAgentsMarkdown returns the mold's rendered root AGENTS.md, if any.
+func rootAgentsMarkdown(files []RenderedFile) []byte {
+	for _, rf := range files {
+		if filepath.ToSlash(rf.CastDest) == "AGENTS.md" {
+			return rf.Content
+		}
+	}
+	return nil
+}
+
+// instructionsMarkdown renders preamble followed by one "## <name>" section
+// per skill, with the skill's description and its headings nested beneath
+// it. Empty when there is nothing to render.
+func instructionsMarkdown(preamble []byte, sections []*InstructionSection) []byte {
+	var buf bytes.Buffer
+	if len(bytes.TrimSpace(preamble)) > 0 {
+		buf.Write(bytes.TrimRight(preamble, "\n"))
+		buf.WriteString("\n")
+	}
+	for _, s := range sections {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "## %s\n\n", s.Name)
+		if s.Description != "" {
+			buf.WriteString(s.Description + "\n\n")
+		}
+		buf.WriteString(strings.TrimRight(nestHeadings(strings.TrimLeft(s.Body, "\n"), 2), "\n"))
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// nestHeadings pushes markdown headings outside code fences down by levels,
+// capping at h6.
+func nestHeadings(body string, levels int) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, "#"))
+		rest := line[depth:]
+		if rest != "" && rest[0] != ' ' {
+			continue // e.g. "#hashtag", not a heading
+		}
+		lines[i] = strings.Repeat("#", min(depth+levels, 6)) + rest
+	}
+	return strings.Join(lines, "\n")
+}
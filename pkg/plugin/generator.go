@@ -1,10 +1,10 @@
 package plugin
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -12,12 +12,43 @@ import (
 	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
+// Output formats supported by Generator.
+const (
+	FormatClaude    = "claude"    // Claude Code plugin (default)
+	FormatCursor    = "cursor"    // Cursor rules under .cursor/rules/
+	FormatOpenCode  = "opencode"  // OpenCode commands under .opencode/command/ + opencode.json
+	FormatCodex     = "codex"     // Codex CLI prompts under .codex/prompts/ + AGENTS.md
+	FormatWindsurf  = "windsurf"  // Windsurf workflows under .windsurf/workflows/ + .windsurfrules
+	FormatJetBrains = "jetbrains" // JetBrains AI Assistant prompt library + rules under .aiassistant/
+)
+
+// Formats lists every Generator output format: FormatClaude followed by
+// the registered output adapters.
+func Formats() []string {
+	return append([]string{FormatClaude}, blanks.OutputAdapterNames()...)
+}
+
 // Generator handles the generation of Claude Code plugins from Ailloy blanks
 type Generator struct {
 	OutputDir string
 	Config    *Config
-	reader    *blanks.MoldReader
-	commands  []BlankInfo
+	// Format selects the output: FormatClaude (or empty) for a Claude Code
+	// plugin, or the name of a registered blanks.OutputAdapter for that
+	// tool's native layout.
+	Format string
+	// Files are the mold's blanks as cast's render pipeline produced them
+	// (flux layering, ingots, template processing). When nil, Generate
+	// renders the blanks itself against the mold's flux defaults.
+	Files []RenderedFile
+	// Readme is the rendered mold README.md for the plugin. When empty a
+	// README listing the plugin's commands is generated.
+	Readme []byte
+	// HadWorkflows is set by Generate when workflow blanks were left out
+	// of a Claude Code plugin, which can't bundle them.
+	HadWorkflows bool
+	reader       *blanks.MoldReader
+	moldName     string
+	commands     []BlankInfo
 }
 
 // Config represents the plugin configuration
@@ -40,6 +71,7 @@ type BlankInfo struct {
 	Name        string
 	Description string
 	Content     []byte
+	Dest        string // output-mapped destination path
 }
 
 // NewGenerator creates a new plugin generator
@@ -52,11 +84,27 @@ func NewGenerator(outputDir string, reader *blanks.MoldReader) *Generator {
 
 // Generate creates the complete plugin structure
 func (g *Generator) Generate() error {
+	if g.Format != "" && g.Format != FormatClaude {
+		adapter, ok := blanks.LookupOutputAdapter(g.Format)
+		if !ok {
+			return fmt.Errorf("unknown format %q (want one of %s)", g.Format, strings.Join(Formats(), ", "))
+		}
+		return g.generateAdapter(adapter)
+	}
+
 	// Load all blanks
 	if err := g.loadBlanks(); err != nil {
 		return fmt.Errorf("failed to load blanks: %w", err)
 	}
 
+	if g.Config == nil {
+		manifest, err := g.reader.LoadManifest()
+		if err != nil {
+			return fmt.Errorf("failed to load mold manifest: %w", err)
+		}
+		g.Config = configFromManifest(manifest)
+	}
+
 	// Create directory structure
 	if err := g.createStructure(); err != nil {
 		return fmt.Errorf("failed to create structure: %w", err)
@@ -67,9 +115,9 @@ func (g *Generator) Generate() error {
 		return fmt.Errorf("failed to generate manifest: %w", err)
 	}
 
-	// Transform and write commands
-	if err := g.generateCommands(); err != nil {
-		return fmt.Errorf("failed to generate commands: %w", err)
+	// Write commands, skills, agents and hooks at their plugin paths
+	if err := g.generateFiles(); err != nil {
+		return fmt.Errorf("failed to generate plugin files: %w", err)
 	}
 
 	// Generate README
@@ -77,11 +125,6 @@ func (g *Generator) Generate() error {
 		return fmt.Errorf("failed to generate README: %w", err)
 	}
 
-	// Generate hooks configuration
-	if err := g.generateHooks(); err != nil {
-		return fmt.Errorf("failed to generate hooks: %w", err)
-	}
-
 	// Generate installation script
 	if err := g.generateInstallScript(); err != nil {
 		return fmt.Errorf("failed to generate install script: %w", err)
@@ -90,39 +133,89 @@ func (g *Generator) Generate() error {
 	return nil
 }
 
-// loadBlanks loads all blanks from the mold reader using convention-based discovery.
+// loadBlanks loads the rendered blanks: Files when the caller rendered
+// them, else the mold's blanks rendered against its flux defaults.
 func (g *Generator) loadBlanks() error {
+	manifest, mErr := g.reader.LoadManifest()
+	if mErr == nil {
+		g.moldName = manifest.Name
+	}
+
+	files := g.Files
+	if files == nil {
+		var err error
+		if files, err = g.renderBlanks(manifest); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		name := strings.TrimSuffix(path.Base(filepath.ToSlash(f.CastDest)), path.Ext(f.CastDest))
+		g.commands = append(g.commands, BlankInfo{
+			Name:        name,
+			Description: extractDescription(f.Content),
+			Content:     f.Content,
+			Dest:        f.CastDest,
+		})
+	}
+
+	return nil
+}
+
+// renderBlanks renders the mold's blanks with its flux defaults (flux.yaml
+// and schema defaults), for callers that don't supply Files. Blanks that
+// render to nothing are skipped, as cast skips them.
+func (g *Generator) renderBlanks(manifest *mold.Mold) ([]RenderedFile, error) {
 	flux, err := g.reader.LoadFluxDefaults()
 	if err != nil {
 		flux = make(map[string]any)
 	}
-	if manifest, mErr := g.reader.LoadManifest(); mErr == nil {
+	if manifest != nil {
+		if len(manifest.Flux) > 0 {
+			flux = mold.ApplyFluxDefaults(manifest.Flux, flux)
+		}
 		mold.ApplyManifestOutputDefault(flux, manifest)
 	}
 
-	resolved, err := mold.ResolveFiles(flux["output"], g.reader.FS())
+	var opts []mold.ResolveOption
+	if patterns := mold.LoadIgnorePatterns(g.reader.FS(), manifest); len(patterns) > 0 {
+		opts = append(opts, mold.WithIgnorePatterns(patterns))
+	}
+	resolved, err := mold.ResolveFiles(flux["output"], g.reader.FS(), opts...)
 	if err != nil {
-		return fmt.Errorf("resolving files: %w", err)
+		return nil, fmt.Errorf("resolving files: %w", err)
 	}
 
+	files := make([]RenderedFile, 0, len(resolved))
 	for _, rf := range resolved {
 		content, err := fs.ReadFile(g.reader.FS(), rf.SrcPath)
 		if err != nil {
-			return fmt.Errorf("failed to load blank %s: %w", rf.SrcPath, err)
+			return nil, fmt.Errorf("failed to load blank %s: %w", rf.SrcPath, err)
 		}
-
-		// Extract description from content
-		desc := extractDescription(content)
-
-		name := strings.TrimSuffix(filepath.Base(rf.SrcPath), filepath.Ext(rf.SrcPath))
-		g.commands = append(g.commands, BlankInfo{
-			Name:        name,
-			Description: desc,
-			Content:     content,
-		})
+		if rf.Process {
+			rendered, err := mold.ProcessTemplate(string(content), mold.MergeSet(flux, rf.Set))
+			if err != nil {
+				return nil, fmt.Errorf("rendering %s: %w", rf.SrcPath, err)
+			}
+			if strings.TrimSpace(rendered) == "" {
+				continue
+			}
+			content = []byte(rendered)
+		}
+		files = append(files, RenderedFile{CastDest: rf.DestPath, Content: content})
 	}
+	return files, nil
+}
 
-	return nil
+// configFromManifest derives the plugin configuration from mold.yaml, as
+// cast --claude-plugin does.
+func configFromManifest(m *mold.Mold) *Config {
+	return &Config{
+		Name:        m.Name,
+		Version:     m.Version,
+		Description: m.Description,
+		Author:      Author{Name: m.Author.Name, URL: m.Author.URL},
+	}
 }
 
 // createStructure creates the plugin directory structure
@@ -144,85 +237,64 @@ func (g *Generator) createStructure() error {
 	return nil
 }
 
-// generateManifest creates the plugin.json file
+// generateManifest creates the plugin.json file with the fields cast
+// --claude-plugin writes (see writeManifest).
 func (g *Generator) generateManifest() error {
-	// Use only the fields documented in the official Claude Code plugin schema
-	// See: https://code.claude.com/docs/en/plugins
-	manifest := map[string]interface{}{
-		"name":        g.Config.Name,
-		"version":     g.Config.Version,
-		"description": g.Config.Description,
-		"author": map[string]string{
-			"name": g.Config.Author.Name,
-		},
-	}
+	return writeManifest(g.OutputDir, ManifestInput{
+		Name:        g.Config.Name,
+		Version:     g.Config.Version,
+		Description: g.Config.Description,
+		Author:      mold.Author{Name: g.Config.Author.Name},
+	})
+}
 
-	data, err := json.MarshalIndent(manifest, "", "  ")
-	if err != nil {
-		return err
+// generateFiles writes the rendered blanks at their plugin paths —
+// commands, skills, agents, hooks and AGENTS.md, laid out as cast
+// --claude-plugin lays them out. Workflow blanks are left out.
+func (g *Generator) generateFiles() error {
+	files := make([]RenderedFile, 0, len(g.commands))
+	for _, f := range g.blankFiles() {
+		if strings.HasPrefix(filepath.ToSlash(f.CastDest), ".github/workflows/") {
+			g.HadWorkflows = true
+			continue
+		}
+		files = append(files, f)
 	}
-
-	manifestPath := filepath.Join(g.OutputDir, ".claude-plugin", "plugin.json")
-	return os.WriteFile(manifestPath, data, 0644) // #nosec G306 -- Plugin manifest needs to be readable
+	return writePluginFiles(g.OutputDir, files)
 }
 
-// generateCommands transforms blanks into Claude Code commands
-func (g *Generator) generateCommands() error {
-	transformer := NewTransformer()
+// generateAdapter converts the blanks with adapter and writes its files
+// under OutputDir at their project paths, ready to copy into place.
+func (g *Generator) generateAdapter(adapter blanks.OutputAdapter) error {
+	if err := g.loadBlanks(); err != nil {
+		return fmt.Errorf("failed to load blanks: %w", err)
+	}
+	_, err := blanks.Adapt(adapter, g.blankFiles(), blanks.AdaptOptions{
+		Root:     g.OutputDir,
+		Scope:    blanks.ScopeBundle,
+		MoldName: g.moldName,
+	})
+	return err
+}
 
+// blankFiles presents the loaded blanks as rendered files at their
+// output-mapped destinations.
+func (g *Generator) blankFiles() []RenderedFile {
+	files := make([]RenderedFile, 0, len(g.commands))
 	for _, tmpl := range g.commands {
-		// Transform blank to command format
-		command, err := transformer.Transform(tmpl)
-		if err != nil {
-			return fmt.Errorf("failed to transform blank %s: %w", tmpl.Name, err)
-		}
-
-		// Write command file
-		cmdPath := filepath.Join(g.OutputDir, "commands", tmpl.Name+".md")
-		//#nosec G306 -- Command files need to be readable
-		if err := os.WriteFile(cmdPath, command, 0644); err != nil {
-			return fmt.Errorf("failed to write command %s: %w", tmpl.Name, err)
-		}
+		files = append(files, RenderedFile{CastDest: tmpl.Dest, Content: tmpl.Content})
 	}
-
-	return nil
+	return files
 }
 
 // generateREADME creates the plugin README
 func (g *Generator) generateREADME() error {
-	readme := g.buildREADME()
-	readmePath := filepath.Join(g.OutputDir, "README.md")
-	return os.WriteFile(readmePath, []byte(readme), 0644) // #nosec G306 -- README needs to be readable
-}
-
-// generateHooks creates the hooks configuration
-func (g *Generator) generateHooks() error {
-	hooks := map[string]interface{}{
-		"hooks": []map[string]string{
-			{
-				"name":        "pre-issue-create",
-				"event":       "command:before",
-				"pattern":     "/create-issue",
-				"action":      "validate-github-auth",
-				"description": "Ensure GitHub CLI is authenticated",
-			},
-			{
-				"name":        "variable-substitution",
-				"event":       "template:load",
-				"pattern":     "*",
-				"action":      "substitute-variables",
-				"description": "Replace template variables",
-			},
-		},
-	}
-
-	data, err := json.MarshalIndent(hooks, "", "  ")
-	if err != nil {
-		return err
+	readme := g.Readme
+	if len(readme) == 0 {
+		readme = []byte(g.buildREADME())
 	}
-
-	hooksPath := filepath.Join(g.OutputDir, "hooks", "hooks.json")
-	return os.WriteFile(hooksPath, data, 0644) // #nosec G306 -- Hooks config needs to be readable
+	readmePath := filepath.Join(g.OutputDir, "README.md")
+	return os.WriteFile(readmePath, readme, 0644) // #nosec G306 -- README needs to be readable
 }
 
 // generateInstallScript creates the installation script
@@ -301,6 +373,9 @@ func extractDescription(content []byte) string {
 func (g *Generator) buildREADME() string {
 	var cmdList strings.Builder
 	for _, tmpl := range g.commands {
+		if tmpl.Dest != "" && !strings.HasPrefix(filepath.ToSlash(tmpl.Dest), ".claude/commands/") {
+			continue
+		}
 		fmt.Fprintf(&cmdList, "| `/%s:%s` | %s |\n", g.Config.Name, tmpl.Name, tmpl.Description)
 	}
 
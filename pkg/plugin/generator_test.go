@@ -124,18 +124,12 @@ func TestGenerator_Generate_FullPlugin(t *testing.T) {
 		t.Error("expected README to mention Ailloy")
 	}
 
-	// Verify hooks
-	hooksPath := filepath.Join(outputDir, "hooks", "hooks.json")
-	hooksData, err := os.ReadFile(hooksPath)
-	if err != nil {
-		t.Fatalf("failed to read hooks: %v", err)
-	}
-	var hooks map[string]interface{}
-	if err := json.Unmarshal(hooksData, &hooks); err != nil {
-		t.Fatalf("failed to parse hooks: %v", err)
+	// Skills are packaged next to commands; workflows are left out.
+	if _, err := os.Stat(filepath.Join(outputDir, "skills", "brainstorm.md")); err != nil {
+		t.Errorf("expected skill in plugin: %v", err)
 	}
-	if _, ok := hooks["hooks"]; !ok {
-		t.Error("expected hooks key in hooks.json")
+	if !g.HadWorkflows {
+		t.Error("expected HadWorkflows for the mold's workflow blank")
 	}
 
 	// Verify install script
@@ -268,53 +262,61 @@ func TestGenerator_GenerateManifest(t *testing.T) {
 	}
 }
 
-func TestGenerator_GenerateCommands(t *testing.T) {
+func TestGenerator_GenerateFiles(t *testing.T) {
 	dir := t.TempDir()
-	outputDir := filepath.Join(dir, "commands-test")
+	outputDir := filepath.Join(dir, "files-test")
 
-	g := NewGenerator(outputDir, testMoldReader())
-	g.Config = &Config{
-		Name:    "cmd-test",
-		Version: "1.0.0",
-	}
+	fsys := fstest.MapFS{
+		"mold.yaml":            &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: render\nversion: 1.0.0\nflux:\n  - name: org\n    type: string\n    default: acme\n")},
+		"flux.yaml":            &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n  agents: .claude/agents\n  skills: .claude/skills\n  AGENTS.md: AGENTS.md\n  workflows: .github/workflows\n")},
+		"commands/deploy.md":   &fstest.MapFile{Data: []byte("---\ndescription: Deploy\n---\nDeploy for {{org}}.\n")},
+		"commands/empty.md":    &fstest.MapFile{Data: []byte("{{if false}}x{{end}}")},
+		"agents/reviewer.md":   &fstest.MapFile{Data: []byte("# Reviewer\n")},
+		"skills/lint/SKILL.md": &fstest.MapFile{Data: []byte("# Lint\n")},
+		"AGENTS.md":            &fstest.MapFile{Data: []byte("# Rules for {{org}}\n")},
+		"workflows/ci.yml":     &fstest.MapFile{Data: []byte("name: CI\n")},
+	}
+	g := NewGenerator(outputDir, blanks.NewMoldReader(fsys))
+	g.Config = &Config{Name: "files-test", Version: "1.0.0"}
 
-	// Create structure and load blanks
-	if err := g.createStructure(); err != nil {
-		t.Fatalf("failed to create structure: %v", err)
-	}
 	if err := g.loadBlanks(); err != nil {
 		t.Fatalf("failed to load blanks: %v", err)
 	}
-
-	err := g.generateCommands()
-	if err != nil {
+	if err := g.generateFiles(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// Verify commands were written
-	entries, err := os.ReadDir(filepath.Join(outputDir, "commands"))
-	if err != nil {
-		t.Fatalf("failed to read commands dir: %v", err)
+	// Blanks are rendered with the schema defaults and copied as is, at
+	// the paths cast --claude-plugin uses.
+	for rel, want := range map[string]string{
+		"commands/deploy.md":   "---\ndescription: Deploy\n---\nDeploy for acme.\n",
+		"agents/reviewer.md":   "# Reviewer\n",
+		"skills/lint/SKILL.md": "# Lint\n",
+		"AGENTS.md":            "# Rules for acme\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(outputDir, rel))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, got, err, want)
+		}
 	}
-
-	if len(entries) != len(g.commands) {
-		t.Errorf("expected %d commands, got %d", len(g.commands), len(entries))
+	if _, err := os.Stat(filepath.Join(outputDir, "commands", "empty.md")); !os.IsNotExist(err) {
+		t.Error("a blank that renders empty should be skipped")
+	}
+	if !g.HadWorkflows {
+		t.Error("expected HadWorkflows")
 	}
 
-	for _, entry := range entries {
-		content, err := os.ReadFile(filepath.Join(outputDir, "commands", entry.Name()))
-		if err != nil {
-			t.Errorf("failed to read command %s: %v", entry.Name(), err)
-			continue
-		}
-		if len(content) == 0 {
-			t.Errorf("command %s is empty", entry.Name())
-		}
-		// Each command should have been transformed
-		contentStr := string(content)
-		if !strings.Contains(contentStr, "## Instructions for Claude") {
-			t.Errorf("command %s missing instructions section", entry.Name())
-		}
+	// Pre-rendered Files replace the generator's own render.
+	g = NewGenerator(filepath.Join(dir, "prerendered"), blanks.NewMoldReader(fsys))
+	g.Files = []RenderedFile{{CastDest: ".claude/commands/deploy.md", Content: []byte("Deploy for globex.\n")}}
+	if err := g.loadBlanks(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.generateFiles(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "prerendered", "commands", "deploy.md")); string(got) != "Deploy for globex.\n" {
+		t.Errorf("deploy.md = %q, want the supplied render", got)
 	}
 }
 
@@ -381,42 +383,6 @@ func TestGenerator_BuildREADME(t *testing.T) {
 	}
 }
 
-func TestGenerator_GenerateHooks(t *testing.T) {
-	dir := t.TempDir()
-	outputDir := filepath.Join(dir, "hooks-test")
-
-	g := NewGenerator(outputDir, testMoldReader())
-	g.Config = &Config{Name: "hooks-test"}
-
-	if err := g.createStructure(); err != nil {
-		t.Fatalf("failed to create structure: %v", err)
-	}
-
-	err := g.generateHooks()
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	hooksPath := filepath.Join(outputDir, "hooks", "hooks.json")
-	data, err := os.ReadFile(hooksPath)
-	if err != nil {
-		t.Fatalf("failed to read hooks: %v", err)
-	}
-
-	var hooks map[string]interface{}
-	if err := json.Unmarshal(data, &hooks); err != nil {
-		t.Fatalf("invalid JSON in hooks: %v", err)
-	}
-
-	hooksList, ok := hooks["hooks"].([]interface{})
-	if !ok {
-		t.Fatal("expected hooks to be an array")
-	}
-	if len(hooksList) < 2 {
-		t.Errorf("expected at least 2 hooks, got %d", len(hooksList))
-	}
-}
-
 func TestGenerator_GenerateInstallScript(t *testing.T) {
 	dir := t.TempDir()
 	outputDir := filepath.Join(dir, "script-test")
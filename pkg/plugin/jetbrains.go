@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+const (
+	jetBrainsRulesPrefix   = ".aiassistant/rules/"
+	jetBrainsPromptsPrefix = ".aiassistant/prompts/"
+)
+
+// JetBrainsPrompt is one entry in a JetBrains AI Assistant prompt library.
+// AI Assistant substitutes $SELECTION where Claude Code commands use
+// $ARGUMENTS, so the placeholder is rewritten.
+type JetBrainsPrompt struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Content     string `json:"content"`
+	// Source is the cast destination the prompt was compiled from.
+	Source string `json:"-"`
+}
+
+// JetBrainsPromptLibrary is the prompt library file written per mold
+// (.aiassistant/prompts/<mold>.json), for import into AI Assistant's
+// Prompt Library settings.
+type JetBrainsPromptLibrary struct {
+	Prompts []*JetBrainsPrompt `json:"prompts"`
+}
+
+// jetBrainsRuleMarkdown renders an AI Assistant project rule
+// (.aiassistant/rules/<name>.md). Rules carry no frontmatter — when to apply
+// them is configured in the IDE — so the description leads the body.
+func jetBrainsRuleMarkdown(s *InstructionSection) []byte {
+	var b strings.Builder
+	if s.Description != "" {
+		b.WriteString(s.Description + "\n\n")
+	}
+	b.WriteString(strings.TrimLeft(s.Body, "\n"))
+	if !strings.HasSuffix(s.Body, "\n") {
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// JetBrainsOutput is a mold converted for JetBrains AI Assistant.
+type JetBrainsOutput struct {
+	Library JetBrainsPromptLibrary
+	// Rules are written one file each under .aiassistant/rules/.
+	Rules []*InstructionSection
+}
+
+// CompileJetBrains converts rendered blanks for JetBrains AI Assistant.
+// Command blanks become prompt library entries; skill entrypoints and
+// blanks already cast under .aiassistant/rules/ become project rules.
+// Resources and other files are ignored.
+func CompileJetBrains(files []RenderedFile) (*JetBrainsOutput, error) {
+	entries, err := collectEntrypoints(files, "prompt or rule", jetBrainsRulesPrefix, ".md")
+	if err != nil {
+		return nil, err
+	}
+	out := &JetBrainsOutput{Library: JetBrainsPromptLibrary{Prompts: []*JetBrainsPrompt{}}}
+	for _, e := range entries {
+		if e.Kind != entryCommand {
+			s, err := skillSection(e)
+			if err != nil {
+				return nil, err
+			}
+			out.Rules = append(out.Rules, s)
+			continue
+		}
+		desc, _, body, err := carriedFrontmatter(e.File.Content, e.File.CastDest)
+		if err != nil {
+			return nil, err
+		}
+		out.Library.Prompts = append(out.Library.Prompts, &JetBrainsPrompt{
+			Name:        e.Name,
+			Description: desc,
+			Content:     strings.TrimSpace(strings.ReplaceAll(body, "$ARGUMENTS", "$SELECTION")),
+			Source:      e.File.CastDest,
+		})
+	}
+	return out, nil
+}
+
+// JetBrainsAdapter is the "jetbrains" output adapter: a prompt library file
+// per mold under .aiassistant/prompts/ and project rules under
+// .aiassistant/rules/. Project-only.
+type JetBrainsAdapter struct{}
+
+// Name implements blanks.OutputAdapter.
+func (JetBrainsAdapter) Name() string { return FormatJetBrains }
+
+// Title implements blanks.TitledAdapter.
+func (JetBrainsAdapter) Title() string { return "JetBrains AI Assistant prompts" }
+
+// Hint implements blanks.HintAdapter.
+func (JetBrainsAdapter) Hint() string {
+	return "Import the prompt library under Settings | Tools | AI Assistant | Prompt Library."
+}
+
+// MapDestination implements blanks.OutputAdapter. Commands are left to
+// PostInstall, which gathers them into the prompt library.
+func (JetBrainsAdapter) MapDestination(dest string) (string, bool) {
+	name, kind, ok := classifyEntrypoint(dest, jetBrainsRulesPrefix, ".md")
+	if !ok || kind == entryCommand {
+		return "", false
+	}
+	return jetBrainsRulesPrefix + name + ".md", true
+}
+
+// TransformContent implements blanks.OutputAdapter.
+func (JetBrainsAdapter) TransformContent(dest string, content []byte) ([]byte, error) {
+	name, kind, _ := classifyEntrypoint(dest, jetBrainsRulesPrefix, ".md")
+	s, err := skillSection(entrypoint{Name: name, Kind: kind, File: RenderedFile{CastDest: dest, Content: content}})
+	if err != nil {
+		return nil, err
+	}
+	return jetBrainsRuleMarkdown(s), nil
+}
+
+// PostInstall implements blanks.OutputAdapter: it writes the command blanks
+// as the mold's prompt library.
+func (JetBrainsAdapter) PostInstall(ctx *blanks.InstallContext) error {
+	out, err := CompileJetBrains(ctx.Rendered)
+	if err != nil {
+		return err
+	}
+	if len(out.Library.Prompts) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(out.Library, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling prompt library: %w", err)
+	}
+	return ctx.Write(jetBrainsPromptsPrefix+ctx.MoldName+".json", append(data, '\n'), blanks.WriteReplace)
+}
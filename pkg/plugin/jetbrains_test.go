@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestJetBrainsAdapter(t *testing.T) {
+	dir := t.TempDir()
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/explain.md", Content: []byte("---\ndescription: Explains code.\n---\nExplain $ARGUMENTS.\n")},
+		{CastDest: ".claude/skills/style/SKILL.md", Content: []byte("---\ndescription: House style.\n---\nBe terse.\n")},
+		{CastDest: ".aiassistant/rules/native.md", Content: []byte("Native rule.\n")},
+	}
+	written, err := blanks.Adapt(JetBrainsAdapter{}, files, blanks.AdaptOptions{Root: dir, MoldName: "jb"})
+	if err != nil {
+		t.Fatalf("Adapt: %v", err)
+	}
+	if len(written) != 3 {
+		t.Fatalf("written = %+v", written)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".aiassistant", "prompts", "jb.json"))
+	if err != nil {
+		t.Fatalf("reading library: %v", err)
+	}
+	var lib JetBrainsPromptLibrary
+	if err := json.Unmarshal(data, &lib); err != nil {
+		t.Fatalf("library JSON: %v", err)
+	}
+	if len(lib.Prompts) != 1 || lib.Prompts[0].Content != "Explain $SELECTION." || lib.Prompts[0].Description != "Explains code." {
+		t.Errorf("unexpected library: %s", data)
+	}
+
+	rule, err := os.ReadFile(filepath.Join(dir, ".aiassistant", "rules", "style.md"))
+	if err != nil || string(rule) != "House style.\n\nBe terse.\n" {
+		t.Errorf("style rule = %q, err = %v", rule, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".aiassistant", "rules", "native.md")); err != nil {
+		t.Errorf("expected native rule: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Scopes of a plugin discovery directory.
+const (
+	ScopeProject = "project"
+	ScopeUser    = "user"
+)
+
+// ClaudeConfigDirEnv overrides the user-level Claude Code config directory
+// (~/.claude) on every OS.
+const ClaudeConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// Location is a directory Claude Code discovers plugins in; each plugin is a
+// subdirectory holding .claude-plugin/plugin.json.
+type Location struct {
+	Scope string `json:"scope" yaml:"scope"`
+	Dir   string `json:"dir" yaml:"dir"`
+}
+
+// InstalledPlugin is a plugin found in a discovery directory.
+type InstalledPlugin struct {
+	Name        string `json:"name" yaml:"name"`
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Scope       string `json:"scope" yaml:"scope"`
+	Dir         string `json:"dir" yaml:"dir"`
+}
+
+// UserPluginsDir returns the user-level plugins directory:
+// $CLAUDE_CONFIG_DIR/plugins when set, otherwise .claude/plugins under the
+// home directory ($HOME on macOS and Linux, %USERPROFILE% on Windows).
+func UserPluginsDir() (string, error) {
+	if dir := os.Getenv(ClaudeConfigDirEnv); dir != "" {
+		return filepath.Join(dir, "plugins"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude", "plugins"), nil
+}
+
+// ProjectPluginsDir returns the project-level plugins directory of projectDir.
+func ProjectPluginsDir(projectDir string) string {
+	return filepath.Join(projectDir, ".claude", "plugins")
+}
+
+// DiscoveryDirs returns the directories Claude Code discovers plugins in for
+// a session started in projectDir: the project's .claude/plugins, then the
+// user's. The project directory is left out when it is the user's.
+func DiscoveryDirs(projectDir string) ([]Location, error) {
+	user, err := UserPluginsDir()
+	if err != nil {
+		return nil, err
+	}
+	project := ProjectPluginsDir(projectDir)
+	locations := []Location{}
+	if !sameDir(project, user) {
+		locations = append(locations, Location{Scope: ScopeProject, Dir: project})
+	}
+	return append(locations, Location{Scope: ScopeUser, Dir: user}), nil
+}
+
+// ListInstalled returns the plugins in locations, in location order and by
+// directory name within each. Missing directories are skipped; a plugin
+// whose manifest can't be read is listed under its directory name.
+func ListInstalled(locations []Location) ([]InstalledPlugin, error) {
+	var plugins []InstalledPlugin
+	for _, loc := range locations {
+		entries, err := os.ReadDir(loc.Dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("reading %s: %w", loc.Dir, err)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			dir := filepath.Join(loc.Dir, entry.Name())
+			p, ok := ReadInstalled(dir)
+			if !ok {
+				continue
+			}
+			p.Scope = loc.Scope
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins, nil
+}
+
+// ReadInstalled reads the plugin in dir. The bool is false when dir has no
+// plugin manifest; a manifest that isn't valid JSON yields the directory
+// name with no version.
+func ReadInstalled(dir string) (InstalledPlugin, bool) {
+	p := InstalledPlugin{Name: filepath.Base(dir), Dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, ".claude-plugin", "plugin.json")) // #nosec G304 -- plugin dirs are discovered by the CLI
+	if err != nil {
+		return p, false
+	}
+	var manifest struct {
+		Name        string `json:"name"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	}
+	if json.Unmarshal(data, &manifest) == nil {
+		if manifest.Name != "" {
+			p.Name = manifest.Name
+		}
+		p.Version = manifest.Version
+		p.Description = manifest.Description
+	}
+	return p, true
+}
+
+// sameDir reports whether a and b name the same directory.
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserPluginsDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(ClaudeConfigDirEnv, "")
+
+	got, err := UserPluginsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".claude", "plugins"); got != want {
+		t.Errorf("UserPluginsDir() = %q, want %q", got, want)
+	}
+
+	config := t.TempDir()
+	t.Setenv(ClaudeConfigDirEnv, config)
+	if got, _ := UserPluginsDir(); got != filepath.Join(config, "plugins") {
+		t.Errorf("with %s set, UserPluginsDir() = %q", ClaudeConfigDirEnv, got)
+	}
+}
+
+func TestDiscoveryDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(ClaudeConfigDirEnv, "")
+
+	project := t.TempDir()
+	got, err := DiscoveryDirs(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Location{
+		{Scope: ScopeProject, Dir: filepath.Join(project, ".claude", "plugins")},
+		{Scope: ScopeUser, Dir: filepath.Join(home, ".claude", "plugins")},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("DiscoveryDirs = %+v, want %+v", got, want)
+	}
+
+	// From the home directory the project and user directories coincide.
+	if got, _ := DiscoveryDirs(home); len(got) != 1 || got[0].Scope != ScopeUser {
+		t.Errorf("DiscoveryDirs(home) = %+v, want only the user directory", got)
+	}
+}
+
+func TestListInstalled(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "plugins")
+	user := filepath.Join(t.TempDir(), "plugins")
+	writeInstalled(t, filepath.Join(project, "zeta"), `{"name": "zeta", "version": "2.0.0", "description": "Last"}`)
+	writeInstalled(t, filepath.Join(project, "alpha"), `{"name": "alpha-tools", "version": "1.0.0"}`)
+	writeInstalled(t, filepath.Join(user, "broken"), `{not json`)
+	if err := os.MkdirAll(filepath.Join(user, "not-a-plugin"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ListInstalled([]Location{
+		{Scope: ScopeProject, Dir: project},
+		{Scope: ScopeUser, Dir: user},
+		{Scope: ScopeUser, Dir: filepath.Join(t.TempDir(), "missing")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []InstalledPlugin{
+		{Name: "alpha-tools", Version: "1.0.0", Scope: ScopeProject, Dir: filepath.Join(project, "alpha")},
+		{Name: "zeta", Version: "2.0.0", Description: "Last", Scope: ScopeProject, Dir: filepath.Join(project, "zeta")},
+		{Name: "broken", Scope: ScopeUser, Dir: filepath.Join(user, "broken")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListInstalled = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("plugin %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func writeInstalled(t *testing.T, dir, manifest string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, ".claude-plugin"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".claude-plugin", "plugin.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
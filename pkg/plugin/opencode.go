@@ -0,0 +1,167 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+// OpenCodeConfigName is the project config file OpenCode reads.
+const OpenCodeConfigName = "opencode.json"
+
+const (
+	openCodeCommandPrefix = ".opencode/command/"
+	openCodeSchemaURL     = "https://opencode.ai/config.json"
+)
+
+// openCodeCommandFields are the frontmatter fields OpenCode understands on
+// a command besides description; they carry over from the blank.
+var openCodeCommandFields = []string{"agent", "model", "subtask"}
+
+// OpenCodeCommand is a single OpenCode custom command
+// (.opencode/command/<name>.md). Arguments use the same $ARGUMENTS and $1
+// placeholders as Claude Code commands, so bodies carry over unchanged.
+type OpenCodeCommand struct {
+	Name        string
+	Description string
+	// Body is the markdown following the frontmatter.
+	Body string
+	// Source is the cast destination the command was compiled from.
+	Source string
+
+	extra []frontmatterField
+}
+
+// Markdown renders the command file: description and any carried-over
+// agent/model/subtask frontmatter followed by the body.
+func (c *OpenCodeCommand) Markdown() ([]byte, error) {
+	fm := yaml.MapSlice{{Key: "description", Value: c.Description}}
+	for _, f := range c.extra {
+		fm = append(fm, yaml.MapItem{Key: f.Key, Value: f.Value})
+	}
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling frontmatter for command %s: %w", c.Name, err)
+	}
+	return frontmatterDocument(data, c.Body), nil
+}
+
+// OpenCodeTransformer converts Ailloy blanks to OpenCode command format.
+type OpenCodeTransformer struct{}
+
+// NewOpenCodeTransformer creates a new OpenCode command transformer.
+func NewOpenCodeTransformer() *OpenCodeTransformer {
+	return &OpenCodeTransformer{}
+}
+
+// Transform converts an Ailloy blank to an OpenCode command file.
+func (t *OpenCodeTransformer) Transform(tmpl BlankInfo) ([]byte, error) {
+	c, err := t.Command(tmpl.Name, tmpl.Content, tmpl.Name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Markdown()
+}
+
+// Command builds an OpenCodeCommand from blank content.
+func (t *OpenCodeTransformer) Command(name string, content []byte, source string) (*OpenCodeCommand, error) {
+	desc, extra, body, err := carriedFrontmatter(content, source, openCodeCommandFields...)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenCodeCommand{Name: name, Description: desc, Body: body, Source: source, extra: extra}, nil
+}
+
+// CompileOpenCodeCommands converts rendered blanks into OpenCode commands.
+// Command blanks, skill entrypoints, and blanks already cast under
+// .opencode/command/ become commands named after the blank; resources and
+// other files are ignored. Commands are returned sorted by name.
+func CompileOpenCodeCommands(files []RenderedFile) ([]*OpenCodeCommand, error) {
+	entries, err := collectEntrypoints(files, "command", openCodeCommandPrefix, ".md")
+	if err != nil {
+		return nil, err
+	}
+	t := NewOpenCodeTransformer()
+	commands := make([]*OpenCodeCommand, 0, len(entries))
+	for _, e := range entries {
+		c, err := t.Command(e.Name, e.File.Content, e.File.CastDest)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, c)
+	}
+	return commands, nil
+}
+
+// OpenCodeAdapter is the "opencode" output adapter: commands under
+// .opencode/command/ plus a minimal opencode.json when the project has none.
+// Global casts go to OpenCode's user config dir.
+type OpenCodeAdapter struct{}
+
+// Name implements blanks.OutputAdapter.
+func (OpenCodeAdapter) Name() string { return FormatOpenCode }
+
+// Title implements blanks.TitledAdapter.
+func (OpenCodeAdapter) Title() string { return "OpenCode commands" }
+
+// Hint implements blanks.HintAdapter.
+func (OpenCodeAdapter) Hint() string { return "Run them in OpenCode as /<name>." }
+
+// UserDir implements blanks.UserDirAdapter: $XDG_CONFIG_HOME/opencode, default
+// ~/.config/opencode.
+func (OpenCodeAdapter) UserDir() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "opencode"), nil
+}
+
+// UserPath implements blanks.UserDirAdapter.
+func (OpenCodeAdapter) UserPath(mapped string) (string, bool, bool) {
+	switch {
+	case strings.HasPrefix(mapped, openCodeCommandPrefix):
+		return "command/" + strings.TrimPrefix(mapped, openCodeCommandPrefix), false, true
+	case mapped == OpenCodeConfigName:
+		return OpenCodeConfigName, false, true
+	}
+	return "", false, false
+}
+
+// MapDestination implements blanks.OutputAdapter.
+func (OpenCodeAdapter) MapDestination(dest string) (string, bool) {
+	name, _, ok := classifyEntrypoint(dest, openCodeCommandPrefix, ".md")
+	if !ok {
+		return "", false
+	}
+	return openCodeCommandPrefix + name + ".md", true
+}
+
+// TransformContent implements blanks.OutputAdapter.
+func (OpenCodeAdapter) TransformContent(dest string, content []byte) ([]byte, error) {
+	name, _, _ := classifyEntrypoint(dest, openCodeCommandPrefix, ".md")
+	c, err := NewOpenCodeTransformer().Command(name, content, dest)
+	if err != nil {
+		return nil, err
+	}
+	return c.Markdown()
+}
+
+// PostInstall implements blanks.OutputAdapter: it creates a minimal
+// opencode.json when commands were written and none exists. An existing
+// config is never rewritten, since users keep their own settings in it.
+func (OpenCodeAdapter) PostInstall(ctx *blanks.InstallContext) error {
+	if len(ctx.Written) == 0 {
+		return nil
+	}
+	config := fmt.Sprintf("{\n  \"$schema\": %q\n}\n", openCodeSchemaURL)
+	return ctx.Write(OpenCodeConfigName, []byte(config), blanks.WriteIfMissing)
+}
@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestCompileOpenCodeCommands(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/review.md", Content: []byte("---\ndescription: Reviews a PR.\nagent: plan\nallowed-tools: Bash\n---\nReview $ARGUMENTS.\n")},
+		{CastDest: ".claude/skills/triage/SKILL.md", Content: []byte("# Triage\n\nTriages issues.\n")},
+		{CastDest: ".opencode/command/native.md", Content: []byte("---\ndescription: Native.\n---\nBody\n")},
+		{CastDest: "AGENTS.md", Content: []byte("agents")},
+	}
+
+	commands, err := CompileOpenCodeCommands(files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commands) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(commands))
+	}
+	md, err := commands[1].Markdown()
+	if err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	want := "---\ndescription: Reviews a PR.\nagent: plan\n---\n\nReview $ARGUMENTS.\n"
+	if commands[1].Name != "review" || string(md) != want {
+		t.Errorf("review command:\n%s\nwant:\n%s", md, want)
+	}
+	if commands[2].Name != "triage" || commands[2].Description != "Triages issues." {
+		t.Errorf("triage command = %+v", commands[2])
+	}
+}
+
+func TestOpenCodeAdapter_CreatesConfigOnce(t *testing.T) {
+	dir := t.TempDir()
+	files := []RenderedFile{{CastDest: ".claude/commands/hello.md", Content: []byte("Hello")}}
+	opts := blanks.AdaptOptions{Root: dir}
+	configPath := filepath.Join(dir, OpenCodeConfigName)
+
+	written, err := blanks.Adapt(OpenCodeAdapter{}, files, opts)
+	if err != nil || written[len(written)-1].Action != blanks.ActionCreated {
+		t.Fatalf("first Adapt: %+v, err=%v", written, err)
+	}
+	config, err := os.ReadFile(configPath)
+	if err != nil || !strings.Contains(string(config), openCodeSchemaURL) {
+		t.Fatalf("config = %q, err = %v", config, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".opencode", "command", "hello.md")); err != nil {
+		t.Fatalf("expected command file: %v", err)
+	}
+
+	custom := []byte("{\"model\": \"x\"}\n")
+	if err := os.WriteFile(configPath, custom, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	written, err = blanks.Adapt(OpenCodeAdapter{}, files, opts)
+	if err != nil || written[len(written)-1].Action != blanks.ActionKept {
+		t.Fatalf("second Adapt: %+v, err=%v", written, err)
+	}
+	if got, _ := os.ReadFile(configPath); string(got) != string(custom) {
+		t.Errorf("existing config rewritten: %s", got)
+	}
+}
+
+func TestOpenCodeAdapter_GlobalUsesXDGConfigHome(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	files := []RenderedFile{{CastDest: ".claude/commands/hello.md", Content: []byte("Hello")}}
+	if _, err := blanks.Adapt(OpenCodeAdapter{}, files, blanks.AdaptOptions{Root: t.TempDir(), Scope: blanks.ScopeGlobal}); err != nil {
+		t.Fatalf("Adapt: %v", err)
+	}
+	for _, rel := range []string{"command/hello.md", OpenCodeConfigName} {
+		if _, err := os.Stat(filepath.Join(configHome, "opencode", rel)); err != nil {
+			t.Errorf("expected %s: %v", rel, err)
+		}
+	}
+}
+
+func TestGenerator_Generate_OpenCodeFormat(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "out")
+	g := NewGenerator(outputDir, testMoldReader())
+	g.Format = FormatOpenCode
+	if err := g.Generate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, rel := range []string{".opencode/command/test.md", OpenCodeConfigName} {
+		if _, err := os.Stat(filepath.Join(outputDir, rel)); err != nil {
+			t.Errorf("expected %s: %v", rel, err)
+		}
+	}
+}
@@ -8,15 +8,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/nimble-giant/ailloy/pkg/blanks"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
 // RenderedFile is a single flux-rendered blank produced by cast, identified by
 // its intended cast destination (e.g. ".claude/commands/foo.md").
-type RenderedFile struct {
-	CastDest string
-	Content  []byte
-}
+type RenderedFile = blanks.RenderedFile
 
 // ManifestInput supplies the fields synthesized into .claude-plugin/plugin.json.
 type ManifestInput struct {
@@ -50,6 +48,28 @@ func (p *Packager) Package(files []RenderedFile, manifest ManifestInput, readme
 		return fmt.Errorf("creating plugin output dir: %w", err)
 	}
 
+	if err := writePluginFiles(p.OutputDir, files); err != nil {
+		return err
+	}
+
+	if err := writeManifest(p.OutputDir, manifest); err != nil {
+		return err
+	}
+
+	if len(readme) > 0 {
+		readmePath := filepath.Join(p.OutputDir, "README.md")
+		if err := os.WriteFile(readmePath, readme, 0o644); err != nil { // #nosec G306
+			return fmt.Errorf("writing README.md: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writePluginFiles writes each RenderedFile under outputDir at its plugin
+// internal path. Unrecognized destinations are dropped with a log line; two
+// files mapping to the same path are an error.
+func writePluginFiles(outputDir string, files []RenderedFile) error {
 	written := make(map[string]string) // plugin internal path -> source CastDest
 	for _, rf := range files {
 		internal, ok := translatePath(rf.CastDest)
@@ -62,7 +82,7 @@ func (p *Packager) Package(files []RenderedFile, manifest ManifestInput, readme
 		}
 		written[internal] = rf.CastDest
 
-		dest := filepath.Join(p.OutputDir, internal)
+		dest := filepath.Join(outputDir, internal)
 		if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil { // #nosec G301
 			return fmt.Errorf("creating dir for %s: %w", dest, err)
 		}
@@ -70,18 +90,6 @@ func (p *Packager) Package(files []RenderedFile, manifest ManifestInput, readme
 			return fmt.Errorf("writing %s: %w", dest, err)
 		}
 	}
-
-	if err := writeManifest(p.OutputDir, manifest); err != nil {
-		return err
-	}
-
-	if len(readme) > 0 {
-		readmePath := filepath.Join(p.OutputDir, "README.md")
-		if err := os.WriteFile(readmePath, readme, 0o644); err != nil { // #nosec G306
-			return fmt.Errorf("writing README.md: %w", err)
-		}
-	}
-
 	return nil
 }
 
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 )
 
@@ -91,6 +90,7 @@ When this command is invoked, you must do custom things.
 
 	// Step 3: Update the plugin
 	u := NewUpdater(outputDir, testMoldReader())
+	u.Generator.Config = g.Config
 
 	// Backup first
 	if err := u.Backup(); err != nil {
@@ -185,38 +185,6 @@ func TestIntegration_PluginBackupRestoreCycle(t *testing.T) {
 	}
 }
 
-func TestIntegration_BlankTransformationConsistency(t *testing.T) {
-	// Test that all embedded blanks can be loaded and transformed successfully
-	g := NewGenerator(t.TempDir(), testMoldReader())
-	if err := g.loadBlanks(); err != nil {
-		t.Fatalf("failed to load blanks: %v", err)
-	}
-
-	tr := NewTransformer()
-
-	for _, tmpl := range g.commands {
-		t.Run(tmpl.Name, func(t *testing.T) {
-			output, err := tr.Transform(tmpl)
-			if err != nil {
-				t.Fatalf("transform failed for %s: %v", tmpl.Name, err)
-			}
-
-			content := string(output)
-
-			// Every transformed command must have these sections
-			if !strings.Contains(content, "# "+tmpl.Name) {
-				t.Errorf("missing command header for %s", tmpl.Name)
-			}
-			if !strings.Contains(content, "description:") {
-				t.Errorf("missing description for %s", tmpl.Name)
-			}
-			if !strings.Contains(content, "## Instructions for Claude") {
-				t.Errorf("missing instructions section for %s", tmpl.Name)
-			}
-		})
-	}
-}
-
 func TestIntegration_ManifestSchemaConsistency(t *testing.T) {
 	dir := t.TempDir()
 	outputDir := filepath.Join(dir, "schema-test")
@@ -0,0 +1,50 @@
+package plugin
+
+import "strings"
+
+// Prompt is a command blank or skill entrypoint offered as a reusable prompt
+// (see `ailloy mcp serve`).
+type Prompt struct {
+	Name        string
+	Description string
+	// Kind is "command" or "skill".
+	Kind string
+	// Body is the markdown following the frontmatter.
+	Body string
+	// Source is the cast destination the prompt was compiled from.
+	Source string
+}
+
+// Expand returns the prompt text with $ARGUMENTS replaced by args. A body
+// without the placeholder gets non-empty args appended, as Claude Code does
+// for slash commands.
+func (p *Prompt) Expand(args string) string {
+	body := strings.TrimSpace(p.Body)
+	if strings.Contains(body, "$ARGUMENTS") {
+		return strings.ReplaceAll(body, "$ARGUMENTS", args)
+	}
+	if args != "" {
+		body += "\n\nARGUMENTS: " + args
+	}
+	return body
+}
+
+// CompilePrompts picks the command blanks and skill entrypoints out of
+// rendered files, sorted by name. Other files are ignored.
+func CompilePrompts(files []RenderedFile) ([]*Prompt, error) {
+	entries, err := collectEntrypoints(files, "prompt", "")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Prompt, 0, len(entries))
+	for _, e := range entries {
+		desc, _, body, err := carriedFrontmatter(e.File.Content, e.File.CastDest)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &Prompt{
+			Name: e.Name, Description: desc, Kind: e.Kind, Body: body, Source: e.File.CastDest,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package plugin
+
+import "testing"
+
+func TestCompilePrompts(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/review.md", Content: []byte("---\ndescription: Review a PR\n---\nReview $ARGUMENTS carefully.\n")},
+		{CastDest: ".claude/skills/style/SKILL.md", Content: []byte("---\nname: style\n---\n## Purpose\nHouse style.\n")},
+		{CastDest: ".claude/skills/style/ref.md", Content: []byte("resource\n")},
+		{CastDest: "CLAUDE.md", Content: []byte("# Project\n")},
+	}
+	prompts, err := CompilePrompts(files)
+	if err != nil {
+		t.Fatalf("CompilePrompts: %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("expected 2 prompts, got %d", len(prompts))
+	}
+	review, style := prompts[0], prompts[1]
+	if review.Name != "review" || review.Kind != "command" || review.Description != "Review a PR" {
+		t.Errorf("review = %+v", review)
+	}
+	if style.Name != "style" || style.Kind != "skill" || style.Description != "House style." {
+		t.Errorf("style = %+v", style)
+	}
+
+	if got := review.Expand("#42"); got != "Review #42 carefully." {
+		t.Errorf("Expand with placeholder = %q", got)
+	}
+	if got := style.Expand("docs"); got != "## Purpose\nHouse style.\n\nARGUMENTS: docs" {
+		t.Errorf("Expand without placeholder = %q", got)
+	}
+	if got := style.Expand(""); got != "## Purpose\nHouse style." {
+		t.Errorf("Expand with no args = %q", got)
+	}
+}
@@ -0,0 +1,240 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
+)
+
+// ErrClaudeNotInstalled is returned by RuntimeVerifier when the claude CLI
+// cannot be found on PATH.
+var ErrClaudeNotInstalled = errors.New("claude CLI not found on PATH; install Claude Code to use runtime verification")
+
+// DefaultRuntimeTimeout bounds each claude invocation made during runtime
+// verification.
+const DefaultRuntimeTimeout = 2 * time.Minute
+
+// ClaudeRunner abstracts invoking the claude CLI so runtime verification can
+// be tested without Claude Code installed.
+type ClaudeRunner interface {
+	// Run executes claude with args inside dir and returns combined output.
+	Run(ctx context.Context, dir string, args []string) ([]byte, error)
+}
+
+// ExecClaudeRunner runs the real claude binary.
+type ExecClaudeRunner struct {
+	// Bin is the claude executable; "" means look up "claude" on PATH.
+	Bin string
+}
+
+// Run implements ClaudeRunner.
+func (r *ExecClaudeRunner) Run(ctx context.Context, dir string, args []string) ([]byte, error) {
+	bin := r.Bin
+	if bin == "" {
+		p, err := exec.LookPath("claude")
+		if err != nil {
+			return nil, ErrClaudeNotInstalled
+		}
+		bin = p
+	}
+	cmd := exec.CommandContext(ctx, bin, args...) // #nosec G204 -- fixed claude subcommands; plugin path is the user's own
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// RuntimeVerifier loads a plugin into the locally installed Claude Code and
+// confirms its commands register. It complements Validator: static checks
+// follow ailloy's understanding of the plugin spec, while runtime checks ask
+// Claude Code itself, catching spec drift between the two.
+//
+// Verification runs inside a throwaway sandbox project directory so the
+// user's real project settings, CLAUDE.md, and installed plugins don't
+// influence the result.
+type RuntimeVerifier struct {
+	PluginPath string
+	Runner     ClaudeRunner
+	Timeout    time.Duration
+}
+
+// RuntimeResult reports the outcome of a runtime verification.
+type RuntimeResult struct {
+	ClaudeVersion string
+	// Expected are the command names found in the plugin's commands/ dir.
+	Expected []string
+	// Registered are the expected commands Claude Code reported as loaded.
+	Registered []string
+	// Missing are expected commands Claude Code did not register.
+	Missing []string
+	Errors  []string
+}
+
+// IsValid reports whether Claude Code loaded the plugin without errors and
+// registered every command.
+func (r *RuntimeResult) IsValid() bool {
+	return len(r.Errors) == 0 && len(r.Missing) == 0
+}
+
+// NewRuntimeVerifier creates a verifier using the real claude CLI.
+func NewRuntimeVerifier(pluginPath string) *RuntimeVerifier {
+	return &RuntimeVerifier{
+		PluginPath: pluginPath,
+		Runner:     &ExecClaudeRunner{},
+		Timeout:    DefaultRuntimeTimeout,
+	}
+}
+
+// Verify runs `claude plugin validate` against the plugin, then starts a
+// single non-interactive session with the plugin loaded and compares the
+// slash commands Claude Code reports at init with the plugin's commands/.
+// A missing claude CLI is returned as ErrClaudeNotInstalled; problems
+// Claude Code reports are collected into RuntimeResult.Errors.
+func (v *RuntimeVerifier) Verify(ctx context.Context) (*RuntimeResult, error) {
+	absPlugin, err := filepath.Abs(v.PluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolving plugin path: %w", err)
+	}
+	if _, err := os.Stat(absPlugin); err != nil {
+		return nil, fmt.Errorf("plugin directory not found: %s", v.PluginPath)
+	}
+
+	result := &RuntimeResult{}
+	result.Expected, err = pluginCommandNames(absPlugin)
+	if err != nil {
+		return nil, err
+	}
+
+	sandbox, err := tmpdir.MkdirTemp("plugin-verify-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating sandbox project: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(sandbox) }()
+
+	out, err := v.run(ctx, sandbox, "--version")
+	if err != nil {
+		if errors.Is(err, ErrClaudeNotInstalled) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("running claude --version: %w", err)
+	}
+	result.ClaudeVersion = strings.TrimSpace(string(out))
+
+	if out, err := v.run(ctx, sandbox, "plugin", "validate", absPlugin); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("claude plugin validate: %s", firstLines(out, err)))
+	}
+
+	out, err = v.run(ctx, sandbox,
+		"--plugin-dir", absPlugin,
+		"--print", "--verbose",
+		"--output-format", "stream-json",
+		"--max-turns", "1",
+		"Reply with OK.",
+	)
+	registered, found := parseInitSlashCommands(out)
+	if !found {
+		result.Errors = append(result.Errors, fmt.Sprintf("claude session did not report its loaded commands: %s", firstLines(out, err)))
+		return result, nil
+	}
+
+	for _, name := range result.Expected {
+		if commandRegistered(name, registered) {
+			result.Registered = append(result.Registered, name)
+		} else {
+			result.Missing = append(result.Missing, name)
+		}
+	}
+	return result, nil
+}
+
+func (v *RuntimeVerifier) run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	timeout := v.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRuntimeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return v.Runner.Run(ctx, dir, args)
+}
+
+// pluginCommandNames lists command names (file stems) under commands/.
+func pluginCommandNames(pluginPath string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(pluginPath, "commands"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading plugin commands: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".md" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(e.Name(), ".md"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// parseInitSlashCommands extracts the slash_commands list from the
+// system/init event of a claude stream-json transcript. found is false when
+// no init event is present (claude failed before starting the session).
+func parseInitSlashCommands(out []byte) (commands []string, found bool) {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var event struct {
+			Type          string   `json:"type"`
+			Subtype       string   `json:"subtype"`
+			SlashCommands []string `json:"slash_commands"`
+		}
+		if json.Unmarshal(line, &event) != nil {
+			continue
+		}
+		if event.Type == "system" && event.Subtype == "init" {
+			return event.SlashCommands, true
+		}
+	}
+	return nil, false
+}
+
+// commandRegistered reports whether name appears in registered, either bare
+// or namespaced by the plugin (`<plugin>:<name>`).
+func commandRegistered(name string, registered []string) bool {
+	for _, r := range registered {
+		r = strings.TrimPrefix(r, "/")
+		if r == name || strings.HasSuffix(r, ":"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLines summarizes claude output for an error message.
+func firstLines(out []byte, err error) string {
+	s := strings.TrimSpace(string(out))
+	if lines := strings.SplitN(s, "\n", 4); len(lines) > 3 {
+		s = strings.Join(lines[:3], "\n") + "\n..."
+	}
+	if s == "" && err != nil {
+		return err.Error()
+	}
+	if s == "" {
+		return "no output"
+	}
+	return s
+}
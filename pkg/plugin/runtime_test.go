@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeClaude returns canned output keyed by the first claude argument.
+type fakeClaude struct {
+	outputs map[string]string
+	errs    map[string]error
+	dirs    []string
+}
+
+func (f *fakeClaude) Run(_ context.Context, dir string, args []string) ([]byte, error) {
+	f.dirs = append(f.dirs, dir)
+	key := args[0]
+	return []byte(f.outputs[key]), f.errs[key]
+}
+
+const initEvent = `{"type":"system","subtype":"init","slash_commands":["help","test-plugin:test-command","compact"]}
+{"type":"result","subtype":"success"}`
+
+func TestRuntimeVerifier_AllCommandsRegistered(t *testing.T) {
+	dir := setupValidPlugin(t)
+	fake := &fakeClaude{outputs: map[string]string{
+		"--version":    "2.1.0 (Claude Code)\n",
+		"plugin":       "Validation passed",
+		"--plugin-dir": initEvent,
+	}}
+	v := &RuntimeVerifier{PluginPath: dir, Runner: fake}
+
+	res, err := v.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.IsValid() {
+		t.Fatalf("expected valid result, got errors=%v missing=%v", res.Errors, res.Missing)
+	}
+	if res.ClaudeVersion != "2.1.0 (Claude Code)" {
+		t.Errorf("ClaudeVersion = %q", res.ClaudeVersion)
+	}
+	if len(res.Registered) != 1 || res.Registered[0] != "test-command" {
+		t.Errorf("Registered = %v, want [test-command]", res.Registered)
+	}
+	for _, d := range fake.dirs {
+		if d == dir || d == "" {
+			t.Errorf("claude must run in a sandbox project, ran in %q", d)
+		}
+	}
+}
+
+func TestRuntimeVerifier_MissingCommand(t *testing.T) {
+	dir := setupValidPlugin(t)
+	fake := &fakeClaude{outputs: map[string]string{
+		"--plugin-dir": `{"type":"system","subtype":"init","slash_commands":["help"]}`,
+	}}
+	v := &RuntimeVerifier{PluginPath: dir, Runner: fake}
+
+	res, err := v.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.IsValid() {
+		t.Fatal("expected invalid result")
+	}
+	if len(res.Missing) != 1 || res.Missing[0] != "test-command" {
+		t.Errorf("Missing = %v, want [test-command]", res.Missing)
+	}
+}
+
+func TestRuntimeVerifier_ValidateFailsAndNoInit(t *testing.T) {
+	dir := setupValidPlugin(t)
+	fake := &fakeClaude{
+		outputs: map[string]string{
+			"plugin":       "Error: unknown field \"foo\" in plugin.json",
+			"--plugin-dir": "Error: failed to load plugin",
+		},
+		errs: map[string]error{
+			"plugin":       errors.New("exit status 1"),
+			"--plugin-dir": errors.New("exit status 1"),
+		},
+	}
+	v := &RuntimeVerifier{PluginPath: dir, Runner: fake}
+
+	res, err := v.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %v", res.Errors)
+	}
+	if !strings.Contains(res.Errors[0], "unknown field") {
+		t.Errorf("expected claude validate output in error, got %q", res.Errors[0])
+	}
+}
+
+func TestRuntimeVerifier_ClaudeNotInstalled(t *testing.T) {
+	dir := setupValidPlugin(t)
+	fake := &fakeClaude{errs: map[string]error{"--version": ErrClaudeNotInstalled}}
+	v := &RuntimeVerifier{PluginPath: dir, Runner: fake}
+
+	if _, err := v.Verify(context.Background()); !errors.Is(err, ErrClaudeNotInstalled) {
+		t.Errorf("expected ErrClaudeNotInstalled, got %v", err)
+	}
+}
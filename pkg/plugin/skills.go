@@ -0,0 +1,322 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Limits from the Agent Skills specification
+// (https://agentskills.io/specification) and Claude's skill authoring
+// guidance. Skills exceeding the name/description limits fail to register;
+// the body limit keeps SKILL.md within a single progressive-disclosure read.
+const (
+	MaxSkillNameLength        = 64
+	MaxSkillDescriptionLength = 1024
+	MaxSkillBodyLines         = 500
+)
+
+const (
+	skillCommandsPrefix = ".claude/commands/"
+	skillSkillsPrefix   = ".claude/skills/"
+	skillEntrypointName = "SKILL.md"
+	skillFrontmatterSep = "---"
+)
+
+var (
+	skillNamePattern   = regexp.MustCompile(`^[a-z0-9]([a-z0-9]|-[a-z0-9])*$`)
+	skillXMLTagPattern = regexp.MustCompile(`<[A-Za-z/][^>]*>`)
+	skillReservedWords = []string{"anthropic", "claude"}
+)
+
+// Skill is a single compiled Claude Skill: a SKILL.md entrypoint plus any
+// resource files that live alongside it in the skill directory.
+type Skill struct {
+	Name        string
+	Description string
+	// Body is the markdown following the frontmatter.
+	Body string
+	// Resources maps skill-relative slash paths to file content.
+	Resources map[string][]byte
+	// Source is the cast destination the entrypoint was compiled from.
+	Source string
+
+	// extra holds the source frontmatter fields other than name and
+	// description, in their original order, so fields like allowed-tools
+	// carry over to the skill.
+	extra yaml.MapSlice
+}
+
+// SkillMarkdown renders the SKILL.md entrypoint: frontmatter with name and
+// description first, followed by any other source fields, then the body.
+func (s *Skill) SkillMarkdown() ([]byte, error) {
+	fm := yaml.MapSlice{
+		{Key: "name", Value: s.Name},
+		{Key: "description", Value: s.Description},
+	}
+	fm = append(fm, s.extra...)
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling frontmatter for skill %s: %w", s.Name, err)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(skillFrontmatterSep + "\n")
+	buf.Write(data)
+	buf.WriteString(skillFrontmatterSep + "\n\n")
+	buf.WriteString(strings.TrimLeft(s.Body, "\n"))
+	if !strings.HasSuffix(s.Body, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// Validate checks the skill against the Agent Skills spec and returns one
+// message per problem. An empty result means the skill will register.
+func (s *Skill) Validate() []string {
+	var problems []string
+
+	switch {
+	case s.Name == "":
+		problems = append(problems, "name is required")
+	case len(s.Name) > MaxSkillNameLength:
+		problems = append(problems, fmt.Sprintf("name is %d characters (max %d)", len(s.Name), MaxSkillNameLength))
+	case !skillNamePattern.MatchString(s.Name):
+		problems = append(problems, fmt.Sprintf("name %q must contain only lowercase letters, numbers, and single hyphens", s.Name))
+	}
+	for _, word := range skillReservedWords {
+		if strings.Contains(s.Name, word) {
+			problems = append(problems, fmt.Sprintf("name %q contains reserved word %q", s.Name, word))
+			break
+		}
+	}
+
+	switch {
+	case strings.TrimSpace(s.Description) == "":
+		problems = append(problems, "description is required; add a description to the blank's frontmatter")
+	case len(s.Description) > MaxSkillDescriptionLength:
+		problems = append(problems, fmt.Sprintf("description is %d characters (max %d)", len(s.Description), MaxSkillDescriptionLength))
+	case skillXMLTagPattern.MatchString(s.Description):
+		problems = append(problems, "description must not contain XML tags")
+	}
+
+	if lines := strings.Count(strings.TrimRight(s.Body, "\n"), "\n") + 1; lines > MaxSkillBodyLines {
+		problems = append(problems, fmt.Sprintf("SKILL.md body is %d lines (max %d); move detail into resource files", lines, MaxSkillBodyLines))
+	}
+
+	for p := range s.Resources {
+		if p == skillEntrypointName {
+			problems = append(problems, "resource collides with the SKILL.md entrypoint")
+		}
+	}
+	return problems
+}
+
+// CompileSkills converts rendered blanks into Claude Skills. Command blanks
+// (.claude/commands/<name>.md) become skills named <name>; rendered files
+// under .claude/commands/<name>/ are bundled as that skill's resources.
+// Blanks already in skill layout (.claude/skills/<name>/SKILL.md plus
+// siblings) are carried over, with their frontmatter normalized the same
+// way. Other files are ignored.
+//
+// When selected is non-empty, only skills with those names are returned and
+// an unknown name is an error. Skills are returned sorted by name.
+func CompileSkills(files []RenderedFile, selected []string) ([]*Skill, error) {
+	entrypoints := make(map[string]RenderedFile)
+	resources := make(map[string]map[string][]byte)
+	addResource := func(name, rel string, content []byte) {
+		if resources[name] == nil {
+			resources[name] = make(map[string][]byte)
+		}
+		resources[name][rel] = content
+	}
+
+	for _, rf := range files {
+		dest := filepath.ToSlash(rf.CastDest)
+		var rest string
+		switch {
+		case strings.HasPrefix(dest, skillCommandsPrefix):
+			rest = strings.TrimPrefix(dest, skillCommandsPrefix)
+			if !strings.Contains(rest, "/") {
+				if path.Ext(rest) != ".md" {
+					continue
+				}
+				name := strings.TrimSuffix(rest, ".md")
+				if prev, dup := entrypoints[name]; dup {
+					return nil, fmt.Errorf("skill %s is produced by both %s and %s", name, prev.CastDest, rf.CastDest)
+				}
+				entrypoints[name] = rf
+				continue
+			}
+		case strings.HasPrefix(dest, skillSkillsPrefix):
+			rest = strings.TrimPrefix(dest, skillSkillsPrefix)
+			name, rel, ok := strings.Cut(rest, "/")
+			if !ok {
+				continue
+			}
+			if rel == skillEntrypointName {
+				if prev, dup := entrypoints[name]; dup {
+					return nil, fmt.Errorf("skill %s is produced by both %s and %s", name, prev.CastDest, rf.CastDest)
+				}
+				entrypoints[name] = rf
+				continue
+			}
+		default:
+			continue
+		}
+		name, rel, _ := strings.Cut(rest, "/")
+		addResource(name, rel, rf.Content)
+	}
+
+	names := make([]string, 0, len(entrypoints))
+	if len(selected) > 0 {
+		for _, name := range selected {
+			if _, ok := entrypoints[name]; !ok {
+				return nil, fmt.Errorf("no blank compiles to skill %q", name)
+			}
+			if !containsName(names, name) {
+				names = append(names, name)
+			}
+		}
+	} else {
+		for name := range entrypoints {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	skills := make([]*Skill, 0, len(names))
+	for _, name := range names {
+		s, err := compileSkill(name, entrypoints[name])
+		if err != nil {
+			return nil, err
+		}
+		s.Resources = resources[name]
+		skills = append(skills, s)
+	}
+	return skills, nil
+}
+
+// compileSkill builds a Skill from a rendered entrypoint. A frontmatter name
+// overrides the file-derived one; a missing description falls back to the
+// first paragraph of the body.
+func compileSkill(name string, rf RenderedFile) (*Skill, error) {
+	fm, body, err := splitFrontmatter(rf.Content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing frontmatter in %s: %w", rf.CastDest, err)
+	}
+	s := &Skill{Name: name, Body: body, Source: rf.CastDest}
+	for _, item := range fm {
+		key := fmt.Sprint(item.Key)
+		switch key {
+		case "name":
+			if v := strings.TrimSpace(fmt.Sprint(item.Value)); v != "" {
+				s.Name = v
+			}
+		case "description":
+			s.Description = strings.TrimSpace(fmt.Sprint(item.Value))
+		default:
+			s.extra = append(s.extra, item)
+		}
+	}
+	if s.Description == "" {
+		s.Description = firstParagraph(body)
+	}
+	return s, nil
+}
+
+// splitFrontmatter separates leading YAML frontmatter from markdown content.
+// Content without frontmatter returns a nil slice and the full content.
+func splitFrontmatter(content []byte) (yaml.MapSlice, string, error) {
+	text := string(content)
+	if !strings.HasPrefix(text, skillFrontmatterSep+"\n") {
+		return nil, text, nil
+	}
+	rest := text[len(skillFrontmatterSep)+1:]
+	end := strings.Index(rest, "\n"+skillFrontmatterSep)
+	if end < 0 {
+		return nil, text, nil
+	}
+	raw := rest[:end]
+	body := rest[end+len(skillFrontmatterSep)+1:]
+	body = strings.TrimPrefix(body, "\n")
+
+	var fm yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(raw), &fm); err != nil {
+		return nil, "", err
+	}
+	return fm, body, nil
+}
+
+// firstParagraph returns the first non-heading paragraph of markdown,
+// collapsed to a single line.
+func firstParagraph(body string) string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" && len(lines) > 0:
+			return strings.Join(lines, " ")
+		case line == "", strings.HasPrefix(line, "#"):
+			continue
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+func containsName(list []string, name string) bool {
+	for _, n := range list {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SkillWriter writes compiled skills into OutputDir as
+// <OutputDir>/<name>/SKILL.md plus resources.
+type SkillWriter struct {
+	OutputDir string
+}
+
+// Write replaces each skill's directory with its compiled contents. Skill
+// directories not being written are left untouched.
+func (w *SkillWriter) Write(skills []*Skill) error {
+	if w.OutputDir == "" {
+		return fmt.Errorf("skill writer: OutputDir is empty")
+	}
+	for _, s := range skills {
+		dir := filepath.Join(w.OutputDir, s.Name)
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("clearing skill dir %s: %w", dir, err)
+		}
+		if err := os.MkdirAll(dir, 0o750); err != nil { // #nosec G301 -- skill dir needs group read access
+			return fmt.Errorf("creating skill dir %s: %w", dir, err)
+		}
+		md, err := s.SkillMarkdown()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, skillEntrypointName), md, 0o644); err != nil { // #nosec G306 -- skill contents need to be readable
+			return fmt.Errorf("writing SKILL.md for %s: %w", s.Name, err)
+		}
+		for rel, content := range s.Resources {
+			dest := filepath.Join(dir, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil { // #nosec G301
+				return fmt.Errorf("creating dir for %s: %w", dest, err)
+			}
+			if err := os.WriteFile(dest, content, 0o644); err != nil { // #nosec G306
+				return fmt.Errorf("writing %s: %w", dest, err)
+			}
+		}
+	}
+	return nil
+}
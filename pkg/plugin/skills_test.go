@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompileSkills_CommandBlanks(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/create-issue.md", Content: []byte("---\ndescription: Creates GitHub issues. Use when filing bugs.\nallowed-tools: Bash(gh:*)\n---\n# Create Issue\n\nSteps here.\n")},
+		{CastDest: ".claude/commands/create-issue/reference.md", Content: []byte("# Reference\n")},
+		{CastDest: ".claude/commands/triage.md", Content: []byte("# Triage\n\nTriages open issues\nby label.\n\nMore.\n")},
+		{CastDest: ".claude/agents/reviewer.md", Content: []byte("agent")},
+		{CastDest: "AGENTS.md", Content: []byte("agents")},
+	}
+
+	skills, err := CompileSkills(files, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(skills) != 2 {
+		t.Fatalf("expected 2 skills, got %d", len(skills))
+	}
+
+	issue := skills[0]
+	if issue.Name != "create-issue" {
+		t.Errorf("name = %q", issue.Name)
+	}
+	if issue.Description != "Creates GitHub issues. Use when filing bugs." {
+		t.Errorf("description = %q", issue.Description)
+	}
+	if string(issue.Resources["reference.md"]) != "# Reference\n" {
+		t.Errorf("resources = %v", issue.Resources)
+	}
+	md, err := issue.SkillMarkdown()
+	if err != nil {
+		t.Fatalf("SkillMarkdown: %v", err)
+	}
+	got := string(md)
+	if !strings.HasPrefix(got, "---\nname: create-issue\ndescription: ") {
+		t.Errorf("frontmatter should lead with name and description:\n%s", got)
+	}
+	if !strings.Contains(got, "allowed-tools: Bash(gh:*)") {
+		t.Errorf("extra frontmatter fields should carry over:\n%s", got)
+	}
+	if !strings.Contains(got, "---\n\n# Create Issue\n") {
+		t.Errorf("body missing:\n%s", got)
+	}
+
+	if skills[1].Description != "Triages open issues by label." {
+		t.Errorf("fallback description = %q", skills[1].Description)
+	}
+}
+
+func TestCompileSkills_SkillLayoutAndSelection(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/skills/processing-pdfs/SKILL.md", Content: []byte("---\nname: processing-pdfs\ndescription: Processes PDFs.\n---\nBody\n")},
+		{CastDest: ".claude/skills/processing-pdfs/scripts/extract.py", Content: []byte("print()\n")},
+		{CastDest: ".claude/commands/other.md", Content: []byte("---\ndescription: Other.\n---\nx\n")},
+	}
+
+	skills, err := CompileSkills(files, []string{"processing-pdfs"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(skills) != 1 || skills[0].Name != "processing-pdfs" {
+		t.Fatalf("unexpected skills: %+v", skills)
+	}
+	if _, ok := skills[0].Resources["scripts/extract.py"]; !ok {
+		t.Errorf("expected nested resource, got %v", skills[0].Resources)
+	}
+
+	if _, err := CompileSkills(files, []string{"missing"}); err == nil {
+		t.Error("expected error for unknown selected skill")
+	}
+}
+
+func TestCompileSkills_DuplicateEntrypoint(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/dup.md", Content: []byte("a")},
+		{CastDest: ".claude/skills/dup/SKILL.md", Content: []byte("b")},
+	}
+	if _, err := CompileSkills(files, nil); err == nil {
+		t.Error("expected collision error")
+	}
+}
+
+func TestSkillValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		skill Skill
+		want  string
+	}{
+		{"valid", Skill{Name: "processing-pdfs", Description: "Processes PDFs.", Body: "x\n"}, ""},
+		{"uppercase name", Skill{Name: "Bad_Name", Description: "d", Body: "x"}, "lowercase"},
+		{"long name", Skill{Name: strings.Repeat("a", MaxSkillNameLength+1), Description: "d", Body: "x"}, "max 64"},
+		{"reserved word", Skill{Name: "claude-helper", Description: "d", Body: "x"}, "reserved word"},
+		{"no description", Skill{Name: "ok", Body: "x"}, "description is required"},
+		{"long description", Skill{Name: "ok", Description: strings.Repeat("d", MaxSkillDescriptionLength+1), Body: "x"}, "max 1024"},
+		{"xml description", Skill{Name: "ok", Description: "Uses <tool> tags", Body: "x"}, "XML"},
+		{"long body", Skill{Name: "ok", Description: "d", Body: strings.Repeat("line\n", MaxSkillBodyLines+1)}, "move detail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := tt.skill.Validate()
+			if tt.want == "" {
+				if len(problems) != 0 {
+					t.Errorf("expected no problems, got %v", problems)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(problems, "\n"), tt.want) {
+				t.Errorf("expected problem containing %q, got %v", tt.want, problems)
+			}
+		})
+	}
+}
+
+func TestSkillWriter_Write(t *testing.T) {
+	out := t.TempDir()
+	stale := filepath.Join(out, "create-issue", "stale.md")
+	if err := os.MkdirAll(filepath.Dir(stale), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	keep := filepath.Join(out, "unrelated", "SKILL.md")
+	if err := os.MkdirAll(filepath.Dir(keep), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keep, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &SkillWriter{OutputDir: out}
+	err := w.Write([]*Skill{{
+		Name:        "create-issue",
+		Description: "Creates issues.",
+		Body:        "# Create\n",
+		Resources:   map[string][]byte{"docs/reference.md": []byte("ref")},
+	}})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "create-issue", "SKILL.md")); err != nil {
+		t.Errorf("SKILL.md not written: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "create-issue", "docs", "reference.md")); string(data) != "ref" {
+		t.Errorf("resource = %q", data)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale file in rewritten skill dir should be removed")
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Error("unrelated skill dir should be untouched")
+	}
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"sort"
+	"strings"
+)
+
+// The Claude Code plugin spec as the validator checks it: manifest fields and
+// their JSON types, hook events and hook types, and command frontmatter
+// fields. Anything outside these tables is reported as unknown.
+
+// pluginRootVar is expanded by Claude Code to the installed plugin directory
+// in hook commands and MCP server configs.
+const pluginRootVar = "${CLAUDE_PLUGIN_ROOT}"
+
+// JSON kinds a manifest field may take.
+const (
+	kindString = "string"
+	kindObject = "object"
+	kindArray  = "array"
+)
+
+// manifestFields maps each plugin.json field to the JSON kinds it accepts.
+var manifestFields = map[string][]string{
+	"name":        {kindString},
+	"version":     {kindString},
+	"description": {kindString},
+	"author":      {kindObject},
+	"homepage":    {kindString},
+	"repository":  {kindString},
+	"license":     {kindString},
+	"keywords":    {kindArray},
+	"commands":    {kindString, kindArray},
+	"agents":      {kindString, kindArray},
+	"hooks":       {kindString, kindObject},
+	"mcpServers":  {kindString, kindObject},
+}
+
+// manifestRequiredFields must be present in every plugin.json.
+var manifestRequiredFields = []string{"name", "version", "description"}
+
+// manifestPathFields hold paths (or, for hooks and mcpServers, inline
+// configuration) that Claude Code loads in addition to the default layout.
+var manifestPathFields = []string{"commands", "agents", "hooks", "mcpServers"}
+
+// authorFields are the string fields of the manifest author object.
+var authorFields = map[string]bool{"name": true, "email": true, "url": true}
+
+// hookEvents are the events a hooks configuration may register for.
+var hookEvents = map[string]bool{
+	"PreToolUse":       true,
+	"PostToolUse":      true,
+	"Notification":     true,
+	"UserPromptSubmit": true,
+	"Stop":             true,
+	"SubagentStop":     true,
+	"PreCompact":       true,
+	"SessionStart":     true,
+	"SessionEnd":       true,
+}
+
+// hookTypes maps each hook type to the string field it requires.
+var hookTypes = map[string]string{
+	"command": "command",
+	"prompt":  "prompt",
+}
+
+// commandFrontmatterFields maps each command frontmatter field to a
+// description of the YAML type it takes.
+var commandFrontmatterFields = map[string]string{
+	"description":              kindString,
+	"argument-hint":            kindString,
+	"model":                    kindString,
+	"allowed-tools":            "string or list of strings",
+	"disable-model-invocation": "boolean",
+	"name":                     kindString,
+}
+
+// jsonKind names the JSON kind of a value decoded by encoding/json.
+func jsonKind(v any) string {
+	switch v.(type) {
+	case string:
+		return kindString
+	case map[string]any:
+		return kindObject
+	case []any:
+		return kindArray
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return "unknown"
+}
+
+// isKebabCase reports whether name is lowercase letters, digits and single
+// hyphens, as Claude Code requires of plugin names.
+func isKebabCase(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") || strings.Contains(name, "--") {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns a set's keys in order, for stable messages.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,11 +1,12 @@
 package plugin
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/nimble-giant/ailloy/pkg/blanks"
@@ -13,9 +14,13 @@ import (
 
 // Updater handles updating existing Claude Code plugins
 type Updater struct {
-	PluginPath     string
-	BackupPath     string
-	reader         *blanks.MoldReader
+	PluginPath string
+	BackupPath string
+	// Generator renders the new content. NewUpdater sets one that renders
+	// the mold's blanks with their flux defaults; callers that resolve
+	// ingots, --set and --values swap in their own. Its OutputDir is
+	// replaced with PluginPath.
+	Generator      *Generator
 	UpdatedFiles   int
 	NewCommands    int
 	PreservedFiles int
@@ -26,50 +31,78 @@ func NewUpdater(pluginPath string, reader *blanks.MoldReader) *Updater {
 	return &Updater{
 		PluginPath: pluginPath,
 		BackupPath: pluginPath + ".backup." + time.Now().Format("20060102-150405"),
-		reader:     reader,
+		Generator:  NewGenerator(pluginPath, reader),
 	}
 }
 
-// Update updates an existing plugin with new blanks
+// Update regenerates the plugin in place through the same path as
+// Generate, so an updated plugin matches a freshly generated one. Files
+// the blanks no longer produce (custom commands, for instance) are left
+// alone and counted as preserved.
 func (u *Updater) Update() error {
-	// Create a generator for the new content
-	generator := NewGenerator(u.PluginPath, u.reader)
-	generator.Config = &Config{
-		Name:        "ailloy",
-		Version:     "1.0.0",
-		Description: "AI-assisted development workflows and structured blanks for Claude Code",
-		Author: Author{
-			Name:  "Ailloy Team",
-			Email: "support@ailloy.dev",
-			URL:   "https://github.com/nimble-giant/ailloy",
-		},
-	}
-
-	// Load blanks
-	if err := generator.loadBlanks(); err != nil {
-		return fmt.Errorf("failed to load blanks: %w", err)
+	existing, err := pluginContentFiles(u.PluginPath)
+	if err != nil {
+		return fmt.Errorf("failed to read existing plugin: %w", err)
 	}
 
-	// Update commands
-	if err := u.updateCommands(generator); err != nil {
-		return fmt.Errorf("failed to update commands: %w", err)
+	generator := u.Generator
+	generator.OutputDir = u.PluginPath
+	if err := generator.Generate(); err != nil {
+		return err
 	}
 
-	// Update manifest
-	if err := generator.generateManifest(); err != nil {
-		return fmt.Errorf("failed to update manifest: %w", err)
+	for _, f := range generator.blankFiles() {
+		internal, ok := translatePath(f.CastDest)
+		if !ok {
+			continue
+		}
+		if existing[internal] {
+			u.UpdatedFiles++
+		} else {
+			u.NewCommands++
+		}
+		delete(existing, internal)
 	}
-	u.UpdatedFiles++
+	u.PreservedFiles = len(existing)
 
-	// Update README
-	if err := generator.generateREADME(); err != nil {
-		return fmt.Errorf("failed to update README: %w", err)
-	}
-	u.UpdatedFiles++
+	// Manifest and README
+	u.UpdatedFiles += 2
 
 	return nil
 }
 
+// pluginContentFiles lists the files under the plugin's command, skill,
+// agent and hook directories, plus AGENTS.md, by plugin-internal path.
+func pluginContentFiles(pluginPath string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	for _, dir := range []string{"commands", "skills", "agents", "hooks"} {
+		err := filepath.WalkDir(filepath.Join(pluginPath, dir), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(pluginPath, p)
+			if err != nil {
+				return err
+			}
+			files[rel] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := os.Stat(filepath.Join(pluginPath, "AGENTS.md")); err == nil {
+		files["AGENTS.md"] = true
+	}
+	return files, nil
+}
+
 // Backup creates a backup of the existing plugin
 func (u *Updater) Backup() error {
 	// Create backup directory
@@ -92,57 +125,6 @@ func (u *Updater) Restore() error {
 	return u.copyDir(u.BackupPath, u.PluginPath)
 }
 
-func (u *Updater) updateCommands(generator *Generator) error {
-	commandsPath := filepath.Join(u.PluginPath, "commands")
-
-	// Get list of existing commands
-	existingCommands := make(map[string]bool)
-	if entries, err := os.ReadDir(commandsPath); err == nil {
-		for _, entry := range entries {
-			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".md" {
-				name := strings.TrimSuffix(entry.Name(), ".md")
-				existingCommands[name] = true
-			}
-		}
-	}
-
-	// Transform and update commands
-	transformer := NewTransformer()
-	for _, tmpl := range generator.commands {
-		// Transform blank
-		command, err := transformer.Transform(tmpl)
-		if err != nil {
-			return fmt.Errorf("failed to transform blank %s: %w", tmpl.Name, err)
-		}
-
-		// Write command file
-		cmdPath := filepath.Join(commandsPath, tmpl.Name+".md")
-		//#nosec G306 -- Command files need to be readable
-		if err := os.WriteFile(cmdPath, command, 0644); err != nil {
-			return fmt.Errorf("failed to write command %s: %w", tmpl.Name, err)
-		}
-
-		// Track updates
-		if existingCommands[tmpl.Name] {
-			u.UpdatedFiles++
-		} else {
-			u.NewCommands++
-		}
-
-		// Remove from existing commands map
-		delete(existingCommands, tmpl.Name)
-	}
-
-	// Count preserved custom commands
-	for cmdName := range existingCommands {
-		if !strings.HasPrefix(cmdName, "ailloy-") {
-			u.PreservedFiles++
-		}
-	}
-
-	return nil
-}
-
 func (u *Updater) copyDir(src, dst string) error {
 	// Get source info
 	srcInfo, err := os.Stat(src)
@@ -1,10 +1,14 @@
 package plugin
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
 )
 
 func setupPluginForUpdate(t *testing.T) string {
@@ -163,6 +167,96 @@ func TestUpdater_Update_PreservesCustomCommands(t *testing.T) {
 	}
 }
 
+func TestUpdater_Update_MatchesGenerate(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{
+		"mold.yaml":            &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: mold\nname: render\nversion: 1.0.0\nflux:\n  - name: org\n    type: string\n    default: acme\n")},
+		"flux.yaml":            &fstest.MapFile{Data: []byte("output:\n  commands: .claude/commands\n  agents: .claude/agents\n  skills: .claude/skills\n  AGENTS.md: AGENTS.md\n  workflows: .github/workflows\n")},
+		"commands/deploy.md":   &fstest.MapFile{Data: []byte("Deploy for {{org}}.\n")},
+		"agents/reviewer.md":   &fstest.MapFile{Data: []byte("# Reviewer\n")},
+		"skills/lint/SKILL.md": &fstest.MapFile{Data: []byte("# Lint\n")},
+		"AGENTS.md":            &fstest.MapFile{Data: []byte("# Rules for {{org}}\n")},
+		"workflows/ci.yml":     &fstest.MapFile{Data: []byte("name: CI\n")},
+	}
+	reader := blanks.NewMoldReader(fsys)
+
+	// A render with --set org=globex, as the command supplies it.
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/deploy.md", Content: []byte("Deploy for globex.\n")},
+		{CastDest: ".claude/agents/reviewer.md", Content: []byte("# Reviewer\n")},
+		{CastDest: ".claude/skills/lint/SKILL.md", Content: []byte("# Lint\n")},
+		{CastDest: "AGENTS.md", Content: []byte("# Rules for globex\n")},
+		{CastDest: ".github/workflows/ci.yml", Content: []byte("name: CI\n")},
+	}
+	newGenerator := func(out string) *Generator {
+		g := NewGenerator(out, reader)
+		g.Files = files
+		g.Readme = []byte("# Render\n")
+		return g
+	}
+
+	generated := filepath.Join(dir, "generated")
+	if err := newGenerator(generated).Generate(); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// An older plugin from the mold's defaults, plus a custom command.
+	updated := filepath.Join(dir, "updated")
+	if err := NewGenerator(updated, reader).Generate(); err != nil {
+		t.Fatalf("generate old plugin: %v", err)
+	}
+	customPath := filepath.Join(updated, "commands", "custom.md")
+	if err := os.WriteFile(customPath, []byte("# Custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	u := NewUpdater(updated, reader)
+	u.Generator = newGenerator("ignored")
+	if err := u.Update(); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := os.Remove(customPath); err != nil {
+		t.Errorf("custom command should be preserved: %v", err)
+	}
+
+	want, got := readTree(t, generated), readTree(t, updated)
+	for rel, content := range want {
+		if got[rel] != content {
+			t.Errorf("%s after update = %q, generate wrote %q", rel, got[rel], content)
+		}
+	}
+	for rel := range got {
+		if _, ok := want[rel]; !ok {
+			t.Errorf("update left %s, which generate does not write", rel)
+		}
+	}
+	if u.UpdatedFiles != 6 || u.NewCommands != 0 || u.PreservedFiles != 1 {
+		t.Errorf("updated/new/preserved = %d/%d/%d, want 6/0/1", u.UpdatedFiles, u.NewCommands, u.PreservedFiles)
+	}
+}
+
+// readTree returns every file under root keyed by slash-separated path.
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		tree[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
 func TestUpdater_CopyDir(t *testing.T) {
 	srcDir := t.TempDir()
 	dstDir := filepath.Join(t.TempDir(), "copy-dest")
@@ -232,6 +326,7 @@ func TestUpdater_CopyFile(t *testing.T) {
 func TestUpdater_BackupAndUpdate_FullCycle(t *testing.T) {
 	pluginDir := setupPluginForUpdate(t)
 	u := NewUpdater(pluginDir, testMoldReader())
+	u.Generator.Config = &Config{Name: "update-test", Version: "1.0.1", Description: "Plugin for update testing"}
 
 	// Backup
 	if err := u.Backup(); err != nil {
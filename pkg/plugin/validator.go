@@ -4,7 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
 // Validator validates Claude Code plugin structure
@@ -12,17 +17,31 @@ type Validator struct {
 	PluginPath string
 }
 
-// ValidationResult contains the results of plugin validation
+// ValidationResult contains the results of plugin validation. Diagnostics
+// carry the findings in the same shape temper reports them, with File
+// relative to the plugin root; Warnings and Errors hold their messages.
 type ValidationResult struct {
 	IsValid      bool
 	HasManifest  bool
 	HasCommands  bool
 	HasREADME    bool
 	CommandCount int
+	Diagnostics  []mold.Diagnostic
 	Warnings     []string
 	Errors       []string
 }
 
+// Rule names attached to validator diagnostics.
+const (
+	ruleStructure   = "plugin-structure"
+	ruleManifest    = "plugin-manifest"
+	rulePaths       = "plugin-paths"
+	ruleHooks       = "plugin-hooks"
+	ruleFrontmatter = "command-frontmatter"
+)
+
+const manifestFile = ".claude-plugin/plugin.json"
+
 // NewValidator creates a new plugin validator
 func NewValidator(pluginPath string) *Validator {
 	return &Validator{
@@ -40,13 +59,15 @@ func (v *Validator) Validate() (*ValidationResult, error) {
 
 	// Check if plugin directory exists
 	if _, err := os.Stat(v.PluginPath); err != nil {
+		result.errorf("", ruleStructure, "Plugin directory not found: %s", v.PluginPath)
 		result.IsValid = false
-		result.Errors = append(result.Errors, fmt.Sprintf("Plugin directory not found: %s", v.PluginPath))
 		return result, fmt.Errorf("plugin directory not found: %s", v.PluginPath)
 	}
 
-	// Validate manifest
-	v.validateManifest(result)
+	// Validate manifest and the files it references
+	if manifest := v.validateManifest(result); manifest != nil {
+		v.validateManifestPaths(manifest, result)
+	}
 
 	// Validate commands
 	v.validateCommands(result)
@@ -68,48 +89,167 @@ func (v *Validator) Validate() (*ValidationResult, error) {
 	return result, nil
 }
 
-func (v *Validator) validateManifest(result *ValidationResult) {
-	manifestPath := filepath.Join(v.PluginPath, ".claude-plugin", "plugin.json")
+// add records a diagnostic and mirrors its message into Errors or Warnings.
+func (r *ValidationResult) add(d mold.Diagnostic) {
+	r.Diagnostics = append(r.Diagnostics, d)
+	if d.Severity == mold.SeverityError {
+		r.Errors = append(r.Errors, d.Message)
+	} else {
+		r.Warnings = append(r.Warnings, d.Message)
+	}
+}
+
+func (r *ValidationResult) errorf(file, rule, format string, args ...any) {
+	r.add(mold.Diagnostic{Severity: mold.SeverityError, Message: fmt.Sprintf(format, args...), File: file, Rule: rule})
+}
+
+func (r *ValidationResult) warnf(file, rule, format string, args ...any) {
+	r.add(mold.Diagnostic{Severity: mold.SeverityWarning, Message: fmt.Sprintf(format, args...), File: file, Rule: rule})
+}
+
+// validateManifest checks plugin.json against the manifest schema and returns
+// the decoded manifest, or nil when it is missing or not valid JSON.
+func (v *Validator) validateManifest(result *ValidationResult) map[string]any {
+	manifestPath := filepath.Join(v.PluginPath, filepath.FromSlash(manifestFile))
 
 	data, err := os.ReadFile(manifestPath) // #nosec G304 -- CLI tool validates plugin files
 	if err != nil {
 		result.HasManifest = false
-		result.Errors = append(result.Errors, "Missing plugin manifest (.claude-plugin/plugin.json)")
-		return
+		result.errorf(manifestFile, ruleManifest, "Missing plugin manifest (%s)", manifestFile)
+		return nil
 	}
 
 	result.HasManifest = true
 
 	// Validate manifest JSON
-	var manifest map[string]interface{}
+	var manifest map[string]any
 	if err := json.Unmarshal(data, &manifest); err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Invalid manifest JSON: %v", err))
-		return
+		result.errorf(manifestFile, ruleManifest, "Invalid manifest JSON: %v", err)
+		return nil
 	}
 
 	// Check required fields
-	requiredFields := []string{"name", "version", "description"}
-	for _, field := range requiredFields {
+	for _, field := range manifestRequiredFields {
 		if _, ok := manifest[field]; !ok {
-			result.Errors = append(result.Errors, fmt.Sprintf("Manifest missing required field: %s", field))
+			result.errorf(manifestFile, ruleManifest, "Manifest missing required field: %s", field)
 		}
 	}
 
+	// Check field types
+	for _, field := range sortedKeys(manifest) {
+		kinds, known := manifestFields[field]
+		if !known {
+			result.add(mold.Diagnostic{
+				Severity: mold.SeverityWarning,
+				Message:  fmt.Sprintf("Unknown manifest field: %s", field),
+				Tip:      "known fields: " + strings.Join(sortedKeys(manifestFields), ", "),
+				File:     manifestFile,
+				Rule:     ruleManifest,
+			})
+			continue
+		}
+		if kind := jsonKind(manifest[field]); !slices.Contains(kinds, kind) {
+			result.errorf(manifestFile, ruleManifest, "Manifest field %s must be %s, got %s", field, strings.Join(kinds, " or "), kind)
+		}
+	}
+
+	if name, ok := manifest["name"].(string); ok && !isKebabCase(name) {
+		result.errorf(manifestFile, ruleManifest, "Plugin name %q must be kebab-case (lowercase letters, digits and hyphens)", name)
+	}
+
 	// Check version format
 	if version, ok := manifest["version"].(string); ok {
 		if !isValidVersion(version) {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("Invalid version format: %s", version))
+			result.warnf(manifestFile, ruleManifest, "Invalid version format: %s", version)
+		}
+	}
+
+	if author, ok := manifest["author"].(map[string]any); ok {
+		if _, ok := author["name"]; !ok {
+			result.errorf(manifestFile, ruleManifest, "Manifest author missing required field: name")
+		}
+		for _, field := range sortedKeys(author) {
+			switch {
+			case !authorFields[field]:
+				result.warnf(manifestFile, ruleManifest, "Unknown manifest author field: %s", field)
+			case jsonKind(author[field]) != kindString:
+				result.errorf(manifestFile, ruleManifest, "Manifest author field %s must be string, got %s", field, jsonKind(author[field]))
+			}
+		}
+	}
+
+	if keywords, ok := manifest["keywords"].([]any); ok {
+		for i, kw := range keywords {
+			if jsonKind(kw) != kindString {
+				result.errorf(manifestFile, ruleManifest, "Manifest keywords[%d] must be string, got %s", i, jsonKind(kw))
+			}
+		}
+	}
+
+	return manifest
+}
+
+// validateManifestPaths checks that the custom component paths declared in
+// plugin.json exist, and validates inline and referenced hook configs.
+func (v *Validator) validateManifestPaths(manifest map[string]any, result *ValidationResult) {
+	for _, field := range manifestPathFields {
+		switch val := manifest[field].(type) {
+		case string:
+			if !v.checkManifestPath(field, val, result) {
+				continue
+			}
+			switch field {
+			case "hooks":
+				// hooks/hooks.json is validated on its own below
+				if rel := path.Clean(val); rel != "hooks/hooks.json" {
+					v.validateHooksFile(rel, result)
+				}
+			case "mcpServers":
+				v.validateJSONFile(path.Clean(val), result)
+			}
+		case []any:
+			for i, item := range val {
+				p, ok := item.(string)
+				if !ok {
+					result.errorf(manifestFile, ruleManifest, "Manifest %s[%d] must be string, got %s", field, i, jsonKind(item))
+					continue
+				}
+				v.checkManifestPath(field, p, result)
+			}
+		case map[string]any:
+			if field == "hooks" {
+				v.validateHookConfig(manifestFile, val, result)
+			}
 		}
 	}
 }
 
+// checkManifestPath reports a manifest path that isn't "./"-relative, escapes
+// the plugin root, or doesn't exist, and reports whether it is usable.
+func (v *Validator) checkManifestPath(field, p string, result *ValidationResult) bool {
+	if !strings.HasPrefix(p, "./") {
+		result.errorf(manifestFile, rulePaths, "Manifest %s path %q must be relative to the plugin root and start with ./", field, p)
+		return false
+	}
+	clean := path.Clean(p)
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		result.errorf(manifestFile, rulePaths, "Manifest %s path %q points outside the plugin", field, p)
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(v.PluginPath, filepath.FromSlash(clean))); err != nil {
+		result.errorf(manifestFile, rulePaths, "Manifest %s path %q does not exist", field, p)
+		return false
+	}
+	return true
+}
+
 func (v *Validator) validateCommands(result *ValidationResult) {
 	commandsPath := filepath.Join(v.PluginPath, "commands")
 
 	entries, err := os.ReadDir(commandsPath)
 	if err != nil {
 		result.HasCommands = false
-		result.Errors = append(result.Errors, "Commands directory not found or not accessible")
+		result.errorf("commands", ruleStructure, "Commands directory not found or not accessible")
 		return
 	}
 
@@ -129,34 +269,57 @@ func (v *Validator) validateCommands(result *ValidationResult) {
 		result.HasCommands = true
 	} else {
 		result.HasCommands = false
-		result.Errors = append(result.Errors, "No command files found in commands directory")
+		result.errorf("commands", ruleStructure, "No command files found in commands directory")
 	}
 }
 
 func (v *Validator) validateCommandFile(cmdPath string, result *ValidationResult) {
+	cmdName := filepath.Base(cmdPath)
+	rel := "commands/" + cmdName
+
 	content, err := os.ReadFile(cmdPath) // #nosec G304 -- CLI tool validates plugin command files
 	if err != nil {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("Cannot read command file: %s", filepath.Base(cmdPath)))
+		result.warnf(rel, ruleStructure, "Cannot read command file: %s", cmdName)
 		return
 	}
 
-	// Check for required elements
+	// Check frontmatter fields against the command spec
 	contentStr := string(content)
-	cmdName := filepath.Base(cmdPath)
+	fm, body, err := splitFrontmatter(content)
+	if err != nil {
+		result.errorf(rel, ruleFrontmatter, "Command %s has invalid frontmatter YAML: %v", cmdName, err)
+		body = contentStr
+	}
+	for _, item := range fm {
+		key, _ := item.Key.(string)
+		want, known := commandFrontmatterFields[key]
+		switch {
+		case !known:
+			result.add(mold.Diagnostic{
+				Severity: mold.SeverityWarning,
+				Message:  fmt.Sprintf("Command %s has unknown frontmatter field: %s", cmdName, key),
+				Tip:      "known fields: " + strings.Join(sortedKeys(commandFrontmatterFields), ", "),
+				File:     rel,
+				Rule:     ruleFrontmatter,
+			})
+		case !frontmatterTypeOK(want, item.Value):
+			result.errorf(rel, ruleFrontmatter, "Command %s frontmatter field %s must be %s", cmdName, key, want)
+		}
+	}
 
 	// Check for command name header
-	if !hasCommandHeader(contentStr) {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %s missing proper header", cmdName))
+	if !hasCommandHeader(body) {
+		result.warnf(rel, ruleStructure, "Command %s missing proper header", cmdName)
 	}
 
 	// Check for description
 	if !hasDescription(contentStr) {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %s missing description", cmdName))
+		result.warnf(rel, ruleStructure, "Command %s missing description", cmdName)
 	}
 
 	// Check for instructions
 	if !hasInstructions(contentStr) {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("Command %s missing instructions for Claude", cmdName))
+		result.warnf(rel, ruleStructure, "Command %s missing instructions for Claude", cmdName)
 	}
 }
 
@@ -165,27 +328,129 @@ func (v *Validator) validateREADME(result *ValidationResult) {
 
 	if _, err := os.Stat(readmePath); err != nil {
 		result.HasREADME = false
-		result.Warnings = append(result.Warnings, "README.md not found (recommended for documentation)")
+		result.warnf("README.md", ruleStructure, "README.md not found (recommended for documentation)")
 	} else {
 		result.HasREADME = true
 	}
 }
 
 func (v *Validator) validateHooks(result *ValidationResult) {
-	hooksPath := filepath.Join(v.PluginPath, "hooks", "hooks.json")
-
-	if _, err := os.Stat(hooksPath); err == nil {
-		// Hooks file exists, validate it
-		data, err := os.ReadFile(hooksPath) // #nosec G304 -- CLI tool validates plugin hooks files
-		if err == nil {
-			var hooks map[string]interface{}
-			if err := json.Unmarshal(data, &hooks); err != nil {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("Invalid hooks.json: %v", err))
+	if _, err := os.Stat(filepath.Join(v.PluginPath, "hooks", "hooks.json")); err == nil {
+		v.validateHooksFile("hooks/hooks.json", result)
+	}
+}
+
+// validateHooksFile checks a hooks file (rel is relative to the plugin root):
+// a JSON object whose "hooks" key holds the hook configuration.
+func (v *Validator) validateHooksFile(rel string, result *ValidationResult) {
+	data, err := os.ReadFile(filepath.Join(v.PluginPath, filepath.FromSlash(rel))) // #nosec G304 -- CLI tool validates plugin hooks files
+	if err != nil {
+		result.errorf(rel, ruleHooks, "Cannot read %s: %v", path.Base(rel), err)
+		return
+	}
+	var file map[string]any
+	if err := json.Unmarshal(data, &file); err != nil {
+		result.errorf(rel, ruleHooks, "Invalid %s: %v", path.Base(rel), err)
+		return
+	}
+	hooks, ok := file["hooks"]
+	if !ok {
+		result.errorf(rel, ruleHooks, "%s missing top-level \"hooks\" object", path.Base(rel))
+		return
+	}
+	v.validateHookConfig(rel, hooks, result)
+}
+
+// validateHookConfig checks a hook configuration: an object mapping hook
+// events to lists of matchers, each with a list of hooks to run.
+func (v *Validator) validateHookConfig(file string, hooks any, result *ValidationResult) {
+	events, ok := hooks.(map[string]any)
+	if !ok {
+		result.errorf(file, ruleHooks, "\"hooks\" must be an object mapping hook events to matchers, got %s", jsonKind(hooks))
+		return
+	}
+	for _, event := range sortedKeys(events) {
+		if !hookEvents[event] {
+			result.add(mold.Diagnostic{
+				Severity: mold.SeverityError,
+				Message:  fmt.Sprintf("Unknown hook event: %s", event),
+				Tip:      "hook events: " + strings.Join(sortedKeys(hookEvents), ", "),
+				File:     file,
+				Rule:     ruleHooks,
+			})
+			continue
+		}
+		matchers, ok := events[event].([]any)
+		if !ok {
+			result.errorf(file, ruleHooks, "%s must be a list of matchers, got %s", event, jsonKind(events[event]))
+			continue
+		}
+		for i, m := range matchers {
+			loc := fmt.Sprintf("%s[%d]", event, i)
+			matcher, ok := m.(map[string]any)
+			if !ok {
+				result.errorf(file, ruleHooks, "%s must be an object, got %s", loc, jsonKind(m))
+				continue
+			}
+			if pattern, ok := matcher["matcher"]; ok && jsonKind(pattern) != kindString {
+				result.errorf(file, ruleHooks, "%s.matcher must be string, got %s", loc, jsonKind(pattern))
+			}
+			list, ok := matcher["hooks"].([]any)
+			if !ok {
+				result.errorf(file, ruleHooks, "%s missing \"hooks\" list", loc)
+				continue
+			}
+			for j, h := range list {
+				v.validateHook(file, fmt.Sprintf("%s.hooks[%d]", loc, j), h, result)
 			}
 		}
 	}
 }
 
+// validateHook checks a single hook entry and the plugin file its command
+// runs, when the command is rooted at ${CLAUDE_PLUGIN_ROOT}.
+func (v *Validator) validateHook(file, loc string, h any, result *ValidationResult) {
+	hook, ok := h.(map[string]any)
+	if !ok {
+		result.errorf(file, ruleHooks, "%s must be an object, got %s", loc, jsonKind(h))
+		return
+	}
+	typ, _ := hook["type"].(string)
+	field, known := hookTypes[typ]
+	if !known {
+		result.errorf(file, ruleHooks, "%s has unknown type %q (want %s)", loc, typ, strings.Join(sortedKeys(hookTypes), " or "))
+		return
+	}
+	value, ok := hook[field].(string)
+	if !ok || strings.TrimSpace(value) == "" {
+		result.errorf(file, ruleHooks, "%s missing required field: %s", loc, field)
+		return
+	}
+	if timeout, ok := hook["timeout"]; ok && jsonKind(timeout) != "number" {
+		result.errorf(file, ruleHooks, "%s.timeout must be number, got %s", loc, jsonKind(timeout))
+	}
+	if typ != "command" {
+		return
+	}
+	if ref := pluginRootReference(value); ref != "" {
+		if _, err := os.Stat(filepath.Join(v.PluginPath, filepath.FromSlash(ref))); err != nil {
+			result.errorf(file, rulePaths, "%s runs %s, which does not exist in the plugin", loc, ref)
+		}
+	}
+}
+
+// validateJSONFile reports a referenced config file that isn't valid JSON.
+func (v *Validator) validateJSONFile(rel string, result *ValidationResult) {
+	data, err := os.ReadFile(filepath.Join(v.PluginPath, filepath.FromSlash(rel))) // #nosec G304 -- CLI tool validates plugin config files
+	if err != nil {
+		return
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		result.errorf(rel, rulePaths, "Invalid %s: %v", path.Base(rel), err)
+	}
+}
+
 func (v *Validator) validateScripts(result *ValidationResult) {
 	scriptsPath := filepath.Join(v.PluginPath, "scripts")
 
@@ -193,7 +458,7 @@ func (v *Validator) validateScripts(result *ValidationResult) {
 		// Check for install script
 		installScript := filepath.Join(scriptsPath, "install.sh")
 		if _, err := os.Stat(installScript); err != nil {
-			result.Warnings = append(result.Warnings, "Missing install.sh script (recommended)")
+			result.warnf("scripts/install.sh", ruleStructure, "Missing install.sh script (recommended)")
 		}
 	}
 }
@@ -217,6 +482,50 @@ func hasInstructions(content string) bool {
 	return containsPattern(content, "## Instructions") || containsPattern(content, "When this command")
 }
 
+// frontmatterTypeOK reports whether a decoded frontmatter value has the type
+// named in commandFrontmatterFields.
+func frontmatterTypeOK(want string, value any) bool {
+	switch want {
+	case kindString:
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default: // string or list of strings
+		if _, ok := value.(string); ok {
+			return true
+		}
+		list, ok := value.([]any)
+		if !ok {
+			return false
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// pluginRootReference returns the plugin-relative path a hook command runs
+// from ${CLAUDE_PLUGIN_ROOT}, or "" when it doesn't reference one.
+func pluginRootReference(command string) string {
+	_, rest, ok := strings.Cut(command, pluginRootVar)
+	if !ok {
+		return ""
+	}
+	if end := strings.IndexAny(rest, " \t\"'"); end >= 0 {
+		rest = rest[:end]
+	}
+	rest = strings.TrimPrefix(path.Clean("/"+rest), "/")
+	if rest == "" {
+		return ""
+	}
+	return rest
+}
+
 func containsPattern(content, pattern string) bool {
 	return len(content) > len(pattern) && (findSubstring(content, pattern) != -1)
 }
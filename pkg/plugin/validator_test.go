@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
 )
 
 func setupValidPlugin(t *testing.T) string {
@@ -58,9 +60,8 @@ When this command is invoked, you must:
 	}
 
 	// Create hooks
-	hooks := map[string]interface{}{"hooks": []interface{}{}}
-	hooksData, _ := json.MarshalIndent(hooks, "", "  ")
-	if err := os.WriteFile(filepath.Join(dir, "hooks", "hooks.json"), hooksData, 0644); err != nil {
+	hooks := `{"hooks": {"PostToolUse": [{"matcher": "Write|Edit", "hooks": [{"type": "command", "command": "${CLAUDE_PLUGIN_ROOT}/scripts/install.sh"}]}]}}`
+	if err := os.WriteFile(filepath.Join(dir, "hooks", "hooks.json"), []byte(hooks), 0644); err != nil {
 		t.Fatalf("failed to write hooks: %v", err)
 	}
 
@@ -280,14 +281,17 @@ func TestValidator_InvalidHooksJSON(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	foundWarning := false
-	for _, w := range result.Warnings {
-		if contains(w, "hooks.json") {
-			foundWarning = true
+	if result.IsValid {
+		t.Error("expected IsValid to be false with invalid hooks.json")
+	}
+	foundError := false
+	for _, e := range result.Errors {
+		if contains(e, "hooks.json") {
+			foundError = true
 		}
 	}
-	if !foundWarning {
-		t.Error("expected warning about invalid hooks.json")
+	if !foundError {
+		t.Error("expected error about invalid hooks.json")
 	}
 }
 
@@ -315,6 +319,211 @@ func TestValidator_CommandMissingHeader(t *testing.T) {
 	}
 }
 
+func TestValidator_ValidPluginHasNoDiagnostics(t *testing.T) {
+	result, err := NewValidator(setupValidPlugin(t)).Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %+v", result.Diagnostics)
+	}
+}
+
+func TestValidator_ManifestSchema(t *testing.T) {
+	dir := setupValidPlugin(t)
+	writePluginJSON(t, dir, `{
+  "name": "My Plugin",
+  "version": 1,
+  "description": "A test plugin",
+  "author": {"name": "Test Author", "email": 42},
+  "keywords": ["ok", 7],
+  "marketplace": "acme"
+}`)
+
+	result, err := NewValidator(dir).Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsValid {
+		t.Error("expected IsValid to be false with schema violations")
+	}
+	for _, want := range []struct {
+		severity mold.DiagSeverity
+		message  string
+	}{
+		{mold.SeverityError, "kebab-case"},
+		{mold.SeverityError, "Manifest field version must be string, got number"},
+		{mold.SeverityError, "Manifest author field email must be string"},
+		{mold.SeverityError, "Manifest keywords[1] must be string"},
+		{mold.SeverityWarning, "Unknown manifest field: marketplace"},
+	} {
+		d := findDiag(result, ruleManifest, want.message)
+		if d == nil {
+			t.Errorf("missing diagnostic %q in %+v", want.message, result.Diagnostics)
+			continue
+		}
+		if d.Severity != want.severity || d.File != ".claude-plugin/plugin.json" {
+			t.Errorf("diagnostic %q = %+v", want.message, *d)
+		}
+	}
+}
+
+func TestValidator_ManifestPaths(t *testing.T) {
+	dir := setupValidPlugin(t)
+	if err := os.MkdirAll(filepath.Join(dir, "extra"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	writePluginJSON(t, dir, `{
+  "name": "test-plugin",
+  "version": "1.0.0",
+  "description": "A test plugin",
+  "commands": ["./extra", "./missing"],
+  "agents": "agents/",
+  "hooks": "./hooks/extra.json"
+}`)
+
+	result, err := NewValidator(dir).Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findDiag(result, rulePaths, "./extra") != nil {
+		t.Error("existing path ./extra should not be reported")
+	}
+	for _, want := range []string{
+		`Manifest commands path "./missing" does not exist`,
+		`Manifest agents path "agents/" must be relative to the plugin root and start with ./`,
+		`Manifest hooks path "./hooks/extra.json" does not exist`,
+	} {
+		if findDiag(result, rulePaths, want) == nil {
+			t.Errorf("missing diagnostic %q in %+v", want, result.Diagnostics)
+		}
+	}
+}
+
+func TestValidator_HooksSchema(t *testing.T) {
+	dir := setupValidPlugin(t)
+	hooks := `{"hooks": {
+  "PostToolUse": [{"matcher": "Write", "hooks": [{"type": "command", "command": "\"${CLAUDE_PLUGIN_ROOT}/scripts/format.sh\" --fix"}]}],
+  "BeforeToolUse": [],
+  "Stop": [{"hooks": [{"type": "shell", "command": "echo done"}, {"type": "prompt"}]}]
+}}`
+	if err := os.WriteFile(filepath.Join(dir, "hooks", "hooks.json"), []byte(hooks), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewValidator(dir).Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsValid {
+		t.Error("expected IsValid to be false with an invalid hooks.json")
+	}
+	for _, want := range []struct{ rule, message string }{
+		{ruleHooks, "Unknown hook event: BeforeToolUse"},
+		{ruleHooks, `Stop[0].hooks[0] has unknown type "shell"`},
+		{ruleHooks, "Stop[0].hooks[1] missing required field: prompt"},
+		{rulePaths, "PostToolUse[0].hooks[0] runs scripts/format.sh, which does not exist"},
+	} {
+		d := findDiag(result, want.rule, want.message)
+		if d == nil {
+			t.Errorf("missing diagnostic %q in %+v", want.message, result.Diagnostics)
+			continue
+		}
+		if d.File != "hooks/hooks.json" {
+			t.Errorf("diagnostic %q has File %q", want.message, d.File)
+		}
+	}
+}
+
+func TestValidator_InlineManifestHooks(t *testing.T) {
+	dir := setupValidPlugin(t)
+	writePluginJSON(t, dir, `{
+  "name": "test-plugin",
+  "version": "1.0.0",
+  "description": "A test plugin",
+  "hooks": {"OnSave": []}
+}`)
+
+	result, err := NewValidator(dir).Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := findDiag(result, ruleHooks, "Unknown hook event: OnSave")
+	if d == nil || d.File != ".claude-plugin/plugin.json" {
+		t.Errorf("expected an unknown event diagnostic on plugin.json, got %+v", result.Diagnostics)
+	}
+}
+
+func TestValidator_CommandFrontmatter(t *testing.T) {
+	dir := setupValidPlugin(t)
+	cmd := `---
+description: Review a pull request
+argument-hint: <pr-number>
+allowed-tools: [Bash, Read]
+disable-model-invocation: "yes"
+color: blue
+---
+# review
+
+## Instructions
+
+Review the pull request.
+`
+	if err := os.WriteFile(filepath.Join(dir, "commands", "review.md"), []byte(cmd), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewValidator(dir).Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := findDiag(result, ruleFrontmatter, "disable-model-invocation must be boolean"); d == nil || d.Severity != mold.SeverityError || d.File != "commands/review.md" {
+		t.Errorf("expected a type error for disable-model-invocation, got %+v", result.Diagnostics)
+	}
+	if d := findDiag(result, ruleFrontmatter, "unknown frontmatter field: color"); d == nil || d.Severity != mold.SeverityWarning {
+		t.Errorf("expected an unknown field warning for color, got %+v", result.Diagnostics)
+	}
+	if findDiag(result, ruleStructure, "review.md missing proper header") != nil {
+		t.Error("the header after frontmatter should be accepted")
+	}
+	if findDiag(result, ruleFrontmatter, "allowed-tools") != nil {
+		t.Error("a list of tools is valid allowed-tools")
+	}
+}
+
+func TestPluginRootReference(t *testing.T) {
+	tests := map[string]string{
+		"${CLAUDE_PLUGIN_ROOT}/scripts/run.sh":     "scripts/run.sh",
+		`"${CLAUDE_PLUGIN_ROOT}/bin/fmt" --check`:  "bin/fmt",
+		"bash ${CLAUDE_PLUGIN_ROOT}/../outside.sh": "outside.sh",
+		"echo ${CLAUDE_PLUGIN_ROOT}":               "",
+		"npx prettier --write":                     "",
+	}
+	for command, want := range tests {
+		if got := pluginRootReference(command); got != want {
+			t.Errorf("pluginRootReference(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func writePluginJSON(t *testing.T, dir, manifest string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ".claude-plugin", "plugin.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+}
+
+// findDiag returns the first diagnostic with the given rule whose message
+// contains substr, or nil.
+func findDiag(result *ValidationResult, rule, substr string) *mold.Diagnostic {
+	for i := range result.Diagnostics {
+		if result.Diagnostics[i].Rule == rule && contains(result.Diagnostics[i].Message, substr) {
+			return &result.Diagnostics[i]
+		}
+	}
+	return nil
+}
+
 func TestIsValidVersion(t *testing.T) {
 	tests := []struct {
 		version string
@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+// WindsurfRulesName is the project rules file Windsurf's Cascade reads.
+const WindsurfRulesName = ".windsurfrules"
+
+const windsurfWorkflowsPrefix = ".windsurf/workflows/"
+
+// WindsurfWorkflow is a single Windsurf Cascade workflow
+// (.windsurf/workflows/<name>.md), invoked as /<name>.
+type WindsurfWorkflow struct {
+	Name        string
+	Description string
+	// Body is the markdown following the frontmatter.
+	Body string
+	// Source is the cast destination the workflow was compiled from.
+	Source string
+}
+
+// Markdown renders the workflow file: description frontmatter followed by
+// the body.
+func (w *WindsurfWorkflow) Markdown() ([]byte, error) {
+	data, err := yaml.Marshal(yaml.MapSlice{{Key: "description", Value: w.Description}})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling frontmatter for workflow %s: %w", w.Name, err)
+	}
+	return frontmatterDocument(data, w.Body), nil
+}
+
+// WindsurfOutput is a mold converted for Windsurf: workflows from command
+// blanks and .windsurfrules sections from skills.
+type WindsurfOutput struct {
+	Workflows []*WindsurfWorkflow
+	Rules     []*InstructionSection
+}
+
+// RulesMarkdown renders the block added to .windsurfrules, one section per
+// skill. Empty when the mold has no skills.
+func (o *WindsurfOutput) RulesMarkdown() []byte {
+	return instructionsMarkdown(nil, o.Rules)
+}
+
+// CompileWindsurf converts rendered blanks for Windsurf. Command blanks and
+// blanks already cast under .windsurf/workflows/ become workflows; skill
+// entrypoints become .windsurfrules sections. Resources and other files are
+// ignored.
+func CompileWindsurf(files []RenderedFile) (*WindsurfOutput, error) {
+	entries, err := collectEntrypoints(files, "workflow", windsurfWorkflowsPrefix, ".md")
+	if err != nil {
+		return nil, err
+	}
+	out := &WindsurfOutput{}
+	for _, e := range entries {
+		if e.Kind == entrySkill {
+			s, err := skillSection(e)
+			if err != nil {
+				return nil, err
+			}
+			out.Rules = append(out.Rules, s)
+			continue
+		}
+		desc, _, body, err := carriedFrontmatter(e.File.Content, e.File.CastDest)
+		if err != nil {
+			return nil, err
+		}
+		out.Workflows = append(out.Workflows, &WindsurfWorkflow{
+			Name: e.Name, Description: desc, Body: body, Source: e.File.CastDest,
+		})
+	}
+	return out, nil
+}
+
+// WindsurfAdapter is the "windsurf" output adapter: workflows under
+// .windsurf/workflows/ and skills in a .windsurfrules block keyed by the
+// mold's name. Project-only.
+type WindsurfAdapter struct{}
+
+// Name implements blanks.OutputAdapter.
+func (WindsurfAdapter) Name() string { return FormatWindsurf }
+
+// Title implements blanks.TitledAdapter.
+func (WindsurfAdapter) Title() string { return "Windsurf rules and workflows" }
+
+// Hint implements blanks.HintAdapter.
+func (WindsurfAdapter) Hint() string { return "Run workflows in Cascade as /<name>." }
+
+// MapDestination implements blanks.OutputAdapter. Skills are left to
+// PostInstall, which folds them into .windsurfrules.
+func (WindsurfAdapter) MapDestination(dest string) (string, bool) {
+	name, kind, ok := classifyEntrypoint(dest, windsurfWorkflowsPrefix, ".md")
+	if !ok || kind == entrySkill {
+		return "", false
+	}
+	return windsurfWorkflowsPrefix + name + ".md", true
+}
+
+// TransformContent implements blanks.OutputAdapter.
+func (WindsurfAdapter) TransformContent(dest string, content []byte) ([]byte, error) {
+	name, _, _ := classifyEntrypoint(dest, windsurfWorkflowsPrefix, ".md")
+	desc, _, body, err := carriedFrontmatter(content, dest)
+	if err != nil {
+		return nil, err
+	}
+	w := &WindsurfWorkflow{Name: name, Description: desc, Body: body, Source: dest}
+	return w.Markdown()
+}
+
+// PostInstall implements blanks.OutputAdapter: it writes the skill sections
+// into a .windsurfrules block.
+func (WindsurfAdapter) PostInstall(ctx *blanks.InstallContext) error {
+	out, err := CompileWindsurf(ctx.Rendered)
+	if err != nil {
+		return err
+	}
+	rules := out.RulesMarkdown()
+	if len(rules) == 0 {
+		return nil
+	}
+	return ctx.Write(WindsurfRulesName, rules, blanks.WriteBlock)
+}
@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/blanks"
+)
+
+func TestCompileWindsurf(t *testing.T) {
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/deploy.md", Content: []byte("---\ndescription: Deploys.\nallowed-tools: Bash\n---\n1. Build\n2. Ship\n")},
+		{CastDest: ".claude/skills/go-style/SKILL.md", Content: []byte("---\ndescription: Go conventions.\n---\n# Go\nUse gofmt.\n")},
+		{CastDest: ".windsurf/workflows/native.md", Content: []byte("Native steps\n")},
+	}
+	out, err := CompileWindsurf(files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Workflows) != 2 || len(out.Rules) != 1 {
+		t.Fatalf("workflows=%d rules=%d", len(out.Workflows), len(out.Rules))
+	}
+	md, err := out.Workflows[0].Markdown()
+	if err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+	if want := "---\ndescription: Deploys.\n---\n\n1. Build\n2. Ship\n"; string(md) != want {
+		t.Errorf("workflow:\n%s\nwant:\n%s", md, want)
+	}
+	if want := "## go-style\n\nGo conventions.\n\n### Go\nUse gofmt.\n"; string(out.RulesMarkdown()) != want {
+		t.Errorf("rules:\n%s\nwant:\n%s", out.RulesMarkdown(), want)
+	}
+}
+
+func TestWindsurfAdapter_WritesRulesBlock(t *testing.T) {
+	dir := t.TempDir()
+	rulesPath := filepath.Join(dir, WindsurfRulesName)
+	if err := os.WriteFile(rulesPath, []byte("Team rules.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := []RenderedFile{
+		{CastDest: ".claude/commands/deploy.md", Content: []byte("Ship it.\n")},
+		{CastDest: ".claude/skills/style/SKILL.md", Content: []byte("Be terse.\n")},
+	}
+	if _, err := blanks.Adapt(WindsurfAdapter{}, files, blanks.AdaptOptions{Root: dir, MoldName: "wind"}); err != nil {
+		t.Fatalf("Adapt: %v", err)
+	}
+
+	rules, err := os.ReadFile(rulesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(rules)
+	if !strings.HasPrefix(got, "Team rules.\n") || !strings.Contains(got, "ailloy:mold=wind:start") || !strings.Contains(got, "## style") {
+		t.Errorf("unexpected .windsurfrules:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".windsurf", "workflows", "deploy.md")); err != nil {
+		t.Errorf("expected workflow: %v", err)
+	}
+}
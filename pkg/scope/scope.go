@@ -0,0 +1,157 @@
+// Package scope locates ailloy's install roots and reports how they stack.
+//
+// Three scopes layer from lowest to highest precedence:
+//
+//   - system: organization-provisioned content shared by every user on the
+//     machine (AILLOY_SYSTEM_ROOT, else /usr/local/share/ailloy or
+//     /etc/ailloy). Usually read-only for regular users.
+//   - global: the user's ~/.ailloy.
+//   - project: ./.ailloy in the current working directory, layered over the
+//     .ailloy directories of its workspace ancestors (see WorkspaceDirs).
+//
+// Each root may hold config.yaml (foundries), ores/, ingots/, and flux/.
+// Readers consult every scope and let the higher one win on conflicts.
+package scope
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// SystemRootEnv overrides the system root. When set, it is used even if the
+// directory does not exist yet, so an administrator can point --system
+// writes at a new location.
+const SystemRootEnv = "AILLOY_SYSTEM_ROOT"
+
+// Scope names, in precedence order (lowest first).
+const (
+	System  = "system"
+	Global  = "global"
+	Project = "project"
+)
+
+// SystemRootCandidates returns the default system root locations, checked
+// in order.
+func SystemRootCandidates() []string {
+	if runtime.GOOS == "windows" {
+		if pd := os.Getenv("ProgramData"); pd != "" {
+			return []string{filepath.Join(pd, "ailloy")}
+		}
+		return nil
+	}
+	return []string{"/usr/local/share/ailloy", "/etc/ailloy"}
+}
+
+// SystemRoot returns the active system root: the AILLOY_SYSTEM_ROOT value
+// if set, otherwise the first candidate that exists. It returns "" when the
+// machine has no system scope.
+func SystemRoot() string {
+	if v := os.Getenv(SystemRootEnv); v != "" {
+		return v
+	}
+	for _, c := range SystemRootCandidates() {
+		if info, err := os.Stat(c); err == nil && info.IsDir() {
+			return c
+		}
+	}
+	return ""
+}
+
+// SystemInstallRoot returns where --system writes go: the active system
+// root, or the first candidate when none exists yet.
+func SystemInstallRoot() string {
+	if root := SystemRoot(); root != "" {
+		return root
+	}
+	if c := SystemRootCandidates(); len(c) > 0 {
+		return c[0]
+	}
+	return ""
+}
+
+// GlobalRoot returns ~/.ailloy, or "" if the home directory is unknown.
+func GlobalRoot() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ailloy")
+}
+
+// ProjectRoot returns the project-scope root, relative to the working
+// directory.
+func ProjectRoot() string {
+	return ".ailloy"
+}
+
+// Writable reports whether the current user can create files in dir, or in
+// its nearest existing ancestor when dir does not exist yet.
+func Writable(dir string) bool {
+	if dir == "" {
+		return false
+	}
+	probe := dir
+	for {
+		if info, err := os.Stat(probe); err == nil {
+			if !info.IsDir() {
+				return false
+			}
+			break
+		}
+		parent := filepath.Dir(probe)
+		if parent == probe {
+			return false
+		}
+		probe = parent
+	}
+	f, err := os.CreateTemp(probe, ".ailloy-write-probe-*")
+	if err != nil {
+		return false
+	}
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
+	return true
+}
+
+// RequireWritableSystem returns the system install root when the current
+// user can write to it, and an actionable error otherwise.
+func RequireWritableSystem() (string, error) {
+	root := SystemInstallRoot()
+	if root == "" {
+		return "", fmt.Errorf("no system scope location on this platform; set %s", SystemRootEnv)
+	}
+	if !Writable(root) {
+		return "", fmt.Errorf("system scope %s is read-only for this user; re-run as an administrator (e.g. with sudo) or set %s to a writable directory", root, SystemRootEnv)
+	}
+	return root, nil
+}
+
+// Layer describes one scope in the effective stack.
+type Layer struct {
+	Name     string
+	Root     string // "" when the scope is unavailable (no system root, no home)
+	Exists   bool
+	Writable bool
+}
+
+// Stack returns the scopes from lowest to highest precedence.
+func Stack() []Layer {
+	layers := []Layer{
+		{Name: System, Root: SystemRoot()},
+		{Name: Global, Root: GlobalRoot()},
+		{Name: Project, Root: ProjectRoot()},
+	}
+	for i := range layers {
+		if layers[i].Root == "" {
+			continue
+		}
+		if info, err := os.Stat(layers[i].Root); err == nil && info.IsDir() {
+			layers[i].Exists = true
+		}
+		layers[i].Writable = Writable(layers[i].Root)
+	}
+	return layers
+}
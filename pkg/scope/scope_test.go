@@ -0,0 +1,82 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSystemRoot_EnvOverride(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "not-yet-created")
+	t.Setenv(SystemRootEnv, dir)
+
+	if got := SystemRoot(); got != dir {
+		t.Errorf("SystemRoot() = %q, want %q", got, dir)
+	}
+	if got := SystemInstallRoot(); got != dir {
+		t.Errorf("SystemInstallRoot() = %q, want %q", got, dir)
+	}
+}
+
+func TestWritable(t *testing.T) {
+	dir := t.TempDir()
+	if !Writable(dir) {
+		t.Errorf("temp dir should be writable")
+	}
+	if !Writable(filepath.Join(dir, "a", "b")) {
+		t.Errorf("missing dir under a writable parent should be writable")
+	}
+	if Writable("") {
+		t.Errorf("empty path should not be writable")
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if Writable(filepath.Join(file, "child")) {
+		t.Errorf("path under a regular file should not be writable")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("probe files should be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestRequireWritableSystem_ReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o555); err != nil { // #nosec G302 -- test fixture
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o750) }) // #nosec G302 -- restore for cleanup
+	t.Setenv(SystemRootEnv, dir)
+
+	_, err := RequireWritableSystem()
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+}
+
+func TestStack_Order(t *testing.T) {
+	t.Setenv(SystemRootEnv, t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	layers := Stack()
+	var names []string
+	for _, l := range layers {
+		names = append(names, l.Name)
+	}
+	if strings.Join(names, ",") != "system,global,project" {
+		t.Fatalf("order = %v", names)
+	}
+	if !layers[0].Exists || !layers[0].Writable {
+		t.Errorf("system layer = %+v, want existing and writable", layers[0])
+	}
+	if layers[1].Exists {
+		t.Errorf("global root under an empty HOME should not exist")
+	}
+}
@@ -0,0 +1,86 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+)
+
+// WorkspaceMarkerFile is the project config file whose top-level
+// `workspace: true` marks the root of a workspace.
+const WorkspaceMarkerFile = ".ailloyrc.yaml"
+
+// WorkspaceDirs returns the directories whose project configuration applies
+// to start, from the workspace root down to start itself.
+//
+// The workspace root is the nearest ancestor (start included) whose
+// .ailloyrc.yaml sets `workspace: true`; without one it is the nearest
+// repository root (a directory holding .git). When neither exists only start
+// is returned, so a lone directory behaves exactly as before. Sub-packages of
+// a monorepo thereby inherit the root's configuration while their own files,
+// later in the list, override it.
+func WorkspaceDirs(start string) []string {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return []string{start}
+	}
+	var chain []string // start first
+	repoRoot := -1
+	for {
+		chain = append(chain, dir)
+		if isWorkspaceMarker(dir) {
+			slices.Reverse(chain)
+			return chain
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil && repoRoot < 0 {
+			repoRoot = len(chain) - 1
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if repoRoot < 0 {
+		return chain[:1]
+	}
+	chain = chain[:repoRoot+1]
+	slices.Reverse(chain)
+	return chain
+}
+
+// WorkspaceProjectRoots returns the project-scope roots (.ailloy
+// directories) of the working directory's workspace ancestors, nearest
+// first. The working directory's own ./.ailloy is not included; see
+// ProjectRoot. Roots that do not exist are skipped.
+func WorkspaceProjectRoots() []string {
+	dirs := WorkspaceDirs(".")
+	var roots []string
+	for i := len(dirs) - 2; i >= 0; i-- {
+		root := filepath.Join(dirs[i], ".ailloy")
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// isWorkspaceMarker reports whether dir's .ailloyrc.yaml (or .yml) sets
+// `workspace: true`.
+func isWorkspaceMarker(dir string) bool {
+	for _, name := range []string{WorkspaceMarkerFile, ".ailloyrc.yml"} {
+		data, err := os.ReadFile(filepath.Join(dir, name)) // #nosec G304 -- fixed file name in an ancestor directory
+		if err != nil {
+			continue
+		}
+		var rc struct {
+			Workspace bool `yaml:"workspace"`
+		}
+		if yaml.Unmarshal(data, &rc) == nil && rc.Workspace {
+			return true
+		}
+	}
+	return false
+}
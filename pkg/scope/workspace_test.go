@@ -0,0 +1,48 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWorkspaceDirs(t *testing.T) {
+	root, _ := filepath.EvalSymlinks(t.TempDir())
+	repo := filepath.Join(root, "repo")
+	pkg := filepath.Join(repo, "packages", "web")
+	if err := os.MkdirAll(pkg, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	// No repository and no marker: only the start directory.
+	if got := WorkspaceDirs(pkg); !slices.Equal(got, []string{pkg}) {
+		t.Errorf("bare dir: got %v", got)
+	}
+
+	// A .git directory makes the repository root the workspace root.
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{repo, filepath.Join(repo, "packages"), pkg}
+	if got := WorkspaceDirs(pkg); !slices.Equal(got, want) {
+		t.Errorf("repo: got %v, want %v", got, want)
+	}
+
+	// A workspace marker takes precedence, even above the repository.
+	if err := os.WriteFile(filepath.Join(root, WorkspaceMarkerFile), []byte("workspace: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want = append([]string{root}, want...)
+	if got := WorkspaceDirs(pkg); !slices.Equal(got, want) {
+		t.Errorf("marker: got %v, want %v", got, want)
+	}
+
+	// workspace: false is not a marker.
+	if err := os.WriteFile(filepath.Join(root, WorkspaceMarkerFile), []byte("workspace: false\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := WorkspaceDirs(pkg); got[0] != repo {
+		t.Errorf("workspace: false: got root %q, want %q", got[0], repo)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/goccy/go-yaml"
 	"github.com/knadh/stuffbin"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/safepath"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -16,19 +19,129 @@ import (
 // all mold files and appending them to the current ailloy binary using stuffbin.
 // The output binary can be distributed and run directly: ./my-mold cast.
 func PackageBinary(moldDir, outputDir string) (string, int64, error) {
+	return PackageMultiMoldBinary([]string{moldDir}, "", outputDir)
+}
+
+// PackageMultiMoldBinary packages one or more molds into a single
+// self-contained binary. With one mold it is PackageBinary. With several,
+// each mold's files are embedded under molds/<name>/ next to a molds.yaml
+// index, and the binary picks one at cast time (--mold, a prompt, or
+// defaultMold when set). The binary is named after the first mold.
+func PackageMultiMoldBinary(moldDirs []string, defaultMold, outputDir string) (string, int64, error) {
+	prefix, stuffPaths, cleanup, err := stageBinary(moldDirs, defaultMold)
+	if err != nil {
+		return "", 0, err
+	}
+	defer cleanup()
+
+	// Resolve current executable.
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", 0, fmt.Errorf("resolving executable: %w", err)
+	}
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("resolving executable symlinks: %w", err)
+	}
+
+	// Determine output path.
+	if outputDir == "" {
+		outputDir = "."
+	}
+	outputPath := filepath.Join(outputDir, prefix)
+
+	size, err := stuffBinary(execPath, outputPath, stuffPaths)
+	if err != nil {
+		return "", 0, err
+	}
+	return outputPath, size, nil
+}
+
+// stageBinary collects the files of every mold in moldDirs and writes them
+// to a staging directory. A single mold is laid out at the root, as binaries
+// always have been; several go under molds/<name>/ with a molds.yaml index.
+// It returns the binary's {name}-{version} prefix (from the first mold), the
+// stuffbin alias paths, and a cleanup func that removes the staging directory.
+func stageBinary(moldDirs []string, defaultMold string) (string, []string, func(), error) {
+	if len(moldDirs) == 0 {
+		return "", nil, nil, fmt.Errorf("no mold directories given")
+	}
+	if len(moldDirs) == 1 && defaultMold != "" {
+		return "", nil, nil, fmt.Errorf("a default mold needs more than one mold")
+	}
+
+	var (
+		files  []archiveFile
+		prefix string
+		index  EmbeddedIndex
+	)
+	for i, dir := range moldDirs {
+		m, moldFiles, err := collectBinaryFiles(dir)
+		if err != nil {
+			if len(moldDirs) > 1 {
+				return "", nil, nil, fmt.Errorf("%s: %w", dir, err)
+			}
+			return "", nil, nil, err
+		}
+		if i == 0 {
+			prefix = fmt.Sprintf("%s-%s", m.Name, m.Version)
+		}
+		if len(moldDirs) == 1 {
+			files = moldFiles
+			break
+		}
+		if index.Find(m.Name) != nil {
+			return "", nil, nil, fmt.Errorf("two molds are named %q; embedded molds need distinct names", m.Name)
+		}
+		index.Molds = append(index.Molds, EmbeddedMold{Name: m.Name, Version: m.Version, Description: m.Description})
+		for _, f := range moldFiles {
+			files = append(files, archiveFile{path: embeddedMoldDir(m.Name) + "/" + f.path, data: f.data})
+		}
+	}
+	if len(moldDirs) > 1 {
+		if defaultMold != "" && index.Find(defaultMold) == nil {
+			return "", nil, nil, fmt.Errorf("default mold %q is not one of the molds being smelted (%s)", defaultMold, strings.Join(index.Names(), ", "))
+		}
+		index.Default = defaultMold
+		data, err := yaml.Marshal(index)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("writing %s: %w", EmbeddedIndexFile, err)
+		}
+		files = append(files, archiveFile{path: EmbeddedIndexFile, data: data})
+	}
+
+	// Write collected files to a temp staging directory in parallel.
+	stagingDir, err := tmpdir.MkdirTemp("smelt-*")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(stagingDir) }
+
+	stuffPaths, err := stageFiles(stagingDir, files)
+	if err != nil {
+		cleanup()
+		return "", nil, nil, fmt.Errorf("staging files: %w", err)
+	}
+	return prefix, stuffPaths, cleanup, nil
+}
+
+// collectBinaryFiles loads and validates the mold in moldDir and returns
+// every file the binary embeds for it: mold files, generated flux defaults,
+// the transitive dep tree and provenance.yaml.
+func collectBinaryFiles(moldDir string) (*mold.Mold, []archiveFile, error) {
 	cleanDir, err := safepath.Clean(moldDir)
 	if err != nil {
-		return "", 0, fmt.Errorf("invalid mold directory: %w", err)
+		return nil, nil, fmt.Errorf("invalid mold directory: %w", err)
 	}
 
 	moldPath := filepath.Join(cleanDir, "mold.yaml")
 	m, err := mold.LoadMold(moldPath)
 	if err != nil {
-		return "", 0, fmt.Errorf("loading mold: %w", err)
+		return nil, nil, fmt.Errorf("loading mold: %w", err)
 	}
 
 	if err := mold.ValidateMold(m); err != nil {
-		return "", 0, fmt.Errorf("validating mold: %w", err)
+		return nil, nil, fmt.Errorf("validating mold: %w", err)
 	}
 
 	moldFS := os.DirFS(cleanDir)
@@ -36,14 +149,14 @@ func PackageBinary(moldDir, outputDir string) (string, int64, error) {
 	// Collect files to include in the binary.
 	files, hasFluxYAML, err := collectMoldFiles(moldFS, cleanDir)
 	if err != nil {
-		return "", 0, fmt.Errorf("collecting files: %w", err)
+		return nil, nil, fmt.Errorf("collecting files: %w", err)
 	}
 
 	// Generate flux.yaml defaults only if no source flux.yaml was found.
 	if !hasFluxYAML {
 		fluxData, err := generateFluxDefaults(m.Flux)
 		if err != nil {
-			return "", 0, fmt.Errorf("generating flux defaults: %w", err)
+			return nil, nil, fmt.Errorf("generating flux defaults: %w", err)
 		}
 		if fluxData != nil {
 			files = append(files, archiveFile{path: "flux.yaml", data: fluxData})
@@ -53,63 +166,42 @@ func PackageBinary(moldDir, outputDir string) (string, int64, error) {
 	// Resolve and embed the full transitive dep tree (molds + ores + ingots).
 	depFiles, depManifest, err := collectDeps(cleanDir, m)
 	if err != nil {
-		return "", 0, fmt.Errorf("collecting deps: %w", err)
+		return nil, nil, fmt.Errorf("collecting deps: %w", err)
 	}
 	files = append(files, depFiles...)
 	if manifestData := marshalDepManifest(depManifest); manifestData != nil {
 		files = append(files, archiveFile{path: "deps/manifest.json", data: manifestData})
 	}
 
-	// Write collected files to a temp staging directory in parallel.
-	stagingDir, err := os.MkdirTemp("", "ailloy-smelt-*")
-	if err != nil {
-		return "", 0, fmt.Errorf("creating staging directory: %w", err)
-	}
-	defer func() { _ = os.RemoveAll(stagingDir) }()
-
-	stuffPaths, err := stageFiles(stagingDir, files)
-	if err != nil {
-		return "", 0, fmt.Errorf("staging files: %w", err)
-	}
-
-	// Resolve current executable.
-	execPath, err := os.Executable()
+	prov, err := buildProvenance(m, cleanDir, files)
 	if err != nil {
-		return "", 0, fmt.Errorf("resolving executable: %w", err)
-	}
-	execPath, err = filepath.EvalSymlinks(execPath)
-	if err != nil {
-		return "", 0, fmt.Errorf("resolving executable symlinks: %w", err)
+		return nil, nil, err
 	}
+	return m, append(files, prov), nil
+}
 
-	// Determine output path.
-	outputName := fmt.Sprintf("%s-%s", m.Name, m.Version)
-	if outputDir == "" {
-		outputDir = "."
-	}
-	outputPath := filepath.Join(outputDir, outputName)
-
+// stuffBinary copies the ailloy binary at basePath to outputPath, appends the
+// staged files, and makes the result executable. It returns the output size.
+func stuffBinary(basePath, outputPath string, stuffPaths []string) (int64, error) {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0750); err != nil { // #nosec G301
-		return "", 0, fmt.Errorf("creating output directory: %w", err)
+		return 0, fmt.Errorf("creating output directory: %w", err)
 	}
 
 	// Stuff the binary with mold files using alias format for clean zip paths.
-	_, _, err = stuffbin.Stuff(execPath, outputPath, "/", stuffPaths...)
-	if err != nil {
-		return "", 0, fmt.Errorf("stuffing binary: %w", err)
+	if _, _, err := stuffbin.Stuff(basePath, outputPath, "/", stuffPaths...); err != nil {
+		return 0, fmt.Errorf("stuffing binary: %w", err)
 	}
 
 	// Make output executable.
 	if err := os.Chmod(outputPath, 0755); err != nil { // #nosec G302 -- binary must be executable
-		return "", 0, fmt.Errorf("making binary executable: %w", err)
+		return 0, fmt.Errorf("making binary executable: %w", err)
 	}
 
 	info, err := os.Stat(outputPath)
 	if err != nil {
-		return "", 0, fmt.Errorf("stating output: %w", err)
+		return 0, fmt.Errorf("stating output: %w", err)
 	}
-
-	return outputPath, info.Size(), nil
+	return info.Size(), nil
 }
 
 // stageFiles writes archiveFiles to a staging directory in parallel using
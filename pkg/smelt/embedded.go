@@ -2,16 +2,66 @@ package smelt
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/goccy/go-yaml"
 	"github.com/knadh/stuffbin"
 )
 
 // ErrNoEmbeddedMold indicates the current binary does not contain a stuffed mold.
 var ErrNoEmbeddedMold = errors.New("no embedded mold in binary")
 
+// ErrNoMoldSelected indicates the current binary embeds several molds, has
+// no default, and none was chosen with SelectEmbeddedMold.
+var ErrNoMoldSelected = errors.New("this binary embeds several molds; choose one with --mold")
+
+var errSingleMold = errors.New("this binary embeds a single mold; --mold applies to binaries smelted with several")
+
+// EmbeddedIndexFile lists the molds of a binary smelted with several; each
+// mold's files live under molds/<name>/.
+const EmbeddedIndexFile = "molds.yaml"
+
+// EmbeddedIndex is the contents of molds.yaml.
+type EmbeddedIndex struct {
+	Default string         `yaml:"default,omitempty"`
+	Molds   []EmbeddedMold `yaml:"molds"`
+}
+
+// EmbeddedMold is one mold of a multi-mold binary.
+type EmbeddedMold struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Find returns the mold called name, or nil.
+func (ix *EmbeddedIndex) Find(name string) *EmbeddedMold {
+	for i := range ix.Molds {
+		if ix.Molds[i].Name == name {
+			return &ix.Molds[i]
+		}
+	}
+	return nil
+}
+
+// Names returns the mold names, in smelt order.
+func (ix *EmbeddedIndex) Names() []string {
+	names := make([]string, len(ix.Molds))
+	for i, m := range ix.Molds {
+		names[i] = m.Name
+	}
+	return names
+}
+
+func embeddedMoldDir(name string) string {
+	return "molds/" + name
+}
+
 // HasEmbeddedMold returns true if the current binary has a stuffed mold.
 func HasEmbeddedMold() bool {
 	execPath, err := resolveExecutable()
@@ -22,22 +72,156 @@ func HasEmbeddedMold() bool {
 	return err == nil
 }
 
+var (
+	payloadOnce sync.Once
+	payloadFS   fs.FS
+	payloadErr  error
+
+	embeddedMu   sync.Mutex
+	selectedMold string
+	openedMolds  = map[string]openedMold{}
+)
+
+type openedMold struct {
+	fsys fs.FS
+	err  error
+}
+
+// embeddedPayload unstuffs every file in the current binary, once per process.
+func embeddedPayload() (fs.FS, error) {
+	payloadOnce.Do(func() {
+		execPath, err := resolveExecutable()
+		if err != nil {
+			payloadErr = ErrNoEmbeddedMold
+			return
+		}
+		payloadFS, payloadErr = UnstuffFS(execPath)
+		if errors.Is(payloadErr, stuffbin.ErrNoID) {
+			payloadErr = ErrNoEmbeddedMold
+		} else if payloadErr != nil {
+			payloadErr = fmt.Errorf("unpacking embedded files: %w", payloadErr)
+		}
+	})
+	return payloadFS, payloadErr
+}
+
+// EmbeddedMolds returns the index of a binary smelted with several molds,
+// or nil for a single-mold binary.
+func EmbeddedMolds() (*EmbeddedIndex, error) {
+	payload, err := embeddedPayload()
+	if err != nil {
+		return nil, err
+	}
+	return readEmbeddedIndex(payload)
+}
+
+func readEmbeddedIndex(payload fs.FS) (*EmbeddedIndex, error) {
+	data, err := fs.ReadFile(payload, EmbeddedIndexFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", EmbeddedIndexFile, err)
+	}
+	var ix EmbeddedIndex
+	if err := yaml.Unmarshal(data, &ix); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", EmbeddedIndexFile, err)
+	}
+	return &ix, nil
+}
+
+// SelectEmbeddedMold chooses which mold of a multi-mold binary
+// OpenEmbeddedMold returns, overriding the index's default.
+func SelectEmbeddedMold(name string) error {
+	ix, err := EmbeddedMolds()
+	if err != nil {
+		return err
+	}
+	if ix == nil {
+		return errSingleMold
+	}
+	if ix.Find(name) == nil {
+		return fmt.Errorf("no embedded mold named %q (available: %s)", name, strings.Join(ix.Names(), ", "))
+	}
+	embeddedMu.Lock()
+	defer embeddedMu.Unlock()
+	selectedMold = name
+	return nil
+}
+
 // OpenEmbeddedMold returns an fs.FS backed by the mold files stuffed into
-// the current binary. Returns ErrNoEmbeddedMold if the binary is not stuffed.
+// the current binary: for a multi-mold binary, those of the mold chosen
+// with SelectEmbeddedMold or else the default. Returns ErrNoEmbeddedMold if
+// the binary is not stuffed, ErrNoMoldSelected if it embeds several molds
+// and none was chosen, and an *IntegrityError if the stuffed files don't
+// match the digests smelt recorded in provenance.yaml (binaries smelted
+// before provenance existed aren't checked). The check runs once per mold
+// per process.
 func OpenEmbeddedMold() (fs.FS, error) {
-	execPath, err := resolveExecutable()
+	embeddedMu.Lock()
+	defer embeddedMu.Unlock()
+	if m, ok := openedMolds[selectedMold]; ok {
+		return m.fsys, m.err
+	}
+	fsys, err := openEmbeddedMold(selectedMold)
+	openedMolds[selectedMold] = openedMold{fsys: fsys, err: err}
+	return fsys, err
+}
+
+func openEmbeddedMold(name string) (fs.FS, error) {
+	fsys, _, err := VerifyEmbeddedMold(name)
+	if err != nil && !errors.Is(err, ErrNoProvenance) {
+		if errors.Is(err, ErrNoEmbeddedMold) || errors.Is(err, ErrNoMoldSelected) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("embedded mold is corrupted: %w; rebuild the binary with ailloy smelt -o binary", err)
+	}
+	return fsys, nil
+}
+
+// VerifyEmbeddedMold unstuffs the current binary's mold and checks it with
+// VerifyFS, returning its files and provenance along with VerifyFS's error.
+// name picks a mold of a multi-mold binary; empty means the default, and
+// is required for a single-mold binary. The files are nil only when they
+// couldn't be read at all: the binary isn't stuffed (ErrNoEmbeddedMold),
+// its payload doesn't unpack, or no mold could be picked.
+func VerifyEmbeddedMold(name string) (fs.FS, *Provenance, error) {
+	payload, err := embeddedPayload()
+	if err != nil {
+		return nil, nil, err
+	}
+	fsys, err := embeddedMoldFS(payload, name)
 	if err != nil {
-		return nil, ErrNoEmbeddedMold
+		return nil, nil, err
 	}
+	p, err := VerifyFS(fsys)
+	return fsys, p, err
+}
 
-	fsys, err := UnstuffFS(execPath)
+// embeddedMoldFS returns the files of the mold called name in a stuffed
+// payload: the payload itself for a single-mold binary, or the mold's
+// molds/<name>/ directory, the default when name is empty.
+func embeddedMoldFS(payload fs.FS, name string) (fs.FS, error) {
+	ix, err := readEmbeddedIndex(payload)
 	if err != nil {
-		if errors.Is(err, stuffbin.ErrNoID) {
-			return nil, ErrNoEmbeddedMold
-		}
 		return nil, err
 	}
-	return fsys, nil
+	if ix == nil {
+		if name != "" {
+			return nil, errSingleMold
+		}
+		return payload, nil
+	}
+	if name == "" {
+		name = ix.Default
+	}
+	if name == "" {
+		return nil, ErrNoMoldSelected
+	}
+	if ix.Find(name) == nil {
+		return nil, fmt.Errorf("no embedded mold named %q (available: %s)", name, strings.Join(ix.Names(), ", "))
+	}
+	return fs.Sub(payload, embeddedMoldDir(name))
 }
 
 // resolveExecutable returns the resolved path to the current executable.
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/fs"
+	"sync"
 
 	"github.com/nimble-giant/ailloy/pkg/foundry"
 	"github.com/nimble-giant/ailloy/pkg/foundry/depgraph"
@@ -14,12 +15,15 @@ import (
 // binary's embedded dep store. Mold deps present in the embedded manifest are
 // served from the embedded FS without network access; deps absent from the
 // manifest fall through to the wrapped ProdFetcher so non-smelted or
-// partially-smelted binaries continue to work.
+// partially-smelted binaries continue to work. It is safe for concurrent
+// use.
 type EmbeddedDepFetcher struct {
 	embFS    fs.FS
 	manifest *DepManifest
 	fallback *depgraph.ProdFetcher
-	cache    map[depgraph.NodeKey]*depgraph.ProdFetchCacheEntry
+
+	mu    sync.Mutex
+	cache map[depgraph.NodeKey]*depgraph.ProdFetchCacheEntry
 }
 
 // NewEmbeddedDepFetcher opens the embedded FS from the current binary and
@@ -69,7 +73,7 @@ func (e *EmbeddedDepFetcher) Fetch(ref *foundry.Reference) (depgraph.FetchResult
 			return depgraph.FetchResult{}, err
 		}
 		if ce := e.fallback.CacheEntry(key); ce != nil {
-			e.cache[key] = ce
+			e.store(key, ce)
 		}
 		return result, nil
 	}
@@ -88,14 +92,14 @@ func (e *EmbeddedDepFetcher) Fetch(ref *foundry.Reference) (depgraph.FetchResult
 	pinnedRef := *ref
 	pinnedRef.Version = entry.Version
 
-	e.cache[key] = &depgraph.ProdFetchCacheEntry{
+	e.store(key, &depgraph.ProdFetchCacheEntry{
 		FS:       subFS,
 		Root:     "",
 		Mold:     m,
 		Resolved: foundry.ResolvedVersion{Tag: entry.Version, Commit: entry.Commit},
 		// Reference carries the pinned version for downstream provenance recording.
 		Reference: &pinnedRef,
-	}
+	})
 
 	return depgraph.FetchResult{
 		Mold:    m,
@@ -118,9 +122,21 @@ func (e *EmbeddedDepFetcher) Tags(source, subpath string) (map[string]depgraph.T
 
 // CacheEntry returns the cached fetch result for the given node key.
 func (e *EmbeddedDepFetcher) CacheEntry(key depgraph.NodeKey) *depgraph.ProdFetchCacheEntry {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.cache[key]
 }
 
+// store records a fetch result for CacheEntry.
+func (e *EmbeddedDepFetcher) store(key depgraph.NodeKey, entry *depgraph.ProdFetchCacheEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cache == nil {
+		e.cache = map[depgraph.NodeKey]*depgraph.ProdFetchCacheEntry{}
+	}
+	e.cache[key] = entry
+}
+
 // LookupEmbeddedArtifact checks whether the current binary has a mold, ore,
 // or ingot dep embedded that matches (source, subpath). Returns (fs.FS,
 // version, commit, true) when found. The returned FS is rooted at the
@@ -0,0 +1,129 @@
+package smelt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeNamedMoldFixture writes writeMoldFixture's mold renamed to name.
+func writeNamedMoldFixture(t *testing.T, name string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeMoldFixture(t, dir)
+	path := filepath.Join(dir, "mold.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "name: test-mold", "name: "+name, 1))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPackageMultiMoldBinary(t *testing.T) {
+	base := writeNamedMoldFixture(t, "base")
+	team := writeNamedMoldFixture(t, "team-web")
+
+	bin, _, err := PackageMultiMoldBinary([]string{base, team}, "team-web", t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Base(bin) != "base-1.2.3" {
+		t.Errorf("binary = %s, want it named after the first mold", filepath.Base(bin))
+	}
+	payload, err := UnstuffFS(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ix, err := readEmbeddedIndex(payload)
+	if err != nil || ix == nil {
+		t.Fatalf("index = %v, %v", ix, err)
+	}
+	if got := strings.Join(ix.Names(), ","); got != "base,team-web" || ix.Default != "team-web" {
+		t.Errorf("index = %+v", ix)
+	}
+
+	for name, want := range map[string]string{"": "team-web", "base": "base", "team-web": "team-web"} {
+		fsys, err := embeddedMoldFS(payload, name)
+		if err != nil {
+			t.Fatalf("embeddedMoldFS(%q): %v", name, err)
+		}
+		p, err := VerifyFS(fsys)
+		if err != nil {
+			t.Fatalf("VerifyFS(%q): %v", name, err)
+		}
+		if p.Mold.Name != want {
+			t.Errorf("embeddedMoldFS(%q) opened %s, want %s", name, p.Mold.Name, want)
+		}
+	}
+	if _, err := embeddedMoldFS(payload, "team-data"); err == nil || !strings.Contains(err.Error(), "available: base, team-web") {
+		t.Errorf("unknown mold: err = %v", err)
+	}
+	if _, err := ReadProvenance(bin); err == nil || !strings.Contains(err.Error(), "embeds several molds") {
+		t.Errorf("ReadProvenance: err = %v, want a pointer to embedded info", err)
+	}
+}
+
+func TestPackageMultiMoldBinary_NoDefault(t *testing.T) {
+	bin, _, err := PackageMultiMoldBinary([]string{writeNamedMoldFixture(t, "a"), writeNamedMoldFixture(t, "b")}, "", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := UnstuffFS(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := embeddedMoldFS(payload, ""); !errors.Is(err, ErrNoMoldSelected) {
+		t.Errorf("err = %v, want ErrNoMoldSelected", err)
+	}
+}
+
+func TestPackageMultiMoldBinary_Errors(t *testing.T) {
+	a, b := writeNamedMoldFixture(t, "a"), writeNamedMoldFixture(t, "b")
+	cases := []struct {
+		name        string
+		dirs        []string
+		defaultMold string
+		want        string
+	}{
+		{"duplicate names", []string{a, writeNamedMoldFixture(t, "a")}, "", `two molds are named "a"`},
+		{"unknown default", []string{a, b}, "c", `default mold "c" is not one of`},
+		{"default for one mold", []string{a}, "a", "needs more than one mold"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := PackageMultiMoldBinary(tc.dirs, tc.defaultMold, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMoldFS_SingleMold(t *testing.T) {
+	moldDir := t.TempDir()
+	writeMoldFixture(t, moldDir)
+	bin, _, err := PackageBinary(moldDir, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := UnstuffFS(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ix, err := readEmbeddedIndex(payload); ix != nil || err != nil {
+		t.Errorf("index = %v, %v; want none for a single mold", ix, err)
+	}
+	if fsys, err := embeddedMoldFS(payload, ""); err != nil || fsys != payload {
+		t.Errorf("embeddedMoldFS = %v, %v; want the payload itself", fsys, err)
+	}
+	if _, err := embeddedMoldFS(payload, "test-mold"); !errors.Is(err, errSingleMold) {
+		t.Errorf("err = %v, want errSingleMold", err)
+	}
+}
@@ -0,0 +1,269 @@
+package smelt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// homebrewArch and scoopArch map the GOARCH of a platform binary to the
+// architecture blocks of a Homebrew formula and a Scoop manifest. Targets
+// they don't list are left out of that format.
+var (
+	homebrewArch = map[string]string{"amd64": "on_intel", "arm64": "on_arm"}
+	scoopArch    = map[string]string{"amd64": "64bit", "386": "32bit", "arm64": "arm64"}
+)
+
+// WritePackageMeta writes distribution packaging for the platform binaries
+// of the mold in moldDir into outputDir: a Homebrew formula ({name}.rb) for
+// the darwin and linux binaries, a Scoop manifest ({name}.json) for the
+// windows ones, and an nfpm config ({name}-{version}-nfpm-linux-{arch}.yaml)
+// per linux binary for building .deb and .rpm packages. downloadURL is where
+// the binaries will be published; each URL is it joined with the binary's
+// file name. Every format installs the binary as the mold's name. It returns
+// the paths written, in that order; formats with no matching binaries are
+// skipped.
+func WritePackageMeta(moldDir, outputDir, downloadURL string, bins []PlatformBinary) ([]string, error) {
+	if downloadURL == "" {
+		return nil, fmt.Errorf("a download URL is required for package metadata")
+	}
+	m, err := mold.LoadMold(filepath.Join(moldDir, "mold.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("loading mold: %w", err)
+	}
+	if outputDir == "" {
+		outputDir = "."
+	}
+	base := strings.TrimSuffix(downloadURL, "/") + "/"
+
+	var written []string
+	write := func(name string, data []byte) error {
+		path := filepath.Join(outputDir, name)
+		//#nosec G306 -- packaging metadata is published alongside the binaries
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		written = append(written, path)
+		return nil
+	}
+
+	if formula := homebrewFormula(m, base, bins); formula != "" {
+		if err := write(m.Name+".rb", []byte(formula)); err != nil {
+			return nil, err
+		}
+	}
+	manifest, err := scoopManifest(m, base, bins)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		if err := write(m.Name+".json", manifest); err != nil {
+			return nil, err
+		}
+	}
+	for _, b := range bins {
+		if b.Platform.OS != "linux" {
+			continue
+		}
+		cfg, err := nfpmConfig(m, b)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(fmt.Sprintf("%s-%s-nfpm-linux-%s.yaml", m.Name, m.Version, b.Platform.Arch), cfg); err != nil {
+			return nil, err
+		}
+	}
+	return written, nil
+}
+
+// packageHomepage is the homepage packaging metadata names: the mold
+// author's URL, else the download location.
+func packageHomepage(m *mold.Mold, base string) string {
+	if m.Author.URL != "" {
+		return m.Author.URL
+	}
+	return base
+}
+
+// packageDescription is the mold's description, or a generic one naming it.
+func packageDescription(m *mold.Mold) string {
+	if m.Description != "" {
+		return m.Description
+	}
+	return "Installer for the " + m.Name + " ailloy mold"
+}
+
+// homebrewFormula renders a formula with one url/sha256 pair per supported
+// darwin and linux binary, or "" when there are none.
+func homebrewFormula(m *mold.Mold, base string, bins []PlatformBinary) string {
+	var blocks strings.Builder
+	for _, goos := range []string{"darwin", "linux"} {
+		var arches strings.Builder
+		for _, b := range bins {
+			block, ok := homebrewArch[b.Platform.Arch]
+			if b.Platform.OS != goos || !ok {
+				continue
+			}
+			fmt.Fprintf(&arches, "    %s do\n      url %s\n      sha256 %s\n    end\n",
+				block, rubyString(base+filepath.Base(b.Path)), rubyString(b.SHA256))
+		}
+		if arches.Len() == 0 {
+			continue
+		}
+		onOS := "on_linux"
+		if goos == "darwin" {
+			onOS = "on_macos"
+		}
+		fmt.Fprintf(&blocks, "\n  %s do\n%s  end\n", onOS, arches.String())
+	}
+	if blocks.Len() == 0 {
+		return ""
+	}
+
+	var f strings.Builder
+	fmt.Fprintf(&f, "class %s < Formula\n", formulaClass(m.Name))
+	fmt.Fprintf(&f, "  desc %s\n", rubyString(packageDescription(m)))
+	fmt.Fprintf(&f, "  homepage %s\n", rubyString(packageHomepage(m, base)))
+	fmt.Fprintf(&f, "  version %s\n", rubyString(m.Version))
+	if m.License != "" {
+		fmt.Fprintf(&f, "  license %s\n", rubyString(m.License))
+	}
+	f.WriteString(blocks.String())
+	fmt.Fprintf(&f, "\n  def install\n    bin.install Dir[%s].first => %s\n  end\n",
+		rubyString(m.Name+"-"+m.Version+"-*"), rubyString(m.Name))
+	fmt.Fprintf(&f, "\n  test do\n    system bin/%s, \"embedded\", \"info\"\n  end\nend\n", rubyString(m.Name))
+	return f.String()
+}
+
+// formulaClass turns a formula name into the Ruby class Homebrew expects:
+// "my-team-mold" becomes "MyTeamMold".
+func formulaClass(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// rubyString quotes s as a Ruby double-quoted string literal.
+func rubyString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "#{", `\#{`)
+	return `"` + s + `"`
+}
+
+type scoopResource struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash"`
+}
+
+type scoopFile struct {
+	Version      string                   `json:"version"`
+	Description  string                   `json:"description"`
+	Homepage     string                   `json:"homepage"`
+	License      string                   `json:"license,omitempty"`
+	Architecture map[string]scoopResource `json:"architecture"`
+	Bin          string                   `json:"bin"`
+}
+
+// scoopManifest renders a manifest covering the supported windows
+// binaries, or nil when there are none. The "#/<name>.exe" URL suffix makes
+// Scoop save each download under the same name, so bin is the same on every
+// architecture.
+func scoopManifest(m *mold.Mold, base string, bins []PlatformBinary) ([]byte, error) {
+	exe := m.Name + ".exe"
+	arch := map[string]scoopResource{}
+	for _, b := range bins {
+		key, ok := scoopArch[b.Platform.Arch]
+		if b.Platform.OS != "windows" || !ok {
+			continue
+		}
+		arch[key] = scoopResource{URL: base + filepath.Base(b.Path) + "#/" + exe, Hash: b.SHA256}
+	}
+	if len(arch) == 0 {
+		return nil, nil
+	}
+	data, err := json.MarshalIndent(scoopFile{
+		Version:      m.Version,
+		Description:  packageDescription(m),
+		Homepage:     packageHomepage(m, base),
+		License:      m.License,
+		Architecture: arch,
+		Bin:          exe,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding Scoop manifest: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// fileMode marshals as an octal YAML literal, the way nfpm configs are
+// written by hand.
+type fileMode os.FileMode
+
+func (m fileMode) MarshalYAML() ([]byte, error) {
+	return fmt.Appendf(nil, "0%o", uint32(m)), nil
+}
+
+type nfpmContent struct {
+	Src      string `yaml:"src"`
+	Dst      string `yaml:"dst"`
+	FileInfo struct {
+		Mode fileMode `yaml:"mode"`
+	} `yaml:"file_info"`
+}
+
+type nfpmFile struct {
+	Name        string        `yaml:"name"`
+	Arch        string        `yaml:"arch"`
+	Platform    string        `yaml:"platform"`
+	Version     string        `yaml:"version"`
+	Maintainer  string        `yaml:"maintainer"`
+	Description string        `yaml:"description"`
+	Homepage    string        `yaml:"homepage,omitempty"`
+	License     string        `yaml:"license,omitempty"`
+	Contents    []nfpmContent `yaml:"contents"`
+}
+
+// nfpmConfig renders the nfpm config packaging one linux binary as
+// /usr/bin/<name>. src is the binary's file name, so nfpm is run from the
+// output directory.
+func nfpmConfig(m *mold.Mold, b PlatformBinary) ([]byte, error) {
+	maintainer := m.Author.Name
+	if maintainer == "" {
+		maintainer = m.Name
+	}
+	content := nfpmContent{Src: filepath.Base(b.Path), Dst: "/usr/bin/" + m.Name}
+	content.FileInfo.Mode = 0o755
+	data, err := yaml.Marshal(nfpmFile{
+		Name:        m.Name,
+		Arch:        b.Platform.Arch,
+		Platform:    "linux",
+		Version:     m.Version,
+		Maintainer:  maintainer,
+		Description: packageDescription(m),
+		Homepage:    m.Author.URL,
+		License:     m.License,
+		Contents:    []nfpmContent{content},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding nfpm config for %s: %w", b.Platform, err)
+	}
+	return data, nil
+}
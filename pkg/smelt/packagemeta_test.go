@@ -0,0 +1,120 @@
+package smelt
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestWritePackageMeta(t *testing.T) {
+	moldDir := t.TempDir()
+	writeMoldFixture(t, moldDir)
+	outputDir := t.TempDir()
+	bins := []PlatformBinary{
+		{Platform: Platform{"linux", "amd64"}, Path: filepath.Join(outputDir, "test-mold-1.2.3-linux-amd64"), SHA256: "aaa"},
+		{Platform: Platform{"darwin", "arm64"}, Path: filepath.Join(outputDir, "test-mold-1.2.3-darwin-arm64"), SHA256: "bbb"},
+		{Platform: Platform{"windows", "amd64"}, Path: filepath.Join(outputDir, "test-mold-1.2.3-windows-amd64.exe"), SHA256: "ccc"},
+		{Platform: Platform{"freebsd", "amd64"}, Path: filepath.Join(outputDir, "test-mold-1.2.3-freebsd-amd64"), SHA256: "ddd"},
+	}
+
+	written, err := WritePackageMeta(moldDir, outputDir, "https://example.com/releases/v1.2.3/", bins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range written {
+		names = append(names, filepath.Base(p))
+	}
+	if got := strings.Join(names, " "); got != "test-mold.rb test-mold.json test-mold-1.2.3-nfpm-linux-amd64.yaml" {
+		t.Fatalf("written = %s", got)
+	}
+
+	formula := readString(t, filepath.Join(outputDir, "test-mold.rb"))
+	for _, want := range []string{
+		"class TestMold < Formula",
+		`desc "A test mold for packaging"`,
+		"  on_macos do\n    on_arm do\n      url \"https://example.com/releases/v1.2.3/test-mold-1.2.3-darwin-arm64\"\n      sha256 \"bbb\"",
+		"  on_linux do\n    on_intel do\n      url \"https://example.com/releases/v1.2.3/test-mold-1.2.3-linux-amd64\"",
+		`bin.install Dir["test-mold-1.2.3-*"].first => "test-mold"`,
+	} {
+		if !strings.Contains(formula, want) {
+			t.Errorf("formula missing %q:\n%s", want, formula)
+		}
+	}
+	if strings.Contains(formula, "freebsd") {
+		t.Errorf("formula should skip platforms Homebrew doesn't run on:\n%s", formula)
+	}
+
+	var scoop scoopFile
+	if err := json.Unmarshal([]byte(readString(t, filepath.Join(outputDir, "test-mold.json"))), &scoop); err != nil {
+		t.Fatal(err)
+	}
+	if scoop.Version != "1.2.3" || scoop.Bin != "test-mold.exe" || len(scoop.Architecture) != 1 ||
+		scoop.Architecture["64bit"].URL != "https://example.com/releases/v1.2.3/test-mold-1.2.3-windows-amd64.exe#/test-mold.exe" ||
+		scoop.Architecture["64bit"].Hash != "ccc" {
+		t.Errorf("scoop manifest = %+v", scoop)
+	}
+
+	raw := readString(t, filepath.Join(outputDir, "test-mold-1.2.3-nfpm-linux-amd64.yaml"))
+	if !strings.Contains(raw, "mode: 0755") {
+		t.Errorf("nfpm mode should be octal:\n%s", raw)
+	}
+	var nfpm struct {
+		Name     string `yaml:"name"`
+		Arch     string `yaml:"arch"`
+		Version  string `yaml:"version"`
+		Contents []struct {
+			Src string `yaml:"src"`
+			Dst string `yaml:"dst"`
+		} `yaml:"contents"`
+	}
+	if err := yaml.Unmarshal([]byte(raw), &nfpm); err != nil {
+		t.Fatal(err)
+	}
+	if nfpm.Name != "test-mold" || nfpm.Arch != "amd64" || nfpm.Version != "1.2.3" || len(nfpm.Contents) != 1 ||
+		nfpm.Contents[0].Src != "test-mold-1.2.3-linux-amd64" || nfpm.Contents[0].Dst != "/usr/bin/test-mold" {
+		t.Errorf("nfpm config = %+v", nfpm)
+	}
+}
+
+func TestWritePackageMeta_SkipsFormatsWithoutTargets(t *testing.T) {
+	moldDir := t.TempDir()
+	writeMoldFixture(t, moldDir)
+	outputDir := t.TempDir()
+	bins := []PlatformBinary{{Platform: Platform{"windows", "arm64"}, Path: "test-mold-1.2.3-windows-arm64.exe", SHA256: "eee"}}
+
+	written, err := WritePackageMeta(moldDir, outputDir, "https://example.com/dl", bins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1 || filepath.Base(written[0]) != "test-mold.json" {
+		t.Errorf("written = %v, want only the Scoop manifest", written)
+	}
+	if _, err := WritePackageMeta(moldDir, outputDir, "", bins); err == nil {
+		t.Error("expected an error without a download URL")
+	}
+}
+
+func TestFormulaClassAndRubyString(t *testing.T) {
+	for in, want := range map[string]string{"my-team-mold": "MyTeamMold", "ai_tools2": "AiTools2", "x.y": "XY"} {
+		if got := formulaClass(in); got != want {
+			t.Errorf("formulaClass(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := rubyString(`say "hi" #{x} \n`); got != `"say \"hi\" \#{x} \\n"` {
+		t.Errorf("rubyString = %s", got)
+	}
+}
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
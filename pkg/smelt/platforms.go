@@ -0,0 +1,143 @@
+package smelt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Platform is a GOOS/GOARCH pair a smelted binary runs on.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+func (p Platform) String() string { return p.OS + "/" + p.Arch }
+
+// Exe returns the executable suffix binaries for p carry.
+func (p Platform) Exe() string {
+	if p.OS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+// ParsePlatforms parses a comma-separated list of os/arch targets such as
+// "linux/amd64,darwin/arm64,windows/amd64". Duplicates are dropped; order is
+// kept.
+func ParsePlatforms(list string) ([]Platform, error) {
+	var out []Platform
+	for item := range strings.SplitSeq(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(item, "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("invalid platform %q: want os/arch, e.g. linux/amd64", item)
+		}
+		p := Platform{OS: strings.ToLower(goos), Arch: strings.ToLower(goarch)}
+		if !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no platforms given")
+	}
+	return out, nil
+}
+
+// BaseBinaryFunc returns the path of a plain (unstuffed) ailloy executable
+// built for p. PackageBinaries stuffs the mold into a copy of it.
+type BaseBinaryFunc func(p Platform) (string, error)
+
+// PlatformBinary is one binary written by PackageBinaries.
+type PlatformBinary struct {
+	Platform Platform
+	Path     string
+	Size     int64
+	SHA256   string
+}
+
+// PackageBinaries packages a mold into one self-contained binary per
+// platform, each a copy of the ailloy binary base returns for that platform
+// with the same mold files stuffed in. Binaries are named
+// {name}-{version}-{os}-{arch} (".exe" on Windows). A sha256sum-style
+// {name}-{version}-checksums.txt covering every binary is written alongside;
+// its path is returned with the binaries, in platform order.
+func PackageBinaries(moldDir, outputDir string, platforms []Platform, base BaseBinaryFunc) ([]PlatformBinary, string, error) {
+	return PackageMultiMoldBinaries([]string{moldDir}, "", outputDir, platforms, base)
+}
+
+// PackageMultiMoldBinaries is PackageBinaries for the molds of
+// PackageMultiMoldBinary: every platform's binary embeds all of them.
+func PackageMultiMoldBinaries(moldDirs []string, defaultMold, outputDir string, platforms []Platform, base BaseBinaryFunc) ([]PlatformBinary, string, error) {
+	if len(platforms) == 0 {
+		return nil, "", fmt.Errorf("no platforms given")
+	}
+	prefix, stuffPaths, cleanup, err := stageBinary(moldDirs, defaultMold)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cleanup()
+
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	bins := make([]PlatformBinary, len(platforms))
+	var g errgroup.Group
+	for i, p := range platforms {
+		g.Go(func() error {
+			basePath, err := base(p)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			outputPath := filepath.Join(outputDir, fmt.Sprintf("%s-%s-%s%s", prefix, p.OS, p.Arch, p.Exe()))
+			size, err := stuffBinary(basePath, outputPath, stuffPaths)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			sum, err := fileSHA256(outputPath)
+			if err != nil {
+				return fmt.Errorf("%s: hashing binary: %w", p, err)
+			}
+			bins[i] = PlatformBinary{Platform: p, Path: outputPath, Size: size, SHA256: sum}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, "", err
+	}
+
+	var sums strings.Builder
+	for _, b := range bins {
+		fmt.Fprintf(&sums, "%s  %s\n", b.SHA256, filepath.Base(b.Path))
+	}
+	checksumsPath := filepath.Join(outputDir, prefix+"-checksums.txt")
+	//#nosec G306 -- checksums are published alongside the binaries
+	if err := os.WriteFile(checksumsPath, []byte(sums.String()), 0644); err != nil {
+		return nil, "", fmt.Errorf("writing checksums: %w", err)
+	}
+	return bins, checksumsPath, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is a binary this package just wrote
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,92 @@
+package smelt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/knadh/stuffbin"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	got, err := ParsePlatforms(" linux/amd64,darwin/arm64,,Windows/AMD64,linux/amd64")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Platform{{"linux", "amd64"}, {"darwin", "arm64"}, {"windows", "amd64"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"", "linux", "linux/", "/amd64", "linux/amd64/v2"} {
+		if _, err := ParsePlatforms(bad); err == nil {
+			t.Errorf("ParsePlatforms(%q): expected error", bad)
+		}
+	}
+}
+
+func TestPackageBinaries(t *testing.T) {
+	moldDir := t.TempDir()
+	writeMoldFixture(t, moldDir)
+	baseDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	base := func(p Platform) (string, error) {
+		path := filepath.Join(baseDir, "ailloy-"+p.OS+"-"+p.Arch+p.Exe())
+		return path, os.WriteFile(path, []byte("base binary for "+p.String()), 0755)
+	}
+	platforms := []Platform{{"linux", "amd64"}, {"darwin", "arm64"}, {"windows", "amd64"}}
+
+	bins, checksums, err := PackageBinaries(moldDir, outputDir, platforms, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantNames := []string{"test-mold-1.2.3-linux-amd64", "test-mold-1.2.3-darwin-arm64", "test-mold-1.2.3-windows-amd64.exe"}
+	if len(bins) != len(wantNames) {
+		t.Fatalf("got %d binaries, want %d", len(bins), len(wantNames))
+	}
+	for i, b := range bins {
+		if filepath.Base(b.Path) != wantNames[i] || b.Platform != platforms[i] {
+			t.Errorf("binary %d = %s (%s), want %s", i, filepath.Base(b.Path), b.Platform, wantNames[i])
+		}
+		if _, err := stuffbin.GetFileID(b.Path); err != nil {
+			t.Errorf("%s: expected valid stuffbin ID, got error: %v", b.Path, err)
+		}
+		sum, err := fileSHA256(b.Path)
+		if err != nil || sum != b.SHA256 {
+			t.Errorf("%s: SHA256 = %s, file hashes to %s (%v)", b.Path, b.SHA256, sum, err)
+		}
+	}
+
+	if filepath.Base(checksums) != "test-mold-1.2.3-checksums.txt" {
+		t.Errorf("checksums file = %s", checksums)
+	}
+	data, err := os.ReadFile(checksums) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(bins) {
+		t.Fatalf("checksums has %d lines, want %d:\n%s", len(lines), len(bins), data)
+	}
+	for i, b := range bins {
+		if want := b.SHA256 + "  " + wantNames[i]; lines[i] != want {
+			t.Errorf("checksums line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestPackageBinaries_BaseError(t *testing.T) {
+	moldDir := t.TempDir()
+	writeMoldFixture(t, moldDir)
+
+	base := func(p Platform) (string, error) { return "", errors.New("no release binary") }
+	_, _, err := PackageBinaries(moldDir, t.TempDir(), []Platform{{"linux", "riscv64"}}, base)
+	if err == nil || !strings.Contains(err.Error(), "linux/riscv64: no release binary") {
+		t.Errorf("err = %v, want the platform named", err)
+	}
+}
@@ -0,0 +1,211 @@
+package smelt
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/knadh/stuffbin"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+// ProvenanceFile is the name of the provenance record smelt embeds at the
+// root of every tarball and binary.
+const ProvenanceFile = "provenance.yaml"
+
+// Provenance records where a smelted artifact came from and exactly what it
+// contains.
+type Provenance struct {
+	Mold    ProvenanceMold    `yaml:"mold"`
+	Source  *ProvenanceSource `yaml:"source,omitempty"`
+	Builder ProvenanceBuilder `yaml:"builder"`
+	// BuiltAt is $SOURCE_DATE_EPOCH when set, else the source commit time.
+	// It is never the wall clock, so rebuilding the same commit reproduces
+	// the same record.
+	BuiltAt string       `yaml:"builtAt,omitempty"`
+	Files   []FileDigest `yaml:"files"`
+}
+
+// ProvenanceMold identifies the packaged mold.
+type ProvenanceMold struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// ProvenanceSource describes the git checkout the mold was smelted from.
+// Absent when the mold directory is not in a git work tree.
+type ProvenanceSource struct {
+	Repository  string `yaml:"repository,omitempty"`
+	Commit      string `yaml:"commit"`
+	CommittedAt string `yaml:"committedAt,omitempty"`
+	// Dirty reports uncommitted changes under the mold directory.
+	Dirty bool `yaml:"dirty"`
+}
+
+// ProvenanceBuilder names the tool that produced the artifact.
+type ProvenanceBuilder struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+}
+
+// FileDigest is the digest of one file in the artifact.
+type FileDigest struct {
+	Path   string `yaml:"path"`
+	Size   int    `yaml:"size"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// builderVersion is the ailloy version recorded in provenance.
+var builderVersion = "dev"
+
+// SetBuilderVersion sets the ailloy version smelt records as the builder in
+// provenance.yaml.
+func SetBuilderVersion(v string) {
+	if v = strings.TrimSpace(v); v != "" {
+		builderVersion = v
+	}
+}
+
+// provenanceGit runs the git commands that describe the mold's checkout.
+var provenanceGit = foundry.DefaultGitRunner()
+
+// buildProvenance describes the artifact made of files, smelted from the
+// mold in moldDir, and returns it as an archiveFile to embed alongside them.
+func buildProvenance(m *mold.Mold, moldDir string, files []archiveFile) (archiveFile, error) {
+	p := Provenance{
+		Mold:    ProvenanceMold{Name: m.Name, Version: m.Version},
+		Source:  gitSource(moldDir),
+		Builder: ProvenanceBuilder{Name: "ailloy", Version: builderVersion},
+	}
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		secs, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return archiveFile{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+		}
+		p.BuiltAt = time.Unix(secs, 0).UTC().Format(time.RFC3339)
+	} else if p.Source != nil {
+		p.BuiltAt = p.Source.CommittedAt
+	}
+
+	for _, f := range files {
+		sum := sha256.Sum256(f.data)
+		p.Files = append(p.Files, FileDigest{Path: f.path, Size: len(f.data), SHA256: hex.EncodeToString(sum[:])})
+	}
+	slices.SortFunc(p.Files, func(a, b FileDigest) int { return strings.Compare(a.Path, b.Path) })
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return archiveFile{}, fmt.Errorf("marshaling provenance: %w", err)
+	}
+	return archiveFile{path: ProvenanceFile, data: data}, nil
+}
+
+// gitSource returns the checkout moldDir belongs to, or nil when it is not in
+// a git work tree.
+func gitSource(moldDir string) *ProvenanceSource {
+	git := func(args ...string) (string, error) {
+		out, err := provenanceGit(append([]string{"-C", moldDir}, args...)...)
+		return strings.TrimSpace(string(out)), err
+	}
+	commit, err := git("rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return nil
+	}
+	src := &ProvenanceSource{Commit: commit}
+	if t, err := git("log", "-1", "--format=%ct", "HEAD"); err == nil {
+		if secs, err := strconv.ParseInt(t, 10, 64); err == nil {
+			src.CommittedAt = time.Unix(secs, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	if status, err := git("status", "--porcelain", "--", "."); err == nil {
+		src.Dirty = status != ""
+	}
+	if remote, err := git("config", "--get", "remote.origin.url"); err == nil {
+		src.Repository = redactRemote(remote)
+	}
+	return src
+}
+
+// redactRemote drops any credentials embedded in a remote URL.
+func redactRemote(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil || u.Scheme == "" {
+		return remote
+	}
+	if u.Scheme == "ssh" {
+		u.User = url.User(u.User.Username())
+	} else {
+		u.User = nil
+	}
+	return u.String()
+}
+
+// ReadProvenance reads the provenance record embedded in a smelted artifact:
+// a .tar.gz from `smelt -o tar` or a binary from `smelt -o binary`.
+func ReadProvenance(artifact string) (*Provenance, error) {
+	data, err := readArtifactFile(artifact, ProvenanceFile)
+	if err != nil {
+		return nil, err
+	}
+	var p Provenance
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ProvenanceFile, err)
+	}
+	return &p, nil
+}
+
+// readArtifactFile returns the root-level file name from a smelted tarball
+// (under its {name}-{version}/ prefix) or stuffed binary.
+func readArtifactFile(artifact, name string) ([]byte, error) {
+	if _, err := stuffbin.GetFileID(artifact); err == nil {
+		fsys, err := UnstuffFS(artifact)
+		if err != nil {
+			return nil, fmt.Errorf("reading embedded files: %w", err)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			if ix, _ := readEmbeddedIndex(fsys); ix != nil {
+				return nil, fmt.Errorf("%s embeds several molds (%s); run %s embedded info to see each one", artifact, strings.Join(ix.Names(), ", "), artifact)
+			}
+			return nil, fmt.Errorf("%s has no %s (smelted by an older ailloy?)", artifact, name)
+		}
+		return data, nil
+	}
+
+	f, err := os.Open(artifact) // #nosec G304 -- artifact path supplied by the user
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a smelted tarball nor a smelted binary", artifact)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s has no %s (smelted by an older ailloy?)", artifact, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", artifact, err)
+		}
+		if _, rest, ok := strings.Cut(hdr.Name, "/"); ok && path.Clean(rest) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package smelt
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/nimble-giant/ailloy/pkg/foundry"
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+	"github.com/nimble-giant/ailloy/pkg/mold"
+	"github.com/nimble-giant/ailloy/pkg/safepath"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
+)
+
+// Release providers Push can publish to.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// ReleaseRunner runs a release CLI (gh or glab) and returns its combined
+// output. It is injectable for testing.
+type ReleaseRunner func(tool string, args ...string) ([]byte, error)
+
+// DefaultReleaseRunner shells out to the named CLI.
+func DefaultReleaseRunner(tool string, args ...string) ([]byte, error) {
+	return exec.Command(tool, args...).CombinedOutput() // #nosec G204 -- tool is gh or glab; args are built by Push
+}
+
+// PushOptions configures Push.
+type PushOptions struct {
+	// Remote is the git remote to tag and release against (default "origin").
+	Remote string
+	// OutputDir keeps the tarball and checksums file. Empty uses a scratch
+	// directory removed once they are uploaded.
+	OutputDir string
+	// Provider is ProviderGitHub or ProviderGitLab; empty detects it from
+	// the remote's host.
+	Provider string
+	// IndexPath, when set, is a foundry.yaml whose entry for this mold is
+	// added or updated.
+	IndexPath string
+	// NoRelease tags and pushes without creating a release.
+	NoRelease bool
+	// AllowDirty publishes even with uncommitted changes under the mold.
+	AllowDirty bool
+	// DryRun runs every check and reports the plan without smelting,
+	// tagging, releasing, or writing the index.
+	DryRun bool
+
+	Git     foundry.GitRunner // default foundry.DefaultGitRunner()
+	Release ReleaseRunner     // default DefaultReleaseRunner
+	// OnStep is called before each step with a short description.
+	OnStep func(msg string)
+}
+
+// PushResult describes a publish, or the plan for one under DryRun.
+type PushResult struct {
+	Mold     *mold.Mold
+	Source   string // foundry reference for the mold: host/owner/repo[//subpath]
+	Tag      string
+	Remote   string
+	Provider string // empty with NoRelease
+	Tarball  string
+	Checksum string // path of the checksums file
+	Index    string // foundry.yaml that was (or would be) updated
+}
+
+// Push publishes the mold in moldDir: it tags the repository with the
+// version from mold.yaml (`v<version>`, or `<dir>-v<version>` for a mold in
+// a subdirectory, matching how foundry resolves monorepo tags), pushes the
+// tag, smelts the tarball and a checksums file, uploads both to a new
+// GitHub or GitLab release, and optionally records the mold in a foundry
+// index. The mold must be committed and the tag must not exist yet.
+func Push(moldDir string, opts PushOptions) (*PushResult, error) {
+	if opts.Remote == "" {
+		opts.Remote = "origin"
+	}
+	if opts.Git == nil {
+		opts.Git = foundry.DefaultGitRunner()
+	}
+	if opts.Release == nil {
+		opts.Release = DefaultReleaseRunner
+	}
+	step := func(format string, args ...any) {
+		if opts.OnStep != nil {
+			opts.OnStep(fmt.Sprintf(format, args...))
+		}
+	}
+
+	res, root, err := planPush(moldDir, &opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return res, nil
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		scratch, err := tmpdir.MkdirTemp("smelt-push-*")
+		if err != nil {
+			return nil, fmt.Errorf("creating scratch directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(scratch) }()
+		outputDir = scratch
+	}
+
+	step("Smelting %s@%s", res.Mold.Name, res.Mold.Version)
+	res.Tarball, _, err = PackageTarball(moldDir, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := fileSHA256(res.Tarball)
+	if err != nil {
+		return nil, fmt.Errorf("hashing tarball: %w", err)
+	}
+	res.Checksum = filepath.Join(outputDir, fmt.Sprintf("%s-%s-checksums.txt", res.Mold.Name, res.Mold.Version))
+	//#nosec G306 -- checksums are published alongside the tarball
+	if err := os.WriteFile(res.Checksum, []byte(sum+"  "+filepath.Base(res.Tarball)+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("writing checksums: %w", err)
+	}
+
+	step("Tagging %s and pushing it to %s", res.Tag, res.Remote)
+	if out, err := opts.Git("-C", root, "tag", "-a", res.Tag, "-m", res.Mold.Name+" "+res.Mold.Version); err != nil {
+		return nil, fmt.Errorf("git tag %s: %w\n%s", res.Tag, err, out)
+	}
+	if out, err := opts.Git("-C", root, "push", res.Remote, "refs/tags/"+res.Tag); err != nil {
+		_, _ = opts.Git("-C", root, "tag", "-d", res.Tag)
+		return nil, fmt.Errorf("git push %s %s: %w\n%s", res.Remote, res.Tag, err, out)
+	}
+
+	if res.Provider != "" {
+		step("Creating %s release %s", res.Provider, res.Tag)
+		tool, args := releaseCommand(res)
+		if out, err := opts.Release(tool, args...); err != nil {
+			return nil, fmt.Errorf("%s release create: %w\n%s\ntag %s is pushed; create the release by hand or delete the tag and retry",
+				tool, err, strings.TrimSpace(string(out)), res.Tag)
+		}
+	}
+
+	if res.Index != "" {
+		step("Updating %s", res.Index)
+		if err := updateIndex(res.Index, res.Mold, res.Source); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// planPush runs every check Push needs before changing anything and fills in
+// what it will publish. It returns the repository root as well.
+func planPush(moldDir string, opts *PushOptions) (*PushResult, string, error) {
+	cleanDir, err := safepath.Clean(moldDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid mold directory: %w", err)
+	}
+	m, err := mold.LoadMold(filepath.Join(cleanDir, "mold.yaml"))
+	if err != nil {
+		return nil, "", fmt.Errorf("loading mold: %w", err)
+	}
+	if err := mold.ValidateMold(m); err != nil {
+		return nil, "", fmt.Errorf("validating mold: %w", err)
+	}
+
+	git := func(args ...string) (string, error) {
+		out, err := opts.Git(append([]string{"-C", cleanDir}, args...)...)
+		return strings.TrimSpace(string(out)), err
+	}
+	root, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, "", fmt.Errorf("%s is not in a git repository", moldDir)
+	}
+	subpath, err := repoSubpath(root, cleanDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The configured URL, not `remote get-url`, which applies insteadOf
+	// rewrites (such as foundry mirrors) the index must not record.
+	remoteURL, err := git("config", "--get", "remote."+opts.Remote+".url")
+	if err != nil || remoteURL == "" {
+		return nil, "", fmt.Errorf("no git remote %q", opts.Remote)
+	}
+	ref, err := foundry.ParseReference(remoteReference(remoteURL))
+	if err != nil {
+		return nil, "", fmt.Errorf("remote %s: %w", opts.Remote, err)
+	}
+	ref.Subpath = subpath
+
+	res := &PushResult{
+		Mold:   m,
+		Source: ref.CacheKey(),
+		Tag:    "v" + strings.TrimPrefix(m.Version, "v"),
+		Remote: opts.Remote,
+		Index:  opts.IndexPath,
+	}
+	if subpath != "" {
+		res.Source = ref.Host + "/" + ref.Owner + "/" + ref.Repo + "//" + subpath
+	}
+	if prefix := ref.ReleasePrefix(); prefix != "" {
+		res.Tag = prefix + "-" + res.Tag
+	}
+
+	if !opts.AllowDirty {
+		if status, err := git("status", "--porcelain", "--", "."); err == nil && status != "" {
+			return nil, "", fmt.Errorf("%s has uncommitted changes; commit them or pass --allow-dirty", moldDir)
+		}
+	}
+	if _, err := git("rev-parse", "-q", "--verify", "refs/tags/"+res.Tag); err == nil {
+		return nil, "", fmt.Errorf("tag %s already exists; bump version in mold.yaml", res.Tag)
+	}
+	if out, err := git("ls-remote", "--tags", opts.Remote, "refs/tags/"+res.Tag); err != nil {
+		return nil, "", fmt.Errorf("checking %s for tag %s: %w\n%s", opts.Remote, res.Tag, err, out)
+	} else if strings.Contains(out, "refs/tags/") {
+		return nil, "", fmt.Errorf("tag %s already exists on %s; bump version in mold.yaml", res.Tag, opts.Remote)
+	}
+
+	if !opts.NoRelease {
+		res.Provider = opts.Provider
+		if res.Provider == "" {
+			res.Provider = detectProvider(ref.Host)
+		}
+		switch res.Provider {
+		case ProviderGitHub, ProviderGitLab:
+		case "":
+			return nil, "", fmt.Errorf("can't tell whether %s is GitHub or GitLab; pass --provider github|gitlab, or --no-release", ref.Host)
+		default:
+			return nil, "", fmt.Errorf("unknown provider %q (supported: github, gitlab)", res.Provider)
+		}
+	}
+
+	if res.Index != "" {
+		if _, err := loadIndex(res.Index); err != nil {
+			return nil, "", err
+		}
+	}
+	return res, root, nil
+}
+
+// remoteReference turns a git remote URL into the host/owner/repo form
+// foundry.ParseReference accepts, dropping credentials and ssh:// users.
+func remoteReference(remoteURL string) string {
+	s := redactRemote(remoteURL)
+	if after, ok := strings.CutPrefix(s, "ssh://"); ok {
+		s = after
+		if _, host, ok := strings.Cut(s, "@"); ok {
+			s = host
+		}
+	}
+	return s
+}
+
+// repoSubpath returns dir relative to the repository root, in slash form,
+// or "" when dir is the root.
+func repoSubpath(root, dir string) (string, error) {
+	if r, err := filepath.EvalSymlinks(root); err == nil {
+		root = r
+	}
+	if d, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = d
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside repository %s", dir, root)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// detectProvider guesses the release provider from a git host.
+func detectProvider(host string) string {
+	switch {
+	case strings.Contains(host, "github"):
+		return ProviderGitHub
+	case strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	}
+	return ""
+}
+
+// releaseCommand returns the CLI and arguments that create res's release
+// with the tarball and checksums attached.
+func releaseCommand(res *PushResult) (string, []string) {
+	repo, _, _ := strings.Cut(res.Source, "//")
+	title := res.Mold.Name + " " + res.Mold.Version
+	notes := res.Mold.Description
+	if notes == "" {
+		notes = title
+	}
+	if res.Provider == ProviderGitLab {
+		return "glab", []string{"release", "create", res.Tag, res.Tarball, res.Checksum,
+			"--repo", "https://" + repo, "--name", title, "--notes", notes}
+	}
+	return "gh", []string{"release", "create", res.Tag, res.Tarball, res.Checksum,
+		"--repo", repo, "--title", title, "--notes", notes}
+}
+
+func loadIndex(path string) (*index.Index, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- index path supplied by the user
+	if err != nil {
+		return nil, fmt.Errorf("reading foundry index: %w", err)
+	}
+	idx, err := index.ParseIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// updateIndex adds the mold to the foundry index at path, or refreshes the
+// description of an entry with the same source.
+func updateIndex(path string, m *mold.Mold, source string) error {
+	idx, err := loadIndex(path)
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range idx.Molds {
+		if sameSource(idx.Molds[i].Source, source) {
+			if m.Description != "" {
+				idx.Molds[i].Description = m.Description
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		idx.Molds = append(idx.Molds, index.MoldEntry{Name: m.Name, Source: source, Description: m.Description})
+	}
+	data, err := yaml.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("marshaling foundry index: %w", err)
+	}
+	//#nosec G306 -- foundry index is meant to be published
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing foundry index: %w", err)
+	}
+	return nil
+}
+
+// sameSource reports whether two index sources name the same mold, ignoring
+// scheme, .git suffix, and any version.
+func sameSource(a, b string) bool {
+	ra, errA := foundry.ParseReference(a)
+	rb, errB := foundry.ParseReference(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ra.CacheKey() == rb.CacheKey() && strings.Trim(ra.Subpath, "/") == strings.Trim(rb.Subpath, "/")
+}
@@ -0,0 +1,169 @@
+package smelt
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nimble-giant/ailloy/pkg/foundry/index"
+)
+
+// pushRepo creates a repository whose origin is github.com/acme/molds,
+// rewritten to a local bare repository, with the test mold committed at
+// subpath (or the root when empty). It returns the mold dir and the bare repo.
+func pushRepo(t *testing.T, subpath string) (string, string) {
+	t.Helper()
+	t.Setenv("AILLOY_TMPDIR", t.TempDir())
+	repo, bare := t.TempDir(), t.TempDir()
+	moldDir := filepath.Join(repo, subpath)
+	if err := os.MkdirAll(moldDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeMoldFixture(t, moldDir)
+	if out, err := exec.Command("git", "init", "-q", "--bare", bare).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v\n%s", err, out)
+	}
+	gitCommitAll(t, repo)
+	for _, args := range [][]string{
+		{"remote", "set-url", "origin", "git@github.com:acme/molds.git"},
+		{"config", "url.file://" + bare + ".insteadOf", "git@github.com:acme/molds.git"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return moldDir, bare
+}
+
+func writeIndex(t *testing.T, molds string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "foundry.yaml")
+	content := "apiVersion: v1\nkind: foundry-index\nname: acme\nmolds:" + molds
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPush(t *testing.T) {
+	moldDir, bare := pushRepo(t, "")
+	indexPath := writeIndex(t, " []\n")
+	outputDir := t.TempDir()
+
+	var tool string
+	var args []string
+	release := func(name string, a ...string) ([]byte, error) {
+		tool, args = name, a
+		return nil, nil
+	}
+	res, err := Push(moldDir, PushOptions{OutputDir: outputDir, IndexPath: indexPath, Release: release})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	if res.Tag != "v1.2.3" || res.Source != "github.com/acme/molds" || res.Provider != ProviderGitHub {
+		t.Errorf("result = %+v", res)
+	}
+	if out, err := exec.Command("git", "-C", bare, "tag").Output(); err != nil || strings.TrimSpace(string(out)) != "v1.2.3" {
+		t.Errorf("remote tags = %q (%v), want v1.2.3", out, err)
+	}
+	for _, f := range []string{"test-mold-1.2.3.tar.gz", "test-mold-1.2.3-checksums.txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, f)); err != nil {
+			t.Errorf("missing %s: %v", f, err)
+		}
+	}
+	want := []string{"release", "create", "v1.2.3", res.Tarball, res.Checksum, "--repo", "github.com/acme/molds"}
+	if tool != "gh" || !slices.Equal(args[:len(want)], want) {
+		t.Errorf("release = %s %v, want gh %v ...", tool, args, want)
+	}
+
+	data, _ := os.ReadFile(indexPath) // #nosec G304 -- test temp path
+	idx, err := index.ParseIndex(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Molds) != 1 || idx.Molds[0].Name != "test-mold" || idx.Molds[0].Source != "github.com/acme/molds" {
+		t.Errorf("index molds = %+v", idx.Molds)
+	}
+
+	// Publishing the same version again is refused before anything changes.
+	if _, err := Push(moldDir, PushOptions{NoRelease: true}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second push: err = %v, want tag already exists", err)
+	}
+}
+
+func TestPush_SubpathTagAndIndexUpdate(t *testing.T) {
+	moldDir, _ := pushRepo(t, "molds/review")
+	indexPath := writeIndex(t, "\n- name: review\n  source: https://github.com/acme/molds.git//molds/review\n  description: old\n")
+
+	res, err := Push(moldDir, PushOptions{DryRun: true, IndexPath: indexPath, Provider: ProviderGitLab})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if res.Tag != "review-v1.2.3" || res.Source != "github.com/acme/molds//molds/review" || res.Tarball != "" {
+		t.Errorf("dry-run result = %+v", res)
+	}
+
+	var tool string
+	release := func(name string, _ ...string) ([]byte, error) { tool = name; return nil, nil }
+	if _, err := Push(moldDir, PushOptions{IndexPath: indexPath, Provider: ProviderGitLab, Release: release}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if tool != "glab" {
+		t.Errorf("release tool = %q, want glab", tool)
+	}
+	data, _ := os.ReadFile(indexPath) // #nosec G304 -- test temp path
+	idx, _ := index.ParseIndex(data)
+	if len(idx.Molds) != 1 || idx.Molds[0].Name != "review" || idx.Molds[0].Description != "A test mold for packaging" {
+		t.Errorf("index molds = %+v, want the existing entry updated in place", idx.Molds)
+	}
+}
+
+func TestPush_Refusals(t *testing.T) {
+	t.Run("dirty", func(t *testing.T) {
+		moldDir, _ := pushRepo(t, "")
+		if err := os.WriteFile(filepath.Join(moldDir, "commands", "new.md"), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Push(moldDir, PushOptions{DryRun: true}); err == nil || !strings.Contains(err.Error(), "--allow-dirty") {
+			t.Errorf("err = %v, want uncommitted changes", err)
+		}
+		if _, err := Push(moldDir, PushOptions{DryRun: true, AllowDirty: true}); err != nil {
+			t.Errorf("--allow-dirty: %v", err)
+		}
+	})
+
+	t.Run("unknown host", func(t *testing.T) {
+		moldDir, bare := pushRepo(t, "")
+		for _, args := range [][]string{
+			{"remote", "set-url", "origin", "https://git.example.com/acme/molds.git"},
+			{"config", "url.file://" + bare + ".insteadOf", "https://git.example.com/acme/molds.git"},
+		} {
+			if out, err := exec.Command("git", append([]string{"-C", moldDir}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+		if _, err := Push(moldDir, PushOptions{DryRun: true}); err == nil || !strings.Contains(err.Error(), "--provider") {
+			t.Errorf("err = %v, want a --provider hint", err)
+		}
+		if _, err := Push(moldDir, PushOptions{DryRun: true, NoRelease: true}); err != nil {
+			t.Errorf("--no-release: %v", err)
+		}
+	})
+
+	t.Run("release failure keeps tag", func(t *testing.T) {
+		moldDir, bare := pushRepo(t, "")
+		release := func(string, ...string) ([]byte, error) { return []byte("HTTP 401"), errors.New("exit status 1") }
+		_, err := Push(moldDir, PushOptions{Release: release})
+		if err == nil || !strings.Contains(err.Error(), "tag v1.2.3 is pushed") {
+			t.Errorf("err = %v, want the pushed tag called out", err)
+		}
+		if out, _ := exec.Command("git", "-C", bare, "tag").Output(); strings.TrimSpace(string(out)) != "v1.2.3" {
+			t.Errorf("remote tags = %q", out)
+		}
+	})
+}
@@ -2,15 +2,22 @@ package smelt
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/nimble-giant/ailloy/pkg/mold"
 	"github.com/nimble-giant/ailloy/pkg/safepath"
+	"github.com/nimble-giant/ailloy/pkg/tmpdir"
 )
 
 // PackageTarball packages a mold directory into a .tar.gz archive.
@@ -57,6 +64,17 @@ func PackageTarball(moldDir, outputDir string) (string, int64, error) {
 		}
 	}
 
+	// Record provenance over everything else in the archive
+	contents := files
+	if fluxData != nil {
+		contents = append(slices.Clone(files), archiveFile{path: "flux.yaml", data: fluxData})
+	}
+	prov, err := buildProvenance(m, cleanDir, contents)
+	if err != nil {
+		return "", 0, err
+	}
+	files = append(files, prov)
+
 	// Create the archive
 	prefix := fmt.Sprintf("%s-%s", m.Name, m.Version)
 	size, err := writeTarGz(outputPath, prefix, files, fluxData)
@@ -73,6 +91,23 @@ type archiveFile struct {
 	path string
 	// data is the file content.
 	data []byte
+	// exec marks a file whose source had an executable bit; it is archived
+	// as 0755 instead of 0644.
+	exec bool
+}
+
+// readArchiveFile reads p from fsys as an archiveFile, keeping whether it
+// is executable.
+func readArchiveFile(fsys fs.FS, p string) (archiveFile, error) {
+	data, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return archiveFile{}, err
+	}
+	af := archiveFile{path: p, data: data}
+	if info, err := fs.Stat(fsys, p); err == nil {
+		af.exec = info.Mode().Perm()&0o111 != 0
+	}
+	return af, nil
 }
 
 // collectMoldFiles gathers all files referenced by the mold manifest.
@@ -118,11 +153,30 @@ func collectMoldFiles(moldFS fs.FS, moldDir string) ([]archiveFile, bool, error)
 			continue
 		}
 		seenSrc[rf.SrcPath] = true
-		data, err := fs.ReadFile(moldFS, rf.SrcPath)
+		af, err := readArchiveFile(moldFS, rf.SrcPath)
 		if err != nil {
 			return nil, false, fmt.Errorf("reading %s: %w", rf.SrcPath, err)
 		}
-		files = append(files, archiveFile{path: rf.SrcPath, data: data})
+		files = append(files, af)
+	}
+
+	// Hook scripts usually sit outside the output mapping; cast runs them
+	// from the archive, so they are packaged too.
+	if m, err := mold.ParseMold(moldYAML); err == nil {
+		for _, stage := range []string{mold.HookPreCast, mold.HookPostCast, mold.HookPreUpgrade} {
+			for _, script := range m.Hooks.Scripts(stage) {
+				p := path.Clean(strings.TrimPrefix(script, "./"))
+				if seenSrc[p] || !fs.ValidPath(p) {
+					continue
+				}
+				seenSrc[p] = true
+				af, err := readArchiveFile(moldFS, p)
+				if err != nil {
+					return nil, false, fmt.Errorf("reading hook script %s: %w", script, err)
+				}
+				files = append(files, af)
+			}
+		}
 	}
 
 	// Collect ingots directory if present
@@ -151,11 +205,11 @@ func collectIngots(moldFS fs.FS, _ string) ([]archiveFile, error) {
 		if d.IsDir() {
 			return nil
 		}
-		data, err := fs.ReadFile(moldFS, path)
+		af, err := readArchiveFile(moldFS, path)
 		if err != nil {
 			return fmt.Errorf("reading ingot file %s: %w", path, err)
 		}
-		files = append(files, archiveFile{path: path, data: data})
+		files = append(files, af)
 		return nil
 	})
 	if err != nil {
@@ -190,13 +244,28 @@ func generateFluxDefaults(fluxVars []mold.FluxVar) ([]byte, error) {
 	return data, nil
 }
 
+// archiveEpoch is the modification time stamped on every tarball entry.
+// Fixed metadata makes PackageTarball reproducible: the same mold always
+// yields a byte-identical archive, whoever builds it and whenever.
+var archiveEpoch = time.Unix(0, 0).UTC()
+
 // writeTarGz creates a .tar.gz archive at outputPath with all files under the
 // given prefix directory. If fluxData is non-nil, it's included as flux.yaml.
+//
+// The archive is deterministic: entries are sorted by path and carry a fixed
+// mtime (archiveEpoch), mode 0644, and no owner (uid/gid 0, no user/group
+// names); the gzip header has no name or timestamp.
 func writeTarGz(outputPath, prefix string, files []archiveFile, fluxData []byte) (int64, error) {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0750); err != nil { // #nosec G301
 		return 0, fmt.Errorf("creating output directory: %w", err)
 	}
 
+	entries := slices.Clone(files)
+	if fluxData != nil {
+		entries = append(entries, archiveFile{path: "flux.yaml", data: fluxData})
+	}
+	slices.SortStableFunc(entries, func(a, b archiveFile) int { return strings.Compare(a.path, b.path) })
+
 	f, err := os.Create(outputPath) // #nosec G304 -- output path controlled by caller
 	if err != nil {
 		return 0, fmt.Errorf("creating archive file: %w", err)
@@ -205,12 +274,19 @@ func writeTarGz(outputPath, prefix string, files []archiveFile, fluxData []byte)
 	gw := gzip.NewWriter(f)
 	tw := tar.NewWriter(gw)
 
-	// Write each file
-	for _, af := range files {
+	// Write each file. Modes are normalized to 0755 or 0644, and mtime,
+	// uid and gid fixed, so the archive only depends on the mold's content.
+	for _, af := range entries {
+		mode := int64(0644)
+		if af.exec {
+			mode = 0755
+		}
 		header := &tar.Header{
-			Name: filepath.Join(prefix, af.path),
-			Mode: 0644,
-			Size: int64(len(af.data)),
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(prefix, filepath.ToSlash(af.path)),
+			Mode:     mode,
+			Size:     int64(len(af.data)),
+			ModTime:  archiveEpoch,
 		}
 		if err := tw.WriteHeader(header); err != nil {
 			_ = f.Close()
@@ -222,23 +298,6 @@ func writeTarGz(outputPath, prefix string, files []archiveFile, fluxData []byte)
 		}
 	}
 
-	// Write flux.yaml defaults if present
-	if fluxData != nil {
-		header := &tar.Header{
-			Name: filepath.Join(prefix, "flux.yaml"),
-			Mode: 0644,
-			Size: int64(len(fluxData)),
-		}
-		if err := tw.WriteHeader(header); err != nil {
-			_ = f.Close()
-			return 0, fmt.Errorf("writing tar header for flux.yaml: %w", err)
-		}
-		if _, err := tw.Write(fluxData); err != nil {
-			_ = f.Close()
-			return 0, fmt.Errorf("writing tar data for flux.yaml: %w", err)
-		}
-	}
-
 	// Flush writers to get accurate size
 	if err := tw.Close(); err != nil {
 		_ = f.Close()
@@ -261,3 +320,32 @@ func writeTarGz(outputPath, prefix string, files []archiveFile, fluxData []byte)
 	}
 	return size, nil
 }
+
+// CheckTarballReproducible packages moldDir a second time into a scratch
+// directory and reports an error unless the result is byte-identical to the
+// archive at archivePath.
+func CheckTarballReproducible(moldDir, archivePath string) error {
+	scratch, err := tmpdir.MkdirTemp("smelt-repro-*")
+	if err != nil {
+		return fmt.Errorf("creating scratch directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(scratch) }()
+
+	rebuilt, _, err := PackageTarball(moldDir, scratch)
+	if err != nil {
+		return fmt.Errorf("rebuilding archive: %w", err)
+	}
+	want, err := os.ReadFile(archivePath) // #nosec G304 -- archive path controlled by caller
+	if err != nil {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+	got, err := os.ReadFile(rebuilt) // #nosec G304 -- path under our scratch dir
+	if err != nil {
+		return fmt.Errorf("reading rebuilt archive: %w", err)
+	}
+	if !bytes.Equal(want, got) {
+		return fmt.Errorf("%s is not reproducible: a second build differs (sha256 %x vs %x)",
+			filepath.Base(archivePath), sha256.Sum256(want), sha256.Sum256(got))
+	}
+	return nil
+}
@@ -2,13 +2,17 @@ package smelt
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/nimble-giant/ailloy/pkg/mold"
 )
@@ -47,6 +51,30 @@ func TestCollectMoldFiles_DedupsMultiDestSource(t *testing.T) {
 	}
 }
 
+func TestCollectMoldFiles_HookScripts(t *testing.T) {
+	moldFS := fstest.MapFS{
+		"mold.yaml":        {Data: []byte("name: m\nversion: 0.1.0\nhooks:\n  post-cast: [./hooks/done.sh]\n")},
+		"flux.yaml":        {Data: []byte("output:\n  commands: .claude/commands\n")},
+		"commands/a.md":    {Data: []byte("a")},
+		"hooks/done.sh":    {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+		"hooks/unused.txt": {Data: []byte("x")},
+	}
+	files, _, err := collectMoldFiles(moldFS, t.TempDir())
+	if err != nil {
+		t.Fatalf("collectMoldFiles: %v", err)
+	}
+	exec := map[string]bool{}
+	for _, f := range files {
+		exec[f.path] = f.exec
+	}
+	if isExec, ok := exec["hooks/done.sh"]; !ok || !isExec {
+		t.Errorf("hook script archived = %v, executable = %v; want both", ok, isExec)
+	}
+	if _, ok := exec["hooks/unused.txt"]; ok || exec["commands/a.md"] {
+		t.Errorf("files = %v, want only declared hooks, and blanks not executable", exec)
+	}
+}
+
 // writeMoldFixture creates a minimal valid mold directory structure in dir.
 func writeMoldFixture(t *testing.T, dir string) {
 	t.Helper()
@@ -541,6 +569,94 @@ output:
 	}
 }
 
+func TestPackageTarball_Reproducible(t *testing.T) {
+	moldDir := t.TempDir()
+	writeMoldFixture(t, moldDir)
+
+	first, _, err := PackageTarball(moldDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Touch every source file and loosen its mode without making it
+	// executable; neither may leak into the archive.
+	later := time.Now().Add(time.Hour)
+	err = filepath.WalkDir(moldDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if err := os.Chmod(path, 0666); err != nil {
+			return err
+		}
+		return os.Chtimes(path, later, later)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := PackageTarball(moldDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a, _ := os.ReadFile(first)  // #nosec G304 -- test temp path
+	b, _ := os.ReadFile(second) // #nosec G304 -- test temp path
+	if !bytes.Equal(a, b) {
+		t.Fatal("two builds of the same mold differ")
+	}
+
+	entries := listTarEntries(t, first)
+	if !slices.IsSorted(entries) {
+		t.Errorf("entries not sorted: %v", entries)
+	}
+
+	f, err := os.Open(first) // #nosec G304 -- test temp path
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gr.ModTime.IsZero() || gr.Name != "" {
+		t.Errorf("gzip header carries name %q / mtime %v", gr.Name, gr.ModTime)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hdr.ModTime.Equal(archiveEpoch) || hdr.Mode != 0644 || hdr.Uid != 0 || hdr.Gid != 0 || hdr.Uname != "" || hdr.Gname != "" {
+			t.Errorf("%s: unnormalized header (mtime %v, mode %o, uid %d, gid %d, %q/%q)",
+				hdr.Name, hdr.ModTime, hdr.Mode, hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname)
+		}
+	}
+}
+
+func TestCheckTarballReproducible(t *testing.T) {
+	moldDir := t.TempDir()
+	writeMoldFixture(t, moldDir)
+
+	archive, _, err := PackageTarball(moldDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckTarballReproducible(moldDir, archive); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(archive, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckTarballReproducible(moldDir, archive); err == nil || !strings.Contains(err.Error(), "not reproducible") {
+		t.Errorf("err = %v, want a reproducibility failure", err)
+	}
+}
+
 func TestGenerateFluxDefaults(t *testing.T) {
 	tests := []struct {
 		name     string
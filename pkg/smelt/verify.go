@@ -0,0 +1,86 @@
+package smelt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ErrNoProvenance indicates an artifact smelted before provenance.yaml was
+// recorded, whose files can't be verified.
+var ErrNoProvenance = errors.New("no " + ProvenanceFile + " (smelted by an older ailloy?)")
+
+// IntegrityError reports files of a smelted artifact that don't match the
+// digests its provenance.yaml recorded.
+type IntegrityError struct {
+	Problems []string // one per file, sorted by path
+}
+
+func (e *IntegrityError) Error() string {
+	return "files do not match " + ProvenanceFile + ": " + strings.Join(e.Problems, "; ")
+}
+
+// VerifyFS checks the files of a smelted artifact in fsys against the
+// digests in its provenance.yaml: every recorded file must be present with
+// its recorded size and SHA-256, and no other file may be present. It
+// returns the provenance, and an *IntegrityError listing any mismatch or
+// ErrNoProvenance when there is no record to check against.
+func VerifyFS(fsys fs.FS) (*Provenance, error) {
+	data, err := fs.ReadFile(fsys, ProvenanceFile)
+	if err != nil {
+		return nil, ErrNoProvenance
+	}
+	var p Provenance
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, &IntegrityError{Problems: []string{fmt.Sprintf("%s: %v", ProvenanceFile, err)}}
+	}
+
+	var problems []string
+	recorded := make(map[string]bool, len(p.Files))
+	for _, f := range p.Files {
+		recorded[f.Path] = true
+		body, err := fs.ReadFile(fsys, f.Path)
+		if err != nil {
+			problems = append(problems, f.Path+": missing")
+			continue
+		}
+		sum := sha256.Sum256(body)
+		if len(body) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			problems = append(problems, f.Path+": content does not match its recorded sha256")
+		}
+	}
+	_ = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || p == ProvenanceFile {
+			return nil
+		}
+		if !recorded[p] {
+			problems = append(problems, p+": not recorded in "+ProvenanceFile)
+		}
+		return nil
+	})
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return &p, &IntegrityError{Problems: problems}
+	}
+	return &p, nil
+}
+
+// Digest returns a digest of the artifact's contents as recorded in p:
+// the SHA-256 of its "<sha256>  <path>" lines, sorted by path, prefixed
+// "sha256:". It changes when any file does and ignores the rest of the
+// record (builder, build time, source).
+func (p *Provenance) Digest() string {
+	files := append([]FileDigest(nil), p.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	h := sha256.New()
+	for _, f := range files {
+		_, _ = fmt.Fprintf(h, "%s  %s\n", f.SHA256, f.Path)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
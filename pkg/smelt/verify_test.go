@@ -0,0 +1,56 @@
+package smelt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nimble-giant/ailloy/pkg/mold"
+)
+
+func TestVerifyFS(t *testing.T) {
+	files := []archiveFile{
+		{path: "mold.yaml", data: []byte("name: demo\nversion: 1.2.3\n")},
+		{path: "commands/hi.md", data: []byte("Hello {{ .team }}\n")},
+	}
+	prov, err := buildProvenance(&mold.Mold{Name: "demo", Version: "1.2.3"}, t.TempDir(), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{prov.path: {Data: prov.data}}
+	for _, f := range files {
+		fsys[f.path] = &fstest.MapFile{Data: f.data}
+	}
+
+	p, err := VerifyFS(fsys)
+	if err != nil {
+		t.Fatalf("intact files: %v", err)
+	}
+	digest := p.Digest()
+	if !strings.HasPrefix(digest, "sha256:") || len(digest) != len("sha256:")+64 {
+		t.Errorf("Digest() = %q", digest)
+	}
+
+	fsys["commands/hi.md"] = &fstest.MapFile{Data: []byte("Hello {{ .evil }}\n")}
+	delete(fsys, "mold.yaml")
+	fsys["commands/extra.md"] = &fstest.MapFile{Data: []byte("x")}
+	_, err = VerifyFS(fsys)
+	var integrity *IntegrityError
+	if !errors.As(err, &integrity) {
+		t.Fatalf("err = %v, want *IntegrityError", err)
+	}
+	want := []string{
+		"commands/extra.md: not recorded in provenance.yaml",
+		"commands/hi.md: content does not match its recorded sha256",
+		"mold.yaml: missing",
+	}
+	if got := strings.Join(integrity.Problems, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("problems:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	delete(fsys, prov.path)
+	if _, err := VerifyFS(fsys); !errors.Is(err, ErrNoProvenance) {
+		t.Errorf("without provenance: err = %v, want ErrNoProvenance", err)
+	}
+}
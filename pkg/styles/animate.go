@@ -27,9 +27,9 @@ func SetNoAnimate(v bool) {
 
 // ShouldAnimate reports whether terminal animations should run. Animations are
 // suppressed when stdout is not a TTY, when standard "be quiet" environment
-// variables are set, or when the user passed --no-animate.
+// variables are set, or when the user passed --no-animate or --plain.
 func ShouldAnimate() bool {
-	if noAnimate {
+	if noAnimate || Plain() {
 		return false
 	}
 	if os.Getenv("AILLOY_NO_ANIMATE") != "" {
@@ -151,8 +151,12 @@ const (
 ˙✧˖°🦊 ༘ ⋆｡˚`
 )
 
-// FoxArt returns styled ASCII art based on context
+// FoxArt returns styled ASCII art based on context. Plain output has no
+// fox art, so it returns "".
 func FoxArt(foxType string) string {
+	if Plain() {
+		return ""
+	}
 	var art string
 	var style lipgloss.Style
 
@@ -197,7 +201,7 @@ func WelcomeChrome(ver string) string {
 		Padding(1, 3).
 		MarginTop(1).
 		MarginBottom(1).
-		Render("🧠 AILLOY")
+		Render(Text("🧠 AILLOY"))
 
 	subtitle := SubtleStyle.Render("AI-powered development workflows")
 
@@ -214,50 +218,35 @@ func WelcomeChrome(ver string) string {
 
 // Welcome banner with ailloy fox matching the main logo
 func WelcomeBanner(ver string) string {
+	return withFox("ailloy", WelcomeChrome(ver))
+}
+
+// withFox stacks the named fox above block, or returns block alone in plain
+// output.
+func withFox(foxType, block string) string {
+	if Plain() {
+		return block
+	}
 	return lipgloss.JoinVertical(
 		lipgloss.Center,
-		FoxArt("ailloy"),
-		WelcomeChrome(ver),
+		FoxArt(foxType),
+		block,
 	)
 }
 
 // Success message with celebrating fox
 func SuccessBanner(message string) string {
-	fox := FoxArt("celebration")
-
-	styledMessage := SuccessStyle.Render("✅ " + message)
-
-	return lipgloss.JoinVertical(
-		lipgloss.Center,
-		fox,
-		styledMessage,
-	)
+	return withFox("celebration", SuccessStyle.Render("✅ "+message))
 }
 
 // Loading message with thinking fox
 func LoadingBanner(message string) string {
-	fox := FoxArt("thinking")
-
-	styledMessage := InfoStyle.Render("⏳ " + message)
-
-	return lipgloss.JoinVertical(
-		lipgloss.Center,
-		fox,
-		styledMessage,
-	)
+	return withFox("thinking", InfoStyle.Render("⏳ "+message))
 }
 
 // Working message with working fox
 func WorkingBanner(message string) string {
-	fox := FoxArt("working")
-
-	styledMessage := AccentStyle.Render("🔧 " + message)
-
-	return lipgloss.JoinVertical(
-		lipgloss.Center,
-		fox,
-		styledMessage,
-	)
+	return withFox("working", AccentStyle.Render("🔧 "+message))
 }
 
 // Inline fox bullet for lists using the small fox
@@ -270,7 +259,7 @@ func Logo() string {
 	return lipgloss.NewStyle().
 		Foreground(Primary1).
 		Bold(true).
-		Render("🧠 Ailloy")
+		Render(Text("🧠 Ailloy"))
 }
 
 // Small fox with text for compact displays
@@ -0,0 +1,187 @@
+package styles
+
+import (
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var (
+	noColor bool
+	plain   bool
+
+	// savedProfile holds the color profile in effect before Init switched
+	// to ASCII, so clearing the flags restores it.
+	savedProfile *termenv.Profile
+)
+
+// baseStyles records every exported style as declared, so Init can derive
+// the plain variants from scratch each time it runs.
+var baseStyles = map[*lipgloss.Style]lipgloss.Style{
+	&HeaderStyle:      HeaderStyle,
+	&LargeHeaderStyle: LargeHeaderStyle,
+	&SuccessStyle:     SuccessStyle,
+	&ErrorStyle:       ErrorStyle,
+	&InfoStyle:        InfoStyle,
+	&WarningStyle:     WarningStyle,
+	&SubtleStyle:      SubtleStyle,
+	&AccentStyle:      AccentStyle,
+	&CodeStyle:        CodeStyle,
+	&BoxStyle:         BoxStyle,
+	&SuccessBoxStyle:  SuccessBoxStyle,
+	&ErrorBoxStyle:    ErrorBoxStyle,
+	&InfoBoxStyle:     InfoBoxStyle,
+}
+
+// SetNoColor toggles color output. Wired up to the root command's
+// --no-color persistent flag; takes effect on the next Init.
+func SetNoColor(v bool) {
+	noColor = v
+}
+
+// SetPlain toggles plain output: no color, no animation, ASCII borders, and
+// emoji replaced with ASCII markers. Wired up to the root command's --plain
+// persistent flag; takes effect on the next Init.
+func SetPlain(v bool) {
+	plain = v
+}
+
+// Plain reports whether plain output is in effect: --plain, AILLOY_PLAIN,
+// or TERM=dumb.
+func Plain() bool {
+	return plain || os.Getenv("AILLOY_PLAIN") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// NoColor reports whether color output is disabled: --no-color, the
+// NO_COLOR convention (https://no-color.org), or plain output.
+func NoColor() bool {
+	return noColor || os.Getenv("NO_COLOR") != "" || Plain()
+}
+
+// Text returns s with emoji and box-drawing glyphs replaced by ASCII when
+// plain output is in effect, and s unchanged otherwise. Use it for strings
+// rendered outside the package styles, which apply it themselves.
+func Text(s string) string {
+	if !Plain() {
+		return s
+	}
+	return ASCII(s)
+}
+
+// TableBorder is the border for command tables: lipgloss.NormalBorder, or
+// lipgloss.ASCIIBorder in plain output.
+func TableBorder() lipgloss.Border {
+	if Plain() {
+		return lipgloss.ASCIIBorder()
+	}
+	return lipgloss.NormalBorder()
+}
+
+// BoxBorder is the border for boxes and cards: lipgloss.RoundedBorder, or
+// lipgloss.ASCIIBorder in plain output.
+func BoxBorder() lipgloss.Border {
+	if Plain() {
+		return lipgloss.ASCIIBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// applyOutputMode resets the exported styles and the color profile to match
+// the current flags and environment.
+func applyOutputMode() {
+	if NoColor() {
+		if savedProfile == nil {
+			p := lipgloss.ColorProfile()
+			savedProfile = &p
+		}
+		lipgloss.SetColorProfile(termenv.Ascii)
+	} else if savedProfile != nil {
+		lipgloss.SetColorProfile(*savedProfile)
+		savedProfile = nil
+	}
+
+	p := Plain()
+	for ptr, base := range baseStyles {
+		s := base
+		if p {
+			s = s.Transform(ASCII)
+			if s.GetBorderStyle() != (lipgloss.Border{}) {
+				s = s.Border(lipgloss.ASCIIBorder())
+			}
+		}
+		*ptr = s
+	}
+}
+
+// asciiGlyphs maps the glyphs ailloy prints to their plain-output stand-ins.
+// Other symbols (most emoji) are dropped.
+var asciiGlyphs = map[rune]string{
+	'✅': "[ok]",
+	'✔': "[ok]",
+	'✓': "[ok]",
+	'❌': "[x]",
+	'✗': "[x]",
+	'✘': "[x]",
+	'⚠': "[!]",
+	'💡': "tip:",
+	'🦊': "*",
+	'•': "*",
+	'✦': "*",
+	'→': "->",
+	'←': "<-",
+	'—': "-",
+	'–': "-",
+	'…': "...",
+	'█': "#",
+	'░': "-",
+	'‘': "'",
+	'’': "'",
+	'“': `"`,
+	'”': `"`,
+}
+
+// ASCII replaces the emoji and symbols in s with ASCII: known glyphs become
+// markers such as "[ok]" and "[!]", and the rest are dropped along with the
+// spacing that followed them. Letters and punctuation are kept.
+func ASCII(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	// After a glyph, squeeze the spaces that padded it: keep one after a
+	// marker, none after a dropped glyph that started a line or followed
+	// a space.
+	squeeze, dropAll := false, false
+	for _, r := range s {
+		if r == '\u200d' || unicode.Is(unicode.Variation_Selector, r) {
+			// Joiners and presentation selectors go with their emoji.
+			continue
+		}
+		if squeeze && r == ' ' {
+			if !dropAll {
+				b.WriteByte(' ')
+				dropAll = true
+			}
+			continue
+		}
+		squeeze = false
+
+		if rep, ok := asciiGlyphs[r]; ok {
+			b.WriteString(rep)
+			squeeze, dropAll = true, false
+			continue
+		}
+		switch {
+		case r < 0x80:
+			b.WriteRune(r)
+		case unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r):
+			out := b.String()
+			squeeze = true
+			dropAll = out == "" || strings.HasSuffix(out, " ") || strings.HasSuffix(out, "\n")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
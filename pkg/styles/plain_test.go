@@ -0,0 +1,72 @@
+package styles
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestASCII(t *testing.T) {
+	cases := map[string]string{
+		"✅ Created: a.md":        "[ok] Created: a.md",
+		"⚠️  stale lock":         "[!] stale lock",
+		"🧪 Kept ephemeral trial": "Kept ephemeral trial",
+		"done 🎉 now":             "done now",
+		"🦊 ailloy cast":          "* ailloy cast",
+		"a → b — c…":             "a -> b - c...",
+		"café":                   "café",
+		"███░░":                  "###--",
+	}
+	for in, want := range cases {
+		if got := ASCII(in); got != want {
+			t.Errorf("ASCII(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPlainMode(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("AILLOY_PLAIN", "")
+	t.Setenv("NO_COLOR", "")
+	t.Cleanup(func() {
+		SetPlain(false)
+		Init()
+	})
+
+	SetPlain(true)
+	Init()
+	if !Plain() || !NoColor() || ShouldAnimate() {
+		t.Fatal("--plain should imply no color and no animation")
+	}
+	got := SuccessBanner("Mold cast")
+	if got != "[ok] Mold cast" {
+		t.Errorf("SuccessBanner = %q", got)
+	}
+	box := InfoBoxStyle.Render("🦊 hi")
+	if strings.ContainsAny(box, "╭╮╰╯│─") || !strings.Contains(box, "* hi") {
+		t.Errorf("InfoBoxStyle = %q, want ASCII border and text", box)
+	}
+
+	SetPlain(false)
+	Init()
+	if got := SuccessStyle.Render("✅"); !strings.Contains(got, "✅") {
+		t.Errorf("styles not restored: %q", got)
+	}
+	if !strings.Contains(WorkingBanner("x"), "🔧") {
+		t.Error("fox banner not restored")
+	}
+}
+
+func TestPlainMode_Env(t *testing.T) {
+	t.Setenv("AILLOY_PLAIN", "")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "dumb")
+	if !Plain() {
+		t.Error("TERM=dumb should select plain output")
+	}
+
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("NO_COLOR", "1")
+	if Plain() || !NoColor() {
+		t.Error("NO_COLOR should disable color only")
+	}
+}
@@ -120,13 +120,13 @@ func GradientHeader(text string) string {
 		Foreground(White).
 		Bold(true).
 		Padding(0, 2).
-		Render(text)
+		Render(Text(text))
 }
 
 // Fox-themed bullet points
 func BulletPoint(text string, icon string) string {
 	if icon == "" {
-		icon = "🦊"
+		icon = SmallFox
 	}
 	return AccentStyle.Render(icon+" ") + text
 }
@@ -134,7 +134,7 @@ func BulletPoint(text string, icon string) string {
 // Create a styled table
 func NewTable() *table.Table {
 	t := table.New().
-		Border(lipgloss.NormalBorder()).
+		Border(TableBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(Primary1)).
 		StyleFunc(func(row, col int) lipgloss.Style {
 			switch {
@@ -180,8 +180,10 @@ func SupportsColor() bool {
 	return termenv.HasDarkBackground() || termenv.ColorProfile() != termenv.Ascii
 }
 
-// Initialize styles based on terminal capabilities
+// Initialize styles based on terminal capabilities and the --no-color and
+// --plain settings
 func Init() {
+	applyOutputMode()
 	if !SupportsColor() {
 		// Fallback to simpler styles for limited terminals
 		HeaderStyle = HeaderStyle.Foreground(lipgloss.NoColor{})
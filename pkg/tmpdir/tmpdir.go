@@ -0,0 +1,204 @@
+// Package tmpdir centralizes ailloy's scratch space under ~/.ailloy/tmp.
+//
+// Downloads, smelt staging, and cache extraction create their temporary
+// files here instead of $TMPDIR, so an interrupted run leaves litter in
+// one known place that the next invocation sweeps up (see CleanOrphans).
+// Cache entries are staged here and moved into place with Commit, so a
+// half-written entry is never visible at its final path.
+package tmpdir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Env overrides the scratch root.
+const Env = "AILLOY_TMPDIR"
+
+// OrphanAge is how old a scratch entry must be before CleanOrphans treats
+// it as abandoned by a crashed or interrupted run.
+const OrphanAge = 24 * time.Hour
+
+// Root returns the scratch root: $AILLOY_TMPDIR when set, else
+// ~/.ailloy/tmp, falling back to $TMPDIR/ailloy when the home directory is
+// unknown.
+func Root() string {
+	if v := os.Getenv(Env); v != "" {
+		return v
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".ailloy", "tmp")
+	}
+	return filepath.Join(os.TempDir(), "ailloy")
+}
+
+// MkdirTemp creates a new directory under Root, as os.MkdirTemp does.
+func MkdirTemp(pattern string) (string, error) {
+	root := Root()
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return "", fmt.Errorf("creating scratch directory %s: %w", root, err)
+	}
+	return os.MkdirTemp(root, pattern)
+}
+
+// CreateTemp creates a new file under Root, as os.CreateTemp does.
+func CreateTemp(pattern string) (*os.File, error) {
+	root := Root()
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("creating scratch directory %s: %w", root, err)
+	}
+	return os.CreateTemp(root, pattern)
+}
+
+// Commit moves a staged file or directory to dest, which must not exist.
+// The move is a rename when staged and dest share a filesystem. Otherwise
+// staged is copied to a hidden sibling of dest and that is renamed, so dest
+// only ever appears complete. staged is gone on success.
+func Commit(staged, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(dest), err)
+	}
+	err := os.Rename(staged, dest)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) || exists(dest) {
+		return fmt.Errorf("moving %s into place: %w", dest, err)
+	}
+
+	// Cross-device rename: copy next to dest, then rename.
+	sibling, err := os.MkdirTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".partial-*")
+	if err != nil {
+		return fmt.Errorf("staging %s: %w", dest, err)
+	}
+	target := filepath.Join(sibling, "entry")
+	if err := copyTree(staged, target); err != nil {
+		_ = os.RemoveAll(sibling)
+		return fmt.Errorf("copying %s into place: %w", dest, err)
+	}
+	if err := os.Rename(target, dest); err != nil {
+		_ = os.RemoveAll(sibling)
+		return fmt.Errorf("moving %s into place: %w", dest, err)
+	}
+	_ = os.RemoveAll(sibling)
+	_ = os.RemoveAll(staged)
+	return nil
+}
+
+// WriteFileAtomic writes data to path via a temp file in the same
+// directory and a rename, so readers never see a truncated file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// CleanOrphans removes entries directly under Root last modified more than
+// maxAge ago and returns how many it removed. A missing root is not an
+// error.
+func CleanOrphans(maxAge time.Duration) (int, error) {
+	return cleanOrphans(Root(), time.Now().Add(-maxAge))
+}
+
+func cleanOrphans(root string, cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	var firstErr error
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, e.Name())); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		removed++
+	}
+	return removed, firstErr
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			in, err := os.Open(path) // #nosec G304 -- walking our own staged tree
+			if err != nil {
+				return err
+			}
+			defer func() { _ = in.Close() }()
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm()) // #nosec G304 -- target under our own staging dir
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, in); err != nil {
+				_ = out.Close()
+				return err
+			}
+			return out.Close()
+		}
+	})
+}
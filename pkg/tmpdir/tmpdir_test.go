@@ -0,0 +1,112 @@
+package tmpdir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMkdirTemp_UsesEnvRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "scratch")
+	t.Setenv(Env, root)
+
+	dir, err := MkdirTemp("clone-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(dir) != root || !strings.HasPrefix(filepath.Base(dir), "clone-") {
+		t.Errorf("MkdirTemp = %s, want clone-* under %s", dir, root)
+	}
+}
+
+func TestCommit_MovesStagedTree(t *testing.T) {
+	base := t.TempDir()
+	staged := filepath.Join(base, "staged")
+	if err := os.MkdirAll(filepath.Join(staged, "sub"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(staged, "sub", "f.txt"), []byte("hi"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(base, "cache", "entry")
+	if err := Commit(staged, dest); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "sub", "f.txt")) // #nosec G304 -- test temp path
+	if err != nil || string(data) != "hi" {
+		t.Fatalf("committed file = %q, %v", data, err)
+	}
+	if exists(staged) {
+		t.Error("staged tree should be gone after Commit")
+	}
+}
+
+func TestCommit_CopyTreeFallback(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "src")
+	if err := os.MkdirAll(filepath.Join(src, "a"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "a", "b.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a/b.txt", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(base, "dst")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "link")); err != nil || string(data) != "x" { // #nosec G304 -- test temp path
+		t.Errorf("symlinked copy = %q, %v", data, err)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "index.yaml")
+	if err := WriteFileAtomic(path, []byte("one"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("two"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path) // #nosec G304 -- test temp path
+	if string(data) != "two" {
+		t.Errorf("content = %q, want two", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the target file, found %d entries", len(entries))
+	}
+}
+
+func TestCleanOrphans(t *testing.T) {
+	root := t.TempDir()
+	old := filepath.Join(root, "clone-old")
+	fresh := filepath.Join(root, "clone-fresh")
+	for _, d := range []string{old, fresh} {
+		if err := os.MkdirAll(filepath.Join(d, "git"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := time.Now().Add(-2 * OrphanAge)
+	if err := os.Chtimes(old, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := cleanOrphans(root, time.Now().Add(-OrphanAge))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || exists(old) || !exists(fresh) {
+		t.Errorf("removed %d; old exists=%v fresh exists=%v", n, exists(old), exists(fresh))
+	}
+
+	if n, err := cleanOrphans(filepath.Join(root, "missing"), time.Now()); n != 0 || err != nil {
+		t.Errorf("missing root: %d, %v", n, err)
+	}
+}
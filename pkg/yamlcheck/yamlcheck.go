@@ -0,0 +1,245 @@
+// Package yamlcheck finds keys in a YAML document that the Go type it is
+// decoded into has no field for. Decoding silently drops such keys, so a
+// typo like `fluxx:` in mold.yaml would otherwise go unnoticed.
+package yamlcheck
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// ExtensionPrefix marks keys that are deliberately outside the schema
+// (`x-team: platform`). They are never reported.
+const ExtensionPrefix = "x-"
+
+// UnknownField is a key with no matching field in the target type.
+type UnknownField struct {
+	// Path is the dotted location of the key, e.g. "flux[2].defualt".
+	Path string
+	// Key is the key itself.
+	Key string
+	// Line is the 1-based line the key is on.
+	Line int
+	// Suggestion is the closest known key at the same level, or "".
+	Suggestion string
+}
+
+// String renders the field as "unknown field `key`", with a suggestion when
+// there is one.
+func (f UnknownField) String() string {
+	s := fmt.Sprintf("unknown field `%s`", f.Path)
+	if f.Suggestion != "" {
+		s += fmt.Sprintf(" (did you mean `%s`?)", f.Suggestion)
+	}
+	return s
+}
+
+// UnknownFields returns every key in data that decoding into v would ignore.
+// v is a value or pointer of the target type. Maps and interface values are
+// free-form, as are types that unmarshal themselves, so nothing below them
+// is reported. A document that does not parse returns the parse error.
+func UnknownFields(data []byte, v any) ([]UnknownField, error) {
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	var unknown []UnknownField
+	for _, doc := range file.Docs {
+		if doc.Body != nil {
+			walk(doc.Body, reflect.TypeOf(v), "", &unknown)
+		}
+	}
+	return unknown, nil
+}
+
+// KeyLines maps the dotted path of every mapping key in data, nested ones
+// included, to the 1-based line it is on. Keys inside sequences are not
+// listed. A document that does not parse returns the parse error.
+func KeyLines(data []byte) (map[string]int, error) {
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	lines := map[string]int{}
+	for _, doc := range file.Docs {
+		if doc.Body != nil {
+			keyLines(doc.Body, "", lines)
+		}
+	}
+	return lines, nil
+}
+
+func keyLines(node ast.Node, path string, lines map[string]int) {
+	for _, mv := range mappingValues(unwrap(node)) {
+		key := join(path, keyString(mv))
+		lines[key] = mv.Key.GetToken().Position.Line
+		keyLines(mv.Value, key, lines)
+	}
+}
+
+var unmarshalerTypes = []reflect.Type{
+	reflect.TypeFor[yaml.BytesUnmarshaler](),
+	reflect.TypeFor[yaml.BytesUnmarshalerContext](),
+	reflect.TypeFor[yaml.InterfaceUnmarshaler](),
+	reflect.TypeFor[yaml.InterfaceUnmarshalerContext](),
+	reflect.TypeFor[yaml.NodeUnmarshaler](),
+	reflect.TypeFor[yaml.NodeUnmarshalerContext](),
+}
+
+func walk(node ast.Node, t reflect.Type, path string, unknown *[]UnknownField) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return
+	}
+	for _, u := range unmarshalerTypes {
+		if reflect.PointerTo(t).Implements(u) {
+			return
+		}
+	}
+	node = unwrap(node)
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := structFields(t)
+		for _, mv := range mappingValues(node) {
+			key := keyString(mv)
+			if key == "<<" || strings.HasPrefix(key, ExtensionPrefix) {
+				continue
+			}
+			ft, ok := fields[key]
+			if !ok {
+				*unknown = append(*unknown, UnknownField{
+					Path:       join(path, key),
+					Key:        key,
+					Line:       mv.Key.GetToken().Position.Line,
+					Suggestion: suggest(key, fields),
+				})
+				continue
+			}
+			walk(mv.Value, ft, join(path, key), unknown)
+		}
+	case reflect.Map:
+		for _, mv := range mappingValues(node) {
+			walk(mv.Value, t.Elem(), join(path, keyString(mv)), unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		if seq, ok := node.(*ast.SequenceNode); ok {
+			for i, item := range seq.Values {
+				walk(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+			}
+		}
+	}
+}
+
+// structFields maps each yaml key t decodes to the type of its field,
+// including the fields of inlined structs.
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range structFields(ft) {
+					fields[k] = v
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func unwrap(node ast.Node) ast.Node {
+	for {
+		switch n := node.(type) {
+		case *ast.AnchorNode:
+			node = n.Value
+		case *ast.TagNode:
+			node = n.Value
+		default:
+			return node
+		}
+	}
+}
+
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	}
+	return nil
+}
+
+func keyString(mv *ast.MappingValueNode) string {
+	if tk := mv.Key.GetToken(); tk != nil {
+		return tk.Value
+	}
+	return mv.Key.String()
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// suggest returns the known key closest to key, when it is close enough to
+// plausibly be what was meant.
+func suggest(key string, fields map[string]reflect.Type) string {
+	target := strings.ToLower(key)
+	maxDist := min(3, max(1, len(target)/3), len(target)-1)
+	best, bestDist := "", maxDist+1
+	for name := range fields {
+		d := levenshtein(target, strings.ToLower(name))
+		if d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// levenshtein computes the edit distance between two short strings.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(curr[j-1]+1, prev[j]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,84 @@
+package yamlcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+type inner struct {
+	Name    string `yaml:"name"`
+	Default string `yaml:"default,omitempty"`
+}
+
+type embedded struct {
+	Shared string `yaml:"shared"`
+}
+
+type manifest struct {
+	Version  string         `yaml:"version"`
+	Flux     []inner        `yaml:"flux,omitempty"`
+	Author   *inner         `yaml:"author,omitempty"`
+	Profiles map[string]any `yaml:"profiles,omitempty"`
+	Output   any            `yaml:"output,omitempty"`
+	Skipped  string         `yaml:"-"`
+	Base     embedded       `yaml:",inline"`
+}
+
+func TestUnknownFields(t *testing.T) {
+	data := []byte(`version: 1.0.0
+fluxx: []
+flux:
+  - name: a
+    defualt: x
+author:
+  name: me
+  url: https://example.com
+profiles:
+  cursor: {anything: goes}
+output: {free: form}
+shared: yes
+x-team: platform
+Skipped: no
+`)
+	got, err := UnknownFields(data, &manifest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UnknownField{
+		{Path: "fluxx", Key: "fluxx", Line: 2, Suggestion: "flux"},
+		{Path: "flux[0].defualt", Key: "defualt", Line: 5, Suggestion: "default"},
+		{Path: "author.url", Key: "url", Line: 8},
+		{Path: "Skipped", Key: "Skipped", Line: 14},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownFields =\n%+v\nwant\n%+v", got, want)
+	}
+	if s := got[0].String(); s != "unknown field `fluxx` (did you mean `flux`?)" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestUnknownFields_ParseError(t *testing.T) {
+	if _, err := UnknownFields([]byte("a: [\n"), &manifest{}); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestKeyLines(t *testing.T) {
+	data := []byte(`board: Ops
+project:
+  # the org
+  org: acme
+  ids: [1, 2]
+items:
+  - name: a
+`)
+	got, err := KeyLines(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"board": 1, "project": 2, "project.org": 4, "project.ids": 5, "items": 6}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KeyLines = %v, want %v", got, want)
+	}
+}